@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"service-travego/internal/midtransapi/midtranstest"
+)
+
+// fake_midtrans serves the Midtrans status and refund endpoints locally. Point
+// MIDTRANS_API_URL at it and seed transactions with
+//
+//	curl -u $MIDTRANS_SERVER_KEY: -X POST localhost:8089/_fake/transactions \
+//	  -d '{"order_id":"INV-1","transaction_status":"settlement","gross_amount":"150000.00"}'
+func main() {
+	addr := flag.String("addr", ":8089", "listen address")
+	flag.Parse()
+
+	fake := midtranstest.NewFake(os.Getenv("MIDTRANS_SERVER_KEY"))
+	log.Printf("fake midtrans listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, fake))
+}
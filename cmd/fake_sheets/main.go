@@ -0,0 +1,22 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"service-travego/internal/sheetsapi/sheetstest"
+)
+
+// fake_sheets serves the Google token endpoint and the Sheets API locally.
+// Point GOOGLE_SHEETS_API_URL at it, set token_uri of the service account key
+// to http://localhost:8090/token and create spreadsheets with
+//
+//	curl -X POST localhost:8090/_fake/spreadsheets -d '{"spreadsheet_id":"finance"}'
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	flag.Parse()
+
+	log.Printf("fake sheets listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, sheetstest.NewFake()))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"service-travego/configs"
+	"service-travego/database"
+	"service-travego/helper"
+	"service-travego/internal/migrate"
+)
+
+const usage = `usage: go run ./cmd/migrate [flags] <command>
+
+commands:
+  up [-n N] [-baseline]  apply pending migrations (N at most)
+  down [-n N]            revert the last N applied migrations (default 1)
+  status                 list migrations and whether they are applied
+  create <name>          add an empty up/down migration pair
+
+flags:
+`
+
+// migrate applies the migrations in db/migrations to the database configured
+// the same way as the API (config/app.json overridden by the .env file)
+func main() {
+	dir := flag.String("dir", "db/migrations", "migrations directory")
+	configPath := flag.String("config", "config/app.json", "config file")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	if cmd == "create" {
+		if len(args) < 1 {
+			log.Fatal("create needs a migration name")
+		}
+		up, down, err := migrate.Create(*dir, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Printf("created %s\ncreated %s\n", up, down)
+		return
+	}
+
+	sub := flag.NewFlagSet(cmd, flag.ExitOnError)
+	n := sub.Int("n", 0, "number of migrations")
+	baseline := sub.Bool("baseline", false, "record the first migration as applied without running it, for databases created before migrations were tracked")
+	_ = sub.Parse(args)
+
+	if err := helper.LoadEnv(); err != nil {
+		log.Printf("Warning: Failed to load .env file: %v. Continuing with system environment variables.", err)
+	}
+	cfg, err := configs.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := database.InitDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db, cfg.Database.Driver, *dir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch cmd {
+	case "up":
+		done, err := m.Up(ctx, *n, *baseline)
+		for _, mig := range done {
+			fmt.Printf("applied  %03d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		done, err := m.Down(ctx, *n)
+		for _, mig := range done {
+			fmt.Printf("reverted %03d_%s\n", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, st := range list {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if st.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%03d_%-40s %s\n", st.Version, st.Name, state)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
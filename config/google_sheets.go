@@ -0,0 +1,38 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"service-travego/internal/sheetsapi"
+)
+
+// InitGoogleSheets membuat client Google Sheets dari service account;
+// nil jika service account belum dikonfigurasi
+func InitGoogleSheets() *sheetsapi.Client {
+	raw := []byte(os.Getenv("GOOGLE_SHEETS_CREDENTIALS"))
+	if path := os.Getenv("GOOGLE_SHEETS_CREDENTIALS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			slog.Warn("failed to read Google Sheets credentials", "error", err)
+			return nil
+		}
+		raw = data
+	}
+	if len(raw) == 0 {
+		slog.Info("Google Sheets credentials not set, sheet sync disabled")
+		return nil
+	}
+
+	account, err := sheetsapi.ParseServiceAccount(raw)
+	if err != nil {
+		slog.Warn("invalid Google Sheets credentials", "error", err)
+		return nil
+	}
+
+	// GOOGLE_SHEETS_API_URL mengarahkan Sheets API ke server lain (mis. fake_sheets)
+	apiURL := os.Getenv("GOOGLE_SHEETS_API_URL")
+	if apiURL == "" {
+		apiURL = sheetsapi.DefaultBaseURL
+	}
+	return sheetsapi.NewClient(apiURL, account, nil)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"service-travego/internal/midtransapi"
 
 	"github.com/veritrans/go-midtrans"
 )
@@ -10,6 +11,7 @@ import (
 type MidtransConfig struct {
 	Client midtrans.Client
 	Snap   midtrans.SnapGateway
+	API    *midtransapi.Client
 }
 
 // InitMidtrans menginisialisasi client Midtrans
@@ -32,8 +34,15 @@ func InitMidtrans() *MidtransConfig {
 		Client: client,
 	}
 
+	// MIDTRANS_API_URL mengarahkan status & refund API ke server lain (mis. fake_midtrans)
+	apiURL := os.Getenv("MIDTRANS_API_URL")
+	if apiURL == "" {
+		apiURL = midtransEnv.String()
+	}
+
 	return &MidtransConfig{
 		Client: client,
 		Snap:   snap,
+		API:    midtransapi.NewClient(apiURL, serverKey),
 	}
 }
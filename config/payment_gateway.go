@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"service-travego/internal/paymentgateway"
+)
+
+// InitPaymentGateways mendaftarkan payment gateway yang tersedia. Xendit hanya
+// aktif bila XENDIT_SECRET_KEY diisi.
+func InitPaymentGateways(midtransCfg *MidtransConfig) *paymentgateway.Registry {
+	defaultProvider := os.Getenv("PAYMENT_GATEWAY_DEFAULT")
+	if !paymentgateway.IsSupported(defaultProvider) {
+		defaultProvider = paymentgateway.ProviderMidtrans
+	}
+
+	var gateways []paymentgateway.Gateway
+	if midtransCfg != nil {
+		gateways = append(gateways, paymentgateway.NewMidtrans(midtransCfg.Snap))
+	}
+
+	if secretKey := os.Getenv("XENDIT_SECRET_KEY"); secretKey != "" {
+		apiURL := os.Getenv("XENDIT_API_URL")
+		if apiURL == "" {
+			apiURL = "https://api.xendit.co"
+		}
+		gateways = append(gateways, paymentgateway.NewXendit(apiURL, secretKey, os.Getenv("XENDIT_CALLBACK_TOKEN")))
+	}
+
+	return paymentgateway.NewRegistry(defaultProvider, gateways...)
+}
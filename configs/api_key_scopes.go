@@ -0,0 +1,38 @@
+package configs
+
+// APIKeyScope is what an organization API key may be used for on the Open API
+type APIKeyScope string
+
+const (
+	APIKeyScopeOrdersRead       APIKeyScope = "orders.read"
+	APIKeyScopeOrdersCreate     APIKeyScope = "orders.create"
+	APIKeyScopeOrdersCancel     APIKeyScope = "orders.cancel"
+	APIKeyScopePaymentsWrite    APIKeyScope = "payments.write"
+	APIKeyScopeAvailabilityRead APIKeyScope = "availability.read"
+	APIKeyScopeReviewsCreate    APIKeyScope = "reviews.create"
+	APIKeyScopeDocumentsPrint   APIKeyScope = "documents.print"
+)
+
+// APIKeyScopeLabel lists every API key scope with its label, in display order
+var APIKeyScopeLabel = []struct {
+	Scope APIKeyScope
+	Label string
+}{
+	{APIKeyScopeOrdersRead, "Lihat pesanan"},
+	{APIKeyScopeOrdersCreate, "Buat pesanan"},
+	{APIKeyScopeOrdersCancel, "Batalkan pesanan"},
+	{APIKeyScopePaymentsWrite, "Konfirmasi & unggah bukti pembayaran"},
+	{APIKeyScopeAvailabilityRead, "Cek armada & ketersediaan"},
+	{APIKeyScopeReviewsCreate, "Kirim ulasan"},
+	{APIKeyScopeDocumentsPrint, "Cetak dokumen pesanan & invoice"},
+}
+
+// IsValid checks if the scope is known
+func (s APIKeyScope) IsValid() bool {
+	for _, it := range APIKeyScopeLabel {
+		if it.Scope == s {
+			return true
+		}
+	}
+	return false
+}
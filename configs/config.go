@@ -15,6 +15,7 @@ type Config struct {
 	JWT      JWTConfig      `json:"jwt"`
 	Email    EmailConfig    `json:"email"`
 	Redis    RedisConfig    `json:"redis"`
+	Security SecurityConfig `json:"security"`
 }
 
 // AppConfig holds application configuration
@@ -62,6 +63,25 @@ type RedisConfig struct {
 	OTPTTL   int    `json:"otp_ttl"` // OTP TTL in minutes, default: 5
 }
 
+// RateLimitRule limits how many requests a client may make per window
+type RateLimitRule struct {
+	Max           int `json:"max"`
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// SecurityConfig holds rate limits and lockout settings
+type SecurityConfig struct {
+	// RateLimits per route group, e.g. "auth" or "otp"
+	RateLimits               map[string]RateLimitRule `json:"rate_limits"`
+	LoginMaxFailures         int                      `json:"login_max_failures"`          // Default: 5
+	LoginLockoutMinutes      int                      `json:"login_lockout_minutes"`       // Default: 15
+	OTPMaxAttempts           int                      `json:"otp_max_attempts"`            // per token, default: 5
+	OTPResendCooldownSeconds int                      `json:"otp_resend_cooldown_seconds"` // Default: 60
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose
+	// X-Forwarded-For is believed. Default: loopback only.
+	TrustedProxies []string `json:"trusted_proxies"`
+}
+
 // LoadConfig loads configuration from JSON file
 func LoadConfig(path string) (*Config, error) {
 	file, err := os.Open(path)
@@ -200,6 +220,49 @@ func OverrideWithEnv(cfg *Config) {
 	if envPort := os.Getenv("EMAIL_SMTP_PORT"); envPort != "" {
 		cfg.Email.SMTPPort = envPort
 	}
+
+	overrideSecurityWithEnv(&cfg.Security)
+}
+
+// overrideSecurityWithEnv reads LOGIN_MAX_FAILURES, LOGIN_LOCKOUT_MINUTES,
+// OTP_MAX_ATTEMPTS, OTP_RESEND_COOLDOWN, TRUSTED_PROXIES (comma separated) and
+// one RATE_LIMIT_<GROUP>=max/seconds per route group (e.g. RATE_LIMIT_AUTH=5/60)
+func overrideSecurityWithEnv(sec *SecurityConfig) {
+	envInt := func(name string, dst *int) {
+		if v, err := strconv.Atoi(os.Getenv(name)); err == nil && v > 0 {
+			*dst = v
+		}
+	}
+	envInt("LOGIN_MAX_FAILURES", &sec.LoginMaxFailures)
+	envInt("LOGIN_LOCKOUT_MINUTES", &sec.LoginLockoutMinutes)
+	envInt("OTP_MAX_ATTEMPTS", &sec.OTPMaxAttempts)
+	envInt("OTP_RESEND_COOLDOWN", &sec.OTPResendCooldownSeconds)
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		sec.TrustedProxies = nil
+		for _, proxy := range strings.Split(v, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				sec.TrustedProxies = append(sec.TrustedProxies, proxy)
+			}
+		}
+	}
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, "RATE_LIMIT_") {
+			continue
+		}
+		group := strings.ToLower(strings.TrimPrefix(name, "RATE_LIMIT_"))
+		maxStr, windowStr, ok := strings.Cut(value, "/")
+		max, err1 := strconv.Atoi(strings.TrimSpace(maxStr))
+		window, err2 := strconv.Atoi(strings.TrimSpace(windowStr))
+		if group == "" || !ok || err1 != nil || err2 != nil || max <= 0 || window <= 0 {
+			continue
+		}
+		if sec.RateLimits == nil {
+			sec.RateLimits = make(map[string]RateLimitRule)
+		}
+		sec.RateLimits[group] = RateLimitRule{Max: max, WindowSeconds: window}
+	}
 }
 
 // ValidateEmailConfig validates that email configuration is properly set
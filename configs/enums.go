@@ -65,6 +65,7 @@ const (
 	UploadTypeContent          UploadType = "content"
 	UploadTypeEmployeePhoto    UploadType = "employee_photo"
 	UploadTypePayment          UploadType = "payment"
+	UploadTypeDocument         UploadType = "document"
 )
 
 // String returns the string representation of UploadType
@@ -75,7 +76,8 @@ func (u UploadType) String() string {
 // IsValid checks if the upload type is valid
 func (u UploadType) IsValid() bool {
 	return u == UploadTypeProfileUser || u == UploadTypeIconCompany || u == UploadTypeContentThumbnail ||
-		u == UploadTypeArmada || u == UploadTypePackage || u == UploadTypeOrder || u == UploadTypeContent || u == UploadTypeEmployeePhoto || u == UploadTypePayment
+		u == UploadTypeArmada || u == UploadTypePackage || u == UploadTypeOrder || u == UploadTypeContent || u == UploadTypeEmployeePhoto || u == UploadTypePayment ||
+		u == UploadTypeDocument
 }
 
 // GetStoragePath returns the storage path for the upload type
@@ -99,6 +101,8 @@ func (u UploadType) GetStoragePath() string {
 		return "/assets/employee"
 	case UploadTypePayment:
 		return "/assets/payment"
+	case UploadTypeDocument:
+		return "/assets/document"
 	default:
 		return ""
 	}
@@ -0,0 +1,87 @@
+package configs
+
+// Permission is a named action a member's organization role allows
+type Permission string
+
+const (
+	PermissionOrdersView       Permission = "orders.view"
+	PermissionOrdersManage     Permission = "orders.manage"
+	PermissionOrdersCancel     Permission = "orders.cancel"
+	PermissionFinanceView      Permission = "finance.view"
+	PermissionFinanceManage    Permission = "finance.manage"
+	PermissionInventoryView    Permission = "inventory.view"
+	PermissionInventoryManage  Permission = "inventory.manage"
+	PermissionInventoryApprove Permission = "inventory.approve"
+	PermissionFleetManage      Permission = "fleet.manage"
+	PermissionTeamManage       Permission = "team.manage"
+	PermissionSettingsManage   Permission = "settings.manage"
+	PermissionAuditView        Permission = "audit.view"
+)
+
+// PermissionLabel lists every permission with its label, in display order
+var PermissionLabel = []struct {
+	Permission Permission
+	Label      string
+}{
+	{PermissionOrdersView, "Lihat pesanan"},
+	{PermissionOrdersManage, "Kelola pesanan"},
+	{PermissionOrdersCancel, "Batalkan pesanan"},
+	{PermissionFinanceView, "Lihat keuangan"},
+	{PermissionFinanceManage, "Kelola keuangan"},
+	{PermissionInventoryView, "Lihat inventaris"},
+	{PermissionInventoryManage, "Kelola inventaris"},
+	{PermissionInventoryApprove, "Setujui permintaan inventaris"},
+	{PermissionFleetManage, "Kelola armada"},
+	{PermissionTeamManage, "Kelola tim & role"},
+	{PermissionSettingsManage, "Kelola pengaturan organisasi"},
+	{PermissionAuditView, "Lihat log audit"},
+}
+
+// DefaultStaffPermissions are granted to staff members without a role. The
+// sensitive permissions (cancelling orders, managing finance, approving
+// inventory requests, team and settings) need an admin or a role granting them.
+var DefaultStaffPermissions = []Permission{
+	PermissionOrdersView,
+	PermissionOrdersManage,
+	PermissionFinanceView,
+	PermissionInventoryView,
+	PermissionInventoryManage,
+	PermissionFleetManage,
+}
+
+// IsValid checks if the permission is known
+func (p Permission) IsValid() bool {
+	for _, it := range PermissionLabel {
+		if it.Permission == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePermissions returns the permissions of an organization member.
+// Admins have every permission; staff have the permissions of their role, or
+// DefaultStaffPermissions when no role is assigned.
+func ResolvePermissions(organizationRole int, hasRole bool, granted []string) []string {
+	var perms []Permission
+	switch {
+	case OrganizationRole(organizationRole) == OrganizationRoleAdmin:
+		for _, it := range PermissionLabel {
+			perms = append(perms, it.Permission)
+		}
+	case hasRole:
+		for _, p := range granted {
+			if Permission(p).IsValid() {
+				perms = append(perms, Permission(p))
+			}
+		}
+	default:
+		perms = DefaultStaffPermissions
+	}
+
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		out = append(out, string(p))
+	}
+	return out
+}
@@ -0,0 +1,263 @@
+package cron
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"service-travego/database"
+	"service-travego/internal/supervisor"
+	"service-travego/internal/wagy"
+	"service-travego/model"
+	"service-travego/repository"
+	"service-travego/service"
+	"strings"
+	"time"
+)
+
+type DocumentExpiryCron struct {
+	db              *sql.DB
+	driver          string
+	wagyClient      *wagy.WagyClient
+	outbox          *service.OutboxService
+	docRepo         *repository.DocumentRepository
+	organizationIDs []string
+}
+
+func NewDocumentExpiryCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient) *DocumentExpiryCron {
+	// Read organization IDs from environment variable
+	var orgIDs []string
+	orgIDsStr := os.Getenv("DOCUMENT_EXPIRY_CRON_ORGANIZATION_IDS")
+	if orgIDsStr != "" {
+		for _, id := range strings.Split(orgIDsStr, ",") {
+			trimmed := strings.TrimSpace(id)
+			if trimmed != "" {
+				orgIDs = append(orgIDs, trimmed)
+			}
+		}
+	}
+
+	return &DocumentExpiryCron{
+		db:              db,
+		driver:          driver,
+		wagyClient:      wagyClient,
+		outbox:          service.NewOutboxService(repository.NewOutboxRepository(db, driver)),
+		docRepo:         repository.NewDocumentRepository(db, driver),
+		organizationIDs: orgIDs,
+	}
+}
+
+// Run runs the job once; the supervisor records its outcome
+func (c *DocumentExpiryCron) Run() error {
+	ctx := context.Background()
+	slog.InfoContext(ctx, "document expiry cron: starting scheduled job")
+
+	if c.wagyClient == nil {
+		slog.InfoContext(ctx, "document expiry cron: wagy client not configured, skipping")
+		return supervisor.ErrSkipped
+	}
+
+	targets, err := c.queryActiveOrganizations(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "document expiry cron: failed to query organizations", "error", err)
+		return err
+	}
+
+	if len(targets) == 0 {
+		slog.InfoContext(ctx, "document expiry cron: no active organizations found")
+		return nil
+	}
+
+	slog.InfoContext(ctx, "document expiry cron: found active organizations", "count", len(targets))
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, org := range targets {
+		c.processOrganization(ctx, org, today)
+	}
+
+	slog.InfoContext(ctx, "document expiry cron: job completed")
+	return nil
+}
+
+func (c *DocumentExpiryCron) queryActiveOrganizations(ctx context.Context) ([]orgTarget, error) {
+	query := `
+		SELECT ac.organization_id, ac.account_number, o.organization_name
+		FROM assistant_accounts ac
+		INNER JOIN organizations o ON ac.organization_id = o.organization_id
+		INNER JOIN _subscription s ON s.organization_id = ac.organization_id
+		WHERE s.expiry_date >= CURRENT_DATE
+	`
+
+	var args []interface{}
+
+	// Add organization ID filter if specified
+	if len(c.organizationIDs) > 0 {
+		placeholders := make([]string, len(c.organizationIDs))
+		for i := range c.organizationIDs {
+			if c.driver == "postgres" || c.driver == "pgx" {
+				placeholders[i] = fmt.Sprintf("$%d", i+1)
+			} else {
+				placeholders[i] = "?"
+			}
+			args = append(args, c.organizationIDs[i])
+		}
+		query += fmt.Sprintf(" AND ac.organization_id IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	query += " GROUP BY ac.organization_id, ac.account_number, o.organization_name"
+
+	rows, err := database.QueryContext(ctx, c.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []orgTarget
+	for rows.Next() {
+		var t orgTarget
+		if err := rows.Scan(&t.OrganizationID, &t.AccountNumber, &t.OrganizationName); err != nil {
+			slog.ErrorContext(ctx, "document expiry cron: query active organizations failed", "error", err)
+			continue
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// reminderStage returns the reminder stage a document falls into, i.e. the
+// smallest configured day count that is still >= daysLeft. It returns 0 when the
+// document is not yet inside any reminder window.
+func reminderStage(daysLeft int) int {
+	stage := 0
+	for _, d := range model.DocumentReminderDays {
+		if daysLeft <= d && (stage == 0 || d < stage) {
+			stage = d
+		}
+	}
+	return stage
+}
+
+type dueDocument struct {
+	model.DocumentReminderRow
+	DaysLeft int
+	Stage    int
+}
+
+func (c *DocumentExpiryCron) processOrganization(ctx context.Context, org orgTarget, today time.Time) {
+	ctx = database.WithOrganization(ctx, org.OrganizationID)
+	slog.InfoContext(ctx, "document expiry cron: process organization", "organization_id", org.OrganizationID, "organization_name", org.OrganizationName)
+
+	maxDays := 0
+	for _, d := range model.DocumentReminderDays {
+		if d > maxDays {
+			maxDays = d
+		}
+	}
+
+	rows, err := c.docRepo.ListReminderCandidates(ctx, today, today.AddDate(0, 0, maxDays))
+	if err != nil {
+		slog.ErrorContext(ctx, "document expiry cron: query documents failed", "organization_id", org.OrganizationID, "error", err)
+		return
+	}
+
+	var due []dueDocument
+	for _, row := range rows {
+		expiry := time.Date(row.ExpiryDate.Year(), row.ExpiryDate.Month(), row.ExpiryDate.Day(), 0, 0, 0, 0, today.Location())
+		daysLeft := int(expiry.Sub(today).Hours() / 24)
+		stage := reminderStage(daysLeft)
+		if stage == 0 {
+			continue
+		}
+		// Skip when this stage (or a later, smaller one) was already announced
+		if row.DaysBefore != 0 && row.DaysBefore <= stage {
+			continue
+		}
+		due = append(due, dueDocument{DocumentReminderRow: row, DaysLeft: daysLeft, Stage: stage})
+	}
+
+	if len(due) == 0 {
+		slog.InfoContext(ctx, "document expiry cron: no expiring documents", "organization_id", org.OrganizationID)
+		return
+	}
+
+	message := c.formatMessage(org.OrganizationName, due)
+	if err := c.outbox.EnqueueWhatsApp(ctx, org.OrganizationID, service.OutboxKindDocumentExpiry, org.AccountNumber, message); err != nil {
+		slog.ErrorContext(ctx, "document expiry cron: failed to queue message", "account_number", org.AccountNumber, "error", err)
+		return
+	}
+
+	// Crew members also get a personal reminder for their own documents
+	for _, d := range due {
+		if d.OwnerType != model.DocumentOwnerEmployee || strings.TrimSpace(d.OwnerPhone) == "" {
+			continue
+		}
+		if err := c.outbox.EnqueueWhatsApp(ctx, org.OrganizationID, service.OutboxKindDocumentExpiry, d.OwnerPhone, c.formatCrewMessage(org.OrganizationName, d)); err != nil {
+			slog.ErrorContext(ctx, "document expiry cron: failed to queue message to crew", "owner_phone", d.OwnerPhone, "error", err)
+		}
+	}
+
+	sentAt := time.Now()
+	for _, d := range due {
+		if err := c.docRepo.InsertReminder(ctx, d.DocumentID, d.Stage, sentAt); err != nil {
+			slog.ErrorContext(ctx, "document expiry cron: failed to record reminder", "document_id", d.DocumentID, "error", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "document expiry cron: message queued", "account_number", org.AccountNumber, "organization_name", org.OrganizationName, "documents", len(due))
+}
+
+func documentLabel(documentType string) string {
+	if label, ok := model.DocumentTypeLabel[documentType]; ok {
+		return label
+	}
+	return documentType
+}
+
+func (c *DocumentExpiryCron) formatMessage(orgName string, docs []dueDocument) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("Selamat Pagi %s\n\n", orgName))
+	b.WriteString("Berikut adalah daftar dokumen yang akan segera habis masa berlakunya:\n\n")
+
+	for i, d := range docs {
+		b.WriteString(fmt.Sprintf("%d. %s - %s\n", i+1, documentLabel(d.DocumentType), d.OwnerName))
+		if d.DocumentNumber != "" {
+			b.WriteString(fmt.Sprintf("   Nomor: %s\n", d.DocumentNumber))
+		}
+		b.WriteString(fmt.Sprintf("   Berlaku s/d: %s (%d hari lagi)\n", d.ExpiryDate.Format("02-01-2006"), d.DaysLeft))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Mohon segera lakukan perpanjangan. Terima kasih.\n")
+
+	return b.String()
+}
+
+func (c *DocumentExpiryCron) formatCrewMessage(orgName string, d dueDocument) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("Halo %s\n\n", d.OwnerName))
+	b.WriteString(fmt.Sprintf("Dokumen %s Anda akan habis masa berlakunya pada %s (%d hari lagi).\n", documentLabel(d.DocumentType), d.ExpiryDate.Format("02-01-2006"), d.DaysLeft))
+	b.WriteString(fmt.Sprintf("Mohon segera lakukan perpanjangan dan kirimkan salinannya ke %s.\n\n", orgName))
+	b.WriteString("Terima kasih.\n")
+
+	return b.String()
+}
+
+// StartDocumentExpiryCron registers the job with the background job supervisor
+func StartDocumentExpiryCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient) {
+	cronJob := NewDocumentExpiryCron(db, driver, wagyClient)
+
+	// Schedule: every day at 08:00
+	if err := supervisor.AddCron("document_expiry", "0 8 * * *", cronJob.Run); err != nil {
+		slog.Error("document expiry cron: failed to register cron", "error", err)
+		return
+	}
+	slog.Info("document expiry cron: scheduled", "schedule", "every day at 08:00")
+}
@@ -1,23 +1,25 @@
 package cron
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"service-travego/database"
+	"service-travego/internal/supervisor"
 	"service-travego/internal/wagy"
 	"service-travego/repository"
 	"service-travego/service"
 	"strings"
 	"time"
-
-	"github.com/robfig/cron/v3"
 )
 
 type FleetAvailabilityCron struct {
 	db              *sql.DB
 	driver          string
 	wagyClient      *wagy.WagyClient
+	outbox          *service.OutboxService
 	fleetSvc        *service.FleetService
 	organizationIDs []string
 }
@@ -43,6 +45,7 @@ func NewFleetAvailabilityCron(db *sql.DB, driver string, wagyClient *wagy.WagyCl
 		db:              db,
 		driver:          driver,
 		wagyClient:      wagyClient,
+		outbox:          service.NewOutboxService(repository.NewOutboxRepository(db, driver)),
 		fleetSvc:        fleetSvc,
 		organizationIDs: orgIDs,
 	}
@@ -54,40 +57,43 @@ type orgTarget struct {
 	OrganizationName string
 }
 
-func (c *FleetAvailabilityCron) Run() {
-	log.Println("[FleetAvailabilityCron] Starting scheduled job...")
+// Run runs the job once; the supervisor records its outcome
+func (c *FleetAvailabilityCron) Run() error {
+	ctx := context.Background()
+	slog.InfoContext(ctx, "fleet availability cron: starting scheduled job")
 
 	if c.wagyClient == nil {
-		log.Println("[FleetAvailabilityCron] Wagy client not configured, skipping")
-		return
+		slog.InfoContext(ctx, "fleet availability cron: wagy client not configured, skipping")
+		return supervisor.ErrSkipped
 	}
 
 	// 1. Query active organizations with assistant accounts
-	targets, err := c.queryActiveOrganizations()
+	targets, err := c.queryActiveOrganizations(ctx)
 	if err != nil {
-		log.Printf("[FleetAvailabilityCron] Failed to query organizations: %v", err)
-		return
+		slog.ErrorContext(ctx, "fleet availability cron: failed to query organizations", "error", err)
+		return err
 	}
 
 	if len(targets) == 0 {
-		log.Println("[FleetAvailabilityCron] No active organizations found")
-		return
+		slog.InfoContext(ctx, "fleet availability cron: no active organizations found")
+		return nil
 	}
 
-	log.Printf("[FleetAvailabilityCron] Found %d active organizations", len(targets))
+	slog.InfoContext(ctx, "fleet availability cron: found active organizations", "count", len(targets))
 
 	// Date range: today to 7 days from now
 	today := time.Now().Format("2006-01-02")
 	nextWeek := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
 
 	for _, org := range targets {
-		c.processOrganization(org, today, nextWeek)
+		c.processOrganization(ctx, org, today, nextWeek)
 	}
 
-	log.Println("[FleetAvailabilityCron] Job completed")
+	slog.InfoContext(ctx, "fleet availability cron: job completed")
+	return nil
 }
 
-func (c *FleetAvailabilityCron) queryActiveOrganizations() ([]orgTarget, error) {
+func (c *FleetAvailabilityCron) queryActiveOrganizations(ctx context.Context) ([]orgTarget, error) {
 	query := `
 		SELECT ac.organization_id, ac.account_number, o.organization_name
 		FROM assistant_accounts ac
@@ -114,7 +120,7 @@ func (c *FleetAvailabilityCron) queryActiveOrganizations() ([]orgTarget, error)
 
 	query += " GROUP BY ac.organization_id, ac.account_number, o.organization_name"
 
-	rows, err := c.db.Query(query, args...)
+	rows, err := database.QueryContext(ctx, c.db, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query organizations: %w", err)
 	}
@@ -124,7 +130,7 @@ func (c *FleetAvailabilityCron) queryActiveOrganizations() ([]orgTarget, error)
 	for rows.Next() {
 		var t orgTarget
 		if err := rows.Scan(&t.OrganizationID, &t.AccountNumber, &t.OrganizationName); err != nil {
-			log.Printf("[FleetAvailabilityCron] Scan row error: %v", err)
+			slog.ErrorContext(ctx, "fleet availability cron: query active organizations failed", "error", err)
 			continue
 		}
 		targets = append(targets, t)
@@ -136,41 +142,39 @@ func (c *FleetAvailabilityCron) queryActiveOrganizations() ([]orgTarget, error)
 	return targets, nil
 }
 
-func (c *FleetAvailabilityCron) processOrganization(org orgTarget, startDate, endDate string) {
-	log.Printf("[FleetAvailabilityCron] Processing org: %s (%s)", org.OrganizationName, org.OrganizationID)
+func (c *FleetAvailabilityCron) processOrganization(ctx context.Context, org orgTarget, startDate, endDate string) {
+	ctx = database.WithOrganization(ctx, org.OrganizationID)
+	slog.InfoContext(ctx, "fleet availability cron: process organization", "organization_id", org.OrganizationID, "organization_name", org.OrganizationName)
 
 	// Parse dates
 	start, err := time.Parse("2006-01-02", startDate)
 	if err != nil {
-		log.Printf("[FleetAvailabilityCron] Invalid startDate %s for org %s: %v", startDate, org.OrganizationID, err)
+		slog.ErrorContext(ctx, "fleet availability cron: invalid start date", "start_date", startDate, "organization_id", org.OrganizationID, "error", err)
 		return
 	}
 	end, err := time.Parse("2006-01-02", endDate)
 	if err != nil {
-		log.Printf("[FleetAvailabilityCron] Invalid endDate %s for org %s: %v", endDate, org.OrganizationID, err)
+		slog.ErrorContext(ctx, "fleet availability cron: invalid end date", "end_date", endDate, "organization_id", org.OrganizationID, "error", err)
 		return
 	}
 
 	// 2. Get fleet availability via FleetService
-	_, items, err := c.fleetSvc.GetFleetAvailibility(org.OrganizationID, start, end, "")
+	_, items, err := c.fleetSvc.GetFleetAvailibility(ctx, start, end, "")
 	if err != nil {
-		log.Printf("[FleetAvailabilityCron] GetFleetAvailibility error for org %s: %v", org.OrganizationID, err)
+		slog.ErrorContext(ctx, "fleet availability cron: get fleet availability failed", "organization_id", org.OrganizationID, "error", err)
 		return
 	}
 
 	// 3. Format message
 	message := c.formatMessage(org.OrganizationName, items)
 
-	// 4. Send via Wagy
-	_, err = c.wagyClient.SendMessage(org.AccountNumber, message)
-	if err != nil {
-		log.Printf("[FleetAvailabilityCron] SendMessage error to %s: %v", org.AccountNumber, err)
-		insertAssistantAccountStat(c.db, c.driver, org.OrganizationID, 2)
+	// 4. Queue for Wagy
+	if err := c.outbox.EnqueueWhatsApp(ctx, org.OrganizationID, service.OutboxKindFleetAvailability, org.AccountNumber, message); err != nil {
+		slog.ErrorContext(ctx, "fleet availability cron: failed to queue message", "account_number", org.AccountNumber, "error", err)
 		return
 	}
 
-	insertAssistantAccountStat(c.db, c.driver, org.OrganizationID, 1)
-	log.Printf("[FleetAvailabilityCron] Message sent to %s (%s)", org.AccountNumber, org.OrganizationName)
+	slog.InfoContext(ctx, "fleet availability cron: message queued", "account_number", org.AccountNumber, "organization_name", org.OrganizationName)
 }
 
 func (c *FleetAvailabilityCron) formatMessage(orgName string, items []repository.FleetAvailibilityItem) string {
@@ -198,21 +202,14 @@ func (c *FleetAvailabilityCron) formatMessage(orgName string, items []repository
 	return b.String()
 }
 
-// Start registers the cron job and starts the scheduler
-func StartFleetAvailabilityCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient) *cron.Cron {
-	c := cron.New(cron.WithLocation(time.Local))
-
+// StartFleetAvailabilityCron registers the job with the background job supervisor
+func StartFleetAvailabilityCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient) {
 	cronJob := NewFleetAvailabilityCron(db, driver, wagyClient)
 
 	// Schedule: Monday, Wednesday, Friday at 09:00
-	_, err := c.AddFunc("0 09 * * 1,3,5", cronJob.Run)
-	if err != nil {
-		log.Printf("[FleetAvailabilityCron] Failed to register cron: %v", err)
-		return nil
+	if err := supervisor.AddCron("fleet_availability", "0 09 * * 1,3,5", cronJob.Run); err != nil {
+		slog.Error("fleet availability cron: failed to register cron", "error", err)
+		return
 	}
-
-	c.Start()
-	log.Println("[FleetAvailabilityCron] Scheduled: Mon, Wed, Fri at 09:00")
-
-	return c
+	slog.Info("fleet availability cron: scheduled", "schedule", "mon, wed, fri at 09:00")
 }
@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"service-travego/config"
+	"service-travego/internal/supervisor"
+	"service-travego/repository"
+	"service-travego/service"
+)
+
+// PaymentReconcileCron catches up on Midtrans webhooks that never arrived and
+// pays recorded order refunds back through Midtrans.
+type PaymentReconcileCron struct {
+	paymentService service.PaymentService
+}
+
+func NewPaymentReconcileCron(db *sql.DB, driver string, midtransCfg *config.MidtransConfig) *PaymentReconcileCron {
+	return &PaymentReconcileCron{
+		paymentService: service.NewPaymentService(
+			repository.NewPaymentRepository(db, driver),
+			repository.NewOrganizationRepository(db, driver),
+			midtransCfg,
+			nil,
+			service.NewOutboxService(repository.NewOutboxRepository(db, driver)),
+		),
+	}
+}
+
+// Run runs the job once; the supervisor records its outcome
+func (c *PaymentReconcileCron) Run() error {
+	ctx := context.Background()
+	slog.InfoContext(ctx, "payment reconcile cron: starting scheduled job")
+
+	// refunds are still issued when reconciling fails; the run fails if either did
+	reconcileErr := c.paymentService.ReconcileMidtransPayments()
+	if reconcileErr != nil {
+		slog.ErrorContext(ctx, "payment reconcile cron: failed to reconcile payments", "error", reconcileErr)
+	}
+	refundErr := c.paymentService.IssueMidtransRefunds()
+	if refundErr != nil {
+		slog.ErrorContext(ctx, "payment reconcile cron: failed to issue refunds", "error", refundErr)
+	}
+
+	slog.InfoContext(ctx, "payment reconcile cron: job completed")
+	return errors.Join(reconcileErr, refundErr)
+}
+
+// StartPaymentReconcileCron registers the job with the background job supervisor
+func StartPaymentReconcileCron(db *sql.DB, driver string, midtransCfg *config.MidtransConfig) {
+	cronJob := NewPaymentReconcileCron(db, driver, midtransCfg)
+
+	// Schedule: every 30 minutes
+	if err := supervisor.AddCron("payment_reconcile", "*/30 * * * *", cronJob.Run); err != nil {
+		slog.Error("payment reconcile cron: failed to register cron", "error", err)
+		return
+	}
+	slog.Info("payment reconcile cron: scheduled", "schedule", "every 30 minutes")
+}
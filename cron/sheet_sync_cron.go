@@ -0,0 +1,51 @@
+package cron
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"service-travego/internal/sheetsapi"
+	"service-travego/internal/supervisor"
+	"service-travego/repository"
+	"service-travego/service"
+)
+
+// SheetSyncCron pushes the new orders, payments and expenses of every
+// organization with Google Sheets sync enabled
+type SheetSyncCron struct {
+	sheetSyncService *service.SheetSyncService
+}
+
+func NewSheetSyncCron(db *sql.DB, driver string, client *sheetsapi.Client) *SheetSyncCron {
+	return &SheetSyncCron{
+		sheetSyncService: service.NewSheetSyncService(repository.NewSheetSyncRepository(db, driver), client),
+	}
+}
+
+// Run runs the job once; the supervisor records its outcome
+func (c *SheetSyncCron) Run() error {
+	ctx := context.Background()
+	slog.InfoContext(ctx, "sheet sync cron: starting scheduled job")
+	if !c.sheetSyncService.Enabled() {
+		slog.InfoContext(ctx, "sheet sync cron: Google Sheets not configured, skipping")
+		return supervisor.ErrSkipped
+	}
+	if err := c.sheetSyncService.RunSync(ctx); err != nil {
+		slog.ErrorContext(ctx, "sheet sync cron: run failed", "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "sheet sync cron: job completed")
+	return nil
+}
+
+// StartSheetSyncCron registers the job with the background job supervisor
+func StartSheetSyncCron(db *sql.DB, driver string, client *sheetsapi.Client) {
+	cronJob := NewSheetSyncCron(db, driver, client)
+
+	// Schedule: every 15 minutes
+	if err := supervisor.AddCron("sheet_sync", "*/15 * * * *", cronJob.Run); err != nil {
+		slog.Error("sheet sync cron: failed to register cron", "error", err)
+		return
+	}
+	slog.Info("sheet sync cron: scheduled", "schedule", "every 15 minutes")
+}
@@ -0,0 +1,48 @@
+package cron
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"service-travego/internal/supervisor"
+	"service-travego/repository"
+	"service-travego/service"
+	"time"
+)
+
+// SubscriptionLifecycleCron sends renewal reminders, starts paid downgrades,
+// and moves lapsed subscriptions into the grace period and then read-only mode
+type SubscriptionLifecycleCron struct {
+	subscriptionService *service.SubscriptionService
+}
+
+func NewSubscriptionLifecycleCron(db *sql.DB, driver string) *SubscriptionLifecycleCron {
+	subscriptionService := service.NewSubscriptionService(repository.NewSubscriptionRepository(db, driver))
+	subscriptionService.SetOrganizationRepository(repository.NewOrganizationRepository(db, driver))
+	subscriptionService.SetOutboxService(service.NewOutboxService(repository.NewOutboxRepository(db, driver)))
+	return &SubscriptionLifecycleCron{subscriptionService: subscriptionService}
+}
+
+// Run runs the job once; the supervisor records its outcome
+func (c *SubscriptionLifecycleCron) Run() error {
+	ctx := context.Background()
+	slog.InfoContext(ctx, "subscription lifecycle cron: starting scheduled job")
+	if err := c.subscriptionService.RunLifecycle(time.Now()); err != nil {
+		slog.ErrorContext(ctx, "subscription lifecycle cron: run failed", "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "subscription lifecycle cron: job completed")
+	return nil
+}
+
+// StartSubscriptionLifecycleCron registers the job with the background job supervisor
+func StartSubscriptionLifecycleCron(db *sql.DB, driver string) {
+	cronJob := NewSubscriptionLifecycleCron(db, driver)
+
+	// Schedule: every day at 00:30
+	if err := supervisor.AddCron("subscription_lifecycle", "30 0 * * *", cronJob.Run); err != nil {
+		slog.Error("subscription lifecycle cron: failed to register cron", "error", err)
+		return
+	}
+	slog.Info("subscription lifecycle cron: scheduled", "schedule", "every day at 00:30")
+}
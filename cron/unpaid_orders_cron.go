@@ -1,17 +1,21 @@
 package cron
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"service-travego/database"
+	"service-travego/helper"
+	"service-travego/internal/supervisor"
 	"service-travego/internal/wagy"
 	"service-travego/model"
+	"service-travego/repository"
+	"service-travego/service"
 	"strings"
 	"time"
-
-	"github.com/robfig/cron/v3"
 )
 
 type unpaidOrderRow struct {
@@ -23,12 +27,18 @@ type unpaidOrderRow struct {
 	CityID         sql.NullString
 	CustomerName   sql.NullString
 	CustomerPhone  sql.NullString
+
+	// Set for orders with a payment plan: the installment that is due
+	InstallmentLabel  sql.NullString
+	InstallmentAmount sql.NullFloat64
+	InstallmentDue    sql.NullTime
 }
 
 type UnpaidOrdersCron struct {
 	db              *sql.DB
 	driver          string
 	wagyClient      *wagy.WagyClient
+	outbox          *service.OutboxService
 	citiesName      map[string]string
 	organizationIDs []string
 }
@@ -51,6 +61,7 @@ func NewUnpaidOrdersCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient)
 		db:              db,
 		driver:          driver,
 		wagyClient:      wagyClient,
+		outbox:          service.NewOutboxService(repository.NewOutboxRepository(db, driver)),
 		organizationIDs: orgIDs,
 	}
 }
@@ -77,39 +88,42 @@ func (c *UnpaidOrdersCron) ensureLocationLoaded() {
 	c.citiesName = m
 }
 
-func (c *UnpaidOrdersCron) Run() {
-	log.Println("[UnpaidOrdersCron] Starting scheduled job...")
+// Run runs the job once; the supervisor records its outcome
+func (c *UnpaidOrdersCron) Run() error {
+	ctx := context.Background()
+	slog.InfoContext(ctx, "unpaid orders cron: starting scheduled job")
 
 	if c.wagyClient == nil {
-		log.Println("[UnpaidOrdersCron] Wagy client not configured, skipping")
-		return
+		slog.InfoContext(ctx, "unpaid orders cron: wagy client not configured, skipping")
+		return supervisor.ErrSkipped
 	}
 
 	c.ensureLocationLoaded()
 
-	targets, err := c.queryActiveOrganizations()
+	targets, err := c.queryActiveOrganizations(ctx)
 	if err != nil {
-		log.Printf("[UnpaidOrdersCron] Failed to query organizations: %v", err)
-		return
+		slog.ErrorContext(ctx, "unpaid orders cron: failed to query organizations", "error", err)
+		return err
 	}
 
 	if len(targets) == 0 {
-		log.Println("[UnpaidOrdersCron] No active organizations found")
-		return
+		slog.InfoContext(ctx, "unpaid orders cron: no active organizations found")
+		return nil
 	}
 
-	log.Printf("[UnpaidOrdersCron] Found %d active organizations", len(targets))
+	slog.InfoContext(ctx, "unpaid orders cron: found active organizations", "count", len(targets))
 
 	nextWeek := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
 
 	for _, org := range targets {
-		c.processOrganization(org, nextWeek)
+		c.processOrganization(ctx, org, nextWeek)
 	}
 
-	log.Println("[UnpaidOrdersCron] Job completed")
+	slog.InfoContext(ctx, "unpaid orders cron: job completed")
+	return nil
 }
 
-func (c *UnpaidOrdersCron) queryActiveOrganizations() ([]orgTarget, error) {
+func (c *UnpaidOrdersCron) queryActiveOrganizations(ctx context.Context) ([]orgTarget, error) {
 	query := `
 		SELECT ac.organization_id, ac.account_number, o.organization_name
 		FROM assistant_accounts ac
@@ -136,7 +150,7 @@ func (c *UnpaidOrdersCron) queryActiveOrganizations() ([]orgTarget, error) {
 
 	query += " GROUP BY ac.organization_id, ac.account_number, o.organization_name"
 
-	rows, err := c.db.Query(query, args...)
+	rows, err := database.QueryContext(ctx, c.db, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query organizations: %w", err)
 	}
@@ -146,7 +160,7 @@ func (c *UnpaidOrdersCron) queryActiveOrganizations() ([]orgTarget, error) {
 	for rows.Next() {
 		var t orgTarget
 		if err := rows.Scan(&t.OrganizationID, &t.AccountNumber, &t.OrganizationName); err != nil {
-			log.Printf("[UnpaidOrdersCron] Scan row error: %v", err)
+			slog.ErrorContext(ctx, "unpaid orders cron: query active organizations failed", "error", err)
 			continue
 		}
 		targets = append(targets, t)
@@ -158,48 +172,119 @@ func (c *UnpaidOrdersCron) queryActiveOrganizations() ([]orgTarget, error) {
 	return targets, nil
 }
 
-func (c *UnpaidOrdersCron) processOrganization(org orgTarget, nextWeek string) {
-	log.Printf("[UnpaidOrdersCron] Processing org: %s (%s)", org.OrganizationName, org.OrganizationID)
+func (c *UnpaidOrdersCron) processOrganization(ctx context.Context, org orgTarget, nextWeek string) {
+	ctx = database.WithOrganization(ctx, org.OrganizationID)
+	slog.InfoContext(ctx, "unpaid orders cron: process organization", "organization_id", org.OrganizationID, "organization_name", org.OrganizationName)
+
+	orders, err := c.queryDueInstallments(ctx, nextWeek)
+	if err != nil {
+		slog.ErrorContext(ctx, "unpaid orders cron: query due installments failed", "organization_id", org.OrganizationID, "error", err)
+		return
+	}
 
-	orders, err := c.queryUnpaidOrders(org.OrganizationID, nextWeek)
+	legacyOrders, err := c.queryUnpaidOrders(ctx, nextWeek)
 	if err != nil {
-		log.Printf("[UnpaidOrdersCron] Query unpaid orders error for org %s: %v", org.OrganizationID, err)
+		slog.ErrorContext(ctx, "unpaid orders cron: query unpaid orders failed", "organization_id", org.OrganizationID, "error", err)
 		return
 	}
+	orders = append(orders, legacyOrders...)
 
 	if len(orders) == 0 {
-		log.Printf("[UnpaidOrdersCron] No unpaid orders for org %s", org.OrganizationID)
+		slog.InfoContext(ctx, "unpaid orders cron: no unpaid orders", "organization_id", org.OrganizationID)
 		return
 	}
 
 	message := c.formatMessage(org.OrganizationName, orders)
 
-	_, err = c.wagyClient.SendMessage(org.AccountNumber, message)
-	if err != nil {
-		log.Printf("[UnpaidOrdersCron] SendMessage error to %s: %v", org.AccountNumber, err)
-		insertAssistantAccountStat(c.db, c.driver, org.OrganizationID, 2)
+	if err := c.outbox.EnqueueWhatsApp(ctx, org.OrganizationID, service.OutboxKindUnpaidOrders, org.AccountNumber, message); err != nil {
+		slog.ErrorContext(ctx, "unpaid orders cron: failed to queue message", "account_number", org.AccountNumber, "error", err)
 		return
 	}
 
-	insertAssistantAccountStat(c.db, c.driver, org.OrganizationID, 1)
-	log.Printf("[UnpaidOrdersCron] Message sent to %s (%s) — %d unpaid orders", org.AccountNumber, org.OrganizationName, len(orders))
+	slog.InfoContext(ctx, "unpaid orders cron: message queued", "account_number", org.AccountNumber, "organization_name", org.OrganizationName, "orders", len(orders))
 }
 
-func (c *UnpaidOrdersCron) queryUnpaidOrders(organizationID string, nextWeek string) ([]unpaidOrderRow, error) {
+// queryDueInstallments returns the unpaid installments of upcoming orders of
+// the organization of ctx that are due within the next week or already overdue.
+func (c *UnpaidOrdersCron) queryDueInstallments(ctx context.Context, nextWeek string) ([]unpaidOrderRow, error) {
+	t, err := database.ForTenant(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT fo.order_id, fo.pickup_location, fo.unit_qty, fo.payment_status,
+		       fo.pickup_city_id, foi.city_id, c.customer_name, c.customer_phone,
+		       oi.label, oi.amount, oi.due_date
+		FROM order_installments oi
+		INNER JOIN fleet_orders fo ON fo.order_id = oi.order_id AND fo.organization_id = oi.organization_id
+		INNER JOIN customer_orders co ON fo.order_id = co.order_id AND co.organization_id = fo.organization_id
+		INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = co.organization_id
+		INNER JOIN fleet_order_itinerary foi ON foi.order_id = fo.order_id AND foi.organization_id = fo.organization_id
+		WHERE oi.status IN (1, 2)
+		  AND oi.due_date <= $1
+		  AND fo.start_date >= CURRENT_DATE
+		  AND oi.organization_id = $2
+		ORDER BY oi.due_date ASC, fo.order_id ASC, oi.seq ASC
+	`
+
+	rows, err := t.Query(query, nextWeek, t.OrganizationID())
+	if err != nil {
+		return nil, fmt.Errorf("query due installments: %w", err)
+	}
+	defer rows.Close()
+
+	var out []unpaidOrderRow
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var t unpaidOrderRow
+		if err := rows.Scan(
+			&t.OrderID, &t.PickupLocation, &t.UnitQty, &t.PaymentStatus,
+			&t.PickupCityID, &t.CityID, &t.CustomerName, &t.CustomerPhone,
+			&t.InstallmentLabel, &t.InstallmentAmount, &t.InstallmentDue,
+		); err != nil {
+			slog.ErrorContext(ctx, "unpaid orders cron: query due installments failed", "error", err)
+			continue
+		}
+		// One line per installment, not per itinerary day
+		key := t.OrderID.String + "|" + t.InstallmentLabel.String + "|" + t.InstallmentDue.Time.Format("2006-01-02")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// queryUnpaidOrders returns unpaid orders of the organization of ctx without a
+// payment plan that start within the next week.
+func (c *UnpaidOrdersCron) queryUnpaidOrders(ctx context.Context, nextWeek string) ([]unpaidOrderRow, error) {
+	t, err := database.ForTenant(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
 	query := `
 		SELECT fo.order_id, fo.pickup_location, fo.unit_qty, fo.payment_status,
 		       fo.pickup_city_id, foi.city_id, c.customer_name, c.customer_phone
 		FROM fleet_orders fo
-		INNER JOIN customer_orders co ON fo.order_id = co.order_id
-		INNER JOIN customers c ON c.customer_id = co.customer_id
-		INNER JOIN fleet_order_itinerary foi ON foi.order_id = fo.order_id
+		INNER JOIN customer_orders co ON fo.order_id = co.order_id AND co.organization_id = fo.organization_id
+		INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = co.organization_id
+		INNER JOIN fleet_order_itinerary foi ON foi.order_id = fo.order_id AND foi.organization_id = fo.organization_id
 		WHERE fo.payment_status > 1
 		  AND fo.start_date <= $1
 		  AND fo.start_date >= CURRENT_DATE
 		  AND fo.organization_id = $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM order_installments oi
+		      WHERE oi.order_id = fo.order_id AND oi.organization_id = fo.organization_id AND oi.status > 0
+		  )
 	`
 
-	rows, err := c.db.Query(query, nextWeek, organizationID)
+	rows, err := t.Query(query, nextWeek, t.OrganizationID())
 	if err != nil {
 		return nil, fmt.Errorf("query unpaid orders: %w", err)
 	}
@@ -212,7 +297,7 @@ func (c *UnpaidOrdersCron) queryUnpaidOrders(organizationID string, nextWeek str
 			&t.OrderID, &t.PickupLocation, &t.UnitQty, &t.PaymentStatus,
 			&t.PickupCityID, &t.CityID, &t.CustomerName, &t.CustomerPhone,
 		); err != nil {
-			log.Printf("[UnpaidOrdersCron] Scan row error: %v", err)
+			slog.ErrorContext(ctx, "unpaid orders cron: query unpaid orders failed", "error", err)
 			continue
 		}
 		out = append(out, t)
@@ -239,6 +324,10 @@ func (c *UnpaidOrdersCron) formatMessage(orgName string, orders []unpaidOrderRow
 		b.WriteString(fmt.Sprintf("   No. Telepon: %s\n", o.CustomerPhone.String))
 		b.WriteString(fmt.Sprintf("   Pickup City: %s\n", pickupCity))
 		b.WriteString(fmt.Sprintf("   Destination City: %s\n", destCity))
+		if o.InstallmentLabel.Valid {
+			b.WriteString(fmt.Sprintf("   Termin: %s - Rp %s\n", o.InstallmentLabel.String, helper.FormatRupiah(o.InstallmentAmount.Float64)))
+			b.WriteString(fmt.Sprintf("   Jatuh Tempo: %s\n", o.InstallmentDue.Time.Format("02-01-2006")))
+		}
 		b.WriteString("\n")
 	}
 
@@ -257,21 +346,14 @@ func (c *UnpaidOrdersCron) lookupCity(cityID string) string {
 	return cityID
 }
 
-// Start registers the cron job and starts the scheduler
-func StartUnpaidOrdersCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient) *cron.Cron {
-	c := cron.New(cron.WithLocation(time.Local))
-
+// StartUnpaidOrdersCron registers the job with the background job supervisor
+func StartUnpaidOrdersCron(db *sql.DB, driver string, wagyClient *wagy.WagyClient) {
 	cronJob := NewUnpaidOrdersCron(db, driver, wagyClient)
 
 	// Schedule: every day at 07:00
-	_, err := c.AddFunc("0 7 * * *", cronJob.Run)
-	if err != nil {
-		log.Printf("[UnpaidOrdersCron] Failed to register cron: %v", err)
-		return nil
+	if err := supervisor.AddCron("unpaid_orders", "0 7 * * *", cronJob.Run); err != nil {
+		slog.Error("unpaid orders cron: failed to register cron", "error", err)
+		return
 	}
-
-	c.Start()
-	log.Println("[UnpaidOrdersCron] Scheduled: Every day at 07:00")
-
-	return c
+	slog.Info("unpaid orders cron: scheduled", "schedule", "every day at 07:00")
 }
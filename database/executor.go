@@ -5,59 +5,52 @@ import (
 	"database/sql"
 )
 
-// Query executes a query that returns rows, typically a SELECT.
-func Query(db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
-	return db.Query(query, args...)
-}
-
-// QueryRow executes a query that is expected to return at most one row.
-func QueryRow(db *sql.DB, query string, args ...interface{}) *sql.Row {
-	return db.QueryRow(query, args...)
-}
-
-// Exec executes a query without returning any rows.
-func Exec(db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
-	return db.Exec(query, args...)
-}
-
 // QueryContext executes a query that returns rows with context.
 func QueryContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*sql.Rows, error) {
-	return db.QueryContext(ctx, query, args...)
+	done := track(ctx, query)
+	rows, err := db.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
 }
 
 // QueryRowContext executes a query that is expected to return at most one row with context.
 func QueryRowContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) *sql.Row {
-	return db.QueryRowContext(ctx, query, args...)
+	done := track(ctx, query)
+	row := db.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
 }
 
 // ExecContext executes a query without returning any rows with context.
 func ExecContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
-	return db.ExecContext(ctx, query, args...)
+	done := track(ctx, query)
+	result, err := db.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
 }
 
 // --- Transaction Support ---
 
-// TxQuery executes a query within a transaction.
-func TxQuery(tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
-	return tx.Query(query, args...)
-}
-
-// TxQueryRow executes a query within a transaction that returns one row.
-func TxQueryRow(tx *sql.Tx, query string, args ...interface{}) *sql.Row {
-	return tx.QueryRow(query, args...)
-}
-
-// TxExec executes a command within a transaction.
-func TxExec(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	return tx.Exec(query, args...)
-}
-
 // TxQueryContext executes a query within a transaction with context.
 func TxQueryContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
-	return tx.QueryContext(ctx, query, args...)
+	done := track(ctx, query)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+// TxQueryRowContext executes a query within a transaction that returns one row with context.
+func TxQueryRowContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) *sql.Row {
+	done := track(ctx, query)
+	row := tx.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
 }
 
 // TxExecContext executes a command within a transaction with context.
 func TxExecContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	return tx.ExecContext(ctx, query, args...)
+	done := track(ctx, query)
+	result, err := tx.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
 }
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"service-travego/internal/telemetry"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slowQueryThreshold is when a statement run for a request is logged as slow,
+// DB_SLOW_QUERY_MS (default 500)
+var slowQueryThreshold = func() time.Duration {
+	if ms, err := strconv.Atoi(os.Getenv("DB_SLOW_QUERY_MS")); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return 500 * time.Millisecond
+}()
+
+// track times a statement for the db_query_duration_seconds metric. Inside a
+// trace the statement also gets a span, and slow or failed statements are
+// logged with the transaction id of ctx.
+func track(ctx context.Context, query string) func(error) {
+	start := time.Now()
+	op := statementOperation(query)
+
+	var span *telemetry.Span
+	if telemetry.InTrace(ctx) {
+		_, span = telemetry.StartSpan(ctx, "db."+op, telemetry.SpanKindClient)
+		span.SetAttribute("db.operation", op)
+		span.SetAttribute("db.statement", compactStatement(query))
+	}
+
+	return func(err error) {
+		elapsed := time.Since(start)
+		outcome := "success"
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			outcome = "error"
+			span.RecordError(err)
+		}
+		span.End()
+		telemetry.DBQueryDuration.Observe(elapsed.Seconds(), op, outcome)
+
+		if outcome == "error" {
+			slog.ErrorContext(ctx, "query failed", "operation", op, "error", err, "statement", compactStatement(query))
+		} else if elapsed >= slowQueryThreshold {
+			slog.WarnContext(ctx, "slow query", "operation", op, "duration_ms", elapsed.Milliseconds(), "statement", compactStatement(query))
+		}
+	}
+}
+
+// statementOperation is the lower case verb of a statement, "other" for
+// anything but select, insert, update and delete
+func statementOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other"
+	}
+	switch op := strings.ToLower(fields[0]); op {
+	case "select", "insert", "update", "delete":
+		return op
+	case "with":
+		return "select"
+	}
+	return "other"
+}
+
+// compactStatement puts a statement on one line for logs and spans
+func compactStatement(query string) string {
+	const maxLen = 500
+	compact := strings.Join(strings.Fields(query), " ")
+	if len(compact) > maxLen {
+		compact = compact[:maxLen] + "..."
+	}
+	return compact
+}
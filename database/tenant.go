@@ -0,0 +1,599 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// organizationKey is the context key of the organization a request acts for
+type organizationKey struct{}
+
+// ErrNoOrganization is returned by a Tenant built from a context without an organization
+var ErrNoOrganization = errors.New("no organization in context")
+
+// ErrUnscopedQuery is returned for a query that reads or writes a tenant table
+// without filtering it by the organization of the Tenant
+var ErrUnscopedQuery = errors.New("query is not scoped to the organization")
+
+// tenantTables are the tables whose rows belong to one organization through
+// their organization_id column
+var tenantTables = map[string]bool{
+	"_subscription": true, "assistant_account_stats": true, "assistant_accounts": true,
+	"assistant_customer_stats": true, "assistant_customers": true, "audit_logs": true,
+	"cancellation_policies": true, "content": true, "customer_orders": true, "customers": true,
+	"document_reminders": true, "documents": true, "employee": true, "employee_leaves": true,
+	"employee_shift": true, "facilities": true, "fleet_addon": true, "fleet_facilities": true,
+	"fleet_images": true, "fleet_order_addons": true, "fleet_order_customers": true,
+	"fleet_order_expenses": true, "fleet_order_items": true, "fleet_order_itinerary": true,
+	"fleet_order_payment": true, "fleet_order_price_rules": true, "fleet_orders": true,
+	"fleet_pickup": true, "fleet_prices": true, "fleet_prices_history": true,
+	"fleet_unit_maintenance": true, "fleet_unit_maintenance_parts": true, "fleet_unit_odometer": true,
+	"fleet_unit_ownership": true, "fleet_unit_service_plans": true, "fleet_units": true,
+	"fleets": true, "garage": true, "hot_offers": true, "inventory_item_garage": true,
+	"inventory_items": true, "inventory_movement": true, "inventory_orders": true,
+	"inventory_request": true, "messages": true, "notifications": true, "operation_partner": true,
+	"order_cancellation_requests": true, "order_installments": true, "order_payment_history": true,
+	"order_reviews": true, "organization_api_keys": true, "organization_bank_accounts": true,
+	"organization_divisions": true, "organization_members": true, "organization_roles": true,
+	"organization_users": true, "organizations": true, "payment_midtrans_refunds": true,
+	"payment_orders": true, "payment_plans": true, "preference_cities": true,
+	"preference_city_types": true, "price_rules": true, "schedule_fleet_teams": true,
+	"schedule_fleets": true, "schedule_teams": true, "schedules": true, "sheet_sync_configs": true, "sheet_sync_cursors": true,
+	"subscription_events": true, "supliers": true,
+	"tour_package_addons": true, "tour_package_destinations": true, "tour_package_facilities": true,
+	"tour_package_images": true, "tour_package_itineraries": true, "tour_package_order_addons": true,
+	"tour_package_orders": true, "tour_package_pickup": true, "tour_package_prices": true,
+	"tour_package_schedules": true, "tour_packages": true, "transaction_fleet_trips": true,
+	"transaction_fleets": true, "transaction_orders": true, "transaction_refund": true,
+	"transaction_reimbursement": true, "transactions": true, "transacton_fleet_trips": true,
+	"travego_transactions": true, "voucher_redemptions": true, "vouchers": true, "wa_contacts": true,
+}
+
+// sharedOrganizations own the default rows every organization may read, such
+// as the built in roles and divisions
+var sharedOrganizations = map[string]bool{"00000000-0000-0000-0000-000000000000": true, "000": true}
+
+// WithOrganization returns a copy of ctx for requests acting for organizationID
+func WithOrganization(ctx context.Context, organizationID string) context.Context {
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// OrganizationFromContext returns the organization set by WithOrganization
+func OrganizationFromContext(ctx context.Context) (string, bool) {
+	organizationID, ok := ctx.Value(organizationKey{}).(string)
+	return organizationID, ok && organizationID != ""
+}
+
+// Tenant runs queries for the organization of a request. A query may only
+// touch a tenant table filtered by organization_id, either bound to the
+// organization or equal to the organization_id of a table that is; inserts
+// must write the organization into organization_id. A SELECT may also read the
+// default rows with organization_id IN (...) of the organization and the shared
+// ones, or the built in rows without an organization with
+// (organization_id IS NULL OR organization_id = ...). Tables are recognized
+// after FROM, JOIN, UPDATE and INTO and in comma separated FROM lists, and
+// organization_id must be qualified once a query has two tenant tables.
+type Tenant struct {
+	ctx            context.Context
+	db             *sql.DB
+	organizationID string
+}
+
+// TenantTx is a transaction started by Tenant.Begin, with the same checks
+type TenantTx struct {
+	tenant *Tenant
+	tx     *sql.Tx
+}
+
+// Row is the result of a tenant QueryRow; Scan reports a refused query
+type Row struct {
+	row *sql.Row
+	err error
+}
+
+// ForTenant returns a Tenant for the organization in ctx
+func ForTenant(ctx context.Context, db *sql.DB) (*Tenant, error) {
+	organizationID, ok := OrganizationFromContext(ctx)
+	if !ok {
+		return nil, ErrNoOrganization
+	}
+	return &Tenant{ctx: ctx, db: db, organizationID: organizationID}, nil
+}
+
+// OrganizationID is the organization every query is scoped to
+func (t *Tenant) OrganizationID() string {
+	return t.organizationID
+}
+
+// Query runs a query that returns rows
+func (t *Tenant) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if err := checkScope(query, args, t.organizationID); err != nil {
+		return nil, err
+	}
+	return QueryContext(t.ctx, t.db, query, args...)
+}
+
+// QueryRow runs a query that returns at most one row
+func (t *Tenant) QueryRow(query string, args ...interface{}) *Row {
+	if err := checkScope(query, args, t.organizationID); err != nil {
+		return &Row{err: err}
+	}
+	return &Row{row: QueryRowContext(t.ctx, t.db, query, args...)}
+}
+
+// Exec runs a query without returning rows
+func (t *Tenant) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if err := checkScope(query, args, t.organizationID); err != nil {
+		return nil, err
+	}
+	return ExecContext(t.ctx, t.db, query, args...)
+}
+
+// Begin starts a transaction
+func (t *Tenant) Begin() (*TenantTx, error) {
+	tx, err := t.db.BeginTx(t.ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &TenantTx{tenant: t, tx: tx}, nil
+}
+
+// OrganizationID is the organization every query is scoped to
+func (tx *TenantTx) OrganizationID() string {
+	return tx.tenant.organizationID
+}
+
+// Query runs a query that returns rows within the transaction
+func (tx *TenantTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if err := checkScope(query, args, tx.tenant.organizationID); err != nil {
+		return nil, err
+	}
+	return TxQueryContext(tx.tenant.ctx, tx.tx, query, args...)
+}
+
+// QueryRow runs a query that returns at most one row within the transaction
+func (tx *TenantTx) QueryRow(query string, args ...interface{}) *Row {
+	if err := checkScope(query, args, tx.tenant.organizationID); err != nil {
+		return &Row{err: err}
+	}
+	return &Row{row: TxQueryRowContext(tx.tenant.ctx, tx.tx, query, args...)}
+}
+
+// Exec runs a query without returning rows within the transaction
+func (tx *TenantTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if err := checkScope(query, args, tx.tenant.organizationID); err != nil {
+		return nil, err
+	}
+	return TxExecContext(tx.tenant.ctx, tx.tx, query, args...)
+}
+
+// Commit commits the transaction
+func (tx *TenantTx) Commit() error {
+	return tx.tx.Commit()
+}
+
+// Rollback aborts the transaction
+func (tx *TenantTx) Rollback() error {
+	return tx.tx.Rollback()
+}
+
+// Scan copies the row into dest, or returns why the query was refused
+func (r *Row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Scan(dest...)
+}
+
+var (
+	sqlTablePattern  = regexp.MustCompile(`\b(from|join|update|into)\s+(?:public\.)?([a-z_][a-z0-9_]*)(?:\s+(?:as\s+)?([a-z_][a-z0-9_]*))?`)
+	sqlListedPattern = regexp.MustCompile(`^\s*,\s*(?:public\.)?([a-z_][a-z0-9_]*)(?:\s+(?:as\s+)?([a-z_][a-z0-9_]*))?`)
+	sqlFilterPattern = regexp.MustCompile(`(?:\b([a-z_][a-z0-9_]*)\.)?\borganization_id(?:::[a-z]+)?\s*=\s*(?:(\$\d+|\?)|([a-z_][a-z0-9_]*)\.organization_id\b)`)
+	sqlListPattern   = regexp.MustCompile(`(?:\b([a-z_][a-z0-9_]*)\.)?\borganization_id(?:::[a-z]+)?\s+in\s*\(((?:\s*(?:\$\d+|\?)\s*,)*\s*(?:\$\d+|\?)\s*)\)`)
+	sqlMarkerPattern = regexp.MustCompile(`\$\d+|\?`)
+	sqlNullPattern   = regexp.MustCompile(`\(\s*(?:\b([a-z_][a-z0-9_]*)\.)?\borganization_id\s+is\s+null\s+or\s+$`)
+	sqlClosePattern  = regexp.MustCompile(`^(?:::[a-z]+)?\s*\)`)
+	sqlInsertPattern = regexp.MustCompile(`\binsert\s+into\s+(?:public\.)?([a-z_][a-z0-9_]*)\s*\(([^)]*)\)\s*values\b`)
+	sqlSetPattern    = regexp.MustCompile(`(?s)^\s*update\b.*?\bset\b`)
+	sqlWherePattern  = regexp.MustCompile(`\bwhere\b`)
+	sqlDollarPattern = regexp.MustCompile(`\$\d+`)
+	sqlClausePattern = regexp.MustCompile(`\b(select|from|where|on|join|group|order|limit|offset|having|union|returning|set|values|using|when|then|else|or)\b`)
+)
+
+// sqlKeywords can follow a table name and are not its alias
+var sqlKeywords = map[string]bool{
+	"where": true, "on": true, "set": true, "left": true, "right": true, "inner": true,
+	"outer": true, "full": true, "cross": true, "join": true, "natural": true, "group": true,
+	"order": true, "limit": true, "offset": true, "values": true, "using": true, "union": true,
+	"having": true, "returning": true, "select": true, "and": true, "or": true, "for": true,
+	"lateral": true, "window": true, "default": true,
+}
+
+// checkScope refuses query unless every tenant table in it is filtered by, or
+// written with, organizationID. A filter only counts when it is ANDed with the
+// rest of its clause.
+func checkScope(query string, args []interface{}, organizationID string) error {
+	q := stripSQL(strings.ToLower(query))
+
+	refs := map[string]string{}
+	addRef := func(table, alias string) {
+		if !tenantTables[table] {
+			return
+		}
+		name := table
+		if alias != "" && !sqlKeywords[alias] {
+			name = alias
+		}
+		refs[name] = table
+	}
+	for _, m := range sqlTablePattern.FindAllStringSubmatchIndex(q, -1) {
+		table, alias, end := q[m[4]:m[5]], "", m[5]
+		if m[6] >= 0 {
+			alias = q[m[6]:m[7]]
+			if !sqlKeywords[alias] {
+				end = m[7]
+			}
+		}
+		addRef(table, alias)
+		if q[m[2]:m[3]] != "from" {
+			continue
+		}
+		// the other tables of a comma separated FROM list
+		for {
+			l := sqlListedPattern.FindStringSubmatchIndex(q[end:])
+			if l == nil {
+				break
+			}
+			table, alias = q[end+l[2]:end+l[3]], ""
+			next := end + l[3]
+			if l[4] >= 0 {
+				alias = q[end+l[4] : end+l[5]]
+				if !sqlKeywords[alias] {
+					next = end + l[5]
+				}
+			}
+			addRef(table, alias)
+			end = next
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	// an UPDATE may assign organization_id; that is not a filter, but it must
+	// not move the row to another organization
+	setStart, setEnd := -1, -1
+	if loc := sqlSetPattern.FindStringIndex(q); loc != nil {
+		setStart, setEnd = loc[1], len(q)
+		if w := sqlWherePattern.FindStringIndex(q[setStart:]); w != nil {
+			setEnd = setStart + w[0]
+		}
+	}
+
+	// only a read may include the shared organizations or the built in rows
+	// beside its own
+	read := strings.HasPrefix(strings.TrimSpace(q), "select")
+
+	scoped := map[string]bool{}
+	var links [][2]string
+	for _, m := range sqlFilterPattern.FindAllStringSubmatchIndex(q, -1) {
+		var left string
+		if m[2] >= 0 {
+			left = q[m[2]:m[3]]
+		} else if len(refs) == 1 {
+			// an unqualified column can only be told apart with one tenant table
+			for name := range refs {
+				left = name
+			}
+		}
+		inSet := m[0] >= setStart && m[0] < setEnd
+		at := m[0]
+		if read {
+			if open := builtInGroup(q, m); open >= 0 {
+				at = open
+			}
+		}
+		if m[4] >= 0 {
+			value, ok := bindArg(q, args, m[4], q[m[4]:m[5]])
+			if !ok || !sameOrganization(value, organizationID) {
+				return fmt.Errorf("%w: organization_id is bound to another organization", ErrUnscopedQuery)
+			}
+			if !inSet && left != "" && andedFilter(q, at) {
+				scoped[left] = true
+			}
+			continue
+		}
+		if !inSet && left != "" && andedFilter(q, at) {
+			links = append(links, [2]string{left, q[m[6]:m[7]]})
+		}
+	}
+
+	for _, m := range sqlListPattern.FindAllStringSubmatchIndex(q, -1) {
+		var left string
+		if m[2] >= 0 {
+			left = q[m[2]:m[3]]
+		} else if len(refs) == 1 {
+			for name := range refs {
+				left = name
+			}
+		}
+		for _, p := range sqlMarkerPattern.FindAllStringIndex(q[m[4]:m[5]], -1) {
+			offset := m[4] + p[0]
+			value, ok := bindArg(q, args, offset, q[offset:m[4]+p[1]])
+			if !ok {
+				return fmt.Errorf("%w: organization_id is bound to another organization", ErrUnscopedQuery)
+			}
+			if sameOrganization(value, organizationID) {
+				continue
+			}
+			if s, isString := value.(string); !read || !isString || !sharedOrganizations[strings.TrimSpace(s)] {
+				return fmt.Errorf("%w: organization_id is bound to another organization", ErrUnscopedQuery)
+			}
+		}
+		if left != "" && andedFilter(q, m[0]) {
+			scoped[left] = true
+		}
+	}
+
+	if m := sqlInsertPattern.FindStringSubmatchIndex(q); m != nil {
+		table := q[m[2]:m[3]]
+		if tenantTables[table] {
+			column := -1
+			for i, c := range strings.Split(q[m[4]:m[5]], ",") {
+				if strings.TrimSpace(c) == "organization_id" {
+					column = i
+				}
+			}
+			if column < 0 {
+				return fmt.Errorf("%w: insert into %s without organization_id", ErrUnscopedQuery, table)
+			}
+			for _, row := range valueRows(q, m[1]) {
+				if column >= len(row.values) {
+					return fmt.Errorf("%w: insert into %s without organization_id", ErrUnscopedQuery, table)
+				}
+				value, ok := bindArg(q, args, row.offsets[column], row.values[column])
+				if !ok || !sameOrganization(value, organizationID) {
+					return fmt.Errorf("%w: insert into %s for another organization", ErrUnscopedQuery, table)
+				}
+			}
+			scoped[table] = true
+		}
+	}
+
+	// a table filtered by the organization_id of a scoped table is scoped too
+	for changed := true; changed; {
+		changed = false
+		for _, l := range links {
+			if scoped[l[0]] != scoped[l[1]] {
+				scoped[l[0]], scoped[l[1]], changed = true, true, true
+			}
+		}
+	}
+
+	for name, table := range refs {
+		if !scoped[name] {
+			return fmt.Errorf("%w: %s is not filtered by organization_id", ErrUnscopedQuery, table)
+		}
+	}
+	return nil
+}
+
+// builtInGroup returns the offset of the parenthesis opening
+// (x.organization_id IS NULL OR <filter>) when the filter matched by m is the
+// second half of one on the same column, or -1
+func builtInGroup(q string, m []int) int {
+	n := sqlNullPattern.FindStringSubmatchIndex(q[:m[0]])
+	if n == nil || !sqlClosePattern.MatchString(q[m[1]:]) {
+		return -1
+	}
+	var nullAlias, filterAlias string
+	if n[2] >= 0 {
+		nullAlias = q[n[2]:n[3]]
+	}
+	if m[2] >= 0 {
+		filterAlias = q[m[2]:m[3]]
+	}
+	if nullAlias != filterAlias {
+		return -1
+	}
+	return n[0]
+}
+
+// andedFilter reports whether the predicate at offset pos of q holds for every
+// row it lets through: no OR sits beside it in its clause, nor beside any
+// parenthesized group around it up to the (sub)query it belongs to
+func andedFilter(q string, pos int) bool {
+	depth := make([]int, len(q)+1)
+	for i := 0; i < len(q); i++ {
+		depth[i+1] = depth[i]
+		switch q[i] {
+		case '(':
+			depth[i+1]++
+		case ')':
+			depth[i+1]--
+		}
+	}
+	// depth[i] is the depth at the start of q[i]
+	words := sqlClausePattern.FindAllStringSubmatchIndex(q, -1)
+
+	for {
+		d := depth[pos]
+		open := -1
+		for i := pos - 1; i >= 0; i-- {
+			if q[i] == '(' && depth[i] == d-1 {
+				open = i
+				break
+			}
+		}
+		end := len(q)
+		for i := pos; i < len(q); i++ {
+			if q[i] == ')' && depth[i+1] == d-1 {
+				end = i
+				break
+			}
+		}
+
+		// the clause of pos runs between the keywords at its depth around it
+		clauseStart, clauseEnd := open+1, end
+		for _, w := range words {
+			if w[0] <= open || w[0] >= end || depth[w[0]] != d || q[w[2]:w[3]] == "or" {
+				continue
+			}
+			if w[0] < pos {
+				clauseStart = w[1]
+			} else if w[0] < clauseEnd {
+				clauseEnd = w[0]
+			}
+		}
+		for _, w := range words {
+			if w[0] >= clauseStart && w[0] < clauseEnd && depth[w[0]] == d && q[w[2]:w[3]] == "or" {
+				return false
+			}
+		}
+
+		// a subquery is scoped on its own; a plain group is checked in turn
+		if open < 0 || strings.HasPrefix(strings.TrimSpace(q[open+1:end]), "select") {
+			return true
+		}
+		pos = open
+	}
+}
+
+// bindArg returns the argument bound to the placeholder at offset in q, which
+// is a $n placeholder or the next ? one
+func bindArg(q string, args []interface{}, offset int, placeholder string) (interface{}, bool) {
+	index := -1
+	if strings.HasPrefix(placeholder, "$") {
+		n, err := strconv.Atoi(placeholder[1:])
+		if err != nil {
+			return nil, false
+		}
+		index = n - 1
+	} else if placeholder == "?" && !sqlDollarPattern.MatchString(q) {
+		index = strings.Count(q[:offset], "?")
+	}
+	if index < 0 || index >= len(args) {
+		return nil, false
+	}
+	return args[index], true
+}
+
+func sameOrganization(value interface{}, organizationID string) bool {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return false
+		}
+		value = v
+	}
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return false
+	}
+	return s != "" && strings.EqualFold(strings.TrimSpace(s), organizationID)
+}
+
+// valueRow is one parenthesized row of an INSERT ... VALUES list
+type valueRow struct {
+	values  []string
+	offsets []int
+}
+
+// valueRows splits the rows after the VALUES keyword ending at offset start
+func valueRows(q string, start int) []valueRow {
+	var rows []valueRow
+	i := start
+	for {
+		for i < len(q) && (q[i] == ' ' || q[i] == '\n' || q[i] == '\t' || q[i] == '\r') {
+			i++
+		}
+		if i >= len(q) || q[i] != '(' {
+			return rows
+		}
+		var row valueRow
+		depth, begin := 0, i+1
+		for i++; i < len(q); i++ {
+			c := q[i]
+			if c == '(' {
+				depth++
+				continue
+			}
+			if (c == ',' || c == ')') && depth == 0 {
+				value := strings.TrimSpace(q[begin:i])
+				row.values = append(row.values, value)
+				row.offsets = append(row.offsets, begin+strings.Index(q[begin:i], value))
+				begin = i + 1
+				if c == ')' {
+					break
+				}
+				continue
+			}
+			if c == ')' {
+				depth--
+			}
+		}
+		rows = append(rows, row)
+		i++
+		for i < len(q) && (q[i] == ' ' || q[i] == '\n' || q[i] == '\t' || q[i] == '\r') {
+			i++
+		}
+		if i >= len(q) || q[i] != ',' {
+			return rows
+		}
+		i++
+	}
+}
+
+// stripSQL blanks out string literals and comments, keeping offsets
+func stripSQL(q string) string {
+	b := []byte(q)
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\'':
+			j := i + 1
+			for j < len(b) {
+				if b[j] == '\'' {
+					if j+1 < len(b) && b[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			for k := i + 1; k < j && k < len(b); k++ {
+				b[k] = ' '
+			}
+			i = j
+		case b[i] == '-' && i+1 < len(b) && b[i+1] == '-':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			for ; i < len(b); i++ {
+				if b[i] == '*' && i+1 < len(b) && b[i+1] == '/' {
+					b[i], b[i+1] = ' ', ' '
+					i++
+					break
+				}
+				b[i] = ' '
+			}
+		case b[i] == '"':
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const (
+	orgA = "6f1c1a52-0a0e-4c4e-9a57-6d3c3f1b0a01"
+	orgB = "0b6a9d7e-55b2-4f0e-8d6e-2a9f4c7e0b02"
+)
+
+func TestCheckScopeAllowsScopedQueries(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"filter", `SELECT * FROM inventory_items WHERE item_id = $1 AND organization_id = $2`, []interface{}{"item", orgA}},
+		{"cast filter", `SELECT * FROM inventory_items i WHERE i.organization_id::text = $1`, []interface{}{orgA}},
+		{"mysql placeholders", "SELECT * FROM garage WHERE garage_id = ? AND organization_id = ?", []interface{}{"g", orgA}},
+		{"join through scoped table", `
+			SELECT i.item_name, g.garage_name FROM inventory_item_garage ig
+			INNER JOIN inventory_items i ON i.item_id = ig.item_id AND i.organization_id = ig.organization_id
+			LEFT JOIN garage g ON g.garage_id = ig.garage_id AND g.organization_id = i.organization_id
+			WHERE ig.organization_id = $1`, []interface{}{orgA}},
+		{"global table", `SELECT label FROM inventory_movement_types WHERE id = $1`, []interface{}{1}},
+		{"insert", `INSERT INTO garage (garage_id, organization_id, garage_name) VALUES ($1, $2, 'it''s, (here)')`, []interface{}{"g", orgA}},
+		{"update", `UPDATE inventory_items SET item_name = $1 WHERE item_id = $2 AND organization_id = $3`, []interface{}{"x", "item", orgA}},
+		{"literal mentions", `SELECT * FROM garage WHERE organization_id = $1 AND note <> 'from supliers'`, []interface{}{orgA}},
+		{"or in a group", `SELECT * FROM garage WHERE organization_id = $1 AND (status = 1 OR status = 2)`, []interface{}{orgA}},
+		{"or in another clause", `
+			SELECT g.garage_name, CASE WHEN g.status = 1 OR g.status = 2 THEN 'open' END FROM garage g
+			LEFT JOIN inventory_item_garage ig ON ig.garage_id = g.garage_id AND ig.organization_id = g.organization_id
+			WHERE g.organization_id = $1`, []interface{}{orgA}},
+		{"or beside a scoped subquery", `
+			SELECT * FROM garage g WHERE g.organization_id = $1 AND (g.garage_id = $2 OR g.garage_id IN (
+				SELECT ig.garage_id FROM inventory_item_garage ig WHERE ig.organization_id = $1 AND (ig.stock > 0 OR ig.stock IS NULL)))`, []interface{}{orgA, "g"}},
+		{"comma join", `
+			SELECT * FROM inventory_items i, inventory_item_garage AS ig, inventory_movement_types mt
+			WHERE ig.item_id = i.item_id AND ig.organization_id = i.organization_id AND i.organization_id = $1`, []interface{}{orgA}},
+		{"update from list", `
+			UPDATE inventory_item_garage ig SET stock = 0
+			FROM inventory_items i, garage g
+			WHERE i.item_id = ig.item_id AND g.garage_id = ig.garage_id
+				AND ig.organization_id = $1 AND i.organization_id = ig.organization_id AND g.organization_id = ig.organization_id`, []interface{}{orgA}},
+		{"shared default rows", `SELECT * FROM organization_roles WHERE organization_id::text IN ($1,$2,$3)`, []interface{}{orgA, "00000000-0000-0000-0000-000000000000", "000"}},
+		{"shared default rows mysql", "SELECT * FROM organization_roles r WHERE r.role_id = ? AND r.organization_id IN (?, ?)", []interface{}{"r", "000", orgA}},
+		{"built in rows", `SELECT * FROM facilities WHERE (organization_id IS NULL OR organization_id = $1) AND facility_name = $2`, []interface{}{orgA, "wifi"}},
+		{"built in rows through a join", `
+			SELECT f.facility_name FROM fleet_facilities ff
+			INNER JOIN facilities f ON f.facility_id = ff.facility_id AND (f.organization_id IS NULL OR f.organization_id = ff.organization_id)
+			WHERE ff.fleet_id = $1 AND ff.organization_id = $2`, []interface{}{"fleet", orgA}},
+	}
+	for _, c := range cases {
+		if err := checkScope(c.query, c.args, orgA); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+		}
+	}
+}
+
+func TestCheckScopeRefusesOtherTenants(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"no filter", `SELECT * FROM inventory_items WHERE item_id = $1`, []interface{}{"item"}},
+		{"other organization", `SELECT * FROM inventory_items WHERE organization_id = $1`, []interface{}{orgB}},
+		{"other organization mysql", "SELECT * FROM garage WHERE garage_id = ? AND organization_id = ?", []interface{}{orgA, orgB}},
+		{"unscoped join", `
+			SELECT * FROM inventory_items i
+			LEFT JOIN inventory_item_garage ig ON ig.item_id = i.item_id
+			WHERE i.organization_id = $1`, []interface{}{orgA}},
+		{"unqualified filter with two tables", `
+			SELECT * FROM inventory_items i JOIN garage g ON g.garage_id = i.garage_id
+			WHERE organization_id = $1`, []interface{}{orgA}},
+		{"filter only in a comment", `SELECT * FROM garage -- WHERE organization_id = $1
+			WHERE garage_id = $1`, []interface{}{orgA}},
+		{"insert without organization", `INSERT INTO garage (garage_id, garage_name) VALUES ($1, $2)`, []interface{}{"g", "x"}},
+		{"insert for other organization", `INSERT INTO garage (garage_id, organization_id) VALUES ($1, $2), ($3, $4)`, []interface{}{"g", orgA, "h", orgB}},
+		{"update moves row", `UPDATE garage SET organization_id = $1 WHERE garage_id = $2 AND organization_id = $3`, []interface{}{orgB, "g", orgA}},
+		{"set is not a filter", `UPDATE garage SET organization_id = $1 WHERE garage_id = $2`, []interface{}{orgA, "g"}},
+		{"delete", `DELETE FROM supliers WHERE suplier_id = $1`, []interface{}{"s"}},
+		{"or after the filter", `SELECT * FROM garage WHERE organization_id = $1 OR garage_id = $2`, []interface{}{orgA, "g"}},
+		{"or before the filter", `SELECT * FROM garage WHERE garage_id = $2 OR organization_id = $1`, []interface{}{orgA, "g"}},
+		{"or inside the group of the filter", `SELECT * FROM garage WHERE status = 1 AND (organization_id = $1 OR is_public)`, []interface{}{orgA}},
+		{"or beside the group of the filter", `SELECT * FROM garage WHERE (organization_id = $1 AND status = 1) OR status = 2`, []interface{}{orgA}},
+		{"or beside a join filter", `
+			SELECT * FROM garage g
+			JOIN inventory_item_garage ig ON ig.garage_id = g.garage_id AND ig.organization_id = g.organization_id OR ig.stock > 0
+			WHERE g.organization_id = $1`, []interface{}{orgA}},
+		{"or in a delete", `DELETE FROM supliers WHERE organization_id = $1 OR suplier_id = $2`, []interface{}{orgA, "s"}},
+		{"unscoped comma join", `
+			SELECT * FROM inventory_items i, inventory_item_garage ig
+			WHERE ig.item_id = i.item_id AND i.organization_id = $1`, []interface{}{orgA}},
+		{"unscoped table in a comma join", `SELECT * FROM garage g, supliers WHERE g.organization_id = $1`, []interface{}{orgA}},
+		{"tenant table listed after a global one", `SELECT * FROM inventory_movement_types, supliers WHERE id = $1`, []interface{}{1}},
+		{"other organization in a list", `SELECT * FROM organization_roles WHERE organization_id IN ($1, $2)`, []interface{}{orgA, orgB}},
+		{"write to shared rows", `UPDATE organization_roles SET role_name = $1 WHERE organization_id IN ($2, $3)`, []interface{}{"x", orgA, "000"}},
+		{"or beside a list", `SELECT * FROM organization_roles WHERE organization_id IN ($1, $2) OR status = 1`, []interface{}{orgA, "000"}},
+		{"write to built in rows", `UPDATE facilities SET facility_name = $1 WHERE (organization_id IS NULL OR organization_id = $2)`, []interface{}{"x", orgA}},
+		{"built in rows of another column", `
+			SELECT f.facility_name FROM fleet_facilities ff
+			INNER JOIN facilities f ON f.facility_id = ff.facility_id AND (ff.organization_id IS NULL OR f.organization_id = ff.organization_id)
+			WHERE ff.organization_id = $1`, []interface{}{orgA}},
+		{"or beside the built in rows", `SELECT * FROM facilities WHERE (organization_id IS NULL OR organization_id = $1) OR facility_name = $2`, []interface{}{orgA, "wifi"}},
+		{"built in rows of another organization", `SELECT * FROM facilities WHERE (organization_id IS NULL OR organization_id = $1)`, []interface{}{orgB}},
+	}
+	for _, c := range cases {
+		if err := checkScope(c.query, c.args, orgA); !errors.Is(err, ErrUnscopedQuery) {
+			t.Errorf("%s: expected ErrUnscopedQuery, got %v", c.name, err)
+		}
+	}
+}
+
+func TestForTenantNeedsOrganization(t *testing.T) {
+	if _, err := ForTenant(context.Background(), nil); !errors.Is(err, ErrNoOrganization) {
+		t.Fatalf("expected ErrNoOrganization, got %v", err)
+	}
+
+	tenant, err := ForTenant(WithOrganization(context.Background(), orgA), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenant.OrganizationID() != orgA {
+		t.Fatalf("unexpected organization %s", tenant.OrganizationID())
+	}
+	// refused before reaching the (nil) connection
+	if err := tenant.QueryRow(`SELECT 1 FROM garage`).Scan(); !errors.Is(err, ErrUnscopedQuery) {
+		t.Fatalf("expected ErrUnscopedQuery, got %v", err)
+	}
+}
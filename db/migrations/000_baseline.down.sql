@@ -0,0 +1,95 @@
+-- Drops everything the baseline created. This empties the database.
+DROP TABLE IF EXISTS public.wa_contacts CASCADE;
+DROP TABLE IF EXISTS public.users CASCADE;
+DROP TABLE IF EXISTS public.travego_visitors CASCADE;
+DROP TABLE IF EXISTS public.travego_transactions CASCADE;
+DROP TABLE IF EXISTS public.travego_reviews CASCADE;
+DROP TABLE IF EXISTS public.travego_messages CASCADE;
+DROP TABLE IF EXISTS public.transacton_fleet_trips CASCADE;
+DROP TABLE IF EXISTS public.transactions CASCADE;
+DROP TABLE IF EXISTS public.transaction_types CASCADE;
+DROP TABLE IF EXISTS public.transaction_reimbursement CASCADE;
+DROP TABLE IF EXISTS public.transaction_refund CASCADE;
+DROP TABLE IF EXISTS public.transaction_orders CASCADE;
+DROP TABLE IF EXISTS public.transaction_fleets CASCADE;
+DROP TABLE IF EXISTS public.transaction_fleet_trips CASCADE;
+DROP TABLE IF EXISTS public.tour_packages CASCADE;
+DROP TABLE IF EXISTS public.tour_package_schedules CASCADE;
+DROP TABLE IF EXISTS public.tour_package_prices CASCADE;
+DROP TABLE IF EXISTS public.tour_package_pickup CASCADE;
+DROP TABLE IF EXISTS public.tour_package_orders CASCADE;
+DROP TABLE IF EXISTS public.tour_package_order_addons CASCADE;
+DROP TABLE IF EXISTS public.tour_package_itineraries CASCADE;
+DROP TABLE IF EXISTS public.tour_package_images CASCADE;
+DROP TABLE IF EXISTS public.tour_package_facilities CASCADE;
+DROP TABLE IF EXISTS public.tour_package_destinations CASCADE;
+DROP TABLE IF EXISTS public.tour_package_addons CASCADE;
+DROP TABLE IF EXISTS public.supliers CASCADE;
+DROP TABLE IF EXISTS public.schedules CASCADE;
+DROP TABLE IF EXISTS public.schedule_teams CASCADE;
+DROP TABLE IF EXISTS public.schedule_fleets CASCADE;
+DROP TABLE IF EXISTS public.schedule_fleet_teams CASCADE;
+DROP TABLE IF EXISTS public.preference_city_types CASCADE;
+DROP TABLE IF EXISTS public.preference_cities CASCADE;
+DROP TABLE IF EXISTS public.payment_orders CASCADE;
+DROP TABLE IF EXISTS public.payment_midtrans CASCADE;
+DROP TABLE IF EXISTS public.organizations CASCADE;
+DROP TABLE IF EXISTS public.organization_users CASCADE;
+DROP TABLE IF EXISTS public.organization_types CASCADE;
+DROP TABLE IF EXISTS public.organization_roles CASCADE;
+DROP TABLE IF EXISTS public.organization_members CASCADE;
+DROP TABLE IF EXISTS public.organization_divisions CASCADE;
+DROP TABLE IF EXISTS public.organization_bank_accounts CASCADE;
+DROP TABLE IF EXISTS public.order_reviews CASCADE;
+DROP TABLE IF EXISTS public.order_payment_history CASCADE;
+DROP TABLE IF EXISTS public.operation_partner CASCADE;
+DROP TABLE IF EXISTS public.notifications CASCADE;
+DROP TABLE IF EXISTS public.messages CASCADE;
+DROP TABLE IF EXISTS public.inventory_request_fleets CASCADE;
+DROP TABLE IF EXISTS public.inventory_request CASCADE;
+DROP TABLE IF EXISTS public.inventory_orders CASCADE;
+DROP TABLE IF EXISTS public.inventory_movement_types CASCADE;
+DROP TABLE IF EXISTS public.inventory_movement CASCADE;
+DROP TABLE IF EXISTS public.inventory_items CASCADE;
+DROP TABLE IF EXISTS public.inventory_item_supliers CASCADE;
+DROP TABLE IF EXISTS public.inventory_item_garage CASCADE;
+DROP TABLE IF EXISTS public.hot_offers CASCADE;
+DROP TABLE IF EXISTS public.garage CASCADE;
+DROP TABLE IF EXISTS public.fleets CASCADE;
+DROP TABLE IF EXISTS public.fleet_units CASCADE;
+DROP TABLE IF EXISTS public.fleet_unit_ownership CASCADE;
+DROP TABLE IF EXISTS public.fleet_types CASCADE;
+DROP TABLE IF EXISTS public.fleet_prices_history CASCADE;
+DROP TABLE IF EXISTS public.fleet_prices CASCADE;
+DROP TABLE IF EXISTS public.fleet_pickup CASCADE;
+DROP TABLE IF EXISTS public.fleet_orders CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_payment CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_itinerary CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_items CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_expenses CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_destinations CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_customers CASCADE;
+DROP TABLE IF EXISTS public.fleet_order_addons CASCADE;
+DROP TABLE IF EXISTS public.fleet_images CASCADE;
+DROP TABLE IF EXISTS public.fleet_facilities CASCADE;
+DROP TABLE IF EXISTS public.fleet_addon CASCADE;
+DROP TABLE IF EXISTS public.facilities CASCADE;
+DROP TABLE IF EXISTS public.employee_shift CASCADE;
+DROP TABLE IF EXISTS public.employee_leaves CASCADE;
+DROP TABLE IF EXISTS public.employee_leave_type CASCADE;
+DROP TABLE IF EXISTS public.employee CASCADE;
+DROP TABLE IF EXISTS public.customers CASCADE;
+DROP TABLE IF EXISTS public.customer_orders CASCADE;
+DROP TABLE IF EXISTS public.content_list CASCADE;
+DROP TABLE IF EXISTS public.content CASCADE;
+DROP TABLE IF EXISTS public.bank_list CASCADE;
+DROP TABLE IF EXISTS public.assistant_customers CASCADE;
+DROP TABLE IF EXISTS public.assistant_customer_stats CASCADE;
+DROP TABLE IF EXISTS public.assistant_accounts CASCADE;
+DROP TABLE IF EXISTS public.assistant_account_stats CASCADE;
+DROP TABLE IF EXISTS public._usage CASCADE;
+DROP TABLE IF EXISTS public._subscription_payment CASCADE;
+DROP TABLE IF EXISTS public._subscription CASCADE;
+DROP TABLE IF EXISTS public._packages CASCADE;
+DROP TABLE IF EXISTS public._assistant CASCADE;
+DROP SEQUENCE IF EXISTS public.travego_visitors_id_seq;
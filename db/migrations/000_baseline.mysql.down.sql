@@ -0,0 +1,95 @@
+-- Drops everything the baseline created. This empties the database.
+ALTER TABLE organizations DROP FOREIGN KEY organizations_created_by_fkey;
+DROP TABLE IF EXISTS wa_contacts;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS travego_visitors;
+DROP TABLE IF EXISTS travego_transactions;
+DROP TABLE IF EXISTS travego_reviews;
+DROP TABLE IF EXISTS travego_messages;
+DROP TABLE IF EXISTS transacton_fleet_trips;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS transaction_types;
+DROP TABLE IF EXISTS transaction_reimbursement;
+DROP TABLE IF EXISTS transaction_refund;
+DROP TABLE IF EXISTS transaction_orders;
+DROP TABLE IF EXISTS transaction_fleets;
+DROP TABLE IF EXISTS transaction_fleet_trips;
+DROP TABLE IF EXISTS tour_packages;
+DROP TABLE IF EXISTS tour_package_schedules;
+DROP TABLE IF EXISTS tour_package_prices;
+DROP TABLE IF EXISTS tour_package_pickup;
+DROP TABLE IF EXISTS tour_package_orders;
+DROP TABLE IF EXISTS tour_package_order_addons;
+DROP TABLE IF EXISTS tour_package_itineraries;
+DROP TABLE IF EXISTS tour_package_images;
+DROP TABLE IF EXISTS tour_package_facilities;
+DROP TABLE IF EXISTS tour_package_destinations;
+DROP TABLE IF EXISTS tour_package_addons;
+DROP TABLE IF EXISTS supliers;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS schedule_teams;
+DROP TABLE IF EXISTS schedule_fleets;
+DROP TABLE IF EXISTS schedule_fleet_teams;
+DROP TABLE IF EXISTS preference_city_types;
+DROP TABLE IF EXISTS preference_cities;
+DROP TABLE IF EXISTS payment_orders;
+DROP TABLE IF EXISTS payment_midtrans;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS organization_users;
+DROP TABLE IF EXISTS organization_types;
+DROP TABLE IF EXISTS organization_roles;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organization_divisions;
+DROP TABLE IF EXISTS organization_bank_accounts;
+DROP TABLE IF EXISTS order_reviews;
+DROP TABLE IF EXISTS order_payment_history;
+DROP TABLE IF EXISTS operation_partner;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS inventory_request_fleets;
+DROP TABLE IF EXISTS inventory_request;
+DROP TABLE IF EXISTS inventory_orders;
+DROP TABLE IF EXISTS inventory_movement_types;
+DROP TABLE IF EXISTS inventory_movement;
+DROP TABLE IF EXISTS inventory_items;
+DROP TABLE IF EXISTS inventory_item_supliers;
+DROP TABLE IF EXISTS inventory_item_garage;
+DROP TABLE IF EXISTS hot_offers;
+DROP TABLE IF EXISTS garage;
+DROP TABLE IF EXISTS fleets;
+DROP TABLE IF EXISTS fleet_units;
+DROP TABLE IF EXISTS fleet_unit_ownership;
+DROP TABLE IF EXISTS fleet_types;
+DROP TABLE IF EXISTS fleet_prices_history;
+DROP TABLE IF EXISTS fleet_prices;
+DROP TABLE IF EXISTS fleet_pickup;
+DROP TABLE IF EXISTS fleet_orders;
+DROP TABLE IF EXISTS fleet_order_payment;
+DROP TABLE IF EXISTS fleet_order_itinerary;
+DROP TABLE IF EXISTS fleet_order_items;
+DROP TABLE IF EXISTS fleet_order_expenses;
+DROP TABLE IF EXISTS fleet_order_destinations;
+DROP TABLE IF EXISTS fleet_order_customers;
+DROP TABLE IF EXISTS fleet_order_addons;
+DROP TABLE IF EXISTS fleet_images;
+DROP TABLE IF EXISTS fleet_facilities;
+DROP TABLE IF EXISTS fleet_addon;
+DROP TABLE IF EXISTS facilities;
+DROP TABLE IF EXISTS employee_shift;
+DROP TABLE IF EXISTS employee_leaves;
+DROP TABLE IF EXISTS employee_leave_type;
+DROP TABLE IF EXISTS employee;
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS customer_orders;
+DROP TABLE IF EXISTS content_list;
+DROP TABLE IF EXISTS content;
+DROP TABLE IF EXISTS bank_list;
+DROP TABLE IF EXISTS assistant_customers;
+DROP TABLE IF EXISTS assistant_customer_stats;
+DROP TABLE IF EXISTS assistant_accounts;
+DROP TABLE IF EXISTS assistant_account_stats;
+DROP TABLE IF EXISTS _usage;
+DROP TABLE IF EXISTS _subscription_payment;
+DROP TABLE IF EXISTS _subscription;
+DROP TABLE IF EXISTS _packages;
+DROP TABLE IF EXISTS _assistant;
@@ -0,0 +1,1393 @@
+-- MySQL variant of 000_baseline.up.sql: uuids are CHAR(36), timestamps are
+-- DATETIME and travego_visitors.id is AUTO_INCREMENT instead of a sequence.
+--
+-- Baseline schema: every table the application used before schema changes
+-- were tracked, consolidated from the former pg_dump (db/sql/travego-db.sql)
+-- and database/migrations/001_create_wa_contacts.sql, with the reference data
+-- (banks, fleet types, organization types, ...) a fresh database needs.
+-- It replaces db/migrations/001..004, which the dump had already superseded.
+--
+-- Databases created before migrations were tracked already have this schema;
+-- record it without running it with: go run ./cmd/migrate up -baseline
+
+CREATE TABLE _assistant (
+    account_id CHAR(36),
+    user_id CHAR(36),
+    username VARCHAR(50),
+    phone_number VARCHAR(20),
+    status integer,
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE _packages (
+    package_id VARCHAR(10),
+    package_name VARCHAR(20),
+    package_price DECIMAL(20,2),
+    original_price DECIMAL(20,2),
+    fleet_limit integer,
+    tour_package_limit integer,
+    fleet_order_limit integer,
+    tour_order_limit integer,
+    assistant_account_limit integer,
+    assistant_request_limit DECIMAL(20,2)
+);
+
+CREATE TABLE _subscription (
+    subscription_id CHAR(36),
+    organization_id CHAR(36),
+    package_id VARCHAR(10),
+    activate_date date,
+    expiry_date date,
+    subscription_type integer,
+    status integer,
+    package_price DECIMAL(20,2),
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE _subscription_payment (
+    payment_id CHAR(36),
+    invoice_id VARCHAR(255),
+    subscription_id CHAR(36),
+    user_id CHAR(36),
+    payment_amount DECIMAL(20,2),
+    discount DECIMAL(20,2),
+    promotion_id CHAR(36),
+    referral_id CHAR(36),
+    payment_type VARCHAR(20),
+    payment_date DATETIME,
+    merchant_id VARCHAR(20)
+);
+
+CREATE TABLE _usage (
+    usage_id CHAR(36),
+    subscription_id CHAR(36),
+    user_id CHAR(36),
+    fleet_limit integer,
+    tour_package_limit integer,
+    fleet_order_limit integer,
+    tour_order_limit integer,
+    assistant_limit integer,
+    created_at DATETIME
+);
+
+CREATE TABLE assistant_account_stats (
+    statistic_id CHAR(36),
+    period date,
+    count integer,
+    organization_id CHAR(36),
+    type integer,
+    status integer
+);
+
+CREATE TABLE assistant_accounts (
+    assistant_id CHAR(36),
+    organization_id CHAR(36),
+    user_type integer,
+    user_id CHAR(36),
+    account_number VARCHAR(17),
+    account_name VARCHAR(50),
+    created_at DATETIME,
+    created_by CHAR(36),
+    status integer
+);
+
+CREATE TABLE assistant_customer_stats (
+    statistic_id CHAR(36),
+    period date,
+    count integer,
+    organization_id CHAR(36),
+    type integer,
+    status integer
+);
+
+CREATE TABLE assistant_customers (
+    device_id VARCHAR(150),
+    device_name VARCHAR(50),
+    assistant_device_id VARCHAR(40),
+    account VARCHAR(20),
+    device_token VARCHAR(200),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE bank_list (
+    code VARCHAR(10) NOT NULL,
+    name text NOT NULL,
+    icon VARCHAR(100)
+);
+
+CREATE TABLE content (
+    uuid CHAR(36),
+    section_tag VARCHAR(100),
+    organization_id CHAR(36),
+    content text,
+    parent VARCHAR(100),
+    is_active boolean,
+    type VARCHAR(20),
+    fuel_type VARCHAR(10),
+    transmission VARCHAR(20),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_by CHAR(36),
+    updated_at DATETIME
+);
+
+CREATE TABLE content_list (
+    uuid CHAR(36),
+    content_id CHAR(36),
+    icon VARCHAR(255),
+    label VARCHAR(100),
+    sub_label VARCHAR(255),
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE customer_orders (
+    order_id VARCHAR(100),
+    customer_id CHAR(36),
+    order_type integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE customers (
+    customer_id CHAR(36),
+    organization_id CHAR(36),
+    customer_name VARCHAR(100),
+    customer_email VARCHAR(100),
+    customer_address VARCHAR(100),
+    customer_city integer,
+    customer_phone VARCHAR(16),
+    customer_bod date,
+    customer_company VARCHAR(100),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE employee (
+    uuid CHAR(36),
+    employee_id VARCHAR(100),
+    nik VARCHAR(20),
+    fullname VARCHAR(50),
+    phone VARCHAR(20),
+    birth_date date,
+    email VARCHAR(50),
+    address VARCHAR(255),
+    address_city integer,
+    join_date date,
+    role_id CHAR(36),
+    organization_id CHAR(36),
+    avatar VARCHAR(200),
+    contract_status integer,
+    status integer,
+    resign_date date,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE employee_leave_type (
+    id integer,
+    label VARCHAR(50)
+);
+
+CREATE TABLE employee_leaves (
+    leave_id CHAR(36),
+    organization_id CHAR(36),
+    employee_id CHAR(36),
+    substituted_by CHAR(36),
+    start_date date,
+    end_date date,
+    leave_type integer,
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE employee_shift (
+    shift_id CHAR(36),
+    organization_id CHAR(36),
+    employee_id CHAR(36),
+    shift_date date,
+    shift_type integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE facilities (
+    facility_id CHAR(36),
+    facility_name VARCHAR(200),
+    facility_icon character(20),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_addon (
+    uuid CHAR(36),
+    fleet_id CHAR(36),
+    addon_name VARCHAR(255),
+    addon_desc text,
+    addon_price integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_facilities (
+    uuid CHAR(36),
+    fleet_id CHAR(36),
+    facility_id CHAR(36),
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_images (
+    uuid CHAR(36),
+    fleet_id CHAR(36),
+    path_file VARCHAR(255),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_order_addons (
+    order_addon_id CHAR(36),
+    order_id VARCHAR(50),
+    addon_id CHAR(36),
+    addon_price DECIMAL(20,2),
+    organization_id CHAR(36),
+    order_item_id CHAR(36),
+    created_at DATETIME
+);
+
+CREATE TABLE fleet_order_customers (
+    customer_id CHAR(36),
+    order_id VARCHAR(50),
+    customer_name VARCHAR(100),
+    customer_phone VARCHAR(20),
+    customer_email VARCHAR(50),
+    customer_address VARCHAR(255),
+    organization_id CHAR(36),
+    created_at DATETIME
+);
+
+CREATE TABLE fleet_order_destinations (
+    order_destination_id CHAR(36),
+    order_id VARCHAR(50),
+    city_id integer,
+    location VARCHAR(255),
+    created_at DATETIME
+);
+
+CREATE TABLE fleet_order_expenses (
+    fleet_expense_id CHAR(36),
+    expense_id CHAR(36),
+    schedule_id CHAR(36),
+    trip_id VARCHAR(50),
+    amount DECIMAL(20,2),
+    quantity integer,
+    total_amount DECIMAL(20,2),
+    payment_type integer,
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE fleet_order_items (
+    order_item_id CHAR(36),
+    organization_id CHAR(36),
+    order_id VARCHAR(100),
+    fleet_id CHAR(36),
+    price_id CHAR(36),
+    quantity DECIMAL(20,2),
+    charge_amount DECIMAL(20,2),
+    discount DECIMAL(20,2),
+    sub_total DECIMAL(20,2),
+    addon_amount DECIMAL(20,2),
+    status integer,
+    create_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE fleet_order_itinerary (
+    fleet_itinerary_id CHAR(36),
+    order_id VARCHAR(100),
+    day_num integer,
+    city_id integer,
+    location VARCHAR(100),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE fleet_order_payment (
+    order_payment_id CHAR(36),
+    order_id VARCHAR(50),
+    organization_id CHAR(36),
+    payment_method CHAR(36),
+    payment_type integer,
+    payment_percentage integer,
+    payment_amount DECIMAL(20,2),
+    total_amount DECIMAL(20,2),
+    payment_remaining DECIMAL(20,2),
+    unique_code VARCHAR(10),
+    evidence_file VARCHAR(100),
+    status integer,
+    created_at DATETIME,
+    settled_at DATETIME,
+    canceled_at DATETIME,
+    approve_by CHAR(36)
+);
+
+CREATE TABLE fleet_orders (
+    order_id VARCHAR(50),
+    fleet_id CHAR(36),
+    start_date DATETIME,
+    end_date DATETIME,
+    pickup_city_id integer,
+    pickup_location VARCHAR(255),
+    unit_qty integer,
+    price_id CHAR(36),
+    total_amount DECIMAL(20,2),
+    additional_amount DECIMAL(20,2),
+    discount DECIMAL(20,2),
+    additional_request text,
+    status integer,
+    payment_status integer,
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    approve_by CHAR(36),
+    approve_date DATETIME,
+    cancel_by CHAR(36),
+    cancel_date DATETIME,
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE fleet_pickup (
+    uuid CHAR(36),
+    fleet_id CHAR(36),
+    city_id integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_prices (
+    uuid CHAR(36),
+    fleet_id CHAR(36),
+    duration integer,
+    rent_type integer,
+    price integer,
+    disc_amount integer,
+    disc_price integer,
+    uom VARCHAR(10),
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_prices_history (
+    uuid CHAR(36),
+    fleet_id CHAR(36),
+    duration integer,
+    rent_type integer,
+    price integer,
+    disc_amount integer,
+    disc_price integer,
+    uom VARCHAR(10),
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_types (
+    id VARCHAR(5),
+    label VARCHAR(50)
+);
+
+CREATE TABLE fleet_unit_ownership (
+    fleet_ownership_id CHAR(36),
+    unit_id CHAR(36),
+    partner_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE fleet_units (
+    unit_id CHAR(36),
+    vehicle_id VARCHAR(100),
+    plate_number VARCHAR(20),
+    fleet_id CHAR(36),
+    engine VARCHAR(100),
+    capacity integer,
+    production_year integer,
+    transmission VARCHAR(20),
+    ownership_type integer,
+    organization_id CHAR(36),
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE fleets (
+    uuid CHAR(36),
+    fleet_name VARCHAR(100),
+    fleet_type VARCHAR(5),
+    capacity integer,
+    production_year integer,
+    engine VARCHAR(50),
+    body VARCHAR(50),
+    description text,
+    organization_id CHAR(36),
+    thumbnail VARCHAR(255),
+    fuel_type VARCHAR(10),
+    transmission VARCHAR(20),
+    views integer,
+    is_public integer,
+    active boolean,
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE garage (
+    organization_id CHAR(36),
+    garage_id CHAR(36),
+    garage_name VARCHAR(50),
+    garage_address VARCHAR(255),
+    garage_city VARCHAR(50),
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE hot_offers (
+    promo_id CHAR(36),
+    service_type VARCHAR(10),
+    product_id CHAR(36),
+    discount_type VARCHAR(10),
+    discount_value bigint,
+    period_start DATETIME,
+    period_end DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+    organization_id CHAR(36),
+    created_by CHAR(36),
+    updated_by CHAR(36)
+);
+
+CREATE TABLE inventory_item_garage (
+    item_garage_id CHAR(36),
+    item_id CHAR(36),
+    garage_id CHAR(36),
+    stock integer,
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE inventory_item_supliers (
+    item_id CHAR(36),
+    suplier_id CHAR(36),
+    transaction_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36)
+);
+
+CREATE TABLE inventory_items (
+    item_id CHAR(36),
+    organization_id CHAR(36),
+    item_name VARCHAR(100),
+    item_uom VARCHAR(20),
+    item_category integer,
+    stock integer,
+    item_sku VARCHAR(20),
+    item_price DECIMAL(20,2),
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE inventory_movement (
+    movement_id CHAR(36),
+    item_id CHAR(36),
+    garage_id CHAR(36),
+    quantity integer,
+    stock_before integer,
+    stock_final integer,
+    movement_type integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    organization_id CHAR(36),
+    notes VARCHAR(100)
+);
+
+CREATE TABLE inventory_movement_types (
+    id integer,
+    label VARCHAR(255)
+);
+
+CREATE TABLE inventory_orders (
+    request_id VARCHAR(20),
+    purchase_id VARCHAR(20),
+    item_id CHAR(36),
+    garage_id CHAR(36),
+    suplier_id CHAR(36),
+    quantity integer,
+    item_price DECIMAL(20,2),
+    total_amount DECIMAL(20,2),
+    item_category integer,
+    transaction_date date,
+    organization_id CHAR(36),
+    status integer,
+    complete_date date,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE inventory_request (
+    request_id VARCHAR(50),
+    item_category integer,
+    item_id CHAR(36),
+    item_name VARCHAR(50),
+    item_uom VARCHAR(10),
+    garage_id CHAR(36),
+    employee_id CHAR(36),
+    quantity integer,
+    notes text,
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    approve_at DATETIME,
+    approve_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36),
+    received_at DATETIME,
+    received_by CHAR(36)
+);
+
+CREATE TABLE inventory_request_fleets (
+    request_id VARCHAR(50),
+    unit_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE messages (
+    message_id CHAR(36),
+    customer_name VARCHAR(100),
+    customer_email VARCHAR(50),
+    customer_phone VARCHAR(20),
+    message_type VARCHAR(20),
+    message text,
+    status integer,
+    created_at DATETIME,
+    updated_at DATETIME,
+    organization_id CHAR(36)
+);
+
+CREATE TABLE notifications (
+    notification_id CHAR(36),
+    organization_id CHAR(36),
+    reference_url text,
+    title VARCHAR(50),
+    message VARCHAR(100),
+    created_at DATETIME,
+    is_read boolean
+);
+
+CREATE TABLE operation_partner (
+    partner_id CHAR(36),
+    partner_name VARCHAR(50),
+    partner_address VARCHAR(100),
+    partner_city integer,
+    partner_phone VARCHAR(20),
+    pic_name VARCHAR(50),
+    partner_email VARCHAR(50),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE order_payment_history (
+    payment_history_id CHAR(36),
+    order_id VARCHAR(50),
+    bank_account_id CHAR(36),
+    bank_code VARCHAR(10),
+    account_number VARCHAR(30),
+    account_name VARCHAR(50),
+    payment_amount DECIMAL(20,2),
+    unique_code VARCHAR(10),  
+    organization_id CHAR(36),
+    created_at DATETIME
+);
+
+CREATE TABLE order_reviews (
+    review_id CHAR(36),
+    star integer,
+    review text,
+    organization_id CHAR(36),
+    customer_id CHAR(36),
+    order_type integer,
+    order_id VARCHAR(50),
+    created_at DATETIME
+);
+
+CREATE TABLE organization_bank_accounts (
+    bank_account_id CHAR(36),
+    bank_code VARCHAR(10),
+    account_number VARCHAR(30),
+    account_name VARCHAR(50),
+    merchant_id VARCHAR(50),
+    merchant_nmid VARCHAR(50),
+    merchant_name VARCHAR(150),
+    merchant_mcc VARCHAR(50),
+    merchant_address VARCHAR(255),
+    merchant_city integer,
+    merchant_postal_code VARCHAR(10),
+    account_type integer,
+    organization_id CHAR(36),
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    created_proxy VARCHAR(50),
+    updated_proxy VARCHAR(50),
+    created_ip VARCHAR(50),
+    updated_ip VARCHAR(50),
+    active boolean
+);
+
+CREATE TABLE organization_divisions (
+    division_id CHAR(36),
+    division_name VARCHAR(100),
+    description VARCHAR(255),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    status integer
+);
+
+CREATE TABLE organization_members (
+    member_id CHAR(36),
+    fullname VARCHAR(50),
+    nip VARCHAR(50),
+    nik VARCHAR(16),
+    phone VARCHAR(20),
+    email VARCHAR(50),
+    division_id CHAR(36),
+    "position" VARCHAR(50),
+    npwp VARCHAR(30),
+    bank_code VARCHAR(10),
+    bank_account_number VARCHAR(20),
+    bank_account_name VARCHAR(50),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    active boolean
+);
+
+CREATE TABLE organization_roles (
+    role_id CHAR(36),
+    description VARCHAR(255),
+    role_name VARCHAR(100),
+    division_id CHAR(36),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    status integer
+);
+
+CREATE TABLE organization_types (
+    id integer,
+    name VARCHAR(50)
+);
+
+CREATE TABLE organization_users (
+    uuid CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    organization_role integer NOT NULL,
+    is_active boolean,
+    created_at DATETIME,
+    created_by CHAR(36) NOT NULL,
+    updated_at DATETIME,
+    updated_by CHAR(36) NOT NULL
+);
+
+CREATE TABLE organizations (
+    organization_id CHAR(36) NOT NULL,
+    organization_code VARCHAR(10) NOT NULL,
+    organization_name VARCHAR(255) NOT NULL,
+    company_name VARCHAR(200),
+    phone VARCHAR(20),
+    address VARCHAR(100),
+    city VARCHAR(100),
+    province VARCHAR(30),
+    npwp_number VARCHAR(30),
+    email VARCHAR(50),
+    created_by CHAR(36) NOT NULL,
+    organization_type integer NOT NULL,
+    postal_code VARCHAR(10),
+    organization_icon text,
+    domain_url VARCHAR(100),
+    logo VARCHAR(50),
+    organization_lat VARCHAR(200),
+    organization_lng text,
+    address_label VARCHAR(50),
+    whatsapp VARCHAR(20),
+    created_at DATETIME,
+    updated_at DATETIME
+);
+
+CREATE TABLE payment_midtrans (
+    transaction_id CHAR(36),
+    transaction_status VARCHAR(20),
+    order_id VARCHAR(50),
+    payment_type VARCHAR(50),
+    merchant_id VARCHAR(50),
+    gross_amount DECIMAL(20,2),
+    currency VARCHAR(10),
+    transaction_time DATETIME,
+    payment_status VARCHAR(10),
+    created_at DATETIME
+);
+
+CREATE TABLE payment_orders (
+    payment_id CHAR(36),
+    order_type integer,
+    order_id VARCHAR(50),
+    organization_id CHAR(36),
+    transaction_id CHAR(36),
+    invoice_number VARCHAR(50),
+    payment_type integer,
+    payment_method integer,
+    bank_id VARCHAR(10),
+    bank_account VARCHAR(100),
+    payment_amount DECIMAL(20,2),
+    total_amount DECIMAL(20,2),
+    remaining_amount DECIMAL(20,2),
+    unique_code DECIMAL(20,2),
+    evidence_file VARCHAR(255),
+    notes VARCHAR(100),
+    status integer,
+    payment_status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    settled_at DATETIME,
+    settled_by CHAR(36),
+    refund_at DATETIME,
+    refund_by CHAR(36)
+);
+
+CREATE TABLE preference_cities (
+    preference_id CHAR(36),
+    city_id integer,
+    province_id integer,
+    minimal_day integer,
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36)
+);
+
+CREATE TABLE preference_city_types (
+    preference_type_id CHAR(36),
+    city_id integer,
+    service_type integer,
+    organization_id CHAR(36)
+);
+
+CREATE TABLE schedule_fleet_teams (
+    uuid CHAR(36),
+    schedule_id CHAR(36),
+    unit_id CHAR(36),
+    schedule_fleet_id CHAR(36),
+    driver_id CHAR(36),
+    crew_id CHAR(36),
+    organization_id CHAR(36),
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE schedule_fleets (
+    uuid CHAR(36),
+    schedule_id CHAR(36),
+    schedule_number VARCHAR(20),
+    order_id VARCHAR(100),
+    fleet_id CHAR(36),
+    unit_id CHAR(36),
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    departure_time TIME,
+    organization_id CHAR(36)
+);
+
+CREATE TABLE schedule_teams (
+    schedule_team_id CHAR(36),
+    employee_id CHAR(36),
+    order_id VARCHAR(100),
+    order_type integer,
+    start_date DATETIME,
+    end_date DATETIME,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    status integer,
+    organization_id CHAR(36)
+);
+
+CREATE TABLE schedules (
+    schedule_id CHAR(36),
+    organization_id CHAR(36),
+    order_id VARCHAR(100),
+    order_type integer,
+    departure_time DATETIME,
+    arrival_time DATETIME,
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE supliers (
+    suplier_id CHAR(36),
+    suplier_name VARCHAR(50),
+    suplier_address VARCHAR(200),
+    suplier_city integer,
+    suplier_phone VARCHAR(20),
+    supliter_email VARCHAR(50),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    suplier_url VARCHAR(100)
+);
+
+CREATE TABLE tour_package_addons (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    description VARCHAR(255),
+    price DECIMAL(20,2),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_by CHAR(36),
+    uppdated_at DATETIME
+);
+
+CREATE TABLE tour_package_destinations (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    city_id integer,
+    destination VARCHAR(100),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE tour_package_facilities (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    facility VARCHAR(255),
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME
+);
+
+CREATE TABLE tour_package_images (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    image_path text,
+    created_at DATETIME,
+    created_by CHAR(36)
+);
+
+CREATE TABLE tour_package_itineraries (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    dayx TIME,
+    activity text,
+    city_id integer,
+    day integer,
+    location VARCHAR(100),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE tour_package_order_addons (
+    order_id VARCHAR(100),
+    organization_id CHAR(36),
+    addon_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE tour_package_orders (
+    uuid CHAR(36),
+    order_id VARCHAR(100),
+    tour_package_id CHAR(36),
+    customer_id CHAR(36),
+    start_date DATETIME,
+    end_date DATETIME,
+    total_pax integer,
+    official_pax integer,
+    member_pax integer,
+    discount_amount DECIMAL(20,2),
+    additional_amount DECIMAL(20,2),
+    total_amount DECIMAL(20,2),
+    organization_id CHAR(36),
+    status integer,
+    payment_status integer,
+    pickup_address text,
+    pickup_city_id integer,
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME
+);
+
+CREATE TABLE tour_package_pickup (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    city_id integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE tour_package_prices (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    min_pax integer,
+    max_pax integer,
+    price DECIMAL(20,2),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE tour_package_schedules (
+    uuid CHAR(36),
+    package_id CHAR(36),
+    organization_id CHAR(36),
+    date_start date,
+    date_end date,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    status integer,
+    active integer
+);
+
+CREATE TABLE tour_packages (
+    uuid CHAR(36),
+    package_type integer,
+    package_name VARCHAR(100),
+    package_description text,
+    min_pax integer,
+    max_pax integer,
+    thumbnail VARCHAR(255),
+    duration integer,
+    active boolean,
+    status integer,
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE transaction_fleet_trips (
+    transaction_trip_id CHAR(36),
+    transaction_id CHAR(36),
+    schedule_number VARCHAR(50),
+    reference_id VARCHAR(50),
+    transaction_date date
+    transaction_type integer,
+    transaction_category VARCHAR(10),
+    transaction_item VARCHAR(10),
+    amount DECIMAL(20,2),
+    payment_type integer,
+    description text,
+    status integer,
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE transaction_fleets (
+    transaction_fleet_id CHAR(36),
+    transaction_id CHAR(36),
+    fleet_unit_id CHAR(36),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE transaction_orders (
+    transaction_order_id CHAR(36),
+    transaction_id CHAR(36),
+    order_id VARCHAR(100),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE transaction_refund (
+    refund_id CHAR(36),
+    transaction_id CHAR(36),
+    reference_id VARCHAR(50),
+    description text,
+    amount DECIMAL(20,2),
+    bank_code VARCHAR(10),
+    bank_account VARCHAR(50),
+    bank_account_name VARCHAR(50),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36)
+);
+
+CREATE TABLE transaction_reimbursement (
+    reimburse_id CHAR(36),
+    reference_id VARCHAR(50),
+    organization_id CHAR(36),
+    description text,
+    amount DECIMAL(20,2),
+    status integer,
+    employee_id CHAR(36),
+    payment_method integer,
+    created_at DATETIME,
+    created_by CHAR(36)
+);
+
+CREATE TABLE transaction_types (
+    type_id integer,
+    type_label VARCHAR(255)
+);
+
+CREATE TABLE transactions (
+    transaction_id CHAR(36),
+    transaction_type integer,
+    reference_id VARCHAR(50),
+    order_type integer,
+    transaction_category VARCHAR(10),
+    transaction_item VARCHAR(10),
+    invoice_number VARCHAR(255),
+    description text,
+    transaction_date date,
+    payment_type integer,
+    organization_id CHAR(36),
+    amount DECIMAL(20,2),
+    bank_code VARCHAR(10),
+    bank_account VARCHAR(20),
+    payment_method integer,
+    transaction_label VARCHAR(50),
+    note text,
+    status integer,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE transacton_fleet_trips (
+    transaction_trip_id CHAR(36),
+    transaction_id CHAR(36),
+    schedule_number VARCHAR(50),
+    reference_id VARCHAR(50),
+    transaction_type integer,
+    transaction_category VARCHAR(10),
+    transaction_item VARCHAR(10),
+    amount DECIMAL(20,2),
+    payment_type integer,
+    description text,
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36),
+    organization_id CHAR(36)
+);
+
+CREATE TABLE travego_messages (
+    message_id CHAR(36),
+    topic_id integer,
+    fullname VARCHAR(50),
+    company_name VARCHAR(50),
+    email VARCHAR(50),
+    whatsapp VARCHAR(20),
+    scale VARCHAR(10),
+    messages text,
+    is_read boolean,
+    created_at DATETIME
+);
+
+CREATE TABLE travego_reviews (
+    review_id CHAR(36),
+    user_id CHAR(36),
+    stars integer,
+    review text,
+    created_at DATETIME,
+    created_by CHAR(36)
+);
+
+CREATE TABLE travego_transactions (
+    transaction_id CHAR(36),
+    transaction_date DATETIME,
+    invoice_number VARCHAR(30),
+    package_id VARCHAR(10),
+    start_date DATETIME,
+    expiry_date DATETIME,
+    payment_method VARCHAR(20),
+    payment_amount DECIMAL(20,2),
+    status integer,
+    user_id CHAR(36),
+    organization_id CHAR(36),
+    created_at DATETIME,
+    created_by CHAR(36),
+    updated_at DATETIME,
+    updated_by CHAR(36)
+);
+
+CREATE TABLE travego_visitors (
+    id integer NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    period date NOT NULL,
+    count integer DEFAULT 0
+);
+
+CREATE TABLE users (
+    user_id CHAR(36) NOT NULL,
+    username VARCHAR(50),
+    fullname VARCHAR(100),
+    email VARCHAR(50),
+    password text,
+    phone VARCHAR(20),
+    address VARCHAR(100),
+    city VARCHAR(30),
+    province VARCHAR(30),
+    postal_code VARCHAR(10),
+    npwp VARCHAR(25),
+    date_of_birth DATETIME,
+    gender VARCHAR(2),
+    avatar VARCHAR(255),
+    is_active boolean,
+    is_verified boolean,
+    is_admin boolean,
+    created_at DATETIME,
+    updated_at DATETIME,
+    verified_at DATETIME,
+    last_login DATETIME,
+    deleted_at DATETIME
+);
+
+INSERT INTO bank_list VALUES ('011', 'BANK DANAMON INDONESIA', NULL);
+INSERT INTO bank_list VALUES ('111', 'BANK DKI', NULL);
+INSERT INTO bank_list VALUES ('046', 'BANK DBS INDONESIA', NULL);
+INSERT INTO bank_list VALUES ('087', 'BANK HSBC INDONESIA', NULL);
+INSERT INTO bank_list VALUES ('016', 'BANK MAYBANK INDONESIA, TBK', NULL);
+INSERT INTO bank_list VALUES ('553', 'BANK MAYORA', NULL);
+INSERT INTO bank_list VALUES ('426', 'BANK MEGA, TBK', NULL);
+INSERT INTO bank_list VALUES ('147', 'BANK MUAMALAT INDONESIA, TBK', NULL);
+INSERT INTO bank_list VALUES ('013', 'BANK PERMATA, TBK', NULL);
+INSERT INTO bank_list VALUES ('721', 'BANK PERMATA, TBK UNIT USAHA SYARIAH', NULL);
+INSERT INTO bank_list VALUES ('494', 'BANK RAKYAT INDONESIA AGRONIAGA, TBK', NULL);
+INSERT INTO bank_list VALUES ('213', 'BANK TABUNGAN PENSIUNAN NASIONAL - (BTPN)', NULL);
+INSERT INTO bank_list VALUES ('547', 'BANK TABUNGAN PENSIUNAN NASIONAL SYARIAH - (BTPN Syariah)', NULL);
+INSERT INTO bank_list VALUES ('164', 'BANK ICBC INDONESIA', NULL);
+INSERT INTO bank_list VALUES ('022', 'BANK CIMB NIAGA - (CIMB)', '/assets/bank-icon/cimb.png');
+INSERT INTO bank_list VALUES ('730', 'BANK CIMB NIAGA UNIT USAHA SYARIAH - (CIMB SYARIAH)', '/assets/bank-icon/cimb.png');
+INSERT INTO bank_list VALUES ('536', 'BANK BCA SYARIAH', '/assets/bank-icon/bca.png');
+INSERT INTO bank_list VALUES ('014', 'BANK CENTRAL ASIA, TBK - (BCA)', '/assets/bank-icon/bca.png');
+INSERT INTO bank_list VALUES ('427', 'BNI SYARIAH', '/assets/bank-icon/bni.png');
+INSERT INTO bank_list VALUES ('009', 'BANK NEGARA INDONESIA (PERSERO), TBK (BNI)', '/assets/bank-icon/bni.png');
+INSERT INTO bank_list VALUES ('008', 'BANK MANDIRI (PERSERO), TBK', '/assets/bank-icon/mandiri.png');
+INSERT INTO bank_list VALUES ('564', 'BANK MANDIRI TASPEN POS', '/assets/bank-icon/mandiri.png');
+INSERT INTO bank_list VALUES ('451', 'BANK SYARIAH MANDIRI', '/assets/bank-icon/mandiri.png');
+INSERT INTO bank_list VALUES ('002', 'BANK RAKYAT INDONESIA (PERSERO), TBK (BRI)', '/assets/bank-icon/bri.png');
+INSERT INTO bank_list VALUES ('422', 'BANK SYARIAH BRI - (BRI SYARIAH)', '/assets/bank-icon/bri.png');
+INSERT INTO bank_list VALUES ('200', 'BANK TABUNGAN NEGARA (PERSERO), TBK (BTN)', '/assets/bank-icon/btn.png');
+INSERT INTO bank_list VALUES ('723', 'BANK TABUNGAN NEGARA (PERSERO) SYARIAH (BTN Syariah)', '/assets/bank-icon/btn.png');
+INSERT INTO bank_list VALUES ('028', 'BANK OCBC NISP, TBK', '/assets/bank-icon/ocbc.png');
+INSERT INTO bank_list VALUES ('731', 'BANK OCBC NISP, TBK UNIT USAHA SYARIAH', '/assets/bank-icon/ocbc.png');
+INSERT INTO bank_list VALUES ('441', 'BANK BUKOPIN', '/assets/bank-icon/bukopin.png');
+INSERT INTO bank_list VALUES ('521', 'BANK SYARIAH BUKOPIN', '/assets/bank-icon/bukopin.png');
+
+INSERT INTO employee_leave_type VALUES (1, 'Cuti Reguler');
+INSERT INTO employee_leave_type VALUES (2, 'Cuti / Izin Sakit');
+INSERT INTO employee_leave_type VALUES (3, 'Izin Keluarga Sakit');
+INSERT INTO employee_leave_type VALUES (4, 'Izin berduka');
+
+INSERT INTO facilities VALUES ('95376cd5-23c8-4d12-aeb1-d945aeedc70c', 'Pengemudi dan Pramusapa profesional', 'Smile               ', NULL);
+INSERT INTO facilities VALUES ('93f60d8d-1c77-42eb-88e0-74174b7d2118', 'Power Plug Onboard', 'Cable               ', NULL);
+INSERT INTO facilities VALUES ('ae22ca4e-1c03-4a09-a08a-954e876418f6', 'Support USB Cable', 'Usb                 ', NULL);
+INSERT INTO facilities VALUES ('b757cb1c-2be5-4c0d-b013-102bc9c2021a', 'Termasuk Bahan Bakar', 'Fuel                ', NULL);
+INSERT INTO facilities VALUES ('4ec69470-3ce7-479e-ac2d-5ff03e22b304', 'Pendingin Ruangan (AC)', 'Snowflake           ', NULL);
+INSERT INTO facilities VALUES ('494fe96f-f505-4b89-aa80-56ca7b806faf', 'Air Suspension', 'RockingChair        ', NULL);
+INSERT INTO facilities VALUES ('6c4e2ce1-a582-45c2-b7ed-04d81cb3b2b5', 'Recleaning Seat', 'Armchair            ', NULL);
+INSERT INTO facilities VALUES ('3625ec50-2c12-4583-9223-2933c9096cf7', 'Movies & Entertaint', 'Clapperboard        ', NULL);
+INSERT INTO facilities VALUES ('23324a46-1c62-4ef7-887f-612f469b1720', 'Alat Pemadam Api Ringan)', 'FireExtinguisher    ', NULL);
+INSERT INTO facilities VALUES ('a892dc03-644d-45ed-89b1-653cc2470956', 'Snack & Makanan Ringan', 'Utensils            ', NULL);
+INSERT INTO facilities VALUES ('b3d43245-3811-4fbd-980d-8d6268866328', 'Minuman Ringan', 'GlassWater          ', NULL);
+INSERT INTO facilities VALUES ('14d25ab3-ed1a-4079-9a08-c01fcfd11921', 'Dilindungi Asuransi', 'ShieldCheck         ', NULL);
+INSERT INTO facilities VALUES ('5b391476-cd97-4e20-8cf9-6f92dc39b518', 'Audio Video On Demand (AVOD)', 'Tv                  ', NULL);
+INSERT INTO facilities VALUES ('16fad6ac-9b5e-4dd1-9182-8b5df31d8e0a', 'Tempat Sampah', 'Trash               ', NULL);
+INSERT INTO facilities VALUES ('54483314-a961-4d92-a04a-471f1b3b1884', 'Cooling Box', 'Snowflake           ', NULL);
+INSERT INTO facilities VALUES ('c6b4bd20-c41e-4e30-b7cc-8d4a0e2f5afd', 'Minibar and Dispenser', 'Wine                ', NULL);
+INSERT INTO facilities VALUES ('d50898a8-fa04-48ca-a725-9ceb4408b71d', 'Toilet', 'Toilet              ', NULL);
+INSERT INTO facilities VALUES ('bb98e836-508e-4941-bdd6-e361d80bafb9', 'Music and Karaoke', 'Music               ', NULL);
+
+INSERT INTO fleet_types VALUES ('FT01', 'Minibus');
+INSERT INTO fleet_types VALUES ('FT03', 'Sedan');
+INSERT INTO fleet_types VALUES ('FT04', 'MPV');
+INSERT INTO fleet_types VALUES ('FT05', 'SUV');
+INSERT INTO fleet_types VALUES ('FT06', 'Medium Bus');
+INSERT INTO fleet_types VALUES ('FT07', 'Big Bus');
+INSERT INTO fleet_types VALUES ('FT08', 'Double Decker');
+
+INSERT INTO inventory_movement_types VALUES (1, 'Item Masuk');
+INSERT INTO inventory_movement_types VALUES (2, 'Item Keluar');
+INSERT INTO inventory_movement_types VALUES (3, 'Koreksi stok');
+INSERT INTO inventory_movement_types VALUES (4, 'Transfer Stok');
+
+INSERT INTO organization_divisions VALUES ('7c2a2d70-b542-4607-ba2b-d2087618e3a2', 'Marketing', 'Bertanggung jawab atas strategi pemasaran dan peningkatan volume penjualan.', '00000000-0000-0000-0000-000000000000', '2026-04-15 11:15:33.468247+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', NULL, NULL, 1);
+INSERT INTO organization_divisions VALUES ('4df1996f-dd57-4586-a819-c2fe08107cf4', 'Finance', 'Mengelola administrasi keuangan, arus kas, serta pelaporan akuntansi', '00000000-0000-0000-0000-000000000000', '2026-04-15 11:30:48.521298+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', NULL, NULL, 1);
+INSERT INTO organization_divisions VALUES ('fe8b3916-5eff-420c-8110-8d974d767afe', 'Operations', 'Mengoordinasikan pelaksanaan teknis perjalanan dan pemeliharaan armada operasional', '00000000-0000-0000-0000-000000000000', '2026-04-15 11:31:23.28055+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 16:02:18.752681+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 1);
+
+INSERT INTO organization_roles VALUES ('0dbdb8c5-8edb-40ef-b0e3-3fd3d37daaa8', 'Pengemudi bertanggung jawab atas keselamatan penumpang dan pengoperasian armada kendaraan', 'Driver - Pengemudi', '00000000-0000-0000-0000-000000000000', '2026-04-15 16:21:21.153106+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 16:21:21.153106+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 'fe8b3916-5eff-420c-8110-8d974d767afe', 1);
+INSERT INTO organization_roles VALUES ('94acb1ae-07fa-44d7-b970-16b61d8aed25', 'Melakukan pemeliharaan rutin dan perbaikan teknis guna menjamin kelaikan armada', 'Mekanik', '00000000-0000-0000-0000-000000000000', '2026-04-15 16:22:35.00341+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 19:23:23.796214+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 'fe8b3916-5eff-420c-8110-8d974d767afe', 1);
+INSERT INTO organization_roles VALUES ('dd94c9a7-15fe-49c2-9c76-6e6472be67ec', 'Pemandu perjalanan pariwisata', 'Tour Guide', '00000000-0000-0000-0000-000000000000', '2026-04-15 19:23:42.300177+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 19:23:42.300177+07:00', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 'fe8b3916-5eff-420c-8110-8d974d767afe', 1);
+
+INSERT INTO organization_types VALUES (1, 'Travel Partner');
+INSERT INTO organization_types VALUES (2, 'Biro Perjalanan dan Wisata');
+INSERT INTO organization_types VALUES (3, 'Perusahaan Otobus');
+INSERT INTO organization_types VALUES (4, 'Rental Armada Pariwisata');
+INSERT INTO organization_types VALUES (5, 'Alat Berat');
+INSERT INTO organization_types VALUES (6, 'Angkutan Ekspedisi dan Logistik');
+
+ALTER TABLE bank_list
+    ADD CONSTRAINT bank_list_pkey PRIMARY KEY (code);
+ALTER TABLE travego_visitors
+    ADD CONSTRAINT travego_visitors_period_key UNIQUE (period);
+ALTER TABLE assistant_customer_stats
+    ADD CONSTRAINT unique_custstat_period_org_type_status UNIQUE (period, type, status, organization_id);
+ALTER TABLE facilities
+    ADD CONSTRAINT unique_facility_per_org UNIQUE (organization_id, facility_name);
+ALTER TABLE assistant_account_stats
+    ADD CONSTRAINT unique_stat_period_org_type_status UNIQUE (period, type, status, organization_id);
+ALTER TABLE users
+    ADD CONSTRAINT users_pkey1 PRIMARY KEY (user_id);
+
+CREATE INDEX idx_email_users ON users (email);
+CREATE INDEX idx_organization_users_created_by ON organization_users (created_by);
+CREATE INDEX idx_organization_users_organization_id ON organization_users (organization_id);
+CREATE INDEX idx_organization_users_updated_by ON organization_users (updated_by);
+CREATE INDEX idx_organization_users_user_id ON organization_users (user_id);
+CREATE INDEX idx_organizations_code ON organizations (organization_code);
+CREATE INDEX idx_organizations_created_by ON organizations (created_by);
+
+ALTER TABLE organizations
+    ADD CONSTRAINT organizations_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(user_id);
+
+-- WhatsApp contacts of the assistant (organization_id now matches
+-- organizations.organization_id)
+CREATE TABLE wa_contacts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    phone VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(100),
+    role VARCHAR(50),
+    organization_id CHAR(36) NOT NULL,
+    is_active boolean DEFAULT true,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wa_contacts_organization_id ON wa_contacts (organization_id);
+CREATE INDEX idx_wa_contacts_active ON wa_contacts (is_active);
@@ -1,31 +1,11 @@
+-- Baseline schema: every table the application used before schema changes
+-- were tracked, consolidated from the former pg_dump (db/sql/travego-db.sql)
+-- and database/migrations/001_create_wa_contacts.sql, with the reference data
+-- (banks, fleet types, organization types, ...) a fresh database needs.
+-- It replaces db/migrations/001..004, which the dump had already superseded.
 --
--- PostgreSQL database dump - 28 June 2026
---
-
--- Dumped from database version 16.9
--- Dumped by pg_dump version 16.9
-
--- Started on 2026-06-29 00:49:33
-
-SET statement_timeout = 0;
-SET lock_timeout = 0;
-SET idle_in_transaction_session_timeout = 0;
-SET client_encoding = 'UTF8';
-SET standard_conforming_strings = on;
-SELECT pg_catalog.set_config('search_path', '', false);
-SET check_function_bodies = false;
-SET xmloption = content;
-SET client_min_messages = warning;
-SET row_security = off;
-
-SET default_tablespace = '';
-
-SET default_table_access_method = heap;
-
---
--- TOC entry 275 (class 1259 OID 33985)
--- Name: _assistant; Type: TABLE; Schema: public; Owner: postgres
---
+-- Databases created before migrations were tracked already have this schema;
+-- record it without running it with: go run ./cmd/migrate up -baseline
 
 CREATE TABLE public._assistant (
     account_id uuid,
@@ -37,14 +17,6 @@ CREATE TABLE public._assistant (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public._assistant OWNER TO postgres;
-
---
--- TOC entry 276 (class 1259 OID 33988)
--- Name: _packages; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public._packages (
     package_id character varying(10),
     package_name character varying(20),
@@ -58,14 +30,6 @@ CREATE TABLE public._packages (
     assistant_request_limit numeric
 );
 
-
-ALTER TABLE public._packages OWNER TO postgres;
-
---
--- TOC entry 273 (class 1259 OID 33977)
--- Name: _subscription; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public._subscription (
     subscription_id uuid,
     organization_id uuid,
@@ -79,14 +43,6 @@ CREATE TABLE public._subscription (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public._subscription OWNER TO postgres;
-
---
--- TOC entry 274 (class 1259 OID 33980)
--- Name: _subscription_payment; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public._subscription_payment (
     payment_id uuid,
     invoice_id character varying,
@@ -101,14 +57,6 @@ CREATE TABLE public._subscription_payment (
     merchant_id character varying(20)
 );
 
-
-ALTER TABLE public._subscription_payment OWNER TO postgres;
-
---
--- TOC entry 277 (class 1259 OID 33993)
--- Name: _usage; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public._usage (
     usage_id uuid,
     subscription_id uuid,
@@ -121,14 +69,6 @@ CREATE TABLE public._usage (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public._usage OWNER TO postgres;
-
---
--- TOC entry 310 (class 1259 OID 51594)
--- Name: assistant_account_stats; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.assistant_account_stats (
     statistic_id uuid,
     period date,
@@ -138,14 +78,6 @@ CREATE TABLE public.assistant_account_stats (
     status integer
 );
 
-
-ALTER TABLE public.assistant_account_stats OWNER TO postgres;
-
---
--- TOC entry 287 (class 1259 OID 35036)
--- Name: assistant_accounts; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.assistant_accounts (
     assistant_id uuid,
     organization_id uuid,
@@ -158,14 +90,6 @@ CREATE TABLE public.assistant_accounts (
     status integer
 );
 
-
-ALTER TABLE public.assistant_accounts OWNER TO postgres;
-
---
--- TOC entry 309 (class 1259 OID 51589)
--- Name: assistant_customer_stats; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.assistant_customer_stats (
     statistic_id uuid,
     period date,
@@ -175,14 +99,6 @@ CREATE TABLE public.assistant_customer_stats (
     status integer
 );
 
-
-ALTER TABLE public.assistant_customer_stats OWNER TO postgres;
-
---
--- TOC entry 296 (class 1259 OID 51486)
--- Name: assistant_customers; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.assistant_customers (
     device_id character varying(150),
     device_name character varying(50),
@@ -196,28 +112,12 @@ CREATE TABLE public.assistant_customers (
     updated_by uuid
 );
 
-
-ALTER TABLE public.assistant_customers OWNER TO postgres;
-
---
--- TOC entry 235 (class 1259 OID 25581)
--- Name: bank_list; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.bank_list (
     code character varying(10) NOT NULL,
     name text NOT NULL,
     icon character varying(100)
 );
 
-
-ALTER TABLE public.bank_list OWNER TO postgres;
-
---
--- TOC entry 227 (class 1259 OID 17407)
--- Name: content; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.content (
     uuid uuid,
     section_tag character varying(100),
@@ -234,14 +134,6 @@ CREATE TABLE public.content (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public.content OWNER TO postgres;
-
---
--- TOC entry 228 (class 1259 OID 17412)
--- Name: content_list; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.content_list (
     uuid uuid,
     content_id uuid,
@@ -252,14 +144,6 @@ CREATE TABLE public.content_list (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public.content_list OWNER TO postgres;
-
---
--- TOC entry 250 (class 1259 OID 33862)
--- Name: customer_orders; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.customer_orders (
     order_id character varying(100),
     customer_id uuid,
@@ -271,13 +155,6 @@ CREATE TABLE public.customer_orders (
     organization_id uuid
 );
 
-
-ALTER TABLE public.customer_orders OWNER TO postgres;
---
--- TOC entry 249 (class 1259 OID 33857)
--- Name: customers; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.customers (
     customer_id uuid,
     organization_id uuid,
@@ -294,14 +171,6 @@ CREATE TABLE public.customers (
     updated_by uuid
 );
 
-
-ALTER TABLE public.customers OWNER TO postgres;
-
---
--- TOC entry 255 (class 1259 OID 33882)
--- Name: employee; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.employee (
     uuid uuid,
     employee_id character varying(100),
@@ -325,27 +194,11 @@ CREATE TABLE public.employee (
     updated_by uuid
 );
 
-
-ALTER TABLE public.employee OWNER TO postgres;
-
---
--- TOC entry 266 (class 1259 OID 33927)
--- Name: employee_leave_type; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.employee_leave_type (
     id integer,
     label character varying(50)
 );
 
-
-ALTER TABLE public.employee_leave_type OWNER TO postgres;
-
---
--- TOC entry 265 (class 1259 OID 33924)
--- Name: employee_leaves; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.employee_leaves (
     leave_id uuid,
     organization_id uuid,
@@ -361,14 +214,6 @@ CREATE TABLE public.employee_leaves (
     updated_by uuid
 );
 
-
-ALTER TABLE public.employee_leaves OWNER TO postgres;
-
---
--- TOC entry 264 (class 1259 OID 33921)
--- Name: employee_shift; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.employee_shift (
     shift_id uuid,
     organization_id uuid,
@@ -381,14 +226,6 @@ CREATE TABLE public.employee_shift (
     updated_by uuid
 );
 
-
-ALTER TABLE public.employee_shift OWNER TO postgres;
-
---
--- TOC entry 299 (class 1259 OID 51513)
--- Name: facilities; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.facilities (
     facility_id uuid,
     facility_name character varying(200),
@@ -396,14 +233,6 @@ CREATE TABLE public.facilities (
     organization_id uuid
 );
 
-
-ALTER TABLE public.facilities OWNER TO postgres;
-
---
--- TOC entry 224 (class 1259 OID 17357)
--- Name: fleet_addon; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_addon (
     uuid uuid,
     fleet_id uuid,
@@ -417,14 +246,6 @@ CREATE TABLE public.fleet_addon (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_addon OWNER TO postgres;
-
---
--- TOC entry 300 (class 1259 OID 51517)
--- Name: fleet_facilities; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_facilities (
     uuid uuid,
     fleet_id uuid,
@@ -436,14 +257,6 @@ CREATE TABLE public.fleet_facilities (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_facilities OWNER TO postgres;
-
---
--- TOC entry 226 (class 1259 OID 17365)
--- Name: fleet_images; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_images (
     uuid uuid,
     fleet_id uuid,
@@ -451,14 +264,6 @@ CREATE TABLE public.fleet_images (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_images OWNER TO postgres;
-
---
--- TOC entry 231 (class 1259 OID 25560)
--- Name: fleet_order_addons; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_addons (
     order_addon_id uuid,
     order_id character varying(50),
@@ -469,14 +274,6 @@ CREATE TABLE public.fleet_order_addons (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.fleet_order_addons OWNER TO postgres;
-
---
--- TOC entry 233 (class 1259 OID 25568)
--- Name: fleet_order_customers; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_customers (
     customer_id uuid,
     order_id character varying(50),
@@ -488,14 +285,6 @@ CREATE TABLE public.fleet_order_customers (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.fleet_order_customers OWNER TO postgres;
-
---
--- TOC entry 232 (class 1259 OID 25565)
--- Name: fleet_order_destinations; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_destinations (
     order_destination_id uuid,
     order_id character varying(50),
@@ -504,14 +293,6 @@ CREATE TABLE public.fleet_order_destinations (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.fleet_order_destinations OWNER TO postgres;
-
---
--- TOC entry 283 (class 1259 OID 34061)
--- Name: fleet_order_expenses; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_expenses (
     fleet_expense_id uuid,
     expense_id uuid,
@@ -528,14 +309,6 @@ CREATE TABLE public.fleet_order_expenses (
     updated_by uuid
 );
 
-
-ALTER TABLE public.fleet_order_expenses OWNER TO postgres;
-
---
--- TOC entry 260 (class 1259 OID 33901)
--- Name: fleet_order_items; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_items (
     order_item_id uuid,
     organization_id uuid,
@@ -554,14 +327,6 @@ CREATE TABLE public.fleet_order_items (
     updated_by uuid
 );
 
-
-ALTER TABLE public.fleet_order_items OWNER TO postgres;
-
---
--- TOC entry 251 (class 1259 OID 33865)
--- Name: fleet_order_itinerary; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_itinerary (
     fleet_itinerary_id uuid,
     order_id character varying(100),
@@ -575,14 +340,6 @@ CREATE TABLE public.fleet_order_itinerary (
     updated_by uuid
 );
 
-
-ALTER TABLE public.fleet_order_itinerary OWNER TO postgres;
-
---
--- TOC entry 236 (class 1259 OID 25591)
--- Name: fleet_order_payment; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_order_payment (
     order_payment_id uuid,
     order_id character varying(50),
@@ -602,14 +359,6 @@ CREATE TABLE public.fleet_order_payment (
     approve_by uuid
 );
 
-
-ALTER TABLE public.fleet_order_payment OWNER TO postgres;
-
---
--- TOC entry 230 (class 1259 OID 17444)
--- Name: fleet_orders; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_orders (
     order_id character varying(50),
     fleet_id uuid,
@@ -636,14 +385,6 @@ CREATE TABLE public.fleet_orders (
     updated_by uuid
 );
 
-
-ALTER TABLE public.fleet_orders OWNER TO postgres;
-
---
--- TOC entry 222 (class 1259 OID 17351)
--- Name: fleet_pickup; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_pickup (
     uuid uuid,
     fleet_id uuid,
@@ -655,14 +396,6 @@ CREATE TABLE public.fleet_pickup (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_pickup OWNER TO postgres;
-
---
--- TOC entry 223 (class 1259 OID 17354)
--- Name: fleet_prices; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_prices (
     uuid uuid,
     fleet_id uuid,
@@ -679,14 +412,6 @@ CREATE TABLE public.fleet_prices (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_prices OWNER TO postgres;
-
---
--- TOC entry 239 (class 1259 OID 25612)
--- Name: fleet_prices_history; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_prices_history (
     uuid uuid,
     fleet_id uuid,
@@ -703,27 +428,11 @@ CREATE TABLE public.fleet_prices_history (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_prices_history OWNER TO postgres;
-
---
--- TOC entry 225 (class 1259 OID 17362)
--- Name: fleet_types; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_types (
     id character varying(5),
     label character varying(50)
 );
 
-
-ALTER TABLE public.fleet_types OWNER TO postgres;
-
---
--- TOC entry 278 (class 1259 OID 33996)
--- Name: fleet_unit_ownership; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_unit_ownership (
     fleet_ownership_id uuid,
     unit_id uuid,
@@ -735,14 +444,6 @@ CREATE TABLE public.fleet_unit_ownership (
     organization_id uuid
 );
 
-
-ALTER TABLE public.fleet_unit_ownership OWNER TO postgres;
-
---
--- TOC entry 252 (class 1259 OID 33868)
--- Name: fleet_units; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleet_units (
     unit_id uuid,
     vehicle_id character varying(100),
@@ -761,14 +462,6 @@ CREATE TABLE public.fleet_units (
     updated_by uuid
 );
 
-
-ALTER TABLE public.fleet_units OWNER TO postgres;
-
---
--- TOC entry 221 (class 1259 OID 17343)
--- Name: fleets; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.fleets (
     uuid uuid,
     fleet_name character varying(100),
@@ -792,14 +485,6 @@ CREATE TABLE public.fleets (
     updated_by uuid
 );
 
-
-ALTER TABLE public.fleets OWNER TO postgres;
-
---
--- TOC entry 290 (class 1259 OID 43276)
--- Name: garage; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.garage (
     organization_id uuid,
     garage_id uuid,
@@ -813,14 +498,6 @@ CREATE TABLE public.garage (
     updated_by uuid
 );
 
-
-ALTER TABLE public.garage OWNER TO postgres;
-
---
--- TOC entry 229 (class 1259 OID 17417)
--- Name: hot_offers; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.hot_offers (
     promo_id uuid,
     service_type character varying(10),
@@ -836,14 +513,6 @@ CREATE TABLE public.hot_offers (
     updated_by uuid
 );
 
-
-ALTER TABLE public.hot_offers OWNER TO postgres;
-
---
--- TOC entry 292 (class 1259 OID 43285)
--- Name: inventory_item_garage; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_item_garage (
     item_garage_id uuid,
     item_id uuid,
@@ -856,14 +525,6 @@ CREATE TABLE public.inventory_item_garage (
     updated_by uuid
 );
 
-
-ALTER TABLE public.inventory_item_garage OWNER TO postgres;
-
---
--- TOC entry 297 (class 1259 OID 51489)
--- Name: inventory_item_supliers; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_item_supliers (
     item_id uuid,
     suplier_id uuid,
@@ -872,14 +533,6 @@ CREATE TABLE public.inventory_item_supliers (
     created_by uuid
 );
 
-
-ALTER TABLE public.inventory_item_supliers OWNER TO postgres;
-
---
--- TOC entry 291 (class 1259 OID 43282)
--- Name: inventory_items; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_items (
     item_id uuid,
     organization_id uuid,
@@ -896,14 +549,6 @@ CREATE TABLE public.inventory_items (
     updated_by uuid
 );
 
-
-ALTER TABLE public.inventory_items OWNER TO postgres;
-
---
--- TOC entry 294 (class 1259 OID 43307)
--- Name: inventory_movement; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_movement (
     movement_id uuid,
     item_id uuid,
@@ -918,27 +563,11 @@ CREATE TABLE public.inventory_movement (
     notes character varying(100)
 );
 
-
-ALTER TABLE public.inventory_movement OWNER TO postgres;
-
---
--- TOC entry 295 (class 1259 OID 43310)
--- Name: inventory_movement_types; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_movement_types (
     id integer,
     label character varying
 );
 
-
-ALTER TABLE public.inventory_movement_types OWNER TO postgres;
-
---
--- TOC entry 298 (class 1259 OID 51505)
--- Name: inventory_orders; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_orders (
     request_id character varying(20),
     purchase_id character varying(20),
@@ -959,14 +588,6 @@ CREATE TABLE public.inventory_orders (
     updated_by uuid
 );
 
-
-ALTER TABLE public.inventory_orders OWNER TO postgres;
-
---
--- TOC entry 302 (class 1259 OID 51547)
--- Name: inventory_request; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_request (
     request_id character varying(50),
     item_category integer,
@@ -989,14 +610,6 @@ CREATE TABLE public.inventory_request (
     received_by uuid
 );
 
-
-ALTER TABLE public.inventory_request OWNER TO postgres;
-
---
--- TOC entry 301 (class 1259 OID 51539)
--- Name: inventory_request_fleets; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.inventory_request_fleets (
     request_id character varying(50),
     unit_id uuid,
@@ -1006,14 +619,6 @@ CREATE TABLE public.inventory_request_fleets (
     updated_by uuid
 );
 
-
-ALTER TABLE public.inventory_request_fleets OWNER TO postgres;
-
---
--- TOC entry 271 (class 1259 OID 33951)
--- Name: messages; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.messages (
     message_id uuid,
     customer_name character varying(100),
@@ -1027,14 +632,6 @@ CREATE TABLE public.messages (
     organization_id uuid
 );
 
-
-ALTER TABLE public.messages OWNER TO postgres;
-
---
--- TOC entry 286 (class 1259 OID 35031)
--- Name: notifications; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.notifications (
     notification_id uuid,
     organization_id uuid,
@@ -1045,14 +642,6 @@ CREATE TABLE public.notifications (
     is_read boolean
 );
 
-
-ALTER TABLE public.notifications OWNER TO postgres;
-
---
--- TOC entry 279 (class 1259 OID 33999)
--- Name: operation_partner; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.operation_partner (
     partner_id uuid,
     partner_name character varying(50),
@@ -1068,14 +657,6 @@ CREATE TABLE public.operation_partner (
     organization_id uuid
 );
 
-
-ALTER TABLE public.operation_partner OWNER TO postgres;
-
---
--- TOC entry 237 (class 1259 OID 25596)
--- Name: order_payment_history; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.order_payment_history (
     payment_history_id uuid,
     order_id character varying(50),
@@ -1089,14 +670,6 @@ CREATE TABLE public.order_payment_history (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.order_payment_history OWNER TO postgres;
-
---
--- TOC entry 272 (class 1259 OID 33961)
--- Name: order_reviews; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.order_reviews (
     review_id uuid,
     star integer,
@@ -1108,14 +681,6 @@ CREATE TABLE public.order_reviews (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.order_reviews OWNER TO postgres;
-
---
--- TOC entry 234 (class 1259 OID 25576)
--- Name: organization_bank_accounts; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organization_bank_accounts (
     bank_account_id uuid,
     bank_code character varying(10),
@@ -1142,14 +707,6 @@ CREATE TABLE public.organization_bank_accounts (
     active boolean
 );
 
-
-ALTER TABLE public.organization_bank_accounts OWNER TO postgres;
-
---
--- TOC entry 254 (class 1259 OID 33879)
--- Name: organization_divisions; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organization_divisions (
     division_id uuid,
     division_name character varying(100),
@@ -1162,14 +719,6 @@ CREATE TABLE public.organization_divisions (
     status integer
 );
 
-
-ALTER TABLE public.organization_divisions OWNER TO postgres;
-
---
--- TOC entry 238 (class 1259 OID 25609)
--- Name: organization_members; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organization_members (
     member_id uuid,
     fullname character varying(50),
@@ -1191,14 +740,6 @@ CREATE TABLE public.organization_members (
     active boolean
 );
 
-
-ALTER TABLE public.organization_members OWNER TO postgres;
-
---
--- TOC entry 253 (class 1259 OID 33876)
--- Name: organization_roles; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organization_roles (
     role_id uuid,
     description character varying(255),
@@ -1212,27 +753,11 @@ CREATE TABLE public.organization_roles (
     status integer
 );
 
-
-ALTER TABLE public.organization_roles OWNER TO postgres;
-
---
--- TOC entry 220 (class 1259 OID 17312)
--- Name: organization_types; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organization_types (
     id integer,
     name character varying(50)
 );
 
-
-ALTER TABLE public.organization_types OWNER TO postgres;
-
---
--- TOC entry 218 (class 1259 OID 17257)
--- Name: organization_users; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organization_users (
     uuid uuid NOT NULL,
     user_id uuid NOT NULL,
@@ -1245,14 +770,6 @@ CREATE TABLE public.organization_users (
     updated_by uuid NOT NULL
 );
 
-
-ALTER TABLE public.organization_users OWNER TO postgres;
-
---
--- TOC entry 217 (class 1259 OID 17241)
--- Name: organizations; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.organizations (
     organization_id uuid NOT NULL,
     organization_code character varying(10) NOT NULL,
@@ -1278,14 +795,6 @@ CREATE TABLE public.organizations (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public.organizations OWNER TO postgres;
-
---
--- TOC entry 270 (class 1259 OID 33946)
--- Name: payment_midtrans; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.payment_midtrans (
     transaction_id uuid,
     transaction_status character varying(20),
@@ -1299,14 +808,6 @@ CREATE TABLE public.payment_midtrans (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.payment_midtrans OWNER TO postgres;
-
---
--- TOC entry 256 (class 1259 OID 33887)
--- Name: payment_orders; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.payment_orders (
     payment_id uuid,
     order_type integer,
@@ -1336,14 +837,6 @@ CREATE TABLE public.payment_orders (
     refund_by uuid
 );
 
-
-ALTER TABLE public.payment_orders OWNER TO postgres;
-
---
--- TOC entry 280 (class 1259 OID 34003)
--- Name: preference_cities; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.preference_cities (
     preference_id uuid,
     city_id integer,
@@ -1354,14 +847,6 @@ CREATE TABLE public.preference_cities (
     created_by uuid
 );
 
-
-ALTER TABLE public.preference_cities OWNER TO postgres;
-
---
--- TOC entry 281 (class 1259 OID 34006)
--- Name: preference_city_types; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.preference_city_types (
     preference_type_id uuid,
     city_id integer,
@@ -1369,14 +854,6 @@ CREATE TABLE public.preference_city_types (
     organization_id uuid
 );
 
-
-ALTER TABLE public.preference_city_types OWNER TO postgres;
-
---
--- TOC entry 259 (class 1259 OID 33898)
--- Name: schedule_fleet_teams; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.schedule_fleet_teams (
     uuid uuid,
     schedule_id uuid,
@@ -1392,14 +869,6 @@ CREATE TABLE public.schedule_fleet_teams (
     updated_by uuid
 );
 
-
-ALTER TABLE public.schedule_fleet_teams OWNER TO postgres;
-
---
--- TOC entry 258 (class 1259 OID 33895)
--- Name: schedule_fleets; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.schedule_fleets (
     uuid uuid,
     schedule_id uuid,
@@ -1413,17 +882,9 @@ CREATE TABLE public.schedule_fleets (
     updated_at timestamp with time zone,
     updated_by uuid,
     departure_time time with time zone,
-    organization_id uuid,
+    organization_id uuid
 );
 
-
-ALTER TABLE public.schedule_fleets OWNER TO postgres;
-
---
--- TOC entry 261 (class 1259 OID 33909)
--- Name: schedule_teams; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.schedule_teams (
     schedule_team_id uuid,
     employee_id uuid,
@@ -1439,14 +900,6 @@ CREATE TABLE public.schedule_teams (
     organization_id uuid
 );
 
-
-ALTER TABLE public.schedule_teams OWNER TO postgres;
-
---
--- TOC entry 257 (class 1259 OID 33892)
--- Name: schedules; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.schedules (
     schedule_id uuid,
     organization_id uuid,
@@ -1461,14 +914,6 @@ CREATE TABLE public.schedules (
     updated_by uuid
 );
 
-
-ALTER TABLE public.schedules OWNER TO postgres;
-
---
--- TOC entry 293 (class 1259 OID 43299)
--- Name: supliers; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.supliers (
     suplier_id uuid,
     suplier_name character varying(50),
@@ -1483,14 +928,6 @@ CREATE TABLE public.supliers (
     suplier_url character varying(100)
 );
 
-
-ALTER TABLE public.supliers OWNER TO postgres;
-
---
--- TOC entry 247 (class 1259 OID 25668)
--- Name: tour_package_addons; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_addons (
     uuid uuid,
     package_id uuid,
@@ -1503,14 +940,6 @@ CREATE TABLE public.tour_package_addons (
     uppdated_at timestamp with time zone
 );
 
-
-ALTER TABLE public.tour_package_addons OWNER TO postgres;
-
---
--- TOC entry 244 (class 1259 OID 25657)
--- Name: tour_package_destinations; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_destinations (
     uuid uuid,
     package_id uuid,
@@ -1523,14 +952,6 @@ CREATE TABLE public.tour_package_destinations (
     updated_by uuid
 );
 
-
-ALTER TABLE public.tour_package_destinations OWNER TO postgres;
-
---
--- TOC entry 242 (class 1259 OID 25650)
--- Name: tour_package_facilities; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_facilities (
     uuid uuid,
     package_id uuid,
@@ -1542,14 +963,6 @@ CREATE TABLE public.tour_package_facilities (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public.tour_package_facilities OWNER TO postgres;
-
---
--- TOC entry 248 (class 1259 OID 25680)
--- Name: tour_package_images; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_images (
     uuid uuid,
     package_id uuid,
@@ -1559,14 +972,6 @@ CREATE TABLE public.tour_package_images (
     created_by uuid
 );
 
-
-ALTER TABLE public.tour_package_images OWNER TO postgres;
-
---
--- TOC entry 245 (class 1259 OID 25660)
--- Name: tour_package_itineraries; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_itineraries (
     uuid uuid,
     package_id uuid,
@@ -1582,14 +987,6 @@ CREATE TABLE public.tour_package_itineraries (
     updated_by uuid
 );
 
-
-ALTER TABLE public.tour_package_itineraries OWNER TO postgres;
-
---
--- TOC entry 263 (class 1259 OID 33917)
--- Name: tour_package_order_addons; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_order_addons (
     order_id character varying(100),
     organization_id uuid,
@@ -1600,14 +997,6 @@ CREATE TABLE public.tour_package_order_addons (
     updated_by uuid
 );
 
-
-ALTER TABLE public.tour_package_order_addons OWNER TO postgres;
-
---
--- TOC entry 262 (class 1259 OID 33912)
--- Name: tour_package_orders; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_orders (
     uuid uuid,
     order_id character varying(100),
@@ -1632,14 +1021,6 @@ CREATE TABLE public.tour_package_orders (
     updated_at timestamp with time zone
 );
 
-
-ALTER TABLE public.tour_package_orders OWNER TO postgres;
-
---
--- TOC entry 241 (class 1259 OID 25647)
--- Name: tour_package_pickup; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_pickup (
     uuid uuid,
     package_id uuid,
@@ -1651,14 +1032,6 @@ CREATE TABLE public.tour_package_pickup (
     organization_id uuid
 );
 
-
-ALTER TABLE public.tour_package_pickup OWNER TO postgres;
-
---
--- TOC entry 243 (class 1259 OID 25653)
--- Name: tour_package_prices; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_prices (
     uuid uuid,
     package_id uuid,
@@ -1672,14 +1045,6 @@ CREATE TABLE public.tour_package_prices (
     updated_by uuid
 );
 
-
-ALTER TABLE public.tour_package_prices OWNER TO postgres;
-
---
--- TOC entry 246 (class 1259 OID 25665)
--- Name: tour_package_schedules; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_package_schedules (
     uuid uuid,
     package_id uuid,
@@ -1694,14 +1059,6 @@ CREATE TABLE public.tour_package_schedules (
     active integer
 );
 
-
-ALTER TABLE public.tour_package_schedules OWNER TO postgres;
-
---
--- TOC entry 240 (class 1259 OID 25642)
--- Name: tour_packages; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.tour_packages (
     uuid uuid,
     package_type integer,
@@ -1720,14 +1077,6 @@ CREATE TABLE public.tour_packages (
     updated_by uuid
 );
 
-
-ALTER TABLE public.tour_packages OWNER TO postgres;
-
---
--- TOC entry 284 (class 1259 OID 34977)
--- Name: transaction_fleet_trips; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transaction_fleet_trips (
     transaction_trip_id uuid,
     transaction_id uuid,
@@ -1748,14 +1097,6 @@ CREATE TABLE public.transaction_fleet_trips (
     updated_by uuid
 );
 
-
-ALTER TABLE public.transaction_fleet_trips OWNER TO postgres;
-
---
--- TOC entry 268 (class 1259 OID 33940)
--- Name: transaction_fleets; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transaction_fleets (
     transaction_fleet_id uuid,
     transaction_id uuid,
@@ -1767,14 +1108,6 @@ CREATE TABLE public.transaction_fleets (
     updated_by uuid
 );
 
-
-ALTER TABLE public.transaction_fleets OWNER TO postgres;
-
---
--- TOC entry 269 (class 1259 OID 33943)
--- Name: transaction_orders; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transaction_orders (
     transaction_order_id uuid,
     transaction_id uuid,
@@ -1786,14 +1119,6 @@ CREATE TABLE public.transaction_orders (
     updated_by uuid
 );
 
-
-ALTER TABLE public.transaction_orders OWNER TO postgres;
-
---
--- TOC entry 289 (class 1259 OID 35089)
--- Name: transaction_refund; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transaction_refund (
     refund_id uuid,
     transaction_id uuid,
@@ -1808,14 +1133,6 @@ CREATE TABLE public.transaction_refund (
     created_by uuid
 );
 
-
-ALTER TABLE public.transaction_refund OWNER TO postgres;
-
---
--- TOC entry 288 (class 1259 OID 35084)
--- Name: transaction_reimbursement; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transaction_reimbursement (
     reimburse_id uuid,
     reference_id character varying(50),
@@ -1829,27 +1146,11 @@ CREATE TABLE public.transaction_reimbursement (
     created_by uuid
 );
 
-
-ALTER TABLE public.transaction_reimbursement OWNER TO postgres;
-
---
--- TOC entry 267 (class 1259 OID 33935)
--- Name: transaction_types; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transaction_types (
     type_id integer,
     type_label character varying
 );
 
-
-ALTER TABLE public.transaction_types OWNER TO postgres;
-
---
--- TOC entry 282 (class 1259 OID 34027)
--- Name: transactions; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transactions (
     transaction_id uuid,
     transaction_type integer,
@@ -1875,14 +1176,6 @@ CREATE TABLE public.transactions (
     updated_by uuid
 );
 
-
-ALTER TABLE public.transactions OWNER TO postgres;
-
---
--- TOC entry 285 (class 1259 OID 34987)
--- Name: transacton_fleet_trips; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.transacton_fleet_trips (
     transaction_trip_id uuid,
     transaction_id uuid,
@@ -1901,14 +1194,6 @@ CREATE TABLE public.transacton_fleet_trips (
     organization_id uuid
 );
 
-
-ALTER TABLE public.transacton_fleet_trips OWNER TO postgres;
-
---
--- TOC entry 305 (class 1259 OID 51563)
--- Name: travego_messages; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.travego_messages (
     message_id uuid,
     topic_id integer,
@@ -1922,14 +1207,6 @@ CREATE TABLE public.travego_messages (
     created_at timestamp with time zone
 );
 
-
-ALTER TABLE public.travego_messages OWNER TO postgres;
-
---
--- TOC entry 304 (class 1259 OID 51558)
--- Name: travego_reviews; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.travego_reviews (
     review_id uuid,
     user_id uuid,
@@ -1939,14 +1216,6 @@ CREATE TABLE public.travego_reviews (
     created_by uuid
 );
 
-
-ALTER TABLE public.travego_reviews OWNER TO postgres;
-
---
--- TOC entry 306 (class 1259 OID 51568)
--- Name: travego_transactions; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.travego_transactions (
     transaction_id uuid,
     transaction_date timestamp with time zone,
@@ -1965,28 +1234,12 @@ CREATE TABLE public.travego_transactions (
     updated_by uuid
 );
 
-
-ALTER TABLE public.travego_transactions OWNER TO postgres;
-
---
--- TOC entry 308 (class 1259 OID 51576)
--- Name: travego_visitors; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.travego_visitors (
     id integer NOT NULL,
     period date NOT NULL,
     count integer DEFAULT 0
 );
 
-
-ALTER TABLE public.travego_visitors OWNER TO postgres;
-
---
--- TOC entry 307 (class 1259 OID 51575)
--- Name: travego_visitors_id_seq; Type: SEQUENCE; Schema: public; Owner: postgres
---
-
 CREATE SEQUENCE public.travego_visitors_id_seq
     AS integer
     START WITH 1
@@ -1995,23 +1248,8 @@ CREATE SEQUENCE public.travego_visitors_id_seq
     NO MAXVALUE
     CACHE 1;
 
-
-ALTER SEQUENCE public.travego_visitors_id_seq OWNER TO postgres;
-
---
--- TOC entry 5315 (class 0 OID 0)
--- Dependencies: 307
--- Name: travego_visitors_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: postgres
---
-
 ALTER SEQUENCE public.travego_visitors_id_seq OWNED BY public.travego_visitors.id;
 
-
---
--- TOC entry 219 (class 1259 OID 17299)
--- Name: users; Type: TABLE; Schema: public; Owner: postgres
---
-
 CREATE TABLE public.users (
     user_id uuid NOT NULL,
     username character varying(50),
@@ -2037,35 +1275,8 @@ CREATE TABLE public.users (
     deleted_at timestamp with time zone
 );
 
-
-ALTER TABLE public.users OWNER TO postgres;
-
---
--- TOC entry 5050 (class 2604 OID 51579)
--- Name: travego_visitors id; Type: DEFAULT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.travego_visitors ALTER COLUMN id SET DEFAULT nextval('public.travego_visitors_id_seq'::regclass);
 
-
---
--- TOC entry 5275 (class 0 OID 33985)
--- Dependencies: 275
--- Data for Name: _assistant; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
---
--- TOC entry 5274 (class 0 OID 33980)
--- Dependencies: 274
--- Data for Name: _subscription_payment; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
---
--- TOC entry 5235 (class 0 OID 25581)
--- Dependencies: 235
--- Data for Name: bank_list; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.bank_list VALUES ('011', 'BANK DANAMON INDONESIA', NULL);
 INSERT INTO public.bank_list VALUES ('111', 'BANK DKI', NULL);
 INSERT INTO public.bank_list VALUES ('046', 'BANK DBS INDONESIA', NULL);
@@ -2098,23 +1309,11 @@ INSERT INTO public.bank_list VALUES ('731', 'BANK OCBC NISP, TBK UNIT USAHA SYAR
 INSERT INTO public.bank_list VALUES ('441', 'BANK BUKOPIN', '/assets/bank-icon/bukopin.png');
 INSERT INTO public.bank_list VALUES ('521', 'BANK SYARIAH BUKOPIN', '/assets/bank-icon/bukopin.png');
 
---
--- TOC entry 5266 (class 0 OID 33927)
--- Dependencies: 266
--- Data for Name: employee_leave_type; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.employee_leave_type VALUES (1, 'Cuti Reguler');
 INSERT INTO public.employee_leave_type VALUES (2, 'Cuti / Izin Sakit');
 INSERT INTO public.employee_leave_type VALUES (3, 'Izin Keluarga Sakit');
 INSERT INTO public.employee_leave_type VALUES (4, 'Izin berduka');
 
---
--- TOC entry 5299 (class 0 OID 51513)
--- Dependencies: 299
--- Data for Name: facilities; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.facilities VALUES ('95376cd5-23c8-4d12-aeb1-d945aeedc70c', 'Pengemudi dan Pramusapa profesional', 'Smile               ', NULL);
 INSERT INTO public.facilities VALUES ('93f60d8d-1c77-42eb-88e0-74174b7d2118', 'Power Plug Onboard', 'Cable               ', NULL);
 INSERT INTO public.facilities VALUES ('ae22ca4e-1c03-4a09-a08a-954e876418f6', 'Support USB Cable', 'Usb                 ', NULL);
@@ -2134,20 +1333,6 @@ INSERT INTO public.facilities VALUES ('c6b4bd20-c41e-4e30-b7cc-8d4a0e2f5afd', 'M
 INSERT INTO public.facilities VALUES ('d50898a8-fa04-48ca-a725-9ceb4408b71d', 'Toilet', 'Toilet              ', NULL);
 INSERT INTO public.facilities VALUES ('bb98e836-508e-4941-bdd6-e361d80bafb9', 'Music and Karaoke', 'Music               ', NULL);
 
---
--- TOC entry 5239 (class 0 OID 25612)
--- Dependencies: 239
--- Data for Name: fleet_prices_history; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
-
-
---
--- TOC entry 5225 (class 0 OID 17362)
--- Dependencies: 225
--- Data for Name: fleet_types; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.fleet_types VALUES ('FT01', 'Minibus');
 INSERT INTO public.fleet_types VALUES ('FT03', 'Sedan');
 INSERT INTO public.fleet_types VALUES ('FT04', 'MPV');
@@ -2156,54 +1341,19 @@ INSERT INTO public.fleet_types VALUES ('FT06', 'Medium Bus');
 INSERT INTO public.fleet_types VALUES ('FT07', 'Big Bus');
 INSERT INTO public.fleet_types VALUES ('FT08', 'Double Decker');
 
---
--- TOC entry 5295 (class 0 OID 43310)
--- Dependencies: 295
--- Data for Name: inventory_movement_types; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.inventory_movement_types VALUES (1, 'Item Masuk');
 INSERT INTO public.inventory_movement_types VALUES (2, 'Item Keluar');
 INSERT INTO public.inventory_movement_types VALUES (3, 'Koreksi stok');
 INSERT INTO public.inventory_movement_types VALUES (4, 'Transfer Stok');
 
-
---
--- TOC entry 5254 (class 0 OID 33879)
--- Dependencies: 254
--- Data for Name: organization_divisions; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.organization_divisions VALUES ('7c2a2d70-b542-4607-ba2b-d2087618e3a2', 'Marketing', 'Bertanggung jawab atas strategi pemasaran dan peningkatan volume penjualan.', '00000000-0000-0000-0000-000000000000', '2026-04-15 11:15:33.468247+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', NULL, NULL, 1);
 INSERT INTO public.organization_divisions VALUES ('4df1996f-dd57-4586-a819-c2fe08107cf4', 'Finance', 'Mengelola administrasi keuangan, arus kas, serta pelaporan akuntansi', '00000000-0000-0000-0000-000000000000', '2026-04-15 11:30:48.521298+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', NULL, NULL, 1);
 INSERT INTO public.organization_divisions VALUES ('fe8b3916-5eff-420c-8110-8d974d767afe', 'Operations', 'Mengoordinasikan pelaksanaan teknis perjalanan dan pemeliharaan armada operasional', '00000000-0000-0000-0000-000000000000', '2026-04-15 11:31:23.28055+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 16:02:18.752681+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 1);
 
-
---
--- TOC entry 5238 (class 0 OID 25609)
--- Dependencies: 238
--- Data for Name: organization_members; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
-
-
---
--- TOC entry 5253 (class 0 OID 33876)
--- Dependencies: 253
--- Data for Name: organization_roles; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.organization_roles VALUES ('0dbdb8c5-8edb-40ef-b0e3-3fd3d37daaa8', 'Pengemudi bertanggung jawab atas keselamatan penumpang dan pengoperasian armada kendaraan', 'Driver - Pengemudi', '00000000-0000-0000-0000-000000000000', '2026-04-15 16:21:21.153106+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 16:21:21.153106+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 'fe8b3916-5eff-420c-8110-8d974d767afe', 1);
 INSERT INTO public.organization_roles VALUES ('94acb1ae-07fa-44d7-b970-16b61d8aed25', 'Melakukan pemeliharaan rutin dan perbaikan teknis guna menjamin kelaikan armada', 'Mekanik', '00000000-0000-0000-0000-000000000000', '2026-04-15 16:22:35.00341+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 19:23:23.796214+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 'fe8b3916-5eff-420c-8110-8d974d767afe', 1);
 INSERT INTO public.organization_roles VALUES ('dd94c9a7-15fe-49c2-9c76-6e6472be67ec', 'Pemandu perjalanan pariwisata', 'Tour Guide', '00000000-0000-0000-0000-000000000000', '2026-04-15 19:23:42.300177+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', '2026-04-15 19:23:42.300177+07', '0cf12050-4ce1-44ac-855e-44110aecb6f6', 'fe8b3916-5eff-420c-8110-8d974d767afe', 1);
 
-
---
--- TOC entry 5220 (class 0 OID 17312)
--- Dependencies: 220
--- Data for Name: organization_types; Type: TABLE DATA; Schema: public; Owner: postgres
---
-
 INSERT INTO public.organization_types VALUES (1, 'Travel Partner');
 INSERT INTO public.organization_types VALUES (2, 'Biro Perjalanan dan Wisata');
 INSERT INTO public.organization_types VALUES (3, 'Perusahaan Otobus');
@@ -2211,146 +1361,44 @@ INSERT INTO public.organization_types VALUES (4, 'Rental Armada Pariwisata');
 INSERT INTO public.organization_types VALUES (5, 'Alat Berat');
 INSERT INTO public.organization_types VALUES (6, 'Angkutan Ekspedisi dan Logistik');
 
---
--- TOC entry 5316 (class 0 OID 0)
--- Dependencies: 307
--- Name: travego_visitors_id_seq; Type: SEQUENCE SET; Schema: public; Owner: postgres
---
-
-SELECT pg_catalog.setval('public.travego_visitors_id_seq', 26, true);
-
-
---
--- TOC entry 5062 (class 2606 OID 25587)
--- Name: bank_list bank_list_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.bank_list
     ADD CONSTRAINT bank_list_pkey PRIMARY KEY (code);
-
-
---
--- TOC entry 5066 (class 2606 OID 51584)
--- Name: travego_visitors travego_visitors_period_key; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.travego_visitors
     ADD CONSTRAINT travego_visitors_period_key UNIQUE (period);
-
-
---
--- TOC entry 5068 (class 2606 OID 51582)
--- Name: travego_visitors travego_visitors_pkey; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.travego_visitors
     ADD CONSTRAINT travego_visitors_pkey PRIMARY KEY (id);
-
-
---
--- TOC entry 5070 (class 2606 OID 51600)
--- Name: assistant_customer_stats unique_custstat_period_org_type_status; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.assistant_customer_stats
     ADD CONSTRAINT unique_custstat_period_org_type_status UNIQUE (period, type, status, organization_id);
-
-
---
--- TOC entry 5064 (class 2606 OID 51588)
--- Name: facilities unique_facility_per_org; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.facilities
     ADD CONSTRAINT unique_facility_per_org UNIQUE (organization_id, facility_name);
-
-
---
--- TOC entry 5072 (class 2606 OID 51598)
--- Name: assistant_account_stats unique_stat_period_org_type_status; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.assistant_account_stats
     ADD CONSTRAINT unique_stat_period_org_type_status UNIQUE (period, type, status, organization_id);
-
-
---
--- TOC entry 5060 (class 2606 OID 17305)
--- Name: users users_pkey1; Type: CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_pkey1 PRIMARY KEY (user_id);
 
-
---
--- TOC entry 5058 (class 1259 OID 17306)
--- Name: idx_email_users; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_email_users ON public.users USING btree (email);
-
-
---
--- TOC entry 5054 (class 1259 OID 17274)
--- Name: idx_organization_users_created_by; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_organization_users_created_by ON public.organization_users USING btree (created_by);
-
-
---
--- TOC entry 5055 (class 1259 OID 17273)
--- Name: idx_organization_users_organization_id; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_organization_users_organization_id ON public.organization_users USING btree (organization_id);
-
-
---
--- TOC entry 5056 (class 1259 OID 17275)
--- Name: idx_organization_users_updated_by; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_organization_users_updated_by ON public.organization_users USING btree (updated_by);
-
-
---
--- TOC entry 5057 (class 1259 OID 17272)
--- Name: idx_organization_users_user_id; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_organization_users_user_id ON public.organization_users USING btree (user_id);
-
-
---
--- TOC entry 5052 (class 1259 OID 17255)
--- Name: idx_organizations_code; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_organizations_code ON public.organizations USING btree (organization_code);
-
-
---
--- TOC entry 5053 (class 1259 OID 17256)
--- Name: idx_organizations_created_by; Type: INDEX; Schema: public; Owner: postgres
---
-
 CREATE INDEX idx_organizations_created_by ON public.organizations USING btree (created_by);
 
-
---
--- TOC entry 5073 (class 2606 OID 17338)
--- Name: organizations organizations_created_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: postgres
---
-
 ALTER TABLE ONLY public.organizations
     ADD CONSTRAINT organizations_created_by_fkey FOREIGN KEY (created_by) REFERENCES public.users(user_id);
 
+-- WhatsApp contacts of the assistant (organization_id now matches
+-- organizations.organization_id)
+CREATE TABLE public.wa_contacts (
+    id bigserial PRIMARY KEY,
+    phone character varying(20) UNIQUE NOT NULL,
+    name character varying(100),
+    role character varying(50),
+    organization_id uuid NOT NULL,
+    is_active boolean DEFAULT true,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
 
--- Completed on 2026-06-29 00:49:33
-
---
--- PostgreSQL database dump complete
---
-
+CREATE INDEX idx_wa_contacts_organization_id ON public.wa_contacts USING btree (organization_id);
+CREATE INDEX idx_wa_contacts_active ON public.wa_contacts USING btree (is_active);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS fleet_unit_maintenance_parts;
+DROP TABLE IF EXISTS fleet_unit_maintenance;
+DROP TABLE IF EXISTS fleet_unit_service_plans;
+DROP TABLE IF EXISTS fleet_unit_odometer;
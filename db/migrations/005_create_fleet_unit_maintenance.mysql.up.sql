@@ -0,0 +1,76 @@
+-- Migration: Fleet unit maintenance (odometer, service plans, work orders)
+-- Description: Odometer readings, km/time based service plans and maintenance
+-- work orders tied to a garage with parts drawn from inventory.
+
+CREATE TABLE IF NOT EXISTS fleet_unit_odometer (
+    reading_id CHAR(36) NOT NULL,
+    unit_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    odometer_km bigint NOT NULL,
+    recorded_at DATETIME NOT NULL,
+    notes VARCHAR(255),
+    created_by CHAR(36),
+    created_at DATETIME,
+    PRIMARY KEY (reading_id)
+);
+
+CREATE INDEX idx_fleet_unit_odometer_unit ON fleet_unit_odometer(organization_id, unit_id, recorded_at);
+
+CREATE TABLE IF NOT EXISTS fleet_unit_service_plans (
+    plan_id CHAR(36) NOT NULL,
+    unit_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    service_type VARCHAR(20) NOT NULL,
+    interval_km bigint DEFAULT 0,
+    interval_days integer DEFAULT 0,
+    last_service_km bigint DEFAULT 0,
+    last_service_date DATETIME,
+    next_due_km bigint DEFAULT 0,
+    next_due_date DATETIME,
+    notes VARCHAR(255),
+    status integer DEFAULT 1,
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    PRIMARY KEY (plan_id)
+);
+
+CREATE INDEX idx_fleet_unit_service_plans_unit ON fleet_unit_service_plans(organization_id, unit_id);
+
+-- status: 0 = cancelled, 1 = scheduled, 2 = in workshop, 3 = completed
+CREATE TABLE IF NOT EXISTS fleet_unit_maintenance (
+    maintenance_id CHAR(36) NOT NULL,
+    unit_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    garage_id CHAR(36) NOT NULL,
+    plan_id CHAR(36),
+    service_type VARCHAR(20) NOT NULL,
+    description VARCHAR(255),
+    odometer_km bigint DEFAULT 0,
+    start_date DATETIME NOT NULL,
+    end_date DATETIME NOT NULL,
+    completed_date DATETIME,
+    status integer NOT NULL DEFAULT 1,
+    parts_cost DECIMAL(20,2) DEFAULT 0,
+    labor_cost DECIMAL(20,2) DEFAULT 0,
+    notes VARCHAR(255),
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    PRIMARY KEY (maintenance_id)
+);
+
+CREATE INDEX idx_fleet_unit_maintenance_unit ON fleet_unit_maintenance(organization_id, unit_id, status);
+
+CREATE TABLE IF NOT EXISTS fleet_unit_maintenance_parts (
+    maintenance_id CHAR(36) NOT NULL,
+    item_id CHAR(36) NOT NULL,
+    quantity integer NOT NULL,
+    price DECIMAL(20,2) DEFAULT 0,
+    organization_id CHAR(36) NOT NULL,
+    created_at DATETIME
+);
+
+CREATE INDEX idx_fleet_unit_maintenance_parts_maintenance ON fleet_unit_maintenance_parts(maintenance_id);
@@ -0,0 +1,76 @@
+-- Migration: Fleet unit maintenance (odometer, service plans, work orders)
+-- Description: Odometer readings, km/time based service plans and maintenance
+-- work orders tied to a garage with parts drawn from inventory.
+
+CREATE TABLE IF NOT EXISTS fleet_unit_odometer (
+    reading_id uuid NOT NULL,
+    unit_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    odometer_km bigint NOT NULL,
+    recorded_at timestamp with time zone NOT NULL,
+    notes character varying(255),
+    created_by uuid,
+    created_at timestamp with time zone,
+    PRIMARY KEY (reading_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fleet_unit_odometer_unit ON fleet_unit_odometer(organization_id, unit_id, recorded_at);
+
+CREATE TABLE IF NOT EXISTS fleet_unit_service_plans (
+    plan_id uuid NOT NULL,
+    unit_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    service_type character varying(20) NOT NULL,
+    interval_km bigint DEFAULT 0,
+    interval_days integer DEFAULT 0,
+    last_service_km bigint DEFAULT 0,
+    last_service_date timestamp with time zone,
+    next_due_km bigint DEFAULT 0,
+    next_due_date timestamp with time zone,
+    notes character varying(255),
+    status integer DEFAULT 1,
+    created_by uuid,
+    created_at timestamp with time zone,
+    updated_by uuid,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (plan_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fleet_unit_service_plans_unit ON fleet_unit_service_plans(organization_id, unit_id);
+
+-- status: 0 = cancelled, 1 = scheduled, 2 = in workshop, 3 = completed
+CREATE TABLE IF NOT EXISTS fleet_unit_maintenance (
+    maintenance_id uuid NOT NULL,
+    unit_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    garage_id uuid NOT NULL,
+    plan_id uuid,
+    service_type character varying(20) NOT NULL,
+    description character varying(255),
+    odometer_km bigint DEFAULT 0,
+    start_date timestamp with time zone NOT NULL,
+    end_date timestamp with time zone NOT NULL,
+    completed_date timestamp with time zone,
+    status integer NOT NULL DEFAULT 1,
+    parts_cost numeric DEFAULT 0,
+    labor_cost numeric DEFAULT 0,
+    notes character varying(255),
+    created_by uuid,
+    created_at timestamp with time zone,
+    updated_by uuid,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (maintenance_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fleet_unit_maintenance_unit ON fleet_unit_maintenance(organization_id, unit_id, status);
+
+CREATE TABLE IF NOT EXISTS fleet_unit_maintenance_parts (
+    maintenance_id uuid NOT NULL,
+    item_id uuid NOT NULL,
+    quantity integer NOT NULL,
+    price numeric DEFAULT 0,
+    organization_id uuid NOT NULL,
+    created_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_fleet_unit_maintenance_parts_maintenance ON fleet_unit_maintenance_parts(maintenance_id);
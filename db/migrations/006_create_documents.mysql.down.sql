@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS document_reminders;
+DROP TABLE IF EXISTS documents;
@@ -0,0 +1,33 @@
+-- Migration: Unit and crew documents with expiry reminders
+-- Description: STNK, KIR, insurance, SIM and KTP records with scans and expiry
+-- dates, plus the reminder stages already sent by the expiry cron.
+
+CREATE TABLE IF NOT EXISTS documents (
+    document_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    owner_type VARCHAR(10) NOT NULL,
+    owner_id CHAR(36) NOT NULL,
+    document_type VARCHAR(20) NOT NULL,
+    document_number VARCHAR(100),
+    issued_date date,
+    expiry_date date NOT NULL,
+    file_path VARCHAR(255),
+    notes VARCHAR(255),
+    status smallint DEFAULT 1,
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    PRIMARY KEY (document_id)
+);
+
+CREATE INDEX idx_documents_owner ON documents(organization_id, owner_type, owner_id);
+CREATE INDEX idx_documents_expiry ON documents(organization_id, expiry_date);
+
+CREATE TABLE IF NOT EXISTS document_reminders (
+    document_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    days_before integer NOT NULL,
+    sent_at DATETIME NOT NULL,
+    PRIMARY KEY (document_id, days_before)
+);
@@ -0,0 +1,33 @@
+-- Migration: Unit and crew documents with expiry reminders
+-- Description: STNK, KIR, insurance, SIM and KTP records with scans and expiry
+-- dates, plus the reminder stages already sent by the expiry cron.
+
+CREATE TABLE IF NOT EXISTS documents (
+    document_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    owner_type character varying(10) NOT NULL,
+    owner_id uuid NOT NULL,
+    document_type character varying(20) NOT NULL,
+    document_number character varying(100),
+    issued_date date,
+    expiry_date date NOT NULL,
+    file_path character varying(255),
+    notes character varying(255),
+    status smallint DEFAULT 1,
+    created_by uuid,
+    created_at timestamp with time zone,
+    updated_by uuid,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (document_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_owner ON documents(organization_id, owner_type, owner_id);
+CREATE INDEX IF NOT EXISTS idx_documents_expiry ON documents(organization_id, expiry_date);
+
+CREATE TABLE IF NOT EXISTS document_reminders (
+    document_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    days_before integer NOT NULL,
+    sent_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (document_id, days_before)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fleet_order_price_rules;
+ALTER TABLE fleet_order_items DROP COLUMN IF EXISTS rule_adjustment;
+DROP TABLE IF EXISTS price_rules;
@@ -0,0 +1,51 @@
+-- Migration: Dynamic pricing rules for fleet price lists
+-- Description: Seasonal/holiday surcharges, weekend multipliers, lead-time
+-- discounts and pickup-city adjustments, plus the per-line breakdown of the
+-- rules applied on each order.
+
+CREATE TABLE IF NOT EXISTS price_rules (
+    rule_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    rule_type VARCHAR(20) NOT NULL,
+    adjustment_type VARCHAR(10) NOT NULL,
+    value DECIMAL(20,2) NOT NULL,
+    start_date date,
+    end_date date,
+    min_lead_days integer DEFAULT 0,
+    city_ids VARCHAR(255),
+    fleet_id CHAR(36),
+    rent_type integer DEFAULT 0,
+    priority integer DEFAULT 0,
+    status smallint DEFAULT 1,
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    PRIMARY KEY (rule_id)
+);
+
+CREATE INDEX idx_price_rules_org ON price_rules(organization_id, status);
+
+ALTER TABLE fleet_order_items ADD COLUMN rule_adjustment DECIMAL(20,2) DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS fleet_order_price_rules (
+    uuid CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    order_id VARCHAR(100) NOT NULL,
+    order_item_id CHAR(36),
+    fleet_id CHAR(36),
+    price_id CHAR(36),
+    rule_id CHAR(36),
+    rule_name VARCHAR(100),
+    rule_type VARCHAR(20) NOT NULL,
+    adjustment_type VARCHAR(10) NOT NULL,
+    value DECIMAL(20,2),
+    unit_amount DECIMAL(20,2),
+    quantity integer,
+    amount DECIMAL(20,2),
+    created_at DATETIME,
+    PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_fleet_order_price_rules_order ON fleet_order_price_rules(organization_id, order_id);
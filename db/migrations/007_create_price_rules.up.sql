@@ -0,0 +1,51 @@
+-- Migration: Dynamic pricing rules for fleet price lists
+-- Description: Seasonal/holiday surcharges, weekend multipliers, lead-time
+-- discounts and pickup-city adjustments, plus the per-line breakdown of the
+-- rules applied on each order.
+
+CREATE TABLE IF NOT EXISTS price_rules (
+    rule_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    name character varying(100) NOT NULL,
+    rule_type character varying(20) NOT NULL,
+    adjustment_type character varying(10) NOT NULL,
+    value numeric NOT NULL,
+    start_date date,
+    end_date date,
+    min_lead_days integer DEFAULT 0,
+    city_ids character varying(255),
+    fleet_id uuid,
+    rent_type integer DEFAULT 0,
+    priority integer DEFAULT 0,
+    status smallint DEFAULT 1,
+    created_by uuid,
+    created_at timestamp with time zone,
+    updated_by uuid,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (rule_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_rules_org ON price_rules(organization_id, status);
+
+ALTER TABLE fleet_order_items ADD COLUMN IF NOT EXISTS rule_adjustment numeric DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS fleet_order_price_rules (
+    uuid uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    order_id character varying(100) NOT NULL,
+    order_item_id uuid,
+    fleet_id uuid,
+    price_id uuid,
+    rule_id uuid,
+    rule_name character varying(100),
+    rule_type character varying(20) NOT NULL,
+    adjustment_type character varying(10) NOT NULL,
+    value numeric,
+    unit_amount numeric,
+    quantity integer,
+    amount numeric,
+    created_at timestamp with time zone,
+    PRIMARY KEY (uuid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fleet_order_price_rules_order ON fleet_order_price_rules(organization_id, order_id);
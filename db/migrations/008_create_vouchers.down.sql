@@ -0,0 +1,3 @@
+ALTER TABLE fleet_order_items DROP COLUMN IF EXISTS voucher_discount;
+DROP TABLE IF EXISTS voucher_redemptions;
+DROP TABLE IF EXISTS vouchers;
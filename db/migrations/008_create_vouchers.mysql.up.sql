@@ -0,0 +1,51 @@
+-- Migration: Promo codes / vouchers
+-- Description: Vouchers with validity windows, total and per-customer usage
+-- caps and minimum spend, scoped to the organization, a fleet or a tour
+-- package, plus the redemptions booked on fleet and tour package orders.
+
+CREATE TABLE IF NOT EXISTS vouchers (
+    voucher_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description text,
+    scope VARCHAR(20) NOT NULL,
+    scope_id CHAR(36),
+    discount_type VARCHAR(10) NOT NULL,
+    discount_value DECIMAL(20,2) NOT NULL,
+    max_discount DECIMAL(20,2) DEFAULT 0,
+    min_spend DECIMAL(20,2) DEFAULT 0,
+    start_date date,
+    end_date date,
+    max_uses integer DEFAULT 0,
+    max_uses_per_customer integer DEFAULT 0,
+    used_count integer DEFAULT 0,
+    status smallint DEFAULT 1,
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    PRIMARY KEY (voucher_id)
+);
+
+CREATE INDEX idx_vouchers_org_code ON vouchers(organization_id, code);
+
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    redemption_id CHAR(36) NOT NULL,
+    voucher_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    code VARCHAR(50),
+    order_id VARCHAR(100) NOT NULL,
+    order_type integer NOT NULL,
+    customer_id CHAR(36),
+    order_amount DECIMAL(20,2),
+    discount_amount DECIMAL(20,2),
+    created_at DATETIME,
+    PRIMARY KEY (redemption_id)
+);
+
+CREATE INDEX idx_voucher_redemptions_voucher ON voucher_redemptions(voucher_id, customer_id);
+CREATE INDEX idx_voucher_redemptions_org ON voucher_redemptions(organization_id, created_at);
+CREATE INDEX idx_voucher_redemptions_order ON voucher_redemptions(organization_id, order_id);
+
+ALTER TABLE fleet_order_items ADD COLUMN voucher_discount DECIMAL(20,2) DEFAULT 0;
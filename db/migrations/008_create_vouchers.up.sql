@@ -0,0 +1,51 @@
+-- Migration: Promo codes / vouchers
+-- Description: Vouchers with validity windows, total and per-customer usage
+-- caps and minimum spend, scoped to the organization, a fleet or a tour
+-- package, plus the redemptions booked on fleet and tour package orders.
+
+CREATE TABLE IF NOT EXISTS vouchers (
+    voucher_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    code character varying(50) NOT NULL,
+    name character varying(100) NOT NULL,
+    description text,
+    scope character varying(20) NOT NULL,
+    scope_id uuid,
+    discount_type character varying(10) NOT NULL,
+    discount_value numeric NOT NULL,
+    max_discount numeric DEFAULT 0,
+    min_spend numeric DEFAULT 0,
+    start_date date,
+    end_date date,
+    max_uses integer DEFAULT 0,
+    max_uses_per_customer integer DEFAULT 0,
+    used_count integer DEFAULT 0,
+    status smallint DEFAULT 1,
+    created_by uuid,
+    created_at timestamp with time zone,
+    updated_by uuid,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (voucher_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vouchers_org_code ON vouchers(organization_id, code);
+
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    redemption_id uuid NOT NULL,
+    voucher_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    code character varying(50),
+    order_id character varying(100) NOT NULL,
+    order_type integer NOT NULL,
+    customer_id uuid,
+    order_amount numeric,
+    discount_amount numeric,
+    created_at timestamp with time zone,
+    PRIMARY KEY (redemption_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_voucher ON voucher_redemptions(voucher_id, customer_id);
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_org ON voucher_redemptions(organization_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_order ON voucher_redemptions(organization_id, order_id);
+
+ALTER TABLE fleet_order_items ADD COLUMN IF NOT EXISTS voucher_discount numeric DEFAULT 0;
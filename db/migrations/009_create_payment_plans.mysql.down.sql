@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_installments;
+DROP TABLE IF EXISTS payment_plan_terms;
+DROP TABLE IF EXISTS payment_plans;
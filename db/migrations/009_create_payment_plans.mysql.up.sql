@@ -0,0 +1,59 @@
+-- Migration: Installment / down-payment plans
+-- Description: Payment plan templates made of terms (e.g. 30% at booking, the
+-- balance 3 days before departure) and the installment schedule generated from
+-- a plan for each order, with the Midtrans invoice issued per installment.
+
+CREATE TABLE IF NOT EXISTS payment_plans (
+    plan_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description text,
+    is_default boolean DEFAULT false,
+    status smallint DEFAULT 1,
+    created_by CHAR(36),
+    created_at DATETIME,
+    updated_by CHAR(36),
+    updated_at DATETIME,
+    PRIMARY KEY (plan_id)
+);
+
+CREATE INDEX idx_payment_plans_org ON payment_plans(organization_id, status);
+
+CREATE TABLE IF NOT EXISTS payment_plan_terms (
+    term_id CHAR(36) NOT NULL,
+    plan_id CHAR(36) NOT NULL,
+    seq integer NOT NULL,
+    label VARCHAR(100),
+    percentage DECIMAL(20,2) NOT NULL,
+    due_type VARCHAR(20) NOT NULL,
+    due_days integer DEFAULT 0,
+    PRIMARY KEY (term_id)
+);
+
+CREATE INDEX idx_payment_plan_terms_plan ON payment_plan_terms(plan_id, seq);
+
+CREATE TABLE IF NOT EXISTS order_installments (
+    installment_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    order_id VARCHAR(100) NOT NULL,
+    order_type integer NOT NULL,
+    plan_id CHAR(36),
+    seq integer NOT NULL,
+    label VARCHAR(100),
+    percentage DECIMAL(20,2),
+    amount DECIMAL(20,2) NOT NULL,
+    due_date date NOT NULL,
+    status smallint DEFAULT 1,
+    invoice_number VARCHAR(100),
+    snap_token VARCHAR(255),
+    redirect_url text,
+    link_created_at DATETIME,
+    paid_at DATETIME,
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (installment_id)
+);
+
+CREATE INDEX idx_order_installments_order ON order_installments(organization_id, order_id, seq);
+CREATE INDEX idx_order_installments_due ON order_installments(organization_id, status, due_date);
+CREATE INDEX idx_order_installments_invoice ON order_installments(invoice_number);
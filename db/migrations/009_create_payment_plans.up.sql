@@ -0,0 +1,59 @@
+-- Migration: Installment / down-payment plans
+-- Description: Payment plan templates made of terms (e.g. 30% at booking, the
+-- balance 3 days before departure) and the installment schedule generated from
+-- a plan for each order, with the Midtrans invoice issued per installment.
+
+CREATE TABLE IF NOT EXISTS payment_plans (
+    plan_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    name character varying(100) NOT NULL,
+    description text,
+    is_default boolean DEFAULT false,
+    status smallint DEFAULT 1,
+    created_by uuid,
+    created_at timestamp with time zone,
+    updated_by uuid,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (plan_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_plans_org ON payment_plans(organization_id, status);
+
+CREATE TABLE IF NOT EXISTS payment_plan_terms (
+    term_id uuid NOT NULL,
+    plan_id uuid NOT NULL,
+    seq integer NOT NULL,
+    label character varying(100),
+    percentage numeric NOT NULL,
+    due_type character varying(20) NOT NULL,
+    due_days integer DEFAULT 0,
+    PRIMARY KEY (term_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_plan_terms_plan ON payment_plan_terms(plan_id, seq);
+
+CREATE TABLE IF NOT EXISTS order_installments (
+    installment_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    order_id character varying(100) NOT NULL,
+    order_type integer NOT NULL,
+    plan_id uuid,
+    seq integer NOT NULL,
+    label character varying(100),
+    percentage numeric,
+    amount numeric NOT NULL,
+    due_date date NOT NULL,
+    status smallint DEFAULT 1,
+    invoice_number character varying(100),
+    snap_token character varying(255),
+    redirect_url text,
+    link_created_at timestamp with time zone,
+    paid_at timestamp with time zone,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (installment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_installments_order ON order_installments(organization_id, order_id, seq);
+CREATE INDEX IF NOT EXISTS idx_order_installments_due ON order_installments(organization_id, status, due_date);
+CREATE INDEX IF NOT EXISTS idx_order_installments_invoice ON order_installments(invoice_number);
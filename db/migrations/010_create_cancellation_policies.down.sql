@@ -0,0 +1,9 @@
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS non_refundable_amount;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS paid_amount;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS refund_percentage;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS days_before_start;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS policy_version;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS policy_id;
+DROP TABLE IF EXISTS order_cancellation_requests;
+DROP TABLE IF EXISTS cancellation_policy_tiers;
+DROP TABLE IF EXISTS cancellation_policies;
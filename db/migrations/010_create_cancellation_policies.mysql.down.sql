@@ -0,0 +1,9 @@
+ALTER TABLE transaction_refund DROP COLUMN non_refundable_amount;
+ALTER TABLE transaction_refund DROP COLUMN paid_amount;
+ALTER TABLE transaction_refund DROP COLUMN refund_percentage;
+ALTER TABLE transaction_refund DROP COLUMN days_before_start;
+ALTER TABLE transaction_refund DROP COLUMN policy_version;
+ALTER TABLE transaction_refund DROP COLUMN policy_id;
+DROP TABLE IF EXISTS order_cancellation_requests;
+DROP TABLE IF EXISTS cancellation_policy_tiers;
+DROP TABLE IF EXISTS cancellation_policies;
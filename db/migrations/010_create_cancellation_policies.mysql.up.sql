@@ -0,0 +1,61 @@
+-- Migration: Cancellation policies and customer cancellation requests
+-- Description: Versioned per-organization cancellation policies with refund
+-- tiers by days before the trip starts, customer cancellation requests waiting
+-- for staff approval, and the policy applied on each recorded refund.
+
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    policy_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    version integer NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description text,
+    non_refundable_addons boolean DEFAULT false,
+    status smallint DEFAULT 1,
+    created_by CHAR(36),
+    created_at DATETIME,
+    PRIMARY KEY (policy_id)
+);
+
+CREATE INDEX idx_cancellation_policies_org ON cancellation_policies(organization_id, version);
+
+CREATE TABLE IF NOT EXISTS cancellation_policy_tiers (
+    tier_id CHAR(36) NOT NULL,
+    policy_id CHAR(36) NOT NULL,
+    seq integer NOT NULL,
+    min_days_before integer NOT NULL,
+    refund_percentage DECIMAL(20,2) NOT NULL,
+    PRIMARY KEY (tier_id)
+);
+
+CREATE INDEX idx_cancellation_policy_tiers_policy ON cancellation_policy_tiers(policy_id, seq);
+
+CREATE TABLE IF NOT EXISTS order_cancellation_requests (
+    request_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    order_id VARCHAR(100) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    reason text,
+    payment_method integer,
+    bank_code VARCHAR(10),
+    bank_account VARCHAR(50),
+    bank_account_name VARCHAR(50),
+    policy_version integer,
+    refund_percentage DECIMAL(20,2),
+    refund_amount DECIMAL(20,2),
+    status smallint DEFAULT 1,
+    requested_at DATETIME,
+    reviewed_by CHAR(36),
+    reviewed_at DATETIME,
+    review_note text,
+    PRIMARY KEY (request_id)
+);
+
+CREATE INDEX idx_order_cancellation_requests_org ON order_cancellation_requests(organization_id, status, requested_at);
+CREATE INDEX idx_order_cancellation_requests_order ON order_cancellation_requests(organization_id, order_id);
+
+ALTER TABLE transaction_refund ADD COLUMN policy_id CHAR(36);
+ALTER TABLE transaction_refund ADD COLUMN policy_version integer;
+ALTER TABLE transaction_refund ADD COLUMN days_before_start integer;
+ALTER TABLE transaction_refund ADD COLUMN refund_percentage DECIMAL(20,2);
+ALTER TABLE transaction_refund ADD COLUMN paid_amount DECIMAL(20,2);
+ALTER TABLE transaction_refund ADD COLUMN non_refundable_amount DECIMAL(20,2);
@@ -0,0 +1,61 @@
+-- Migration: Cancellation policies and customer cancellation requests
+-- Description: Versioned per-organization cancellation policies with refund
+-- tiers by days before the trip starts, customer cancellation requests waiting
+-- for staff approval, and the policy applied on each recorded refund.
+
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    policy_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    version integer NOT NULL,
+    name character varying(100) NOT NULL,
+    description text,
+    non_refundable_addons boolean DEFAULT false,
+    status smallint DEFAULT 1,
+    created_by uuid,
+    created_at timestamp with time zone,
+    PRIMARY KEY (policy_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cancellation_policies_org ON cancellation_policies(organization_id, version);
+
+CREATE TABLE IF NOT EXISTS cancellation_policy_tiers (
+    tier_id uuid NOT NULL,
+    policy_id uuid NOT NULL,
+    seq integer NOT NULL,
+    min_days_before integer NOT NULL,
+    refund_percentage numeric NOT NULL,
+    PRIMARY KEY (tier_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cancellation_policy_tiers_policy ON cancellation_policy_tiers(policy_id, seq);
+
+CREATE TABLE IF NOT EXISTS order_cancellation_requests (
+    request_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    order_id character varying(100) NOT NULL,
+    source character varying(20) NOT NULL,
+    reason text,
+    payment_method integer,
+    bank_code character varying(10),
+    bank_account character varying(50),
+    bank_account_name character varying(50),
+    policy_version integer,
+    refund_percentage numeric,
+    refund_amount numeric,
+    status smallint DEFAULT 1,
+    requested_at timestamp with time zone,
+    reviewed_by uuid,
+    reviewed_at timestamp with time zone,
+    review_note text,
+    PRIMARY KEY (request_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_cancellation_requests_org ON order_cancellation_requests(organization_id, status, requested_at);
+CREATE INDEX IF NOT EXISTS idx_order_cancellation_requests_order ON order_cancellation_requests(organization_id, order_id);
+
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS policy_id uuid;
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS policy_version integer;
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS days_before_start integer;
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS refund_percentage numeric;
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS paid_amount numeric;
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS non_refundable_amount numeric;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS payment_midtrans_refunds;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS midtrans_refund_message;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS midtrans_refund_amount;
+ALTER TABLE transaction_refund DROP COLUMN IF EXISTS midtrans_refund_status;
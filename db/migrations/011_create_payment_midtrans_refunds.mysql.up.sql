@@ -0,0 +1,27 @@
+-- Migration: Midtrans refunds and payment reconciliation
+-- Description: Tracks the Midtrans refunds issued for each recorded order
+-- refund. Refunds recorded before this migration are marked MANUAL so the
+-- reconciliation job never refunds them a second time.
+
+ALTER TABLE transaction_refund ADD COLUMN midtrans_refund_status VARCHAR(20);
+ALTER TABLE transaction_refund ADD COLUMN midtrans_refund_amount DECIMAL(20,2);
+ALTER TABLE transaction_refund ADD COLUMN midtrans_refund_message text;
+
+UPDATE transaction_refund SET midtrans_refund_status = 'MANUAL' WHERE midtrans_refund_status IS NULL;
+
+CREATE TABLE IF NOT EXISTS payment_midtrans_refunds (
+    refund_key VARCHAR(100) NOT NULL,
+    refund_id CHAR(36) NOT NULL,
+    organization_id CHAR(36) NOT NULL,
+    order_id VARCHAR(100) NOT NULL,
+    invoice_number VARCHAR(50) NOT NULL,
+    amount DECIMAL(20,2) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    status_message text,
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (refund_key)
+);
+
+CREATE INDEX idx_payment_midtrans_refunds_refund ON payment_midtrans_refunds(refund_id);
+CREATE INDEX idx_payment_midtrans_refunds_invoice ON payment_midtrans_refunds(invoice_number, status);
@@ -0,0 +1,27 @@
+-- Migration: Midtrans refunds and payment reconciliation
+-- Description: Tracks the Midtrans refunds issued for each recorded order
+-- refund. Refunds recorded before this migration are marked MANUAL so the
+-- reconciliation job never refunds them a second time.
+
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS midtrans_refund_status character varying(20);
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS midtrans_refund_amount numeric;
+ALTER TABLE transaction_refund ADD COLUMN IF NOT EXISTS midtrans_refund_message text;
+
+UPDATE transaction_refund SET midtrans_refund_status = 'MANUAL' WHERE midtrans_refund_status IS NULL;
+
+CREATE TABLE IF NOT EXISTS payment_midtrans_refunds (
+    refund_key character varying(100) NOT NULL,
+    refund_id uuid NOT NULL,
+    organization_id uuid NOT NULL,
+    order_id character varying(100) NOT NULL,
+    invoice_number character varying(50) NOT NULL,
+    amount numeric NOT NULL,
+    status character varying(20) NOT NULL,
+    status_message text,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    PRIMARY KEY (refund_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_midtrans_refunds_refund ON payment_midtrans_refunds(refund_id);
+CREATE INDEX IF NOT EXISTS idx_payment_midtrans_refunds_invoice ON payment_midtrans_refunds(invoice_number, status);
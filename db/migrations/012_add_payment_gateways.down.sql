@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS payment_xendit;
+ALTER TABLE order_installments DROP COLUMN IF EXISTS payment_gateway;
+ALTER TABLE travego_transactions DROP COLUMN IF EXISTS payment_gateway;
+ALTER TABLE payment_orders DROP COLUMN IF EXISTS payment_gateway;
+ALTER TABLE organizations DROP COLUMN IF EXISTS payment_gateway;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS payment_xendit;
+ALTER TABLE order_installments DROP COLUMN payment_gateway;
+ALTER TABLE travego_transactions DROP COLUMN payment_gateway;
+ALTER TABLE payment_orders DROP COLUMN payment_gateway;
+ALTER TABLE organizations DROP COLUMN payment_gateway;
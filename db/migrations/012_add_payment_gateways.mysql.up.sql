@@ -0,0 +1,30 @@
+-- Migration: Payment gateway per organization
+-- Description: Lets each organization choose the payment gateway its invoices
+-- are issued with (midtrans or xendit), records the gateway of every issued
+-- invoice so webhooks and reconciliation go to the right provider, and logs
+-- Xendit invoice callbacks the way payment_midtrans logs Midtrans ones.
+
+ALTER TABLE organizations ADD COLUMN payment_gateway VARCHAR(20) DEFAULT 'midtrans';
+
+ALTER TABLE payment_orders ADD COLUMN payment_gateway VARCHAR(20);
+ALTER TABLE travego_transactions ADD COLUMN payment_gateway VARCHAR(20);
+ALTER TABLE order_installments ADD COLUMN payment_gateway VARCHAR(20);
+
+UPDATE payment_orders SET payment_gateway = 'midtrans' WHERE payment_gateway IS NULL AND payment_method = 1004;
+UPDATE travego_transactions SET payment_gateway = 'midtrans' WHERE payment_gateway IS NULL;
+UPDATE order_installments SET payment_gateway = 'midtrans' WHERE payment_gateway IS NULL AND invoice_number IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS payment_xendit (
+    invoice_id VARCHAR(50) NOT NULL,
+    external_id VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    payment_method VARCHAR(30),
+    payment_channel VARCHAR(30),
+    amount DECIMAL(20,2),
+    paid_amount DECIMAL(20,2),
+    paid_at VARCHAR(40),
+    currency VARCHAR(5),
+    created_at DATETIME
+);
+
+CREATE INDEX idx_payment_xendit_external ON payment_xendit(external_id);
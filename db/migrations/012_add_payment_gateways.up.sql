@@ -0,0 +1,30 @@
+-- Migration: Payment gateway per organization
+-- Description: Lets each organization choose the payment gateway its invoices
+-- are issued with (midtrans or xendit), records the gateway of every issued
+-- invoice so webhooks and reconciliation go to the right provider, and logs
+-- Xendit invoice callbacks the way payment_midtrans logs Midtrans ones.
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS payment_gateway character varying(20) DEFAULT 'midtrans';
+
+ALTER TABLE payment_orders ADD COLUMN IF NOT EXISTS payment_gateway character varying(20);
+ALTER TABLE travego_transactions ADD COLUMN IF NOT EXISTS payment_gateway character varying(20);
+ALTER TABLE order_installments ADD COLUMN IF NOT EXISTS payment_gateway character varying(20);
+
+UPDATE payment_orders SET payment_gateway = 'midtrans' WHERE payment_gateway IS NULL AND payment_method = 1004;
+UPDATE travego_transactions SET payment_gateway = 'midtrans' WHERE payment_gateway IS NULL;
+UPDATE order_installments SET payment_gateway = 'midtrans' WHERE payment_gateway IS NULL AND invoice_number IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS payment_xendit (
+    invoice_id character varying(50) NOT NULL,
+    external_id character varying(50) NOT NULL,
+    status character varying(20) NOT NULL,
+    payment_method character varying(30),
+    payment_channel character varying(30),
+    amount numeric,
+    paid_amount numeric,
+    paid_at character varying(40),
+    currency character varying(5),
+    created_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_xendit_external ON payment_xendit(external_id);
@@ -0,0 +1,23 @@
+-- Every payment gateway notification as received, with its processing outcome.
+-- event_key (provider:transaction_id:status) is unique while a notification
+-- holds the event, so repeated deliveries are recorded as DUPLICATE and not
+-- processed again. A failed notification releases its event_key.
+CREATE TABLE IF NOT EXISTS payment_notifications (
+    notification_id CHAR(36) PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    event_key VARCHAR(200),
+    invoice_number VARCHAR(50),
+    transaction_id VARCHAR(100),
+    transaction_status VARCHAR(30),
+    payload text NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    message text,
+    attempts integer NOT NULL DEFAULT 0,
+    received_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at DATETIME
+);
+
+CREATE UNIQUE INDEX uq_payment_notifications_event_key ON payment_notifications(event_key);
+CREATE INDEX idx_payment_notifications_invoice ON payment_notifications(invoice_number);
+CREATE INDEX idx_payment_notifications_outcome ON payment_notifications(outcome, received_at);
@@ -0,0 +1,23 @@
+-- Every payment gateway notification as received, with its processing outcome.
+-- event_key (provider:transaction_id:status) is unique while a notification
+-- holds the event, so repeated deliveries are recorded as DUPLICATE and not
+-- processed again. A failed notification releases its event_key.
+CREATE TABLE IF NOT EXISTS payment_notifications (
+    notification_id uuid PRIMARY KEY,
+    provider character varying(20) NOT NULL,
+    source character varying(20) NOT NULL,
+    event_key character varying(200),
+    invoice_number character varying(50),
+    transaction_id character varying(100),
+    transaction_status character varying(30),
+    payload text NOT NULL,
+    outcome character varying(20) NOT NULL,
+    message text,
+    attempts integer NOT NULL DEFAULT 0,
+    received_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    processed_at timestamp with time zone
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payment_notifications_event_key ON payment_notifications(event_key);
+CREATE INDEX IF NOT EXISTS idx_payment_notifications_invoice ON payment_notifications(invoice_number);
+CREATE INDEX IF NOT EXISTS idx_payment_notifications_outcome ON payment_notifications(outcome, received_at);
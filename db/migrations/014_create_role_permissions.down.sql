@@ -0,0 +1,2 @@
+ALTER TABLE organization_users DROP COLUMN IF EXISTS role_id;
+DROP TABLE IF EXISTS organization_role_permissions;
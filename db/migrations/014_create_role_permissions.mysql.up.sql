@@ -0,0 +1,11 @@
+-- Named permissions (e.g. orders.cancel, finance.view) granted by an
+-- organization role, and the role each organization member acts as.
+CREATE TABLE IF NOT EXISTS organization_role_permissions (
+    role_id CHAR(36) NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    created_at DATETIME,
+    created_by CHAR(36),
+    PRIMARY KEY (role_id, permission)
+);
+
+ALTER TABLE organization_users ADD COLUMN role_id CHAR(36);
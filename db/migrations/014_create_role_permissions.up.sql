@@ -0,0 +1,11 @@
+-- Named permissions (e.g. orders.cancel, finance.view) granted by an
+-- organization role, and the role each organization member acts as.
+CREATE TABLE IF NOT EXISTS organization_role_permissions (
+    role_id uuid NOT NULL,
+    permission character varying(50) NOT NULL,
+    created_at timestamp with time zone,
+    created_by uuid,
+    PRIMARY KEY (role_id, permission)
+);
+
+ALTER TABLE organization_users ADD COLUMN IF NOT EXISTS role_id uuid;
@@ -0,0 +1,20 @@
+-- Open API keys minted by an organization. Only the SHA-256 hash of a key is
+-- stored; key_prefix identifies it in the dashboard.
+CREATE TABLE IF NOT EXISTS organization_api_keys (
+    api_key_id CHAR(36) PRIMARY KEY,
+    organization_id CHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes text NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    last_used_ip VARCHAR(64),
+    revoked_at DATETIME,
+    revoked_by CHAR(36),
+    rotated_from CHAR(36),
+    created_at DATETIME NOT NULL,
+    created_by CHAR(36)
+);
+
+CREATE INDEX idx_organization_api_keys_organization_id ON organization_api_keys (organization_id);
@@ -0,0 +1,20 @@
+-- Open API keys minted by an organization. Only the SHA-256 hash of a key is
+-- stored; key_prefix identifies it in the dashboard.
+CREATE TABLE IF NOT EXISTS organization_api_keys (
+    api_key_id uuid PRIMARY KEY,
+    organization_id uuid NOT NULL,
+    name character varying(100) NOT NULL,
+    key_prefix character varying(20) NOT NULL,
+    key_hash character varying(64) NOT NULL UNIQUE,
+    scopes text NOT NULL,
+    expires_at timestamp with time zone,
+    last_used_at timestamp with time zone,
+    last_used_ip character varying(64),
+    revoked_at timestamp with time zone,
+    revoked_by uuid,
+    rotated_from uuid,
+    created_at timestamp with time zone NOT NULL,
+    created_by uuid
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_api_keys_organization_id ON organization_api_keys (organization_id);
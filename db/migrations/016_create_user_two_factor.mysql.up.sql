@@ -0,0 +1,18 @@
+-- TOTP two-factor authentication. totp_secret is encrypted; the row exists
+-- from setup, and two-factor is on once enabled_at is set.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id VARCHAR(36) PRIMARY KEY,
+    totp_secret text NOT NULL,
+    enabled_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    user_id VARCHAR(36) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
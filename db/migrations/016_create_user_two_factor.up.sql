@@ -0,0 +1,18 @@
+-- TOTP two-factor authentication. totp_secret is encrypted; the row exists
+-- from setup, and two-factor is on once enabled_at is set.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id character varying(36) PRIMARY KEY,
+    totp_secret text NOT NULL,
+    enabled_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    user_id character varying(36) NOT NULL,
+    code_hash character varying(64) NOT NULL,
+    used_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
@@ -0,0 +1,15 @@
+-- Who changed what in an organization. changes holds the changed fields as
+-- {"field": {"before": ..., "after": ...}}.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    audit_id CHAR(36) PRIMARY KEY,
+    organization_id CHAR(36) NOT NULL,
+    actor_id CHAR(36),
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    changes text NOT NULL DEFAULT ('{}'),
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_audit_logs_organization_created ON audit_logs (organization_id, created_at DESC);
+CREATE INDEX idx_audit_logs_entity ON audit_logs (organization_id, entity_type, entity_id);
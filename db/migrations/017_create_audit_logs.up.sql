@@ -0,0 +1,15 @@
+-- Who changed what in an organization. changes holds the changed fields as
+-- {"field": {"before": ..., "after": ...}}.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    audit_id uuid PRIMARY KEY,
+    organization_id uuid NOT NULL,
+    actor_id uuid,
+    entity_type character varying(50) NOT NULL,
+    entity_id character varying(100) NOT NULL,
+    action character varying(50) NOT NULL,
+    changes text NOT NULL DEFAULT '{}',
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_organization_created ON audit_logs (organization_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (organization_id, entity_type, entity_id);
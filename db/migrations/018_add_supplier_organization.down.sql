@@ -0,0 +1,3 @@
+-- Copies made for other organizations are kept; only the column goes
+DROP INDEX IF EXISTS idx_supliers_organization_id;
+ALTER TABLE supliers DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,3 @@
+-- Copies made for other organizations are kept; only the column goes
+DROP INDEX idx_supliers_organization_id ON supliers;
+ALTER TABLE supliers DROP COLUMN organization_id;
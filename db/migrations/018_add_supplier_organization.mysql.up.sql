@@ -0,0 +1,50 @@
+-- Suppliers belong to one organization. Existing suppliers go to the
+-- organization of their first purchase order, or else to the organization of
+-- the user who added them; a supplier ordered from by several organizations is
+-- copied for each of the others.
+ALTER TABLE supliers ADD COLUMN organization_id CHAR(36);
+
+UPDATE supliers s
+JOIN (
+    SELECT suplier_id, organization_id,
+        ROW_NUMBER() OVER (PARTITION BY suplier_id ORDER BY created_at) AS n
+    FROM inventory_orders
+    WHERE suplier_id IS NOT NULL AND organization_id IS NOT NULL
+) o ON o.suplier_id = s.suplier_id AND o.n = 1
+SET s.organization_id = o.organization_id
+WHERE s.organization_id IS NULL;
+
+UPDATE supliers s
+JOIN (
+    SELECT user_id, organization_id,
+        ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at) AS n
+    FROM organization_users
+) ou ON ou.user_id = s.created_by AND ou.n = 1
+SET s.organization_id = ou.organization_id
+WHERE s.organization_id IS NULL;
+
+CREATE TEMPORARY TABLE suplier_copies AS
+SELECT DISTINCT io.suplier_id AS old_id, io.organization_id, CAST(NULL AS CHAR(36)) AS new_id
+FROM inventory_orders io
+JOIN supliers s ON s.suplier_id = io.suplier_id
+WHERE io.organization_id IS NOT NULL AND NOT (io.organization_id <=> s.organization_id);
+
+UPDATE suplier_copies SET new_id = UUID();
+
+INSERT INTO supliers (suplier_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email, created_at, created_by, updated_at, updated_by, suplier_url, organization_id)
+SELECT c.new_id, s.suplier_name, s.suplier_address, s.suplier_city, s.suplier_phone, s.supliter_email, s.created_at, s.created_by, s.updated_at, s.updated_by, s.suplier_url, c.organization_id
+FROM suplier_copies c
+JOIN supliers s ON s.suplier_id = c.old_id;
+
+UPDATE inventory_orders io
+JOIN suplier_copies c ON io.suplier_id = c.old_id AND io.organization_id = c.organization_id
+SET io.suplier_id = c.new_id;
+
+UPDATE inventory_item_supliers iis
+JOIN suplier_copies c ON iis.suplier_id = c.old_id
+JOIN inventory_items i ON i.item_id = iis.item_id AND i.organization_id = c.organization_id
+SET iis.suplier_id = c.new_id;
+
+DROP TEMPORARY TABLE suplier_copies;
+
+CREATE INDEX idx_supliers_organization_id ON supliers (organization_id);
@@ -0,0 +1,47 @@
+-- Suppliers belong to one organization. Existing suppliers go to the
+-- organization of their first purchase order, or else to the organization of
+-- the user who added them; a supplier ordered from by several organizations is
+-- copied for each of the others.
+ALTER TABLE supliers ADD COLUMN IF NOT EXISTS organization_id uuid;
+
+UPDATE supliers s SET organization_id = o.organization_id
+FROM (
+    SELECT DISTINCT ON (suplier_id) suplier_id, organization_id
+    FROM inventory_orders
+    WHERE suplier_id IS NOT NULL AND organization_id IS NOT NULL
+    ORDER BY suplier_id, created_at
+) o
+WHERE s.suplier_id = o.suplier_id AND s.organization_id IS NULL;
+
+UPDATE supliers s SET organization_id = ou.organization_id
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, organization_id
+    FROM organization_users
+    ORDER BY user_id, created_at
+) ou
+WHERE s.created_by = ou.user_id AND s.organization_id IS NULL;
+
+CREATE TEMPORARY TABLE suplier_copies AS
+SELECT DISTINCT io.suplier_id AS old_id, io.organization_id, NULL::uuid AS new_id
+FROM inventory_orders io
+JOIN supliers s ON s.suplier_id = io.suplier_id
+WHERE io.organization_id IS NOT NULL AND io.organization_id IS DISTINCT FROM s.organization_id;
+
+UPDATE suplier_copies SET new_id = md5(random()::text || clock_timestamp()::text || old_id::text || organization_id::text)::uuid;
+
+INSERT INTO supliers (suplier_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email, created_at, created_by, updated_at, updated_by, suplier_url, organization_id)
+SELECT c.new_id, s.suplier_name, s.suplier_address, s.suplier_city, s.suplier_phone, s.supliter_email, s.created_at, s.created_by, s.updated_at, s.updated_by, s.suplier_url, c.organization_id
+FROM suplier_copies c
+JOIN supliers s ON s.suplier_id = c.old_id;
+
+UPDATE inventory_orders io SET suplier_id = c.new_id
+FROM suplier_copies c
+WHERE io.suplier_id = c.old_id AND io.organization_id = c.organization_id;
+
+UPDATE inventory_item_supliers iis SET suplier_id = c.new_id
+FROM suplier_copies c, inventory_items i
+WHERE iis.suplier_id = c.old_id AND i.item_id = iis.item_id AND i.organization_id = c.organization_id;
+
+DROP TABLE suplier_copies;
+
+CREATE INDEX IF NOT EXISTS idx_supliers_organization_id ON supliers (organization_id);
@@ -0,0 +1,24 @@
+-- Outgoing WhatsApp messages and emails. Call sites only insert a row; the
+-- outbox worker sends it, retrying with backoff until max_attempts, after which
+-- the message is DEAD and can be resent from /api/system/outbox.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    message_id CHAR(36) PRIMARY KEY,
+    organization_id CHAR(36),
+    channel VARCHAR(20) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject text NOT NULL DEFAULT (''),
+    body text NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    max_attempts integer NOT NULL,
+    last_error text NOT NULL DEFAULT (''),
+    next_attempt_at DATETIME NOT NULL,
+    locked_until DATETIME,
+    sent_at DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_outbox_messages_due ON outbox_messages (status, next_attempt_at);
+CREATE INDEX idx_outbox_messages_organization ON outbox_messages (organization_id, created_at DESC);
@@ -0,0 +1,24 @@
+-- Outgoing WhatsApp messages and emails. Call sites only insert a row; the
+-- outbox worker sends it, retrying with backoff until max_attempts, after which
+-- the message is DEAD and can be resent from /api/system/outbox.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    message_id uuid PRIMARY KEY,
+    organization_id uuid,
+    channel character varying(20) NOT NULL,
+    kind character varying(50) NOT NULL,
+    recipient character varying(255) NOT NULL,
+    subject text NOT NULL DEFAULT '',
+    body text NOT NULL,
+    status character varying(20) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    max_attempts integer NOT NULL,
+    last_error text NOT NULL DEFAULT '',
+    next_attempt_at timestamp with time zone NOT NULL,
+    locked_until timestamp with time zone,
+    sent_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_due ON outbox_messages (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_organization ON outbox_messages (organization_id, created_at DESC);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS subscription_events;
+
+ALTER TABLE _subscription DROP COLUMN IF EXISTS scheduled_package_price;
+ALTER TABLE _subscription DROP COLUMN IF EXISTS scheduled_expiry_date;
+ALTER TABLE _subscription DROP COLUMN IF EXISTS scheduled_package_id;
+ALTER TABLE _subscription DROP COLUMN IF EXISTS reminder_days;
+ALTER TABLE _subscription DROP COLUMN IF EXISTS grace_until;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS subscription_events;
+
+ALTER TABLE _subscription DROP COLUMN scheduled_package_price;
+ALTER TABLE _subscription DROP COLUMN scheduled_expiry_date;
+ALTER TABLE _subscription DROP COLUMN scheduled_package_id;
+ALTER TABLE _subscription DROP COLUMN reminder_days;
+ALTER TABLE _subscription DROP COLUMN grace_until;
@@ -0,0 +1,22 @@
+-- Subscription lifecycle. _subscription.status is 1 (active), 2 (grace period
+-- after expiry_date, until grace_until) or 3 (read only). reminder_days is the
+-- last renewal reminder sent for the current period. A downgrade paid before
+-- the period ends waits in the scheduled_* columns until expiry_date.
+ALTER TABLE _subscription ADD COLUMN grace_until date;
+ALTER TABLE _subscription ADD COLUMN reminder_days integer;
+ALTER TABLE _subscription ADD COLUMN scheduled_package_id VARCHAR(10);
+ALTER TABLE _subscription ADD COLUMN scheduled_expiry_date date;
+ALTER TABLE _subscription ADD COLUMN scheduled_package_price DECIMAL(20,2);
+
+CREATE TABLE IF NOT EXISTS subscription_events (
+    event_id CHAR(36) PRIMARY KEY,
+    organization_id CHAR(36) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    from_status integer,
+    to_status integer,
+    package_id VARCHAR(10),
+    note text NOT NULL DEFAULT (''),
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_subscription_events_organization ON subscription_events (organization_id, created_at DESC);
@@ -0,0 +1,22 @@
+-- Subscription lifecycle. _subscription.status is 1 (active), 2 (grace period
+-- after expiry_date, until grace_until) or 3 (read only). reminder_days is the
+-- last renewal reminder sent for the current period. A downgrade paid before
+-- the period ends waits in the scheduled_* columns until expiry_date.
+ALTER TABLE _subscription ADD COLUMN IF NOT EXISTS grace_until date;
+ALTER TABLE _subscription ADD COLUMN IF NOT EXISTS reminder_days integer;
+ALTER TABLE _subscription ADD COLUMN IF NOT EXISTS scheduled_package_id character varying(10);
+ALTER TABLE _subscription ADD COLUMN IF NOT EXISTS scheduled_expiry_date date;
+ALTER TABLE _subscription ADD COLUMN IF NOT EXISTS scheduled_package_price numeric;
+
+CREATE TABLE IF NOT EXISTS subscription_events (
+    event_id uuid PRIMARY KEY,
+    organization_id uuid NOT NULL,
+    event character varying(30) NOT NULL,
+    from_status integer,
+    to_status integer,
+    package_id character varying(10),
+    note text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_events_organization ON subscription_events (organization_id, created_at DESC);
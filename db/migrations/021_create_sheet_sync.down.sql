@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sheet_sync_cursors;
+DROP TABLE IF EXISTS sheet_sync_configs;
@@ -0,0 +1,23 @@
+-- Google Sheets sync. An organization shares a spreadsheet with the service
+-- account and stores its ID here; the sync job pushes orders, payments and
+-- expenses to it. sheet_sync_cursors is how far each entity was pushed, the
+-- (created_at, id) of the last row written, so a failed run resumes there.
+CREATE TABLE IF NOT EXISTS sheet_sync_configs (
+    organization_id CHAR(36) PRIMARY KEY,
+    spreadsheet_id VARCHAR(100) NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    last_synced_at DATETIME,
+    last_error text NOT NULL DEFAULT (''),
+    created_at DATETIME NOT NULL,
+    created_by CHAR(36),
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sheet_sync_cursors (
+    organization_id CHAR(36) NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    cursor_time DATETIME NOT NULL,
+    cursor_id VARCHAR(100) NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (organization_id, entity)
+);
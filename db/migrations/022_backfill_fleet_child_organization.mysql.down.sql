@@ -0,0 +1 @@
+-- The backfilled organizations are kept; the rows were already theirs
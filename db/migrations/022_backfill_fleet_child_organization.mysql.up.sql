@@ -0,0 +1,17 @@
+-- Fleet facilities, fleet images and order itineraries used to be written
+-- without their organization; they take the organization of their fleet or
+-- order, which tenant scoped queries now filter on.
+UPDATE fleet_facilities ff
+JOIN fleets f ON f.uuid = ff.fleet_id
+SET ff.organization_id = f.organization_id
+WHERE ff.organization_id IS NULL;
+
+UPDATE fleet_images fi
+JOIN fleets f ON f.uuid = fi.fleet_id
+SET fi.organization_id = f.organization_id
+WHERE fi.organization_id IS NULL;
+
+UPDATE fleet_order_itinerary it
+JOIN fleet_orders fo ON fo.order_id = it.order_id
+SET it.organization_id = fo.organization_id
+WHERE it.organization_id IS NULL;
@@ -0,0 +1,14 @@
+-- Fleet facilities, fleet images and order itineraries used to be written
+-- without their organization; they take the organization of their fleet or
+-- order, which tenant scoped queries now filter on.
+UPDATE fleet_facilities ff SET organization_id = f.organization_id
+FROM fleets f
+WHERE ff.fleet_id = f.uuid AND ff.organization_id IS NULL;
+
+UPDATE fleet_images fi SET organization_id = f.organization_id
+FROM fleets f
+WHERE fi.fleet_id = f.uuid AND fi.organization_id IS NULL;
+
+UPDATE fleet_order_itinerary it SET organization_id = fo.organization_id
+FROM fleet_orders fo
+WHERE it.order_id = fo.order_id AND it.organization_id IS NULL;
@@ -2,7 +2,7 @@ package handler
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"service-travego/helper"
 	"service-travego/model"
@@ -27,7 +27,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req model.RegisterRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		log.Printf("[ERROR] BodyParser failed - Path: %s, Error: %v", c.Path(), err)
+		slog.ErrorContext(c.UserContext(), "body parser failed", "path", c.Path(), "error", err)
 		return helper.BadRequestResponse(c, "Invalid request body")
 	}
 
@@ -44,17 +44,17 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		}
 	}
 
-	user, token, err := h.authService.Register(req.Username, req.Fullname, req.Email, req.Password, req.Phone)
+	user, token, err := h.authService.Register(c.UserContext(), req.Username, req.Fullname, req.Email, req.Password, req.Phone)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
-		log.Printf("[ERROR] Register failed - Username: %s, Email: %s, Status: %d, Error: %v", req.Username, req.Email, statusCode, err)
+		slog.ErrorContext(c.UserContext(), "register failed", "username", req.Username, "email", req.Email, "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
 	// Insert into subscription table after successful registration and obtaining organization_id
 	if user.OrganizationID != "" {
-		if err := h.authService.CreateSubscription(user.OrganizationID); err != nil {
-			log.Printf("[ERROR] Failed to create subscription - OrgID: %s, Error: %v", user.OrganizationID, err)
+		if err := h.authService.CreateSubscription(c.UserContext(), user.OrganizationID); err != nil {
+			slog.ErrorContext(c.UserContext(), "failed to create subscription", "organization_id", user.OrganizationID, "error", err)
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create subscription")
 		}
 	}
@@ -93,9 +93,9 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, fmt.Sprintf("OTP must be %d digits", otpLength))
 	}
 
-	if err := h.authService.VerifyOTP(req.Token, req.OTP); err != nil {
+	if err := h.authService.VerifyOTP(c.UserContext(), req.Token, req.OTP); err != nil {
 		statusCode := service.GetStatusCode(err)
-		log.Printf("[ERROR] VerifyOTP failed - Status: %d, Error: %v", statusCode, err)
+		slog.ErrorContext(c.UserContext(), "verify OTP failed", "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
@@ -118,16 +118,16 @@ func (h *AuthHandler) ResendOTP(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	log.Printf("[DEBUG] ResendOTP request - Email: %s, TokenPresent: %t, TokenLen: %d", req.Email, req.Token != "", len(req.Token))
+	slog.DebugContext(c.UserContext(), "resend OTP request", "email", req.Email, "token_present", req.Token != "", "token_len", len(req.Token))
 
-	token, err := h.authService.ResendOTP(req.Email, req.Token)
+	token, err := h.authService.ResendOTP(c.UserContext(), req.Email, req.Token)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		tokenPreview := req.Token
 		if len(tokenPreview) > 16 {
 			tokenPreview = tokenPreview[:8] + "..." + tokenPreview[len(tokenPreview)-8:]
 		}
-		log.Printf("[ERROR] ResendOTP failed - Email: %s, TokenPreview: %s, Status: %d, Error: %v", req.Email, tokenPreview, statusCode, err)
+		slog.ErrorContext(c.UserContext(), "resend OTP failed", "email", req.Email, "token_preview", tokenPreview, "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
@@ -143,7 +143,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req model.LoginRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		log.Printf("[ERROR] BodyParser failed - Path: %s, Error: %v", c.Path(), err)
+		slog.ErrorContext(c.UserContext(), "body parser failed", "path", c.Path(), "error", err)
 		return helper.BadRequestResponse(c, "Invalid request body")
 	}
 
@@ -156,13 +156,30 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	loginResponse, err := h.authService.Login(req.Email, req.Phone, req.Password, "")
+	deviceName := req.DeviceName
+	if deviceName == "" {
+		deviceName = c.Get("User-Agent")
+	}
+	loginResponse, err := h.authService.Login(c.UserContext(), req.Email, req.Phone, req.Password, "", service.LoginDevice{
+		DeviceID:   req.DeviceID,
+		DeviceName: deviceName,
+		IPAddress:  c.IP(),
+	})
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
-		log.Printf("[ERROR] Login failed - Email: %s, Phone: %s, Status: %d, Error: %v", req.Email, req.Phone, statusCode, err)
+		slog.ErrorContext(c.UserContext(), "login failed", "email", req.Email, "phone", req.Phone, "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
+	// The password was right but the user still has to enter their second factor
+	if loginResponse.TwoFactorRequired {
+		responseData := map[string]interface{}{
+			"two_factor_required": true,
+			"challenge_token":     loginResponse.ChallengeToken,
+		}
+		return helper.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication required.", responseData)
+	}
+
 	// Store token in locals for middleware access
 	c.Locals("auth_token", loginResponse.Token)
 
@@ -170,6 +187,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	responseData := map[string]interface{}{
 		"token":         loginResponse.Token,
 		"refresh_token": loginResponse.RefreshToken,
+		"session_id":    loginResponse.SessionID,
 		"username":      loginResponse.Username,
 		"fullname":      loginResponse.Fullname,
 		"avatar":        loginResponse.Avatar,
@@ -182,7 +200,7 @@ func (h *AuthHandler) RequestResetPassword(c *fiber.Ctx) error {
 	var req model.RequestResetPasswordRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		log.Printf("[ERROR] BodyParser failed - Path: %s, Error: %v", c.Path(), err)
+		slog.ErrorContext(c.UserContext(), "body parser failed", "path", c.Path(), "error", err)
 		return helper.BadRequestResponse(c, "Invalid request body")
 	}
 
@@ -204,9 +222,9 @@ func (h *AuthHandler) RequestResetPassword(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.authService.RequestResetPassword(req.Email, resetPasswordURL, expiryMinutes); err != nil {
+	if err := h.authService.RequestResetPassword(c.UserContext(), req.Email, resetPasswordURL, expiryMinutes); err != nil {
 		statusCode := service.GetStatusCode(err)
-		log.Printf("[ERROR] RequestResetPassword failed - Email: %s, Status: %d, Error: %v", req.Email, statusCode, err)
+		slog.ErrorContext(c.UserContext(), "request reset password failed", "email", req.Email, "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
@@ -222,7 +240,7 @@ func (h *AuthHandler) UpdatePassword(c *fiber.Ctx) error {
 	var req model.UpdatePasswordRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		log.Printf("[ERROR] BodyParser failed - Path: %s, Error: %v", c.Path(), err)
+		slog.ErrorContext(c.UserContext(), "body parser failed", "path", c.Path(), "error", err)
 		return helper.BadRequestResponse(c, "Invalid request body")
 	}
 
@@ -230,9 +248,9 @@ func (h *AuthHandler) UpdatePassword(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.authService.UpdatePassword(req.Token, req.NewPassword, req.ConfirmPassword); err != nil {
+	if err := h.authService.UpdatePassword(c.UserContext(), req.Token, req.NewPassword, req.ConfirmPassword); err != nil {
 		statusCode := service.GetStatusCode(err)
-		log.Printf("[ERROR] UpdatePassword failed - Status: %d, Error: %v", statusCode, err)
+		slog.ErrorContext(c.UserContext(), "update password failed", "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
@@ -243,7 +261,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 	var req model.RefreshTokenRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		log.Printf("[ERROR] BodyParser failed - Path: %s, Error: %v", c.Path(), err)
+		slog.ErrorContext(c.UserContext(), "body parser failed", "path", c.Path(), "error", err)
 		return helper.BadRequestResponse(c, "Invalid request body")
 	}
 
@@ -251,10 +269,10 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	refreshResponse, err := h.authService.RefreshToken(req.RefreshToken)
+	refreshResponse, err := h.authService.RefreshToken(c.UserContext(), req.RefreshToken)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
-		log.Printf("[ERROR] RefreshToken failed - Status: %d, Error: %v", statusCode, err)
+		slog.ErrorContext(c.UserContext(), "refresh token failed", "status", statusCode, "error", err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
@@ -269,6 +287,7 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	// Get user_id from JWT middleware locals
 	userID, ok := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals("session_id").(string)
 	if !ok || userID == "" {
 		// Try to extract from Authorization header directly
 		authHeader := c.Get("Authorization")
@@ -281,13 +300,14 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		// Decode JWT to get user_id from encrypted token claim
 		claims, err := helper.ParseAuthTokenClaims(tokenStr)
 		if err != nil {
-			log.Printf("[ERROR] Failed to parse token for logout - Error: %v", err)
+			slog.ErrorContext(c.UserContext(), "failed to parse token for logout", "error", err)
 			return helper.BadRequestResponse(c, "Invalid token")
 		}
 		if claims.Token != "" {
 			data, derr := helper.DecryptAuthSensitiveData(claims.Token)
 			if derr == nil {
 				userID = data.UserID
+				sessionID = data.SessionID
 			}
 		}
 		if userID == "" {
@@ -295,8 +315,8 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.authService.Logout(userID); err != nil {
-		log.Printf("[ERROR] Logout failed - UserID: %s, Error: %v", userID, err)
+	if err := h.authService.Logout(userID, sessionID); err != nil {
+		slog.ErrorContext(c.UserContext(), "logout failed", "user_id", userID, "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to logout")
 	}
 
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"log/slog"
+
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoginTwoFactor handles POST /api/auth/login/2fa
+func (h *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var req model.LoginTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "body parser failed", "path", c.Path(), "error", err)
+		return helper.BadRequestResponse(c, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	loginResponse, err := h.authService.VerifyLoginTwoFactor(c.UserContext(), req.ChallengeToken, req.Code)
+	if err != nil {
+		statusCode := service.GetStatusCode(err)
+		slog.ErrorContext(c.UserContext(), "login two factor failed", "status", statusCode, "error", err)
+		return helper.SendErrorResponse(c, statusCode, err.Error())
+	}
+
+	c.Locals("auth_token", loginResponse.Token)
+	responseData := map[string]interface{}{
+		"token":         loginResponse.Token,
+		"refresh_token": loginResponse.RefreshToken,
+		"session_id":    loginResponse.SessionID,
+		"username":      loginResponse.Username,
+		"fullname":      loginResponse.Fullname,
+		"avatar":        loginResponse.Avatar,
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Login successful.", responseData)
+}
+
+// TwoFactorStatus handles GET /api/auth/2fa
+func (h *AuthHandler) TwoFactorStatus(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	res, err := h.authService.TwoFactorStatus(c.UserContext(), userID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Two-factor status loaded", res)
+}
+
+// TwoFactorSetup handles POST /api/auth/2fa/setup
+func (h *AuthHandler) TwoFactorSetup(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	res, err := h.authService.SetupTwoFactor(c.UserContext(), userID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Scan the QR code with your authenticator app, then confirm with a code.", res)
+}
+
+// TwoFactorEnable handles POST /api/auth/2fa/enable
+func (h *AuthHandler) TwoFactorEnable(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	var req model.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+	res, err := h.authService.EnableTwoFactor(c.UserContext(), userID, req.Code)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication enabled. Store your recovery codes somewhere safe.", res)
+}
+
+// TwoFactorDisable handles POST /api/auth/2fa/disable
+func (h *AuthHandler) TwoFactorDisable(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	var req model.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+	if err := h.authService.DisableTwoFactor(c.UserContext(), userID, req.Password, req.Code); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication disabled.", nil)
+}
+
+// TwoFactorRecoveryCodes handles POST /api/auth/2fa/recovery-codes
+func (h *AuthHandler) TwoFactorRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	var req model.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+	res, err := h.authService.RegenerateRecoveryCodes(c.UserContext(), userID, req.Code)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Recovery codes regenerated. The old codes no longer work.", res)
+}
+
+// ListSessions handles GET /api/auth/sessions
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	sessionID, _ := c.Locals("session_id").(string)
+	res, err := h.authService.ListSessions(c.UserContext(), userID, sessionID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Sessions loaded", res)
+}
+
+// RevokeSession handles POST /api/auth/sessions/revoke
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	var req model.RevokeSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+	if err := h.authService.RevokeSession(c.UserContext(), userID, req.SessionID); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Session revoked.", nil)
+}
+
+// RevokeOtherSessions handles POST /api/auth/sessions/revoke-others
+func (h *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid user context")
+	}
+	sessionID, _ := c.Locals("session_id").(string)
+	revoked, err := h.authService.RevokeOtherSessions(c.UserContext(), userID, sessionID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Other sessions revoked.", map[string]interface{}{"revoked": revoked})
+}
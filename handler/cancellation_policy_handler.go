@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CancellationPolicyHandler struct {
+	service *service.CancellationPolicyService
+}
+
+func NewCancellationPolicyHandler(s *service.CancellationPolicyService) *CancellationPolicyHandler {
+	return &CancellationPolicyHandler{service: s}
+}
+
+func (h *CancellationPolicyHandler) Get(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	policy, err := h.service.GetPolicy(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation policy loaded", policy)
+}
+
+func (h *CancellationPolicyHandler) History(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.ListVersions(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation policy history loaded", items)
+}
+
+func (h *CancellationPolicyHandler) Save(c *fiber.Ctx) error {
+	var req model.CancellationPolicySaveRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	policy, err := h.service.Save(c.UserContext(), userID, &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation policy saved", policy)
+}
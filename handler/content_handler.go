@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
@@ -37,8 +37,8 @@ func (h *ContentHandler) UpsertGeneralContent(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	if err := h.service.UpsertGeneralContent(req, orgID, userID); err != nil {
-		fmt.Println(err)
+	if err := h.service.UpsertGeneralContent(c.UserContext(), req, userID); err != nil {
+		slog.ErrorContext(c.UserContext(), "upsert general content failed", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -58,7 +58,7 @@ func (h *ContentHandler) GetGeneralContent(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	res, err := h.service.GetGeneralContent(sectionTag, orgID)
+	res, err := h.service.GetGeneralContent(c.UserContext(), sectionTag)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -79,9 +79,9 @@ func (h *ContentHandler) GetContentByParent(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	res, err := h.service.GetContentByParent(parent, orgID)
+	res, err := h.service.GetContentByParent(c.UserContext(), parent)
 	if err != nil {
-		fmt.Println(err)
+		slog.ErrorContext(c.UserContext(), "get content by parent failed", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -95,7 +95,7 @@ func (h *ContentHandler) GetContentDetailByParentAndTag(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	res, err := h.service.GetContentDetail(parent, sectionTag, orgID)
+	res, err := h.service.GetContentDetail(c.UserContext(), parent, sectionTag)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -129,7 +129,7 @@ func (h *ContentHandler) UploadContent(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	url, err := h.service.UploadContent(file, parent, sectionTag, orgID, userID)
+	url, err := h.service.UploadContent(c.UserContext(), file, parent, sectionTag, userID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -146,7 +146,7 @@ func (h *ContentHandler) GetAllGeneralContent(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	res, err := h.service.GetAllGeneralContent(orgID)
+	res, err := h.service.GetAllGeneralContent(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -163,7 +163,7 @@ func (h *ContentHandler) DeleteListByUUID(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	if err := h.service.DeleteContentByUUID(uuid, orgID); err != nil {
+	if err := h.service.DeleteContentByUUID(c.UserContext(), uuid); err != nil {
 		status := fiber.StatusInternalServerError
 		if err.Error() == "sql: no rows in result set" {
 			status = fiber.StatusNotFound
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"service-travego/helper"
+	"service-travego/internal/export"
 	"service-travego/model"
 	"service-travego/service"
 	"strconv"
@@ -27,7 +28,7 @@ func (h *CustomersHandler) ListCustomers(c *fiber.Ctx) error {
 	}
 	customerName := c.Query("customer_name")
 
-	items, err := h.service.ListCustomers(orgID, customerName)
+	items, err := h.service.ListCustomers(c.UserContext(), customerName)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -35,6 +36,39 @@ func (h *CustomersHandler) ListCustomers(c *fiber.Ctx) error {
 	return helper.SuccessResponse(c, fiber.StatusOK, "Customers loaded", items)
 }
 
+// ExportCustomers downloads the customer list, with the customer_name filter
+// of ListCustomers, as xlsx or csv
+func (h *CustomersHandler) ExportCustomers(c *fiber.Ctx) error {
+	orgID, _ := c.Locals("organization_id").(string)
+	if orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.ListCustomers(c.UserContext(), c.Query("customer_name"))
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+
+	columns := []export.Column{
+		{Header: "Nama Pelanggan"},
+		{Header: "No. Telepon"},
+		{Header: "Email"},
+		{Header: "Perusahaan"},
+		{Header: "Alamat"},
+		{Header: "Kota"},
+	}
+	return helper.SendExport(c, "pelanggan", "Pelanggan", columns, func(w export.Writer) error {
+		for _, cu := range items {
+			if err := w.WriteRow(cu.CustomerName, cu.CustomerPhone, cu.CustomerEmail, cu.CustomerCompany,
+				cu.CustomerAddress, cu.CustomerCity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (h *CustomersHandler) CreateCustomer(c *fiber.Ctx) error {
 	orgID, _ := c.Locals("organization_id").(string)
 	if orgID == "" {
@@ -89,7 +123,7 @@ func (h *CustomersHandler) CreateCustomer(c *fiber.Ctx) error {
 	}
 
 	customerID := helper.GenerateUUID()
-	if err := h.service.CreateCustomer(orgID, req, customerID); err != nil {
+	if err := h.service.CreateCustomer(c.UserContext(), req, customerID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -109,7 +143,7 @@ func (h *CustomersHandler) CustomerDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "customerid is required")
 	}
 
-	data, err := h.service.GetCustomerDetail(orgID, customerID)
+	data, err := h.service.GetCustomerDetail(c.UserContext(), customerID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -175,7 +209,7 @@ func (h *CustomersHandler) UpdateCustomer(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "customer_name, customer_phone, customer_address, customer_city is required")
 	}
 
-	if err := h.service.UpdateCustomer(orgID, customerID, req); err != nil {
+	if err := h.service.UpdateCustomer(c.UserContext(), customerID, req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -185,6 +219,9 @@ func (h *CustomersHandler) UpdateCustomer(c *fiber.Ctx) error {
 
 func (h *CustomersHandler) CustomerOrders(c *fiber.Ctx) error {
 	orgID, _ := c.Locals("organization_id").(string)
+	if orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
 	var req model.CustomerOrdersRequest
 
 	if err := c.BodyParser(&req); err != nil {
@@ -197,7 +234,7 @@ func (h *CustomersHandler) CustomerOrders(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "customer_id is required")
 	}
 
-	data, err := h.service.GetCustomerOrders(orgID, customerID, &req)
+	data, err := h.service.GetCustomerOrders(c.UserContext(), customerID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
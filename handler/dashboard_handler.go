@@ -24,7 +24,7 @@ func (h *DashboardHandler) GetPartnerSummary(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	summary, err := h.service.GetPartnerSummary(orgID)
+	summary, err := h.service.GetPartnerSummary(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -38,7 +38,7 @@ func (h *DashboardHandler) GetDashboard(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetDashboard(orgID)
+	res, err := h.service.GetDashboard(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -76,7 +76,7 @@ func (h *DashboardHandler) GetFinance(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Date range must not exceed 2 years")
 	}
 
-	res, err := h.service.GetFinance(orgID, startDate, endDate)
+	res, err := h.service.GetFinance(c.UserContext(), startDate, endDate)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -90,7 +90,7 @@ func (h *DashboardHandler) GetTopDestinations(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetTopDestinations(orgID)
+	res, err := h.service.GetTopDestinations(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -104,7 +104,7 @@ func (h *DashboardHandler) GetTopPickupCity(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetTopPickupCity(orgID)
+	res, err := h.service.GetTopPickupCity(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -118,7 +118,7 @@ func (h *DashboardHandler) GetTopFleets(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetTopFleets(orgID)
+	res, err := h.service.GetTopFleets(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -132,7 +132,7 @@ func (h *DashboardHandler) GetTopTourPackages(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetTopTourPackages(orgID)
+	res, err := h.service.GetTopTourPackages(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -146,7 +146,7 @@ func (h *DashboardHandler) GetTopDrivers(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetTopDrivers(orgID)
+	res, err := h.service.GetTopDrivers(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -160,7 +160,7 @@ func (h *DashboardHandler) GetTopCustomers(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Missing organization context")
 	}
 
-	res, err := h.service.GetTopCustomers(orgID)
+	res, err := h.service.GetTopCustomers(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
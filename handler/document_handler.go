@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DocumentHandler struct {
+	service *service.DocumentService
+}
+
+func NewDocumentHandler(s *service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{service: s}
+}
+
+// attachFile stores the optional "file" form field through the upload service and
+// sets the resulting path on the request.
+func (h *DocumentHandler) attachFile(c *fiber.Ctx, req *model.DocumentUpsertRequest) error {
+	file, err := c.FormFile("file")
+	if err != nil || file == nil {
+		return nil
+	}
+
+	tempFilePath := filepath.Join(os.TempDir(), fmt.Sprintf("document-%d%s", time.Now().UnixNano(), strings.ToLower(filepath.Ext(file.Filename))))
+	if err := c.SaveFile(file, tempFilePath); err != nil {
+		return helper.BadRequestResponse(c, "failed to save uploaded file")
+	}
+	defer os.Remove(tempFilePath)
+
+	path, err := h.service.UploadAttachment(tempFilePath)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	req.FilePath = path
+	return nil
+}
+
+func (h *DocumentHandler) List(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.List(c.UserContext(), model.DocumentListFilter{
+		OwnerType:      c.Query("owner_type"),
+		OwnerID:        c.Query("owner_id"),
+		ExpiringWithin: c.QueryInt("expiring_within", 0),
+	})
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Documents loaded", items)
+}
+
+func (h *DocumentHandler) Detail(c *fiber.Ctx) error {
+	documentID := strings.TrimSpace(c.Params("document_id"))
+	if documentID == "" {
+		return helper.BadRequestResponse(c, "document_id is required")
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	res, err := h.service.Detail(c.UserContext(), documentID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Document detail loaded", res)
+}
+
+func (h *DocumentHandler) Create(c *fiber.Ctx) error {
+	var req model.DocumentUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+	if err := h.attachFile(c, &req); err != nil {
+		return err
+	}
+
+	id, err := h.service.Create(c.UserContext(), userID, &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Document created", fiber.Map{
+		"document_id": id,
+		"file_path":   req.FilePath,
+	})
+}
+
+func (h *DocumentHandler) Update(c *fiber.Ctx) error {
+	var req model.DocumentUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+	if err := h.attachFile(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.service.Update(c.UserContext(), userID, &req); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Document updated", nil)
+}
+
+func (h *DocumentHandler) Delete(c *fiber.Ctx) error {
+	var req model.DocumentDeleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.Delete(c.UserContext(), userID, req.DocumentID); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Document deleted", nil)
+}
@@ -18,7 +18,7 @@ func (h *OrganizationHandler) EmployeeAll(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	items, err := h.orgService.EmployeeAll(orgID, "")
+	items, err := h.orgService.EmployeeAll(c.UserContext(), "")
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -31,7 +31,7 @@ func (h *OrganizationHandler) EmployeeOperations(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	items, err := h.orgService.EmployeeAll(orgID, "operation")
+	items, err := h.orgService.EmployeeAll(c.UserContext(), "operation")
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -59,7 +59,7 @@ func (h *OrganizationHandler) EmployeeOperationsHistory(c *fiber.Ctx) error {
 		period = time.Now().Format("2006-01")
 	}
 
-	data, err := h.orgService.EmployeeOperationsHistory(orgID, employeeID, period)
+	data, err := h.orgService.EmployeeOperationsHistory(c.UserContext(), employeeID, period)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -105,7 +105,7 @@ func (h *OrganizationHandler) EmployeeCreate(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	id, err := h.orgService.EmployeeCreate(orgID, userID, &req)
+	id, err := h.orgService.EmployeeCreate(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -153,7 +153,7 @@ func (h *OrganizationHandler) EmployeeUpdate(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.EmployeeUpdate(orgID, userID, &req); err != nil {
+	if err := h.orgService.EmployeeUpdate(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -169,7 +169,7 @@ func (h *OrganizationHandler) EmployeeDetail(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	it, err := h.orgService.EmployeeDetail(orgID, id)
+	it, err := h.orgService.EmployeeDetail(c.UserContext(), id)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -206,7 +206,7 @@ func (h *OrganizationHandler) EmployeeDelete(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized")
 	}
 
-	if err := h.orgService.EmployeeDelete(orgID, userID, id); err != nil {
+	if err := h.orgService.EmployeeDelete(c.UserContext(), userID, id); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -227,7 +227,7 @@ func (h *OrganizationHandler) EmployeeShiftSchedule(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	resp, err := h.orgService.EmployeeShiftSchedule(orgID, &req)
+	resp, err := h.orgService.EmployeeShiftSchedule(c.UserContext(), &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -283,7 +283,7 @@ func (h *OrganizationHandler) EmployeeShiftSetSchedule(c *fiber.Ctx) error {
 		}
 	}
 
-	out, err := h.orgService.EmployeeShiftSetSchedule(orgID, userID, &req)
+	out, err := h.orgService.EmployeeShiftSetSchedule(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
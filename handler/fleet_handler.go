@@ -3,9 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"service-travego/configs"
 	"service-travego/helper"
+	"service-travego/internal/export"
 	"service-travego/model"
 	"service-travego/repository"
 	"service-travego/service"
@@ -19,6 +21,7 @@ import (
 type FleetHandler struct {
 	service *service.FleetService
 	orgRepo *repository.OrganizationRepository
+	outbox  *service.OutboxService
 }
 
 func NewFleetHandler(s *service.FleetService, orgRepo *repository.OrganizationRepository) *FleetHandler {
@@ -28,6 +31,11 @@ func NewFleetHandler(s *service.FleetService, orgRepo *repository.OrganizationRe
 	}
 }
 
+// SetOutboxService sets the outbox the order approval emails are queued in
+func (h *FleetHandler) SetOutboxService(outbox *service.OutboxService) {
+	h.outbox = outbox
+}
+
 func (h *FleetHandler) CreateFleet(c *fiber.Ctx) error {
 	var req model.CreateFleetRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -254,7 +262,7 @@ func (h *FleetHandler) CreateFleet(c *fiber.Ctx) error {
 	if userID == "" || orgID == "" {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
-	id, err := h.service.CreateFleet(userID, orgID, &req)
+	id, err := h.service.CreateFleet(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -446,7 +454,7 @@ func (h *FleetHandler) UpdateFleet(c *fiber.Ctx) error {
 	if userID == "" || orgID == "" {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
-	if err := h.service.UpdateFleet(userID, orgID, &req); err != nil {
+	if err := h.service.UpdateFleet(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -495,7 +503,7 @@ func (h *FleetHandler) ListFleets(c *fiber.Ctx) error {
 	}
 
 	if searchType == "unit" {
-		items, err := h.service.ListFleetsForUnit(orgID, searchFor)
+		items, err := h.service.ListFleetsForUnit(c.UserContext(), searchFor)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
@@ -506,7 +514,7 @@ func (h *FleetHandler) ListFleets(c *fiber.Ctx) error {
 				fleetIDs = append(fleetIDs, items[i].FleetID)
 			}
 		}
-		ratings, err := h.service.GetFleetRatings(orgID, fleetIDs)
+		ratings, err := h.service.GetFleetRatings(c.UserContext(), fleetIDs)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
@@ -521,7 +529,7 @@ func (h *FleetHandler) ListFleets(c *fiber.Ctx) error {
 	}
 
 	req.OrganizationID = orgID
-	items, err := h.service.ListFleets(&req)
+	items, err := h.service.ListFleets(c.UserContext(), &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -532,7 +540,7 @@ func (h *FleetHandler) ListFleets(c *fiber.Ctx) error {
 			fleetIDs = append(fleetIDs, items[i].FleetID)
 		}
 	}
-	ratings, err := h.service.GetFleetRatings(orgID, fleetIDs)
+	ratings, err := h.service.GetFleetRatings(c.UserContext(), fleetIDs)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -573,7 +581,7 @@ func (h *FleetHandler) FleetAvailibility(c *fiber.Ctx) error {
 
 	fleetID := strings.TrimSpace(c.Query("fleet_id"))
 
-	available, fleets, err := h.service.GetFleetAvailibility(orgID, startDate, endDate, fleetID)
+	available, fleets, err := h.service.GetFleetAvailibility(c.UserContext(), startDate, endDate, fleetID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -603,12 +611,12 @@ func (h *FleetHandler) FleetDetail(c *fiber.Ctx) error {
 	if orgID == "" {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
-	res, err := h.service.GetFleetDetail(orgID, req.FleetID)
+	res, err := h.service.GetFleetDetail(c.UserContext(), req.FleetID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	ratings, err := h.service.GetFleetRatings(orgID, []string{req.FleetID})
+	ratings, err := h.service.GetFleetRatings(c.UserContext(), []string{req.FleetID})
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -617,7 +625,7 @@ func (h *FleetHandler) FleetDetail(c *fiber.Ctx) error {
 		res.Meta.Rating = v.Rating
 		res.Meta.TotalUlasan = v.TotalUlasan
 	}
-	reviews, err := h.service.GetFleetReviews(req.FleetID, orgID)
+	reviews, err := h.service.GetFleetReviews(c.UserContext(), req.FleetID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -646,7 +654,7 @@ func (h *FleetHandler) SetFleetActiveStatus(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.SetFleetActiveStatus(orgID, userID, req.Action, req.FleetID); err != nil {
+	if err := h.service.SetFleetActiveStatus(c.UserContext(), userID, req.Action, req.FleetID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -673,7 +681,7 @@ func (h *FleetHandler) DeleteFleet(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.DeleteFleet(orgID, userID, req.FleetID); err != nil {
+	if err := h.service.DeleteFleet(c.UserContext(), userID, req.FleetID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -686,6 +694,57 @@ func (h *FleetHandler) GetPartnerOrderList(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
+	filter := partnerOrderListFilter(c)
+	show := strings.ToLower(strings.TrimSpace(c.Query("show")))
+
+	res, err := h.service.GetPartnerOrdersWithSummary(c.UserContext(), &filter)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	if show == "summary" {
+		return helper.SuccessResponse(c, fiber.StatusOK, "Order summary loaded", res.Summary)
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Order list loaded", res)
+}
+
+// ExportPartnerOrderList downloads the order list, with the filters of
+// GetPartnerOrderList, as xlsx or csv
+func (h *FleetHandler) ExportPartnerOrderList(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	filter := partnerOrderListFilter(c)
+	ctx := c.UserContext()
+
+	columns := []export.Column{
+		{Header: "No. Pesanan"},
+		{Header: "Tanggal Pesanan", Kind: export.Date},
+		{Header: "Nama Pelanggan"},
+		{Header: "No. Telepon"},
+		{Header: "Armada"},
+		{Header: "Jenis Sewa"},
+		{Header: "Tanggal Mulai", Kind: export.Date},
+		{Header: "Tanggal Selesai", Kind: export.Date},
+		{Header: "Jumlah Unit", Kind: export.Integer},
+		{Header: "Durasi", Kind: export.Integer},
+		{Header: "Satuan"},
+		{Header: "Status Pembayaran"},
+		{Header: "Total (Rp)", Kind: export.Currency},
+	}
+	return helper.SendExport(c, "pesanan", "Pesanan", columns, func(w export.Writer) error {
+		return h.service.ExportPartnerOrders(ctx, &filter, func(o model.PartnerOrderListItem) error {
+			return w.WriteRow(o.OrderID, o.OrderDate, o.CustomerName, o.CustomerPhone, o.FleetName, o.RentType,
+				o.StartDate, o.EndDate, o.UnitQty, o.Duration, o.Uom, o.PaymentStatusLabel, o.TotalAmount)
+		})
+	})
+}
+
+// partnerOrderListFilter reads the order list filters from the query; without
+// an order date range it covers the last year
+func partnerOrderListFilter(c *fiber.Ctx) model.PartnerOrderListFilter {
 	var filter model.PartnerOrderListFilter
 	if v := strings.TrimSpace(c.Query("start_date")); v != "" {
 		filter.StartDateFrom = v
@@ -714,17 +773,7 @@ func (h *FleetHandler) GetPartnerOrderList(c *fiber.Ctx) error {
 		filter.OrderDateFrom = from.Format("2006-01-02") + " 00:00:00"
 		filter.OrderDateTo = now.Format("2006-01-02") + " 23:59:59"
 	}
-	show := strings.ToLower(strings.TrimSpace(c.Query("show")))
-
-	res, err := h.service.GetPartnerOrdersWithSummary(orgID, &filter)
-	if err != nil {
-		code := service.GetStatusCode(err)
-		return helper.SendErrorResponse(c, code, err.Error())
-	}
-	if show == "summary" {
-		return helper.SuccessResponse(c, fiber.StatusOK, "Order summary loaded", res.Summary)
-	}
-	return helper.SuccessResponse(c, fiber.StatusOK, "Order list loaded", res)
+	return filter
 }
 
 func (h *FleetHandler) GetPartnerOrderDetail(c *fiber.Ctx) error {
@@ -737,17 +786,17 @@ func (h *FleetHandler) GetPartnerOrderDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	res, err := h.service.GetPartnerOrderDetail(orderID, orgID)
+	res, err := h.service.GetPartnerOrderDetail(c.UserContext(), orderID)
 	if err != nil {
 		code := fiber.StatusInternalServerError
-		fmt.Println("Error fetching order detail:", err)
+		slog.ErrorContext(c.UserContext(), "error fetching order detail", "error", err)
 		if err.Error() == "order not found or access denied" {
 			code = fiber.StatusNotFound
 		}
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	payment, err := h.service.GetPartnerOrderPaymentSummary(orderID, orgID, res.TotalAmount)
+	payment, err := h.service.GetPartnerOrderPaymentSummary(c.UserContext(), orderID, res.TotalAmount)
 	if err != nil {
 		payment = &model.PaymentSummary{
 			PaidAmount:       0,
@@ -756,12 +805,12 @@ func (h *FleetHandler) GetPartnerOrderDetail(c *fiber.Ctx) error {
 		}
 	}
 
-	reviews, err := h.service.GetOrderReviews(orderID, orgID)
+	reviews, err := h.service.GetOrderReviews(c.UserContext(), orderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	rating, err := h.service.GetOrderRatingSummary(orderID, orgID)
+	rating, err := h.service.GetOrderRatingSummary(c.UserContext(), orderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -931,7 +980,7 @@ if v, ok := m["pickup_datetime"].(string); ok {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.UpdatePartnerOrder(orgID, userID, &req); err != nil {
+	if err := h.service.UpdatePartnerOrder(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -953,7 +1002,7 @@ func (h *FleetHandler) GetFleetPricesByFleetID(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.GetFleetPricesByFleetID(orgID, fleetID, typeID)
+	items, err := h.service.GetFleetPricesByFleetID(c.UserContext(), fleetID, typeID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -972,7 +1021,7 @@ func (h *FleetHandler) GetFleetAddonList(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.GetFleetAddonList(orgID, fleetID)
+	items, err := h.service.GetFleetAddonList(c.UserContext(), fleetID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -1158,7 +1207,7 @@ func (h *FleetHandler) CreatePartnerOrder(c *fiber.Ctx) error {
 		}
 	}
 
-	orderID, err := h.service.CreatePartnerOrder(orgID, userID, &req)
+	orderID, err := h.service.CreatePartnerOrder(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -1225,14 +1274,14 @@ func (h *FleetHandler) ProcessFleetOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	err := h.service.ProcessFleetOrder(orgID, userID, orderID, processTypeId)
+	err := h.service.ProcessFleetOrder(c.UserContext(), userID, orderID, processTypeId)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
 	if processType == "approve" {
-		orderDetail, derr := h.service.GetPartnerOrderDetail(orderID, orgID)
+		orderDetail, derr := h.service.GetPartnerOrderDetail(c.UserContext(), orderID)
 		if derr == nil && strings.TrimSpace(orderDetail.Customer.CustomerEmail) != "" {
 			tokenPayload := model.OrderTokenPayload{
 				OrderID: orderID,
@@ -1267,9 +1316,9 @@ func (h *FleetHandler) ProcessFleetOrder(c *fiber.Ctx) error {
 					destStr := strings.Join(destinations, ", ")
 
 					orgID, _ := c.Locals("organization_id").(string)
-					domainURL, derr := h.orgRepo.GetDomainURL(orgID)
+					domainURL, derr := h.orgRepo.GetDomainURL(c.UserContext())
 					if derr != nil {
-						fmt.Println("failed to get domain url:", derr)
+						slog.ErrorContext(c.UserContext(), "failed to get domain URL", "error", derr)
 					}
 
 					baseURL := ""
@@ -1291,11 +1340,13 @@ func (h *FleetHandler) ProcessFleetOrder(c *fiber.Ctx) error {
 						OrderDetailUrl: fmt.Sprintf("%s/order/detail/armada/%s", baseURL, token),
 					}
 
-					go func() {
-						if err := helper.SendOrderApprovedEmail(emailCfg, orderDetail.Customer.CustomerEmail, orgName, emailData); err != nil {
-							fmt.Println("failed to send approved order email:", err)
-						}
-					}()
+					approvedEmail, err := helper.NewOrderApprovedEmail(orderDetail.Customer.CustomerEmail, orgName, emailData)
+					if err == nil {
+						err = h.outbox.EnqueueEmail(c.UserContext(), orgID, service.OutboxKindOrderApproved, approvedEmail)
+					}
+					if err != nil {
+						slog.ErrorContext(c.UserContext(), "failed to queue approved order email", "error", err)
+					}
 				}
 			}
 		}
@@ -1339,7 +1390,7 @@ func (h *FleetHandler) FleetRevenue(c *fiber.Ctx) error {
 		prevStart := prevT.Format("2006-01-02")
 		prevEnd := prevT.AddDate(0, 1, -1).Format("2006-01-02")
 
-		currRev, err := h.service.GetFleetRevenue(orgID, req.FleetIDID, currentStart, currentEnd)
+		currRev, err := h.service.GetFleetRevenue(c.UserContext(), req.FleetIDID, currentStart, currentEnd)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
@@ -1348,7 +1399,7 @@ func (h *FleetHandler) FleetRevenue(c *fiber.Ctx) error {
 		currRev.StartDate = ""
 		currRev.EndDate = ""
 
-		prevRev, err := h.service.GetFleetRevenue(orgID, req.FleetIDID, prevStart, prevEnd)
+		prevRev, err := h.service.GetFleetRevenue(c.UserContext(), req.FleetIDID, prevStart, prevEnd)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
@@ -1360,7 +1411,7 @@ func (h *FleetHandler) FleetRevenue(c *fiber.Ctx) error {
 		return helper.SuccessResponse(c, fiber.StatusOK, "Fleet revenue", []interface{}{currRev, prevRev})
 	}
 
-	revenue, err := h.service.GetFleetRevenue(orgID, req.FleetIDID, req.StartDate, req.EndDate)
+	revenue, err := h.service.GetFleetRevenue(c.UserContext(), req.FleetIDID, req.StartDate, req.EndDate)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -1397,7 +1448,7 @@ func (h *FleetHandler) OrderAvailability(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	res, err := h.service.GetOrderAvailability(orgID, req.FleetID, req.CityID, req.StartDate, req.EndDate, req.ServiceType)
+	res, err := h.service.GetOrderAvailability(c.UserContext(), req.FleetID, req.CityID, req.StartDate, req.EndDate, req.ServiceType)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -1428,8 +1479,9 @@ func (h *FleetHandler) DeleteFleetOrderAddon(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
+	userID, _ := c.Locals("user_id").(string)
 
-	if err := h.service.DeleteFleetOrderAddon(orgID, &req); err != nil {
+	if err := h.service.DeleteFleetOrderAddon(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -1446,7 +1498,7 @@ func (h *FleetHandler) CancelPartnerOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 	userID, _ := c.Locals("user_id").(string)
-	if err := h.service.CancelPartnerOrder(orgID, userID, &req); err != nil {
+	if err := h.service.CancelPartnerOrder(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -1463,7 +1515,7 @@ func (h *FleetHandler) CancelPartnerOrderDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 	userID, _ := c.Locals("user_id").(string)
-	res, err := h.service.CancelPartnerOrderDetail(orgID, userID, req.OrderID)
+	res, err := h.service.CancelPartnerOrderDetail(c.UserContext(), userID, req.OrderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -1471,12 +1523,66 @@ func (h *FleetHandler) CancelPartnerOrderDetail(c *fiber.Ctx) error {
 	return helper.SuccessResponse(c, fiber.StatusOK, "Order detail retrieved", res)
 }
 
+func (h *FleetHandler) GetCancellationRequests(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	status := c.QueryInt("status", 0)
+	items, err := h.service.GetCancellationRequests(c.UserContext(), status)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation requests retrieved", items)
+}
+
+func (h *FleetHandler) ApproveCancellationRequest(c *fiber.Ctx) error {
+	var req model.OrderCancellationReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.service.ApproveCancellationRequest(c.UserContext(), userID, &req); err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation request approved", nil)
+}
+
+func (h *FleetHandler) RejectCancellationRequest(c *fiber.Ctx) error {
+	var req model.OrderCancellationReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.service.RejectCancellationRequest(c.UserContext(), userID, &req); err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation request rejected", nil)
+}
+
 func (h *FleetHandler) GetFacilityList(c *fiber.Ctx) error {
 	orgID, _ := c.Locals("organization_id").(string)
 	if orgID == "" {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
-	items, err := h.service.GetFacilityList(orgID)
+	items, err := h.service.GetFacilityList(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -3,7 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
@@ -32,7 +32,7 @@ func (h *FleetUnitHandler) List(c *fiber.Ctx) error {
 	orderID := strings.TrimSpace(c.Query("order_id"))
 	search := strings.TrimSpace(c.Query("search"))
 
-	items, err := h.service.List(orgID, fleetId, orderID, search)
+	items, err := h.service.List(c.UserContext(), fleetId, orderID, search)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -80,9 +80,9 @@ func (h *FleetUnitHandler) Create(c *fiber.Ctx) error {
 			return helper.BadRequestResponse(c, "missing user context")
 		}
 
-		ids, err := h.service.CreateBatch(orgID, userID, batch.FleetID, batch.Units)
+		ids, err := h.service.CreateBatch(c.UserContext(), userID, batch.FleetID, batch.Units)
 		if err != nil {
-			log.Printf("[ERROR] TransactionID: %s - CreateFleetUnitBatch - Error: %v", helper.GetTransactionID(c), err)
+			slog.ErrorContext(c.UserContext(), "create fleet unit batch failed", "error", err)
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
 		}
@@ -108,9 +108,9 @@ func (h *FleetUnitHandler) Create(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user context")
 	}
 
-	id, err := h.service.Create(orgID, userID, &req)
+	id, err := h.service.Create(c.UserContext(), userID, &req)
 	if err != nil {
-		log.Printf("[ERROR] TransactionID: %s - CreateFleetUnit - Error: %v", helper.GetTransactionID(c), err)
+		slog.ErrorContext(c.UserContext(), "create fleet unit failed", "error", err)
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -137,7 +137,7 @@ func (h *FleetUnitHandler) Update(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user context")
 	}
 
-	if err := h.service.Update(orgID, userID, &req); err != nil {
+	if err := h.service.Update(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -153,17 +153,17 @@ func (h *FleetUnitHandler) Detail(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
-	res, err := h.service.Detail(orgID, id)
+	res, err := h.service.Detail(c.UserContext(), id)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	rating, err := h.service.UnitRating(orgID, id)
+	rating, err := h.service.UnitRating(c.UserContext(), id)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	reviews, err := h.service.UnitReviews(orgID, id)
+	reviews, err := h.service.UnitReviews(c.UserContext(), id)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -191,12 +191,12 @@ func (h *FleetUnitHandler) OrderHistory(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.UnitOrderHistory(orgID, strings.TrimSpace(req.UnitID), strings.TrimSpace(req.StartDate), strings.TrimSpace(req.EndDate))
+	items, err := h.service.UnitOrderHistory(c.UserContext(), strings.TrimSpace(req.UnitID), strings.TrimSpace(req.StartDate), strings.TrimSpace(req.EndDate))
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	totalSchedules, latestSchedule, upcomingSchedule, err := h.service.UnitScheduleStats(orgID, strings.TrimSpace(req.UnitID))
+	totalSchedules, latestSchedule, upcomingSchedule, err := h.service.UnitScheduleStats(c.UserContext(), strings.TrimSpace(req.UnitID))
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -244,21 +244,21 @@ func (h *FleetUnitHandler) UnitRevenue(c *fiber.Ctx) error {
 		prevStart := prevT.Format("2006-01-02")
 		prevEnd := prevT.AddDate(0, 1, -1).Format("2006-01-02")
 
-		currRev, err := h.service.GetUnitRevenue(orgID, req.UnitID, currentStart, currentEnd)
+		currRev, err := h.service.GetUnitRevenue(c.UserContext(), req.UnitID, currentStart, currentEnd)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
 		}
 		currRev.Period = formatFleetUnitPeriodIndonesian(t)
 
-		prevRev, err := h.service.GetUnitRevenue(orgID, req.UnitID, prevStart, prevEnd)
+		prevRev, err := h.service.GetUnitRevenue(c.UserContext(), req.UnitID, prevStart, prevEnd)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
 		}
 		prevRev.Period = formatFleetUnitPeriodIndonesian(prevT)
 
-		history, err := h.service.GetUnitRevenueHistory(orgID, req.UnitID, currentStart, currentEnd)
+		history, err := h.service.GetUnitRevenueHistory(c.UserContext(), req.UnitID, currentStart, currentEnd)
 		if err != nil {
 			code := service.GetStatusCode(err)
 			return helper.SendErrorResponse(c, code, err.Error())
@@ -300,7 +300,7 @@ func (h *FleetUnitHandler) UnitExpenses(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.UnitExpenses(orgID, req.UnitID, req.Period)
+	items, err := h.service.UnitExpenses(c.UserContext(), req.UnitID, req.Period)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -315,3 +315,270 @@ func (h *FleetUnitHandler) UnitExpenses(c *fiber.Ctx) error {
 	}
 	return helper.SuccessResponse(c, fiber.StatusOK, "Fleet unit expenses loaded", res)
 }
+
+func (h *FleetUnitHandler) RecordOdometer(c *fiber.Ctx) error {
+	var req model.FleetUnitOdometerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	id, err := h.service.RecordOdometer(c.UserContext(), userID, &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Odometer recorded", fiber.Map{
+		"reading_id": id,
+	})
+}
+
+func (h *FleetUnitHandler) OdometerHistory(c *fiber.Ctx) error {
+	unitID := strings.TrimSpace(c.Params("unit_id"))
+	if unitID == "" {
+		return helper.BadRequestResponse(c, "unit_id is required")
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.ListOdometer(c.UserContext(), unitID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Odometer history loaded", items)
+}
+
+func (h *FleetUnitHandler) ServicePlans(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	unitID := strings.TrimSpace(c.Query("unit_id"))
+	dueOnly := c.QueryBool("due_only", false)
+
+	items, err := h.service.ListServicePlans(c.UserContext(), unitID, dueOnly)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Service plans loaded", items)
+}
+
+func (h *FleetUnitHandler) CreateServicePlan(c *fiber.Ctx) error {
+	var req model.FleetUnitServicePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	id, err := h.service.CreateServicePlan(c.UserContext(), userID, &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Service plan created", fiber.Map{
+		"plan_id": id,
+	})
+}
+
+func (h *FleetUnitHandler) UpdateServicePlan(c *fiber.Ctx) error {
+	var req model.FleetUnitServicePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.UpdateServicePlan(c.UserContext(), userID, &req); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Service plan updated", nil)
+}
+
+func (h *FleetUnitHandler) DeleteServicePlan(c *fiber.Ctx) error {
+	var req model.FleetUnitServicePlanDeleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.DeleteServicePlan(c.UserContext(), userID, req.PlanID); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Service plan deleted", nil)
+}
+
+func (h *FleetUnitHandler) CreateMaintenance(c *fiber.Ctx) error {
+	var req model.FleetUnitMaintenanceCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	id, err := h.service.CreateMaintenance(c.UserContext(), userID, &req)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "create maintenance failed", "error", err)
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Maintenance created", fiber.Map{
+		"maintenance_id": id,
+	})
+}
+
+func (h *FleetUnitHandler) MaintenanceDetail(c *fiber.Ctx) error {
+	maintenanceID := strings.TrimSpace(c.Params("maintenance_id"))
+	if maintenanceID == "" {
+		return helper.BadRequestResponse(c, "maintenance_id is required")
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	res, err := h.service.MaintenanceDetail(c.UserContext(), maintenanceID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Maintenance detail loaded", res)
+}
+
+func (h *FleetUnitHandler) MaintenanceHistory(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	unitID := strings.TrimSpace(c.Query("unit_id"))
+
+	var status *int
+	if raw := strings.TrimSpace(c.Query("status")); raw != "" {
+		v := c.QueryInt("status", -1)
+		if v < 0 {
+			return helper.BadRequestResponse(c, "status is invalid")
+		}
+		status = &v
+	}
+
+	items, err := h.service.MaintenanceHistory(c.UserContext(), unitID, status)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Maintenance history loaded", items)
+}
+
+func (h *FleetUnitHandler) CheckInMaintenance(c *fiber.Ctx) error {
+	var req model.FleetUnitMaintenanceStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.CheckInMaintenance(c.UserContext(), userID, req.MaintenanceID); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Unit checked in to workshop", nil)
+}
+
+func (h *FleetUnitHandler) CompleteMaintenance(c *fiber.Ctx) error {
+	var req model.FleetUnitMaintenanceCompleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.CompleteMaintenance(c.UserContext(), userID, &req); err != nil {
+		slog.ErrorContext(c.UserContext(), "complete maintenance failed", "error", err)
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Maintenance completed", nil)
+}
+
+func (h *FleetUnitHandler) CancelMaintenance(c *fiber.Ctx) error {
+	var req model.FleetUnitMaintenanceStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.CancelMaintenance(c.UserContext(), userID, req.MaintenanceID); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Maintenance cancelled", nil)
+}
@@ -26,7 +26,7 @@ func (h *GarageHandler) GetGarages(c *fiber.Ctx) error {
 
 	itemID := c.Query("item_id", "")
 
-	garages, err := h.garageService.GetGarages(orgID, itemID)
+	garages, err := h.garageService.GetGarages(c.UserContext(), itemID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load garages")
 	}
@@ -54,7 +54,7 @@ func (h *GarageHandler) CreateGarage(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	garage, err := h.garageService.CreateGarage(orgID, userID, &req)
+	garage, err := h.garageService.CreateGarage(c.UserContext(), userID, &req)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -86,7 +86,7 @@ func (h *GarageHandler) UpdateGarage(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	garage, err := h.garageService.UpdateGarage(req.GarageID, orgID, userID, &req)
+	garage, err := h.garageService.UpdateGarage(c.UserContext(), req.GarageID, userID, &req)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -113,7 +113,7 @@ func (h *GarageHandler) DeleteGarage(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.garageService.DeleteGarage(req.GarageID, orgID); err != nil {
+	if err := h.garageService.DeleteGarage(c.UserContext(), req.GarageID); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -3,6 +3,7 @@ package handler
 import (
 	"service-travego/helper"
 	"service-travego/model"
+	"service-travego/repository"
 	"service-travego/service"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ type GeneralHandler struct {
 	fleetTypeService      *service.FleetTypeService
 	fleetMetaService      *service.FleetMetaService
 	preferenceCityService *service.PreferenceCityService
+	orgUserRepo           *repository.OrganizationUserRepository
 }
 
 func NewGeneralHandler(generalService *service.GeneralService) *GeneralHandler {
@@ -35,6 +37,10 @@ func (h *GeneralHandler) SetPreferenceCityService(s *service.PreferenceCityServi
 	h.preferenceCityService = s
 }
 
+func (h *GeneralHandler) SetOrganizationUserRepository(r *repository.OrganizationUserRepository) {
+	h.orgUserRepo = r
+}
+
 func (h *GeneralHandler) GetGeneralConfig(c *fiber.Ctx) error {
 	config, err := h.generalService.GetGeneralConfig()
 	if err != nil {
@@ -45,7 +51,7 @@ func (h *GeneralHandler) GetGeneralConfig(c *fiber.Ctx) error {
 }
 
 func (h *GeneralHandler) GetBankList(c *fiber.Ctx) error {
-	list, err := h.generalService.GetBankList()
+	list, err := h.generalService.GetBankList(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load bank list")
 	}
@@ -53,7 +59,13 @@ func (h *GeneralHandler) GetBankList(c *fiber.Ctx) error {
 }
 
 func (h *GeneralHandler) GetWebMenu(c *fiber.Ctx) error {
-	menu, err := h.generalService.GetWebMenu()
+	var perms []string
+	if userID, _ := c.Locals("user_id").(string); userID != "" && h.orgUserRepo != nil {
+		// Users without an active membership only see the public menu
+		perms, _ = helper.MemberPermissions(c, h.orgUserRepo)
+	}
+
+	menu, err := h.generalService.GetWebMenuFor(perms)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load web menu")
 	}
@@ -94,7 +106,7 @@ func (h *GeneralHandler) GetFleetTypes(c *fiber.Ctx) error {
 	if h.fleetTypeService == nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Fleet type service not configured")
 	}
-	types, err := h.fleetTypeService.GetAllFleetTypes()
+	types, err := h.fleetTypeService.GetAllFleetTypes(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load fleet types")
 	}
@@ -110,7 +122,7 @@ func (h *GeneralHandler) GetFleetBodies(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "missing organization context")
 	}
 	search := c.Query("search", "")
-	list, err := h.fleetMetaService.GetBodies(orgID, search)
+	list, err := h.fleetMetaService.GetBodies(c.UserContext(), search)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load fleet bodies")
 	}
@@ -126,7 +138,7 @@ func (h *GeneralHandler) GetFleetEngines(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "missing organization context")
 	}
 	search := c.Query("search", "")
-	list, err := h.fleetMetaService.GetEngines(orgID, search)
+	list, err := h.fleetMetaService.GetEngines(c.UserContext(), search)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load fleet engines")
 	}
@@ -239,7 +251,7 @@ func (h *GeneralHandler) GetPreferenceCities(c *fiber.Ctx) error {
 		serviceType = &mappedType
 	}
 
-	list, err := h.generalService.GetPreferenceCities(orgID, cityID, serviceType)
+	list, err := h.generalService.GetPreferenceCities(c.UserContext(), cityID, serviceType)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load preference cities: "+err.Error())
 	}
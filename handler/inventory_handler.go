@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"service-travego/helper"
-	"service-travego/internal/wagy"
+	"service-travego/internal/export"
 	"service-travego/model"
 	"service-travego/service"
 	"strconv"
@@ -14,16 +14,18 @@ import (
 )
 
 type InventoryHandler struct {
-	service    *service.InventoryService
-	wagyClient *wagy.WagyClient
+	service *service.InventoryService
+	outbox  *service.OutboxService
 }
 
 func NewInventoryHandler(s *service.InventoryService) *InventoryHandler {
 	return &InventoryHandler{service: s}
 }
 
-func (h *InventoryHandler) SetWagyClient(wagyClient *wagy.WagyClient) {
-	h.wagyClient = wagyClient
+// SetOutboxService sets the outbox the request WhatsApp notifications are
+// queued in
+func (h *InventoryHandler) SetOutboxService(outbox *service.OutboxService) {
+	h.outbox = outbox
 }
 
 func (h *InventoryHandler) GetItems(c *fiber.Ctx) error {
@@ -40,7 +42,7 @@ func (h *InventoryHandler) GetItems(c *fiber.Ctx) error {
 		}
 	}
 
-	items, err := h.service.GetItems(orgID, itemCategory)
+	items, err := h.service.GetItems(c.UserContext(), itemCategory)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -62,7 +64,7 @@ func (h *InventoryHandler) GetAllItems(c *fiber.Ctx) error {
 		}
 	}
 
-	items, err := h.service.GetAllItems(orgID, itemCategory, "all")
+	items, err := h.service.GetAllItems(c.UserContext(), itemCategory, "all")
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -76,7 +78,7 @@ func (h *InventoryHandler) GenerateSKU(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	itemSKU, err := h.service.GenerateItemSKU(orgID)
+	itemSKU, err := h.service.GenerateItemSKU(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -183,7 +185,7 @@ func (h *InventoryHandler) CreateItem(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	item, err := h.service.CreateItem(orgID, userID, &req)
+	item, err := h.service.CreateItem(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -201,7 +203,7 @@ func (h *InventoryHandler) handleCreateItemWithID(c *fiber.Ctx, req model.Create
 		return helper.BadRequestResponse(c, "garage_id is required when item_id is provided")
 	}
 
-	item, err := h.service.CreateItem(orgID, userID, &req)
+	item, err := h.service.CreateItem(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -241,7 +243,7 @@ func (h *InventoryHandler) UpdateItem(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	item, err := h.service.UpdateItem(orgID, userID, &req)
+	item, err := h.service.UpdateItem(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -268,7 +270,8 @@ func (h *InventoryHandler) DeleteItem(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.DeleteItem(orgID, req.ItemID); err != nil {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.service.DeleteItem(c.UserContext(), userID, req.ItemID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -302,7 +305,7 @@ func (h *InventoryHandler) TransferItem(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.TransferItem(orgID, userID, &req); err != nil {
+	if err := h.service.TransferItem(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -333,7 +336,7 @@ func (h *InventoryHandler) GetItemDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "item_id is required")
 	}
 
-	item, err := h.service.GetItemDetail(req.ItemID)
+	item, err := h.service.GetItemDetail(c.UserContext(), req.ItemID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -368,7 +371,7 @@ func (h *InventoryHandler) GetItemOrderHistory(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	histories, err := h.service.GetItemOrderHistory(orgID, req.ItemID, req.StartDate, req.EndDate)
+	histories, err := h.service.GetItemOrderHistory(c.UserContext(), req.ItemID, req.StartDate, req.EndDate)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -406,7 +409,7 @@ func (h *InventoryHandler) GetItemMovements(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	movements, err := h.service.GetItemMovements(orgID, req.ItemID, req.StartDate, req.EndDate, req.GarageID)
+	movements, err := h.service.GetItemMovements(c.UserContext(), req.ItemID, req.StartDate, req.EndDate, req.GarageID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -414,13 +417,66 @@ func (h *InventoryHandler) GetItemMovements(c *fiber.Ctx) error {
 	return helper.SuccessResponse(c, fiber.StatusOK, "Movements loaded", movements)
 }
 
+// ExportItemMovements downloads the stock movements of an item as xlsx or
+// csv. It takes the filters of GetItemMovements as query parameters.
+func (h *InventoryHandler) ExportItemMovements(c *fiber.Ctx) error {
+	req := model.GetItemMovementRequest{
+		ItemID:    strings.TrimSpace(c.Query("item_id")),
+		StartDate: strings.TrimSpace(c.Query("start_date")),
+		EndDate:   strings.TrimSpace(c.Query("end_date")),
+		GarageID:  strings.TrimSpace(c.Query("garage_id")),
+	}
+	if req.ItemID == "" {
+		return helper.BadRequestResponse(c, "item_id is required")
+	}
+
+	orgID, _ := c.Locals("organization_id").(string)
+	if orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	movements, err := h.service.GetItemMovements(c.UserContext(), req.ItemID, req.StartDate, req.EndDate, req.GarageID)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+
+	columns := []export.Column{
+		{Header: "Tanggal", Kind: export.DateTime},
+		{Header: "Garasi"},
+		{Header: "Jenis Pergerakan"},
+		{Header: "Masuk/Keluar"},
+		{Header: "Jumlah", Kind: export.Integer},
+		{Header: "Stok Awal", Kind: export.Integer},
+		{Header: "Stok Akhir", Kind: export.Integer},
+		{Header: "Satuan"},
+		{Header: "Catatan"},
+	}
+	return helper.SendExport(c, "pergerakan_stok", "Pergerakan Stok", columns, func(w export.Writer) error {
+		for _, m := range movements {
+			direction := ""
+			switch m.Label {
+			case "+":
+				direction = "Masuk"
+			case "-":
+				direction = "Keluar"
+			}
+			if err := w.WriteRow(m.MovementDate, m.GarageName, m.MovementType, direction, m.Quantity,
+				m.StockBefore, m.StockFinal, m.ItemUom, m.Notes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (h *InventoryHandler) GetRequests(c *fiber.Ctx) error {
 	orgID, _ := c.Locals("organization_id").(string)
 	if orgID == "" {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	requests, err := h.service.GetRequests(orgID)
+	requests, err := h.service.GetRequests(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -483,18 +539,18 @@ func (h *InventoryHandler) CreateRequest(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	request, err := h.service.CreateRequest(orgID, userID, &req)
+	request, err := h.service.CreateRequest(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	if h.wagyClient != nil {
-		adminPhone, phoneErr := h.service.GetAdminPhone(orgID)
+	if h.outbox != nil {
+		adminPhone, phoneErr := h.service.GetAdminPhone(c.UserContext())
 		if phoneErr == nil && adminPhone != "" {
 			normalized := service.NormalizeAssistantAccountNumber(adminPhone)
 			message := fmt.Sprintf("Ada permintaan item %s untuk garasi dengan jumlah %d", request.ItemName, request.Quantity)
-			go h.wagyClient.SendMessage(normalized, message)
+			_ = h.outbox.EnqueueWhatsApp(c.UserContext(), orgID, service.OutboxKindInventoryRequest, normalized, message)
 		}
 	}
 
@@ -524,7 +580,7 @@ func (h *InventoryHandler) ApproveRequest(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.ApproveRequest(orgID, userID, &req); err != nil {
+	if err := h.service.ApproveRequest(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -555,7 +611,7 @@ func (h *InventoryHandler) CompleteRequest(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.ReceiveRequestItem(orgID, userID, req.EmployeeID, req.RequestID); err != nil {
+	if err := h.service.ReceiveRequestItem(c.UserContext(), userID, req.EmployeeID, req.RequestID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -581,18 +637,18 @@ func (h *InventoryHandler) RejectRequest(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.RejectRequest(orgID, userID, &req); err != nil {
+	if err := h.service.RejectRequest(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	if h.wagyClient != nil {
-		inventoryReq, getErr := h.service.GetRequestForApprove(req.RequestID, orgID)
+	if h.outbox != nil {
+		inventoryReq, getErr := h.service.GetRequestForApprove(c.UserContext(), req.RequestID)
 		if getErr == nil && inventoryReq.EmployeeID != "" {
-			phone, phoneErr := h.service.GetEmployeePhone(inventoryReq.EmployeeID)
+			phone, phoneErr := h.service.GetEmployeePhone(c.UserContext(), inventoryReq.EmployeeID)
 			if phoneErr == nil && phone != "" {
 				message := fmt.Sprintf("Permintaan dengan request_id %s telah ditolak", req.RequestID)
-				go h.wagyClient.SendMessage(phone, message)
+				_ = h.outbox.EnqueueWhatsApp(c.UserContext(), orgID, service.OutboxKindInventoryRejected, phone, message)
 			}
 		}
 	}
@@ -617,7 +673,7 @@ func (h *InventoryHandler) GetRequestDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	request, err := h.service.GetRequest(req.RequestID, orgID)
+	request, err := h.service.GetRequest(c.UserContext(), req.RequestID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -646,7 +702,7 @@ func (h *InventoryHandler) UpdateRequest(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.UpdateRequest(orgID, userID, &req); err != nil {
+	if err := h.service.UpdateRequest(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -700,7 +756,7 @@ func (h *InventoryHandler) SubmitRequestOrders(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "item_uom is required when item_name is provided")
 	}
 
-	order, err := h.service.SubmitRequestOrder(orgID, userID, &req)
+	order, err := h.service.SubmitRequestOrder(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -734,7 +790,7 @@ func (h *InventoryHandler) CompleteOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "purchase_id is required")
 	}
 
-	if err := h.service.ReceiveRequest(orgID, userID, &model.ReceiveInventoryOrderRequest{PurchaseID: req.PurchaseID}); err != nil {
+	if err := h.service.ReceiveRequest(c.UserContext(), userID, &model.ReceiveInventoryOrderRequest{PurchaseID: req.PurchaseID}); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -748,7 +804,7 @@ func (h *InventoryHandler) GetOrders(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	orders, err := h.service.GetOrders(orgID)
+	orders, err := h.service.GetOrders(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -775,7 +831,7 @@ func (h *InventoryHandler) GetOrderDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	order, err := h.service.GetOrder(req.PurchaseID, orgID)
+	order, err := h.service.GetOrder(c.UserContext(), req.PurchaseID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -804,7 +860,7 @@ func (h *InventoryHandler) SubmitOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	order, err := h.service.SubmitOrder(orgID, userID, &req)
+	order, err := h.service.SubmitOrder(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -818,7 +874,7 @@ func (h *InventoryHandler) GetSuppliers(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	suppliers, err := h.service.GetSuppliers(orgID)
+	suppliers, err := h.service.GetSuppliers(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -856,7 +912,7 @@ func (h *InventoryHandler) CreateSupplier(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	supplier, err := h.service.CreateSupplier(orgID, userID, &req)
+	supplier, err := h.service.CreateSupplier(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -880,7 +936,7 @@ func (h *InventoryHandler) GetSupplierDetail(c *fiber.Ctx) error {
 		}
 	}
 
-	supplier, err := h.service.GetSupplier(req.SupplierID)
+	supplier, err := h.service.GetSupplier(c.UserContext(), req.SupplierID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -900,7 +956,8 @@ func (h *InventoryHandler) DeleteSupplier(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.service.DeleteSupplier("", req.SupplierID); err != nil {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.service.DeleteSupplier(c.UserContext(), userID, req.SupplierID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -927,7 +984,7 @@ func (h *InventoryHandler) CancelOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user or organization context")
 	}
 
-	if err := h.service.CancelOrder(orgID, userID, req.PurchaseID); err != nil {
+	if err := h.service.CancelOrder(c.UserContext(), userID, req.PurchaseID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -20,7 +20,7 @@ func NewLeaveManagementHandler(s *service.LeaveManagementService) *LeaveManageme
 }
 
 func (h *LeaveManagementHandler) GetLeaveTypes(c *fiber.Ctx) error {
-	data, err := h.service.GetLeaveTypes()
+	data, err := h.service.GetLeaveTypes(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -37,7 +37,7 @@ func (h *LeaveManagementHandler) GetLeaveList(c *fiber.Ctx) error {
 	month := c.Query("month")
 	year := c.Query("year")
 
-	data, err := h.service.ListLeaveManagement(orgID, month, year)
+	data, err := h.service.ListLeaveManagement(c.UserContext(), month, year)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -101,7 +101,7 @@ func (h *LeaveManagementHandler) CreateLeave(c *fiber.Ctx) error {
 	req.Reason = strings.TrimSpace(req.Reason)
 	req.AttachmentPath = strings.TrimSpace(req.AttachmentPath)
 
-	leaveID, err := h.service.CreateLeave(orgID, userID, &req)
+	leaveID, err := h.service.CreateLeave(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
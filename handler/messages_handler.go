@@ -28,7 +28,7 @@ func (h *MessagesHandler) SubmitMessage(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "invalid payload")
 	}
 
-	messageID, err := h.service.SubmitMessage(orgID, &req)
+	messageID, err := h.service.SubmitMessage(c.UserContext(), &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -45,7 +45,7 @@ func (h *MessagesHandler) ListMessages(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.ListMessages(orgID)
+	items, err := h.service.ListMessages(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -64,7 +64,7 @@ func (h *MessagesHandler) ReadMessage(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "invalid payload")
 	}
 
-	if err := h.service.ReadMessage(orgID, req.MessageID); err != nil {
+	if err := h.service.ReadMessage(c.UserContext(), req.MessageID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
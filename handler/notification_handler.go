@@ -22,7 +22,7 @@ func (h *NotificationHandler) GetAllNotifications(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "missing organization context")
 	}
 
-	items, err := h.service.GetNotifications(orgID)
+	items, err := h.service.GetNotifications(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
 	}
@@ -37,7 +37,7 @@ func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 	}
 
 	notificationID := c.Params("notification_id")
-	if err := h.service.MarkAsRead(orgID, notificationID); err != nil {
+	if err := h.service.MarkAsRead(c.UserContext(), notificationID); err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
 	}
 
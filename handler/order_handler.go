@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"service-travego/helper"
@@ -35,10 +36,13 @@ func (h *OrderHandler) GetFleetOrderSummary(c *fiber.Ctx) error {
 	if req.FleetID == "" || req.PriceID == "" {
 		return helper.BadRequestResponse(c, "fleet_id and price_id are required")
 	}
+	if orgID, ok := c.Locals("organization_id").(string); ok {
+		req.OrganizationID = orgID
+	}
 
-	res, err := h.service.GetFleetOrderSummary(&req)
+	res, err := h.service.GetFleetOrderSummary(c.UserContext(), &req)
 	if err != nil {
-		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
 	}
 
 	return helper.SuccessResponse(c, fiber.StatusOK, "Order summary retrieved", res)
@@ -142,6 +146,12 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 		if v, ok := m["order_type"]; ok {
 			req.OrderType = helper.ToInt(v)
 		}
+		if v, ok := m["voucher_code"].(string); ok {
+			req.VoucherCode = v
+		}
+		if v, ok := m["payment_plan_id"].(string); ok {
+			req.PaymentPlanID = v
+		}
 	}
 
 	// Basic Validation
@@ -156,10 +166,10 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 		req.OrganizationCode = orgCode
 	}
 
-	res, err := h.service.CreateOrder(&req)
+	res, err := h.service.CreateOrder(c.UserContext(), &req)
 	if err != nil {
-		fmt.Println("Error creating order:", err)
-		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+		slog.ErrorContext(c.UserContext(), "error creating order", "error", err)
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
 	}
 
 	return helper.SuccessResponse(c, fiber.StatusOK, "Order created successfully", res)
@@ -177,7 +187,7 @@ func (h *OrderHandler) GetOrderList(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.service.GetOrderList(&req)
+	res, err := h.service.GetOrderList(c.UserContext(), &req)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -194,12 +204,11 @@ func (h *OrderHandler) GetFleetOrderDetailByPrefix(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "order_id is required")
 	}
 
-	orgID, ok := c.Locals("organization_id").(string)
-	if !ok {
+	if _, ok := c.Locals("organization_id").(string); !ok {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.service.GetFleetOrderDetailByPrefix(req.OrderID, orgID)
+	res, err := h.service.GetFleetOrderDetailByPrefix(c.UserContext(), req.OrderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -214,30 +223,29 @@ func (h *OrderHandler) GetOrderDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "encryptOrderId is required")
 	}
 
-	orgID, ok := c.Locals("organization_id").(string)
-	if !ok {
+	if _, ok := c.Locals("organization_id").(string); !ok {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.service.GetOrderDetail(encryptedOrderID, orgID)
+	res, err := h.service.GetOrderDetail(c.UserContext(), encryptedOrderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	reviews, err := h.service.GetOrderReviews(res.OrderID, orgID)
+	reviews, err := h.service.GetOrderReviews(c.UserContext(), res.OrderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	rating, err := h.service.GetOrderRatingSummary(res.OrderID, orgID)
+	rating, err := h.service.GetOrderRatingSummary(c.UserContext(), res.OrderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
 	payment := &model.PaymentSummary{}
-	if totalAddon, totalDiscount, totalCharge, totalPayment, err := h.service.GetFleetOrderItemTotals(res.OrderID, orgID); err == nil {
+	if totalAddon, totalDiscount, totalCharge, totalPayment, err := h.service.GetFleetOrderItemTotals(c.UserContext(), res.OrderID); err == nil {
 		payment.TotalAddon = totalAddon
 		payment.TotalDiscount = totalDiscount
 		payment.TotalCharge = totalCharge
@@ -261,12 +269,11 @@ func (h *OrderHandler) FindOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "order_id is required")
 	}
 
-	orgID, ok := c.Locals("organization_id").(string)
-	if !ok {
+	if _, ok := c.Locals("organization_id").(string); !ok {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.service.FindOrderDetail(orderID, orgID)
+	res, err := h.service.FindOrderDetail(c.UserContext(), orderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -281,7 +288,8 @@ func (h *OrderHandler) CreateOrderPayment(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid payload")
 	}
 
-	if req.Token == "" || req.PaymentMethod == "" || req.PaymentType == 0 {
+	// Installment payments go through Midtrans and need no bank account or type
+	if req.Token == "" || (req.InstallmentID == "" && (req.PaymentMethod == "" || req.PaymentType == 0)) {
 		return helper.BadRequestResponse(c, "Required fields missing")
 	}
 
@@ -291,9 +299,9 @@ func (h *OrderHandler) CreateOrderPayment(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.service.CreateOrderPayment(&req)
+	res, err := h.service.CreateOrderPayment(c.UserContext(), &req)
 	if err != nil {
-		fmt.Println("Error creating payment:", err)
+		slog.ErrorContext(c.UserContext(), "error creating payment", "error", err)
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -305,7 +313,7 @@ func (h *OrderHandler) CreateServiceOrderPayment(c *fiber.Ctx) error {
 	var req model.CreateServiceOrderPaymentRequest
 	if err := c.BodyParser(&req); err != nil {
 
-		fmt.Println("Error parsing request:", err)
+		slog.ErrorContext(c.UserContext(), "error parsing request", "error", err)
 		return helper.BadRequestResponse(c, "Invalid payload")
 	}
 	if req.OrderID == "" || req.OrderType == 0 || req.PaymentType == 0 || req.PaymentMethod == 0 || req.PaymentAmount <= 0 {
@@ -324,7 +332,7 @@ func (h *OrderHandler) CreateServiceOrderPayment(c *fiber.Ctx) error {
 	}
 	req.CreatedBy = userID
 
-	res, err := h.service.CreateServiceOrderPayment(&req)
+	res, err := h.service.CreateServiceOrderPayment(c.UserContext(), &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -349,7 +357,7 @@ func (h *OrderHandler) GetServiceOrderPaymentHistory(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	list, err := h.service.GetServiceOrderPaymentHistory(orgID, &req)
+	list, err := h.service.GetServiceOrderPaymentHistory(c.UserContext(), &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -368,7 +376,7 @@ func (h *OrderHandler) GetServiceOrderList(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	list, err := h.service.GetServiceOrderList(orgID, &req)
+	list, err := h.service.GetServiceOrderList(c.UserContext(), &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -382,7 +390,7 @@ func (h *OrderHandler) GetPaymentMethods(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.service.GetPaymentMethods(orgID)
+	res, err := h.service.GetPaymentMethods(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -406,9 +414,9 @@ func (h *OrderHandler) ConfirmPayment(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	err := h.service.ConfirmPayment(&req)
+	err := h.service.ConfirmPayment(c.UserContext(), &req)
 	if err != nil {
-		fmt.Println("Error confirming payment:", err)
+		slog.ErrorContext(c.UserContext(), "error confirming payment", "error", err)
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -416,6 +424,48 @@ func (h *OrderHandler) ConfirmPayment(c *fiber.Ctx) error {
 	return helper.SuccessResponse(c, fiber.StatusOK, "Payment confirmed", nil)
 }
 
+func (h *OrderHandler) GetCancellationQuote(c *fiber.Ctx) error {
+	var req model.OrderCancellationSubmitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid payload")
+	}
+	if req.Token == "" {
+		return helper.BadRequestResponse(c, "token is required")
+	}
+
+	if _, ok := c.Locals("organization_id").(string); !ok {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
+	}
+
+	res, err := h.service.GetCancellationQuote(c.UserContext(), req.Token)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation refund retrieved", res)
+}
+
+func (h *OrderHandler) RequestCancellation(c *fiber.Ctx) error {
+	var req model.OrderCancellationSubmitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid payload")
+	}
+	if req.Token == "" || req.Reason == "" {
+		return helper.BadRequestResponse(c, "Required fields missing")
+	}
+
+	if _, ok := c.Locals("organization_id").(string); !ok {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
+	}
+
+	res, err := h.service.RequestCancellation(c.UserContext(), &req)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Cancellation requested", res)
+}
+
 func (h *OrderHandler) UploadPaymentEvidence(c *fiber.Ctx) error {
 	token := c.FormValue("token")
 	if token == "" {
@@ -464,7 +514,7 @@ func (h *OrderHandler) UploadPaymentEvidence(c *fiber.Ctx) error {
 	}
 
 	// Update DB
-	if err := h.service.UploadPaymentEvidence(orderID, organizationID, filePath); err != nil {
+	if err := h.service.UploadPaymentEvidence(c.UserContext(), orderID, filePath); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
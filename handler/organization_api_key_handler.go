@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListAPIKeys handles GET /api/organization/api-config/keys
+func (h *OrganizationHandler) ListAPIKeys(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+	res, err := h.orgService.ListAPIKeys(c.UserContext())
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "API keys loaded", res)
+}
+
+// CreateAPIKey handles POST /api/organization/api-config/keys/create
+func (h *OrganizationHandler) CreateAPIKey(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req model.CreateOrganizationAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	key, err := h.orgService.CreateAPIKey(c.UserContext(), userID, &req)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "API key created. Store it now, it will not be shown again", key)
+}
+
+// RotateAPIKey handles POST /api/organization/api-config/keys/rotate
+func (h *OrganizationHandler) RotateAPIKey(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req model.OrganizationAPIKeyIDRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	key, err := h.orgService.RotateAPIKey(c.UserContext(), userID, req.APIKeyID)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "API key rotated. Store it now, it will not be shown again", key)
+}
+
+// RevokeAPIKey handles POST /api/organization/api-config/keys/revoke
+func (h *OrganizationHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req model.OrganizationAPIKeyIDRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	if err := h.orgService.RevokeAPIKey(c.UserContext(), userID, req.APIKeyID); err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "API key revoked", nil)
+}
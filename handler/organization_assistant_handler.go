@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
@@ -16,7 +16,7 @@ func (h *OrganizationHandler) AssistantList(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	items, err := h.orgService.AssistantList(orgID)
+	items, err := h.orgService.AssistantList(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -44,17 +44,17 @@ func (h *OrganizationHandler) AssistantSubmit(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	res, err := h.orgService.AssistantSubmit(orgID, userID, &req)
+	res, err := h.orgService.AssistantSubmit(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
 	// Send WhatsApp notification
-	if h.wagyClient != nil && req.AccountNumber != "" {
+	if h.outbox != nil && req.AccountNumber != "" {
 		normalized := service.NormalizeAssistantAccountNumber(req.AccountNumber)
 		message := "Halo! Anda sudah bisa menikmati AI Assistant untuk memudahkan pekerjaan. Jika ada kendala harap informasikan ke administrator."
-		go h.wagyClient.SendMessage(normalized, message)
+		_ = h.outbox.EnqueueWhatsApp(c.UserContext(), orgID, service.OutboxKindAssistantWelcome, normalized, message)
 	}
 
 	return helper.SuccessResponse(c, fiber.StatusOK, "Assistant account created", res)
@@ -75,19 +75,19 @@ func (h *OrganizationHandler) AssistantUpdate(c *fiber.Ctx) error {
 	}
 
 	// Fetch old data to check if account_number changed
-	oldData, _ := h.orgService.GetAssistantAccountByID(orgID, req.AssistantID)
+	oldData, _ := h.orgService.GetAssistantAccountByID(c.UserContext(), req.AssistantID)
 
-	if err := h.orgService.AssistantUpdate(orgID, &req); err != nil {
+	if err := h.orgService.AssistantUpdate(c.UserContext(), &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
 	// Send WhatsApp notification if account_number changed
-	if h.wagyClient != nil && req.AccountNumber != nil && *req.AccountNumber != "" {
+	if h.outbox != nil && req.AccountNumber != nil && *req.AccountNumber != "" {
 		normalized := service.NormalizeAssistantAccountNumber(*req.AccountNumber)
 		if oldData == nil || oldData.AccountNumber != normalized {
 			message := "Halo! Anda sudah bisa menikmati AI Assistant untuk memudahkan pekerjaan. Jika ada kendala harap informasikan ke administrator."
-			go h.wagyClient.SendMessage(normalized, message)
+			_ = h.outbox.EnqueueWhatsApp(c.UserContext(), orgID, service.OutboxKindAssistantWelcome, normalized, message)
 		}
 	}
 
@@ -108,7 +108,7 @@ func (h *OrganizationHandler) AssistantDelete(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.AssistantDelete(orgID, req.EmployeeID); err != nil {
+	if err := h.orgService.AssistantDelete(c.UserContext(), req.EmployeeID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -121,7 +121,7 @@ func (h *OrganizationHandler) AssistantWhatsAppBusinessList(c *fiber.Ctx) error
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	accountNumber, err := h.orgService.AssistantWhatsAppBusinessList(orgID)
+	accountNumber, err := h.orgService.AssistantWhatsAppBusinessList(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -150,7 +150,7 @@ func (h *OrganizationHandler) AssistantWhatsAppBusinessUpdate(c *fiber.Ctx) erro
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Account number must start with 62")
 	}
 
-	err := h.orgService.AssistantWhatsAppBusinessUpdate(orgID, req.AccountNumber)
+	err := h.orgService.AssistantWhatsAppBusinessUpdate(c.UserContext(), req.AccountNumber)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -170,8 +170,8 @@ func (h *OrganizationHandler) EmployeeWhatsApp(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "employee_id is required")
 	}
 
-	fmt.Println(employeeID, " - employeeID")
-	res, err := h.orgService.EmployeeWhatsApp(orgID, employeeID)
+	slog.DebugContext(c.UserContext(), "employee WhatsApp", "employee_id", employeeID)
+	res, err := h.orgService.EmployeeWhatsApp(c.UserContext(), employeeID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListAuditLogs handles GET /api/organization/audit
+func (h *OrganizationHandler) ListAuditLogs(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+	if h.auditService == nil {
+		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Audit log is not available")
+	}
+
+	filter := model.AuditLogFilter{
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		StartDate:  c.Query("start_date"),
+		EndDate:    c.Query("end_date"),
+		Page:       c.QueryInt("page", 1),
+		PerPage:    c.QueryInt("per_page", 20),
+	}
+	res, err := h.auditService.List(c.UserContext(), filter)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Audit logs loaded", res)
+}
@@ -2,11 +2,11 @@ package handler
 
 import (
 	"database/sql"
-	"fmt"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"service-travego/helper"
-	"service-travego/internal/wagy"
 	"service-travego/model"
 	"service-travego/service"
 	"strconv"
@@ -20,7 +20,8 @@ type OrganizationHandler struct {
 	orgJoinService *service.OrganizationJoinService
 	orgTypeService *service.OrganizationTypeService
 	authService    *service.AuthService
-	wagyClient     *wagy.WagyClient
+	auditService   *service.AuditService
+	outbox         *service.OutboxService
 }
 
 func NewOrganizationHandler(orgService *service.OrganizationService) *OrganizationHandler {
@@ -43,16 +44,21 @@ func (h *OrganizationHandler) SetAuthService(authService *service.AuthService) {
 	h.authService = authService
 }
 
-// SetWagyClient sets the wagy client
-func (h *OrganizationHandler) SetWagyClient(wagyClient *wagy.WagyClient) {
-	h.wagyClient = wagyClient
+// SetAuditService sets the service the audit log is read from
+func (h *OrganizationHandler) SetAuditService(auditService *service.AuditService) {
+	h.auditService = auditService
+}
+
+// SetOutboxService sets the outbox the WhatsApp notifications are queued in
+func (h *OrganizationHandler) SetOutboxService(outbox *service.OutboxService) {
+	h.outbox = outbox
 }
 
 // CreateOrganization handles POST /api/organization/create
 func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
 	userID, ok := c.Locals("user_id").(string)
 	if !ok || userID == "" {
-		fmt.Println("User not authenticated")
+		slog.DebugContext(c.UserContext(), "user not authenticated")
 		return helper.UnauthorizedResponse(c, "User not authenticated")
 	}
 
@@ -81,9 +87,9 @@ func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
 		PostalCode:       req.PostalCode,
 	}
 
-	createdOrg, err := h.orgService.CreateOrganization(userID, org)
+	createdOrg, err := h.orgService.CreateOrganization(c.UserContext(), userID, org)
 	if err != nil {
-		fmt.Println("Error creating organization:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error creating organization", "error", err)
 		statusCode := fiber.StatusInternalServerError
 		if strings.Contains(err.Error(), "profile") || strings.Contains(err.Error(), "complete") || strings.Contains(err.Error(), "invalid") || strings.Contains(strings.ToLower(err.Error()), "foreign key") {
 			statusCode = fiber.StatusBadRequest
@@ -91,21 +97,21 @@ func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
 
-	if err := h.orgService.CreateOrganizationSubscription(createdOrg.OrganizationId); err != nil {
-		fmt.Println("Error creating subscription:", err.Error())
+	if err := h.orgService.CreateOrganizationSubscription(c.UserContext(), createdOrg.OrganizationId); err != nil {
+		slog.ErrorContext(c.UserContext(), "error creating subscription", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create subscription")
 	}
 
 	assistantAccountID := ""
-	if h.wagyClient != nil && strings.TrimSpace(req.Phone) != "" {
+	if h.outbox != nil && strings.TrimSpace(req.Phone) != "" {
 		message := "Selamat datang di TraveGO. Kini Anda bisa menikmati fitur TraveGO dengan chat AI Assistant dan dashboard web TraveGO."
-		fmt.Printf("Sending welcome WhatsApp to %s\n", req.Phone)
-		if _, err := h.wagyClient.SendMessage(req.Phone, message); err != nil {
-			fmt.Println("Error sending welcome WhatsApp:", err.Error())
+		slog.DebugContext(c.UserContext(), "queueing welcome WhatsApp", "phone", req.Phone)
+		if err := h.outbox.EnqueueWhatsApp(c.UserContext(), createdOrg.OrganizationId, service.OutboxKindAssistantWelcome, req.Phone, message); err != nil {
+			slog.ErrorContext(c.UserContext(), "error queueing welcome WhatsApp", "error", err)
 		} else {
-			assistantAccountID, err = h.orgService.CreateDefaultAssistantAccount(createdOrg.OrganizationId, userID, req.Phone)
+			assistantAccountID, err = h.orgService.CreateDefaultAssistantAccount(c.UserContext(), createdOrg.OrganizationId, userID, req.Phone)
 			if err != nil {
-				fmt.Println("Error creating assistant account:", err.Error())
+				slog.ErrorContext(c.UserContext(), "error creating assistant account", "error", err)
 				return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create assistant account")
 			}
 		}
@@ -114,9 +120,14 @@ func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
 	if h.authService == nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Auth service not initialized")
 	}
-	loginResponse, err := h.authService.Login("", "", "", userID)
+	sessionID, _ := c.Locals("session_id").(string)
+	loginResponse, err := h.authService.Login(c.UserContext(), "", "", "", userID, service.LoginDevice{
+		SessionID:  sessionID,
+		DeviceName: c.Get("User-Agent"),
+		IPAddress:  c.IP(),
+	})
 	if err != nil {
-		fmt.Println("Error generating organization creation token:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error generating organization creation token", "error", err)
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
@@ -157,7 +168,7 @@ func (h *OrganizationHandler) JoinOrganization(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Join service not initialized")
 	}
 
-	err := h.orgJoinService.JoinOrganization(userID, req.OrganizationCode)
+	err := h.orgJoinService.JoinOrganization(c.UserContext(), userID, req.OrganizationCode)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
 	}
@@ -173,7 +184,7 @@ func (h *OrganizationHandler) GetAPIConfig(c *fiber.Ctx) error {
 		return helper.UnauthorizedResponse(c, "User not authenticated")
 	}
 
-	config, err := h.orgService.GetAPIConfig(userID)
+	config, err := h.orgService.GetAPIConfig(c.UserContext(), userID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -203,7 +214,7 @@ func (h *OrganizationHandler) UpdateDomainURL(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid request body")
 	}
 
-	err := h.orgService.UpdateDomainURL(userID, orgID, req.DomainURL)
+	err := h.orgService.UpdateDomainURL(c.UserContext(), userID, req.DomainURL)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -211,13 +222,55 @@ func (h *OrganizationHandler) UpdateDomainURL(c *fiber.Ctx) error {
 	return helper.SuccessResponse(c, fiber.StatusOK, "Domain URL updated successfully", nil)
 }
 
+// GetPaymentGateway handles GET /api/organization/payment-gateway
+func (h *OrganizationHandler) GetPaymentGateway(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+
+	setting, err := h.orgService.GetPaymentGatewaySetting(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment gateway retrieved successfully", setting)
+}
+
+// UpdatePaymentGateway handles POST /api/organization/update/payment-gateway
+func (h *OrganizationHandler) UpdatePaymentGateway(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.UnauthorizedResponse(c, "User not authenticated")
+	}
+
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+
+	var req model.UpdatePaymentGatewayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	if err := h.orgService.UpdatePaymentGateway(c.UserContext(), userID, req.PaymentGateway); err != nil {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment gateway updated successfully", nil)
+}
+
 // GetOrganizationTypes handles GET /api/organization/types
 func (h *OrganizationHandler) GetOrganizationTypes(c *fiber.Ctx) error {
 	if h.orgTypeService == nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Organization type service not initialized")
 	}
 
-	types, err := h.orgTypeService.GetAllOrganizationTypes()
+	types, err := h.orgTypeService.GetAllOrganizationTypes(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -232,9 +285,9 @@ func (h *OrganizationHandler) GetBankAccounts(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
 
-	accounts, err := h.orgService.GetBankAccounts(orgID)
+	accounts, err := h.orgService.GetBankAccounts(c.UserContext())
 	if err != nil {
-		fmt.Println("Error fetching bank accounts:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error fetching bank accounts", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load bank accounts")
 	}
 
@@ -248,9 +301,9 @@ func (h *OrganizationHandler) GetOrganizationDetail(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	res, err := h.orgService.GetOrganizationDetail(orgID)
+	res, err := h.orgService.GetOrganizationDetail(c.UserContext())
 	if err != nil {
-		fmt.Println("Error fetching organization detail:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error fetching organization detail", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -273,7 +326,8 @@ func (h *OrganizationHandler) UpdateOrganizationDetail(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.UpdateOrganizationDetail(orgID, &req); err != nil {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.orgService.UpdateOrganizationDetail(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -287,6 +341,7 @@ func (h *OrganizationHandler) UpdateOrganizationLogo(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
+	userID, _ := c.Locals("user_id").(string)
 
 	// Prefer multipart file upload if provided
 	fileHeader, err := c.FormFile("file")
@@ -306,7 +361,7 @@ func (h *OrganizationHandler) UpdateOrganizationLogo(c *fiber.Ctx) error {
 		}
 		defer os.Remove(tempPath)
 
-		url, svcErr := h.orgService.UpdateOrganizationLogo(orgID, tempPath)
+		url, svcErr := h.orgService.UpdateOrganizationLogo(c.UserContext(), userID, tempPath)
 		if svcErr != nil {
 			code := service.GetStatusCode(svcErr)
 			return helper.SendErrorResponse(c, code, svcErr.Error())
@@ -324,7 +379,7 @@ func (h *OrganizationHandler) UpdateOrganizationLogo(c *fiber.Ctx) error {
 	if payload.FilePath == "" {
 		return helper.BadRequestResponse(c, "file atau file_path wajib")
 	}
-	url, svcErr := h.orgService.UpdateOrganizationLogo(orgID, payload.FilePath)
+	url, svcErr := h.orgService.UpdateOrganizationLogo(c.UserContext(), userID, payload.FilePath)
 	if svcErr != nil {
 		code := service.GetStatusCode(svcErr)
 		return helper.SendErrorResponse(c, code, svcErr.Error())
@@ -339,7 +394,7 @@ func (h *OrganizationHandler) CreateBankAccount(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	fmt.Printf("Received CreateBankAccount Request: %+v\n", req)
+	slog.DebugContext(c.UserContext(), "create bank account request", "req", req)
 
 	orgID, ok := c.Locals("organization_id").(string)
 	if !ok || orgID == "" {
@@ -361,9 +416,9 @@ func (h *OrganizationHandler) CreateBankAccount(c *fiber.Ctx) error {
 
 	createdIP := c.IP()
 
-	err := h.orgService.CreateBankAccount(&req, orgID, userID, createdProxy, createdIP)
+	err := h.orgService.CreateBankAccount(c.UserContext(), &req, userID, createdProxy, createdIP)
 	if err != nil {
-		fmt.Println("Error creating bank account:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error creating bank account", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -393,10 +448,11 @@ func (h *OrganizationHandler) UpdateBankAccount(c *fiber.Ctx) error {
 		updatedProxy = c.Get("X-Forwarded-Fot")
 	}
 	updatedIP := c.IP()
+	userID, _ := c.Locals("user_id").(string)
 
-	err := h.orgService.UpdateBankAccount(&req, orgID, updatedProxy, updatedIP)
+	err := h.orgService.UpdateBankAccount(c.UserContext(), &req, userID, updatedProxy, updatedIP)
 	if err != nil {
-		fmt.Println("Error updating bank account:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error updating bank account", "error", err)
 		if strings.Contains(err.Error(), "simultaneously") || strings.Contains(err.Error(), "required") {
 			return helper.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
 		}
@@ -427,9 +483,10 @@ func (h *OrganizationHandler) DeleteBankAccount(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	err := h.orgService.DeleteBankAccount(req.BankAccountID, orgID)
+	userID, _ := c.Locals("user_id").(string)
+	err := h.orgService.DeleteBankAccount(c.UserContext(), req.BankAccountID, userID)
 	if err != nil {
-		fmt.Println("Error deleting bank account:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error deleting bank account", "error", err)
 		if err == sql.ErrNoRows {
 			return helper.SendErrorResponse(c, fiber.StatusNotFound, "Bank account not found or unauthorized")
 		}
@@ -448,9 +505,9 @@ func (h *OrganizationHandler) GetUsers(c *fiber.Ctx) error {
 
 	status := c.Query("status", "")
 
-	users, err := h.orgService.GetOrganizationUsers(orgID, status)
+	users, err := h.orgService.GetOrganizationUsers(c.UserContext(), status)
 	if err != nil {
-		fmt.Println("Error fetching users:", err.Error())
+		slog.ErrorContext(c.UserContext(), "error fetching users", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load users")
 	}
 
@@ -483,23 +540,27 @@ func (h *OrganizationHandler) HandleJoinAction(c *fiber.Ctx) error {
 
 	action := c.Params("action")
 	userID := c.Params("user_id")
+	actorID, _ := c.Locals("user_id").(string)
 
 	switch action {
 	case "approve":
-		if err := h.orgService.ApproveJoinRequest(orgID, userID); err != nil {
-			fmt.Println("Error approving join request:", err.Error())
+		if err := h.orgService.ApproveJoinRequest(c.UserContext(), actorID, userID); err != nil {
+			slog.ErrorContext(c.UserContext(), "error approving join request", "error", err)
+			if errors.Is(err, service.ErrQuotaExceeded) {
+				return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+			}
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to approve join request")
 		}
 		return helper.SuccessResponse(c, fiber.StatusOK, "Join request approved successfully", nil)
 	case "reject":
-		if err := h.orgService.RejectJoinRequest(orgID, userID); err != nil {
-			fmt.Println("Error rejecting join request:", err.Error())
+		if err := h.orgService.RejectJoinRequest(c.UserContext(), actorID, userID); err != nil {
+			slog.ErrorContext(c.UserContext(), "error rejecting join request", "error", err)
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to reject join request")
 		}
 		return helper.SuccessResponse(c, fiber.StatusOK, "Join request rejected successfully", nil)
 	case "delete":
-		if err := h.orgService.RejectJoinRequest(orgID, userID); err != nil {
-			fmt.Println("Error deleting join request:", err.Error())
+		if err := h.orgService.RejectJoinRequest(c.UserContext(), actorID, userID); err != nil {
+			slog.ErrorContext(c.UserContext(), "error deleting join request", "error", err)
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete join request")
 		}
 		return helper.SuccessResponse(c, fiber.StatusOK, "Join request deleted successfully", nil)
@@ -520,18 +581,23 @@ func (h *OrganizationHandler) HandleUserAction(c *fiber.Ctx) error {
 	action := c.Params("action")
 	userID := c.Params("user_id")
 
+	actorID, _ := c.Locals("user_id").(string)
+
 	if ownID == userID {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Anda tidak dapat mengaktifkan atau nonaktifkan akun sendiri dari menu ini")
 	}
 
 	switch action {
 	case "enable":
-		if err := h.orgService.ToggleUserStatus(orgID, userID, true); err != nil {
+		if err := h.orgService.ToggleUserStatus(c.UserContext(), actorID, userID, true); err != nil {
+			if errors.Is(err, service.ErrQuotaExceeded) {
+				return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+			}
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to enable user")
 		}
 		return helper.SuccessResponse(c, fiber.StatusOK, "User enabled successfully", nil)
 	case "disable":
-		if err := h.orgService.ToggleUserStatus(orgID, userID, false); err != nil {
+		if err := h.orgService.ToggleUserStatus(c.UserContext(), actorID, userID, false); err != nil {
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to disable user")
 		}
 		return helper.SuccessResponse(c, fiber.StatusOK, "User disabled successfully", nil)
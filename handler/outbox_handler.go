@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OutboxHandler struct {
+	service *service.OutboxService
+}
+
+func NewOutboxHandler(s *service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{service: s}
+}
+
+// GetOutboxMessages handles GET /api/system/outbox
+func (h *OutboxHandler) GetOutboxMessages(c *fiber.Ctx) error {
+	if isSuperAdmin, _ := c.Locals("is_superadmin").(bool); !isSuperAdmin {
+		return helper.SendErrorResponse(c, fiber.StatusForbidden, "Only Travego staff can view outgoing messages")
+	}
+
+	filter := model.OutboxMessageFilter{
+		Status:         strings.ToUpper(c.Query("status")),
+		Channel:        strings.ToLower(c.Query("channel")),
+		Kind:           c.Query("kind"),
+		OrganizationID: c.Query("organization_id"),
+		Recipient:      c.Query("recipient"),
+		Limit:          c.QueryInt("limit", 50),
+	}
+	res, err := h.service.List(c.UserContext(), filter)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Outbox messages retrieved successfully", res)
+}
+
+// ResendOutboxMessage handles POST /api/system/outbox/:message_id/resend
+func (h *OutboxHandler) ResendOutboxMessage(c *fiber.Ctx) error {
+	if isSuperAdmin, _ := c.Locals("is_superadmin").(bool); !isSuperAdmin {
+		return helper.SendErrorResponse(c, fiber.StatusForbidden, "Only Travego staff can resend outgoing messages")
+	}
+
+	res, err := h.service.Resend(c.UserContext(), c.Params("message_id"))
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Outbox message queued for resending", res)
+}
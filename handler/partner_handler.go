@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
@@ -27,7 +27,7 @@ func (h *PartnerHandler) List(c *fiber.Ctx) error {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
-	partners, err := h.service.List(orgID, partnerName, startDate, endDate)
+	partners, err := h.service.List(c.UserContext(), partnerName, startDate, endDate)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -55,9 +55,9 @@ func (h *PartnerHandler) Create(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, errs)
 	}
 
-	partner, err := h.service.Create(req, orgID, userID)
+	partner, err := h.service.Create(c.UserContext(), req, userID)
 	if err != nil {
-		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
 	}
 
 	return helper.SuccessResponse(c, fiber.StatusOK, "Success create operation partner", partner)
@@ -65,7 +65,7 @@ func (h *PartnerHandler) Create(c *fiber.Ctx) error {
 
 func (h *PartnerHandler) Update(c *fiber.Ctx) error {
 
-	fmt.Println("this is update partner")
+	slog.DebugContext(c.UserContext(), "update partner")
 
 	orgID, ok := c.Locals("organization_id").(string)
 	userID, okUser := c.Locals("user_id").(string)
@@ -75,18 +75,18 @@ func (h *PartnerHandler) Update(c *fiber.Ctx) error {
 
 	var req model.UpdateOperationPartnerRequest
 	if err := c.BodyParser(&req); err != nil {
-		fmt.Println("this is error 1 --- ", err)
+		slog.ErrorContext(c.UserContext(), "update partner: invalid payload", "error", err)
 		return helper.BadRequestResponse(c, "Invalid payload")
 	}
 
 	if errs := helper.ValidateStruct(req); len(errs) > 0 {
-		fmt.Println("this is error 2 --- ", errs)
+		slog.ErrorContext(c.UserContext(), "update partner: validation failed", "errors", errs)
 		return helper.SendValidationErrorResponse(c, errs)
 	}
 
-	partner, err := h.service.Update(req, orgID, userID)
+	partner, err := h.service.Update(c.UserContext(), req, userID)
 	if err != nil {
-		fmt.Println("this is error 3 --- ", err)
+		slog.ErrorContext(c.UserContext(), "update partner failed", "error", err)
 		if err.Error() == "partner not found" {
 			return helper.NotFoundResponse(c, "Partner not found")
 		}
@@ -111,7 +111,7 @@ func (h *PartnerHandler) Detail(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, errs)
 	}
 
-	partner, err := h.service.Detail(&req, orgID)
+	partner, err := h.service.Detail(c.UserContext(), &req)
 	if err != nil {
 		if err.Error() == "partner not found" {
 			return helper.NotFoundResponse(c, "Partner not found")
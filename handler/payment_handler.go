@@ -2,8 +2,11 @@ package handler
 
 import (
 	"fmt"
+	"log/slog"
+	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,7 +38,7 @@ func (h *PaymentHandler) CreatePayment(c *fiber.Ctx) error {
 		req.UserID = fmt.Sprintf("%v", userID)
 	}
 
-	fmt.Printf("[DEBUG] CreatePayment - req: %+v\n", req)
+	slog.DebugContext(c.UserContext(), "create payment", "req", req)
 
 	// Validation
 	if req.OrderID == "" {
@@ -45,9 +48,9 @@ func (h *PaymentHandler) CreatePayment(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid order_type. Must be 1 (fleet) or 2 (tour package)"})
 	}
 
-	resp, err := h.paymentService.CreatePayment(&req)
+	resp, err := h.paymentService.CreatePayment(c.UserContext(), &req)
 	if err != nil {
-		fmt.Println("Error creating payment:", err)
+		slog.ErrorContext(c.UserContext(), "error creating payment", "error", err)
 		if err.Error() == "invalid payment type: 0" || err.Error() == "invalid payment type" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -64,9 +67,9 @@ func (h *PaymentHandler) PaymentNotifications(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid webhook payload"})
 	}
 
-	err := h.paymentService.PaymentNotifications(&req)
+	err := h.paymentService.PaymentNotifications(c.UserContext(), c.Body(), &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(service.GetStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(model.WebhookResponse{Message: "OK"})
@@ -75,7 +78,7 @@ func (h *PaymentHandler) PaymentNotifications(c *fiber.Ctx) error {
 func (h *PaymentHandler) HandlePaymentNotification(c *fiber.Ctx) error {
 	var req model.MidtransWebhookRequest
 	if err := c.BodyParser(&req); err != nil {
-		fmt.Println("Error parsing request body:", err)
+		slog.ErrorContext(c.UserContext(), "error parsing request body", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
 			"message": "Invalid request body",
@@ -83,12 +86,12 @@ func (h *PaymentHandler) HandlePaymentNotification(c *fiber.Ctx) error {
 	}
 
 	body := string(c.Body())
-	fmt.Printf("[WEBHOOK LOG] Received Request Body: %s\n", body)
+	slog.DebugContext(c.UserContext(), "payment notification received", "body", body)
 
-	err := h.paymentService.ProcessPaymentNotification(&req)
+	err := h.paymentService.HandleMidtransNotification(c.UserContext(), c.Body(), &req)
 	if err != nil {
-		fmt.Println("Error processing payment notification:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		slog.ErrorContext(c.UserContext(), "error processing payment notification", "error", err)
+		return c.Status(service.GetStatusCode(err)).JSON(fiber.Map{
 			"status":  "error",
 			"message": fmt.Sprintf("Failed to process payment notification: %v", err),
 		})
@@ -99,3 +102,63 @@ func (h *PaymentHandler) HandlePaymentNotification(c *fiber.Ctx) error {
 		"message": "Payment notification processed successfully",
 	})
 }
+
+// HandleXenditNotification menangani callback invoice dari Xendit
+func (h *PaymentHandler) HandleXenditNotification(c *fiber.Ctx) error {
+	var req model.XenditInvoiceCallback
+	if err := c.BodyParser(&req); err != nil {
+		slog.ErrorContext(c.UserContext(), "error parsing request body", "error", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"status":  "error",
+			"message": "Invalid request body",
+		})
+	}
+
+	ctx := c.UserContext()
+	err := h.paymentService.ProcessXenditNotification(ctx, c.Get("x-callback-token"), c.Body(), &req)
+	if err != nil {
+		slog.WarnContext(ctx, "xendit notification not processed", "external_id", req.ExternalID, "error", err)
+		return c.Status(service.GetStatusCode(err)).JSON(fiber.Map{
+			"status":  "error",
+			"message": fmt.Sprintf("Failed to process payment notification: %v", err),
+		})
+	}
+
+	slog.InfoContext(ctx, "xendit notification processed", "external_id", req.ExternalID, "status", req.Status)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Payment notification processed successfully",
+	})
+}
+
+// GetPaymentNotifications menangani GET /api/system/payment/notifications
+func (h *PaymentHandler) GetPaymentNotifications(c *fiber.Ctx) error {
+	if isSuperAdmin, _ := c.Locals("is_superadmin").(bool); !isSuperAdmin {
+		return helper.SendErrorResponse(c, fiber.StatusForbidden, "Only Travego staff can view payment notifications")
+	}
+
+	filter := model.PaymentNotificationFilter{
+		InvoiceNumber: c.Query("invoice_number"),
+		Outcome:       strings.ToUpper(c.Query("outcome")),
+		Provider:      c.Query("provider"),
+		Limit:         c.QueryInt("limit", 50),
+	}
+	res, err := h.paymentService.ListPaymentNotifications(c.UserContext(), filter)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment notifications retrieved successfully", res)
+}
+
+// ReplayPaymentNotification menangani POST /api/system/payment/notifications/:notification_id/replay
+func (h *PaymentHandler) ReplayPaymentNotification(c *fiber.Ctx) error {
+	if isSuperAdmin, _ := c.Locals("is_superadmin").(bool); !isSuperAdmin {
+		return helper.SendErrorResponse(c, fiber.StatusForbidden, "Only Travego staff can replay payment notifications")
+	}
+
+	res, err := h.paymentService.ReplayPaymentNotification(c.UserContext(), c.Params("notification_id"))
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment notification replayed", res)
+}
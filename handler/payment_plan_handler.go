@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentPlanHandler struct {
+	service *service.PaymentPlanService
+}
+
+func NewPaymentPlanHandler(s *service.PaymentPlanService) *PaymentPlanHandler {
+	return &PaymentPlanHandler{service: s}
+}
+
+func (h *PaymentPlanHandler) List(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.List(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment plans loaded", items)
+}
+
+// ListActive serves the plans a customer can pick on the order form.
+func (h *PaymentPlanHandler) ListActive(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.ListActive(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment plans loaded", items)
+}
+
+func (h *PaymentPlanHandler) Create(c *fiber.Ctx) error {
+	var req model.PaymentPlanUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	id, err := h.service.Create(c.UserContext(), userID, &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment plan created", fiber.Map{"plan_id": id})
+}
+
+func (h *PaymentPlanHandler) Update(c *fiber.Ctx) error {
+	var req model.PaymentPlanUpsertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.Update(c.UserContext(), userID, &req); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment plan updated", nil)
+}
+
+func (h *PaymentPlanHandler) Delete(c *fiber.Ctx) error {
+	var req model.PaymentPlanDeleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	if err := h.service.Delete(c.UserContext(), userID, req.PlanID); err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment plan deleted", nil)
+}
+
+func (h *PaymentPlanHandler) OrderInstallments(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	orderID := c.Params("order_id")
+	if orderID == "" {
+		return helper.BadRequestResponse(c, "order_id is required")
+	}
+
+	items, err := h.service.OrderInstallments(c.UserContext(), orderID)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Order installments loaded", items)
+}
+
+func (h *PaymentPlanHandler) Apply(c *fiber.Ctx) error {
+	var req model.PaymentPlanApplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	items, err := h.service.ApplyToOrder(c.UserContext(), &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Payment plan applied", items)
+}
@@ -34,7 +34,7 @@ func (h *PreferenceCityHandler) GetCities(c *fiber.Ctx) error {
 		}
 	}
 
-	list, err := h.prefService.GetAll(orgID, cityID)
+	list, err := h.prefService.GetAll(c.UserContext(), cityID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load preference cities: "+err.Error())
 	}
@@ -73,7 +73,7 @@ func (h *PreferenceCityHandler) CreateCity(c *fiber.Ctx) error {
 		serviceTypes = req.ServiceType
 	}
 
-	if err := h.prefService.Create(req.CityID, req.MinimalDay, orgID, userID, serviceTypes); err != nil {
+	if err := h.prefService.Create(c.UserContext(), req.CityID, req.MinimalDay, userID, serviceTypes); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create preference city: "+err.Error())
 	}
 
@@ -119,7 +119,7 @@ func (h *PreferenceCityHandler) UpdateCity(c *fiber.Ctx) error {
 		serviceTypeIDs = req.ServiceType
 	}
 
-	if err := h.prefService.Update(req.PreferenceID, req.CityID, req.MinimalDay, orgID, serviceTypeIDs); err != nil {
+	if err := h.prefService.Update(c.UserContext(), req.PreferenceID, req.CityID, req.MinimalDay, serviceTypeIDs); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update preference city: "+err.Error())
 	}
 
@@ -140,7 +140,7 @@ func (h *PreferenceCityHandler) DeleteCity(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if err := h.prefService.Delete(req.PreferenceID, orgID); err != nil {
+	if err := h.prefService.Delete(c.UserContext(), req.PreferenceID); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete preference city")
 	}
 
@@ -162,7 +162,7 @@ func (h *PreferenceCityHandler) DeleteTypes(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if err := h.prefService.DeleteByCityAndServiceType(req.CityID, req.ServiceType, orgID); err != nil {
+	if err := h.prefService.DeleteByCityAndServiceType(c.UserContext(), req.CityID, req.ServiceType); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete preference city types")
 	}
 
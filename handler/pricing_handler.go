@@ -17,10 +17,9 @@ func NewPricingHandler(s *service.PricingService) *PricingHandler {
 }
 
 func (h *PricingHandler) GetPackages(c *fiber.Ctx) error {
-	orgID, _ := c.Locals("organization_id").(string)
 	userID, _ := c.Locals("user_id").(string)
 
-	packages, err := h.service.GetPackages(orgID, userID)
+	packages, err := h.service.GetPackages(c.UserContext(), userID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -29,10 +28,9 @@ func (h *PricingHandler) GetPackages(c *fiber.Ctx) error {
 
 func (h *PricingHandler) GetPackageDetail(c *fiber.Ctx) error {
 	packageID := c.Params("package_id")
-	orgID, _ := c.Locals("organization_id").(string)
 	userID, _ := c.Locals("user_id").(string)
 
-	packageDetail, err := h.service.GetPackageDetail(packageID, orgID, userID)
+	packageDetail, err := h.service.GetPackageDetail(c.UserContext(), packageID, userID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -40,7 +38,7 @@ func (h *PricingHandler) GetPackageDetail(c *fiber.Ctx) error {
 }
 
 func (h *PricingHandler) GetReviews(c *fiber.Ctx) error {
-	reviews, err := h.service.GetReviews()
+	reviews, err := h.service.GetReviews(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -52,7 +50,7 @@ func (h *PricingHandler) SubmitContact(c *fiber.Ctx) error {
 	if err := c.BodyParser(&contact); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
-	if err := h.service.SubmitContact(contact); err != nil {
+	if err := h.service.SubmitContact(c.UserContext(), contact); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 	return helper.SuccessResponse(c, fiber.StatusOK, "Contact submitted", nil)
@@ -28,7 +28,7 @@ func (h *PrintManagementHandler) GenerateOrderFleetDocument(c *fiber.Ctx) error
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	pdf, err := h.service.GenerateOrderFleetPDF(orgID, req.OrderID)
+	pdf, err := h.service.GenerateOrderFleetPDF(c.UserContext(), req.OrderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -50,7 +50,7 @@ func (h *PrintManagementHandler) GenerateFleetInvoiceDocument(c *fiber.Ctx) erro
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	pdf, err := h.service.GenerateFleetInvoicePDF(orgID, req.OrderID, req.InvoiceNumber)
+	pdf, err := h.service.GenerateFleetInvoicePDF(c.UserContext(), req.OrderID, req.InvoiceNumber)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -72,7 +72,7 @@ func (h *PrintManagementHandler) GenerateFleetTripsDocument(c *fiber.Ctx) error
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	pdf, err := h.service.GenerateFleetTripsPDF(orgID, scheduleNumber)
+	pdf, err := h.service.GenerateFleetTripsPDF(c.UserContext(), scheduleNumber)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -94,7 +94,7 @@ func (h *PrintManagementHandler) GenerateSubscriptionDocument(c *fiber.Ctx) erro
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	pdf, err := h.service.GenerateSubscriptionPDF(orgID, req.InvoiceNumber)
+	pdf, err := h.service.GenerateSubscriptionPDF(c.UserContext(), req.InvoiceNumber)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
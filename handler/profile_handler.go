@@ -60,7 +60,7 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 		Avatar:      req.Avatar,
 	}
 
-	updatedUser, err := h.userService.UpdateProfile(user)
+	updatedUser, err := h.userService.UpdateProfile(c.UserContext(), user)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -76,7 +76,7 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 		return helper.UnauthorizedResponse(c, "User not authenticated")
 	}
 
-	profile, err := h.userService.GetProfile(userID)
+	profile, err := h.userService.GetProfile(c.UserContext(), userID)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -99,7 +99,7 @@ func (h *UserHandler) CheckPassword(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.userService.CheckPassword(userID, req.Password); err != nil {
+	if err := h.userService.CheckPassword(c.UserContext(), userID, req.Password); err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
@@ -116,7 +116,7 @@ func (h *UserHandler) ValidateUpdatePassword(c *fiber.Ctx) error {
 		return helper.UnauthorizedResponse(c, "Organization not found")
 	}
 
-	if err := h.userService.SendUpdatePasswordOTP(orgID, userID); err != nil {
+	if err := h.userService.SendUpdatePasswordOTP(c.UserContext(), userID); err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
@@ -145,7 +145,7 @@ func (h *UserHandler) UpdatePassword(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.userService.UpdatePasswordWithOTP(userID, req.OTP, req.ExistingPassword, req.NewPassword, req.ConfirmPassword); err != nil {
+	if err := h.userService.UpdatePasswordWithOTP(c.UserContext(), userID, req.OTP, req.ExistingPassword, req.NewPassword, req.ConfirmPassword); err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"os"
+	"service-travego/database"
 	"service-travego/helper"
+	"service-travego/internal/export"
 	"service-travego/model"
 	"service-travego/service"
 	"strings"
@@ -79,10 +82,9 @@ func (h *ScheduleHandler) Create(c *fiber.Ctx) error {
 		return err
 	}
 
-	id, err := h.service.CreateSchedule(model.ScheduleCreateServiceInput{
-		OrganizationID: orgID,
-		UserID:         userID,
-		Request:        &req,
+	id, err := h.service.CreateSchedule(c.UserContext(), model.ScheduleCreateServiceInput{
+		UserID:  userID,
+		Request: &req,
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -116,15 +118,14 @@ func (h *ScheduleHandler) Update(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing user context")
 	}
 
-	currentScheduleID, _ := h.latestScheduleIDByOrderID(orgID, req.OrderID)
+	currentScheduleID, _ := h.latestScheduleIDByOrderID(c.UserContext(), req.OrderID)
 	if err := h.validateOrderScheduleAvailability(c, req.OrderID, req.ScheduleUnits, currentScheduleID); err != nil {
 		return err
 	}
 
-	scheduleID, err := h.service.UpdateSchedule(model.ScheduleUpdateServiceInput{
-		OrganizationID: orgID,
-		UserID:         userID,
-		Request:        &req,
+	scheduleID, err := h.service.UpdateSchedule(c.UserContext(), model.ScheduleUpdateServiceInput{
+		UserID:  userID,
+		Request: &req,
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -135,12 +136,88 @@ func (h *ScheduleHandler) Update(c *fiber.Ctx) error {
 	})
 }
 
+// AutoAssign proposes units, drivers and crews for an order. Nothing is saved;
+// the dispatcher submits the (possibly adjusted) proposal to /schedule/create.
+func (h *ScheduleHandler) AutoAssign(c *fiber.Ctx) error {
+	var req model.ScheduleAutoAssignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if validationErrors := helper.ValidateStruct(&req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	result, err := h.service.AutoAssignSchedule(c.UserContext(), model.ScheduleAutoAssignServiceInput{
+		Request: &req,
+	})
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+
+	return helper.SuccessResponse(c, fiber.StatusOK, "Schedule assignment proposed", result)
+}
+
 func (h *ScheduleHandler) GetFleetSchedule(c *fiber.Ctx) error {
 	orgID, ok := c.Locals("organization_id").(string)
 	if !ok || orgID == "" {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
+	result, err := h.fleetScheduleList(c)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+
+	return helper.SuccessResponse(c, fiber.StatusOK, "Schedule fleets loaded", result)
+}
+
+// ExportFleetSchedule downloads the fleet schedules of a period, with the
+// filters of GetFleetSchedule, as xlsx or csv
+func (h *ScheduleHandler) ExportFleetSchedule(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	result, err := h.fleetScheduleList(c)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+
+	columns := []export.Column{
+		{Header: "No. Jadwal"},
+		{Header: "No. Pesanan"},
+		{Header: "Armada"},
+		{Header: "ID Kendaraan"},
+		{Header: "Plat Nomor"},
+		{Header: "Mesin"},
+		{Header: "Kapasitas", Kind: export.Integer},
+		{Header: "Pengemudi"},
+		{Header: "Kru"},
+		{Header: "Tanggal Mulai", Kind: export.Date},
+		{Header: "Tanggal Selesai", Kind: export.Date},
+		{Header: "Kota Penjemputan"},
+		{Header: "Tujuan"},
+	}
+	return helper.SendExport(c, "jadwal_armada", "Jadwal Armada", columns, func(w export.Writer) error {
+		for _, s := range result.Schedules {
+			if err := w.WriteRow(s.ScheduleNumber, s.OrderID, s.FleetName, s.VehicleID, s.PlateNumber, s.Engine, s.Capacity,
+				s.DriverName, s.CrewName, s.StartDate, s.EndDate, s.PickupCityLabel, s.Destinations); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// fleetScheduleList loads the fleet schedules matching the query, with the
+// destination names filled in
+func (h *ScheduleHandler) fleetScheduleList(c *fiber.Ctx) (*model.ScheduleFleetListResponse, error) {
 	query := model.ScheduleFleetListQuery{
 		Period:         strings.TrimSpace(c.Query("period")),
 		OrderID:        strings.TrimSpace(c.Query("order_id")),
@@ -154,12 +231,11 @@ func (h *ScheduleHandler) GetFleetSchedule(c *fiber.Ctx) error {
 		ProductionYear: strings.TrimSpace(c.Query("production_year")),
 	}
 
-	result, err := h.service.GetScheduleFleetList(model.ScheduleFleetListServiceInput{
-		OrganizationID: orgID,
-		Query:          query,
+	result, err := h.service.GetScheduleFleetList(c.UserContext(), model.ScheduleFleetListServiceInput{
+		Query: query,
 	})
 	if err != nil {
-		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+		return nil, err
 	}
 
 	citiesMap := getScheduleCitiesMap()
@@ -191,8 +267,7 @@ func (h *ScheduleHandler) GetFleetSchedule(c *fiber.Ctx) error {
 			result.Schedules[i].Destinations = strings.Join(names, ", ")
 		}
 	}
-
-	return helper.SuccessResponse(c, fiber.StatusOK, "Schedule fleets loaded", result)
+	return result, nil
 }
 
 func (h *ScheduleHandler) GetFleetTripDetail(c *fiber.Ctx) error {
@@ -206,8 +281,7 @@ func (h *ScheduleHandler) GetFleetTripDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "schedule_number is required")
 	}
 
-	res, err := h.service.GetFleetTripDetail(model.ScheduleFleetTripDetailServiceInput{
-		OrganizationID: orgID,
+	res, err := h.service.GetFleetTripDetail(c.UserContext(), model.ScheduleFleetTripDetailServiceInput{
 		ScheduleNumber: scheduleNumber,
 	})
 	if err != nil {
@@ -272,9 +346,14 @@ func (h *ScheduleHandler) UpdateFleetTrip(c *fiber.Ctx) error {
 		`
 	}
 
+	t, err := database.ForTenant(c.UserContext(), h.db)
+	if err != nil {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
 	var startDate sql.NullTime
 	var endDate sql.NullTime
-	if err := h.db.QueryRow(periodQuery, scheduleFleetID, orgID).Scan(&startDate, &endDate); err != nil {
+	if err := t.QueryRow(periodQuery, scheduleFleetID, orgID).Scan(&startDate, &endDate); err != nil {
 		if err == sql.ErrNoRows {
 			return helper.SendErrorResponse(c, fiber.StatusNotFound, "SCHEDULE_FLEET_NOT_FOUND")
 		}
@@ -327,7 +406,7 @@ func (h *ScheduleHandler) UpdateFleetTrip(c *fiber.Ctx) error {
 			return false, nil
 		}
 		var one int
-		err := h.db.QueryRow(conflictQuery, orgID, employeeID, startDate.Time, endDate.Time, scheduleFleetID).Scan(&one)
+		err := t.QueryRow(conflictQuery, orgID, employeeID, startDate.Time, endDate.Time, scheduleFleetID).Scan(&one)
 		if err == nil {
 			return true, nil
 		}
@@ -364,7 +443,7 @@ func (h *ScheduleHandler) UpdateFleetTrip(c *fiber.Ctx) error {
 		WHERE ` + updateSftExpr + ` AND ` + updateOrgExpr + ` AND COALESCE(status, 0) = 1
 	`
 
-	res, err := h.db.Exec(updateQuery, driverID, crewID, time.Now(), userID, scheduleFleetID, orgID)
+	res, err := t.Exec(updateQuery, driverID, crewID, time.Now(), userID, scheduleFleetID, orgID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "failed to update")
 	}
@@ -397,9 +476,8 @@ func (h *ScheduleHandler) GetFleetAvailability(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, err.Error())
 	}
 
-	result, getErr := h.service.GetFleetAvailability(model.ScheduleFleetAvailabilityServiceInput{
-		OrganizationID: orgID,
-		Filter:         filter,
+	result, getErr := h.service.GetFleetAvailability(c.UserContext(), model.ScheduleFleetAvailabilityServiceInput{
+		Filter: filter,
 	})
 	if getErr != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(getErr), getErr.Error())
@@ -424,11 +502,10 @@ func (h *ScheduleHandler) GetDailyAvailabilityFleet(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	result, err := h.service.GetDailyAvailabilityFleet(model.DailyAvailabilityFleetServiceInput{
-		OrganizationID: orgID,
-		FleetID:        strings.TrimSpace(req.FleetID),
-		StartDate:      strings.TrimSpace(req.StartDate),
-		EndDate:        strings.TrimSpace(req.EndDate),
+	result, err := h.service.GetDailyAvailabilityFleet(c.UserContext(), model.DailyAvailabilityFleetServiceInput{
+		FleetID:   strings.TrimSpace(req.FleetID),
+		StartDate: strings.TrimSpace(req.StartDate),
+		EndDate:   strings.TrimSpace(req.EndDate),
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -453,11 +530,10 @@ func (h *ScheduleHandler) GetDailyAvailabilityFleetUnit(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	result, err := h.service.GetDailyAvailabilityFleetUnit(model.DailyAvailabilityFleetUnitServiceInput{
-		OrganizationID: orgID,
-		UnitID:         strings.TrimSpace(req.UnitID),
-		StartDate:      strings.TrimSpace(req.StartDate),
-		EndDate:        strings.TrimSpace(req.EndDate),
+	result, err := h.service.GetDailyAvailabilityFleetUnit(c.UserContext(), model.DailyAvailabilityFleetUnitServiceInput{
+		UnitID:    strings.TrimSpace(req.UnitID),
+		StartDate: strings.TrimSpace(req.StartDate),
+		EndDate:   strings.TrimSpace(req.EndDate),
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -477,9 +553,8 @@ func (h *ScheduleHandler) GetScheduleDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "order_id is required")
 	}
 
-	result, err := h.service.GetScheduleDetail(model.ScheduleDetailServiceInput{
-		OrganizationID: orgID,
-		OrderID:        orderID,
+	result, err := h.service.GetScheduleDetail(c.UserContext(), model.ScheduleDetailServiceInput{
+		OrderID: orderID,
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -499,9 +574,8 @@ func (h *ScheduleHandler) GetScheduleDetailByDate(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "date is required")
 	}
 
-	result, err := h.service.GetScheduleDetailByDate(model.ScheduleDetailByDateServiceInput{
-		OrganizationID: orgID,
-		Date:           date,
+	result, err := h.service.GetScheduleDetailByDate(c.UserContext(), model.ScheduleDetailByDateServiceInput{
+		Date: date,
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -528,7 +602,7 @@ func (h *ScheduleHandler) GetScheduleOperationAvailability(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "end_date is required")
 	}
 
-	result, err := h.service.GetScheduleOperationAvailability(orgID, startDate, endDate, employeeID)
+	result, err := h.service.GetScheduleOperationAvailability(c.UserContext(), startDate, endDate, employeeID)
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
 	}
@@ -557,11 +631,10 @@ func (h *ScheduleHandler) GetScheduleFleetUnitAvailability(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "fleet_id is required")
 	}
 
-	result, err := h.service.GetScheduleFleetUnitAvailability(model.ScheduleFleetUnitAvailabilityServiceInput{
-		OrganizationID: orgID,
-		StartDate:      startDate,
-		EndDate:        endDate,
-		FleetID:        fleetID,
+	result, err := h.service.GetScheduleFleetUnitAvailability(c.UserContext(), model.ScheduleFleetUnitAvailabilityServiceInput{
+		StartDate: startDate,
+		EndDate:   endDate,
+		FleetID:   fleetID,
 	})
 	if err != nil {
 		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
@@ -572,11 +645,15 @@ func (h *ScheduleHandler) GetScheduleFleetUnitAvailability(c *fiber.Ctx) error {
 	})
 }
 
-func (h *ScheduleHandler) latestScheduleIDByOrderID(orgID, orderID string) (string, error) {
+func (h *ScheduleHandler) latestScheduleIDByOrderID(ctx context.Context, orderID string) (string, error) {
 	orderID = strings.TrimSpace(orderID)
-	if orderID == "" || strings.TrimSpace(orgID) == "" {
+	if orderID == "" {
 		return "", nil
 	}
+	t, err := database.ForTenant(ctx, h.db)
+	if err != nil {
+		return "", err
+	}
 
 	placeholder := func(position int) string {
 		if h.driver == "postgres" || h.driver == "pgx" {
@@ -596,7 +673,7 @@ func (h *ScheduleHandler) latestScheduleIDByOrderID(orgID, orderID string) (stri
 
 	query := "SELECT " + scheduleIDExpr + " FROM schedules WHERE " + orderExpr + " AND " + orgExpr + " ORDER BY created_at DESC LIMIT 1"
 	var scheduleID string
-	if err := h.db.QueryRow(query, orderID, orgID).Scan(&scheduleID); err != nil {
+	if err := t.QueryRow(query, orderID, t.OrganizationID()).Scan(&scheduleID); err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
 		}
@@ -615,6 +692,10 @@ func (h *ScheduleHandler) validateOrderScheduleAvailability(c *fiber.Ctx, orderI
 	if orderID == "" {
 		return helper.BadRequestResponse(c, "order_id is required")
 	}
+	t, err := database.ForTenant(c.UserContext(), h.db)
+	if err != nil {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
 
 	placeholder := func(position int) string {
 		if h.driver == "postgres" || h.driver == "pgx" {
@@ -633,7 +714,7 @@ func (h *ScheduleHandler) validateOrderScheduleAvailability(c *fiber.Ctx, orderI
 	query := "SELECT start_date, end_date FROM fleet_orders WHERE " + orderExpr + " AND " + orgExpr + " LIMIT 1"
 	var startDate sql.NullTime
 	var endDate sql.NullTime
-	if err := h.db.QueryRow(query, orderID, orgID).Scan(&startDate, &endDate); err != nil {
+	if err := t.QueryRow(query, orderID, orgID).Scan(&startDate, &endDate); err != nil {
 		if err == sql.ErrNoRows {
 			return helper.BadRequestResponse(c, "ORDER_ID_NOT_FOUND")
 		}
@@ -697,7 +778,7 @@ func (h *ScheduleHandler) validateOrderScheduleAvailability(c *fiber.Ctx, orderI
 		}
 
 		var one int
-		if err := h.db.QueryRow(conflictQuery, args...).Scan(&one); err == nil {
+		if err := t.QueryRow(conflictQuery, args...).Scan(&one); err == nil {
 			return helper.BadRequestResponse(c, "UNIT_NOT_AVAILABLE")
 		} else if err != sql.ErrNoRows {
 			return helper.SendErrorResponse(c, fiber.StatusInternalServerError, "failed to validate availability")
@@ -2,7 +2,7 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
@@ -30,9 +30,9 @@ func (h *ServiceHandler) GetServiceFleets(c *fiber.Ctx) error {
 	page := c.QueryInt("page", 1)
 	perPage := c.QueryInt("per_page", 10)
 
-	items, err := h.service.GetServiceFleets(page, perPage)
+	items, err := h.service.GetServiceFleets(c.UserContext(), page, perPage)
 	if err != nil {
-		fmt.Println("Error fetching service fleets:", err)
+		slog.ErrorContext(c.UserContext(), "error fetching service fleets", "error", err)
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 	orgID, ok := c.Locals("organization_id").(string)
@@ -46,7 +46,7 @@ func (h *ServiceHandler) GetServiceFleets(c *fiber.Ctx) error {
 			fleetIDs = append(fleetIDs, items[i].FleetID)
 		}
 	}
-	ratings, err := h.service.GetFleetRatings(orgID, fleetIDs)
+	ratings, err := h.service.GetFleetRatings(c.UserContext(), fleetIDs)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -61,7 +61,7 @@ func (h *ServiceHandler) GetServiceFleets(c *fiber.Ctx) error {
 }
 
 func (h *ServiceHandler) GetServiceFleetDetail(c *fiber.Ctx) error {
-	fmt.Println("GetServiceFleetDetail")
+	slog.DebugContext(c.UserContext(), "get service fleet detail", "path", c.Path())
 	var req model.ServiceFleetDetailRequest
 	if err := c.BodyParser(&req); err != nil {
 		return helper.BadRequestResponse(c, "Invalid payload")
@@ -74,16 +74,16 @@ func (h *ServiceHandler) GetServiceFleetDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	res, err := h.service.GetServiceFleetDetail(req.FleetID)
+	res, err := h.service.GetServiceFleetDetail(c.UserContext(), req.FleetID)
 	if err != nil {
-		fmt.Println("Error fetching service fleet detail:", err)
+		slog.ErrorContext(c.UserContext(), "error fetching service fleet detail", "error", err)
 		code := fiber.StatusInternalServerError
 		if err.Error() == "fleet not found" {
 			code = fiber.StatusNotFound
 		}
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
-	ratings, err := h.service.GetFleetRatings(orgID, []string{req.FleetID})
+	ratings, err := h.service.GetFleetRatings(c.UserContext(), []string{req.FleetID})
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -92,7 +92,7 @@ func (h *ServiceHandler) GetServiceFleetDetail(c *fiber.Ctx) error {
 		res.Meta.Rating = v.Rating
 		res.Meta.TotalUlasan = v.TotalUlasan
 	}
-	reviews, err := h.service.GetFleetReviews(req.FleetID, orgID)
+	reviews, err := h.service.GetFleetReviews(c.UserContext(), req.FleetID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -116,7 +116,7 @@ func (h *ServiceHandler) GetServiceFleetAddons(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	items, err := h.service.GetServiceFleetAddons(orgID, fleetID)
+	items, err := h.service.GetServiceFleetAddons(c.UserContext(), fleetID)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -154,7 +154,7 @@ func (h *ServiceHandler) GetServiceFleetAvailibility(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	available, fleets, err := h.service.GetFleetAvailibility(orgID, startDate, endDate, req.FleetID)
+	available, fleets, err := h.service.GetFleetAvailibility(c.UserContext(), startDate, endDate, req.FleetID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -172,7 +172,7 @@ func (h *ServiceHandler) GetAvailableCities(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	cities, err := h.service.GetAvailableCities(orgID)
+	cities, err := h.service.GetAvailableCities(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -185,7 +185,7 @@ func (h *ServiceHandler) GetPublicTourPackages(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	items, err := h.tourService.GetPublicTourPackages(c.Context(), orgID)
+	items, err := h.tourService.GetPublicTourPackages(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -206,7 +206,7 @@ func (h *ServiceHandler) CheckCustomerAvailibility(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	data, err := h.customerService.CheckCustomerAvailibility(orgID, req.Email, req.Phone)
+	data, err := h.customerService.CheckCustomerAvailibility(c.UserContext(), req.Email, req.Phone)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -249,7 +249,7 @@ func (h *ServiceHandler) SubmitReview(c *fiber.Ctx) error {
 		orderID = decrypted
 	}
 
-	if err := h.service.SubmitOrderReview(orgID, orderID, req.Star, req.Review); err != nil {
+	if err := h.service.SubmitOrderReview(c.UserContext(), orderID, req.Star, req.Review); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -286,7 +286,7 @@ func (h *ServiceHandler) OrderAvailability(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid or missing organization_id")
 	}
 
-	res, err := h.service.GetOrderAvailability(orgID, req.FleetID, req.CityID, req.StartDate, req.EndDate, req.ServiceType)
+	res, err := h.service.GetOrderAvailability(c.UserContext(), req.FleetID, req.CityID, req.StartDate, req.EndDate, req.ServiceType)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
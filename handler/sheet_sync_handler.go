@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SheetSyncHandler struct {
+	service *service.SheetSyncService
+}
+
+func NewSheetSyncHandler(s *service.SheetSyncService) *SheetSyncHandler {
+	return &SheetSyncHandler{service: s}
+}
+
+// GetSheetSync handles GET /api/services/integrations/google-sheets
+func (h *SheetSyncHandler) GetSheetSync(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	res, err := h.service.GetStatus(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Google Sheets sync retrieved successfully", res)
+}
+
+// ConfigureSheetSync handles POST /api/services/integrations/google-sheets
+func (h *SheetSyncHandler) ConfigureSheetSync(c *fiber.Ctx) error {
+	var req model.SheetSyncConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.BadRequestResponse(c, "missing user context")
+	}
+
+	res, err := h.service.Configure(c.UserContext(), userID, &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Google Sheets sync saved successfully", res)
+}
+
+// SyncSheet handles POST /api/services/integrations/google-sheets/sync
+func (h *SheetSyncHandler) SyncSheet(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	res, err := h.service.SyncOrganization(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Google Sheets synced successfully", res)
+}
+
+// ResyncSheet handles POST /api/services/integrations/google-sheets/resync
+func (h *SheetSyncHandler) ResyncSheet(c *fiber.Ctx) error {
+	var req model.SheetResyncRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequestResponse(c, "invalid payload")
+	}
+	if errs := helper.ValidateStruct(&req); len(errs) > 0 {
+		return helper.SendValidationErrorResponse(c, errs)
+	}
+
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.BadRequestResponse(c, "missing organization context")
+	}
+
+	res, err := h.service.Resync(c.UserContext(), &req)
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Google Sheets resynced successfully", res)
+}
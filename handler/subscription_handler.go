@@ -10,6 +10,7 @@ import (
 
 type SubscriptionHandler struct {
 	subscriptionService *service.SubscriptionService
+	entitlementService  *service.EntitlementService
 }
 
 type SubmitSubscriptionRequest struct {
@@ -22,6 +23,11 @@ func NewSubscriptionHandler(subscriptionService *service.SubscriptionService) *S
 	}
 }
 
+// SetEntitlementService sets the service reporting package quota usage
+func (h *SubscriptionHandler) SetEntitlementService(entitlementService *service.EntitlementService) {
+	h.entitlementService = entitlementService
+}
+
 func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 	orgID, ok := c.Locals("organization_id").(string)
 
@@ -33,7 +39,7 @@ func (h *SubscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid organization ID format")
 	}
 
-	subscription, err := h.subscriptionService.GetSubscription(orgID)
+	subscription, err := h.subscriptionService.GetSubscription(c.UserContext())
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -58,7 +64,7 @@ func (h *SubscriptionHandler) GetSubscriptionHistory(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid organization ID format")
 	}
 
-	subscriptions, err := h.subscriptionService.GetSubscriptionHistory(userID, orgID)
+	subscriptions, err := h.subscriptionService.GetSubscriptionHistory(c.UserContext(), userID)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -66,6 +72,40 @@ func (h *SubscriptionHandler) GetSubscriptionHistory(c *fiber.Ctx) error {
 	return helper.SuccessResponse(c, fiber.StatusOK, "Subscription history retrieved successfully", subscriptions)
 }
 
+// GetSubscriptionEvents returns the lifecycle history of the organization's subscription
+func (h *SubscriptionHandler) GetSubscriptionEvents(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.UnauthorizedResponse(c, "Organization not authenticated")
+	}
+	if _, err := uuid.Parse(orgID); err != nil {
+		return helper.BadRequestResponse(c, "Invalid organization ID format")
+	}
+
+	events, err := h.subscriptionService.GetSubscriptionEvents(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Subscription events retrieved successfully", events)
+}
+
+// GetSubscriptionUsage returns how much of each package quota the organization uses
+func (h *SubscriptionHandler) GetSubscriptionUsage(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.UnauthorizedResponse(c, "Organization not authenticated")
+	}
+	if _, err := uuid.Parse(orgID); err != nil {
+		return helper.BadRequestResponse(c, "Invalid organization ID format")
+	}
+
+	usage, err := h.entitlementService.Usage(c.UserContext())
+	if err != nil {
+		return helper.SendErrorResponse(c, service.GetStatusCode(err), err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Subscription usage retrieved successfully", usage)
+}
+
 func (h *SubscriptionHandler) SubmitSubscription(c *fiber.Ctx) error {
 	userID, ok := c.Locals("user_id").(string)
 	if !ok || userID == "" {
@@ -97,7 +137,7 @@ func (h *SubscriptionHandler) SubmitSubscription(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid package ID")
 	}
 
-	result, err := h.subscriptionService.SubmitSubscriptionPayment(packageID, userID, orgID)
+	result, err := h.subscriptionService.SubmitSubscriptionPayment(c.UserContext(), packageID, userID)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -136,7 +176,7 @@ func (h *SubscriptionHandler) GetSubscriptionSummary(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid package ID")
 	}
 
-	result, err := h.subscriptionService.GetSubscriptionSummary(packageID, orgID)
+	result, err := h.subscriptionService.GetSubscriptionSummary(c.UserContext(), packageID)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -150,7 +190,7 @@ func (h *SubscriptionHandler) GetSubscriptionDetailByInvoice(c *fiber.Ctx) error
 		return helper.BadRequestResponse(c, "Invoice number is required")
 	}
 
-	result, err := h.subscriptionService.GetSubscriptionDetailByInvoice(invoiceNumber)
+	result, err := h.subscriptionService.GetSubscriptionDetailByInvoice(c.UserContext(), invoiceNumber)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -32,7 +32,7 @@ func (h *SystemHandler) GetSystemSummarymarize(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid period value")
 	}
 
-	res, err := h.service.GetSystemSummarize(period)
+	res, err := h.service.GetSystemSummarize(c.UserContext(), period)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -47,7 +47,7 @@ func (h *SystemHandler) GetDeviceList(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid status value")
 	}
 
-	res, err := h.service.GetDeviceList(search, status)
+	res, err := h.service.GetDeviceList(c.UserContext(), search, status)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -70,7 +70,7 @@ func (h *SystemHandler) UpdateDevice(c *fiber.Ctx) error {
 			return helper.BadRequestResponse(c, "account is required")
 		}
 
-		if err := h.service.UpdateDevice(req.Account, "disable", nil); err != nil {
+		if err := h.service.UpdateDevice(c.UserContext(), req.Account, "disable", nil); err != nil {
 			if err == sql.ErrNoRows {
 				return helper.NotFoundResponse(c, "Device not found")
 			}
@@ -89,7 +89,7 @@ func (h *SystemHandler) UpdateDevice(c *fiber.Ctx) error {
 			return helper.BadRequestResponse(c, "account is required")
 		}
 
-		if err := h.service.UpdateDevice(req.Account, "enable", &req); err != nil {
+		if err := h.service.UpdateDevice(c.UserContext(), req.Account, "enable", &req); err != nil {
 			if err == sql.ErrNoRows {
 				return helper.NotFoundResponse(c, "Device not found")
 			}
@@ -109,7 +109,7 @@ func (h *SystemHandler) GetOrganizations(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid status value")
 	}
 
-	res, err := h.service.GetOrganizations(search, status)
+	res, err := h.service.GetOrganizations(c.UserContext(), search, status)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -124,7 +124,7 @@ func (h *SystemHandler) GetUsers(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid is_active value")
 	}
 
-	res, err := h.service.GetUsers(search, isActive)
+	res, err := h.service.GetUsers(c.UserContext(), search, isActive)
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -133,7 +133,7 @@ func (h *SystemHandler) GetUsers(c *fiber.Ctx) error {
 }
 
 func (h *SystemHandler) GetMessages(c *fiber.Ctx) error {
-	res, err := h.service.GetMessages()
+	res, err := h.service.GetMessages(c.UserContext())
 	if err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -145,7 +145,7 @@ func (h *SystemHandler) ReadMessage(c *fiber.Ctx) error {
 	if messageID == "" {
 		return helper.BadRequestResponse(c, "message_id is required")
 	}
-	if err := h.service.ReadMessage(messageID); err != nil {
+	if err := h.service.ReadMessage(c.UserContext(), messageID); err != nil {
 		return helper.SendErrorResponse(c, fiber.StatusInternalServerError, err.Error())
 	}
 	return helper.SuccessResponse(c, fiber.StatusOK, "Message marked as read", nil)
@@ -13,7 +13,7 @@ func (h *OrganizationHandler) TeamListDivisions(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	items, err := h.orgService.ListDivisions(orgID)
+	items, err := h.orgService.ListDivisions(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -39,7 +39,7 @@ func (h *OrganizationHandler) TeamCreateDivision(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	id, err := h.orgService.CreateDivision(orgID, userID, &req)
+	id, err := h.orgService.CreateDivision(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -67,7 +67,7 @@ func (h *OrganizationHandler) TeamUpdateDivision(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.UpdateDivision(orgID, userID, &req); err != nil {
+	if err := h.orgService.UpdateDivision(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -92,7 +92,7 @@ func (h *OrganizationHandler) TeamDeleteDivision(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.DeleteDivision(orgID, userID, req.DivisionID); err != nil {
+	if err := h.orgService.DeleteDivision(c.UserContext(), userID, req.DivisionID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -104,7 +104,7 @@ func (h *OrganizationHandler) TeamListRoles(c *fiber.Ctx) error {
 	if !ok || orgID == "" {
 		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
 	}
-	items, err := h.orgService.ListRoles(orgID)
+	items, err := h.orgService.ListRoles(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -130,7 +130,7 @@ func (h *OrganizationHandler) TeamCreateRole(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	id, err := h.orgService.CreateRole(orgID, userID, &req)
+	id, err := h.orgService.CreateRole(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -158,7 +158,7 @@ func (h *OrganizationHandler) TeamUpdateRole(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.UpdateRole(orgID, userID, &req); err != nil {
+	if err := h.orgService.UpdateRole(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -183,9 +183,45 @@ func (h *OrganizationHandler) TeamDeleteRole(c *fiber.Ctx) error {
 		return helper.SendValidationErrorResponse(c, validationErrors)
 	}
 
-	if err := h.orgService.DeleteRole(orgID, userID, req.RoleID); err != nil {
+	if err := h.orgService.DeleteRole(c.UserContext(), userID, req.RoleID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 	return helper.SuccessResponse(c, fiber.StatusOK, "Role deleted", nil)
 }
+
+func (h *OrganizationHandler) TeamListPermissions(c *fiber.Ctx) error {
+	granted, _ := c.Locals("permissions").([]string)
+	if granted == nil {
+		granted = []string{}
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Permissions loaded", model.MemberPermissions{
+		Permissions: service.PermissionOptions(),
+		Granted:     granted,
+	})
+}
+
+func (h *OrganizationHandler) TeamAssignMemberRole(c *fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(string)
+	if !ok || orgID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Missing organization context")
+	}
+	userID, ok := c.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var req model.AssignMemberRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if validationErrors := helper.ValidateStruct(req); len(validationErrors) > 0 {
+		return helper.SendValidationErrorResponse(c, validationErrors)
+	}
+
+	if err := h.orgService.AssignMemberRole(c.UserContext(), userID, &req); err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+	return helper.SuccessResponse(c, fiber.StatusOK, "Member role updated", nil)
+}
@@ -22,7 +22,7 @@ func (h *TourPackageHandler) GetTourPackages(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.GetTourPackages(orgID)
+	items, err := h.service.GetTourPackages(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -36,7 +36,7 @@ func (h *TourPackageHandler) GetTourPackageOrderList(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	items, err := h.service.GetTourPackageOrderList(orgID)
+	items, err := h.service.GetTourPackageOrderList(c.UserContext())
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -56,7 +56,7 @@ func (h *TourPackageHandler) CreateTourPackage(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.CreateTourPackage(c.Context(), &req, orgID, userID); err != nil {
+	if err := h.service.CreateTourPackage(c.UserContext(), &req, userID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -75,7 +75,7 @@ func (h *TourPackageHandler) UpdateTourPackage(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.UpdateTourPackage(c.Context(), &req, orgID, userID); err != nil {
+	if err := h.service.UpdateTourPackage(c.UserContext(), &req, userID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -93,7 +93,7 @@ func (h *TourPackageHandler) TourPackageDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	res, err := h.service.GetTourPackageDetail(c.Context(), orgID, req.PackageID)
+	res, err := h.service.GetTourPackageDetail(c.UserContext(), req.PackageID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -113,7 +113,7 @@ func (h *TourPackageHandler) SetTourPackageActiveStatus(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.SetTourPackageActiveStatus(c.Context(), orgID, userID, req.Action, req.PackageID); err != nil {
+	if err := h.service.SetTourPackageActiveStatus(c.UserContext(), userID, req.Action, req.PackageID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -132,7 +132,7 @@ func (h *TourPackageHandler) DeleteTourPackage(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.DeleteTourPackage(c.Context(), orgID, userID, packageID); err != nil {
+	if err := h.service.DeleteTourPackage(c.UserContext(), userID, packageID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -151,7 +151,7 @@ func (h *TourPackageHandler) CreateTourPackageOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	orderID, err := h.service.CreateTourPackageOrder(c.Context(), orgID, userID, &req)
+	orderID, err := h.service.CreateTourPackageOrder(c.UserContext(), userID, &req)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -173,7 +173,7 @@ func (h *TourPackageHandler) UpdateTourPackageOrder(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	if err := h.service.UpdateTourPackageOrder(c.Context(), orgID, userID, &req); err != nil {
+	if err := h.service.UpdateTourPackageOrder(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -191,7 +191,7 @@ func (h *TourPackageHandler) GetTourPackageOrderDetail(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "missing organization context")
 	}
 
-	res, err := h.service.GetTourPackageOrderDetail(c.Context(), orgID, orderID)
+	res, err := h.service.GetTourPackageOrderDetail(c.UserContext(), orderID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/service"
@@ -22,23 +22,23 @@ func (h *TransactionHandler) GetFleetTripSummary(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	totalAmount, err := h.service.GetFleetTripTotalAmount(scheduleNumber)
+	totalAmount, err := h.service.GetFleetTripTotalAmount(c.UserContext(), scheduleNumber)
 	if err != nil {
-		fmt.Println("failed to get fleet trip total amount: ", err)
+		slog.ErrorContext(c.UserContext(), "failed to get fleet trip total amount", "error", err)
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	summary, err := h.service.GetFleetTripAmountSummaryByPaymentMethod(scheduleNumber, orgID)
+	summary, err := h.service.GetFleetTripAmountSummaryByPaymentMethod(c.UserContext(), scheduleNumber)
 	if err != nil {
-		fmt.Println("failed to get fleet trip amount summary by payment method: ", err)
+		slog.ErrorContext(c.UserContext(), "failed to get fleet trip amount summary by payment method", "error", err)
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	rows, err := h.service.ListFleetTripExpenses(scheduleNumber, orgID)
+	rows, err := h.service.ListFleetTripExpenses(c.UserContext(), scheduleNumber)
 	if err != nil {
-		fmt.Println("failed to list fleet trip expenses: ", err)
+		slog.ErrorContext(c.UserContext(), "failed to list fleet trip expenses", "error", err)
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
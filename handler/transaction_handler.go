@@ -5,6 +5,7 @@ import (
 	"os"
 	"service-travego/configs"
 	"service-travego/helper"
+	"service-travego/internal/export"
 	"service-travego/model"
 	"service-travego/service"
 	"sort"
@@ -172,28 +173,90 @@ func (h *TransactionHandler) ListAllExpenses(c *fiber.Ctx) error {
 }
 
 func (h *TransactionHandler) listTransactions(c *fiber.Ctx, mode string) error {
+	transformedRes, err := h.transactionList(c, mode)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+
+	msg := "Transactions retrieved"
+	if mode == "revenue" {
+		msg = "Revenue transactions retrieved"
+	} else {
+		msg = "Expense transactions retrieved"
+	}
+
+	return helper.SuccessResponse(c, fiber.StatusOK, msg, transformedRes)
+}
+
+func (h *TransactionHandler) ExportRevenue(c *fiber.Ctx) error {
+	return h.exportTransactions(c, "revenue")
+}
+
+func (h *TransactionHandler) ExportExpenses(c *fiber.Ctx) error {
+	return h.exportTransactions(c, "expenses")
+}
+
+// exportTransactions downloads the revenue or expenses, with the filters of
+// the list, as xlsx or csv
+func (h *TransactionHandler) exportTransactions(c *fiber.Ctx, mode string) error {
+	rows, err := h.transactionList(c, mode)
+	if err != nil {
+		code := service.GetStatusCode(err)
+		return helper.SendErrorResponse(c, code, err.Error())
+	}
+
+	name, sheet := "pendapatan", "Pendapatan"
+	if mode != "revenue" {
+		name, sheet = "pengeluaran", "Pengeluaran"
+	}
+	columns := []export.Column{
+		{Header: "No. Invoice"},
+		{Header: "Tanggal Transaksi", Kind: export.Date},
+		{Header: "Keterangan"},
+		{Header: "Kategori"},
+		{Header: "Item"},
+		{Header: "Metode Pembayaran"},
+		{Header: "Jenis Pembayaran"},
+		{Header: "Jumlah (Rp)", Kind: export.Currency},
+		{Header: "Dibuat Oleh"},
+		{Header: "Dibuat Pada", Kind: export.DateTime},
+	}
+	return helper.SendExport(c, name, sheet, columns, func(w export.Writer) error {
+		for _, t := range rows {
+			if err := w.WriteRow(t.InvoiceNumber, t.TransactionDate, t.Description, t.TransactionCategoryLabel, t.TransactionItemLabel,
+				t.PaymentMethodLabel, t.PaymentTypeLabel, t.Amount, t.CreatedBy, t.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// transactionList loads the revenue or expenses matching the query, with
+// their labels
+func (h *TransactionHandler) transactionList(c *fiber.Ctx, mode string) ([]model.TransactionListItem, error) {
 	var req model.TransactionListRequest
 	if err := c.QueryParser(&req); err != nil {
-		return helper.BadRequestResponse(c, "Invalid query parameters")
+		return nil, service.NewServiceError(service.ErrInvalidInput, fiber.StatusBadRequest, "Invalid query parameters")
 	}
 
 	orgID, ok := c.Locals("organization_id").(string)
 	if !ok || orgID == "" {
-		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
+		return nil, service.NewServiceError(service.ErrUnauthorized, fiber.StatusUnauthorized, "Organization not found")
 	}
 
 	var rows []model.TransactionListItem
 	var err error
 
 	if mode == "revenue" {
-		rows, err = h.service.ListAllRevenue(orgID, &req)
+		rows, err = h.service.ListAllRevenue(c.UserContext(), &req)
 	} else {
-		rows, err = h.service.ListAllExpenses(orgID, &req)
+		rows, err = h.service.ListAllExpenses(c.UserContext(), &req)
 	}
 
 	if err != nil {
-		code := service.GetStatusCode(err)
-		return helper.SendErrorResponse(c, code, err.Error())
+		return nil, err
 	}
 
 	ensurePaymentStatusLoaded()
@@ -274,15 +337,7 @@ func (h *TransactionHandler) listTransactions(c *fiber.Ctx, mode string) error {
 			PaymentTypeLabel:         paymentTypeLabel,
 		}
 	}
-
-	msg := "Transactions retrieved"
-	if mode == "revenue" {
-		msg = "Revenue transactions retrieved"
-	} else {
-		msg = "Expense transactions retrieved"
-	}
-
-	return helper.SuccessResponse(c, fiber.StatusOK, msg, transformedRes)
+	return transformedRes, nil
 }
 
 func (h *TransactionHandler) CreateManualRevenue(c *fiber.Ctx) error {
@@ -323,7 +378,7 @@ func (h *TransactionHandler) CreateManualRevenue(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found")
 	}
 
-	err := h.service.CreateManualRevenue(orgID, userID, &model.CreateManualRevenueRequest{
+	err := h.service.CreateManualRevenue(c.UserContext(), userID, &model.CreateManualRevenueRequest{
 		Description:     req.Description,
 		TransactionDate: req.TransactionDate,
 		Status:          req.Status,
@@ -384,7 +439,7 @@ func (h *TransactionHandler) SubmitExpenseTransaction(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found")
 	}
 
-	if err := h.service.SubmitExpenseTransaction(orgID, userID, &req); err != nil {
+	if err := h.service.SubmitExpenseTransaction(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -411,7 +466,8 @@ func (h *TransactionHandler) DeleteExpenseTransaction(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "Organization not found")
 	}
 
-	if err := h.service.DeleteExpenseTransaction(orgID, req.TransactionID); err != nil {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.service.DeleteExpenseTransaction(c.UserContext(), userID, req.TransactionID); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -447,7 +503,7 @@ func (h *TransactionHandler) UpdateExpenseTransaction(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found")
 	}
 
-	if err := h.service.UpdateExpenseTransaction(orgID, userID, &req); err != nil {
+	if err := h.service.UpdateExpenseTransaction(c.UserContext(), userID, &req); err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
@@ -715,19 +771,19 @@ func (h *TransactionHandler) SubmitFleetTripExpenseForm(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found")
 	}
 
-	err := h.service.SubmitFleetTripExpense(orgID, userID, req.TransactionItem, req.ScheduleNumber, req.PaymentMethod, req.Amount, req.Description)
+	err := h.service.SubmitFleetTripExpense(c.UserContext(), userID, req.TransactionItem, req.ScheduleNumber, req.PaymentMethod, req.Amount, req.Description)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	totalAmount, err := h.service.GetFleetTripTotalAmount(req.ScheduleNumber)
+	totalAmount, err := h.service.GetFleetTripTotalAmount(c.UserContext(), req.ScheduleNumber)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
 	}
 
-	summary, err := h.service.GetFleetTripAmountSummaryByPaymentMethod(req.ScheduleNumber, orgID)
+	summary, err := h.service.GetFleetTripAmountSummaryByPaymentMethod(c.UserContext(), req.ScheduleNumber)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -769,7 +825,7 @@ func (h *TransactionHandler) DeleteFleetTripExpenseForm(c *fiber.Ctx) error {
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found")
 	}
 
-	err := h.service.DeleteFleetTripExpense(orgID, userID, req.ScheduleNumber, req.TransactionTripID)
+	err := h.service.DeleteFleetTripExpense(c.UserContext(), userID, req.ScheduleNumber, req.TransactionTripID)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
@@ -808,7 +864,7 @@ func (h *TransactionHandler) SubmitFleetTripReimbursementForm(c *fiber.Ctx) erro
 		return helper.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found")
 	}
 
-	err := h.service.SubmitFleetTripReimbursement(orgID, userID, req.ScheduleNumber, req.RecipientID, req.PaymentMethodID, req.TransactionDate)
+	err := h.service.SubmitFleetTripReimbursement(c.UserContext(), userID, req.ScheduleNumber, req.RecipientID, req.PaymentMethodID, req.TransactionDate)
 	if err != nil {
 		code := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, code, err.Error())
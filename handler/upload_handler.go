@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"fmt"
+	"log/slog"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -97,7 +97,7 @@ func (h *UploadHandler) UploadCommon(c *fiber.Ctx) error {
 		}
 
 		file, err := c.FormFile("files")
-		fmt.Println("check file ", file)
+		slog.DebugContext(c.UserContext(), "check file", "file", file)
 		if err != nil || file == nil {
 			file, err = c.FormFile("file")
 			if err != nil || file == nil {
@@ -134,7 +134,7 @@ func (h *UploadHandler) UploadCommon(c *fiber.Ctx) error {
 
 		avatarPath := "/assets/avatar/" + filename
 		if h.userRepo != nil {
-			if err := h.userRepo.UpdateAvatar(userID, avatarPath); err != nil {
+			if err := h.userRepo.UpdateAvatar(c.UserContext(), userID, avatarPath); err != nil {
 				_ = os.Remove(destPath)
 				statusCode := service.GetStatusCode(err)
 				return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -150,7 +150,7 @@ func (h *UploadHandler) UploadCommon(c *fiber.Ctx) error {
 	}
 
 	// Validate type
-	validTypes := []string{"armada", "package", "order", "content", "employee_photo", "payment"}
+	validTypes := []string{"armada", "package", "order", "content", "employee_photo", "payment", "document"}
 	isValid := false
 	for _, vt := range validTypes {
 		if uploadType == vt {
@@ -159,7 +159,7 @@ func (h *UploadHandler) UploadCommon(c *fiber.Ctx) error {
 		}
 	}
 	if !isValid {
-		return helper.BadRequestResponse(c, "type must be one of: armada, package, order, content, employee_photo, payment")
+		return helper.BadRequestResponse(c, "type must be one of: armada, package, order, content, employee_photo, payment, document")
 	}
 
 	// Support multiple files
@@ -21,7 +21,7 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 }
 
 func (h *UserHandler) GetAllUsers(c *fiber.Ctx) error {
-	users, err := h.userService.GetAllUsers()
+	users, err := h.userService.GetAllUsers(c.UserContext())
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, "Failed to fetch users")
@@ -40,7 +40,7 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid user ID format")
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(c.UserContext(), id)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -69,7 +69,7 @@ func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
 		Address:  req.Address,
 	}
 
-	createdUser, err := h.userService.CreateUser(user)
+	createdUser, err := h.userService.CreateUser(c.UserContext(), user)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -105,7 +105,7 @@ func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
 		Province: strconv.Itoa(req.Province),
 	}
 
-	updatedUser, err := h.userService.UpdateUser(id, user)
+	updatedUser, err := h.userService.UpdateUser(c.UserContext(), id, user)
 	if err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
@@ -124,7 +124,7 @@ func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid user ID format")
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
+	if err := h.userService.DeleteUser(c.UserContext(), id); err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
@@ -142,7 +142,7 @@ func (h *UserHandler) DeleteProfile(c *fiber.Ctx) error {
 		return helper.BadRequestResponse(c, "Invalid user ID format")
 	}
 
-	if err := h.userService.DeleteProfile(userID); err != nil {
+	if err := h.userService.DeleteProfile(c.UserContext(), userID); err != nil {
 		statusCode := service.GetStatusCode(err)
 		return helper.SendErrorResponse(c, statusCode, err.Error())
 	}
@@ -3,13 +3,14 @@ package helper
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"service-travego/configs"
+	"service-travego/database"
 	"service-travego/repository"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -27,8 +28,10 @@ func AuthTokenMiddleware() fiber.Handler {
 }
 
 // JWTAuthorizationMiddleware extracts JWT token from Authorization header and validates it
-// Sets user_id in locals for use in handlers
+// Sets user_id in locals for use in handlers and refuses changes from
+// organizations whose subscription is read only
 func JWTAuthorizationMiddleware() fiber.Handler {
+	readOnly := SubscriptionReadOnlyMiddleware()
 	return func(c *fiber.Ctx) error {
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
@@ -85,7 +88,7 @@ func JWTAuthorizationMiddleware() fiber.Handler {
 		if claims, ok := token.Claims.(*AuthTokenClaims); ok && token.Valid {
 			c.Locals("username", claims.Username)
 			c.Locals("organization_name", claims.OrganizationName)
-			c.Locals("organization_id", claims.OrganizationID)
+			setOrganization(c, claims.OrganizationID)
 			c.Locals("is_admin", claims.IsAdmin)
 			// Decrypt sensitive token to populate locals
 			if claims.Token != "" {
@@ -96,14 +99,26 @@ func JWTAuthorizationMiddleware() fiber.Handler {
 					isAdmin = false
 				}
 				if derr == nil {
+					if data.SessionID != "" && redisClient != nil {
+						active, err := AuthSessionExists(data.UserID, data.SessionID)
+						if err == nil && !active {
+							return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+								"status":         "error",
+								"message":        "Session has been revoked",
+								"data":           nil,
+								"transaction_id": GetTransactionID(c),
+							})
+						}
+					}
+					c.Locals("session_id", data.SessionID)
 					c.Locals("user_id", data.UserID)
-					c.Locals("organization_id", data.OrganizationID)
+					setOrganization(c, data.OrganizationID)
 					c.Locals("organization_role", data.OrganizationRole)
 					c.Locals("is_admin", isAdmin)
 					c.Locals("is_superadmin", isSuperAdmin)
 				}
 			}
-			return c.Next()
+			return readOnly(c)
 		}
 
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -133,8 +148,10 @@ func ApiKeyMiddleware() fiber.Handler {
 	}
 }
 
-// DualAuthMiddleware checks for api-key header or Authorization header
-func DualAuthMiddleware(orgRepo *repository.OrganizationRepository) fiber.Handler {
+// DualAuthMiddleware checks for api-key header or Authorization header.
+// API keys minted by an organization are accepted when they hold one of
+// scopes; without scopes only the static key is accepted.
+func DualAuthMiddleware(orgRepo *repository.OrganizationRepository, scopes ...configs.APIKeyScope) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Check for api-key header
 		apiKey := c.Get("api-key")
@@ -154,6 +171,10 @@ func DualAuthMiddleware(orgRepo *repository.OrganizationRepository) fiber.Handle
 			apiKey = c.Get("APIKEY")
 		}
 
+		if apiKey != "" && isOrganizationAPIKey(apiKey) {
+			return authenticateOrganizationAPIKey(c, orgRepo, apiKey, scopes)
+		}
+
 		if apiKey != "" {
 			var orgID string
 			var userID string
@@ -242,12 +263,12 @@ func DualAuthMiddleware(orgRepo *repository.OrganizationRepository) fiber.Handle
 			}
 
 			// Validate organization_id against database
-			org, err := orgRepo.FindByID(orgID)
+			org, err := orgRepo.FindByID(c.UserContext(), orgID)
 			if err != nil {
 				// If not found by ID, maybe it's organization_code?
-				org, err = orgRepo.FindByCode(orgID)
+				org, err = orgRepo.FindByCode(c.UserContext(), orgID)
 				if err != nil {
-					fmt.Printf("Error fetching organization (ID/Code: %s): %v\n", orgID, err)
+					slog.ErrorContext(c.UserContext(), "error fetching organization by id or code", "organization", orgID, "error", err)
 					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 						"status":  "error",
 						"message": "Organization not found or invalid",
@@ -257,13 +278,13 @@ func DualAuthMiddleware(orgRepo *repository.OrganizationRepository) fiber.Handle
 			}
 
 			// Set locals
-			c.Locals("organization_id", orgID)
+			setOrganization(c, orgID)
 			if userID != "" {
 				c.Locals("user_id", userID)
 			}
 			c.Locals("organization_code", org.OrganizationCode)
 			c.Locals("role", "visitor")
-			return c.Next()
+			return enforceSubscriptionReadOnly(c)
 		}
 
 		// Check for Authorization header
@@ -323,29 +344,15 @@ func StaticApiKeyMiddleware() fiber.Handler {
 	}
 }
 
-// AuthRateLimiter creates a rate limiting middleware for sensitive auth endpoints (login, register)
-// Default limit: 5 requests per 1 minute per IP
+// AuthRateLimiter creates a rate limiting middleware for sensitive auth endpoints (login, register).
+// It uses the "auth" limit, by default 5 requests per 1 minute per IP.
 func AuthRateLimiter() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        5,
-		Expiration: 1 * time.Minute,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			// Try to get real IP if running behind a proxy
-			ip := c.Get("X-Forwarded-For")
-			if ip == "" {
-				ip = c.Get("X-Real-IP")
-			}
-			if ip == "" {
-				ip = c.IP()
-			}
-			// X-Forwarded-For can contain multiple IPs separated by comma
-			if strings.Contains(ip, ",") {
-				ip = strings.Split(ip, ",")[0]
-			}
-			return strings.TrimSpace(ip)
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return SendErrorResponse(c, fiber.StatusTooManyRequests, "Too many requests. Please try again after 1 minute.")
-		},
-	})
+	return RateLimiter("auth")
+}
+
+// setOrganization stores the organization of the request in the locals read
+// by handlers and in the user context read by tenant scoped repositories
+func setOrganization(c *fiber.Ctx, organizationID string) {
+	c.Locals("organization_id", organizationID)
+	c.SetUserContext(database.WithOrganization(c.UserContext(), organizationID))
 }
@@ -76,6 +76,27 @@ type OrderReceivedEmailData struct {
 	Year                    int
 }
 
+type NewDeviceLoginEmailData struct {
+	Username   string
+	DeviceName string
+	IPAddress  string
+	LoginTime  string
+	Year       int
+}
+
+// SubscriptionNoticeEmailData fills the renewal reminder, grace period and
+// read-only emails of a subscription
+type SubscriptionNoticeEmailData struct {
+	Title            string
+	Message          string
+	OrganizationName string
+	PackageName      string
+	ExpiryDate       string
+	GraceUntil       string
+	RenewURL         string
+	Year             int
+}
+
 // GetOTPLength returns the OTP length from environment variable or default to 8
 func GetOTPLength() int {
 	if envLength := os.Getenv("OTP_LENGTH"); envLength != "" {
@@ -136,6 +157,18 @@ func renderEmailTemplate(templatePath string, data interface{}) (string, error)
 	return buf.String(), nil
 }
 
+// Email is a rendered email, ready to be queued in the outbox or sent
+type Email struct {
+	To       string
+	Subject  string
+	HTMLBody string
+}
+
+// SendEmail sends a rendered email over SMTP
+func SendEmail(cfg *configs.EmailConfig, email *Email) error {
+	return sendHTMLEmail(cfg, email.To, email.Subject, email.HTMLBody)
+}
+
 func sendHTMLEmail(cfg *configs.EmailConfig, to, subject, htmlBody string) error {
 	from := cfg.From
 	password := cfg.Password
@@ -164,7 +197,7 @@ func sendHTMLEmail(cfg *configs.EmailConfig, to, subject, htmlBody string) error
 	return nil
 }
 
-func SendOTPEmail(cfg *configs.EmailConfig, to, username, otp string) error {
+func NewOTPEmail(to, username, otp string) (*Email, error) {
 	data := EmailTemplateData{
 		Username:      username,
 		OTP:           otp,
@@ -174,14 +207,14 @@ func SendOTPEmail(cfg *configs.EmailConfig, to, username, otp string) error {
 
 	htmlBody, err := renderEmailTemplate("otp_register.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := "Verify Your Email - TraveGO"
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendRegisterSuccessEmail(cfg *configs.EmailConfig, to, username string) error {
+func NewRegisterSuccessEmail(to, username string) (*Email, error) {
 	data := EmailTemplateData{
 		Username: username,
 		Year:     time.Now().Year(),
@@ -189,14 +222,14 @@ func SendRegisterSuccessEmail(cfg *configs.EmailConfig, to, username string) err
 
 	htmlBody, err := renderEmailTemplate("register_success.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := "Welcome to TraveGO - Registration Successful"
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendResetPasswordOTPEmail(cfg *configs.EmailConfig, to, username, otp string) error {
+func NewResetPasswordOTPEmail(to, username, otp string) (*Email, error) {
 	data := EmailTemplateData{
 		Username:      username,
 		OTP:           otp,
@@ -206,15 +239,15 @@ func SendResetPasswordOTPEmail(cfg *configs.EmailConfig, to, username, otp strin
 
 	htmlBody, err := renderEmailTemplate("otp_reset_password.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := "Reset Your Password - TraveGO"
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-// SendResetPasswordEmail sends a reset password email with link and token
-func SendResetPasswordEmail(cfg *configs.EmailConfig, to, username, resetLink string, expiryMinutes int) error {
+// NewResetPasswordEmail renders a reset password email with link and token
+func NewResetPasswordEmail(to, username, resetLink string, expiryMinutes int) (*Email, error) {
 	data := EmailTemplateData{
 		Username:      username,
 		ResetLink:     resetLink,
@@ -224,15 +257,15 @@ func SendResetPasswordEmail(cfg *configs.EmailConfig, to, username, resetLink st
 
 	htmlBody, err := renderEmailTemplate("reset_password.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := "Reset Your Password - TraveGO"
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-// SendJoinOrganizationApprovalEmail sends an email to organization members for approval
-func SendJoinOrganizationApprovalEmail(cfg *configs.EmailConfig, to, username, requesterUsername, organizationName, approveURL string) error {
+// NewJoinOrganizationApprovalEmail renders an email to organization members for approval
+func NewJoinOrganizationApprovalEmail(to, username, requesterUsername, organizationName, approveURL string) (*Email, error) {
 	data := EmailTemplateData{
 		Username:         username,
 		Year:             time.Now().Year(),
@@ -243,64 +276,90 @@ func SendJoinOrganizationApprovalEmail(cfg *configs.EmailConfig, to, username, r
 
 	htmlBody, err := renderEmailTemplate("join_organization_approval.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := "New Member Request - TraveGO"
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendOrderSuccessEmail(cfg *configs.EmailConfig, to string, data OrderSuccessEmailData) error {
+func NewOrderSuccessEmail(to string, data OrderSuccessEmailData) (*Email, error) {
 	data.Year = time.Now().Year()
 	htmlBody, err := renderEmailTemplate("order_success.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := fmt.Sprintf("Order Confirmation - %s", data.OrderID)
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendOrderApprovedEmail(cfg *configs.EmailConfig, to string, organizationName string, data OrderSuccessEmailData) error {
+func NewOrderApprovedEmail(to string, organizationName string, data OrderSuccessEmailData) (*Email, error) {
 	data.Year = time.Now().Year()
 	htmlBody, err := renderEmailTemplate("order_approved.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := fmt.Sprintf("Pesanan Dikonfirmasi oleh Tim %s - %s", organizationName, data.OrderID)
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendPaymentSuccessEmail(cfg *configs.EmailConfig, to string, data PaymentSuccessEmailData) error {
+func NewPaymentSuccessEmail(to string, data PaymentSuccessEmailData) (*Email, error) {
 	data.Year = time.Now().Year()
 	htmlBody, err := renderEmailTemplate("payment_success.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := fmt.Sprintf("Pembayaran Berhasil - %s", data.OrderID)
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendPaymentReceivedEmail(cfg *configs.EmailConfig, to string, data PaymentSuccessEmailData) error {
+func NewPaymentReceivedEmail(to string, data PaymentSuccessEmailData) (*Email, error) {
 	data.Year = time.Now().Year()
 	htmlBody, err := renderEmailTemplate("payment_received.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := fmt.Sprintf("Pembayaran Diterima - %s", data.OrderID)
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
 
-func SendOrderReceivedEmail(cfg *configs.EmailConfig, to string, data OrderReceivedEmailData) error {
+func NewOrderReceivedEmail(to string, data OrderReceivedEmailData) (*Email, error) {
 	data.Year = time.Now().Year()
 	htmlBody, err := renderEmailTemplate("order_received.html", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subject := fmt.Sprintf("Pesanan Baru - %s", data.OrderID)
-	return sendHTMLEmail(cfg, to, subject, htmlBody)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
+}
+
+// NewDeviceLoginEmail tells a user their account was logged in to from a
+// device it wasn't used on before
+func NewDeviceLoginEmail(to string, data NewDeviceLoginEmailData) (*Email, error) {
+	data.Year = time.Now().Year()
+	htmlBody, err := renderEmailTemplate("new_device_login.html", data)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := "New Login to Your Account - TraveGO"
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
+}
+
+// NewSubscriptionNoticeEmail tells an organization its subscription is about
+// to expire, has expired or became read only
+func NewSubscriptionNoticeEmail(to string, data SubscriptionNoticeEmailData) (*Email, error) {
+	data.Year = time.Now().Year()
+	htmlBody, err := renderEmailTemplate("subscription_notice.html", data)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("%s - TraveGO", data.Title)
+	return &Email{To: to, Subject: subject, HTMLBody: htmlBody}, nil
 }
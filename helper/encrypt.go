@@ -400,6 +400,7 @@ type AuthSensitiveData struct {
 	IsAdmin          bool   `json:"is_admin"`
 	UserID           string `json:"user_id"`
 	OrganizationRole int    `json:"organization_role"`
+	SessionID        string `json:"session_id,omitempty"`
 }
 
 // EncryptAuthSensitiveData encrypts sensitive auth data into a token (AES-256-GCM, base64url)
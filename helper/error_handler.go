@@ -2,7 +2,7 @@ package helper
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"runtime/debug"
 	"strings"
@@ -55,11 +55,11 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	txID := GetTransactionID(c)
 	// Use original error for logging
 	errorLogMessage := fmt.Sprintf("TransactionID: %s - %s %s - Status: %d - Error: %v", txID, c.Method(), c.Path(), code, err)
-	log.Printf("[ERROR] %s", errorLogMessage)
+	slog.ErrorContext(c.UserContext(), "request failed", "method", c.Method(), "path", c.Path(), "status", code, "error", err)
 
 	env := os.Getenv("APP_ENV")
 	if env == "" || env == "development" || env == "dev" || env == "local" {
-		log.Printf("[ERROR] TransactionID: %s - Stack trace: %s", txID, string(debug.Stack()))
+		slog.ErrorContext(c.UserContext(), "request failed", "stack", string(debug.Stack()))
 	}
 
 	// Response with user-friendly message
@@ -72,7 +72,7 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 
 	// Log original error detail to file
 	if err := LogErrorToFile(c, code, errorLogMessage, response); err != nil {
-		log.Printf("[ERROR] Failed to write error log to file: %v", err)
+		slog.ErrorContext(c.UserContext(), "failed to write error log to file", "error", err)
 	}
 
 	return c.Status(code).JSON(response)
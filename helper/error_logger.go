@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -12,10 +14,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-const (
-	logFilePath = "assets/log.json"
-	maxLogLines = 1000
-)
+const maxLogLines = 1000
 
 type ErrorLogEntry struct {
 	Timestamp    string      `json:"timestamp"`
@@ -34,17 +33,33 @@ var (
 	logMutex sync.Mutex
 	logFile  *os.File
 	logCount int
+
+	// logFilePath is where LogErrorToFile writes, ERROR_LOG_PATH or
+	// assets/log.json
+	logFilePath = "assets/log.json"
 )
 
 func init() {
-	ensureLogFile()
+	if path := strings.TrimSpace(os.Getenv("ERROR_LOG_PATH")); path != "" {
+		logFilePath = path
+	}
 	countLogLines()
 }
 
-func ensureLogFile() {
-	if _, err := os.Stat("assets"); os.IsNotExist(err) {
-		os.MkdirAll("assets", 0755)
+// SetErrorLogPath makes LogErrorToFile write to path from now on
+func SetErrorLogPath(path string) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
 	}
+	logFilePath = path
+	countLogLines()
+}
+
+func ensureLogFile() error {
+	return os.MkdirAll(filepath.Dir(logFilePath), 0755)
 }
 
 func countLogLines() {
@@ -71,6 +86,9 @@ func resetLogFile() error {
 		logFile = nil
 	}
 
+	if err := ensureLogFile(); err != nil {
+		return err
+	}
 	file, err := os.Create(logFilePath)
 	if err != nil {
 		return err
@@ -154,6 +172,9 @@ func LogErrorToFile(c *fiber.Ctx, errorCode int, errorMessage string, response i
 	}
 
 	if logFile == nil {
+		if err := ensureLogFile(); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
 		var err error
 		logFile, err = os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 		if err != nil {
@@ -223,7 +244,7 @@ func LogErrorToFile(c *fiber.Ctx, errorCode int, errorMessage string, response i
 	// Print to console for non-production environments
 	env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))
 	if env != "production" && env != "prod" {
-		fmt.Println(string(jsonData))
+		slog.InfoContext(c.UserContext(), "error log entry", "entry", json.RawMessage(jsonData))
 	}
 
 	if _, err := logFile.WriteString(string(jsonData) + "\n"); err != nil {
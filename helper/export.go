@@ -0,0 +1,50 @@
+package helper
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"service-travego/internal/export"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SendExport streams a report as a file download in the format of the format
+// query parameter, xlsx (default) or csv. rows runs while the body streams,
+// after the handler has returned: it must not use c, and a report that can
+// grow large should read its data a page at a time there instead of loading
+// it first. Once streaming has started the status can no longer change, so
+// errors from rows are only logged; check the request before calling it.
+func SendExport(c *fiber.Ctx, name, sheet string, columns []export.Column, rows func(export.Writer) error) error {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return SendErrorResponse(c, http.StatusBadRequest, err.Error())
+	}
+
+	filename := fmt.Sprintf("%s_%s%s", name, time.Now().Format("20060102_150405"), format.Extension())
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	// the body is written after the handler returns, when c is reused
+	ctx := c.UserContext()
+
+	c.Status(fiber.StatusOK).Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		w, err := export.NewWriter(bw, format, sheet, columns)
+		if err == nil {
+			err = rows(w)
+			if closeErr := w.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "export failed", "filename", filename, "error", err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,204 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"service-travego/configs"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// --- Account lockout and OTP attempt helpers ---
+//
+//   login_fail:{userID}          failed logins in the current window
+//   login_lock:{userID}          set while the account is locked
+//   otp_fail:token:{token}       wrong OTPs entered for a token
+//   otp_fail:email:{email}       wrong OTPs entered for an email, all tokens
+//   otp_resend:email:{email}     set during the resend cooldown of an email
+//   otp_resend:token:{token}     set during the resend cooldown of a token
+
+const (
+	loginFailPrefix  = "login_fail:"
+	loginLockPrefix  = "login_lock:"
+	otpFailPrefix    = "otp_fail:"
+	otpResendPrefix  = "otp_resend:"
+	otpEmailFailRate = 3 // an email may use up this many tokens' attempts
+)
+
+// ErrTooManyAttempts is returned while an account or OTP is blocked
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+// AttemptLimitError tells how long a blocked account or OTP stays blocked
+type AttemptLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AttemptLimitError) Error() string {
+	return "too many attempts, try again in " + retryAfterText(e.RetryAfter)
+}
+
+func (e *AttemptLimitError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+var (
+	loginMaxFailures  = 5
+	loginLockout      = 15 * time.Minute
+	otpMaxAttempts    = 5
+	otpResendCooldown = time.Minute
+)
+
+// SetSecurityConfig applies the configured rate limits, trusted proxies and
+// lockout settings.
+// Call it before the routes are set up.
+func SetSecurityConfig(cfg *configs.SecurityConfig) {
+	if cfg == nil {
+		return
+	}
+	rateLimitMu.Lock()
+	for group, rule := range cfg.RateLimits {
+		if rule.Max > 0 && rule.WindowSeconds > 0 {
+			rateLimitRules[group] = rule
+		}
+	}
+	if len(cfg.TrustedProxies) > 0 {
+		trustedProxies = parseTrustedProxies(cfg.TrustedProxies)
+	}
+	rateLimitMu.Unlock()
+
+	if cfg.LoginMaxFailures > 0 {
+		loginMaxFailures = cfg.LoginMaxFailures
+	}
+	if cfg.LoginLockoutMinutes > 0 {
+		loginLockout = time.Duration(cfg.LoginLockoutMinutes) * time.Minute
+	}
+	if cfg.OTPMaxAttempts > 0 {
+		otpMaxAttempts = cfg.OTPMaxAttempts
+	}
+	if cfg.OTPResendCooldownSeconds > 0 {
+		otpResendCooldown = time.Duration(cfg.OTPResendCooldownSeconds) * time.Second
+	}
+}
+
+// LoginLockedFor returns how long the account stays locked, or 0 when it is
+// not locked
+func LoginLockedFor(userID string) (time.Duration, error) {
+	if redisClient == nil {
+		return 0, fmt.Errorf("redis client not initialized")
+	}
+	ttl, err := redisClient.PTTL(ctx, loginLockPrefix+userID).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// RecordLoginFailure counts a failed login. Once the account reaches the
+// maximum failures within the lockout window it is locked, and the lockout is
+// returned.
+func RecordLoginFailure(userID string) (time.Duration, error) {
+	count, _, err := hitCounter(loginFailPrefix+userID, loginLockout)
+	if err != nil {
+		return 0, err
+	}
+	if count < int64(loginMaxFailures) {
+		return 0, nil
+	}
+	pipe := redisClient.TxPipeline()
+	pipe.Set(ctx, loginLockPrefix+userID, count, loginLockout)
+	pipe.Del(ctx, loginFailPrefix+userID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return loginLockout, nil
+}
+
+// ClearLoginFailures resets the failed logins of an account after a
+// successful login
+func ClearLoginFailures(userID string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	return redisClient.Del(ctx, loginFailPrefix+userID).Err()
+}
+
+// CheckOTPAttempts returns an *AttemptLimitError when too many wrong OTPs were
+// entered for the token or the email
+func CheckOTPAttempts(email, token string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	keys := []struct {
+		key string
+		max int
+	}{
+		{otpFailPrefix + "token:" + token, otpMaxAttempts},
+		{otpFailPrefix + "email:" + email, otpMaxAttempts * otpEmailFailRate},
+	}
+	for _, k := range keys {
+		n, err := redisClient.Get(ctx, k.key).Int()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n >= k.max {
+			ttl, err := redisClient.PTTL(ctx, k.key).Result()
+			if err != nil {
+				return err
+			}
+			return &AttemptLimitError{RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// RecordOTPFailure counts a wrong OTP for the token and the email
+func RecordOTPFailure(email, token string) error {
+	window := loginLockout
+	if _, _, err := hitCounter(otpFailPrefix+"token:"+token, window); err != nil {
+		return err
+	}
+	_, _, err := hitCounter(otpFailPrefix+"email:"+email, window)
+	return err
+}
+
+// ClearOTPAttempts resets the wrong OTP counters after a successful
+// verification
+func ClearOTPAttempts(email, token string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	return redisClient.Del(ctx, otpFailPrefix+"token:"+token, otpFailPrefix+"email:"+email).Err()
+}
+
+// ClaimOTPResend starts the resend cooldown of the email and the token (token
+// may be empty). It returns an *AttemptLimitError while either is cooling
+// down.
+func ClaimOTPResend(email, token string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	keys := []string{otpResendPrefix + "email:" + email}
+	if token != "" {
+		keys = append(keys, otpResendPrefix+"token:"+token)
+	}
+	for _, key := range keys {
+		ttl, err := redisClient.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl > 0 {
+			return &AttemptLimitError{RetryAfter: ttl}
+		}
+	}
+	pipe := redisClient.TxPipeline()
+	for _, key := range keys {
+		pipe.Set(ctx, key, 1, otpResendCooldown)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
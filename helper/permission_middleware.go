@@ -0,0 +1,93 @@
+package helper
+
+import (
+	"database/sql"
+	"fmt"
+	"service-travego/configs"
+	"service-travego/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MemberPermissions returns the permissions of the authenticated member, set
+// by JWTAuthorizationMiddleware. Travego staff (superadmin) have every
+// permission. The result is kept in locals for the rest of the request.
+func MemberPermissions(c *fiber.Ctx, orgUserRepo *repository.OrganizationUserRepository) ([]string, error) {
+	if perms, ok := c.Locals("permissions").([]string); ok {
+		return perms, nil
+	}
+
+	var perms []string
+	if isSuperAdmin, _ := c.Locals("is_superadmin").(bool); isSuperAdmin {
+		perms = configs.ResolvePermissions(int(configs.OrganizationRoleAdmin), false, nil)
+	} else {
+		userID, _ := c.Locals("user_id").(string)
+		orgID, _ := c.Locals("organization_id").(string)
+		if userID == "" || orgID == "" {
+			return nil, sql.ErrNoRows
+		}
+		role, roleID, granted, err := orgUserRepo.GetMemberPermissions(c.UserContext(), userID)
+		if err != nil {
+			return nil, err
+		}
+		perms = configs.ResolvePermissions(role, roleID != "", granted)
+	}
+
+	c.Locals("permissions", perms)
+	return perms, nil
+}
+
+// HasPermission reports whether permission is in perms
+func HasPermission(perms []string, permission configs.Permission) bool {
+	for _, p := range perms {
+		if p == string(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission only lets members whose role grants permission through.
+// It runs after JWTAuthorizationMiddleware.
+func RequirePermission(orgUserRepo *repository.OrganizationUserRepository, permission configs.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		perms, err := MemberPermissions(c, orgUserRepo)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return SendErrorResponse(c, fiber.StatusForbidden, "You are not an active member of this organization")
+			}
+			return SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check permission")
+		}
+		if !HasPermission(perms, permission) {
+			return SendErrorResponse(c, fiber.StatusForbidden, fmt.Sprintf("Permission %s is required", permission))
+		}
+		return c.Next()
+	}
+}
+
+// OptionalJWTAuthorizationMiddleware authenticates the request like
+// JWTAuthorizationMiddleware when an Authorization header is sent, and lets
+// anonymous requests through.
+func OptionalJWTAuthorizationMiddleware() fiber.Handler {
+	jwtMiddleware := JWTAuthorizationMiddleware()
+	return func(c *fiber.Ctx) error {
+		if c.Get("Authorization") == "" {
+			return c.Next()
+		}
+		return jwtMiddleware(c)
+	}
+}
+
+// LoadPermissions stores the member's permissions in locals ("permissions")
+// without requiring any of them.
+func LoadPermissions(orgUserRepo *repository.OrganizationUserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, err := MemberPermissions(c, orgUserRepo); err != nil {
+			if err == sql.ErrNoRows {
+				return SendErrorResponse(c, fiber.StatusForbidden, "You are not an active member of this organization")
+			}
+			return SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check permission")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,220 @@
+package helper
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service-travego/configs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+// --- Rate limiting ---
+//
+// Limits are counted in Redis so they survive restarts and hold across
+// replicas:
+//   ratelimit:{group}:{ip}      requests in the current window
+//   ratelimit:{group}:org:{id}  outbound messages of an organization
+
+const rateLimitPrefix = "ratelimit:"
+
+var (
+	rateLimitMu sync.RWMutex
+	// rateLimitRules are the limits per route group; SetSecurityConfig
+	// overrides them
+	rateLimitRules = map[string]configs.RateLimitRule{
+		"auth":           {Max: 5, WindowSeconds: 60},
+		"otp":            {Max: 10, WindowSeconds: 60},
+		"password_reset": {Max: 5, WindowSeconds: 900},
+		// every API route except the payment gateway callbacks
+		"api": {Max: 300, WindowSeconds: 60},
+		// WhatsApp messages sent per organization, see AllowOrganizationSend
+		"whatsapp": {Max: 20, WindowSeconds: 60},
+	}
+	defaultRateLimitRule = configs.RateLimitRule{Max: 60, WindowSeconds: 60}
+
+	// trustedProxies are the reverse proxies whose forwarding headers are
+	// believed; SetSecurityConfig overrides them
+	trustedProxies = parseTrustedProxies([]string{"127.0.0.0/8", "::1"})
+)
+
+// incrWindowScript counts a hit and starts the window on the first one. It
+// returns the count and the milliseconds left in the window.
+var incrWindowScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {n, redis.call('PTTL', KEYS[1])}
+`)
+
+// RateLimitRuleFor returns the limit of a route group
+func RateLimitRuleFor(group string) configs.RateLimitRule {
+	rateLimitMu.RLock()
+	defer rateLimitMu.RUnlock()
+	if rule, ok := rateLimitRules[group]; ok {
+		return rule
+	}
+	return defaultRateLimitRule
+}
+
+// parseTrustedProxies turns IPs and CIDRs into networks, skipping invalid
+// entries
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			slog.Warn("rate limit: ignoring trusted proxy", "proxy", proxy, "error", err)
+			continue
+		}
+		nets = append(nets, network)
+	}
+	return nets
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	rateLimitMu.RLock()
+	defer rateLimitMu.RUnlock()
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the client IP. The forwarding headers are only believed
+// when the connection comes from a trusted proxy, and X-Forwarded-For is read
+// from the right: each proxy appends the address it received the request
+// from, so the first hop that is not a trusted proxy is the client. Anything
+// left of it was written by the client and may be forged.
+func ClientIP(c *fiber.Ctx) string {
+	client := c.Context().RemoteIP()
+	if !isTrustedProxy(client) {
+		return client.String()
+	}
+	forwarded := false
+	if header := c.Get(fiber.HeaderXForwardedFor); header != "" {
+		hops := strings.Split(header, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			client, forwarded = ip, true
+			if !isTrustedProxy(ip) {
+				break
+			}
+		}
+	}
+	if !forwarded {
+		if ip := net.ParseIP(strings.TrimSpace(c.Get("X-Real-IP"))); ip != nil {
+			client = ip
+		}
+	}
+	return client.String()
+}
+
+// hitCounter counts a hit on key in a fixed window and returns the count and
+// the time left in the window
+func hitCounter(key string, window time.Duration) (int64, time.Duration, error) {
+	if redisClient == nil {
+		return 0, 0, fmt.Errorf("redis client not initialized")
+	}
+	res, err := incrWindowScript.Run(ctx, redisClient, []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(res) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rate limit reply %v", res)
+	}
+	ttl := time.Duration(res[1]) * time.Millisecond
+	if ttl < 0 {
+		ttl = window
+	}
+	return res[0], ttl, nil
+}
+
+// retryAfterText formats a wait for error messages, e.g. "45 seconds" or
+// "15 minutes"
+func retryAfterText(d time.Duration) string {
+	if d < time.Minute {
+		secs := int((d + time.Second - 1) / time.Second)
+		if secs < 1 {
+			secs = 1
+		}
+		return fmt.Sprintf("%d seconds", secs)
+	}
+	mins := int((d + time.Minute - 1) / time.Minute)
+	if mins == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", mins)
+}
+
+// AllowOrganizationSend counts one outbound message of an organization against
+// the limit of group (e.g. "whatsapp"). Over the limit it returns false and
+// the time until the window resets. Without Redis nothing is limited and the
+// error is returned for logging.
+func AllowOrganizationSend(group, organizationID string) (bool, time.Duration, error) {
+	rule := RateLimitRuleFor(group)
+	count, ttl, err := hitCounter(rateLimitPrefix+group+":org:"+organizationID, time.Duration(rule.WindowSeconds)*time.Second)
+	if err != nil {
+		return true, 0, err
+	}
+	return count <= int64(rule.Max), ttl, nil
+}
+
+// RateLimiter limits requests per client IP using the limit configured for
+// group. When Redis is unavailable it falls back to an in-memory limiter.
+func RateLimiter(group string) fiber.Handler {
+	rule := RateLimitRuleFor(group)
+	window := time.Duration(rule.WindowSeconds) * time.Second
+	limitReached := func(c *fiber.Ctx, retryAfter time.Duration) error {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+		return SendErrorResponse(c, fiber.StatusTooManyRequests, "Too many requests. Please try again after "+retryAfterText(retryAfter)+".")
+	}
+	fallback := limiter.New(limiter.Config{
+		Max:          rule.Max,
+		Expiration:   window,
+		KeyGenerator: ClientIP,
+		LimitReached: func(c *fiber.Ctx) error {
+			return limitReached(c, window)
+		},
+	})
+
+	return func(c *fiber.Ctx) error {
+		count, ttl, err := hitCounter(rateLimitPrefix+group+":"+ClientIP(c), window)
+		if err != nil {
+			if redisClient != nil {
+				slog.ErrorContext(c.UserContext(), "rate limit: counter failed", "group", group, "error", err)
+			}
+			return fallback(c)
+		}
+		remaining := int64(rule.Max) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Set("X-RateLimit-Limit", strconv.Itoa(rule.Max))
+		c.Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		if count > int64(rule.Max) {
+			return limitReached(c, ttl)
+		}
+		return c.Next()
+	}
+}
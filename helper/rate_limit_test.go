@@ -0,0 +1,345 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"service-travego/configs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis answers the commands of the limiter and the login guard from
+// memory, with a clock the tests move forward
+type fakeRedis struct {
+	now     time.Time
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+// useFakeRedis points the helpers at a fresh fakeRedis for the test
+func useFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	f := &fakeRedis{
+		now:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		values:  map[string]string{},
+		expires: map[string]time.Time{},
+	}
+	client := redis.NewClient(&redis.Options{Addr: "fake:6379"})
+	client.AddHook(f)
+	previous := redisClient
+	redisClient = client
+	t.Cleanup(func() {
+		redisClient = previous
+		client.Close()
+	})
+	return f
+}
+
+func (f *fakeRedis) advance(d time.Duration) { f.now = f.now.Add(d) }
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (f *fakeRedis) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		f.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			f.process(cmd)
+		}
+		return nil
+	}
+}
+
+// live drops key when it expired and reports whether it is still there
+func (f *fakeRedis) live(key string) bool {
+	if at, ok := f.expires[key]; ok && !f.now.Before(at) {
+		delete(f.values, key)
+		delete(f.expires, key)
+	}
+	_, ok := f.values[key]
+	return ok
+}
+
+func (f *fakeRedis) pttl(key string) time.Duration {
+	if !f.live(key) {
+		return -2
+	}
+	at, ok := f.expires[key]
+	if !ok {
+		return -1
+	}
+	return at.Sub(f.now)
+}
+
+func (f *fakeRedis) process(cmd redis.Cmder) {
+	args := cmd.Args()
+	arg := func(i int) string { return fmt.Sprint(args[i]) }
+	switch cmd.Name() {
+	case "evalsha", "eval":
+		// incrWindowScript, the only script in the package
+		key := arg(3)
+		window, _ := strconv.ParseInt(arg(4), 10, 64)
+		n := int64(1)
+		if f.live(key) {
+			n, _ = strconv.ParseInt(f.values[key], 10, 64)
+			n++
+		}
+		f.values[key] = strconv.FormatInt(n, 10)
+		if n == 1 {
+			f.expires[key] = f.now.Add(time.Duration(window) * time.Millisecond)
+		}
+		cmd.(*redis.Cmd).SetVal([]interface{}{n, f.pttl(key).Milliseconds()})
+	case "get":
+		if !f.live(arg(1)) {
+			cmd.SetErr(redis.Nil)
+			return
+		}
+		cmd.(*redis.StringCmd).SetVal(f.values[arg(1)])
+	case "set":
+		key := arg(1)
+		f.values[key] = arg(2)
+		delete(f.expires, key)
+		if len(args) == 5 {
+			n, _ := strconv.ParseInt(arg(4), 10, 64)
+			unit := time.Millisecond
+			if strings.EqualFold(arg(3), "ex") {
+				unit = time.Second
+			}
+			f.expires[key] = f.now.Add(time.Duration(n) * unit)
+		}
+		cmd.(*redis.StatusCmd).SetVal("OK")
+	case "del":
+		var n int64
+		for i := 1; i < len(args); i++ {
+			if f.live(arg(i)) {
+				delete(f.values, arg(i))
+				delete(f.expires, arg(i))
+				n++
+			}
+		}
+		cmd.(*redis.IntCmd).SetVal(n)
+	case "pttl":
+		cmd.(*redis.DurationCmd).SetVal(f.pttl(arg(1)))
+	case "multi", "exec":
+	default:
+		cmd.SetErr(fmt.Errorf("fakeRedis: unsupported command %s", cmd.Name()))
+	}
+}
+
+// withLoginLimits sets the lockout settings for the test
+func withLoginLimits(t *testing.T, maxFailures int, lockout time.Duration) {
+	t.Helper()
+	prevMax, prevLockout := loginMaxFailures, loginLockout
+	loginMaxFailures, loginLockout = maxFailures, lockout
+	t.Cleanup(func() { loginMaxFailures, loginLockout = prevMax, prevLockout })
+}
+
+func TestLoginLockoutThreshold(t *testing.T) {
+	useFakeRedis(t)
+	withLoginLimits(t, 3, 15*time.Minute)
+
+	for i := 1; i < 3; i++ {
+		locked, err := RecordLoginFailure("user-1")
+		if err != nil || locked != 0 {
+			t.Fatalf("failure %d: expected no lockout, got %v, %v", i, locked, err)
+		}
+	}
+	if locked, _ := LoginLockedFor("user-1"); locked != 0 {
+		t.Fatalf("locked before the threshold for %v", locked)
+	}
+
+	locked, err := RecordLoginFailure("user-1")
+	if err != nil || locked != 15*time.Minute {
+		t.Fatalf("expected a 15 minute lockout at the threshold, got %v, %v", locked, err)
+	}
+	if locked, _ := LoginLockedFor("user-1"); locked != 15*time.Minute {
+		t.Fatalf("expected the account to be locked, got %v", locked)
+	}
+	if locked, _ := LoginLockedFor("user-2"); locked != 0 {
+		t.Fatalf("another account is locked for %v", locked)
+	}
+}
+
+func TestLoginLockoutExpires(t *testing.T) {
+	f := useFakeRedis(t)
+	withLoginLimits(t, 2, 15*time.Minute)
+
+	RecordLoginFailure("user-1")
+	RecordLoginFailure("user-1")
+	f.advance(10 * time.Minute)
+	if locked, _ := LoginLockedFor("user-1"); locked != 5*time.Minute {
+		t.Fatalf("expected 5 minutes of lockout left, got %v", locked)
+	}
+	f.advance(5 * time.Minute)
+	if locked, _ := LoginLockedFor("user-1"); locked != 0 {
+		t.Fatalf("lockout did not expire, %v left", locked)
+	}
+
+	// the failures that led to the lockout are not counted again
+	if locked, _ := RecordLoginFailure("user-1"); locked != 0 {
+		t.Fatalf("locked again after a single failure for %v", locked)
+	}
+}
+
+func TestLoginFailuresOutsideTheWindowAreForgotten(t *testing.T) {
+	f := useFakeRedis(t)
+	withLoginLimits(t, 2, 15*time.Minute)
+
+	RecordLoginFailure("user-1")
+	f.advance(16 * time.Minute)
+	if locked, _ := RecordLoginFailure("user-1"); locked != 0 {
+		t.Fatalf("an expired failure was counted, locked for %v", locked)
+	}
+
+	if err := ClearLoginFailures("user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _ := RecordLoginFailure("user-1"); locked != 0 {
+		t.Fatalf("a cleared failure was counted, locked for %v", locked)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	f := useFakeRedis(t)
+	// the 429 responses are written to the error log
+	previous := logFilePath
+	SetErrorLogPath(filepath.Join(t.TempDir(), "log.json"))
+	t.Cleanup(func() { SetErrorLogPath(previous) })
+	rateLimitMu.Lock()
+	rateLimitRules["test"] = configs.RateLimitRule{Max: 2, WindowSeconds: 60}
+	rateLimitMu.Unlock()
+	t.Cleanup(func() {
+		rateLimitMu.Lock()
+		delete(rateLimitRules, "test")
+		rateLimitMu.Unlock()
+	})
+
+	app := fiber.New()
+	app.Get("/", RateLimiter("test"), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	get := func() (int, string) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if status, _ := get(); status != fiber.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, status)
+		}
+	}
+	f.advance(15 * time.Second)
+	status, retryAfter := get()
+	if status != fiber.StatusTooManyRequests || retryAfter != "45" {
+		t.Fatalf("expected 429 with Retry-After 45, got %d with %q", status, retryAfter)
+	}
+
+	f.advance(45 * time.Second)
+	if status, _ := get(); status != fiber.StatusOK {
+		t.Fatalf("expected the limit to reset with the window, got %d", status)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	// the test requests of fiber come from 0.0.0.0
+	rateLimitMu.Lock()
+	previous := trustedProxies
+	rateLimitMu.Unlock()
+	t.Cleanup(func() {
+		rateLimitMu.Lock()
+		trustedProxies = previous
+		rateLimitMu.Unlock()
+	})
+	trust := func(proxies ...string) {
+		rateLimitMu.Lock()
+		trustedProxies = parseTrustedProxies(proxies)
+		rateLimitMu.Unlock()
+	}
+
+	tests := []struct {
+		name    string
+		trusted []string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "headers of an untrusted peer are ignored",
+			trusted: []string{"127.0.0.1"},
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.9", "X-Real-IP": "203.0.113.9"},
+			want:    "0.0.0.0",
+		},
+		{
+			name:    "the hop added by the trusted proxy is the client",
+			trusted: []string{"0.0.0.0"},
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.9"},
+			want:    "203.0.113.9",
+		},
+		{
+			name:    "hops forged by the client are skipped",
+			trusted: []string{"0.0.0.0"},
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1, 198.51.100.2, 203.0.113.9"},
+			want:    "203.0.113.9",
+		},
+		{
+			name:    "trusted proxies in the chain are skipped",
+			trusted: []string{"0.0.0.0", "10.0.0.0/8"},
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.9, 10.1.2.3"},
+			want:    "203.0.113.9",
+		},
+		{
+			name:    "a malformed hop stops the walk",
+			trusted: []string{"0.0.0.0", "10.0.0.0/8"},
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1, junk, 10.1.2.3"},
+			want:    "10.1.2.3",
+		},
+		{
+			name:    "X-Real-IP of a trusted proxy without X-Forwarded-For",
+			trusted: []string{"0.0.0.0"},
+			headers: map[string]string{"X-Real-IP": "203.0.113.9"},
+			want:    "203.0.113.9",
+		},
+		{
+			name:    "a trusted proxy without headers",
+			trusted: []string{"0.0.0.0"},
+			want:    "0.0.0.0",
+		},
+	}
+	for _, tt := range tests {
+		trust(tt.trusted...)
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error { return c.SendString(ClientIP(c)) })
+		req := httptest.NewRequest("GET", "/", nil)
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if got := string(body); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if got := parseTrustedProxies([]string{"::1", "192.0.2.1", "not-an-ip"}); len(got) != 2 || !got[0].Contains(net.IPv6loopback) {
+		t.Errorf("unexpected trusted proxies %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"fmt"
+	"log/slog"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -53,7 +54,7 @@ func SendErrorResponse(c *fiber.Ctx, statusCode int, message string) error {
 
 	if err := LogErrorToFile(c, statusCode, errorLogMessage, response); err != nil {
 		// Log to console if file logging fails, but don't fail the request
-		fmt.Printf("[WARNING] Failed to write error log to file: %v\n", err)
+		slog.WarnContext(c.UserContext(), "failed to write error log to file", "error", err)
 	}
 
 	return c.Status(statusCode).JSON(response)
@@ -80,7 +81,7 @@ func SendValidationErrorResponse(c *fiber.Ctx, validationErrors []ValidationErro
 	}
 
 	if err := LogErrorToFile(c, fiber.StatusBadRequest, errorLogMessage, response); err != nil {
-		fmt.Printf("[WARNING] Failed to write error log to file: %v\n", err)
+		slog.WarnContext(c.UserContext(), "failed to write error log to file", "error", err)
 	}
 
 	return c.Status(fiber.StatusBadRequest).JSON(response)
@@ -0,0 +1,275 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// --- Login session helpers ---
+//
+// Every login creates a session per device, so logging in on a second device
+// no longer replaces the first one's refresh token:
+//   session:{userID}:{sessionID}  JSON AuthSession, expires with its refresh token
+//   sessions:{userID}             set of the user's session IDs
+//   refresh_session:{token}       "{userID}:{sessionID}" for a refresh token
+
+const (
+	sessionPrefix        = "session:"
+	userSessionsPrefix   = "sessions:"
+	refreshSessionPrefix = "refresh_session:"
+	knownDevicesPrefix   = "known_devices:"
+	loginChallengePrefix = "login_2fa:"
+	usedTOTPPrefix       = "totp_used:"
+
+	userSessionsTTL = 30 * 24 * time.Hour
+	knownDevicesTTL = 180 * 24 * time.Hour
+)
+
+// ErrSessionNotFound is returned for a session that expired or was revoked
+var ErrSessionNotFound = errors.New("session not found")
+
+// AuthSession is a login on one device
+type AuthSession struct {
+	SessionID    string    `json:"session_id"`
+	UserID       string    `json:"user_id"`
+	DeviceID     string    `json:"device_id"`
+	DeviceName   string    `json:"device_name"`
+	IPAddress    string    `json:"ip_address"`
+	RefreshToken string    `json:"refresh_token"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+}
+
+func sessionKey(userID, sessionID string) string {
+	return sessionPrefix + userID + ":" + sessionID
+}
+
+// SaveAuthSession stores a session and maps its refresh token to it. The
+// session expires after ttl unless saved again (sliding expiration).
+func SaveAuthSession(s *AuthSession, ttl time.Duration) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	pipe := redisClient.TxPipeline()
+	pipe.Set(ctx, sessionKey(s.UserID, s.SessionID), data, ttl)
+	pipe.SAdd(ctx, userSessionsPrefix+s.UserID, s.SessionID)
+	pipe.Expire(ctx, userSessionsPrefix+s.UserID, userSessionsTTL)
+	pipe.Set(ctx, refreshSessionPrefix+s.RefreshToken, s.UserID+":"+s.SessionID, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetAuthSession returns a session, or ErrSessionNotFound
+func GetAuthSession(userID, sessionID string) (*AuthSession, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	data, err := redisClient.Get(ctx, sessionKey(userID, sessionID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	var s AuthSession
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// AuthSessionExists reports whether a session is still active
+func AuthSessionExists(userID, sessionID string) (bool, error) {
+	if redisClient == nil {
+		return false, fmt.Errorf("redis client not initialized")
+	}
+	n, err := redisClient.Exists(ctx, sessionKey(userID, sessionID)).Result()
+	return n > 0, err
+}
+
+// GetAuthSessionByRefreshToken returns the session a refresh token belongs to,
+// or ErrSessionNotFound
+func GetAuthSessionByRefreshToken(token string) (*AuthSession, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	ref, err := redisClient.Get(ctx, refreshSessionPrefix+token).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	userID, sessionID, ok := strings.Cut(ref, ":")
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	s, err := GetAuthSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.RefreshToken != token {
+		return nil, ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// DeleteRefreshTokenSession removes the mapping of a rotated refresh token
+func DeleteRefreshTokenSession(token string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	return redisClient.Del(ctx, refreshSessionPrefix+token).Err()
+}
+
+// ListAuthSessions returns the user's active sessions, the most recently used
+// first. Expired sessions are dropped from the user's set.
+func ListAuthSessions(userID string) ([]AuthSession, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	ids, err := redisClient.SMembers(ctx, userSessionsPrefix+userID).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]AuthSession, 0, len(ids))
+	for _, id := range ids {
+		s, err := GetAuthSession(userID, id)
+		if err == ErrSessionNotFound {
+			redisClient.SRem(ctx, userSessionsPrefix+userID, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// DeleteAuthSession revokes a session and its refresh token. It returns
+// ErrSessionNotFound when the session is not active.
+func DeleteAuthSession(userID, sessionID string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	s, err := GetAuthSession(userID, sessionID)
+	if err != nil {
+		redisClient.SRem(ctx, userSessionsPrefix+userID, sessionID)
+		return err
+	}
+	pipe := redisClient.TxPipeline()
+	pipe.Del(ctx, sessionKey(userID, sessionID))
+	pipe.Del(ctx, refreshSessionPrefix+s.RefreshToken)
+	pipe.SRem(ctx, userSessionsPrefix+userID, sessionID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// RememberDevice records a device the user logged in on. isNew is true the
+// first time the device is seen; firstDevice is true when the user had no
+// known device yet.
+func RememberDevice(userID, deviceID string) (isNew bool, firstDevice bool, err error) {
+	if redisClient == nil {
+		return false, false, fmt.Errorf("redis client not initialized")
+	}
+	key := knownDevicesPrefix + userID
+	count, err := redisClient.SCard(ctx, key).Result()
+	if err != nil {
+		return false, false, err
+	}
+	added, err := redisClient.SAdd(ctx, key, deviceID).Result()
+	if err != nil {
+		return false, false, err
+	}
+	redisClient.Expire(ctx, key, knownDevicesTTL)
+	return added > 0, count == 0, nil
+}
+
+// LoginChallenge is a password login waiting for its second factor
+type LoginChallenge struct {
+	UserID     string `json:"user_id"`
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name"`
+	IPAddress  string `json:"ip_address"`
+	Attempts   int    `json:"attempts"`
+}
+
+// SetLoginChallenge stores a login challenge under token
+func SetLoginChallenge(token string, c *LoginChallenge, ttl time.Duration) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return redisClient.Set(ctx, loginChallengePrefix+token, data, ttl).Err()
+}
+
+// GetLoginChallenge returns a login challenge, or ErrSessionNotFound when it
+// expired
+func GetLoginChallenge(token string) (*LoginChallenge, error) {
+	if redisClient == nil {
+		return nil, fmt.Errorf("redis client not initialized")
+	}
+	data, err := redisClient.Get(ctx, loginChallengePrefix+token).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	var c LoginChallenge
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateLoginChallenge saves a challenge again without extending its expiry
+func UpdateLoginChallenge(token string, c *LoginChallenge) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return redisClient.Set(ctx, loginChallengePrefix+token, data, redis.KeepTTL).Err()
+}
+
+// DeleteLoginChallenge removes a login challenge
+func DeleteLoginChallenge(token string) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	return redisClient.Del(ctx, loginChallengePrefix+token).Err()
+}
+
+// ClaimTOTPStep records that the user used the TOTP code of step. It returns
+// false when that code was already used, so a code can't be replayed.
+func ClaimTOTPStep(userID string, step int64, ttl time.Duration) (bool, error) {
+	if redisClient == nil {
+		return false, fmt.Errorf("redis client not initialized")
+	}
+	return redisClient.SetNX(ctx, fmt.Sprintf("%s%s:%d", usedTOTPPrefix, userID, step), 1, ttl).Result()
+}
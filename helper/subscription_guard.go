@@ -0,0 +1,137 @@
+package helper
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"service-travego/database"
+	"service-travego/model"
+	"service-travego/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// subscriptionStateTTL is how long the lifecycle state of an organization is
+// cached before it is read again
+const subscriptionStateTTL = time.Minute
+
+var (
+	subscriptionRepo    *repository.SubscriptionRepository
+	subscriptionStateMu sync.Mutex
+	subscriptionStates  = map[string]cachedSubscriptionState{}
+)
+
+type cachedSubscriptionState struct {
+	status  int
+	expires time.Time
+}
+
+// readOnlyWritablePrefixes stay writable in read-only mode so the organization
+// can still sign in and renew its subscription
+var readOnlyWritablePrefixes = []string{
+	"/api/auth/",
+	"/api/account/",
+	"/api/subscription/",
+	"/api/profile/",
+	"/api/organization/create",
+	"/api/organization/join",
+}
+
+// readOnlyQueryActions are the last path segments of POST endpoints that only
+// read data
+var readOnlyQueryActions = map[string]bool{
+	"detail": true, "summary": true, "revenue": true, "history": true, "availibility": true,
+	"payment-history": true, "order-history": true, "cancelation-detail": true, "quote": true,
+	"check-password": true,
+}
+
+// SetSubscriptionRepository enables read-only mode for organizations whose
+// subscription lapsed. Call it before the routes are set up.
+func SetSubscriptionRepository(repo *repository.SubscriptionRepository) {
+	subscriptionRepo = repo
+}
+
+// ForgetSubscriptionState drops the cached lifecycle state of an organization,
+// e.g. once it paid for a subscription
+func ForgetSubscriptionState(organizationID string) {
+	subscriptionStateMu.Lock()
+	delete(subscriptionStates, organizationID)
+	subscriptionStateMu.Unlock()
+}
+
+func subscriptionStatus(ctx context.Context, organizationID string) (int, error) {
+	now := time.Now()
+	subscriptionStateMu.Lock()
+	cached, ok := subscriptionStates[organizationID]
+	subscriptionStateMu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.status, nil
+	}
+
+	status := model.SubscriptionStatusActive
+	sub, err := subscriptionRepo.GetLifecycle(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if sub != nil {
+		status = sub.Status
+	}
+
+	subscriptionStateMu.Lock()
+	subscriptionStates[organizationID] = cachedSubscriptionState{status: status, expires: now.Add(subscriptionStateTTL)}
+	subscriptionStateMu.Unlock()
+	return status, nil
+}
+
+func isReadOnlyRequest(method, path string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	for _, prefix := range readOnlyWritablePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	if method == fiber.MethodPost {
+		return readOnlyQueryActions[path[strings.LastIndex(path, "/")+1:]]
+	}
+	return false
+}
+
+// SubscriptionReadOnlyMiddleware refuses changes from members of an
+// organization whose subscription is read only. It runs after the
+// organization of the request is known; JWTAuthorizationMiddleware and the
+// API key paths of DualAuthMiddleware apply it.
+func SubscriptionReadOnlyMiddleware() fiber.Handler {
+	return enforceSubscriptionReadOnly
+}
+
+func enforceSubscriptionReadOnly(c *fiber.Ctx) error {
+	if subscriptionRepo == nil || isReadOnlyRequest(c.Method(), c.Path()) {
+		return c.Next()
+	}
+	if isSuperAdmin, _ := c.Locals("is_superadmin").(bool); isSuperAdmin {
+		return c.Next()
+	}
+	ctx := c.UserContext()
+	orgID, ok := database.OrganizationFromContext(ctx)
+	if !ok {
+		return c.Next()
+	}
+
+	status, err := subscriptionStatus(ctx, orgID)
+	if err != nil {
+		// an unreadable subscription must not take the dashboard down
+		slog.WarnContext(ctx, "failed to read subscription", "organization_id", orgID, "error", err)
+		return c.Next()
+	}
+	if status == model.SubscriptionStatusReadOnly {
+		return SendErrorResponse(c, http.StatusPaymentRequired, "Your subscription has expired and the account is read only. Renew the subscription to make changes")
+	}
+	return c.Next()
+}
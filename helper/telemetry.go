@@ -0,0 +1,107 @@
+package helper
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"os"
+	"service-travego/database"
+	"service-travego/internal/telemetry"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestTelemetryMiddleware traces each request, continuing an incoming W3C
+// traceparent, records its latency by route and writes one JSON log line per
+// request. Errors returned by handlers are rendered here with the app's
+// ErrorHandler so the logged status is the one the client gets.
+func RequestTelemetryMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		ctx := telemetry.ContextWithTraceparent(c.UserContext(), c.Get("traceparent"))
+		ctx, span := telemetry.StartSpan(ctx, c.Method()+" "+c.Path(), telemetry.SpanKindServer)
+		c.SetUserContext(ctx)
+
+		chainErr := c.Next()
+		if chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		elapsed := time.Since(start)
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		telemetry.HTTPRequestDuration.Observe(elapsed.Seconds(), c.Method(), route, strconv.Itoa(status))
+
+		span.SetName(c.Method() + " " + route)
+		span.SetAttribute("http.method", c.Method())
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.target", c.Path())
+		span.SetAttribute("http.status_code", status)
+		if status >= fiber.StatusInternalServerError {
+			if chainErr != nil {
+				span.RecordError(chainErr)
+			} else {
+				span.RecordError(fmt.Errorf("status %d", status))
+			}
+		}
+		span.End()
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= fiber.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+		attrs := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"route", route,
+			"status", status,
+			"latency_ms", float64(elapsed.Microseconds()) / 1000,
+			"ip", c.IP(),
+		}
+		if orgID, ok := database.OrganizationFromContext(c.UserContext()); ok {
+			attrs = append(attrs, "organization_id", orgID)
+		}
+		if chainErr != nil {
+			attrs = append(attrs, "error", chainErr.Error())
+		}
+		slog.Log(ctx, level, "request", attrs...)
+		return nil
+	}
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format. When
+// METRICS_TOKEN is set the scraper has to send it as a bearer token; without
+// it only a scraper on the same host, not going through a proxy, is served.
+func MetricsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token := os.Getenv("METRICS_TOKEN"); token != "" {
+			got := c.Get(fiber.HeaderAuthorization)
+			if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+token)) != 1 {
+				return c.SendStatus(fiber.StatusUnauthorized)
+			}
+		} else if !localRequest(c) {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		var buf bytes.Buffer
+		telemetry.WritePrometheus(&buf)
+		c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return c.Send(buf.Bytes())
+	}
+}
+
+// localRequest reports whether the request comes straight from the loopback
+// interface. A request relayed by a proxy on the same host carries forwarding
+// headers and is not local: it may have come from anywhere.
+func localRequest(c *fiber.Ctx) bool {
+	if c.Get(fiber.HeaderXForwardedFor) != "" || c.Get("X-Real-IP") != "" || c.Get("Forwarded") != "" {
+		return false
+	}
+	return c.Context().RemoteIP().IsLoopback()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"service-travego/internal/telemetry"
 	"strings"
 	"time"
 
@@ -45,6 +46,7 @@ func TransactionIDMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		txID := GenerateTransactionID()
 		c.Locals(TransactionIDKey, txID)
+		c.SetUserContext(telemetry.WithTransactionID(c.UserContext(), txID))
 		return c.Next()
 	}
 }
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvWriter writes semicolon separated values with a UTF-8 byte order mark,
+// which is what Excel with Indonesian regional settings opens without an
+// import dialog (the comma is the decimal separator there)
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+	cw.w.Comma = ';'
+	for i, col := range columns {
+		cw.record[i] = col.Header
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(values ...interface{}) error {
+	for i, col := range cw.columns {
+		cw.record[i] = ""
+		if i < len(values) {
+			cw.record[i] = csvValue(col.Kind, values[i])
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func csvValue(kind Kind, v interface{}) string {
+	switch kind {
+	case Integer, Currency:
+		if n, ok := toNumber(v); ok {
+			return formatNumber(n, 0)
+		}
+	case Decimal:
+		if n, ok := toNumber(v); ok {
+			return formatNumber(n, 2)
+		}
+	case Date:
+		if t, ok := toTime(v); ok {
+			return t.Format("02/01/2006")
+		}
+	case DateTime:
+		if t, ok := toTime(v); ok {
+			return t.Format("02/01/2006 15:04")
+		}
+	}
+	return EscapeFormula(toText(v))
+}
+
+// EscapeFormula keeps text that a spreadsheet would run as a formula from
+// being one. Phone numbers like +62 812-3456 are left alone.
+func EscapeFormula(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + s
+	case '+', '-':
+		if strings.Trim(s[1:], "0123456789 -()") != "" {
+			return "'" + s
+		}
+	}
+	return s
+}
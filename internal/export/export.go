@@ -0,0 +1,177 @@
+// Package export writes report rows as CSV or XLSX files for download. Rows
+// are written as they come so a large report never has to be held as a file in
+// memory. Headers are given by the caller; numbers and dates are formatted the
+// Indonesian way (1.250.000,50 and 31/12/2026).
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat reads the format asked for by a client; empty means XLSX
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", XLSX, "excel":
+		return XLSX, nil
+	case CSV:
+		return CSV, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q, use xlsx or csv", s)
+}
+
+// ContentType is the MIME type of files in the format
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// Extension is the file extension of the format, with the dot
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Kind is how the values of a column are written
+type Kind int
+
+const (
+	Text Kind = iota
+	// Integer is a whole number with thousand separators
+	Integer
+	// Decimal is a number with two decimals
+	Decimal
+	// Currency is an amount of Rupiah
+	Currency
+	// Date is a calendar date
+	Date
+	// DateTime is a date with the time of day
+	DateTime
+)
+
+// Column is a column of an export
+type Column struct {
+	Header string
+	Kind   Kind
+}
+
+// Writer writes the rows of an export. Values are given in column order and
+// may be strings, numbers, time.Time or *time.Time; nil is an empty cell.
+type Writer interface {
+	WriteRow(values ...interface{}) error
+	// Close finishes the file; it does not close the underlying writer
+	Close() error
+}
+
+// NewWriter starts an export in format f on w and writes the header row
+func NewWriter(w io.Writer, f Format, sheet string, columns []Column) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case XLSX:
+		return newXLSXWriter(w, sheet, columns)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", f)
+}
+
+// dateLayouts are the layouts dates arrive in from the services
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// toTime reads v as a time; ok is false for an empty or unreadable value
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, !t.IsZero()
+	case string:
+		s := strings.TrimSpace(t)
+		for _, layout := range dateLayouts {
+			if parsed, err := time.Parse(layout, s); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// toNumber reads v as a number; ok is false for an empty or unreadable value
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, !math.IsNaN(n) && !math.IsInf(n, 0)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toText writes any value as text
+func toText(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case fmt.Stringer:
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// formatNumber writes n with Indonesian separators and the given decimals,
+// e.g. 1250000.5 with 2 decimals is 1.250.000,50
+func formatNumber(n float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(n), 'f', decimals, 64)
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteByte(',')
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testColumns = []Column{
+	{Header: "No. Invoice", Kind: Text},
+	{Header: "Tanggal", Kind: Date},
+	{Header: "Jumlah Unit", Kind: Integer},
+	{Header: "Total (Rp)", Kind: Currency},
+}
+
+func TestFormatNumber(t *testing.T) {
+	cases := []struct {
+		n        float64
+		decimals int
+		want     string
+	}{
+		{0, 0, "0"},
+		{999, 0, "999"},
+		{1250000, 0, "1.250.000"},
+		{-1250000.5, 2, "-1.250.000,50"},
+		{1234.567, 2, "1.234,57"},
+		{-0.001, 0, "0"},
+	}
+	for _, c := range cases {
+		if got := formatNumber(c.n, c.decimals); got != c.want {
+			t.Errorf("formatNumber(%v, %d) = %q, want %q", c.n, c.decimals, got, c.want)
+		}
+	}
+}
+
+func TestCSVUsesIndonesianFormats(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, CSV, "Pesanan", testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteRow("INV-001", "2026-03-10", 2, 1250000.0)
+	w.WriteRow("=HYPERLINK(\"x\")", time.Date(2026, 12, 31, 8, 0, 0, 0, time.UTC), nil, "")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\ufeffNo. Invoice;Tanggal;Jumlah Unit;Total (Rp)\n" +
+		"INV-001;10/03/2026;2;1.250.000\n" +
+		"\"'=HYPERLINK(\"\"x\"\")\";31/12/2026;;\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("csv =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestEscapeFormulaKeepsPhoneNumbers(t *testing.T) {
+	for in, want := range map[string]string{
+		"+62 812-3456-7890": "+62 812-3456-7890",
+		"-5":                "-5",
+		"+cmd|' /C calc'":   "'+cmd|' /C calc'",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"Budi":              "Budi",
+	} {
+		if got := EscapeFormula(in); got != want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestXLSXWritesTypedCells(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, XLSX, "Pesanan: Maret", testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteRow("PT A & B <Tour>", "2026-03-10", 2, 1250000.0)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Pesanan- Maret"`) {
+		t.Errorf("sheet name not sanitized: %s", parts["xl/workbook.xml"])
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">No. Invoice</t></is></c>`,
+		`<t xml:space="preserve">PT A &amp; B &lt;Tour&gt;</t>`,
+		`<c r="B2" s="5"><v>46091</v></c>`,
+		`<c r="C2" s="2"><v>2</v></c>`,
+		`<c r="D2" s="4"><v>1250000</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet is missing %s:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": XLSX, "XLSX": XLSX, "excel": XLSX, "csv": CSV} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("ParseFormat(pdf) should fail")
+	}
+}
@@ -0,0 +1,238 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cell styles, the index into cellXfs of xlsxStyles
+const (
+	styleDefault = iota
+	styleHeader
+	styleInteger
+	styleDecimal
+	styleCurrency
+	styleDate
+	styleDateTime
+)
+
+// xlsxStyles declares the number formats of the column kinds. Excel shows the
+// separators of the reader's locale, so #,##0 reads 1.250.000 in Indonesia.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="3"><numFmt numFmtId="164" formatCode="&quot;Rp&quot;\ #,##0"/><numFmt numFmtId="165" formatCode="dd/mm/yyyy"/><numFmt numFmtId="166" formatCode="dd/mm/yyyy\ hh:mm"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="7"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// excelEpoch is day 0 of Excel's 1900 date system, allowing for its 1900
+// leap year bug
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter writes a workbook with a single sheet. The sheet is the last
+// part of the zip, so its rows go straight to the output as they are written.
+type xlsxWriter struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, sheet string, columns []Column) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheet)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(f), columns: columns}
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<cols>`)
+	for i, col := range columns {
+		n := strconv.Itoa(i + 1)
+		xw.sheet.WriteString(`<col min="` + n + `" max="` + n + `" width="` + strconv.Itoa(columnWidth(col)) + `" customWidth="1"/>`)
+	}
+	xw.sheet.WriteString(`</cols><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.Header
+	}
+	if err := xw.writeRow(header, true); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func xlsxWorkbook(sheet string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="` + escapeXML(sheetName(sheet)) + `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+}
+
+// sheetName makes name a valid sheet name: at most 31 characters and none of
+// : \ / ? * [ ]
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+func columnWidth(col Column) int {
+	width := len([]rune(col.Header)) + 2
+	min := 12
+	switch col.Kind {
+	case Currency:
+		min = 16
+	case DateTime:
+		min = 17
+	case Text:
+		min = 18
+	}
+	if width < min {
+		width = min
+	}
+	return width
+}
+
+func (xw *xlsxWriter) WriteRow(values ...interface{}) error {
+	return xw.writeRow(values, false)
+}
+
+func (xw *xlsxWriter) writeRow(values []interface{}, header bool) error {
+	xw.row++
+	row := strconv.Itoa(xw.row)
+	xw.sheet.WriteString(`<row r="` + row + `">`)
+	for i, col := range xw.columns {
+		if i >= len(values) || values[i] == nil {
+			continue
+		}
+		ref := columnName(i) + row
+		if header {
+			xw.writeText(ref, styleHeader, toText(values[i]))
+			continue
+		}
+		xw.writeCell(ref, col.Kind, values[i])
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxWriter) writeCell(ref string, kind Kind, v interface{}) {
+	switch kind {
+	case Integer, Decimal, Currency:
+		if n, ok := toNumber(v); ok {
+			style := map[Kind]int{Integer: styleInteger, Decimal: styleDecimal, Currency: styleCurrency}[kind]
+			xw.writeNumber(ref, style, strconv.FormatFloat(n, 'f', -1, 64))
+			return
+		}
+	case Date, DateTime:
+		if t, ok := toTime(v); ok {
+			style := styleDate
+			if kind == DateTime {
+				style = styleDateTime
+			}
+			xw.writeNumber(ref, style, strconv.FormatFloat(excelSerial(t), 'f', -1, 64))
+			return
+		}
+	}
+	if s := toText(v); s != "" {
+		xw.writeText(ref, styleDefault, s)
+	}
+}
+
+func (xw *xlsxWriter) writeNumber(ref string, style int, n string) {
+	xw.sheet.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `"><v>` + n + `</v></c>`)
+}
+
+func (xw *xlsxWriter) writeText(ref string, style int, s string) {
+	xw.sheet.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `" t="inlineStr"><is><t xml:space="preserve">` + escapeXML(s) + `</t></is></c>`)
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// excelSerial is t as an Excel date: days since the epoch, the time of day as
+// the fraction. The wall clock of t is kept whatever its location.
+func excelSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// columnName is the letter name of the zero based column i: A, B, ..., Z, AA
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package midtransapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Midtrans transaction statuses (transaction_status).
+const (
+	StatusPending       = "pending"
+	StatusCapture       = "capture"
+	StatusSettlement    = "settlement"
+	StatusDeny          = "deny"
+	StatusCancel        = "cancel"
+	StatusExpire        = "expire"
+	StatusFailure       = "failure"
+	StatusRefund        = "refund"
+	StatusPartialRefund = "partial_refund"
+)
+
+// ErrTransactionNotFound is returned by Status when Midtrans has no
+// transaction for the order ID, e.g. a Snap link the customer never used.
+var ErrTransactionNotFound = errors.New("midtrans transaction not found")
+
+// Client calls the Midtrans Core API status and refund endpoints. Unlike the
+// go-midtrans client its base URL is configurable, so it can be pointed at the
+// fake server of package midtranstest.
+type Client struct {
+	baseURL    string
+	serverKey  string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the Core API at baseURL, for example
+// https://api.sandbox.midtrans.com.
+func NewClient(baseURL, serverKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		serverKey:  serverKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type Refund struct {
+	RefundChargebackID int    `json:"refund_chargeback_id"`
+	RefundAmount       string `json:"refund_amount"`
+	Reason             string `json:"reason"`
+	RefundKey          string `json:"refund_key"`
+	RefundMethod       string `json:"refund_method"`
+	BankConfirmedAt    string `json:"bank_confirmed_at"`
+	CreatedAt          string `json:"created_at"`
+}
+
+type VANumber struct {
+	Bank     string `json:"bank"`
+	VANumber string `json:"va_number"`
+}
+
+// TransactionStatus is the response of GET /v2/{order_id}/status.
+type TransactionStatus struct {
+	StatusCode        string     `json:"status_code"`
+	StatusMessage     string     `json:"status_message"`
+	OrderID           string     `json:"order_id"`
+	TransactionID     string     `json:"transaction_id"`
+	TransactionStatus string     `json:"transaction_status"`
+	TransactionTime   string     `json:"transaction_time"`
+	SettlementTime    string     `json:"settlement_time"`
+	FraudStatus       string     `json:"fraud_status"`
+	PaymentType       string     `json:"payment_type"`
+	GrossAmount       string     `json:"gross_amount"`
+	Currency          string     `json:"currency"`
+	MerchantID        string     `json:"merchant_id"`
+	VANumbers         []VANumber `json:"va_numbers"`
+	RefundAmount      string     `json:"refund_amount"`
+	Refunds           []Refund   `json:"refunds"`
+}
+
+type RefundRequest struct {
+	RefundKey string `json:"refund_key"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason"`
+}
+
+// RefundResponse is the response of POST /v2/{order_id}/refund. StatusCode 200
+// means the refund is done, 201 that it is still being processed.
+type RefundResponse struct {
+	StatusCode         string `json:"status_code"`
+	StatusMessage      string `json:"status_message"`
+	OrderID            string `json:"order_id"`
+	TransactionID      string `json:"transaction_id"`
+	TransactionStatus  string `json:"transaction_status"`
+	RefundChargebackID int    `json:"refund_chargeback_id"`
+	RefundAmount       string `json:"refund_amount"`
+	RefundKey          string `json:"refund_key"`
+}
+
+// Status returns the current state of the transaction of orderID.
+func (c *Client) Status(orderID string) (*TransactionStatus, error) {
+	var res TransactionStatus
+	if err := c.call(http.MethodGet, "/v2/"+url.PathEscape(orderID)+"/status", nil, &res); err != nil {
+		return nil, err
+	}
+	if res.StatusCode == "404" {
+		return nil, ErrTransactionNotFound
+	}
+	if res.TransactionStatus == "" {
+		return nil, fmt.Errorf("midtrans status %s: %s", res.StatusCode, res.StatusMessage)
+	}
+	return &res, nil
+}
+
+// Refund refunds amount of the settled transaction of orderID. Midtrans ignores
+// a repeated refund_key, so retrying a refund with the same key is safe.
+func (c *Client) Refund(orderID string, req RefundRequest) (*RefundResponse, error) {
+	var res RefundResponse
+	if err := c.call(http.MethodPost, "/v2/"+url.PathEscape(orderID)+"/refund", req, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) call(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(c.serverKey, "")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("midtrans http %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("midtrans http %d: invalid response: %w", resp.StatusCode, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package midtransapi_test
+
+import (
+	"errors"
+	"testing"
+
+	"service-travego/internal/midtransapi"
+	"service-travego/internal/midtransapi/midtranstest"
+)
+
+func newClient(t *testing.T) (*midtranstest.Fake, *midtransapi.Client) {
+	t.Helper()
+	fake, srv := midtranstest.NewServer("server-key")
+	t.Cleanup(srv.Close)
+	return fake, midtransapi.NewClient(srv.URL, "server-key")
+}
+
+func TestStatusReturnsTransaction(t *testing.T) {
+	fake, client := newClient(t)
+	fake.SetTransaction(midtransapi.TransactionStatus{
+		OrderID:           "INV-1",
+		TransactionStatus: midtransapi.StatusSettlement,
+		GrossAmount:       "150000.00",
+		PaymentType:       "bank_transfer",
+	})
+
+	st, err := client.Status("INV-1")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if st.TransactionStatus != midtransapi.StatusSettlement || st.GrossAmount != "150000.00" {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
+
+func TestStatusUnknownTransaction(t *testing.T) {
+	_, client := newClient(t)
+
+	if _, err := client.Status("INV-404"); !errors.Is(err, midtransapi.ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound, got %v", err)
+	}
+}
+
+func TestStatusRejectsWrongServerKey(t *testing.T) {
+	fake, srv := midtranstest.NewServer("server-key")
+	defer srv.Close()
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "INV-1", TransactionStatus: midtransapi.StatusPending})
+
+	if _, err := midtransapi.NewClient(srv.URL, "other-key").Status("INV-1"); err == nil {
+		t.Fatal("expected an error for a wrong server key")
+	}
+}
+
+func TestRefundIsIdempotentByKey(t *testing.T) {
+	fake, client := newClient(t)
+	fake.SetTransaction(midtransapi.TransactionStatus{
+		OrderID:           "INV-1",
+		TransactionStatus: midtransapi.StatusSettlement,
+		GrossAmount:       "100000.00",
+	})
+
+	req := midtransapi.RefundRequest{RefundKey: "rf-1", Amount: 40000, Reason: "cancelled"}
+	for i := 0; i < 2; i++ {
+		res, err := client.Refund("INV-1", req)
+		if err != nil {
+			t.Fatalf("refund: %v", err)
+		}
+		if res.StatusCode != "200" {
+			t.Fatalf("unexpected refund response: %+v", res)
+		}
+	}
+
+	tx, _ := fake.Transaction("INV-1")
+	if len(tx.Refunds) != 1 || tx.RefundAmount != "40000.00" {
+		t.Fatalf("expected a single refund of 40000, got %+v", tx)
+	}
+	if tx.TransactionStatus != midtransapi.StatusPartialRefund {
+		t.Fatalf("expected partial_refund, got %s", tx.TransactionStatus)
+	}
+}
+
+func TestRefundCannotExceedRemainingAmount(t *testing.T) {
+	fake, client := newClient(t)
+	fake.SetTransaction(midtransapi.TransactionStatus{
+		OrderID:           "INV-1",
+		TransactionStatus: midtransapi.StatusSettlement,
+		GrossAmount:       "100000.00",
+	})
+
+	if _, err := client.Refund("INV-1", midtransapi.RefundRequest{RefundKey: "rf-1", Amount: 100000}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	res, err := client.Refund("INV-1", midtransapi.RefundRequest{RefundKey: "rf-2", Amount: 1})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if res.StatusCode == "200" {
+		t.Fatal("expected refund over the remaining amount to be rejected")
+	}
+
+	tx, _ := fake.Transaction("INV-1")
+	if tx.TransactionStatus != midtransapi.StatusRefund {
+		t.Fatalf("expected refund, got %s", tx.TransactionStatus)
+	}
+}
@@ -0,0 +1,236 @@
+// Package midtranstest provides an in-memory fake of the Midtrans Core API
+// status and refund endpoints, for tests and local development.
+package midtranstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service-travego/internal/midtransapi"
+)
+
+// Fake serves GET /v2/{order_id}/status and POST /v2/{order_id}/refund from
+// transactions seeded with SetTransaction or POST /_fake/transactions.
+type Fake struct {
+	mu           sync.Mutex
+	serverKey    string
+	transactions map[string]*midtransapi.TransactionStatus
+	rejections   map[string]string
+	nextRefundID int
+}
+
+// NewFake creates a fake that only accepts requests authenticated with
+// serverKey. An empty serverKey accepts any request.
+func NewFake(serverKey string) *Fake {
+	return &Fake{
+		serverKey:    serverKey,
+		transactions: make(map[string]*midtransapi.TransactionStatus),
+		rejections:   make(map[string]string),
+		nextRefundID: 1,
+	}
+}
+
+// NewServer starts a fake on an httptest server. The caller closes the server.
+func NewServer(serverKey string) (*Fake, *httptest.Server) {
+	fake := NewFake(serverKey)
+	return fake, httptest.NewServer(fake)
+}
+
+// SetTransaction adds or replaces a transaction. Only OrderID,
+// TransactionStatus and GrossAmount are required.
+func (f *Fake) SetTransaction(tx midtransapi.TransactionStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if tx.StatusCode == "" {
+		tx.StatusCode = "200"
+	}
+	if tx.TransactionID == "" {
+		tx.TransactionID = "fake-" + tx.OrderID
+	}
+	if tx.TransactionTime == "" {
+		tx.TransactionTime = time.Now().Format("2006-01-02 15:04:05")
+	}
+	if tx.Currency == "" {
+		tx.Currency = "IDR"
+	}
+	if tx.FraudStatus == "" && (tx.TransactionStatus == midtransapi.StatusCapture || tx.TransactionStatus == midtransapi.StatusSettlement) {
+		tx.FraudStatus = "accept"
+	}
+	f.transactions[tx.OrderID] = &tx
+}
+
+// Transaction returns a copy of the transaction of orderID.
+func (f *Fake) Transaction(orderID string) (midtransapi.TransactionStatus, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tx, ok := f.transactions[orderID]
+	if !ok {
+		return midtransapi.TransactionStatus{}, false
+	}
+	out := *tx
+	out.Refunds = append([]midtransapi.Refund(nil), tx.Refunds...)
+	return out, true
+}
+
+// RejectRefunds makes refunds of orderID fail with message, the way Midtrans
+// rejects refunds for payment types that do not support them.
+func (f *Fake) RejectRefunds(orderID, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejections[orderID] = message
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.serverKey != "" {
+		if user, _, ok := r.BasicAuth(); !ok || user != f.serverKey {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"status_code":    "401",
+				"status_message": "Access denied due to unauthorized transaction, please check client or server key",
+			})
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/_fake/transactions" {
+		f.seed(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "v2" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"status_code": "404", "status_message": "Not found"})
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && parts[2] == "status":
+		f.status(w, parts[1])
+	case r.Method == http.MethodPost && parts[2] == "refund":
+		f.refund(w, r, parts[1])
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"status_code": "404", "status_message": "Not found"})
+	}
+}
+
+func (f *Fake) seed(w http.ResponseWriter, r *http.Request) {
+	var tx midtransapi.TransactionStatus
+	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil || tx.OrderID == "" || tx.TransactionStatus == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"status_code": "400", "status_message": "order_id and transaction_status are required"})
+		return
+	}
+	f.SetTransaction(tx)
+	stored, _ := f.Transaction(tx.OrderID)
+	writeJSON(w, http.StatusOK, stored)
+}
+
+func (f *Fake) status(w http.ResponseWriter, orderID string) {
+	tx, ok := f.Transaction(orderID)
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, tx)
+}
+
+func (f *Fake) refund(w http.ResponseWriter, r *http.Request, orderID string) {
+	var req midtransapi.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusOK, map[string]string{"status_code": "400", "status_message": "Invalid request body"})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tx, ok := f.transactions[orderID]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if msg, ok := f.rejections[orderID]; ok {
+		writeJSON(w, http.StatusOK, map[string]string{"status_code": "412", "status_message": msg})
+		return
+	}
+
+	// A repeated refund_key returns the refund already made.
+	for _, rf := range tx.Refunds {
+		if req.RefundKey != "" && rf.RefundKey == req.RefundKey {
+			writeJSON(w, http.StatusOK, refundResponse(tx, rf))
+			return
+		}
+	}
+
+	if tx.TransactionStatus != midtransapi.StatusSettlement && tx.TransactionStatus != midtransapi.StatusCapture &&
+		tx.TransactionStatus != midtransapi.StatusPartialRefund {
+		writeJSON(w, http.StatusOK, map[string]string{"status_code": "412", "status_message": "Transaction status cannot be updated"})
+		return
+	}
+
+	gross := parseAmount(tx.GrossAmount)
+	refunded := parseAmount(tx.RefundAmount)
+	remaining := gross - refunded
+	amount := float64(req.Amount)
+	if req.Amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		writeJSON(w, http.StatusOK, map[string]string{"status_code": "412", "status_message": "Refund amount exceeds the remaining amount"})
+		return
+	}
+
+	rf := midtransapi.Refund{
+		RefundChargebackID: f.nextRefundID,
+		RefundAmount:       formatAmount(amount),
+		Reason:             req.Reason,
+		RefundKey:          req.RefundKey,
+		RefundMethod:       "online",
+		CreatedAt:          time.Now().Format("2006-01-02 15:04:05"),
+	}
+	f.nextRefundID++
+	tx.Refunds = append(tx.Refunds, rf)
+	tx.RefundAmount = formatAmount(refunded + amount)
+	if refunded+amount >= gross {
+		tx.TransactionStatus = midtransapi.StatusRefund
+	} else {
+		tx.TransactionStatus = midtransapi.StatusPartialRefund
+	}
+	writeJSON(w, http.StatusOK, refundResponse(tx, rf))
+}
+
+func refundResponse(tx *midtransapi.TransactionStatus, rf midtransapi.Refund) midtransapi.RefundResponse {
+	return midtransapi.RefundResponse{
+		StatusCode:         "200",
+		StatusMessage:      "Success, refund request is approved",
+		OrderID:            tx.OrderID,
+		TransactionID:      tx.TransactionID,
+		TransactionStatus:  tx.TransactionStatus,
+		RefundChargebackID: rf.RefundChargebackID,
+		RefundAmount:       rf.RefundAmount,
+		RefundKey:          rf.RefundKey,
+	}
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"status_code": "404", "status_message": "Transaction doesn't exist."})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func parseAmount(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return v
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
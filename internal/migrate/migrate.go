@@ -0,0 +1,483 @@
+// Package migrate applies the versioned SQL migrations in db/migrations and
+// records them in a schema_migrations table.
+//
+// A migration is a pair of files <version>_<name>.up.sql and
+// <version>_<name>.down.sql. A file named <version>_<name>.<driver>.up.sql
+// (driver postgres or mysql) is used instead of the plain one on that driver.
+// The plain files are written for PostgreSQL; every migration ships a mysql
+// variant.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockName identifies the migration lock; only one runner migrates at a time
+const lockName = "schema_migrations"
+
+// lockTimeout is how long a runner waits for another one to finish (mysql)
+const lockTimeout = 60 * time.Second
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+?)(?:\.(postgres|mysql))?\.(up|down)\.sql$`)
+var createNamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	UpPath   string
+	DownPath string
+}
+
+// Status tells whether a migration is applied
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Missing is set for an applied migration whose files are gone
+	Missing bool
+}
+
+// Load reads the migrations in dir for driver, sorted by version
+func Load(dir, driver string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		path     string
+		specific bool
+	}
+	byVersion := map[int64]*Migration{}
+	chosen := map[string]file{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		if m[3] != "" && m[3] != driver {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", e.Name(), err)
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, mig.Name, m[2])
+		}
+
+		// a driver specific file wins over the plain one
+		key := m[1] + m[4]
+		if prev, ok := chosen[key]; ok && (prev.specific || m[3] == "") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		chosen[key] = file{path: path, specific: m[3] != ""}
+		if m[4] == "up" {
+			mig.UpPath = path
+		} else {
+			mig.DownPath = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.UpPath == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Create writes an empty up and down file for a new migration numbered after
+// the last one in dir, and returns their paths
+func Create(dir, name string) (string, string, error) {
+	name = strings.Trim(createNamePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name is required")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	var next int64 = 1
+	for _, e := range entries {
+		if m := fileNamePattern.FindStringSubmatch(e.Name()); m != nil {
+			if v, err := strconv.ParseInt(m[1], 10, 64); err == nil && v >= next {
+				next = v + 1
+			}
+		}
+	}
+
+	base := fmt.Sprintf("%03d_%s", next, name)
+	up, down := filepath.Join(dir, base+".up.sql"), filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(up, []byte("-- "+base+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- Reverts "+base+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New loads the migrations in dir for the database's driver
+func New(db *sql.DB, driver, dir string) (*Migrator, error) {
+	if driver != "postgres" && driver != "mysql" {
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+	migrations, err := Load(dir, driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+func (m *Migrator) getPlaceholder(pos int) string {
+	if m.driver == "mysql" {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", pos)
+}
+
+// session holds the connection a run works on while it holds the lock
+type session struct {
+	conn *sql.Conn
+	ctx  context.Context
+}
+
+// lock takes the migration lock on its own connection, creating the
+// schema_migrations table when needed
+func (m *Migrator) lock(ctx context.Context) (*session, func(), error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlock string
+	if m.driver == "mysql" {
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&got); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		if got.Int64 != 1 {
+			conn.Close()
+			return nil, nil, errors.New("another migration is running")
+		}
+		unlock = "SELECT RELEASE_LOCK('" + lockName + "')"
+	} else {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", lockName); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		unlock = "SELECT pg_advisory_unlock(hashtext('" + lockName + "'))"
+	}
+	release := func() {
+		_, _ = conn.ExecContext(context.Background(), unlock)
+		conn.Close()
+	}
+
+	table := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name character varying(255) NOT NULL,
+		applied_at timestamp with time zone NOT NULL
+	)`
+	if m.driver == "mysql" {
+		table = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at DATETIME NOT NULL
+		)`
+	}
+	if _, err := conn.ExecContext(ctx, table); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return &session{conn: conn, ctx: ctx}, release, nil
+}
+
+// applied returns when each applied version was applied
+func (m *Migrator) applied(s *session) (map[int64]time.Time, map[int64]string, error) {
+	rows, err := s.conn.QueryContext(s.ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	at := map[int64]time.Time{}
+	names := map[int64]string{}
+	for rows.Next() {
+		var version int64
+		var name string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+			return nil, nil, err
+		}
+		at[version] = appliedAt
+		names[version] = name
+	}
+	return at, names, rows.Err()
+}
+
+// run executes a migration file and records (or forgets) its version in the
+// same transaction. MySQL commits DDL implicitly, so there the statements run
+// one by one and the version is recorded after the last one.
+func (m *Migrator) run(s *session, mig Migration, path string, up bool) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	record := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %s", m.getPlaceholder(1))
+	args := []interface{}{mig.Version}
+	if up {
+		record = fmt.Sprintf("INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)",
+			m.getPlaceholder(1), m.getPlaceholder(2), m.getPlaceholder(3))
+		args = append(args, mig.Name, time.Now())
+	}
+
+	if m.driver == "mysql" {
+		for _, stmt := range SplitStatements(string(body)) {
+			if _, err := s.conn.ExecContext(s.ctx, stmt); err != nil {
+				return err
+			}
+		}
+		_, err := s.conn.ExecContext(s.ctx, record, args...)
+		return err
+	}
+
+	tx, err := s.conn.BeginTx(s.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if strings.TrimSpace(string(body)) != "" {
+		if _, err := tx.ExecContext(s.ctx, string(body)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(s.ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Up applies up to limit pending migrations (all when limit < 1) and returns
+// the ones it applied. With baseline, a database that has no migrations
+// recorded yet gets the first migration recorded without running it: for
+// databases created before migrations were tracked.
+func (m *Migrator) Up(ctx context.Context, limit int, baseline bool) ([]Migration, error) {
+	s, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, _, err := m.applied(s)
+	if err != nil {
+		return nil, err
+	}
+	if baseline && len(applied) == 0 && len(m.migrations) > 0 {
+		first := m.migrations[0]
+		query := fmt.Sprintf("INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)",
+			m.getPlaceholder(1), m.getPlaceholder(2), m.getPlaceholder(3))
+		if _, err := s.conn.ExecContext(ctx, query, first.Version, first.Name, time.Now()); err != nil {
+			return nil, err
+		}
+		applied[first.Version] = time.Now()
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if limit > 0 && len(done) >= limit {
+			break
+		}
+		if err := m.run(s, mig, mig.UpPath, true); err != nil {
+			return done, fmt.Errorf("migration %03d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down reverts the last limit applied migrations (at least one) and returns
+// the ones it reverted
+func (m *Migrator) Down(ctx context.Context, limit int) ([]Migration, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	s, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, names, err := m.applied(s)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	byVersion := map[int64]Migration{}
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	var done []Migration
+	for _, v := range versions {
+		if len(done) >= limit {
+			break
+		}
+		mig, ok := byVersion[v]
+		if !ok {
+			return done, fmt.Errorf("migration %03d_%s is applied but its files are missing", v, names[v])
+		}
+		if mig.DownPath == "" {
+			return done, fmt.Errorf("migration %03d_%s has no down file", mig.Version, mig.Name)
+		}
+		if err := m.run(s, mig, mig.DownPath, false); err != nil {
+			return done, fmt.Errorf("reverting %03d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Status lists every migration, on disk or applied, by version
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	s, release, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, names, err := m.applied(s)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(m.migrations))
+	seen := map[int64]bool{}
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			at := at
+			st.AppliedAt = &at
+		}
+		seen[mig.Version] = true
+		list = append(list, st)
+	}
+	for v, at := range applied {
+		if seen[v] {
+			continue
+		}
+		at := at
+		list = append(list, Status{Version: v, Name: names[v], AppliedAt: &at, Missing: true})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// SplitStatements splits a SQL script into statements on the semicolons that
+// are outside quotes and comments. Comments are kept with the statement they
+// precede; statements with nothing but comments are dropped.
+func SplitStatements(script string) []string {
+	var stmts []string
+	var cur strings.Builder
+	hasCode := false
+	flush := func() {
+		if hasCode {
+			stmts = append(stmts, strings.TrimSpace(cur.String()))
+		}
+		cur.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-', c == '#':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			cur.WriteString(script[i : i+end])
+			i += end - 1
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 2
+			} else {
+				end += 2
+			}
+			cur.WriteString(script[i : i+2+end])
+			i += 1 + end
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(script) {
+				if script[j] == '\\' && c != '`' {
+					j += 2
+					continue
+				}
+				if script[j] == c {
+					// a doubled quote is an escaped quote
+					if j+1 < len(script) && script[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(script) {
+				j = len(script) - 1
+			}
+			cur.WriteString(script[i : j+1])
+			hasCode = true
+			i = j
+		case c == ';':
+			flush()
+		default:
+			cur.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				hasCode = true
+			}
+		}
+	}
+	flush()
+	return stmts
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("-- "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadPrefersDriverFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir,
+		"002_add_notes.up.sql", "002_add_notes.down.sql",
+		"002_add_notes.mysql.up.sql",
+		"001_init.up.sql", "001_init.down.sql",
+		"README.md",
+	)
+
+	migrations, err := Load(dir, "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Name != "add_notes" {
+		t.Fatalf("unexpected migrations %+v", migrations)
+	}
+	if got := filepath.Base(migrations[1].UpPath); got != "002_add_notes.mysql.up.sql" {
+		t.Fatalf("mysql should use its own up file, got %s", got)
+	}
+	if got := filepath.Base(migrations[1].DownPath); got != "002_add_notes.down.sql" {
+		t.Fatalf("mysql should fall back to the plain down file, got %s", got)
+	}
+
+	migrations, err = Load(dir, "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filepath.Base(migrations[1].UpPath); got != "002_add_notes.up.sql" {
+		t.Fatalf("postgres should use the plain up file, got %s", got)
+	}
+}
+
+func TestLoadRejectsMissingUpFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "001_init.down.sql")
+	if _, err := Load(dir, "postgres"); err == nil {
+		t.Fatal("expected an error for a migration without an up file")
+	}
+}
+
+func TestCreateNumbersAfterLastMigration(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "000_baseline.up.sql", "017_create_audit_logs.up.sql")
+
+	up, down, err := Create(dir, "Add Fleet Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(up) != "018_add_fleet_notes.up.sql" || filepath.Base(down) != "018_add_fleet_notes.down.sql" {
+		t.Fatalf("unexpected files %s %s", up, down)
+	}
+	if _, err := Load(dir, "postgres"); err != nil {
+		t.Fatalf("created migration does not load: %v", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- header comment only;
+CREATE TABLE a (note text DEFAULT 'x;y');
+/* block; comment */
+INSERT INTO a VALUES ('it''s; fine');
+
+`
+	got := SplitStatements(script)
+	want := []string{
+		"-- header comment only;\nCREATE TABLE a (note text DEFAULT 'x;y')",
+		"/* block; comment */\nINSERT INTO a VALUES ('it''s; fine')",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+// postgresOnly matches the PostgreSQL syntax the mysql variants must not use
+var postgresOnly = regexp.MustCompile(`::|public\.|\w uuid\b|with time zone|IF (NOT )?EXISTS idx|COLUMN IF|CASCADE|SEQUENCE|,\s*\)\s*$`)
+
+func TestShippedMigrationsRunOnMySQL(t *testing.T) {
+	migrations, err := Load(filepath.Join("..", "..", "db", "migrations"), "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mig := range migrations {
+		for _, path := range []string{mig.UpPath, mig.DownPath} {
+			if !strings.HasSuffix(path, ".mysql.up.sql") && !strings.HasSuffix(path, ".mysql.down.sql") {
+				t.Errorf("%03d_%s has no mysql variant for %s", mig.Version, mig.Name, filepath.Base(path))
+				continue
+			}
+			body, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, stmt := range SplitStatements(string(body)) {
+				if pg := postgresOnly.FindString(stmt); pg != "" {
+					t.Errorf("%s uses %q in %q", filepath.Base(path), pg, stmt)
+				}
+			}
+		}
+	}
+}
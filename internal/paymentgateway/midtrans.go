@@ -0,0 +1,42 @@
+package paymentgateway
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/veritrans/go-midtrans"
+)
+
+// Midtrans issues invoices as Snap transactions.
+type Midtrans struct {
+	snap midtrans.SnapGateway
+}
+
+func NewMidtrans(snap midtrans.SnapGateway) *Midtrans {
+	return &Midtrans{snap: snap}
+}
+
+func (m *Midtrans) Provider() string {
+	return ProviderMidtrans
+}
+
+func (m *Midtrans) CreateCharge(req ChargeRequest) (*Charge, error) {
+	snapReq := &midtrans.SnapReq{
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.InvoiceNumber,
+			GrossAmt: req.Amount,
+		},
+	}
+	if req.FinishURL != "" {
+		snapReq.Callbacks = &midtrans.Callbacks{Finish: req.FinishURL}
+	}
+
+	res, err := m.snap.GetToken(snapReq)
+	if err != nil {
+		return nil, err
+	}
+	if res.Token == "" {
+		return nil, fmt.Errorf("midtrans snap %s: %s", res.StatusCode, strings.Join(res.ErrorMessages, "; "))
+	}
+	return &Charge{Provider: ProviderMidtrans, Token: res.Token, RedirectURL: res.RedirectURL}, nil
+}
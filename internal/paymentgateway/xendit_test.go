@@ -0,0 +1,69 @@
+package paymentgateway_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"service-travego/internal/paymentgateway"
+)
+
+func TestXenditCreateCharge(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, ok := r.BasicAuth(); !ok || user != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error_code":"INVALID_API_KEY","message":"invalid key"}`))
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/invoices" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"id":"inv-1","invoice_url":"https://checkout.xendit.co/web/inv-1"}`))
+	}))
+	defer srv.Close()
+
+	x := paymentgateway.NewXendit(srv.URL, "secret", "token")
+	charge, err := x.CreateCharge(paymentgateway.ChargeRequest{InvoiceNumber: "INV-1", Amount: 150000})
+	if err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+	if charge.Provider != paymentgateway.ProviderXendit || charge.Token != "inv-1" || charge.RedirectURL == "" {
+		t.Fatalf("unexpected charge %+v", charge)
+	}
+	if got["external_id"] != "INV-1" || got["amount"] != float64(150000) || got["currency"] != "IDR" {
+		t.Fatalf("unexpected invoice request %v", got)
+	}
+
+	if _, err := paymentgateway.NewXendit(srv.URL, "wrong", "token").CreateCharge(paymentgateway.ChargeRequest{InvoiceNumber: "INV-2", Amount: 1}); err == nil {
+		t.Fatal("expected an error for a rejected API key")
+	}
+}
+
+func TestXenditVerifyCallbackToken(t *testing.T) {
+	x := paymentgateway.NewXendit("", "secret", "token")
+	if !x.VerifyCallbackToken("token") {
+		t.Fatal("expected the configured token to verify")
+	}
+	if x.VerifyCallbackToken("other") || x.VerifyCallbackToken("") {
+		t.Fatal("expected other tokens to be rejected")
+	}
+	if paymentgateway.NewXendit("", "secret", "").VerifyCallbackToken("") {
+		t.Fatal("expected an unset token to reject every callback")
+	}
+}
+
+func TestRegistryGet(t *testing.T) {
+	reg := paymentgateway.NewRegistry(paymentgateway.ProviderXendit, paymentgateway.NewXendit("", "secret", "token"))
+	if g, err := reg.Get(""); err != nil || g.Provider() != paymentgateway.ProviderXendit {
+		t.Fatalf("Get(\"\") = %v, %v; want the default gateway", g, err)
+	}
+	if _, err := reg.Get(paymentgateway.ProviderMidtrans); err == nil {
+		t.Fatal("expected an error for a gateway that is not configured")
+	}
+	if _, err := reg.Get("paypal"); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,63 @@
+package pdfrender
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cache keeps the most recently used PDFs up to a total size in bytes
+type cache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key string
+	pdf []byte
+}
+
+func newCache(maxBytes int64) *cache {
+	return &cache{maxBytes: maxBytes, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *cache) get(key string) ([]byte, bool) {
+	if c.maxBytes <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).pdf, true
+}
+
+// put stores pdf, evicting the least recently used PDFs to make room; a PDF
+// larger than the whole cache is not stored
+func (c *cache) put(key string, pdf []byte) {
+	n := int64(len(pdf))
+	if c.maxBytes <= 0 || n > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	for c.size+n > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= int64(len(entry.pdf))
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, pdf: pdf})
+	c.size += n
+}
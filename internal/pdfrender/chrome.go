@@ -0,0 +1,116 @@
+package pdfrender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// browser is a running Chrome the pool opens tabs in
+type browser interface {
+	NewTab() (tab, error)
+	Close()
+}
+
+// tab prints one document at a time
+type tab interface {
+	Print(ctx context.Context, htmlDoc string) ([]byte, error)
+	Close()
+}
+
+type chromeBrowser struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	cancelAlloc context.CancelFunc
+}
+
+// launchChrome starts a headless Chrome process
+func launchChrome() (browser, error) {
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(
+		context.Background(),
+		append(chromedp.DefaultExecAllocatorOptions[:],
+			chromedp.Flag("headless", true),
+			chromedp.Flag("disable-gpu", true),
+			chromedp.Flag("no-sandbox", true),
+			chromedp.Flag("disable-dev-shm-usage", true),
+		)...,
+	)
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	// running no actions starts the browser
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		cancelAlloc()
+		return nil, fmt.Errorf("start chrome: %w", err)
+	}
+	return &chromeBrowser{ctx: ctx, cancel: cancel, cancelAlloc: cancelAlloc}, nil
+}
+
+func (b *chromeBrowser) NewTab() (tab, error) {
+	if b.ctx.Err() != nil {
+		return nil, errors.New("chrome has exited")
+	}
+	ctx, cancel := chromedp.NewContext(b.ctx)
+	if err := chromedp.Run(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("open tab: %w", err)
+	}
+	return &chromeTab{ctx: ctx, cancel: cancel}, nil
+}
+
+func (b *chromeBrowser) Close() {
+	b.cancel()
+	b.cancelAlloc()
+}
+
+type chromeTab struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Print loads htmlDoc into the tab and prints it on A4. ctx bounds the print
+// without closing the tab, which is reused for the next document.
+func (t *chromeTab) Print(ctx context.Context, htmlDoc string) ([]byte, error) {
+	runCtx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	var pdfBuf []byte
+	err := chromedp.Run(
+		runCtx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			frameTree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(frameTree.Frame.ID, htmlDoc).Do(ctx)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			buf, _, err := page.PrintToPDF().
+				WithPrintBackground(true).
+				WithPaperWidth(8.27).
+				WithPaperHeight(11.69).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			pdfBuf = buf
+			return nil
+		}),
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return pdfBuf, nil
+}
+
+func (t *chromeTab) Close() {
+	t.cancel()
+}
@@ -0,0 +1,266 @@
+// Package pdfrender prints HTML documents to PDF with a long lived headless
+// Chrome. A fixed number of tabs print concurrently and are reused between
+// documents; renders beyond that wait in a bounded queue. PDFs are cached by
+// the hash of their HTML, so printing an unchanged document again is served
+// without Chrome.
+package pdfrender
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"service-travego/internal/telemetry"
+)
+
+var (
+	// ErrBusy is returned when the render queue is full
+	ErrBusy = errors.New("pdf render queue is full")
+	// ErrClosed is returned once the pool has shut down
+	ErrClosed = errors.New("pdf renderer is shut down")
+)
+
+// Options tune the pool
+type Options struct {
+	// Tabs is how many documents are printed at once
+	Tabs int
+	// QueueSize is how many renders may wait for a free tab
+	QueueSize int
+	// Timeout bounds a render, queueing included
+	Timeout time.Duration
+	// TabReuse is how many documents a tab prints before it is replaced
+	TabReuse int
+	// CacheBytes is the total size of cached PDFs; 0 turns the cache off
+	CacheBytes int64
+}
+
+// LoadOptions reads PDF_RENDER_TABS (default 2), PDF_RENDER_QUEUE_SIZE
+// (default 32), PDF_RENDER_TIMEOUT_SECONDS (default 45) and PDF_CACHE_MB
+// (default 64, 0 turns the cache off)
+func LoadOptions() Options {
+	opts := Options{Tabs: 2, QueueSize: 32, Timeout: 45 * time.Second, TabReuse: 100, CacheBytes: 64 << 20}
+	if n, err := strconv.Atoi(os.Getenv("PDF_RENDER_TABS")); err == nil && n > 0 {
+		opts.Tabs = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("PDF_RENDER_QUEUE_SIZE")); err == nil && n >= 0 {
+		opts.QueueSize = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("PDF_RENDER_TIMEOUT_SECONDS")); err == nil && n > 0 {
+		opts.Timeout = time.Duration(n) * time.Second
+	}
+	if n, err := strconv.Atoi(os.Getenv("PDF_CACHE_MB")); err == nil && n >= 0 {
+		opts.CacheBytes = int64(n) << 20
+	}
+	return opts
+}
+
+// Pool renders PDFs with a shared Chrome that is started on the first render
+// and restarted when it stops responding
+type Pool struct {
+	opts   Options
+	launch func() (browser, error)
+	jobs   chan *job
+	cache  *cache
+
+	browserMu sync.Mutex
+	browser   browser
+
+	mu     sync.Mutex
+	calls  map[string]*job
+	closed bool
+}
+
+type job struct {
+	key      string
+	ctx      context.Context
+	htmlDoc  string
+	enqueued time.Time
+	done     chan struct{}
+	pdf      []byte
+	err      error
+}
+
+// NewPool creates a pool; documents are printed once Run is started
+func NewPool(opts Options) *Pool {
+	if opts.Tabs <= 0 {
+		opts.Tabs = 1
+	}
+	if opts.QueueSize < 0 {
+		opts.QueueSize = 0
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 45 * time.Second
+	}
+	return &Pool{
+		opts:   opts,
+		launch: launchChrome,
+		jobs:   make(chan *job, opts.QueueSize),
+		cache:  newCache(opts.CacheBytes),
+		calls:  map[string]*job{},
+	}
+}
+
+// Render prints htmlDoc to an A4 PDF. Renders of the same document share one
+// print, and a document printed before is served from the cache. The returned
+// slice may be shared and must not be modified.
+func (p *Pool) Render(ctx context.Context, htmlDoc string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(htmlDoc))
+	key := hex.EncodeToString(sum[:])
+	if pdf, ok := p.cache.get(key); ok {
+		telemetry.PDFRenders.Inc("cached")
+		return pdf, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrClosed
+	}
+	j, shared := p.calls[key]
+	if !shared {
+		j = &job{key: key, ctx: ctx, htmlDoc: htmlDoc, enqueued: time.Now(), done: make(chan struct{})}
+		select {
+		case p.jobs <- j:
+			p.calls[key] = j
+			telemetry.PDFRenderQueueDepth.Set(float64(len(p.jobs)))
+		default:
+			p.mu.Unlock()
+			telemetry.PDFRenders.Inc("rejected")
+			return nil, ErrBusy
+		}
+	}
+	p.mu.Unlock()
+	if shared {
+		telemetry.PDFRenders.Inc("shared")
+	}
+
+	select {
+	case <-j.done:
+		return j.pdf, j.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Run prints queued documents until stop is cancelled, then finishes the
+// renders already queued and closes Chrome
+func (p *Pool) Run(stop context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Tabs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.worker()
+		}()
+	}
+
+	<-stop.Done()
+	p.mu.Lock()
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+	wg.Wait()
+
+	p.browserMu.Lock()
+	if p.browser != nil {
+		p.browser.Close()
+		p.browser = nil
+	}
+	p.browserMu.Unlock()
+}
+
+// worker owns one tab and prints on it until the queue is closed
+func (p *Pool) worker() {
+	var t tab
+	uses := 0
+	defer func() {
+		if t != nil {
+			t.Close()
+		}
+	}()
+
+	for j := range p.jobs {
+		telemetry.PDFRenderQueueDepth.Set(float64(len(p.jobs)))
+		telemetry.PDFRenderQueueWait.Observe(time.Since(j.enqueued).Seconds())
+
+		if j.ctx.Err() != nil {
+			telemetry.PDFRenders.Inc("expired")
+			p.finish(j, nil, j.ctx.Err())
+			continue
+		}
+		if t == nil {
+			var err error
+			if t, err = p.openTab(); err != nil {
+				slog.Error("pdf renderer failed to open a tab", "error", err.Error())
+				telemetry.PDFRenders.Inc("failed")
+				p.finish(j, nil, err)
+				continue
+			}
+			uses = 0
+		}
+
+		telemetry.PDFRenderTabsBusy.Add(1)
+		start := time.Now()
+		pdf, err := t.Print(j.ctx, j.htmlDoc)
+		telemetry.PDFRenderTabsBusy.Add(-1)
+		uses++
+
+		outcome := "rendered"
+		if err != nil {
+			outcome = "failed"
+			slog.Error("pdf render failed", "error", err.Error())
+		}
+		telemetry.PDFRenders.Inc(outcome)
+		telemetry.PDFRenderDuration.Observe(time.Since(start).Seconds(), outcome)
+		p.finish(j, pdf, err)
+
+		// a tab that failed may be stuck on the document; tabs are also
+		// replaced now and then so a leak in Chrome cannot build up
+		if err != nil || (p.opts.TabReuse > 0 && uses >= p.opts.TabReuse) {
+			t.Close()
+			t = nil
+		}
+	}
+}
+
+// openTab opens a tab, starting Chrome first or again if it is not running
+func (p *Pool) openTab() (tab, error) {
+	p.browserMu.Lock()
+	defer p.browserMu.Unlock()
+	if p.browser != nil {
+		t, err := p.browser.NewTab()
+		if err == nil {
+			return t, nil
+		}
+		slog.Warn("pdf renderer restarting chrome", "error", err.Error())
+		p.browser.Close()
+		p.browser = nil
+	}
+
+	b, err := p.launch()
+	if err != nil {
+		return nil, err
+	}
+	p.browser = b
+	return b.NewTab()
+}
+
+func (p *Pool) finish(j *job, pdf []byte, err error) {
+	if err == nil {
+		p.cache.put(j.key, pdf)
+	}
+	p.mu.Lock()
+	delete(p.calls, j.key)
+	p.mu.Unlock()
+	j.pdf, j.err = pdf, err
+	close(j.done)
+}
@@ -0,0 +1,209 @@
+package pdfrender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBrowser prints a document as "pdf:<html>" and records how it was used
+type fakeBrowser struct {
+	mu       sync.Mutex
+	launches int
+	tabs     int
+	prints   int
+	printing int
+	maxPrint int
+	fail     map[string]bool
+	release  chan struct{}
+}
+
+func (f *fakeBrowser) launch() (browser, error) {
+	f.mu.Lock()
+	f.launches++
+	f.mu.Unlock()
+	return fakeSession{f}, nil
+}
+
+type fakeSession struct{ f *fakeBrowser }
+
+func (s fakeSession) NewTab() (tab, error) {
+	s.f.mu.Lock()
+	s.f.tabs++
+	s.f.mu.Unlock()
+	return fakeTab{s.f}, nil
+}
+
+func (s fakeSession) Close() {}
+
+type fakeTab struct{ f *fakeBrowser }
+
+func (t fakeTab) Print(ctx context.Context, htmlDoc string) ([]byte, error) {
+	f := t.f
+	f.mu.Lock()
+	f.prints++
+	f.printing++
+	if f.printing > f.maxPrint {
+		f.maxPrint = f.printing
+	}
+	fail := f.fail[htmlDoc]
+	release := f.release
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.printing--
+		f.mu.Unlock()
+	}()
+
+	if release != nil {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if fail {
+		return nil, errors.New("tab crashed")
+	}
+	return []byte("pdf:" + htmlDoc), nil
+}
+
+func (t fakeTab) Close() {}
+
+func (f *fakeBrowser) counts() (launches, tabs, prints, maxPrint int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.launches, f.tabs, f.prints, f.maxPrint
+}
+
+func startPool(t *testing.T, opts Options, f *fakeBrowser) *Pool {
+	t.Helper()
+	p := NewPool(opts)
+	p.launch = f.launch
+	stop, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(stop)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return p
+}
+
+func TestPoolReusesTabsAndCachesUnchangedDocuments(t *testing.T) {
+	f := &fakeBrowser{}
+	p := startPool(t, Options{Tabs: 1, QueueSize: 4, Timeout: time.Second, CacheBytes: 1 << 20}, f)
+
+	for _, doc := range []string{"INV-1", "INV-2", "INV-1"} {
+		pdf, err := p.Render(context.Background(), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(pdf) != "pdf:"+doc {
+			t.Fatalf("pdf = %q", pdf)
+		}
+	}
+	if launches, tabs, prints, _ := f.counts(); launches != 1 || tabs != 1 || prints != 2 {
+		t.Fatalf("launches=%d tabs=%d prints=%d, want 1 chrome, 1 tab and 2 prints", launches, tabs, prints)
+	}
+}
+
+func TestPoolBoundsConcurrencyAndSharesIdenticalRenders(t *testing.T) {
+	f := &fakeBrowser{release: make(chan struct{})}
+	p := startPool(t, Options{Tabs: 2, QueueSize: 16, Timeout: 5 * time.Second}, f)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 12)
+	for i := 0; i < 12; i++ {
+		doc := fmt.Sprintf("INV-%d", i%6)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pdf, err := p.Render(context.Background(), doc)
+			if err == nil && string(pdf) != "pdf:"+doc {
+				err = fmt.Errorf("pdf = %q", pdf)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(f.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, prints, maxPrint := f.counts(); maxPrint > 2 || prints > 12 {
+		t.Fatalf("prints=%d at most %d at once, want at most 2 at once", prints, maxPrint)
+	}
+}
+
+func TestPoolRejectsWhenTheQueueIsFull(t *testing.T) {
+	f := &fakeBrowser{release: make(chan struct{})}
+	p := startPool(t, Options{Tabs: 1, QueueSize: 1, Timeout: 5 * time.Second}, f)
+	defer close(f.release)
+
+	go p.Render(context.Background(), "INV-1")
+	waitFor(t, func() bool { _, _, prints, _ := f.counts(); return prints == 1 })
+	go p.Render(context.Background(), "INV-2")
+	waitFor(t, func() bool { return len(p.jobs) == 1 })
+
+	if _, err := p.Render(context.Background(), "INV-3"); !errors.Is(err, ErrBusy) {
+		t.Fatalf("err = %v, want ErrBusy", err)
+	}
+}
+
+func TestPoolReplacesAFailedTab(t *testing.T) {
+	f := &fakeBrowser{fail: map[string]bool{"broken": true}}
+	p := startPool(t, Options{Tabs: 1, QueueSize: 4, Timeout: time.Second}, f)
+
+	if _, err := p.Render(context.Background(), "broken"); err == nil {
+		t.Fatal("expected the render to fail")
+	}
+	if _, err := p.Render(context.Background(), "INV-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, tabs, _, _ := f.counts(); tabs != 2 {
+		t.Fatalf("tabs = %d, want the failed tab replaced", tabs)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCache(10)
+	c.put("a", []byte("1234"))
+	c.put("b", []byte("1234"))
+	c.get("a")
+	c.put("c", []byte("1234"))
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Fatalf("%s should be cached", key)
+		}
+	}
+	c.put("big", make([]byte, 11))
+	if _, ok := c.get("big"); ok {
+		t.Fatal("a PDF larger than the cache should not be stored")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
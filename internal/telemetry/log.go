@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type transactionIDKey struct{}
+
+// WithTransactionID returns a copy of ctx for the request with the given
+// transaction id; log records written with ctx carry it
+func WithTransactionID(ctx context.Context, transactionID string) context.Context {
+	return context.WithValue(ctx, transactionIDKey{}, transactionID)
+}
+
+// TransactionID returns the transaction id set by WithTransactionID
+func TransactionID(ctx context.Context) string {
+	id, _ := ctx.Value(transactionIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the transaction id and trace ids found in the context
+// of a record to its attributes
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := TransactionID(ctx); id != "" {
+			r.AddAttrs(slog.String("transaction_id", id))
+		}
+		if traceID, spanID, ok := traceIDs(ctx); ok {
+			r.AddAttrs(slog.String("trace_id", traceID), slog.String("span_id", spanID))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// NewLogger returns a JSON logger writing to w at the given level (debug,
+// info, warn or error)
+func NewLogger(w io.Writer, level string) *slog.Logger {
+	var lvl slog.Level
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn", "warning":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		lvl = slog.LevelInfo
+	}
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl})})
+}
+
+// SetupLogging makes JSON on stdout the default for slog and for the log
+// package, so existing log.Printf calls become structured records too
+func SetupLogging(service string) {
+	logger := NewLogger(os.Stdout, os.Getenv("LOG_LEVEL")).With("service", service)
+	slog.SetDefault(logger)
+}
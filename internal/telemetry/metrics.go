@@ -0,0 +1,268 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	// HTTPRequestDuration is the latency of API requests by route pattern
+	HTTPRequestDuration = NewHistogram("http_request_duration_seconds", "Latency of HTTP requests.", DefaultBuckets, "method", "route", "status")
+	// DBQueryDuration is the time spent in SQL statements by kind of statement
+	DBQueryDuration = NewHistogram("db_query_duration_seconds", "Latency of SQL statements.", DefaultBuckets, "operation", "outcome")
+	// CronRuns counts scheduled job runs by outcome (success, failure, skipped)
+	CronRuns = NewCounter("cron_runs_total", "Scheduled job runs.", "job", "outcome")
+	// CronRunDuration is how long scheduled jobs take
+	CronRunDuration = NewHistogram("cron_run_duration_seconds", "Duration of scheduled job runs.", []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300}, "job")
+	// WagySends counts WhatsApp messages sent through Wagy by outcome
+	WagySends = NewCounter("wagy_messages_total", "WhatsApp messages sent through Wagy.", "kind", "outcome")
+	// AIToolCalls counts tool calls made by the WhatsApp assistant
+	AIToolCalls = NewCounter("ai_tool_calls_total", "Tool calls made by the AI assistant.", "tool", "outcome")
+	// PDFRenders counts PDF render requests by outcome (rendered, cached, shared, failed, expired, rejected)
+	PDFRenders = NewCounter("pdf_renders_total", "PDF render requests.", "outcome")
+	// PDFRenderDuration is how long Chrome takes to print a document
+	PDFRenderDuration = NewHistogram("pdf_render_duration_seconds", "Duration of PDF renders.", []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}, "outcome")
+	// PDFRenderQueueWait is how long a render waits for a free tab
+	PDFRenderQueueWait = NewHistogram("pdf_render_queue_wait_seconds", "Time PDF renders wait in the queue.", DefaultBuckets)
+	// PDFRenderQueueDepth is the number of renders waiting for a free tab
+	PDFRenderQueueDepth = NewGauge("pdf_render_queue_depth", "PDF renders waiting in the queue.")
+	// PDFRenderTabsBusy is the number of Chrome tabs printing a document
+	PDFRenderTabsBusy = NewGauge("pdf_render_tabs_busy", "Chrome tabs printing a PDF.")
+)
+
+type metric interface {
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]metric{}
+)
+
+func register(name string, m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("telemetry: metric " + name + " registered twice")
+	}
+	registry[name] = m
+}
+
+// WritePrometheus writes every metric in the Prometheus text format
+func WritePrometheus(w io.Writer) {
+	registryMu.Lock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	metrics := make([]metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, registry[name])
+	}
+	registryMu.Unlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// Counter is a monotonically increasing count split by label values
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter registers a counter with the given label names
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, values: map[string]float64{}}
+	register(name, c)
+	return c
+}
+
+// Inc adds one for the given label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v for the given label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	key := labelKey(c.labels, labelValues)
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, braces(key), formatFloat(c.values[key]))
+	}
+}
+
+// Gauge is a value that goes up and down split by label values
+type Gauge struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewGauge registers a gauge with the given label names
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{name: name, help: help, labels: labels, values: map[string]float64{}}
+	register(name, g)
+	return g
+}
+
+// Set sets the value for the given label values
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	key := labelKey(g.labels, labelValues)
+	g.mu.Lock()
+	g.values[key] = v
+	g.mu.Unlock()
+}
+
+// Add adds v, which may be negative, for the given label values
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	key := labelKey(g.labels, labelValues)
+	g.mu.Lock()
+	g.values[key] += v
+	g.mu.Unlock()
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+	for _, key := range sortedKeys(g.values) {
+		fmt.Fprintf(w, "%s%s %s\n", g.name, braces(key), formatFloat(g.values[key]))
+	}
+}
+
+// Histogram counts observations into cumulative buckets split by label values
+type Histogram struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram with the given upper bounds and label names
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+	register(name, h)
+	return h
+}
+
+// Observe records v for the given label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := labelKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, braces(joinLabels(key, `le="`+formatFloat(bound)+`"`)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, braces(joinLabels(key, `le="+Inf"`)), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, braces(key), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, braces(key), s.count)
+	}
+}
+
+// labelKey renders label pairs as they appear between the braces; missing
+// values are written as empty strings
+func labelKey(names, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs[i] = name + `="` + escapeLabel(value) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func joinLabels(key, extra string) string {
+	if key == "" {
+		return extra
+	}
+	return key + "," + extra
+}
+
+func braces(key string) string {
+	if key == "" {
+		return ""
+	}
+	return "{" + key + "}"
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
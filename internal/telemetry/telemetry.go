@@ -0,0 +1,34 @@
+// Package telemetry holds the structured logger, the Prometheus metrics and
+// the trace spans of the API, the cron jobs and the WhatsApp assistant.
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup configures JSON logging and, when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, exporting spans to that
+// collector. The returned function flushes the spans still queued.
+func Setup(service string) func(context.Context) error {
+	if name := strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")); name != "" {
+		service = name
+	}
+	SetupLogging(service)
+
+	url := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+	if url == "" {
+		if endpoint := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")); endpoint != "" {
+			url = strings.TrimRight(endpoint, "/") + "/v1/traces"
+		}
+	}
+	if url == "" {
+		return func(context.Context) error { return nil }
+	}
+
+	exporter := StartExporter(url, service)
+	slog.Info("exporting traces", "url", url)
+	return exporter.Shutdown
+}
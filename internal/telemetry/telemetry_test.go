@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWritePrometheusFormatsCountersGaugesAndHistograms(t *testing.T) {
+	counter := NewCounter("test_events_total", "Test events.", "kind")
+	counter.Inc("a")
+	counter.Add(2, `b"x`)
+	gauge := NewGauge("test_queue_depth", "Test queue depth.")
+	gauge.Set(4)
+	gauge.Add(-1)
+	histogram := NewHistogram("test_latency_seconds", "Test latency.", []float64{0.1, 1}, "route")
+	histogram.Observe(0.05, "/x")
+	histogram.Observe(0.5, "/x")
+	histogram.Observe(3, "/x")
+
+	var buf bytes.Buffer
+	WritePrometheus(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE test_events_total counter\n",
+		`test_events_total{kind="a"} 1` + "\n",
+		`test_events_total{kind="b\"x"} 2` + "\n",
+		"# TYPE test_queue_depth gauge\n",
+		"test_queue_depth 3\n",
+		"# TYPE test_latency_seconds histogram\n",
+		`test_latency_seconds_bucket{route="/x",le="0.1"} 1` + "\n",
+		`test_latency_seconds_bucket{route="/x",le="1"} 2` + "\n",
+		`test_latency_seconds_bucket{route="/x",le="+Inf"} 3` + "\n",
+		`test_latency_seconds_sum{route="/x"} 3.55` + "\n",
+		`test_latency_seconds_count{route="/x"} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("exposition is missing %q", want)
+		}
+	}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ContextWithTraceparent(context.Background(), header)
+	if got := Traceparent(ctx); got != header {
+		t.Fatalf("Traceparent = %q, want %q", got, header)
+	}
+
+	ctx, span := StartSpan(ctx, "child", SpanKindInternal)
+	if span.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("child span trace id = %s", span.TraceID())
+	}
+	if hexParent := Traceparent(ctx); !strings.HasPrefix(hexParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || hexParent == header {
+		t.Fatalf("child traceparent = %q", hexParent)
+	}
+
+	for _, invalid := range []string{"", "garbage", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4bf92f35-00f067aa0ba902b7-01"} {
+		if InTrace(ContextWithTraceparent(context.Background(), invalid)) {
+			t.Errorf("traceparent %q should be ignored", invalid)
+		}
+	}
+}
+
+func TestExporterSendsOTLPJSON(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		payload otlpRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("collector got invalid JSON: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	exporter := StartExporter(server.URL, "travego-test")
+	defer exporter.Shutdown(context.Background())
+
+	ctx, parent := StartSpan(context.Background(), "GET /api/items", SpanKindServer)
+	_, child := StartSpan(ctx, "db.select", SpanKindClient)
+	child.SetAttribute("db.rows", 3)
+	child.RecordError(io.ErrUnexpectedEOF)
+	child.End()
+	parent.End()
+	exporter.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payload.ResourceSpans) != 1 || len(payload.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected payload shape: %+v", payload)
+	}
+	if got := payload.ResourceSpans[0].Resource.Attributes[0].Value["stringValue"]; got != "travego-test" {
+		t.Errorf("service.name = %v", got)
+	}
+	spans := payload.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	db, root := spans[0], spans[1]
+	if db.Name != "db.select" || db.ParentSpanID != root.SpanID || db.TraceID != root.TraceID {
+		t.Errorf("child span not linked to its parent: %+v / %+v", db, root)
+	}
+	if db.Status.Code != 2 || root.Status.Code != 0 {
+		t.Errorf("status codes = %d, %d", db.Status.Code, root.Status.Code)
+	}
+}
+
+func TestLoggerAddsTransactionAndTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "info")
+
+	ctx := WithTransactionID(context.Background(), "TX-1")
+	ctx, span := StartSpan(ctx, "test", SpanKindInternal)
+	logger.InfoContext(ctx, "hello", "n", 1)
+	logger.DebugContext(ctx, "hidden")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &record); err != nil {
+		t.Fatalf("expected exactly one JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["msg"] != "hello" || record["transaction_id"] != "TX-1" || record["trace_id"] != span.TraceID() {
+		t.Errorf("unexpected record %v", record)
+	}
+}
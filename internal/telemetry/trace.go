@@ -0,0 +1,165 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKind follows the OpenTelemetry span kinds
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+type spanKey struct{}
+
+// spanRef identifies the current span in a context, local or remote
+type spanRef struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+// Span is one timed operation of a trace. A nil Span is valid and does
+// nothing, so callers never need to check.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     SpanKind
+	start    time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes []attribute
+	errMessage string
+	ended      bool
+}
+
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+// StartSpan starts a span as a child of the span in ctx, or a new trace when
+// there is none, and returns a context carrying it
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{name: name, kind: kind, start: time.Now()}
+	if parent, ok := ctx.Value(spanKey{}).(spanRef); ok {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(span.traceID[:])
+	}
+	_, _ = rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, spanRef{traceID: span.traceID, spanID: span.spanID}), span
+}
+
+// SetName renames the span, e.g. once the route of a request is known
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.name = name
+	s.mu.Unlock()
+}
+
+// SetAttribute adds a string, bool, integer or float attribute to the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attributes = append(s.attributes, attribute{key: key, value: value})
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.errMessage = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export; later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	if e := currentExporter(); e != nil {
+		e.enqueue(s)
+	}
+}
+
+// TraceID is the hex trace id of the span
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// InTrace reports whether ctx carries a span to start children of
+func InTrace(ctx context.Context) bool {
+	_, ok := ctx.Value(spanKey{}).(spanRef)
+	return ok
+}
+
+// ContextWithTraceparent continues the trace of a W3C traceparent header
+// (version-traceid-spanid-flags); an invalid header is ignored
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ctx
+	}
+	var ref spanRef
+	if _, err := hex.Decode(ref.traceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(ref.spanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+	if ref.traceID == [16]byte{} || ref.spanID == [8]byte{} {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, ref)
+}
+
+// Traceparent renders the span in ctx as a W3C traceparent header, empty
+// when there is none
+func Traceparent(ctx context.Context) string {
+	ref, ok := ctx.Value(spanKey{}).(spanRef)
+	if !ok {
+		return ""
+	}
+	return "00-" + hex.EncodeToString(ref.traceID[:]) + "-" + hex.EncodeToString(ref.spanID[:]) + "-01"
+}
+
+// traceIDs returns the hex trace and span id in ctx for log records
+func traceIDs(ctx context.Context) (string, string, bool) {
+	ref, ok := ctx.Value(spanKey{}).(spanRef)
+	if !ok {
+		return "", "", false
+	}
+	return hex.EncodeToString(ref.traceID[:]), hex.EncodeToString(ref.spanID[:]), true
+}
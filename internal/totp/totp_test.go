@@ -0,0 +1,64 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 appendix B, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; the last 6 digits are the 6 digit code
+	vectors := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, v := range vectors {
+		got, err := Code(rfcSecret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", v.unix, err)
+		}
+		if got != v.want {
+			t.Errorf("Code(%d) = %s, want %s", v.unix, got, v.want)
+		}
+	}
+}
+
+func TestValidateAcceptsAdjacentStepOnly(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	prev, _ := Code(rfcSecret, now.Add(-Period*time.Second))
+	if step, ok := Validate(rfcSecret, prev, now); !ok || step != Step(now)-1 {
+		t.Fatalf("expected previous step code to validate, got step %d ok %v", step, ok)
+	}
+
+	old, _ := Code(rfcSecret, now.Add(-3*Period*time.Second))
+	if _, ok := Validate(rfcSecret, old, now); ok {
+		t.Fatal("expected a code three steps old to be rejected")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now); ok {
+		t.Fatal("expected a short code to be rejected")
+	}
+}
+
+func TestGenerateSecretRoundTrips(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(strings.ToLower(secret), code, time.Now()); !ok {
+		t.Fatal("expected a code for a generated secret to validate")
+	}
+}
@@ -7,13 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"service-travego/configs"
+	"service-travego/database"
+	"service-travego/helper"
+	"service-travego/internal/telemetry"
 	"service-travego/internal/wagy"
 	"service-travego/model"
 	"service-travego/repository"
@@ -58,6 +61,8 @@ type AIClient struct {
 	inventoryService      *service.InventoryService
 	garageService         *service.GarageService
 	printService          *service.PrintManagementService
+	cancellationService   *service.CancellationPolicyService
+	outbox                *service.OutboxService
 	wagyClient            *wagy.WagyClient
 }
 
@@ -121,7 +126,31 @@ func NewAIClient(apiKey string, db *sql.DB, dbDriver string, rdb *redis.Client,
 		SMTPPort: os.Getenv("EMAIL_SMTP_PORT"),
 	}
 
-	notificationSvc := service.NewNotificationService(db, dbDriver)
+	notificationSvc := service.NewNotificationService(repository.NewNotificationRepository(db, dbDriver))
+	// Changes made through the assistant land in the organization's audit log
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(db, dbDriver))
+
+	// Orders placed through the assistant are priced with the organization's pricing rules
+	priceRuleService := service.NewPriceRuleService(repository.NewPriceRuleRepository(db, dbDriver), fleetRepo)
+	fleetService := service.NewFleetService(fleetRepo)
+	fleetService.SetPriceRuleService(priceRuleService)
+	fleetService.SetAuditService(auditService)
+	organizationService := service.NewOrganizationService(orgRepo, userRepo)
+	organizationService.SetAuditService(auditService)
+	scheduleService := service.NewScheduleService(scheduleRepo)
+	scheduleService.SetAuditService(auditService)
+	transactionService := service.NewTransactionService(transactionRepo, notificationSvc)
+	transactionService.SetAuditService(auditService)
+	inventoryService := service.NewInventoryService(inventoryRepo, notificationSvc)
+	inventoryService.SetAuditService(auditService)
+	// Notifications of the assistant go through the outbox like the rest of the API
+	outbox := service.NewOutboxService(repository.NewOutboxRepository(db, dbDriver))
+	orderService := service.NewOrderService(fleetRepo, contentRepo, orgRepo, emailCfg)
+	orderService.SetOutboxService(outbox)
+	orderService.SetPriceRuleService(priceRuleService)
+	orderService.SetVoucherService(service.NewVoucherService(repository.NewVoucherRepository(db, dbDriver)))
+	// and split over the organization's default payment plan
+	orderService.SetPaymentPlanService(service.NewPaymentPlanService(repository.NewPaymentPlanRepository(db, dbDriver)))
 
 	return &AIClient{
 		apiKey: apiKey,
@@ -140,19 +169,21 @@ func NewAIClient(apiKey string, db *sql.DB, dbDriver string, rdb *redis.Client,
 		tenantRepo:            NewTenantRepository(db, dbDriver, authMgr),
 		sessionMgr:            NewSessionManager(rdb),
 		toolExec:              NewToolExecutor(db, dbDriver),
-		fleetService:          service.NewFleetService(fleetRepo),
+		fleetService:          fleetService,
 		fleetUnitService:      service.NewFleetUnitService(fleetUnitRepo, partnerRepo, orgRepo),
 		generalService:        service.NewGeneralService("config/general-config.json", "config/web-menu.json", "config/location.json", generalRepo),
 		preferenceCityService: service.NewPreferenceCityService(preferenceCityRepo, "config/location.json"),
 		customersService:      service.NewCustomersService(customersRepo),
-		organizationService:   service.NewOrganizationService(orgRepo, userRepo),
-		scheduleService:       service.NewScheduleService(scheduleRepo),
-		orderService:          service.NewOrderService(fleetRepo, contentRepo, orgRepo, emailCfg),
+		organizationService:   organizationService,
+		scheduleService:       scheduleService,
+		orderService:          orderService,
 		dashboardService:      service.NewDashboardService(dashboardRepo),
-		transactionService:    service.NewTransactionService(transactionRepo, notificationSvc),
-		inventoryService:      service.NewInventoryService(inventoryRepo, notificationSvc),
+		transactionService:    transactionService,
+		inventoryService:      inventoryService,
 		garageService:         service.NewGarageService(garageRepo),
 		printService:          service.NewPrintManagementService(printRepo),
+		cancellationService:   service.NewCancellationPolicyService(repository.NewCancellationPolicyRepository(db, dbDriver), fleetRepo),
+		outbox:                outbox,
 		wagyClient:            wagyClient,
 	}
 }
@@ -205,7 +236,7 @@ func (ac *AIClient) ProcessMessage(ctx context.Context, phone, incomingMessage s
 		if retErr != nil {
 			status = 0
 		}
-		insertAssistantAccountStat(ac.db, ac.driver, statOrganizationID, 1, status)
+		insertAssistantAccountStat(ctx, ac.db, ac.driver, 1, status)
 	}()
 
 	if err == nil {
@@ -214,7 +245,7 @@ func (ac *AIClient) ProcessMessage(ctx context.Context, phone, incomingMessage s
 		ctx = withAuthorizedTenantContext(ctx, tenant)
 
 		// Get business snapshot
-		snapshot, err = ac.tenantRepo.GetOrganizationSnapshot(ctx, tenant.OrganizationID)
+		snapshot, err = ac.tenantRepo.GetOrganizationSnapshot(ctx)
 		if err != nil {
 			snapshot = map[string]interface{}{} // Use empty snapshot if error
 		}
@@ -225,7 +256,7 @@ func (ac *AIClient) ProcessMessage(ctx context.Context, phone, incomingMessage s
 		}
 	} else {
 		// Tenant not found - handle as guest
-		log.Printf("[WAAI][AI] Processing message for unregistered phone: %s", phone)
+		slog.InfoContext(ctx, "assistant ai: processing message for unregistered phone", "phone", phone)
 		tenant = &TenantInfo{
 			Phone: phone,
 			Role:  "Guest",
@@ -276,7 +307,7 @@ func (ac *AIClient) ProcessMessage(ctx context.Context, phone, incomingMessage s
 	err = ac.sessionMgr.SaveSession(ctx, phone, history)
 	if err != nil {
 		// Log but don't fail - message should still be sent
-		fmt.Printf("Warning: failed to save session: %v\n", err)
+		slog.WarnContext(ctx, "assistant ai: failed to save session", "error", err)
 	}
 
 	return finalResponse, nil
@@ -291,7 +322,7 @@ func (ac *AIClient) callAnthropicWithTools(ctx context.Context, systemPrompt str
 		if err != nil {
 			return "", err
 		}
-		log.Printf("[WAAI][AI] Iteration %d stop_reason=%s content=%s", i+1, response.StopReason, summarizeAnthropicContent(response.Content))
+		slog.InfoContext(ctx, "assistant ai: iteration", "iteration", i+1, "stop_reason", response.StopReason, "content", summarizeAnthropicContent(response.Content))
 
 		hasToolUse := false
 		textResponse := ""
@@ -365,7 +396,7 @@ func (ac *AIClient) callAnthropicWithTools(ctx context.Context, systemPrompt str
 	// Force one final pass without tools so the model must answer with text.
 	finalResponse, err := ac.callAnthropicFinal(ctx, systemPrompt, messages)
 	if err == nil {
-		log.Printf("[WAAI][AI] Final no-tools pass stop_reason=%s content=%s", finalResponse.StopReason, summarizeAnthropicContent(finalResponse.Content))
+		slog.InfoContext(ctx, "assistant ai: final no-tools pass", "stop_reason", finalResponse.StopReason, "content", summarizeAnthropicContent(finalResponse.Content))
 		for _, content := range finalResponse.Content {
 			if content.Type == "text" && content.Text != "" {
 				return content.Text, nil
@@ -429,7 +460,7 @@ func (ac *AIClient) callAnthropicWithCompanyTools(ctx context.Context, systemPro
 		if retErr != nil {
 			status = 0
 		}
-		insertAssistantCustomerStat(ac.db, ac.driver, orgID, 1, status)
+		insertAssistantCustomerStat(ctx, ac.db, ac.driver, 1, status)
 	}()
 
 	for i := 0; i < 5; i++ {
@@ -438,7 +469,7 @@ func (ac *AIClient) callAnthropicWithCompanyTools(ctx context.Context, systemPro
 			retErr = err
 			return "", messages, retErr
 		}
-		log.Printf("[WAAI][Company] Iteration %d stop_reason=%s content=%s", i+1, response.StopReason, summarizeAnthropicContent(response.Content))
+		slog.InfoContext(ctx, "assistant company: iteration", "iteration", i+1, "stop_reason", response.StopReason, "content", summarizeAnthropicContent(response.Content))
 
 		hasToolUse := false
 		textResponse := ""
@@ -487,15 +518,15 @@ func (ac *AIClient) callAnthropicWithCompanyTools(ctx context.Context, systemPro
 				continue
 			}
 
-			log.Printf("[WAAI][Company] Executing tool name=%s input=%s", content.Name, truncateResponseBody(content.Input))
+			slog.InfoContext(ctx, "assistant company: executing tool", "name", content.Name, "input", truncateResponseBody(content.Input))
 			toolResult := ac.executeTool(ctx, content.Name, content.Input)
 			formattedToolResult := formatToolResult(toolResult)
-			log.Printf("[WAAI][Company] Tool result name=%s output=%s", content.Name, truncateResponseBody([]byte(formattedToolResult)))
+			slog.InfoContext(ctx, "assistant company: tool result", "name", content.Name, "output", truncateResponseBody([]byte(formattedToolResult)))
 			toolContextNotes = append(toolContextNotes, fmt.Sprintf("Tool %s result: %s", content.Name, formattedToolResult))
 			if content.Name == "create_order" {
 				createOrderSucceeded, createOrderFailed, createOrderMissing, createOrderError, createOrderID = analyzeCreateOrderToolResult(toolResult)
 				if createOrderSucceeded && !createdOrderStatusKnown && strings.TrimSpace(createOrderID) != "" && ac != nil && ac.fleetService != nil {
-					if detail, err := ac.fleetService.GetPartnerOrderDetail(strings.TrimSpace(createOrderID), orgID); err == nil && detail != nil {
+					if detail, err := ac.fleetService.GetPartnerOrderDetail(ctx, strings.TrimSpace(createOrderID)); err == nil && detail != nil {
 						createdOrderStatus = detail.Status
 						createdOrderStatusKnown = true
 					}
@@ -566,7 +597,7 @@ func (ac *AIClient) callAnthropicWithCompanyTools(ctx context.Context, systemPro
 					toolUsed = true
 					createOrderSucceeded, createOrderFailed, createOrderMissing, createOrderError, createOrderID = analyzeCreateOrderToolResult(forcedResult)
 					if createOrderSucceeded && !createdOrderStatusKnown && strings.TrimSpace(createOrderID) != "" && ac != nil && ac.fleetService != nil {
-						if detail, err := ac.fleetService.GetPartnerOrderDetail(strings.TrimSpace(createOrderID), orgID); err == nil && detail != nil {
+						if detail, err := ac.fleetService.GetPartnerOrderDetail(ctx, strings.TrimSpace(createOrderID)); err == nil && detail != nil {
 							createdOrderStatus = detail.Status
 							createdOrderStatusKnown = true
 						}
@@ -586,7 +617,7 @@ func (ac *AIClient) callAnthropicWithCompanyTools(ctx context.Context, systemPro
 					toolUsed = true
 					createOrderSucceeded, createOrderFailed, createOrderMissing, createOrderError, createOrderID = analyzeCreateOrderToolResult(forcedResult)
 					if createOrderSucceeded && !createdOrderStatusKnown && strings.TrimSpace(createOrderID) != "" && ac != nil && ac.fleetService != nil {
-						if detail, err := ac.fleetService.GetPartnerOrderDetail(strings.TrimSpace(createOrderID), orgID); err == nil && detail != nil {
+						if detail, err := ac.fleetService.GetPartnerOrderDetail(ctx, strings.TrimSpace(createOrderID)); err == nil && detail != nil {
 							createdOrderStatus = detail.Status
 							createdOrderStatusKnown = true
 						}
@@ -790,7 +821,7 @@ func (ac *AIClient) callAnthropicWithCompanyTools(ctx context.Context, systemPro
 
 	finalAnthropicResponse, err := ac.callAnthropicFinal(ctx, systemPrompt, finalMessages)
 	if err == nil {
-		log.Printf("[WAAI][Company] Final no-tools pass stop_reason=%s content=%s", finalAnthropicResponse.StopReason, summarizeAnthropicContent(finalAnthropicResponse.Content))
+		slog.InfoContext(ctx, "assistant company: final no-tools pass", "stop_reason", finalAnthropicResponse.StopReason, "content", summarizeAnthropicContent(finalAnthropicResponse.Content))
 		for _, content := range finalAnthropicResponse.Content {
 			if content.Type == "text" && content.Text != "" {
 				return content.Text, finalMessages, nil
@@ -963,7 +994,7 @@ func (ac *AIClient) callGeminiRequest(ctx context.Context, systemPrompt string,
 		httpResp, err := client.Do(httpReq)
 		if err != nil {
 			lastErr = fmt.Errorf("model %s: failed to send gemini request: %w", modelName, err)
-			log.Printf("[WAAI][Gemini] Request failed for model=%s: %v", modelName, err)
+			slog.ErrorContext(ctx, "assistant gemini: request failed", "model", modelName, "error", err)
 			continue
 		}
 
@@ -971,27 +1002,27 @@ func (ac *AIClient) callGeminiRequest(ctx context.Context, systemPrompt string,
 		_ = httpResp.Body.Close()
 		if readErr != nil {
 			lastErr = fmt.Errorf("model %s: failed to read gemini response: %w", modelName, readErr)
-			log.Printf("[WAAI][Gemini] Failed reading response for model=%s: %v", modelName, readErr)
+			slog.ErrorContext(ctx, "assistant gemini: failed reading response", "model", modelName, "error", readErr)
 			continue
 		}
 
-		log.Printf("[WAAI][Gemini] Raw response model=%s status=%d body=%s", modelName, httpResp.StatusCode, truncateResponseBody(respBody))
+		slog.InfoContext(ctx, "assistant gemini: raw response", "model", modelName, "status", httpResp.StatusCode, "body", truncateResponseBody(respBody))
 
 		if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
 			lastErr = fmt.Errorf("model %s: gemini error (%d): %s", modelName, httpResp.StatusCode, truncateResponseBody(respBody))
-			log.Printf("[WAAI][Gemini] Falling back from model=%s because status=%d", modelName, httpResp.StatusCode)
+			slog.InfoContext(ctx, "assistant gemini: falling back", "model", modelName, "status", httpResp.StatusCode)
 			continue
 		}
 
 		response, parseErr := parseGeminiResponse(respBody)
 		if parseErr != nil {
 			lastErr = fmt.Errorf("model %s: failed to parse gemini response: %w", modelName, parseErr)
-			log.Printf("[WAAI][Gemini] Failed parsing response for model=%s: %v", modelName, parseErr)
+			slog.ErrorContext(ctx, "assistant gemini: failed parsing response", "model", modelName, "error", parseErr)
 			continue
 		}
 
 		if modelName != ac.model {
-			log.Printf("[WAAI][Gemini] Using fallback model=%s", modelName)
+			slog.InfoContext(ctx, "assistant gemini: using fallback model", "model", modelName)
 		}
 
 		return response, nil
@@ -1200,7 +1231,7 @@ func (ac *AIClient) callAnthropicRequest(ctx context.Context, systemPrompt strin
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
-	log.Printf("[WAAI][AI] Raw response status=%d body=%s", httpResp.StatusCode, truncateResponseBody(respBody))
+	slog.InfoContext(ctx, "assistant ai: raw response", "status", httpResp.StatusCode, "body", truncateResponseBody(respBody))
 
 	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
 		errResponse, err := parseAnthropicResponse(respBody)
@@ -1683,6 +1714,7 @@ Panduan penggunaan tool:
 - Harga sewa -> jika armada belum jelas tanyakan armada dulu; setelah ada fleet_id dan jenis layanan, panggil get_fleet_prices
 - Ketersediaan armada -> butuh start_date dan end_date; setelah lengkap panggil get_fleet_availability
 - Lacak pesanan, detail pesanan, status pembayaran, invoice -> gunakan get_order_list, get_order_detail, atau print_invoice sesuai kebutuhan
+- Pembatalan pesanan -> panggil get_cancellation_refund dan sampaikan estimasi refund; setelah customer menyebut alasan dan menegaskan ingin membatalkan, panggil request_order_cancellation. Sampaikan bahwa pembatalan menunggu persetujuan tim, jangan bilang pesanan sudah dibatalkan
 - Buat pesanan baru -> kumpulkan data wajib, cek harga/tool terkait, lalu panggil create_order
 - Jika get_order_list mengembalikan lebih dari 1 pesanan dan customer belum menyebut order_id, ajukan tepat satu pertanyaan klarifikasi untuk meminta order_id yang dimaksud (atau tanggal sewa).
 - Jika ada pesanan customer dengan status = 2 (belum dikonfirmasi), jangan kirim nomor rekening. Sampaikan bahwa pembayaran dapat dilakukan setelah pesanan selesai ditinjau dan dikonfirmasi oleh tim.
@@ -2356,7 +2388,27 @@ func collapseRepeatedLetters(text string) string {
 	}
 	return b.String()
 }
+
+// executeTool runs a tool call inside a trace span and counts it by outcome
 func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json.RawMessage) interface{} {
+	ctx, span := telemetry.StartSpan(ctx, "ai.tool."+toolName, telemetry.SpanKindInternal)
+	defer span.End()
+	span.SetAttribute("ai.tool", toolName)
+
+	result := ac.runTool(ctx, toolName, input)
+
+	outcome := "success"
+	if resultMap, ok := result.(map[string]interface{}); ok {
+		if errValue, failed := resultMap["error"]; failed {
+			outcome = "error"
+			span.RecordError(fmt.Errorf("%v", errValue))
+		}
+	}
+	telemetry.AIToolCalls.Inc(toolName, outcome)
+	return result
+}
+
+func (ac *AIClient) runTool(ctx context.Context, toolName string, input json.RawMessage) interface{} {
 	// Parse input parameters
 	var params map[string]interface{}
 	_ = json.Unmarshal(input, &params)
@@ -2372,7 +2424,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	switch toolName {
 	case "get_business_snapshot":
-		return ac.toolExec.ExecuteGetBusinessSnapshot(ctx, orgID)
+		return ac.toolExec.ExecuteGetBusinessSnapshot(ctx)
 
 	case "get_fleet_availability":
 		startStr := getStringParam(params, "start_date", "date_start")
@@ -2385,7 +2437,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			return map[string]interface{}{"error": err.Error()}
 		}
 		fleetID := getStringParam(params, "fleet_id")
-		available, fleets, err := ac.fleetService.GetFleetAvailibility(orgID, startDate, endDate, fleetID)
+		available, fleets, err := ac.fleetService.GetFleetAvailibility(ctx, startDate, endDate, fleetID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2403,7 +2455,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			FleetEngine:    getStringParam(params, "fleet_engine"),
 		}
 		req.PickupLocation = getIntParam(params, "pickup_location")
-		items, err := ac.fleetService.ListFleets(req)
+		items, err := ac.fleetService.ListFleets(ctx, req)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2413,7 +2465,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 				fleetIDs = append(fleetIDs, items[i].FleetID)
 			}
 		}
-		ratings, err := ac.fleetService.GetFleetRatings(orgID, fleetIDs)
+		ratings, err := ac.fleetService.GetFleetRatings(ctx, fleetIDs)
 		if err == nil {
 			for i := range items {
 				if v, ok := ratings[items[i].FleetID]; ok {
@@ -2429,18 +2481,18 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if fleetID == "" {
 			return map[string]interface{}{"error": "fleet_id is required"}
 		}
-		res, err := ac.fleetService.GetFleetDetail(orgID, fleetID)
+		res, err := ac.fleetService.GetFleetDetail(ctx, fleetID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
-		ratings, err := ac.fleetService.GetFleetRatings(orgID, []string{fleetID})
+		ratings, err := ac.fleetService.GetFleetRatings(ctx, []string{fleetID})
 		if err == nil {
 			if v, ok := ratings[fleetID]; ok {
 				res.Meta.Rating = v.Rating
 				res.Meta.TotalUlasan = v.TotalUlasan
 			}
 		}
-		reviews, err := ac.fleetService.GetFleetReviews(fleetID, orgID)
+		reviews, err := ac.fleetService.GetFleetReviews(ctx, fleetID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2451,7 +2503,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		return out
 
 	case "get_fleet_units":
-		return ac.getFleetUnits(orgID, params)
+		return ac.getFleetUnits(ctx, params)
 
 	case "get_city_list":
 		provinceID, provinceName := normalizeCityFilters(getStringParam(params, "province_id"), getStringParam(params, "province"))
@@ -2464,7 +2516,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	case "get_preference_cities":
 		cityID := getOptionalIntParam(params, "city_id")
-		items, err := ac.preferenceCityService.GetAll(orgID, cityID)
+		items, err := ac.preferenceCityService.GetAll(ctx, cityID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2472,7 +2524,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	case "get_customer_list":
 		customerName := getStringParam(params, "customer_name")
-		items, err := ac.customersService.ListCustomers(orgID, customerName)
+		items, err := ac.customersService.ListCustomers(ctx, customerName)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2483,14 +2535,14 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if customerID == "" {
 			return map[string]interface{}{"error": "customer_id is required"}
 		}
-		data, err := ac.customersService.GetCustomerDetail(orgID, customerID)
+		data, err := ac.customersService.GetCustomerDetail(ctx, customerID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 		return data
 
 	case "get_inventory_items":
-		items, err := ac.inventoryService.GetItems(orgID, 0)
+		items, err := ac.inventoryService.GetItems(ctx, 0)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2523,11 +2575,11 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if itemID == "" {
 			return map[string]interface{}{"error": "item_id is required"}
 		}
-		if _, err := ac.inventoryService.GetItem(orgID, itemID); err != nil {
+		if _, err := ac.inventoryService.GetItem(ctx, itemID); err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 
-		detail, err := ac.inventoryService.GetItemDetail(itemID)
+		detail, err := ac.inventoryService.GetItemDetail(ctx, itemID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2552,11 +2604,11 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if itemID == "" {
 			return map[string]interface{}{"error": "item_id is required"}
 		}
-		if _, err := ac.inventoryService.GetItem(orgID, itemID); err != nil {
+		if _, err := ac.inventoryService.GetItem(ctx, itemID); err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 
-		detail, err := ac.inventoryService.GetItemDetail(itemID)
+		detail, err := ac.inventoryService.GetItemDetail(ctx, itemID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2598,14 +2650,14 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	case "get_garage_list":
 		itemID := getStringParam(params, "item_id")
-		garages, err := ac.garageService.GetGarages(orgID, itemID)
+		garages, err := ac.garageService.GetGarages(ctx, itemID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 		return garages
 
 	case "get_item_suppliers":
-		suppliers, err := ac.inventoryService.GetSuppliers(orgID)
+		suppliers, err := ac.inventoryService.GetSuppliers(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2616,7 +2668,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if itemID == "" {
 			return map[string]interface{}{"error": "item_id is required"}
 		}
-		movements, err := ac.inventoryService.GetItemMovements(orgID, itemID, getStringParam(params, "start_date"), getStringParam(params, "end_date"), getStringParam(params, "garage_id"))
+		movements, err := ac.inventoryService.GetItemMovements(ctx, itemID, getStringParam(params, "start_date"), getStringParam(params, "end_date"), getStringParam(params, "garage_id"))
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2630,7 +2682,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if itemID == "" {
 			return map[string]interface{}{"error": "item_id is required"}
 		}
-		history, err := ac.inventoryService.GetItemOrderHistory(orgID, itemID, getStringParam(params, "start_date"), getStringParam(params, "end_date"))
+		history, err := ac.inventoryService.GetItemOrderHistory(ctx, itemID, getStringParam(params, "start_date"), getStringParam(params, "end_date"))
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2644,7 +2696,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if itemID == "" {
 			return map[string]interface{}{"error": "item_id is required"}
 		}
-		detail, err := ac.inventoryService.GetItemDetail(itemID)
+		detail, err := ac.inventoryService.GetItemDetail(ctx, itemID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2665,7 +2717,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		}
 
 	case "get_purchase_order_list":
-		orders, err := ac.inventoryService.GetOrders(orgID)
+		orders, err := ac.inventoryService.GetOrders(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2679,7 +2731,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if purchaseID == "" {
 			return map[string]interface{}{"error": "purchase_id is required"}
 		}
-		order, err := ac.inventoryService.GetOrder(purchaseID, orgID)
+		order, err := ac.inventoryService.GetOrder(ctx, purchaseID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2690,7 +2742,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if purchaseID == "" {
 			return map[string]interface{}{"error": "purchase_id is required"}
 		}
-		if err := ac.inventoryService.ReceiveRequest(orgID, userID, &model.ReceiveInventoryOrderRequest{PurchaseID: purchaseID}); err != nil {
+		if err := ac.inventoryService.ReceiveRequest(ctx, userID, &model.ReceiveInventoryOrderRequest{PurchaseID: purchaseID}); err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 		return map[string]interface{}{
@@ -2704,7 +2756,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if purchaseID == "" {
 			return map[string]interface{}{"error": "purchase_id is required"}
 		}
-		if err := ac.inventoryService.CancelOrder(orgID, userID, purchaseID); err != nil {
+		if err := ac.inventoryService.CancelOrder(ctx, userID, purchaseID); err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 		return map[string]interface{}{
@@ -2731,19 +2783,19 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if limit := getIntParam(params, "limit"); limit > 0 {
 			limitVal = limit
 		}
-		return ac.toolExec.ExecuteGetBookingList(ctx, orgID, status, limitVal)
+		return ac.toolExec.ExecuteGetBookingList(ctx, status, limitVal)
 
 	case "get_revenue_summary":
 		period := getStringParam(params, "period")
 		if period == "" {
 			return map[string]interface{}{"error": "period is required"}
 		}
-		return ac.toolExec.ExecuteGetRevenueSummary(ctx, orgID, period)
+		return ac.toolExec.ExecuteGetRevenueSummary(ctx, period)
 
 	case "get_organization_info":
-		fmt.Println("------ get organization info")
-		res, err := ac.organizationService.GetOrganizationDetail(orgID)
-		fmt.Println("res:", res)
+		slog.DebugContext(ctx, "assistant ai: get organization info")
+		res, err := ac.organizationService.GetOrganizationDetail(ctx)
+		slog.DebugContext(ctx, "assistant ai: organization info", "result", res)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2766,7 +2818,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 				}
 			}
 		}
-		accounts, err := ac.organizationService.GetBankAccounts(orgID)
+		accounts, err := ac.organizationService.GetBankAccounts(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2779,7 +2831,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			return map[string]interface{}{"error": "to is required"}
 		}
 		if strings.TrimSpace(from) == "" {
-			orgDetail, err := ac.organizationService.GetOrganizationDetail(orgID)
+			orgDetail, err := ac.organizationService.GetOrganizationDetail(ctx)
 			if err == nil && orgDetail != nil {
 				if v, ok := orgDetail["city_label"].(string); ok && strings.TrimSpace(v) != "" {
 					from = strings.TrimSpace(v)
@@ -2825,7 +2877,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		}
 
 	case "get_order_list":
-		fmt.Println("------ get order list")
+		slog.DebugContext(ctx, "assistant ai: get order list")
 		req := &model.PartnerOrderListFilter{
 			StartDateFrom: getStringParam(params, "start_date"),
 			StartDateTo:   getStringParam(params, "end_date"),
@@ -2841,7 +2893,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			req.HasPaymentStatus = true
 		}
 		req.OrderDateFrom, req.OrderDateTo = resolveOrderDateRange(params)
-		res, err := ac.fleetService.GetPartnerOrdersWithSummary(orgID, req)
+		res, err := ac.fleetService.GetPartnerOrdersWithSummary(ctx, req)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2862,16 +2914,14 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		return enrichOrderListForAI(res)
 
 	case "get_order_detail":
-		fmt.Println("------ get order detail")
 		orderID := getStringParam(params, "order_id")
-		fmt.Println("params:", params)
-		fmt.Println("orderID:", orderID)
+		slog.DebugContext(ctx, "assistant ai: get order detail", "params", params, "order_id", orderID)
 		if orderID == "" {
 			return map[string]interface{}{"error": "order_id is required"}
 		}
-		res, err := ac.fleetService.GetPartnerOrderDetail(orderID, orgID)
+		res, err := ac.fleetService.GetPartnerOrderDetail(ctx, orderID)
 		if err != nil {
-			fmt.Println("error get order detail:", err)
+			slog.ErrorContext(ctx, "assistant ai: get order detail failed", "order_id", orderID, "error", err)
 			return map[string]interface{}{"error": err.Error()}
 		}
 
@@ -2884,12 +2934,10 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			}
 		}
 
-		fmt.Println("payment status", res.PaymentStatus)
-		fmt.Println("payment status label", res.PaymentStatusLabel)
+		slog.DebugContext(ctx, "assistant ai: order payment status", "payment_status", res.PaymentStatus, "payment_status_label", res.PaymentStatusLabel)
 		return enrichOrderDetailForAI(res)
 	case "get_schedule_list":
-		items, err := ac.scheduleService.GetScheduleFleetList(model.ScheduleFleetListServiceInput{
-			OrganizationID: orgID,
+		items, err := ac.scheduleService.GetScheduleFleetList(ctx, model.ScheduleFleetListServiceInput{
 			Query: model.ScheduleFleetListQuery{
 				Period:         getStringParam(params, "period"),
 				OrderID:        getStringParam(params, "order_id"),
@@ -2910,8 +2958,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if scheduleNumber == "" {
 			return map[string]interface{}{"error": "schedule_number is required"}
 		}
-		res, err := ac.scheduleService.GetFleetTripDetail(model.ScheduleFleetTripDetailServiceInput{
-			OrganizationID: orgID,
+		res, err := ac.scheduleService.GetFleetTripDetail(ctx, model.ScheduleFleetTripDetailServiceInput{
 			ScheduleNumber: scheduleNumber,
 		})
 		if err != nil {
@@ -2924,7 +2971,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if orderID == "" {
 			return map[string]interface{}{"error": "order_id is required"}
 		}
-		history, err := ac.orderService.GetServiceOrderPaymentHistory(orgID, &model.ServiceOrderPaymentHistoryRequest{
+		history, err := ac.orderService.GetServiceOrderPaymentHistory(ctx, &model.ServiceOrderPaymentHistoryRequest{
 			OrderID:   orderID,
 			OrderType: 1, // Fleet order type
 		})
@@ -2940,7 +2987,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		}
 		// Get user ID from context
 		userID, _ := ctx.Value(contextUserID).(string)
-		err := ac.fleetService.ProcessFleetOrder(orgID, userID, orderID, 1) // 1 = approve
+		err := ac.fleetService.ProcessFleetOrder(ctx, userID, orderID, 1) // 1 = approve
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2957,7 +3004,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		}
 		// Get user ID from context
 		userID, _ := ctx.Value(contextUserID).(string)
-		err := ac.fleetService.ProcessFleetOrder(orgID, userID, orderID, 0) // 0 = reject
+		err := ac.fleetService.ProcessFleetOrder(ctx, userID, orderID, 0) // 0 = reject
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2974,7 +3021,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			RoleID:     getStringParam(params, "role_id"),
 			DivisionID: getStringParam(params, "division_id"),
 		}
-		schedule, err := ac.organizationService.EmployeeShiftSchedule(orgID, req)
+		schedule, err := ac.organizationService.EmployeeShiftSchedule(ctx, req)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -2999,7 +3046,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			ShiftDate:  shiftDate,
 			ShiftType:  shiftType,
 		}
-		result, err := ac.organizationService.EmployeeShiftSetSchedule(orgID, userID, req)
+		result, err := ac.organizationService.EmployeeShiftSetSchedule(ctx, userID, req)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3024,7 +3071,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		startDate := time.Date(monthTime.Year(), monthTime.Month(), 1, 0, 0, 0, 0, time.Local)
 		endDate := startDate.AddDate(0, 1, -1)
 		// Get finance data
-		finance, err := ac.dashboardService.GetFinance(orgID, startDate, endDate)
+		finance, err := ac.dashboardService.GetFinance(ctx, startDate, endDate)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3039,21 +3086,21 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		}
 
 	case "get_top_fleets":
-		topFleets, err := ac.dashboardService.GetTopFleets(orgID)
+		topFleets, err := ac.dashboardService.GetTopFleets(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 		return topFleets
 
 	case "get_top_destinations":
-		topDestinations, err := ac.dashboardService.GetTopDestinations(orgID)
+		topDestinations, err := ac.dashboardService.GetTopDestinations(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
 		return topDestinations
 
 	case "get_top_customers":
-		topCustomers, err := ac.dashboardService.GetTopCustomers(orgID)
+		topCustomers, err := ac.dashboardService.GetTopCustomers(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3064,11 +3111,11 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if scheduleNumber == "" {
 			return map[string]interface{}{"error": "schedule_number is required"}
 		}
-		totalAmount, err := ac.transactionService.GetFleetTripTotalAmount(scheduleNumber)
+		totalAmount, err := ac.transactionService.GetFleetTripTotalAmount(ctx, scheduleNumber)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
-		summary, err := ac.transactionService.GetFleetTripAmountSummaryByPaymentMethod(scheduleNumber, orgID)
+		summary, err := ac.transactionService.GetFleetTripAmountSummaryByPaymentMethod(ctx, scheduleNumber)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3145,7 +3192,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			paymentMethod = 1 // Default to Biaya Operasional
 		}
 
-		err = ac.transactionService.SubmitFleetTripExpense(orgID, userID, transactionItemCode, scheduleNumber, paymentMethod, amount, description)
+		err = ac.transactionService.SubmitFleetTripExpense(ctx, userID, transactionItemCode, scheduleNumber, paymentMethod, amount, description)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3159,7 +3206,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if scheduleNumber == "" {
 			return map[string]interface{}{"error": "schedule_number is required"}
 		}
-		expenses, err := ac.transactionService.ListFleetTripExpenses(scheduleNumber, orgID)
+		expenses, err := ac.transactionService.ListFleetTripExpenses(ctx, scheduleNumber)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3170,11 +3217,11 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if scheduleNumber == "" {
 			return map[string]interface{}{"error": "schedule_number is required"}
 		}
-		totalAmount, err := ac.transactionService.GetFleetTripTotalAmount(scheduleNumber)
+		totalAmount, err := ac.transactionService.GetFleetTripTotalAmount(ctx, scheduleNumber)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
-		summary, err := ac.transactionService.GetFleetTripAmountSummaryByPaymentMethod(scheduleNumber, orgID)
+		summary, err := ac.transactionService.GetFleetTripAmountSummaryByPaymentMethod(ctx, scheduleNumber)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3197,14 +3244,14 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			return map[string]interface{}{"error": "schedule_number is required"}
 		}
 		roleName, _ := ctx.Value(contextRoleName).(string)
-		sendResultHook := buildAssistantSendResultHook(ac.db, ac.driver, orgID, roleName)
+		sendResultHook := buildAssistantSendResultHook(ctx, ac.db, ac.driver, roleName)
 
-		log.Printf("[WAAI][AI] print_surat_jalan called with schedule_number: '%s'", scheduleNumber)
+		slog.InfoContext(ctx, "assistant ai: print_surat_jalan called", "schedule_number", scheduleNumber)
 
 		// Generate PDF
-		pdfData, err := ac.printService.GenerateFleetTripsPDF(orgID, scheduleNumber)
+		pdfData, err := ac.printService.GenerateFleetTripsPDF(ctx, scheduleNumber)
 		if err != nil {
-			log.Printf("[WAAI][AI] Failed to generate PDF for %s: %v", scheduleNumber, err)
+			slog.ErrorContext(ctx, "assistant ai: failed to generate PDF", "schedule_number", scheduleNumber, "error", err)
 			return map[string]interface{}{"error": "Gagal membuat file PDF: " + err.Error()}
 		}
 
@@ -3221,12 +3268,12 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		// Simpan PDF ke folder assets/temp/surat-jalan/ sebagai file sementara
 		tempDir := filepath.Join("assets", "temp", "surat-jalan")
 		if err := os.MkdirAll(tempDir, 0755); err != nil {
-			log.Printf("[WAAI][AI] Failed to create temp dir: %v", err)
+			slog.ErrorContext(ctx, "assistant ai: failed to create temp dir", "error", err)
 			return map[string]interface{}{"error": "Gagal menyimpan file sementara: " + err.Error()}
 		}
 		tempPath := filepath.Join(tempDir, filename)
 		if err := os.WriteFile(tempPath, pdfData, 0644); err != nil {
-			log.Printf("[WAAI][AI] Failed to write temp file: %v", err)
+			slog.ErrorContext(ctx, "assistant ai: failed to write temp file", "error", err)
 			return map[string]interface{}{"error": "Gagal menyimpan file sementara: " + err.Error()}
 		}
 
@@ -3238,22 +3285,27 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		relativePath := strings.ReplaceAll(tempPath, "\\", "/")
 		mediaURL := fmt.Sprintf("%s/%s", baseURL, relativePath)
 
-		log.Printf("[WAAI][AI] Attempting to send PDF %s to %s via URL: %s", filename, phone, mediaURL)
+		slog.InfoContext(ctx, "assistant ai: sending PDF", "filename", filename, "phone", phone, "media_url", mediaURL)
+
+		if err := allowAssistantSend(ctx, orgID); err != nil {
+			_ = os.Remove(tempPath)
+			return map[string]interface{}{"error": "Gagal mengirim surat jalan ke WhatsApp: " + err.Error()}
+		}
 
 		// Kirim via URL — Wagy akan download dari URL ini
 		_, err = ac.wagyClient.SendDocumentWithURLAndHook(phone, filename, mediaURL, caption, sendResultHook)
 		if err != nil {
-			log.Printf("[WAAI][AI] Failed to send PDF: %v", err)
+			slog.ErrorContext(ctx, "assistant ai: failed to send PDF", "error", err)
 			_ = os.Remove(tempPath) // Bersihkan file meskipun gagal
 			return map[string]interface{}{"error": "Gagal mengirim surat jalan ke WhatsApp: " + err.Error()}
 		}
 
 		// Hapus file setelah berhasil dikirim
 		if err := os.Remove(tempPath); err != nil {
-			log.Printf("[WAAI][AI] Warning: failed to remove temp file %s: %v", tempPath, err)
+			slog.WarnContext(ctx, "assistant ai: failed to remove temp file", "temp_path", tempPath, "error", err)
 		}
 
-		log.Printf("[WAAI][AI] PDF successfully sent via URL and temp file cleaned up")
+		slog.InfoContext(ctx, "assistant ai: PDF sent and temp file removed")
 		return map[string]interface{}{
 			"status":  "success",
 			"message": "Surat jalan " + scheduleNumber + " berhasil dikirim ke WhatsApp Anda",
@@ -3270,7 +3322,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			return map[string]interface{}{"error": err.Error()}
 		}
 		fleetID := getStringParam(params, "fleet_id")
-		availibility, _, err := ac.fleetService.GetFleetAvailibility(orgID, startDate, endDate, fleetID)
+		availibility, _, err := ac.fleetService.GetFleetAvailibility(ctx, startDate, endDate, fleetID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3278,7 +3330,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	case "get_fleet_unit_detail":
 		unitID := getStringParam(params, "unit_id")
-		unit, err := ac.fleetUnitService.Detail(orgID, unitID)
+		unit, err := ac.fleetUnitService.Detail(ctx, unitID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3287,10 +3339,10 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 	case "get_fleet_unit_by_partner":
 		fleetID := getStringParam(params, "fleet_id")
 		items, err := ac.partnerService.Detail(
+			ctx,
 			&model.OperationPartnerDetailRequest{
 				PartnerID: fleetID,
 			},
-			orgID,
 		)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
@@ -3299,7 +3351,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	case "get_upcoming_unit_schedule":
 		unitID := getStringParam(params, "unit_id")
-		_, _, upcoming, err := ac.fleetUnitService.UnitScheduleStats(orgID, unitID)
+		_, _, upcoming, err := ac.fleetUnitService.UnitScheduleStats(ctx, unitID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3307,7 +3359,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 	case "get_latest_unit_schedule":
 		unitID := getStringParam(params, "unit_id")
-		_, latest, _, err := ac.fleetUnitService.UnitScheduleStats(orgID, unitID)
+		_, latest, _, err := ac.fleetUnitService.UnitScheduleStats(ctx, unitID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3326,7 +3378,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
-		trips, err := ac.fleetUnitService.UnitOrderHistory(orgID, unitID, startDate.Format(layout), endDate.Format(layout))
+		trips, err := ac.fleetUnitService.UnitOrderHistory(ctx, unitID, startDate.Format(layout), endDate.Format(layout))
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3338,7 +3390,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if fleetID == "" || typeID == "" {
 			return map[string]interface{}{"error": "fleet_id and type_id are required"}
 		}
-		prices, err := ac.fleetService.GetFleetPricesByFleetID(orgID, fleetID, typeID)
+		prices, err := ac.fleetService.GetFleetPricesByFleetID(ctx, fleetID, typeID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3349,7 +3401,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 		if fleetID == "" {
 			return map[string]interface{}{"error": "fleet_id is required"}
 		}
-		addons, err := ac.fleetService.GetFleetAddonList(orgID, fleetID)
+		addons, err := ac.fleetService.GetFleetAddonList(ctx, fleetID)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3358,18 +3410,62 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 	case "create_order":
 		return ac.executeCreateOrder(ctx, orgID, userID, params)
 
+	case "get_cancellation_refund", "request_order_cancellation":
+		orderID := getStringParam(params, "order_id")
+		if orderID == "" {
+			return map[string]interface{}{"error": "order_id is required"}
+		}
+
+		// Customers can only cancel their own orders
+		phone, _ := ctx.Value(phoneKey).(string)
+		if phone != "" {
+			detail, err := ac.fleetService.GetPartnerOrderDetail(ctx, orderID)
+			if err != nil {
+				return map[string]interface{}{"error": "Pesanan tidak ditemukan"}
+			}
+			if detail.Customer.CustomerPhone != "" && detail.Customer.CustomerPhone != phone {
+				return map[string]interface{}{"error": "Pesanan ini bukan milik Anda"}
+			}
+		}
+
+		if toolName == "get_cancellation_refund" {
+			quote, err := ac.cancellationService.Quote(ctx, orderID, time.Now())
+			if err != nil {
+				return map[string]interface{}{"error": err.Error()}
+			}
+			return quote
+		}
+
+		item, err := ac.cancellationService.SubmitRequest(ctx, &model.OrderCancellationSubmitRequest{
+			Reason:          getStringParam(params, "reason"),
+			BankCode:        getStringParam(params, "bank_code"),
+			BankAccount:     getStringParam(params, "bank_account"),
+			BankAccountName: getStringParam(params, "bank_account_name"),
+			OrderID:         orderID,
+			Source:          model.CancellationSourceWhatsApp,
+		})
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		return map[string]interface{}{
+			"status":        "success",
+			"message":       "Permintaan pembatalan diterima dan menunggu persetujuan tim",
+			"order_id":      orderID,
+			"refund_amount": item.RefundAmount,
+		}
+
 	case "print_invoice":
 		orderID := getStringParam(params, "order_id")
 		if orderID == "" {
 			return map[string]interface{}{"error": "order_id is required"}
 		}
 		roleName, _ := ctx.Value(contextRoleName).(string)
-		sendResultHook := buildAssistantSendResultHook(ac.db, ac.driver, orgID, roleName)
+		sendResultHook := buildAssistantSendResultHook(ctx, ac.db, ac.driver, roleName)
 
 		// Validasi nomor customer: hanya bisa akses invoice miliknya sendiri
 		phone, _ := ctx.Value(phoneKey).(string)
 		if phone != "" {
-			detail, err := ac.fleetService.GetPartnerOrderDetail(orderID, orgID)
+			detail, err := ac.fleetService.GetPartnerOrderDetail(ctx, orderID)
 			if err != nil {
 				return map[string]interface{}{"error": "Pesanan tidak ditemukan"}
 			}
@@ -3384,7 +3480,7 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 			invPtr = &invoiceNum
 		}
 
-		pdfData, err := ac.printService.GenerateFleetInvoicePDF(orgID, orderID, invPtr)
+		pdfData, err := ac.printService.GenerateFleetInvoicePDF(ctx, orderID, invPtr)
 		if err != nil {
 			return map[string]interface{}{"error": "Gagal membuat invoice: " + err.Error()}
 		}
@@ -3396,12 +3492,15 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 
 		filename := fmt.Sprintf("invoice-%s.pdf", orderID)
 		caption := fmt.Sprintf("Berikut invoice untuk pesanan *%s*", orderID)
+		if err := allowAssistantSend(ctx, orgID); err != nil {
+			return map[string]interface{}{"error": "Gagal kirim invoice: " + err.Error()}
+		}
 		_, err = ac.wagyClient.SendDocumentWithHook(phone, filename, pdfData, caption, sendResultHook)
 		if err != nil {
 			return map[string]interface{}{"error": "Gagal kirim invoice: " + err.Error()}
 		}
 
-		log.Printf("[WAAI][AI] Invoice %s sent to %s", orderID, phone)
+		slog.InfoContext(ctx, "assistant ai: invoice sent", "order_id", orderID, "phone", phone)
 		return map[string]interface{}{
 			"status":   "success",
 			"message":  "Invoice " + orderID + " berhasil dikirim ke WhatsApp Anda",
@@ -3415,9 +3514,9 @@ func (ac *AIClient) executeTool(ctx context.Context, toolName string, input json
 	}
 }
 
-func (ac *AIClient) getFleetUnits(orgID string, params map[string]interface{}) interface{} {
+func (ac *AIClient) getFleetUnits(ctx context.Context, params map[string]interface{}) interface{} {
 	items, err := ac.fleetUnitService.List(
-		orgID,
+		ctx,
 		getStringParam(params, "fleet_id"),
 		getStringParam(params, "order_id"),
 		getStringParam(params, "search"),
@@ -3498,7 +3597,7 @@ func (ac *AIClient) createNewItem(ctx context.Context, orgID, userID string, par
 	}
 
 	if itemSKU == "" {
-		generatedSKU, err := ac.inventoryService.GenerateItemSKU(orgID)
+		generatedSKU, err := ac.inventoryService.GenerateItemSKU(ctx)
 		if err != nil {
 			return map[string]interface{}{"error": err.Error()}
 		}
@@ -3523,7 +3622,7 @@ func (ac *AIClient) createNewItem(ctx context.Context, orgID, userID string, par
 		Notes:           notes,
 	}
 
-	item, err := ac.inventoryService.CreateItem(orgID, userID, req)
+	item, err := ac.inventoryService.CreateItem(ctx, userID, req)
 	if err != nil {
 		return map[string]interface{}{"error": err.Error()}
 	}
@@ -3558,7 +3657,8 @@ func withAuthorizedTenantContext(ctx context.Context, tenant *TenantInfo) contex
 	ctx = context.WithValue(ctx, contextOrganizationID, tenant.OrganizationID)
 	ctx = context.WithValue(ctx, contextUserID, tenant.UserID)
 	ctx = context.WithValue(ctx, contextRoleName, tenant.RoleName)
-	return ctx
+	// the tenant scoped repositories read the organization from ctx
+	return database.WithOrganization(ctx, tenant.OrganizationID)
 }
 
 func getAuthorizedContextValues(ctx context.Context) (string, error) {
@@ -3576,7 +3676,7 @@ func getAuthorizedContextValues(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("missing user_id in context")
 	}
 	if roleName == "" {
-		log.Printf("[WAAI][AI] role_name missing in context for org=%s user=%s", orgID, userID)
+		slog.InfoContext(ctx, "assistant ai: role_name missing in context", "organization_id", orgID, "user_id", userID)
 	}
 	return orgID, nil
 }
@@ -3912,7 +4012,7 @@ func (ac *AIClient) requireAdmin(ctx context.Context) error {
 	if phone == "" {
 		return fmt.Errorf("unauthorized: admin access required")
 	}
-	isAdmin, err := ac.inventoryService.IsAdminByAccountNumber(phone)
+	isAdmin, err := ac.inventoryService.IsAdminByAccountNumber(ctx, phone)
 	if err != nil {
 		return fmt.Errorf("failed to verify admin: %w", err)
 	}
@@ -3934,6 +4034,7 @@ func (ac *AIClient) executeCreateOrder(ctx context.Context, orgID, userID string
 	pickupLocation := getStringParam(params, "pickup_location")
 	qty := getIntParam(params, "qty")
 	additionalRequest := getStringParam(params, "additional_request")
+	voucherCode := getStringParam(params, "voucher_code")
 
 	// Parse destinations and addons from JSON strings
 	var destinations []model.OrderDestination
@@ -3953,7 +4054,7 @@ func (ac *AIClient) executeCreateOrder(ctx context.Context, orgID, userID string
 	}
 
 	// Get organization code and company origin city
-	orgDetail, err := ac.organizationService.GetOrganizationDetail(orgID)
+	orgDetail, err := ac.organizationService.GetOrganizationDetail(ctx)
 	if err != nil {
 		return map[string]interface{}{"error": "Failed to get organization info: " + err.Error()}
 	}
@@ -4031,7 +4132,7 @@ func (ac *AIClient) executeCreateOrder(ctx context.Context, orgID, userID string
 	reviewRequired := false
 	if strings.TrimSpace(roleName) == "CustomerAssistant" {
 		var validationErr error
-		reviewRequired, validationErr = ac.validateCompanyAssistantOrderRules(orgID, fleetID, priceID, pickupCityID, startDate, endDate, destinations)
+		reviewRequired, validationErr = ac.validateCompanyAssistantOrderRules(ctx, orgID, fleetID, priceID, pickupCityID, startDate, endDate, destinations)
 		if validationErr != nil {
 			return map[string]interface{}{"error": validationErr.Error()}
 		}
@@ -4052,6 +4153,7 @@ func (ac *AIClient) executeCreateOrder(ctx context.Context, orgID, userID string
 		Qty:               qty,
 		Addons:            addons,
 		AdditionalRequest: additionalRequest,
+		VoucherCode:       voucherCode,
 		OrganizationID:    orgID,
 		OrganizationCode:  orgCode,
 	}
@@ -4059,7 +4161,7 @@ func (ac *AIClient) executeCreateOrder(ctx context.Context, orgID, userID string
 		req.CityID = cityID
 	}
 
-	result, err := ac.orderService.CreateOrder(req)
+	result, err := ac.orderService.CreateOrder(ctx, req)
 	if err != nil {
 		customerPhone, _ := ctx.Value(phoneKey).(string)
 		if suppress, _ := ctx.Value(contextSuppressAdminNotify).(bool); !suppress {
@@ -4086,12 +4188,19 @@ func (ac *AIClient) executeCreateOrder(ctx context.Context, orgID, userID string
 		message += "\n\nTim sedang meninjau pesanan anda, kami akan segera menghubungi anda. \nTerimakasih, Calista Prima"
 	}
 
-	return map[string]interface{}{
+	res := map[string]interface{}{
 		"status":   "success",
 		"message":  message,
 		"order_id": result.OrderID,
 		"token":    result.Token,
 	}
+	if result.Pricing != nil && len(result.Pricing.Lines) > 0 {
+		res["pricing"] = result.Pricing
+	}
+	if result.Voucher != nil {
+		res["voucher"] = result.Voucher
+	}
+	return res
 }
 
 func (ac *AIClient) customerHasUnconfirmedOrderStatus2(ctx context.Context, orgID, phone string) bool {
@@ -4112,7 +4221,7 @@ func (ac *AIClient) customerHasUnconfirmedOrderStatus2(ctx context.Context, orgI
 		OrderDateTo:   to,
 	}
 
-	res, err := ac.fleetService.GetPartnerOrdersWithSummary(orgID, filter)
+	res, err := ac.fleetService.GetPartnerOrdersWithSummary(ctx, filter)
 	if err != nil || res == nil {
 		return false
 	}
@@ -4146,7 +4255,7 @@ func (ac *AIClient) customerHasConfirmedOrderStatus1(ctx context.Context, orgID,
 		OrderDateTo:   to,
 	}
 
-	res, err := ac.fleetService.GetPartnerOrdersWithSummary(orgID, filter)
+	res, err := ac.fleetService.GetPartnerOrdersWithSummary(ctx, filter)
 	if err != nil || res == nil {
 		return false
 	}
@@ -4163,7 +4272,7 @@ func (ac *AIClient) customerHasConfirmedOrderStatus1(ctx context.Context, orgID,
 }
 
 func (ac *AIClient) notifyAdminCreateOrderFailed(ctx context.Context, orgID, orgName, customerPhone string, detail map[string]interface{}) {
-	if ac == nil || ac.organizationService == nil || ac.wagyClient == nil {
+	if ac == nil || ac.organizationService == nil || ac.outbox == nil {
 		return
 	}
 
@@ -4172,9 +4281,9 @@ func (ac *AIClient) notifyAdminCreateOrderFailed(ctx context.Context, orgID, org
 		return
 	}
 
-	adminPhone, err := ac.organizationService.GetAdminAccountNumber(orgID)
+	adminPhone, err := ac.organizationService.GetAdminAccountNumber(ctx)
 	if err != nil {
-		log.Printf("[WAAI][Company] Failed get admin account number org=%s: %v", orgID, err)
+		slog.ErrorContext(ctx, "assistant company: failed to get admin account number", "organization_id", orgID, "error", err)
 		return
 	}
 	adminPhone = strings.TrimSpace(adminPhone)
@@ -4226,15 +4335,13 @@ func (ac *AIClient) notifyAdminCreateOrderFailed(ctx context.Context, orgID, org
 	}
 
 	adminPhone = service.NormalizeAssistantAccountNumber(adminPhone)
-	go func() {
-		if _, err := ac.wagyClient.SendMessage(adminPhone, strings.TrimSpace(message)); err != nil {
-			log.Printf("[WAAI][Company] Failed notify admin %s: %v", adminPhone, err)
-		}
-	}()
+	if err := ac.outbox.EnqueueWhatsApp(ctx, orgID, service.OutboxKindAssistantOrderAlert, adminPhone, strings.TrimSpace(message)); err != nil {
+		slog.ErrorContext(ctx, "assistant company: failed to notify admin", "admin_phone", adminPhone, "error", err)
+	}
 }
 
-func (ac *AIClient) validateCompanyAssistantOrderRules(orgID, fleetID, priceID, pickupCityID, startDate, endDate string, destinations []model.OrderDestination) (bool, error) {
-	selectedPrice, err := ac.findFleetPriceByPriceID(orgID, fleetID, priceID)
+func (ac *AIClient) validateCompanyAssistantOrderRules(ctx context.Context, orgID, fleetID, priceID, pickupCityID, startDate, endDate string, destinations []model.OrderDestination) (bool, error) {
+	selectedPrice, err := ac.findFleetPriceByPriceID(ctx, fleetID, priceID)
 	if err != nil {
 		return false, err
 	}
@@ -4244,7 +4351,7 @@ func (ac *AIClient) validateCompanyAssistantOrderRules(orgID, fleetID, priceID,
 		return false, err
 	}
 
-	prefs, err := ac.preferenceCityService.GetAll(orgID, nil)
+	prefs, err := ac.preferenceCityService.GetAll(ctx, nil)
 	if err != nil {
 		return false, err
 	}
@@ -4289,13 +4396,13 @@ func (ac *AIClient) validateCompanyAssistantOrderRules(orgID, fleetID, priceID,
 	}
 }
 
-func (ac *AIClient) findFleetPriceByPriceID(orgID, fleetID, priceID string) (*model.FleetPriceListItem, error) {
+func (ac *AIClient) findFleetPriceByPriceID(ctx context.Context, fleetID, priceID string) (*model.FleetPriceListItem, error) {
 	for _, typeID := range []string{
 		strconv.Itoa(model.ServiceTypeCityTour),
 		strconv.Itoa(model.ServiceTypeOverland),
 		strconv.Itoa(model.ServiceTypeDropOnly),
 	} {
-		items, err := ac.fleetService.GetFleetPricesByFleetID(orgID, fleetID, typeID)
+		items, err := ac.fleetService.GetFleetPricesByFleetID(ctx, fleetID, typeID)
 		if err != nil {
 			continue
 		}
@@ -4359,18 +4466,25 @@ func fallbackCityLabel(pref model.PreferenceCityWithLabels, fallback string) str
 	return fallback
 }
 
-func insertAssistantAccountStat(db *sql.DB, driver string, organizationID string, messageType int, status int) {
-	insertAssistantStat(db, driver, "assistant_account_stats", "AssistantAccountStat", organizationID, messageType, status)
+func insertAssistantAccountStat(ctx context.Context, db *sql.DB, driver string, messageType int, status int) {
+	insertAssistantStat(ctx, db, driver, "assistant_account_stats", "AssistantAccountStat", messageType, status)
 }
 
-func insertAssistantCustomerStat(db *sql.DB, driver string, organizationID string, messageType int, status int) {
-	insertAssistantStat(db, driver, "assistant_customer_stats", "AssistantCustomerStat", organizationID, messageType, status)
+func insertAssistantCustomerStat(ctx context.Context, db *sql.DB, driver string, messageType int, status int) {
+	insertAssistantStat(ctx, db, driver, "assistant_customer_stats", "AssistantCustomerStat", messageType, status)
 }
 
-func insertAssistantStat(db *sql.DB, driver string, tableName string, logPrefix string, organizationID string, messageType int, status int) {
-	if db == nil || strings.TrimSpace(organizationID) == "" {
+// insertAssistantStat counts a message of the organization of ctx; messages
+// without an organization are not counted
+func insertAssistantStat(ctx context.Context, db *sql.DB, driver string, tableName string, logPrefix string, messageType int, status int) {
+	if db == nil {
+		return
+	}
+	t, err := database.ForTenant(ctx, db)
+	if err != nil {
 		return
 	}
+	organizationID := t.OrganizationID()
 
 	period := time.Now().Format("2006-01-02")
 	query := fmt.Sprintf(`
@@ -4380,35 +4494,51 @@ func insertAssistantStat(db *sql.DB, driver string, tableName string, logPrefix
 		DO UPDATE SET count = %s.count + 1
 	`, tableName, tableName)
 
-	_, err := db.Exec(query, period, organizationID, messageType, status)
+	_, err = t.Exec(query, period, organizationID, messageType, status)
 	if err != nil {
-		log.Printf("[%s] Failed to insert stat for org %s, type %d, status %d, driver %s: %v", logPrefix, organizationID, messageType, status, driver, err)
+		slog.ErrorContext(ctx, "assistant: failed to insert stat", "source", logPrefix, "organization_id", organizationID, "message_type", messageType, "status", status, "driver", driver, "error", err)
 		return
 	}
 
-	log.Printf("[%s] Stat recorded for org %s, type %d, status %d", logPrefix, organizationID, messageType, status)
+	slog.InfoContext(ctx, "assistant: stat recorded", "source", logPrefix, "organization_id", organizationID, "message_type", messageType, "status", status)
 }
 
-func buildAssistantSendResultHook(db *sql.DB, driver string, organizationID string, roleName string) func(error) {
-	roleName = strings.TrimSpace(roleName)
+// allowAssistantSend counts a direct send of the assistant against the
+// WhatsApp limit of the organization, the same limit the outbox keeps to.
+// Sends without an organization, or while Redis is down, are not limited.
+func allowAssistantSend(ctx context.Context, organizationID string) error {
 	organizationID = strings.TrimSpace(organizationID)
+	if organizationID == "" {
+		return nil
+	}
+	allowed, retryAfter, err := helper.AllowOrganizationSend(model.OutboxChannelWhatsApp, organizationID)
+	if err != nil {
+		slog.ErrorContext(ctx, "assistant: send limit not checked", "organization_id", organizationID, "error", err)
+		return nil
+	}
+	if !allowed {
+		return fmt.Errorf("batas pengiriman WhatsApp tercapai, coba lagi dalam %d detik", int((retryAfter+time.Second-1)/time.Second))
+	}
+	return nil
+}
 
-	return func(err error) {
-		if organizationID == "" {
-			return
-		}
+// buildAssistantSendResultHook counts a send of the assistant in the
+// statistics of the organization of ctx
+func buildAssistantSendResultHook(ctx context.Context, db *sql.DB, driver string, roleName string) func(error) {
+	roleName = strings.TrimSpace(roleName)
 
+	return func(err error) {
 		status := 1
 		if err != nil {
 			status = 0
 		}
 
 		if roleName == "CustomerAssistant" {
-			insertAssistantCustomerStat(db, driver, organizationID, 2, status)
+			insertAssistantCustomerStat(ctx, db, driver, 2, status)
 			return
 		}
 
-		insertAssistantAccountStat(db, driver, organizationID, 2, status)
+		insertAssistantAccountStat(ctx, db, driver, 2, status)
 	}
 }
 
@@ -4446,7 +4576,7 @@ func (ac *AIClient) createInventoryRequest(ctx context.Context, orgID, userID st
 		Notes:        notes,
 	}
 
-	request, err := ac.inventoryService.CreateRequest(orgID, userID, req)
+	request, err := ac.inventoryService.CreateRequest(ctx, userID, req)
 	if err != nil {
 		return map[string]interface{}{"error": err.Error()}
 	}
@@ -4476,7 +4606,7 @@ func (ac *AIClient) approveInventoryRequest(ctx context.Context, orgID, userID s
 		ItemID:    itemID,
 	}
 
-	if err := ac.inventoryService.ApproveRequest(orgID, userID, req); err != nil {
+	if err := ac.inventoryService.ApproveRequest(ctx, userID, req); err != nil {
 		return map[string]interface{}{"error": err.Error()}
 	}
 
@@ -4500,7 +4630,7 @@ func (ac *AIClient) rejectInventoryRequest(ctx context.Context, orgID, userID st
 		RequestID: requestID,
 	}
 
-	if err := ac.inventoryService.RejectRequest(orgID, userID, req); err != nil {
+	if err := ac.inventoryService.RejectRequest(ctx, userID, req); err != nil {
 		return map[string]interface{}{"error": err.Error()}
 	}
 
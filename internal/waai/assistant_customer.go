@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"service-travego/database"
 )
 
 // AssistantCustomer merepresentasikan baris assistant_customers
@@ -34,7 +35,8 @@ func (r *AssistantCustomerRepository) getPlaceholder(pos int) string {
 }
 
 // FindByDeviceID mencari perusahaan assistant berdasarkan nomor WA yang menerima pesan
-// phone adalah nomor WA owner (diekstrak dari OwnerJID payload Wagy)
+// phone adalah nomor WA owner (diekstrak dari OwnerJID payload Wagy); dicari di
+// semua organisasi karena organisasi pesan baru diketahui dari hasilnya
 func (r *AssistantCustomerRepository) FindByDeviceID(ctx context.Context, phone string) (*AssistantCustomer, bool, error) {
 	query := fmt.Sprintf(`
 		SELECT
@@ -50,7 +52,7 @@ func (r *AssistantCustomerRepository) FindByDeviceID(ctx context.Context, phone
 	`, r.getPlaceholder(1))
 
 	var ac AssistantCustomer
-	err := r.db.QueryRowContext(ctx, query, phone).Scan(
+	err := database.QueryRowContext(ctx, r.db, query, phone).Scan(
 		&ac.DeviceID,
 		&ac.DeviceName,
 		&ac.AssistantDeviceID,
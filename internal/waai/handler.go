@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"service-travego/database"
+	"service-travego/internal/supervisor"
 	"service-travego/internal/wagy"
 	"strings"
 	"time"
@@ -67,7 +69,7 @@ func (h *Handler) HandleWebhookPOST(c *fiber.Ctx) error {
 	rawBody := c.Body()
 
 	if !VerifySignature(rawBody, signature, h.config.WagyWebhookSecret) {
-		log.Printf("[WAAI] Signature verification failed")
+		slog.ErrorContext(c.UserContext(), "assistant: signature verification failed")
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid signature",
 		})
@@ -75,7 +77,7 @@ func (h *Handler) HandleWebhookPOST(c *fiber.Ctx) error {
 
 	var payload WebhookPayload
 	if err := json.Unmarshal(rawBody, &payload); err != nil {
-		log.Printf("[WAAI] Failed to parse payload: %v", err)
+		slog.ErrorContext(c.UserContext(), "assistant: failed to parse payload", "error", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid payload",
 		})
@@ -90,8 +92,7 @@ func (h *Handler) HandleWebhookPOST(c *fiber.Ctx) error {
 	messageText := payload.Data.Content.Message
 	wagyDeviceID := payload.Data.DeviceID
 
-	log.Printf("[WAAI] Event=message.received | wagy_device=%s | owner=%s | from=%s | msg=%s",
-		wagyDeviceID, ownerPhone, customerPhone, messageText)
+	slog.InfoContext(c.UserContext(), "assistant: message received", "wagy_device", wagyDeviceID, "owner", ownerPhone, "from", customerPhone, "message", messageText)
 
 	switch {
 	case ownerPhone == h.config.ServiceAccount:
@@ -111,7 +112,7 @@ func (h *Handler) processERPAssistant(customerPhone, messageText string) {
 	_, err := h.tenantRepo.GetTenantByPhone(ctx, customerPhone)
 	if err != nil {
 		if isCapabilitiesQuestion(messageText) || isIdentityOrDeveloperQuestion(messageText) || isRegistrationQuestion(messageText) {
-			go h.processMessageAsync(customerPhone, messageText)
+			h.processMessageAsync(customerPhone, messageText)
 			return
 		}
 		replyText := buildUnregisteredReply(messageText)
@@ -119,7 +120,7 @@ func (h *Handler) processERPAssistant(customerPhone, messageText string) {
 		return
 	}
 
-	go h.processMessageAsync(customerPhone, messageText)
+	h.processMessageAsync(customerPhone, messageText)
 }
 
 // processCompanyAssistant — Skenario 2: Customer mengirim ke nomor perusahaan customer
@@ -129,32 +130,36 @@ func (h *Handler) processCompanyAssistant(customerPhone, ownerPhone, messageText
 
 	asstCust, found, err := h.asstCustRepo.FindByDeviceID(ctx, ownerPhone)
 	if err != nil {
-		log.Printf("[WAAI][Company] DB error for %s: %v", ownerPhone, err)
+		slog.ErrorContext(ctx, "assistant company: find assistant customer failed", "owner_phone", ownerPhone, "error", err)
 		return
 	}
 	if !found {
-		log.Printf("[WAAI][Company] Ignored: %s not registered in assistant_customers", ownerPhone)
+		slog.InfoContext(ctx, "assistant company: ignored, owner not registered in assistant_customers", "owner_phone", ownerPhone)
 		return
 	}
 
-	log.Printf("[WAAI][Company] org=%s | assistant_device=%s | from=%s",
-		asstCust.OrganizationID, asstCust.AssistantDeviceID, customerPhone)
+	slog.InfoContext(ctx, "assistant company: message", "organization_id", asstCust.OrganizationID, "assistant_device", asstCust.AssistantDeviceID, "from", customerPhone)
 
-	go h.processCompanyMessageAsync(customerPhone, messageText, asstCust)
+	h.processCompanyMessageAsync(customerPhone, messageText, asstCust)
 }
 
 // processCompanyMessageAsync memproses pesan untuk company assistant (Skenario 2)
+// di background lewat supervisor
 func (h *Handler) processCompanyMessageAsync(customerPhone, messageText string, asstCust *AssistantCustomer) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	supervisor.Go("waai.company_message", func(ctx context.Context) {
+		h.processCompanyMessage(ctx, customerPhone, messageText, asstCust)
+	})
+}
+
+func (h *Handler) processCompanyMessage(ctx context.Context, customerPhone, messageText string, asstCust *AssistantCustomer) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	sendClient := h.clientRegistry.GetClient(asstCust.DeviceID, asstCust.DeviceToken)
 	if sendClient == nil {
-		fmt.Println("sendClient is nil -- deviceToken ", asstCust.DeviceToken)
-		fmt.Println("sendClient is nil -- deviceID ", asstCust.DeviceID)
-		log.Printf("[WAAI][Company] Cannot get WagyClient for device %s", asstCust.DeviceID)
+		slog.ErrorContext(ctx, "assistant company: cannot get wagy client", "device_id", asstCust.DeviceID)
 		finalResponse := "Maaf, layanan assistant sedang tidak tersedia. Silakan hubungi kantor langsung."
-		_ = h.sendMessageWithClient(customerPhone, finalResponse, h.wagyClient, asstCust.OrganizationID, "CustomerAssistant")
+		_ = h.sendMessageWithClient(database.WithOrganization(ctx, asstCust.OrganizationID), customerPhone, finalResponse, h.wagyClient, "CustomerAssistant")
 		return
 	}
 
@@ -169,9 +174,9 @@ func (h *Handler) processCompanyMessageAsync(customerPhone, messageText string,
 	if tenant.OrganizationID != "" {
 		ctx = withAuthorizedTenantContext(ctx, tenant)
 		var err error
-		snapshot, err = h.tenantRepo.GetOrganizationSnapshot(ctx, tenant.OrganizationID)
+		snapshot, err = h.tenantRepo.GetOrganizationSnapshot(ctx)
 		if err != nil {
-			log.Printf("[WAAI][Company] Failed snapshot org %s: %v", tenant.OrganizationID, err)
+			slog.ErrorContext(ctx, "assistant company: failed to snapshot organization", "organization_id", tenant.OrganizationID, "error", err)
 			snapshot = map[string]interface{}{}
 		}
 		if tenant.OrganizationName == "" {
@@ -183,7 +188,7 @@ func (h *Handler) processCompanyMessageAsync(customerPhone, messageText string,
 
 	history, err := h.sessionMgr.LoadSessionFor(ctx, asstCust.OrganizationID, customerPhone)
 	if err != nil {
-		log.Printf("[WAAI][Company] Failed load session: %v", err)
+		slog.ErrorContext(ctx, "assistant company: process company message failed", "error", err)
 		history = []ConversationMessage{}
 	}
 
@@ -204,7 +209,7 @@ func (h *Handler) processCompanyMessageAsync(customerPhone, messageText string,
 	// Use Company-specific AI method with restricted tool definitions
 	finalResponse, updatedHistory, err := h.aiClient.callAnthropicWithCompanyTools(ctx, systemPrompt, history)
 	if err != nil {
-		log.Printf("[WAAI][Company] AI error: %v", err)
+		slog.ErrorContext(ctx, "assistant company: process company message failed", "error", err)
 		finalResponse = "Maaf, layanan sedang sibuk. Silakan coba lagi."
 	}
 	finalResponse = formatWhatsAppReply(finalResponse)
@@ -222,40 +227,51 @@ func (h *Handler) processCompanyMessageAsync(customerPhone, messageText string,
 	}
 	_ = h.sessionMgr.SaveSessionFor(ctx, asstCust.OrganizationID, customerPhone, history)
 
-	if err := h.sendMessageWithClient(customerPhone, finalResponse, sendClient, asstCust.OrganizationID, tenant.RoleName); err != nil {
-		log.Printf("[WAAI][Company] Failed send via device %s: %v", asstCust.AssistantDeviceID, err)
+	if err := h.sendMessageWithClient(ctx, customerPhone, finalResponse, sendClient, tenant.RoleName); err != nil {
+		slog.ErrorContext(ctx, "assistant company: failed to send", "assistant_device_id", asstCust.AssistantDeviceID, "error", err)
 	}
 
-	log.Printf("[WAAI][Company] Reply sent | device=%s | to=%s", asstCust.AssistantDeviceID, customerPhone)
+	slog.InfoContext(ctx, "assistant company: reply sent", "device", asstCust.AssistantDeviceID, "to", customerPhone)
 }
 
-func (h *Handler) sendMessageWithClient(phone, message string, client *wagy.WagyClient, organizationID string, roleName string) error {
+// sendMessageWithClient sends a reply on behalf of the organization of ctx
+func (h *Handler) sendMessageWithClient(ctx context.Context, phone, message string, client *wagy.WagyClient, roleName string) error {
 	if client == nil {
 		return fmt.Errorf("WagyClient is nil")
 	}
-	_, err := client.SendMessageWithHook(phone, message, buildAssistantSendResultHook(h.aiClient.db, h.aiClient.driver, organizationID, roleName))
+	organizationID, _ := database.OrganizationFromContext(ctx)
+	if err := allowAssistantSend(ctx, organizationID); err != nil {
+		return err
+	}
+	_, err := client.SendMessageWithHook(phone, message, buildAssistantSendResultHook(ctx, h.aiClient.db, h.aiClient.driver, roleName))
 	if err != nil {
 		return err
 	}
-	log.Printf("[WAAI] Message sent to %s", phone)
+	slog.InfoContext(ctx, "assistant: message sent", "phone", phone)
 	return nil
 }
 
 // processMessageAsync processes the message asynchronously
 func (h *Handler) processMessageAsync(phone, messageText string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	supervisor.Go("waai.message", func(ctx context.Context) {
+		h.processMessage(ctx, phone, messageText)
+	})
+}
+
+func (h *Handler) processMessage(ctx context.Context, phone, messageText string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Process message with AI
 	response, err := h.aiClient.ProcessMessage(ctx, phone, messageText)
 	if err != nil {
-		log.Printf("[WAAI] Error processing message from %s: %v", phone, err)
+		slog.ErrorContext(ctx, "assistant: error processing message", "phone", phone, "error", err)
 		response = "Maaf, terjadi kesalahan saat memproses permintaan Anda. Silakan coba lagi."
 	}
 
 	// Send response
 	if err := h.sendMessage(phone, response); err != nil {
-		log.Printf("[WAAI] Error sending message to %s: %v", phone, err)
+		slog.ErrorContext(ctx, "assistant: error sending message", "phone", phone, "error", err)
 	}
 }
 
@@ -270,14 +286,18 @@ func (h *Handler) sendMessage(phone, message string) error {
 	if err == nil {
 		organizationID = tenant.OrganizationID
 		roleName = tenant.RoleName
+		ctx = database.WithOrganization(ctx, organizationID)
+	}
+	if err := allowAssistantSend(ctx, organizationID); err != nil {
+		return err
 	}
 
-	_, err = h.wagyClient.SendMessageWithHook(phone, message, buildAssistantSendResultHook(h.aiClient.db, h.aiClient.driver, organizationID, roleName))
+	_, err = h.wagyClient.SendMessageWithHook(phone, message, buildAssistantSendResultHook(ctx, h.aiClient.db, h.aiClient.driver, roleName))
 	if err != nil {
-		fmt.Printf("Error sending message to %s: %v", phone, err)
+		slog.ErrorContext(ctx, "assistant: error sending message", "phone", phone, "error", err)
 		return err
 	}
-	log.Printf("[WAAI] Message sent to %s", phone)
+	slog.InfoContext(ctx, "assistant: message sent", "phone", phone)
 	return nil
 }
 
@@ -304,7 +324,7 @@ func (h *Handler) ClearSessionHandler(c *fiber.Ctx) error {
 
 	err := h.sessionMgr.ClearSession(ctx, phone)
 	if err != nil {
-		log.Printf("[WAAI] Error clearing session for %s: %v", phone, err)
+		slog.ErrorContext(ctx, "assistant: error clearing session", "phone", phone, "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to clear session",
 		})
@@ -337,7 +357,7 @@ func RegisterRoutes(app *fiber.App, cfg *Config, db *sql.DB, dbDriver string, rd
 		adminGroup.Get("/health", handler.HealthCheck)
 	}
 
-	log.Println("[WAAI] Routes registered successfully")
+	slog.Info("assistant: routes registered")
 	return nil
 }
 
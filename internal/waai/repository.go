@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"service-travego/database"
 	"strings"
 )
 
@@ -44,7 +46,9 @@ func (tr *TenantRepository) textCompareExpr(column string, pos int) string {
 	return fmt.Sprintf("%s::text = %s", column, tr.getPlaceholder(pos))
 }
 
-// GetTenantByPhone retrieves tenant information by WhatsApp phone number
+// GetTenantByPhone retrieves tenant information by WhatsApp phone number; the
+// number is looked up across organizations because the organization of a
+// message is only known once it is found
 func (tr *TenantRepository) GetTenantByPhone(ctx context.Context, phone string) (*TenantInfo, error) {
 	// Normalize phone: remove @s.whatsapp.net suffix if present
 	phone = strings.TrimSuffix(phone, "@s.whatsapp.net")
@@ -54,7 +58,7 @@ func (tr *TenantRepository) GetTenantByPhone(ctx context.Context, phone string)
 	if tr.authMgr != nil {
 		authData, err := tr.authMgr.GetTenantAuth(ctx, phone)
 		if err != nil {
-			fmt.Println("Failed to get tenant auth from redis for phone", phone, ":", err)
+			slog.ErrorContext(ctx, "assistant: failed to get tenant auth from redis", "phone", phone, "error", err)
 		}
 		if authData != nil {
 			_ = tr.authMgr.RefreshTenantAuthTTL(ctx, phone)
@@ -132,7 +136,7 @@ func (tr *TenantRepository) GetTenantByPhone(ctx context.Context, phone string)
 
 	var tenant TenantInfo
 	var createdAt sql.NullTime
-	err := tr.db.QueryRowContext(ctx, query, phone).Scan(
+	err := database.QueryRowContext(ctx, tr.db, query, phone).Scan(
 		&tenant.AssistantID,
 		&tenant.UserID,
 		&createdAt,
@@ -146,8 +150,8 @@ func (tr *TenantRepository) GetTenantByPhone(ctx context.Context, phone string)
 	)
 
 	if err != nil {
-		fmt.Println("query:", query)
-		fmt.Println("Failed to query tenant for phone", phone, ":", err)
+		slog.DebugContext(ctx, "assistant: tenant query", "query", query)
+		slog.ErrorContext(ctx, "assistant: failed to query tenant", "phone", phone, "error", err)
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("tenant not found for phone: %s", phone)
 		}
@@ -166,24 +170,29 @@ func (tr *TenantRepository) GetTenantByPhone(ctx context.Context, phone string)
 
 	if tr.authMgr != nil {
 		if err := tr.authMgr.SaveTenantAuth(ctx, phone, &tenant); err != nil {
-			fmt.Println("Failed to save tenant auth to redis for phone", phone, ":", err)
+			slog.ErrorContext(ctx, "assistant: failed to save tenant auth to redis", "phone", phone, "error", err)
 		}
 	}
 
 	return &tenant, nil
 }
 
-// GetOrganizationSnapshot retrieves business snapshot for an organization
-func (tr *TenantRepository) GetOrganizationSnapshot(ctx context.Context, orgID string) (map[string]interface{}, error) {
+// GetOrganizationSnapshot retrieves business snapshot of the organization of ctx
+func (tr *TenantRepository) GetOrganizationSnapshot(ctx context.Context) (map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, tr.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	// Query organization basic info
 	orgQuery := fmt.Sprintf("SELECT organization_id as id, organization_name as name FROM organizations WHERE %s", tr.textCompareExpr("organization_id", 1))
 	var org struct {
 		ID   string
 		Name string
 	}
-	fmt.Println("orgQuery:", orgQuery)
+	slog.DebugContext(ctx, "assistant: organization query", "query", orgQuery)
 
-	err := tr.db.QueryRowContext(ctx, orgQuery, orgID).Scan(&org.ID, &org.Name)
+	err = t.QueryRow(orgQuery, orgID).Scan(&org.ID, &org.Name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("organization not found: %s", orgID)
@@ -194,12 +203,12 @@ func (tr *TenantRepository) GetOrganizationSnapshot(ctx context.Context, orgID s
 	// Query fleet count
 	fleetQuery := fmt.Sprintf("SELECT COUNT(*) FROM fleets WHERE %s", tr.textCompareExpr("organization_id", 1))
 	var fleetCount int
-	_ = tr.db.QueryRowContext(ctx, fleetQuery, orgID).Scan(&fleetCount)
+	_ = t.QueryRow(fleetQuery, orgID).Scan(&fleetCount)
 
 	// Query fleet units count
-	unitQuery := fmt.Sprintf("SELECT COUNT(*) FROM fleet_units WHERE fleet_id IN (SELECT id FROM fleets WHERE %s)", tr.textCompareExpr("organization_id", 1))
+	unitQuery := fmt.Sprintf("SELECT COUNT(*) FROM fleet_units WHERE %s", tr.textCompareExpr("organization_id", 1))
 	var unitCount int
-	_ = tr.db.QueryRowContext(ctx, unitQuery, orgID).Scan(&unitCount)
+	_ = t.QueryRow(unitQuery, orgID).Scan(&unitCount)
 
 	// Query today's bookings count
 	bookingQuery := `
@@ -208,7 +217,7 @@ func (tr *TenantRepository) GetOrganizationSnapshot(ctx context.Context, orgID s
 		AND DATE(created_at) = CURRENT_DATE
 	`
 	var bookingCount int
-	_ = tr.db.QueryRowContext(ctx, bookingQuery, orgID).Scan(&bookingCount)
+	_ = t.QueryRow(bookingQuery, orgID).Scan(&bookingCount)
 
 	snapshot := map[string]interface{}{
 		"organization_name": org.Name,
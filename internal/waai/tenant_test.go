@@ -0,0 +1,123 @@
+package waai
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"service-travego/database"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	tenantA = "6f1c1a52-0a0e-4c4e-9a57-6d3c3f1b0a01"
+	tenantB = "0b6a9d7e-55b2-4f0e-8d6e-2a9f4c7e0b02"
+)
+
+// recordingDB is a fake database without rows that records the arguments of
+// every statement it is sent
+type recordingDB struct {
+	mu   sync.Mutex
+	args [][]driver.Value
+}
+
+var fakeDB = &recordingDB{}
+
+func init() {
+	sql.Register("waaifake", recordingDriver{})
+}
+
+func (d *recordingDB) take() [][]driver.Value {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	args := d.args
+	d.args = nil
+	return args
+}
+
+type recordingDriver struct{}
+
+func (recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{}, nil }
+
+type recordingConn struct{}
+
+func (recordingConn) Prepare(query string) (driver.Stmt, error) { return recordingStmt{}, nil }
+func (recordingConn) Close() error                              { return nil }
+func (recordingConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type recordingStmt struct{}
+
+func (recordingStmt) Close() error  { return nil }
+func (recordingStmt) NumInput() int { return -1 }
+
+func (recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fakeDB.mu.Lock()
+	defer fakeDB.mu.Unlock()
+	fakeDB.args = append(fakeDB.args, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	fakeDB.mu.Lock()
+	defer fakeDB.mu.Unlock()
+	fakeDB.args = append(fakeDB.args, args)
+	return noRows{}, nil
+}
+
+type noRows struct{}
+
+func (noRows) Columns() []string              { return nil }
+func (noRows) Close() error                   { return nil }
+func (noRows) Next(dest []driver.Value) error { return io.EOF }
+
+// TestAssistantQueriesStayInTheOrganizationOfTheMessage checks that the
+// assistant only reads and writes the organization the message belongs to
+func TestAssistantQueriesStayInTheOrganizationOfTheMessage(t *testing.T) {
+	db, err := sql.Open("waaifake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewTenantRepository(db, "postgres", nil)
+	tools := NewToolExecutor(db, "postgres")
+	calls := map[string]func(ctx context.Context){
+		"GetOrganizationSnapshot":     func(ctx context.Context) { _, _ = repo.GetOrganizationSnapshot(ctx) },
+		"ExecuteGetBusinessSnapshot":  func(ctx context.Context) { tools.ExecuteGetBusinessSnapshot(ctx) },
+		"ExecuteGetFleetAvailability": func(ctx context.Context) { tools.ExecuteGetFleetAvailability(ctx, "2026-01-01", "2026-01-02") },
+		"ExecuteGetBookingList":       func(ctx context.Context) { tools.ExecuteGetBookingList(ctx, "", 10) },
+		"ExecuteGetRevenueSummary":    func(ctx context.Context) { tools.ExecuteGetRevenueSummary(ctx, "daily") },
+		"insertAssistantAccountStat":  func(ctx context.Context) { insertAssistantAccountStat(ctx, db, "postgres", 1, 1) },
+		"insertAssistantCustomerStat": func(ctx context.Context) { insertAssistantCustomerStat(ctx, db, "postgres", 1, 1) },
+		"send result hook":            func(ctx context.Context) { buildAssistantSendResultHook(ctx, db, "postgres", "Admin")(nil) },
+	}
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	fakeDB.take()
+	for name, call := range calls {
+		call(ctxA)
+		statements := fakeDB.take()
+		if len(statements) == 0 {
+			t.Errorf("%s: sent no statement", name)
+		}
+		for _, args := range statements {
+			bound := false
+			for _, arg := range args {
+				s, _ := arg.(string)
+				if s == tenantB {
+					t.Errorf("%s: reached tenant B with %v", name, args)
+				}
+				bound = bound || strings.EqualFold(s, tenantA)
+			}
+			if !bound {
+				t.Errorf("%s: statement not bound to tenant A: %v", name, args)
+			}
+		}
+
+		call(context.Background())
+		if statements := fakeDB.take(); len(statements) != 0 {
+			t.Errorf("%s: sent %d statements without an organization", name, len(statements))
+		}
+	}
+}
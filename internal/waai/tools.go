@@ -1116,7 +1116,7 @@ func GetToolDefinitions() []ToolDefinition {
 			Name: "create_order",
 			Function: FunctionDefinition{
 				Name:        "create_order",
-				Description: "Create a new booking/order for fleet rental. All required params must be collected first: fleet_id, price_id, fullname, email, address, start_date, end_date, pickup_city_id, pickup_location, qty.",
+				Description: "Create a new booking/order for fleet rental. All required params must be collected first: fleet_id, price_id, fullname, email, address, start_date, end_date, pickup_city_id, pickup_location, qty. The result may include a pricing breakdown (seasonal/weekend surcharges, lead-time discounts, pickup city adjustments) that should be explained to the customer.",
 				Parameters: map[string]interface{}{
 					"type": "object",
 					"properties": map[string]interface{}{
@@ -1172,6 +1172,10 @@ func GetToolDefinitions() []ToolDefinition {
 							"type":        "string",
 							"description": "Optional notes",
 						},
+						"voucher_code": map[string]interface{}{
+							"type":        "string",
+							"description": "Optional voucher / promo code given by the customer",
+						},
 					},
 					"required": []string{"fleet_id", "price_id", "fullname", "email", "address", "start_date", "end_date", "pickup_city_id", "pickup_location"},
 				},
@@ -1506,5 +1510,57 @@ func GetCompanyToolDefinitions() []ToolDefinition {
 				},
 			},
 		},
+		{
+			Type: "function",
+			Name: "get_cancellation_refund",
+			Function: FunctionDefinition{
+				Name:        "get_cancellation_refund",
+				Description: "Preview the refund the customer gets if the order is cancelled today, computed from the company's cancellation policy. Only works for orders belonging to the customer's phone number",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"order_id": map[string]interface{}{
+							"type":        "string",
+							"description": "Order ID",
+						},
+					},
+					"required": []string{"order_id"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Name: "request_order_cancellation",
+			Function: FunctionDefinition{
+				Name:        "request_order_cancellation",
+				Description: "Submit the customer's request to cancel an order. The request waits for approval by the company staff; the order is not cancelled yet. Only call after the customer confirmed the refund preview and explicitly asked to cancel",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"order_id": map[string]interface{}{
+							"type":        "string",
+							"description": "Order ID",
+						},
+						"reason": map[string]interface{}{
+							"type":        "string",
+							"description": "Cancellation reason given by the customer",
+						},
+						"bank_code": map[string]interface{}{
+							"type":        "string",
+							"description": "Optional bank of the refund account",
+						},
+						"bank_account": map[string]interface{}{
+							"type":        "string",
+							"description": "Optional refund account number",
+						},
+						"bank_account_name": map[string]interface{}{
+							"type":        "string",
+							"description": "Optional refund account holder name",
+						},
+					},
+					"required": []string{"order_id", "reason"},
+				},
+			},
+		},
 	}
 }
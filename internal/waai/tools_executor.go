@@ -3,6 +3,7 @@ package waai
 import (
 	"context"
 	"database/sql"
+	"service-travego/database"
 	"strconv"
 )
 
@@ -41,29 +42,35 @@ func (te *ToolExecutor) dateParamExpr(pos int) string {
 	return te.getPlaceholder(pos) + "::date"
 }
 
-// ExecuteGetBusinessSnapshot returns current business metrics
-func (te *ToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context, orgID string) map[string]interface{} {
+// ExecuteGetBusinessSnapshot returns current business metrics of the
+// organization of ctx
+func (te *ToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context) map[string]interface{} {
 	result := map[string]interface{}{
 		"fleet_count":     0,
 		"available_units": 0,
 		"today_bookings":  0,
 		"today_revenue":   0,
 	}
+	t, err := database.ForTenant(ctx, te.db)
+	if err != nil {
+		return result
+	}
+	orgID := t.OrganizationID()
 
 	// Query fleet count
 	fleetQuery := `SELECT COUNT(*) FROM fleets WHERE ` + te.textCompareExpr("organization_id", 1)
 	var fleetCount int
-	_ = te.db.QueryRowContext(ctx, fleetQuery, orgID).Scan(&fleetCount)
+	_ = t.QueryRow(fleetQuery, orgID).Scan(&fleetCount)
 	result["fleet_count"] = fleetCount
 
 	// Query available units (simplified)
 	unitQuery := `
 		SELECT COUNT(*) FROM fleet_units
-		WHERE fleet_id IN (SELECT id FROM fleets WHERE ` + te.textCompareExpr("organization_id", 1) + `)
+		WHERE ` + te.textCompareExpr("organization_id", 1) + `
 		AND is_active = true
 	`
 	var unitCount int
-	_ = te.db.QueryRowContext(ctx, unitQuery, orgID).Scan(&unitCount)
+	_ = t.QueryRow(unitQuery, orgID).Scan(&unitCount)
 	result["available_units"] = unitCount
 
 	// Query today's bookings
@@ -73,7 +80,7 @@ func (te *ToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context, orgID st
 		AND DATE(created_at) = CURRENT_DATE
 	`
 	var bookingCount int
-	_ = te.db.QueryRowContext(ctx, bookingQuery, orgID).Scan(&bookingCount)
+	_ = t.QueryRow(bookingQuery, orgID).Scan(&bookingCount)
 	result["today_bookings"] = bookingCount
 
 	// Query today's revenue (simplified, assumes there's a revenue tracking)
@@ -84,33 +91,38 @@ func (te *ToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context, orgID st
 		AND status = 'completed'
 	`
 	var revenue float64
-	_ = te.db.QueryRowContext(ctx, revenueQuery, orgID).Scan(&revenue)
+	_ = t.QueryRow(revenueQuery, orgID).Scan(&revenue)
 	result["today_revenue"] = revenue
 
 	return result
 }
 
-// ExecuteGetFleetAvailability returns available fleet units for a date range
-func (te *ToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, orgID string, dateStart, dateEnd string) map[string]interface{} {
+// ExecuteGetFleetAvailability returns available fleet units of the
+// organization of ctx for a date range
+func (te *ToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, dateStart, dateEnd string) map[string]interface{} {
 	result := map[string]interface{}{
 		"available_units": 0,
 		"date_range":      dateStart + " to " + dateEnd,
 		"details":         []map[string]interface{}{},
 	}
+	t, err := database.ForTenant(ctx, te.db)
+	if err != nil {
+		return result
+	}
 
 	// Query available units for date range
 	query := `
 		SELECT DISTINCT fu.id, fu.name, ft.name as fleet_type
 		FROM fleet_units fu
-		JOIN fleets f ON fu.fleet_id = f.id
+		JOIN fleets f ON fu.fleet_id = f.id AND f.organization_id = fu.organization_id
 		JOIN fleet_types ft ON f.fleet_type_id = ft.id
-		WHERE ` + te.textCompareExpr("f.organization_id", 1) + `
+		WHERE ` + te.textCompareExpr("fu.organization_id", 1) + `
 		AND fu.is_active = true
 		AND fu.id NOT IN (
 			SELECT DISTINCT fu2.id
 			FROM bookings b
 			JOIN booking_units bu ON b.id = bu.booking_id
-			JOIN fleet_units fu2 ON bu.fleet_unit_id = fu2.id
+			JOIN fleet_units fu2 ON bu.fleet_unit_id = fu2.id AND fu2.organization_id = fu.organization_id
 			WHERE b.start_date <= ` + te.dateParamExpr(3) + `
 			AND b.end_date >= ` + te.dateParamExpr(2) + `
 			AND b.status NOT IN ('cancelled')
@@ -118,7 +130,7 @@ func (te *ToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, orgID s
 		LIMIT 20
 	`
 
-	rows, err := te.db.QueryContext(ctx, query, orgID, dateStart, dateEnd)
+	rows, err := t.Query(query, t.OrganizationID(), dateStart, dateEnd)
 	if err != nil {
 		return result
 	}
@@ -147,8 +159,13 @@ func (te *ToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, orgID s
 	return result
 }
 
-// ExecuteGetBookingList returns list of bookings with optional status filter
-func (te *ToolExecutor) ExecuteGetBookingList(ctx context.Context, orgID string, status string, limit int) []map[string]interface{} {
+// ExecuteGetBookingList returns list of bookings of the organization of ctx
+// with optional status filter
+func (te *ToolExecutor) ExecuteGetBookingList(ctx context.Context, status string, limit int) []map[string]interface{} {
+	t, err := database.ForTenant(ctx, te.db)
+	if err != nil {
+		return []map[string]interface{}{}
+	}
 	if limit <= 0 {
 		limit = 10
 	}
@@ -161,7 +178,7 @@ func (te *ToolExecutor) ExecuteGetBookingList(ctx context.Context, orgID string,
 		FROM bookings
 		WHERE ` + te.textCompareExpr("organization_id", 1) + `
 	`
-	args := []interface{}{orgID}
+	args := []interface{}{t.OrganizationID()}
 
 	if status != "" {
 		query += ` AND status = ` + te.getPlaceholder(2)
@@ -171,7 +188,7 @@ func (te *ToolExecutor) ExecuteGetBookingList(ctx context.Context, orgID string,
 	query += ` ORDER BY created_at DESC LIMIT ` + te.getPlaceholder(len(args)+1)
 	args = append(args, limit)
 
-	rows, err := te.db.QueryContext(ctx, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return []map[string]interface{}{}
 	}
@@ -201,14 +218,19 @@ func (te *ToolExecutor) ExecuteGetBookingList(ctx context.Context, orgID string,
 	return bookings
 }
 
-// ExecuteGetRevenueSummary returns revenue data for a period
-func (te *ToolExecutor) ExecuteGetRevenueSummary(ctx context.Context, orgID string, period string) map[string]interface{} {
+// ExecuteGetRevenueSummary returns revenue data of the organization of ctx
+// for a period
+func (te *ToolExecutor) ExecuteGetRevenueSummary(ctx context.Context, period string) map[string]interface{} {
 	result := map[string]interface{}{
 		"period":              period,
 		"total_revenue":       0,
 		"transaction_count":   0,
 		"average_transaction": 0,
 	}
+	t, err := database.ForTenant(ctx, te.db)
+	if err != nil {
+		return result
+	}
 
 	// Determine date range based on period
 	var dateFilter string
@@ -237,7 +259,7 @@ func (te *ToolExecutor) ExecuteGetRevenueSummary(ctx context.Context, orgID stri
 	var transactionCount int
 	var avgTransaction float64
 
-	err := te.db.QueryRowContext(ctx, query, orgID).Scan(
+	err = t.QueryRow(query, t.OrganizationID()).Scan(
 		&totalRevenue,
 		&transactionCount,
 		&avgTransaction,
@@ -258,7 +280,7 @@ func (te *ToolExecutor) ExecuteGetRevenueSummary(ctx context.Context, orgID stri
 type MockToolExecutor struct{}
 
 // ExecuteGetBusinessSnapshot mock
-func (mte *MockToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context, orgID string) map[string]interface{} {
+func (mte *MockToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context) map[string]interface{} {
 	return map[string]interface{}{
 		"fleet_count":     5,
 		"available_units": 12,
@@ -268,7 +290,7 @@ func (mte *MockToolExecutor) ExecuteGetBusinessSnapshot(ctx context.Context, org
 }
 
 // ExecuteGetFleetAvailability mock
-func (mte *MockToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, orgID string, dateStart, dateEnd string) map[string]interface{} {
+func (mte *MockToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, dateStart, dateEnd string) map[string]interface{} {
 	return map[string]interface{}{
 		"available_units": 8,
 		"date_range":      dateStart + " to " + dateEnd,
@@ -280,7 +302,7 @@ func (mte *MockToolExecutor) ExecuteGetFleetAvailability(ctx context.Context, or
 }
 
 // ExecuteGetBookingList mock
-func (mte *MockToolExecutor) ExecuteGetBookingList(ctx context.Context, orgID string, status string, limit int) []map[string]interface{} {
+func (mte *MockToolExecutor) ExecuteGetBookingList(ctx context.Context, status string, limit int) []map[string]interface{} {
 	return []map[string]interface{}{
 		{
 			"id":          1001,
@@ -294,7 +316,7 @@ func (mte *MockToolExecutor) ExecuteGetBookingList(ctx context.Context, orgID st
 }
 
 // ExecuteGetRevenueSummary mock
-func (mte *MockToolExecutor) ExecuteGetRevenueSummary(ctx context.Context, orgID string, period string) map[string]interface{} {
+func (mte *MockToolExecutor) ExecuteGetRevenueSummary(ctx context.Context, period string) map[string]interface{} {
 	return map[string]interface{}{
 		"period":              period,
 		"total_revenue":       2500000,
@@ -1,7 +1,7 @@
 package waai
 
 import (
-	"log"
+	"log/slog"
 	"service-travego/internal/wagy"
 	"sync"
 )
@@ -22,7 +22,7 @@ func NewWagyClientRegistry() *WagyClientRegistry {
 // Membuat client baru (lazy) jika belum ada di cache.
 func (r *WagyClientRegistry) GetClient(assistantDeviceID, deviceToken string) *wagy.WagyClient {
 	if assistantDeviceID == "" || deviceToken == "" {
-		log.Printf("[WAAI][Registry] Empty assistantDeviceID or deviceToken, cannot create client")
+		slog.Error("assistant registry: cannot create client without a device id and token")
 		return nil
 	}
 
@@ -44,7 +44,7 @@ func (r *WagyClientRegistry) GetClient(assistantDeviceID, deviceToken string) *w
 	client = wagy.NewWagyClient(assistantDeviceID, deviceToken)
 	r.clients[assistantDeviceID] = client
 
-	log.Printf("[WAAI][Registry] Registered WagyClient for device: %s", assistantDeviceID)
+	slog.Info("assistant registry: client registered", "assistant_device_id", assistantDeviceID)
 	return client
 }
 
@@ -53,5 +53,5 @@ func (r *WagyClientRegistry) Invalidate(assistantDeviceID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.clients, assistantDeviceID)
-	log.Printf("[WAAI][Registry] Invalidated client for device: %s", assistantDeviceID)
+	slog.Info("assistant registry: client invalidated", "assistant_device_id", assistantDeviceID)
 }
@@ -6,11 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"service-travego/helper"
+	"service-travego/internal/telemetry"
 	"strings"
 	"time"
 )
@@ -111,8 +112,6 @@ func (wc *WagyClient) SendDocumentWithURL(phone, filename, mediaURL, caption str
 // SendDocumentWithURLAndHook sends a document via URL and reports the result to the provided hook.
 func (wc *WagyClient) SendDocumentWithURLAndHook(phone, filename, mediaURL, caption string, onResult func(error)) (int64, error) {
 	url := fmt.Sprintf("%s/%s/send", wc.baseURL, wc.deviceID)
-	log.Printf("[Wagy] SendDocumentWithURL filename=%s media_url=%s", filename, mediaURL)
-
 	payload := SendDocumentRequest{
 		Phone:     phone,
 		Filename:  filename,
@@ -121,7 +120,7 @@ func (wc *WagyClient) SendDocumentWithURLAndHook(phone, filename, mediaURL, capt
 		MediaURL:  mediaURL,
 	}
 
-	log.Printf("[Wagy] payload: %+v", payload)
+	slog.Debug("wagy: send document", "payload", payload)
 
 	return wc.sendJSONWithHook(url, payload, onResult)
 }
@@ -215,6 +214,11 @@ func (wc *WagyClient) sendJSON(url string, payload interface{}) (int64, error) {
 
 func (wc *WagyClient) sendJSONWithHook(url string, payload interface{}, onResult func(error)) (messageID int64, retErr error) {
 	defer func() {
+		outcome := "success"
+		if retErr != nil {
+			outcome = "failure"
+		}
+		telemetry.WagySends.Inc(sendKind(payload), outcome)
 		if onResult != nil {
 			onResult(retErr)
 		}
@@ -252,6 +256,14 @@ func (wc *WagyClient) sendJSONWithHook(url string, payload interface{}, onResult
 	return messageID, retErr
 }
 
+// sendKind labels a send in the metrics: message, document or image
+func sendKind(payload interface{}) string {
+	if media, ok := payload.(SendDocumentRequest); ok && media.MediaType != "" {
+		return media.MediaType
+	}
+	return "message"
+}
+
 func parseWagySendResponse(statusCode int, respBody []byte) (int64, error) {
 	trimmedBody := strings.TrimSpace(string(respBody))
 	if trimmedBody == "" {
@@ -1,16 +1,21 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"os/signal"
 	"service-travego/configs"
 	"service-travego/helper"
+	"service-travego/internal/supervisor"
+	"service-travego/internal/telemetry"
 	"service-travego/routes"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
@@ -20,21 +25,26 @@ func main() {
 	// APP_ENV=production, APP_ENV=preprod, APP_ENV=development (default)
 	err := helper.LoadEnv()
 	if err != nil {
-		log.Printf("Warning: Failed to load .env file: %v. Continuing with system environment variables.", err)
+		slog.Warn("failed to load .env file, continuing with system environment variables", "error", err)
 	}
 
 	// Load configuration from JSON (will be overridden by env vars if present)
 	cfg, err := configs.LoadConfig("config/app.json")
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
 	}
 
 	// Override config with environment variables if they exist
 	configs.OverrideWithEnv(cfg)
 
+	// Structured JSON logging and, when an OTLP endpoint is configured, trace export
+	shutdownTelemetry := telemetry.Setup(cfg.App.Name)
+
 	// Validate email configuration
 	if err := configs.ValidateEmailConfig(&cfg.Email); err != nil {
-		log.Fatalf("Email configuration error: %v", err)
+		slog.Error("email configuration error", "error", err)
+		os.Exit(1)
 	}
 
 	// Initialize Fiber app
@@ -55,11 +65,7 @@ func main() {
 	// Middleware
 	app.Use(helper.TransactionIDMiddleware())
 	app.Use(helper.BodyCaptureMiddleware())
-	app.Use(logger.New(logger.Config{
-		Format:     "${time} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
-		TimeFormat: "15:04:05",
-		Output:     os.Stdout,
-	}))
+	app.Use(helper.RequestTelemetryMiddleware())
 	app.Use(recover.New(recover.Config{
 		EnableStackTrace: true,
 	}))
@@ -83,8 +89,45 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Drain for at most SHUTDOWN_TIMEOUT_SECONDS after SIGINT/SIGTERM (default 30)
+	shutdownTimeout := 30 * time.Second
+	if v := os.Getenv("SHUTDOWN_TIMEOUT_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			shutdownTimeout = time.Duration(n) * time.Second
+		}
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "port", port)
+		serverErr <- app.Listen(":" + port)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-serverErr:
+		slog.Error("failed to start server", "error", err)
+		os.Exit(1)
+	case sig := <-quit:
+		slog.Info("shutting down", "signal", sig.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Stop taking requests first so no new background jobs are started, then
+	// wait for the cron runs and async workers still in flight
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to stop server gracefully", "error", err)
+	}
+	if err := supervisor.Shutdown(ctx); err != nil {
+		slog.ErrorContext(ctx, "background jobs did not finish before the deadline", "error", err)
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := shutdownTelemetry(flushCtx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
 	}
 }
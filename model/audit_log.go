@@ -0,0 +1,71 @@
+package model
+
+// Entity types recorded in the audit log
+const (
+	AuditEntityFleet            = "fleet"
+	AuditEntityOrder            = "order"
+	AuditEntitySchedule         = "schedule"
+	AuditEntityInventoryItem    = "inventory_item"
+	AuditEntityInventoryRequest = "inventory_request"
+	AuditEntityInventoryOrder   = "inventory_order"
+	AuditEntitySupplier         = "supplier"
+	AuditEntityTransaction      = "transaction"
+	AuditEntityOrganization     = "organization"
+	AuditEntityBankAccount      = "bank_account"
+	AuditEntityAPIKey           = "api_key"
+	AuditEntityMember           = "member"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionCancel  = "cancel"
+	AuditActionApprove = "approve"
+	AuditActionReject  = "reject"
+	AuditActionProcess = "process"
+	AuditActionReceive = "receive"
+	AuditActionRotate  = "rotate"
+	AuditActionRevoke  = "revoke"
+)
+
+// AuditChange is a field's value before and after a change
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLog is one recorded mutation
+type AuditLog struct {
+	AuditID        string                 `json:"audit_id"`
+	OrganizationID string                 `json:"organization_id"`
+	ActorID        string                 `json:"actor_id"`
+	ActorName      string                 `json:"actor_name"`
+	EntityType     string                 `json:"entity_type"`
+	EntityID       string                 `json:"entity_id"`
+	Action         string                 `json:"action"`
+	Changes        map[string]AuditChange `json:"changes"`
+	CreatedAt      string                 `json:"created_at"`
+}
+
+// AuditLogFilter filters the audit log of an organization. StartDate and
+// EndDate are YYYY-MM-DD and inclusive.
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   string
+	ActorID    string
+	Action     string
+	StartDate  string
+	EndDate    string
+	Page       int
+	PerPage    int
+}
+
+// AuditLogList is a page of the audit log
+type AuditLogList struct {
+	Items   []AuditLog `json:"items"`
+	Page    int        `json:"page"`
+	PerPage int        `json:"per_page"`
+	Total   int        `json:"total"`
+}
@@ -22,10 +22,20 @@ type ResendOTPRequest struct {
 }
 
 // LoginRequest represents login request payload
+// DeviceID and DeviceName identify the device for the session list; without
+// them the User-Agent is used.
 type LoginRequest struct {
-	Email    string `json:"email" validate:"omitempty,email"`
-	Phone    string `json:"phone" validate:"omitempty"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"omitempty,email"`
+	Phone      string `json:"phone" validate:"omitempty"`
+	Password   string `json:"password" validate:"required"`
+	DeviceID   string `json:"device_id" validate:"omitempty,max=100"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=100"`
+}
+
+// LoginTwoFactorRequest completes a login with a TOTP or recovery code
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
 }
 
 // RequestResetPasswordRequest represents request reset password payload
@@ -44,3 +54,48 @@ type UpdatePasswordRequest struct {
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+// TwoFactorCodeRequest carries a TOTP code (or a recovery code)
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// DisableTwoFactorRequest represents disable two-factor request payload
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RevokeSessionRequest represents revoke session request payload
+type RevokeSessionRequest struct {
+	SessionID string `json:"session_id" validate:"required"`
+}
+
+// TwoFactorStatus represents the two-factor state of the logged in user
+type TwoFactorStatus struct {
+	Enabled           bool   `json:"enabled"`
+	EnabledAt         string `json:"enabled_at,omitempty"`
+	RecoveryCodesLeft int    `json:"recovery_codes_left"`
+}
+
+// TwoFactorSetup is returned when two-factor is set up; Secret and OTPAuthURL
+// are added to an authenticator app
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// TwoFactorRecoveryCodes are shown once, when generated
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginSession represents a device the user is logged in on
+type LoginSession struct {
+	SessionID  string `json:"session_id"`
+	DeviceName string `json:"device_name"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	Current    bool   `json:"current"`
+}
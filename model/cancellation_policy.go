@@ -0,0 +1,121 @@
+package model
+
+// Cancellation policy statuses (cancellation_policies.status). Saving a policy
+// inserts a new version and supersedes the previous one, so refunds keep
+// pointing at the exact version they were computed with.
+const (
+	CancellationPolicyStatusDeleted    = 0
+	CancellationPolicyStatusActive     = 1
+	CancellationPolicyStatusSuperseded = 2
+)
+
+// Cancellation request sources (order_cancellation_requests.source).
+const (
+	CancellationSourceWeb      = "WEB"
+	CancellationSourceWhatsApp = "WHATSAPP"
+)
+
+// Cancellation request statuses (order_cancellation_requests.status).
+const (
+	CancellationRequestPending  = 1
+	CancellationRequestApproved = 2
+	CancellationRequestRejected = 3
+)
+
+var CancellationRequestStatusLabel = map[int]string{
+	CancellationRequestPending:  "Menunggu Persetujuan",
+	CancellationRequestApproved: "Disetujui",
+	CancellationRequestRejected: "Ditolak",
+}
+
+var CancellationSourceLabel = map[string]string{
+	CancellationSourceWeb:      "Website",
+	CancellationSourceWhatsApp: "WhatsApp",
+}
+
+type CancellationPolicy struct {
+	PolicyID            string                   `json:"policy_id"`
+	Version             int                      `json:"version"`
+	Name                string                   `json:"name"`
+	Description         string                   `json:"description"`
+	NonRefundableAddons bool                     `json:"non_refundable_addons"`
+	Active              bool                     `json:"active"`
+	Tiers               []CancellationPolicyTier `json:"tiers"`
+	CreatedDate         string                   `json:"created_date"`
+}
+
+// CancellationPolicyTier refunds RefundPercentage of the paid amount when the
+// order is cancelled at least MinDaysBefore days before the start date.
+type CancellationPolicyTier struct {
+	Seq              int     `json:"seq"`
+	MinDaysBefore    int     `json:"min_days_before"`
+	RefundPercentage float64 `json:"refund_percentage"`
+}
+
+type CancellationPolicySaveRequest struct {
+	Name                string                   `json:"name" validate:"required"`
+	Description         string                   `json:"description"`
+	NonRefundableAddons bool                     `json:"non_refundable_addons"`
+	Tiers               []CancellationPolicyTier `json:"tiers"`
+
+	OrganizationID string `json:"-"`
+	UserID         string `json:"-"`
+}
+
+// CancellationRefundQuote is the refund an order gets when cancelled now.
+// PolicyVersion is 0 when the organization has no policy yet, in which case
+// everything paid is refunded.
+type CancellationRefundQuote struct {
+	OrderID             string  `json:"order_id"`
+	PolicyID            string  `json:"policy_id"`
+	PolicyVersion       int     `json:"policy_version"`
+	PolicyName          string  `json:"policy_name"`
+	StartDate           string  `json:"start_date"`
+	DaysBeforeStart     int     `json:"days_before_start"`
+	RefundPercentage    float64 `json:"refund_percentage"`
+	PaidAmount          float64 `json:"paid_amount"`
+	NonRefundableAmount float64 `json:"non_refundable_amount"`
+	RefundableAmount    float64 `json:"refundable_amount"`
+	RefundAmount        float64 `json:"refund_amount"`
+}
+
+type OrderCancellationRequest struct {
+	RequestID        string  `json:"request_id"`
+	OrderID          string  `json:"order_id"`
+	Source           string  `json:"source"`
+	SourceLabel      string  `json:"source_label"`
+	Reason           string  `json:"reason"`
+	PaymentMethod    int     `json:"payment_method"`
+	BankCode         string  `json:"bank_code"`
+	BankAccount      string  `json:"bank_account"`
+	BankAccountName  string  `json:"bank_account_name"`
+	PolicyVersion    int     `json:"policy_version"`
+	RefundPercentage float64 `json:"refund_percentage"`
+	RefundAmount     float64 `json:"refund_amount"`
+	Status           int     `json:"status"`
+	StatusLabel      string  `json:"status_label"`
+	CustomerName     string  `json:"customer_name"`
+	CustomerPhone    string  `json:"customer_phone"`
+	RequestedDate    string  `json:"requested_date"`
+	ReviewedDate     string  `json:"reviewed_date"`
+	ReviewNote       string  `json:"review_note"`
+}
+
+// OrderCancellationSubmitRequest is a customer asking to cancel an order. Token
+// is the encrypted order token of the public order API.
+type OrderCancellationSubmitRequest struct {
+	Token           string `json:"token"`
+	Reason          string `json:"reason"`
+	PaymentMethod   int    `json:"payment_method"`
+	BankCode        string `json:"bank_code"`
+	BankAccount     string `json:"bank_account"`
+	BankAccountName string `json:"bank_account_name"`
+
+	OrderID string `json:"-"`
+	Source  string `json:"-"`
+}
+
+type OrderCancellationReviewRequest struct {
+	RequestID string `json:"request_id" validate:"required"`
+	Note      string `json:"note"`
+}
@@ -0,0 +1,107 @@
+package model
+
+import "time"
+
+// Document owner types (documents.owner_type)
+const (
+	DocumentOwnerUnit     = "UNIT"
+	DocumentOwnerEmployee = "EMPLOYEE"
+)
+
+// Document types
+const (
+	DocumentTypeSTNK      = "STNK"
+	DocumentTypeKIR       = "KIR"
+	DocumentTypeInsurance = "INSURANCE"
+	DocumentTypeSIM       = "SIM"
+	DocumentTypeKTP       = "KTP"
+	DocumentTypeOther     = "OTHER"
+)
+
+var DocumentTypeLabel = map[string]string{
+	DocumentTypeSTNK:      "STNK",
+	DocumentTypeKIR:       "KIR",
+	DocumentTypeInsurance: "Asuransi",
+	DocumentTypeSIM:       "SIM",
+	DocumentTypeKTP:       "KTP",
+	DocumentTypeOther:     "Lainnya",
+}
+
+// DocumentOwnerTypes lists the document types accepted per owner type.
+var DocumentOwnerTypes = map[string][]string{
+	DocumentOwnerUnit:     {DocumentTypeSTNK, DocumentTypeKIR, DocumentTypeInsurance, DocumentTypeOther},
+	DocumentOwnerEmployee: {DocumentTypeSIM, DocumentTypeKTP, DocumentTypeOther},
+}
+
+// DocumentReminderDays are the number of days before expiry a reminder is sent.
+var DocumentReminderDays = []int{30, 14, 3}
+
+type Document struct {
+	DocumentID     string `json:"document_id"`
+	OrganizationID string `json:"organization_id"`
+	OwnerType      string `json:"owner_type"`
+	OwnerID        string `json:"owner_id"`
+	OwnerName      string `json:"owner_name"`
+	DocumentType   string `json:"document_type"`
+	DocumentLabel  string `json:"document_label"`
+	DocumentNumber string `json:"document_number"`
+	IssuedDate     string `json:"issued_date"`
+	ExpiryDate     string `json:"expiry_date"`
+	DaysToExpiry   int    `json:"days_to_expiry"`
+	IsExpired      bool   `json:"is_expired"`
+	FilePath       string `json:"file_path"`
+	FileURL        string `json:"file_url"`
+	Notes          string `json:"notes"`
+	CreatedBy      string `json:"created_by"`
+	CreatedDate    string `json:"created_date"`
+}
+
+type DocumentUpsertRequest struct {
+	DocumentID     string `json:"document_id" form:"document_id"`
+	OwnerType      string `json:"owner_type" form:"owner_type" validate:"required"`
+	OwnerID        string `json:"owner_id" form:"owner_id" validate:"required"`
+	DocumentType   string `json:"document_type" form:"document_type" validate:"required"`
+	DocumentNumber string `json:"document_number" form:"document_number"`
+	IssuedDate     string `json:"issued_date" form:"issued_date"`
+	ExpiryDate     string `json:"expiry_date" form:"expiry_date" validate:"required"`
+	FilePath       string `json:"file_path" form:"file_path"`
+	Notes          string `json:"notes" form:"notes"`
+
+	OrganizationID string    `json:"-" form:"-"`
+	UserID         string    `json:"-" form:"-"`
+	IssuedAt       time.Time `json:"-" form:"-"`
+	ExpiryAt       time.Time `json:"-" form:"-"`
+}
+
+type DocumentDeleteRequest struct {
+	DocumentID string `json:"document_id" validate:"required"`
+}
+
+type DocumentListFilter struct {
+	OwnerType      string
+	OwnerID        string
+	ExpiringWithin int
+}
+
+// DocumentReminderRow is a document due for a reminder, as read by the expiry cron.
+type DocumentReminderRow struct {
+	DocumentID     string
+	OrganizationID string
+	OwnerType      string
+	OwnerID        string
+	OwnerName      string
+	OwnerPhone     string
+	DocumentType   string
+	DocumentNumber string
+	ExpiryDate     time.Time
+	DaysBefore     int
+}
+
+// ScheduleExpiredDocumentRow is a unit or crew document that lapses before a trip ends.
+type ScheduleExpiredDocumentRow struct {
+	OwnerType    string
+	OwnerID      string
+	OwnerName    string
+	DocumentType string
+	ExpiryDate   time.Time
+}
@@ -0,0 +1,52 @@
+package model
+
+// Quotas a subscription package limits
+const (
+	QuotaDashboardUsers = "dashboard_users"
+	QuotaAssistantUsers = "assistant_users"
+	QuotaFleets         = "fleets"
+	QuotaFleetUnits     = "fleet_units"
+	QuotaPartners       = "partners"
+)
+
+// Quotas lists every quota in the order the usage endpoint shows them
+var Quotas = []string{QuotaDashboardUsers, QuotaAssistantUsers, QuotaFleets, QuotaFleetUnits, QuotaPartners}
+
+// QuotaLabels are the names of the quotas as the package features word them
+var QuotaLabels = map[string]string{
+	QuotaDashboardUsers: "Pengguna Dashboard",
+	QuotaAssistantUsers: "Pengguna AI Assistant",
+	QuotaFleets:         "Armada Terdaftar",
+	QuotaFleetUnits:     "Unit Armada Terdaftar",
+	QuotaPartners:       "Mitra KSO Terdaftar",
+}
+
+// PackageEntitlements maps a quota to the number of records a package allows;
+// a quota that is null or missing is unlimited
+type PackageEntitlements map[string]*int
+
+// Limit returns the limit of quota and whether there is one
+func (e PackageEntitlements) Limit(quota string) (int, bool) {
+	limit, ok := e[quota]
+	if !ok || limit == nil {
+		return 0, false
+	}
+	return *limit, true
+}
+
+// QuotaUsage is how much of one quota an organization uses; Limit and
+// Remaining are null when the quota is unlimited
+type QuotaUsage struct {
+	Quota     string `json:"quota"`
+	Label     string `json:"label"`
+	Used      int    `json:"used"`
+	Limit     *int   `json:"limit"`
+	Remaining *int   `json:"remaining"`
+}
+
+// SubscriptionUsage is the response of GET /api/account/subscription/usage
+type SubscriptionUsage struct {
+	PackageID   string       `json:"package_id"`
+	PackageName string       `json:"package_name"`
+	Quotas      []QuotaUsage `json:"quotas"`
+}
@@ -33,6 +33,9 @@ type FleetOrderFleetItem struct {
 	Discount  float64  `json:"discount"`
 	Addons    []string `json:"addons"`
 	AddonID   string   `json:"addon_id,omitempty"`
+
+	RuleAdjustment float64         `json:"-"`
+	PriceRuleLines []PriceRuleLine `json:"-"`
 }
 
 type FleetOrderAddonItem struct {
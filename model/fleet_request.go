@@ -290,7 +290,6 @@ type FleetOrderCancelRequest struct {
 	PaymentMethod      int    `json:"payment_method"`
 	PaymentMethodLabel string `json:"payment_method_label"`
 	Reason             string `json:"reason"`
-	RefundPercentage   int    `json:"refund_percentage"`
 	BankAccount        string `json:"bank_account,omitempty"`
 	BankId             string `json:"bank_id,omitempty"`
 	BankAccountName    string `json:"bank_account_name,omitempty"`
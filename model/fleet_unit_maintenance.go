@@ -0,0 +1,156 @@
+package model
+
+import "time"
+
+// Maintenance work order status (fleet_unit_maintenance.status)
+const (
+	FleetUnitMaintenanceStatusCancelled  = 0
+	FleetUnitMaintenanceStatusScheduled  = 1
+	FleetUnitMaintenanceStatusInWorkshop = 2
+	FleetUnitMaintenanceStatusCompleted  = 3
+)
+
+// Service types used by service plans and work orders
+const (
+	FleetUnitServiceTypeGeneral   = "GENERAL"
+	FleetUnitServiceTypeOilChange = "OIL_CHANGE"
+	FleetUnitServiceTypeTire      = "TIRE"
+	FleetUnitServiceTypeBrake     = "BRAKE"
+	FleetUnitServiceTypeKIR       = "KIR"
+	FleetUnitServiceTypeSTNKTax   = "STNK_TAX"
+)
+
+var FleetUnitServiceTypeLabel = map[string]string{
+	FleetUnitServiceTypeGeneral:   "Servis Berkala",
+	FleetUnitServiceTypeOilChange: "Ganti Oli",
+	FleetUnitServiceTypeTire:      "Ganti Ban",
+	FleetUnitServiceTypeBrake:     "Servis Rem",
+	FleetUnitServiceTypeKIR:       "Uji KIR",
+	FleetUnitServiceTypeSTNKTax:   "Pajak STNK",
+}
+
+var FleetUnitMaintenanceStatusLabel = map[int]string{
+	FleetUnitMaintenanceStatusCancelled:  "Dibatalkan",
+	FleetUnitMaintenanceStatusScheduled:  "Dijadwalkan",
+	FleetUnitMaintenanceStatusInWorkshop: "Di Bengkel",
+	FleetUnitMaintenanceStatusCompleted:  "Selesai",
+}
+
+type FleetUnitOdometerRequest struct {
+	UnitID     string `json:"unit_id" validate:"required"`
+	OdometerKm int64  `json:"odometer_km" validate:"required"`
+	RecordedAt string `json:"recorded_at"`
+	Notes      string `json:"notes"`
+}
+
+type FleetUnitOdometerReading struct {
+	ReadingID  string `json:"reading_id"`
+	UnitID     string `json:"unit_id"`
+	OdometerKm int64  `json:"odometer_km"`
+	RecordedAt string `json:"recorded_at"`
+	Notes      string `json:"notes"`
+	CreatedBy  string `json:"created_by"`
+}
+
+type FleetUnitServicePlan struct {
+	PlanID          string `json:"plan_id"`
+	UnitID          string `json:"unit_id"`
+	PlateNumber     string `json:"plate_number,omitempty"`
+	ServiceType     string `json:"service_type"`
+	ServiceLabel    string `json:"service_label"`
+	IntervalKm      int64  `json:"interval_km"`
+	IntervalDays    int    `json:"interval_days"`
+	LastServiceKm   int64  `json:"last_service_km"`
+	LastServiceDate string `json:"last_service_date"`
+	NextDueKm       int64  `json:"next_due_km"`
+	NextDueDate     string `json:"next_due_date"`
+	CurrentKm       int64  `json:"current_km"`
+	IsDue           bool   `json:"is_due"`
+	Notes           string `json:"notes"`
+}
+
+type FleetUnitServicePlanRequest struct {
+	PlanID          string `json:"plan_id"`
+	UnitID          string `json:"unit_id" validate:"required"`
+	ServiceType     string `json:"service_type" validate:"required"`
+	IntervalKm      int64  `json:"interval_km"`
+	IntervalDays    int    `json:"interval_days"`
+	LastServiceKm   int64  `json:"last_service_km"`
+	LastServiceDate string `json:"last_service_date"`
+	Notes           string `json:"notes"`
+
+	OrganizationID string    `json:"-"`
+	UserID         string    `json:"-"`
+	LastServiceAt  time.Time `json:"-"`
+}
+
+type FleetUnitServicePlanDeleteRequest struct {
+	PlanID string `json:"plan_id" validate:"required"`
+}
+
+type FleetUnitMaintenancePartRequest struct {
+	ItemID   string `json:"item_id" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+}
+
+type FleetUnitMaintenanceCreateRequest struct {
+	UnitID      string                            `json:"unit_id" validate:"required"`
+	GarageID    string                            `json:"garage_id" validate:"required"`
+	PlanID      string                            `json:"plan_id"`
+	ServiceType string                            `json:"service_type" validate:"required"`
+	Description string                            `json:"description"`
+	OdometerKm  int64                             `json:"odometer_km"`
+	StartDate   string                            `json:"start_date" validate:"required"`
+	EndDate     string                            `json:"end_date" validate:"required"`
+	InWorkshop  bool                              `json:"in_workshop"`
+	Parts       []FleetUnitMaintenancePartRequest `json:"parts" validate:"dive"`
+
+	OrganizationID string    `json:"-"`
+	UserID         string    `json:"-"`
+	StartAt        time.Time `json:"-"`
+	EndAt          time.Time `json:"-"`
+}
+
+type FleetUnitMaintenanceCompleteRequest struct {
+	MaintenanceID string  `json:"maintenance_id" validate:"required"`
+	OdometerKm    int64   `json:"odometer_km"`
+	LaborCost     float64 `json:"labor_cost"`
+	CompletedDate string  `json:"completed_date"`
+	Notes         string  `json:"notes"`
+}
+
+type FleetUnitMaintenanceStatusRequest struct {
+	MaintenanceID string `json:"maintenance_id" validate:"required"`
+}
+
+type FleetUnitMaintenancePart struct {
+	ItemID   string  `json:"item_id"`
+	ItemName string  `json:"item_name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type FleetUnitMaintenance struct {
+	MaintenanceID string                     `json:"maintenance_id"`
+	UnitID        string                     `json:"unit_id"`
+	PlateNumber   string                     `json:"plate_number"`
+	GarageID      string                     `json:"garage_id"`
+	GarageName    string                     `json:"garage_name"`
+	PlanID        string                     `json:"plan_id"`
+	ServiceType   string                     `json:"service_type"`
+	ServiceLabel  string                     `json:"service_label"`
+	Description   string                     `json:"description"`
+	OdometerKm    int64                      `json:"odometer_km"`
+	StartDate     string                     `json:"start_date"`
+	EndDate       string                     `json:"end_date"`
+	CompletedDate string                     `json:"completed_date"`
+	Status        int                        `json:"status"`
+	StatusLabel   string                     `json:"status_label"`
+	PartsCost     float64                    `json:"parts_cost"`
+	LaborCost     float64                    `json:"labor_cost"`
+	TotalCost     float64                    `json:"total_cost"`
+	Notes         string                     `json:"notes"`
+	CreatedBy     string                     `json:"created_by"`
+	CreatedDate   string                     `json:"created_date"`
+	Parts         []FleetUnitMaintenancePart `json:"parts,omitempty"`
+}
@@ -9,10 +9,11 @@ type GeneralConfig struct {
 
 // MenuItem represents a menu item
 type MenuItem struct {
-	Title    string     `json:"title"`
-	Desc     string     `json:"desc"`
-	URL      string     `json:"url"`
-	SubMenus []MenuItem `json:"subMenus,omitempty"`
+	Title      string     `json:"title"`
+	Desc       string     `json:"desc"`
+	URL        string     `json:"url"`
+	Permission string     `json:"permission,omitempty"`
+	SubMenus   []MenuItem `json:"subMenus,omitempty"`
 }
 
 // Bank represents bank information
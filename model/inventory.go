@@ -280,6 +280,7 @@ type ReceiveInventoryOrderRequest struct {
 
 type Supplier struct {
 	SupplierID        string    `json:"suplier_id"`
+	OrganizationID    string    `json:"organization_id"`
 	SupplierName      string    `json:"suplier_name"`
 	SupplierAddress   string    `json:"suplier_address"`
 	SupplierCity      int       `json:"suplier_city"`
@@ -5,6 +5,15 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
+)
+
+// Leave statuses. Leaves recorded through the leave menu are approved right
+// away; only approved leaves keep an employee off schedules.
+const (
+	LeaveStatusRejected = 0
+	LeaveStatusApproved = 1
+	LeaveStatusPending  = 2
 )
 
 type LeaveManagementTypeItem struct {
@@ -83,3 +92,14 @@ func (r *LeaveManagementCreateRequest) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// EmployeeLeavePeriod is a leave of an employee within a period, with or
+// without a substitute
+type EmployeeLeavePeriod struct {
+	EmployeeID     string
+	SubstitutedBy  string
+	Status         int
+	StartDate      time.Time
+	EndDate        time.Time
+	LeaveTypeLabel string
+}
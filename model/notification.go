@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Notification is a message shown in an organization's dashboard
+type Notification struct {
+	NotificationID string    `json:"notification_id"`
+	ReferenceURL   string    `json:"reference_url"`
+	Title          string    `json:"title"`
+	Message        string    `json:"message"`
+	CreatedAt      time.Time `json:"created_at"`
+	IsRead         bool      `json:"is_read"`
+}
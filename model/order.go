@@ -6,8 +6,16 @@ import (
 )
 
 type OrderFleetSummaryRequest struct {
-	FleetID string `json:"fleet_id" validate:"required"`
-	PriceID string `json:"price_id" validate:"required"`
+	FleetID        string `json:"fleet_id" validate:"required"`
+	PriceID        string `json:"price_id" validate:"required"`
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	PickupCityID   string `json:"pickup_city_id"`
+	Qty            int    `json:"qty"`
+	VoucherCode    string `json:"voucher_code"`
+	Phone          string `json:"phone"`
+	Email          string `json:"email"`
+	OrganizationID string `json:"-"`
 }
 
 type OrderFleetSummaryResponse struct {
@@ -27,8 +35,10 @@ type OrderFleetSummaryResponse struct {
 	Price         float64 `json:"price"`
 	Uom           string  `json:"uom"`
 
-	Facilities   []string      `json:"facilities"`
-	PickupPoints []PickupPoint `json:"pickup_points"`
+	Facilities   []string            `json:"facilities"`
+	PickupPoints []PickupPoint       `json:"pickup_points"`
+	Pricing      *PriceQuote         `json:"pricing,omitempty"`
+	Voucher      *VoucherApplication `json:"voucher,omitempty"`
 }
 
 type PickupPoint struct {
@@ -59,6 +69,13 @@ type CreateOrderRequest struct {
 	OrganizationCode  string             `json:"-"`
 	OrderID           string             `json:"-"`
 	TotalAmount       float64            `json:"-"`
+	RuleAdjustment    float64            `json:"-"`
+	PriceRuleLines    []PriceRuleLine    `json:"-"`
+	VoucherCode       string             `json:"voucher_code"`
+	PaymentPlanID     string             `json:"payment_plan_id"`
+
+	Voucher      *VoucherApplication `json:"-"`
+	Installments []OrderInstallment  `json:"-"`
 }
 
 type OrderDestination struct {
@@ -72,8 +89,12 @@ type OrderTokenPayload struct {
 }
 
 type CreateOrderResponse struct {
-	Token   string `json:"token"`
-	OrderID string `json:"order_id"`
+	Token   string              `json:"token"`
+	OrderID string              `json:"order_id"`
+	Pricing *PriceQuote         `json:"pricing,omitempty"`
+	Voucher *VoucherApplication `json:"voucher,omitempty"`
+
+	Installments []OrderInstallment `json:"installments,omitempty"`
 }
 
 type GetOrderListRequest struct {
@@ -129,8 +150,12 @@ type OrderDetailResponse struct {
 	StartDate          string                    `json:"start_date"`
 	EndDate            string                    `json:"end_date"`
 	Fleets             []OrderDetailFleetItem    `json:"fleets"`
+	PriceRules         []PriceRuleLine           `json:"price_rules"`
 	Scheduled          bool                      `json:"scheduled"`
 	UpdatedAt          string                    `json:"updated_at"`
+	Installments       []OrderInstallment        `json:"installments,omitempty"`
+	CancellationRefund *CancellationRefundQuote  `json:"cancellation_refund,omitempty"`
+	CancellationReq    *OrderCancellationRequest `json:"cancellation_request,omitempty"`
 }
 
 type OrderReviewItem struct {
@@ -211,6 +236,13 @@ type PartnerOrderListFilter struct {
 	HasPaymentStatus bool
 }
 
+// PartnerOrderCursor is the last order of a page of the order list, which is
+// in (created_at, order_id) descending order
+type PartnerOrderCursor struct {
+	CreatedAt time.Time
+	OrderID   string
+}
+
 type ServiceOrderListRequest struct {
 	OrderType   string `query:"order_type"`
 	ProcessType string `query:"process_type"`
@@ -276,6 +308,7 @@ type CreatePaymentRequest struct {
 	PaymentMethod     string  `json:"payment_method"`
 	PaymentType       int     `json:"payment_type"`
 	PaymentPercentage float64 `json:"payment_percentage"`
+	InstallmentID     string  `json:"installment_id"`
 	OrganizationID    string  `json:"-"`
 }
 
@@ -348,6 +381,12 @@ type FleetOrderPayment struct {
 	AccountNumber     string        `json:"account_number"`
 	AccountName       string        `json:"account_name"`
 	UniqueCode        int           `json:"unique_code"`
+
+	InstallmentID  string `json:"installment_id,omitempty"`
+	InvoiceNumber  string `json:"invoice_number,omitempty"`
+	SnapToken      string `json:"snap_token,omitempty"`
+	RedirectURL    string `json:"redirect_url,omitempty"`
+	PaymentGateway string `json:"payment_gateway,omitempty"`
 }
 
 type OrderPaymentHistory struct {
@@ -508,4 +547,6 @@ type PaymentSummary struct {
 	PaymentMethodLabel string  `json:"payment_method_label"`
 	PaymentStatus      string  `json:"payment_status"`
 	PaymentDate        string  `json:"payment_date"`
+
+	Installments []OrderInstallment `json:"installments,omitempty"`
 }
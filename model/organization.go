@@ -112,16 +112,29 @@ type OrganizationDivision struct {
 }
 
 type OrganizationRole struct {
-	RoleID       string `json:"role_id"`
-	RoleName     string `json:"role_name"`
-	Description  string `json:"description"`
-	DivisionID   string `json:"division_id"`
-	DivisionName string `json:"division_name"`
-	Status       int    `json:"status"`
-	CreatedBy    string `json:"created_by"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedBy    string `json:"updated_by"`
-	UpdatedAt    string `json:"updated_at"`
+	RoleID       string   `json:"role_id"`
+	RoleName     string   `json:"role_name"`
+	Description  string   `json:"description"`
+	DivisionID   string   `json:"division_id"`
+	DivisionName string   `json:"division_name"`
+	Permissions  []string `json:"permissions"`
+	Status       int      `json:"status"`
+	CreatedBy    string   `json:"created_by"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedBy    string   `json:"updated_by"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+// PermissionOption is a permission that can be granted to a role
+type PermissionOption struct {
+	Permission string `json:"permission"`
+	Label      string `json:"label"`
+}
+
+// MemberPermissions lists every permission and the ones the member has
+type MemberPermissions struct {
+	Permissions []PermissionOption `json:"permissions"`
+	Granted     []string           `json:"granted"`
 }
 
 type EmployeeListItem struct {
@@ -213,3 +226,73 @@ type EmployeeOperationsHistoryRequest struct {
 	Period     string `json:"period"`
 	EmployeeID string `json:"employee_id"`
 }
+
+// PaymentGatewayOption adalah payment gateway yang bisa dipilih organisasi
+type PaymentGatewayOption struct {
+	Provider string `json:"provider"`
+	Label    string `json:"label"`
+}
+
+// PaymentGatewaySetting adalah payment gateway yang dipakai organisasi untuk
+// menagih pembayaran pelanggan
+type PaymentGatewaySetting struct {
+	PaymentGateway string                 `json:"payment_gateway"`
+	Options        []PaymentGatewayOption `json:"options"`
+}
+
+type UpdatePaymentGatewayRequest struct {
+	PaymentGateway string `json:"payment_gateway" validate:"required"`
+}
+
+// OrganizationAPIKey adalah API key Open API milik organisasi. Key aslinya
+// hanya ditampilkan sekali saat dibuat atau dirotasi.
+type OrganizationAPIKey struct {
+	APIKeyID         string   `json:"api_key_id"`
+	OrganizationID   string   `json:"organization_id"`
+	OrganizationCode string   `json:"-"`
+	Name             string   `json:"name"`
+	KeyPrefix        string   `json:"key_prefix"`
+	Scopes           []string `json:"scopes"`
+	Status           string   `json:"status"`
+	ExpiresAt        string   `json:"expires_at,omitempty"`
+	LastUsedAt       string   `json:"last_used_at,omitempty"`
+	LastUsedIP       string   `json:"last_used_ip,omitempty"`
+	RevokedAt        string   `json:"revoked_at,omitempty"`
+	CreatedAt        string   `json:"created_at"`
+	CreatedBy        string   `json:"created_by"`
+}
+
+// Status API key
+const (
+	APIKeyStatusActive  = "active"
+	APIKeyStatusExpired = "expired"
+	APIKeyStatusRevoked = "revoked"
+)
+
+// APIKeyScopeOption adalah scope yang bisa diberikan ke API key
+type APIKeyScopeOption struct {
+	Scope string `json:"scope"`
+	Label string `json:"label"`
+}
+
+// OrganizationAPIKeyList berisi API key organisasi dan scope yang tersedia
+type OrganizationAPIKeyList struct {
+	Keys   []OrganizationAPIKey `json:"keys"`
+	Scopes []APIKeyScopeOption  `json:"scopes"`
+}
+
+// CreatedOrganizationAPIKey berisi key asli yang tidak bisa dilihat lagi
+type CreatedOrganizationAPIKey struct {
+	OrganizationAPIKey
+	APIKey string `json:"api_key"`
+}
+
+type CreateOrganizationAPIKeyRequest struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	Scopes    []string `json:"scopes" validate:"required,min=1"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+type OrganizationAPIKeyIDRequest struct {
+	APIKeyID string `json:"api_key_id" validate:"required"`
+}
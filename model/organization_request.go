@@ -49,22 +49,32 @@ type DeleteOrganizationDivisionRequest struct {
 }
 
 type CreateOrganizationRoleRequest struct {
-	RoleName    string `json:"role_name" validate:"required"`
-	Description string `json:"description"`
-	DivisionID  string `json:"division_id" validate:"required"`
+	RoleName    string   `json:"role_name" validate:"required"`
+	Description string   `json:"description"`
+	DivisionID  string   `json:"division_id" validate:"required"`
+	Permissions []string `json:"permissions"`
 }
 
+// UpdateOrganizationRoleRequest keeps the role's permissions when Permissions
+// is omitted
 type UpdateOrganizationRoleRequest struct {
-	RoleID      string `json:"role_id" validate:"required"`
-	RoleName    string `json:"role_name" validate:"required"`
-	Description string `json:"description"`
-	DivisionID  string `json:"division_id" validate:"required"`
+	RoleID      string   `json:"role_id" validate:"required"`
+	RoleName    string   `json:"role_name" validate:"required"`
+	Description string   `json:"description"`
+	DivisionID  string   `json:"division_id" validate:"required"`
+	Permissions []string `json:"permissions"`
 }
 
 type DeleteOrganizationRoleRequest struct {
 	RoleID string `json:"role_id" validate:"required"`
 }
 
+// AssignMemberRoleRequest assigns a role to a member; an empty role_id removes it
+type AssignMemberRoleRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+	RoleID string `json:"role_id"`
+}
+
 type CreateEmployeeRequest struct {
 	EmployeeID     string  `json:"employee_id" validate:"required"`
 	NIK            string  `json:"nik"`
@@ -0,0 +1,45 @@
+package model
+
+// Channels of outgoing messages (outbox_messages.channel)
+const (
+	OutboxChannelWhatsApp = "whatsapp"
+	OutboxChannelEmail    = "email"
+)
+
+// Delivery states of outgoing messages (outbox_messages.status)
+const (
+	OutboxStatusPending = "PENDING"
+	OutboxStatusSending = "SENDING"
+	OutboxStatusSent    = "SENT"
+	OutboxStatusDead    = "DEAD"
+)
+
+// OutboxMessage is one queued WhatsApp message or email. Kind says what it is
+// for (e.g. "order_success"), Subject is only used by emails.
+type OutboxMessage struct {
+	MessageID      string `json:"message_id"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Channel        string `json:"channel"`
+	Kind           string `json:"kind"`
+	Recipient      string `json:"recipient"`
+	Subject        string `json:"subject,omitempty"`
+	Body           string `json:"body"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	MaxAttempts    int    `json:"max_attempts"`
+	LastError      string `json:"last_error,omitempty"`
+	NextAttemptAt  string `json:"next_attempt_at,omitempty"`
+	SentAt         string `json:"sent_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// OutboxMessageFilter filters the outbox list of the admin endpoint
+type OutboxMessageFilter struct {
+	Status         string
+	Channel        string
+	Kind           string
+	OrganizationID string
+	Recipient      string
+	Limit          int
+}
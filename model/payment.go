@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // PaymentRequest adalah payload dari UI untuk membuat pembayaran
 type PaymentRequest struct {
 	OrderID        string `json:"order_id"`
@@ -13,8 +15,10 @@ type PaymentRequest struct {
 
 // PaymentResponse adalah response sukses create payment
 type PaymentResponse struct {
-	SnapToken string `json:"snap_token"`
-	OrderID   string `json:"order_id"`
+	SnapToken      string `json:"snap_token"`
+	OrderID        string `json:"order_id"`
+	PaymentGateway string `json:"payment_gateway"`
+	RedirectURL    string `json:"redirect_url,omitempty"`
 }
 
 // WebhookResponse adalah response sukses untuk webhook
@@ -48,3 +52,145 @@ type VaNumber struct {
 	VaNumber string `json:"va_number"`
 	Bank     string `json:"bank"`
 }
+
+// XenditInvoiceCallback adalah payload webhook invoice dari Xendit
+type XenditInvoiceCallback struct {
+	ID                 string  `json:"id"`
+	ExternalID         string  `json:"external_id"`
+	UserID             string  `json:"user_id"`
+	Status             string  `json:"status"`
+	MerchantName       string  `json:"merchant_name"`
+	Amount             float64 `json:"amount"`
+	PaidAmount         float64 `json:"paid_amount"`
+	PaymentMethod      string  `json:"payment_method"`
+	PaymentChannel     string  `json:"payment_channel"`
+	PaymentDestination string  `json:"payment_destination"`
+	BankCode           string  `json:"bank_code"`
+	PaidAt             string  `json:"paid_at"`
+	Currency           string  `json:"currency"`
+	Description        string  `json:"description"`
+	Created            string  `json:"created"`
+	Updated            string  `json:"updated"`
+}
+
+// Payment notification statuses (PaymentNotification.Status)
+const (
+	PaymentNotificationPending  = "PENDING"
+	PaymentNotificationPaid     = "PAID"
+	PaymentNotificationFailed   = "FAILED"
+	PaymentNotificationRefunded = "REFUNDED"
+)
+
+// PaymentNotification adalah status pembayaran dari payment gateway mana pun.
+// Webhook Midtrans dan Xendit sama-sama diubah ke bentuk ini sebelum diproses.
+type PaymentNotification struct {
+	Provider        string
+	InvoiceNumber   string
+	TransactionID   string
+	Status          string
+	PaymentType     string
+	PaymentMethod   string
+	GrossAmount     string
+	TransactionTime string
+	SettlementTime  string
+
+	Midtrans *MidtransWebhookRequest
+	Xendit   *XenditInvoiceCallback
+}
+
+// Midtrans refund statuses (transaction_refund.midtrans_refund_status and
+// payment_midtrans_refunds.status). MANUAL means the refund is not paid back
+// through Midtrans, e.g. the order was paid by bank transfer.
+const (
+	MidtransRefundRequested = "REQUESTED"
+	MidtransRefundRefunded  = "REFUNDED"
+	MidtransRefundFailed    = "FAILED"
+	MidtransRefundManual    = "MANUAL"
+)
+
+// PendingMidtransPayment adalah invoice Midtrans yang belum menerima notifikasi
+// akhir. OrderType 0 berarti invoice langganan (travego_transactions).
+type PendingMidtransPayment struct {
+	InvoiceNumber  string
+	OrganizationID string
+	OrderID        string
+	OrderType      int64
+	CreatedAt      time.Time
+}
+
+// PendingMidtransRefund adalah refund order yang belum (selesai) dikirim ke
+// Midtrans. IssuedAmount dan IssuedCount adalah refund Midtrans yang sudah
+// dibuat sebelumnya untuk refund ini.
+type PendingMidtransRefund struct {
+	RefundID       string
+	OrganizationID string
+	OrderID        string
+	Amount         float64
+	Description    string
+	IssuedAmount   float64
+	IssuedCount    int
+}
+
+// MidtransSettledPayment adalah pembayaran Midtrans yang sudah lunas beserta
+// jumlah yang sudah direfund
+type MidtransSettledPayment struct {
+	InvoiceNumber  string
+	PaymentAmount  float64
+	RefundedAmount float64
+}
+
+// MidtransRefund adalah satu refund yang dikirim ke Midtrans untuk satu invoice
+type MidtransRefund struct {
+	RefundKey      string
+	RefundID       string
+	OrganizationID string
+	OrderID        string
+	InvoiceNumber  string
+	Amount         float64
+	Status         string
+	StatusMessage  string
+}
+
+// Outcome notifikasi pembayaran yang disimpan (payment_notifications.outcome)
+const (
+	NotificationOutcomeProcessing = "PROCESSING"
+	NotificationOutcomeProcessed  = "PROCESSED"
+	NotificationOutcomeFailed     = "FAILED"
+	NotificationOutcomeDuplicate  = "DUPLICATE"
+	NotificationOutcomeRejected   = "REJECTED"
+)
+
+// Asal notifikasi pembayaran (payment_notifications.source)
+const (
+	NotificationSourceWebhook      = "webhook"
+	NotificationSourceOrderWebhook = "order_webhook"
+	NotificationSourceReconcile    = "reconcile"
+)
+
+// PaymentNotificationRecord adalah satu notifikasi payment gateway yang
+// diterima, disimpan mentah beserta hasil pemrosesannya. EventKey
+// (provider:transaction_id:status) hanya diisi selama notifikasi itu yang
+// memegang event tersebut, sehingga pengiriman ulang dianggap duplikat.
+type PaymentNotificationRecord struct {
+	NotificationID    string `json:"notification_id"`
+	Provider          string `json:"provider"`
+	Source            string `json:"source"`
+	EventKey          string `json:"event_key,omitempty"`
+	InvoiceNumber     string `json:"invoice_number"`
+	TransactionID     string `json:"transaction_id"`
+	TransactionStatus string `json:"transaction_status"`
+	Payload           string `json:"payload"`
+	Outcome           string `json:"outcome"`
+	Message           string `json:"message,omitempty"`
+	Attempts          int    `json:"attempts"`
+	ReceivedAt        string `json:"received_at"`
+	ProcessedAt       string `json:"processed_at,omitempty"`
+}
+
+// PaymentNotificationFilter adalah filter daftar notifikasi pembayaran
+type PaymentNotificationFilter struct {
+	InvoiceNumber string
+	Outcome       string
+	Provider      string
+	Limit         int
+}
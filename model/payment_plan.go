@@ -0,0 +1,98 @@
+package model
+
+// Payment plan term due types (payment_plan_terms.due_type). BOOKING terms are
+// due due_days after the order is created, BEFORE_START terms due_days before
+// the trip starts (H-due_days).
+const (
+	PaymentTermDueBooking     = "BOOKING"
+	PaymentTermDueBeforeStart = "BEFORE_START"
+)
+
+// Installment statuses (order_installments.status). PENDING means a Midtrans
+// link was issued and is waiting for the payment notification.
+const (
+	InstallmentStatusCancelled = 0
+	InstallmentStatusUnpaid    = 1
+	InstallmentStatusPending   = 2
+	InstallmentStatusPaid      = 3
+)
+
+// Order types of an installment schedule (order_installments.order_type), same
+// numbering as utils.GenerateOrderID.
+const (
+	InstallmentOrderFleet       = 1
+	InstallmentOrderTourPackage = 2
+)
+
+var PaymentTermDueTypeLabel = map[string]string{
+	PaymentTermDueBooking:     "Setelah Pemesanan",
+	PaymentTermDueBeforeStart: "Sebelum Keberangkatan",
+}
+
+var InstallmentStatusLabel = map[int]string{
+	InstallmentStatusCancelled: "Dibatalkan",
+	InstallmentStatusUnpaid:    "Belum Dibayar",
+	InstallmentStatusPending:   "Menunggu Pembayaran",
+	InstallmentStatusPaid:      "Lunas",
+}
+
+type PaymentPlan struct {
+	PlanID         string            `json:"plan_id"`
+	OrganizationID string            `json:"organization_id"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	IsDefault      bool              `json:"is_default"`
+	Active         bool              `json:"active"`
+	Terms          []PaymentPlanTerm `json:"terms"`
+	CreatedDate    string            `json:"created_date"`
+}
+
+type PaymentPlanTerm struct {
+	Seq          int     `json:"seq"`
+	Label        string  `json:"label"`
+	Percentage   float64 `json:"percentage"`
+	DueType      string  `json:"due_type"`
+	DueTypeLabel string  `json:"due_type_label"`
+	DueDays      int     `json:"due_days"`
+}
+
+type PaymentPlanUpsertRequest struct {
+	PlanID      string            `json:"plan_id"`
+	Name        string            `json:"name" validate:"required"`
+	Description string            `json:"description"`
+	IsDefault   bool              `json:"is_default"`
+	Terms       []PaymentPlanTerm `json:"terms" validate:"required"`
+	Active      *bool             `json:"active"`
+
+	OrganizationID string `json:"-"`
+	UserID         string `json:"-"`
+}
+
+type PaymentPlanDeleteRequest struct {
+	PlanID string `json:"plan_id" validate:"required"`
+}
+
+type PaymentPlanApplyRequest struct {
+	OrderID string `json:"order_id" validate:"required"`
+	PlanID  string `json:"plan_id" validate:"required"`
+}
+
+type OrderInstallment struct {
+	InstallmentID  string  `json:"installment_id"`
+	OrderID        string  `json:"order_id"`
+	OrderType      int     `json:"order_type"`
+	PlanID         string  `json:"plan_id"`
+	Seq            int     `json:"seq"`
+	Label          string  `json:"label"`
+	Percentage     float64 `json:"percentage"`
+	Amount         float64 `json:"amount"`
+	DueDate        string  `json:"due_date"`
+	Status         int     `json:"status"`
+	StatusLabel    string  `json:"status_label"`
+	InvoiceNumber  string  `json:"invoice_number"`
+	SnapToken      string  `json:"snap_token"`
+	RedirectURL    string  `json:"redirect_url"`
+	PaymentGateway string  `json:"payment_gateway"`
+	LinkCreatedAt  string  `json:"link_created_at"`
+	PaidAt         string  `json:"paid_at"`
+}
@@ -0,0 +1,113 @@
+package model
+
+import "time"
+
+// Price rule types (price_rules.rule_type)
+const (
+	PriceRuleTypeSeasonal   = "SEASONAL"
+	PriceRuleTypeWeekend    = "WEEKEND"
+	PriceRuleTypeLeadTime   = "LEAD_TIME"
+	PriceRuleTypePickupCity = "PICKUP_CITY"
+)
+
+// Price adjustment types. A positive value is a surcharge, a negative value a
+// discount. FIXED is an amount per unit, or per unit per day for SEASONAL and
+// WEEKEND rules.
+const (
+	PriceAdjustmentPercent = "PERCENT"
+	PriceAdjustmentFixed   = "FIXED"
+)
+
+var PriceRuleTypeLabel = map[string]string{
+	PriceRuleTypeSeasonal:   "Musim / Hari Raya",
+	PriceRuleTypeWeekend:    "Akhir Pekan",
+	PriceRuleTypeLeadTime:   "Pesan Lebih Awal",
+	PriceRuleTypePickupCity: "Kota Penjemputan",
+}
+
+type PriceRule struct {
+	RuleID         string   `json:"rule_id"`
+	OrganizationID string   `json:"organization_id"`
+	Name           string   `json:"name"`
+	RuleType       string   `json:"rule_type"`
+	RuleTypeLabel  string   `json:"rule_type_label"`
+	AdjustmentType string   `json:"adjustment_type"`
+	Value          float64  `json:"value"`
+	StartDate      string   `json:"start_date"`
+	EndDate        string   `json:"end_date"`
+	MinLeadDays    int      `json:"min_lead_days"`
+	CityIDs        []string `json:"city_ids"`
+	FleetID        string   `json:"fleet_id"`
+	RentType       int      `json:"rent_type"`
+	Priority       int      `json:"priority"`
+	Active         bool     `json:"active"`
+	CreatedDate    string   `json:"created_date"`
+}
+
+type PriceRuleUpsertRequest struct {
+	RuleID         string   `json:"rule_id"`
+	Name           string   `json:"name" validate:"required"`
+	RuleType       string   `json:"rule_type" validate:"required"`
+	AdjustmentType string   `json:"adjustment_type" validate:"required"`
+	Value          float64  `json:"value"`
+	StartDate      string   `json:"start_date"`
+	EndDate        string   `json:"end_date"`
+	MinLeadDays    int      `json:"min_lead_days"`
+	CityIDs        []string `json:"city_ids"`
+	FleetID        string   `json:"fleet_id"`
+	RentType       int      `json:"rent_type"`
+	Priority       int      `json:"priority"`
+	Active         *bool    `json:"active"`
+
+	OrganizationID string `json:"-"`
+	UserID         string `json:"-"`
+}
+
+type PriceRuleDeleteRequest struct {
+	RuleID string `json:"rule_id" validate:"required"`
+}
+
+type PriceQuoteRequest struct {
+	FleetID      string `json:"fleet_id" validate:"required"`
+	PriceID      string `json:"price_id" validate:"required"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	PickupCityID string `json:"pickup_city_id"`
+	Qty          int    `json:"qty"`
+}
+
+// PriceQuoteInput is what the pricing engine evaluates rules against.
+type PriceQuoteInput struct {
+	FleetID      string
+	PriceID      string
+	RentType     int
+	BasePrice    float64
+	Qty          int
+	StartDate    time.Time
+	EndDate      time.Time
+	PickupCityID string
+	OrderedAt    time.Time
+}
+
+// PriceRuleLine is one applied rule on an order line. UnitAmount is the
+// adjustment per unit, Amount the adjustment for the whole line.
+type PriceRuleLine struct {
+	RuleID         string  `json:"rule_id"`
+	RuleName       string  `json:"rule_name"`
+	RuleType       string  `json:"rule_type"`
+	AdjustmentType string  `json:"adjustment_type"`
+	Value          float64 `json:"value"`
+	UnitAmount     float64 `json:"unit_amount"`
+	Quantity       int     `json:"quantity"`
+	Amount         float64 `json:"amount"`
+}
+
+type PriceQuote struct {
+	PriceID        string          `json:"price_id"`
+	BasePrice      float64         `json:"base_price"`
+	UnitAdjustment float64         `json:"unit_adjustment"`
+	UnitPrice      float64         `json:"unit_price"`
+	Quantity       int             `json:"quantity"`
+	Total          float64         `json:"total"`
+	Lines          []PriceRuleLine `json:"lines"`
+}
@@ -3,14 +3,15 @@ package model
 import "time"
 
 type Package struct {
-	PackageID          string   `json:"package_id"`
-	PackageName        string   `json:"package_name"`
-	PackageDescription string   `json:"package_description"`
-	PackageNotes       string   `json:"package_notes"`
-	PackagePrice       int      `json:"package_price"`
-	OriginalPrice      int      `json:"original_price"`
-	PackageDuration    int      `json:"package_duration"`
-	Features           []string `json:"features"`
+	PackageID          string              `json:"package_id"`
+	PackageName        string              `json:"package_name"`
+	PackageDescription string              `json:"package_description"`
+	PackageNotes       string              `json:"package_notes"`
+	PackagePrice       int                 `json:"package_price"`
+	OriginalPrice      int                 `json:"original_price"`
+	PackageDuration    int                 `json:"package_duration"`
+	Features           []string            `json:"features"`
+	Entitlements       PackageEntitlements `json:"entitlements"`
 }
 
 type PackageResponse struct {
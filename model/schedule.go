@@ -20,9 +20,8 @@ type ScheduleCreateRequest struct {
 }
 
 type ScheduleCreateServiceInput struct {
-	OrganizationID string
-	UserID         string
-	Request        *ScheduleCreateRequest
+	UserID  string
+	Request *ScheduleCreateRequest
 }
 
 type ScheduleUpdateRequest struct {
@@ -32,9 +31,8 @@ type ScheduleUpdateRequest struct {
 }
 
 type ScheduleUpdateServiceInput struct {
-	OrganizationID string
-	UserID         string
-	Request        *ScheduleUpdateRequest
+	UserID  string
+	Request *ScheduleUpdateRequest
 }
 
 type ScheduleFleetTripDetailRequest struct {
@@ -50,7 +48,6 @@ type ScheduleFleetTripUpdateRequest struct {
 }
 
 type ScheduleFleetTripDetailServiceInput struct {
-	OrganizationID  string
 	ScheduleNumber  string
 	ScheduleFleetID string
 	DriverID        string
@@ -80,21 +77,18 @@ type ScheduleFleetTripDetailResponse struct {
 }
 
 type ScheduleOrderValidationInput struct {
-	OrganizationID string
-	OrderID        string
+	OrderID string
 }
 
 type ScheduleOrderItemValidationInput struct {
-	OrganizationID string
-	OrderID        string
-	FleetID        string
+	OrderID string
+	FleetID string
 }
 
 type ScheduleFleetUnitAvailabilityServiceInput struct {
-	OrganizationID string
-	StartDate      string
-	EndDate        string
-	FleetID        string
+	StartDate string
+	EndDate   string
+	FleetID   string
 }
 
 type ScheduleFleetUnitAvailabilityItem struct {
@@ -126,17 +120,15 @@ type DailyAvailabilityFleetUnitRequest struct {
 }
 
 type DailyAvailabilityFleetServiceInput struct {
-	OrganizationID string
-	FleetID        string
-	StartDate      string
-	EndDate        string
+	FleetID   string
+	StartDate string
+	EndDate   string
 }
 
 type DailyAvailabilityFleetUnitServiceInput struct {
-	OrganizationID string
-	UnitID         string
-	StartDate      string
-	EndDate        string
+	UnitID    string
+	StartDate string
+	EndDate   string
 }
 
 type DailyAvailabilityFleetAvailableUnitItem struct {
@@ -277,8 +269,7 @@ type ScheduleFleetListQuery struct {
 }
 
 type ScheduleFleetListServiceInput struct {
-	OrganizationID string
-	Query          ScheduleFleetListQuery
+	Query ScheduleFleetListQuery
 }
 
 type ScheduleFleetListResponse struct {
@@ -347,8 +338,7 @@ type ScheduleFleetAvailabilityFilter struct {
 }
 
 type ScheduleFleetAvailabilityServiceInput struct {
-	OrganizationID string
-	Filter         ScheduleFleetAvailabilityFilter
+	Filter ScheduleFleetAvailabilityFilter
 }
 
 type ScheduleFleetAvailabilityItem struct {
@@ -384,8 +374,7 @@ type ScheduleFleetAvailabilityRow struct {
 }
 
 type ScheduleDetailServiceInput struct {
-	OrganizationID string
-	OrderID        string
+	OrderID string
 }
 
 type ScheduleDetailResponse struct {
@@ -431,8 +420,7 @@ type ScheduleDetailRow struct {
 }
 
 type ScheduleDetailByDateServiceInput struct {
-	OrganizationID string
-	Date           string
+	Date string
 }
 
 type ScheduleDetailByDateItem struct {
@@ -461,9 +449,8 @@ type ScheduleDetailByDateRow struct {
 }
 
 type ScheduleOperationAvailabilityServiceInput struct {
-	OrganizationID string
-	StartDate      string
-	EndDate        string
+	StartDate string
+	EndDate   string
 }
 
 type ScheduleOperationAvailabilityItem struct {
@@ -481,3 +468,58 @@ type ScheduleOperationAvailabilityRow struct {
 	Phone      string
 	ScheduleID string
 }
+
+type ScheduleAutoAssignRequest struct {
+	OrderID       string `json:"order_id" validate:"required"`
+	DepartureTime string `json:"departure_time"`
+}
+
+type ScheduleAutoAssignServiceInput struct {
+	Request *ScheduleAutoAssignRequest
+}
+
+// ScheduleAutoAssignUnit is one proposed unit/driver/crew assignment. FleetID,
+// UnitID, DriverID and CrewID match ScheduleUnitRequest so the dispatcher can
+// post the proposal (as is or adjusted) to /schedule/create.
+type ScheduleAutoAssignUnit struct {
+	FleetID     string   `json:"fleet_id"`
+	FleetName   string   `json:"fleet_name"`
+	UnitID      string   `json:"unit_id"`
+	PlateNumber string   `json:"plate_number"`
+	DriverID    string   `json:"driver_id"`
+	DriverName  string   `json:"driver_name"`
+	CrewID      string   `json:"crew_id"`
+	CrewName    string   `json:"crew_name"`
+	Reasons     []string `json:"reasons"`
+}
+
+type ScheduleAutoAssignResponse struct {
+	OrderID       string                   `json:"order_id"`
+	StartDate     string                   `json:"start_date"`
+	EndDate       string                   `json:"end_date"`
+	DepartureTime string                   `json:"departure_time"`
+	Complete      bool                     `json:"complete"`
+	ScheduleUnits []ScheduleAutoAssignUnit `json:"schedule_units"`
+	Warnings      []string                 `json:"warnings"`
+}
+
+type ScheduleOrderFleetItemRow struct {
+	FleetID   string
+	FleetName string
+	Quantity  int
+}
+
+// ScheduleTeamTripRow is a past or upcoming trip of a driver/crew pair, used to
+// balance workload when proposing assignments.
+type ScheduleTeamTripRow struct {
+	DriverID  string
+	CrewID    string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type ScheduleUnitTripRow struct {
+	UnitID    string
+	StartDate time.Time
+	EndDate   time.Time
+}
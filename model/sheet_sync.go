@@ -0,0 +1,98 @@
+package model
+
+import "time"
+
+// Entities pushed to an organization's Google Sheet, each to its own sheet
+const (
+	SheetEntityOrders   = "orders"
+	SheetEntityPayments = "payments"
+	SheetEntityExpenses = "expenses"
+)
+
+// SheetEntities are the synced entities in sync order
+var SheetEntities = []string{SheetEntityOrders, SheetEntityPayments, SheetEntityExpenses}
+
+// SheetSyncConfig is the Google Sheet an organization's data is pushed to
+type SheetSyncConfig struct {
+	OrganizationID string     `json:"organization_id"`
+	SpreadsheetID  string     `json:"spreadsheet_id"`
+	Enabled        bool       `json:"enabled"`
+	LastSyncedAt   *time.Time `json:"last_synced_at"`
+	LastError      string     `json:"last_error"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// SheetSyncCursor is the last row of an entity pushed to the sheet, in
+// (created_at, id) order
+type SheetSyncCursor struct {
+	Entity    string    `json:"entity"`
+	After     time.Time `json:"after"`
+	AfterID   string    `json:"after_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SheetSyncStatus is the sync setup of an organization shown in the dashboard
+type SheetSyncStatus struct {
+	Configured          bool              `json:"configured"`
+	ServiceAccountEmail string            `json:"service_account_email"`
+	Config              *SheetSyncConfig  `json:"config"`
+	Cursors             []SheetSyncCursor `json:"cursors"`
+}
+
+type SheetSyncConfigRequest struct {
+	SpreadsheetID string `json:"spreadsheet_id" validate:"required"`
+	Enabled       *bool  `json:"enabled"`
+}
+
+// SheetResyncRequest pushes again the rows created from StartDate to EndDate
+// (YYYY-MM-DD); an empty Entity resyncs every entity
+type SheetResyncRequest struct {
+	Entity    string `json:"entity"`
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+// SheetSyncResult is how many rows of an entity a sync pushed
+type SheetSyncResult struct {
+	Entity string `json:"entity"`
+	Rows   int    `json:"rows"`
+}
+
+// SheetOrderRow is a fleet order as pushed to the sheet
+type SheetOrderRow struct {
+	OrderID       string
+	CreatedAt     time.Time
+	CustomerName  string
+	CustomerPhone string
+	FleetName     string
+	StartDate     *time.Time
+	EndDate       *time.Time
+	UnitQty       int
+	TotalAmount   float64
+	PaymentStatus int
+	Status        int
+}
+
+// SheetPaymentRow is a payment of a fleet order as pushed to the sheet
+type SheetPaymentRow struct {
+	PaymentID        string
+	OrderID          string
+	CreatedAt        time.Time
+	PaymentType      int
+	PaymentAmount    float64
+	PaymentRemaining float64
+	Status           int
+	SettledAt        *time.Time
+}
+
+// SheetExpenseRow is an expense transaction as pushed to the sheet
+type SheetExpenseRow struct {
+	TransactionID       string
+	CreatedAt           time.Time
+	InvoiceNumber       string
+	TransactionDate     *time.Time
+	Description         string
+	TransactionCategory string
+	TransactionItem     string
+	Amount              float64
+}
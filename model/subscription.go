@@ -7,12 +7,16 @@ type GetSubscriptionRequest struct {
 }
 
 type SubscriptionDetail struct {
-	PackageID    string    `json:"package_id"`
-	PackagePrice float64   `json:"package_price"`
-	PackageName  string    `json:"package_name"`
-	StartDate    time.Time `json:"start_date"`
-	ExpireDate   time.Time `json:"expire_date"`
-	Status       string    `json:"status"`
+	PackageID          string     `json:"package_id"`
+	PackagePrice       float64    `json:"package_price"`
+	PackageName        string     `json:"package_name"`
+	StartDate          time.Time  `json:"start_date"`
+	ExpireDate         time.Time  `json:"expire_date"`
+	Status             string     `json:"status"`
+	LifecycleStatus    string     `json:"lifecycle_status"`
+	GraceUntil         *time.Time `json:"grace_until,omitempty"`
+	ScheduledPackageID string     `json:"scheduled_package_id,omitempty"`
+	ScheduledStartDate *time.Time `json:"scheduled_start_date,omitempty"`
 }
 
 type SubscriptionHistory struct {
@@ -31,16 +35,20 @@ type SubscriptionHistory struct {
 }
 
 type SubmitSubscriptionResponse struct {
-	PackageID           string   `json:"package_id"`
-	PackageName         string   `json:"package_name"`
-	PackageDuration     int      `json:"package_duration"`
-	PackageDescription  string   `json:"package_description"`
-	Features            []string `json:"features"`
-	PaymentAmount       int      `json:"payment_amount"`
-	PackagePrice        int      `json:"package_price"`
-	OriginalPrice       int      `json:"original_price"`
-	CurrentPackagePrice float64  `json:"current_package_price"`
-	DiscountPrice       int      `json:"discount_price"`
+	PackageID           string    `json:"package_id"`
+	PackageName         string    `json:"package_name"`
+	PackageDuration     int       `json:"package_duration"`
+	PackageDescription  string    `json:"package_description"`
+	Features            []string  `json:"features"`
+	PaymentAmount       int       `json:"payment_amount"`
+	PackagePrice        int       `json:"package_price"`
+	OriginalPrice       int       `json:"original_price"`
+	CurrentPackagePrice float64   `json:"current_package_price"`
+	DiscountPrice       int       `json:"discount_price"`
+	ChangeType          string    `json:"change_type"`
+	ProrationCredit     int       `json:"proration_credit"`
+	StartDate           time.Time `json:"start_date"`
+	ExpiryDate          time.Time `json:"expiry_date"`
 }
 
 type SubscriptionDetailByInvoiceResponse struct {
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Lifecycle states of a subscription, stored in _subscription.status
+const (
+	SubscriptionStatusActive   = 1
+	SubscriptionStatusGrace    = 2
+	SubscriptionStatusReadOnly = 3
+)
+
+var SubscriptionStatusLabels = map[int]string{
+	SubscriptionStatusActive:   "active",
+	SubscriptionStatusGrace:    "grace",
+	SubscriptionStatusReadOnly: "read_only",
+}
+
+// Events recorded in the subscription history
+const (
+	SubscriptionEventActivated          = "activated"
+	SubscriptionEventRenewed            = "renewed"
+	SubscriptionEventUpgraded           = "upgraded"
+	SubscriptionEventDowngradeScheduled = "downgrade_scheduled"
+	SubscriptionEventDowngraded         = "downgraded"
+	SubscriptionEventReminderSent       = "reminder_sent"
+	SubscriptionEventGraceStarted       = "grace_started"
+	SubscriptionEventReadOnly           = "read_only"
+)
+
+// Kinds of package change a subscription payment makes
+const (
+	SubscriptionChangeNew       = "new"
+	SubscriptionChangeRenewal   = "renewal"
+	SubscriptionChangeUpgrade   = "upgrade"
+	SubscriptionChangeDowngrade = "downgrade"
+)
+
+// SubscriptionReminderDays are the days before expiry a renewal reminder is sent
+var SubscriptionReminderDays = []int{7, 3, 1}
+
+// SubscriptionLifecycle is the lifecycle state of an organization's subscription
+type SubscriptionLifecycle struct {
+	OrganizationID        string     `json:"organization_id"`
+	PackageID             string     `json:"package_id"`
+	Status                int        `json:"status"`
+	ExpiryDate            time.Time  `json:"expiry_date"`
+	PackagePrice          float64    `json:"package_price"`
+	GraceUntil            *time.Time `json:"grace_until"`
+	ReminderDays          int        `json:"-"`
+	ScheduledPackageID    string     `json:"scheduled_package_id,omitempty"`
+	ScheduledExpiryDate   *time.Time `json:"scheduled_expiry_date,omitempty"`
+	ScheduledPackagePrice float64    `json:"-"`
+}
+
+// SubscriptionEvent is one entry of an organization's subscription history
+type SubscriptionEvent struct {
+	EventID        string    `json:"event_id"`
+	OrganizationID string    `json:"organization_id"`
+	Event          string    `json:"event"`
+	FromStatus     int       `json:"from_status"`
+	ToStatus       int       `json:"to_status"`
+	PackageID      string    `json:"package_id"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"created_at"`
+}
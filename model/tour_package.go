@@ -45,6 +45,7 @@ type TourPackageOrderCreateRequest struct {
 	PackageID        string   `json:"package_id" validate:"required"`
 	PriceID          string   `json:"price_id" validate:"required"`
 	Addons           []string `json:"addons"`
+	VoucherCode      string   `json:"voucher_code"`
 }
 
 type TourPackageOrderUpdateRequest struct {
@@ -28,3 +28,12 @@ type User struct {
 	UpdatedAt      time.Time  `json:"updated_at"`
 	DeletedAt      *time.Time `json:"-"`
 }
+
+// UserTwoFactor holds a user's TOTP two-factor settings
+type UserTwoFactor struct {
+	UserID            string
+	TOTPSecret        string // encrypted
+	Enabled           bool
+	EnabledAt         *time.Time
+	RecoveryCodesLeft int
+}
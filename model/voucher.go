@@ -0,0 +1,115 @@
+package model
+
+import "time"
+
+// Voucher scopes (vouchers.scope). ORGANIZATION vouchers apply to every order of
+// the organization, FLEET and TOUR_PACKAGE vouchers only to the product in
+// scope_id.
+const (
+	VoucherScopeOrganization = "ORGANIZATION"
+	VoucherScopeFleet        = "FLEET"
+	VoucherScopeTourPackage  = "TOUR_PACKAGE"
+)
+
+// Voucher discount types. PERCENT may be capped with max_discount, FIXED is an
+// amount off the order.
+const (
+	VoucherDiscountPercent = "PERCENT"
+	VoucherDiscountFixed   = "FIXED"
+)
+
+// Order types a voucher is redeemed on (voucher_redemptions.order_type), same
+// numbering as utils.GenerateOrderID.
+const (
+	VoucherOrderFleet       = 1
+	VoucherOrderTourPackage = 2
+)
+
+var VoucherScopeLabel = map[string]string{
+	VoucherScopeOrganization: "Semua Layanan",
+	VoucherScopeFleet:        "Armada",
+	VoucherScopeTourPackage:  "Paket Wisata",
+}
+
+type Voucher struct {
+	VoucherID          string  `json:"voucher_id"`
+	OrganizationID     string  `json:"organization_id"`
+	Code               string  `json:"code"`
+	Name               string  `json:"name"`
+	Description        string  `json:"description"`
+	Scope              string  `json:"scope"`
+	ScopeLabel         string  `json:"scope_label"`
+	ScopeID            string  `json:"scope_id"`
+	DiscountType       string  `json:"discount_type"`
+	DiscountValue      float64 `json:"discount_value"`
+	MaxDiscount        float64 `json:"max_discount"`
+	MinSpend           float64 `json:"min_spend"`
+	StartDate          string  `json:"start_date"`
+	EndDate            string  `json:"end_date"`
+	MaxUses            int     `json:"max_uses"`
+	MaxUsesPerCustomer int     `json:"max_uses_per_customer"`
+	UsedCount          int     `json:"used_count"`
+	Active             bool    `json:"active"`
+	CreatedDate        string  `json:"created_date"`
+}
+
+type VoucherUpsertRequest struct {
+	VoucherID          string  `json:"voucher_id"`
+	Code               string  `json:"code" validate:"required"`
+	Name               string  `json:"name" validate:"required"`
+	Description        string  `json:"description"`
+	Scope              string  `json:"scope" validate:"required"`
+	ScopeID            string  `json:"scope_id"`
+	DiscountType       string  `json:"discount_type" validate:"required"`
+	DiscountValue      float64 `json:"discount_value" validate:"required"`
+	MaxDiscount        float64 `json:"max_discount"`
+	MinSpend           float64 `json:"min_spend"`
+	StartDate          string  `json:"start_date"`
+	EndDate            string  `json:"end_date"`
+	MaxUses            int     `json:"max_uses"`
+	MaxUsesPerCustomer int     `json:"max_uses_per_customer"`
+	Active             *bool   `json:"active"`
+
+	OrganizationID string `json:"-"`
+	UserID         string `json:"-"`
+}
+
+type VoucherDeleteRequest struct {
+	VoucherID string `json:"voucher_id" validate:"required"`
+}
+
+// VoucherApplyInput is what a voucher code is validated against.
+type VoucherApplyInput struct {
+	Code       string
+	OrderType  int
+	ProductID  string
+	CustomerID string
+	Amount     float64
+	At         time.Time
+}
+
+// VoucherApplication is a validated voucher and the discount it gives on an order.
+type VoucherApplication struct {
+	VoucherID      string  `json:"voucher_id"`
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	DiscountType   string  `json:"discount_type"`
+	DiscountValue  float64 `json:"discount_value"`
+	Amount         float64 `json:"amount"`
+	DiscountAmount float64 `json:"discount_amount"`
+	TotalAmount    float64 `json:"total_amount"`
+
+	MaxUsesPerCustomer int `json:"-"`
+}
+
+type VoucherRedemption struct {
+	RedemptionID   string  `json:"redemption_id"`
+	VoucherID      string  `json:"voucher_id"`
+	Code           string  `json:"code"`
+	OrderID        string  `json:"order_id"`
+	OrderType      int     `json:"order_type"`
+	CustomerID     string  `json:"customer_id"`
+	CustomerName   string  `json:"customer_name"`
+	DiscountAmount float64 `json:"discount_amount"`
+	CreatedDate    string  `json:"created_date"`
+}
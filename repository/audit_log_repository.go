@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+type AuditLogRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewAuditLogRepository(db *sql.DB, driver string) *AuditLogRepository {
+	return &AuditLogRepository{
+		db:     db,
+		driver: driver,
+	}
+}
+
+func (r *AuditLogRepository) getPlaceholder(pos int) string {
+	if r.driver == "mysql" {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", pos)
+}
+
+func (r *AuditLogRepository) textColumn(column string) string {
+	if r.driver != "mysql" {
+		return column + "::text"
+	}
+	return column
+}
+
+// Create stores an audit log entry
+func (r *AuditLogRepository) Create(ctx context.Context, entry *model.AuditLog, createdAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO audit_logs
+			(audit_id, organization_id, actor_id, entity_type, entity_id, action, changes, created_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
+		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
+
+	_, err = t.Exec(query, entry.AuditID, t.OrganizationID(), nullableString(entry.ActorID),
+		entry.EntityType, entry.EntityID, entry.Action, string(changes), createdAt)
+	return err
+}
+
+// List returns a page of an organization's audit log, the newest first, and
+// the number of matching entries
+func (r *AuditLogRepository) List(ctx context.Context, filter model.AuditLogFilter) ([]model.AuditLog, int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, 0, err
+	}
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, r.getPlaceholder(len(args))))
+	}
+	add(r.textColumn("a.organization_id")+" = %s", t.OrganizationID())
+	if filter.EntityType != "" {
+		add("a.entity_type = %s", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		add("a.entity_id = %s", filter.EntityID)
+	}
+	if filter.ActorID != "" {
+		add(r.textColumn("a.actor_id")+" = %s", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("a.action = %s", filter.Action)
+	}
+	if filter.StartDate != "" {
+		add("a.created_at >= %s", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		// EndDate is inclusive, so compare with the start of the next day
+		end, err := time.Parse("2006-01-02", filter.EndDate)
+		if err != nil {
+			return nil, 0, err
+		}
+		add("a.created_at < %s", end.AddDate(0, 0, 1).Format("2006-01-02"))
+	}
+	where := " WHERE " + strings.Join(conds, " AND ")
+
+	var total int
+	if err := t.QueryRow("SELECT COUNT(*) FROM audit_logs a"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, %s, COALESCE(%s, ''), COALESCE(u.fullname, ''), a.entity_type, a.entity_id, a.action, a.changes, a.created_at
+		FROM audit_logs a
+		LEFT JOIN users u ON u.user_id = a.actor_id`,
+		r.textColumn("a.audit_id"), r.textColumn("a.organization_id"), r.textColumn("a.actor_id"))
+	query += where + fmt.Sprintf(" ORDER BY a.created_at DESC LIMIT %d OFFSET %d", filter.PerPage, (filter.Page-1)*filter.PerPage)
+
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]model.AuditLog, 0)
+	for rows.Next() {
+		var it model.AuditLog
+		var changes string
+		var createdAt sql.NullTime
+		if err := rows.Scan(&it.AuditID, &it.OrganizationID, &it.ActorID, &it.ActorName, &it.EntityType,
+			&it.EntityID, &it.Action, &changes, &createdAt); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal([]byte(changes), &it.Changes); err != nil || it.Changes == nil {
+			it.Changes = map[string]model.AuditChange{}
+		}
+		if createdAt.Valid {
+			it.CreatedAt = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		items = append(items, it)
+	}
+	return items, total, rows.Err()
+}
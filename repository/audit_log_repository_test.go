@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestAuditLogRepositoryIsTenantScoped(t *testing.T) {
+	r := NewAuditLogRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"List": func(ctx context.Context) error {
+			_, _, err := r.List(ctx, model.AuditLogFilter{Page: 1, PerPage: 20})
+			return err
+		},
+		"Create": func(ctx context.Context) error {
+			return r.Create(ctx, &model.AuditLog{AuditID: "audit-1", EntityType: "order"}, time.Now())
+		},
+	})
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CancellationPolicyRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewCancellationPolicyRepository(db *sql.DB, driver string) *CancellationPolicyRepository {
+	return &CancellationPolicyRepository{db: db, driver: driver}
+}
+
+func (r *CancellationPolicyRepository) placeholder(pos int) string {
+	if r.driver == "postgres" || r.driver == "pgx" {
+		return "$" + strconv.Itoa(pos)
+	}
+	return "?"
+}
+
+const selectCancellationPolicy = `
+SELECT
+	policy_id,
+	version,
+	name,
+	COALESCE(description, '') AS description,
+	COALESCE(non_refundable_addons, false) AS non_refundable_addons,
+	COALESCE(status, 0) AS status,
+	created_at
+FROM cancellation_policies
+`
+
+func (r *CancellationPolicyRepository) queryPolicies(t *database.Tenant, query string, args ...interface{}) ([]model.CancellationPolicy, error) {
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.CancellationPolicy, 0)
+	for rows.Next() {
+		var it model.CancellationPolicy
+		var status int
+		var createdAt sql.NullTime
+		if err := rows.Scan(&it.PolicyID, &it.Version, &it.Name, &it.Description, &it.NonRefundableAddons, &status, &createdAt); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.Active = status == model.CancellationPolicyStatusActive
+		it.Tiers = []model.CancellationPolicyTier{}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range out {
+		tiers, err := r.listTiers(t, out[i].PolicyID)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Tiers = tiers
+	}
+	return out, nil
+}
+
+// listTiers returns the tiers of a policy, most days before start first.
+func (r *CancellationPolicyRepository) listTiers(t *database.Tenant, policyID string) ([]model.CancellationPolicyTier, error) {
+	query := fmt.Sprintf(`
+		SELECT cpt.seq, cpt.min_days_before, COALESCE(cpt.refund_percentage, 0)
+		FROM cancellation_policy_tiers cpt
+		JOIN cancellation_policies cp ON cp.policy_id = cpt.policy_id
+		WHERE cpt.policy_id = %s AND cp.organization_id = %s
+		ORDER BY cpt.min_days_before DESC, cpt.seq ASC
+	`, r.placeholder(1), r.placeholder(2))
+	rows, err := t.Query(query, policyID, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.CancellationPolicyTier, 0)
+	for rows.Next() {
+		var it model.CancellationPolicyTier
+		if err := rows.Scan(&it.Seq, &it.MinDaysBefore, &it.RefundPercentage); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetActive returns the policy version currently in force, or sql.ErrNoRows.
+func (r *CancellationPolicyRepository) GetActive(ctx context.Context) (*model.CancellationPolicy, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectCancellationPolicy + " WHERE organization_id = " + r.placeholder(1) + " AND status = 1 ORDER BY version DESC LIMIT 1"
+	items, err := r.queryPolicies(t, query, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+// ListVersions returns every saved version of the organization's policy, newest first.
+func (r *CancellationPolicyRepository) ListVersions(ctx context.Context) ([]model.CancellationPolicy, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectCancellationPolicy + " WHERE organization_id = " + r.placeholder(1) + " AND status IN (1, 2) ORDER BY version DESC"
+	return r.queryPolicies(t, query, t.OrganizationID())
+}
+
+// Save stores req as the next policy version and supersedes the active one.
+func (r *CancellationPolicyRepository) Save(ctx context.Context, req *model.CancellationPolicySaveRequest) (string, int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", 0, err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var version int
+	versionQuery := fmt.Sprintf(`SELECT COALESCE(MAX(version), 0) FROM cancellation_policies WHERE organization_id = %s`, r.placeholder(1))
+	if err = tx.QueryRow(versionQuery, req.OrganizationID).Scan(&version); err != nil {
+		return "", 0, err
+	}
+	version++
+
+	supersedeQuery := fmt.Sprintf(`UPDATE cancellation_policies SET status = 2 WHERE organization_id = %s AND status = 1`, r.placeholder(1))
+	if _, err = tx.Exec(supersedeQuery, req.OrganizationID); err != nil {
+		return "", 0, err
+	}
+
+	policyID := uuid.New().String()
+	query := fmt.Sprintf(`
+		INSERT INTO cancellation_policies
+			(policy_id, organization_id, version, name, description, non_refundable_addons, status, created_by, created_at)
+		VALUES (%s, %s, %s, %s, %s, %s, 1, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5),
+		r.placeholder(6), r.placeholder(7), r.placeholder(8))
+	if _, err = tx.Exec(query,
+		policyID,
+		req.OrganizationID,
+		version,
+		req.Name,
+		req.Description,
+		req.NonRefundableAddons,
+		req.UserID,
+		time.Now(),
+	); err != nil {
+		return "", 0, err
+	}
+
+	tierQuery := fmt.Sprintf(`
+		INSERT INTO cancellation_policy_tiers (tier_id, policy_id, seq, min_days_before, refund_percentage)
+		VALUES (%s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5))
+	for _, tier := range req.Tiers {
+		if _, err = tx.Exec(tierQuery, uuid.New().String(), policyID, tier.Seq, tier.MinDaysBefore, tier.RefundPercentage); err != nil {
+			return "", 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", 0, err
+	}
+	return policyID, version, nil
+}
+
+const selectCancellationRequest = `
+SELECT
+	ocr.request_id,
+	ocr.order_id,
+	ocr.source,
+	COALESCE(ocr.reason, '') AS reason,
+	COALESCE(ocr.payment_method, 0) AS payment_method,
+	COALESCE(ocr.bank_code, '') AS bank_code,
+	COALESCE(ocr.bank_account, '') AS bank_account,
+	COALESCE(ocr.bank_account_name, '') AS bank_account_name,
+	COALESCE(ocr.policy_version, 0) AS policy_version,
+	COALESCE(ocr.refund_percentage, 0) AS refund_percentage,
+	COALESCE(ocr.refund_amount, 0) AS refund_amount,
+	COALESCE(ocr.status, 0) AS status,
+	COALESCE(c.customer_name, '') AS customer_name,
+	COALESCE(c.customer_phone, '') AS customer_phone,
+	ocr.requested_at,
+	ocr.reviewed_at,
+	COALESCE(ocr.review_note, '') AS review_note
+FROM order_cancellation_requests ocr
+LEFT JOIN customer_orders co ON co.order_id = ocr.order_id AND co.organization_id = ocr.organization_id
+LEFT JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = co.organization_id
+`
+
+func (r *CancellationPolicyRepository) queryRequests(t *database.Tenant, query string, args ...interface{}) ([]model.OrderCancellationRequest, error) {
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.OrderCancellationRequest, 0)
+	for rows.Next() {
+		var it model.OrderCancellationRequest
+		var requestedAt, reviewedAt sql.NullTime
+		if err := rows.Scan(
+			&it.RequestID,
+			&it.OrderID,
+			&it.Source,
+			&it.Reason,
+			&it.PaymentMethod,
+			&it.BankCode,
+			&it.BankAccount,
+			&it.BankAccountName,
+			&it.PolicyVersion,
+			&it.RefundPercentage,
+			&it.RefundAmount,
+			&it.Status,
+			&it.CustomerName,
+			&it.CustomerPhone,
+			&requestedAt,
+			&reviewedAt,
+			&it.ReviewNote,
+		); err != nil {
+			return nil, err
+		}
+		if requestedAt.Valid {
+			it.RequestedDate = requestedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if reviewedAt.Valid {
+			it.ReviewedDate = reviewedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.SourceLabel = model.CancellationSourceLabel[it.Source]
+		it.StatusLabel = model.CancellationRequestStatusLabel[it.Status]
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListRequests returns the organization's cancellation requests, filtered by
+// status when status > 0.
+func (r *CancellationPolicyRepository) ListRequests(ctx context.Context, status int) ([]model.OrderCancellationRequest, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectCancellationRequest + " WHERE ocr.organization_id = " + r.placeholder(1)
+	args := []interface{}{t.OrganizationID()}
+	if status > 0 {
+		query += " AND ocr.status = " + r.placeholder(2)
+		args = append(args, status)
+	}
+	query += " ORDER BY ocr.requested_at DESC"
+	return r.queryRequests(t, query, args...)
+}
+
+func (r *CancellationPolicyRepository) GetRequest(ctx context.Context, requestID string) (*model.OrderCancellationRequest, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectCancellationRequest + " WHERE ocr.organization_id = " + r.placeholder(1) + " AND ocr.request_id = " + r.placeholder(2)
+	items, err := r.queryRequests(t, query, t.OrganizationID(), requestID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+// GetPendingRequest returns the order's request waiting for approval, or sql.ErrNoRows.
+func (r *CancellationPolicyRepository) GetPendingRequest(ctx context.Context, orderID string) (*model.OrderCancellationRequest, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectCancellationRequest + " WHERE ocr.organization_id = " + r.placeholder(1) + " AND ocr.order_id = " + r.placeholder(2) + " AND ocr.status = 1 ORDER BY ocr.requested_at DESC LIMIT 1"
+	items, err := r.queryRequests(t, query, t.OrganizationID(), orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+func (r *CancellationPolicyRepository) CreateRequest(ctx context.Context, req *model.OrderCancellationRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	req.RequestID = uuid.New().String()
+	query := fmt.Sprintf(`
+		INSERT INTO order_cancellation_requests
+			(request_id, organization_id, order_id, source, reason, payment_method, bank_code, bank_account, bank_account_name,
+			 policy_version, refund_percentage, refund_amount, status, requested_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 1, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12),
+		r.placeholder(13))
+	_, err = t.Exec(query,
+		req.RequestID,
+		t.OrganizationID(),
+		req.OrderID,
+		req.Source,
+		req.Reason,
+		req.PaymentMethod,
+		req.BankCode,
+		req.BankAccount,
+		req.BankAccountName,
+		req.PolicyVersion,
+		req.RefundPercentage,
+		req.RefundAmount,
+		time.Now(),
+	)
+	return err
+}
+
+// ReviewRequest closes a pending request with status. It returns
+// sql.ErrNoRows when the request is not pending anymore.
+func (r *CancellationPolicyRepository) ReviewRequest(ctx context.Context, requestID, userID string, status int, note string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE order_cancellation_requests
+		SET status = %s, reviewed_by = %s, reviewed_at = %s, review_note = %s
+		WHERE request_id = %s AND organization_id = %s AND status = 1
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6))
+	result, err := t.Exec(query, status, nullableString(userID), time.Now(), note, requestID, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+	if affected, aerr := result.RowsAffected(); aerr == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ApprovePendingRequests closes every pending request of an order staff
+// cancelled directly.
+func (r *CancellationPolicyRepository) ApprovePendingRequests(ctx context.Context, orderID, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE order_cancellation_requests
+		SET status = 2, reviewed_by = %s, reviewed_at = %s
+		WHERE order_id = %s AND organization_id = %s AND status = 1
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	_, err = t.Exec(query, nullableString(userID), time.Now(), orderID, t.OrganizationID())
+	return err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+)
+
+func TestCancellationPolicyRepositoryIsTenantScoped(t *testing.T) {
+	r := NewCancellationPolicyRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"GetActive":    func(ctx context.Context) error { _, err := r.GetActive(ctx); return err },
+		"ListVersions": func(ctx context.Context) error { _, err := r.ListVersions(ctx); return err },
+		"ListRequests": func(ctx context.Context) error { _, err := r.ListRequests(ctx, 0); return err },
+		"GetRequest":   func(ctx context.Context) error { _, err := r.GetRequest(ctx, requestOfB); return err },
+		"GetPendingRequest": func(ctx context.Context) error {
+			_, err := r.GetPendingRequest(ctx, orderOfB)
+			return err
+		},
+		"CreateRequest": func(ctx context.Context) error {
+			return r.CreateRequest(ctx, &model.OrderCancellationRequest{OrderID: orderOfB})
+		},
+		"ReviewRequest": func(ctx context.Context) error {
+			return r.ReviewRequest(ctx, requestOfB, "", model.CancellationRequestRejected, "")
+		},
+		"ApprovePendingRequests": func(ctx context.Context) error { return r.ApprovePendingRequests(ctx, orderOfB, "") },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Save": func() error {
+			_, _, err := r.Save(ctxA, &model.CancellationPolicySaveRequest{OrganizationID: tenantB, Name: "strict"})
+			return err
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -41,8 +42,13 @@ func (r *ContentRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-// FindByTagAndOrgID checks if content exists
-func (r *ContentRepository) FindByTagAndOrgID(sectionTag, orgID string) (*model.Content, error) {
+// FindByTag checks if content exists
+func (r *ContentRepository) FindByTag(ctx context.Context, sectionTag string) (*model.Content, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
         SELECT uuid, section_tag, parent, type, is_active, content, organization_id, created_at, created_by, updated_at, updated_by
         FROM content
@@ -50,7 +56,7 @@ func (r *ContentRepository) FindByTagAndOrgID(sectionTag, orgID string) (*model.
     `, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var content model.Content
-	err := database.QueryRow(r.db, query, sectionTag, orgID).Scan(
+	err = t.QueryRow(query, sectionTag, t.OrganizationID()).Scan(
 		&content.UUID,
 		&content.SectionTag,
 		&content.Parent,
@@ -73,7 +79,12 @@ func (r *ContentRepository) FindByTagAndOrgID(sectionTag, orgID string) (*model.
 
 	return &content, nil
 }
-func (r *ContentRepository) FindByTagParentAndOrgID(sectionTag, parent, orgID string) (*model.Content, error) {
+func (r *ContentRepository) FindByTagAndParent(ctx context.Context, sectionTag, parent string) (*model.Content, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
         SELECT uuid, section_tag, parent, type, is_active, content, organization_id, created_at, created_by, updated_at, updated_by
         FROM content
@@ -81,7 +92,7 @@ func (r *ContentRepository) FindByTagParentAndOrgID(sectionTag, parent, orgID st
     `, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	var content model.Content
-	err := database.QueryRow(r.db, query, sectionTag, parent, orgID).Scan(
+	err = t.QueryRow(query, sectionTag, parent, t.OrganizationID()).Scan(
 		&content.UUID,
 		&content.SectionTag,
 		&content.Parent,
@@ -106,7 +117,12 @@ func (r *ContentRepository) FindByTagParentAndOrgID(sectionTag, parent, orgID st
 }
 
 // Create inserts new content
-func (r *ContentRepository) Create(content *model.Content) error {
+func (r *ContentRepository) Create(ctx context.Context, content *model.Content) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
         INSERT INTO content (uuid, section_tag, parent, type, is_active, content, organization_id, created_at, created_by, updated_at, updated_by)
         VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
@@ -115,7 +131,7 @@ func (r *ContentRepository) Create(content *model.Content) error {
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		content.UUID,
 		content.SectionTag,
 		content.Parent,
@@ -133,7 +149,12 @@ func (r *ContentRepository) Create(content *model.Content) error {
 }
 
 // Update updates existing content
-func (r *ContentRepository) Update(content *model.Content) error {
+func (r *ContentRepository) Update(ctx context.Context, content *model.Content) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
         UPDATE content
         SET content = %s, type = %s, is_active = %s, updated_at = %s, updated_by = %s
@@ -143,7 +164,7 @@ func (r *ContentRepository) Update(content *model.Content) error {
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		content.Content,
 		content.Type,
 		content.IsActive,
@@ -157,15 +178,20 @@ func (r *ContentRepository) Update(content *model.Content) error {
 	return err
 }
 
-// FindByParentAndOrgID retrieves content
-func (r *ContentRepository) FindByParentAndOrgID(parent, orgID string) ([]model.Content, error) {
+// FindByParent retrieves content
+func (r *ContentRepository) FindByParent(ctx context.Context, parent string) ([]model.Content, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
         SELECT uuid, section_tag, parent, type, is_active, content
         FROM content
         WHERE parent = %s AND organization_id = %s
     `, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, parent, orgID)
+	rows, err := t.Query(query, parent, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -183,15 +209,20 @@ func (r *ContentRepository) FindByParentAndOrgID(parent, orgID string) ([]model.
 	return contents, nil
 }
 
-// FindAllByOrgID retrieves all content
-func (r *ContentRepository) FindAllByOrgID(orgID string) ([]model.Content, error) {
+// FindAll retrieves all content
+func (r *ContentRepository) FindAll(ctx context.Context) ([]model.Content, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
         SELECT uuid, section_tag, parent, type, is_active, content
         FROM content
         WHERE organization_id = %s
     `, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +248,12 @@ func (r *ContentRepository) FindAllByOrgID(orgID string) ([]model.Content, error
 	return contents, nil
 }
 
-func (r *ContentRepository) GetOrganizationContact(orgID string) (*OrganizationContact, error) {
+func (r *ContentRepository) GetOrganizationContact(ctx context.Context) (*OrganizationContact, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT company_name,
 			   address as company_address,
@@ -248,7 +284,7 @@ func (r *ContentRepository) GetOrganizationContact(orgID string) (*OrganizationC
 		companyPostalCode   sql.NullString
 	)
 
-	err := database.QueryRow(r.db, query, orgID).Scan(
+	err = t.QueryRow(query, t.OrganizationID()).Scan(
 		&companyName,
 		&companyAddress,
 		&cityID,
@@ -283,8 +319,13 @@ func (r *ContentRepository) GetOrganizationContact(orgID string) (*OrganizationC
 	}, nil
 }
 
-// FindByUUIDAndTagAndOrgID checks content
-func (r *ContentRepository) FindByUUIDAndTagAndOrgID(uuid, sectionTag, orgID string) (*model.Content, error) {
+// FindByUUIDAndTag checks content
+func (r *ContentRepository) FindByUUIDAndTag(ctx context.Context, uuid, sectionTag string) (*model.Content, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
         SELECT uuid, section_tag, parent, type, is_active, content, organization_id, created_at, created_by, updated_at, updated_by
         FROM content
@@ -292,7 +333,7 @@ func (r *ContentRepository) FindByUUIDAndTagAndOrgID(uuid, sectionTag, orgID str
     `, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	var c model.Content
-	err := database.QueryRow(r.db, query, uuid, sectionTag, orgID).Scan(
+	err = t.QueryRow(query, uuid, sectionTag, t.OrganizationID()).Scan(
 		&c.UUID, &c.SectionTag, &c.Parent, &c.Type, &c.IsActive, &c.Content, &c.OrganizationID, &c.CreatedAt, &c.CreatedBy, &c.UpdatedAt, &c.UpdatedBy,
 	)
 	if err != nil {
@@ -304,18 +345,32 @@ func (r *ContentRepository) FindByUUIDAndTagAndOrgID(uuid, sectionTag, orgID str
 	return &c, nil
 }
 
-func (r *ContentRepository) InsertContentListItems(items []model.ContentListItem) error {
+// InsertContentListItems adds list items to content of the organization;
+// items of content it does not own are refused with sql.ErrNoRows
+func (r *ContentRepository) InsertContentListItems(ctx context.Context, items []model.ContentListItem) error {
 	if len(items) == 0 {
 		return nil
 	}
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	for _, it := range items {
+		owned, err := r.ownsContent(t, it.ContentID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return sql.ErrNoRows
+		}
 		query := fmt.Sprintf(`
             INSERT INTO content_list (uuid, content_id, label, icon, sub_label, created_at, updated_at)
             VALUES (%s, %s, %s, %s, %s, %s, %s)
         `,
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7),
 		)
-		_, err := database.Exec(r.db, query, it.UUID, it.ContentID, it.Label, it.Icon, it.SubLabel, it.CreatedAt, it.UpdatedAt)
+		_, err = t.Exec(query, it.UUID, it.ContentID, it.Label, it.Icon, it.SubLabel, it.CreatedAt, it.UpdatedAt)
 		if err != nil {
 			return err
 		}
@@ -323,26 +378,38 @@ func (r *ContentRepository) InsertContentListItems(items []model.ContentListItem
 	return nil
 }
 
-func (r *ContentRepository) UpdateContentListItemByUUID(uuid, label, icon, subLabel string, updatedAt interface{}) error {
+func (r *ContentRepository) UpdateContentListItemByUUID(ctx context.Context, uuid, label, icon, subLabel string, updatedAt interface{}) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
         UPDATE content_list
         SET label = %s, icon = %s, sub_label = %s, updated_at = %s
         WHERE uuid = %s
+          AND content_id IN (SELECT c.uuid FROM content c WHERE c.organization_id = %s)
     `,
-		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6),
 	)
-	_, err := database.Exec(r.db, query, label, icon, subLabel, updatedAt, uuid)
+	_, err = t.Exec(query, label, icon, subLabel, updatedAt, uuid, t.OrganizationID())
 	return err
 }
 
-func (r *ContentRepository) FindContentListByContentID(contentID string) ([]model.ContentListItem, error) {
+func (r *ContentRepository) FindContentListByContentID(ctx context.Context, contentID string) ([]model.ContentListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
-        SELECT uuid, content_id, label, icon, sub_label, created_at, updated_at
-        FROM content_list
-        WHERE content_id = %s
-        ORDER BY created_at ASC
-    `, r.getPlaceholder(1))
-	rows, err := database.Query(r.db, query, contentID)
+        SELECT cl.uuid, cl.content_id, cl.label, cl.icon, cl.sub_label, cl.created_at, cl.updated_at
+        FROM content_list cl
+        INNER JOIN content c ON c.uuid = cl.content_id AND c.organization_id = %s
+        WHERE cl.content_id = %s
+        ORDER BY cl.created_at ASC
+    `, r.getPlaceholder(1), r.getPlaceholder(2))
+	rows, err := t.Query(query, t.OrganizationID(), contentID)
 	if err != nil {
 		return nil, err
 	}
@@ -362,23 +429,28 @@ func (r *ContentRepository) FindContentListByContentID(contentID string) ([]mode
 	return items, nil
 }
 
-func (r *ContentRepository) GetContentListByTag(sectionTag, orgID string) ([]model.ContentListItem, error) {
-	content, err := r.FindByTagAndOrgID(sectionTag, orgID)
+func (r *ContentRepository) GetContentListByTag(ctx context.Context, sectionTag string) ([]model.ContentListItem, error) {
+	content, err := r.FindByTag(ctx, sectionTag)
 	if err != nil {
 		return nil, err
 	}
 	if content == nil {
 		return nil, nil
 	}
-	return r.FindContentListByContentID(content.UUID)
+	return r.FindContentListByContentID(ctx, content.UUID)
 }
 
-func (r *ContentRepository) DeleteContentByUUID(uuid, orgID string) error {
+func (r *ContentRepository) DeleteContentByUUID(ctx context.Context, uuid string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
         DELETE FROM content
         WHERE uuid = %s AND organization_id = %s
     `, r.getPlaceholder(1), r.getPlaceholder(2))
-	result, err := database.Exec(r.db, query, uuid, orgID)
+	result, err := t.Exec(query, uuid, t.OrganizationID())
 	if err != nil {
 		return err
 	}
@@ -387,3 +459,17 @@ func (r *ContentRepository) DeleteContentByUUID(uuid, orgID string) error {
 	}
 	return nil
 }
+
+// ownsContent reports whether contentID is content of the organization of t
+func (r *ContentRepository) ownsContent(t *database.Tenant, contentID string) (bool, error) {
+	query := fmt.Sprintf(`
+        SELECT COUNT(1)
+        FROM content
+        WHERE uuid = %s AND organization_id = %s
+    `, r.getPlaceholder(1), r.getPlaceholder(2))
+	var n int
+	if err := t.QueryRow(query, contentID, t.OrganizationID()).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
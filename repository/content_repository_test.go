@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestContentRepositoryIsTenantScoped(t *testing.T) {
+	r := NewContentRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"FindByTag": func(ctx context.Context) error { _, err := r.FindByTag(ctx, "brand-logo"); return err },
+		"FindByTagAndParent": func(ctx context.Context) error {
+			_, err := r.FindByTagAndParent(ctx, "brand-logo", "general")
+			return err
+		},
+		"Update": func(ctx context.Context) error {
+			organizationID, _ := database.OrganizationFromContext(ctx)
+			return r.Update(ctx, &model.Content{OrganizationID: organizationID, SectionTag: "brand-logo"})
+		},
+		"FindByParent":           func(ctx context.Context) error { _, err := r.FindByParent(ctx, "general"); return err },
+		"FindAll":                func(ctx context.Context) error { _, err := r.FindAll(ctx); return err },
+		"GetOrganizationContact": func(ctx context.Context) error { _, err := r.GetOrganizationContact(ctx); return err },
+		"FindByUUIDAndTag": func(ctx context.Context) error {
+			_, err := r.FindByUUIDAndTag(ctx, contentOfB, "brand-logo")
+			return err
+		},
+		"InsertContentListItems": func(ctx context.Context) error {
+			return r.InsertContentListItems(ctx, []model.ContentListItem{{UUID: "list-1", ContentID: contentOfB, Label: "WhatsApp"}})
+		},
+		"UpdateContentListItemByUUID": func(ctx context.Context) error {
+			return r.UpdateContentListItemByUUID(ctx, "list-of-b", "WhatsApp", "", "", time.Now())
+		},
+		"FindContentListByContentID": func(ctx context.Context) error {
+			_, err := r.FindContentListByContentID(ctx, contentOfB)
+			return err
+		},
+		"DeleteContentByUUID": func(ctx context.Context) error { return r.DeleteContentByUUID(ctx, contentOfB) },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error {
+			return r.Create(ctxA, &model.Content{UUID: "content-1", OrganizationID: tenantB, SectionTag: "brand-logo"})
+		},
+		"Update": func() error {
+			return r.Update(ctxA, &model.Content{OrganizationID: tenantB, SectionTag: "brand-logo"})
+		},
+	})
+}
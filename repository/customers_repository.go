@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/configs"
@@ -19,16 +20,19 @@ func NewCustomersRepository(db *sql.DB, driver string) *CustomersRepository {
 	return &CustomersRepository{db: db, driver: driver}
 }
 
-func (r *CustomersRepository) ListCustomers(orgID, customerName string) ([]model.CustomerListItem, error) {
+func (r *CustomersRepository) ListCustomers(ctx context.Context, customerName string) ([]model.CustomerListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	where := make([]string, 0, 2)
 	args := make([]interface{}, 0, 2)
 	pos := 1
 
-	if orgID != "" {
-		where = append(where, fmt.Sprintf("organization_id = %s", r.getPlaceholder(pos)))
-		args = append(args, orgID)
-		pos++
-	}
+	where = append(where, fmt.Sprintf("organization_id = %s", r.getPlaceholder(pos)))
+	args = append(args, t.OrganizationID())
+	pos++
 
 	if customerName != "" {
 		op := "LIKE"
@@ -44,15 +48,13 @@ func (r *CustomersRepository) ListCustomers(orgID, customerName string) ([]model
 		SELECT customer_id, customer_name, customer_phone, customer_email, customer_address, customer_company, customer_city, organization_id
 		FROM customers
 	`
-	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
-	}
+	query += " WHERE " + strings.Join(where, " AND ")
 	query += `
 		GROUP BY customer_id, customer_name, customer_phone, customer_email, customer_address, customer_company, customer_city, organization_id
 		ORDER BY customer_name
 	`
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +82,12 @@ func (r *CustomersRepository) ListCustomers(orgID, customerName string) ([]model
 	return items, nil
 }
 
-func (r *CustomersRepository) CreateCustomer(orgID string, req *model.CustomerCreateRequest, customerID string) error {
+func (r *CustomersRepository) CreateCustomer(ctx context.Context, req *model.CustomerCreateRequest, customerID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		INSERT INTO customers
 			(customer_id, organization_id, customer_name, customer_phone, customer_telephone, customer_address, customer_city, customer_email, customer_company, customer_bod, created_at)
@@ -89,11 +96,10 @@ func (r *CustomersRepository) CreateCustomer(orgID string, req *model.CustomerCr
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11))
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		customerID,
-		orgID,
+		t.OrganizationID(),
 		req.CustomerName,
 		req.CustomerPhone,
 		req.CustomerTelephone,
@@ -107,13 +113,18 @@ func (r *CustomersRepository) CreateCustomer(orgID string, req *model.CustomerCr
 	return err
 }
 
-func (r *CustomersRepository) GetCustomerDetail(orgID, customerID string) (map[string]interface{}, error) {
+func (r *CustomersRepository) GetCustomerDetail(ctx context.Context, customerID string) (map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(
 		"SELECT * FROM customers WHERE organization_id = %s AND customer_id = %s LIMIT 1",
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	rows, err := database.Query(r.db, query, orgID, customerID)
+	rows, err := t.Query(query, t.OrganizationID(), customerID)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +162,12 @@ func (r *CustomersRepository) GetCustomerDetail(orgID, customerID string) (map[s
 	return out, nil
 }
 
-func (r *CustomersRepository) UpdateCustomer(orgID, customerID string, req *model.CustomerCreateRequest) error {
+func (r *CustomersRepository) UpdateCustomer(ctx context.Context, customerID string, req *model.CustomerCreateRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	sets := make([]string, 0, 8)
 	args := make([]interface{}, 0, 10)
 	pos := 1
@@ -211,9 +227,9 @@ func (r *CustomersRepository) UpdateCustomer(orgID, customerID string, req *mode
 		r.getPlaceholder(pos),
 		r.getPlaceholder(pos+1),
 	)
-	args = append(args, orgID, customerID)
+	args = append(args, t.OrganizationID(), customerID)
 
-	res, err := database.Exec(r.db, query, args...)
+	res, err := t.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -227,7 +243,12 @@ func (r *CustomersRepository) UpdateCustomer(orgID, customerID string, req *mode
 	return nil
 }
 
-func (r *CustomersRepository) CheckCustomerAvailibility(orgID, email, phone string) (map[string]interface{}, error) {
+func (r *CustomersRepository) CheckCustomerAvailibility(ctx context.Context, email, phone string) (map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT customer_address, customer_city, customer_company 
 		FROM customers 
@@ -235,7 +256,7 @@ func (r *CustomersRepository) CheckCustomerAvailibility(orgID, email, phone stri
 		LIMIT 1
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	row := database.QueryRow(r.db, query, orgID, email, phone)
+	row := t.QueryRow(query, t.OrganizationID(), email, phone)
 	var address, city, company sql.NullString
 	if err := row.Scan(&address, &city, &company); err != nil {
 		return nil, err
@@ -256,7 +277,12 @@ func (r *CustomersRepository) getPlaceholder(pos int) string {
 	return "?"
 }
 
-func (r *CustomersRepository) GetCustomerOrders(orgID, customerID string, req *model.CustomerOrdersRequest) ([]map[string]interface{}, error) {
+func (r *CustomersRepository) GetCustomerOrders(ctx context.Context, customerID string, req *model.CustomerOrdersRequest) ([]map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	pos := 3
 	query := fmt.Sprintf(
 		`SELECT co.order_id, co.order_type, co.created_at, 
@@ -264,14 +290,14 @@ func (r *CustomersRepository) GetCustomerOrders(orgID, customerID string, req *m
 		CASE WHEN co.order_type = 1 THEN fo.payment_status ELSE tpo.payment_status END AS payment_status,
 		CASE WHEN co.order_type = 1 THEN fo.total_amount ELSE tpo.total_amount END AS total_amount
 		FROM customer_orders co 
-		LEFT JOIN fleet_orders fo ON co.order_id = fo.order_id AND co.order_type = 1 
-		LEFT JOIN tour_package_orders tpo ON co.order_id = tpo.order_id AND co.order_type = 2 
+		LEFT JOIN fleet_orders fo ON co.order_id = fo.order_id AND co.order_type = 1 AND fo.organization_id = co.organization_id 
+		LEFT JOIN tour_package_orders tpo ON co.order_id = tpo.order_id AND co.order_type = 2 AND tpo.organization_id = co.organization_id 
 		WHERE co.organization_id = %s AND co.customer_id = %s`,
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
 	args := make([]interface{}, 0, 12)
-	args = append(args, orgID, customerID)
+	args = append(args, t.OrganizationID(), customerID)
 
 	if req.StartDate != "" {
 		query += fmt.Sprintf(" AND co.created_at >= %s", r.getPlaceholder(pos))
@@ -289,7 +315,7 @@ func (r *CustomersRepository) GetCustomerOrders(orgID, customerID string, req *m
 		pos++
 	}
 	query += " ORDER BY co.created_at DESC"
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
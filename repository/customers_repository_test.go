@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+)
+
+func TestCustomersRepositoryIsTenantScoped(t *testing.T) {
+	r := NewCustomersRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"ListCustomers": func(ctx context.Context) error { _, err := r.ListCustomers(ctx, ""); return err },
+		"CreateCustomer": func(ctx context.Context) error {
+			return r.CreateCustomer(ctx, &model.CustomerCreateRequest{CustomerName: "Budi"}, "customer-1")
+		},
+		"GetCustomerDetail": func(ctx context.Context) error {
+			_, err := r.GetCustomerDetail(ctx, customerOfB)
+			return err
+		},
+		"UpdateCustomer": func(ctx context.Context) error {
+			return r.UpdateCustomer(ctx, customerOfB, &model.CustomerCreateRequest{CustomerName: "Budi"})
+		},
+		"CheckCustomerAvailibility": func(ctx context.Context) error {
+			_, err := r.CheckCustomerAvailibility(ctx, "budi@example.com", "08123456789")
+			return err
+		},
+		"GetCustomerOrders": func(ctx context.Context) error {
+			_, err := r.GetCustomerOrders(ctx, customerOfB, &model.CustomerOrdersRequest{})
+			return err
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -113,6 +114,11 @@ type DashboardFinanceRow struct {
 	Expenses float64
 }
 
+type DashboardVoucherRow struct {
+	Period   time.Time
+	Discount float64
+}
+
 func NewDashboardRepository(db *sql.DB, driver string) *DashboardRepository {
 	ensureDashboardCitiesLoaded()
 	return &DashboardRepository{
@@ -128,7 +134,12 @@ func (r *DashboardRepository) getPlaceholder(pos int) string {
 	return "?"
 }
 
-func (r *DashboardRepository) GetFinance(orgID string, groupBy string, startDate time.Time, endDate time.Time) ([]DashboardFinanceRow, error) {
+func (r *DashboardRepository) GetFinance(ctx context.Context, groupBy string, startDate time.Time, endDate time.Time) ([]DashboardFinanceRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	if r.driver != "postgres" {
 		return nil, fmt.Errorf("unsupported driver")
 	}
@@ -182,7 +193,7 @@ func (r *DashboardRepository) GetFinance(orgID string, groupBy string, startDate
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 	}
 
-	rows, err := database.Query(r.db, query, orgID, startDate, endDate)
+	rows, err := t.Query(query, orgID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -209,11 +220,15 @@ func (r *DashboardRepository) GetFinance(orgID string, groupBy string, startDate
 	return items, nil
 }
 
-func (r *DashboardRepository) GetPartnerSummary(orgID string) (*model.DashboardPartnerSummaryResponse, error) {
+func (r *DashboardRepository) GetPartnerSummary(ctx context.Context) (*model.DashboardPartnerSummaryResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	resp := &model.DashboardPartnerSummaryResponse{}
 
 	// 1. Orders Summary
-	ordersSummary, err := r.getOrdersSummary(orgID)
+	ordersSummary, err := r.getOrdersSummary(t)
 	if err != nil {
 		return nil, err
 	}
@@ -229,16 +244,20 @@ func (r *DashboardRepository) GetPartnerSummary(orgID string) (*model.DashboardP
 	return resp, nil
 }
 
-func (r *DashboardRepository) GetDashboard(orgID string) (*model.DashboardResponse, error) {
+func (r *DashboardRepository) GetDashboard(ctx context.Context) (*model.DashboardResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	resp := &model.DashboardResponse{}
 
-	tx, err := r.getTransactionMetrics(orgID)
+	tx, err := r.getTransactionMetrics(t)
 	if err != nil {
 		return nil, err
 	}
 	resp.Transaction = *tx
 
-	cust, err := r.getCustomerMetrics(orgID)
+	cust, err := r.getCustomerMetrics(t)
 	if err != nil {
 		return nil, err
 	}
@@ -256,7 +275,7 @@ func (r *DashboardRepository) GetDashboard(orgID string) (*model.DashboardRespon
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		res, err := r.getMessages(orgID)
+		res, err := r.getMessages(t)
 		if err != nil {
 			errCh <- err
 			return
@@ -267,7 +286,7 @@ func (r *DashboardRepository) GetDashboard(orgID string) (*model.DashboardRespon
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		res, err := r.getRevenueExpenses(orgID, 1)
+		res, err := r.getRevenueExpenses(t, 1)
 		if err != nil {
 			errCh <- err
 			return
@@ -278,7 +297,7 @@ func (r *DashboardRepository) GetDashboard(orgID string) (*model.DashboardRespon
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		res, err := r.getRevenueExpenses(orgID, 2)
+		res, err := r.getRevenueExpenses(t, 2)
 		if err != nil {
 			errCh <- err
 			return
@@ -319,7 +338,8 @@ func (r *DashboardRepository) getThisMonthBounds(now time.Time) (time.Time, time
 	return startOfMonth, endOfThisMonth, startOfLastMonth, endOfLastMonth
 }
 
-func (r *DashboardRepository) getMessages(orgID string) (*model.DashboardMessages, error) {
+func (r *DashboardRepository) getMessages(t *database.Tenant) (*model.DashboardMessages, error) {
+	orgID := t.OrganizationID()
 	now := time.Now()
 	startCur, endCur, startPrev, endPrev := r.getThisMonthBounds(now)
 
@@ -330,12 +350,12 @@ func (r *DashboardRepository) getMessages(orgID string) (*model.DashboardMessage
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	var current int
-	if err := database.QueryRow(r.db, query, orgID, startCur, endCur).Scan(&current); err != nil {
+	if err := t.QueryRow(query, orgID, startCur, endCur).Scan(&current); err != nil {
 		return nil, err
 	}
 
 	var previous int
-	if err := database.QueryRow(r.db, query, orgID, startPrev, endPrev).Scan(&previous); err != nil {
+	if err := t.QueryRow(query, orgID, startPrev, endPrev).Scan(&previous); err != nil {
 		return nil, err
 	}
 
@@ -345,7 +365,8 @@ func (r *DashboardRepository) getMessages(orgID string) (*model.DashboardMessage
 	}, nil
 }
 
-func (r *DashboardRepository) getRevenueExpenses(orgID string, TransactionItem int) (*model.DashboardRevenue, error) {
+func (r *DashboardRepository) getRevenueExpenses(t *database.Tenant, TransactionItem int) (*model.DashboardRevenue, error) {
+	orgID := t.OrganizationID()
 	now := time.Now()
 	startCur, endCur, startPrev, endPrev := r.getThisMonthBounds(now)
 
@@ -357,17 +378,17 @@ func (r *DashboardRepository) getRevenueExpenses(orgID string, TransactionItem i
 
 	var currentTotal int
 	var currentAmount sql.NullFloat64
-	if err := database.QueryRow(r.db, query, orgID, TransactionItem, startCur, endCur).Scan(&currentTotal, &currentAmount); err != nil {
+	if err := t.QueryRow(query, orgID, TransactionItem, startCur, endCur).Scan(&currentTotal, &currentAmount); err != nil {
 		return nil, err
 	}
 
 	var previousTotal int
 	var previousAmount sql.NullFloat64
-	if err := database.QueryRow(r.db, query, orgID, TransactionItem, startPrev, endPrev).Scan(&previousTotal, &previousAmount); err != nil {
+	if err := t.QueryRow(query, orgID, TransactionItem, startPrev, endPrev).Scan(&previousTotal, &previousAmount); err != nil {
 		return nil, err
 	}
 
-	metrics, err := r.getTransactionMetricsByType(orgID, TransactionItem, startCur, endCur)
+	metrics, err := r.getTransactionMetricsByType(t, TransactionItem, startCur, endCur)
 	if err != nil {
 		return nil, err
 	}
@@ -381,7 +402,8 @@ func (r *DashboardRepository) getRevenueExpenses(orgID string, TransactionItem i
 	}, nil
 }
 
-func (r *DashboardRepository) getTransactionMetricsByType(orgID string, TransactionItem int, from time.Time, to time.Time) ([]model.DashboardTransactionMetricItem, error) {
+func (r *DashboardRepository) getTransactionMetricsByType(t *database.Tenant, TransactionItem int, from time.Time, to time.Time) ([]model.DashboardTransactionMetricItem, error) {
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT transaction_category, transaction_item, SUM(amount) AS value
 		FROM transactions
@@ -390,7 +412,7 @@ func (r *DashboardRepository) getTransactionMetricsByType(orgID string, Transact
 		ORDER BY value DESC
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	rows, err := database.Query(r.db, query, orgID, TransactionItem, from, to)
+	rows, err := t.Query(query, orgID, TransactionItem, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -436,20 +458,25 @@ func (r *DashboardRepository) getTransactionMetricsByType(orgID string, Transact
 	return items, nil
 }
 
-func (r *DashboardRepository) GetTopDestinations(orgID string) ([]model.DashboardTopDestination, error) {
+func (r *DashboardRepository) GetTopDestinations(ctx context.Context) ([]model.DashboardTopDestination, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT city_id, SUM(total) AS total FROM (
 			SELECT foi.city_id, COUNT(*) AS total FROM fleet_order_itinerary foi
-			INNER JOIN fleet_orders fo ON fo.order_id=foi.order_id
+			INNER JOIN fleet_orders fo ON fo.order_id=foi.order_id AND foi.organization_id=fo.organization_id
 			WHERE fo.organization_id=%s AND fo.status=1 GROUP BY foi.city_id
 			UNION ALL
 			SELECT foi.city_id, COUNT(*) AS total FROM tour_package_itineraries foi
-			INNER JOIN tour_package_orders fo ON fo.tour_package_id=foi.package_id
+			INNER JOIN tour_package_orders fo ON fo.tour_package_id=foi.package_id AND foi.organization_id=fo.organization_id
 			WHERE fo.organization_id=%s AND fo.status=1 GROUP BY foi.city_id
 		) t GROUP BY city_id ORDER BY total DESC LIMIT 5
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, orgID, orgID)
+	rows, err := t.Query(query, orgID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -478,16 +505,21 @@ func (r *DashboardRepository) GetTopDestinations(orgID string) ([]model.Dashboar
 	return items, nil
 }
 
-func (r *DashboardRepository) GetTopPickupCity(orgID string) ([]model.DashboardTopPickupCity, error) {
+func (r *DashboardRepository) GetTopPickupCity(ctx context.Context) ([]model.DashboardTopPickupCity, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT pickup_city_id, SUM(total) AS total FROM (
-			SELECT pickup_city_id, COUNT(*) AS total FROM tour_package_orders WHERE organization_id=%s AND status=1 GROUP BY pickup_city_id
+			SELECT tpo.pickup_city_id, COUNT(*) AS total FROM tour_package_orders tpo WHERE tpo.organization_id=%s AND tpo.status=1 GROUP BY tpo.pickup_city_id
 			UNION ALL
-			SELECT pickup_city_id, COUNT(*) AS total FROM fleet_orders WHERE organization_id=%s AND status=1 GROUP BY pickup_city_id
+			SELECT fo.pickup_city_id, COUNT(*) AS total FROM fleet_orders fo WHERE fo.organization_id=%s AND fo.status=1 GROUP BY fo.pickup_city_id
 		) t GROUP BY pickup_city_id ORDER BY total DESC LIMIT 5
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, orgID, orgID)
+	rows, err := t.Query(query, orgID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -516,17 +548,22 @@ func (r *DashboardRepository) GetTopPickupCity(orgID string) ([]model.DashboardT
 	return items, nil
 }
 
-func (r *DashboardRepository) GetTopFleets(orgID string) ([]model.DashboardTopFleet, error) {
+func (r *DashboardRepository) GetTopFleets(ctx context.Context) ([]model.DashboardTopFleet, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT fu.vehicle_id, fu.plate_number, COUNT(sf.unit_id) AS total
 		FROM schedule_fleets sf
-		INNER JOIN fleet_units fu ON fu.unit_id=sf.unit_id
+		INNER JOIN fleet_units fu ON fu.unit_id=sf.unit_id AND fu.organization_id=sf.organization_id
 		WHERE sf.organization_id=%s
 		GROUP BY sf.unit_id, fu.vehicle_id, fu.plate_number
 		ORDER BY total DESC LIMIT 5
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -553,17 +590,22 @@ func (r *DashboardRepository) GetTopFleets(orgID string) ([]model.DashboardTopFl
 	return items, nil
 }
 
-func (r *DashboardRepository) GetTopTourPackages(orgID string) ([]model.DashboardTopTourPackage, error) {
+func (r *DashboardRepository) GetTopTourPackages(ctx context.Context) ([]model.DashboardTopTourPackage, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT tp.package_name, COUNT(tpo.order_id) AS total
 		FROM tour_package_orders tpo
-		INNER JOIN tour_packages tp ON tpo.tour_package_id=tp.uuid
+		INNER JOIN tour_packages tp ON tpo.tour_package_id=tp.uuid AND tp.organization_id=tpo.organization_id
 		WHERE tpo.organization_id=%s AND tpo.status=1
 		GROUP BY tpo.tour_package_id, tp.package_name
 		ORDER BY total DESC LIMIT 5
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -588,20 +630,25 @@ func (r *DashboardRepository) GetTopTourPackages(orgID string) ([]model.Dashboar
 	return items, nil
 }
 
-func (r *DashboardRepository) GetTopDrivers(orgID string) ([]model.DashboardTopDriver, error) {
+func (r *DashboardRepository) GetTopDrivers(ctx context.Context) ([]model.DashboardTopDriver, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	now := time.Now()
 	startCur, endCur, _, _ := r.getThisMonthBounds(now)
 
 	query := fmt.Sprintf(`
 		SELECT e.fullname, COUNT(sft.uuid) AS total
 		FROM schedule_fleet_teams sft
-		INNER JOIN employee e ON sft.driver_id=e.uuid
+		INNER JOIN employee e ON sft.driver_id=e.uuid AND e.organization_id=sft.organization_id
 		WHERE sft.organization_id=%s AND sft.created_at BETWEEN %s AND %s
 		GROUP BY sft.driver_id, e.fullname
 		ORDER BY total DESC LIMIT 5
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	rows, err := database.Query(r.db, query, orgID, startCur, endCur)
+	rows, err := t.Query(query, orgID, startCur, endCur)
 	if err != nil {
 		return nil, err
 	}
@@ -626,17 +673,22 @@ func (r *DashboardRepository) GetTopDrivers(orgID string) ([]model.DashboardTopD
 	return items, nil
 }
 
-func (r *DashboardRepository) GetTopCustomers(orgID string) ([]model.DashboardTopCustomer, error) {
+func (r *DashboardRepository) GetTopCustomers(ctx context.Context) ([]model.DashboardTopCustomer, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT c.customer_name, COUNT(co.order_id) AS total
 		FROM customer_orders co
-		INNER JOIN customers c ON co.customer_id=c.customer_id
+		INNER JOIN customers c ON co.customer_id=c.customer_id AND c.organization_id=co.organization_id
 		WHERE co.organization_id=%s
 		GROUP BY co.customer_id, c.customer_name
 		ORDER BY total DESC LIMIT 5
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -661,7 +713,8 @@ func (r *DashboardRepository) GetTopCustomers(orgID string) ([]model.DashboardTo
 	return items, nil
 }
 
-func (r *DashboardRepository) getOrdersSummary(orgID string) (*model.DashboardOrdersSummary, error) {
+func (r *DashboardRepository) getOrdersSummary(t *database.Tenant) (*model.DashboardOrdersSummary, error) {
+	orgID := t.OrganizationID()
 	// Total orders (all time)
 	var totalOrders int
 	queryTotal := fmt.Sprintf(`
@@ -670,7 +723,7 @@ func (r *DashboardRepository) getOrdersSummary(orgID string) (*model.DashboardOr
 		WHERE organization_id = %s
 	`, r.getPlaceholder(1))
 
-	err := database.QueryRow(r.db, queryTotal, orgID).Scan(&totalOrders)
+	err := t.QueryRow(queryTotal, orgID).Scan(&totalOrders)
 	if err != nil {
 		return nil, err
 	}
@@ -695,13 +748,13 @@ func (r *DashboardRepository) getOrdersSummary(orgID string) (*model.DashboardOr
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	// Current Month
-	err = database.QueryRow(r.db, queryMonth, orgID, startOfMonth, startOfNextMonth).Scan(&currentMonthCount)
+	err = t.QueryRow(queryMonth, orgID, startOfMonth, startOfNextMonth).Scan(&currentMonthCount)
 	if err != nil {
 		return nil, err
 	}
 
 	// Last Month
-	err = database.QueryRow(r.db, queryMonth, orgID, startOfLastMonth, startOfMonth).Scan(&lastMonthCount)
+	err = t.QueryRow(queryMonth, orgID, startOfLastMonth, startOfMonth).Scan(&lastMonthCount)
 	if err != nil {
 		return nil, err
 	}
@@ -732,7 +785,8 @@ func (r *DashboardRepository) getOrdersSummary(orgID string) (*model.DashboardOr
 	}, nil
 }
 
-func (r *DashboardRepository) getTransactionMetrics(orgID string) (*model.DashboardTransaction, error) {
+func (r *DashboardRepository) getTransactionMetrics(t *database.Tenant) (*model.DashboardTransaction, error) {
+	orgID := t.OrganizationID()
 	now := time.Now()
 	from := now.AddDate(-1, 0, 0)
 
@@ -742,7 +796,7 @@ func (r *DashboardRepository) getTransactionMetrics(orgID string) (*model.Dashbo
 		FROM customer_orders
 		WHERE organization_id = %s AND created_at >= %s AND created_at <= %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	if err := database.QueryRow(r.db, qTotal, orgID, from, now).Scan(&total); err != nil {
+	if err := t.QueryRow(qTotal, orgID, from, now).Scan(&total); err != nil {
 		return nil, err
 	}
 
@@ -759,10 +813,10 @@ func (r *DashboardRepository) getTransactionMetrics(orgID string) (*model.Dashbo
 		WHERE organization_id = %s AND created_at >= %s AND created_at < %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	if err := database.QueryRow(r.db, qMonth, orgID, startOfMonth, startOfNextMonth).Scan(&currentMonthCount); err != nil {
+	if err := t.QueryRow(qMonth, orgID, startOfMonth, startOfNextMonth).Scan(&currentMonthCount); err != nil {
 		return nil, err
 	}
-	if err := database.QueryRow(r.db, qMonth, orgID, startOfLastMonth, startOfMonth).Scan(&lastMonthCount); err != nil {
+	if err := t.QueryRow(qMonth, orgID, startOfLastMonth, startOfMonth).Scan(&lastMonthCount); err != nil {
 		return nil, err
 	}
 
@@ -782,7 +836,8 @@ func (r *DashboardRepository) getTransactionMetrics(orgID string) (*model.Dashbo
 	}, nil
 }
 
-func (r *DashboardRepository) getCustomerMetrics(orgID string) (*model.DashboardCustomers, error) {
+func (r *DashboardRepository) getCustomerMetrics(t *database.Tenant) (*model.DashboardCustomers, error) {
+	orgID := t.OrganizationID()
 	now := time.Now()
 	from := now.AddDate(-1, 0, 0)
 
@@ -792,7 +847,7 @@ func (r *DashboardRepository) getCustomerMetrics(orgID string) (*model.Dashboard
 		FROM customers
 		WHERE organization_id = %s AND created_at >= %s AND created_at <= %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	if err := database.QueryRow(r.db, qTotal, orgID, from, now).Scan(&total); err != nil {
+	if err := t.QueryRow(qTotal, orgID, from, now).Scan(&total); err != nil {
 		return nil, err
 	}
 
@@ -809,10 +864,10 @@ func (r *DashboardRepository) getCustomerMetrics(orgID string) (*model.Dashboard
 		WHERE organization_id = %s AND created_at >= %s AND created_at < %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	if err := database.QueryRow(r.db, qMonth, orgID, startOfMonth, startOfNextMonth).Scan(&currentMonthCount); err != nil {
+	if err := t.QueryRow(qMonth, orgID, startOfMonth, startOfNextMonth).Scan(&currentMonthCount); err != nil {
 		return nil, err
 	}
-	if err := database.QueryRow(r.db, qMonth, orgID, startOfLastMonth, startOfMonth).Scan(&lastMonthCount); err != nil {
+	if err := t.QueryRow(qMonth, orgID, startOfLastMonth, startOfMonth).Scan(&lastMonthCount); err != nil {
 		return nil, err
 	}
 
@@ -831,3 +886,62 @@ func (r *DashboardRepository) getCustomerMetrics(orgID string) (*model.Dashboard
 		CustomerPercentage: math.Round(percentage*100) / 100,
 	}, nil
 }
+
+// GetVoucherDiscounts sums the voucher discounts given on orders per period,
+// using the same grouping as GetFinance.
+func (r *DashboardRepository) GetVoucherDiscounts(ctx context.Context, groupBy string, startDate time.Time, endDate time.Time) ([]DashboardVoucherRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	if r.driver != "postgres" {
+		return nil, fmt.Errorf("unsupported driver")
+	}
+
+	var periodExpr string
+	switch groupBy {
+	case "day":
+		periodExpr = "DATE(created_at)"
+	case "2day":
+		periodExpr = "DATE_TRUNC('day', created_at) - (EXTRACT(DOY FROM created_at)::int % 2) * INTERVAL '1 day'"
+	case "week":
+		periodExpr = "DATE_TRUNC('week', created_at)"
+	default:
+		periodExpr = "DATE_TRUNC('month', created_at)"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s AS period,
+			SUM(COALESCE(discount_amount, 0)) AS discount
+		FROM voucher_redemptions
+		WHERE organization_id=%s AND created_at BETWEEN %s AND %s
+		GROUP BY period
+		ORDER BY period ASC
+	`, periodExpr, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+
+	rows, err := t.Query(query, orgID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]DashboardVoucherRow, 0)
+	for rows.Next() {
+		var period time.Time
+		var discount sql.NullFloat64
+		if err := rows.Scan(&period, &discount); err != nil {
+			return nil, err
+		}
+		items = append(items, DashboardVoucherRow{
+			Period:   period,
+			Discount: discount.Float64,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
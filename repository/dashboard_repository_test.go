@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDashboardRepositoryIsTenantScoped(t *testing.T) {
+	r := NewDashboardRepository(openTenantFake(t), "postgres")
+	end := time.Now()
+	start := end.AddDate(0, -1, 0)
+	checkTenantIsolation(t, tenantCalls{
+		"GetFinance": func(ctx context.Context) error {
+			_, err := r.GetFinance(ctx, "day", start, end)
+			return err
+		},
+		"GetPartnerSummary":  func(ctx context.Context) error { _, err := r.GetPartnerSummary(ctx); return err },
+		"GetDashboard":       func(ctx context.Context) error { _, err := r.GetDashboard(ctx); return err },
+		"GetTopDestinations": func(ctx context.Context) error { _, err := r.GetTopDestinations(ctx); return err },
+		"GetTopPickupCity":   func(ctx context.Context) error { _, err := r.GetTopPickupCity(ctx); return err },
+		"GetTopFleets":       func(ctx context.Context) error { _, err := r.GetTopFleets(ctx); return err },
+		"GetTopTourPackages": func(ctx context.Context) error { _, err := r.GetTopTourPackages(ctx); return err },
+		"GetTopDrivers":      func(ctx context.Context) error { _, err := r.GetTopDrivers(ctx); return err },
+		"GetTopCustomers":    func(ctx context.Context) error { _, err := r.GetTopCustomers(ctx); return err },
+		"GetVoucherDiscounts": func(ctx context.Context) error {
+			_, err := r.GetVoucherDiscounts(ctx, "month", start, end)
+			return err
+		},
+	})
+}
@@ -0,0 +1,416 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DocumentRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewDocumentRepository(db *sql.DB, driver string) *DocumentRepository {
+	return &DocumentRepository{db: db, driver: driver}
+}
+
+func (r *DocumentRepository) placeholder(pos int) string {
+	if r.driver == "postgres" || r.driver == "pgx" {
+		return "$" + strconv.Itoa(pos)
+	}
+	return "?"
+}
+
+func (r *DocumentRepository) OwnerExists(ctx context.Context, ownerType, ownerID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	var query string
+	switch ownerType {
+	case model.DocumentOwnerUnit:
+		query = fmt.Sprintf(`SELECT COUNT(*) FROM fleet_units WHERE unit_id = %s AND organization_id = %s`, r.placeholder(1), r.placeholder(2))
+	case model.DocumentOwnerEmployee:
+		query = fmt.Sprintf(`SELECT COUNT(*) FROM employee WHERE uuid = %s AND organization_id = %s`, r.placeholder(1), r.placeholder(2))
+	default:
+		return false, nil
+	}
+	var total int64
+	if err := t.QueryRow(query, ownerID, t.OrganizationID()).Scan(&total); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+const selectDocument = `
+SELECT
+	d.document_id,
+	d.organization_id,
+	d.owner_type,
+	d.owner_id,
+	COALESCE(fu.plate_number, e.fullname, '') AS owner_name,
+	d.document_type,
+	COALESCE(d.document_number, '') AS document_number,
+	d.issued_date,
+	d.expiry_date,
+	COALESCE(d.file_path, '') AS file_path,
+	COALESCE(d.notes, '') AS notes,
+	COALESCE(CAST(d.created_by AS CHAR(36)), '') AS created_by,
+	d.created_at
+FROM documents d
+LEFT JOIN fleet_units fu ON d.owner_type = 'UNIT' AND fu.unit_id = d.owner_id AND fu.organization_id = d.organization_id
+LEFT JOIN employee e ON d.owner_type = 'EMPLOYEE' AND e.uuid = d.owner_id AND e.organization_id = d.organization_id
+`
+
+func (r *DocumentRepository) scanDocuments(rows *sql.Rows) ([]model.Document, error) {
+	out := make([]model.Document, 0)
+	for rows.Next() {
+		var it model.Document
+		var issuedDate, expiryDate, createdAt sql.NullTime
+		if err := rows.Scan(
+			&it.DocumentID,
+			&it.OrganizationID,
+			&it.OwnerType,
+			&it.OwnerID,
+			&it.OwnerName,
+			&it.DocumentType,
+			&it.DocumentNumber,
+			&issuedDate,
+			&expiryDate,
+			&it.FilePath,
+			&it.Notes,
+			&it.CreatedBy,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		if issuedDate.Valid {
+			it.IssuedDate = issuedDate.Time.Format("2006-01-02")
+		}
+		if expiryDate.Valid {
+			it.ExpiryDate = expiryDate.Time.Format("2006-01-02")
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *DocumentRepository) List(ctx context.Context, filter model.DocumentListFilter) ([]model.Document, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	args := []interface{}{t.OrganizationID()}
+	query := selectDocument + " WHERE d.organization_id = " + r.placeholder(1) + " AND COALESCE(d.status, 1) = 1"
+	pos := 2
+	if filter.OwnerType != "" {
+		query += " AND d.owner_type = " + r.placeholder(pos)
+		args = append(args, filter.OwnerType)
+		pos++
+	}
+	if filter.OwnerID != "" {
+		query += " AND d.owner_id = " + r.placeholder(pos)
+		args = append(args, filter.OwnerID)
+		pos++
+	}
+	if filter.ExpiringWithin > 0 {
+		query += " AND d.expiry_date <= " + r.placeholder(pos)
+		args = append(args, time.Now().AddDate(0, 0, filter.ExpiringWithin))
+	}
+	query += " ORDER BY d.expiry_date ASC"
+
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanDocuments(rows)
+}
+
+func (r *DocumentRepository) GetByID(ctx context.Context, documentID string) (*model.Document, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectDocument + " WHERE d.organization_id = " + r.placeholder(1) + " AND d.document_id = " + r.placeholder(2) + " AND COALESCE(d.status, 1) = 1"
+	rows, err := t.Query(query, t.OrganizationID(), documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items, err := r.scanDocuments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func (r *DocumentRepository) Create(ctx context.Context, req *model.DocumentUpsertRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	documentID := uuid.New().String()
+	now := time.Now()
+	query := fmt.Sprintf(`
+		INSERT INTO documents
+			(document_id, organization_id, owner_type, owner_id, document_type, document_number, issued_date, expiry_date,
+			 file_path, notes, status, created_by, created_at, updated_by, updated_at)
+		VALUES
+			(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 1, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7),
+		r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12), r.placeholder(13), r.placeholder(14))
+	_, err = t.Exec(query,
+		documentID,
+		req.OrganizationID,
+		req.OwnerType,
+		req.OwnerID,
+		req.DocumentType,
+		req.DocumentNumber,
+		nullableTime(req.IssuedAt),
+		req.ExpiryAt,
+		req.FilePath,
+		req.Notes,
+		req.UserID,
+		now,
+		req.UserID,
+		now,
+	)
+	if err != nil {
+		return "", err
+	}
+	return documentID, nil
+}
+
+// Update replaces the document fields. A renewed expiry date clears the reminders
+// already sent so the next cycle is announced again.
+func (r *DocumentRepository) Update(ctx context.Context, req *model.DocumentUpsertRequest, expiryChanged bool) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+
+	rollback := true
+	defer func() {
+		if rollback {
+			_ = tx.Rollback()
+		}
+	}()
+
+	query := fmt.Sprintf(`
+		UPDATE documents
+		SET document_type = %s, document_number = %s, issued_date = %s, expiry_date = %s, file_path = %s, notes = %s,
+			updated_by = %s, updated_at = %s
+		WHERE document_id = %s AND organization_id = %s AND COALESCE(status, 1) = 1
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10))
+	result, err := tx.Exec(query,
+		req.DocumentType,
+		req.DocumentNumber,
+		nullableTime(req.IssuedAt),
+		req.ExpiryAt,
+		req.FilePath,
+		req.Notes,
+		req.UserID,
+		time.Now(),
+		req.DocumentID,
+		req.OrganizationID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if expiryChanged {
+		resetQuery := fmt.Sprintf(`DELETE FROM document_reminders WHERE document_id = %s AND organization_id = %s`, r.placeholder(1), r.placeholder(2))
+		if _, err := tx.Exec(resetQuery, req.DocumentID, req.OrganizationID); err != nil {
+			return err
+		}
+	}
+
+	rollback = false
+	return tx.Commit()
+}
+
+func (r *DocumentRepository) Delete(ctx context.Context, userID, documentID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE documents SET status = 0, updated_by = %s, updated_at = %s
+		WHERE document_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	result, err := t.Exec(query, userID, time.Now(), documentID, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListReminderCandidates returns documents of an organization expiring between
+// today and until. Like ListExpiredForOwners only the latest expiry per owner
+// and document type counts, so a document already renewed is not reminded.
+// DaysBefore carries the smallest reminder stage already sent (0 when none has
+// been sent yet).
+func (r *DocumentRepository) ListReminderCandidates(ctx context.Context, today, until time.Time) ([]model.DocumentReminderRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT
+			d.document_id,
+			d.organization_id,
+			d.owner_type,
+			d.owner_id,
+			COALESCE(fu.plate_number, e.fullname, '') AS owner_name,
+			COALESCE(e.phone, '') AS owner_phone,
+			d.document_type,
+			COALESCE(d.document_number, '') AS document_number,
+			d.expiry_date,
+			COALESCE((SELECT MIN(dr.days_before) FROM document_reminders dr WHERE dr.document_id = d.document_id AND dr.organization_id = d.organization_id), 0) AS last_stage
+		FROM documents d
+		LEFT JOIN fleet_units fu ON d.owner_type = 'UNIT' AND fu.unit_id = d.owner_id AND fu.organization_id = d.organization_id
+		LEFT JOIN employee e ON d.owner_type = 'EMPLOYEE' AND e.uuid = d.owner_id AND e.organization_id = d.organization_id
+		WHERE d.organization_id = %s
+		  AND COALESCE(d.status, 1) = 1
+		  AND d.expiry_date >= %s
+		  AND d.expiry_date <= %s
+		  AND NOT EXISTS (
+			SELECT 1 FROM documents nd
+			WHERE nd.organization_id = d.organization_id
+			  AND nd.owner_type = d.owner_type
+			  AND nd.owner_id = d.owner_id
+			  AND nd.document_type = d.document_type
+			  AND COALESCE(nd.status, 1) = 1
+			  AND nd.expiry_date > d.expiry_date
+		  )
+		ORDER BY d.expiry_date ASC
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3))
+
+	rows, err := t.Query(query, t.OrganizationID(), today, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.DocumentReminderRow, 0)
+	for rows.Next() {
+		var it model.DocumentReminderRow
+		if err := rows.Scan(
+			&it.DocumentID,
+			&it.OrganizationID,
+			&it.OwnerType,
+			&it.OwnerID,
+			&it.OwnerName,
+			&it.OwnerPhone,
+			&it.DocumentType,
+			&it.DocumentNumber,
+			&it.ExpiryDate,
+			&it.DaysBefore,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *DocumentRepository) InsertReminder(ctx context.Context, documentID string, daysBefore int, sentAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO document_reminders (document_id, organization_id, days_before, sent_at)
+		VALUES (%s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	_, err = t.Exec(query, documentID, t.OrganizationID(), daysBefore, sentAt)
+	return err
+}
+
+// ListExpiredForOwners returns, per owner and document type, the documents whose
+// latest expiry date falls before the given date. Older records superseded by a
+// renewed document are ignored.
+func (r *DocumentRepository) ListExpiredForOwners(ctx context.Context, ownerIDs []string, before time.Time) ([]model.ScheduleExpiredDocumentRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	if len(ownerIDs) == 0 {
+		return nil, nil
+	}
+	in := make([]string, 0, len(ownerIDs))
+	args := []interface{}{t.OrganizationID(), before}
+	for i, id := range ownerIDs {
+		in = append(in, r.placeholder(i+3))
+		args = append(args, id)
+	}
+	query := `
+		SELECT d.owner_type, d.owner_id, COALESCE(MAX(fu.plate_number), MAX(e.fullname), '') AS owner_name, d.document_type, MAX(d.expiry_date) AS expiry_date
+		FROM documents d
+		LEFT JOIN fleet_units fu ON d.owner_type = 'UNIT' AND fu.unit_id = d.owner_id AND fu.organization_id = d.organization_id
+		LEFT JOIN employee e ON d.owner_type = 'EMPLOYEE' AND e.uuid = d.owner_id AND e.organization_id = d.organization_id
+		WHERE d.organization_id = ` + r.placeholder(1) + `
+		  AND COALESCE(d.status, 1) = 1
+		  AND d.owner_id IN (` + strings.Join(in, ",") + `)
+		GROUP BY d.owner_type, d.owner_id, d.document_type
+		HAVING MAX(d.expiry_date) < ` + r.placeholder(2) + `
+		ORDER BY expiry_date ASC
+	`
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.ScheduleExpiredDocumentRow, 0)
+	for rows.Next() {
+		var it model.ScheduleExpiredDocumentRow
+		if err := rows.Scan(&it.OwnerType, &it.OwnerID, &it.OwnerName, &it.DocumentType, &it.ExpiryDate); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestDocumentRepositoryIsTenantScoped(t *testing.T) {
+	r := NewDocumentRepository(openTenantFake(t), "postgres")
+	today := time.Now()
+	checkTenantIsolation(t, tenantCalls{
+		"OwnerExists": func(ctx context.Context) error {
+			_, err := r.OwnerExists(ctx, model.DocumentOwnerEmployee, employeeOfB)
+			return err
+		},
+		"List":    func(ctx context.Context) error { _, err := r.List(ctx, model.DocumentListFilter{}); return err },
+		"GetByID": func(ctx context.Context) error { _, err := r.GetByID(ctx, documentOfB); return err },
+		"Update": func(ctx context.Context) error {
+			organizationID, _ := database.OrganizationFromContext(ctx)
+			return r.Update(ctx, &model.DocumentUpsertRequest{
+				OrganizationID: organizationID,
+				DocumentID:     documentOfB,
+				ExpiryAt:       today,
+			}, true)
+		},
+		"Delete": func(ctx context.Context) error { return r.Delete(ctx, "", documentOfB) },
+		"ListReminderCandidates": func(ctx context.Context) error {
+			_, err := r.ListReminderCandidates(ctx, today, today.AddDate(0, 0, 30))
+			return err
+		},
+		"InsertReminder": func(ctx context.Context) error { return r.InsertReminder(ctx, documentOfB, 30, today) },
+		"ListExpiredForOwners": func(ctx context.Context) error {
+			_, err := r.ListExpiredForOwners(ctx, []string{employeeOfB}, today)
+			return err
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error {
+			_, err := r.Create(ctxA, &model.DocumentUpsertRequest{OrganizationID: tenantB, OwnerID: employeeOfB, ExpiryAt: today})
+			return err
+		},
+		"Update": func() error {
+			return r.Update(ctxA, &model.DocumentUpsertRequest{OrganizationID: tenantB, DocumentID: documentOfB, ExpiryAt: today}, true)
+		},
+	})
+}
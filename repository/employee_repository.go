@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -11,7 +12,12 @@ import (
 	"github.com/google/uuid"
 )
 
-func (r *OrganizationRepository) RoleExistsForOrgOrDefault(organizationID, roleID string) (bool, error) {
+func (r *OrganizationRepository) RoleExistsForOrgOrDefault(ctx context.Context, roleID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	defaultOrgID := "00000000-0000-0000-0000-000000000000"
 	legacyDefaultOrgID := "000"
 
@@ -29,13 +35,18 @@ func (r *OrganizationRepository) RoleExistsForOrgOrDefault(organizationID, roleI
 	`, roleExpr, orgExpr)
 
 	var cnt int
-	if err := database.QueryRow(r.db, query, roleID, organizationID, defaultOrgID, legacyDefaultOrgID).Scan(&cnt); err != nil {
+	if err := t.QueryRow(query, roleID, organizationID, defaultOrgID, legacyDefaultOrgID).Scan(&cnt); err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
-func (r *OrganizationRepository) EmployeeIDExists(organizationID, employeeID string) (bool, error) {
+func (r *OrganizationRepository) EmployeeIDExists(ctx context.Context, employeeID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(1)
 	if r.driver != "mysql" {
 		orgExpr = "organization_id::text = " + r.getPlaceholder(1)
@@ -47,13 +58,18 @@ func (r *OrganizationRepository) EmployeeIDExists(organizationID, employeeID str
 	`, orgExpr, r.getPlaceholder(2))
 
 	var cnt int
-	if err := database.QueryRow(r.db, query, organizationID, employeeID).Scan(&cnt); err != nil {
+	if err := t.QueryRow(query, organizationID, employeeID).Scan(&cnt); err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
-func (r *OrganizationRepository) NIKExists(organizationID, nik string) (bool, error) {
+func (r *OrganizationRepository) NIKExists(ctx context.Context, nik string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(1)
 	if r.driver != "mysql" {
 		orgExpr = "organization_id::text = " + r.getPlaceholder(1)
@@ -65,14 +81,22 @@ func (r *OrganizationRepository) NIKExists(organizationID, nik string) (bool, er
 	`, orgExpr, r.getPlaceholder(2))
 
 	var cnt int
-	if err := database.QueryRow(r.db, query, organizationID, nik).Scan(&cnt); err != nil {
+	if err := t.QueryRow(query, organizationID, nik).Scan(&cnt); err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
-func (r *OrganizationRepository) ListEmployees(organizationID, divisionName string) ([]model.EmployeeListItem, error) {
-	orgExpr := "e.organization_id = " + r.getPlaceholder(1)
+func (r *OrganizationRepository) ListEmployees(ctx context.Context, divisionName string) ([]model.EmployeeListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	roleOrgExpr, args := r.sharedOrganizationFilter("r.organization_id", organizationID, nil)
+	divisionOrgExpr, args := r.sharedOrganizationFilter("d.organization_id", organizationID, args)
+	orgExpr := "e.organization_id = " + r.getPlaceholder(len(args)+1)
+	args = append(args, organizationID)
 
 	divisionNameNormalized := strings.ToLower(strings.TrimSpace(divisionName))
 	operationDivisionID := "fe8b3916-5eff-420c-8110-8d974d767afe"
@@ -126,14 +150,14 @@ func (r *OrganizationRepository) ListEmployees(organizationID, divisionName stri
 			e.created_at,
 			e.updated_at
 		FROM employee e
-		LEFT JOIN organization_roles r ON r.role_id::text = e.role_id::text
-		LEFT JOIN organization_divisions d ON d.division_id::text = r.division_id::text
+		LEFT JOIN organization_roles r ON r.role_id::text = e.role_id::text AND %s
+		LEFT JOIN organization_divisions d ON d.division_id::text = r.division_id::text AND %s
 		WHERE %s AND COALESCE(e.status, 0) > 0
 		%s
 		ORDER BY e.created_at DESC
-	`, orgExpr, divisionFilter)
+	`, roleOrgExpr, divisionOrgExpr, orgExpr, divisionFilter)
 
-	rows, err := database.Query(r.db, query, organizationID)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -199,12 +223,21 @@ func (r *OrganizationRepository) ListEmployees(organizationID, divisionName stri
 	return out, nil
 }
 
-func (r *OrganizationRepository) EmployeeDetail(organizationID, uuid string) (*model.EmployeeDetailResponse, error) {
-	orgExpr := "e.organization_id = " + r.getPlaceholder(2)
-	uuidExpr := "e.uuid = " + r.getPlaceholder(1)
+func (r *OrganizationRepository) EmployeeDetail(ctx context.Context, uuid string) (*model.EmployeeDetailResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	roleOrgExpr, args := r.sharedOrganizationFilter("r.organization_id", organizationID, nil)
+	divisionOrgExpr, args := r.sharedOrganizationFilter("d.organization_id", organizationID, args)
+	pos := len(args)
+	args = append(args, uuid, organizationID)
+	orgExpr := "e.organization_id = " + r.getPlaceholder(pos+2)
+	uuidExpr := "e.uuid = " + r.getPlaceholder(pos+1)
 	if r.driver != "mysql" {
-		orgExpr = "e.organization_id::text = " + r.getPlaceholder(2)
-		uuidExpr = "e.uuid::text = " + r.getPlaceholder(1)
+		orgExpr = "e.organization_id::text = " + r.getPlaceholder(pos+2)
+		uuidExpr = "e.uuid::text = " + r.getPlaceholder(pos+1)
 	}
 
 	query := fmt.Sprintf(`
@@ -232,11 +265,11 @@ func (r *OrganizationRepository) EmployeeDetail(organizationID, uuid string) (*m
 			COALESCE(e.updated_by::text, ''),
 			e.updated_at
 		FROM employee e
-		LEFT JOIN organization_roles r ON r.role_id::text = e.role_id::text
-		LEFT JOIN organization_divisions d ON d.division_id::text = r.division_id::text
+		LEFT JOIN organization_roles r ON r.role_id::text = e.role_id::text AND %s
+		LEFT JOIN organization_divisions d ON d.division_id::text = r.division_id::text AND %s
 		WHERE %s AND %s AND COALESCE(e.status, 0) > 0
 		LIMIT 1
-	`, uuidExpr, orgExpr)
+	`, roleOrgExpr, divisionOrgExpr, uuidExpr, orgExpr)
 
 	if r.driver == "mysql" {
 		query = fmt.Sprintf(`
@@ -264,11 +297,11 @@ func (r *OrganizationRepository) EmployeeDetail(organizationID, uuid string) (*m
 				COALESCE(e.updated_by, ''),
 				e.updated_at
 			FROM employee e
-			LEFT JOIN organization_roles r ON r.role_id = e.role_id
-			LEFT JOIN organization_divisions d ON d.division_id = r.division_id
+			LEFT JOIN organization_roles r ON r.role_id = e.role_id AND %s
+			LEFT JOIN organization_divisions d ON d.division_id = r.division_id AND %s
 			WHERE %s AND %s AND COALESCE(e.status, 0) > 0
 			LIMIT 1
-		`, uuidExpr, orgExpr)
+		`, roleOrgExpr, divisionOrgExpr, uuidExpr, orgExpr)
 	}
 
 	var it model.EmployeeDetailResponse
@@ -279,7 +312,7 @@ func (r *OrganizationRepository) EmployeeDetail(organizationID, uuid string) (*m
 	var createdAt sql.NullTime
 	var updatedAt sql.NullTime
 
-	err := database.QueryRow(r.db, query, uuid, organizationID).Scan(
+	err = t.QueryRow(query, args...).Scan(
 		&it.UUID,
 		&it.EmployeeID,
 		&it.NIK,
@@ -329,7 +362,12 @@ func (r *OrganizationRepository) EmployeeDetail(organizationID, uuid string) (*m
 	return &it, nil
 }
 
-func (r *OrganizationRepository) CreateEmployee(organizationID, createdBy string, req *model.CreateEmployeeRequest) (string, error) {
+func (r *OrganizationRepository) CreateEmployee(ctx context.Context, createdBy string, req *model.CreateEmployeeRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	id := uuid.New().String()
 	now := time.Now()
 
@@ -370,8 +408,7 @@ func (r *OrganizationRepository) CreateEmployee(organizationID, createdBy string
 		r.getPlaceholder(11), r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15), r.getPlaceholder(16),
 		r.getPlaceholder(17))
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		id,
 		req.EmployeeID,
@@ -397,7 +434,12 @@ func (r *OrganizationRepository) CreateEmployee(organizationID, createdBy string
 	return id, nil
 }
 
-func (r *OrganizationRepository) UpdateEmployee(organizationID, updatedBy string, req *model.UpdateEmployeeRequest) error {
+func (r *OrganizationRepository) UpdateEmployee(ctx context.Context, updatedBy string, req *model.UpdateEmployeeRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	now := time.Now()
 
 	var birth interface{}
@@ -503,7 +545,7 @@ func (r *OrganizationRepository) UpdateEmployee(organizationID, updatedBy string
 
 	args = append([]interface{}{organizationID, req.UUID}, args...)
 
-	res, err := database.Exec(r.db, query, args...)
+	res, err := t.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -514,7 +556,12 @@ func (r *OrganizationRepository) UpdateEmployee(organizationID, updatedBy string
 	return nil
 }
 
-func (r *OrganizationRepository) DeactivateEmployeeByEmployeeID(organizationID, updatedBy, employeeID string) error {
+func (r *OrganizationRepository) DeactivateEmployeeByEmployeeID(ctx context.Context, updatedBy, employeeID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	now := time.Now()
 
 	orgExpr := "organization_id = " + r.getPlaceholder(1)
@@ -527,7 +574,7 @@ func (r *OrganizationRepository) DeactivateEmployeeByEmployeeID(organizationID,
 		WHERE %s AND uuid = %s AND COALESCE(status, 0) > 0
 	`, r.getPlaceholder(3), r.getPlaceholder(4), orgExpr, r.getPlaceholder(2))
 
-	res, err := database.Exec(r.db, query, organizationID, employeeID, now, updatedBy)
+	res, err := t.Exec(query, organizationID, employeeID, now, updatedBy)
 	if err != nil {
 		return err
 	}
@@ -538,27 +585,33 @@ func (r *OrganizationRepository) DeactivateEmployeeByEmployeeID(organizationID,
 	return nil
 }
 
-func (r *OrganizationRepository) EmployeeShiftSchedule(organizationID, roleID, divisionID string, startDate, endDate time.Time) ([]model.EmployeeShiftScheduleRow, error) {
-	orgExpr := "e.organization_id = " + r.getPlaceholder(1)
+func (r *OrganizationRepository) EmployeeShiftSchedule(ctx context.Context, roleID, divisionID string, startDate, endDate time.Time) ([]model.EmployeeShiftScheduleRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	roleOrgExpr, args := r.sharedOrganizationFilter("r.organization_id", organizationID, nil)
+	pos := len(args) + 1
+	orgExpr := "e.organization_id = " + r.getPlaceholder(pos+2)
 	shiftJoinEmployeeExpr := "es.employee_id = e.uuid"
 	shiftJoinOrgExpr := "es.organization_id = e.organization_id"
-	shiftJoinDateExpr := "es.shift_date BETWEEN " + r.getPlaceholder(2) + " AND " + r.getPlaceholder(3)
-	roleJoinExpr := "r.role_id = e.role_id"
+	shiftJoinDateExpr := "es.shift_date BETWEEN " + r.getPlaceholder(pos) + " AND " + r.getPlaceholder(pos+1)
+	roleJoinExpr := "r.role_id = e.role_id AND " + roleOrgExpr
 
 	if r.driver != "mysql" {
-		orgExpr = "e.organization_id::text = " + r.getPlaceholder(1)
+		orgExpr = "e.organization_id::text = " + r.getPlaceholder(pos+2)
 		shiftJoinEmployeeExpr = "es.employee_id::text = e.uuid::text"
 		shiftJoinOrgExpr = "es.organization_id::text = e.organization_id::text"
-		roleJoinExpr = "r.role_id::text = e.role_id::text"
+		roleJoinExpr = "r.role_id::text = e.role_id::text AND " + roleOrgExpr
 	}
 
 	whereParts := make([]string, 0, 4)
 	whereParts = append(whereParts, orgExpr)
 	whereParts = append(whereParts, "COALESCE(e.status, 0) > 0")
 
-	args := make([]interface{}, 0, 5)
-	args = append(args, organizationID, startDate, endDate)
-	pos := 4
+	args = append(args, startDate, endDate, organizationID)
+	pos += 3
 
 	if strings.TrimSpace(roleID) != "" {
 		roleExpr := "e.role_id = " + r.getPlaceholder(pos)
@@ -594,7 +647,7 @@ func (r *OrganizationRepository) EmployeeShiftSchedule(organizationID, roleID, d
 		ORDER BY e.fullname ASC, es.shift_date ASC
 	`, roleJoinExpr, shiftJoinEmployeeExpr, shiftJoinOrgExpr, shiftJoinDateExpr, strings.Join(whereParts, " AND "))
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -632,7 +685,12 @@ func (r *OrganizationRepository) EmployeeShiftSchedule(organizationID, roleID, d
 	return out, nil
 }
 
-func (r *OrganizationRepository) EmployeeShiftOffdayCounts(organizationID string, employeeIDs []string, monthStart, monthEnd time.Time) (map[string]int, error) {
+func (r *OrganizationRepository) EmployeeShiftOffdayCounts(ctx context.Context, employeeIDs []string, monthStart, monthEnd time.Time) (map[string]int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	out := make(map[string]int)
 	if len(employeeIDs) == 0 {
 		return out, nil
@@ -665,7 +723,7 @@ func (r *OrganizationRepository) EmployeeShiftOffdayCounts(organizationID string
 		GROUP BY %s
 	`, employeeCol, orgExpr, r.getPlaceholder(2), r.getPlaceholder(3), employeeCol, strings.Join(inParts, ", "), employeeCol)
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -685,7 +743,12 @@ func (r *OrganizationRepository) EmployeeShiftOffdayCounts(organizationID string
 	return out, nil
 }
 
-func (r *OrganizationRepository) CreateEmployeeShiftSchedules(organizationID, createdBy string, items []model.EmployeeShiftSubmitItem) ([]string, error) {
+func (r *OrganizationRepository) CreateEmployeeShiftSchedules(ctx context.Context, createdBy string, items []model.EmployeeShiftSubmitItem) ([]string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	if len(items) == 0 {
 		return []string{}, nil
 	}
@@ -738,13 +801,18 @@ func (r *OrganizationRepository) CreateEmployeeShiftSchedules(organizationID, cr
 		VALUES %s
 	`, strings.Join(valueParts, ", "))
 
-	if _, err := database.Exec(r.db, query, args...); err != nil {
+	if _, err := t.Exec(query, args...); err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
-func (r *OrganizationRepository) DeleteEmployeeShiftSchedule(organizationID, employeeID, shiftID string) error {
+func (r *OrganizationRepository) DeleteEmployeeShiftSchedule(ctx context.Context, employeeID, shiftID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(1)
 	employeeExpr := "employee_id = " + r.getPlaceholder(2)
 	shiftExpr := "shift_id = " + r.getPlaceholder(3)
@@ -759,7 +827,7 @@ func (r *OrganizationRepository) DeleteEmployeeShiftSchedule(organizationID, emp
 		WHERE %s AND %s AND %s
 	`, orgExpr, employeeExpr, shiftExpr)
 
-	res, err := database.Exec(r.db, query, organizationID, employeeID, shiftID)
+	res, err := t.Exec(query, organizationID, employeeID, shiftID)
 	if err != nil {
 		return err
 	}
@@ -770,7 +838,12 @@ func (r *OrganizationRepository) DeleteEmployeeShiftSchedule(organizationID, emp
 	return nil
 }
 
-func (r *OrganizationRepository) EmployeeOperationsHistoryTotal(organizationID, employeeID string, startDate, endDate *time.Time) (int, error) {
+func (r *OrganizationRepository) EmployeeOperationsHistoryTotal(ctx context.Context, employeeID string, startDate, endDate *time.Time) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(2)
 	empExpr := "employee_id = " + r.getPlaceholder(1)
 
@@ -792,7 +865,7 @@ func (r *OrganizationRepository) EmployeeOperationsHistoryTotal(organizationID,
 	`, strings.Join(whereParts, " AND "))
 
 	var total int
-	err := database.QueryRow(r.db, query, args...).Scan(&total)
+	err = t.QueryRow(query, args...).Scan(&total)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
@@ -802,7 +875,12 @@ func (r *OrganizationRepository) EmployeeOperationsHistoryTotal(organizationID,
 	return total, nil
 }
 
-func (r *OrganizationRepository) EmployeeOperationsHistory(organizationID, employeeID string, startDate, endDate *time.Time) ([]model.EmployeeOperationsHistoryRow, error) {
+func (r *OrganizationRepository) EmployeeOperationsHistory(ctx context.Context, employeeID string, startDate, endDate *time.Time) ([]model.EmployeeOperationsHistoryRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "st.organization_id = " + r.getPlaceholder(2)
 	empExpr := "st.employee_id = " + r.getPlaceholder(1)
 
@@ -823,10 +901,10 @@ func (r *OrganizationRepository) EmployeeOperationsHistory(organizationID, emplo
 
 	cityAggExpr := "STRING_AGG(DISTINCT foi.city_id::text, ', ')"
 
-	empJoinExpr := "e.uuid = st.employee_id"
-	foJoinExpr := "fo.order_id = st.order_id"
-	foiJoinExpr := "foi.order_id = st.order_id"
-	fpJoinExpr := "fp.uuid = fo.price_id"
+	empJoinExpr := "e.uuid = st.employee_id AND e.organization_id = st.organization_id"
+	foJoinExpr := "fo.order_id = st.order_id AND fo.organization_id = st.organization_id"
+	foiJoinExpr := "foi.order_id = st.order_id AND foi.organization_id = st.organization_id"
+	fpJoinExpr := "fp.uuid = fo.price_id AND fp.organization_id = fo.organization_id"
 
 	query := fmt.Sprintf(`
 		SELECT 
@@ -844,15 +922,15 @@ func (r *OrganizationRepository) EmployeeOperationsHistory(organizationID, emplo
 		INNER JOIN fleet_order_itinerary foi ON %s
 		INNER JOIN fleet_orders fo ON %s
 		INNER JOIN fleet_prices fp ON %s
-		LEFT JOIN schedule_fleets su ON su.order_id = st.order_id
-		LEFT JOIN fleets f ON f.uuid = su.fleet_id
-		LEFT JOIN fleet_units fu ON fu.unit_id = su.unit_id
+		LEFT JOIN schedule_fleets su ON su.order_id = st.order_id AND su.organization_id = st.organization_id
+		LEFT JOIN fleets f ON f.uuid = su.fleet_id AND f.organization_id = su.organization_id
+		LEFT JOIN fleet_units fu ON fu.unit_id = su.unit_id AND fu.organization_id = su.organization_id
 		WHERE %s
 		GROUP BY e.fullname, st.order_id, st.start_date, st.end_date, fp.rent_type,f.fleet_name, fu.vehicle_id, fu.plate_number
 		ORDER BY st.start_date DESC
 	`, cityAggExpr, empJoinExpr, foiJoinExpr, foJoinExpr, fpJoinExpr, strings.Join(whereParts, " AND "))
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
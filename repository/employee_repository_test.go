@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestEmployeeRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOrganizationRepository(openTenantFake(t), "postgres")
+	now := time.Now()
+	later := now.AddDate(0, 0, 6)
+	checkTenantIsolation(t, tenantCalls{
+		"RoleExistsForOrgOrDefault": func(ctx context.Context) error {
+			_, err := r.RoleExistsForOrgOrDefault(ctx, "role-of-b")
+			return err
+		},
+		"EmployeeIDExists": func(ctx context.Context) error { _, err := r.EmployeeIDExists(ctx, employeeOfB); return err },
+		"NIKExists":        func(ctx context.Context) error { _, err := r.NIKExists(ctx, "3201"); return err },
+		"ListEmployees":    func(ctx context.Context) error { _, err := r.ListEmployees(ctx, "operation"); return err },
+		"EmployeeDetail":   func(ctx context.Context) error { _, err := r.EmployeeDetail(ctx, employeeOfB); return err },
+		"CreateEmployee": func(ctx context.Context) error {
+			_, err := r.CreateEmployee(ctx, employeeOfB, &model.CreateEmployeeRequest{EmployeeID: "EMP-1", Fullname: "Budi"})
+			return err
+		},
+		"UpdateEmployee": func(ctx context.Context) error {
+			return r.UpdateEmployee(ctx, employeeOfB, &model.UpdateEmployeeRequest{UUID: employeeOfB, EmployeeID: "EMP-1", Fullname: "Budi"})
+		},
+		"DeactivateEmployeeByEmployeeID": func(ctx context.Context) error {
+			return r.DeactivateEmployeeByEmployeeID(ctx, employeeOfB, employeeOfB)
+		},
+		"EmployeeShiftSchedule": func(ctx context.Context) error {
+			_, err := r.EmployeeShiftSchedule(ctx, "role-of-b", "division-of-b", now, later)
+			return err
+		},
+		"EmployeeShiftOffdayCounts": func(ctx context.Context) error {
+			_, err := r.EmployeeShiftOffdayCounts(ctx, []string{employeeOfB}, now, later)
+			return err
+		},
+		"CreateEmployeeShiftSchedules": func(ctx context.Context) error {
+			_, err := r.CreateEmployeeShiftSchedules(ctx, employeeOfB, []model.EmployeeShiftSubmitItem{{EmployeeID: employeeOfB, ShiftDate: "2024-01-01"}})
+			return err
+		},
+		"DeleteEmployeeShiftSchedule": func(ctx context.Context) error {
+			return r.DeleteEmployeeShiftSchedule(ctx, employeeOfB, "shift-of-b")
+		},
+		"EmployeeOperationsHistoryTotal": func(ctx context.Context) error {
+			_, err := r.EmployeeOperationsHistoryTotal(ctx, employeeOfB, &now, &later)
+			return err
+		},
+		"EmployeeOperationsHistory": func(ctx context.Context) error {
+			_, err := r.EmployeeOperationsHistory(ctx, employeeOfB, &now, &later)
+			return err
+		},
+	})
+}
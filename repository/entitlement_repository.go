@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+// EntitlementRepository reads the package of an organization and how many
+// records it has of each quota
+type EntitlementRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewEntitlementRepository(db *sql.DB, driver string) *EntitlementRepository {
+	return &EntitlementRepository{
+		db:     db,
+		driver: driver,
+	}
+}
+
+func (r *EntitlementRepository) getPlaceholder(pos int) string {
+	if r.driver == "mysql" {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", pos)
+}
+
+func (r *EntitlementRepository) orgWhere(pos int) string {
+	if r.driver == "mysql" {
+		return "organization_id = " + r.getPlaceholder(pos)
+	}
+	return "organization_id::text = " + r.getPlaceholder(pos)
+}
+
+// GetActivePackageID returns the package of the organization's subscription
+// that has not expired or is in its grace period, empty when there is none
+func (r *EntitlementRepository) GetActivePackageID(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	query := fmt.Sprintf(`
+		SELECT package_id
+		FROM _subscription
+		WHERE %s AND (expiry_date >= CURRENT_DATE OR status = %d)
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, r.orgWhere(1), model.SubscriptionStatusGrace)
+
+	var packageID string
+	if err := t.QueryRow(query, t.OrganizationID()).Scan(&packageID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return packageID, nil
+}
+
+// quotaCountQueries count the records of the organization that use a quota;
+// deleted and disabled records do not count
+var quotaCountQueries = map[string]string{
+	model.QuotaDashboardUsers: "SELECT COUNT(*) FROM organization_users WHERE %s AND is_active = true",
+	model.QuotaAssistantUsers: "SELECT COUNT(*) FROM assistant_accounts WHERE %s AND status = 1",
+	model.QuotaFleets:         "SELECT COUNT(*) FROM fleets WHERE %s AND status > 0",
+	model.QuotaFleetUnits:     "SELECT COUNT(*) FROM fleet_units WHERE %s AND COALESCE(status, 1) > 0",
+	model.QuotaPartners:       "SELECT COUNT(*) FROM operation_partner WHERE %s",
+}
+
+// CountUsage returns how many records of quota the organization has
+func (r *EntitlementRepository) CountUsage(ctx context.Context, quota string) (int, error) {
+	query, ok := quotaCountQueries[quota]
+	if !ok {
+		return 0, fmt.Errorf("unknown quota %q", quota)
+	}
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	if err := t.QueryRow(fmt.Sprintf(query, r.orgWhere(1)), t.OrganizationID()).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// LockOrganization starts a transaction holding the row lock of the
+// organization. Quotas counted in it cannot change under another request of
+// the same organization until the transaction ends.
+func (r *EntitlementRepository) LockOrganization(ctx context.Context) (*database.TenantTx, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT organization_id FROM organizations WHERE " + r.orgWhere(1) + " FOR UPDATE"
+	var id string
+	if err := tx.QueryRow(query, tx.OrganizationID()).Scan(&id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// CountUsageTx is CountUsage within tx
+func (r *EntitlementRepository) CountUsageTx(tx *database.TenantTx, quota string) (int, error) {
+	query, ok := quotaCountQueries[quota]
+	if !ok {
+		return 0, fmt.Errorf("unknown quota %q", quota)
+	}
+
+	var total int
+	if err := tx.QueryRow(fmt.Sprintf(query, r.orgWhere(1)), tx.OrganizationID()).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
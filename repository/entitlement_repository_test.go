@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+)
+
+func TestEntitlementRepositoryIsTenantScoped(t *testing.T) {
+	r := NewEntitlementRepository(openTenantFake(t), "postgres")
+	calls := tenantCalls{
+		"GetActivePackageID": func(ctx context.Context) error { _, err := r.GetActivePackageID(ctx); return err },
+		"LockOrganization": func(ctx context.Context) error {
+			tx, err := r.LockOrganization(ctx)
+			if err == nil {
+				tx.Rollback()
+			}
+			return err
+		},
+	}
+	for _, quota := range model.Quotas {
+		quota := quota
+		calls["CountUsage "+quota] = func(ctx context.Context) error { _, err := r.CountUsage(ctx, quota); return err }
+	}
+	checkTenantIsolation(t, calls)
+}
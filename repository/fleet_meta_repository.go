@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -22,9 +23,14 @@ func (r *FleetMetaRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-func (r *FleetMetaRepository) FindBodies(organizationID string, search string) ([]string, error) {
+func (r *FleetMetaRepository) FindBodies(ctx context.Context, search string) ([]string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+
 	var rows *sql.Rows
-	var err error
 	if search != "" {
 		if r.driver == "mysql" {
 			query := fmt.Sprintf(`
@@ -32,14 +38,14 @@ func (r *FleetMetaRepository) FindBodies(organizationID string, search string) (
 				WHERE organization_id = %s AND body IS NOT NULL AND body <> '' AND body LIKE CONCAT('%%', %s, '%%')
 				ORDER BY body
 			`, r.getPlaceholder(1), r.getPlaceholder(2))
-			rows, err = database.Query(r.db, query, organizationID, search)
+			rows, err = t.Query(query, organizationID, search)
 		} else {
 			query := fmt.Sprintf(`
 				SELECT DISTINCT body FROM fleets
 				WHERE organization_id = %s AND COALESCE(body, '') <> '' AND body LIKE '%%' || %s || '%%'
 				ORDER BY body
 			`, r.getPlaceholder(1), r.getPlaceholder(2))
-			rows, err = database.Query(r.db, query, organizationID, search)
+			rows, err = t.Query(query, organizationID, search)
 		}
 	} else {
 		query := fmt.Sprintf(`
@@ -47,7 +53,7 @@ func (r *FleetMetaRepository) FindBodies(organizationID string, search string) (
 			WHERE organization_id = %s AND body IS NOT NULL AND body <> ''
 			ORDER BY body
 		`, r.getPlaceholder(1))
-		rows, err = database.Query(r.db, query, organizationID)
+		rows, err = t.Query(query, organizationID)
 	}
 	if err != nil {
 		return nil, err
@@ -69,9 +75,14 @@ func (r *FleetMetaRepository) FindBodies(organizationID string, search string) (
 	return list, nil
 }
 
-func (r *FleetMetaRepository) FindEngines(organizationID string, search string) ([]string, error) {
+func (r *FleetMetaRepository) FindEngines(ctx context.Context, search string) ([]string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+
 	var rows *sql.Rows
-	var err error
 	if search != "" {
 		if r.driver == "mysql" {
 			query := fmt.Sprintf(`
@@ -79,14 +90,14 @@ func (r *FleetMetaRepository) FindEngines(organizationID string, search string)
                 WHERE organization_id = %s AND engine LIKE CONCAT('%%', %s, '%%')
                 ORDER BY engine
             `, r.getPlaceholder(1), r.getPlaceholder(2))
-			rows, err = database.Query(r.db, query, organizationID, search)
+			rows, err = t.Query(query, organizationID, search)
 		} else {
 			query := fmt.Sprintf(`
                 SELECT engine FROM fleets
                 WHERE organization_id = %s AND engine LIKE '%%' || %s || '%%'
                 ORDER BY engine
             `, r.getPlaceholder(1), r.getPlaceholder(2))
-			rows, err = database.Query(r.db, query, organizationID, search)
+			rows, err = t.Query(query, organizationID, search)
 		}
 	} else {
 		query := fmt.Sprintf(`
@@ -94,7 +105,7 @@ func (r *FleetMetaRepository) FindEngines(organizationID string, search string)
             WHERE organization_id = %s
             ORDER BY engine
         `, r.getPlaceholder(1))
-		rows, err = database.Query(r.db, query, organizationID)
+		rows, err = t.Query(query, organizationID)
 	}
 	if err != nil {
 		return nil, err
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFleetMetaRepositoryIsTenantScoped(t *testing.T) {
+	r := NewFleetMetaRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"FindBodies":        func(ctx context.Context) error { _, err := r.FindBodies(ctx, ""); return err },
+		"FindBodiesSearch":  func(ctx context.Context) error { _, err := r.FindBodies(ctx, "Hiace"); return err },
+		"FindEngines":       func(ctx context.Context) error { _, err := r.FindEngines(ctx, ""); return err },
+		"FindEnginesSearch": func(ctx context.Context) error { _, err := r.FindEngines(ctx, "Diesel"); return err },
+	})
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"math"
 	"os"
 	"service-travego/configs"
@@ -154,24 +156,25 @@ func NewFleetRepository(db *sql.DB, driver string) *FleetRepository {
 
 func uuid2() string { return uuid.New().String() }
 
-func (r *FleetRepository) ListFleets(req *model.ListFleetRequest) ([]model.FleetListItem, error) {
-	totalUnitExpr := "COALESCE((SELECT COUNT(*) FROM fleet_units fu WHERE fu.fleet_id::text = f.uuid::text AND fu.status = 1), 0)"
+func (r *FleetRepository) ListFleets(ctx context.Context, req *model.ListFleetRequest) ([]model.FleetListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	totalUnitExpr := "COALESCE((SELECT COUNT(*) FROM fleet_units fu WHERE fu.fleet_id::text = f.uuid::text AND fu.organization_id = f.organization_id AND fu.status = 1), 0)"
 	base := `
         SELECT f.uuid AS fleet_id, ft.label AS fleet_type, f.fleet_name, f.capacity, f.engine, f.body, %s as total_unit, f.active, f.status, f.thumbnail, STRING_AGG(DISTINCT fu.engine::text, ', ') AS engines, STRING_AGG(DISTINCT fu.capacity::text, ', ') AS capacities
         FROM fleets f INNER JOIN fleet_types ft ON f.fleet_type = ft.id
-		LEFT JOIN fleet_units fu ON fu.fleet_id::text = f.uuid::text
+		LEFT JOIN fleet_units fu ON fu.fleet_id::text = f.uuid::text AND fu.organization_id = f.organization_id
     `
 	base = fmt.Sprintf(base, totalUnitExpr)
 	where := make([]string, 0, 4)
 	args := make([]interface{}, 0, 4)
 	pos := 1
 	where = append(where, "f.status > 0")
-	if req.OrganizationID != "" {
-		orgExpr := fmt.Sprintf("f.organization_id::text = %s", r.getPlaceholder(pos))
-		where = append(where, orgExpr)
-		args = append(args, req.OrganizationID)
-		pos++
-	}
+	where = append(where, fmt.Sprintf("f.organization_id::text = %s", r.getPlaceholder(pos)))
+	args = append(args, t.OrganizationID())
+	pos++
 	if req.FleetType != "" {
 		where = append(where, fmt.Sprintf("f.fleet_type = %s", r.getPlaceholder(pos)))
 		args = append(args, req.FleetType)
@@ -194,7 +197,7 @@ func (r *FleetRepository) ListFleets(req *model.ListFleetRequest) ([]model.Fleet
 		pos++
 	}
 	if req.PickupLocation > 0 {
-		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM fleet_pickup fp WHERE fp.fleet_id = f.uuid AND fp.city_id = %s)", r.getPlaceholder(pos)))
+		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM fleet_pickup fp WHERE fp.fleet_id = f.uuid AND fp.organization_id = f.organization_id AND fp.city_id = %s)", r.getPlaceholder(pos)))
 		args = append(args, req.PickupLocation)
 		pos++
 	}
@@ -203,9 +206,9 @@ func (r *FleetRepository) ListFleets(req *model.ListFleetRequest) ([]model.Fleet
 		query = query + " WHERE " + strings.Join(where, " AND ")
 	}
 	query = query + " GROUP BY f.uuid, ft.label, f.fleet_name, f.capacity, f.engine, f.body, f.active, f.status, f.thumbnail, f.created_at ORDER BY f.created_at DESC"
-	fmt.Println(query)
+	slog.DebugContext(ctx, "list fleets", "query", query)
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +251,12 @@ func (r *FleetRepository) ListFleets(req *model.ListFleetRequest) ([]model.Fleet
 	return items, nil
 }
 
-func (r *FleetRepository) InsertFacilities(orgID string, facilityNames []string) ([]string, error) {
+func (r *FleetRepository) InsertFacilities(ctx context.Context, facilityNames []string) ([]string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	if len(facilityNames) == 0 {
 		return nil, nil
 	}
@@ -266,7 +274,7 @@ func (r *FleetRepository) InsertFacilities(orgID string, facilityNames []string)
 	for _, name := range facilityNames {
 		var fetchedID string
 
-		err := r.db.QueryRow(query, uuid2(), name, "Check", orgID).Scan(&fetchedID)
+		err := t.QueryRow(query, uuid2(), name, "Check", orgID).Scan(&fetchedID)
 		if err != nil {
 			return nil, err
 		}
@@ -278,7 +286,11 @@ func (r *FleetRepository) InsertFacilities(orgID string, facilityNames []string)
 	return ids, nil
 }
 
-func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, error) {
+func (r *FleetRepository) CreateFleet(ctx context.Context, req *model.CreateFleetRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
 	id := uuid2()
 	now := time.Now()
 	query := `
@@ -290,8 +302,8 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 		r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15), r.getPlaceholder(16))
 
 	// Status default 1 (Active/Draft?)
-	fmt.Println("is public --- ", req.IsPublic)
-	_, err := database.Exec(r.db, query,
+	slog.DebugContext(ctx, "create fleet", "is_public", req.IsPublic)
+	_, err = t.Exec(query,
 		id,
 		req.OrganizationID,
 		req.FleetType,
@@ -316,11 +328,11 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 
 	// Insert facilities
 	if len(req.FacilityIDs) > 0 {
-		fQuery := fmt.Sprintf("INSERT INTO fleet_facilities (uuid, fleet_id, facility_id) VALUES (%s, %s, %s)",
-			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+		fQuery := fmt.Sprintf("INSERT INTO fleet_facilities (uuid, fleet_id, facility_id, organization_id) VALUES (%s, %s, %s, %s)",
+			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 		for _, facID := range req.FacilityIDs {
 			fID := uuid2()
-			_, err := database.Exec(r.db, fQuery, fID, id, facID)
+			_, err := t.Exec(fQuery, fID, id, facID, req.OrganizationID)
 			if err != nil {
 				return "", err
 			}
@@ -333,7 +345,7 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 		for _, p := range req.Pickup {
 			pID := uuid2()
-			_, err := database.Exec(r.db, pQuery, pID, id, req.OrganizationID, p.CityID)
+			_, err := t.Exec(pQuery, pID, id, req.OrganizationID, p.CityID)
 			if err != nil {
 				return "", err
 			}
@@ -346,7 +358,7 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 		for _, a := range req.Addon {
 			aID := uuid2()
-			_, err := database.Exec(r.db, aQuery, aID, id, req.OrganizationID, a.AddonName, a.AddonDesc, a.AddonPrice)
+			_, err := t.Exec(aQuery, aID, id, req.OrganizationID, a.AddonName, a.AddonDesc, a.AddonPrice)
 			if err != nil {
 				return "", err
 			}
@@ -359,7 +371,7 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
 		for _, pr := range req.Pricing {
 			prID := uuid2()
-			_, err := database.Exec(r.db, prQuery, prID, id, req.OrganizationID, pr.Duration, pr.RentType, pr.Price, pr.DiscAmount, pr.DiscPrice, pr.Uom)
+			_, err := t.Exec(prQuery, prID, id, req.OrganizationID, pr.Duration, pr.RentType, pr.Price, pr.DiscAmount, pr.DiscPrice, pr.Uom)
 			if err != nil {
 				return "", err
 			}
@@ -368,11 +380,11 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 
 	// Insert images
 	if len(req.Images) > 0 {
-		iQuery := fmt.Sprintf("INSERT INTO fleet_images (uuid, fleet_id, path_file) VALUES (%s, %s, %s)",
-			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+		iQuery := fmt.Sprintf("INSERT INTO fleet_images (uuid, fleet_id, path_file, organization_id) VALUES (%s, %s, %s, %s)",
+			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 		for _, img := range req.Images {
 			iID := uuid2()
-			_, err := database.Exec(r.db, iQuery, iID, id, img.PathFile)
+			_, err := t.Exec(iQuery, iID, id, img.PathFile, req.OrganizationID)
 			if err != nil {
 				return "", err
 			}
@@ -382,8 +394,12 @@ func (r *FleetRepository) CreateFleet(req *model.CreateFleetRequest) (string, er
 	return id, nil
 }
 
-func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) UpdateFleet(ctx context.Context, req *model.UpdateFleetRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -408,8 +424,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 		r.getPlaceholder(14),
 	)
 
-	res, err := database.TxExec(
-		tx,
+	res, err := tx.Exec(
 		updateFleetQuery,
 		req.FleetType,
 		req.FleetName,
@@ -435,15 +450,15 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 	}
 
 	if len(req.FacilityIDs) > 0 {
-		delQuery := fmt.Sprintf("DELETE FROM fleet_facilities WHERE fleet_id = %s", r.getPlaceholder(1))
-		if _, err := database.TxExec(tx, delQuery, req.FleetID); err != nil {
+		delQuery := fmt.Sprintf("DELETE FROM fleet_facilities WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+		if _, err := tx.Exec(delQuery, req.FleetID, req.OrganizationID); err != nil {
 			return err
 		}
-		fQuery := fmt.Sprintf("INSERT INTO fleet_facilities (uuid, fleet_id, facility_id) VALUES (%s, %s, %s)",
-			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+		fQuery := fmt.Sprintf("INSERT INTO fleet_facilities (uuid, fleet_id, facility_id, organization_id) VALUES (%s, %s, %s, %s)",
+			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 		for _, facID := range req.FacilityIDs {
 			fID := uuid2()
-			if _, err := database.TxExec(tx, fQuery, fID, req.FleetID, facID); err != nil {
+			if _, err := tx.Exec(fQuery, fID, req.FleetID, facID, req.OrganizationID); err != nil {
 				return err
 			}
 		}
@@ -455,14 +470,14 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 			if it.UUID == "" {
 				newID := uuid2()
 				insertQuery := fmt.Sprintf("INSERT INTO fleet_pickup (uuid, fleet_id, organization_id, city_id) VALUES (%s, %s, %s, %s)", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
-				if _, err := database.TxExec(tx, insertQuery, newID, req.FleetID, req.OrganizationID, it.CityID); err != nil {
+				if _, err := tx.Exec(insertQuery, newID, req.FleetID, req.OrganizationID, it.CityID); err != nil {
 					return err
 				}
 				keepIDs = append(keepIDs, newID)
 				continue
 			}
 			updateQuery := fmt.Sprintf("UPDATE fleet_pickup SET city_id = %s WHERE uuid = %s AND fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
-			if _, err := database.TxExec(tx, updateQuery, it.CityID, it.UUID, req.FleetID, req.OrganizationID); err != nil {
+			if _, err := tx.Exec(updateQuery, it.CityID, it.UUID, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 			keepIDs = append(keepIDs, it.UUID)
@@ -470,7 +485,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 
 		if len(keepIDs) == 0 {
 			delQuery := fmt.Sprintf("DELETE FROM fleet_pickup WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExec(tx, delQuery, req.FleetID, req.OrganizationID); err != nil {
+			if _, err := tx.Exec(delQuery, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 		} else {
@@ -482,7 +497,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 				args = append(args, id)
 			}
 			delQuery := fmt.Sprintf("DELETE FROM fleet_pickup WHERE fleet_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(in, ","))
-			if _, err := database.TxExec(tx, delQuery, args...); err != nil {
+			if _, err := tx.Exec(delQuery, args...); err != nil {
 				return err
 			}
 		}
@@ -495,7 +510,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 				newID := uuid2()
 				insertQuery := fmt.Sprintf("INSERT INTO fleet_addon (uuid, fleet_id, organization_id, addon_name, addon_desc, addon_price) VALUES (%s, %s, %s, %s, %s, %s)",
 					r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-				if _, err := database.TxExec(tx, insertQuery, newID, req.FleetID, req.OrganizationID, it.AddonName, it.AddonDesc, it.AddonPrice); err != nil {
+				if _, err := tx.Exec(insertQuery, newID, req.FleetID, req.OrganizationID, it.AddonName, it.AddonDesc, it.AddonPrice); err != nil {
 					return err
 				}
 				keepIDs = append(keepIDs, newID)
@@ -503,7 +518,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 			}
 			updateQuery := fmt.Sprintf("UPDATE fleet_addon SET addon_name = %s, addon_desc = %s, addon_price = %s WHERE uuid = %s AND fleet_id = %s AND organization_id = %s",
 				r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-			if _, err := database.TxExec(tx, updateQuery, it.AddonName, it.AddonDesc, it.AddonPrice, it.UUID, req.FleetID, req.OrganizationID); err != nil {
+			if _, err := tx.Exec(updateQuery, it.AddonName, it.AddonDesc, it.AddonPrice, it.UUID, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 			keepIDs = append(keepIDs, it.UUID)
@@ -511,7 +526,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 
 		if len(keepIDs) == 0 {
 			delQuery := fmt.Sprintf("DELETE FROM fleet_addon WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExec(tx, delQuery, req.FleetID, req.OrganizationID); err != nil {
+			if _, err := tx.Exec(delQuery, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 		} else {
@@ -523,7 +538,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 				args = append(args, id)
 			}
 			delQuery := fmt.Sprintf("DELETE FROM fleet_addon WHERE fleet_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(in, ","))
-			if _, err := database.TxExec(tx, delQuery, args...); err != nil {
+			if _, err := tx.Exec(delQuery, args...); err != nil {
 				return err
 			}
 		}
@@ -536,7 +551,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 				newID := uuid2()
 				insertQuery := fmt.Sprintf("INSERT INTO fleet_prices (uuid, fleet_id, organization_id, duration, rent_type, price, disc_amount, disc_price, uom) VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)",
 					r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
-				if _, err := database.TxExec(tx, insertQuery, newID, req.FleetID, req.OrganizationID, it.Duration, it.RentType, it.Price, it.DiscAmount, it.DiscPrice, it.Uom); err != nil {
+				if _, err := tx.Exec(insertQuery, newID, req.FleetID, req.OrganizationID, it.Duration, it.RentType, it.Price, it.DiscAmount, it.DiscPrice, it.Uom); err != nil {
 					return err
 				}
 				keepIDs = append(keepIDs, newID)
@@ -544,7 +559,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 			}
 			updateQuery := fmt.Sprintf("UPDATE fleet_prices SET duration = %s, rent_type = %s, price = %s, disc_amount = %s, disc_price = %s, uom = %s WHERE uuid = %s AND fleet_id = %s AND organization_id = %s",
 				r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
-			if _, err := database.TxExec(tx, updateQuery, it.Duration, it.RentType, it.Price, it.DiscAmount, it.DiscPrice, it.Uom, it.UUID, req.FleetID, req.OrganizationID); err != nil {
+			if _, err := tx.Exec(updateQuery, it.Duration, it.RentType, it.Price, it.DiscAmount, it.DiscPrice, it.Uom, it.UUID, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 			keepIDs = append(keepIDs, it.UUID)
@@ -552,7 +567,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 
 		if len(keepIDs) == 0 {
 			delQuery := fmt.Sprintf("DELETE FROM fleet_prices WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExec(tx, delQuery, req.FleetID, req.OrganizationID); err != nil {
+			if _, err := tx.Exec(delQuery, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 		} else {
@@ -564,7 +579,7 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 				args = append(args, id)
 			}
 			delQuery := fmt.Sprintf("DELETE FROM fleet_prices WHERE fleet_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(in, ","))
-			if _, err := database.TxExec(tx, delQuery, args...); err != nil {
+			if _, err := tx.Exec(delQuery, args...); err != nil {
 				return err
 			}
 		}
@@ -575,35 +590,35 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 		for _, it := range req.Images {
 			if it.UUID == "" {
 				newID := uuid2()
-				insertQuery := fmt.Sprintf("INSERT INTO fleet_images (uuid, fleet_id, path_file) VALUES (%s, %s, %s)", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-				if _, err := database.TxExec(tx, insertQuery, newID, req.FleetID, it.PathFile); err != nil {
+				insertQuery := fmt.Sprintf("INSERT INTO fleet_images (uuid, fleet_id, path_file, organization_id) VALUES (%s, %s, %s, %s)", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+				if _, err := tx.Exec(insertQuery, newID, req.FleetID, it.PathFile, req.OrganizationID); err != nil {
 					return err
 				}
 				keepIDs = append(keepIDs, newID)
 				continue
 			}
-			updateQuery := fmt.Sprintf("UPDATE fleet_images SET path_file = %s WHERE uuid = %s AND fleet_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-			if _, err := database.TxExec(tx, updateQuery, it.PathFile, it.UUID, req.FleetID); err != nil {
+			updateQuery := fmt.Sprintf("UPDATE fleet_images SET path_file = %s WHERE uuid = %s AND fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+			if _, err := tx.Exec(updateQuery, it.PathFile, it.UUID, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 			keepIDs = append(keepIDs, it.UUID)
 		}
 
 		if len(keepIDs) == 0 {
-			delQuery := fmt.Sprintf("DELETE FROM fleet_images WHERE fleet_id = %s", r.getPlaceholder(1))
-			if _, err := database.TxExec(tx, delQuery, req.FleetID); err != nil {
+			delQuery := fmt.Sprintf("DELETE FROM fleet_images WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+			if _, err := tx.Exec(delQuery, req.FleetID, req.OrganizationID); err != nil {
 				return err
 			}
 		} else {
 			in := make([]string, 0, len(keepIDs))
-			args := make([]interface{}, 0, 1+len(keepIDs))
-			args = append(args, req.FleetID)
+			args := make([]interface{}, 0, 2+len(keepIDs))
+			args = append(args, req.FleetID, req.OrganizationID)
 			for i, id := range keepIDs {
-				in = append(in, r.getPlaceholder(i+2))
+				in = append(in, r.getPlaceholder(i+3))
 				args = append(args, id)
 			}
-			delQuery := fmt.Sprintf("DELETE FROM fleet_images WHERE fleet_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), strings.Join(in, ","))
-			if _, err := database.TxExec(tx, delQuery, args...); err != nil {
+			delQuery := fmt.Sprintf("DELETE FROM fleet_images WHERE fleet_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(in, ","))
+			if _, err := tx.Exec(delQuery, args...); err != nil {
 				return err
 			}
 		}
@@ -612,7 +627,12 @@ func (r *FleetRepository) UpdateFleet(req *model.UpdateFleetRequest) error {
 	return tx.Commit()
 }
 
-func (r *FleetRepository) GetFleetDetail(id, orgID string) (*model.FleetDetailResponse, error) {
+func (r *FleetRepository) GetFleetDetail(ctx context.Context, id string) (*model.FleetDetailResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	// Main fleet data
 	query := fmt.Sprintf(`
         SELECT fleet_name, fleet_type, capacity, engine, body, description, active, status, thumbnail
@@ -622,7 +642,7 @@ func (r *FleetRepository) GetFleetDetail(id, orgID string) (*model.FleetDetailRe
 
 	var res model.FleetDetailResponse
 	res.Meta.FleetID = id
-	err := database.QueryRow(r.db, query, id, orgID).Scan(
+	err = t.QueryRow(query, id, orgID).Scan(
 		&res.Meta.FleetName, &res.Meta.FleetType, &res.Meta.Capacity, &res.Meta.Engine,
 		&res.Meta.Body, &res.Meta.Description, &res.Meta.Active, &res.Meta.Status, &res.Meta.Thumbnail,
 	)
@@ -631,27 +651,32 @@ func (r *FleetRepository) GetFleetDetail(id, orgID string) (*model.FleetDetailRe
 	}
 
 	// Facilities
-	res.Facilities, _ = r.GetFleetFacilities(id)
+	res.Facilities, _ = r.GetFleetFacilities(ctx, id)
 
 	// Pickup
-	res.Pickup, _ = r.GetFleetPickup(orgID, id)
+	res.Pickup, _ = r.GetFleetPickup(ctx, id)
 
 	// Pricing
-	res.Pricing, _ = r.GetFleetPricing(orgID, id)
+	res.Pricing, _ = r.GetFleetPricing(ctx, id)
 
 	// Addon
-	res.Addon, _ = r.GetFleetAddon(orgID, id)
+	res.Addon, _ = r.GetFleetAddon(ctx, id)
 
 	// Images
-	res.Images, _ = r.GetFleetImages(id)
+	res.Images, _ = r.GetFleetImages(ctx, id)
 
 	return &res, nil
 }
 
-func (r *FleetRepository) GetFleetFacilities(fleetID string) ([]model.FacilityItem, error) {
+func (r *FleetRepository) GetFleetFacilities(ctx context.Context, fleetID string) ([]model.FacilityItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	// First try new approach with facilities table
-	query := fmt.Sprintf("SELECT f.facility_name, f.facility_icon FROM fleet_facilities ff INNER JOIN facilities f ON f.facility_id = ff.facility_id WHERE ff.fleet_id = %s", r.getPlaceholder(1))
-	rows, err := database.Query(r.db, query, fleetID)
+	query := fmt.Sprintf("SELECT f.facility_name, f.facility_icon FROM fleet_facilities ff INNER JOIN facilities f ON f.facility_id = ff.facility_id AND (f.organization_id IS NULL OR f.organization_id = ff.organization_id) WHERE ff.fleet_id = %s AND ff.organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	rows, err := t.Query(query, fleetID, orgID)
 	if err == nil {
 		defer rows.Close()
 		var facilities []model.FacilityItem
@@ -667,8 +692,8 @@ func (r *FleetRepository) GetFleetFacilities(fleetID string) ([]model.FacilityIt
 	}
 
 	// Fallback to old approach (direct facility column)
-	query = fmt.Sprintf("SELECT COALESCE(facility, '') AS facility FROM fleet_facilities WHERE fleet_id = %s", r.getPlaceholder(1))
-	rows, err = database.Query(r.db, query, fleetID)
+	query = fmt.Sprintf("SELECT COALESCE(facility, '') AS facility FROM fleet_facilities WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	rows, err = t.Query(query, fleetID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -687,7 +712,12 @@ func (r *FleetRepository) GetFleetFacilities(fleetID string) ([]model.FacilityIt
 	return facilities, nil
 }
 
-func (r *FleetRepository) GetFacilityList(orgID string) ([]model.FacilityItem, error) {
+func (r *FleetRepository) GetFacilityList(ctx context.Context) ([]model.FacilityItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	var query string
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = `
@@ -705,7 +735,7 @@ func (r *FleetRepository) GetFacilityList(orgID string) ([]model.FacilityItem, e
 		`
 	}
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -732,22 +762,20 @@ func (r *FleetRepository) GetFacilityList(orgID string) ([]model.FacilityItem, e
 	return items, nil
 }
 
-func (r *FleetRepository) GetFleetPricing(orgID, fleetID string) ([]model.FleetPriceItem, error) {
+func (r *FleetRepository) GetFleetPricing(ctx context.Context, fleetID string) ([]model.FleetPriceItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := `
         SELECT uuid, duration, rent_type, price, disc_amount, disc_price, uom
         FROM fleet_prices
-        WHERE fleet_id = %s
+        WHERE fleet_id = %s AND organization_id = %s
     `
-	args := []interface{}{fleetID}
-	if orgID != "" {
-		query += " AND organization_id = %s"
-		query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
-		args = append(args, orgID)
-	} else {
-		query = fmt.Sprintf(query, r.getPlaceholder(1))
-	}
+	query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, fleetID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -767,8 +795,12 @@ func (r *FleetRepository) GetFleetPricing(orgID, fleetID string) ([]model.FleetP
 	return items, nil
 }
 
-func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) CreateOrder(ctx context.Context, req *model.CreateOrderRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -790,47 +822,47 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), configs.PaymentStatusWaitingPayment, r.getPlaceholder(12), r.getPlaceholder(13))
 
-	_, _ = database.TxExec(tx, "SAVEPOINT sp_orders")
-	_, err = database.TxExec(tx, orderQueryFull, orderID, req.FleetID, req.StartDate, req.EndDate, req.PickupCityID, req.PickupLocation, req.Qty, req.PriceID, now, totalAmount, req.AdditionalAmount, req.OrganizationID, req.AdditionalRequest)
+	_, _ = tx.Exec("SAVEPOINT sp_orders")
+	_, err = tx.Exec(orderQueryFull, orderID, req.FleetID, req.StartDate, req.EndDate, req.PickupCityID, req.PickupLocation, req.Qty, req.PriceID, now, totalAmount, req.AdditionalAmount, req.OrganizationID, req.AdditionalRequest)
 	if err != nil {
 		errMsg := strings.ToLower(err.Error())
 		if strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "does not exist") {
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_orders")
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_orders")
 			orderQueryWithRequest := fmt.Sprintf(`
 				INSERT INTO fleet_orders (order_id, fleet_id, start_date, end_date, pickup_city_id, pickup_location, unit_qty, price_id, created_at, total_amount, status, payment_status, organization_id, additional_request)
 				VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 2, %d, %s, %s)
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 				r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), configs.PaymentStatusWaitingPayment, r.getPlaceholder(11), r.getPlaceholder(12))
 
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_orders_2")
-			_, err = database.TxExec(tx, orderQueryWithRequest, orderID, req.FleetID, req.StartDate, req.EndDate, req.PickupCityID, req.PickupLocation, req.Qty, req.PriceID, now, totalAmount, req.OrganizationID, req.AdditionalRequest)
+			_, _ = tx.Exec("SAVEPOINT sp_orders_2")
+			_, err = tx.Exec(orderQueryWithRequest, orderID, req.FleetID, req.StartDate, req.EndDate, req.PickupCityID, req.PickupLocation, req.Qty, req.PriceID, now, totalAmount, req.OrganizationID, req.AdditionalRequest)
 			if err != nil {
 				errMsg2 := strings.ToLower(err.Error())
 				if strings.Contains(errMsg2, "unknown column") || strings.Contains(errMsg2, "does not exist") {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_orders_2")
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_orders_2")
 					orderQueryLegacy := fmt.Sprintf(`
 						INSERT INTO fleet_orders (order_id, fleet_id, start_date, end_date, pickup_city_id, pickup_location, unit_qty, price_id, created_at, total_amount, status, payment_status, organization_id)
 						VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 2, %d, %s)
 					`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 						r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), configs.PaymentStatusWaitingPayment, r.getPlaceholder(11))
 
-					_, err = database.TxExec(tx, orderQueryLegacy, orderID, req.FleetID, req.StartDate, req.EndDate, req.PickupCityID, req.PickupLocation, req.Qty, req.PriceID, now, totalAmount, req.OrganizationID)
+					_, err = tx.Exec(orderQueryLegacy, orderID, req.FleetID, req.StartDate, req.EndDate, req.PickupCityID, req.PickupLocation, req.Qty, req.PriceID, now, totalAmount, req.OrganizationID)
 					if err != nil {
-						fmt.Println("error create orders legacy", err)
+						slog.ErrorContext(ctx, "error create orders legacy", "error", err)
 						return err
 					}
 				} else {
-					fmt.Println("error create orders fallback", err)
+					slog.ErrorContext(ctx, "error create orders fallback", "error", err)
 					return err
 				}
 			}
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_orders_2")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_orders_2")
 		} else {
-			fmt.Println("error create orders full", err)
+			slog.ErrorContext(ctx, "error create orders full", "error", err)
 			return err
 		}
 	}
-	_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_orders")
+	_, _ = tx.Exec("RELEASE SAVEPOINT sp_orders")
 
 	// 2. Check and Insert customers
 	var custID string
@@ -842,9 +874,9 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 		LIMIT 1
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	err = database.TxQueryRow(tx, checkQuery, req.OrganizationID, req.Phone, req.Email, req.Email).Scan(&custID)
+	err = tx.QueryRow(checkQuery, req.OrganizationID, req.Phone, req.Email, req.Email).Scan(&custID)
 	if err != nil && err != sql.ErrNoRows {
-		fmt.Println("error checking existing customer", err)
+		slog.ErrorContext(ctx, "error checking existing customer", "error", err)
 		return err
 	}
 
@@ -855,9 +887,9 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 			VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
 
-		_, err = database.TxExec(tx, customerQuery, custID, req.OrganizationID, req.Fullname, req.Email, req.Address, req.CityID, req.CompanyName, now, req.Phone)
+		_, err = tx.Exec(customerQuery, custID, req.OrganizationID, req.Fullname, req.Email, req.Address, req.CityID, req.CompanyName, now, req.Phone)
 		if err != nil {
-			fmt.Println("error insert customers", err)
+			slog.ErrorContext(ctx, "error insert customers", "error", err)
 			return err
 		}
 	}
@@ -868,19 +900,19 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 		VALUES (%s, %s, %s, %s, %s)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
 
-	_, err = database.TxExec(tx, custOrderQuery, orderID, custID, req.OrderType, now, req.OrganizationID)
+	_, err = tx.Exec(custOrderQuery, orderID, custID, req.OrderType, now, req.OrganizationID)
 	if err != nil {
-		fmt.Println("error insert customer_orders", err)
+		slog.ErrorContext(ctx, "error insert customer_orders", "error", err)
 		return err
 	}
 
 	// 4. Insert fleet_order_items
 	// Get price again or pass it from service. Since we are in repo, let's query it.
 	var price float64
-	priceQuery := fmt.Sprintf("SELECT price FROM fleet_prices WHERE uuid = %s", r.getPlaceholder(1))
-	err = database.TxQueryRow(tx, priceQuery, req.PriceID).Scan(&price)
+	priceQuery := fmt.Sprintf("SELECT price FROM fleet_prices WHERE uuid = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	err = tx.QueryRow(priceQuery, req.PriceID, req.OrganizationID).Scan(&price)
 	if err != nil {
-		fmt.Println("error get price for order items", err)
+		slog.ErrorContext(ctx, "error get price for order items", "error", err)
 		return err
 	}
 
@@ -888,48 +920,81 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 	var addonAmount float64
 	if len(req.Addons) > 0 {
 		placeholders := make([]string, len(req.Addons))
-		args := make([]interface{}, len(req.Addons))
+		args := make([]interface{}, len(req.Addons), len(req.Addons)+1)
 		for i, id := range req.Addons {
 			placeholders[i] = r.getPlaceholder(i + 1)
 			args[i] = id
 		}
-		addonSumQuery := fmt.Sprintf("SELECT COALESCE(SUM(addon_price), 0) FROM fleet_addon WHERE uuid IN (%s)", strings.Join(placeholders, ","))
-		err = database.TxQueryRow(tx, addonSumQuery, args...).Scan(&addonAmount)
+		args = append(args, req.OrganizationID)
+		addonSumQuery := fmt.Sprintf("SELECT COALESCE(SUM(addon_price), 0) FROM fleet_addon WHERE uuid IN (%s) AND organization_id = %s", strings.Join(placeholders, ","), r.getPlaceholder(len(req.Addons)+1))
+		err = tx.QueryRow(addonSumQuery, args...).Scan(&addonAmount)
 		if err != nil {
-			fmt.Println("error get addon sum for order items", err)
+			slog.ErrorContext(ctx, "error get addon sum for order items", "error", err)
 			return err
 		}
 	}
 
-	subTotal := (float64(req.Qty) * price) + (float64(req.Qty) * addonAmount)
+	subTotal := (float64(req.Qty) * price) + (float64(req.Qty) * req.RuleAdjustment) + (float64(req.Qty) * addonAmount)
+	if req.Voucher != nil {
+		subTotal -= req.Voucher.DiscountAmount
+		if subTotal < 0 {
+			subTotal = 0
+		}
+	}
 	orderItemID := uuid2()
 	itemQuery := fmt.Sprintf(`
 		INSERT INTO fleet_order_items (order_item_id, organization_id, order_id, fleet_id, price_id, quantity, sub_total, create_at, status, addon_amount)
 		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, 1, %s)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
 
-	_, err = database.TxExec(tx, itemQuery, orderItemID, req.OrganizationID, orderID, req.FleetID, req.PriceID, req.Qty, subTotal, now, addonAmount)
+	_, err = tx.Exec(itemQuery, orderItemID, req.OrganizationID, orderID, req.FleetID, req.PriceID, req.Qty, subTotal, now, addonAmount)
 	if err != nil {
-		fmt.Println("error insert fleet_order_items", err)
+		slog.ErrorContext(ctx, "error insert fleet_order_items", "error", err)
+		return err
+	}
+
+	err = saveOrderItemPriceRules(tx, r.getPlaceholder, orderID, orderItemID, req.FleetID, req.PriceID, req.RuleAdjustment, req.PriceRuleLines)
+	if err != nil {
+		slog.ErrorContext(ctx, "error insert fleet_order_price_rules", "error", err)
+		return err
+	}
+
+	if req.Voucher != nil {
+		voucherQuery := fmt.Sprintf(`UPDATE fleet_order_items SET voucher_discount = %s WHERE order_item_id = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+		if _, err = tx.Exec(voucherQuery, req.Voucher.DiscountAmount, orderItemID, req.OrganizationID); err != nil {
+			slog.ErrorContext(ctx, "error update fleet_order_items voucher_discount", "error", err)
+			return err
+		}
+		if err = redeemVoucher(tx, r.getPlaceholder, orderID, model.VoucherOrderFleet, custID, req.Voucher); err != nil {
+			slog.ErrorContext(ctx, "error redeem voucher", "error", err)
+			return err
+		}
+	}
+
+	if err = saveOrderInstallments(tx, r.getPlaceholder, orderID, model.InstallmentOrderFleet, req.Installments); err != nil {
+		slog.ErrorContext(ctx, "error insert order_installments", "error", err)
 		return err
 	}
 
 	// 5. Insert fleet_orders_addon (existing logic, keeping it but it might be redundant now)
 	if len(req.Addons) > 0 {
+		addonPriceQuery := fmt.Sprintf(`SELECT addon_price FROM fleet_addon WHERE uuid = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
 		addonQuery := fmt.Sprintf(`
 			INSERT INTO fleet_order_addons (order_addon_id, order_id, order_item_id, organization_id, addon_id, addon_price, created_at)
-			SELECT %s, %s, %s, %s, uuid, addon_price, %s FROM fleet_addon WHERE uuid = %s
-		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
+			VALUES (%s, %s, %s, %s, %s, %s, %s)
+		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
 		for _, addonID := range req.Addons {
-			id := uuid2()
-			res, err := database.TxExec(tx, addonQuery, id, orderID, orderItemID, req.OrganizationID, now, addonID)
-			if err != nil {
-				fmt.Println("error create addon orders", err)
+			var addonPrice sql.NullFloat64
+			if err = tx.QueryRow(addonPriceQuery, addonID, req.OrganizationID).Scan(&addonPrice); err != nil {
+				if err == sql.ErrNoRows {
+					err = fmt.Errorf("addon not found: %s", addonID)
+				}
 				return err
 			}
-			rows, _ := res.RowsAffected()
-			if rows == 0 {
-				return fmt.Errorf("addon not found: %s", addonID)
+			id := uuid2()
+			if _, err = tx.Exec(addonQuery, id, orderID, orderItemID, req.OrganizationID, addonID, addonPrice, now); err != nil {
+				slog.ErrorContext(ctx, "error create addon orders", "error", err)
+				return err
 			}
 		}
 	}
@@ -941,10 +1006,6 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 			INSERT INTO fleet_order_itinerary (fleet_itinerary_id, order_id, day_num, city_id, location, organization_id, created_at)
 			VALUES (%s, %s, %s, %s, %s, %s, %s)
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
-		itineraryWithoutOrg := fmt.Sprintf(`
-			INSERT INTO fleet_order_itinerary (fleet_itinerary_id, order_id, day_num, city_id, location, created_at)
-			VALUES (%s, %s, %s, %s, %s, %s)
-		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 		destQuery := fmt.Sprintf(`
 			INSERT INTO fleet_order_destinations (order_id, city_id, location, created_at)
 			VALUES (%s, %s, %s, %s)
@@ -958,41 +1019,34 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 			id := uuid2()
 			dayNum := i + 1
 			for {
-				_, _ = database.TxExec(tx, "SAVEPOINT sp_dest")
+				_, _ = tx.Exec("SAVEPOINT sp_dest")
 				switch mode {
 				case 0:
-					_, err = database.TxExec(tx, itineraryWithOrg, id, orderID, dayNum, dest.CityID, dest.Location, req.OrganizationID, now)
-				case 1:
-					_, err = database.TxExec(tx, itineraryWithoutOrg, id, orderID, dayNum, dest.CityID, dest.Location, now)
+					_, err = tx.Exec(itineraryWithOrg, id, orderID, dayNum, dest.CityID, dest.Location, req.OrganizationID, now)
 				case 2:
-					_, err = database.TxExec(tx, destQuery, orderID, dest.CityID, dest.Location, now)
+					_, err = tx.Exec(destQuery, orderID, dest.CityID, dest.Location, now)
 				default:
-					_, err = database.TxExec(tx, destQueryWithID, id, orderID, dest.CityID, dest.Location, now)
+					_, err = tx.Exec(destQueryWithID, id, orderID, dest.CityID, dest.Location, now)
 				}
 
 				if err == nil {
-					_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_dest")
+					_, _ = tx.Exec("RELEASE SAVEPOINT sp_dest")
 					break
 				}
 
 				errMsg := strings.ToLower(err.Error())
-				if mode == 0 && (strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "does not exist")) {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_dest")
-					mode = 1
-					continue
-				}
-				if (mode == 0 || mode == 1) && (strings.Contains(errMsg, "doesn't exist") || strings.Contains(errMsg, "does not exist") || strings.Contains(errMsg, "relation") || strings.Contains(errMsg, "unknown table")) {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_dest")
+				if mode == 0 && (strings.Contains(errMsg, "doesn't exist") || strings.Contains(errMsg, "does not exist") || strings.Contains(errMsg, "relation") || strings.Contains(errMsg, "unknown table")) {
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_dest")
 					mode = 2
 					continue
 				}
 				if mode == 2 && (strings.Contains(errMsg, "unknown column \"uuid\"") || strings.Contains(errMsg, "column \"uuid\" of relation") || strings.Contains(errMsg, "does not exist")) {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_dest")
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_dest")
 					mode = 3
 					continue
 				}
 
-				fmt.Println("error create dest orders", err)
+				slog.ErrorContext(ctx, "error create dest orders", "error", err)
 				return err
 			}
 		}
@@ -1005,8 +1059,13 @@ func (r *FleetRepository) CreateOrder(req *model.CreateOrderRequest) error {
 	return nil
 }
 
-func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation string, qty int, priceID string, totalAmount, additionalAmount float64, customerID, orgID, createdBy string, itinerary []model.FleetOrderItineraryItem, addons []model.FleetOrderAddonItem, additionalRequest string, fleets []model.FleetOrderFleetItem) error {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) CreatePartnerOrder(ctx context.Context, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation string, qty int, priceID string, totalAmount, additionalAmount float64, customerID, createdBy string, itinerary []model.FleetOrderItineraryItem, addons []model.FleetOrderAddonItem, additionalRequest string, fleets []model.FleetOrderFleetItem) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -1027,31 +1086,31 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), configs.PaymentStatusWaitingPayment, r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14))
 
-	_, _ = database.TxExec(tx, "SAVEPOINT sp_orders")
-	_, err = database.TxExec(tx, insertWithCreatedBy, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation, qty, priceID, now, totalAmount, additionalAmount, orgID, createdBy, additionalRequest)
+	_, _ = tx.Exec("SAVEPOINT sp_orders")
+	_, err = tx.Exec(insertWithCreatedBy, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation, qty, priceID, now, totalAmount, additionalAmount, orgID, createdBy, additionalRequest)
 	if err != nil {
 		errMsg := strings.ToLower(err.Error())
 		if strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "does not exist") {
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_orders")
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_orders")
 			insertWithoutCreatedBy := fmt.Sprintf(`
 				INSERT INTO fleet_orders (order_id, fleet_id, start_date, end_date, pickup_city_id, pickup_location, unit_qty, price_id, created_at, total_amount, status, payment_status, organization_id, additional_request)
 				VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 1, %d, %s, %s)
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 				r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), configs.PaymentStatusWaitingPayment, r.getPlaceholder(11), r.getPlaceholder(12))
 
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_orders_2")
-			_, err = database.TxExec(tx, insertWithoutCreatedBy, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation, qty, priceID, now, totalAmount, orgID, additionalRequest)
+			_, _ = tx.Exec("SAVEPOINT sp_orders_2")
+			_, err = tx.Exec(insertWithoutCreatedBy, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation, qty, priceID, now, totalAmount, orgID, additionalRequest)
 			if err != nil {
 				errMsg2 := strings.ToLower(err.Error())
 				if strings.Contains(errMsg2, "additional_request") {
 					// Fallback if additional_request missing
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_orders_2")
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_orders_2")
 					insertLegacy := fmt.Sprintf(`
 						INSERT INTO fleet_orders (order_id, fleet_id, start_date, end_date, pickup_city_id, pickup_location, unit_qty, price_id, created_at, total_amount, status, payment_status, organization_id)
 						VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 1, %d, %s)
 					`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 						r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), configs.PaymentStatusWaitingPayment, r.getPlaceholder(11))
-					_, err = database.TxExec(tx, insertLegacy, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation, qty, priceID, now, totalAmount, orgID)
+					_, err = tx.Exec(insertLegacy, orderID, fleetID, startDate, endDate, pickupCityID, pickupLocation, qty, priceID, now, totalAmount, orgID)
 					if err != nil {
 						return fmt.Errorf("insert fleet_orders legacy: %w", err)
 					}
@@ -1059,19 +1118,19 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 					return fmt.Errorf("insert fleet_orders without created_by: %w", err)
 				}
 			}
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_orders_2")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_orders_2")
 		} else {
 			return fmt.Errorf("insert fleet_orders full: %w", err)
 		}
 	}
-	_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_orders")
+	_, _ = tx.Exec("RELEASE SAVEPOINT sp_orders")
 
 	custOrderWithCreatedBy := fmt.Sprintf(`
 		INSERT INTO customer_orders (order_id, customer_id, order_type, created_at, created_by, organization_id)
 		VALUES (%s, %s, 1, %s, %s, %s)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
-	_, _ = database.TxExec(tx, "SAVEPOINT sp_custorders")
-	_, err = database.TxExec(tx, custOrderWithCreatedBy, orderID, customerID, now, createdBy, orgID)
+	_, _ = tx.Exec("SAVEPOINT sp_custorders")
+	_, err = tx.Exec(custOrderWithCreatedBy, orderID, customerID, now, createdBy, orgID)
 	if err != nil {
 		errMsg := strings.ToLower(err.Error())
 		if strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "does not exist") {
@@ -1079,8 +1138,8 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 				INSERT INTO customer_orders (order_id, customer_id, order_type, created_at, organization_id)
 				VALUES (%s, %s, 1, %s, %s)
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_custorders")
-			_, err = database.TxExec(tx, custOrderWithoutCreatedBy, orderID, customerID, now, orgID)
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_custorders")
+			_, err = tx.Exec(custOrderWithoutCreatedBy, orderID, customerID, now, orgID)
 			if err != nil {
 				return fmt.Errorf("insert customer_orders: %w", err)
 			}
@@ -1088,7 +1147,7 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 			return fmt.Errorf("insert customer_orders: %w", err)
 		}
 	}
-	_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_custorders")
+	_, _ = tx.Exec("RELEASE SAVEPOINT sp_custorders")
 
 	if len(itinerary) > 0 {
 		mode := 0
@@ -1108,27 +1167,27 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 		for _, it := range itinerary {
 			id := uuid2()
 			for {
-				_, _ = database.TxExec(tx, "SAVEPOINT sp_it")
+				_, _ = tx.Exec("SAVEPOINT sp_it")
 				switch mode {
 				case 0:
-					_, err = database.TxExec(tx, itineraryWithCreatedBy, id, orderID, it.Day, it.CityID, it.Destination, orgID, now, createdBy)
+					_, err = tx.Exec(itineraryWithCreatedBy, id, orderID, it.Day, it.CityID, it.Destination, orgID, now, createdBy)
 				case 1:
-					_, err = database.TxExec(tx, itineraryWithoutCreatedBy, id, orderID, it.Day, it.CityID, it.Destination, orgID, now)
+					_, err = tx.Exec(itineraryWithoutCreatedBy, id, orderID, it.Day, it.CityID, it.Destination, orgID, now)
 				default:
-					_, err = database.TxExec(tx, destQuery, id, orderID, it.CityID, it.Destination, now)
+					_, err = tx.Exec(destQuery, id, orderID, it.CityID, it.Destination, now)
 				}
 				if err == nil {
-					_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_it")
+					_, _ = tx.Exec("RELEASE SAVEPOINT sp_it")
 					break
 				}
 				errMsg := strings.ToLower(err.Error())
 				if mode == 0 && (strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "does not exist")) {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_it")
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_it")
 					mode = 1
 					continue
 				}
 				if mode != 2 && (strings.Contains(errMsg, "doesn't exist") || strings.Contains(errMsg, "does not exist") || strings.Contains(errMsg, "relation") || strings.Contains(errMsg, "unknown table")) {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_it")
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_it")
 					mode = 2
 					continue
 				}
@@ -1138,11 +1197,11 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 	}
 
 	// Insert fleet_order_items
-	if err := r.CreateFleetOrderItems(tx, orderID, orgID, createdBy, fleets); err != nil {
+	if err = r.CreateFleetOrderItems(ctx, tx, orderID, createdBy, fleets); err != nil {
 		return err
 	}
 
-	_, _ = r.RecalculateFleetOrderTotal(tx, orderID, orgID)
+	_, _ = r.RecalculateFleetOrderTotal(tx, orderID)
 
 	if err = tx.Commit(); err != nil {
 		return err
@@ -1151,10 +1210,11 @@ func (r *FleetRepository) CreatePartnerOrder(orderID, fleetID, startDate, endDat
 	return nil
 }
 
-func (r *FleetRepository) CreateFleetOrderItems(tx *sql.Tx, orderID, orgID, createdBy string, fleets []model.FleetOrderFleetItem) error {
+func (r *FleetRepository) CreateFleetOrderItems(ctx context.Context, tx *database.TenantTx, orderID, createdBy string, fleets []model.FleetOrderFleetItem) error {
 	if len(fleets) == 0 {
 		return nil
 	}
+	orgID := tx.OrganizationID()
 
 	now := time.Now()
 
@@ -1174,8 +1234,8 @@ func (r *FleetRepository) CreateFleetOrderItems(tx *sql.Tx, orderID, orgID, crea
 			addonIDs = append(addonIDs, strings.TrimSpace(f.AddonID))
 		}
 	}
-	priceMap, _ := r.GetFleetPricesByIDs(priceIDs)
-	addonPriceMap, err := r.GetAddonPrices(addonIDs)
+	priceMap, _ := r.GetFleetPricesByIDs(ctx, priceIDs)
+	addonPriceMap, err := r.GetAddonPrices(ctx, addonIDs)
 	if err != nil {
 		return err
 	}
@@ -1218,7 +1278,7 @@ func (r *FleetRepository) CreateFleetOrderItems(tx *sql.Tx, orderID, orgID, crea
 			if p, ok := priceMap[strings.TrimSpace(f.PriceID)]; ok {
 				unitPrice = p
 			} else {
-				p, _, e := r.GetPriceByID(strings.TrimSpace(f.PriceID))
+				p, _, e := r.GetPriceByID(ctx, strings.TrimSpace(f.PriceID))
 				if e == nil {
 					unitPrice = p
 				}
@@ -1231,38 +1291,42 @@ func (r *FleetRepository) CreateFleetOrderItems(tx *sql.Tx, orderID, orgID, crea
 			addonAmount += addonPriceMap[a]
 		}
 
-		subTotal := (unitPrice * float64(q)) + (f.BiayaLain * float64(q)) + (addonAmount * float64(q)) - (f.Discount * float64(q))
+		subTotal := (unitPrice * float64(q)) + (f.RuleAdjustment * float64(q)) + (f.BiayaLain * float64(q)) + (addonAmount * float64(q)) - (f.Discount * float64(q))
 		if subTotal < 0 {
 			subTotal = 0
 		}
 
 		for {
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_fleet_items")
+			_, _ = tx.Exec("SAVEPOINT sp_fleet_items")
 			var err error
 			switch mode {
 			case 0:
-				_, err = database.TxExec(tx, insertWithAll, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, addonAmount, f.Discount, subTotal, now, createdBy)
+				_, err = tx.Exec(insertWithAll, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, addonAmount, f.Discount, subTotal, now, createdBy)
 			case 1:
-				_, err = database.TxExec(tx, insertWithoutCreatedBy, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, addonAmount, f.Discount, subTotal, now)
+				_, err = tx.Exec(insertWithoutCreatedBy, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, addonAmount, f.Discount, subTotal, now)
 			case 2:
-				_, err = database.TxExec(tx, insertWithAllNoAddon, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, f.Discount, subTotal, now, createdBy)
+				_, err = tx.Exec(insertWithAllNoAddon, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, f.Discount, subTotal, now, createdBy)
 			default:
-				_, err = database.TxExec(tx, insertWithoutCreatedByNoAddon, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, f.Discount, subTotal, now)
+				_, err = tx.Exec(insertWithoutCreatedByNoAddon, id, orgID, orderID, f.ArmadaID, f.PriceID, q, f.BiayaLain, f.Discount, subTotal, now)
 			}
 			if err == nil {
-				_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_fleet_items")
+				_, _ = tx.Exec("RELEASE SAVEPOINT sp_fleet_items")
 				break
 			}
 			errMsg := strings.ToLower(err.Error())
 			if mode < 3 && (strings.Contains(errMsg, "unknown column") || strings.Contains(errMsg, "does not exist")) {
-				_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_fleet_items")
+				_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_fleet_items")
 				mode++
 				continue
 			}
 			return fmt.Errorf("insert fleet_order_items: %w", err)
 		}
 
-		if err := r.replaceFleetOrderItemAddons(tx, orderID, orgID, createdBy, id, addonIDsForItem, now, false); err != nil {
+		if err := r.replaceFleetOrderItemAddons(tx, orderID, createdBy, id, addonIDsForItem, now, false); err != nil {
+			return err
+		}
+
+		if err := saveOrderItemPriceRules(tx, r.getPlaceholder, orderID, id, f.ArmadaID, f.PriceID, f.RuleAdjustment, f.PriceRuleLines); err != nil {
 			return err
 		}
 	}
@@ -1270,6 +1334,111 @@ func (r *FleetRepository) CreateFleetOrderItems(tx *sql.Tx, orderID, orgID, crea
 	return nil
 }
 
+// PartnerOrderPricing is what the pricing rules of an order were evaluated
+// against: the trip, the pickup city, the booking time and the fleet and price
+// of each item, keyed by order_item_id.
+type PartnerOrderPricing struct {
+	StartDate    time.Time
+	EndDate      time.Time
+	PickupCityID string
+	OrderedAt    time.Time
+	Items        map[string]PartnerOrderItemPricing
+}
+
+// PartnerOrderItemPricing is the priced fleet of an order item and the per-unit
+// pricing rule adjustment applied to it.
+type PartnerOrderItemPricing struct {
+	FleetID        string
+	PriceID        string
+	Quantity       int
+	RuleAdjustment float64
+}
+
+// GetPartnerOrderPricing returns what the pricing rules of an order were last
+// evaluated against.
+func (r *FleetRepository) GetPartnerOrderPricing(ctx context.Context, orderID string) (*PartnerOrderPricing, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	orgExpr := "organization_id = " + r.getPlaceholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "organization_id::text = " + r.getPlaceholder(2)
+	}
+
+	orderQuery := fmt.Sprintf(`SELECT start_date, end_date, COALESCE(pickup_city_id, 0), created_at FROM fleet_orders WHERE order_id = %s AND %s`, r.getPlaceholder(1), orgExpr)
+	var start, end, orderedAt sql.NullTime
+	var pickupCityID int64
+	if err := t.QueryRow(orderQuery, orderID, orgID).Scan(&start, &end, &pickupCityID, &orderedAt); err != nil {
+		return nil, err
+	}
+	pricing := &PartnerOrderPricing{
+		StartDate: start.Time,
+		EndDate:   end.Time,
+		OrderedAt: orderedAt.Time,
+		Items:     make(map[string]PartnerOrderItemPricing),
+	}
+	if pickupCityID != 0 {
+		pricing.PickupCityID = strconv.FormatInt(pickupCityID, 10)
+	}
+
+	itemQuery := fmt.Sprintf(`
+		SELECT COALESCE(CAST(order_item_id AS CHAR(36)), ''), COALESCE(CAST(fleet_id AS CHAR(36)), ''),
+		       COALESCE(CAST(price_id AS CHAR(36)), ''), COALESCE(quantity, 0), COALESCE(rule_adjustment, 0)
+		FROM fleet_order_items
+		WHERE order_id = %s AND %s
+	`, r.getPlaceholder(1), orgExpr)
+	rows, err := t.Query(itemQuery, orderID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var qty float64
+		var item PartnerOrderItemPricing
+		if err := rows.Scan(&id, &item.FleetID, &item.PriceID, &qty, &item.RuleAdjustment); err != nil {
+			return nil, err
+		}
+		item.Quantity = int(qty)
+		pricing.Items[id] = item
+	}
+	return pricing, rows.Err()
+}
+
+// GetOrderItemVoucherDiscounts returns the voucher discount booked on each
+// item of an order, keyed by order_item_id.
+func (r *FleetRepository) GetOrderItemVoucherDiscounts(ctx context.Context, orderID string) (map[string]float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	orgExpr := "organization_id = " + r.getPlaceholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "organization_id::text = " + r.getPlaceholder(2)
+	}
+	query := fmt.Sprintf(`SELECT COALESCE(CAST(order_item_id AS CHAR(36)), ''), COALESCE(voucher_discount, 0) FROM fleet_order_items WHERE order_id = %s AND %s`, r.getPlaceholder(1), orgExpr)
+	rows, err := t.Query(query, orderID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[string]float64)
+	for rows.Next() {
+		var id string
+		var discount float64
+		if err := rows.Scan(&id, &discount); err != nil {
+			return nil, err
+		}
+		res[id] = discount
+	}
+	return res, rows.Err()
+}
+
 func normalizeAddonIDs(addons []string, addonID string) []string {
 	seen := make(map[string]struct{}, len(addons)+1)
 	out := make([]string, 0, len(addons)+1)
@@ -1293,12 +1462,13 @@ func normalizeAddonIDs(addons []string, addonID string) []string {
 	return out
 }
 
-func (r *FleetRepository) replaceFleetOrderItemAddons(tx *sql.Tx, orderID, orgID, createdBy, orderItemID string, addonIDs []string, now time.Time, deleteFirst bool) error {
+func (r *FleetRepository) replaceFleetOrderItemAddons(tx *database.TenantTx, orderID, createdBy, orderItemID string, addonIDs []string, now time.Time, deleteFirst bool) error {
 	if len(addonIDs) == 0 {
 		if !deleteFirst {
 			return nil
 		}
 	}
+	orgID := tx.OrganizationID()
 
 	orgExpr := "organization_id = " + r.getPlaceholder(3)
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -1306,106 +1476,77 @@ func (r *FleetRepository) replaceFleetOrderItemAddons(tx *sql.Tx, orderID, orgID
 	}
 
 	if deleteFirst {
-		delCandidates := []struct {
-			query string
-			args  []interface{}
-		}{
-			{
-				query: fmt.Sprintf(`DELETE FROM fleet_order_addons WHERE order_item_id = %s AND order_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr),
-				args:  []interface{}{orderItemID, orderID, orgID},
-			},
-			{
-				query: fmt.Sprintf(`DELETE FROM fleet_order_addons WHERE order_item_id = %s AND order_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2)),
-				args:  []interface{}{orderItemID, orderID},
-			},
-		}
-		var deleted bool
-		for _, c := range delCandidates {
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_del_addons_item")
-			_, e := database.TxExec(tx, c.query, c.args...)
-			if e == nil {
-				_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_del_addons_item")
-				deleted = true
-				break
-			}
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_del_addons_item")
-			msg := strings.ToLower(e.Error())
-			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
-				continue
-			}
-			return e
+		delQuery := fmt.Sprintf(`DELETE FROM fleet_order_addons WHERE order_item_id = %s AND order_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
+		if _, err := tx.Exec(delQuery, orderItemID, orderID, orgID); err != nil {
+			return err
 		}
-		_ = deleted
 	}
 
 	if len(addonIDs) == 0 {
 		return nil
 	}
 
+	priceQuery := fmt.Sprintf(`SELECT addon_price FROM fleet_addon WHERE uuid = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
 	addonWithCreatedBy := fmt.Sprintf(`
 		INSERT INTO fleet_order_addons (order_addon_id, order_id, order_item_id, organization_id, addon_id, addon_price, created_at, created_by)
-		SELECT %s, %s, %s, %s, uuid, addon_price, %s, %s FROM fleet_addon WHERE uuid = %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
 	addonWithoutCreatedBy := fmt.Sprintf(`
 		INSERT INTO fleet_order_addons (order_addon_id, order_id, order_item_id, organization_id, addon_id, addon_price, created_at)
-		SELECT %s, %s, %s, %s, uuid, addon_price, %s FROM fleet_addon WHERE uuid = %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
+		VALUES (%s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
 
 	addonWithCreatedByQty := fmt.Sprintf(`
 		INSERT INTO fleet_order_addons (order_addon_id, order_id, order_item_id, organization_id, addon_id, addon_price, addon_qty, created_at, created_by)
-		SELECT %s, %s, %s, %s, uuid, addon_price, %s, %s, %s FROM fleet_addon WHERE uuid = %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
 	addonWithoutCreatedByQty := fmt.Sprintf(`
 		INSERT INTO fleet_order_addons (order_addon_id, order_id, order_item_id, organization_id, addon_id, addon_price, addon_qty, created_at)
-		SELECT %s, %s, %s, %s, uuid, addon_price, %s, %s FROM fleet_addon WHERE uuid = %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
 
 	for _, a := range addonIDs {
 		if strings.TrimSpace(a) == "" {
 			continue
 		}
+		// the price is copied from an addon of the organization only
+		var addonPrice sql.NullFloat64
+		if err := tx.QueryRow(priceQuery, a, orgID).Scan(&addonPrice); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("addon not found: %s", a)
+			}
+			return err
+		}
 		id := uuid2()
-		_, _ = database.TxExec(tx, "SAVEPOINT sp_ins_addon_item")
-		res, execErr := database.TxExec(tx, addonWithCreatedBy, id, orderID, orderItemID, orgID, now, createdBy, a)
+		_, _ = tx.Exec("SAVEPOINT sp_ins_addon_item")
+		_, execErr := tx.Exec(addonWithCreatedBy, id, orderID, orderItemID, orgID, a, addonPrice, now, createdBy)
 		if execErr != nil {
 			msg := strings.ToLower(execErr.Error())
 			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
-				_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_addon_item")
-				res2, execErr2 := database.TxExec(tx, addonWithoutCreatedBy, id, orderID, orderItemID, orgID, now, a)
+				_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_addon_item")
+				_, execErr2 := tx.Exec(addonWithoutCreatedBy, id, orderID, orderItemID, orgID, a, addonPrice, now)
 				if execErr2 == nil {
-					_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_addon_item")
-					rows2, _ := res2.RowsAffected()
-					if rows2 == 0 {
-						return fmt.Errorf("addon not found: %s", a)
-					}
+					_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_addon_item")
 					continue
 				}
 
 				msg2 := strings.ToLower(execErr2.Error())
 				if strings.Contains(msg2, "unknown column") || strings.Contains(msg2, "does not exist") || strings.Contains(msg2, "column") {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_addon_item")
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_addon_item")
 					addonQty := 1
-					res3, execErr3 := database.TxExec(tx, addonWithCreatedByQty, id, orderID, orderItemID, orgID, addonQty, now, createdBy, a)
+					_, execErr3 := tx.Exec(addonWithCreatedByQty, id, orderID, orderItemID, orgID, a, addonPrice, addonQty, now, createdBy)
 					if execErr3 == nil {
-						_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_addon_item")
-						rows3, _ := res3.RowsAffected()
-						if rows3 == 0 {
-							return fmt.Errorf("addon not found: %s", a)
-						}
+						_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_addon_item")
 						continue
 					}
 					msg3 := strings.ToLower(execErr3.Error())
 					if strings.Contains(msg3, "unknown column") || strings.Contains(msg3, "does not exist") || strings.Contains(msg3, "column") {
-						_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_addon_item")
-						res4, execErr4 := database.TxExec(tx, addonWithoutCreatedByQty, id, orderID, orderItemID, orgID, addonQty, now, a)
+						_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_addon_item")
+						_, execErr4 := tx.Exec(addonWithoutCreatedByQty, id, orderID, orderItemID, orgID, a, addonPrice, addonQty, now)
 						if execErr4 != nil {
 							return fmt.Errorf("insert addons: %w", execErr4)
 						}
-						_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_addon_item")
-						rows4, _ := res4.RowsAffected()
-						if rows4 == 0 {
-							return fmt.Errorf("addon not found: %s", a)
-						}
+						_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_addon_item")
 						continue
 					}
 					return fmt.Errorf("insert addons: %w", execErr3)
@@ -1414,24 +1555,26 @@ func (r *FleetRepository) replaceFleetOrderItemAddons(tx *sql.Tx, orderID, orgID
 			}
 			return fmt.Errorf("insert addons: %w", execErr)
 		}
-		_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_addon_item")
-		rows, _ := res.RowsAffected()
-		if rows == 0 {
-			return fmt.Errorf("addon not found: %s", a)
-		}
+		_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_addon_item")
 	}
 	return nil
 }
 
-func (r *FleetRepository) updateFleetOrderAddonPrice(tx *sql.Tx, orderID, orgID, updatedBy, addonID string, now time.Time) error {
+func (r *FleetRepository) updateFleetOrderAddonPrice(tx *database.TenantTx, orderID, updatedBy, addonID string, now time.Time) error {
 	addonID = strings.TrimSpace(addonID)
 	if addonID == "" {
 		return nil
 	}
+	orgID := tx.OrganizationID()
 
-	orgExpr := "organization_id = " + r.getPlaceholder(4)
-	if r.driver == "postgres" || r.driver == "pgx" {
-		orgExpr = "organization_id::text = " + r.getPlaceholder(4)
+	// an addon that is gone, or is not the organization's, keeps its price
+	priceQuery := fmt.Sprintf(`SELECT addon_price FROM fleet_addon WHERE uuid = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
+	var addonPrice sql.NullFloat64
+	if err := tx.QueryRow(priceQuery, addonID, orgID).Scan(&addonPrice); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
 	}
 
 	candidates := []struct {
@@ -1441,51 +1584,29 @@ func (r *FleetRepository) updateFleetOrderAddonPrice(tx *sql.Tx, orderID, orgID,
 		{
 			query: fmt.Sprintf(`
 				UPDATE fleet_order_addons
-				SET addon_price = (SELECT addon_price FROM fleet_addon WHERE uuid = %s),
-				    updated_at = %s, updated_by = %s
-				WHERE order_id = %s AND addon_id = %s AND %s
-				  AND EXISTS (SELECT 1 FROM fleet_addon WHERE uuid = %s)
-			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), orgExpr, r.getPlaceholder(6)),
-			args: []interface{}{addonID, now, updatedBy, orderID, addonID, orgID, addonID},
-		},
-		{
-			query: fmt.Sprintf(`
-				UPDATE fleet_order_addons
-				SET addon_price = (SELECT addon_price FROM fleet_addon WHERE uuid = %s)
-				WHERE order_id = %s AND addon_id = %s AND %s
-				  AND EXISTS (SELECT 1 FROM fleet_addon WHERE uuid = %s)
-			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), orgExpr, r.getPlaceholder(4)),
-			args: []interface{}{addonID, orderID, addonID, orgID, addonID},
-		},
-		{
-			query: fmt.Sprintf(`
-				UPDATE fleet_order_addons
-				SET addon_price = (SELECT addon_price FROM fleet_addon WHERE uuid = %s),
-				    updated_at = %s, updated_by = %s
-				WHERE order_id = %s AND addon_id = %s
-				  AND EXISTS (SELECT 1 FROM fleet_addon WHERE uuid = %s)
+				SET addon_price = %s, updated_at = %s, updated_by = %s
+				WHERE order_id = %s AND addon_id = %s AND organization_id = %s
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6)),
-			args: []interface{}{addonID, now, updatedBy, orderID, addonID, addonID},
+			args: []interface{}{addonPrice, now, updatedBy, orderID, addonID, orgID},
 		},
 		{
 			query: fmt.Sprintf(`
 				UPDATE fleet_order_addons
-				SET addon_price = (SELECT addon_price FROM fleet_addon WHERE uuid = %s)
-				WHERE order_id = %s AND addon_id = %s
-				  AND EXISTS (SELECT 1 FROM fleet_addon WHERE uuid = %s)
+				SET addon_price = %s
+				WHERE order_id = %s AND addon_id = %s AND organization_id = %s
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4)),
-			args: []interface{}{addonID, orderID, addonID, addonID},
+			args: []interface{}{addonPrice, orderID, addonID, orgID},
 		},
 	}
 
 	for _, c := range candidates {
-		_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_addon_price")
-		_, e := database.TxExec(tx, c.query, c.args...)
+		_, _ = tx.Exec("SAVEPOINT sp_upd_addon_price")
+		_, e := tx.Exec(c.query, c.args...)
 		if e == nil {
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_addon_price")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_addon_price")
 			return nil
 		}
-		_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_addon_price")
+		_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_addon_price")
 		msg := strings.ToLower(e.Error())
 		if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 			continue
@@ -1495,7 +1616,7 @@ func (r *FleetRepository) updateFleetOrderAddonPrice(tx *sql.Tx, orderID, orgID,
 	return nil
 }
 
-func (r *FleetRepository) getFleetOrderAddonIDsByItem(tx *sql.Tx, orderID, orgID, orderItemID string) (map[string]struct{}, error) {
+func (r *FleetRepository) getFleetOrderAddonIDsByItem(tx *database.TenantTx, orderID, orderItemID string) (map[string]struct{}, error) {
 	out := map[string]struct{}{}
 	orderItemID = strings.TrimSpace(orderItemID)
 	if orderItemID == "" {
@@ -1507,47 +1628,26 @@ func (r *FleetRepository) getFleetOrderAddonIDsByItem(tx *sql.Tx, orderID, orgID
 		orgExpr = "organization_id::text = " + r.getPlaceholder(3)
 	}
 
-	candidates := []struct {
-		query string
-		args  []interface{}
-	}{
-		{
-			query: fmt.Sprintf(`SELECT addon_id FROM fleet_order_addons WHERE order_item_id = %s AND order_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr),
-			args:  []interface{}{orderItemID, orderID, orgID},
-		},
-		{
-			query: fmt.Sprintf(`SELECT addon_id FROM fleet_order_addons WHERE order_item_id = %s AND order_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2)),
-			args:  []interface{}{orderItemID, orderID},
-		},
+	query := fmt.Sprintf(`SELECT addon_id FROM fleet_order_addons WHERE order_item_id = %s AND order_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
+	rows, err := tx.Query(query, orderItemID, orderID, tx.OrganizationID())
+	if err != nil {
+		return nil, err
 	}
-
-	for _, c := range candidates {
-		rows, err := database.TxQuery(tx, c.query, c.args...)
-		if err != nil {
-			msg := strings.ToLower(err.Error())
-			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
-				continue
-			}
+	defer rows.Close()
+	for rows.Next() {
+		var id sql.NullString
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		for rows.Next() {
-			var id sql.NullString
-			if err := rows.Scan(&id); err != nil {
-				_ = rows.Close()
-				return nil, err
-			}
-			if id.Valid && strings.TrimSpace(id.String) != "" {
-				out[strings.TrimSpace(id.String)] = struct{}{}
-			}
+		if id.Valid && strings.TrimSpace(id.String) != "" {
+			out[strings.TrimSpace(id.String)] = struct{}{}
 		}
-		_ = rows.Close()
-		return out, nil
 	}
-
-	return out, nil
+	return out, rows.Err()
 }
 
-func (r *FleetRepository) RecalculateFleetOrderTotal(tx *sql.Tx, orderID, organizationID string) (float64, error) {
+func (r *FleetRepository) RecalculateFleetOrderTotal(tx *database.TenantTx, orderID string) (float64, error) {
+	organizationID := tx.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.getPlaceholder(2)
@@ -1556,7 +1656,7 @@ func (r *FleetRepository) RecalculateFleetOrderTotal(tx *sql.Tx, orderID, organi
 	sumQuery := fmt.Sprintf(`SELECT COALESCE(SUM(sub_total), 0), COUNT(1) FROM fleet_order_items WHERE order_id = %s AND %s`, r.getPlaceholder(1), orgExpr)
 	var sumSubTotal float64
 	var count int
-	if err := database.TxQueryRow(tx, sumQuery, orderID, organizationID).Scan(&sumSubTotal, &count); err != nil {
+	if err := tx.QueryRow(sumQuery, orderID, organizationID).Scan(&sumSubTotal, &count); err != nil {
 		return 0, err
 	}
 	if count == 0 {
@@ -1576,7 +1676,7 @@ func (r *FleetRepository) RecalculateFleetOrderTotal(tx *sql.Tx, orderID, organi
 	}
 
 	updateQuery := fmt.Sprintf(`UPDATE fleet_orders SET total_amount = %s WHERE order_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), orgExprUpdate)
-	if _, err := database.TxExec(tx, updateQuery, total, orderID, organizationID); err != nil {
+	if _, err := tx.Exec(updateQuery, total, orderID, organizationID); err != nil {
 		return 0, err
 	}
 	return total, nil
@@ -1613,6 +1713,11 @@ type UpdatePartnerOrderFleetItem struct {
 	Discount     float64
 	SubTotal     float64
 	Addons       []string
+	// Repriced items store RuleAdjustment and PriceRuleLines in place of the
+	// pricing rule breakdown they had
+	Repriced       bool
+	RuleAdjustment float64
+	PriceRuleLines []model.PriceRuleLine
 }
 
 type UpdatePartnerOrderItineraryItem struct {
@@ -1622,8 +1727,12 @@ type UpdatePartnerOrderItineraryItem struct {
 	Location         string
 }
 
-func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err error) {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) UpdatePartnerOrder(ctx context.Context, in UpdatePartnerOrderInput) (err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -1641,7 +1750,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 	}
 	lockQuery := fmt.Sprintf("SELECT total_amount FROM fleet_orders WHERE order_id = %s AND %s FOR UPDATE", r.getPlaceholder(1), orgExpr2)
 	var oldTotal float64
-	if err := database.TxQueryRow(tx, lockQuery, in.OrderID, in.OrganizationID).Scan(&oldTotal); err != nil {
+	if err := tx.QueryRow(lockQuery, in.OrderID, in.OrganizationID).Scan(&oldTotal); err != nil {
 		return err
 	}
 
@@ -1730,10 +1839,10 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 
 	var updated bool
 	for _, c := range updateCandidates {
-		_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_orders")
-		res, e := database.TxExec(tx, c.query, c.args...)
+		_, _ = tx.Exec("SAVEPOINT sp_upd_orders")
+		res, e := tx.Exec(c.query, c.args...)
 		if e == nil {
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_orders")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_orders")
 			aff, _ := res.RowsAffected()
 			if aff == 0 {
 				return sql.ErrNoRows
@@ -1741,7 +1850,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 			updated = true
 			break
 		}
-		_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_orders")
+		_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_orders")
 		msg := strings.ToLower(e.Error())
 		if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 			continue
@@ -1777,15 +1886,15 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 		var affected int64
 		var lastErr error
 		for _, c := range custUpdateCandidates {
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_cust")
-			res, e := database.TxExec(tx, c.query, c.args...)
+			_, _ = tx.Exec("SAVEPOINT sp_upd_cust")
+			res, e := tx.Exec(c.query, c.args...)
 			if e == nil {
-				_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_cust")
+				_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_cust")
 				affected, _ = res.RowsAffected()
 				lastErr = nil
 				break
 			}
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_cust")
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_cust")
 			msg := strings.ToLower(e.Error())
 			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 				lastErr = e
@@ -1806,13 +1915,13 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 				VALUES (%s, %s, 1, %s, %s)
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_ins_cust")
-			_, e := database.TxExec(tx, insertCustWithCreatedBy, in.OrderID, in.CustomerID, now, in.UpdatedBy, in.OrganizationID)
+			_, _ = tx.Exec("SAVEPOINT sp_ins_cust")
+			_, e := tx.Exec(insertCustWithCreatedBy, in.OrderID, in.CustomerID, now, in.UpdatedBy, in.OrganizationID)
 			if e != nil {
 				msg := strings.ToLower(e.Error())
 				if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_cust")
-					_, e2 := database.TxExec(tx, insertCustWithoutCreatedBy, in.OrderID, in.CustomerID, now, in.OrganizationID)
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_cust")
+					_, e2 := tx.Exec(insertCustWithoutCreatedBy, in.OrderID, in.CustomerID, now, in.OrganizationID)
 					if e2 != nil {
 						return e2
 					}
@@ -1820,7 +1929,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 					return e
 				}
 			}
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_cust")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_cust")
 		}
 	}
 
@@ -1840,13 +1949,13 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 				VALUES (%s, %s, %s, %s, %s, %s, %s)
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
 
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_ins_it")
-			_, e := database.TxExec(tx, insertWithCreatedBy, id, in.OrderID, day, it.CityID, it.Location, in.OrganizationID, now, in.UpdatedBy)
+			_, _ = tx.Exec("SAVEPOINT sp_ins_it")
+			_, e := tx.Exec(insertWithCreatedBy, id, in.OrderID, day, it.CityID, it.Location, in.OrganizationID, now, in.UpdatedBy)
 			if e != nil {
 				msg := strings.ToLower(e.Error())
 				if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_it")
-					_, e2 := database.TxExec(tx, insertWithoutCreatedBy, id, in.OrderID, day, it.CityID, it.Location, in.OrganizationID, now)
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_it")
+					_, e2 := tx.Exec(insertWithoutCreatedBy, id, in.OrderID, day, it.CityID, it.Location, in.OrganizationID, now)
 					if e2 != nil {
 						return e2
 					}
@@ -1854,7 +1963,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 					return e
 				}
 			}
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_it")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_it")
 			continue
 		}
 
@@ -1881,17 +1990,17 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 		}
 		var itUpdated bool
 		for _, c := range updateItCandidates {
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_it")
-			res, e := database.TxExec(tx, c.query, c.args...)
+			_, _ = tx.Exec("SAVEPOINT sp_upd_it")
+			res, e := tx.Exec(c.query, c.args...)
 			if e == nil {
-				_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_it")
+				_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_it")
 				aff, _ := res.RowsAffected()
 				if aff > 0 {
 					itUpdated = true
 				}
 				break
 			}
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_it")
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_it")
 			msg := strings.ToLower(e.Error())
 			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 				continue
@@ -1930,23 +2039,23 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 			`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 				r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
 
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_ins_item")
-			_, e := database.TxExec(tx, insertWithAll, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.AddonAmount, f.Discount, f.SubTotal, now, in.UpdatedBy)
+			_, _ = tx.Exec("SAVEPOINT sp_ins_item")
+			_, e := tx.Exec(insertWithAll, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.AddonAmount, f.Discount, f.SubTotal, now, in.UpdatedBy)
 			if e != nil {
 				msg := strings.ToLower(e.Error())
 				if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
-					_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_item")
-					_, e2 := database.TxExec(tx, insertWithoutCreatedBy, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.AddonAmount, f.Discount, f.SubTotal, now)
+					_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_item")
+					_, e2 := tx.Exec(insertWithoutCreatedBy, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.AddonAmount, f.Discount, f.SubTotal, now)
 					if e2 != nil {
 						msg2 := strings.ToLower(e2.Error())
 						if strings.Contains(msg2, "unknown column") || strings.Contains(msg2, "does not exist") || strings.Contains(msg2, "column") {
-							_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_item")
-							_, e3 := database.TxExec(tx, insertWithAllNoAddon, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.Discount, f.SubTotal, now, in.UpdatedBy)
+							_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_item")
+							_, e3 := tx.Exec(insertWithAllNoAddon, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.Discount, f.SubTotal, now, in.UpdatedBy)
 							if e3 != nil {
 								msg3 := strings.ToLower(e3.Error())
 								if strings.Contains(msg3, "unknown column") || strings.Contains(msg3, "does not exist") || strings.Contains(msg3, "column") {
-									_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_ins_item")
-									_, e4 := database.TxExec(tx, insertWithoutCreatedByNoAddon, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.Discount, f.SubTotal, now)
+									_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_ins_item")
+									_, e4 := tx.Exec(insertWithoutCreatedByNoAddon, id, in.OrganizationID, in.OrderID, f.FleetID, f.PriceID, q, f.ChargeAmount, f.Discount, f.SubTotal, now)
 									if e4 != nil {
 										return e4
 									}
@@ -1962,16 +2071,21 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 					return e
 				}
 			}
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_ins_item")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_ins_item")
 
-			if err := r.replaceFleetOrderItemAddons(tx, in.OrderID, in.OrganizationID, in.UpdatedBy, id, addonIDsForItem, now, false); err != nil {
+			if err := r.replaceFleetOrderItemAddons(tx, in.OrderID, in.UpdatedBy, id, addonIDsForItem, now, false); err != nil {
 				return err
 			}
+			if f.Repriced {
+				if err := saveOrderItemPriceRules(tx, r.getPlaceholder, in.OrderID, id, f.FleetID, f.PriceID, f.RuleAdjustment, f.PriceRuleLines); err != nil {
+					return err
+				}
+			}
 			continue
 		}
 
 		if len(addonIDsForItem) > 0 {
-			existingAddonSet, err := r.getFleetOrderAddonIDsByItem(tx, in.OrderID, in.OrganizationID, strings.TrimSpace(f.OrderItemID))
+			existingAddonSet, err := r.getFleetOrderAddonIDsByItem(tx, in.OrderID, strings.TrimSpace(f.OrderItemID))
 			if err != nil {
 				return err
 			}
@@ -1979,7 +2093,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 				if _, ok := existingAddonSet[a]; !ok {
 					continue
 				}
-				if err := r.updateFleetOrderAddonPrice(tx, in.OrderID, in.OrganizationID, in.UpdatedBy, a, now); err != nil {
+				if err := r.updateFleetOrderAddonPrice(tx, in.OrderID, in.UpdatedBy, a, now); err != nil {
 					return err
 				}
 			}
@@ -1991,7 +2105,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 				missing = append(missing, a)
 			}
 			if len(missing) > 0 {
-				if err := r.replaceFleetOrderItemAddons(tx, in.OrderID, in.OrganizationID, in.UpdatedBy, strings.TrimSpace(f.OrderItemID), missing, now, false); err != nil {
+				if err := r.replaceFleetOrderItemAddons(tx, in.OrderID, in.UpdatedBy, strings.TrimSpace(f.OrderItemID), missing, now, false); err != nil {
 					return err
 				}
 			}
@@ -2040,24 +2154,28 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 		}
 		var itemUpdated bool
 		for _, c := range updateItemCandidates {
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_item")
-			res, e := database.TxExec(tx, c.query, c.args...)
+			_, _ = tx.Exec("SAVEPOINT sp_upd_item")
+			res, e := tx.Exec(c.query, c.args...)
 			if e == nil {
-				_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_item")
+				_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_item")
 				aff, _ := res.RowsAffected()
 				if aff > 0 {
 					itemUpdated = true
 				}
 				break
 			}
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_item")
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_item")
 			msg := strings.ToLower(e.Error())
 			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 				continue
 			}
 			return e
 		}
-		_ = itemUpdated
+		if itemUpdated && f.Repriced {
+			if err := replaceOrderItemPriceRules(tx, r.getPlaceholder, in.OrderID, f.OrderItemID, f.FleetID, f.PriceID, f.RuleAdjustment, f.PriceRuleLines); err != nil {
+				return err
+			}
+		}
 	}
 
 	sumOrgExpr := "organization_id = " + r.getPlaceholder(2)
@@ -2066,7 +2184,7 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 	}
 	sumQuery := fmt.Sprintf(`SELECT COALESCE(SUM(sub_total), 0), COALESCE(SUM(COALESCE(discount, 0) * COALESCE(quantity, 0)), 0) FROM fleet_order_items WHERE order_id = %s AND %s`, r.getPlaceholder(1), sumOrgExpr)
 	var sumSubTotal, sumDiscount float64
-	sumErr := database.TxQueryRow(tx, sumQuery, in.OrderID, in.OrganizationID).Scan(&sumSubTotal, &sumDiscount)
+	sumErr := tx.QueryRow(sumQuery, in.OrderID, in.OrganizationID).Scan(&sumSubTotal, &sumDiscount)
 	finalTotal := in.TotalAmount
 	finalDiscount := in.DiscountTotal
 	if sumErr == nil {
@@ -2115,13 +2233,13 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 		},
 	}
 	for _, c := range totalUpdateCandidates {
-		_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_totals")
-		_, e := database.TxExec(tx, c.query, c.args...)
+		_, _ = tx.Exec("SAVEPOINT sp_upd_totals")
+		_, e := tx.Exec(c.query, c.args...)
 		if e == nil {
-			_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_totals")
+			_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_totals")
 			break
 		}
-		_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_totals")
+		_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_totals")
 		msg := strings.ToLower(e.Error())
 		if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 			continue
@@ -2155,36 +2273,45 @@ func (r *FleetRepository) UpdatePartnerOrder(in UpdatePartnerOrderInput) (err er
 			},
 		}
 		for _, c := range payUpdateCandidates {
-			_, _ = database.TxExec(tx, "SAVEPOINT sp_upd_payment_orders")
-			_, e := database.TxExec(tx, c.query, c.args...)
+			_, _ = tx.Exec("SAVEPOINT sp_upd_payment_orders")
+			_, e := tx.Exec(c.query, c.args...)
 			if e == nil {
-				_, _ = database.TxExec(tx, "RELEASE SAVEPOINT sp_upd_payment_orders")
+				_, _ = tx.Exec("RELEASE SAVEPOINT sp_upd_payment_orders")
 				break
 			}
-			_, _ = database.TxExec(tx, "ROLLBACK TO SAVEPOINT sp_upd_payment_orders")
+			_, _ = tx.Exec("ROLLBACK TO SAVEPOINT sp_upd_payment_orders")
 			msg := strings.ToLower(e.Error())
 			if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 				continue
 			}
 			return e
 		}
+
+		if err := resizeOrderInstallments(tx, r.getPlaceholder, in.OrderID, finalTotal); err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit()
 }
 
-func (r *FleetRepository) GetFleetOrderSummary(fleetID, priceID string) (*model.OrderFleetSummaryResponse, error) {
+func (r *FleetRepository) GetFleetOrderSummary(ctx context.Context, fleetID, priceID string) (*model.OrderFleetSummaryResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT f.fleet_name, f.capacity, f.engine, f.body, f.description, f.active, f.thumbnail,
 		       fp.duration, fp.rent_type, fp.price, fp.uom
 		FROM fleets f
-		JOIN fleet_prices fp ON fp.fleet_id = f.uuid
-		WHERE f.uuid = %s AND fp.uuid = %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2))
+		JOIN fleet_prices fp ON fp.fleet_id = f.uuid AND fp.organization_id = f.organization_id
+		WHERE f.uuid = %s AND fp.uuid = %s AND f.organization_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	res := &model.OrderFleetSummaryResponse{}
 
-	err := database.QueryRow(r.db, query, fleetID, priceID).Scan(
+	err = t.QueryRow(query, fleetID, priceID, orgID).Scan(
 		&res.FleetName, &res.Capacity, &res.Engine, &res.Body, &res.Description, &res.Active, &res.Thumbnail,
 		&res.Duration, &res.RentType, &res.Price, &res.Uom,
 	)
@@ -2193,8 +2320,8 @@ func (r *FleetRepository) GetFleetOrderSummary(fleetID, priceID string) (*model.
 	}
 
 	// Facilities
-	fQuery := fmt.Sprintf("SELECT f.facility_name as facility FROM fleet_facilities ff INNER JOIN facilities f ON f.facility_id = ff.facility_id WHERE ff.fleet_id = %s", r.getPlaceholder(1))
-	rows, err := database.Query(r.db, fQuery, fleetID)
+	fQuery := fmt.Sprintf("SELECT f.facility_name as facility FROM fleet_facilities ff INNER JOIN facilities f ON f.facility_id = ff.facility_id AND (f.organization_id IS NULL OR f.organization_id = ff.organization_id) WHERE ff.fleet_id = %s AND ff.organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	rows, err := t.Query(fQuery, fleetID, orgID)
 	if err == nil {
 		defer rows.Close()
 		for rows.Next() {
@@ -2206,8 +2333,8 @@ func (r *FleetRepository) GetFleetOrderSummary(fleetID, priceID string) (*model.
 	}
 
 	// Pickup Points
-	pQuery := fmt.Sprintf("SELECT city_id FROM fleet_pickup WHERE fleet_id = %s", r.getPlaceholder(1))
-	pRows, err := database.Query(r.db, pQuery, fleetID)
+	pQuery := fmt.Sprintf("SELECT city_id FROM fleet_pickup WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	pRows, err := t.Query(pQuery, fleetID, orgID)
 	if err == nil {
 		defer pRows.Close()
 		for pRows.Next() {
@@ -2221,14 +2348,19 @@ func (r *FleetRepository) GetFleetOrderSummary(fleetID, priceID string) (*model.
 	return res, nil
 }
 
-func (r *FleetRepository) GetFleetPrices(orgID, fleetID string) ([]model.FleetPriceItem, error) {
+func (r *FleetRepository) GetFleetPrices(ctx context.Context, fleetID string) ([]model.FleetPriceItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT uuid, duration, rent_type, price, disc_amount, disc_price, uom
 		FROM fleet_prices
-		WHERE fleet_id = %s ORDER BY price
-	`, r.getPlaceholder(1))
+		WHERE fleet_id = %s AND organization_id = %s ORDER BY price
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, fleetID)
+	rows, err := t.Query(query, fleetID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -2251,15 +2383,20 @@ func (r *FleetRepository) GetFleetPrices(orgID, fleetID string) ([]model.FleetPr
 	return items, nil
 }
 
-func (r *FleetRepository) GetFleetPriceListByRentType(fleetID string, rentType int) ([]model.FleetPriceListItem, error) {
+func (r *FleetRepository) GetFleetPriceListByRentType(ctx context.Context, fleetID string, rentType int) ([]model.FleetPriceListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT uuid, fleet_id, duration, rent_type, price
 		FROM fleet_prices
-		WHERE fleet_id = %s AND rent_type = %s
+		WHERE fleet_id = %s AND rent_type = %s AND organization_id = %s
 		ORDER BY price
-	`, r.getPlaceholder(1), r.getPlaceholder(2))
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	rows, err := database.Query(r.db, query, fleetID, rentType)
+	rows, err := t.Query(query, fleetID, rentType, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -2278,22 +2415,21 @@ func (r *FleetRepository) GetFleetPriceListByRentType(fleetID string, rentType i
 
 // GetCities returns city IDs
 // Map cities in Service layer
-func (r *FleetRepository) GetFleetPickup(orgID, fleetID string) ([]model.FleetPickupItem, error) {
+func (r *FleetRepository) GetFleetPickup(ctx context.Context, fleetID string) ([]model.FleetPickupItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := `
         SELECT uuid, city_id, '' as city_name 
         FROM fleet_pickup
         WHERE fleet_id = %s
+        AND organization_id = %s
     `
-	args := []interface{}{fleetID}
-	if orgID != "" {
-		query += " AND organization_id = %s"
-		query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
-		args = append(args, orgID)
-	} else {
-		query = fmt.Sprintf(query, r.getPlaceholder(1))
-	}
+	query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, fleetID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -2308,7 +2444,11 @@ func (r *FleetRepository) GetFleetPickup(orgID, fleetID string) ([]model.FleetPi
 	return items, nil
 }
 
-func (r *FleetRepository) GetOrderList(req *model.GetOrderListRequest) ([]model.OrderListItem, int, error) {
+func (r *FleetRepository) GetOrderList(ctx context.Context, req *model.GetOrderListRequest) ([]model.OrderListItem, int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, 0, err
+	}
 	offset := (req.Page - 1) * req.Limit
 	query := fmt.Sprintf(`
 		SELECT fo.order_id, fo.created_at, fo.total_amount, fo.status, fo.payment_status,
@@ -2316,11 +2456,11 @@ func (r *FleetRepository) GetOrderList(req *model.GetOrderListRequest) ([]model.
 		       COALESCE(foc.customer_email, '') as customer_email,
 		       COALESCE(foc.customer_phone, '') as customer_phone
 		FROM fleet_orders fo
-		LEFT JOIN fleet_order_customers foc ON fo.order_id = foc.order_id
+		LEFT JOIN fleet_order_customers foc ON fo.order_id = foc.order_id AND foc.organization_id = fo.organization_id
 		WHERE fo.organization_id = %s
 	`, r.getPlaceholder(1))
 
-	args := []interface{}{req.OrganizationID}
+	args := []interface{}{t.OrganizationID()}
 
 	if req.Status != 0 {
 		query += fmt.Sprintf(" AND fo.status = %s", r.getPlaceholder(len(args)+1))
@@ -2330,7 +2470,7 @@ func (r *FleetRepository) GetOrderList(req *model.GetOrderListRequest) ([]model.
 	query += fmt.Sprintf(" ORDER BY fo.created_at DESC LIMIT %s OFFSET %s", r.getPlaceholder(len(args)+1), r.getPlaceholder(len(args)+2))
 	args = append(args, req.Limit, offset)
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -2348,30 +2488,40 @@ func (r *FleetRepository) GetOrderList(req *model.GetOrderListRequest) ([]model.
 
 	// Count total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM fleet_orders WHERE organization_id = %s", r.getPlaceholder(1))
-	countArgs := []interface{}{req.OrganizationID}
+	countArgs := []interface{}{t.OrganizationID()}
 	if req.Status != 0 {
 		countQuery += fmt.Sprintf(" AND status = %s", r.getPlaceholder(len(countArgs)+1))
 		countArgs = append(countArgs, req.Status)
 	}
 	var total int
-	database.QueryRow(r.db, countQuery, countArgs...).Scan(&total)
+	t.QueryRow(countQuery, countArgs...).Scan(&total)
 
 	return items, total, nil
 }
 
-func (r *FleetRepository) GetOrderDetail(orderID, priceID, organizationID string) (*model.OrderDetailResponse, error) {
+func (r *FleetRepository) GetOrderDetail(ctx context.Context, orderID, priceID string) (*model.OrderDetailResponse, error) {
 	// Reusing FindOrderDetail logic
-	return r.FindOrderDetail(orderID, organizationID)
+	return r.FindOrderDetail(ctx, orderID)
 }
 
-func (r *FleetRepository) GetFleetOrderTotalAmount(orderID, priceID, organizationID string) (float64, error) {
+func (r *FleetRepository) GetFleetOrderTotalAmount(ctx context.Context, orderID, priceID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT total_amount FROM fleet_orders WHERE order_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
 	var amount float64
-	err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&amount)
+	err = t.QueryRow(query, orderID, organizationID).Scan(&amount)
 	return amount, err
 }
 
-func (r *FleetRepository) GetFleetOrderPaymentsByOrderID(orderID, organizationID string) ([]model.FleetOrderPayment, error) {
+func (r *FleetRepository) GetFleetOrderPaymentsByOrderID(ctx context.Context, orderID string) ([]model.FleetOrderPayment, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT order_payment_id, payment_type, payment_percentage, payment_amount, total_amount, payment_remaining, status, created_at
 		FROM fleet_order_payment
@@ -2379,7 +2529,7 @@ func (r *FleetRepository) GetFleetOrderPaymentsByOrderID(orderID, organizationID
 		ORDER BY created_at ASC
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, orderID, organizationID)
+	rows, err := t.Query(query, orderID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -2397,8 +2547,12 @@ func (r *FleetRepository) GetFleetOrderPaymentsByOrderID(orderID, organizationID
 	return items, nil
 }
 
-func (r *FleetRepository) CreateOrderPayment(payment *model.FleetOrderPayment, history *model.OrderPaymentHistory) error {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) CreateOrderPayment(ctx context.Context, payment *model.FleetOrderPayment, history *model.OrderPaymentHistory) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -2436,18 +2590,28 @@ func (r *FleetRepository) CreateOrderPayment(payment *model.FleetOrderPayment, h
 	return tx.Commit()
 }
 
-func (r *FleetRepository) UpdateFleetOrderPaymentStatus(orderID, organizationID string, oldStatus, newStatus int) error {
+func (r *FleetRepository) UpdateFleetOrderPaymentStatus(ctx context.Context, orderID string, oldStatus, newStatus int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		UPDATE fleet_order_payment
 		SET status = %s
 		WHERE order_id = %s AND organization_id = %s AND status = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	_, err := database.Exec(r.db, query, newStatus, orderID, organizationID, oldStatus)
+	_, err = t.Exec(query, newStatus, orderID, organizationID, oldStatus)
 	return err
 }
 
-func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*model.OrderDetailResponse, error) {
+func (r *FleetRepository) FindOrderDetail(ctx context.Context, orderID string) (*model.OrderDetailResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	customerCityExpr := r.customerCityExpr()
 
 	query := fmt.Sprintf(`
@@ -2460,10 +2624,10 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
             COALESCE(c.customer_name, '') as customer_name, COALESCE(c.customer_phone, '') as customer_phone, COALESCE(c.customer_email, '') as customer_email, COALESCE(c.customer_address, '') as customer_address, %[1]s as customer_city,
             COALESCE(fo.additional_request, '') as additional_request
         FROM fleet_orders fo
-        JOIN fleets f ON fo.fleet_id = f.uuid
-        JOIN fleet_prices fp ON fo.price_id = fp.uuid
-        INNER JOIN customer_orders co ON fo.order_id = co.order_id
-		INNER JOIN customers c ON c.customer_id = co.customer_id
+        JOIN fleets f ON fo.fleet_id = f.uuid AND f.organization_id = fo.organization_id
+        JOIN fleet_prices fp ON fo.price_id = fp.uuid AND fp.organization_id = fo.organization_id
+        INNER JOIN customer_orders co ON fo.order_id = co.order_id AND co.organization_id = fo.organization_id
+		INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = fo.organization_id
         WHERE fo.order_id = %[2]s AND fo.organization_id = %[3]s
     `, customerCityExpr, r.getPlaceholder(1), r.getPlaceholder(2))
 
@@ -2473,7 +2637,7 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 	var customerCity sql.NullString
 	var startDate, endDate time.Time
 
-	err := database.QueryRow(r.db, query, orderID, organizationID).Scan(
+	err = t.QueryRow(query, orderID, organizationID).Scan(
 		&res.OrderID, &createdAt, &res.PriceID, &res.Status, &res.PaymentStatus,
 		&res.FleetName,
 		&res.RentType, &res.Price,
@@ -2483,7 +2647,7 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 		&res.AdditionalRequest,
 	)
 	if err != nil {
-		fmt.Println("Error querying order detail:", err)
+		slog.ErrorContext(ctx, "error querying order detail", "error", err)
 		return nil, err
 	}
 	res.Customer.CustomerCity = parseOrderCustomerCity(customerCity)
@@ -2503,7 +2667,7 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 
 	// Destinations
 	destQuery := fmt.Sprintf(`SELECT city_id, location FROM fleet_order_destinations WHERE order_id = %s`, r.getPlaceholder(1))
-	dRows, err := r.db.Query(destQuery, orderID)
+	dRows, err := t.Query(destQuery, orderID)
 	if err == nil {
 		defer dRows.Close()
 		for dRows.Next() {
@@ -2534,7 +2698,7 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 	cityExpr := "city_id::text"
 
 	itQuery := fmt.Sprintf(`SELECT fleet_itinerary_id, day_num, %s as city_id, location FROM fleet_order_itinerary WHERE order_id = %s AND organization_id = %s ORDER BY day_num`, cityExpr, r.getPlaceholder(1), r.getPlaceholder(2))
-	iRows, itErr := r.db.Query(itQuery, orderID, organizationID)
+	iRows, itErr := t.Query(itQuery, orderID, organizationID)
 	if itErr == nil {
 		defer iRows.Close()
 		items := make([]model.FleetOrderItineraryItem, 0)
@@ -2556,10 +2720,10 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 	addonQuery := fmt.Sprintf(`
         SELECT fa.addon_name, fa.addon_desc, fa.addon_price
         FROM fleet_order_addons foa 
-        JOIN fleet_addon fa ON foa.addon_id = fa.uuid 
-        WHERE foa.order_id = %s
-    `, r.getPlaceholder(1))
-	aRows, err := database.Query(r.db, addonQuery, orderID)
+        JOIN fleet_addon fa ON foa.addon_id = fa.uuid AND fa.organization_id = foa.organization_id
+        WHERE foa.order_id = %s AND foa.organization_id = %s
+    `, r.getPlaceholder(1), r.getPlaceholder(2))
+	aRows, err := t.Query(addonQuery, orderID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -2578,13 +2742,13 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 			op.payment_type, 0 as payment_percentage, op.payment_amount, op.total_amount, 
 			op.remaining_amount, op.status, op.created_at, op.payment_id
 		FROM payment_orders op
-		LEFT JOIN organization_bank_accounts ba ON op.bank_account = ba.bank_account_id
+		LEFT JOIN organization_bank_accounts ba ON op.bank_account = ba.bank_account_id AND ba.organization_id = op.organization_id
 		LEFT JOIN bank_lists bl ON ba.bank_code = bl.code
-		WHERE op.order_id = %s AND op.order_type = 1 AND COALESCE(op.status, 0) > 0
+		WHERE op.order_id = %s AND op.organization_id = %s AND op.order_type = 1 AND COALESCE(op.status, 0) > 0
 		ORDER BY op.created_at DESC
-	`, r.getPlaceholder(1))
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	pRows, err := database.Query(r.db, paymentQuery, orderID)
+	pRows, err := t.Query(paymentQuery, orderID, organizationID)
 	if err == nil {
 		defer pRows.Close()
 		var allStatus1 bool = true
@@ -2613,7 +2777,7 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 					allStatus1 = false
 				}
 			} else {
-				fmt.Println("Payment scan error:", err)
+				slog.ErrorContext(ctx, "payment scan error", "error", err)
 			}
 		}
 
@@ -2628,8 +2792,13 @@ func (r *FleetRepository) FindOrderDetail(orderID, organizationID string) (*mode
 	return &res, nil
 }
 
-func (r *FleetRepository) DeleteFleetOrderAddon(orderID, orderItemID, addonID, orgID string) error {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) DeleteFleetOrderAddon(ctx context.Context, orderID, orderItemID, addonID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -2647,13 +2816,9 @@ func (r *FleetRepository) DeleteFleetOrderAddon(orderID, orderItemID, addonID, o
 	// 1. Recalculate addon_amount (excluding the to-be-deleted one)
 	sumAddonQuery := fmt.Sprintf(`SELECT COALESCE(SUM(addon_price), 0) FROM fleet_order_addons WHERE order_id = %s AND order_item_id = %s AND addon_id != %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), orgExpr)
 	var newAddonAmount float64
-	err = database.TxQueryRow(tx, sumAddonQuery, orderID, orderItemID, addonID, orgID).Scan(&newAddonAmount)
+	err = tx.QueryRow(sumAddonQuery, orderID, orderItemID, addonID, orgID).Scan(&newAddonAmount)
 	if err != nil && err != sql.ErrNoRows {
-		sumAddonQueryLegacy := fmt.Sprintf(`SELECT COALESCE(SUM(addon_price), 0) FROM fleet_order_addons WHERE order_id = %s AND order_item_id = %s AND addon_id != %s`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-		err = database.TxQueryRow(tx, sumAddonQueryLegacy, orderID, orderItemID, addonID).Scan(&newAddonAmount)
-		if err != nil && err != sql.ErrNoRows {
-			return err
-		}
+		return err
 	}
 
 	// 2. Update fleet_order_items.addon_amount
@@ -2662,27 +2827,23 @@ func (r *FleetRepository) DeleteFleetOrderAddon(orderID, orderItemID, addonID, o
 		orgExprItem = "organization_id::text = " + r.getPlaceholder(3)
 	}
 	updateItemQuery := fmt.Sprintf(`UPDATE fleet_order_items SET addon_amount = %s WHERE order_item_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), orgExprItem)
-	_, err = database.TxExec(tx, updateItemQuery, newAddonAmount, orderItemID, orgID)
+	_, err = tx.Exec(updateItemQuery, newAddonAmount, orderItemID, orgID)
 	if err != nil {
-		updateItemQueryLegacy := fmt.Sprintf(`UPDATE fleet_order_items SET addon_amount = %s WHERE order_item_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
-		_, err = database.TxExec(tx, updateItemQueryLegacy, newAddonAmount, orderItemID)
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	// 3. Recalculate sub_total
 	var priceID string
 	var quantity, chargeAmount, discount float64
-	fetchItemQuery := fmt.Sprintf(`SELECT price_id, quantity, COALESCE(charge_amount, 0), COALESCE(discount, 0) FROM fleet_order_items WHERE order_item_id = %s`, r.getPlaceholder(1))
-	err = database.TxQueryRow(tx, fetchItemQuery, orderItemID).Scan(&priceID, &quantity, &chargeAmount, &discount)
+	fetchItemQuery := fmt.Sprintf(`SELECT price_id, quantity, COALESCE(charge_amount, 0), COALESCE(discount, 0) FROM fleet_order_items WHERE order_item_id = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
+	err = tx.QueryRow(fetchItemQuery, orderItemID, orgID).Scan(&priceID, &quantity, &chargeAmount, &discount)
 	if err != nil {
 		return err
 	}
 
 	var originalPrice float64
-	priceQuery := fmt.Sprintf(`SELECT price FROM fleet_prices WHERE uuid = %s`, r.getPlaceholder(1))
-	err = database.TxQueryRow(tx, priceQuery, priceID).Scan(&originalPrice)
+	priceQuery := fmt.Sprintf(`SELECT price FROM fleet_prices WHERE uuid = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
+	err = tx.QueryRow(priceQuery, priceID, orgID).Scan(&originalPrice)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
@@ -2692,33 +2853,34 @@ func (r *FleetRepository) DeleteFleetOrderAddon(orderID, orderItemID, addonID, o
 		newSubTotal = 0
 	}
 
-	updateSubTotalQuery := fmt.Sprintf(`UPDATE fleet_order_items SET sub_total = %s WHERE order_item_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
-	_, err = database.TxExec(tx, updateSubTotalQuery, newSubTotal, orderItemID)
+	updateSubTotalQuery := fmt.Sprintf(`UPDATE fleet_order_items SET sub_total = %s WHERE order_item_id = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	_, err = tx.Exec(updateSubTotalQuery, newSubTotal, orderItemID, orgID)
 	if err != nil {
 		return err
 	}
 
 	// 4. Recalculate total_amount in fleet_orders
-	_, err = r.RecalculateFleetOrderTotal(tx, orderID, orgID)
+	_, err = r.RecalculateFleetOrderTotal(tx, orderID)
 	if err != nil {
 		return err
 	}
 
 	// 5. Delete from fleet_order_addons
 	deleteAddonQuery := fmt.Sprintf(`DELETE FROM fleet_order_addons WHERE order_id = %s AND order_item_id = %s AND addon_id = %s AND %s`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), orgExpr)
-	_, err = database.TxExec(tx, deleteAddonQuery, orderID, orderItemID, addonID, orgID)
+	_, err = tx.Exec(deleteAddonQuery, orderID, orderItemID, addonID, orgID)
 	if err != nil {
-		deleteAddonQueryLegacy := fmt.Sprintf(`DELETE FROM fleet_order_addons WHERE order_id = %s AND order_item_id = %s AND addon_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-		_, err = database.TxExec(tx, deleteAddonQueryLegacy, orderID, orderItemID, addonID)
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	return tx.Commit()
 }
 
-func (r *FleetRepository) UpdatePaymentEvidence(orderID, organizationID, filePath string) error {
+func (r *FleetRepository) UpdatePaymentEvidence(ctx context.Context, orderID, filePath string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	// Find latest payment ID
 	subQuery := fmt.Sprintf(`
 		SELECT order_payment_id 
@@ -2731,15 +2893,20 @@ func (r *FleetRepository) UpdatePaymentEvidence(orderID, organizationID, filePat
 	query := fmt.Sprintf(`
 		UPDATE fleet_order_payment
 		SET evidence_file = %s
-		WHERE order_payment_id = (%s)
-	`, r.getPlaceholder(1), subQuery)
+		WHERE order_payment_id = (%s) AND organization_id = %s
+	`, r.getPlaceholder(1), subQuery, r.getPlaceholder(4))
 
-	_, err := r.db.Exec(query, filePath, orderID, organizationID)
+	_, err = t.Exec(query, filePath, orderID, organizationID, organizationID)
 	return err
 }
 
-func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizationID string) (float64, error) {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(ctx context.Context, orderID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return 0, err
 	}
@@ -2754,14 +2921,14 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 
 	lockQuery := fmt.Sprintf("SELECT total_amount FROM fleet_orders WHERE %s AND %s FOR UPDATE", orderExpr, orgExpr)
 	var oldTotal float64
-	if e := database.TxQueryRow(tx, lockQuery, orderID, organizationID).Scan(&oldTotal); e != nil {
+	if e := tx.QueryRow(lockQuery, orderID, organizationID).Scan(&oldTotal); e != nil {
 		err = e
 		return 0, err
 	}
 
 	countQuery := fmt.Sprintf("SELECT COUNT(1) FROM fleet_order_items WHERE %s AND %s AND COALESCE(status, 1) > 0", orderExpr, orgExpr)
 	var itemCount int
-	if e := database.TxQueryRow(tx, countQuery, orderID, organizationID).Scan(&itemCount); e != nil {
+	if e := tx.QueryRow(countQuery, orderID, organizationID).Scan(&itemCount); e != nil {
 		err = e
 		return 0, err
 	}
@@ -2770,7 +2937,7 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 		return 0, err
 	}
 
-	joinExpr := "fp.uuid::text = oi.price_id::text"
+	joinExpr := "fp.uuid::text = oi.price_id::text AND fp.organization_id = oi.organization_id"
 	var sumItems float64
 	useLegacyAddonSum := false
 	itemsQueryNew := fmt.Sprintf(`
@@ -2784,7 +2951,7 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 		LEFT JOIN fleet_prices fp ON %s
 		WHERE %s AND %s AND COALESCE(oi.status, 1) > 0
 	`, joinExpr, "oi."+orderExpr, "oi."+orgExpr)
-	if e := database.TxQueryRow(tx, itemsQueryNew, orderID, organizationID).Scan(&sumItems); e != nil {
+	if e := tx.QueryRow(itemsQueryNew, orderID, organizationID).Scan(&sumItems); e != nil {
 		msg := strings.ToLower(e.Error())
 		if strings.Contains(msg, "unknown column") || strings.Contains(msg, "does not exist") || strings.Contains(msg, "column") {
 			useLegacyAddonSum = true
@@ -2794,7 +2961,7 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 				LEFT JOIN fleet_prices fp ON %s
 				WHERE %s AND %s AND COALESCE(oi.status, 1) > 0
 			`, joinExpr, "oi."+orderExpr, "oi."+orgExpr)
-			if e2 := database.TxQueryRow(tx, itemsQueryOld, orderID, organizationID).Scan(&sumItems); e2 != nil {
+			if e2 := tx.QueryRow(itemsQueryOld, orderID, organizationID).Scan(&sumItems); e2 != nil {
 				err = e2
 				return 0, err
 			}
@@ -2810,7 +2977,7 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 	var sumAddons float64
 	if useLegacyAddonSum {
 		addonsQuery := fmt.Sprintf(`SELECT COALESCE(SUM(COALESCE(addon_price, 0) * COALESCE(addon_qty, 1)), 0) FROM fleet_order_addons WHERE %s AND %s`, orderExpr, orgExpr)
-		_ = database.TxQueryRow(tx, addonsQuery, orderID, organizationID).Scan(&sumAddons)
+		_ = tx.QueryRow(addonsQuery, orderID, organizationID).Scan(&sumAddons)
 		if sumAddons < 0 {
 			sumAddons = 0
 		}
@@ -2830,7 +2997,7 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 		updOrgExpr := "organization_id::text = " + r.getPlaceholder(3)
 
 		updateQuery := fmt.Sprintf("UPDATE fleet_orders SET total_amount = %s WHERE %s AND %s", r.getPlaceholder(1), updOrderExpr, updOrgExpr)
-		if _, e := database.TxExec(tx, updateQuery, total, orderID, organizationID); e != nil {
+		if _, e := tx.Exec(updateQuery, total, orderID, organizationID); e != nil {
 			err = e
 			return 0, err
 		}
@@ -2843,7 +3010,12 @@ func (r *FleetRepository) SyncFleetOrderTotalAmountFromItems(orderID, organizati
 	return total, nil
 }
 
-func (r *FleetRepository) GetOrderTotalAmountByType(orderType int, orderID, organizationID string) (float64, error) {
+func (r *FleetRepository) GetOrderTotalAmountByType(ctx context.Context, orderType int, orderID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	var query string
 	switch orderType {
 	case 1: // fleet
@@ -2863,14 +3035,19 @@ func (r *FleetRepository) GetOrderTotalAmountByType(orderType int, orderID, orga
 	}
 
 	var total float64
-	err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&total)
+	err = t.QueryRow(query, orderID, organizationID).Scan(&total)
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
-func (r *FleetRepository) GetServiceOrderPaymentStats(orderID, organizationID string) (*model.ServiceOrderPaymentStats, error) {
+func (r *FleetRepository) GetServiceOrderPaymentStats(ctx context.Context, orderID string) (*model.ServiceOrderPaymentStats, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id::text = " + r.getPlaceholder(2)
 
 	query := fmt.Sprintf(`
@@ -2882,7 +3059,7 @@ func (r *FleetRepository) GetServiceOrderPaymentStats(orderID, organizationID st
 
 	var totalPaid float64
 	var dpCount int
-	err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&totalPaid, &dpCount)
+	err = t.QueryRow(query, orderID, organizationID).Scan(&totalPaid, &dpCount)
 	if err != nil {
 		return nil, err
 	}
@@ -2892,14 +3069,14 @@ func (r *FleetRepository) GetServiceOrderPaymentStats(orderID, organizationID st
 	}, nil
 }
 
-func (r *FleetRepository) generatePaymentOrderInvoiceNumber(tx *sql.Tx, orderType int, organizationID string, now time.Time) (string, error) {
-	return utils.GenerateInvoiceNumberTx(tx, r.driver, organizationID, orderType, now)
-}
-
-func (r *FleetRepository) InsertServiceOrderPayment(req *model.CreateServiceOrderPaymentRequest, totalAmount, remainingAmount float64) (string, string, error) {
+func (r *FleetRepository) InsertServiceOrderPayment(ctx context.Context, req *model.CreateServiceOrderPaymentRequest, totalAmount, remainingAmount float64) (string, string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", "", err
+	}
 	paymentID := uuid.New().String()
 	now := time.Now()
-	tx, err := r.db.Begin()
+	tx, err := t.Begin()
 	if err != nil {
 		return "", "", err
 	}
@@ -2909,7 +3086,7 @@ func (r *FleetRepository) InsertServiceOrderPayment(req *model.CreateServiceOrde
 		}
 	}()
 
-	invoiceNumber, err := r.generatePaymentOrderInvoiceNumber(tx, req.OrderType, req.OrganizationID, now)
+	invoiceNumber, err := utils.GenerateTenantInvoiceNumber(tx, r.driver, req.OrderType, now)
 	if err != nil {
 		return "", "", err
 	}
@@ -2937,8 +3114,7 @@ func (r *FleetRepository) InsertServiceOrderPayment(req *model.CreateServiceOrde
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10),
 		r.getPlaceholder(11), r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15))
 
-	_, err = database.TxExec(
-		tx,
+	_, err = tx.Exec(
 		query,
 		paymentID,
 		invoiceNumber,
@@ -2992,8 +3168,7 @@ func (r *FleetRepository) InsertServiceOrderPayment(req *model.CreateServiceOrde
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6),
 		r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15))
 
-	_, err = database.TxExec(
-		tx,
+	_, err = tx.Exec(
 		transactionQuery,
 		uuid.New().String(),
 		transactionType,
@@ -3020,7 +3195,12 @@ func (r *FleetRepository) InsertServiceOrderPayment(req *model.CreateServiceOrde
 	return paymentID, invoiceNumber, nil
 }
 
-func (r *FleetRepository) FindFleetOrderIDByPrefix(prefix, organizationID string) (string, error) {
+func (r *FleetRepository) FindFleetOrderIDByPrefix(ctx context.Context, prefix string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	prefix = strings.TrimSpace(prefix)
 	if prefix == "" {
 		return "", sql.ErrNoRows
@@ -3038,7 +3218,7 @@ func (r *FleetRepository) FindFleetOrderIDByPrefix(prefix, organizationID string
 		LIMIT 2
 	`, likeExpr, orgExpr)
 
-	rows, err := database.Query(r.db, query, prefix+"%", organizationID)
+	rows, err := t.Query(query, prefix+"%", organizationID)
 	if err != nil {
 		return "", err
 	}
@@ -3064,7 +3244,12 @@ func (r *FleetRepository) FindFleetOrderIDByPrefix(prefix, organizationID string
 	return ids[0], nil
 }
 
-func (r *FleetRepository) ListFleetOrderPaymentHistory(orderID, organizationID string) ([]model.PaymentOrderHistoryRow, error) {
+func (r *FleetRepository) ListFleetOrderPaymentHistory(ctx context.Context, orderID string) ([]model.PaymentOrderHistoryRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id::text = " + r.getPlaceholder(2)
 
 	query := fmt.Sprintf(`
@@ -3084,7 +3269,7 @@ func (r *FleetRepository) ListFleetOrderPaymentHistory(orderID, organizationID s
 		ORDER BY created_at ASC
 	`, r.getPlaceholder(1), orgExpr)
 
-	rows, err := database.Query(r.db, query, orderID, organizationID)
+	rows, err := t.Query(query, orderID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -3115,7 +3300,12 @@ func (r *FleetRepository) ListFleetOrderPaymentHistory(orderID, organizationID s
 	return out, nil
 }
 
-func (r *FleetRepository) ListPaymentOrders(orderID string, orderType int, organizationID string) ([]model.PaymentOrderRow, error) {
+func (r *FleetRepository) ListPaymentOrders(ctx context.Context, orderID string, orderType int) ([]model.PaymentOrderRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "po.organization_id::text = " + r.getPlaceholder(3)
 	query := fmt.Sprintf(`
 		SELECT
@@ -3141,7 +3331,7 @@ func (r *FleetRepository) ListPaymentOrders(orderID string, orderType int, organ
 		ORDER BY po.created_at DESC
 	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
 
-	rows, err := database.Query(r.db, query, orderID, orderType, organizationID)
+	rows, err := t.Query(query, orderID, orderType, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -3171,14 +3361,14 @@ func (r *FleetRepository) ListPaymentOrders(orderID string, orderType int, organ
 		); err != nil {
 			return nil, err
 		}
-		fmt.Println("bankName:", bankName)
+		slog.DebugContext(ctx, "bank name", "bank_name", bankName)
 		if !bankName.Valid || bankName.String == "" {
 			it.BankName = sql.NullString{}
 		} else {
 			it.BankName.Valid = true
 			it.BankName = bankName
 		}
-		fmt.Println("it:", it)
+		slog.DebugContext(ctx, "payment order", "payment_order", it)
 		out = append(out, it)
 	}
 	if err := rows.Err(); err != nil {
@@ -3187,7 +3377,12 @@ func (r *FleetRepository) ListPaymentOrders(orderID string, orderType int, organ
 	return out, nil
 }
 
-func (r *FleetRepository) GetLatestPaymentOrder(orderID string, orderType int, organizationID string) (*model.PaymentOrderRow, error) {
+func (r *FleetRepository) GetLatestPaymentOrder(ctx context.Context, orderID string, orderType int) (*model.PaymentOrderRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id::text = " + r.getPlaceholder(3)
 	query := fmt.Sprintf(`
 		SELECT
@@ -3213,7 +3408,7 @@ func (r *FleetRepository) GetLatestPaymentOrder(orderID string, orderType int, o
 	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
 
 	var it model.PaymentOrderRow
-	err := database.QueryRow(r.db, query, orderID, orderType, organizationID).Scan(
+	err = t.QueryRow(query, orderID, orderType, organizationID).Scan(
 		&it.PaymentID,
 		&it.OrderType,
 		&it.OrderID,
@@ -3233,11 +3428,16 @@ func (r *FleetRepository) GetLatestPaymentOrder(orderID string, orderType int, o
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("it:", it)
+	slog.DebugContext(ctx, "latest payment order", "payment_order", it)
 	return &it, nil
 }
 
-func (r *FleetRepository) GetFleetOrderItemTotals(orderID, organizationID string) (float64, float64, float64, float64, error) {
+func (r *FleetRepository) GetFleetOrderItemTotals(ctx context.Context, orderID string) (float64, float64, float64, float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "foi.organization_id::text = " + r.getPlaceholder(2)
 
 	query := fmt.Sprintf(`
@@ -3247,29 +3447,34 @@ func (r *FleetRepository) GetFleetOrderItemTotals(orderID, organizationID string
 			COALESCE(SUM(COALESCE(charge_amount, 0)), 0) as total_charge,
 			COALESCE(SUM(COALESCE(payment_amount, 0)), 0) as total_payment
 		FROM fleet_order_items foi
-		LEFT JOIN payment_orders po ON foi.order_id = po.order_id AND po.order_type = 1 AND po.status = 1
+		LEFT JOIN payment_orders po ON foi.order_id = po.order_id AND po.organization_id = foi.organization_id AND po.order_type = 1 AND po.status = 1
 		WHERE foi.order_id = %s AND %s AND po.status > 0
 	`, r.getPlaceholder(1), orgExpr)
 
 	var totalAddon, totalDiscount, totalCharge, totalPayment float64
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&totalAddon, &totalDiscount, &totalCharge, &totalPayment); err != nil {
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&totalAddon, &totalDiscount, &totalCharge, &totalPayment); err != nil {
 		return 0, 0, 0, 0, err
 	}
 	return totalAddon, totalDiscount, totalCharge, totalPayment, nil
 }
 
-func (r *FleetRepository) UpdateFleetOrderPaymentStatusOnOrder(orderID, organizationID string, paymentStatus int) error {
-	orgExpr := "organization_id = " + r.getPlaceholder(2)
+func (r *FleetRepository) UpdateFleetOrderPaymentStatusOnOrder(ctx context.Context, orderID string, paymentStatus int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	orgExpr := "organization_id = " + r.getPlaceholder(3)
 	if r.driver == "postgres" || r.driver == "pgx" {
-		orgExpr = "organization_id::text = " + r.getPlaceholder(2)
+		orgExpr = "organization_id::text = " + r.getPlaceholder(3)
 	}
 	query := fmt.Sprintf(`
 		UPDATE fleet_orders
 		SET payment_status = %s
 		WHERE order_id = %s AND %s AND COALESCE(status, 0) > 0
-	`, r.getPlaceholder(1), r.getPlaceholder(3), orgExpr)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
 
-	res, err := database.Exec(r.db, query, paymentStatus, organizationID, orderID)
+	res, err := t.Exec(query, paymentStatus, orderID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -3280,7 +3485,12 @@ func (r *FleetRepository) UpdateFleetOrderPaymentStatusOnOrder(orderID, organiza
 	return nil
 }
 
-func (r *FleetRepository) ListServiceOrderFleet(orgID, processType string) ([]model.ServiceOrderListItem, error) {
+func (r *FleetRepository) ListServiceOrderFleet(ctx context.Context, processType string) ([]model.ServiceOrderListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(1)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.getPlaceholder(1)
@@ -3305,7 +3515,7 @@ func (r *FleetRepository) ListServiceOrderFleet(orgID, processType string) ([]mo
 	}
 	query += " ORDER BY start_date ASC"
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -3327,7 +3537,9 @@ func (r *FleetRepository) ListServiceOrderFleet(orgID, processType string) ([]mo
 	return out, nil
 }
 
-func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.PartnerOrderListFilter) ([]model.PartnerOrderListItem, error) {
+// partnerOrderListQuery is the order list query, without its ORDER BY, and its
+// arguments
+func (r *FleetRepository) partnerOrderListQuery(orgID string, filter *model.PartnerOrderListFilter) (string, []interface{}) {
 	orgExpr := r.getPlaceholder(1)
 	scheduleIDExpr := "s.schedule_id"
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -3341,7 +3553,7 @@ func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.Partne
 				SELECT c.customer_name
 				FROM customer_orders co
 				INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id
-				WHERE co.order_id = fo.order_id
+				WHERE co.order_id = fo.order_id AND co.organization_id = f.organization_id
 				ORDER BY co.created_at DESC
 				LIMIT 1
 			), '') as customer_name,
@@ -3349,7 +3561,7 @@ func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.Partne
 				SELECT c.customer_phone
 				FROM customer_orders co
 				INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id
-				WHERE co.order_id = fo.order_id
+				WHERE co.order_id = fo.order_id AND co.organization_id = f.organization_id
 				ORDER BY co.created_at DESC
 				LIMIT 1
 			), '') as customer_phone,
@@ -3368,13 +3580,13 @@ func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.Partne
 			COALESCE((
 				SELECT %s
 				FROM schedules s
-				WHERE s.order_id = fo.order_id
+				WHERE s.order_id = fo.order_id AND s.organization_id = f.organization_id
 				ORDER BY s.created_at DESC
 				LIMIT 1
 			), '') as schedule_id
         FROM fleet_orders fo 
-        INNER JOIN fleets f ON fo.fleet_id = f.uuid 
-        INNER JOIN fleet_prices p ON p.uuid = fo.price_id 
+        INNER JOIN fleets f ON fo.fleet_id = f.uuid AND f.organization_id = fo.organization_id 
+        INNER JOIN fleet_prices p ON p.uuid = fo.price_id AND p.organization_id = f.organization_id 
         WHERE f.organization_id = %s
     `, scheduleIDExpr, orgExpr)
 	args := make([]interface{}, 0, 6)
@@ -3409,7 +3621,7 @@ func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.Partne
 			like := "%" + v + "%"
 			pos := len(args) + 1
 			cond += fmt.Sprintf(
-				" AND (fo.order_id %s %s OR f.fleet_name %s %s OR EXISTS (SELECT 1 FROM customer_orders co INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id WHERE co.order_id = fo.order_id AND c.customer_name %s %s))",
+				" AND (fo.order_id %s %s OR f.fleet_name %s %s OR EXISTS (SELECT 1 FROM customer_orders co INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id WHERE co.order_id = fo.order_id AND co.organization_id = f.organization_id AND c.customer_name %s %s))",
 				op, r.getPlaceholder(pos),
 				op, r.getPlaceholder(pos+1),
 				op, r.getPlaceholder(pos+2),
@@ -3417,9 +3629,16 @@ func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.Partne
 			args = append(args, like, like, like)
 		}
 	}
-	query := base + cond + " ORDER BY fo.created_at DESC"
+	return base + cond, args
+}
 
-	rows, err := r.db.Query(query, args...)
+func (r *FleetRepository) GetPartnerOrderList(ctx context.Context, filter *model.PartnerOrderListFilter) ([]model.PartnerOrderListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query, args := r.partnerOrderListQuery(t.OrganizationID(), filter)
+	rows, err := t.Query(query+" ORDER BY fo.created_at DESC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -3427,48 +3646,96 @@ func (r *FleetRepository) GetPartnerOrderList(orgID string, filter *model.Partne
 
 	items := make([]model.PartnerOrderListItem, 0)
 	for rows.Next() {
-		var it model.PartnerOrderListItem
-		var startDate, endDate time.Time
-		var rentType int
-		var latestPaymentType int
-		var scheduleID sql.NullString
-		var orderDate, createdAt time.Time
-		if err := rows.Scan(
-			&it.OrderID, &it.FleetName, &it.Thumbnail, &it.CustomerName, &it.CustomerPhone,
-			&startDate, &endDate, &it.UnitQty, &it.PaymentStatus, &it.Status,
-			&it.Duration, &it.Uom, &it.TotalAmount, &rentType, &orderDate,
-			&latestPaymentType, &scheduleID,
-		); err != nil {
+		it, _, err := scanPartnerOrderListItem(rows)
+		if err != nil {
 			return nil, err
 		}
-		it.StartDate = startDate.Format("2006-01-02")
-		it.EndDate = endDate.Format("2006-01-02")
-		it.LatestPaymentType = latestPaymentType
-		if !scheduleID.Valid || scheduleID.String == "" {
-			it.ScheduleID = ""
-		} else {
-			it.ScheduleID = scheduleID.String
-		}
-		it.OrderDate = orderDate.Format("2006-01-02")
-		it.CreatedAt = createdAt.Format("2006-01-02")
-		switch rentType {
-		case 1:
-			it.RentType = "Cititour"
-		case 2:
-			it.RentType = "Overland"
-		case 3:
-			it.RentType = "Pickup / Drop"
-		default:
-			it.RentType = "Unknown"
-		}
-
 		items = append(items, it)
 	}
 
 	return items, nil
 }
 
-func (r *FleetRepository) GetPartnerOrderSummary(orgID string, filter *model.PartnerOrderListFilter) (*model.PartnerOrderSummary, error) {
+// GetPartnerOrderPage returns up to limit orders of the order list that come
+// after the cursor, newest first, and the cursor of the last one. The zero
+// cursor starts at the newest order.
+func (r *FleetRepository) GetPartnerOrderPage(ctx context.Context, filter *model.PartnerOrderListFilter, after model.PartnerOrderCursor, limit int) ([]model.PartnerOrderListItem, model.PartnerOrderCursor, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, after, err
+	}
+	query, args := r.partnerOrderListQuery(t.OrganizationID(), filter)
+	if !after.CreatedAt.IsZero() {
+		query += fmt.Sprintf(" AND (fo.created_at, fo.order_id) < (%s, %s)", r.getPlaceholder(len(args)+1), r.getPlaceholder(len(args)+2))
+		args = append(args, after.CreatedAt, after.OrderID)
+	}
+	query += fmt.Sprintf(" ORDER BY fo.created_at DESC, fo.order_id DESC LIMIT %s", r.getPlaceholder(len(args)+1))
+	args = append(args, limit)
+
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, after, err
+	}
+	defer rows.Close()
+
+	items := make([]model.PartnerOrderListItem, 0, limit)
+	for rows.Next() {
+		it, createdAt, err := scanPartnerOrderListItem(rows)
+		if err != nil {
+			return nil, after, err
+		}
+		items = append(items, it)
+		after = model.PartnerOrderCursor{CreatedAt: createdAt, OrderID: it.OrderID}
+	}
+	return items, after, rows.Err()
+}
+
+// scanPartnerOrderListItem scans a row of the order list query and returns it
+// with the order's created_at
+func scanPartnerOrderListItem(rows *sql.Rows) (model.PartnerOrderListItem, time.Time, error) {
+	var it model.PartnerOrderListItem
+	var startDate, endDate time.Time
+	var rentType int
+	var latestPaymentType int
+	var scheduleID sql.NullString
+	var orderDate, createdAt time.Time
+	if err := rows.Scan(
+		&it.OrderID, &it.FleetName, &it.Thumbnail, &it.CustomerName, &it.CustomerPhone,
+		&startDate, &endDate, &it.UnitQty, &it.PaymentStatus, &it.Status,
+		&it.Duration, &it.Uom, &it.TotalAmount, &rentType, &orderDate,
+		&latestPaymentType, &scheduleID,
+	); err != nil {
+		return it, time.Time{}, err
+	}
+	it.StartDate = startDate.Format("2006-01-02")
+	it.EndDate = endDate.Format("2006-01-02")
+	it.LatestPaymentType = latestPaymentType
+	if !scheduleID.Valid || scheduleID.String == "" {
+		it.ScheduleID = ""
+	} else {
+		it.ScheduleID = scheduleID.String
+	}
+	it.OrderDate = orderDate.Format("2006-01-02")
+	it.CreatedAt = createdAt.Format("2006-01-02")
+	switch rentType {
+	case 1:
+		it.RentType = "Cititour"
+	case 2:
+		it.RentType = "Overland"
+	case 3:
+		it.RentType = "Pickup / Drop"
+	default:
+		it.RentType = "Unknown"
+	}
+	return it, orderDate, nil
+}
+
+func (r *FleetRepository) GetPartnerOrderSummary(ctx context.Context, filter *model.PartnerOrderListFilter) (*model.PartnerOrderSummary, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	orgExpr := r.getPlaceholder(1)
 	base := fmt.Sprintf(`
         SELECT 
@@ -3479,7 +3746,7 @@ func (r *FleetRepository) GetPartnerOrderSummary(orgID string, filter *model.Par
             COALESCE(SUM(CASE WHEN fo.payment_status IN (1,4) THEN fo.total_amount ELSE 0 END), 0) AS revenue,
             COUNT(DISTINCT CASE WHEN fo.start_date <= CURRENT_DATE AND fo.end_date >= CURRENT_DATE THEN fo.order_id END) AS ongoing
         FROM fleet_orders fo
-        INNER JOIN fleets f ON fo.fleet_id = f.uuid
+        INNER JOIN fleets f ON fo.fleet_id = f.uuid AND f.organization_id = fo.organization_id
         WHERE f.organization_id = %s
     `, orgExpr)
 	args := make([]interface{}, 0, 6)
@@ -3514,7 +3781,7 @@ func (r *FleetRepository) GetPartnerOrderSummary(orgID string, filter *model.Par
 			like := "%" + v + "%"
 			pos := len(args) + 1
 			cond += fmt.Sprintf(
-				" AND (fo.order_id %s %s OR f.fleet_name %s %s OR EXISTS (SELECT 1 FROM customer_orders co INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id WHERE co.order_id = fo.order_id AND c.customer_name %s %s))",
+				" AND (fo.order_id %s %s OR f.fleet_name %s %s OR EXISTS (SELECT 1 FROM customer_orders co INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id WHERE co.order_id = fo.order_id AND co.organization_id = f.organization_id AND c.customer_name %s %s))",
 				op, r.getPlaceholder(pos),
 				op, r.getPlaceholder(pos+1),
 				op, r.getPlaceholder(pos+2),
@@ -3523,7 +3790,7 @@ func (r *FleetRepository) GetPartnerOrderSummary(orgID string, filter *model.Par
 		}
 	}
 	query := base + cond
-	row := r.db.QueryRow(query, args...)
+	row := t.QueryRow(query, args...)
 	var s model.PartnerOrderSummary
 	if err := row.Scan(&s.TotalOrders, &s.Paid, &s.Unpaid, &s.Pending, &s.Revenue, &s.Ongoing); err != nil {
 		return nil, err
@@ -3531,23 +3798,28 @@ func (r *FleetRepository) GetPartnerOrderSummary(orgID string, filter *model.Par
 	return &s, nil
 }
 
-func (r *FleetRepository) GetPartnerOrderFleetItems(organizationId, orderId string) ([]model.OrderDetailFleetItem, error) {
-	priceJoinExpr := "p.uuid::text = oi.price_id::text"
-	fleetJoinExpr := "f.uuid::text = oi.fleet_id::text"
+func (r *FleetRepository) GetPartnerOrderFleetItems(ctx context.Context, orderId string) ([]model.OrderDetailFleetItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationId := t.OrganizationID()
+	priceJoinExpr := "p.uuid::text = oi.price_id::text AND p.organization_id = oi.organization_id"
+	fleetJoinExpr := "f.uuid::text = oi.fleet_id::text AND f.organization_id = oi.organization_id"
 
 	query := fmt.Sprintf(`
 		SELECT oi.order_item_id, oi.order_id, oi.fleet_id, f.fleet_name,
 		tp.label as fleet_type, oi.price_id, p.price, oi.quantity,
 		COALESCE(oi.charge_amount, 0) as charge_amount, COALESCE(oi.discount, 0) as discount, COALESCE(oi.sub_total, 0) as sub_total
 		FROM fleet_order_items oi
-		INNER JOIN fleet_orders o ON oi.order_id = o.order_id
+		INNER JOIN fleet_orders o ON oi.order_id = o.order_id AND o.organization_id = oi.organization_id
 		INNER JOIN fleet_prices p ON %[3]s
 		INNER JOIN fleets f ON %[4]s
 		INNER JOIN fleet_types tp ON tp.id = f.fleet_type
 		WHERE oi.organization_id = %[1]s AND oi.order_id = %[2]s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), priceJoinExpr, fleetJoinExpr)
 
-	rows, err := database.Query(r.db, query, organizationId, orderId)
+	rows, err := t.Query(query, organizationId, orderId)
 	if err != nil {
 		return nil, err
 	}
@@ -3584,8 +3856,8 @@ func (r *FleetRepository) GetPartnerOrderFleetItems(organizationId, orderId stri
 
 	orgExpr := "oi.organization_id::text = " + r.getPlaceholder(1)
 	orderExpr := "oi.order_id::text = " + r.getPlaceholder(2)
-	itemJoinExpr := "oi.order_id::text = foa.order_id::text"
-	addonJoinExpr := "foa.addon_id::text = a.uuid::text"
+	itemJoinExpr := "oi.order_id::text = foa.order_id::text AND foa.organization_id = oi.organization_id"
+	addonJoinExpr := "foa.addon_id::text = a.uuid::text AND a.organization_id = foa.organization_id"
 
 	addonsQuery := fmt.Sprintf(`
 		SELECT foa.order_addon_id, COALESCE(a.addon_name, ''), COALESCE(a.addon_desc, ''), COALESCE(foa.addon_price, 0)
@@ -3595,7 +3867,7 @@ func (r *FleetRepository) GetPartnerOrderFleetItems(organizationId, orderId stri
 		WHERE %s AND %s
 	`, itemJoinExpr, addonJoinExpr, orgExpr, orderExpr)
 
-	aRows, err := database.Query(r.db, addonsQuery, organizationId, orderId)
+	aRows, err := t.Query(addonsQuery, organizationId, orderId)
 	if err != nil {
 		return nil, err
 	}
@@ -3628,12 +3900,17 @@ func (r *FleetRepository) GetPartnerOrderFleetItems(organizationId, orderId stri
 	return items, nil
 }
 
-func (r *FleetRepository) GetPartnerOrderDetail(orderID, orgID string) (*model.OrderDetailResponse, error) {
+func (r *FleetRepository) GetPartnerOrderDetail(ctx context.Context, orderID string) (*model.OrderDetailResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	customerCityExpr := r.customerCityExpr()
 	customerIDExpr := "COALESCE(c.customer_id::text, '')"
 
-	fleetJoinExpr := "fo.fleet_id::text = f.uuid::text"
-	priceJoinExpr := "fo.price_id::text = fp.uuid::text"
+	fleetJoinExpr := "fo.fleet_id::text = f.uuid::text AND f.organization_id = fo.organization_id"
+	priceJoinExpr := "fo.price_id::text = fp.uuid::text AND fp.organization_id = fo.organization_id"
 	customerOrderOrgExpr := "co.organization_id::text = f.organization_id::text"
 	customerJoinExpr := "c.customer_id::text = co.customer_id::text"
 	customerOrgExpr := "c.organization_id::text = f.organization_id::text"
@@ -3669,7 +3946,7 @@ func (r *FleetRepository) GetPartnerOrderDetail(orderID, orgID string) (*model.O
 	var startDate, endDate time.Time
 	var updatedAt sql.NullTime
 
-	err := r.db.QueryRow(query, orderID, orgID).Scan(
+	err = t.QueryRow(query, orderID, orgID).Scan(
 		&res.OrderID, &res.FleetID, &createdAt, &res.PriceID, &res.PaymentStatus, &res.Status,
 		&res.FleetName,
 		&res.RentType, &res.Price,
@@ -3708,7 +3985,7 @@ func (r *FleetRepository) GetPartnerOrderDetail(orderID, orgID string) (*model.O
 
 	// Destinations
 	destQuery := fmt.Sprintf(`SELECT city_id, location FROM fleet_order_destinations WHERE order_id = %s`, r.getPlaceholder(1))
-	dRows, err := r.db.Query(destQuery, orderID)
+	dRows, err := t.Query(destQuery, orderID)
 	if err == nil {
 		defer dRows.Close()
 		for dRows.Next() {
@@ -3739,7 +4016,7 @@ func (r *FleetRepository) GetPartnerOrderDetail(orderID, orgID string) (*model.O
 	cityExpr := "city_id::text"
 
 	itQuery := fmt.Sprintf(`SELECT fleet_itinerary_id, day_num, %s as city_id, location FROM fleet_order_itinerary WHERE order_id = %s AND organization_id = %s ORDER BY day_num`, cityExpr, r.getPlaceholder(1), r.getPlaceholder(2))
-	iRows, itErr := r.db.Query(itQuery, orderID, orgID)
+	iRows, itErr := t.Query(itQuery, orderID, orgID)
 	if itErr == nil {
 		defer iRows.Close()
 		items := make([]model.FleetOrderItineraryItem, 0)
@@ -3761,11 +4038,11 @@ func (r *FleetRepository) GetPartnerOrderDetail(orderID, orgID string) (*model.O
 	addonQuery := fmt.Sprintf(`
         SELECT fa.uuid as addon_id, fa.addon_name, fa.addon_desc, foa.addon_price, foa.order_item_id
         FROM fleet_order_addons foa 
-        JOIN fleet_addon fa ON foa.addon_id = fa.uuid 
-		INNER JOIN fleet_order_items fai ON fai.order_item_id = foa.order_item_id
-        WHERE foa.order_id = %s
-    `, r.getPlaceholder(1))
-	aRows, err := r.db.Query(addonQuery, orderID)
+        JOIN fleet_addon fa ON foa.addon_id = fa.uuid AND fa.organization_id = foa.organization_id 
+		INNER JOIN fleet_order_items fai ON fai.order_item_id = foa.order_item_id AND fai.organization_id = foa.organization_id
+        WHERE foa.order_id = %s AND foa.organization_id = %s
+    `, r.getPlaceholder(1), r.getPlaceholder(2))
+	aRows, err := t.Query(addonQuery, orderID, orgID)
 	if err == nil {
 		defer aRows.Close()
 		for aRows.Next() {
@@ -3778,18 +4055,15 @@ func (r *FleetRepository) GetPartnerOrderDetail(orderID, orgID string) (*model.O
 	return &res, nil
 }
 
-func (r *FleetRepository) GetFleetAddon(orgID, fleetID string) ([]model.FleetAddonItem, error) {
-	query := `SELECT uuid, addon_name, addon_desc, addon_price FROM fleet_addon WHERE fleet_id = %s`
-	args := []interface{}{fleetID}
-	if orgID != "" {
-		query += " AND organization_id = %s"
-		query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
-		args = append(args, orgID)
-	} else {
-		query = fmt.Sprintf(query, r.getPlaceholder(1))
+func (r *FleetRepository) GetFleetAddon(ctx context.Context, fleetID string) ([]model.FleetAddonItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
 	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`SELECT uuid, addon_name, addon_desc, addon_price FROM fleet_addon WHERE fleet_id = %s AND organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, fleetID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -3804,9 +4078,14 @@ func (r *FleetRepository) GetFleetAddon(orgID, fleetID string) ([]model.FleetAdd
 	return items, nil
 }
 
-func (r *FleetRepository) GetFleetImages(fleetID string) ([]model.FleetImageItem, error) {
-	query := fmt.Sprintf("SELECT uuid, path_file FROM fleet_images WHERE fleet_id = %s", r.getPlaceholder(1))
-	rows, err := database.Query(r.db, query, fleetID)
+func (r *FleetRepository) GetFleetImages(ctx context.Context, fleetID string) ([]model.FleetImageItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf("SELECT uuid, path_file FROM fleet_images WHERE fleet_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	rows, err := t.Query(query, fleetID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -3821,7 +4100,9 @@ func (r *FleetRepository) GetFleetImages(fleetID string) ([]model.FleetImageItem
 	return items, nil
 }
 
-func (r *FleetRepository) GetServiceFleets(page, perPage int) ([]model.ServiceFleetItem, error) {
+// GetServiceFleets lists the public fleets of every organization for the
+// service catalogue, so it is not scoped to the organization of the request
+func (r *FleetRepository) GetServiceFleets(ctx context.Context, page, perPage int) ([]model.ServiceFleetItem, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -3844,7 +4125,7 @@ func (r *FleetRepository) GetServiceFleets(page, perPage int) ([]model.ServiceFl
         LIMIT %d OFFSET %d
     `, groupConcat, perPage, offset)
 
-	rows, err := database.Query(r.db, query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -3885,7 +4166,12 @@ func (r *FleetRepository) GetServiceFleets(page, perPage int) ([]model.ServiceFl
 	return items, nil
 }
 
-func (r *FleetRepository) GetAvailableCities(orgID string) ([]int, error) {
+func (r *FleetRepository) GetAvailableCities(ctx context.Context) ([]int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT city_id
 		FROM fleet_pickup
@@ -3893,7 +4179,7 @@ func (r *FleetRepository) GetAvailableCities(orgID string) ([]int, error) {
 		GROUP BY city_id
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -3909,14 +4195,25 @@ func (r *FleetRepository) GetAvailableCities(orgID string) ([]int, error) {
 	return cities, nil
 }
 
-func (r *FleetRepository) GetFleetOrgID(fleetID string) (string, error) {
-	query := fmt.Sprintf("SELECT organization_id FROM fleets WHERE uuid = %s", r.getPlaceholder(1))
+// GetFleetOrgID returns the organization of a fleet the request's organization
+// may see: one of its own or a public fleet of another organization
+func (r *FleetRepository) GetFleetOrgID(ctx context.Context, fleetID string) (string, error) {
+	requestOrgID, ok := database.OrganizationFromContext(ctx)
+	if !ok {
+		return "", database.ErrNoOrganization
+	}
+	query := fmt.Sprintf("SELECT organization_id FROM fleets WHERE uuid = %s AND (organization_id = %s OR is_public = 1)", r.getPlaceholder(1), r.getPlaceholder(2))
 	var orgID string
-	err := database.QueryRow(r.db, query, fleetID).Scan(&orgID)
+	err := database.QueryRowContext(ctx, r.db, query, fleetID, requestOrgID).Scan(&orgID)
 	return orgID, err
 }
 
-func (r *FleetRepository) GetFleetDetailMeta(orgID, fleetID string) (*model.FleetDetailMeta, error) {
+func (r *FleetRepository) GetFleetDetailMeta(ctx context.Context, fleetID string) (*model.FleetDetailMeta, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	fleetTypeExpr := "CAST(f.fleet_type AS CHAR)"
 	createdByExpr := "COALESCE(u.fullname, CAST(f.created_by AS CHAR))"
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -3949,20 +4246,10 @@ func (r *FleetRepository) GetFleetDetailMeta(orgID, fleetID string) (*model.Flee
         FROM fleets f
 		LEFT JOIN fleet_types ft ON f.fleet_type = ft.id
 		LEFT JOIN users u ON u.user_id = f.created_by
-		LEFT JOIN fleet_units fu ON fu.fleet_id::text = f.uuid::text
-        WHERE f.uuid = %s
-    `, fleetTypeExpr, createdByExpr, r.getPlaceholder(1))
-
-	groupBy := ` GROUP BY f.uuid, f.fleet_type, ft.label, f.fleet_name, f.capacity, f.production_year, f.engine, f.body, f.fuel_type, f.transmission, f.description, f.thumbnail, f.active, f.status, f.created_at, u.fullname, f.created_by, f.updated_at, f.updated_by`
-
-	args := []interface{}{fleetID}
-	if orgID != "" {
-		orgExpr := "f.organization_id::text = " + r.getPlaceholder(2)
-		query += " AND " + orgExpr + groupBy
-		args = append(args, orgID)
-	} else {
-		query += groupBy
-	}
+		LEFT JOIN fleet_units fu ON fu.fleet_id::text = f.uuid::text AND fu.organization_id = f.organization_id
+        WHERE f.uuid = %s AND f.organization_id::text = %s
+		GROUP BY f.uuid, f.fleet_type, ft.label, f.fleet_name, f.capacity, f.production_year, f.engine, f.body, f.fuel_type, f.transmission, f.description, f.thumbnail, f.active, f.status, f.created_at, u.fullname, f.created_by, f.updated_at, f.updated_by
+    `, fleetTypeExpr, createdByExpr, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var meta model.FleetDetailMeta
 	// Note: using explicit fields
@@ -3979,7 +4266,7 @@ func (r *FleetRepository) GetFleetDetailMeta(orgID, fleetID string) (*model.Flee
 	var capacities sql.NullString
 	// FleetDetailMeta: CreatedAt string `json:"created_at"`
 
-	err := database.QueryRow(r.db, query, args...).Scan(
+	err = t.QueryRow(query, fleetID, orgID).Scan(
 		&meta.FleetID,
 		&fleetType,
 		&fleetTypeLabel,
@@ -4037,15 +4324,25 @@ func (r *FleetRepository) GetFleetDetailMeta(orgID, fleetID string) (*model.Flee
 	return &meta, nil
 }
 
-func (r *FleetRepository) GetPriceByID(priceID string) (float64, int, error) {
-	query := fmt.Sprintf("SELECT price, rent_type FROM fleet_prices WHERE uuid = %s", r.getPlaceholder(1))
+func (r *FleetRepository) GetPriceByID(ctx context.Context, priceID string) (float64, int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, 0, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf("SELECT price, rent_type FROM fleet_prices WHERE uuid = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
 	var price float64
 	var rentType int
-	err := database.QueryRow(r.db, query, priceID).Scan(&price, &rentType)
+	err = t.QueryRow(query, priceID, orgID).Scan(&price, &rentType)
 	return price, rentType, err
 }
 
-func (r *FleetRepository) GetFleetPricesByIDs(priceIDs []string) (map[string]float64, error) {
+func (r *FleetRepository) GetFleetPricesByIDs(ctx context.Context, priceIDs []string) (map[string]float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	res := make(map[string]float64)
 	if len(priceIDs) == 0 {
 		return res, nil
@@ -4072,8 +4369,8 @@ func (r *FleetRepository) GetFleetPricesByIDs(priceIDs []string) (map[string]flo
 		placeholders[i] = r.getPlaceholder(i + 1)
 		args[i] = id
 	}
-	query := fmt.Sprintf("SELECT uuid, price FROM fleet_prices WHERE uuid IN (%s)", strings.Join(placeholders, ","))
-	rows, err := database.Query(r.db, query, args...)
+	query := fmt.Sprintf("SELECT uuid, price FROM fleet_prices WHERE uuid IN (%s) AND organization_id = %s", strings.Join(placeholders, ","), r.getPlaceholder(len(args)+1))
+	rows, err := t.Query(query, append(args, orgID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -4092,7 +4389,12 @@ func (r *FleetRepository) GetFleetPricesByIDs(priceIDs []string) (map[string]flo
 	return res, nil
 }
 
-func (r *FleetRepository) GetAddonPrices(addonIDs []string) (map[string]float64, error) {
+func (r *FleetRepository) GetAddonPrices(ctx context.Context, addonIDs []string) (map[string]float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	res := make(map[string]float64)
 	if len(addonIDs) == 0 {
 		return res, nil
@@ -4119,8 +4421,8 @@ func (r *FleetRepository) GetAddonPrices(addonIDs []string) (map[string]float64,
 		placeholders[i] = r.getPlaceholder(i + 1)
 		args[i] = id
 	}
-	query := fmt.Sprintf("SELECT uuid, addon_price FROM fleet_addon WHERE uuid IN (%s)", strings.Join(placeholders, ","))
-	rows, err := database.Query(r.db, query, args...)
+	query := fmt.Sprintf("SELECT uuid, addon_price FROM fleet_addon WHERE uuid IN (%s) AND organization_id = %s", strings.Join(placeholders, ","), r.getPlaceholder(len(args)+1))
+	rows, err := t.Query(query, append(args, orgID)...)
 	if err != nil {
 		return nil, err
 	}
@@ -4144,7 +4446,12 @@ func (r *FleetRepository) GetAddonPrices(addonIDs []string) (map[string]float64,
 	return res, nil
 }
 
-func (r *FleetRepository) GetAddonPriceSum(addonIDs []string) (float64, error) {
+func (r *FleetRepository) GetAddonPriceSum(ctx context.Context, addonIDs []string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	if len(addonIDs) == 0 {
 		return 0, nil
 	}
@@ -4155,23 +4462,33 @@ func (r *FleetRepository) GetAddonPriceSum(addonIDs []string) (float64, error) {
 		placeholders[i] = r.getPlaceholder(i + 1)
 		args[i] = id
 	}
-	query := fmt.Sprintf("SELECT COALESCE(SUM(addon_price), 0) FROM fleet_addon WHERE uuid IN (%s)", strings.Join(placeholders, ","))
+	query := fmt.Sprintf("SELECT COALESCE(SUM(addon_price), 0) FROM fleet_addon WHERE uuid IN (%s) AND organization_id = %s", strings.Join(placeholders, ","), r.getPlaceholder(len(args)+1))
 	var total float64
-	err := database.QueryRow(r.db, query, args...).Scan(&total)
+	err = t.QueryRow(query, append(args, orgID)...).Scan(&total)
 	return total, err
 }
 
-func (r *FleetRepository) GetOrderCountByOrgID(orgID string) (int, error) {
+func (r *FleetRepository) GetOrderCountByOrgID(ctx context.Context) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT COUNT(*) FROM fleet_orders WHERE organization_id = %s", r.getPlaceholder(1))
 	var count int
-	err := database.QueryRow(r.db, query, orgID).Scan(&count)
+	err = t.QueryRow(query, orgID).Scan(&count)
 	return count, err
 }
 
-func (r *FleetRepository) GetOrganizationCodeByOrgID(orgID string) (string, error) {
+func (r *FleetRepository) GetOrganizationCodeByOrgID(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT organization_code FROM organizations WHERE organization_id = %s", r.getPlaceholder(1))
 	var code string
-	err := database.QueryRow(r.db, query, orgID).Scan(&code)
+	err = t.QueryRow(query, orgID).Scan(&code)
 	return code, err
 }
 
@@ -4182,10 +4499,12 @@ type FleetAvailibilityItem struct {
 	TotalAvailable int    `json:"total_available"`
 }
 
-func (r *FleetRepository) GetFleetAvailibility(orgID string, reqStart time.Time, reqEnd time.Time, fleetID string) ([]FleetAvailibilityItem, error) {
-	if orgID == "" {
-		return nil, fmt.Errorf("missing organization_id")
+func (r *FleetRepository) GetFleetAvailibility(ctx context.Context, reqStart time.Time, reqEnd time.Time, fleetID string) ([]FleetAvailibilityItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
 	}
+	orgID := t.OrganizationID()
 
 	args := []interface{}{orgID, reqStart, reqEnd}
 	orgExpr := "f.organization_id::text = $1"
@@ -4200,7 +4519,7 @@ func (r *FleetRepository) GetFleetAvailibility(orgID string, reqStart time.Time,
 	conflictExpr := "$2::date <= fo.end_date::date AND $3::date >= fo.start_date::date"
 
 	fleetJoinExpr := "b.fleet_id::text = f.uuid::text"
-	totalUnitExpr := "COALESCE((SELECT COUNT(*) FROM fleet_units fu WHERE fu.fleet_id::text = f.uuid::text AND fu.status = 1), 0)"
+	totalUnitExpr := "COALESCE((SELECT COUNT(*) FROM fleet_units fu WHERE fu.fleet_id::text = f.uuid::text AND fu.organization_id = f.organization_id AND fu.status = 1), 0)"
 
 	greatestFn := "GREATEST"
 
@@ -4216,8 +4535,8 @@ func (r *FleetRepository) GetFleetAvailibility(orgID string, reqStart time.Time,
 				sf.fleet_id,
 				COUNT(DISTINCT sf.unit_id) AS booked_unit
 			FROM schedule_fleets sf
-			INNER JOIN schedules s ON s.schedule_id = sf.schedule_id
-			INNER JOIN fleet_orders fo ON fo.order_id = s.order_id
+			INNER JOIN schedules s ON s.schedule_id = sf.schedule_id AND s.organization_id = sf.organization_id
+			INNER JOIN fleet_orders fo ON fo.order_id = s.order_id AND fo.organization_id = sf.organization_id
 			WHERE %s
 			  AND sf.status = 1
 			  AND s.order_type = 1
@@ -4232,7 +4551,7 @@ func (r *FleetRepository) GetFleetAvailibility(orgID string, reqStart time.Time,
 		ORDER BY f.fleet_name ASC
 	`, totalUnitExpr, greatestFn, totalUnitExpr, bookOrgExpr, conflictExpr, fleetJoinExpr, orgExpr, fleetFilterExpr, totalUnitExpr)
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -4263,7 +4582,12 @@ func (r *FleetRepository) getPlaceholder(pos int) string {
 	return "?"
 }
 
-func (r *FleetRepository) ListFleetsForUnit(orgID, searchFor string) ([]model.FleetUnitSearchItem, error) {
+func (r *FleetRepository) ListFleetsForUnit(ctx context.Context, searchFor string) ([]model.FleetUnitSearchItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	var query string
 	args := make([]interface{}, 0, 2)
 	args = append(args, orgID)
@@ -4282,7 +4606,7 @@ func (r *FleetRepository) ListFleetsForUnit(orgID, searchFor string) ([]model.Fl
 		}
 	}
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -4302,12 +4626,17 @@ func (r *FleetRepository) ListFleetsForUnit(orgID, searchFor string) ([]model.Fl
 	return items, nil
 }
 
-func (r *FleetRepository) SetFleetActiveStatus(orgID, userID, fleetID string, active bool) error {
+func (r *FleetRepository) SetFleetActiveStatus(ctx context.Context, userID, fleetID string, active bool) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := setFleetActiveMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = setFleetActivePostgres
 	}
-	res, err := database.Exec(r.db, query, active, time.Now(), userID, fleetID, orgID)
+	res, err := t.Exec(query, active, time.Now(), userID, fleetID, orgID)
 	if err != nil {
 		return err
 	}
@@ -4318,12 +4647,17 @@ func (r *FleetRepository) SetFleetActiveStatus(orgID, userID, fleetID string, ac
 	return nil
 }
 
-func (r *FleetRepository) SoftDeleteFleet(orgID, userID, fleetID string) error {
+func (r *FleetRepository) SoftDeleteFleet(ctx context.Context, userID, fleetID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := softDeleteFleetMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = softDeleteFleetPostgres
 	}
-	res, err := database.Exec(r.db, query, time.Now(), userID, fleetID, orgID)
+	res, err := t.Exec(query, time.Now(), userID, fleetID, orgID)
 	if err != nil {
 		return err
 	}
@@ -4334,12 +4668,17 @@ func (r *FleetRepository) SoftDeleteFleet(orgID, userID, fleetID string) error {
 	return nil
 }
 
-func (r *FleetRepository) GetScheduleByOrderID(orderID string) (*model.ModuleScheduleInfo, error) {
-	query := fmt.Sprintf("SELECT schedule_id, order_id, departure_time, arrival_time, status, created_at, created_by FROM schedules WHERE order_type = 1 AND order_id = %s AND status = 1 LIMIT 1", r.getPlaceholder(1))
+func (r *FleetRepository) GetScheduleByOrderID(ctx context.Context, orderID string) (*model.ModuleScheduleInfo, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf("SELECT schedule_id, order_id, departure_time, arrival_time, status, created_at, created_by FROM schedules WHERE order_type = 1 AND order_id = %s AND organization_id = %s AND status = 1 LIMIT 1", r.getPlaceholder(1), r.getPlaceholder(2))
 	var schedule model.ModuleScheduleInfo
 	var DepartureTime sql.NullTime
 	var ArrivalTime sql.NullTime
-	if err := database.QueryRow(r.db, query, orderID).Scan(&schedule.ScheduleID, &schedule.OrderID, &DepartureTime, &ArrivalTime, &schedule.Status, &schedule.CreatedAt, &schedule.CreatedBy); err != nil {
+	if err := t.QueryRow(query, orderID, orgID).Scan(&schedule.ScheduleID, &schedule.OrderID, &DepartureTime, &ArrivalTime, &schedule.Status, &schedule.CreatedAt, &schedule.CreatedBy); err != nil {
 		return nil, err
 	}
 	if DepartureTime.Valid {
@@ -4358,7 +4697,12 @@ type OrderAvailabilityRepoResult struct {
 	Prices       []model.OrderAvailabilityPriceItem
 }
 
-func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int, startDate time.Time, endDate *time.Time, daysCount int, serviceType *int) (*OrderAvailabilityRepoResult, error) {
+func (r *FleetRepository) GetOrderAvailability(ctx context.Context, fleetID string, cityID int, startDate time.Time, endDate *time.Time, daysCount int, serviceType *int) (*OrderAvailabilityRepoResult, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	result := &OrderAvailabilityRepoResult{
 		Available: true,
 	}
@@ -4373,7 +4717,7 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 			WHERE city_id = %s AND organization_id = %s
 		`, r.getPlaceholder(1), r.getPlaceholder(2))
 		serviceTypesArgs := []interface{}{cityID, orgID}
-		serviceTypesRows, err := database.Query(r.db, serviceTypesQuery, serviceTypesArgs...)
+		serviceTypesRows, err := t.Query(serviceTypesQuery, serviceTypesArgs...)
 		if err != nil {
 			return nil, err
 		}
@@ -4395,7 +4739,7 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 	minDayArgs := []interface{}{cityID, orgID}
 	var minDay sql.NullInt64
-	_ = database.QueryRow(r.db, minDayQuery, minDayArgs...).Scan(&minDay)
+	_ = t.QueryRow(minDayQuery, minDayArgs...).Scan(&minDay)
 	if minDay.Valid {
 		result.MinimalDay = int(minDay.Int64)
 	}
@@ -4406,7 +4750,7 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 		nextScheduleQuery := fmt.Sprintf(`
 			SELECT s.departure_time
 			FROM schedules s
-			INNER JOIN schedule_fleets sf ON s.schedule_id = sf.schedule_id
+			INNER JOIN schedule_fleets sf ON s.schedule_id = sf.schedule_id AND sf.organization_id = s.organization_id
 			WHERE sf.fleet_id::text = %s
 			  AND s.organization_id::text = %s
 			  AND s.departure_time > %s
@@ -4416,7 +4760,7 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 		nextScheduleArgs := []interface{}{fleetID, orgID, startDate}
 		var nextDeparture sql.NullTime
-		_ = database.QueryRow(r.db, nextScheduleQuery, nextScheduleArgs...).Scan(&nextDeparture)
+		_ = t.QueryRow(nextScheduleQuery, nextScheduleArgs...).Scan(&nextDeparture)
 		if nextDeparture.Valid {
 			// Availability is until day before next departure
 			availableDays := int(nextDeparture.Time.Sub(startDate).Hours() / 24)
@@ -4426,7 +4770,7 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 		}
 	} else {
 		// Use existing availability check
-		availItems, err := r.GetFleetAvailibility(orgID, startDate, *endDate, fleetID)
+		availItems, err := r.GetFleetAvailibility(ctx, startDate, *endDate, fleetID)
 		if err != nil {
 			return nil, err
 		}
@@ -4454,9 +4798,10 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 			FROM fleet_prices
 			WHERE fleet_id::text = %s
 			  AND rent_type IN (%s)
-		`, r.getPlaceholder(1), strings.Join(placeholders, ","))
+			  AND organization_id = %s
+		`, r.getPlaceholder(1), strings.Join(placeholders, ","), r.getPlaceholder(len(args)+1))
 
-		rows, err := database.Query(r.db, priceQuery, args...)
+		rows, err := t.Query(priceQuery, append(args, orgID)...)
 		if err != nil {
 			return nil, err
 		}
@@ -4486,9 +4831,14 @@ func (r *FleetRepository) GetOrderAvailability(orgID, fleetID string, cityID int
 	return result, nil
 }
 
-func (r *FleetRepository) ProcessFleetOrder(orgID, userID, orderID string, processTypeId int) error {
+func (r *FleetRepository) ProcessFleetOrder(ctx context.Context, userID, orderID string, processTypeId int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := "UPDATE fleet_orders SET status = $1, updated_at = $2, updated_by = $3 WHERE order_id = $4 AND organization_id = $5"
-	res, err := database.Exec(r.db, query, processTypeId, time.Now(), userID, orderID, orgID)
+	res, err := t.Exec(query, processTypeId, time.Now(), userID, orderID, orgID)
 	if err != nil {
 		return err
 	}
@@ -4499,7 +4849,12 @@ func (r *FleetRepository) ProcessFleetOrder(orgID, userID, orderID string, proce
 	return nil
 }
 
-func (r *FleetRepository) GetCustomerOrderMeta(orderID, organizationID string) (string, int, error) {
+func (r *FleetRepository) GetCustomerOrderMeta(ctx context.Context, orderID string) (string, int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", 0, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT customer_id, order_type
 		FROM customer_orders
@@ -4509,13 +4864,18 @@ func (r *FleetRepository) GetCustomerOrderMeta(orderID, organizationID string) (
 
 	var customerID string
 	var orderType int
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&customerID, &orderType); err != nil {
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&customerID, &orderType); err != nil {
 		return "", 0, err
 	}
 	return customerID, orderType, nil
 }
 
-func (r *FleetRepository) InsertOrderReview(reviewID, orderID string, star int, review string, organizationID string, customerID string, orderType int, createdAt time.Time) error {
+func (r *FleetRepository) InsertOrderReview(ctx context.Context, reviewID, orderID string, star int, review string, customerID string, orderType int, createdAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		INSERT INTO order_reviews (
 			review_id, star, review, organization_id, customer_id, order_type, order_id, created_at
@@ -4524,22 +4884,27 @@ func (r *FleetRepository) InsertOrderReview(reviewID, orderID string, star int,
 		)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
 
-	_, err := database.Exec(r.db, query, reviewID, star, review, organizationID, customerID, orderType, orderID, createdAt)
+	_, err = t.Exec(query, reviewID, star, review, organizationID, customerID, orderType, orderID, createdAt)
 	return err
 }
 
-func (r *FleetRepository) GetOrderReviews(orderID, organizationID string) ([]model.OrderReviewItem, error) {
+func (r *FleetRepository) GetOrderReviews(ctx context.Context, orderID string) ([]model.OrderReviewItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT r.star, r.review, c.customer_name, r.created_at
 		FROM order_reviews r
-		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
-		INNER JOIN customers c ON c.customer_id = r.customer_id
+		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
+		INNER JOIN customers c ON c.customer_id = r.customer_id AND c.organization_id = r.organization_id
 		WHERE fo.order_id = %s AND r.organization_id = %s
 		ORDER BY r.created_at DESC
 		LIMIT 20
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, orderID, organizationID)
+	rows, err := t.Query(query, orderID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -4561,17 +4926,22 @@ func (r *FleetRepository) GetOrderReviews(orderID, organizationID string) ([]mod
 	return out, nil
 }
 
-func (r *FleetRepository) GetOrderRatingSummary(orderID, organizationID string) (*model.OrderRatingSummary, error) {
+func (r *FleetRepository) GetOrderRatingSummary(ctx context.Context, orderID string) (*model.OrderRatingSummary, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT ROUND(AVG(r.star),1) AS rating, COUNT(r.review_id) AS total_ulasan
 		FROM order_reviews r
-		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
+		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
 		WHERE fo.order_id = %s AND r.organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var ratingAny interface{}
 	var total int64
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&ratingAny, &total); err != nil {
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&ratingAny, &total); err != nil {
 		return nil, err
 	}
 
@@ -4599,7 +4969,12 @@ func (r *FleetRepository) GetOrderRatingSummary(orderID, organizationID string)
 	}, nil
 }
 
-func (r *FleetRepository) GetFleetRatings(organizationID string, fleetIDs []string) (map[string]model.FleetRatingSummary, error) {
+func (r *FleetRepository) GetFleetRatings(ctx context.Context, fleetIDs []string) (map[string]model.FleetRatingSummary, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	if len(fleetIDs) == 0 {
 		return map[string]model.FleetRatingSummary{}, nil
 	}
@@ -4618,12 +4993,12 @@ func (r *FleetRepository) GetFleetRatings(organizationID string, fleetIDs []stri
 			COUNT(r.review_id) AS total_ulasan,
 			fo.fleet_id
 		FROM order_reviews r
-		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
+		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
 		WHERE fo.fleet_id IN (%s) AND fo.organization_id = %s
 		GROUP BY fo.fleet_id
 	`, strings.Join(placeholders, ","), r.getPlaceholder(len(fleetIDs)+1))
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -4668,16 +5043,21 @@ func (r *FleetRepository) GetFleetRatings(organizationID string, fleetIDs []stri
 	return out, nil
 }
 
-func (r *FleetRepository) GetFleetReviews(fleetID, organizationID string) ([]model.OrderReviewItem, error) {
+func (r *FleetRepository) GetFleetReviews(ctx context.Context, fleetID string) ([]model.OrderReviewItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT r.star, r.review, r.order_id, c.customer_name, r.created_at
 		FROM order_reviews r
-		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
-		INNER JOIN customers c ON c.customer_id = r.customer_id
+		INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
+		INNER JOIN customers c ON c.customer_id = r.customer_id AND c.organization_id = r.organization_id
 		WHERE fo.fleet_id = %s AND r.organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, fleetID, organizationID)
+	rows, err := t.Query(query, fleetID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -4699,7 +5079,12 @@ func (r *FleetRepository) GetFleetReviews(fleetID, organizationID string) ([]mod
 	return out, nil
 }
 
-func (r *FleetRepository) GetFleetRevenue(orgID, fleetID, startDate, endDate string) (*model.FleetRevenue, error) {
+func (r *FleetRepository) GetFleetRevenue(ctx context.Context, fleetID, startDate, endDate string) (*model.FleetRevenue, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	startAt, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(startDate), time.Local)
 	if err != nil {
 		return nil, err
@@ -4715,7 +5100,7 @@ func (r *FleetRepository) GetFleetRevenue(orgID, fleetID, startDate, endDate str
 			COALESCE(SUM(po.payment_amount), 0) AS revenue,
 			COALESCE(COUNT(DISTINCT fo.order_id), 0) AS total_booking
 		FROM fleet_orders fo
-		INNER JOIN payment_orders po ON po.order_id = fo.order_id
+		INNER JOIN payment_orders po ON po.order_id = fo.order_id AND po.organization_id = fo.organization_id
 		WHERE fo.fleet_id = %s
 		  AND fo.organization_id = %s
 		  AND fo.status = 1
@@ -4724,7 +5109,7 @@ func (r *FleetRepository) GetFleetRevenue(orgID, fleetID, startDate, endDate str
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 	var revenueAny interface{}
 	var totalBooking int64
-	if err := database.QueryRow(r.db, query, fleetID, orgID, startAt, endExclusive).Scan(&revenueAny, &totalBooking); err != nil {
+	if err := t.QueryRow(query, fleetID, orgID, startAt, endExclusive).Scan(&revenueAny, &totalBooking); err != nil {
 		if err == sql.ErrNoRows {
 			return &model.FleetRevenue{
 				StartDate:    startDate,
@@ -4784,17 +5169,22 @@ func (r *FleetRepository) GetFleetRevenue(orgID, fleetID, startDate, endDate str
 	}, nil
 }
 
-func (r *FleetRepository) GetPaidAmount(orderID, orgID string) (float64, error) {
+func (r *FleetRepository) GetPaidAmount(ctx context.Context, orderID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT COALESCE(SUM(t.amount), 0) AS paid_amount
 		FROM transactions t
-		INNER JOIN fleet_orders fo ON fo.order_id = t.reference_id
+		INNER JOIN fleet_orders fo ON fo.order_id = t.reference_id AND fo.organization_id = t.organization_id
 		WHERE fo.organization_id = %s
 		  AND fo.status = 1
 		  AND t.reference_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 	var paidAmount float64
-	if err := database.QueryRow(r.db, query, orgID, orderID).Scan(&paidAmount); err != nil {
+	if err := t.QueryRow(query, orgID, orderID).Scan(&paidAmount); err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
 		}
@@ -4803,21 +5193,70 @@ func (r *FleetRepository) GetPaidAmount(orderID, orgID string) (float64, error)
 	return paidAmount, nil
 }
 
-func (r *FleetRepository) FleetOrderCancelation(userID, orderID, orgID string) error {
+// GetOrderAddonAmount returns what the addons of an order cost, each addon
+// price times its quantity
+func (r *FleetRepository) GetOrderAddonAmount(ctx context.Context, orderID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		SELECT COALESCE(SUM(COALESCE(foa.addon_price, 0) * COALESCE(foa.addon_qty, 1)), 0) AS addon_amount
+		FROM fleet_order_addons foa
+		INNER JOIN fleet_orders fo ON fo.order_id = foa.order_id AND fo.organization_id = foa.organization_id
+		WHERE fo.organization_id = %s
+		  AND foa.order_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
+	var addonAmount float64
+	if err := t.QueryRow(query, orgID, orderID).Scan(&addonAmount); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return addonAmount, nil
+}
+
+func (r *FleetRepository) FleetOrderCancelation(ctx context.Context, userID, orderID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		UPDATE fleet_orders
 		SET status = 0, updated_at = now(), updated_by = %s
 		WHERE order_id = %s
 		  AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	if _, err := database.Exec(r.db, query, userID, orderID, orgID); err != nil {
+	if _, err := t.Exec(query, userID, orderID, orgID); err != nil {
+		return err
+	}
+
+	// Open installments of a cancelled order are no longer collected
+	installmentQuery := fmt.Sprintf(`
+		UPDATE order_installments
+		SET status = 0, updated_at = now()
+		WHERE order_id = %s
+		  AND organization_id = %s
+		  AND status IN (1, 2)
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
+	if _, err := t.Exec(installmentQuery, orderID, orgID); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *FleetRepository) RefundOrderTransactions(orderID string, refundAmount float64, reason string, paymentMethod string, bankCode string, bankAccount string, bankAccountName string, orgID string, userID string) error {
-	tx, err := r.db.Begin()
+// RefundOrderTransactions books the refund computed by the cancellation policy
+// and records the policy version it was computed with.
+func (r *FleetRepository) RefundOrderTransactions(ctx context.Context, orderID string, quote *model.CancellationRefundQuote, reason string, paymentMethod string, bankCode string, bankAccount string, bankAccountName string, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -4828,7 +5267,7 @@ func (r *FleetRepository) RefundOrderTransactions(orderID string, refundAmount f
 		return err
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumberTx(tx, r.driver, orgID, 1, time.Now())
+	invoiceNumber, err := utils.GenerateTenantInvoiceNumber(tx, r.driver, 1, time.Now())
 	if err != nil {
 		return err
 	}
@@ -4854,12 +5293,12 @@ func (r *FleetRepository) RefundOrderTransactions(orderID string, refundAmount f
 	)
 	VALUES (%s, 2, 1, 'TRX01', 'TRX-I14', %s, %s, %s, 1004, %s, %s, %s, %s, %s, %s, 1)`,
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
-	_, err = database.TxExec(tx, query,
+	_, err = tx.Exec(query,
 		transactionID.String(),
 		invoiceNumber,
 		"Refund - Order ID "+orderID,
 		time.Now(),
-		refundAmount,
+		quote.RefundAmount,
 		orgID,
 		paymentMethod,
 		time.Now(),
@@ -4867,7 +5306,7 @@ func (r *FleetRepository) RefundOrderTransactions(orderID string, refundAmount f
 		orderID,
 	)
 	if err != nil {
-		fmt.Println("INSERT INTO transactions err:", err)
+		slog.ErrorContext(ctx, "insert transaction failed", "error", err)
 		return err
 	}
 
@@ -4888,22 +5327,35 @@ func (r *FleetRepository) RefundOrderTransactions(orderID string, refundAmount f
 		bank_account_name,
 		organization_id,
 		created_at,
-		created_by
+		created_by,
+		policy_id,
+		policy_version,
+		days_before_start,
+		refund_percentage,
+		paid_amount,
+		non_refundable_amount
 	)
-	VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
-		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11))
-	_, err = database.TxExec(tx, refundQuery,
+	VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11),
+		r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15), r.getPlaceholder(16), r.getPlaceholder(17))
+	_, err = tx.Exec(refundQuery,
 		refundID.String(),
 		transactionID.String(),
 		orderID,
 		reason,
-		refundAmount,
+		quote.RefundAmount,
 		bankCode,
 		bankAccount,
 		bankAccountName,
 		orgID,
 		time.Now(),
 		userID,
+		nullableString(quote.PolicyID),
+		quote.PolicyVersion,
+		quote.DaysBeforeStart,
+		quote.RefundPercentage,
+		quote.PaidAmount,
+		quote.NonRefundableAmount,
 	)
 	if err != nil {
 		return err
@@ -4912,8 +5364,13 @@ func (r *FleetRepository) RefundOrderTransactions(orderID string, refundAmount f
 	return tx.Commit()
 }
 
-func (r *FleetRepository) CancelSchedulesAndRelated(userID, orderID, orgID string) error {
-	tx, err := r.db.Begin()
+func (r *FleetRepository) CancelSchedulesAndRelated(ctx context.Context, userID, orderID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -4928,7 +5385,7 @@ func (r *FleetRepository) CancelSchedulesAndRelated(userID, orderID, orgID strin
 		WHERE order_id = %s AND %s
 	`, r.getPlaceholder(1), orgExpr)
 
-	rows, err := database.TxQuery(tx, getScheduleQuery, orderID, orgID)
+	rows, err := tx.Query(getScheduleQuery, orderID, orgID)
 	if err != nil {
 		return err
 	}
@@ -4946,12 +5403,13 @@ func (r *FleetRepository) CancelSchedulesAndRelated(userID, orderID, orgID strin
 	}
 
 	// Update schedules table
+	updateOrgExpr := "organization_id::text = " + r.getPlaceholder(3)
 	updateSchedulesQuery := fmt.Sprintf(`
 		UPDATE schedules
 		SET status = 0, updated_at = now(), updated_by = %s
 		WHERE order_id = %s AND %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
-	_, err = database.TxExec(tx, updateSchedulesQuery, userID, orderID)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), updateOrgExpr)
+	_, err = tx.Exec(updateSchedulesQuery, userID, orderID, orgID)
 	if err != nil {
 		return err
 	}
@@ -4961,8 +5419,8 @@ func (r *FleetRepository) CancelSchedulesAndRelated(userID, orderID, orgID strin
 		UPDATE schedule_fleets
 		SET status = 0, updated_at = now(), updated_by = %s
 		WHERE order_id = %s AND %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
-	_, err = database.TxExec(tx, updateScheduleFleetsQuery, userID, orderID)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), updateOrgExpr)
+	_, err = tx.Exec(updateScheduleFleetsQuery, userID, orderID, orgID)
 	if err != nil {
 		return err
 	}
@@ -4973,9 +5431,9 @@ func (r *FleetRepository) CancelSchedulesAndRelated(userID, orderID, orgID strin
 			updateScheduleTeamsQuery := fmt.Sprintf(`
 				UPDATE schedule_fleet_teams
 				SET status = 0, updated_at = now(), updated_by = %s
-				WHERE schedule_id = %s
-			`, r.getPlaceholder(1), r.getPlaceholder(2))
-			_, err = database.TxExec(tx, updateScheduleTeamsQuery, userID, sid)
+				WHERE schedule_id = %s AND %s
+			`, r.getPlaceholder(1), r.getPlaceholder(2), updateOrgExpr)
+			_, err = tx.Exec(updateScheduleTeamsQuery, userID, sid, orgID)
 			if err != nil {
 				return err
 			}
@@ -4985,7 +5443,12 @@ func (r *FleetRepository) CancelSchedulesAndRelated(userID, orderID, orgID strin
 	return tx.Commit()
 }
 
-func (r *FleetRepository) CreateCustomer(customerName, customerPhone, customerCompany, orgID string) (string, error) {
+func (r *FleetRepository) CreateCustomer(ctx context.Context, customerName, customerPhone, customerCompany string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
 	customerID := uuid.New().String()
 	query := fmt.Sprintf(`
 		INSERT INTO customers
@@ -4994,18 +5457,23 @@ func (r *FleetRepository) CreateCustomer(customerName, customerPhone, customerCo
 			(%s, %s, %s, %s, %s, %s)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	_, err := database.Exec(r.db, query, customerID, orgID, customerName, customerPhone, customerCompany, time.Now())
+	_, err = t.Exec(query, customerID, orgID, customerName, customerPhone, customerCompany, time.Now())
 	if err != nil {
 		return "", err
 	}
 	return customerID, nil
 }
 
-func (r *FleetRepository) GetRefundOrderDetail(orderID string, orgID string) (*model.FleetOrderCancelRequest, error) {
+func (r *FleetRepository) GetRefundOrderDetail(ctx context.Context, orderID string) (*model.FleetOrderCancelRequest, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		WHERE order_id = %s AND %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
-	rows, err := database.Query(r.db, query, orderID, orgID)
+	rows, err := t.Query(query, orderID, orgID)
 	if err != nil {
 		return nil, err
 	}
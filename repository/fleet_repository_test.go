@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetPartnerOrderPageContinuesAfterTheCursor(t *testing.T) {
+	r := NewFleetRepository(openTenantFake(t), "postgres")
+	ctx := database.WithOrganization(context.Background(), tenantA)
+	filter := &model.PartnerOrderListFilter{OrderDateFrom: "2026-01-01 00:00:00"}
+
+	if _, _, err := r.GetPartnerOrderPage(ctx, filter, model.PartnerOrderCursor{}, 500); err != nil {
+		t.Fatal(err)
+	}
+	first := fakeTenantDB.take()
+	if len(first) != 1 || strings.Contains(first[0].query, "(fo.created_at, fo.order_id) <") {
+		t.Fatalf("the first page must start at the newest order: %+v", first)
+	}
+
+	after := model.PartnerOrderCursor{CreatedAt: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), OrderID: "ORD-9"}
+	if _, _, err := r.GetPartnerOrderPage(ctx, filter, after, 500); err != nil {
+		t.Fatal(err)
+	}
+	next := fakeTenantDB.take()
+	if len(next) != 1 {
+		t.Fatalf("expected one statement, got %d", len(next))
+	}
+	query, args := next[0].query, next[0].args
+	if !strings.Contains(query, "(fo.created_at, fo.order_id) < ($3, $4)") || !strings.Contains(query, "ORDER BY fo.created_at DESC, fo.order_id DESC LIMIT $5") {
+		t.Errorf("the next page does not continue after the cursor: %s", query)
+	}
+	if len(args) != 5 || args[3] != "ORD-9" || args[4] != int64(500) {
+		t.Errorf("unexpected arguments %v", args)
+	}
+}
+
+func TestFleetRepositoryIsTenantScoped(t *testing.T) {
+	r := NewFleetRepository(openTenantFake(t), "postgres")
+	const priceOfB, addonOfB, itemOfOrderB = "price-of-b", "addon-of-b", "order-item-of-b"
+	start, end := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC)
+	checkTenantIsolation(t, tenantCalls{
+		"ListFleets":     func(ctx context.Context) error { _, err := r.ListFleets(ctx, &model.ListFleetRequest{}); return err },
+		"GetFleetDetail": func(ctx context.Context) error { _, err := r.GetFleetDetail(ctx, fleetOfB); return err },
+		"GetFleetFacilities": func(ctx context.Context) error {
+			_, err := r.GetFleetFacilities(ctx, fleetOfB)
+			return err
+		},
+		"GetFacilityList": func(ctx context.Context) error { _, err := r.GetFacilityList(ctx); return err },
+		"GetFleetPricing": func(ctx context.Context) error { _, err := r.GetFleetPricing(ctx, fleetOfB); return err },
+		"GetPartnerOrderPricing": func(ctx context.Context) error {
+			_, err := r.GetPartnerOrderPricing(ctx, orderOfB)
+			return err
+		},
+		"GetOrderItemVoucherDiscounts": func(ctx context.Context) error {
+			_, err := r.GetOrderItemVoucherDiscounts(ctx, orderOfB)
+			return err
+		},
+		"GetFleetOrderSummary": func(ctx context.Context) error {
+			_, err := r.GetFleetOrderSummary(ctx, fleetOfB, priceOfB)
+			return err
+		},
+		"GetFleetPrices": func(ctx context.Context) error { _, err := r.GetFleetPrices(ctx, fleetOfB); return err },
+		"GetFleetPriceListByRentType": func(ctx context.Context) error {
+			_, err := r.GetFleetPriceListByRentType(ctx, fleetOfB, 1)
+			return err
+		},
+		"GetFleetPickup": func(ctx context.Context) error { _, err := r.GetFleetPickup(ctx, fleetOfB); return err },
+		"GetOrderList": func(ctx context.Context) error {
+			_, _, err := r.GetOrderList(ctx, &model.GetOrderListRequest{Page: 1, Limit: 10})
+			return err
+		},
+		"GetFleetOrderTotalAmount": func(ctx context.Context) error {
+			_, err := r.GetFleetOrderTotalAmount(ctx, orderOfB, priceOfB)
+			return err
+		},
+		"GetFleetOrderPaymentsByOrderID": func(ctx context.Context) error {
+			_, err := r.GetFleetOrderPaymentsByOrderID(ctx, orderOfB)
+			return err
+		},
+		"UpdateFleetOrderPaymentStatus": func(ctx context.Context) error {
+			return r.UpdateFleetOrderPaymentStatus(ctx, orderOfB, 2, 1)
+		},
+		"FindOrderDetail": func(ctx context.Context) error { _, err := r.FindOrderDetail(ctx, orderOfB); return err },
+		"DeleteFleetOrderAddon": func(ctx context.Context) error {
+			return r.DeleteFleetOrderAddon(ctx, orderOfB, itemOfOrderB, addonOfB)
+		},
+		"UpdatePaymentEvidence": func(ctx context.Context) error {
+			return r.UpdatePaymentEvidence(ctx, orderOfB, "/assets/evidence/b.png")
+		},
+		"SyncFleetOrderTotalAmountFromItems": func(ctx context.Context) error {
+			_, err := r.SyncFleetOrderTotalAmountFromItems(ctx, orderOfB)
+			return err
+		},
+		"GetOrderTotalAmountByType": func(ctx context.Context) error {
+			_, err := r.GetOrderTotalAmountByType(ctx, 1, orderOfB)
+			return err
+		},
+		"GetServiceOrderPaymentStats": func(ctx context.Context) error {
+			_, err := r.GetServiceOrderPaymentStats(ctx, orderOfB)
+			return err
+		},
+		"FindFleetOrderIDByPrefix": func(ctx context.Context) error {
+			_, err := r.FindFleetOrderIDByPrefix(ctx, "FO-B")
+			return err
+		},
+		"ListFleetOrderPaymentHistory": func(ctx context.Context) error {
+			_, err := r.ListFleetOrderPaymentHistory(ctx, orderOfB)
+			return err
+		},
+		"ListPaymentOrders": func(ctx context.Context) error {
+			_, err := r.ListPaymentOrders(ctx, orderOfB, 1)
+			return err
+		},
+		"GetLatestPaymentOrder": func(ctx context.Context) error {
+			_, err := r.GetLatestPaymentOrder(ctx, orderOfB, 1)
+			return err
+		},
+		"GetFleetOrderItemTotals": func(ctx context.Context) error {
+			_, _, _, _, err := r.GetFleetOrderItemTotals(ctx, orderOfB)
+			return err
+		},
+		"UpdateFleetOrderPaymentStatusOnOrder": func(ctx context.Context) error {
+			return r.UpdateFleetOrderPaymentStatusOnOrder(ctx, orderOfB, 1)
+		},
+		"ListServiceOrderFleet": func(ctx context.Context) error {
+			_, err := r.ListServiceOrderFleet(ctx, "ongoing")
+			return err
+		},
+		"GetPartnerOrderList": func(ctx context.Context) error {
+			_, err := r.GetPartnerOrderList(ctx, &model.PartnerOrderListFilter{Search: "b"})
+			return err
+		},
+		"GetPartnerOrderSummary": func(ctx context.Context) error {
+			_, err := r.GetPartnerOrderSummary(ctx, &model.PartnerOrderListFilter{Search: "b"})
+			return err
+		},
+		"GetPartnerOrderFleetItems": func(ctx context.Context) error {
+			_, err := r.GetPartnerOrderFleetItems(ctx, orderOfB)
+			return err
+		},
+		"GetPartnerOrderDetail": func(ctx context.Context) error {
+			_, err := r.GetPartnerOrderDetail(ctx, orderOfB)
+			return err
+		},
+		"GetFleetAddon":  func(ctx context.Context) error { _, err := r.GetFleetAddon(ctx, fleetOfB); return err },
+		"GetFleetImages": func(ctx context.Context) error { _, err := r.GetFleetImages(ctx, fleetOfB); return err },
+		"GetAvailableCities": func(ctx context.Context) error {
+			_, err := r.GetAvailableCities(ctx)
+			return err
+		},
+		"GetFleetOrgID": func(ctx context.Context) error { _, err := r.GetFleetOrgID(ctx, fleetOfB); return err },
+		"GetFleetDetailMeta": func(ctx context.Context) error {
+			_, err := r.GetFleetDetailMeta(ctx, fleetOfB)
+			return err
+		},
+		"GetPriceByID": func(ctx context.Context) error { _, _, err := r.GetPriceByID(ctx, priceOfB); return err },
+		"GetFleetPricesByIDs": func(ctx context.Context) error {
+			_, err := r.GetFleetPricesByIDs(ctx, []string{priceOfB})
+			return err
+		},
+		"GetAddonPrices": func(ctx context.Context) error {
+			_, err := r.GetAddonPrices(ctx, []string{addonOfB})
+			return err
+		},
+		"GetAddonPriceSum": func(ctx context.Context) error {
+			_, err := r.GetAddonPriceSum(ctx, []string{addonOfB})
+			return err
+		},
+		"GetOrderCountByOrgID": func(ctx context.Context) error {
+			_, err := r.GetOrderCountByOrgID(ctx)
+			return err
+		},
+		"GetOrganizationCodeByOrgID": func(ctx context.Context) error {
+			_, err := r.GetOrganizationCodeByOrgID(ctx)
+			return err
+		},
+		"GetFleetAvailibility": func(ctx context.Context) error {
+			_, err := r.GetFleetAvailibility(ctx, start, end, fleetOfB)
+			return err
+		},
+		"ListFleetsForUnit": func(ctx context.Context) error {
+			_, err := r.ListFleetsForUnit(ctx, "bus")
+			return err
+		},
+		"SetFleetActiveStatus": func(ctx context.Context) error {
+			return r.SetFleetActiveStatus(ctx, employeeOfB, fleetOfB, false)
+		},
+		"SoftDeleteFleet": func(ctx context.Context) error { return r.SoftDeleteFleet(ctx, employeeOfB, fleetOfB) },
+		"GetScheduleByOrderID": func(ctx context.Context) error {
+			_, err := r.GetScheduleByOrderID(ctx, orderOfB)
+			return err
+		},
+		"GetOrderAvailability": func(ctx context.Context) error {
+			_, err := r.GetOrderAvailability(ctx, fleetOfB, 3171, start, &end, 3, nil)
+			return err
+		},
+		"ProcessFleetOrder": func(ctx context.Context) error {
+			return r.ProcessFleetOrder(ctx, employeeOfB, orderOfB, 2)
+		},
+		"GetCustomerOrderMeta": func(ctx context.Context) error {
+			_, _, err := r.GetCustomerOrderMeta(ctx, orderOfB)
+			return err
+		},
+		"InsertOrderReview": func(ctx context.Context) error {
+			return r.InsertOrderReview(ctx, "review-of-b", orderOfB, 5, "good", customerOfB, 1, start)
+		},
+		"GetOrderReviews": func(ctx context.Context) error { _, err := r.GetOrderReviews(ctx, orderOfB); return err },
+		"GetOrderRatingSummary": func(ctx context.Context) error {
+			_, err := r.GetOrderRatingSummary(ctx, orderOfB)
+			return err
+		},
+		"GetFleetRatings": func(ctx context.Context) error {
+			_, err := r.GetFleetRatings(ctx, []string{fleetOfB})
+			return err
+		},
+		"GetFleetReviews": func(ctx context.Context) error { _, err := r.GetFleetReviews(ctx, fleetOfB); return err },
+		"GetFleetRevenue": func(ctx context.Context) error {
+			_, err := r.GetFleetRevenue(ctx, fleetOfB, "2026-05-01", "2026-05-31")
+			return err
+		},
+		"GetPaidAmount": func(ctx context.Context) error { _, err := r.GetPaidAmount(ctx, orderOfB); return err },
+		"GetOrderAddonAmount": func(ctx context.Context) error {
+			_, err := r.GetOrderAddonAmount(ctx, orderOfB)
+			return err
+		},
+		"FleetOrderCancelation": func(ctx context.Context) error {
+			return r.FleetOrderCancelation(ctx, employeeOfB, orderOfB)
+		},
+		"RefundOrderTransactions": func(ctx context.Context) error {
+			return r.RefundOrderTransactions(ctx, orderOfB, &model.CancellationRefundQuote{RefundAmount: 100}, "cancelled", "transfer", "014", "123", "B", employeeOfB)
+		},
+		"CancelSchedulesAndRelated": func(ctx context.Context) error {
+			return r.CancelSchedulesAndRelated(ctx, employeeOfB, orderOfB)
+		},
+		"CreateCustomer": func(ctx context.Context) error {
+			_, err := r.CreateCustomer(ctx, "Budi", "0812", "PT B")
+			return err
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"CreateFleet": func() error {
+			_, err := r.CreateFleet(ctxA, &model.CreateFleetRequest{FleetName: "Bus", OrganizationID: tenantB})
+			return err
+		},
+		"UpdateFleet": func() error {
+			return r.UpdateFleet(ctxA, &model.UpdateFleetRequest{FleetID: fleetOfB, OrganizationID: tenantB})
+		},
+		"CreateOrder": func() error {
+			return r.CreateOrder(ctxA, &model.CreateOrderRequest{FleetID: fleetOfB, PriceID: priceOfB, Qty: 1, OrganizationID: tenantB})
+		},
+		"UpdatePartnerOrder": func() error {
+			return r.UpdatePartnerOrder(ctxA, UpdatePartnerOrderInput{OrderID: orderOfB, OrganizationID: tenantB})
+		},
+		"CreateOrderPayment": func() error {
+			return r.CreateOrderPayment(ctxA, &model.FleetOrderPayment{OrderID: orderOfB, OrganizationID: tenantB}, &model.OrderPaymentHistory{OrderID: orderOfB, OrganizationID: tenantB})
+		},
+		"InsertServiceOrderPayment": func() error {
+			_, _, err := r.InsertServiceOrderPayment(ctxA, &model.CreateServiceOrderPaymentRequest{OrderID: orderOfB, OrderType: 1, OrganizationID: tenantB}, 100, 0)
+			return err
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"service-travego/database"
 )
@@ -14,8 +15,8 @@ func NewFleetTypeRepository(db *sql.DB) *FleetTypeRepository {
 }
 
 // FindAll returns all fleet_types
-func (r *FleetTypeRepository) FindAll() ([]map[string]interface{}, error) {
-	rows, err := database.Query(r.db, "SELECT * FROM fleet_types ORDER BY label")
+func (r *FleetTypeRepository) FindAll(ctx context.Context) ([]map[string]interface{}, error) {
+	rows, err := database.QueryContext(ctx, r.db, "SELECT * FROM fleet_types ORDER BY label")
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"service-travego/database"
 	"service-travego/model"
 	"strconv"
@@ -28,7 +30,12 @@ func (r *FleetUnitRepository) placeholder(pos int) string {
 	return "?"
 }
 
-func (r *FleetUnitRepository) FindExistingVehicleIDs(orgID string, vehicleIDs []string) (map[string]struct{}, error) {
+func (r *FleetUnitRepository) FindExistingVehicleIDs(ctx context.Context, vehicleIDs []string) (map[string]struct{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	out := map[string]struct{}{}
 	if len(vehicleIDs) == 0 {
 		return out, nil
@@ -50,7 +57,7 @@ func (r *FleetUnitRepository) FindExistingVehicleIDs(orgID string, vehicleIDs []
 	}
 
 	query := "SELECT DISTINCT " + vehicleExpr + " AS vehicle_id FROM fleet_units WHERE " + orgExpr + " AND " + vehicleExpr + " IN (" + strings.Join(in, ",") + ")"
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +78,12 @@ func (r *FleetUnitRepository) FindExistingVehicleIDs(orgID string, vehicleIDs []
 	return out, nil
 }
 
-func (r *FleetUnitRepository) FindExistingPlateNumbers(orgID string, plateNumbers []string) (map[string]struct{}, error) {
+func (r *FleetUnitRepository) FindExistingPlateNumbers(ctx context.Context, plateNumbers []string) (map[string]struct{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	out := map[string]struct{}{}
 	if len(plateNumbers) == 0 {
 		return out, nil
@@ -92,7 +104,7 @@ func (r *FleetUnitRepository) FindExistingPlateNumbers(orgID string, plateNumber
 	}
 
 	query := "SELECT DISTINCT " + plateExpr + " AS plate_number FROM fleet_units WHERE " + orgExpr + " AND " + plateExpr + " IN (" + strings.Join(in, ",") + ")"
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -129,9 +141,9 @@ SELECT
 	COALESCE(fu.status, 0) AS status,
 	COALESCE(fu.ownership_type, 0) AS ownership_type
 FROM fleet_units fu
-LEFT JOIN fleets f ON f.uuid::text = fu.fleet_id::text
+LEFT JOIN fleets f ON f.uuid::text = fu.fleet_id::text AND f.organization_id = fu.organization_id
 LEFT JOIN schedule_fleets sf ON sf.fleet_id::text = fu.fleet_id::text
-AND sf.order_id::text = $3
+AND sf.order_id::text = $3 AND sf.organization_id = fu.organization_id
 WHERE fu.organization_id::text = $1 AND (fu.fleet_id::text = $2 OR $2 = '')
 AND (
 	$4 = '' OR
@@ -159,8 +171,8 @@ SELECT
 	COALESCE(fu.status, 0) AS status,
 	COALESCE(fu.ownership_type, 0) AS ownership_type
 FROM fleet_units fu
-LEFT JOIN fleets f ON f.uuid::text = fu.fleet_id::text
-INNER JOIN schedule_fleets sf ON sf.fleet_id::text = fu.fleet_id::text
+LEFT JOIN fleets f ON f.uuid::text = fu.fleet_id::text AND f.organization_id = fu.organization_id
+INNER JOIN schedule_fleets sf ON sf.fleet_id::text = fu.fleet_id::text AND sf.organization_id = fu.organization_id
 WHERE fu.organization_id::text = $1 AND (fu.fleet_id::text = $2 OR $2 = '') AND sf.order_id::text = $3
 AND (
 	$4 = '' OR
@@ -336,11 +348,11 @@ SELECT
 	fu.ownership_type,
 	fuo.partner_id
 FROM fleet_units fu
-LEFT JOIN fleets f ON f.uuid::text = fu.fleet_id::text
+LEFT JOIN fleets f ON f.uuid::text = fu.fleet_id::text AND f.organization_id = fu.organization_id
 LEFT JOIN fleet_types ft ON f.fleet_type = ft.id
 LEFT JOIN users uc ON fu.created_by = uc.user_id
 LEFT JOIN users uu ON fu.updated_by = uu.user_id
-LEFT JOIN fleet_unit_ownership fuo ON fuo.unit_id = fu.unit_id
+LEFT JOIN fleet_unit_ownership fuo ON fuo.unit_id = fu.unit_id AND fuo.organization_id = fu.organization_id
 WHERE fu.unit_id = $1 AND fu.organization_id::text = $2
 `
 
@@ -365,7 +377,7 @@ SELECT
 	fu.updated_at,
 	fu.ownership_type
 FROM fleet_units fu
-LEFT JOIN fleets f ON fu.fleet_id = f.uuid
+LEFT JOIN fleets f ON fu.fleet_id = f.uuid AND f.organization_id = fu.organization_id
 LEFT JOIN fleet_types ft ON f.fleet_type = ft.id
 LEFT JOIN users uc ON fu.created_by = uc.user_id
 LEFT JOIN users uu ON fu.updated_by = uu.user_id
@@ -376,8 +388,8 @@ const unitRatingPostgres = `
 SELECT
 	COALESCE(ROUND(AVG(r.star), 1), 0)::float8 AS rating
 FROM order_reviews r
-INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
-INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id
+INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
+INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id AND sf.organization_id = r.organization_id
 WHERE sf.unit_id::text = $1 AND sf.organization_id::text = $2
 `
 
@@ -385,17 +397,17 @@ const unitRatingMySQL = `
 SELECT
 	COALESCE(ROUND(AVG(r.star), 1), 0) AS rating
 FROM order_reviews r
-INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
-INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id
+INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
+INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id AND sf.organization_id = r.organization_id
 WHERE sf.unit_id = ? AND sf.organization_id = ?
 `
 
 const unitReviewsPostgres = `
 SELECT r.star, r.review, c.customer_name, r.created_at
 FROM order_reviews r
-INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
-INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id
-INNER JOIN customers c ON c.customer_id = r.customer_id
+INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
+INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id AND sf.organization_id = r.organization_id
+INNER JOIN customers c ON c.customer_id = r.customer_id AND c.organization_id = r.organization_id
 WHERE sf.unit_id::text = $1 AND sf.organization_id::text = $2
 ORDER BY r.created_at DESC
 LIMIT 10
@@ -404,9 +416,9 @@ LIMIT 10
 const unitReviewsMySQL = `
 SELECT r.star, r.review, c.customer_name, r.created_at
 FROM order_reviews r
-INNER JOIN fleet_orders fo ON r.order_id = fo.order_id
-INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id
-INNER JOIN customers c ON c.customer_id = r.customer_id
+INNER JOIN fleet_orders fo ON r.order_id = fo.order_id AND fo.organization_id = r.organization_id
+INNER JOIN schedule_fleets sf ON sf.order_id = r.order_id AND sf.organization_id = r.organization_id
+INNER JOIN customers c ON c.customer_id = r.customer_id AND c.organization_id = r.organization_id
 WHERE sf.unit_id = ? AND sf.organization_id = ?
 ORDER BY r.created_at DESC
 LIMIT 10
@@ -415,7 +427,7 @@ LIMIT 10
 const unitUpcomingSchedulePostgres = `
 SELECT fo.start_date, fo.end_date
 FROM schedule_fleets sf
-INNER JOIN fleet_orders fo ON sf.order_id = fo.order_id
+INNER JOIN fleet_orders fo ON sf.order_id = fo.order_id AND fo.organization_id = sf.organization_id
 WHERE sf.unit_id::text = $1 AND sf.organization_id::text = $2 AND fo.start_date >= $3
 ORDER BY fo.start_date ASC
 LIMIT 1
@@ -424,20 +436,25 @@ LIMIT 1
 const unitUpcomingScheduleMySQL = `
 SELECT fo.start_date, fo.end_date
 FROM schedule_fleets sf
-INNER JOIN fleet_orders fo ON sf.order_id = fo.order_id
+INNER JOIN fleet_orders fo ON sf.order_id = fo.order_id AND fo.organization_id = sf.organization_id
 WHERE sf.unit_id = ? AND sf.organization_id = ? AND fo.start_date >= ?
 ORDER BY fo.start_date ASC
 LIMIT 1
 `
 
-func (r *FleetUnitRepository) GetFleetPickupCityIDs(orgID, fleetID string) ([]int, error) {
+func (r *FleetUnitRepository) GetFleetPickupCityIDs(ctx context.Context, fleetID string) ([]int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.placeholder(1)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.placeholder(1)
 	}
 	query := "SELECT city_id FROM fleet_pickup WHERE fleet_id = " + r.placeholder(2) + " AND " + orgExpr
 
-	rows, err := database.Query(r.db, query, orgID, fleetID)
+	rows, err := t.Query(query, orgID, fleetID)
 	if err != nil {
 		return nil, err
 	}
@@ -457,7 +474,12 @@ func (r *FleetUnitRepository) GetFleetPickupCityIDs(orgID, fleetID string) ([]in
 	return items, nil
 }
 
-func (r *FleetUnitRepository) List(orgID, fleetId, orderID, search string) ([]model.FleetUnitListItem, error) {
+func (r *FleetUnitRepository) List(ctx context.Context, fleetId, orderID, search string) ([]model.FleetUnitListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	search = strings.TrimSpace(search)
 	searchPattern := ""
 	if search != "" {
@@ -474,9 +496,8 @@ func (r *FleetUnitRepository) List(orgID, fleetId, orderID, search string) ([]mo
 	args = append(args, fleetId)
 	args = append(args, orderID)
 	args = append(args, searchPattern)
-	rows, err := database.Query(r.db, query, args...)
-	fmt.Println(query)
-	fmt.Println(args)
+	rows, err := t.Query(query, args...)
+	slog.DebugContext(ctx, "list fleet units", "query", query, "args", args)
 	if err != nil {
 		return nil, err
 	}
@@ -512,18 +533,21 @@ func (r *FleetUnitRepository) List(orgID, fleetId, orderID, search string) ([]mo
 	return items, nil
 }
 
-func (r *FleetUnitRepository) Create(req *model.FleetUnitCreateRequest) (string, error) {
+func (r *FleetUnitRepository) Create(ctx context.Context, req *model.FleetUnitCreateRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
 	id := uuid.New().String()
 	now := time.Now()
 	req.CreatedDate = now
 	req.UnitID = id
 
 	tryExec := func(query string, args ...interface{}) error {
-		_, err := database.Exec(r.db, query, args...)
+		_, err := t.Exec(query, args...)
 		return err
 	}
 
-	var err error
 	if r.driver == "postgres" || r.driver == "pgx" {
 		err = tryExec(createFleetUnitPostgresWithUUIDAndStatus, id, req.UnitID, req.VehicleID, req.PlateNumber, req.FleetID, req.Engine, req.Transmission, req.Capacity, req.ProductionYear, 1, req.CreatedBy, req.OrganizationID, req.CreatedDate, req.OwnershipType)
 		if err != nil {
@@ -612,7 +636,11 @@ func (r *FleetUnitRepository) Create(req *model.FleetUnitCreateRequest) (string,
 	return id, nil
 }
 
-func (r *FleetUnitRepository) Update(req *model.FleetUnitUpdateRequest) error {
+func (r *FleetUnitRepository) Update(ctx context.Context, req *model.FleetUnitUpdateRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	now := time.Now()
 	req.UpdatedDate = now
 
@@ -620,8 +648,7 @@ func (r *FleetUnitRepository) Update(req *model.FleetUnitUpdateRequest) error {
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = updateFleetUnitPostgres
 	}
-	res, err := database.Exec(
-		r.db,
+	res, err := t.Exec(
 		query,
 		req.VehicleID,
 		req.PlateNumber,
@@ -646,7 +673,12 @@ func (r *FleetUnitRepository) Update(req *model.FleetUnitUpdateRequest) error {
 	return nil
 }
 
-func (r *FleetUnitRepository) Detail(orgID, id string) (*model.FleetUnitDetailResponse, error) {
+func (r *FleetUnitRepository) Detail(ctx context.Context, id string) (*model.FleetUnitDetailResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := detailFleetUnitMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = detailFleetUnitPostgres
@@ -657,7 +689,7 @@ func (r *FleetUnitRepository) Detail(orgID, id string) (*model.FleetUnitDetailRe
 	var partnerID sql.NullString
 
 	var ownershipType sql.NullInt32
-	err := database.QueryRow(r.db, query, id, orgID).Scan(
+	err = t.QueryRow(query, id, orgID).Scan(
 		&res.UnitID,
 		&res.VehicleID,
 		&res.PlateNumber,
@@ -695,18 +727,23 @@ func (r *FleetUnitRepository) Detail(orgID, id string) (*model.FleetUnitDetailRe
 	return &res, nil
 }
 
-func (r *FleetUnitRepository) GetOwnershipInformation(orgID, unitID string) (*model.FleetUnitOwnershipInformation, error) {
+func (r *FleetUnitRepository) GetOwnershipInformation(ctx context.Context, unitID string) (*model.FleetUnitOwnershipInformation, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := `
 		SELECT op.partner_id, op.partner_name, op.partner_phone, op.partner_email, op.pic_name as partner_pic
 		FROM operation_partner op
-		INNER JOIN fleet_unit_ownership fuo ON fuo.partner_id = op.partner_id
-		INNER JOIN fleet_units fu ON fu.unit_id = fuo.unit_id
+		INNER JOIN fleet_unit_ownership fuo ON fuo.partner_id = op.partner_id AND fuo.organization_id = op.organization_id
+		INNER JOIN fleet_units fu ON fu.unit_id = fuo.unit_id AND fu.organization_id = fuo.organization_id
 		WHERE fuo.organization_id = $1 AND fu.unit_id = $2`
 
 	var info model.FleetUnitOwnershipInformation
 	var partnerEmail sql.NullString
 	var partnerPic sql.NullString
-	err := r.db.QueryRow(query, orgID, unitID).Scan(
+	err = t.QueryRow(query, orgID, unitID).Scan(
 		&info.PartnerID,
 		&info.PartnerName,
 		&info.PartnerPhone,
@@ -728,7 +765,12 @@ func (r *FleetUnitRepository) GetOwnershipInformation(orgID, unitID string) (*mo
 	return &info, nil
 }
 
-func (r *FleetUnitRepository) SetUnitOwnership(unitID, partnerID, orgID, userID string) error {
+func (r *FleetUnitRepository) SetUnitOwnership(ctx context.Context, unitID, partnerID, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	now := time.Now()
 	fleetOwnershipID := uuid.New().String()
 
@@ -749,7 +791,7 @@ func (r *FleetUnitRepository) SetUnitOwnership(unitID, partnerID, orgID, userID
 		query = strings.ReplaceAll(query, "$8", "?")
 	}
 
-	_, err := r.db.Exec(query, fleetOwnershipID, unitID, partnerID, now, userID, now, userID, orgID)
+	_, err = t.Exec(query, fleetOwnershipID, unitID, partnerID, now, userID, now, userID, orgID)
 	if err != nil {
 		return err
 	}
@@ -764,11 +806,16 @@ func (r *FleetUnitRepository) SetUnitOwnership(unitID, partnerID, orgID, userID
 		deleteQuery = strings.ReplaceAll(deleteQuery, "$3", "?")
 	}
 
-	_, err = r.db.Exec(deleteQuery, unitID, partnerID, orgID)
+	_, err = t.Exec(deleteQuery, unitID, partnerID, orgID)
 	return err
 }
 
-func (r *FleetUnitRepository) UnitOrderHistory(orgID, unitID, startDate, endDate string) ([]model.FleetUnitOrderHistoryItem, error) {
+func (r *FleetUnitRepository) UnitOrderHistory(ctx context.Context, unitID, startDate, endDate string) ([]model.FleetUnitOrderHistoryItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	startAt, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(startDate), time.Local)
 	if err != nil {
 		return nil, err
@@ -790,17 +837,17 @@ func (r *FleetUnitRepository) UnitOrderHistory(orgID, unitID, startDate, endDate
 		COALESCE(fo.pickup_city_id::text, '') AS pickup_city_id,
 		STRING_AGG(DISTINCT fi.city_id::text, ', ') AS destination_ids
 	FROM schedule_fleets fuo
-	INNER JOIN fleet_orders fo ON fuo.order_id::text = fo.order_id::text
-	INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id = fuo.uuid
-	INNER JOIN fleet_order_itinerary fi ON fi.order_id = fo.order_id
-	LEFT JOIN employee d ON d.uuid::text = sft.driver_id::text
+	INNER JOIN fleet_orders fo ON fuo.order_id::text = fo.order_id::text AND fuo.organization_id = fo.organization_id
+	INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id = fuo.uuid AND sft.organization_id = fo.organization_id
+	INNER JOIN fleet_order_itinerary fi ON fi.order_id = fo.order_id AND fi.organization_id = fo.organization_id
+	LEFT JOIN employee d ON d.uuid::text = sft.driver_id::text AND d.organization_id = fo.organization_id
 	WHERE fo.organization_id::text = $1 AND fuo.unit_id::text = $2 AND fo.start_date >= $3 AND fo.end_date < $4
 	GROUP BY fuo.order_id, fuo.unit_id, sft.driver_id, d.fullname, fo.start_date, fo.end_date, fo.status, fo.pickup_city_id, fi.city_id
 	ORDER BY fo.start_date DESC
 	`
-	fmt.Println(query, orgID, unitID, startAt, endExclusive)
+	slog.DebugContext(ctx, "unit order history", "query", query, "organization_id", orgID, "unit_id", unitID, "start_at", startAt, "end_exclusive", endExclusive)
 
-	rows, err := database.Query(r.db, query, orgID, unitID, startAt, endExclusive)
+	rows, err := t.Query(query, orgID, unitID, startAt, endExclusive)
 	if err != nil {
 		return nil, err
 	}
@@ -839,13 +886,18 @@ func (r *FleetUnitRepository) UnitOrderHistory(orgID, unitID, startDate, endDate
 	return out, nil
 }
 
-func (r *FleetUnitRepository) UnitRating(orgID, unitID string) (float64, error) {
+func (r *FleetUnitRepository) UnitRating(ctx context.Context, unitID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	query := unitRatingMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = unitRatingPostgres
 	}
 	var rating sql.NullFloat64
-	if err := database.QueryRow(r.db, query, unitID, orgID).Scan(&rating); err != nil {
+	if err := t.QueryRow(query, unitID, orgID).Scan(&rating); err != nil {
 		return 0, err
 	}
 	if rating.Valid {
@@ -854,13 +906,18 @@ func (r *FleetUnitRepository) UnitRating(orgID, unitID string) (float64, error)
 	return 0, nil
 }
 
-func (r *FleetUnitRepository) UnitReviews(orgID, unitID string) ([]model.OrderReviewItem, error) {
+func (r *FleetUnitRepository) UnitReviews(ctx context.Context, unitID string) ([]model.OrderReviewItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := unitReviewsMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = unitReviewsPostgres
 	}
 
-	rows, err := database.Query(r.db, query, unitID, orgID)
+	rows, err := t.Query(query, unitID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -882,30 +939,40 @@ func (r *FleetUnitRepository) UnitReviews(orgID, unitID string) ([]model.OrderRe
 	return out, nil
 }
 
-func (r *FleetUnitRepository) UnitTotalSchedules(orgID, unitID string) (int64, error) {
+func (r *FleetUnitRepository) UnitTotalSchedules(ctx context.Context, unitID string) (int64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	query := `
 			SELECT COUNT(*) AS total_schedules
 			FROM schedule_fleets
 			WHERE unit_id::text = $1 AND organization_id::text = $2
 			`
 	var total int64
-	if err := database.QueryRow(r.db, query, unitID, orgID).Scan(&total); err != nil {
+	if err := t.QueryRow(query, unitID, orgID).Scan(&total); err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
-func (r *FleetUnitRepository) UnitLatestSchedule(orgID, unitID string, now time.Time) (*model.FleetUnitScheduleRange, error) {
+func (r *FleetUnitRepository) UnitLatestSchedule(ctx context.Context, unitID string, now time.Time) (*model.FleetUnitScheduleRange, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := `SELECT fo.start_date, fo.end_date
 			FROM schedule_fleets sf
-			INNER JOIN fleet_orders fo ON sf.order_id = fo.order_id
+			INNER JOIN fleet_orders fo ON sf.order_id = fo.order_id AND fo.organization_id = sf.organization_id
 			WHERE sf.unit_id::text = $1 AND sf.organization_id::text = $2 AND fo.end_date <= $3
 			ORDER BY fo.end_date DESC
 			LIMIT 1
 			`
 	var startDate sql.NullTime
 	var endDate sql.NullTime
-	if err := database.QueryRow(r.db, query, unitID, orgID, now).Scan(&startDate, &endDate); err != nil {
+	if err := t.QueryRow(query, unitID, orgID, now).Scan(&startDate, &endDate); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -921,14 +988,19 @@ func (r *FleetUnitRepository) UnitLatestSchedule(orgID, unitID string, now time.
 	return out, nil
 }
 
-func (r *FleetUnitRepository) UnitUpcomingSchedule(orgID, unitID string, now time.Time) (*model.FleetUnitScheduleRange, error) {
+func (r *FleetUnitRepository) UnitUpcomingSchedule(ctx context.Context, unitID string, now time.Time) (*model.FleetUnitScheduleRange, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := unitUpcomingScheduleMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = unitUpcomingSchedulePostgres
 	}
 	var startDate sql.NullTime
 	var endDate sql.NullTime
-	if err := database.QueryRow(r.db, query, unitID, orgID, now).Scan(&startDate, &endDate); err != nil {
+	if err := t.QueryRow(query, unitID, orgID, now).Scan(&startDate, &endDate); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -944,25 +1016,31 @@ func (r *FleetUnitRepository) UnitUpcomingSchedule(orgID, unitID string, now tim
 	return out, nil
 }
 
-func (r *FleetUnitRepository) GetOrderDestinationCityIDs(orderIDs []string) (map[string][]string, error) {
+func (r *FleetUnitRepository) GetOrderDestinationCityIDs(ctx context.Context, orderIDs []string) (map[string][]string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	out := map[string][]string{}
 	if len(orderIDs) == 0 {
 		return out, nil
 	}
 
 	in := make([]string, 0, len(orderIDs))
-	args := make([]interface{}, 0, len(orderIDs))
+	args := make([]interface{}, 0, 1+len(orderIDs))
+	args = append(args, orgID)
 	for i, id := range orderIDs {
-		in = append(in, r.placeholder(i+1))
+		in = append(in, r.placeholder(i+2))
 		args = append(args, strings.TrimSpace(id))
 	}
 
-	query := "SELECT COALESCE(order_id, '') AS order_id, COALESCE(CAST(city_id AS CHAR), '') AS city_id FROM fleet_order_itinerary WHERE order_id IN (" + strings.Join(in, ",") + ")"
+	query := "SELECT COALESCE(order_id, '') AS order_id, COALESCE(CAST(city_id AS CHAR), '') AS city_id FROM fleet_order_itinerary WHERE organization_id = " + r.placeholder(1) + " AND order_id IN (" + strings.Join(in, ",") + ")"
 	if r.driver == "postgres" || r.driver == "pgx" {
-		query = "SELECT COALESCE(order_id::text, '') AS order_id, COALESCE(city_id::text, '') AS city_id FROM fleet_order_itinerary WHERE order_id::text IN (" + strings.Join(in, ",") + ")"
+		query = "SELECT COALESCE(order_id::text, '') AS order_id, COALESCE(city_id::text, '') AS city_id FROM fleet_order_itinerary WHERE organization_id::text = " + r.placeholder(1) + " AND order_id::text IN (" + strings.Join(in, ",") + ")"
 	}
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -995,7 +1073,12 @@ func (r *FleetUnitRepository) GetOrderDestinationCityIDs(orderIDs []string) (map
 	return out, nil
 }
 
-func (r *FleetUnitRepository) GetUnitRevenue(orgID, unitID, startDate, endDate string) (*model.FleetUnitRevenue, error) {
+func (r *FleetUnitRepository) GetUnitRevenue(ctx context.Context, unitID, startDate, endDate string) (*model.FleetUnitRevenue, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	startAt, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(startDate), time.Local)
 	if err != nil {
 		return nil, err
@@ -1056,80 +1139,45 @@ func (r *FleetUnitRepository) GetUnitRevenue(orgID, unitID, startDate, endDate s
 		}
 	}
 
-	sfOrderExpr := "order_id::text"
-	trxRefExpr := "reference_id::text"
-	foiOrderExpr := "order_id::text"
-	unitExpr := "unit_id::text = " + r.placeholder(1)
-	orgExpr := "organization_id::text = " + r.placeholder(2)
-	totalBookingUnitExpr := "sf.unit_id::text = " + r.placeholder(5)
-	totalBookingOrgExpr := "sf.organization_id::text = " + r.placeholder(6)
-	totalBookingFoOrgExpr := "fo2.organization_id::text = " + r.placeholder(7)
-
 	query := fmt.Sprintf(`
 		SELECT
 			(
 				SELECT COALESCE(SUM(COALESCE(t.total_amount / NULLIF(q.total_qty, 0), 0)), 0) AS revenue
 				FROM (
-					SELECT DISTINCT %s AS order_id
-					FROM schedule_fleets
-					WHERE %s AND %s
+					SELECT DISTINCT s.order_id::text AS order_id
+					FROM schedule_fleets s
+					WHERE s.unit_id::text = %[1]s AND s.organization_id::text = %[2]s
 				) sf
 				INNER JOIN (
-					SELECT %s AS order_id, SUM(amount) AS total_amount
-					FROM transactions
-					WHERE transaction_date IS NOT NULL
-						AND transaction_date >= %s AND transaction_date < %s
-					GROUP BY %s
+					SELECT tr.reference_id::text AS order_id, SUM(tr.amount) AS total_amount
+					FROM transactions tr
+					WHERE tr.organization_id::text = %[2]s AND tr.transaction_date IS NOT NULL
+						AND tr.transaction_date >= %[3]s AND tr.transaction_date < %[4]s
+					GROUP BY tr.reference_id::text
 				) t ON t.order_id = sf.order_id
 				INNER JOIN (
-					SELECT %s AS order_id, SUM(quantity) AS total_qty
-					FROM fleet_order_items
-					GROUP BY %s
+					SELECT foi.order_id::text AS order_id, SUM(foi.quantity) AS total_qty
+					FROM fleet_order_items foi
+					WHERE foi.organization_id::text = %[2]s
+					GROUP BY foi.order_id::text
 				) q ON q.order_id = sf.order_id
 			) AS revenue,
 			(
 				SELECT COALESCE(COUNT(DISTINCT sf.schedule_number), 0) AS total_booking
 				FROM schedule_fleets sf
 				INNER JOIN fleet_orders fo2 ON fo2.order_id = sf.order_id
-				WHERE %s
-					AND %s
-					AND %s
+				WHERE sf.unit_id::text = %[1]s
+					AND sf.organization_id::text = %[2]s
+					AND fo2.organization_id::text = %[2]s
 					AND fo2.status = 1
-					AND fo2.start_date >= %s
-					AND fo2.end_date < %s
+					AND fo2.start_date >= %[3]s
+					AND fo2.end_date < %[4]s
 			) AS total_booking
-	`,
-		sfOrderExpr,
-		unitExpr,
-		orgExpr,
-		trxRefExpr,
-		r.placeholder(3),
-		r.placeholder(4),
-		trxRefExpr,
-		foiOrderExpr,
-		foiOrderExpr,
-		totalBookingUnitExpr,
-		totalBookingOrgExpr,
-		totalBookingFoOrgExpr,
-		r.placeholder(8),
-		r.placeholder(9),
-	)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
 
 	var revenueAny interface{}
 	var totalBookingAny interface{}
-	if err := database.QueryRow(
-		r.db,
-		query,
-		unitID,
-		orgID,
-		startAt,
-		endExclusive,
-		unitID,
-		orgID,
-		orgID,
-		startAt,
-		endExclusive,
-	).Scan(&revenueAny, &totalBookingAny); err != nil {
+	if err := t.QueryRow(query, unitID, orgID, startAt, endExclusive).Scan(&revenueAny, &totalBookingAny); err != nil {
 		return nil, err
 	}
 
@@ -1145,7 +1193,12 @@ func (r *FleetUnitRepository) GetUnitRevenue(orgID, unitID, startDate, endDate s
 	return &model.FleetUnitRevenue{TotalRevenue: revenue, TotalBooking: totalBooking}, nil
 }
 
-func (r *FleetUnitRepository) ListUnitRevenueHistory(orgID, unitID, startDate, endDate string) ([]model.FleetUnitRevenueHistoryItem, error) {
+func (r *FleetUnitRepository) ListUnitRevenueHistory(ctx context.Context, unitID, startDate, endDate string) ([]model.FleetUnitRevenueHistoryItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	if r.driver != "postgres" && r.driver != "pgx" {
 		return nil, fmt.Errorf("unsupported driver")
 	}
@@ -1219,8 +1272,8 @@ func (r *FleetUnitRepository) ListUnitRevenueHistory(orgID, unitID, startDate, e
 			COALESCE(t.payment_method, 0) AS payment_method,
 			COALESCE(SUM(t.amount) / NULLIF(SUM(foi.quantity), 0), 0) AS amount
 		FROM schedule_fleets sf
-		INNER JOIN transactions t ON t.reference_id::text = sf.order_id::text
-		INNER JOIN fleet_order_items foi ON foi.order_id::text = sf.order_id::text
+		INNER JOIN transactions t ON t.reference_id::text = sf.order_id::text AND t.organization_id = sf.organization_id
+		INNER JOIN fleet_order_items foi ON foi.order_id::text = sf.order_id::text AND foi.organization_id = sf.organization_id
 		WHERE sf.unit_id::text = $1
 			AND sf.organization_id::text = $2
 			AND t.transaction_date IS NOT NULL
@@ -1238,7 +1291,7 @@ func (r *FleetUnitRepository) ListUnitRevenueHistory(orgID, unitID, startDate, e
 		ORDER BY t.created_at DESC
 	`
 
-	rows, err := database.Query(r.db, query, unitID, orgID, startAt, endExclusive)
+	rows, err := t.Query(query, unitID, orgID, startAt, endExclusive)
 	if err != nil {
 		return nil, err
 	}
@@ -1292,7 +1345,12 @@ func (r *FleetUnitRepository) ListUnitRevenueHistory(orgID, unitID, startDate, e
 	return out, nil
 }
 
-func (r *FleetUnitRepository) ListUnitExpenses(orgID, unitID string, startDate, endDate time.Time) ([]model.FleetUnitExpenseItem, error) {
+func (r *FleetUnitRepository) ListUnitExpenses(ctx context.Context, unitID string, startDate, endDate time.Time) ([]model.FleetUnitExpenseItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	parseFloat64 := func(v interface{}) (float64, bool) {
 		switch vv := v.(type) {
 		case nil:
@@ -1353,7 +1411,7 @@ func (r *FleetUnitRepository) ListUnitExpenses(orgID, unitID string, startDate,
 				COALESCE(t.payment_type, 0) AS payment_type,
 				COALESCE(t.amount, 0) AS amount
 			FROM transactions t
-			LEFT JOIN schedule_fleets sf ON sf.schedule_number::text = t.reference_id::text
+			LEFT JOIN schedule_fleets sf ON sf.schedule_number::text = t.reference_id::text AND sf.organization_id = t.organization_id
 			WHERE sf.unit_id::text = %s AND t.organization_id::text = %s
 				AND COALESCE(t.status, 0) = 1
 				AND COALESCE(t.transaction_type, 0) = 2
@@ -1368,7 +1426,7 @@ func (r *FleetUnitRepository) ListUnitExpenses(orgID, unitID string, startDate,
 				COALESCE(t.payment_type, 0) AS payment_type,
 				COALESCE(t.amount, 0) AS amount
 			FROM transactions t
-			INNER JOIN transaction_fleets tf ON t.transaction_id = tf.transaction_id
+			INNER JOIN transaction_fleets tf ON t.transaction_id = tf.transaction_id AND tf.organization_id = t.organization_id
 			WHERE tf.fleet_unit_id::text = %s AND t.organization_id::text = %s
 				AND COALESCE(t.status, 0) = 1
 				AND COALESCE(t.transaction_type, 0) = 2
@@ -1377,9 +1435,9 @@ func (r *FleetUnitRepository) ListUnitExpenses(orgID, unitID string, startDate,
 		WHERE expenses.transaction_date >= %s AND expenses.transaction_date < %s
 		ORDER BY expenses.transaction_date DESC
 	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6))
-	fmt.Println((query))
+	slog.DebugContext(ctx, "list unit expenses", "query", query)
 
-	rows, err := database.Query(r.db, query, unitID, orgID, unitID, orgID, startDate, endDate)
+	rows, err := t.Query(query, unitID, orgID, unitID, orgID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1421,16 +1479,20 @@ func (r *FleetUnitRepository) ListUnitExpenses(orgID, unitID string, startDate,
 	return out, nil
 }
 
-func (r *FleetUnitRepository) UpdateOwnerInformation(orgID, unitID string, partnerID, partnerName string, partnerPhone, partnerPic string) error {
+func (r *FleetUnitRepository) UpdateOwnerInformation(ctx context.Context, unitID string, partnerID, partnerName string, partnerPhone, partnerPic string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	if partnerID != "" && partnerName != "" && partnerPhone != "" {
 		query := fmt.Sprintf(`
 			UPDATE operation_partner
 			SET partner_name = %s, partner_phone = %s, pic_name = %s
 			WHERE partner_id = %s AND organization_id = %s
 		`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5))
-		if _, err := database.Exec(r.db, query, partnerName, partnerPhone, partnerPic, partnerID, orgID); err != nil {
-			fmt.Println("UpdateOwnerInformation: Update partner information failed")
-			fmt.Println(err)
+		if _, err := t.Exec(query, partnerName, partnerPhone, partnerPic, partnerID, orgID); err != nil {
+			slog.ErrorContext(ctx, "update partner information failed", "partner_id", partnerID, "error", err)
 			return err
 		}
 
@@ -1439,20 +1501,732 @@ func (r *FleetUnitRepository) UpdateOwnerInformation(orgID, unitID string, partn
 			SET partner_id = %s
 			WHERE unit_id = %s AND organization_id = %s
 		`, r.placeholder(1), r.placeholder(2), r.placeholder(3))
-		if _, err := database.Exec(r.db, updataQuery, partnerID, unitID, orgID); err != nil {
+		if _, err := t.Exec(updataQuery, partnerID, unitID, orgID); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *FleetUnitRepository) DeleteUnitOwnership(orgID, unitID string) error {
+func (r *FleetUnitRepository) DeleteUnitOwnership(ctx context.Context, unitID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		DELETE FROM fleet_unit_ownership
 		WHERE unit_id = %s AND organization_id = %s
 	`, r.placeholder(1), r.placeholder(2))
-	if _, err := database.Exec(r.db, query, unitID, orgID); err != nil {
+	if _, err := t.Exec(query, unitID, orgID); err != nil {
 		return err
 	}
 	return nil
 }
+
+func (r *FleetUnitRepository) UnitExists(ctx context.Context, unitID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM fleet_units WHERE unit_id = %s AND organization_id = %s`, r.placeholder(1), r.placeholder(2))
+	var total int64
+	if err := t.QueryRow(query, unitID, orgID).Scan(&total); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// GarageExists reports whether an active garage belongs to the organization
+func (r *FleetUnitRepository) GarageExists(ctx context.Context, garageID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM garage WHERE garage_id = %s AND organization_id = %s AND COALESCE(status, 1) = 1`, r.placeholder(1), r.placeholder(2))
+	var total int64
+	if err := t.QueryRow(query, garageID, orgID).Scan(&total); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// CountItems counts how many of the distinct itemIDs are inventory items of
+// the organization
+func (r *FleetUnitRepository) CountItems(ctx context.Context, itemIDs []string) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
+	if len(itemIDs) == 0 {
+		return 0, nil
+	}
+	args := []interface{}{orgID}
+	placeholders := make([]string, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		args = append(args, id)
+		placeholders = append(placeholders, r.placeholder(len(args)))
+	}
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM inventory_items WHERE organization_id = %s AND item_id IN (%s)`, r.placeholder(1), strings.Join(placeholders, ", "))
+	var total int
+	if err := t.QueryRow(query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *FleetUnitRepository) CreateOdometerReading(ctx context.Context, userID, unitID string, odometerKm int64, recordedAt time.Time, notes string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
+	readingID := uuid.New().String()
+	query := fmt.Sprintf(`
+		INSERT INTO fleet_unit_odometer
+			(reading_id, unit_id, organization_id, odometer_km, recorded_at, notes, created_by, created_at)
+		VALUES
+			(%s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8))
+	if _, err := t.Exec(query, readingID, unitID, orgID, odometerKm, recordedAt, notes, userID, time.Now()); err != nil {
+		return "", err
+	}
+	return readingID, nil
+}
+
+func (r *FleetUnitRepository) LatestOdometer(ctx context.Context, unitID string) (int64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		SELECT COALESCE(MAX(odometer_km), 0)
+		FROM fleet_unit_odometer
+		WHERE unit_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2))
+	var km int64
+	if err := t.QueryRow(query, unitID, orgID).Scan(&km); err != nil {
+		return 0, err
+	}
+	return km, nil
+}
+
+func (r *FleetUnitRepository) ListOdometerReadings(ctx context.Context, unitID string) ([]model.FleetUnitOdometerReading, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		SELECT reading_id, unit_id, odometer_km, recorded_at, COALESCE(notes, ''), COALESCE(CAST(created_by AS CHAR(36)), '')
+		FROM fleet_unit_odometer
+		WHERE unit_id = %s AND organization_id = %s
+		ORDER BY recorded_at DESC
+	`, r.placeholder(1), r.placeholder(2))
+	rows, err := t.Query(query, unitID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.FleetUnitOdometerReading, 0)
+	for rows.Next() {
+		var it model.FleetUnitOdometerReading
+		var recordedAt time.Time
+		if err := rows.Scan(&it.ReadingID, &it.UnitID, &it.OdometerKm, &recordedAt, &it.Notes, &it.CreatedBy); err != nil {
+			return nil, err
+		}
+		it.RecordedAt = recordedAt.Format("2006-01-02")
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+const selectFleetUnitServicePlan = `
+SELECT
+	sp.plan_id,
+	sp.unit_id,
+	COALESCE(fu.plate_number, '') AS plate_number,
+	sp.service_type,
+	COALESCE(sp.interval_km, 0) AS interval_km,
+	COALESCE(sp.interval_days, 0) AS interval_days,
+	COALESCE(sp.last_service_km, 0) AS last_service_km,
+	sp.last_service_date,
+	COALESCE(sp.next_due_km, 0) AS next_due_km,
+	sp.next_due_date,
+	COALESCE(sp.notes, '') AS notes,
+	COALESCE((SELECT MAX(o.odometer_km) FROM fleet_unit_odometer o WHERE o.unit_id = sp.unit_id AND o.organization_id = sp.organization_id), 0) AS current_km
+FROM fleet_unit_service_plans sp
+LEFT JOIN fleet_units fu ON fu.unit_id = sp.unit_id AND fu.organization_id = sp.organization_id
+`
+
+func (r *FleetUnitRepository) scanServicePlans(rows *sql.Rows) ([]model.FleetUnitServicePlan, error) {
+	out := make([]model.FleetUnitServicePlan, 0)
+	for rows.Next() {
+		var it model.FleetUnitServicePlan
+		var lastServiceDate, nextDueDate sql.NullTime
+		if err := rows.Scan(
+			&it.PlanID,
+			&it.UnitID,
+			&it.PlateNumber,
+			&it.ServiceType,
+			&it.IntervalKm,
+			&it.IntervalDays,
+			&it.LastServiceKm,
+			&lastServiceDate,
+			&it.NextDueKm,
+			&nextDueDate,
+			&it.Notes,
+			&it.CurrentKm,
+		); err != nil {
+			return nil, err
+		}
+		if lastServiceDate.Valid {
+			it.LastServiceDate = lastServiceDate.Time.Format("2006-01-02")
+		}
+		if nextDueDate.Valid {
+			it.NextDueDate = nextDueDate.Time.Format("2006-01-02")
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *FleetUnitRepository) ListServicePlans(ctx context.Context, unitID string) ([]model.FleetUnitServicePlan, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	args := []interface{}{orgID}
+	query := selectFleetUnitServicePlan + " WHERE sp.organization_id = " + r.placeholder(1) + " AND COALESCE(sp.status, 1) = 1"
+	if strings.TrimSpace(unitID) != "" {
+		query += " AND sp.unit_id = " + r.placeholder(2)
+		args = append(args, unitID)
+	}
+	query += " ORDER BY sp.next_due_date ASC, sp.created_at ASC"
+
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanServicePlans(rows)
+}
+
+func (r *FleetUnitRepository) GetServicePlan(ctx context.Context, planID string) (*model.FleetUnitServicePlan, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	query := selectFleetUnitServicePlan + " WHERE sp.organization_id = " + r.placeholder(1) + " AND sp.plan_id = " + r.placeholder(2) + " AND COALESCE(sp.status, 1) = 1"
+	rows, err := t.Query(query, orgID, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	plans, err := r.scanServicePlans(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &plans[0], nil
+}
+
+func (r *FleetUnitRepository) CreateServicePlan(ctx context.Context, req *model.FleetUnitServicePlanRequest, nextDueKm int64, nextDueDate *time.Time) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	planID := uuid.New().String()
+	var lastServiceDate interface{}
+	if !req.LastServiceAt.IsZero() {
+		lastServiceDate = req.LastServiceAt
+	}
+	var nextDue interface{}
+	if nextDueDate != nil {
+		nextDue = *nextDueDate
+	}
+	now := time.Now()
+	query := fmt.Sprintf(`
+		INSERT INTO fleet_unit_service_plans
+			(plan_id, unit_id, organization_id, service_type, interval_km, interval_days, last_service_km, last_service_date,
+			 next_due_km, next_due_date, notes, status, created_by, created_at, updated_by, updated_at)
+		VALUES
+			(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 1, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8),
+		r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12), r.placeholder(13), r.placeholder(14), r.placeholder(15))
+	_, err = t.Exec(query,
+		planID,
+		req.UnitID,
+		req.OrganizationID,
+		req.ServiceType,
+		req.IntervalKm,
+		req.IntervalDays,
+		req.LastServiceKm,
+		lastServiceDate,
+		nextDueKm,
+		nextDue,
+		req.Notes,
+		req.UserID,
+		now,
+		req.UserID,
+		now,
+	)
+	if err != nil {
+		return "", err
+	}
+	return planID, nil
+}
+
+func (r *FleetUnitRepository) UpdateServicePlan(ctx context.Context, req *model.FleetUnitServicePlanRequest, nextDueKm int64, nextDueDate *time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	var lastServiceDate interface{}
+	if !req.LastServiceAt.IsZero() {
+		lastServiceDate = req.LastServiceAt
+	}
+	var nextDue interface{}
+	if nextDueDate != nil {
+		nextDue = *nextDueDate
+	}
+	query := fmt.Sprintf(`
+		UPDATE fleet_unit_service_plans
+		SET service_type = %s, interval_km = %s, interval_days = %s, last_service_km = %s, last_service_date = %s,
+			next_due_km = %s, next_due_date = %s, notes = %s, updated_by = %s, updated_at = %s
+		WHERE plan_id = %s AND organization_id = %s AND COALESCE(status, 1) = 1
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12))
+	result, err := t.Exec(query,
+		req.ServiceType,
+		req.IntervalKm,
+		req.IntervalDays,
+		req.LastServiceKm,
+		lastServiceDate,
+		nextDueKm,
+		nextDue,
+		req.Notes,
+		req.UserID,
+		time.Now(),
+		req.PlanID,
+		req.OrganizationID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *FleetUnitRepository) DeleteServicePlan(ctx context.Context, userID, planID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		UPDATE fleet_unit_service_plans SET status = 0, updated_by = %s, updated_at = %s
+		WHERE plan_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	result, err := t.Exec(query, userID, time.Now(), planID, orgID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// HasOverlappingMaintenance reports whether the unit already has an open work order
+// (scheduled or in workshop) overlapping the given date range.
+func (r *FleetUnitRepository) HasOverlappingMaintenance(ctx context.Context, unitID string, startDate, endDate time.Time) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM fleet_unit_maintenance
+		WHERE organization_id = %s AND unit_id = %s
+		  AND status IN (%d, %d)
+		  AND start_date <= %s AND end_date >= %s
+	`, r.placeholder(1), r.placeholder(2), model.FleetUnitMaintenanceStatusScheduled, model.FleetUnitMaintenanceStatusInWorkshop, r.placeholder(3), r.placeholder(4))
+	var total int64
+	if err := t.QueryRow(query, orgID, unitID, endDate, startDate).Scan(&total); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+func (r *FleetUnitRepository) CreateMaintenance(ctx context.Context, req *model.FleetUnitMaintenanceCreateRequest, status int) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	rollback := true
+	defer func() {
+		if rollback {
+			_ = tx.Rollback()
+		}
+	}()
+
+	maintenanceID := uuid.New().String()
+	now := time.Now()
+	var planID interface{}
+	if strings.TrimSpace(req.PlanID) != "" {
+		planID = strings.TrimSpace(req.PlanID)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO fleet_unit_maintenance
+			(maintenance_id, unit_id, organization_id, garage_id, plan_id, service_type, description, odometer_km,
+			 start_date, end_date, status, parts_cost, labor_cost, created_by, created_at, updated_by, updated_at)
+		VALUES
+			(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 0, 0, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8),
+		r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12), r.placeholder(13), r.placeholder(14), r.placeholder(15))
+	if _, err := tx.Exec(query,
+		maintenanceID,
+		req.UnitID,
+		req.OrganizationID,
+		req.GarageID,
+		planID,
+		req.ServiceType,
+		req.Description,
+		req.OdometerKm,
+		req.StartAt,
+		req.EndAt,
+		status,
+		req.UserID,
+		now,
+		req.UserID,
+		now,
+	); err != nil {
+		return "", err
+	}
+
+	partQuery := fmt.Sprintf(`
+		INSERT INTO fleet_unit_maintenance_parts (maintenance_id, item_id, quantity, price, organization_id, created_at)
+		VALUES (%s, %s, %s, 0, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5))
+	for _, part := range req.Parts {
+		if _, err := tx.Exec(partQuery, maintenanceID, part.ItemID, part.Quantity, req.OrganizationID, now); err != nil {
+			return "", err
+		}
+	}
+
+	rollback = false
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return maintenanceID, nil
+}
+
+const selectFleetUnitMaintenance = `
+SELECT
+	m.maintenance_id,
+	m.unit_id,
+	COALESCE(fu.plate_number, '') AS plate_number,
+	COALESCE(CAST(m.garage_id AS CHAR(36)), '') AS garage_id,
+	COALESCE(g.garage_name, '') AS garage_name,
+	COALESCE(CAST(m.plan_id AS CHAR(36)), '') AS plan_id,
+	COALESCE(m.service_type, '') AS service_type,
+	COALESCE(m.description, '') AS description,
+	COALESCE(m.odometer_km, 0) AS odometer_km,
+	m.start_date,
+	m.end_date,
+	m.completed_date,
+	COALESCE(m.status, 0) AS status,
+	COALESCE(m.parts_cost, 0) AS parts_cost,
+	COALESCE(m.labor_cost, 0) AS labor_cost,
+	COALESCE(m.notes, '') AS notes,
+	COALESCE(CAST(m.created_by AS CHAR(36)), '') AS created_by,
+	m.created_at
+FROM fleet_unit_maintenance m
+LEFT JOIN fleet_units fu ON fu.unit_id = m.unit_id AND fu.organization_id = m.organization_id
+LEFT JOIN garage g ON g.garage_id = m.garage_id AND g.organization_id = m.organization_id
+`
+
+func (r *FleetUnitRepository) scanMaintenance(rows *sql.Rows) ([]model.FleetUnitMaintenance, error) {
+	out := make([]model.FleetUnitMaintenance, 0)
+	for rows.Next() {
+		var it model.FleetUnitMaintenance
+		var startDate, endDate, completedDate, createdAt sql.NullTime
+		if err := rows.Scan(
+			&it.MaintenanceID,
+			&it.UnitID,
+			&it.PlateNumber,
+			&it.GarageID,
+			&it.GarageName,
+			&it.PlanID,
+			&it.ServiceType,
+			&it.Description,
+			&it.OdometerKm,
+			&startDate,
+			&endDate,
+			&completedDate,
+			&it.Status,
+			&it.PartsCost,
+			&it.LaborCost,
+			&it.Notes,
+			&it.CreatedBy,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		if startDate.Valid {
+			it.StartDate = startDate.Time.Format("2006-01-02")
+		}
+		if endDate.Valid {
+			it.EndDate = endDate.Time.Format("2006-01-02")
+		}
+		if completedDate.Valid {
+			it.CompletedDate = completedDate.Time.Format("2006-01-02")
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.TotalCost = it.PartsCost + it.LaborCost
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *FleetUnitRepository) ListMaintenance(ctx context.Context, unitID string, status *int) ([]model.FleetUnitMaintenance, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	args := []interface{}{orgID}
+	query := selectFleetUnitMaintenance + " WHERE m.organization_id = " + r.placeholder(1)
+	pos := 2
+	if strings.TrimSpace(unitID) != "" {
+		query += " AND m.unit_id = " + r.placeholder(pos)
+		args = append(args, unitID)
+		pos++
+	}
+	if status != nil {
+		query += " AND m.status = " + r.placeholder(pos)
+		args = append(args, *status)
+	}
+	query += " ORDER BY m.start_date DESC, m.created_at DESC"
+
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanMaintenance(rows)
+}
+
+func (r *FleetUnitRepository) GetMaintenance(ctx context.Context, maintenanceID string) (*model.FleetUnitMaintenance, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	query := selectFleetUnitMaintenance + " WHERE m.organization_id = " + r.placeholder(1) + " AND m.maintenance_id = " + r.placeholder(2)
+	rows, err := t.Query(query, orgID, maintenanceID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.scanMaintenance(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	out := &items[0]
+
+	partQuery := fmt.Sprintf(`
+		SELECT mp.item_id, COALESCE(ii.item_name, ''), mp.quantity, COALESCE(mp.price, 0)
+		FROM fleet_unit_maintenance_parts mp
+		LEFT JOIN inventory_items ii ON ii.item_id = mp.item_id AND ii.organization_id = mp.organization_id
+		WHERE mp.maintenance_id = %s AND mp.organization_id = %s
+	`, r.placeholder(1), r.placeholder(2))
+	partRows, err := t.Query(partQuery, maintenanceID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer partRows.Close()
+	out.Parts = make([]model.FleetUnitMaintenancePart, 0)
+	for partRows.Next() {
+		var p model.FleetUnitMaintenancePart
+		if err := partRows.Scan(&p.ItemID, &p.ItemName, &p.Quantity, &p.Price); err != nil {
+			return nil, err
+		}
+		out.Parts = append(out.Parts, p)
+	}
+	if err := partRows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *FleetUnitRepository) UpdateMaintenanceStatus(ctx context.Context, userID, maintenanceID string, fromStatus []int, toStatus int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	in := make([]string, 0, len(fromStatus))
+	for _, st := range fromStatus {
+		in = append(in, strconv.Itoa(st))
+	}
+	query := fmt.Sprintf(`
+		UPDATE fleet_unit_maintenance SET status = %s, updated_by = %s, updated_at = %s
+		WHERE maintenance_id = %s AND organization_id = %s AND status IN (%s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), strings.Join(in, ","))
+	result, err := t.Exec(query, toStatus, userID, time.Now(), maintenanceID, orgID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CompleteMaintenance closes a work order: draws the parts from the garage stock
+// (movement type 2 / Item Keluar), stores the costs, records the odometer and rolls
+// the linked service plan forward when plan is not nil.
+func (r *FleetUnitRepository) CompleteMaintenance(ctx context.Context, userID string, m *model.FleetUnitMaintenance, req *model.FleetUnitMaintenanceCompleteRequest, completedAt time.Time, plan *model.FleetUnitServicePlan) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+
+	rollback := true
+	defer func() {
+		if rollback {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	stockQuery := fmt.Sprintf(`
+		SELECT COALESCE(ig.stock, 0), COALESCE(ii.item_price, 0)
+		FROM inventory_item_garage ig
+		INNER JOIN inventory_items ii ON ii.item_id = ig.item_id AND ii.organization_id = ig.organization_id
+		WHERE ig.item_id = %s AND ig.garage_id = %s AND ig.organization_id = %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3))
+	updateStockQuery := fmt.Sprintf(`
+		UPDATE inventory_item_garage SET stock = %s, updated_at = %s, updated_by = %s
+		WHERE item_id = %s AND garage_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6))
+	movementQuery := fmt.Sprintf(`
+		INSERT INTO inventory_movement (movement_id, item_id, garage_id, quantity, stock_before, stock_final, movement_type, notes, organization_id, created_at, created_by)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11))
+	partPriceQuery := fmt.Sprintf(`
+		UPDATE fleet_unit_maintenance_parts SET price = %s
+		WHERE maintenance_id = %s AND item_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+
+	partsCost := 0.0
+	for _, part := range m.Parts {
+		var currentStock int
+		var price float64
+		if err := tx.QueryRow(stockQuery, part.ItemID, m.GarageID, orgID).Scan(&currentStock, &price); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("insufficient stock for item %s", part.ItemID)
+			}
+			return err
+		}
+		if currentStock < part.Quantity {
+			return fmt.Errorf("insufficient stock for item %s", part.ItemID)
+		}
+		newStock := currentStock - part.Quantity
+		if _, err := tx.Exec(updateStockQuery, newStock, now, userID, part.ItemID, m.GarageID, orgID); err != nil {
+			return err
+		}
+		notes := fmt.Sprintf("Maintenance %s", m.PlateNumber)
+		if _, err := tx.Exec(movementQuery, uuid.New().String(), part.ItemID, m.GarageID, part.Quantity, currentStock, newStock, 2, notes, orgID, now, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(partPriceQuery, price, m.MaintenanceID, part.ItemID, orgID); err != nil {
+			return err
+		}
+		partsCost += price * float64(part.Quantity)
+	}
+
+	odometerKm := req.OdometerKm
+	if odometerKm <= 0 {
+		odometerKm = m.OdometerKm
+	}
+	completeQuery := fmt.Sprintf(`
+		UPDATE fleet_unit_maintenance
+		SET status = %s, completed_date = %s, odometer_km = %s, parts_cost = %s, labor_cost = %s, notes = %s, updated_by = %s, updated_at = %s
+		WHERE maintenance_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10))
+	if _, err := tx.Exec(completeQuery, model.FleetUnitMaintenanceStatusCompleted, completedAt, odometerKm, partsCost, req.LaborCost, req.Notes, userID, now, m.MaintenanceID, orgID); err != nil {
+		return err
+	}
+
+	if req.OdometerKm > 0 {
+		odoQuery := fmt.Sprintf(`
+			INSERT INTO fleet_unit_odometer
+				(reading_id, unit_id, organization_id, odometer_km, recorded_at, notes, created_by, created_at)
+			VALUES
+				(%s, %s, %s, %s, %s, %s, %s, %s)
+		`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8))
+		if _, err := tx.Exec(odoQuery, uuid.New().String(), m.UnitID, orgID, req.OdometerKm, completedAt, "Maintenance", userID, now); err != nil {
+			return err
+		}
+	}
+
+	if plan != nil {
+		var nextDue interface{}
+		if plan.NextDueDate != "" {
+			if t, err := time.Parse("2006-01-02", plan.NextDueDate); err == nil {
+				nextDue = t
+			}
+		}
+		planQuery := fmt.Sprintf(`
+			UPDATE fleet_unit_service_plans
+			SET last_service_km = %s, last_service_date = %s, next_due_km = %s, next_due_date = %s, updated_by = %s, updated_at = %s
+			WHERE plan_id = %s AND organization_id = %s
+		`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7), r.placeholder(8))
+		if _, err := tx.Exec(planQuery, plan.LastServiceKm, completedAt, plan.NextDueKm, nextDue, userID, now, plan.PlanID, orgID); err != nil {
+			return err
+		}
+	}
+
+	rollback = false
+	return tx.Commit()
+}
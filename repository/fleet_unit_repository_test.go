@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestFleetUnitRepositoryIsTenantScoped(t *testing.T) {
+	r := NewFleetUnitRepository(openTenantFake(t), "postgres")
+	now := time.Now()
+	checkTenantIsolation(t, tenantCalls{
+		"FindExistingVehicleIDs": func(ctx context.Context) error {
+			_, err := r.FindExistingVehicleIDs(ctx, []string{"B 1234 CD"})
+			return err
+		},
+		"FindExistingPlateNumbers": func(ctx context.Context) error {
+			_, err := r.FindExistingPlateNumbers(ctx, []string{"B 1234 CD"})
+			return err
+		},
+		"GetFleetPickupCityIDs": func(ctx context.Context) error { _, err := r.GetFleetPickupCityIDs(ctx, fleetOfB); return err },
+		"List":                  func(ctx context.Context) error { _, err := r.List(ctx, fleetOfB, "", "bus"); return err },
+		"ListByOrder":           func(ctx context.Context) error { _, err := r.List(ctx, "", orderOfB, ""); return err },
+		"Detail":                func(ctx context.Context) error { _, err := r.Detail(ctx, unitOfB); return err },
+		"GetOwnershipInformation": func(ctx context.Context) error {
+			_, err := r.GetOwnershipInformation(ctx, unitOfB)
+			return err
+		},
+		"SetUnitOwnership": func(ctx context.Context) error { return r.SetUnitOwnership(ctx, unitOfB, partnerOfB, "user") },
+		"UnitOrderHistory": func(ctx context.Context) error {
+			_, err := r.UnitOrderHistory(ctx, unitOfB, "2024-01-01", "2024-01-31")
+			return err
+		},
+		"UnitRating":           func(ctx context.Context) error { _, err := r.UnitRating(ctx, unitOfB); return err },
+		"UnitReviews":          func(ctx context.Context) error { _, err := r.UnitReviews(ctx, unitOfB); return err },
+		"UnitTotalSchedules":   func(ctx context.Context) error { _, err := r.UnitTotalSchedules(ctx, unitOfB); return err },
+		"UnitLatestSchedule":   func(ctx context.Context) error { _, err := r.UnitLatestSchedule(ctx, unitOfB, now); return err },
+		"UnitUpcomingSchedule": func(ctx context.Context) error { _, err := r.UnitUpcomingSchedule(ctx, unitOfB, now); return err },
+		"GetOrderDestinationCityIDs": func(ctx context.Context) error {
+			_, err := r.GetOrderDestinationCityIDs(ctx, []string{orderOfB})
+			return err
+		},
+		"GetUnitRevenue": func(ctx context.Context) error {
+			_, err := r.GetUnitRevenue(ctx, unitOfB, "2024-01-01", "2024-01-31")
+			return err
+		},
+		"ListUnitRevenueHistory": func(ctx context.Context) error {
+			_, err := r.ListUnitRevenueHistory(ctx, unitOfB, "2024-01-01", "2024-01-31")
+			return err
+		},
+		"ListUnitExpenses": func(ctx context.Context) error {
+			_, err := r.ListUnitExpenses(ctx, unitOfB, now.AddDate(0, -1, 0), now)
+			return err
+		},
+		"UpdateOwnerInformation": func(ctx context.Context) error {
+			return r.UpdateOwnerInformation(ctx, unitOfB, partnerOfB, "Partner", "0812", "Pic")
+		},
+		"DeleteUnitOwnership": func(ctx context.Context) error { return r.DeleteUnitOwnership(ctx, unitOfB) },
+		"UnitExists":          func(ctx context.Context) error { _, err := r.UnitExists(ctx, unitOfB); return err },
+		"GarageExists":        func(ctx context.Context) error { _, err := r.GarageExists(ctx, garageOfB); return err },
+		"CountItems":          func(ctx context.Context) error { _, err := r.CountItems(ctx, []string{itemOfB}); return err },
+		"LatestOdometer":      func(ctx context.Context) error { _, err := r.LatestOdometer(ctx, unitOfB); return err },
+		"ListOdometerReadings": func(ctx context.Context) error {
+			_, err := r.ListOdometerReadings(ctx, unitOfB)
+			return err
+		},
+		"ListServicePlans":  func(ctx context.Context) error { _, err := r.ListServicePlans(ctx, unitOfB); return err },
+		"GetServicePlan":    func(ctx context.Context) error { _, err := r.GetServicePlan(ctx, planOfB); return err },
+		"DeleteServicePlan": func(ctx context.Context) error { return r.DeleteServicePlan(ctx, "user", planOfB) },
+		"HasOverlappingMaintenance": func(ctx context.Context) error {
+			_, err := r.HasOverlappingMaintenance(ctx, unitOfB, now, now.AddDate(0, 0, 1))
+			return err
+		},
+		"ListMaintenance": func(ctx context.Context) error { _, err := r.ListMaintenance(ctx, unitOfB, nil); return err },
+		"GetMaintenance":  func(ctx context.Context) error { _, err := r.GetMaintenance(ctx, workOfB); return err },
+		"UpdateMaintenanceStatus": func(ctx context.Context) error {
+			return r.UpdateMaintenanceStatus(ctx, "user", workOfB, []int{model.FleetUnitMaintenanceStatusScheduled}, model.FleetUnitMaintenanceStatusInWorkshop)
+		},
+		"CompleteMaintenance": func(ctx context.Context) error {
+			m := &model.FleetUnitMaintenance{MaintenanceID: workOfB, UnitID: unitOfB}
+			return r.CompleteMaintenance(ctx, "user", m, &model.FleetUnitMaintenanceCompleteRequest{MaintenanceID: workOfB}, now, nil)
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error {
+			_, err := r.Create(ctxA, &model.FleetUnitCreateRequest{OrganizationID: tenantB, FleetID: fleetOfB, PlateNumber: "B 1234 CD"})
+			return err
+		},
+		"Update": func() error {
+			return r.Update(ctxA, &model.FleetUnitUpdateRequest{OrganizationID: tenantB, UnitID: unitOfB})
+		},
+		"CreateServicePlan": func() error {
+			_, err := r.CreateServicePlan(ctxA, &model.FleetUnitServicePlanRequest{OrganizationID: tenantB, UnitID: unitOfB}, 0, nil)
+			return err
+		},
+		"UpdateServicePlan": func() error {
+			return r.UpdateServicePlan(ctxA, &model.FleetUnitServicePlanRequest{OrganizationID: tenantB, PlanID: planOfB}, 0, nil)
+		},
+		"CreateMaintenance": func() error {
+			_, err := r.CreateMaintenance(ctxA, &model.FleetUnitMaintenanceCreateRequest{OrganizationID: tenantB, UnitID: unitOfB, GarageID: garageOfB}, model.FleetUnitMaintenanceStatusScheduled)
+			return err
+		},
+	})
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -30,7 +31,12 @@ func (r *GarageRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-func (r *GarageRepository) GetAll(organizationID, itemID string) ([]model.Garage, error) {
+func (r *GarageRepository) GetAll(ctx context.Context, itemID string) ([]model.Garage, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	var query string
 	var args []interface{}
 
@@ -39,7 +45,7 @@ func (r *GarageRepository) GetAll(organizationID, itemID string) ([]model.Garage
 			SELECT g.garage_id, g.organization_id, g.garage_name, g.garage_address, g.garage_city,
 			       g.created_at, g.created_by, g.updated_at, g.updated_by
 			FROM garage g
-			INNER JOIN inventory_item_garage ig ON g.garage_id = ig.garage_id
+			INNER JOIN inventory_item_garage ig ON g.garage_id = ig.garage_id AND ig.organization_id = g.organization_id
 			WHERE g.organization_id = %s AND ig.item_id = %s 
 			ORDER BY garage_name
 		`, r.getPlaceholder(1), r.getPlaceholder(2))
@@ -54,9 +60,7 @@ func (r *GarageRepository) GetAll(organizationID, itemID string) ([]model.Garage
 		`, r.getPlaceholder(1))
 		args = append(args, organizationID)
 	}
-	fmt.Println("query ... ", query)
-
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +93,11 @@ func (r *GarageRepository) GetAll(organizationID, itemID string) ([]model.Garage
 	return garages, nil
 }
 
-func (r *GarageRepository) GetByID(garageID, organizationID string) (*model.Garage, error) {
+func (r *GarageRepository) GetByID(ctx context.Context, garageID string) (*model.Garage, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`
 		SELECT garage_id, organization_id, garage_name, garage_address, garage_city,
 		       created_at, created_by, updated_at, updated_by
@@ -98,7 +106,7 @@ func (r *GarageRepository) GetByID(garageID, organizationID string) (*model.Gara
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var g model.Garage
-	err := database.QueryRow(r.db, query, garageID, organizationID).Scan(
+	err = t.QueryRow(query, garageID, t.OrganizationID()).Scan(
 		&g.GarageID,
 		&g.OrganizationID,
 		&g.GarageName,
@@ -119,7 +127,11 @@ func (r *GarageRepository) GetByID(garageID, organizationID string) (*model.Gara
 	return &g, nil
 }
 
-func (r *GarageRepository) Create(garage *model.Garage) error {
+func (r *GarageRepository) Create(ctx context.Context, garage *model.Garage) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	garage.GarageID = uuid.New().String()
 	now := time.Now()
 	garage.CreatedAt = now
@@ -135,7 +147,7 @@ func (r *GarageRepository) Create(garage *model.Garage) error {
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		garage.OrganizationID,
 		garage.GarageID,
 		garage.GarageName,
@@ -150,7 +162,11 @@ func (r *GarageRepository) Create(garage *model.Garage) error {
 	return err
 }
 
-func (r *GarageRepository) Update(garageID, organizationID string, updates map[string]interface{}) error {
+func (r *GarageRepository) Update(ctx context.Context, garageID string, updates map[string]interface{}) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	now := time.Now()
 	updates["updated_at"] = now
 
@@ -170,17 +186,21 @@ func (r *GarageRepository) Update(garageID, organizationID string, updates map[s
 		r.getPlaceholder(pos+1),
 	)
 
-	args = append(args, garageID, organizationID)
+	args = append(args, garageID, t.OrganizationID())
 
-	_, err := database.Exec(r.db, query, args...)
+	_, err = t.Exec(query, args...)
 	return err
 }
 
-func (r *GarageRepository) Delete(garageID, organizationID string) error {
+func (r *GarageRepository) Delete(ctx context.Context, garageID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	query := fmt.Sprintf("UPDATE garage SET status = 0 WHERE garage_id = %s AND organization_id = %s",
 		r.getPlaceholder(1), r.getPlaceholder(2))
 
-	result, err := database.Exec(r.db, query, garageID, organizationID)
+	result, err := t.Exec(query, garageID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
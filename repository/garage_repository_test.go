@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+)
+
+func TestGarageRepositoryIsTenantScoped(t *testing.T) {
+	r := NewGarageRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"GetAll":         func(ctx context.Context) error { _, err := r.GetAll(ctx, ""); return err },
+		"GetAllWithItem": func(ctx context.Context) error { _, err := r.GetAll(ctx, itemOfB); return err },
+		"GetByID":        func(ctx context.Context) error { _, err := r.GetByID(ctx, garageOfB); return err },
+		"Update": func(ctx context.Context) error {
+			return r.Update(ctx, garageOfB, map[string]interface{}{"garage_name": "taken"})
+		},
+		"Delete": func(ctx context.Context) error { return r.Delete(ctx, garageOfB) },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error { return r.Create(ctxA, &model.Garage{OrganizationID: tenantB, GarageName: "depot"}) },
+	})
+}
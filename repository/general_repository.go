@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -27,14 +28,14 @@ func (r *GeneralRepository) getPlaceholder(pos int) string {
 }
 
 // GetBankList retrieves bank list
-func (r *GeneralRepository) GetBankList() ([]model.Bank, error) {
+func (r *GeneralRepository) GetBankList(ctx context.Context) ([]model.Bank, error) {
 	query := `
         SELECT code, name
         FROM bank_list
         ORDER BY name ASC
     `
 
-	rows, err := database.Query(r.db, query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +57,11 @@ func (r *GeneralRepository) GetBankList() ([]model.Bank, error) {
 	return banks, nil
 }
 
-func (r *GeneralRepository) GetPreferenceCities(organizationID string, cityID *int, serviceType *int) ([]model.PreferenceCity, error) {
+func (r *GeneralRepository) GetPreferenceCities(ctx context.Context, cityID *int, serviceType *int) ([]model.PreferenceCity, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`
 		SELECT DISTINCT pc.preference_id, pc.city_id, pc.minimal_day, pc.organization_id, pc.created_at, pc.created_by
 		FROM preference_cities pc
@@ -66,7 +71,7 @@ func (r *GeneralRepository) GetPreferenceCities(organizationID string, cityID *i
 		WHERE pc.organization_id = %s
 	`, r.getPlaceholder(1))
 
-	args := []interface{}{organizationID}
+	args := []interface{}{t.OrganizationID()}
 	argPos := 2
 
 	if cityID != nil {
@@ -81,7 +86,7 @@ func (r *GeneralRepository) GetPreferenceCities(organizationID string, cityID *i
 		argPos++
 	}
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -110,14 +115,18 @@ func (r *GeneralRepository) GetPreferenceCities(organizationID string, cityID *i
 	return prefs, nil
 }
 
-func (r *GeneralRepository) GetPreferenceCityTypesByCityIDAndOrganizationID(cityID int, organizationID string) ([]int, error) {
+func (r *GeneralRepository) GetPreferenceCityTypesByCityID(ctx context.Context, cityID int) ([]int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`
 		SELECT service_type
 		FROM preference_city_types
 		WHERE city_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, cityID, organizationID)
+	rows, err := t.Query(query, cityID, t.OrganizationID())
 	if err != nil {
 		return []int{}, nil
 	}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGeneralRepositoryIsTenantScoped(t *testing.T) {
+	r := NewGeneralRepository(openTenantFake(t), "postgres")
+	cityID, serviceType := 3171, 1
+	checkTenantIsolation(t, tenantCalls{
+		"GetPreferenceCities": func(ctx context.Context) error {
+			_, err := r.GetPreferenceCities(ctx, &cityID, &serviceType)
+			return err
+		},
+		"GetPreferenceCityTypesByCityID": func(ctx context.Context) error {
+			_, err := r.GetPreferenceCityTypesByCityID(ctx, cityID)
+			return err
+		},
+	})
+}
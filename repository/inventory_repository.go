@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -27,8 +28,36 @@ func NewInventoryRepository(db *sql.DB, driver string) *InventoryRepository {
 	}
 }
 
-func (r *InventoryRepository) GenerateItemSKU(organizationID string) (string, error) {
-	return utils.GenerateItemSKU(r.db, r.driver, organizationID)
+func (r *InventoryRepository) GenerateItemSKU(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	return utils.GenerateItemSKU(t, r.driver)
+}
+
+func (r *InventoryRepository) GenerateRequestNumber(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	return utils.GenerateRequestNumber(t, r.driver)
+}
+
+func (r *InventoryRepository) GeneratePurchaseOrderID(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	return utils.GeneratePurchaseOrderID(t, r.driver)
+}
+
+func (r *InventoryRepository) GenerateInvoiceNumber(ctx context.Context, orderType int, now time.Time) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	return utils.GenerateTenantInvoiceNumber(t, r.driver, orderType, now)
 }
 
 func (r *InventoryRepository) getPlaceholder(pos int) string {
@@ -38,10 +67,14 @@ func (r *InventoryRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-func (r *InventoryRepository) GetOrganizationCodeByOrgID(orgID string) (string, error) {
+func (r *InventoryRepository) GetOrganizationCode(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
 	query := fmt.Sprintf("SELECT organization_code FROM organizations WHERE organization_id = %s", r.getPlaceholder(1))
 	var code string
-	err := database.QueryRow(r.db, query, orgID).Scan(&code)
+	err = t.QueryRow(query, t.OrganizationID()).Scan(&code)
 	if err == sql.ErrNoRows {
 		return "", sql.ErrNoRows
 	}
@@ -69,17 +102,22 @@ func (r *InventoryRepository) ensureLocationsLoaded() {
 	}
 }
 
-func (r *InventoryRepository) GetAllItems(organizationID string, itemCategory int, status string) ([]model.InventoryItemWithLabel, error) {
+func (r *InventoryRepository) GetAllItems(ctx context.Context, itemCategory int, status string) ([]model.InventoryItemWithLabel, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	var query string
 	var args []interface{}
-	args = append(args, organizationID)
+	args = append(args, t.OrganizationID())
 	pos := 2
 
 	query = fmt.Sprintf(`
 			SELECT i.item_id, i.item_sku, i.item_name, i.item_uom, i.item_category, i.status, SUM(ig.stock) AS total_stock, STRING_AGG(g.garage_name, ', ') AS garage_names, MAX(ig.created_at) AS created_at, MAX(ig.updated_at) AS updated_at
 			FROM inventory_items i
-			LEFT JOIN inventory_item_garage ig ON ig.item_id = i.item_id
-			LEFT JOIN garage g ON g.garage_id = ig.garage_id
+			LEFT JOIN inventory_item_garage ig ON ig.item_id = i.item_id AND ig.organization_id = i.organization_id
+			LEFT JOIN garage g ON g.garage_id = ig.garage_id AND g.organization_id = i.organization_id
 			WHERE i.organization_id = %s AND i.status = 1
 		`, r.getPlaceholder(1))
 
@@ -95,7 +133,7 @@ func (r *InventoryRepository) GetAllItems(organizationID string, itemCategory in
 
 	query += " GROUP BY i.item_id, i.item_sku, i.item_name, i.item_uom, i.item_category, i.status"
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +185,12 @@ func (r *InventoryRepository) GetAllItems(organizationID string, itemCategory in
 	return items, nil
 }
 
-func (r *InventoryRepository) GetItemByID(itemID, organizationID string) (*model.InventoryItem, error) {
+func (r *InventoryRepository) GetItemByID(ctx context.Context, itemID string) (*model.InventoryItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT item_id, organization_id, item_name, item_uom, item_category, stock, status,
 		       created_at, created_by, updated_at, updated_by
@@ -156,7 +199,7 @@ func (r *InventoryRepository) GetItemByID(itemID, organizationID string) (*model
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var item model.InventoryItem
-	err := database.QueryRow(r.db, query, itemID, organizationID).Scan(
+	err = t.QueryRow(query, itemID, t.OrganizationID()).Scan(
 		&item.ItemID,
 		&item.OrganizationID,
 		&item.ItemName,
@@ -178,13 +221,18 @@ func (r *InventoryRepository) GetItemByID(itemID, organizationID string) (*model
 	return &item, nil
 }
 
-func (r *InventoryRepository) CreateItem(item *model.InventoryItem) error {
+func (r *InventoryRepository) CreateItem(ctx context.Context, item *model.InventoryItem) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	item.ItemID = uuid.New().String()
 	now := time.Now()
 	item.CreatedAt = now
 	item.UpdatedAt = now
 
-	sku, err := utils.GenerateItemSKU(r.db, r.driver, item.OrganizationID)
+	sku, err := utils.GenerateItemSKU(t, r.driver)
 	if err != nil {
 		return err
 	}
@@ -201,7 +249,7 @@ func (r *InventoryRepository) CreateItem(item *model.InventoryItem) error {
 		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12),
 	)
 
-	_, err = database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		item.ItemID,
 		item.ItemSKU,
 		item.OrganizationID,
@@ -218,14 +266,19 @@ func (r *InventoryRepository) CreateItem(item *model.InventoryItem) error {
 	return err
 }
 
-func (r *InventoryRepository) CreateItemWithoutStock(item *model.InventoryItem) error {
+func (r *InventoryRepository) CreateItemWithoutStock(ctx context.Context, item *model.InventoryItem) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	item.ItemID = uuid.New().String()
 	now := time.Now()
 	item.CreatedAt = now
 	item.UpdatedAt = now
 	item.Stock = 0
 
-	sku, err := utils.GenerateItemSKU(r.db, r.driver, item.OrganizationID)
+	sku, err := utils.GenerateItemSKU(t, r.driver)
 	if err != nil {
 		return err
 	}
@@ -242,7 +295,7 @@ func (r *InventoryRepository) CreateItemWithoutStock(item *model.InventoryItem)
 		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12),
 	)
 
-	_, err = database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		item.ItemID,
 		item.ItemSKU,
 		item.OrganizationID,
@@ -259,7 +312,12 @@ func (r *InventoryRepository) CreateItemWithoutStock(item *model.InventoryItem)
 	return err
 }
 
-func (r *InventoryRepository) UpdateItem(itemID, organizationID, updatedBy string, updates map[string]interface{}) error {
+func (r *InventoryRepository) UpdateItem(ctx context.Context, itemID, updatedBy string, updates map[string]interface{}) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	updates["updated_at"] = now
 	updates["updated_by"] = updatedBy
@@ -280,9 +338,9 @@ func (r *InventoryRepository) UpdateItem(itemID, organizationID, updatedBy strin
 		r.getPlaceholder(pos+1),
 	)
 
-	args = append(args, itemID, organizationID)
+	args = append(args, itemID, t.OrganizationID())
 
-	result, err := database.Exec(r.db, query, args...)
+	result, err := t.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -299,11 +357,16 @@ func (r *InventoryRepository) UpdateItem(itemID, organizationID, updatedBy strin
 	return nil
 }
 
-func (r *InventoryRepository) DeleteItem(itemID, organizationID string) error {
+func (r *InventoryRepository) DeleteItem(ctx context.Context, itemID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf("UPDATE inventory_items SET status = 0 WHERE item_id = %s AND organization_id = %s",
 		r.getPlaceholder(1), r.getPlaceholder(2))
 
-	result, err := database.Exec(r.db, query, itemID, organizationID)
+	result, err := t.Exec(query, itemID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
@@ -320,20 +383,25 @@ func (r *InventoryRepository) DeleteItem(itemID, organizationID string) error {
 	return nil
 }
 
-func (r *InventoryRepository) GetRequestList(organizationID string) ([]model.InventoryRequestWithLabel, error) {
+func (r *InventoryRepository) GetRequestList(ctx context.Context) ([]model.InventoryRequestWithLabel, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT ir.request_id, ir.item_category, i.item_name, i.item_uom, i.item_sku, 
 		g.garage_name, ir.quantity, ir.status, io.status as order_status, e.fullname as employee_name
-		FROM inventory_request ir INNER JOIN inventory_items i ON i.item_id = ir.item_id
-		INNER JOIN garage g ON g.garage_id = ir.garage_id
-		LEFT JOIN employee e ON e.uuid = ir.employee_id
-		LEFT JOIN inventory_orders io ON io.request_id = ir.request_id
+		FROM inventory_request ir INNER JOIN inventory_items i ON i.item_id = ir.item_id AND i.organization_id = ir.organization_id
+		INNER JOIN garage g ON g.garage_id = ir.garage_id AND g.organization_id = ir.organization_id
+		LEFT JOIN employee e ON e.uuid = ir.employee_id AND e.organization_id = ir.organization_id
+		LEFT JOIN inventory_orders io ON io.request_id = ir.request_id AND io.organization_id = ir.organization_id
 		WHERE ir.organization_id = %s 
 		ORDER BY ir.created_at DESC
 	`
 	query = fmt.Sprintf(query, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, organizationID)
+	rows, err := t.Query(query, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -378,7 +446,12 @@ func (r *InventoryRepository) GetRequestList(organizationID string) ([]model.Inv
 	return items, nil
 }
 
-func (r *InventoryRepository) GetRequestByID(requestID, organizationID string) (*model.InventoryRequestWithLabel, error) {
+func (r *InventoryRepository) GetRequestByID(ctx context.Context, requestID string) (*model.InventoryRequestWithLabel, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT ir.request_id, ir.item_id, ir.garage_id, ir.organization_id, COALESCE(NULLIF(i.item_name, ''), ir.item_name) AS item_name, ir.item_category, i.item_sku, ir.quantity, ir.item_uom,
 			   g.garage_name, g.garage_city, ir.status as request_status,
@@ -387,15 +460,15 @@ func (r *InventoryRepository) GetRequestByID(requestID, organizationID string) (
 			   ir.created_at, uc.fullname as created_by, ir.approve_at, ir.approve_by, ir.updated_at, uu.fullname as updated_by,
 			   ir.received_at, er.fullname as received_by
 		FROM inventory_request ir
-		LEFT JOIN employee e ON e.uuid = ir.employee_id
+		LEFT JOIN employee e ON e.uuid = ir.employee_id AND e.organization_id = ir.organization_id
 		INNER JOIN users uc ON uc.user_id = ir.created_by
 		LEFT JOIN users uu ON uu.user_id = ir.updated_by
-		LEFT JOIN employee er ON er.uuid = ir.received_by
-		LEFT JOIN inventory_items i ON ir.item_id = i.item_id
-		LEFT JOIN garage g ON ir.garage_id = g.garage_id
-		LEFT JOIN inventory_orders io ON io.request_id = ir.request_id
+		LEFT JOIN employee er ON er.uuid = ir.received_by AND er.organization_id = ir.organization_id
+		LEFT JOIN inventory_items i ON ir.item_id = i.item_id AND i.organization_id = ir.organization_id
+		LEFT JOIN garage g ON ir.garage_id = g.garage_id AND g.organization_id = ir.organization_id
+		LEFT JOIN inventory_orders io ON io.request_id = ir.request_id AND io.organization_id = ir.organization_id
 		LEFT JOIN inventory_request_fleets if ON if.request_id = ir.request_id
-		LEFT JOIN fleet_units fu ON fu.unit_id = if.unit_id
+		LEFT JOIN fleet_units fu ON fu.unit_id = if.unit_id AND fu.organization_id = ir.organization_id
 		WHERE ir.request_id = %s AND ir.organization_id = %s
 	`
 	query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
@@ -417,7 +490,7 @@ func (r *InventoryRepository) GetRequestByID(requestID, organizationID string) (
 	var receivedAt sql.NullTime
 	var employeeName sql.NullString
 
-	err := database.QueryRow(r.db, query, requestID, organizationID).Scan(
+	err = t.QueryRow(query, requestID, t.OrganizationID()).Scan(
 		&item.RequestID,
 		&item.ItemID,
 		&item.GarageID,
@@ -531,19 +604,29 @@ func (r *InventoryRepository) GetRequestByID(requestID, organizationID string) (
 	return &item, nil
 }
 
-func (r *InventoryRepository) GetCurrentGarageItemStock(itemID, garageID, organizationID string) (int, error) {
+func (r *InventoryRepository) GetCurrentGarageItemStock(ctx context.Context, itemID, garageID string) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT stock FROM inventory_item_garage WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	var stock int
-	if err := database.QueryRow(r.db, query, itemID, garageID, organizationID).Scan(&stock); err != nil {
+	if err := t.QueryRow(query, itemID, garageID, t.OrganizationID()).Scan(&stock); err != nil {
 		return 0, err
 	}
 	return stock, nil
 }
 
-func (r *InventoryRepository) CreateRequest(request *model.InventoryRequest) error {
+func (r *InventoryRepository) CreateRequest(ctx context.Context, request *model.InventoryRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	request.CreatedAt = now
 	request.UpdatedAt = now
@@ -556,13 +639,7 @@ func (r *InventoryRepository) CreateRequest(request *model.InventoryRequest) err
 		r.getPlaceholder(13), r.getPlaceholder(14),
 	)
 	query := fmt.Sprintf("INSERT INTO inventory_request (%s) VALUES %s", columns, values)
-	fmt.Println("CreateRequest query:", query)                      // Debugging line
-	fmt.Println("CreateRequest request id:", request.RequestNumber) // Debugging line
-	fmt.Println("CreateRequest ItemID, ", request.ItemID)
-	fmt.Println("CreateRequest GarageID, ", request.GarageID)
-	fmt.Println("CreateRequest GarageID, ", request.GarageID)
-
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		request.RequestNumber,
 		request.ItemID,
 		request.ItemName,
@@ -582,43 +659,64 @@ func (r *InventoryRepository) CreateRequest(request *model.InventoryRequest) err
 	return err
 }
 
-func (r *InventoryRepository) CreateRequestFleet(requestNumber, unitID, createdBy string) error {
+// CreateRequestFleet links a unit to a request; the link table has no
+// organization of its own and follows the request
+func (r *InventoryRepository) CreateRequestFleet(ctx context.Context, requestNumber, unitID, createdBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	query := fmt.Sprintf(`
 		INSERT INTO inventory_request_fleets (request_id, unit_id, created_at, created_by)
 		VALUES (%s, %s, %s, %s)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	_, err := database.Exec(r.db, query, requestNumber, unitID, time.Now(), createdBy)
+	_, err = t.Exec(query, requestNumber, unitID, time.Now(), createdBy)
 	return err
 }
 
-func (r *InventoryRepository) UpdateRequestStatus(requestID, organizationID, updatedBy string, status int) error {
+func (r *InventoryRepository) UpdateRequestStatus(ctx context.Context, requestID, updatedBy string, status int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE inventory_request SET status = %s, updated_at = %s, updated_by = %s WHERE request_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
 
 	now := time.Now()
-	_, err := database.Exec(r.db, query, status, now, updatedBy, requestID, organizationID)
+	_, err = t.Exec(query, status, now, updatedBy, requestID, t.OrganizationID())
 	return err
 }
 
-func (r *InventoryRepository) UpdateRequestApprove(requestID, organizationID, updatedBy string, approveAt time.Time) error {
+func (r *InventoryRepository) UpdateRequestApprove(ctx context.Context, requestID, updatedBy string, approveAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE inventory_request SET status = 2, approve_at = %s, approve_by = %s, updated_at = %s, updated_by = %s WHERE request_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
 	now := time.Now()
-	_, err := database.Exec(r.db, query, approveAt, updatedBy, now, updatedBy, requestID, organizationID)
+	_, err = t.Exec(query, approveAt, updatedBy, now, updatedBy, requestID, t.OrganizationID())
 	return err
 }
 
-func (r *InventoryRepository) GetEmployeePhoneByUUID(employeeUUID string) (string, error) {
+func (r *InventoryRepository) GetEmployeePhoneByUUID(ctx context.Context, employeeUUID string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+
 	query := fmt.Sprintf(`
-		SELECT phone FROM employee WHERE uuid = %s LIMIT 1
-	`, r.getPlaceholder(1))
+		SELECT phone FROM employee WHERE uuid = %s AND organization_id = %s LIMIT 1
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var phone string
-	if err := database.QueryRow(r.db, query, employeeUUID).Scan(&phone); err != nil {
+	if err := t.QueryRow(query, employeeUUID, t.OrganizationID()).Scan(&phone); err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
 		}
@@ -627,7 +725,12 @@ func (r *InventoryRepository) GetEmployeePhoneByUUID(employeeUUID string) (strin
 	return phone, nil
 }
 
-func (r *InventoryRepository) GetRequestForApprove(requestID, organizationID string) (*model.InventoryRequest, error) {
+func (r *InventoryRepository) GetRequestForApprove(ctx context.Context, requestID string) (*model.InventoryRequest, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT ir.request_id, ir.item_id, ir.item_name, ir.garage_id, ir.quantity, ir.employee_id, ir.organization_id
 		FROM inventory_request ir
@@ -636,7 +739,7 @@ func (r *InventoryRepository) GetRequestForApprove(requestID, organizationID str
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var req model.InventoryRequest
-	err := database.QueryRow(r.db, query, requestID, organizationID).Scan(
+	err = t.QueryRow(query, requestID, t.OrganizationID()).Scan(
 		&req.RequestID,
 		&req.ItemID,
 		&req.ItemName,
@@ -654,13 +757,19 @@ func (r *InventoryRepository) GetRequestForApprove(requestID, organizationID str
 	return &req, nil
 }
 
-func (r *InventoryRepository) ApproveInventoryRequest(requestID, organizationID, itemID, employeeID, updatedBy string) error {
-	req, err := r.GetRequestForApprove(requestID, organizationID)
+func (r *InventoryRepository) ApproveInventoryRequest(ctx context.Context, requestID, itemID, employeeID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+
+	req, err := r.GetRequestForApprove(ctx, requestID)
 	if err != nil {
 		return err
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -676,10 +785,9 @@ func (r *InventoryRepository) ApproveInventoryRequest(requestID, organizationID,
 	quantity := req.Quantity
 
 	if req.ItemID != "" {
-		currentStock, stockErr := r.GetItemGarageStock(req.ItemID, req.GarageID, organizationID)
+		currentStock, stockErr := r.GetItemGarageStock(ctx, req.ItemID, req.GarageID)
 
 		if stockErr != nil && stockErr != sql.ErrNoRows {
-			fmt.Println("error get item garage stock ", stockErr)
 			return stockErr
 		}
 		if stockErr == sql.ErrNoRows {
@@ -689,22 +797,18 @@ func (r *InventoryRepository) ApproveInventoryRequest(requestID, organizationID,
 		if newStock < 0 {
 			newStock = 0
 		}
-		fmt.Println("check newStock here ... ", newStock)
 		stockQuery := fmt.Sprintf(`
 			UPDATE inventory_item_garage SET stock = %s, updated_at = %s, updated_by = %s
 			WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-		if _, err := database.TxExec(tx, stockQuery, newStock, now, updatedBy, req.ItemID, req.GarageID, organizationID); err != nil {
-			fmt.Println("error insert item_garage ", err)
+		if _, err := tx.Exec(stockQuery, newStock, now, updatedBy, req.ItemID, req.GarageID, organizationID); err != nil {
 			return err
 		}
-		fmt.Println("check movement here ... ")
 		movementQuery := fmt.Sprintf(`
 			INSERT INTO inventory_movement (movement_id, item_id, garage_id, quantity, stock_before, stock_final, movement_type, notes, organization_id, created_at, created_by)
 			VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11))
-		if _, err := database.TxExec(tx, movementQuery, uuid.New().String(), req.ItemID, req.GarageID, quantity, currentStock, newStock, 2, "Request approved", organizationID, now, updatedBy); err != nil {
-			fmt.Println("error insert movement ", err)
+		if _, err := tx.Exec(movementQuery, uuid.New().String(), req.ItemID, req.GarageID, quantity, currentStock, newStock, 2, "Request approved", organizationID, now, updatedBy); err != nil {
 			return err
 		}
 
@@ -712,9 +816,8 @@ func (r *InventoryRepository) ApproveInventoryRequest(requestID, organizationID,
 			UPDATE inventory_request SET item_id = %s, status = 1, approved_at = %s, approved_by = %s, updated_at = %s, updated_by = %s
 			WHERE request_id = %s AND organization_id = %s
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
-		_, err = database.TxExec(tx, updateQuery, req.ItemID, now, employeeID, now, updatedBy, requestID, organizationID)
+		_, err = tx.Exec(updateQuery, req.ItemID, now, employeeID, now, updatedBy, requestID, organizationID)
 		if err != nil {
-			fmt.Println("error update inventory_request ", err)
 			return err
 		}
 	} else {
@@ -722,7 +825,7 @@ func (r *InventoryRepository) ApproveInventoryRequest(requestID, organizationID,
 			UPDATE inventory_request SET status = 1, approved_at = %s, approved_by = %s, updated_at = %s, updated_by = %s
 			WHERE request_id = %s AND organization_id = %s
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-		_, err = database.TxExec(tx, updateQuery, now, employeeID, now, updatedBy, requestID, organizationID)
+		_, err = tx.Exec(updateQuery, now, employeeID, now, updatedBy, requestID, organizationID)
 	}
 
 	if err != nil {
@@ -733,33 +836,43 @@ func (r *InventoryRepository) ApproveInventoryRequest(requestID, organizationID,
 	return tx.Commit()
 }
 
-func (r *InventoryRepository) RejectInventoryRequest(requestID, organizationID, updatedBy string) error {
+func (r *InventoryRepository) RejectInventoryRequest(ctx context.Context, requestID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE inventory_request SET status = 0, updated_at = %s, updated_by = %s
 		WHERE request_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
 	now := time.Now()
-	_, err := database.Exec(r.db, query, now, updatedBy, requestID, organizationID)
+	_, err = t.Exec(query, now, updatedBy, requestID, t.OrganizationID())
 	return err
 }
 
-func (r *InventoryRepository) GetOrdersList(organizationID string) ([]model.InventoryOrderWithDetail, error) {
+func (r *InventoryRepository) GetOrdersList(ctx context.Context) ([]model.InventoryOrderWithDetail, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT io.purchase_id, io.request_id, io.suplier_id, io.quantity, io.item_price, io.total_amount, io.status,
 		       io.organization_id, io.created_at,
 		       s.suplier_name, s.suplier_city, io.item_id, i.item_uom, io.garage_id, i.item_name, i.item_sku, g.garage_name, g.garage_city, i.item_category, io.transaction_date
 		FROM inventory_orders io
-		LEFT JOIN supliers s ON io.suplier_id = s.suplier_id
-		LEFT JOIN inventory_request ir ON io.request_id = ir.request_id
-		LEFT JOIN inventory_items i ON io.item_id = i.item_id
-		LEFT JOIN garage g ON io.garage_id = g.garage_id
+		LEFT JOIN supliers s ON io.suplier_id = s.suplier_id AND s.organization_id = io.organization_id
+		LEFT JOIN inventory_request ir ON io.request_id = ir.request_id AND ir.organization_id = io.organization_id
+		LEFT JOIN inventory_items i ON io.item_id = i.item_id AND i.organization_id = io.organization_id
+		LEFT JOIN garage g ON io.garage_id = g.garage_id AND g.organization_id = io.organization_id
 		WHERE io.organization_id = %s AND io.status > 0
 		ORDER BY io.created_at DESC
 	`
 	query = fmt.Sprintf(query, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, organizationID)
+	rows, err := t.Query(query, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -815,20 +928,25 @@ func (r *InventoryRepository) GetOrdersList(organizationID string) ([]model.Inve
 	return items, nil
 }
 
-func (r *InventoryRepository) GetOrderByPurchaseID(purchaseID, organizationID string) (*model.InventoryOrderWithDetail, error) {
+func (r *InventoryRepository) GetOrderByPurchaseID(ctx context.Context, purchaseID string) (*model.InventoryOrderWithDetail, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT io.purchase_id, io.request_id, io.quantity, io.item_price, io.total_amount, io.status,
 		       s.suplier_name, s.suplier_city,
 			   io.item_id, i.item_name, i.item_sku, i.item_uom, i.item_category, io.transaction_date, 
 			   g.garage_name, g.garage_city, io.created_at, u.fullname as create_by, u2.fullname as updated_by, io.updated_at, t.invoice_number
 		FROM inventory_orders io
-		LEFT JOIN supliers s ON io.suplier_id = s.suplier_id
-		LEFT JOIN inventory_request ir ON io.request_id = ir.request_id
-		LEFT JOIN inventory_items i ON io.item_id = i.item_id
-		LEFT JOIN garage g ON io.garage_id = g.garage_id
+		LEFT JOIN supliers s ON io.suplier_id = s.suplier_id AND s.organization_id = io.organization_id
+		LEFT JOIN inventory_request ir ON io.request_id = ir.request_id AND ir.organization_id = io.organization_id
+		LEFT JOIN inventory_items i ON io.item_id = i.item_id AND i.organization_id = io.organization_id
+		LEFT JOIN garage g ON io.garage_id = g.garage_id AND g.organization_id = io.organization_id
 		INNER JOIN users u ON u.user_id = io.created_by
 		INNER JOIN users u2 ON u.user_id = io.updated_by
-		INNER JOIN transactions t ON t.reference_id = io.purchase_id
+		INNER JOIN transactions t ON t.reference_id = io.purchase_id AND t.organization_id = io.organization_id
 		WHERE io.purchase_id = %s AND io.organization_id = %s
 	`
 	query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
@@ -840,7 +958,7 @@ func (r *InventoryRepository) GetOrderByPurchaseID(purchaseID, organizationID st
 	var itemSKU sql.NullString
 	var updateBy sql.NullString
 	var updatedAt sql.NullTime
-	err := database.QueryRow(r.db, query, purchaseID, organizationID).Scan(
+	err = t.QueryRow(query, purchaseID, t.OrganizationID()).Scan(
 		&item.PurchaseID,
 		&item.RequestID,
 		&item.Quantity,
@@ -900,7 +1018,12 @@ func (r *InventoryRepository) GetOrderByPurchaseID(purchaseID, organizationID st
 	return &item, nil
 }
 
-func (r *InventoryRepository) CreateOrder(order *model.InventoryOrder) error {
+func (r *InventoryRepository) CreateOrder(ctx context.Context, order *model.InventoryOrder) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	order.PurchaseID = uuid.New().String()
 	now := time.Now()
 	order.CreatedAt = now
@@ -915,7 +1038,7 @@ func (r *InventoryRepository) CreateOrder(order *model.InventoryOrder) error {
 		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		order.PurchaseID,
 		order.RequestID,
 		order.SupplierID,
@@ -932,25 +1055,36 @@ func (r *InventoryRepository) CreateOrder(order *model.InventoryOrder) error {
 	return err
 }
 
-func (r *InventoryRepository) UpdateOrderSupplier(purchaseID, organizationID, supplierID, updatedBy string) error {
+func (r *InventoryRepository) UpdateOrderSupplier(ctx context.Context, purchaseID, supplierID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE inventory_orders SET suplier_id = %s, updated_at = %s, updated_by = %s WHERE purchase_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
 
 	now := time.Now()
-	_, err := database.Exec(r.db, query, supplierID, now, updatedBy, purchaseID, organizationID)
+	_, err = t.Exec(query, supplierID, now, updatedBy, purchaseID, t.OrganizationID())
 	return err
 }
 
-func (r *InventoryRepository) GetSuppliers(organizationID string) ([]model.Supplier, error) {
-	query := `
-		SELECT suplier_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email,
+func (r *InventoryRepository) GetSuppliers(ctx context.Context) ([]model.Supplier, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT suplier_id, organization_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email,
 		       created_at, created_by, updated_at, updated_by
 		FROM supliers
+		WHERE organization_id = %s
 		ORDER BY created_at DESC
-	`
+	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query)
+	rows, err := t.Query(query, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -963,6 +1097,7 @@ func (r *InventoryRepository) GetSuppliers(organizationID string) ([]model.Suppl
 		var city int
 		if err := rows.Scan(
 			&item.SupplierID,
+			&item.OrganizationID,
 			&item.SupplierName,
 			&item.SupplierAddress,
 			&city,
@@ -984,18 +1119,24 @@ func (r *InventoryRepository) GetSuppliers(organizationID string) ([]model.Suppl
 	return items, nil
 }
 
-func (r *InventoryRepository) GetSupplierByID(supplierID string) (*model.Supplier, error) {
+func (r *InventoryRepository) GetSupplierByID(ctx context.Context, supplierID string) (*model.Supplier, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
-		SELECT suplier_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email,
+		SELECT suplier_id, organization_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email,
 		       created_at, created_by, updated_at, updated_by
 		FROM supliers
-		WHERE suplier_id = %s
-	`, r.getPlaceholder(1))
+		WHERE suplier_id = %s AND organization_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var item model.Supplier
 	var city int
-	err := database.QueryRow(r.db, query, supplierID).Scan(
+	err = t.QueryRow(query, supplierID, t.OrganizationID()).Scan(
 		&item.SupplierID,
+		&item.OrganizationID,
 		&item.SupplierName,
 		&item.SupplierAddress,
 		&city,
@@ -1021,23 +1162,30 @@ func (r *InventoryRepository) GetSupplierByID(supplierID string) (*model.Supplie
 	return &item, nil
 }
 
-func (r *InventoryRepository) CreateSupplier(supplier *model.Supplier) error {
+func (r *InventoryRepository) CreateSupplier(ctx context.Context, supplier *model.Supplier) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	supplier.SupplierID = uuid.New().String()
+	supplier.OrganizationID = t.OrganizationID()
 	now := time.Now()
 	supplier.CreatedAt = now
 	supplier.UpdatedAt = now
 
 	query := fmt.Sprintf(`
-		INSERT INTO supliers (suplier_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email, created_at, created_by, updated_at, updated_by)
-		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+		INSERT INTO supliers (suplier_id, organization_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email, created_at, created_by, updated_at, updated_by)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 	`,
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
-		r.getPlaceholder(9), r.getPlaceholder(10),
+		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		supplier.SupplierID,
+		supplier.OrganizationID,
 		supplier.SupplierName,
 		supplier.SupplierAddress,
 		supplier.SupplierCity,
@@ -1051,15 +1199,37 @@ func (r *InventoryRepository) CreateSupplier(supplier *model.Supplier) error {
 	return err
 }
 
-func (r *InventoryRepository) DeleteSupplier(supplierID string) error {
-	query := fmt.Sprintf("DELETE FROM supliers WHERE suplier_id = %s",
-		r.getPlaceholder(1))
+func (r *InventoryRepository) DeleteSupplier(ctx context.Context, supplierID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 
-	_, err := database.Exec(r.db, query, supplierID)
-	return err
+	query := fmt.Sprintf("DELETE FROM supliers WHERE suplier_id = %s AND organization_id = %s",
+		r.getPlaceholder(1), r.getPlaceholder(2))
+
+	result, err := t.Exec(query, supplierID, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
 }
 
-func (r *InventoryRepository) GetItemGarageStock(itemID, garageID, organizationID string) (int, error) {
+func (r *InventoryRepository) GetItemGarageStock(ctx context.Context, itemID, garageID string) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT stock FROM inventory_item_garage 
 		WHERE item_id = %s AND garage_id = %s AND organization_id = %s
@@ -1067,7 +1237,7 @@ func (r *InventoryRepository) GetItemGarageStock(itemID, garageID, organizationI
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	var stock int
-	err := database.QueryRow(r.db, query, itemID, garageID, organizationID).Scan(&stock)
+	err = t.QueryRow(query, itemID, garageID, t.OrganizationID()).Scan(&stock)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, sql.ErrNoRows
@@ -1077,17 +1247,22 @@ func (r *InventoryRepository) GetItemGarageStock(itemID, garageID, organizationI
 	return stock, nil
 }
 
-func (r *InventoryRepository) GetItemGarageStockWithGarageName(itemID, garageID, organizationID string) (model.InventoryGarageStock, error) {
+func (r *InventoryRepository) GetItemGarageStockWithGarageName(ctx context.Context, itemID, garageID string) (model.InventoryGarageStock, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return model.InventoryGarageStock{}, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT ig.stock, g.garage_name
 		FROM inventory_item_garage ig
-		INNER JOIN garage g ON ig.garage_id = g.garage_id
+		INNER JOIN garage g ON ig.garage_id = g.garage_id AND g.organization_id = ig.organization_id
 		WHERE ig.item_id = %s AND ig.organization_id = %s AND ig.garage_id = %s
 		LIMIT 1
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	var stock model.InventoryGarageStock
-	err := database.QueryRow(r.db, query, itemID, organizationID, garageID).Scan(&stock.Stock, &stock.GarageName)
+	err = t.QueryRow(query, itemID, t.OrganizationID(), garageID).Scan(&stock.Stock, &stock.GarageName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return model.InventoryGarageStock{}, sql.ErrNoRows
@@ -1097,14 +1272,19 @@ func (r *InventoryRepository) GetItemGarageStockWithGarageName(itemID, garageID,
 	return stock, nil
 }
 
-func (r *InventoryRepository) UpdateItemGarageStock(itemID, garageID, organizationID, updatedBy string, stock int) error {
+func (r *InventoryRepository) UpdateItemGarageStock(ctx context.Context, itemID, garageID, updatedBy string, stock int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	query := fmt.Sprintf(`
 		UPDATE inventory_item_garage SET stock = %s, updated_at = %s, updated_by = %s
 		WHERE garage_id = %s AND item_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	result, err := database.Exec(r.db, query, stock, now, updatedBy, garageID, itemID, organizationID)
+	result, err := t.Exec(query, stock, now, updatedBy, garageID, itemID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
@@ -1120,8 +1300,14 @@ func (r *InventoryRepository) UpdateItemGarageStock(itemID, garageID, organizati
 	return nil
 }
 
-func (r *InventoryRepository) TransferItemStock(organizationID, updatedBy string, req *model.TransferInventoryItemRequest, currentStockFrom, currentStockDest model.InventoryGarageStock) error {
-	tx, err := r.db.Begin()
+func (r *InventoryRepository) TransferItemStock(ctx context.Context, updatedBy string, req *model.TransferInventoryItemRequest, currentStockFrom, currentStockDest model.InventoryGarageStock) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -1142,11 +1328,11 @@ func (r *InventoryRepository) TransferItemStock(organizationID, updatedBy string
 		WHERE garage_id = %s AND item_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	if _, err := database.TxExec(tx, query, newStockFrom, now, updatedBy, req.GarageFrom, req.ItemID, organizationID); err != nil {
+	if _, err := tx.Exec(query, newStockFrom, now, updatedBy, req.GarageFrom, req.ItemID, organizationID); err != nil {
 		return err
 	}
 
-	if err := r.createInventoryMovementInTx(tx, organizationID, &model.InventoryMovement{
+	if err := r.createInventoryMovementInTx(tx, &model.InventoryMovement{
 		MovementID:   uuid.New().String(),
 		ItemID:       req.ItemID,
 		GarageID:     req.GarageFrom,
@@ -1161,11 +1347,11 @@ func (r *InventoryRepository) TransferItemStock(organizationID, updatedBy string
 		return err
 	}
 
-	if _, err := database.TxExec(tx, query, newStockDest, now, updatedBy, req.GarageDestination, req.ItemID, organizationID); err != nil {
+	if _, err := tx.Exec(query, newStockDest, now, updatedBy, req.GarageDestination, req.ItemID, organizationID); err != nil {
 		return err
 	}
 
-	if err := r.createInventoryMovementInTx(tx, organizationID, &model.InventoryMovement{
+	if err := r.createInventoryMovementInTx(tx, &model.InventoryMovement{
 		MovementID:   uuid.New().String(),
 		ItemID:       req.ItemID,
 		GarageID:     req.GarageDestination,
@@ -1184,7 +1370,7 @@ func (r *InventoryRepository) TransferItemStock(organizationID, updatedBy string
 	return tx.Commit()
 }
 
-func (r *InventoryRepository) createInventoryMovementInTx(tx *sql.Tx, organizationID string, movement *model.InventoryMovement) error {
+func (r *InventoryRepository) createInventoryMovementInTx(tx *database.TenantTx, movement *model.InventoryMovement) error {
 	query := fmt.Sprintf(`
 		INSERT INTO inventory_movement (movement_id, item_id, garage_id, quantity, stock_before, stock_final, movement_type, notes, organization_id, created_at, created_by)
 		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
@@ -1194,7 +1380,7 @@ func (r *InventoryRepository) createInventoryMovementInTx(tx *sql.Tx, organizati
 		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11),
 	)
 
-	_, err := database.TxExec(tx, query,
+	_, err := tx.Exec(query,
 		movement.MovementID,
 		movement.ItemID,
 		movement.GarageID,
@@ -1203,15 +1389,21 @@ func (r *InventoryRepository) createInventoryMovementInTx(tx *sql.Tx, organizati
 		movement.StockFinal,
 		movement.MovementType,
 		movement.Notes,
-		organizationID,
+		tx.OrganizationID(),
 		movement.CreatedAt,
 		movement.CreatedBy,
 	)
 	return err
 }
 
-func (r *InventoryRepository) UpsertItemGarage(itemID, garageID, organizationID, createdBy string, stock int) error {
-	_, err := r.GetItemGarageStock(itemID, garageID, organizationID)
+func (r *InventoryRepository) UpsertItemGarage(ctx context.Context, itemID, garageID, createdBy string, stock int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+
+	_, err = r.GetItemGarageStock(ctx, itemID, garageID)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
@@ -1226,7 +1418,7 @@ func (r *InventoryRepository) UpsertItemGarage(itemID, garageID, organizationID,
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9),
 		)
-		_, err = database.Exec(r.db, query,
+		_, err = t.Exec(query,
 			uuid.New().String(),
 			itemID,
 			garageID,
@@ -1245,11 +1437,16 @@ func (r *InventoryRepository) UpsertItemGarage(itemID, garageID, organizationID,
 		WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	_, err = database.Exec(r.db, query, stock, now, createdBy, itemID, garageID, organizationID)
+	_, err = t.Exec(query, stock, now, createdBy, itemID, garageID, organizationID)
 	return err
 }
 
-func (r *InventoryRepository) CreateInventoryMovement(organizationID string, movement *model.InventoryMovement) error {
+func (r *InventoryRepository) CreateInventoryMovement(ctx context.Context, movement *model.InventoryMovement) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		INSERT INTO inventory_movement (movement_id, item_id, garage_id, quantity, stock_before, stock_final, movement_type, notes, organization_id, created_at, created_by)
 		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
@@ -1259,7 +1456,7 @@ func (r *InventoryRepository) CreateInventoryMovement(organizationID string, mov
 		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		movement.MovementID,
 		movement.ItemID,
 		movement.GarageID,
@@ -1268,24 +1465,34 @@ func (r *InventoryRepository) CreateInventoryMovement(organizationID string, mov
 		movement.StockFinal,
 		movement.MovementType,
 		movement.Notes,
-		organizationID,
+		t.OrganizationID(),
 		movement.CreatedAt,
 		movement.CreatedBy,
 	)
 	return err
 }
 
-func (r *InventoryRepository) UpdateOrderReceived(purchaseID, organizationID, updatedBy string) error {
+func (r *InventoryRepository) UpdateOrderReceived(ctx context.Context, purchaseID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	query := fmt.Sprintf(`
 		UPDATE inventory_orders SET status = %s, complete_date = %s, updated_at = %s, updated_by = %s WHERE purchase_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	_, err := database.Exec(r.db, query, 1, now, now, updatedBy, purchaseID, organizationID)
+	_, err = t.Exec(query, 1, now, now, updatedBy, purchaseID, t.OrganizationID())
 	return err
 }
 
-func (r *InventoryRepository) GetOrderForReceive(purchaseID, organizationID string) (*model.InventoryOrder, error) {
+func (r *InventoryRepository) GetOrderForReceive(ctx context.Context, purchaseID string) (*model.InventoryOrder, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT io.item_id, io.garage_id, io.quantity, io.created_by
 		FROM inventory_orders io
@@ -1294,7 +1501,7 @@ func (r *InventoryRepository) GetOrderForReceive(purchaseID, organizationID stri
 	query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var item model.InventoryOrder
-	err := database.QueryRow(r.db, query, purchaseID, organizationID).Scan(
+	err = t.QueryRow(query, purchaseID, t.OrganizationID()).Scan(
 		&item.ItemID,
 		&item.GarageID,
 		&item.Quantity,
@@ -1310,13 +1517,19 @@ func (r *InventoryRepository) GetOrderForReceive(purchaseID, organizationID stri
 	return &item, nil
 }
 
-func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, updatedBy string) error {
-	order, err := r.GetOrderForReceive(purchaseID, organizationID)
+func (r *InventoryRepository) ReceivePurchaseOrder(ctx context.Context, purchaseID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
 	if err != nil {
 		return err
 	}
+	organizationID := t.OrganizationID()
 
-	tx, err := r.db.Begin()
+	order, err := r.GetOrderForReceive(ctx, purchaseID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -1335,7 +1548,7 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 		WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 		LIMIT 1
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	err = database.TxQueryRow(tx, stockQuery, order.ItemID, order.GarageID, organizationID).Scan(&currentStock)
+	err = tx.QueryRow(stockQuery, order.ItemID, order.GarageID, organizationID).Scan(&currentStock)
 
 	now := time.Now()
 	if err != nil && err != sql.ErrNoRows {
@@ -1350,7 +1563,7 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9),
 		)
-		if _, err := database.TxExec(tx, insertQuery,
+		if _, err := tx.Exec(insertQuery,
 			uuid.New().String(),
 			order.ItemID,
 			order.GarageID,
@@ -1369,7 +1582,7 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 			UPDATE inventory_item_garage SET stock = %s, updated_at = %s, updated_by = %s
 			WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 		`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-		if _, err := database.TxExec(tx, updateQuery, currentStock+order.Quantity, now, updatedBy, order.ItemID, order.GarageID, organizationID); err != nil {
+		if _, err := tx.Exec(updateQuery, currentStock+order.Quantity, now, updatedBy, order.ItemID, order.GarageID, organizationID); err != nil {
 			return err
 		}
 		currentStock = currentStock + order.Quantity
@@ -1388,7 +1601,7 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 		CreatedBy:    updatedBy,
 	}
 
-	if err := r.createInventoryMovementInTx(tx, organizationID, movement); err != nil {
+	if err := r.createInventoryMovementInTx(tx, movement); err != nil {
 		return err
 	}
 
@@ -1397,7 +1610,7 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 		WHERE purchase_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	if _, err := database.TxExec(tx, updateOrderQuery, 1, now, now, updatedBy, purchaseID, organizationID); err != nil {
+	if _, err := tx.Exec(updateOrderQuery, 1, now, now, updatedBy, purchaseID, organizationID); err != nil {
 		return err
 	}
 
@@ -1406,7 +1619,7 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 		WHERE reference_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	if _, err := database.TxExec(tx, updateTransactionQuery, 1, purchaseID, organizationID); err != nil {
+	if _, err := tx.Exec(updateTransactionQuery, 1, purchaseID, organizationID); err != nil {
 		return err
 	}
 
@@ -1414,23 +1627,29 @@ func (r *InventoryRepository) ReceivePurchaseOrder(purchaseID, organizationID, u
 	return tx.Commit()
 }
 
-func (r *InventoryRepository) ReceiveOrderItem(organizationID, userID, employeeID string, requestID string) error {
+func (r *InventoryRepository) ReceiveOrderItem(ctx context.Context, userID, employeeID string, requestID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := `UPDATE inventory_request SET status = 1, received_at = %s, received_by = %s, updated_at = %s, updated_by = %s 
 	WHERE request_id = %s AND organization_id = %s
 	`
 	query = fmt.Sprintf(query, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 	now := time.Now()
-	_, err := r.db.Exec(query, now, employeeID, now, userID, requestID, organizationID)
+	_, err = t.Exec(query, now, employeeID, now, userID, requestID, t.OrganizationID())
 	return err
 }
 
-func (r *InventoryRepository) UpsertItemGarageInTx(tx *sql.Tx, itemID, garageID, organizationID, createdBy string, stock int) error {
+func (r *InventoryRepository) UpsertItemGarageInTx(tx *database.TenantTx, itemID, garageID, createdBy string, stock int) error {
+	organizationID := tx.OrganizationID()
 	var existingStock int
 	stockQuery := fmt.Sprintf(`
 		SELECT stock FROM inventory_item_garage 
 		WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	err := database.TxQueryRow(tx, stockQuery, itemID, garageID, organizationID).Scan(&existingStock)
+	err := tx.QueryRow(stockQuery, itemID, garageID, organizationID).Scan(&existingStock)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	}
@@ -1444,7 +1663,7 @@ func (r *InventoryRepository) UpsertItemGarageInTx(tx *sql.Tx, itemID, garageID,
 			r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9),
 		)
-		_, err = database.TxExec(tx, query,
+		_, err = tx.Exec(query,
 			uuid.New().String(),
 			itemID,
 			garageID,
@@ -1463,11 +1682,16 @@ func (r *InventoryRepository) UpsertItemGarageInTx(tx *sql.Tx, itemID, garageID,
 		WHERE item_id = %s AND garage_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	_, err = database.TxExec(tx, query, stock, now, createdBy, itemID, garageID, organizationID)
+	_, err = tx.Exec(query, stock, now, createdBy, itemID, garageID, organizationID)
 	return err
 }
 
-func (r *InventoryRepository) GetItemIDByName(itemName, organizationID string) (string, error) {
+func (r *InventoryRepository) GetItemIDByName(ctx context.Context, itemName string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT item_id FROM inventory_items 
 		WHERE item_name = %s AND organization_id = %s AND status > 0
@@ -1475,7 +1699,7 @@ func (r *InventoryRepository) GetItemIDByName(itemName, organizationID string) (
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var itemID string
-	err := database.QueryRow(r.db, query, itemName, organizationID).Scan(&itemID)
+	err = t.QueryRow(query, itemName, t.OrganizationID()).Scan(&itemID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", sql.ErrNoRows
@@ -1485,15 +1709,20 @@ func (r *InventoryRepository) GetItemIDByName(itemName, organizationID string) (
 	return itemID, nil
 }
 
-func (r *InventoryRepository) GetSupplierIDByName(supplierName string) (string, error) {
+func (r *InventoryRepository) GetSupplierIDByName(ctx context.Context, supplierName string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT suplier_id FROM supliers 
-		WHERE suplier_name = %s
+		WHERE suplier_name = %s AND organization_id = %s
 		LIMIT 1
-	`, r.getPlaceholder(1))
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var supplierID string
-	err := database.QueryRow(r.db, query, supplierName).Scan(&supplierID)
+	err = t.QueryRow(query, supplierName, t.OrganizationID()).Scan(&supplierID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", sql.ErrNoRows
@@ -1503,21 +1732,27 @@ func (r *InventoryRepository) GetSupplierIDByName(supplierName string) (string,
 	return supplierID, nil
 }
 
-func (r *InventoryRepository) CreateSupplierFromExternal(supplier *model.Supplier) (string, error) {
+func (r *InventoryRepository) CreateSupplierFromExternal(ctx context.Context, supplier *model.Supplier) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+
 	supplierID := uuid.New().String()
 	now := time.Now()
 
 	query := fmt.Sprintf(`
-		INSERT INTO supliers (suplier_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email, created_at, created_by, updated_at, updated_by)
-		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+		INSERT INTO supliers (suplier_id, organization_id, suplier_name, suplier_address, suplier_city, suplier_phone, supliter_email, created_at, created_by, updated_at, updated_by)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 	`,
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
-		r.getPlaceholder(9), r.getPlaceholder(10),
+		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		supplierID,
+		t.OrganizationID(),
 		supplier.SupplierName,
 		supplier.SupplierAddress,
 		supplier.SupplierCity,
@@ -1534,26 +1769,23 @@ func (r *InventoryRepository) CreateSupplierFromExternal(supplier *model.Supplie
 	return supplierID, nil
 }
 
-func (r *InventoryRepository) GetDB() *sql.DB {
-	return r.db
-}
-
-func (r *InventoryRepository) GetDriver() string {
-	return r.driver
-}
+func (r *InventoryRepository) GetAdminAccountNumber(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
 
-func (r *InventoryRepository) GetAdminAccountNumber(organizationID string) (string, error) {
 	query := fmt.Sprintf(`
 		SELECT aa.account_number
 		FROM assistant_accounts aa
 		WHERE aa.user_type = 1
 		  AND aa.status = 1
-		  AND %s
+		  AND aa.organization_id = %s
 		LIMIT 1
 	`, r.getPlaceholder(1))
 
 	var accountNumber string
-	if err := database.QueryRow(r.db, query, organizationID).Scan(&accountNumber); err != nil {
+	if err := t.QueryRow(query, t.OrganizationID()).Scan(&accountNumber); err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
 		}
@@ -1562,20 +1794,28 @@ func (r *InventoryRepository) GetAdminAccountNumber(organizationID string) (stri
 	return accountNumber, nil
 }
 
-func (r *InventoryRepository) IsAdminByAccountNumber(accountNumber string) (bool, error) {
+// IsAdminByAccountNumber looks the number up across organizations; it is how
+// the WhatsApp assistant finds out who is messaging before it knows the
+// organization, so it does not go through a Tenant.
+func (r *InventoryRepository) IsAdminByAccountNumber(ctx context.Context, accountNumber string) (bool, error) {
 	query := fmt.Sprintf(`
 		SELECT COUNT(1) FROM assistant_accounts
 		WHERE account_number = %s AND user_type = 1 AND status = 1
 	`, r.getPlaceholder(1))
 
 	var count int
-	if err := database.QueryRow(r.db, query, accountNumber).Scan(&count); err != nil {
+	if err := database.QueryRowContext(ctx, r.db, query, accountNumber).Scan(&count); err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *InventoryRepository) CreatePurchaseOrder(order *model.InventoryOrder) error {
+func (r *InventoryRepository) CreatePurchaseOrder(ctx context.Context, order *model.InventoryOrder) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	if order.PurchaseID == "" {
 		order.PurchaseID = uuid.New().String()
 	}
@@ -1595,7 +1835,7 @@ func (r *InventoryRepository) CreatePurchaseOrder(order *model.InventoryOrder) e
 		r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15), r.getPlaceholder(16),
 	)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		order.PurchaseID,
 		order.RequestID,
 		order.ItemID,
@@ -1616,7 +1856,12 @@ func (r *InventoryRepository) CreatePurchaseOrder(order *model.InventoryOrder) e
 	return err
 }
 
-func (r *InventoryRepository) CreateInventoryTransaction(organizationID string, txn *model.InventoryTransaction) error {
+func (r *InventoryRepository) CreateInventoryTransaction(ctx context.Context, txn *model.InventoryTransaction) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	if txn.CreatedAt.IsZero() {
 		txn.CreatedAt = now
@@ -1630,9 +1875,7 @@ func (r *InventoryRepository) CreateInventoryTransaction(organizationID string,
 		r.getPlaceholder(11), r.getPlaceholder(12), r.getPlaceholder(13), r.getPlaceholder(14), r.getPlaceholder(15),
 	)
 
-	fmt.Println("query==== ", txn.TransactionDateStr)
-
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		txn.TransactionID,
 		txn.TransactionType,
 		1,
@@ -1642,7 +1885,7 @@ func (r *InventoryRepository) CreateInventoryTransaction(organizationID string,
 		txn.Description,
 		txn.TransactionDateStr,
 		txn.PaymentType,
-		organizationID,
+		t.OrganizationID(),
 		txn.Amount,
 		txn.CreatedBy,
 		txn.CreatedAt,
@@ -1652,29 +1895,39 @@ func (r *InventoryRepository) CreateInventoryTransaction(organizationID string,
 	return err
 }
 
-func (r *InventoryRepository) UpdateRequestOrderStatus(requestID, organizationID, userID string, status int) error {
+func (r *InventoryRepository) UpdateRequestOrderStatus(ctx context.Context, requestID, userID string, status int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE inventory_request
 		SET status = %s, updated_at = NOW(), updated_by = %s
 		WHERE request_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	_, err := database.Exec(r.db, query, status, userID, requestID, organizationID)
+	_, err = t.Exec(query, status, userID, requestID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *InventoryRepository) GetItemLocations(itemID string) ([]model.InventoryItemLocation, error) {
+func (r *InventoryRepository) GetItemLocations(ctx context.Context, itemID string) ([]model.InventoryItemLocation, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT ig.garage_id, g.garage_name, g.garage_address, g.garage_city, ig.stock, ig.updated_at
 		FROM inventory_item_garage ig
-		INNER JOIN garage g ON g.garage_id = ig.garage_id
-		WHERE ig.item_id = %s
-	`, r.getPlaceholder(1))
+		INNER JOIN garage g ON g.garage_id = ig.garage_id AND g.organization_id = ig.organization_id
+		WHERE ig.item_id = %s AND ig.organization_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, itemID)
+	rows, err := t.Query(query, itemID, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -1709,16 +1962,21 @@ func (r *InventoryRepository) GetItemLocations(itemID string) ([]model.Inventory
 	return locations, nil
 }
 
-func (r *InventoryRepository) GetItemDetail(itemID string) (*model.InventoryItemDetail, error) {
+func (r *InventoryRepository) GetItemDetail(ctx context.Context, itemID string) (*model.InventoryItemDetail, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT item_id, item_sku, item_name, item_uom, item_category, status
 		FROM inventory_items
-		WHERE item_id = %s AND status > 0
+		WHERE item_id = %s AND organization_id = %s AND status > 0
 		LIMIT 1
-	`, r.getPlaceholder(1))
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var item model.InventoryItemDetail
-	err := database.QueryRow(r.db, query, itemID).Scan(
+	err = t.QueryRow(query, itemID, t.OrganizationID()).Scan(
 		&item.ItemID,
 		&item.ItemSKU,
 		&item.ItemName,
@@ -1733,7 +1991,7 @@ func (r *InventoryRepository) GetItemDetail(itemID string) (*model.InventoryItem
 		return nil, err
 	}
 
-	locations, err := r.GetItemLocations(itemID)
+	locations, err := r.GetItemLocations(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -1742,18 +2000,23 @@ func (r *InventoryRepository) GetItemDetail(itemID string) (*model.InventoryItem
 	return &item, nil
 }
 
-func (r *InventoryRepository) GetItemMovements(organizationID, itemID, startDate, endDate, garageID string) ([]model.InventoryItemMovement, error) {
+func (r *InventoryRepository) GetItemMovements(ctx context.Context, itemID, startDate, endDate, garageID string) ([]model.InventoryItemMovement, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT im.movement_id, g.garage_name, mt.label as movement_type, im.quantity, im.stock_before, im.stock_final, i.item_uom, im.notes, im.created_at as movement_date
 		FROM inventory_movement im
-		INNER JOIN garage g ON im.garage_id = g.garage_id
+		INNER JOIN garage g ON im.garage_id = g.garage_id AND g.organization_id = im.organization_id
 		INNER JOIN inventory_movement_types mt ON mt.id = im.movement_type
-		INNER JOIN inventory_items i ON i.item_id = im.item_id
+		INNER JOIN inventory_items i ON i.item_id = im.item_id AND i.organization_id = im.organization_id
 		WHERE im.organization_id = $1 AND im.item_id = $2
 	`
 
 	var args []interface{}
-	args = append(args, organizationID, itemID)
+	args = append(args, t.OrganizationID(), itemID)
 	pos := 3
 
 	if startDate != "" {
@@ -1773,9 +2036,7 @@ func (r *InventoryRepository) GetItemMovements(organizationID, itemID, startDate
 	}
 
 	query += " ORDER BY im.created_at DESC"
-	fmt.Println("GetItemMovements query: ", query, organizationID, itemID, startDate, endDate, garageID)
-
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1816,17 +2077,22 @@ func (r *InventoryRepository) GetItemMovements(organizationID, itemID, startDate
 	return movements, nil
 }
 
-func (r *InventoryRepository) GetItemOrderHistory(organizationID, itemID, startDate, endDate string) ([]model.InventoryHistory, error) {
+func (r *InventoryRepository) GetItemOrderHistory(ctx context.Context, itemID, startDate, endDate string) ([]model.InventoryHistory, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT io.request_id, io.purchase_id, io.quantity, io.item_price, io.total_amount, io.transaction_date, io.complete_date, e.fullname as received_by, ir.received_at 
 		FROM inventory_orders io
-		LEFT JOIN inventory_request ir ON ir.request_id = io.request_id
-		LEFT JOIN employee e ON e.uuid = ir.received_by
+		LEFT JOIN inventory_request ir ON ir.request_id = io.request_id AND ir.organization_id = io.organization_id
+		LEFT JOIN employee e ON e.uuid = ir.received_by AND e.organization_id = io.organization_id
 		WHERE io.organization_id = $1 AND io.item_id = $2 AND io.status = 1
 	`
 
 	var args []interface{}
-	args = append(args, organizationID, itemID)
+	args = append(args, t.OrganizationID(), itemID)
 	pos := 3
 
 	if startDate != "" {
@@ -1840,9 +2106,8 @@ func (r *InventoryRepository) GetItemOrderHistory(organizationID, itemID, startD
 		pos++
 	}
 	query += " ORDER BY io.transaction_date DESC"
-	fmt.Println("GetItemOrderHistory query: ", query, organizationID, itemID, startDate, endDate)
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1887,26 +2152,36 @@ func (r *InventoryRepository) GetItemOrderHistory(organizationID, itemID, startD
 	return histories, nil
 }
 
-func (r *InventoryRepository) CancelPurchaseOrder(purchaseID, organizationID, updatedBy string) error {
+func (r *InventoryRepository) CancelPurchaseOrder(ctx context.Context, purchaseID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE inventory_orders
 		SET updated_at = $1, updated_by = $2, status = 0
 		WHERE purchase_id = $3 AND organization_id = $4
 	`
-	_, err := r.db.Exec(query, time.Now().Format("2006-01-02 15:04:05"), updatedBy, purchaseID, organizationID)
+	_, err = t.Exec(query, time.Now().Format("2006-01-02 15:04:05"), updatedBy, purchaseID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *InventoryRepository) CancelInventoryTransaction(organizationID, updatedBy, purchaseID string) error {
+func (r *InventoryRepository) CancelInventoryTransaction(ctx context.Context, updatedBy, purchaseID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE transactions
 		SET updated_at = $1, updated_by = $2, status = 0
 		WHERE reference_id = $3 AND organization_id = $4
 	`
-	_, err := r.db.Exec(query, time.Now().Format("2006-01-02 15:04:05"), updatedBy, purchaseID, organizationID)
+	_, err = t.Exec(query, time.Now().Format("2006-01-02 15:04:05"), updatedBy, purchaseID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
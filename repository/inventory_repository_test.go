@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+// inventoryCalls tries every inventory repository method on tenantB's
+// records with the organization taken from ctx
+func inventoryCalls(r *InventoryRepository) tenantCalls {
+	stock := model.InventoryGarageStock{Stock: 10}
+	return tenantCalls{
+		"GenerateItemSKU":         func(ctx context.Context) error { _, err := r.GenerateItemSKU(ctx); return err },
+		"GenerateRequestNumber":   func(ctx context.Context) error { _, err := r.GenerateRequestNumber(ctx); return err },
+		"GeneratePurchaseOrderID": func(ctx context.Context) error { _, err := r.GeneratePurchaseOrderID(ctx); return err },
+		"GenerateInvoiceNumber": func(ctx context.Context) error {
+			_, err := r.GenerateInvoiceNumber(ctx, 1, time.Now())
+			return err
+		},
+		"GetAllItems": func(ctx context.Context) error { _, err := r.GetAllItems(ctx, 0, "all"); return err },
+		"GetItemByID": func(ctx context.Context) error { _, err := r.GetItemByID(ctx, itemOfB); return err },
+		"UpdateItem": func(ctx context.Context) error {
+			return r.UpdateItem(ctx, itemOfB, "user", map[string]interface{}{"item_name": "taken"})
+		},
+		"DeleteItem":     func(ctx context.Context) error { return r.DeleteItem(ctx, itemOfB) },
+		"GetRequestList": func(ctx context.Context) error { _, err := r.GetRequestList(ctx); return err },
+		"GetRequestByID": func(ctx context.Context) error { _, err := r.GetRequestByID(ctx, requestOfB); return err },
+		"GetCurrentGarageItemStock": func(ctx context.Context) error {
+			_, err := r.GetCurrentGarageItemStock(ctx, itemOfB, garageOfB)
+			return err
+		},
+		"UpdateRequestStatus": func(ctx context.Context) error { return r.UpdateRequestStatus(ctx, requestOfB, "user", 0) },
+		"UpdateRequestApprove": func(ctx context.Context) error {
+			return r.UpdateRequestApprove(ctx, requestOfB, "user", time.Now())
+		},
+		"GetEmployeePhoneByUUID": func(ctx context.Context) error { _, err := r.GetEmployeePhoneByUUID(ctx, employeeOfB); return err },
+		"GetRequestForApprove":   func(ctx context.Context) error { _, err := r.GetRequestForApprove(ctx, requestOfB); return err },
+		"ApproveInventoryRequest": func(ctx context.Context) error {
+			return r.ApproveInventoryRequest(ctx, requestOfB, itemOfB, employeeOfB, "user")
+		},
+		"RejectInventoryRequest": func(ctx context.Context) error { return r.RejectInventoryRequest(ctx, requestOfB, "user") },
+		"GetOrdersList":          func(ctx context.Context) error { _, err := r.GetOrdersList(ctx); return err },
+		"GetOrderByPurchaseID":   func(ctx context.Context) error { _, err := r.GetOrderByPurchaseID(ctx, purchaseOfB); return err },
+		"UpdateOrderSupplier": func(ctx context.Context) error {
+			return r.UpdateOrderSupplier(ctx, purchaseOfB, supplierOfB, "user")
+		},
+		"GetSuppliers":    func(ctx context.Context) error { _, err := r.GetSuppliers(ctx); return err },
+		"GetSupplierByID": func(ctx context.Context) error { _, err := r.GetSupplierByID(ctx, supplierOfB); return err },
+		"DeleteSupplier":  func(ctx context.Context) error { return r.DeleteSupplier(ctx, supplierOfB) },
+		"GetItemGarageStock": func(ctx context.Context) error {
+			_, err := r.GetItemGarageStock(ctx, itemOfB, garageOfB)
+			return err
+		},
+		"GetItemGarageStockWithGarageName": func(ctx context.Context) error {
+			_, err := r.GetItemGarageStockWithGarageName(ctx, itemOfB, garageOfB)
+			return err
+		},
+		"UpdateItemGarageStock": func(ctx context.Context) error {
+			return r.UpdateItemGarageStock(ctx, itemOfB, garageOfB, "user", 0)
+		},
+		"TransferItemStock": func(ctx context.Context) error {
+			req := &model.TransferInventoryItemRequest{ItemID: itemOfB, GarageFrom: garageOfB, GarageDestination: "other", Stock: 1}
+			return r.TransferItemStock(ctx, "user", req, stock, stock)
+		},
+		"UpdateOrderReceived":  func(ctx context.Context) error { return r.UpdateOrderReceived(ctx, purchaseOfB, "user") },
+		"GetOrderForReceive":   func(ctx context.Context) error { _, err := r.GetOrderForReceive(ctx, purchaseOfB); return err },
+		"ReceivePurchaseOrder": func(ctx context.Context) error { return r.ReceivePurchaseOrder(ctx, purchaseOfB, "user") },
+		"ReceiveOrderItem": func(ctx context.Context) error {
+			return r.ReceiveOrderItem(ctx, "user", employeeOfB, requestOfB)
+		},
+		"GetItemIDByName":       func(ctx context.Context) error { _, err := r.GetItemIDByName(ctx, "oil"); return err },
+		"GetSupplierIDByName":   func(ctx context.Context) error { _, err := r.GetSupplierIDByName(ctx, "supplier"); return err },
+		"GetAdminAccountNumber": func(ctx context.Context) error { _, err := r.GetAdminAccountNumber(ctx); return err },
+		"UpdateRequestOrderStatus": func(ctx context.Context) error {
+			return r.UpdateRequestOrderStatus(ctx, requestOfB, "user", 3)
+		},
+		"GetItemLocations": func(ctx context.Context) error { _, err := r.GetItemLocations(ctx, itemOfB); return err },
+		"GetItemDetail":    func(ctx context.Context) error { _, err := r.GetItemDetail(ctx, itemOfB); return err },
+		"GetItemMovements": func(ctx context.Context) error {
+			_, err := r.GetItemMovements(ctx, itemOfB, "2024-01-01", "2024-12-31", garageOfB)
+			return err
+		},
+		"GetItemOrderHistory": func(ctx context.Context) error {
+			_, err := r.GetItemOrderHistory(ctx, itemOfB, "2024-01-01", "2024-12-31")
+			return err
+		},
+		"CancelPurchaseOrder": func(ctx context.Context) error { return r.CancelPurchaseOrder(ctx, purchaseOfB, "user") },
+		"CancelInventoryTransaction": func(ctx context.Context) error {
+			return r.CancelInventoryTransaction(ctx, "user", purchaseOfB)
+		},
+	}
+}
+
+func TestInventoryRepositoryIsTenantScoped(t *testing.T) {
+	r := NewInventoryRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, inventoryCalls(r))
+}
+
+func TestInventoryRepositoryRefusesWritesForOtherTenant(t *testing.T) {
+	r := NewInventoryRepository(openTenantFake(t), "postgres")
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+
+	writes := map[string]func() error{
+		"CreateItem": func() error {
+			return r.CreateItem(ctxA, &model.InventoryItem{OrganizationID: tenantB, ItemName: "oil"})
+		},
+		"CreateItemWithoutStock": func() error {
+			return r.CreateItemWithoutStock(ctxA, &model.InventoryItem{OrganizationID: tenantB, ItemName: "oil"})
+		},
+		"CreateRequest": func() error {
+			return r.CreateRequest(ctxA, &model.InventoryRequest{OrganizationID: tenantB, ItemID: itemOfB})
+		},
+		"CreateOrder": func() error {
+			return r.CreateOrder(ctxA, &model.InventoryOrder{OrganizationID: tenantB, ItemID: itemOfB})
+		},
+		"CreatePurchaseOrder": func() error {
+			return r.CreatePurchaseOrder(ctxA, &model.InventoryOrder{OrganizationID: tenantB, ItemID: itemOfB})
+		},
+	}
+	checkRefusedWrites(t, writes)
+}
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
 	"service-travego/model"
+	"strconv"
 	"time"
 )
 
@@ -24,13 +26,13 @@ func (r *LeaveManagementRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-func (r *LeaveManagementRepository) ListLeaveTypes() ([]model.LeaveManagementTypeItem, error) {
+func (r *LeaveManagementRepository) ListLeaveTypes(ctx context.Context) ([]model.LeaveManagementTypeItem, error) {
 	query := `
 		SELECT id, label
 		FROM employee_leave_type
 		ORDER BY id ASC
 	`
-	rows, err := database.Query(r.db, query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -50,14 +52,19 @@ func (r *LeaveManagementRepository) ListLeaveTypes() ([]model.LeaveManagementTyp
 	return out, nil
 }
 
-func (r *LeaveManagementRepository) ListEmployeeLeaves(organizationID string, start *time.Time, end *time.Time) ([]model.LeaveManagementListItem, error) {
+func (r *LeaveManagementRepository) ListEmployeeLeaves(ctx context.Context, start *time.Time, end *time.Time) ([]model.LeaveManagementListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	orgExpr := "e.organization_id = " + r.getPlaceholder(1)
 	if r.driver != "mysql" {
 		orgExpr = "e.organization_id::text = " + r.getPlaceholder(1)
 	}
 
 	dateFilter := ""
-	args := []interface{}{organizationID}
+	args := []interface{}{t.OrganizationID()}
 	if start != nil && end != nil {
 		dateFilter = fmt.Sprintf(`
 			AND el.start_date <= %s
@@ -81,10 +88,10 @@ func (r *LeaveManagementRepository) ListEmployeeLeaves(organizationID string, st
 			COALESCE(lt.label, '')
 		FROM employee_leaves el
 		INNER JOIN employee_leave_type lt ON lt.id = el.leave_type
-		INNER JOIN employee e ON e.uuid = el.employee_id
-		INNER JOIN employee es ON es.uuid = el.substituted_by
-		LEFT JOIN organization_roles r ON r.role_id::text = e.role_id::text
-		LEFT JOIN organization_divisions d ON d.division_id::text = r.division_id::text
+		INNER JOIN employee e ON e.uuid = el.employee_id AND e.organization_id = el.organization_id
+		INNER JOIN employee es ON es.uuid = el.substituted_by AND es.organization_id = el.organization_id
+		LEFT JOIN organization_roles r ON r.role_id::text = e.role_id::text AND r.organization_id = e.organization_id
+		LEFT JOIN organization_divisions d ON d.division_id::text = r.division_id::text AND d.organization_id = r.organization_id
 		WHERE %s
 		%s
 		ORDER BY el.start_date DESC
@@ -106,17 +113,17 @@ func (r *LeaveManagementRepository) ListEmployeeLeaves(organizationID string, st
 				COALESCE(lt.label, '')
 			FROM employee_leaves el
 			INNER JOIN employee_leave_type lt ON lt.id = el.leave_type
-			INNER JOIN employee e ON e.uuid = el.employee_id
-			INNER JOIN employee es ON es.uuid = el.substituted_by
-			LEFT JOIN organization_roles r ON r.role_id = e.role_id
-			LEFT JOIN organization_divisions d ON d.division_id = r.division_id
+			INNER JOIN employee e ON e.uuid = el.employee_id AND e.organization_id = el.organization_id
+			INNER JOIN employee es ON es.uuid = el.substituted_by AND es.organization_id = el.organization_id
+			LEFT JOIN organization_roles r ON r.role_id = e.role_id AND r.organization_id = e.organization_id
+			LEFT JOIN organization_divisions d ON d.division_id = r.division_id AND d.organization_id = r.organization_id
 			WHERE %s
 			%s
 			ORDER BY el.start_date DESC
 		`, orgExpr, dateFilter)
 	}
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +171,56 @@ func (r *LeaveManagementRepository) ListEmployeeLeaves(organizationID string, st
 	return out, nil
 }
 
-func (r *LeaveManagementRepository) EmployeeUUIDExists(organizationID, employeeUUID string) (bool, error) {
+// ListApprovedLeaves returns the approved leaves overlapping [start, end],
+// whether or not someone substitutes the employee
+func (r *LeaveManagementRepository) ListApprovedLeaves(ctx context.Context, start, end time.Time) ([]model.EmployeeLeavePeriod, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT
+			CAST(el.employee_id AS CHAR(36)),
+			COALESCE(CAST(el.substituted_by AS CHAR(36)), ''),
+			COALESCE(el.status, 0),
+			el.start_date,
+			COALESCE(el.end_date, el.start_date),
+			COALESCE(lt.label, '')
+		FROM employee_leaves el
+		LEFT JOIN employee_leave_type lt ON lt.id = el.leave_type
+		WHERE el.organization_id = %s
+			AND el.status = %d
+			AND el.start_date <= %s
+			AND COALESCE(el.end_date, el.start_date) >= %s
+		ORDER BY el.start_date ASC
+	`, r.getPlaceholder(1), model.LeaveStatusApproved, r.getPlaceholder(2), r.getPlaceholder(3))
+
+	rows, err := t.Query(query, t.OrganizationID(), end, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.EmployeeLeavePeriod, 0)
+	for rows.Next() {
+		var it model.EmployeeLeavePeriod
+		if err := rows.Scan(&it.EmployeeID, &it.SubstitutedBy, &it.Status, &it.StartDate, &it.EndDate, &it.LeaveTypeLabel); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *LeaveManagementRepository) EmployeeUUIDExists(ctx context.Context, employeeUUID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+
 	orgExpr := "organization_id = " + r.getPlaceholder(1)
 	uuidExpr := "uuid = " + r.getPlaceholder(2)
 	if r.driver != "mysql" {
@@ -178,13 +234,13 @@ func (r *LeaveManagementRepository) EmployeeUUIDExists(organizationID, employeeU
 	`, orgExpr, uuidExpr)
 
 	var cnt int
-	if err := database.QueryRow(r.db, query, organizationID, employeeUUID).Scan(&cnt); err != nil {
+	if err := t.QueryRow(query, t.OrganizationID(), employeeUUID).Scan(&cnt); err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
-func (r *LeaveManagementRepository) CreateEmployeeLeave(leaveID, organizationID, employeeID, substitutedBy string, startDate, endDate time.Time, leaveType int, createdAt time.Time, createdBy string) error {
+func (r *LeaveManagementRepository) CreateEmployeeLeave(ctx context.Context, leaveID, employeeID, substitutedBy string, startDate, endDate time.Time, leaveType int, createdAt time.Time, createdBy string) error {
 	query := `
 		INSERT INTO employee_leaves (
 			leave_id, organization_id, employee_id, substituted_by,
@@ -193,9 +249,13 @@ func (r *LeaveManagementRepository) CreateEmployeeLeave(leaveID, organizationID,
 		) VALUES (
 			` + r.getPlaceholder(1) + `, ` + r.getPlaceholder(2) + `, ` + r.getPlaceholder(3) + `, ` + r.getPlaceholder(4) + `,
 			` + r.getPlaceholder(5) + `, ` + r.getPlaceholder(6) + `, ` + r.getPlaceholder(7) + `,
-			1, ` + r.getPlaceholder(8) + `, ` + r.getPlaceholder(9) + `
+			` + strconv.Itoa(model.LeaveStatusApproved) + `, ` + r.getPlaceholder(8) + `, ` + r.getPlaceholder(9) + `
 		)
 	`
-	_, err := database.Exec(r.db, query, leaveID, organizationID, employeeID, substitutedBy, startDate, endDate, leaveType, createdAt, createdBy)
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	_, err = t.Exec(query, leaveID, t.OrganizationID(), employeeID, substitutedBy, startDate, endDate, leaveType, createdAt, createdBy)
 	return err
 }
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeaveManagementRepositoryIsTenantScoped(t *testing.T) {
+	r := NewLeaveManagementRepository(openTenantFake(t), "postgres")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	checkTenantIsolation(t, tenantCalls{
+		"ListEmployeeLeaves": func(ctx context.Context) error {
+			_, err := r.ListEmployeeLeaves(ctx, &start, &end)
+			return err
+		},
+		"ListApprovedLeaves": func(ctx context.Context) error {
+			_, err := r.ListApprovedLeaves(ctx, start, end)
+			return err
+		},
+		"EmployeeUUIDExists": func(ctx context.Context) error {
+			_, err := r.EmployeeUUIDExists(ctx, employeeOfB)
+			return err
+		},
+		"CreateEmployeeLeave": func(ctx context.Context) error {
+			return r.CreateEmployeeLeave(ctx, "leave-1", employeeOfB, employeeOfB, start, end, 1, time.Now(), "")
+		},
+	})
+}
+
+func TestListApprovedLeavesKeepsUnsubstitutedLeaves(t *testing.T) {
+	r := NewLeaveManagementRepository(openTenantFake(t), "postgres")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := r.ListApprovedLeaves(database.WithOrganization(context.Background(), tenantB), start, start); err != nil {
+		t.Fatal(err)
+	}
+	statements := fakeTenantDB.take()
+	if len(statements) != 1 {
+		t.Fatalf("expected one statement, got %d", len(statements))
+	}
+	query := statements[0].query
+	// an inner join on the substitute would drop the leaves nobody covers
+	if strings.Contains(query, "INNER JOIN") || strings.Contains(query, "substituted_by =") {
+		t.Errorf("leaves are filtered on their substitute: %s", query)
+	}
+	if !strings.Contains(query, fmt.Sprintf("el.status = %d", model.LeaveStatusApproved)) {
+		t.Errorf("leaves are not filtered on approval: %s", query)
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -17,7 +18,12 @@ func NewMessagesRepository(db *sql.DB, driver string) *MessagesRepository {
 	return &MessagesRepository{db: db, driver: driver}
 }
 
-func (r *MessagesRepository) ListMessages(orgID string) ([]model.MessageListItem, error) {
+func (r *MessagesRepository) ListMessages(ctx context.Context) ([]model.MessageListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(
 		`SELECT message_id, customer_name, customer_email, customer_phone, message_type, message, status, created_at
 		 FROM messages
@@ -26,7 +32,7 @@ func (r *MessagesRepository) ListMessages(orgID string) ([]model.MessageListItem
 		r.getPlaceholder(1),
 	)
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, t.OrganizationID())
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +58,12 @@ func (r *MessagesRepository) ListMessages(orgID string) ([]model.MessageListItem
 	return items, nil
 }
 
-func (r *MessagesRepository) CreateMessage(orgID, messageID string, req *model.MessageSubmitRequest) error {
+func (r *MessagesRepository) CreateMessage(ctx context.Context, messageID string, req *model.MessageSubmitRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(
 		`INSERT INTO messages
 			(message_id, organization_id, customer_name, customer_email, customer_phone, message_type, message, status, created_at)
@@ -69,11 +80,10 @@ func (r *MessagesRepository) CreateMessage(orgID, messageID string, req *model.M
 		r.getPlaceholder(9),
 	)
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		messageID,
-		orgID,
+		t.OrganizationID(),
 		req.CustomerName,
 		req.CustomerEmail,
 		req.CustomerPhone,
@@ -85,7 +95,12 @@ func (r *MessagesRepository) CreateMessage(orgID, messageID string, req *model.M
 	return err
 }
 
-func (r *MessagesRepository) MarkMessageRead(orgID, messageID string) error {
+func (r *MessagesRepository) MarkMessageRead(ctx context.Context, messageID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(
 		`UPDATE messages
 		 SET status = %s, updated_at = %s
@@ -96,7 +111,7 @@ func (r *MessagesRepository) MarkMessageRead(orgID, messageID string) error {
 		r.getPlaceholder(4),
 	)
 
-	res, err := database.Exec(r.db, query, 1, time.Now(), messageID, orgID)
+	res, err := t.Exec(query, 1, time.Now(), messageID, t.OrganizationID())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+)
+
+func TestMessagesRepositoryIsTenantScoped(t *testing.T) {
+	r := NewMessagesRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"ListMessages": func(ctx context.Context) error { _, err := r.ListMessages(ctx); return err },
+		"CreateMessage": func(ctx context.Context) error {
+			return r.CreateMessage(ctx, "message-1", &model.MessageSubmitRequest{CustomerName: "Budi"})
+		},
+		"MarkMessageRead": func(ctx context.Context) error { return r.MarkMessageRead(ctx, messageOfB) },
+	})
+}
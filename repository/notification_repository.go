@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+)
+
+type NotificationRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewNotificationRepository(db *sql.DB, driver string) *NotificationRepository {
+	return &NotificationRepository{
+		db:     db,
+		driver: driver,
+	}
+}
+
+func (r *NotificationRepository) getPlaceholder(pos int) string {
+	if r.driver == "postgres" || r.driver == "pgx" {
+		return fmt.Sprintf("$%d", pos)
+	}
+	return "?"
+}
+
+// Create stores a notification of the organization of ctx
+func (r *NotificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(
+		`INSERT INTO notifications
+			(notification_id, organization_id, reference_url, title, message, created_at, is_read)
+		 VALUES
+			(%s, %s, %s, %s, %s, %s, %s)`,
+		r.getPlaceholder(1),
+		r.getPlaceholder(2),
+		r.getPlaceholder(3),
+		r.getPlaceholder(4),
+		r.getPlaceholder(5),
+		r.getPlaceholder(6),
+		r.getPlaceholder(7),
+	)
+
+	_, err = t.Exec(
+		query,
+		n.NotificationID,
+		t.OrganizationID(),
+		n.ReferenceURL,
+		n.Title,
+		n.Message,
+		n.CreatedAt,
+		n.IsRead,
+	)
+	return err
+}
+
+// List returns the latest notifications of the organization of ctx
+func (r *NotificationRepository) List(ctx context.Context, limit int) ([]model.Notification, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(
+		`SELECT notification_id, reference_url, title, message, created_at, is_read
+		 FROM notifications
+		 WHERE organization_id = %s
+		 ORDER BY created_at DESC
+		 LIMIT %d`,
+		r.getPlaceholder(1),
+		limit,
+	)
+
+	rows, err := t.Query(query, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.Notification, 0)
+	for rows.Next() {
+		var item model.Notification
+		if err := rows.Scan(
+			&item.NotificationID,
+			&item.ReferenceURL,
+			&item.Title,
+			&item.Message,
+			&item.CreatedAt,
+			&item.IsRead,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// MarkAsRead marks a notification of the organization of ctx as read; false
+// when the organization has no such notification
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	query := fmt.Sprintf(
+		`UPDATE notifications
+		 SET is_read = %s
+		 WHERE notification_id = %s AND organization_id = %s`,
+		r.getPlaceholder(1),
+		r.getPlaceholder(2),
+		r.getPlaceholder(3),
+	)
+
+	result, err := t.Exec(query, true, notificationID, t.OrganizationID())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestNotificationRepositoryIsTenantScoped(t *testing.T) {
+	r := NewNotificationRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"Create": func(ctx context.Context) error {
+			return r.Create(ctx, &model.Notification{NotificationID: "notification-1", Title: "t", Message: "m", CreatedAt: time.Now()})
+		},
+		"List": func(ctx context.Context) error {
+			_, err := r.List(ctx, 50)
+			return err
+		},
+		"MarkAsRead": func(ctx context.Context) error {
+			_, err := r.MarkAsRead(ctx, notificationOfB)
+			return err
+		},
+	})
+}
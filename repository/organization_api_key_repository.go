@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+const apiKeyTimeFormat = "2006-01-02 15:04:05"
+
+func (r *OrganizationRepository) selectAPIKeys() string {
+	idExpr, orgExpr, createdByExpr := "k.api_key_id", "k.organization_id", "k.created_by"
+	if r.driver != "mysql" {
+		idExpr, orgExpr, createdByExpr = "k.api_key_id::text", "k.organization_id::text", "k.created_by::text"
+	}
+	return fmt.Sprintf(`
+		SELECT %s, %s, COALESCE(o.organization_code, ''), k.name, k.key_prefix, k.scopes,
+			k.expires_at, k.last_used_at, COALESCE(k.last_used_ip, ''), k.revoked_at, k.created_at, COALESCE(%s, '')
+		FROM organization_api_keys k
+		LEFT JOIN organizations o ON o.organization_id = k.organization_id`, idExpr, orgExpr, createdByExpr)
+}
+
+func (r *OrganizationRepository) apiKeyWhere(column string, pos int) string {
+	if r.driver != "mysql" {
+		return fmt.Sprintf("k.%s::text = %s", column, r.getPlaceholder(pos))
+	}
+	return fmt.Sprintf("k.%s = %s", column, r.getPlaceholder(pos))
+}
+
+func scanOrganizationAPIKeys(rows *sql.Rows) ([]model.OrganizationAPIKey, error) {
+	now := time.Now()
+	items := make([]model.OrganizationAPIKey, 0)
+	for rows.Next() {
+		var it model.OrganizationAPIKey
+		var scopes string
+		var expiresAt, lastUsedAt, revokedAt, createdAt sql.NullTime
+		if err := rows.Scan(&it.APIKeyID, &it.OrganizationID, &it.OrganizationCode, &it.Name, &it.KeyPrefix, &scopes,
+			&expiresAt, &lastUsedAt, &it.LastUsedIP, &revokedAt, &createdAt, &it.CreatedBy); err != nil {
+			return nil, err
+		}
+		it.Scopes = []string{}
+		for _, s := range strings.Split(scopes, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				it.Scopes = append(it.Scopes, s)
+			}
+		}
+
+		it.Status = model.APIKeyStatusActive
+		if expiresAt.Valid {
+			it.ExpiresAt = expiresAt.Time.Format(apiKeyTimeFormat)
+			if !expiresAt.Time.After(now) {
+				it.Status = model.APIKeyStatusExpired
+			}
+		}
+		if revokedAt.Valid {
+			it.RevokedAt = revokedAt.Time.Format(apiKeyTimeFormat)
+			it.Status = model.APIKeyStatusRevoked
+		}
+		if lastUsedAt.Valid {
+			it.LastUsedAt = lastUsedAt.Time.Format(apiKeyTimeFormat)
+		}
+		if createdAt.Valid {
+			it.CreatedAt = createdAt.Time.Format(apiKeyTimeFormat)
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func (r *OrganizationRepository) insertAPIKey(exec func(query string, args ...interface{}) (sql.Result, error), key *model.OrganizationAPIKey, keyHash string, expiresAt *time.Time, rotatedFrom string, createdAt time.Time) error {
+	query := fmt.Sprintf(`
+		INSERT INTO organization_api_keys
+			(api_key_id, organization_id, name, key_prefix, key_hash, scopes, expires_at, rotated_from, created_at, created_by)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
+		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
+
+	var expires interface{}
+	if expiresAt != nil {
+		expires = *expiresAt
+	}
+	_, err := exec(query, key.APIKeyID, key.OrganizationID, key.Name, key.KeyPrefix, keyHash,
+		strings.Join(key.Scopes, ","), expires, nullableString(rotatedFrom), createdAt, nullableString(key.CreatedBy))
+	return err
+}
+
+// CreateAPIKey stores a new API key; only keyHash of the key itself is kept
+func (r *OrganizationRepository) CreateAPIKey(ctx context.Context, key *model.OrganizationAPIKey, keyHash string, expiresAt *time.Time, createdAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return r.insertAPIKey(t.Exec, key, keyHash, expiresAt, "", createdAt)
+}
+
+// RotateAPIKey revokes oldKeyID and stores its replacement in one transaction.
+// It returns sql.ErrNoRows when oldKeyID is not an unrevoked key of the
+// organization.
+func (r *OrganizationRepository) RotateAPIKey(ctx context.Context, oldKeyID string, key *model.OrganizationAPIKey, keyHash string, expiresAt *time.Time, rotatedAt time.Time) (err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = r.revokeAPIKey(tx.Exec, t.OrganizationID(), oldKeyID, key.CreatedBy, rotatedAt); err != nil {
+		return err
+	}
+	if err = r.insertAPIKey(tx.Exec, key, keyHash, expiresAt, oldKeyID, rotatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *OrganizationRepository) revokeAPIKey(exec func(query string, args ...interface{}) (sql.Result, error), organizationID, apiKeyID, revokedBy string, revokedAt time.Time) error {
+	query := fmt.Sprintf(`
+		UPDATE organization_api_keys k
+		SET revoked_at = %s, revoked_by = %s
+		WHERE %s AND %s AND k.revoked_at IS NULL
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.apiKeyWhere("organization_id", 3), r.apiKeyWhere("api_key_id", 4))
+
+	result, err := exec(query, revokedAt, nullableString(revokedBy), organizationID, apiKeyID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeAPIKey revokes a key of the organization. It returns sql.ErrNoRows
+// when the key does not exist or is already revoked.
+func (r *OrganizationRepository) RevokeAPIKey(ctx context.Context, apiKeyID, revokedBy string, revokedAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	return r.revokeAPIKey(t.Exec, t.OrganizationID(), apiKeyID, revokedBy, revokedAt)
+}
+
+// ListAPIKeys returns the API keys of the organization, the newest first
+func (r *OrganizationRepository) ListAPIKeys(ctx context.Context) ([]model.OrganizationAPIKey, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := r.selectAPIKeys() + " WHERE " + r.apiKeyWhere("organization_id", 1) + " ORDER BY k.created_at DESC"
+	rows, err := t.Query(query, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOrganizationAPIKeys(rows)
+}
+
+// GetAPIKey returns a key of the organization, or nil when it does not exist
+func (r *OrganizationRepository) GetAPIKey(ctx context.Context, apiKeyID string) (*model.OrganizationAPIKey, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := r.selectAPIKeys() + " WHERE " + r.apiKeyWhere("organization_id", 1) + " AND " + r.apiKeyWhere("api_key_id", 2)
+	rows, err := t.Query(query, t.OrganizationID(), apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := scanOrganizationAPIKeys(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// FindAPIKeyByHash returns the key with keyHash, or nil when there is none.
+// Revoked and expired keys are returned too; check Status. The key is what
+// tells the organization of the request, so the lookup spans organizations.
+func (r *OrganizationRepository) FindAPIKeyByHash(ctx context.Context, keyHash string) (*model.OrganizationAPIKey, error) {
+	query := r.selectAPIKeys() + " WHERE k.key_hash = " + r.getPlaceholder(1)
+	rows, err := database.QueryContext(ctx, r.db, query, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := scanOrganizationAPIKeys(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// TouchAPIKey records that a key of the organization was used. Writes are
+// limited to one a minute per key so busy integrations don't update the row
+// on every call.
+func (r *OrganizationRepository) TouchAPIKey(ctx context.Context, apiKeyID, ip string, usedAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE organization_api_keys k
+		SET last_used_at = %s, last_used_ip = %s
+		WHERE %s AND %s AND (k.last_used_at IS NULL OR k.last_used_at < %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.apiKeyWhere("organization_id", 3), r.apiKeyWhere("api_key_id", 4), r.getPlaceholder(5))
+	_, err = t.Exec(query, usedAt, nullableString(ip), t.OrganizationID(), apiKeyID, usedAt.Add(-time.Minute))
+	return err
+}
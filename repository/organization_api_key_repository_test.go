@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestOrganizationAPIKeyRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOrganizationRepository(openTenantFake(t), "postgres")
+	const apiKeyOfB = "api-key-of-b"
+	newKey := func(ctx context.Context) *model.OrganizationAPIKey {
+		organizationID, _ := database.OrganizationFromContext(ctx)
+		return &model.OrganizationAPIKey{APIKeyID: "api-key-1", OrganizationID: organizationID, Name: "erp"}
+	}
+	checkTenantIsolation(t, tenantCalls{
+		"CreateAPIKey": func(ctx context.Context) error {
+			return r.CreateAPIKey(ctx, newKey(ctx), "hash", nil, time.Now())
+		},
+		"RotateAPIKey": func(ctx context.Context) error {
+			return r.RotateAPIKey(ctx, apiKeyOfB, newKey(ctx), "hash", nil, time.Now())
+		},
+		"RevokeAPIKey": func(ctx context.Context) error { return r.RevokeAPIKey(ctx, apiKeyOfB, employeeOfB, time.Now()) },
+		"ListAPIKeys":  func(ctx context.Context) error { _, err := r.ListAPIKeys(ctx); return err },
+		"GetAPIKey":    func(ctx context.Context) error { _, err := r.GetAPIKey(ctx, apiKeyOfB); return err },
+		"TouchAPIKey": func(ctx context.Context) error {
+			return r.TouchAPIKey(ctx, apiKeyOfB, "10.0.0.1", time.Now())
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"CreateAPIKey": func() error {
+			return r.CreateAPIKey(ctxA, &model.OrganizationAPIKey{APIKeyID: "api-key-1", OrganizationID: tenantB}, "hash", nil, time.Now())
+		},
+	})
+}
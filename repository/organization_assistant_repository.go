@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"service-travego/database"
 	"service-travego/model"
 	"strings"
@@ -50,7 +52,12 @@ func (r *OrganizationRepository) employeeUUIDColumn() string {
 	return "uuid::text"
 }
 
-func (r *OrganizationRepository) CountActiveAssistantAccounts(organizationID string) (int, error) {
+func (r *OrganizationRepository) CountActiveAssistantAccounts(ctx context.Context) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT COUNT(*)
 		FROM assistant_accounts
@@ -59,40 +66,25 @@ func (r *OrganizationRepository) CountActiveAssistantAccounts(organizationID str
 	`, r.assistantOrgWhere("", 1))
 
 	var total int
-	if err := database.QueryRow(r.db, query, organizationID).Scan(&total); err != nil {
+	if err := t.QueryRow(query, organizationID).Scan(&total); err != nil {
 		return 0, err
 	}
 
 	return total, nil
 }
 
-func (r *OrganizationRepository) GetAssistantAccountLimit(organizationID string) (int, error) {
-	query := fmt.Sprintf(`
-		SELECT p.assistant_account_limit
-		FROM _subscription s
-		INNER JOIN _packages p ON s.package_id = p.package_id
-		WHERE %s
-		LIMIT 1
-	`, r.assistantOrgWhere("s", 1))
-
-	var limit sql.NullInt64
-	if err := database.QueryRow(r.db, query, organizationID).Scan(&limit); err != nil {
-		if err == sql.ErrNoRows {
-			return 0, nil
-		}
-		return 0, err
-	}
-	if !limit.Valid {
-		return 0, nil
+func (r *OrganizationRepository) ListAssistantAccounts(ctx context.Context) ([]model.AssistantAccountListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
 	}
-
-	return int(limit.Int64), nil
-}
-
-func (r *OrganizationRepository) ListAssistantAccounts(organizationID string) ([]model.AssistantAccountListItem, error) {
+	organizationID := t.OrganizationID()
 	employeeJoinExpr := "e.uuid = aa.user_id"
-	roleJoinExpr := "e.role_id = orl.role_id"
-	divisionJoinExpr := "orl.division_id = od.division_id"
+	roleOrgExpr, args := r.sharedOrganizationFilter("orl.organization_id", organizationID, nil)
+	divisionOrgExpr, args := r.sharedOrganizationFilter("od.organization_id", organizationID, args)
+	roleJoinExpr := "e.role_id = orl.role_id AND " + roleOrgExpr
+	divisionJoinExpr := "orl.division_id = od.division_id AND " + divisionOrgExpr
+	args = append(args, organizationID, organizationID, organizationID)
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -149,14 +141,14 @@ func (r *OrganizationRepository) ListAssistantAccounts(organizationID string) ([
 		employeeJoinExpr,
 		roleJoinExpr,
 		divisionJoinExpr,
-		r.assistantOrgWhere("aa", 1),
-		r.assistantOrgWhere("e", 2),
+		r.assistantOrgWhere("aa", 7),
+		r.assistantOrgWhere("e", 8),
 		r.assistantIDColumn(),
-		r.assistantOrgWhere("aa", 3),
+		r.assistantOrgWhere("aa", 9),
 	)
-	fmt.Println(query)
+	slog.DebugContext(ctx, "list assistant accounts", "query", query)
 
-	rows, err := database.Query(r.db, query, organizationID, organizationID, organizationID)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -195,7 +187,12 @@ func (r *OrganizationRepository) ListAssistantAccounts(organizationID string) ([
 	return out, nil
 }
 
-func (r *OrganizationRepository) GetAssistantEmployeeTarget(organizationID, employeeID string) (*model.AssistantEmployeeTarget, error) {
+func (r *OrganizationRepository) GetAssistantEmployeeTarget(ctx context.Context, employeeID string) (*model.AssistantEmployeeTarget, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT
 			COALESCE(%s, ''),
@@ -208,10 +205,10 @@ func (r *OrganizationRepository) GetAssistantEmployeeTarget(organizationID, empl
 		  AND COALESCE(status, 0) > 0
 		LIMIT 1
 	`, r.employeeUUIDColumn(), r.getPlaceholder(1), r.assistantOrgWhere("", 2))
-	fmt.Println(query)
+	slog.DebugContext(ctx, "get assistant employee target", "query", query)
 
 	var item model.AssistantEmployeeTarget
-	if err := database.QueryRow(r.db, query, employeeID, organizationID).Scan(
+	if err := t.QueryRow(query, employeeID, organizationID).Scan(
 		&item.UUID,
 		&item.EmployeeID,
 		&item.Fullname,
@@ -223,7 +220,12 @@ func (r *OrganizationRepository) GetAssistantEmployeeTarget(organizationID, empl
 	return &item, nil
 }
 
-func (r *OrganizationRepository) CreateAssistantAccount(organizationID, createdBy string, userType int, userID *string, accountNumber, accountName string) (string, error) {
+func (r *OrganizationRepository) CreateAssistantAccount(ctx context.Context, createdBy string, userType int, userID *string, accountNumber, accountName string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	assistantID := uuid.New().String()
 	now := time.Now()
 
@@ -257,8 +259,7 @@ func (r *OrganizationRepository) CreateAssistantAccount(organizationID, createdB
 		r.getPlaceholder(8),
 	)
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		assistantID,
 		organizationID,
@@ -276,7 +277,12 @@ func (r *OrganizationRepository) CreateAssistantAccount(organizationID, createdB
 	return assistantID, nil
 }
 
-func (r *OrganizationRepository) GetAssistantAccountByID(organizationID, assistantID string) (*model.AssistantAccountListItem, error) {
+func (r *OrganizationRepository) GetAssistantAccountByID(ctx context.Context, assistantID string) (*model.AssistantAccountListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT
 			COALESCE(%s, ''),
@@ -297,7 +303,7 @@ func (r *OrganizationRepository) GetAssistantAccountByID(organizationID, assista
 
 	var item model.AssistantAccountListItem
 	var createdAt sql.NullTime
-	if err := database.QueryRow(r.db, query, assistantID, organizationID).Scan(
+	if err := t.QueryRow(query, assistantID, organizationID).Scan(
 		&item.AssistantID,
 		&item.EmployeeID,
 		&createdAt,
@@ -318,7 +324,12 @@ func (r *OrganizationRepository) GetAssistantAccountByID(organizationID, assista
 	return &item, nil
 }
 
-func (r *OrganizationRepository) UpdateAssistantAccount(organizationID, assistantID string, accountName, accountNumber *string) error {
+func (r *OrganizationRepository) UpdateAssistantAccount(ctx context.Context, assistantID string, accountName, accountNumber *string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	setParts := make([]string, 0, 2)
 	args := make([]interface{}, 0, 4)
 	pos := 1
@@ -351,7 +362,7 @@ func (r *OrganizationRepository) UpdateAssistantAccount(organizationID, assistan
 
 	args = append(args, assistantID, organizationID)
 
-	result, err := database.Exec(r.db, query, args...)
+	result, err := t.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -367,7 +378,12 @@ func (r *OrganizationRepository) UpdateAssistantAccount(organizationID, assistan
 	return nil
 }
 
-func (r *OrganizationRepository) DeleteAssistantAccountByUserID(organizationID, userID string) error {
+func (r *OrganizationRepository) DeleteAssistantAccountByUserID(ctx context.Context, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		UPDATE assistant_accounts
 		SET status = 0
@@ -376,7 +392,7 @@ func (r *OrganizationRepository) DeleteAssistantAccountByUserID(organizationID,
 		  AND status = 1
 	`, r.assistantUserWhere(1), r.assistantOrgWhere("", 2))
 
-	result, err := database.Exec(r.db, query, userID, organizationID)
+	result, err := t.Exec(query, userID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -392,11 +408,16 @@ func (r *OrganizationRepository) DeleteAssistantAccountByUserID(organizationID,
 	return nil
 }
 
-func (r *OrganizationRepository) GetAssistantWhatsAppBusinessList(organizationID string) (*model.AssistantWhatsAppBusinessListResponse, error) {
+func (r *OrganizationRepository) GetAssistantWhatsAppBusinessList(ctx context.Context) (*model.AssistantWhatsAppBusinessListResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`SELECT account as account_number, COALESCE(device_id, '') as device_id, COALESCE(device_name, '') as device_name, COALESCE(device_token, '') as device_token FROM assistant_customers WHERE organization_id = %s LIMIT 1`, r.getPlaceholder(1))
 
 	var item model.AssistantWhatsAppBusinessListResponse
-	err := database.QueryRow(r.db, query, organizationID).Scan(&item.AccountNumber, &item.DeviceID, &item.DeviceName, &item.DeviceToken)
+	err = t.QueryRow(query, organizationID).Scan(&item.AccountNumber, &item.DeviceID, &item.DeviceName, &item.DeviceToken)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -408,15 +429,25 @@ func (r *OrganizationRepository) GetAssistantWhatsAppBusinessList(organizationID
 	return &item, nil
 }
 
-func (r *OrganizationRepository) CreateAssistantWhatsappBusiness(organizationID, accountNumber string) error {
+func (r *OrganizationRepository) CreateAssistantWhatsappBusiness(ctx context.Context, accountNumber string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`INSERT INTO assistant_customers (account, organization_id) VALUES (%s, %s)`, r.getPlaceholder(1), r.getPlaceholder(2))
-	_, err := database.Exec(r.db, query, accountNumber, organizationID)
+	_, err = t.Exec(query, accountNumber, organizationID)
 	return err
 }
 
-func (r *OrganizationRepository) UpdateAssistantWhatsappBusiness(organizationID, accountNumber string) error {
+func (r *OrganizationRepository) UpdateAssistantWhatsappBusiness(ctx context.Context, accountNumber string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`UPDATE assistant_customers SET account = %s WHERE organization_id = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
-	result, err := database.Exec(r.db, query, accountNumber, organizationID)
+	result, err := t.Exec(query, accountNumber, organizationID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOrganizationAssistantRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOrganizationRepository(openTenantFake(t), "postgres")
+	const assistantOfB = "assistant-of-b"
+	accountName := "Admin"
+	checkTenantIsolation(t, tenantCalls{
+		"CountActiveAssistantAccounts": func(ctx context.Context) error {
+			_, err := r.CountActiveAssistantAccounts(ctx)
+			return err
+		},
+		"ListAssistantAccounts": func(ctx context.Context) error { _, err := r.ListAssistantAccounts(ctx); return err },
+		"GetAssistantEmployeeTarget": func(ctx context.Context) error {
+			_, err := r.GetAssistantEmployeeTarget(ctx, employeeOfB)
+			return err
+		},
+		"CreateAssistantAccount": func(ctx context.Context) error {
+			_, err := r.CreateAssistantAccount(ctx, employeeOfB, 1, nil, "6281200000000", "Admin")
+			return err
+		},
+		"GetAssistantAccountByID": func(ctx context.Context) error {
+			_, err := r.GetAssistantAccountByID(ctx, assistantOfB)
+			return err
+		},
+		"UpdateAssistantAccount": func(ctx context.Context) error {
+			return r.UpdateAssistantAccount(ctx, assistantOfB, &accountName, nil)
+		},
+		"DeleteAssistantAccountByUserID": func(ctx context.Context) error {
+			return r.DeleteAssistantAccountByUserID(ctx, employeeOfB)
+		},
+		"GetAssistantWhatsAppBusinessList": func(ctx context.Context) error {
+			_, err := r.GetAssistantWhatsAppBusinessList(ctx)
+			return err
+		},
+		"CreateAssistantWhatsappBusiness": func(ctx context.Context) error {
+			return r.CreateAssistantWhatsappBusiness(ctx, "6281200000000")
+		},
+		"UpdateAssistantWhatsappBusiness": func(ctx context.Context) error {
+			return r.UpdateAssistantWhatsappBusiness(ctx, "6281200000000")
+		},
+	})
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"os"
 	"service-travego/database"
 	"service-travego/model"
@@ -56,15 +58,43 @@ func (r *OrganizationRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-// FindByID retrieves organization
-func (r *OrganizationRepository) FindByID(id string) (*model.Organization, error) {
-	query := fmt.Sprintf(`
+// sharedOrganizationFilter limits column to the organization and the default
+// organizations that own the built in roles and divisions, binding them after args
+func (r *OrganizationRepository) sharedOrganizationFilter(column, organizationID string, args []interface{}) (string, []interface{}) {
+	if r.driver != "mysql" {
+		column += "::text"
+	}
+	pos := len(args) + 1
+	args = append(args, organizationID, "00000000-0000-0000-0000-000000000000", "000")
+	return fmt.Sprintf("%s IN (%s, %s, %s)", column, r.getPlaceholder(pos), r.getPlaceholder(pos+1), r.getPlaceholder(pos+2)), args
+}
+
+// organizationQuery selects an organization by organization_id
+func (r *OrganizationRepository) organizationQuery() string {
+	return fmt.Sprintf(`
         SELECT organization_id, organization_code, organization_name, company_name, address, city, province,
                phone, whatsapp, email, npwp_number, organization_type, postal_code, organization_lat, organization_lng, address_label, domain_url, COALESCE(logo, ''), created_by, created_at, updated_at
         FROM organizations
         WHERE organization_id = %s
     `, r.getPlaceholder(1))
+}
+
+// FindByID retrieves organization by ID, of any organization: the auth
+// middleware resolves the organization of a request with it
+func (r *OrganizationRepository) FindByID(ctx context.Context, id string) (*model.Organization, error) {
+	return r.scanOrganization(ctx, database.QueryRowContext(ctx, r.db, r.organizationQuery(), id), id)
+}
+
+// FindCurrent retrieves the organization of the request
+func (r *OrganizationRepository) FindCurrent(ctx context.Context) (*model.Organization, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanOrganization(ctx, t.QueryRow(r.organizationQuery(), t.OrganizationID()), t.OrganizationID())
+}
 
+func (r *OrganizationRepository) scanOrganization(ctx context.Context, row interface{ Scan(...interface{}) error }, id string) (*model.Organization, error) {
 	var org model.Organization
 	var companyName sql.NullString
 	var npwpNumber sql.NullString
@@ -76,7 +106,7 @@ func (r *OrganizationRepository) FindByID(id string) (*model.Organization, error
 	var whatsApp sql.NullString
 	var phone sql.NullString
 	var logo sql.NullString
-	err := database.QueryRow(r.db, query, id).Scan(
+	err := row.Scan(
 		&org.OrganizationId,
 		&org.OrganizationCode,
 		&org.OrganizationName,
@@ -133,7 +163,7 @@ func (r *OrganizationRepository) FindByID(id string) (*model.Organization, error
 	}
 	if err != nil {
 		if err == sql.ErrNoRows {
-			fmt.Println("No organization found with ID:", id)
+			slog.DebugContext(ctx, "no organization found", "organization_id", id)
 			return nil, sql.ErrNoRows
 		}
 		return nil, err
@@ -142,8 +172,8 @@ func (r *OrganizationRepository) FindByID(id string) (*model.Organization, error
 	return &org, nil
 }
 
-// FindByCode retrieves organization
-func (r *OrganizationRepository) FindByCode(code string) (*model.Organization, error) {
+// FindByCode retrieves organization by code, of any organization, for joining one
+func (r *OrganizationRepository) FindByCode(ctx context.Context, code string) (*model.Organization, error) {
 	query := fmt.Sprintf(`
         SELECT organization_id, organization_code, organization_name, company_name, address, city, province,
                phone, whatsapp, email, created_at, updated_at
@@ -153,7 +183,7 @@ func (r *OrganizationRepository) FindByCode(code string) (*model.Organization, e
 
 	var org model.Organization
 	var whatsApp sql.NullString
-	err := database.QueryRow(r.db, query, code).Scan(
+	err := database.QueryRowContext(ctx, r.db, query, code).Scan(
 		&org.OrganizationId,
 		&org.OrganizationCode,
 		&org.OrganizationName,
@@ -183,8 +213,9 @@ func (r *OrganizationRepository) FindByCode(code string) (*model.Organization, e
 	return &org, nil
 }
 
-// FindByUsernameUserIDByUserID retrieves organizations
-func (r *OrganizationRepository) FindByUsernameUserID(userID string) ([]model.Organization, error) {
+// FindByUsernameUserID retrieves the organizations userID created; they are
+// looked up across organizations, as a user may have created several
+func (r *OrganizationRepository) FindByUsernameUserID(ctx context.Context, userID string) ([]model.Organization, error) {
 	query := fmt.Sprintf(`
 		SELECT organization_id, organization_code, organization_name, company_name, address, city, province,
 		       phone, email, npwp_number, organization_type, postal_code, domain_url, created_by, created_at, updated_at
@@ -192,9 +223,9 @@ func (r *OrganizationRepository) FindByUsernameUserID(userID string) ([]model.Or
 		WHERE created_by = (SELECT user_id FROM users WHERE user_id = %s)
 		ORDER BY created_at DESC
 	`, r.getPlaceholder(1))
-	fmt.Println(query, userID)
+	slog.DebugContext(ctx, "find by username user id", "query", query, "user_id", userID)
 
-	rows, err := database.Query(r.db, query, userID)
+	rows, err := database.QueryContext(ctx, r.db, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -246,8 +277,8 @@ func (r *OrganizationRepository) FindByUsernameUserID(userID string) ([]model.Or
 	return orgs, nil
 }
 
-// Create inserts organization
-func (r *OrganizationRepository) Create(org *model.Organization) (*model.Organization, error) {
+// Create inserts organization; it is not scoped, the organization is new
+func (r *OrganizationRepository) Create(ctx context.Context, org *model.Organization) (*model.Organization, error) {
 	now := time.Now()
 	org.CreatedAt = now
 	org.UpdatedAt = now
@@ -272,8 +303,7 @@ func (r *OrganizationRepository) Create(org *model.Organization) (*model.Organiz
 			r.getPlaceholder(13),
 		)
 
-		err := database.QueryRow(
-			r.db,
+		err := database.QueryRowContext(ctx, r.db,
 			query,
 			org.OrganizationId,
 			org.OrganizationCode,
@@ -293,7 +323,7 @@ func (r *OrganizationRepository) Create(org *model.Organization) (*model.Organiz
 		).Scan(&org.CreatedAt, &org.UpdatedAt)
 
 		if err != nil {
-			fmt.Println("Error creating organization on if clause:", err.Error())
+			slog.ErrorContext(ctx, "error creating organization", "error", err)
 			return nil, err
 		}
 	} else {
@@ -315,8 +345,7 @@ func (r *OrganizationRepository) Create(org *model.Organization) (*model.Organiz
 			r.getPlaceholder(13),
 		)
 
-		_, err := database.Exec(
-			r.db,
+		_, err := database.ExecContext(ctx, r.db,
 			query,
 			org.OrganizationId,
 			org.OrganizationCode,
@@ -335,7 +364,7 @@ func (r *OrganizationRepository) Create(org *model.Organization) (*model.Organiz
 			org.UpdatedAt,
 		)
 		if err != nil {
-			fmt.Println("Error creating organization else clause:", err.Error())
+			slog.ErrorContext(ctx, "error creating organization", "error", err)
 			return nil, err
 		}
 	}
@@ -344,7 +373,12 @@ func (r *OrganizationRepository) Create(org *model.Organization) (*model.Organiz
 }
 
 // GetBankAccountByID retrieves bank account
-func (r *OrganizationRepository) GetBankAccountByID(bankAccountID, organizationID string) (*model.OrganizationBankAccountResponse, error) {
+func (r *OrganizationRepository) GetBankAccountByID(ctx context.Context, bankAccountID string) (*model.OrganizationBankAccountResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT 
 			oba.bank_account_id, oba.bank_code, oba.account_number, oba.account_name,
@@ -365,7 +399,7 @@ func (r *OrganizationRepository) GetBankAccountByID(bankAccountID, organizationI
 	var merchantID, merchantNMID, merchantMCC, merchantAddress, merchantCity, merchantPostalCode sql.NullString
 	var accountType sql.NullInt32
 
-	err := database.QueryRow(r.db, query, bankAccountID, organizationID).Scan(
+	err = t.QueryRow(query, bankAccountID, organizationID).Scan(
 		&acc.BankAccountID,
 		&acc.BankCode,
 		&acc.AccountNumber,
@@ -423,7 +457,12 @@ func (r *OrganizationRepository) GetBankAccountByID(bankAccountID, organizationI
 }
 
 // GetBankAccounts retrieves bank accounts
-func (r *OrganizationRepository) GetBankAccounts(organizationID string) ([]model.OrganizationBankAccountResponse, error) {
+func (r *OrganizationRepository) GetBankAccounts(ctx context.Context) ([]model.OrganizationBankAccountResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT 
 			oba.bank_account_id, oba.bank_code, oba.account_number, oba.account_name,
@@ -441,7 +480,7 @@ func (r *OrganizationRepository) GetBankAccounts(organizationID string) ([]model
 		ORDER BY oba.created_at DESC
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, organizationID)
+	rows, err := t.Query(query, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -511,7 +550,12 @@ func (r *OrganizationRepository) GetBankAccounts(organizationID string) ([]model
 }
 
 // CreateBankAccount inserts bank account
-func (r *OrganizationRepository) CreateBankAccount(req *model.CreateOrganizationBankAccountRequest, organizationID, createdBy, createdProxy, createdIP string) error {
+func (r *OrganizationRepository) CreateBankAccount(ctx context.Context, req *model.CreateOrganizationBankAccountRequest, createdBy, createdProxy, createdIP string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	bankAccountID := uuid.New().String()
 	createdAt := time.Now()
 
@@ -543,7 +587,7 @@ func (r *OrganizationRepository) CreateBankAccount(req *model.CreateOrganization
 	// Since we don't know for sure which one is integer, we can try to pass NULL if empty
 	// Note: sql.NullString with Valid=false passes NULL, which is compatible with Integer columns in Postgres (if nullable)
 
-	_, err := database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		bankAccountID,
 		req.BankCode,
 		req.AccountNumber,
@@ -565,7 +609,11 @@ func (r *OrganizationRepository) CreateBankAccount(req *model.CreateOrganization
 }
 
 // Update updates organization
-func (r *OrganizationRepository) Update(org *model.Organization) (*model.Organization, error) {
+func (r *OrganizationRepository) Update(ctx context.Context, org *model.Organization) (*model.Organization, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	org.UpdatedAt = time.Now()
 
 	if r.driver == "postgres" {
@@ -579,8 +627,7 @@ func (r *OrganizationRepository) Update(org *model.Organization) (*model.Organiz
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 			r.getPlaceholder(9))
 
-		err := database.QueryRow(
-			r.db,
+		err := t.QueryRow(
 			query,
 			org.OrganizationName,
 			org.CompanyName,
@@ -609,8 +656,7 @@ func (r *OrganizationRepository) Update(org *model.Organization) (*model.Organiz
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 			r.getPlaceholder(9))
 
-		_, err := database.Exec(
-			r.db,
+		_, err := t.Exec(
 			query,
 			org.OrganizationName,
 			org.CompanyName,
@@ -631,7 +677,12 @@ func (r *OrganizationRepository) Update(org *model.Organization) (*model.Organiz
 }
 
 // UpdateByID updates organization with dynamic fields
-func (r *OrganizationRepository) UpdateByID(orgID string, updates map[string]interface{}) error {
+func (r *OrganizationRepository) UpdateByID(ctx context.Context, updates map[string]interface{}) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	updatedAt := time.Now()
 
 	var setParts []string
@@ -660,7 +711,7 @@ func (r *OrganizationRepository) UpdateByID(orgID string, updates map[string]int
 
 	args = append(args, orgID)
 
-	res, err := database.Exec(r.db, query, args...)
+	res, err := t.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -675,10 +726,15 @@ func (r *OrganizationRepository) UpdateByID(orgID string, updates map[string]int
 }
 
 // GetDomainURL retrieves domain_url
-func (r *OrganizationRepository) GetDomainURL(orgID string) (string, error) {
+func (r *OrganizationRepository) GetDomainURL(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT domain_url FROM organizations WHERE organization_id = %s", r.getPlaceholder(1))
 	var domainURL sql.NullString
-	err := database.QueryRow(r.db, query, orgID).Scan(&domainURL)
+	err = t.QueryRow(query, orgID).Scan(&domainURL)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
@@ -691,7 +747,12 @@ func (r *OrganizationRepository) GetDomainURL(orgID string) (string, error) {
 	return "", nil
 }
 
-func (r *OrganizationRepository) GetOrganizationEmailAndName(orgID string) (string, string, string, error) {
+func (r *OrganizationRepository) GetOrganizationEmailAndName(ctx context.Context) (string, string, string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", "", "", err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT
 			COALESCE(email, ''),
@@ -703,7 +764,7 @@ func (r *OrganizationRepository) GetOrganizationEmailAndName(orgID string) (stri
 	`, r.getPlaceholder(1))
 
 	var email, organizationName, domainURL string
-	if err := database.QueryRow(r.db, query, orgID).Scan(&email, &organizationName, &domainURL); err != nil {
+	if err := t.QueryRow(query, orgID).Scan(&email, &organizationName, &domainURL); err != nil {
 		if err == sql.ErrNoRows {
 			return "", "", "", nil
 		}
@@ -712,7 +773,12 @@ func (r *OrganizationRepository) GetOrganizationEmailAndName(orgID string) (stri
 	return email, organizationName, domainURL, nil
 }
 
-func (r *OrganizationRepository) GetAdminAccountNumber(organizationID string) (string, error) {
+func (r *OrganizationRepository) GetAdminAccountNumber(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT COALESCE(account_number, '')
 		FROM assistant_accounts
@@ -722,7 +788,7 @@ func (r *OrganizationRepository) GetAdminAccountNumber(organizationID string) (s
 	`, r.assistantOrgWhere("", 1))
 
 	var accountNumber string
-	if err := database.QueryRow(r.db, query, organizationID).Scan(&accountNumber); err != nil {
+	if err := t.QueryRow(query, organizationID).Scan(&accountNumber); err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
 		}
@@ -733,22 +799,68 @@ func (r *OrganizationRepository) GetAdminAccountNumber(organizationID string) (s
 }
 
 // UpdateDomainURL updates domain_url
-func (r *OrganizationRepository) UpdateDomainURL(orgID string, domainURL string) error {
+func (r *OrganizationRepository) UpdateDomainURL(ctx context.Context, domainURL string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("UPDATE organizations SET domain_url = %s, updated_at = %s WHERE organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	_, err := database.Exec(r.db, query, domainURL, time.Now(), orgID)
+	_, err = t.Exec(query, domainURL, time.Now(), orgID)
+	return err
+}
+
+// GetPaymentGateway retrieves payment_gateway; empty when the organization has not chosen one
+func (r *OrganizationRepository) GetPaymentGateway(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf("SELECT COALESCE(payment_gateway, '') FROM organizations WHERE organization_id = %s", r.getPlaceholder(1))
+	var provider string
+	err = t.QueryRow(query, orgID).Scan(&provider)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return provider, nil
+}
+
+// UpdatePaymentGateway updates payment_gateway
+func (r *OrganizationRepository) UpdatePaymentGateway(ctx context.Context, provider string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	query := fmt.Sprintf("UPDATE organizations SET payment_gateway = %s, updated_at = %s WHERE organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	_, err = t.Exec(query, provider, time.Now(), orgID)
 	return err
 }
 
 // UpdateLogo updates logo path
-func (r *OrganizationRepository) UpdateLogo(orgID string, logoPath string) error {
+func (r *OrganizationRepository) UpdateLogo(ctx context.Context, logoPath string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("UPDATE organizations SET logo = %s, updated_at = %s WHERE organization_id = %s",
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	_, err := database.Exec(r.db, query, logoPath, time.Now(), orgID)
+	_, err = t.Exec(query, logoPath, time.Now(), orgID)
 	return err
 }
 
 // UpdateBankAccount updates bank account
-func (r *OrganizationRepository) UpdateBankAccount(bankAccountID, organizationID string, active *bool, accountNumber, accountName, updatedProxy, updatedIP string) error {
+func (r *OrganizationRepository) UpdateBankAccount(ctx context.Context, bankAccountID string, active *bool, accountNumber, accountName, updatedProxy, updatedIP string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	var query string
 	var args []interface{}
 
@@ -762,7 +874,7 @@ func (r *OrganizationRepository) UpdateBankAccount(bankAccountID, organizationID
 		args = append(args, accountNumber, accountName, time.Now(), updatedProxy, updatedIP, bankAccountID, organizationID)
 	}
 
-	result, err := database.Exec(r.db, query, args...)
+	result, err := t.Exec(query, args...)
 	if err != nil {
 		return err
 	}
@@ -780,7 +892,12 @@ func (r *OrganizationRepository) UpdateBankAccount(bankAccountID, organizationID
 }
 
 // GetPaymentMethods retrieves payment methods
-func (r *OrganizationRepository) GetPaymentMethods(organizationID string) (*model.PaymentMethodGroupedResponse, error) {
+func (r *OrganizationRepository) GetPaymentMethods(ctx context.Context) (*model.PaymentMethodGroupedResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT 
 			COALESCE(bl.icon, '') as icon,
@@ -794,7 +911,7 @@ func (r *OrganizationRepository) GetPaymentMethods(organizationID string) (*mode
 		WHERE oba.organization_id = %s AND oba.status = 1 AND oba.active = true
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, organizationID)
+	rows, err := t.Query(query, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -837,7 +954,12 @@ func (r *OrganizationRepository) GetPaymentMethods(organizationID string) (*mode
 }
 
 // CheckBankAccountExists checks bank account
-func (r *OrganizationRepository) CheckBankAccountExists(organizationID, bankCode string) (string, error) {
+func (r *OrganizationRepository) CheckBankAccountExists(ctx context.Context, bankCode string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT bl.name 
 		FROM organization_bank_accounts oba
@@ -846,7 +968,7 @@ func (r *OrganizationRepository) CheckBankAccountExists(organizationID, bankCode
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var bankName string
-	err := database.QueryRow(r.db, query, organizationID, bankCode).Scan(&bankName)
+	err = t.QueryRow(query, organizationID, bankCode).Scan(&bankName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", nil
@@ -857,11 +979,16 @@ func (r *OrganizationRepository) CheckBankAccountExists(organizationID, bankCode
 }
 
 // DeleteBankAccount soft deletes account
-func (r *OrganizationRepository) DeleteBankAccount(bankAccountID, organizationID string) error {
+func (r *OrganizationRepository) DeleteBankAccount(ctx context.Context, bankAccountID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf("UPDATE organization_bank_accounts SET status = 0, updated_at = %s WHERE bank_account_id = %s AND organization_id = %s",
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
-	result, err := database.Exec(r.db, query, time.Now(), bankAccountID, organizationID)
+	result, err := t.Exec(query, time.Now(), bankAccountID, organizationID)
 	if err != nil {
 		return err
 	}
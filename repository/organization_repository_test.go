@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+)
+
+func TestOrganizationRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOrganizationRepository(openTenantFake(t), "postgres")
+	const bankAccountOfB = "bank-account-of-b"
+	active := true
+	checkTenantIsolation(t, tenantCalls{
+		"FindCurrent": func(ctx context.Context) error { _, err := r.FindCurrent(ctx); return err },
+		"GetBankAccountByID": func(ctx context.Context) error {
+			_, err := r.GetBankAccountByID(ctx, bankAccountOfB)
+			return err
+		},
+		"GetBankAccounts": func(ctx context.Context) error { _, err := r.GetBankAccounts(ctx); return err },
+		"CreateBankAccount": func(ctx context.Context) error {
+			return r.CreateBankAccount(ctx, &model.CreateOrganizationBankAccountRequest{BankCode: "014"}, employeeOfB, "", "")
+		},
+		"UpdateByID": func(ctx context.Context) error {
+			return r.UpdateByID(ctx, map[string]interface{}{"organization_name": "Travego"})
+		},
+		"GetDomainURL": func(ctx context.Context) error { _, err := r.GetDomainURL(ctx); return err },
+		"GetOrganizationEmailAndName": func(ctx context.Context) error {
+			_, _, _, err := r.GetOrganizationEmailAndName(ctx)
+			return err
+		},
+		"GetAdminAccountNumber": func(ctx context.Context) error { _, err := r.GetAdminAccountNumber(ctx); return err },
+		"UpdateDomainURL": func(ctx context.Context) error {
+			return r.UpdateDomainURL(ctx, "https://travego.example")
+		},
+		"GetPaymentGateway":    func(ctx context.Context) error { _, err := r.GetPaymentGateway(ctx); return err },
+		"UpdatePaymentGateway": func(ctx context.Context) error { return r.UpdatePaymentGateway(ctx, "xendit") },
+		"UpdateLogo":           func(ctx context.Context) error { return r.UpdateLogo(ctx, "/assets/logo/b.png") },
+		"UpdateBankAccount": func(ctx context.Context) error {
+			return r.UpdateBankAccount(ctx, bankAccountOfB, &active, "", "", "", "")
+		},
+		"GetPaymentMethods": func(ctx context.Context) error { _, err := r.GetPaymentMethods(ctx); return err },
+		"CheckBankAccountExists": func(ctx context.Context) error {
+			_, err := r.CheckBankAccountExists(ctx, "014")
+			return err
+		},
+		"DeleteBankAccount": func(ctx context.Context) error { return r.DeleteBankAccount(ctx, bankAccountOfB) },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Update": func() error {
+			_, err := r.Update(ctxA, &model.Organization{OrganizationId: tenantB, OrganizationName: "Travego"})
+			return err
+		},
+	})
+}
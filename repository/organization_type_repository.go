@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -28,14 +29,14 @@ func (r *OrganizationTypeRepository) getPlaceholder(pos int) string {
 }
 
 // FindAll retrieves organization types
-func (r *OrganizationTypeRepository) FindAll() ([]model.OrganizationType, error) {
+func (r *OrganizationTypeRepository) FindAll(ctx context.Context) ([]model.OrganizationType, error) {
 	query := `
         SELECT id, name
         FROM organization_types
         ORDER BY name ASC
     `
 
-	rows, err := database.Query(r.db, query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +59,7 @@ func (r *OrganizationTypeRepository) FindAll() ([]model.OrganizationType, error)
 }
 
 // FindByID retrieves org type
-func (r *OrganizationTypeRepository) FindByID(id int) (*model.OrganizationType, error) {
+func (r *OrganizationTypeRepository) FindByID(ctx context.Context, id int) (*model.OrganizationType, error) {
 	query := fmt.Sprintf(`
         SELECT id, name
         FROM organization_types
@@ -66,7 +67,7 @@ func (r *OrganizationTypeRepository) FindByID(id int) (*model.OrganizationType,
     `, r.getPlaceholder(1))
 
 	var orgType model.OrganizationType
-	err := database.QueryRow(r.db, query, id).Scan(&orgType.ID, &orgType.Name)
+	err := database.QueryRowContext(ctx, r.db, query, id).Scan(&orgType.ID, &orgType.Name)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -30,8 +31,9 @@ func (r *OrganizationUserRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-// GetOrganizationAndRoleByUserID retrieves organization_id
-func (r *OrganizationUserRepository) GetOrganizationAndRoleByUserID(userID string) (organizationID string, roleUser int, err error) {
+// GetOrganizationAndRoleByUserID retrieves organization_id; it is how a
+// user's organization is found, so it spans organizations
+func (r *OrganizationUserRepository) GetOrganizationAndRoleByUserID(ctx context.Context, userID string) (organizationID string, roleUser int, err error) {
 	query := fmt.Sprintf(`
 		SELECT organization_id, organization_role
 		FROM organization_users
@@ -39,7 +41,7 @@ func (r *OrganizationUserRepository) GetOrganizationAndRoleByUserID(userID strin
 		LIMIT 1
 	`, r.getPlaceholder(1))
 
-	err = database.QueryRow(r.db, query, userID).Scan(&organizationID, &roleUser)
+	err = database.QueryRowContext(ctx, r.db, query, userID).Scan(&organizationID, &roleUser)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", 0, sql.ErrNoRows
@@ -51,7 +53,12 @@ func (r *OrganizationUserRepository) GetOrganizationAndRoleByUserID(userID strin
 }
 
 // CheckUserInOrganization checks user existence
-func (r *OrganizationUserRepository) CheckUserInOrganization(userID, organizationID string) (bool, error) {
+func (r *OrganizationUserRepository) CheckUserInOrganization(ctx context.Context, userID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT COUNT(*) 
 		FROM organization_users
@@ -59,7 +66,7 @@ func (r *OrganizationUserRepository) CheckUserInOrganization(userID, organizatio
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var count int
-	err := database.QueryRow(r.db, query, userID, organizationID).Scan(&count)
+	err = t.QueryRow(query, userID, organizationID).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -67,7 +74,11 @@ func (r *OrganizationUserRepository) CheckUserInOrganization(userID, organizatio
 	return count > 0, nil
 }
 
-func (r *OrganizationUserRepository) CreateOrganizationUser(orgUser *model.OrganizationUser) error {
+func (r *OrganizationUserRepository) CreateOrganizationUser(ctx context.Context, orgUser *model.OrganizationUser) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	query := fmt.Sprintf(`
         INSERT INTO organization_users (
             uuid, user_id, organization_id, organization_role, is_active, created_at, created_by, updated_at, updated_by
@@ -77,8 +88,7 @@ func (r *OrganizationUserRepository) CreateOrganizationUser(orgUser *model.Organ
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9),
 	)
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		orgUser.UUID,
 		orgUser.UserID,
@@ -94,12 +104,17 @@ func (r *OrganizationUserRepository) CreateOrganizationUser(orgUser *model.Organ
 }
 
 // CreateSubscription inserts a new subscription record
-func (r *OrganizationUserRepository) CreateSubscription(orgID string) error {
-	return r.CreateSubscriptionWithDuration(orgID, 30)
+func (r *OrganizationUserRepository) CreateSubscription(ctx context.Context) error {
+	return r.CreateSubscriptionWithDuration(ctx, 30)
 }
 
 // CreateSubscriptionWithDuration inserts a new subscription record
-func (r *OrganizationUserRepository) CreateSubscriptionWithDuration(orgID string, expiryDays int) error {
+func (r *OrganizationUserRepository) CreateSubscriptionWithDuration(ctx context.Context, expiryDays int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	subscriptionID := uuid.New().String()
 	now := time.Now()
 	activateDate := now.Format("2006-01-02")
@@ -117,8 +132,7 @@ func (r *OrganizationUserRepository) CreateSubscriptionWithDuration(orgID string
 		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 	)
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		subscriptionID,
 		orgID,
@@ -133,26 +147,36 @@ func (r *OrganizationUserRepository) CreateSubscriptionWithDuration(orgID string
 }
 
 // UpdateOrganizationUserRole updates role
-func (r *OrganizationUserRepository) UpdateOrganizationUserRole(userID, organizationID string, role int) error {
+func (r *OrganizationUserRepository) UpdateOrganizationUserRole(ctx context.Context, userID string, role int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		UPDATE organization_users
 		SET organization_role = %s, updated_at = %s
 		WHERE user_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	_, err := database.Exec(r.db, query, role, time.Now(), userID, organizationID)
+	_, err = t.Exec(query, role, time.Now(), userID, organizationID)
 	return err
 }
 
 // GetUsersByOrganizationID retrieves users
-func (r *OrganizationUserRepository) GetUsersByOrganizationID(organizationID string) ([]model.OrganizationUser, error) {
+func (r *OrganizationUserRepository) GetUsersByOrganizationID(ctx context.Context) ([]model.OrganizationUser, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT uuid, user_id, organization_id, organization_role, is_active, created_at, created_by, updated_at, updated_by
 		FROM organization_users
 		WHERE organization_id = %s
 	`, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, organizationID)
+	rows, err := t.Query(query, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +209,9 @@ func (r *OrganizationUserRepository) GetUsersByOrganizationID(organizationID str
 	return orgUsers, nil
 }
 
-// GetOrganizationWithJoinDateByUserID retrieves data
-func (r *OrganizationUserRepository) GetOrganizationWithJoinDateByUserID(userID string) (organizationCode, organizationName, companyName string, joinDate time.Time, organizationRole int, err error) {
+// GetOrganizationWithJoinDateByUserID retrieves data of the user's
+// organization, looked up across organizations
+func (r *OrganizationUserRepository) GetOrganizationWithJoinDateByUserID(ctx context.Context, userID string) (organizationCode, organizationName, companyName string, joinDate time.Time, organizationRole int, err error) {
 	query := fmt.Sprintf(`
 		SELECT o.organization_code, o.organization_name, o.company_name, ou.created_at, ou.organization_role
 		FROM organization_users ou
@@ -196,7 +221,7 @@ func (r *OrganizationUserRepository) GetOrganizationWithJoinDateByUserID(userID
 		LIMIT 1
 	`, r.getPlaceholder(1))
 
-	err = database.QueryRow(r.db, query, userID).Scan(&organizationCode, &organizationName, &companyName, &joinDate, &organizationRole)
+	err = database.QueryRowContext(ctx, r.db, query, userID).Scan(&organizationCode, &organizationName, &companyName, &joinDate, &organizationRole)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", "", "", time.Time{}, 0, sql.ErrNoRows
@@ -208,7 +233,12 @@ func (r *OrganizationUserRepository) GetOrganizationWithJoinDateByUserID(userID
 }
 
 // GetUsers retrieves users from an organization with optional status filter
-func (r *OrganizationUserRepository) GetUsers(organizationID string, status interface{}) ([]model.User, error) {
+func (r *OrganizationUserRepository) GetUsers(ctx context.Context, status interface{}) ([]model.User, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT u.user_id, u.username, u.fullname, u.email, u.phone, u.address, u.city, u.province, u.avatar, u.created_at, ou.is_active
 		FROM users u
@@ -226,7 +256,7 @@ func (r *OrganizationUserRepository) GetUsers(organizationID string, status inte
 
 	query += " ORDER BY u.fullname"
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -281,25 +311,35 @@ func (r *OrganizationUserRepository) GetUsers(organizationID string, status inte
 }
 
 // UpdateOrganizationUserActiveByUserID updates is_active on organization_users
-func (r *OrganizationUserRepository) UpdateOrganizationUserActiveByUserID(userID, organizationID string, isActive bool) error {
+func (r *OrganizationUserRepository) UpdateOrganizationUserActiveByUserID(ctx context.Context, userID string, isActive bool) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		UPDATE organization_users
 		SET is_active = %s, updated_at = %s
 		WHERE user_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	_, err := database.Exec(r.db, query, isActive, time.Now(), userID, organizationID)
+	_, err = t.Exec(query, isActive, time.Now(), userID, organizationID)
 	return err
 }
 
 // DeleteOrganizationUserByUserID deletes a row from organization_users
-func (r *OrganizationUserRepository) DeleteOrganizationUserByUserID(userID, organizationID string) error {
+func (r *OrganizationUserRepository) DeleteOrganizationUserByUserID(ctx context.Context, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		DELETE FROM organization_users
 		WHERE user_id = %s AND organization_id = %s
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	result, err := database.Exec(r.db, query, userID, organizationID)
+	result, err := t.Exec(query, userID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -316,15 +356,22 @@ func (r *OrganizationUserRepository) DeleteOrganizationUserByUserID(userID, orga
 	return nil
 }
 
-// UpdateUserIsActive updates is_active on users table
-func (r *OrganizationUserRepository) UpdateUserIsActive(userID, organizationID string, isActive bool) error {
+// UpdateUserIsActive updates is_active on users table for a member of the
+// organization
+func (r *OrganizationUserRepository) UpdateUserIsActive(ctx context.Context, userID string, isActive bool) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET is_active = %s, updated_at = %s
 		WHERE user_id = %s
-	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+		  AND user_id IN (SELECT ou.user_id FROM organization_users ou WHERE ou.organization_id = %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
-	result, err := database.Exec(r.db, query, isActive, time.Now(), userID)
+	result, err := t.Exec(query, isActive, time.Now(), userID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -342,7 +389,12 @@ func (r *OrganizationUserRepository) UpdateUserIsActive(userID, organizationID s
 }
 
 // GetRoleByUserIDAndOrgID retrieves role
-func (r *OrganizationUserRepository) GetRoleByUserIDAndOrgID(userID, organizationID string) (int, error) {
+func (r *OrganizationUserRepository) GetRoleByUserIDAndOrgID(ctx context.Context, userID string) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT organization_role
 		FROM organization_users
@@ -350,7 +402,7 @@ func (r *OrganizationUserRepository) GetRoleByUserIDAndOrgID(userID, organizatio
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var role int
-	err := database.QueryRow(r.db, query, userID, organizationID).Scan(&role)
+	err = t.QueryRow(query, userID, organizationID).Scan(&role)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, sql.ErrNoRows
@@ -360,3 +412,78 @@ func (r *OrganizationUserRepository) GetRoleByUserIDAndOrgID(userID, organizatio
 
 	return role, nil
 }
+
+// GetMemberPermissions retrieves the organization role of an active member,
+// the role_id assigned to them (empty when none or the role was deleted) and
+// the permissions that role grants
+func (r *OrganizationUserRepository) GetMemberPermissions(ctx context.Context, userID string) (organizationRole int, roleID string, permissions []string, err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	organizationID := t.OrganizationID()
+	roleIDExpr := "COALESCE(ro.role_id, '')"
+	if r.driver != "mysql" {
+		roleIDExpr = "COALESCE(ro.role_id::text, '')"
+	}
+	query := fmt.Sprintf(`
+		SELECT ou.organization_role, %s, COALESCE(p.permission, '')
+		FROM organization_users ou
+		LEFT JOIN organization_roles ro ON ro.role_id = ou.role_id AND ro.organization_id = ou.organization_id AND COALESCE(ro.status, 0) > 0
+		LEFT JOIN organization_role_permissions p ON p.role_id = ro.role_id
+		WHERE ou.user_id = %s AND ou.organization_id = %s AND ou.is_active = true
+	`, roleIDExpr, r.getPlaceholder(1), r.getPlaceholder(2))
+
+	rows, err := t.Query(query, userID, organizationID)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&organizationRole, &roleID, &permission); err != nil {
+			return 0, "", nil, err
+		}
+		found = true
+		if permission != "" {
+			permissions = append(permissions, permission)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, "", nil, err
+	}
+	if !found {
+		return 0, "", nil, sql.ErrNoRows
+	}
+	return organizationRole, roleID, permissions, nil
+}
+
+// UpdateOrganizationUserRoleID assigns an organization role to a member; an
+// empty roleID removes it
+func (r *OrganizationUserRepository) UpdateOrganizationUserRoleID(ctx context.Context, userID, roleID, updatedBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		UPDATE organization_users
+		SET role_id = %s, updated_at = %s, updated_by = %s
+		WHERE user_id = %s AND organization_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+
+	var role interface{}
+	if roleID != "" {
+		role = roleID
+	}
+	result, err := t.Exec(query, role, time.Now(), updatedBy, userID, organizationID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+)
+
+func TestOrganizationUserRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOrganizationUserRepository(openTenantFake(t), "postgres")
+	const memberOfB, roleOfB = "member-of-b", "role-of-b"
+	newMember := func(ctx context.Context) *model.OrganizationUser {
+		organizationID, _ := database.OrganizationFromContext(ctx)
+		return &model.OrganizationUser{UUID: "member-1", UserID: memberOfB, OrganizationID: organizationID}
+	}
+	checkTenantIsolation(t, tenantCalls{
+		"CheckUserInOrganization": func(ctx context.Context) error {
+			_, err := r.CheckUserInOrganization(ctx, memberOfB)
+			return err
+		},
+		"CreateOrganizationUser": func(ctx context.Context) error { return r.CreateOrganizationUser(ctx, newMember(ctx)) },
+		"CreateSubscription":     func(ctx context.Context) error { return r.CreateSubscription(ctx) },
+		"UpdateOrganizationUserRole": func(ctx context.Context) error {
+			return r.UpdateOrganizationUserRole(ctx, memberOfB, 2)
+		},
+		"GetUsersByOrganizationID": func(ctx context.Context) error {
+			_, err := r.GetUsersByOrganizationID(ctx)
+			return err
+		},
+		"GetUsers": func(ctx context.Context) error { _, err := r.GetUsers(ctx, true); return err },
+		"UpdateOrganizationUserActiveByUserID": func(ctx context.Context) error {
+			return r.UpdateOrganizationUserActiveByUserID(ctx, memberOfB, true)
+		},
+		"DeleteOrganizationUserByUserID": func(ctx context.Context) error {
+			return r.DeleteOrganizationUserByUserID(ctx, memberOfB)
+		},
+		"UpdateUserIsActive": func(ctx context.Context) error { return r.UpdateUserIsActive(ctx, memberOfB, false) },
+		"GetRoleByUserIDAndOrgID": func(ctx context.Context) error {
+			_, err := r.GetRoleByUserIDAndOrgID(ctx, memberOfB)
+			return err
+		},
+		"GetMemberPermissions": func(ctx context.Context) error {
+			_, _, _, err := r.GetMemberPermissions(ctx, memberOfB)
+			return err
+		},
+		"UpdateOrganizationUserRoleID": func(ctx context.Context) error {
+			return r.UpdateOrganizationUserRoleID(ctx, memberOfB, roleOfB, employeeOfB)
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"CreateOrganizationUser": func() error {
+			return r.CreateOrganizationUser(ctxA, &model.OrganizationUser{UUID: "member-1", UserID: memberOfB, OrganizationID: tenantB})
+		},
+	})
+}
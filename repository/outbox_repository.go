@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+// OutboxRepository stores outgoing WhatsApp messages and emails. Claiming due
+// messages relies on FOR UPDATE SKIP LOCKED, so several API instances can run
+// the outbox worker against the same postgres database. The queue is shared by
+// every organization; only the assistant statistics are the organization's.
+type OutboxRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewOutboxRepository(db *sql.DB, driver string) *OutboxRepository {
+	return &OutboxRepository{
+		db:     db,
+		driver: driver,
+	}
+}
+
+func (r *OutboxRepository) getPlaceholder(pos int) string {
+	if r.driver == "mysql" {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", pos)
+}
+
+const selectOutboxColumns = `message_id::text, COALESCE(organization_id::text, ''), channel, kind, recipient, subject, body,
+	status, attempts, max_attempts, last_error, next_attempt_at, sent_at, created_at, updated_at`
+
+// Create queues a message
+func (r *OutboxRepository) Create(ctx context.Context, msg *model.OutboxMessage, now time.Time) error {
+	query := fmt.Sprintf(`
+		INSERT INTO outbox_messages
+			(message_id, organization_id, channel, kind, recipient, subject, body, status, attempts, max_attempts,
+			 next_attempt_at, created_at, updated_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, 0, %s, %s, %s, %s)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
+		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10),
+		r.getPlaceholder(11), r.getPlaceholder(12))
+	_, err := database.ExecContext(ctx, r.db, query, msg.MessageID, nullableString(msg.OrganizationID), msg.Channel, msg.Kind,
+		msg.Recipient, msg.Subject, msg.Body, model.OutboxStatusPending, msg.MaxAttempts, now, now, now)
+	return err
+}
+
+// ClaimDue marks up to limit due messages as SENDING until now+lease and
+// returns them. Messages whose lease ran out (the worker sending them died)
+// are due again.
+func (r *OutboxRepository) ClaimDue(ctx context.Context, limit int, now time.Time, lease time.Duration) ([]model.OutboxMessage, error) {
+	query := fmt.Sprintf(`
+		UPDATE outbox_messages SET status = %s, locked_until = %s, updated_at = %s
+		WHERE message_id IN (
+			SELECT message_id FROM outbox_messages
+			WHERE (status = %s AND next_attempt_at <= %s) OR (status = %s AND locked_until < %s)
+			ORDER BY next_attempt_at
+			LIMIT %d
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+selectOutboxColumns,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3),
+		r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), limit)
+
+	rows, err := database.QueryContext(ctx, r.db, query, model.OutboxStatusSending, now.Add(lease), now,
+		model.OutboxStatusPending, now, model.OutboxStatusSending, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxMessages(rows)
+}
+
+// MarkSent records a delivered message
+func (r *OutboxRepository) MarkSent(ctx context.Context, messageID string, attempts int, now time.Time) error {
+	query := fmt.Sprintf(`
+		UPDATE outbox_messages SET status = %s, attempts = %s, last_error = '', locked_until = NULL, sent_at = %s, updated_at = %s
+		WHERE message_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+	_, err := database.ExecContext(ctx, r.db, query, model.OutboxStatusSent, attempts, now, now, messageID)
+	return err
+}
+
+// Reschedule puts a message back in the queue until nextAttemptAt, after a
+// failed attempt or when its organization is over the send limit
+func (r *OutboxRepository) Reschedule(ctx context.Context, messageID string, attempts int, lastError string, nextAttemptAt, now time.Time) error {
+	query := fmt.Sprintf(`
+		UPDATE outbox_messages SET status = %s, attempts = %s, last_error = %s, next_attempt_at = %s, locked_until = NULL, updated_at = %s
+		WHERE message_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
+	_, err := database.ExecContext(ctx, r.db, query, model.OutboxStatusPending, attempts, lastError, nextAttemptAt, now, messageID)
+	return err
+}
+
+// MarkDead gives up on a message after its last attempt failed
+func (r *OutboxRepository) MarkDead(ctx context.Context, messageID string, attempts int, lastError string, now time.Time) error {
+	query := fmt.Sprintf(`
+		UPDATE outbox_messages SET status = %s, attempts = %s, last_error = %s, locked_until = NULL, updated_at = %s
+		WHERE message_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+	_, err := database.ExecContext(ctx, r.db, query, model.OutboxStatusDead, attempts, lastError, now, messageID)
+	return err
+}
+
+// Requeue sends a dead message again with a fresh set of attempts. It returns
+// false when the message does not exist or is not dead.
+func (r *OutboxRepository) Requeue(ctx context.Context, messageID string, now time.Time) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE outbox_messages SET status = %s, attempts = 0, next_attempt_at = %s, updated_at = %s
+		WHERE message_id = %s AND status = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+	res, err := database.ExecContext(ctx, r.db, query, model.OutboxStatusPending, now, now, messageID, model.OutboxStatusDead)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Get returns one message, nil when it does not exist
+func (r *OutboxRepository) Get(ctx context.Context, messageID string) (*model.OutboxMessage, error) {
+	query := "SELECT " + selectOutboxColumns + " FROM outbox_messages WHERE message_id::text = " + r.getPlaceholder(1)
+	rows, err := database.QueryContext(ctx, r.db, query, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items, err := scanOutboxMessages(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// List returns the newest messages matching filter
+func (r *OutboxRepository) List(ctx context.Context, filter model.OutboxMessageFilter) ([]model.OutboxMessage, error) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, r.getPlaceholder(len(args))))
+	}
+	if filter.Status != "" {
+		add("status = %s", filter.Status)
+	}
+	if filter.Channel != "" {
+		add("channel = %s", filter.Channel)
+	}
+	if filter.Kind != "" {
+		add("kind = %s", filter.Kind)
+	}
+	if filter.OrganizationID != "" {
+		add("organization_id::text = %s", filter.OrganizationID)
+	}
+	if filter.Recipient != "" {
+		add("recipient = %s", filter.Recipient)
+	}
+
+	query := "SELECT " + selectOutboxColumns + " FROM outbox_messages"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT %d", limit)
+
+	rows, err := database.QueryContext(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxMessages(rows)
+}
+
+// RecordAssistantStat counts a WhatsApp message of an organization's
+// assistant account for today, status 1 delivered and 2 failed
+func (r *OutboxRepository) RecordAssistantStat(ctx context.Context, status int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO assistant_account_stats (period, count, organization_id, type, status)
+		VALUES (%s, 1, %s, 1, %s)
+		ON CONFLICT (period, type, status, organization_id)
+		DO UPDATE SET count = assistant_account_stats.count + 1`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	_, err = t.Exec(query, time.Now().Format("2006-01-02"), t.OrganizationID(), status)
+	return err
+}
+
+func scanOutboxMessages(rows *sql.Rows) ([]model.OutboxMessage, error) {
+	items := make([]model.OutboxMessage, 0)
+	for rows.Next() {
+		var it model.OutboxMessage
+		var nextAttemptAt, sentAt, createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&it.MessageID, &it.OrganizationID, &it.Channel, &it.Kind, &it.Recipient, &it.Subject, &it.Body,
+			&it.Status, &it.Attempts, &it.MaxAttempts, &it.LastError, &nextAttemptAt, &sentAt, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if nextAttemptAt.Valid && it.Status != model.OutboxStatusSent && it.Status != model.OutboxStatusDead {
+			it.NextAttemptAt = nextAttemptAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if sentAt.Valid {
+			it.SentAt = sentAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if createdAt.Valid {
+			it.CreatedAt = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if updatedAt.Valid {
+			it.UpdatedAt = updatedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOutboxRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOutboxRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"RecordAssistantStat": func(ctx context.Context) error { return r.RecordAssistantStat(ctx, 1) },
+	})
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"service-travego/database"
 	"service-travego/model"
@@ -25,13 +27,13 @@ func getPartnerCitiesMap() map[string]string {
 		partnerCitiesMap = map[string]string{}
 		f, err := os.Open("config/location.json")
 		if err != nil {
-			fmt.Printf("Error opening location.json: %v\n", err)
+			slog.Error("error opening location.json", "error", err)
 			return
 		}
 		defer f.Close()
 		var loc model.Location
 		if err := json.NewDecoder(f).Decode(&loc); err != nil {
-			fmt.Printf("Error decoding location.json: %v\n", err)
+			slog.Error("error decoding location.json", "error", err)
 			return
 		}
 		for _, c := range loc.Cities {
@@ -68,7 +70,12 @@ func (r *PartnerRepository) GetCityLabel(cityID *int) string {
 	return ""
 }
 
-func (r *PartnerRepository) List(orgID, partnerName, startDate, endDate string) ([]model.OperationPartner, error) {
+func (r *PartnerRepository) List(ctx context.Context, partnerName, startDate, endDate string) ([]model.OperationPartner, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	args := make([]interface{}, 0, 4)
 	whereClauses := make([]string, 0, 5)
 	placeholder := func() string {
@@ -88,13 +95,13 @@ func (r *PartnerRepository) List(orgID, partnerName, startDate, endDate string)
 		args = append(args, "%"+partnerName+"%")
 	}
 
-	revenueDateClauses := []string{"transaction_date IS NOT NULL"}
+	revenueDateClauses := []string{"tr.transaction_date IS NOT NULL"}
 	if startDate = strings.TrimSpace(startDate); startDate != "" {
-		revenueDateClauses = append(revenueDateClauses, fmt.Sprintf("transaction_date >= %s", placeholder()))
+		revenueDateClauses = append(revenueDateClauses, fmt.Sprintf("tr.transaction_date >= %s", placeholder()))
 		args = append(args, startDate)
 	}
 	if endDate = strings.TrimSpace(endDate); endDate != "" {
-		revenueDateClauses = append(revenueDateClauses, fmt.Sprintf("transaction_date < %s", placeholder()))
+		revenueDateClauses = append(revenueDateClauses, fmt.Sprintf("tr.transaction_date < %s", placeholder()))
 		args = append(args, endDate)
 	}
 
@@ -128,16 +135,18 @@ func (r *PartnerRepository) List(orgID, partnerName, startDate, endDate string)
 				  AND sf.organization_id::text = op.organization_id::text
 			) sf
 			INNER JOIN (
-				SELECT reference_id::text AS order_id, SUM(amount) AS total_amount
-				FROM transactions
-				WHERE %s
-				  AND transaction_type = 1
-				GROUP BY reference_id::text
+				SELECT tr.reference_id::text AS order_id, SUM(tr.amount) AS total_amount
+				FROM transactions tr
+				WHERE tr.organization_id::text = $1
+				  AND %s
+				  AND tr.transaction_type = 1
+				GROUP BY tr.reference_id::text
 			) t ON t.order_id = sf.order_id
 			INNER JOIN (
-				SELECT order_id::text AS order_id, SUM(quantity) AS total_qty
-				FROM fleet_order_items
-				GROUP BY order_id::text
+				SELECT foi.order_id::text AS order_id, SUM(foi.quantity) AS total_qty
+				FROM fleet_order_items foi
+				WHERE foi.organization_id::text = $1
+				GROUP BY foi.order_id::text
 			) q ON q.order_id = sf.order_id
 		) revenue ON true
 		WHERE %s
@@ -161,7 +170,7 @@ func (r *PartnerRepository) List(orgID, partnerName, startDate, endDate string)
 		strings.Join(whereClauses, "\n\t\t  AND "),
 	)
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +194,12 @@ func (r *PartnerRepository) List(orgID, partnerName, startDate, endDate string)
 	return result, nil
 }
 
-func (r *PartnerRepository) Create(req model.CreateOperationPartnerRequest, orgID, userID string) (*model.OperationPartner, error) {
+func (r *PartnerRepository) Create(ctx context.Context, req model.CreateOperationPartnerRequest, userID string) (*model.OperationPartner, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	partnerID := uuid.New().String()
 	now := time.Now()
 
@@ -208,15 +222,20 @@ func (r *PartnerRepository) Create(req model.CreateOperationPartnerRequest, orgI
 		query = strings.ReplaceAll(query, "$12", "?")
 	}
 
-	_, err := r.db.Exec(query, partnerID, req.PartnerName, req.PartnerAddress, req.PartnerCity, req.PartnerPhone, req.PartnerEmail, req.PicName, now, userID, now, userID, orgID)
+	_, err = t.Exec(query, partnerID, req.PartnerName, req.PartnerAddress, req.PartnerCity, req.PartnerPhone, req.PartnerEmail, req.PicName, now, userID, now, userID, orgID)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.GetByID(partnerID, orgID, nil)
+	return r.GetByID(ctx, partnerID, nil)
 }
 
-func (r *PartnerRepository) Update(req model.UpdateOperationPartnerRequest, orgID, userID string) (*model.OperationPartner, error) {
+func (r *PartnerRepository) Update(ctx context.Context, req model.UpdateOperationPartnerRequest, userID string) (*model.OperationPartner, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	now := time.Now()
 
 	query := `
@@ -237,15 +256,20 @@ func (r *PartnerRepository) Update(req model.UpdateOperationPartnerRequest, orgI
 		query = strings.ReplaceAll(query, "$10", "?")
 	}
 
-	_, err := r.db.Exec(query, req.PartnerName, req.PartnerAddress, req.PartnerCity, req.PartnerPhone, req.PartnerEmail, req.PartnerPic, now, userID, req.PartnerID, orgID)
+	_, err = t.Exec(query, req.PartnerName, req.PartnerAddress, req.PartnerCity, req.PartnerPhone, req.PartnerEmail, req.PartnerPic, now, userID, req.PartnerID, orgID)
 	if err != nil {
 		return nil, err
 	}
 
-	return r.GetByID(req.PartnerID, orgID, nil)
+	return r.GetByID(ctx, req.PartnerID, nil)
 }
 
-func (r *PartnerRepository) GetByID(partnerID, orgID string, filter *model.OperationPartnerDetailRequest) (*model.OperationPartner, error) {
+func (r *PartnerRepository) GetByID(ctx context.Context, partnerID string, filter *model.OperationPartnerDetailRequest) (*model.OperationPartner, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	args := make([]interface{}, 0, 4)
 	args = append(args, partnerID, orgID)
 
@@ -283,7 +307,9 @@ func (r *PartnerRepository) GetByID(partnerID, orgID string, filter *model.Opera
 			 INNER JOIN fleet_orders fo ON fo.order_id = sf.order_id
 			 WHERE fuo.partner_id = op.partner_id
 			   AND fuo.organization_id = op.organization_id
+			   AND fu.organization_id = op.organization_id
 			   AND sf.organization_id = op.organization_id
+			   AND fo.organization_id = op.organization_id
 			   %s) AS total_schedule
 		FROM operation_partner op 
 		WHERE op.partner_id = %s AND op.organization_id = %s
@@ -295,7 +321,7 @@ func (r *PartnerRepository) GetByID(partnerID, orgID string, filter *model.Opera
 
 	var p model.OperationPartner
 	var joinDate time.Time
-	err := r.db.QueryRow(query, args...).Scan(
+	err = t.QueryRow(query, args...).Scan(
 		&p.PartnerName,
 		&p.PartnerAddress,
 		&p.PartnerCity,
@@ -322,7 +348,12 @@ func (r *PartnerRepository) GetByID(partnerID, orgID string, filter *model.Opera
 	return &p, nil
 }
 
-func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model.OperationPartnerDetailRequest) (float64, float64, int64, error) {
+func (r *PartnerRepository) GetDetailMetrics(ctx context.Context, partnerID string, req *model.OperationPartnerDetailRequest) (float64, float64, int64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	orgID := t.OrganizationID()
 	transactionStartDate := "0001-01-01"
 	transactionEndDate := "9999-12-31"
 	tripStartDate := "0001-01-01"
@@ -394,29 +425,33 @@ func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model
 	}
 
 	sfOrderExpr := "sf.order_id::text"
-	transactionReferenceExpr := "reference_id::text"
-	fleetOrderItemExpr := "order_id::text"
+	transactionReferenceExpr := "tr.reference_id::text"
+	fleetOrderItemExpr := "foi.order_id::text"
 	revenuePartnerExpr := "fuo.partner_id::text = " + r.getPlaceholder(1)
 	revenueOrgExpr := "sf.organization_id::text = " + r.getPlaceholder(2)
-	expensesPartnerExpr := "fuo.partner_id::text = " + r.getPlaceholder(5)
-	expensesOrgExpr := "sf.organization_id::text = " + r.getPlaceholder(6)
+	transactionOrgExpr := "tr.organization_id::text = " + r.getPlaceholder(5)
+	fleetOrderItemOrgExpr := "foi.organization_id::text = " + r.getPlaceholder(6)
+	expensesPartnerExpr := "fuo.partner_id::text = " + r.getPlaceholder(7)
+	expensesOrgExpr := "sf.organization_id::text = " + r.getPlaceholder(8)
 	expensesReferenceExpr := "t.reference_id::text = sf.schedule_number::text OR t.reference_id::text = sf.order_id::text"
-	totalBookingPartnerExpr := "fuo.partner_id::text = " + r.getPlaceholder(9)
-	totalBookingOrgExpr := "sf.organization_id::text = " + r.getPlaceholder(10)
-	totalBookingFleetOrderOrgExpr := "fo2.organization_id::text = " + r.getPlaceholder(11)
+	totalBookingPartnerExpr := "fuo.partner_id::text = " + r.getPlaceholder(11)
+	totalBookingOrgExpr := "sf.organization_id::text = " + r.getPlaceholder(12)
+	totalBookingFleetOrderOrgExpr := "fo2.organization_id::text = " + r.getPlaceholder(13)
 
 	if r.driver == "mysql" {
 		sfOrderExpr = "sf.order_id"
-		transactionReferenceExpr = "reference_id"
-		fleetOrderItemExpr = "order_id"
+		transactionReferenceExpr = "tr.reference_id"
+		fleetOrderItemExpr = "foi.order_id"
 		revenuePartnerExpr = "fuo.partner_id = " + r.getPlaceholder(1)
 		revenueOrgExpr = "sf.organization_id = " + r.getPlaceholder(2)
-		expensesPartnerExpr = "fuo.partner_id = " + r.getPlaceholder(5)
-		expensesOrgExpr = "sf.organization_id = " + r.getPlaceholder(6)
+		transactionOrgExpr = "tr.organization_id = " + r.getPlaceholder(5)
+		fleetOrderItemOrgExpr = "foi.organization_id = " + r.getPlaceholder(6)
+		expensesPartnerExpr = "fuo.partner_id = " + r.getPlaceholder(7)
+		expensesOrgExpr = "sf.organization_id = " + r.getPlaceholder(8)
 		expensesReferenceExpr = "t.reference_id = sf.schedule_number OR t.reference_id = sf.order_id"
-		totalBookingPartnerExpr = "fuo.partner_id = " + r.getPlaceholder(9)
-		totalBookingOrgExpr = "sf.organization_id = " + r.getPlaceholder(10)
-		totalBookingFleetOrderOrgExpr = "fo2.organization_id = " + r.getPlaceholder(11)
+		totalBookingPartnerExpr = "fuo.partner_id = " + r.getPlaceholder(11)
+		totalBookingOrgExpr = "sf.organization_id = " + r.getPlaceholder(12)
+		totalBookingFleetOrderOrgExpr = "fo2.organization_id = " + r.getPlaceholder(13)
 	}
 
 	query := fmt.Sprintf(`
@@ -427,20 +462,23 @@ func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model
 					SELECT DISTINCT %s AS order_id
 					FROM schedule_fleets sf
 					INNER JOIN fleet_unit_ownership fuo ON fuo.unit_id = sf.unit_id
+						AND fuo.organization_id = sf.organization_id
 					WHERE %s
 					  AND %s
 				) sf
 				INNER JOIN (
-					SELECT %s AS order_id, SUM(amount) AS total_amount
-					FROM transactions
-					WHERE transaction_date IS NOT NULL
-					  AND transaction_date >= %s AND transaction_date < %s
-					  AND transaction_type = 1
+					SELECT %s AS order_id, SUM(tr.amount) AS total_amount
+					FROM transactions tr
+					WHERE tr.transaction_date IS NOT NULL
+					  AND tr.transaction_date >= %s AND tr.transaction_date < %s
+					  AND %s
+					  AND tr.transaction_type = 1
 					GROUP BY %s
 				) t ON t.order_id = sf.order_id
 				INNER JOIN (
-					SELECT %s AS order_id, SUM(quantity) AS total_qty
-					FROM fleet_order_items
+					SELECT %s AS order_id, SUM(foi.quantity) AS total_qty
+					FROM fleet_order_items foi
+					WHERE %s
 					GROUP BY %s
 				) q ON q.order_id = sf.order_id
 			) AS revenue,
@@ -448,7 +486,9 @@ func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model
 				SELECT COALESCE(SUM(t.amount), 0)
 				FROM schedule_fleets sf
 				INNER JOIN fleet_unit_ownership fuo ON fuo.unit_id = sf.unit_id
+					AND fuo.organization_id = sf.organization_id
 				INNER JOIN transactions t ON (%s)
+					AND t.organization_id = sf.organization_id
 				WHERE %s
 				  AND %s
 				  AND t.transaction_type = 2
@@ -460,6 +500,7 @@ func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model
 				FROM schedule_fleets sf
 				INNER JOIN fleet_orders fo2 ON fo2.order_id = sf.order_id
 				INNER JOIN fleet_unit_ownership fuo ON fuo.unit_id = sf.unit_id
+					AND fuo.organization_id = sf.organization_id
 				WHERE %s
 				  AND %s
 				  AND %s
@@ -474,32 +515,35 @@ func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model
 		transactionReferenceExpr,
 		r.getPlaceholder(3),
 		r.getPlaceholder(4),
+		transactionOrgExpr,
 		transactionReferenceExpr,
 		fleetOrderItemExpr,
+		fleetOrderItemOrgExpr,
 		fleetOrderItemExpr,
 		expensesReferenceExpr,
 		expensesPartnerExpr,
 		expensesOrgExpr,
-		r.getPlaceholder(7),
-		r.getPlaceholder(8),
+		r.getPlaceholder(9),
+		r.getPlaceholder(10),
 		totalBookingPartnerExpr,
 		totalBookingOrgExpr,
 		totalBookingFleetOrderOrgExpr,
-		r.getPlaceholder(12),
-		r.getPlaceholder(13),
+		r.getPlaceholder(14),
+		r.getPlaceholder(15),
 	)
 
 	var totalRevenueAny interface{}
 	var totalExpensesAny interface{}
 	var totalBookingAny interface{}
 
-	err := database.QueryRow(
-		r.db,
+	err = t.QueryRow(
 		query,
 		partnerID,
 		orgID,
 		transactionStartDate,
 		transactionEndDate,
+		orgID,
+		orgID,
 		partnerID,
 		orgID,
 		transactionStartDate,
@@ -532,7 +576,14 @@ func (r *PartnerRepository) GetDetailMetrics(partnerID, orgID string, req *model
 	return totalRevenue, totalExpenses, totalBooking, nil
 }
 
-func (r *PartnerRepository) GetOrCreateByNamePhone(orgID, userID, partnerName, partnerPhone string, partnerEmail *string) (string, error) {
+// FindIDByNamePhone returns the ID of the organization's partner with the
+// name and phone, empty when there is none
+func (r *PartnerRepository) FindIDByNamePhone(ctx context.Context, partnerName, partnerPhone string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
 	query := `
 		SELECT partner_id
 		FROM operation_partner
@@ -545,13 +596,17 @@ func (r *PartnerRepository) GetOrCreateByNamePhone(orgID, userID, partnerName, p
 	}
 
 	var partnerID string
-	err := r.db.QueryRow(query, partnerName, partnerPhone, orgID).Scan(&partnerID)
-	if err == nil {
-		return partnerID, nil
+	err = t.QueryRow(query, partnerName, partnerPhone, orgID).Scan(&partnerID)
+	if err == sql.ErrNoRows {
+		return "", nil
 	}
+	return partnerID, err
+}
 
-	if err != sql.ErrNoRows {
-		return "", err
+func (r *PartnerRepository) GetOrCreateByNamePhone(ctx context.Context, userID, partnerName, partnerPhone string, partnerEmail *string) (string, error) {
+	partnerID, err := r.FindIDByNamePhone(ctx, partnerName, partnerPhone)
+	if err != nil || partnerID != "" {
+		return partnerID, err
 	}
 
 	createReq := model.CreateOperationPartnerRequest{
@@ -561,7 +616,7 @@ func (r *PartnerRepository) GetOrCreateByNamePhone(orgID, userID, partnerName, p
 		PicName:      partnerName,
 	}
 
-	partner, err := r.Create(createReq, orgID, userID)
+	partner, err := r.Create(ctx, createReq, userID)
 	if err != nil {
 		return "", err
 	}
@@ -569,7 +624,12 @@ func (r *PartnerRepository) GetOrCreateByNamePhone(orgID, userID, partnerName, p
 	return partner.PartnerID, nil
 }
 
-func (r *PartnerRepository) GetPartnerFleetUnits(partnerID, orgID string, req *model.OperationPartnerDetailRequest) ([]model.PartnerFleetUnit, error) {
+func (r *PartnerRepository) GetPartnerFleetUnits(ctx context.Context, partnerID string, req *model.OperationPartnerDetailRequest) ([]model.PartnerFleetUnit, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	transactionStartDate := "0001-01-01"
 	transactionEndDate := "9999-12-31"
 	tripStartDate := "0001-01-01"
@@ -642,8 +702,8 @@ func (r *PartnerRepository) GetPartnerFleetUnits(partnerID, orgID string, req *m
 
 	fleetOrderOrgExpr := "fo.organization_id::text = fuo.organization_id::text"
 	revenueOrderExpr := "sf.order_id::text"
-	revenueReferenceExpr := "reference_id::text"
-	revenueFleetOrderItemExpr := "order_id::text"
+	revenueReferenceExpr := "tr.reference_id::text"
+	revenueFleetOrderItemExpr := "foi.order_id::text"
 	expenseReferenceExpr := "t.reference_id::text IN (sf.schedule_number::text, sf.order_id::text)"
 	partnerExpr := "fuo.partner_id::text = " + r.getPlaceholder(1)
 	orgExpr := "fuo.organization_id::text = " + r.getPlaceholder(2)
@@ -666,10 +726,13 @@ func (r *PartnerRepository) GetPartnerFleetUnits(partnerID, orgID string, req *m
 			COALESCE(expenses.total_expenses, 0) AS total_expenses
 		FROM fleets f
 		INNER JOIN fleet_units fu ON fu.fleet_id = f.uuid
+			AND fu.organization_id = f.organization_id
 		INNER JOIN (
-			SELECT DISTINCT unit_id, partner_id, organization_id
-			FROM fleet_unit_ownership
+			SELECT DISTINCT own.unit_id, own.partner_id, own.organization_id
+			FROM fleet_unit_ownership own
+			WHERE own.organization_id::text = $2
 		) fuo ON fuo.unit_id = fu.unit_id
+			AND fuo.organization_id = fu.organization_id
 		INNER JOIN fleet_types ft ON ft.id = f.fleet_type
 		LEFT JOIN LATERAL (
 			SELECT COALESCE(COUNT(DISTINCT sf.schedule_number), 0) AS total_booking
@@ -691,23 +754,26 @@ func (r *PartnerRepository) GetPartnerFleetUnits(partnerID, orgID string, req *m
 				  AND sf.organization_id = fuo.organization_id
 			) sf
 			INNER JOIN (
-				SELECT %s AS order_id, SUM(amount) AS total_amount
-				FROM transactions
-				WHERE transaction_date IS NOT NULL
-				  AND transaction_date >= %s AND transaction_date < %s
-				  AND transaction_type = 1
+				SELECT %s AS order_id, SUM(tr.amount) AS total_amount
+				FROM transactions tr
+				WHERE tr.organization_id::text = $2
+				  AND tr.transaction_date IS NOT NULL
+				  AND tr.transaction_date >= %s AND tr.transaction_date < %s
+				  AND tr.transaction_type = 1
 				GROUP BY %s
 			) t ON t.order_id = sf.order_id
 			INNER JOIN (
-				SELECT %s AS order_id, SUM(quantity) AS total_qty
-				FROM fleet_order_items
+				SELECT %s AS order_id, SUM(foi.quantity) AS total_qty
+				FROM fleet_order_items foi
+				WHERE foi.organization_id::text = $2
 				GROUP BY %s
 			) q ON q.order_id = sf.order_id
 		) revenue ON true
 		LEFT JOIN LATERAL (
 			SELECT COALESCE(SUM(t.amount), 0) AS total_expenses
 			FROM schedule_fleets sf
-			INNER JOIN transactions t ON %s
+			INNER JOIN transactions t ON (%s)
+				AND t.organization_id = sf.organization_id
 			WHERE sf.unit_id = fu.unit_id
 			  AND sf.organization_id = fuo.organization_id
 			  AND t.transaction_type = 2
@@ -733,7 +799,7 @@ func (r *PartnerRepository) GetPartnerFleetUnits(partnerID, orgID string, req *m
 		orgExpr,
 	)
 
-	rows, err := r.db.Query(query, partnerID, orgID, tripStartDate, tripEndDate, transactionStartDate, transactionEndDate, transactionStartDate, transactionEndDate)
+	rows, err := t.Query(query, partnerID, orgID, tripStartDate, tripEndDate, transactionStartDate, transactionEndDate, transactionStartDate, transactionEndDate)
 	if err != nil {
 		return nil, err
 	}
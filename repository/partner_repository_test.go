@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+)
+
+func TestPartnerRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPartnerRepository(openTenantFake(t), "postgres")
+	detail := &model.OperationPartnerDetailRequest{
+		PartnerID:            partnerOfB,
+		TransactionStartDate: "2026-01-01",
+		TripStartDate:        "2026-01-01",
+	}
+	checkTenantIsolation(t, tenantCalls{
+		"List": func(ctx context.Context) error {
+			_, err := r.List(ctx, "Mitra", "2026-01-01", "2026-02-01")
+			return err
+		},
+		"Create": func(ctx context.Context) error {
+			_, err := r.Create(ctx, model.CreateOperationPartnerRequest{PartnerName: "Mitra", PartnerPhone: "0812"}, employeeOfB)
+			return err
+		},
+		"Update": func(ctx context.Context) error {
+			_, err := r.Update(ctx, model.UpdateOperationPartnerRequest{PartnerID: partnerOfB, PartnerName: "Mitra"}, employeeOfB)
+			return err
+		},
+		"GetByID": func(ctx context.Context) error { _, err := r.GetByID(ctx, partnerOfB, detail); return err },
+		"GetDetailMetrics": func(ctx context.Context) error {
+			_, _, _, err := r.GetDetailMetrics(ctx, partnerOfB, detail)
+			return err
+		},
+		"FindIDByNamePhone": func(ctx context.Context) error {
+			_, err := r.FindIDByNamePhone(ctx, "Mitra", "0812")
+			return err
+		},
+		"GetPartnerFleetUnits": func(ctx context.Context) error {
+			_, err := r.GetPartnerFleetUnits(ctx, partnerOfB, detail)
+			return err
+		},
+	})
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+const selectPaymentNotification = `
+	SELECT notification_id::text, provider, source, COALESCE(event_key, ''), COALESCE(invoice_number, ''),
+		COALESCE(transaction_id, ''), COALESCE(transaction_status, ''), payload, outcome, COALESCE(message, ''),
+		attempts, received_at, processed_at
+	FROM payment_notifications`
+
+// InsertPaymentNotification stores a received notification. When rec.EventKey
+// is already held by another notification nothing is stored and false is
+// returned.
+func (r *paymentRepository) InsertPaymentNotification(ctx context.Context, rec *model.PaymentNotificationRecord) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO payment_notifications
+			(notification_id, provider, source, event_key, invoice_number, transaction_id, transaction_status, payload, outcome, message, received_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, NOW())
+		ON CONFLICT (event_key) DO NOTHING`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
+		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
+
+	result, err := database.ExecContext(ctx, r.db, query,
+		rec.NotificationID,
+		rec.Provider,
+		rec.Source,
+		nullableString(rec.EventKey),
+		nullableString(rec.InvoiceNumber),
+		nullableString(rec.TransactionID),
+		nullableString(rec.TransactionStatus),
+		rec.Payload,
+		rec.Outcome,
+		nullableString(rec.Message),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// UpdatePaymentNotificationOutcome records the result of processing a
+// notification. releaseEvent frees its event key so the next delivery of the
+// same event is processed again.
+func (r *paymentRepository) UpdatePaymentNotificationOutcome(ctx context.Context, notificationID, outcome, message string, releaseEvent bool) error {
+	eventExpr := "event_key"
+	if releaseEvent {
+		eventExpr = "NULL"
+	}
+	query := fmt.Sprintf(`
+		UPDATE payment_notifications
+		SET outcome = %s, message = %s, event_key = %s, attempts = attempts + 1, processed_at = NOW()
+		WHERE notification_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), eventExpr, r.getPlaceholder(3))
+	_, err := database.ExecContext(ctx, r.db, query, outcome, nullableString(message), notificationID)
+	return err
+}
+
+// ClaimPaymentNotificationEvent gives a replayed notification the event key
+// unless another notification already holds it; false means it is held.
+func (r *paymentRepository) ClaimPaymentNotificationEvent(ctx context.Context, notificationID, eventKey string) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE payment_notifications
+		SET event_key = %s
+		WHERE notification_id = %s AND event_key IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM payment_notifications WHERE event_key = %s)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	result, err := database.ExecContext(ctx, r.db, query, eventKey, notificationID, eventKey)
+	if err != nil {
+		// a concurrent claim of the same event hits the unique index
+		if strings.Contains(err.Error(), "uq_payment_notifications_event_key") {
+			return false, nil
+		}
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetPaymentNotification returns a stored notification, or nil when it does
+// not exist.
+func (r *paymentRepository) GetPaymentNotification(ctx context.Context, notificationID string) (*model.PaymentNotificationRecord, error) {
+	query := selectPaymentNotification + fmt.Sprintf(" WHERE notification_id::text = %s", r.getPlaceholder(1))
+	rows, err := database.QueryContext(ctx, r.db, query, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items, err := scanPaymentNotifications(rows)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// ListPaymentNotifications returns stored notifications, the newest first
+func (r *paymentRepository) ListPaymentNotifications(ctx context.Context, filter model.PaymentNotificationFilter) ([]model.PaymentNotificationRecord, error) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, r.getPlaceholder(len(args))))
+	}
+	if filter.InvoiceNumber != "" {
+		add("invoice_number = %s", filter.InvoiceNumber)
+	}
+	if filter.Outcome != "" {
+		add("outcome = %s", filter.Outcome)
+	}
+	if filter.Provider != "" {
+		add("provider = %s", filter.Provider)
+	}
+
+	query := selectPaymentNotification
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	query += fmt.Sprintf(" ORDER BY received_at DESC LIMIT %d", limit)
+
+	rows, err := database.QueryContext(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPaymentNotifications(rows)
+}
+
+func scanPaymentNotifications(rows *sql.Rows) ([]model.PaymentNotificationRecord, error) {
+	items := make([]model.PaymentNotificationRecord, 0)
+	for rows.Next() {
+		var it model.PaymentNotificationRecord
+		var receivedAt, processedAt sql.NullTime
+		if err := rows.Scan(&it.NotificationID, &it.Provider, &it.Source, &it.EventKey, &it.InvoiceNumber,
+			&it.TransactionID, &it.TransactionStatus, &it.Payload, &it.Outcome, &it.Message,
+			&it.Attempts, &receivedAt, &processedAt); err != nil {
+			return nil, err
+		}
+		if receivedAt.Valid {
+			it.ReceivedAt = receivedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if processedAt.Valid {
+			it.ProcessedAt = processedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
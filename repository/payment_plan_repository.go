@@ -0,0 +1,630 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"service-travego/database"
+	"service-travego/model"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInstallmentsLocked is returned when an order's installment schedule can
+// no longer be replaced because an installment was already paid or has a
+// payment link waiting for the Midtrans notification.
+var ErrInstallmentsLocked = errors.New("order installments already in payment")
+
+type PaymentPlanRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewPaymentPlanRepository(db *sql.DB, driver string) *PaymentPlanRepository {
+	return &PaymentPlanRepository{db: db, driver: driver}
+}
+
+func (r *PaymentPlanRepository) placeholder(pos int) string {
+	if r.driver == "postgres" || r.driver == "pgx" {
+		return "$" + strconv.Itoa(pos)
+	}
+	return "?"
+}
+
+const selectPaymentPlan = `
+SELECT
+	plan_id,
+	organization_id,
+	name,
+	COALESCE(description, '') AS description,
+	COALESCE(is_default, false) AS is_default,
+	COALESCE(status, 0) AS status,
+	created_at
+FROM payment_plans
+`
+
+func (r *PaymentPlanRepository) queryPlans(t *database.Tenant, query string, args ...interface{}) ([]model.PaymentPlan, error) {
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.PaymentPlan, 0)
+	for rows.Next() {
+		var it model.PaymentPlan
+		var status int
+		var createdAt sql.NullTime
+		if err := rows.Scan(&it.PlanID, &it.OrganizationID, &it.Name, &it.Description, &it.IsDefault, &status, &createdAt); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.Active = status == 1
+		it.Terms = []model.PaymentPlanTerm{}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range out {
+		terms, err := r.listTerms(t, out[i].PlanID)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Terms = terms
+	}
+	return out, nil
+}
+
+func (r *PaymentPlanRepository) listTerms(t *database.Tenant, planID string) ([]model.PaymentPlanTerm, error) {
+	query := fmt.Sprintf(`
+		SELECT ppt.seq, COALESCE(ppt.label, ''), COALESCE(ppt.percentage, 0), ppt.due_type, COALESCE(ppt.due_days, 0)
+		FROM payment_plan_terms ppt
+		JOIN payment_plans pp ON pp.plan_id = ppt.plan_id
+		WHERE ppt.plan_id = %s AND pp.organization_id = %s
+		ORDER BY ppt.seq ASC
+	`, r.placeholder(1), r.placeholder(2))
+	rows, err := t.Query(query, planID, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.PaymentPlanTerm, 0)
+	for rows.Next() {
+		var it model.PaymentPlanTerm
+		if err := rows.Scan(&it.Seq, &it.Label, &it.Percentage, &it.DueType, &it.DueDays); err != nil {
+			return nil, err
+		}
+		it.DueTypeLabel = model.PaymentTermDueTypeLabel[it.DueType]
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *PaymentPlanRepository) queryOne(t *database.Tenant, query string, args ...interface{}) (*model.PaymentPlan, error) {
+	items, err := r.queryPlans(t, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+// List returns the organization's payment plans, paused ones included.
+func (r *PaymentPlanRepository) List(ctx context.Context) ([]model.PaymentPlan, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectPaymentPlan + " WHERE organization_id = " + r.placeholder(1) + " AND status IN (1, 2) ORDER BY is_default DESC, created_at DESC"
+	return r.queryPlans(t, query, t.OrganizationID())
+}
+
+// ListActive returns the plans a customer can pick when ordering.
+func (r *PaymentPlanRepository) ListActive(ctx context.Context) ([]model.PaymentPlan, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectPaymentPlan + " WHERE organization_id = " + r.placeholder(1) + " AND status = 1 ORDER BY is_default DESC, created_at DESC"
+	return r.queryPlans(t, query, t.OrganizationID())
+}
+
+func (r *PaymentPlanRepository) GetByID(ctx context.Context, planID string) (*model.PaymentPlan, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectPaymentPlan + " WHERE organization_id = " + r.placeholder(1) + " AND plan_id = " + r.placeholder(2) + " AND status IN (1, 2)"
+	return r.queryOne(t, query, t.OrganizationID(), planID)
+}
+
+// GetDefault returns the active default plan of the organization, or sql.ErrNoRows.
+func (r *PaymentPlanRepository) GetDefault(ctx context.Context) (*model.PaymentPlan, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectPaymentPlan + " WHERE organization_id = " + r.placeholder(1) + " AND status = 1 AND is_default = true ORDER BY created_at DESC LIMIT 1"
+	return r.queryOne(t, query, t.OrganizationID())
+}
+
+func paymentPlanStatus(req *model.PaymentPlanUpsertRequest) int {
+	if req.Active != nil && !*req.Active {
+		return 2
+	}
+	return 1
+}
+
+func (r *PaymentPlanRepository) Create(ctx context.Context, req *model.PaymentPlanUpsertRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	planID := uuid.New().String()
+	now := time.Now()
+	if req.IsDefault {
+		if err = r.clearDefault(tx, req.UserID, now); err != nil {
+			return "", err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO payment_plans
+			(plan_id, organization_id, name, description, is_default, status, created_by, created_at, updated_by, updated_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5),
+		r.placeholder(6), r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10))
+	if _, err = tx.Exec(query,
+		planID,
+		req.OrganizationID,
+		req.Name,
+		req.Description,
+		req.IsDefault,
+		paymentPlanStatus(req),
+		req.UserID,
+		now,
+		req.UserID,
+		now,
+	); err != nil {
+		return "", err
+	}
+	if err = r.saveTerms(tx, planID, req.Terms); err != nil {
+		return "", err
+	}
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
+	return planID, nil
+}
+
+func (r *PaymentPlanRepository) Update(ctx context.Context, req *model.PaymentPlanUpsertRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	if req.IsDefault {
+		if err = r.clearDefault(tx, req.UserID, now); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE payment_plans
+		SET name = %s, description = %s, is_default = %s, status = %s, updated_by = %s, updated_at = %s
+		WHERE plan_id = %s AND organization_id = %s AND status IN (1, 2)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5),
+		r.placeholder(6), r.placeholder(7), r.placeholder(8))
+	result, err := tx.Exec(query,
+		req.Name,
+		req.Description,
+		req.IsDefault,
+		paymentPlanStatus(req),
+		req.UserID,
+		now,
+		req.PlanID,
+		req.OrganizationID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, aerr := result.RowsAffected(); aerr == nil && affected == 0 {
+		err = sql.ErrNoRows
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM payment_plan_terms WHERE plan_id = %s`, r.placeholder(1))
+	if _, err = tx.Exec(deleteQuery, req.PlanID); err != nil {
+		return err
+	}
+	if err = r.saveTerms(tx, req.PlanID, req.Terms); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PaymentPlanRepository) Delete(ctx context.Context, userID, planID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE payment_plans SET status = 0, is_default = false, updated_by = %s, updated_at = %s
+		WHERE plan_id = %s AND organization_id = %s AND status IN (1, 2)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	result, err := t.Exec(query, userID, time.Now(), planID, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// clearDefault unsets the current default plan so only one plan is the default.
+func (r *PaymentPlanRepository) clearDefault(tx *database.TenantTx, userID string, now time.Time) error {
+	query := fmt.Sprintf(`
+		UPDATE payment_plans SET is_default = false, updated_by = %s, updated_at = %s
+		WHERE organization_id = %s AND is_default = true
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3))
+	_, err := tx.Exec(query, userID, now, tx.OrganizationID())
+	return err
+}
+
+func (r *PaymentPlanRepository) saveTerms(tx *database.TenantTx, planID string, terms []model.PaymentPlanTerm) error {
+	query := fmt.Sprintf(`
+		INSERT INTO payment_plan_terms (term_id, plan_id, seq, label, percentage, due_type, due_days)
+		VALUES (%s, %s, %s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6), r.placeholder(7))
+	for _, t := range terms {
+		if _, err := tx.Exec(query, uuid.New().String(), planID, t.Seq, t.Label, t.Percentage, t.DueType, t.DueDays); err != nil {
+			return fmt.Errorf("insert payment plan term: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetFleetOrderSchedule returns the amounts and dates an installment schedule
+// is computed from.
+func (r *PaymentPlanRepository) GetFleetOrderSchedule(ctx context.Context, orderID string) (float64, time.Time, time.Time, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(total_amount, 0), created_at, start_date
+		FROM fleet_orders
+		WHERE organization_id = %s AND order_id = %s
+		LIMIT 1
+	`, r.placeholder(1), r.placeholder(2))
+	var total float64
+	var createdAt, startDate sql.NullTime
+	if err := t.QueryRow(query, t.OrganizationID(), orderID).Scan(&total, &createdAt, &startDate); err != nil {
+		return 0, time.Time{}, time.Time{}, err
+	}
+	return total, createdAt.Time, startDate.Time, nil
+}
+
+const selectOrderInstallment = `
+SELECT
+	installment_id,
+	order_id,
+	COALESCE(order_type, 0) AS order_type,
+	COALESCE(CAST(plan_id AS CHAR(36)), '') AS plan_id,
+	seq,
+	COALESCE(label, '') AS label,
+	COALESCE(percentage, 0) AS percentage,
+	COALESCE(amount, 0) AS amount,
+	due_date,
+	COALESCE(status, 0) AS status,
+	COALESCE(invoice_number, '') AS invoice_number,
+	COALESCE(snap_token, '') AS snap_token,
+	COALESCE(redirect_url, '') AS redirect_url,
+	COALESCE(payment_gateway, '') AS payment_gateway,
+	link_created_at,
+	paid_at
+FROM order_installments
+`
+
+func (r *PaymentPlanRepository) queryInstallments(t *database.Tenant, query string, args ...interface{}) ([]model.OrderInstallment, error) {
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.OrderInstallment, 0)
+	for rows.Next() {
+		var it model.OrderInstallment
+		var dueDate, linkCreatedAt, paidAt sql.NullTime
+		if err := rows.Scan(
+			&it.InstallmentID,
+			&it.OrderID,
+			&it.OrderType,
+			&it.PlanID,
+			&it.Seq,
+			&it.Label,
+			&it.Percentage,
+			&it.Amount,
+			&dueDate,
+			&it.Status,
+			&it.InvoiceNumber,
+			&it.SnapToken,
+			&it.RedirectURL,
+			&it.PaymentGateway,
+			&linkCreatedAt,
+			&paidAt,
+		); err != nil {
+			return nil, err
+		}
+		if dueDate.Valid {
+			it.DueDate = dueDate.Time.Format("2006-01-02")
+		}
+		if linkCreatedAt.Valid {
+			it.LinkCreatedAt = linkCreatedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if paidAt.Valid {
+			it.PaidAt = paidAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.StatusLabel = model.InstallmentStatusLabel[it.Status]
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOrderInstallments returns the installment schedule of an order.
+func (r *PaymentPlanRepository) ListOrderInstallments(ctx context.Context, orderID string) ([]model.OrderInstallment, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectOrderInstallment + " WHERE organization_id = " + r.placeholder(1) + " AND order_id = " + r.placeholder(2) + " AND status > 0 ORDER BY seq ASC"
+	return r.queryInstallments(t, query, t.OrganizationID(), orderID)
+}
+
+func (r *PaymentPlanRepository) GetInstallment(ctx context.Context, orderID, installmentID string) (*model.OrderInstallment, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectOrderInstallment + " WHERE organization_id = " + r.placeholder(1) + " AND order_id = " + r.placeholder(2) + " AND installment_id = " + r.placeholder(3) + " AND status > 0"
+	items, err := r.queryInstallments(t, query, t.OrganizationID(), orderID, installmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+// ReplaceOrderInstallments swaps the schedule of an order for a new one, as
+// long as none of its installments is paid or waiting for a payment.
+func (r *PaymentPlanRepository) ReplaceOrderInstallments(ctx context.Context, orderID string, orderType int, items []model.OrderInstallment) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	lockedQuery := fmt.Sprintf(`
+		SELECT COUNT(1) FROM order_installments
+		WHERE organization_id = %s AND order_id = %s AND status IN (%d, %d)
+	`, r.placeholder(1), r.placeholder(2), model.InstallmentStatusPending, model.InstallmentStatusPaid)
+	var locked int
+	if err = tx.QueryRow(lockedQuery, orgID, orderID).Scan(&locked); err != nil {
+		return err
+	}
+	if locked > 0 {
+		err = ErrInstallmentsLocked
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM order_installments WHERE organization_id = %s AND order_id = %s`, r.placeholder(1), r.placeholder(2))
+	if _, err = tx.Exec(deleteQuery, orgID, orderID); err != nil {
+		return err
+	}
+	if err = saveOrderInstallments(tx, r.placeholder, orderID, orderType, items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetInstallmentPaymentLink stores the payment gateway invoice issued for an
+// installment and marks it as waiting for payment.
+func (r *PaymentPlanRepository) SetInstallmentPaymentLink(ctx context.Context, installmentID, invoiceNumber, paymentGateway, snapToken, redirectURL string, at time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE order_installments
+		SET status = %d, invoice_number = %s, payment_gateway = %s, snap_token = %s, redirect_url = %s, link_created_at = %s, updated_at = %s
+		WHERE organization_id = %s AND installment_id = %s AND status IN (%d, %d)
+	`, model.InstallmentStatusPending, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5),
+		r.placeholder(6), r.placeholder(7), r.placeholder(8), model.InstallmentStatusUnpaid, model.InstallmentStatusPending)
+	result, err := t.Exec(query, invoiceNumber, paymentGateway, snapToken, redirectURL, at, at, t.OrganizationID(), installmentID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// saveOrderInstallments stores an installment schedule inside the order
+// transaction and fills in the ids of the stored installments.
+func saveOrderInstallments(tx *database.TenantTx, placeholder func(int) string, orderID string, orderType int, items []model.OrderInstallment) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO order_installments
+			(installment_id, organization_id, order_id, order_type, plan_id, seq, label, percentage, amount, due_date, status, created_at, updated_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %d, %s, %s)
+	`, placeholder(1), placeholder(2), placeholder(3), placeholder(4), placeholder(5), placeholder(6), placeholder(7),
+		placeholder(8), placeholder(9), placeholder(10), model.InstallmentStatusUnpaid, placeholder(11), placeholder(12))
+	now := time.Now()
+	for i := range items {
+		it := &items[i]
+		if it.InstallmentID == "" {
+			it.InstallmentID = uuid.New().String()
+		}
+		it.OrderID = orderID
+		it.OrderType = orderType
+		if _, err := tx.Exec(query,
+			it.InstallmentID,
+			tx.OrganizationID(),
+			orderID,
+			orderType,
+			nullableString(it.PlanID),
+			it.Seq,
+			it.Label,
+			it.Percentage,
+			it.Amount,
+			it.DueDate,
+			now,
+			now,
+		); err != nil {
+			return fmt.Errorf("insert order installment: %w", err)
+		}
+	}
+	return nil
+}
+
+// resizeInstallments spreads a new order total over a schedule. Installments
+// paid or waiting for a payment keep their amount; the unpaid ones take their
+// percentage of the total and the last of them absorbs the rounding. It
+// returns false when the unpaid installments cannot make up the total.
+func resizeInstallments(items []model.OrderInstallment, total float64) bool {
+	last := -1
+	fixed := 0.0
+	for i, it := range items {
+		if it.Status == model.InstallmentStatusUnpaid {
+			last = i
+		} else {
+			fixed += it.Amount
+		}
+	}
+	if last < 0 {
+		return false
+	}
+
+	allocated := fixed
+	for i := range items {
+		if items[i].Status != model.InstallmentStatusUnpaid || i == last {
+			continue
+		}
+		items[i].Amount = math.Round(total * items[i].Percentage / 100)
+		allocated += items[i].Amount
+	}
+	if total-allocated < 0 {
+		return false
+	}
+	items[last].Amount = total - allocated
+	return true
+}
+
+// resizeOrderInstallments resizes the installment schedule of an order inside
+// the transaction that changed its total. An order without a schedule is left
+// alone; ErrInstallmentsLocked is returned when the schedule cannot take the
+// new total.
+func resizeOrderInstallments(tx *database.TenantTx, placeholder func(int) string, orderID string, total float64) error {
+	orgID := tx.OrganizationID()
+	query := fmt.Sprintf(`
+		SELECT installment_id, COALESCE(percentage, 0), COALESCE(amount, 0), COALESCE(status, 0)
+		FROM order_installments
+		WHERE organization_id = %s AND order_id = %s AND status > 0
+		ORDER BY seq ASC
+		FOR UPDATE
+	`, placeholder(1), placeholder(2))
+	rows, err := tx.Query(query, orgID, orderID)
+	if err != nil {
+		return err
+	}
+	items := make([]model.OrderInstallment, 0)
+	for rows.Next() {
+		var it model.OrderInstallment
+		if err := rows.Scan(&it.InstallmentID, &it.Percentage, &it.Amount, &it.Status); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	if !resizeInstallments(items, total) {
+		return ErrInstallmentsLocked
+	}
+
+	update := fmt.Sprintf(`
+		UPDATE order_installments
+		SET amount = %s, updated_at = %s
+		WHERE organization_id = %s AND installment_id = %s AND status = %d
+	`, placeholder(1), placeholder(2), placeholder(3), placeholder(4), model.InstallmentStatusUnpaid)
+	now := time.Now()
+	for _, it := range items {
+		if it.Status != model.InstallmentStatusUnpaid {
+			continue
+		}
+		if _, err := tx.Exec(update, it.Amount, now, orgID, it.InstallmentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
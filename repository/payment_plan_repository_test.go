@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestPaymentPlanRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPaymentPlanRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"List":       func(ctx context.Context) error { _, err := r.List(ctx); return err },
+		"ListActive": func(ctx context.Context) error { _, err := r.ListActive(ctx); return err },
+		"GetByID":    func(ctx context.Context) error { _, err := r.GetByID(ctx, planOfB); return err },
+		"GetDefault": func(ctx context.Context) error { _, err := r.GetDefault(ctx); return err },
+		"Update": func(ctx context.Context) error {
+			organizationID, _ := database.OrganizationFromContext(ctx)
+			return r.Update(ctx, &model.PaymentPlanUpsertRequest{OrganizationID: organizationID, PlanID: planOfB})
+		},
+		"Delete": func(ctx context.Context) error { return r.Delete(ctx, "", planOfB) },
+		"GetFleetOrderSchedule": func(ctx context.Context) error {
+			_, _, _, err := r.GetFleetOrderSchedule(ctx, orderOfB)
+			return err
+		},
+		"ListOrderInstallments": func(ctx context.Context) error {
+			_, err := r.ListOrderInstallments(ctx, orderOfB)
+			return err
+		},
+		"GetInstallment": func(ctx context.Context) error {
+			_, err := r.GetInstallment(ctx, orderOfB, "installment-of-b")
+			return err
+		},
+		"ReplaceOrderInstallments": func(ctx context.Context) error {
+			return r.ReplaceOrderInstallments(ctx, orderOfB, model.InstallmentOrderFleet, nil)
+		},
+		"SetInstallmentPaymentLink": func(ctx context.Context) error {
+			return r.SetInstallmentPaymentLink(ctx, "installment-of-b", "INV-1", "midtrans", "", "", time.Now())
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error {
+			_, err := r.Create(ctxA, &model.PaymentPlanUpsertRequest{OrganizationID: tenantB, Name: "DP 30%", IsDefault: true})
+			return err
+		},
+		"Update": func() error {
+			return r.Update(ctxA, &model.PaymentPlanUpsertRequest{OrganizationID: tenantB, PlanID: planOfB})
+		},
+	})
+}
+
+func TestResizeInstallments(t *testing.T) {
+	schedule := func(statuses ...int) []model.OrderInstallment {
+		items := []model.OrderInstallment{
+			{Percentage: 30, Amount: 300000},
+			{Percentage: 30, Amount: 300000},
+			{Percentage: 40, Amount: 400000},
+		}
+		for i := range items {
+			items[i].Status = statuses[i]
+		}
+		return items
+	}
+	amounts := func(items []model.OrderInstallment) []float64 {
+		out := make([]float64, len(items))
+		for i, it := range items {
+			out[i] = it.Amount
+		}
+		return out
+	}
+	unpaid, pending, paid := model.InstallmentStatusUnpaid, model.InstallmentStatusPending, model.InstallmentStatusPaid
+
+	tests := []struct {
+		name  string
+		items []model.OrderInstallment
+		total float64
+		want  []float64
+		ok    bool
+	}{
+		{"unpaid schedule follows the percentages", schedule(unpaid, unpaid, unpaid), 1500001, []float64{450000, 450000, 600001}, true},
+		{"paid installment keeps its amount", schedule(paid, unpaid, unpaid), 1500000, []float64{300000, 450000, 750000}, true},
+		{"lower total taken from the last unpaid", schedule(paid, pending, unpaid), 800000, []float64{300000, 300000, 200000}, true},
+		{"unpaid cannot go below zero", schedule(paid, pending, unpaid), 500000, nil, false},
+		{"nothing left unpaid", schedule(paid, paid, pending), 1200000, nil, false},
+	}
+	for _, tt := range tests {
+		ok := resizeInstallments(tt.items, tt.total)
+		if ok != tt.ok {
+			t.Errorf("%s: got %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		got := amounts(tt.items)
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"service-travego/configs"
+	"service-travego/database"
+	"service-travego/model"
+)
+
+// ListPendingMidtransPayments returns Midtrans invoices created before
+// createdBefore that are still waiting for their final notification:
+// subscription invoices (travego_transactions) and order payments
+// (payment_orders issued through the payment gateway). The invoices of every
+// organization are listed: the reconciliation runs for none in particular.
+func (r *paymentRepository) ListPendingMidtransPayments(ctx context.Context, createdBefore time.Time) ([]model.PendingMidtransPayment, error) {
+	query := fmt.Sprintf(`
+		SELECT invoice_number, COALESCE(organization_id::text, ''), '', 0, created_at
+		FROM travego_transactions
+		WHERE status = 2 AND invoice_number IS NOT NULL AND created_at < %s
+		  AND COALESCE(payment_gateway, 'midtrans') = 'midtrans'
+		UNION ALL
+		SELECT invoice_number, COALESCE(organization_id::text, ''), COALESCE(order_id, ''), COALESCE(order_type, 0), created_at
+		FROM payment_orders
+		WHERE payment_method = 1004 AND COALESCE(status, 0) = 0 AND COALESCE(payment_status, 0) <> %d
+		  AND invoice_number IS NOT NULL AND created_at < %s
+		  AND COALESCE(payment_gateway, 'midtrans') = 'midtrans'
+		ORDER BY 5`,
+		r.getPlaceholder(1), configs.PaymentStatusCancelled, r.getPlaceholder(2))
+
+	rows, err := database.QueryContext(ctx, r.db, query, createdBefore, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.PendingMidtransPayment
+	for rows.Next() {
+		var it model.PendingMidtransPayment
+		var createdAt sql.NullTime
+		if err := rows.Scan(&it.InvoiceNumber, &it.OrganizationID, &it.OrderID, &it.OrderType, &createdAt); err != nil {
+			return nil, err
+		}
+		it.CreatedAt = createdAt.Time
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// ExpireTravegoTransaction closes a subscription invoice Midtrans will never settle
+func (r *paymentRepository) ExpireTravegoTransaction(ctx context.Context, invoiceNumber string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf("UPDATE travego_transactions SET status = 0, updated_at = NOW() WHERE status = 2 AND invoice_number = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
+	_, err = t.Exec(query, invoiceNumber, organizationID)
+	return err
+}
+
+// FailGatewayPaymentOrder closes an order payment the gateway expired, denied
+// or cancelled. The installment it was issued for goes back to unpaid so a new
+// link can be created, and an order waiting for this payment goes back to
+// waiting payment (or partially paid).
+func (r *paymentRepository) FailGatewayPaymentOrder(ctx context.Context, invoiceNumber string) (err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var orderID string
+	var orderType int64
+	metaQuery := fmt.Sprintf(`
+		SELECT order_id, COALESCE(order_type, 0)
+		FROM payment_orders
+		WHERE invoice_number = %s AND organization_id = %s AND COALESCE(status, 0) = 0
+		LIMIT 1`, r.getPlaceholder(1), r.getPlaceholder(2))
+	if err = tx.QueryRow(metaQuery, invoiceNumber, organizationID).Scan(&orderID, &orderType); err != nil {
+		if err == sql.ErrNoRows {
+			err = nil
+			_ = tx.Rollback()
+			return nil
+		}
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		UPDATE payment_orders SET payment_status = %d, updated_at = NOW()
+		WHERE invoice_number = %s AND organization_id = %s AND COALESCE(status, 0) = 0`,
+		configs.PaymentStatusCancelled, r.getPlaceholder(1), r.getPlaceholder(2)), invoiceNumber, organizationID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		UPDATE order_installments
+		SET status = %d, invoice_number = NULL, snap_token = NULL, redirect_url = NULL, payment_gateway = NULL, link_created_at = NULL, updated_at = NOW()
+		WHERE invoice_number = %s AND organization_id = %s AND status = %d`,
+		model.InstallmentStatusUnpaid, r.getPlaceholder(1), r.getPlaceholder(2), model.InstallmentStatusPending), invoiceNumber, organizationID)
+	if err != nil {
+		return err
+	}
+
+	var table string
+	switch orderType {
+	case 1:
+		table = "fleet_orders"
+	case 2:
+		table = "tour_package_orders"
+	default:
+		return tx.Commit()
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		UPDATE %[1]s
+		SET payment_status = CASE WHEN EXISTS (
+				SELECT 1 FROM payment_orders po
+				WHERE po.order_id = %[2]s AND po.order_type = %[3]s AND po.organization_id = %[1]s.organization_id AND COALESCE(po.status, 0) > 0
+			) THEN %[4]d ELSE %[5]d END,
+			updated_at = NOW()
+		WHERE order_id = %[6]s AND %[1]s.organization_id = %[7]s AND payment_status = %[8]d`,
+		table, r.getPlaceholder(1), r.getPlaceholder(2), configs.PaymentStatusPartiallyPaid, configs.PaymentStatusWaitingPayment,
+		r.getPlaceholder(3), r.getPlaceholder(4), configs.PaymentStatusWaitingApproval), orderID, orderType, orderID, organizationID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListPendingMidtransRefunds returns recorded order refunds that were not
+// (completely) sent to Midtrans yet, with what was already sent for each, of
+// every organization.
+func (r *paymentRepository) ListPendingMidtransRefunds(ctx context.Context) ([]model.PendingMidtransRefund, error) {
+	query := fmt.Sprintf(`
+		SELECT tr.refund_id, COALESCE(tr.organization_id::text, ''), COALESCE(tr.reference_id, ''), COALESCE(tr.amount, 0), COALESCE(tr.description, ''),
+			COALESCE((
+				SELECT SUM(mr.amount) FROM payment_midtrans_refunds mr
+				WHERE mr.refund_id = tr.refund_id AND mr.status <> '%s'
+			), 0),
+			(SELECT COUNT(1) FROM payment_midtrans_refunds mr WHERE mr.refund_id = tr.refund_id)
+		FROM transaction_refund tr
+		WHERE tr.midtrans_refund_status IS NULL
+		ORDER BY tr.created_at`, model.MidtransRefundFailed)
+
+	rows, err := database.QueryContext(ctx, r.db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.PendingMidtransRefund
+	for rows.Next() {
+		var it model.PendingMidtransRefund
+		if err := rows.Scan(&it.RefundID, &it.OrganizationID, &it.OrderID, &it.Amount, &it.Description, &it.IssuedAmount, &it.IssuedCount); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// ListMidtransSettledPayments returns the order's paid Midtrans invoices, the
+// newest first, with the amount already refunded (or being refunded) on each.
+func (r *paymentRepository) ListMidtransSettledPayments(ctx context.Context, orderID string) ([]model.MidtransSettledPayment, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		SELECT po.invoice_number, COALESCE(po.payment_amount, 0),
+			COALESCE((
+				SELECT SUM(mr.amount) FROM payment_midtrans_refunds mr
+				WHERE mr.invoice_number = po.invoice_number AND mr.organization_id = po.organization_id AND mr.status <> '%s'
+			), 0)
+		FROM payment_orders po
+		WHERE po.order_id = %s AND po.organization_id = %s AND po.payment_method = 1004
+		  AND COALESCE(po.status, 0) > 0 AND po.invoice_number IS NOT NULL
+		  AND COALESCE(po.payment_gateway, 'midtrans') = 'midtrans'
+		ORDER BY po.created_at DESC`,
+		model.MidtransRefundFailed, r.getPlaceholder(1), r.getPlaceholder(2))
+
+	rows, err := t.Query(query, orderID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.MidtransSettledPayment
+	for rows.Next() {
+		var it model.MidtransSettledPayment
+		if err := rows.Scan(&it.InvoiceNumber, &it.PaymentAmount, &it.RefundedAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// SaveMidtransRefund inserts the refund, or updates its status when the refund
+// key was already sent before.
+func (r *paymentRepository) SaveMidtransRefund(ctx context.Context, refund *model.MidtransRefund) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	res, err := t.Exec(fmt.Sprintf(`
+		UPDATE payment_midtrans_refunds SET status = %s, status_message = %s, updated_at = %s
+		WHERE refund_key = %s AND organization_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5)),
+		refund.Status, refund.StatusMessage, now, refund.RefundKey, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return r.markPaymentOrderRefunded(t, refund)
+	}
+
+	_, err = t.Exec(fmt.Sprintf(`
+		INSERT INTO payment_midtrans_refunds
+			(refund_key, refund_id, organization_id, order_id, invoice_number, amount, status, status_message, created_at, updated_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
+		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10)),
+		refund.RefundKey, refund.RefundID, refund.OrganizationID, refund.OrderID, refund.InvoiceNumber,
+		refund.Amount, refund.Status, refund.StatusMessage, now, now)
+	if err != nil {
+		return err
+	}
+	return r.markPaymentOrderRefunded(t, refund)
+}
+
+func (r *paymentRepository) markPaymentOrderRefunded(t *database.Tenant, refund *model.MidtransRefund) error {
+	if refund.Status != model.MidtransRefundRefunded {
+		return nil
+	}
+	query := fmt.Sprintf("UPDATE payment_orders SET refund_at = NOW(), updated_at = NOW() WHERE invoice_number = %s AND organization_id = %s AND refund_at IS NULL", r.getPlaceholder(1), r.getPlaceholder(2))
+	_, err := t.Exec(query, refund.InvoiceNumber, t.OrganizationID())
+	return err
+}
+
+// ListMidtransRefunds returns the Midtrans refunds with the given status,
+// optionally only those of one invoice. The refunds of every organization are
+// listed: the reconciliation and the gateway notifications run for none in
+// particular.
+func (r *paymentRepository) ListMidtransRefunds(ctx context.Context, status string, invoiceNumber string) ([]model.MidtransRefund, error) {
+	query := fmt.Sprintf(`
+		SELECT refund_key, refund_id, organization_id, order_id, invoice_number, amount, status, COALESCE(status_message, '')
+		FROM payment_midtrans_refunds
+		WHERE status = %s`, r.getPlaceholder(1))
+	args := []interface{}{status}
+	if invoiceNumber != "" {
+		query += " AND invoice_number = " + r.getPlaceholder(2)
+		args = append(args, invoiceNumber)
+	}
+	query += " ORDER BY created_at"
+
+	rows, err := database.QueryContext(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.MidtransRefund
+	for rows.Next() {
+		var it model.MidtransRefund
+		if err := rows.Scan(&it.RefundKey, &it.RefundID, &it.OrganizationID, &it.OrderID, &it.InvoiceNumber, &it.Amount, &it.Status, &it.StatusMessage); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// UpdateRefundMidtransStatus sets the Midtrans summary of an order refund
+func (r *paymentRepository) UpdateRefundMidtransStatus(ctx context.Context, refundID string, status string, amount float64, message string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		UPDATE transaction_refund
+		SET midtrans_refund_status = %s, midtrans_refund_amount = %s, midtrans_refund_message = %s
+		WHERE refund_id = %s AND organization_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+	_, err = t.Exec(query, status, amount, nullableString(message), refundID, organizationID)
+	return err
+}
+
+// SyncRefundMidtransStatus recomputes the Midtrans summary of an order refund
+// from its Midtrans refunds: FAILED if any failed, REQUESTED while any is
+// still processed, REFUNDED otherwise.
+func (r *paymentRepository) SyncRefundMidtransStatus(ctx context.Context, refundID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf(`
+		UPDATE transaction_refund
+		SET midtrans_refund_status = CASE
+				WHEN EXISTS (SELECT 1 FROM payment_midtrans_refunds mr WHERE mr.refund_id = %[1]s AND mr.organization_id = transaction_refund.organization_id AND mr.status = '%[2]s') THEN '%[2]s'
+				WHEN EXISTS (SELECT 1 FROM payment_midtrans_refunds mr WHERE mr.refund_id = %[1]s AND mr.organization_id = transaction_refund.organization_id AND mr.status = '%[3]s') THEN '%[3]s'
+				ELSE '%[4]s' END,
+			midtrans_refund_amount = COALESCE((
+				SELECT SUM(mr.amount) FROM payment_midtrans_refunds mr WHERE mr.refund_id = %[1]s AND mr.organization_id = transaction_refund.organization_id AND mr.status = '%[4]s'
+			), 0),
+			midtrans_refund_message = (
+				SELECT mr.status_message FROM payment_midtrans_refunds mr WHERE mr.refund_id = %[1]s AND mr.organization_id = transaction_refund.organization_id AND mr.status = '%[2]s' LIMIT 1
+			)
+		WHERE refund_id = %[1]s AND transaction_refund.organization_id = %[5]s
+			AND EXISTS (SELECT 1 FROM payment_midtrans_refunds mr WHERE mr.refund_id = %[1]s AND mr.organization_id = transaction_refund.organization_id)`,
+		r.getPlaceholder(1), model.MidtransRefundFailed, model.MidtransRefundRequested, model.MidtransRefundRefunded, r.getPlaceholder(2))
+	_, err = t.Exec(query, refundID, organizationID)
+	return err
+}
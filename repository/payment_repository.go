@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -13,50 +15,75 @@ import (
 
 // PaymentRepository adalah interface untuk akses data payment
 type PaymentRepository interface {
-	GetOrderTotalAmount(orderID string, orderType int64) (int64, error)
-	GetOrderTotalPaidAmount(orderID string, orderType int64, organizationID string) (float64, error)
-	UpdatePaymentStatus(orderID string, orderType int64, status int) error
-	UpdateOrderStatus(orderID string, orderType int64, status int, paymentStatus int) error
-	UpdateOrderPaymentStatus(orderID string, orderType int64, paymentStatus int) error
-	GetOrderDetails(InvoiceNumber string) (organizationID string, totalAmount int64, orderType int64, orderID string, err error)
-	UpdatePaymentOrderNotification(orderID string, organizationID string, totalAmount int64, paymentAmount float64, transactionID string, paymentType string) error
-	InsertPaymentMidtrans(req *model.MidtransWebhookRequest, createdAt string) error
-	InsertPaymentOrder(paymentID string, orderType int64, orderID string, organizationID string, paymentType int, paymentMethod int, invoiceNumber string, createdAt string, createdBy string) error
-	GetPaymentOrderMeta(orderID string, organizationID string) (invoiceNumber string, orderType int64, paymentType int64, paymentMethod int64, createdBy string, err error)
-	GetLatestPaymentOrderRemainingAmount(orderID string, organizationID string, orderType int64) (remainingAmount sql.NullFloat64, err error)
-	GetFleetOrderEmailData(orderID string, organizationID string) (customerName string, customerEmail string, fleetName string, pickupLocation string, startDate time.Time, endDate time.Time, destination string, err error)
-	TransactionExistsByInvoice(organizationID string, invoiceNumber string) (bool, error)
-	InsertTransactionMidtrans(transactionID string, orderType int64, invoiceNumber string, description string, transactionDate time.Time, paymentType int64, paymentMethod int64, amount float64, organizationID string, transactionCategory string, createdAt time.Time, createdBy string, orderID string) error
-	GetNextInvoiceNumber(organizationID string, orderType int) (string, error)
-	GetSubscriptionDetail(invoiceNumber string) (transactionID string, packageID string, startDate time.Time, expiryDate time.Time, userID string, organizationID string, paymentMethod sql.NullString, createdAt time.Time, paymentAmount sql.NullFloat64, err error)
-	UpdateTravegoTransactionStatus(invoiceNumber string, paymentMethod string, grossAmount float64) error
-	GetSubscriptionByOrganization(organizationID string) (exists bool, err error)
-	UpdateSubscription(organizationID string, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64) error
-	InsertSubscription(subscriptionID string, organizationID string, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64, createdAt time.Time) error
+	GetOrderTotalAmount(ctx context.Context, orderID string, orderType int64) (int64, error)
+	GetOrderTotalPaidAmount(ctx context.Context, orderID string, orderType int64) (float64, error)
+	UpdatePaymentStatus(ctx context.Context, orderID string, orderType int64, status int) error
+	UpdateOrderStatus(ctx context.Context, orderID string, orderType int64, status int, paymentStatus int) error
+	UpdateOrderPaymentStatus(ctx context.Context, orderID string, orderType int64, paymentStatus int) error
+	GetOrderDetails(ctx context.Context, InvoiceNumber string) (organizationID string, totalAmount int64, orderType int64, orderID string, err error)
+	GetOrderOrganization(ctx context.Context, orderID string) (string, error)
+	UpdatePaymentOrderNotification(ctx context.Context, orderID string, totalAmount int64, paymentAmount float64, transactionID string, notes string) error
+	InsertPaymentMidtrans(ctx context.Context, req *model.MidtransWebhookRequest, createdAt string) error
+	MarkOrderInstallmentPaid(ctx context.Context, invoiceNumber string, paidAt time.Time) error
+	InsertPaymentOrder(ctx context.Context, paymentID string, orderType int64, orderID string, paymentType int, paymentMethod int, invoiceNumber string, paymentGateway string, createdAt string, createdBy string) error
+	GetPaymentOrderMeta(ctx context.Context, orderID string) (invoiceNumber string, orderType int64, paymentType int64, paymentMethod int64, createdBy string, err error)
+	GetLatestPaymentOrderRemainingAmount(ctx context.Context, orderID string, orderType int64) (remainingAmount sql.NullFloat64, err error)
+	GetFleetOrderEmailData(ctx context.Context, orderID string) (customerName string, customerEmail string, fleetName string, pickupLocation string, startDate time.Time, endDate time.Time, destination string, err error)
+	TransactionExistsByInvoice(ctx context.Context, invoiceNumber string) (bool, error)
+	InsertTransactionMidtrans(ctx context.Context, transactionID string, orderType int64, invoiceNumber string, description string, transactionDate time.Time, paymentType int64, paymentMethod int64, amount float64, transactionCategory string, createdAt time.Time, createdBy string, orderID string) error
+	GetNextInvoiceNumber(ctx context.Context, orderType int) (string, error)
+	GetSubscriptionOrganization(ctx context.Context, invoiceNumber string) (string, error)
+	GetSubscriptionDetail(ctx context.Context, invoiceNumber string) (transactionID string, packageID string, startDate time.Time, expiryDate time.Time, userID string, organizationID string, paymentMethod sql.NullString, createdAt time.Time, paymentAmount sql.NullFloat64, err error)
+	UpdateTravegoTransactionStatus(ctx context.Context, invoiceNumber string, paymentMethod string, grossAmount float64) error
+	GetSubscriptionByOrganization(ctx context.Context) (exists bool, err error)
+	UpdateSubscription(ctx context.Context, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64) error
+	InsertSubscription(ctx context.Context, subscriptionID string, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64, createdAt time.Time) error
+	ScheduleSubscription(ctx context.Context, packageID string, expiryDate time.Time, packagePrice float64) error
+	GetSubscriptionLifecycle(ctx context.Context) (*model.SubscriptionLifecycle, error)
+	InsertSubscriptionEvent(ctx context.Context, event *model.SubscriptionEvent) error
+	ListPendingMidtransPayments(ctx context.Context, createdBefore time.Time) ([]model.PendingMidtransPayment, error)
+	ExpireTravegoTransaction(ctx context.Context, invoiceNumber string) error
+	FailGatewayPaymentOrder(ctx context.Context, invoiceNumber string) error
+	InsertPaymentXendit(ctx context.Context, req *model.XenditInvoiceCallback, createdAt string) error
+	ListPendingMidtransRefunds(ctx context.Context) ([]model.PendingMidtransRefund, error)
+	ListMidtransSettledPayments(ctx context.Context, orderID string) ([]model.MidtransSettledPayment, error)
+	SaveMidtransRefund(ctx context.Context, refund *model.MidtransRefund) error
+	ListMidtransRefunds(ctx context.Context, status string, invoiceNumber string) ([]model.MidtransRefund, error)
+	UpdateRefundMidtransStatus(ctx context.Context, refundID string, status string, amount float64, message string) error
+	SyncRefundMidtransStatus(ctx context.Context, refundID string) error
+	InsertPaymentNotification(ctx context.Context, rec *model.PaymentNotificationRecord) (bool, error)
+	UpdatePaymentNotificationOutcome(ctx context.Context, notificationID string, outcome string, message string, releaseEvent bool) error
+	ClaimPaymentNotificationEvent(ctx context.Context, notificationID string, eventKey string) (bool, error)
+	GetPaymentNotification(ctx context.Context, notificationID string) (*model.PaymentNotificationRecord, error)
+	ListPaymentNotifications(ctx context.Context, filter model.PaymentNotificationFilter) ([]model.PaymentNotificationRecord, error)
 }
 
 type paymentRepository struct {
-	db     *sql.DB
-	driver string
+	db            *sql.DB
+	driver        string
+	subscriptions *SubscriptionRepository
 }
 
 // NewPaymentRepository membuat instance baru dari PaymentRepository
 func NewPaymentRepository(db *sql.DB, driver string) PaymentRepository {
 	return &paymentRepository{
-		db:     db,
-		driver: driver,
+		db:            db,
+		driver:        driver,
+		subscriptions: NewSubscriptionRepository(db, driver),
 	}
 }
 
-// GetOrderDetails retrieves organization_id and total_amount from fleet_orders or tour_package_orders
-func (r *paymentRepository) GetOrderDetails(InvoiceNumber string) (string, int64, int64, string, error) {
+// GetOrderDetails retrieves organization_id and total_amount from fleet_orders or tour_package_orders.
+// The invoice of any organization is looked up: a gateway notification tells
+// the organization only through its invoice number.
+func (r *paymentRepository) GetOrderDetails(ctx context.Context, InvoiceNumber string) (string, int64, int64, string, error) {
 	// Try payment_orders first
 	query := fmt.Sprintf("SELECT organization_id, total_amount, order_type, order_id FROM payment_orders WHERE invoice_number = %s LIMIT 1", r.getPlaceholder(1))
 	var orgID string
 	var totalAmount sql.NullInt64
 	var orderType int64
 	var orderID string
-	err := database.QueryRow(r.db, query, InvoiceNumber).Scan(&orgID, &totalAmount, &orderType, &orderID)
+	err := database.QueryRowContext(ctx, r.db, query, InvoiceNumber).Scan(&orgID, &totalAmount, &orderType, &orderID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", 0, 0, "", fmt.Errorf("order not found: %s", InvoiceNumber)
@@ -66,9 +93,33 @@ func (r *paymentRepository) GetOrderDetails(InvoiceNumber string) (string, int64
 	return orgID, totalAmount.Int64, orderType, orderID, nil
 }
 
-// UpdatePaymentOrderNotification updates payment_orders on Midtrans notification
-func (r *paymentRepository) UpdatePaymentOrderNotification(invoiceNumber string, organizationID string, totalAmount int64, paymentAmount float64, transactionID string, paymentType string) error {
-	tx, err := r.db.Begin()
+// GetOrderOrganization returns the organization of a fleet or tour package
+// order, of any organization: a stored notification that is replayed does not
+// tell it otherwise
+func (r *paymentRepository) GetOrderOrganization(ctx context.Context, orderID string) (string, error) {
+	query := fmt.Sprintf(`
+		SELECT organization_id FROM fleet_orders WHERE order_id = %s
+		UNION ALL
+		SELECT organization_id FROM tour_package_orders WHERE order_id = %s
+		LIMIT 1`, r.getPlaceholder(1), r.getPlaceholder(2))
+	var orgID string
+	if err := database.QueryRowContext(ctx, r.db, query, orderID, orderID).Scan(&orgID); err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("order not found: %s", orderID)
+		}
+		return "", err
+	}
+	return orgID, nil
+}
+
+// UpdatePaymentOrderNotification updates payment_orders on a payment gateway notification
+func (r *paymentRepository) UpdatePaymentOrderNotification(ctx context.Context, invoiceNumber string, totalAmount int64, paymentAmount float64, transactionID string, notes string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -99,7 +150,7 @@ func (r *paymentRepository) UpdatePaymentOrderNotification(invoiceNumber string,
 
 	var orderID string
 	var orderType int64
-	if qerr := database.TxQueryRow(tx, orderMetaQuery, invoiceNumber, organizationID).Scan(&orderID, &orderType); qerr != nil {
+	if qerr := tx.QueryRow(orderMetaQuery, invoiceNumber, organizationID).Scan(&orderID, &orderType); qerr != nil {
 		if qerr == sql.ErrNoRows {
 			return nil
 		}
@@ -119,7 +170,7 @@ func (r *paymentRepository) UpdatePaymentOrderNotification(invoiceNumber string,
 	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExprSum)
 
 	var sumPaid float64
-	if qerr := database.TxQueryRow(tx, sumPaidQuery, orderID, orderType, organizationID).Scan(&sumPaid); qerr != nil && qerr != sql.ErrNoRows {
+	if qerr := tx.QueryRow(sumPaidQuery, orderID, orderType, organizationID).Scan(&sumPaid); qerr != nil && qerr != sql.ErrNoRows {
 		err = qerr
 		return err
 	}
@@ -132,7 +183,7 @@ func (r *paymentRepository) UpdatePaymentOrderNotification(invoiceNumber string,
 	`, r.getPlaceholder(1), orgExpr, r.getPlaceholder(3))
 
 	var one int
-	txExistsErr := database.TxQueryRow(tx, txExistsQuery, invoiceNumber, organizationID, transactionID).Scan(&one)
+	txExistsErr := tx.QueryRow(txExistsQuery, invoiceNumber, organizationID, transactionID).Scan(&one)
 	transactionAlreadyCounted := txExistsErr == nil
 	if txExistsErr != nil && txExistsErr != sql.ErrNoRows {
 		err = txExistsErr
@@ -151,11 +202,11 @@ func (r *paymentRepository) UpdatePaymentOrderNotification(invoiceNumber string,
 
 	query := fmt.Sprintf(`
 		UPDATE payment_orders
-		SET total_amount = %s, payment_amount = %s, transaction_id = %s, status = 1, remaining_amount = %s, notes = 'Midtrans - ' || %s
+		SET total_amount = %s, payment_amount = %s, transaction_id = %s, status = 1, remaining_amount = %s, notes = %s
 		WHERE invoice_number = %s AND %s`,
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), orgExprUpdate)
 
-	_, err = database.TxExec(tx, query, totalAmount, paymentAmount, transactionID, remainingAmount, paymentType, invoiceNumber, organizationID)
+	_, err = tx.Exec(query, totalAmount, paymentAmount, transactionID, remainingAmount, notes, invoiceNumber, organizationID)
 	if err != nil {
 		return err
 	}
@@ -167,8 +218,30 @@ func (r *paymentRepository) UpdatePaymentOrderNotification(invoiceNumber string,
 	return nil
 }
 
-// InsertPaymentMidtrans inserts Midtrans notification payload into payment_midtrans
-func (r *paymentRepository) InsertPaymentMidtrans(req *model.MidtransWebhookRequest, createdAt string) error {
+// MarkOrderInstallmentPaid marks the installment the Midtrans invoice was issued for as paid
+func (r *paymentRepository) MarkOrderInstallmentPaid(ctx context.Context, invoiceNumber string, paidAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	orgExpr := "organization_id = " + r.getPlaceholder(4)
+	d := strings.ToLower(r.driver)
+	if d == "postgres" || d == "pgx" || d == "pq" {
+		orgExpr = "organization_id::text = " + r.getPlaceholder(4)
+	}
+	query := fmt.Sprintf(`
+		UPDATE order_installments
+		SET status = %d, paid_at = %s, updated_at = %s
+		WHERE invoice_number = %s AND %s AND status <> %d`,
+		model.InstallmentStatusPaid, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), orgExpr, model.InstallmentStatusPaid)
+	_, err = t.Exec(query, paidAt, paidAt, invoiceNumber, organizationID)
+	return err
+}
+
+// InsertPaymentMidtrans inserts Midtrans notification payload into payment_midtrans,
+// the gateway's log that belongs to no organization
+func (r *paymentRepository) InsertPaymentMidtrans(ctx context.Context, req *model.MidtransWebhookRequest, createdAt string) error {
 	query := fmt.Sprintf(`
 		INSERT INTO payment_midtrans
 			(transaction_id, transaction_status, order_id, payment_type, merchant_id, gross_amount, currency, transaction_time, payment_status, created_at)
@@ -177,7 +250,7 @@ func (r *paymentRepository) InsertPaymentMidtrans(req *model.MidtransWebhookRequ
 		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 		r.getPlaceholder(9), r.getPlaceholder(10))
 
-	_, err := database.Exec(r.db, query,
+	_, err := database.ExecContext(ctx, r.db, query,
 		req.TransactionID,
 		req.TransactionStatus,
 		req.OrderID,
@@ -192,12 +265,43 @@ func (r *paymentRepository) InsertPaymentMidtrans(req *model.MidtransWebhookRequ
 	return err
 }
 
+// InsertPaymentXendit inserts a Xendit invoice callback into payment_xendit, the
+// gateway's log that belongs to no organization
+func (r *paymentRepository) InsertPaymentXendit(ctx context.Context, req *model.XenditInvoiceCallback, createdAt string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO payment_xendit
+			(invoice_id, external_id, status, payment_method, payment_channel, amount, paid_amount, paid_at, currency, created_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
+		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
+		r.getPlaceholder(9), r.getPlaceholder(10))
+
+	_, err := database.ExecContext(ctx, r.db, query,
+		req.ID,
+		req.ExternalID,
+		req.Status,
+		req.PaymentMethod,
+		req.PaymentChannel,
+		req.Amount,
+		req.PaidAmount,
+		req.PaidAt,
+		req.Currency,
+		createdAt,
+	)
+	return err
+}
+
 // InsertPaymentOrder inserts a new row into payment_orders
-func (r *paymentRepository) InsertPaymentOrder(paymentID string, orderType int64, orderID string, organizationID string, paymentType int, paymentMethod int, invoiceNumber string, createdAt string, createdBy string) error {
+func (r *paymentRepository) InsertPaymentOrder(ctx context.Context, paymentID string, orderType int64, orderID string, paymentType int, paymentMethod int, invoiceNumber string, paymentGateway string, createdAt string, createdBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
-		INSERT INTO payment_orders (payment_id, order_type, order_id, organization_id, payment_type, payment_method, invoice_number, created_at, created_by, status)
-		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, 0)`,
-		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
+		INSERT INTO payment_orders (payment_id, order_type, order_id, organization_id, payment_type, payment_method, invoice_number, payment_gateway, created_at, created_by, status)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 0)`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
 
 	// Ensure empty strings are passed as NULL for UUID columns using sql.NullString
 	pid := sql.NullString{String: paymentID, Valid: paymentID != ""}
@@ -205,13 +309,18 @@ func (r *paymentRepository) InsertPaymentOrder(paymentID string, orderType int64
 	orgid := sql.NullString{String: organizationID, Valid: organizationID != ""}
 	cb := sql.NullString{String: createdBy, Valid: createdBy != ""}
 
-	fmt.Printf("[DEBUG] InsertPaymentOrder - pid: %v, oid: %v, orgid: %v, cb: %v\n", pid, oid, orgid, cb)
+	slog.DebugContext(ctx, "insert payment order", "payment_id", pid, "order_id", oid, "organization_id", orgid, "created_by", cb)
 
-	_, err := database.Exec(r.db, query, pid, orderType, oid, orgid, paymentType, paymentMethod, invoiceNumber, createdAt, cb)
+	_, err = t.Exec(query, pid, orderType, oid, orgid, paymentType, paymentMethod, invoiceNumber, nullableString(paymentGateway), createdAt, cb)
 	return err
 }
 
-func (r *paymentRepository) GetPaymentOrderMeta(orderID string, organizationID string) (string, int64, int64, int64, string, error) {
+func (r *paymentRepository) GetPaymentOrderMeta(ctx context.Context, orderID string) (string, int64, int64, int64, string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", 0, 0, 0, "", err
+	}
+	organizationID := t.OrganizationID()
 	createdByExpr := "COALESCE(co.customer_id::text, '') as customer_id"
 	query := fmt.Sprintf(`
 		SELECT
@@ -221,27 +330,31 @@ func (r *paymentRepository) GetPaymentOrderMeta(orderID string, organizationID s
 			COALESCE(po.payment_method, 0),
 			%s
 		FROM payment_orders po
-		INNER JOIN customer_orders co ON co.order_id = po.order_id
+		INNER JOIN customer_orders co ON co.order_id = po.order_id AND co.organization_id = po.organization_id
 		WHERE po.order_id = %s AND po.organization_id::text = %s
 		ORDER BY po.created_at DESC
 		LIMIT 1
 	`, createdByExpr, r.getPlaceholder(1), r.getPlaceholder(2))
-	fmt.Println("[DEBUG] GetPaymentOrderMeta - query:", query)
-	fmt.Println(orderID, organizationID)
+	slog.DebugContext(ctx, "get payment order meta", "query", query, "order_id", orderID, "organization_id", organizationID)
 
 	var invoiceNumber string
 	var orderType int64
 	var paymentType int64
 	var paymentMethod int64
 	var createdBy string
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&invoiceNumber, &orderType, &paymentType, &paymentMethod, &createdBy); err != nil {
-		fmt.Println("[DEBUG] GetPaymentOrderMeta - err:", err)
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&invoiceNumber, &orderType, &paymentType, &paymentMethod, &createdBy); err != nil {
+		slog.DebugContext(ctx, "get payment order meta failed", "error", err)
 		return "", 0, 0, 0, "", err
 	}
 	return invoiceNumber, orderType, paymentType, paymentMethod, createdBy, nil
 }
 
-func (r *paymentRepository) GetLatestPaymentOrderRemainingAmount(invoiceNumber string, organizationID string, orderType int64) (sql.NullFloat64, error) {
+func (r *paymentRepository) GetLatestPaymentOrderRemainingAmount(ctx context.Context, invoiceNumber string, orderType int64) (sql.NullFloat64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return sql.NullFloat64{}, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id::text = " + r.getPlaceholder(3)
 
 	query := fmt.Sprintf(`
@@ -253,13 +366,18 @@ func (r *paymentRepository) GetLatestPaymentOrderRemainingAmount(invoiceNumber s
 	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
 
 	var remaining sql.NullFloat64
-	if err := database.QueryRow(r.db, query, invoiceNumber, orderType, organizationID).Scan(&remaining); err != nil {
+	if err := t.QueryRow(query, invoiceNumber, orderType, organizationID).Scan(&remaining); err != nil {
 		return sql.NullFloat64{}, err
 	}
 	return remaining, nil
 }
 
-func (r *paymentRepository) GetFleetOrderEmailData(orderID string, organizationID string) (string, string, string, string, time.Time, time.Time, string, error) {
+func (r *paymentRepository) GetFleetOrderEmailData(ctx context.Context, orderID string) (string, string, string, string, time.Time, time.Time, string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", "", "", "", time.Time{}, time.Time{}, "", err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "fo.organization_id = " + r.getPlaceholder(2)
 	destExpr := "''"
 	d := strings.ToLower(r.driver)
@@ -280,9 +398,9 @@ func (r *paymentRepository) GetFleetOrderEmailData(orderID string, organizationI
 			fo.end_date,
 			%s
 		FROM fleet_orders fo
-		INNER JOIN fleets f ON fo.fleet_id = f.uuid
-		INNER JOIN customer_orders co ON co.order_id = fo.order_id
-		INNER JOIN customers c ON c.customer_id = co.customer_id
+		INNER JOIN fleets f ON fo.fleet_id = f.uuid AND f.organization_id = fo.organization_id
+		INNER JOIN customer_orders co ON co.order_id = fo.order_id AND co.organization_id = fo.organization_id
+		INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = co.organization_id
 		LEFT JOIN fleet_order_destinations d ON d.order_id = fo.order_id
 		WHERE fo.order_id = %s AND %s
 		GROUP BY c.customer_name, c.customer_email, f.fleet_name, fo.pickup_location, fo.start_date, fo.end_date
@@ -291,13 +409,18 @@ func (r *paymentRepository) GetFleetOrderEmailData(orderID string, organizationI
 
 	var customerName, customerEmail, fleetName, pickupLocation, destination string
 	var startDate, endDate time.Time
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&customerName, &customerEmail, &fleetName, &pickupLocation, &startDate, &endDate, &destination); err != nil {
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&customerName, &customerEmail, &fleetName, &pickupLocation, &startDate, &endDate, &destination); err != nil {
 		return "", "", "", "", time.Time{}, time.Time{}, "", err
 	}
 	return customerName, customerEmail, fleetName, pickupLocation, startDate, endDate, destination, nil
 }
 
-func (r *paymentRepository) TransactionExistsByInvoice(organizationID string, invoiceNumber string) (bool, error) {
+func (r *paymentRepository) TransactionExistsByInvoice(ctx context.Context, invoiceNumber string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT 1
 		FROM transactions
@@ -306,7 +429,7 @@ func (r *paymentRepository) TransactionExistsByInvoice(organizationID string, in
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	var one int
-	err := database.QueryRow(r.db, query, organizationID, invoiceNumber).Scan(&one)
+	err = t.QueryRow(query, organizationID, invoiceNumber).Scan(&one)
 	if err == nil {
 		return true, nil
 	}
@@ -316,7 +439,12 @@ func (r *paymentRepository) TransactionExistsByInvoice(organizationID string, in
 	return false, err
 }
 
-func (r *paymentRepository) InsertTransactionMidtrans(transactionID string, orderType int64, invoiceNumber string, description string, transactionDate time.Time, paymentType int64, paymentMethod int64, amount float64, organizationID string, transactionCategory string, createdAt time.Time, createdBy string, orderID string) error {
+func (r *paymentRepository) InsertTransactionMidtrans(ctx context.Context, transactionID string, orderType int64, invoiceNumber string, description string, transactionDate time.Time, paymentType int64, paymentMethod int64, amount float64, transactionCategory string, createdAt time.Time, createdBy string, orderID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		INSERT INTO transactions
 			(transaction_id, order_type, transaction_type, invoice_number, description, transaction_date, payment_type, amount, organization_id, transaction_category, created_at, created_by, status, reference_id, payment_method)
@@ -328,8 +456,7 @@ func (r *paymentRepository) InsertTransactionMidtrans(transactionID string, orde
 	orgID := sql.NullString{String: organizationID, Valid: strings.TrimSpace(organizationID) != ""}
 	cb := sql.NullString{String: createdBy, Valid: strings.TrimSpace(createdBy) != ""}
 
-	_, err := database.Exec(
-		r.db,
+	_, err = t.Exec(
 		query,
 		transactionID,
 		orderType,
@@ -349,12 +476,21 @@ func (r *paymentRepository) InsertTransactionMidtrans(transactionID string, orde
 }
 
 // GetNextInvoiceNumber generates the next invoice number using utils
-func (r *paymentRepository) GetNextInvoiceNumber(organizationID string, orderType int) (string, error) {
-	return utils.GenerateInvoiceNumber(r.db, r.driver, organizationID, orderType, time.Now())
+func (r *paymentRepository) GetNextInvoiceNumber(ctx context.Context, orderType int) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	return utils.GenerateTenantInvoiceNumber(t, r.driver, orderType, time.Now())
 }
 
 // GetOrderTotalAmount mengambil total_amount dari tabel order yang sesuai
-func (r *paymentRepository) GetOrderTotalAmount(orderID string, orderType int64) (int64, error) {
+func (r *paymentRepository) GetOrderTotalAmount(ctx context.Context, orderID string, orderType int64) (int64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	var table string
 	if orderType == 1 {
 		table = "fleet_orders"
@@ -364,9 +500,9 @@ func (r *paymentRepository) GetOrderTotalAmount(orderID string, orderType int64)
 		return 0, fmt.Errorf("invalid order type: %d", orderType)
 	}
 
-	query := fmt.Sprintf("SELECT total_amount FROM %s WHERE order_id = %s LIMIT 1", table, r.getPlaceholder(1))
+	query := fmt.Sprintf("SELECT total_amount FROM %s WHERE order_id = %s AND organization_id = %s LIMIT 1", table, r.getPlaceholder(1), r.getPlaceholder(2))
 	var totalAmount int64
-	err := database.QueryRow(r.db, query, orderID).Scan(&totalAmount)
+	err = t.QueryRow(query, orderID, organizationID).Scan(&totalAmount)
 	if err != nil {
 		return 0, err
 	}
@@ -374,7 +510,12 @@ func (r *paymentRepository) GetOrderTotalAmount(orderID string, orderType int64)
 	return totalAmount, nil
 }
 
-func (r *paymentRepository) GetOrderTotalPaidAmount(orderID string, orderType int64, organizationID string) (float64, error) {
+func (r *paymentRepository) GetOrderTotalPaidAmount(ctx context.Context, orderID string, orderType int64) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(3)
 	d := strings.ToLower(r.driver)
 	if d == "postgres" || d == "pgx" || d == "pq" {
@@ -387,7 +528,7 @@ func (r *paymentRepository) GetOrderTotalPaidAmount(orderID string, orderType in
 	`, r.getPlaceholder(1), r.getPlaceholder(2), orgExpr)
 
 	var totalPaid float64
-	if err := database.QueryRow(r.db, query, orderID, orderType, organizationID).Scan(&totalPaid); err != nil {
+	if err := t.QueryRow(query, orderID, orderType, organizationID).Scan(&totalPaid); err != nil {
 		if err == sql.ErrNoRows {
 			return 0, nil
 		}
@@ -397,7 +538,12 @@ func (r *paymentRepository) GetOrderTotalPaidAmount(orderID string, orderType in
 }
 
 // UpdatePaymentStatus mengupdate kolom payment_status di tabel order yang sesuai
-func (r *paymentRepository) UpdatePaymentStatus(orderID string, orderType int64, status int) error {
+func (r *paymentRepository) UpdatePaymentStatus(ctx context.Context, orderID string, orderType int64, status int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	var table string
 	if orderType == 1 {
 		table = "fleet_orders"
@@ -407,13 +553,18 @@ func (r *paymentRepository) UpdatePaymentStatus(orderID string, orderType int64,
 		return fmt.Errorf("invalid order type: %d", orderType)
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET payment_status = %s, status = %s WHERE order_id = %s", table, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	_, err := database.Exec(r.db, query, status, status, orderID)
+	query := fmt.Sprintf("UPDATE %s SET payment_status = %s, status = %s WHERE order_id = %s AND organization_id = %s", table, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+	_, err = t.Exec(query, status, status, orderID, organizationID)
 	return err
 }
 
 // UpdateOrderStatus updates kolom status di tabel order yang sesuai
-func (r *paymentRepository) UpdateOrderStatus(orderID string, orderType int64, status int, paymentStatus int) error {
+func (r *paymentRepository) UpdateOrderStatus(ctx context.Context, orderID string, orderType int64, status int, paymentStatus int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	var table string
 	if orderType == 1 {
 		table = "fleet_orders"
@@ -423,12 +574,17 @@ func (r *paymentRepository) UpdateOrderStatus(orderID string, orderType int64, s
 		return fmt.Errorf("invalid order type: %d", orderType)
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET status = %s, payment_status = %s, updated_at = now() WHERE order_id = %s", table, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	_, err := database.Exec(r.db, query, status, paymentStatus, orderID)
+	query := fmt.Sprintf("UPDATE %s SET status = %s, payment_status = %s, updated_at = now() WHERE order_id = %s AND organization_id = %s", table, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+	_, err = t.Exec(query, status, paymentStatus, orderID, organizationID)
 	return err
 }
 
-func (r *paymentRepository) UpdateOrderPaymentStatus(orderID string, orderType int64, paymentStatus int) error {
+func (r *paymentRepository) UpdateOrderPaymentStatus(ctx context.Context, orderID string, orderType int64, paymentStatus int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	var table string
 	switch orderType {
 	case 1:
@@ -439,8 +595,8 @@ func (r *paymentRepository) UpdateOrderPaymentStatus(orderID string, orderType i
 		return fmt.Errorf("invalid order type: %d", orderType)
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET payment_status = %s, status = 1, updated_at = now() WHERE order_id = %s", table, r.getPlaceholder(1), r.getPlaceholder(2))
-	_, err := database.Exec(r.db, query, paymentStatus, orderID)
+	query := fmt.Sprintf("UPDATE %s SET payment_status = %s, status = 1, updated_at = now() WHERE order_id = %s AND organization_id = %s", table, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	_, err = t.Exec(query, paymentStatus, orderID, organizationID)
 	return err
 }
 
@@ -452,27 +608,53 @@ func (r *paymentRepository) getPlaceholder(n int) string {
 	return "?"
 }
 
-func (r *paymentRepository) GetSubscriptionDetail(invoiceNumber string) (transactionID string, packageID string, startDate time.Time, expiryDate time.Time, userID string, organizationID string, paymentMethod sql.NullString, createdAt time.Time, paymentAmount sql.NullFloat64, err error) {
-	query := fmt.Sprintf("SELECT transaction_id, package_id, start_date, expiry_date, user_id, organization_id, payment_method, created_at, payment_amount FROM travego_transactions WHERE invoice_number = %s LIMIT 1", r.getPlaceholder(1))
+// GetSubscriptionOrganization returns the organization a subscription invoice
+// was issued to, of any organization: a gateway notification tells the
+// organization only through its invoice number
+func (r *paymentRepository) GetSubscriptionOrganization(ctx context.Context, invoiceNumber string) (string, error) {
+	query := fmt.Sprintf("SELECT organization_id FROM travego_transactions WHERE invoice_number = %s LIMIT 1", r.getPlaceholder(1))
+	var orgID sql.NullString
+	if err := database.QueryRowContext(ctx, r.db, query, invoiceNumber).Scan(&orgID); err != nil {
+		return "", err
+	}
+	return orgID.String, nil
+}
+
+func (r *paymentRepository) GetSubscriptionDetail(ctx context.Context, invoiceNumber string) (transactionID string, packageID string, startDate time.Time, expiryDate time.Time, userID string, organizationID string, paymentMethod sql.NullString, createdAt time.Time, paymentAmount sql.NullFloat64, err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", "", time.Time{}, time.Time{}, "", "", sql.NullString{}, time.Time{}, sql.NullFloat64{}, err
+	}
+	query := fmt.Sprintf("SELECT transaction_id, package_id, start_date, expiry_date, user_id, organization_id, payment_method, created_at, payment_amount FROM travego_transactions WHERE invoice_number = %s AND organization_id = %s LIMIT 1", r.getPlaceholder(1), r.getPlaceholder(2))
 	var tID, pID, uID, oID sql.NullString
 	var sDate, eDate, cDate sql.NullTime
-	err = database.QueryRow(r.db, query, invoiceNumber).Scan(&tID, &pID, &sDate, &eDate, &uID, &oID, &paymentMethod, &cDate, &paymentAmount)
+	err = t.QueryRow(query, invoiceNumber, t.OrganizationID()).Scan(&tID, &pID, &sDate, &eDate, &uID, &oID, &paymentMethod, &cDate, &paymentAmount)
 	if err != nil {
 		return "", "", time.Time{}, time.Time{}, "", "", sql.NullString{}, time.Time{}, sql.NullFloat64{}, err
 	}
 	return tID.String, pID.String, sDate.Time, eDate.Time, uID.String, oID.String, paymentMethod, cDate.Time, paymentAmount, nil
 }
 
-func (r *paymentRepository) UpdateTravegoTransactionStatus(invoiceNumber string, paymentMethod string, grossAmount float64) error {
-	query := fmt.Sprintf("UPDATE travego_transactions SET status = 1, payment_method = %s, payment_amount = %s, updated_at = NOW() WHERE status = 2 AND invoice_number = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
-	_, err := database.Exec(r.db, query, paymentMethod, grossAmount, invoiceNumber)
+func (r *paymentRepository) UpdateTravegoTransactionStatus(ctx context.Context, invoiceNumber string, paymentMethod string, grossAmount float64) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf("UPDATE travego_transactions SET status = 1, payment_method = %s, payment_amount = %s, updated_at = NOW() WHERE status = 2 AND invoice_number = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+	_, err = t.Exec(query, paymentMethod, grossAmount, invoiceNumber, organizationID)
 	return err
 }
 
-func (r *paymentRepository) GetSubscriptionByOrganization(organizationID string) (exists bool, err error) {
+func (r *paymentRepository) GetSubscriptionByOrganization(ctx context.Context) (exists bool, err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT 1 FROM _subscription WHERE organization_id = %s ORDER BY created_at DESC LIMIT 1", r.getPlaceholder(1))
 	var one int
-	err = database.QueryRow(r.db, query, organizationID).Scan(&one)
+	err = t.QueryRow(query, organizationID).Scan(&one)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return false, nil
@@ -482,14 +664,46 @@ func (r *paymentRepository) GetSubscriptionByOrganization(organizationID string)
 	return true, nil
 }
 
-func (r *paymentRepository) UpdateSubscription(organizationID string, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64) error {
-	query := fmt.Sprintf("UPDATE _subscription SET package_id = %s, activate_date = %s, expiry_date = %s, updated_at = NOW(), package_price = %s WHERE organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
-	_, err := database.Exec(r.db, query, packageID, activateDate, expiryDate, packagePrice, organizationID)
+func (r *paymentRepository) UpdateSubscription(ctx context.Context, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf("UPDATE _subscription SET package_id = %s, activate_date = %s, expiry_date = %s, updated_at = NOW(), package_price = %s, status = 1, grace_until = NULL, reminder_days = NULL, scheduled_package_id = NULL, scheduled_expiry_date = NULL, scheduled_package_price = NULL WHERE organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+	_, err = t.Exec(query, packageID, activateDate, expiryDate, packagePrice, organizationID)
 	return err
 }
 
-func (r *paymentRepository) InsertSubscription(subscriptionID string, organizationID string, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64, createdAt time.Time) error {
+// GetSubscriptionLifecycle returns the organization's subscription; nil when it has none
+func (r *paymentRepository) GetSubscriptionLifecycle(ctx context.Context) (*model.SubscriptionLifecycle, error) {
+	return r.subscriptions.GetLifecycle(ctx)
+}
+
+// InsertSubscriptionEvent adds an entry to the organization's subscription history
+func (r *paymentRepository) InsertSubscriptionEvent(ctx context.Context, event *model.SubscriptionEvent) error {
+	return r.subscriptions.InsertEvent(ctx, event)
+}
+
+// ScheduleSubscription keeps a paid downgrade until the current period ends
+func (r *paymentRepository) ScheduleSubscription(ctx context.Context, packageID string, expiryDate time.Time, packagePrice float64) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	query := fmt.Sprintf("UPDATE _subscription SET scheduled_package_id = %s, scheduled_expiry_date = %s, scheduled_package_price = %s, updated_at = NOW() WHERE organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+	_, err = t.Exec(query, packageID, expiryDate, packagePrice, organizationID)
+	return err
+}
+
+func (r *paymentRepository) InsertSubscription(ctx context.Context, subscriptionID string, packageID string, activateDate time.Time, expiryDate time.Time, packagePrice float64, createdAt time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	query := fmt.Sprintf("INSERT INTO _subscription (subscription_id, organization_id, package_id, activate_date, expiry_date, subscription_type, created_at, package_price, status) VALUES (%s, %s, %s, %s, %s, 1, %s, %s, 1)", r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7))
-	_, err := database.Exec(r.db, query, subscriptionID, organizationID, packageID, activateDate, expiryDate, createdAt, packagePrice)
+	_, err = t.Exec(query, subscriptionID, organizationID, packageID, activateDate, expiryDate, createdAt, packagePrice)
 	return err
 }
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateSubscriptionDropsAScheduledDowngrade(t *testing.T) {
+	r := NewPaymentRepository(openTenantFake(t), "postgres")
+	ctx := database.WithOrganization(context.Background(), tenantB)
+
+	// a downgrade is paid for, then the current package is renewed before
+	// the period ends; the renewal replaces the downgrade
+	now := time.Now()
+	if err := r.ScheduleSubscription(ctx, "basic", now.AddDate(0, 2, 0), 100000); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.UpdateSubscription(ctx, "pro", now, now.AddDate(0, 1, 0), 250000); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := fakeTenantDB.take()
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(statements))
+	}
+	update := statements[1].query
+	for _, column := range []string{"scheduled_package_id", "scheduled_expiry_date", "scheduled_package_price"} {
+		if !strings.Contains(update, column+" = NULL") {
+			t.Errorf("renewal keeps %s: %s", column, update)
+		}
+	}
+}
+
+func TestPaymentRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPaymentRepository(openTenantFake(t), "postgres")
+	const invoiceOfB = "INV-OF-B"
+	now := time.Now()
+	checkTenantIsolation(t, tenantCalls{
+		"GetOrderTotalAmount": func(ctx context.Context) error {
+			_, err := r.GetOrderTotalAmount(ctx, orderOfB, 1)
+			return err
+		},
+		"GetOrderTotalPaidAmount": func(ctx context.Context) error {
+			_, err := r.GetOrderTotalPaidAmount(ctx, orderOfB, 1)
+			return err
+		},
+		"UpdatePaymentStatus": func(ctx context.Context) error { return r.UpdatePaymentStatus(ctx, orderOfB, 2, 3) },
+		"UpdateOrderStatus":   func(ctx context.Context) error { return r.UpdateOrderStatus(ctx, orderOfB, 1, 2, 1) },
+		"UpdateOrderPaymentStatus": func(ctx context.Context) error {
+			return r.UpdateOrderPaymentStatus(ctx, orderOfB, 1, 1)
+		},
+		"UpdatePaymentOrderNotification": func(ctx context.Context) error {
+			return r.UpdatePaymentOrderNotification(ctx, invoiceOfB, 100000, 100000, paymentOfB, "Midtrans - qris")
+		},
+		"MarkOrderInstallmentPaid": func(ctx context.Context) error {
+			return r.MarkOrderInstallmentPaid(ctx, invoiceOfB, now)
+		},
+		"InsertPaymentOrder": func(ctx context.Context) error {
+			return r.InsertPaymentOrder(ctx, "payment-1", 1, orderOfB, 1, 1004, invoiceOfB, "midtrans", now.Format("2006-01-02 15:04:05"), "")
+		},
+		"GetPaymentOrderMeta": func(ctx context.Context) error {
+			_, _, _, _, _, err := r.GetPaymentOrderMeta(ctx, orderOfB)
+			return err
+		},
+		"GetLatestPaymentOrderRemainingAmount": func(ctx context.Context) error {
+			_, err := r.GetLatestPaymentOrderRemainingAmount(ctx, invoiceOfB, 1)
+			return err
+		},
+		"GetFleetOrderEmailData": func(ctx context.Context) error {
+			_, _, _, _, _, _, _, err := r.GetFleetOrderEmailData(ctx, orderOfB)
+			return err
+		},
+		"TransactionExistsByInvoice": func(ctx context.Context) error {
+			_, err := r.TransactionExistsByInvoice(ctx, invoiceOfB)
+			return err
+		},
+		"InsertTransactionMidtrans": func(ctx context.Context) error {
+			return r.InsertTransactionMidtrans(ctx, paymentOfB, 1, invoiceOfB, "Midtrans", now, 1, 1004, 100000, "TRX01", now, "", orderOfB)
+		},
+		"GetNextInvoiceNumber": func(ctx context.Context) error {
+			_, err := r.GetNextInvoiceNumber(ctx, 1)
+			return err
+		},
+		"GetSubscriptionDetail": func(ctx context.Context) error {
+			_, _, _, _, _, _, _, _, _, err := r.GetSubscriptionDetail(ctx, "TRV-OF-B")
+			return err
+		},
+		"UpdateTravegoTransactionStatus": func(ctx context.Context) error {
+			return r.UpdateTravegoTransactionStatus(ctx, "TRV-OF-B", "qris", 250000)
+		},
+		"GetSubscriptionByOrganization": func(ctx context.Context) error {
+			_, err := r.GetSubscriptionByOrganization(ctx)
+			return err
+		},
+		"GetSubscriptionLifecycle": func(ctx context.Context) error {
+			_, err := r.GetSubscriptionLifecycle(ctx)
+			return err
+		},
+		"UpdateSubscription": func(ctx context.Context) error {
+			return r.UpdateSubscription(ctx, "pro", now, now.AddDate(0, 1, 0), 250000)
+		},
+		"InsertSubscription": func(ctx context.Context) error {
+			return r.InsertSubscription(ctx, "subscription-1", "pro", now, now.AddDate(0, 1, 0), 250000, now)
+		},
+		"ScheduleSubscription": func(ctx context.Context) error {
+			return r.ScheduleSubscription(ctx, "basic", now.AddDate(0, 2, 0), 100000)
+		},
+		"ExpireTravegoTransaction": func(ctx context.Context) error {
+			return r.ExpireTravegoTransaction(ctx, "TRV-OF-B")
+		},
+		"FailGatewayPaymentOrder": func(ctx context.Context) error {
+			return r.FailGatewayPaymentOrder(ctx, invoiceOfB)
+		},
+		"ListMidtransSettledPayments": func(ctx context.Context) error {
+			_, err := r.ListMidtransSettledPayments(ctx, orderOfB)
+			return err
+		},
+		"UpdateRefundMidtransStatus": func(ctx context.Context) error {
+			return r.UpdateRefundMidtransStatus(ctx, "refund-of-b", model.MidtransRefundManual, 0, "")
+		},
+		"SyncRefundMidtransStatus": func(ctx context.Context) error {
+			return r.SyncRefundMidtransStatus(ctx, "refund-of-b")
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"InsertSubscriptionEvent": func() error {
+			return r.InsertSubscriptionEvent(ctxA, &model.SubscriptionEvent{OrganizationID: tenantB, Event: model.SubscriptionEventReadOnly})
+		},
+		"SaveMidtransRefund": func() error {
+			return r.SaveMidtransRefund(ctxA, &model.MidtransRefund{
+				RefundKey:      "refund-of-b-1",
+				RefundID:       "refund-of-b",
+				OrganizationID: tenantB,
+				OrderID:        orderOfB,
+				InvoiceNumber:  invoiceOfB,
+				Amount:         50000,
+				Status:         model.MidtransRefundRefunded,
+			})
+		},
+	})
+}
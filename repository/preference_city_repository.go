@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"service-travego/database"
 	"service-travego/model"
 	"time"
@@ -29,7 +31,13 @@ func (r *PreferenceCityRepository) getPlaceholder(pos int) string {
 	return fmt.Sprintf("$%d", pos)
 }
 
-func (r *PreferenceCityRepository) Create(cityID int, minimalDay int, organizationID string, createdBy string, serviceTypes []int) error {
+func (r *PreferenceCityRepository) Create(ctx context.Context, cityID int, minimalDay int, createdBy string, serviceTypes []int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+
 	var existingPreferenceID string
 	checkQuery := fmt.Sprintf(`
 		SELECT preference_id
@@ -39,7 +47,7 @@ func (r *PreferenceCityRepository) Create(cityID int, minimalDay int, organizati
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	err := database.QueryRow(r.db, checkQuery, cityID, organizationID).Scan(&existingPreferenceID)
+	err = t.QueryRow(checkQuery, cityID, organizationID).Scan(&existingPreferenceID)
 	if err == nil {
 		return fmt.Errorf("preference city with city_id %d already exists for this organization", cityID)
 	}
@@ -56,7 +64,7 @@ func (r *PreferenceCityRepository) Create(cityID int, minimalDay int, organizati
 		r.getPlaceholder(5),
 		r.getPlaceholder(6),
 	)
-	_, err = database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		uuid.New().String(),
 		cityID,
 		minimalDay,
@@ -69,13 +77,13 @@ func (r *PreferenceCityRepository) Create(cityID int, minimalDay int, organizati
 	}
 
 	if len(serviceTypes) > 0 {
-		return r.CreateTypes(cityID, organizationID, serviceTypes)
+		return r.createTypes(ctx, t, cityID, serviceTypes)
 	}
 
 	return nil
 }
 
-func (r *PreferenceCityRepository) CreateTypes(cityID int, organizationID string, serviceTypes []int) error {
+func (r *PreferenceCityRepository) createTypes(ctx context.Context, t *database.Tenant, cityID int, serviceTypes []int) error {
 	for _, st := range serviceTypes {
 		query := fmt.Sprintf(`
 			INSERT INTO preference_city_types (preference_type_id, city_id, service_type, organization_id)
@@ -86,16 +94,22 @@ func (r *PreferenceCityRepository) CreateTypes(cityID int, organizationID string
 			r.getPlaceholder(3),
 			r.getPlaceholder(4),
 		)
-		_, err := database.Exec(r.db, query, uuid.New().String(), cityID, st, organizationID)
+		_, err := t.Exec(query, uuid.New().String(), cityID, st, t.OrganizationID())
 		if err != nil {
-			fmt.Println("Error insert into preference_city_types ", err)
+			slog.ErrorContext(ctx, "error insert into preference_city_types", "error", err)
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *PreferenceCityRepository) Update(preferenceID string, cityID int, minimalDay int, organizationID string, serviceTypeIDs []int) error {
+func (r *PreferenceCityRepository) Update(ctx context.Context, preferenceID string, cityID int, minimalDay int, serviceTypeIDs []int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+
 	var oldCityID int
 	query := fmt.Sprintf(`
 		SELECT city_id
@@ -105,7 +119,7 @@ func (r *PreferenceCityRepository) Update(preferenceID string, cityID int, minim
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	err := database.QueryRow(r.db, query, preferenceID, organizationID).Scan(&oldCityID)
+	err = t.QueryRow(query, preferenceID, organizationID).Scan(&oldCityID)
 	if err != nil {
 		return fmt.Errorf("failed to find existing preference city: %w", err)
 	}
@@ -120,7 +134,7 @@ func (r *PreferenceCityRepository) Update(preferenceID string, cityID int, minim
 		r.getPlaceholder(3),
 		r.getPlaceholder(4),
 	)
-	_, err = database.Exec(r.db, query,
+	_, err = t.Exec(query,
 		cityID,
 		minimalDay,
 		preferenceID,
@@ -130,20 +144,26 @@ func (r *PreferenceCityRepository) Update(preferenceID string, cityID int, minim
 		return err
 	}
 
-	_ = r.DeleteTypesByCityIDAndOrganizationID(oldCityID, organizationID)
+	_ = r.deleteTypes(t, oldCityID)
 
 	if oldCityID != cityID {
-		_ = r.DeleteTypesByCityIDAndOrganizationID(cityID, organizationID)
+		_ = r.deleteTypes(t, cityID)
 	}
 
 	if len(serviceTypeIDs) > 0 {
-		return r.CreateTypes(cityID, organizationID, serviceTypeIDs)
+		return r.createTypes(ctx, t, cityID, serviceTypeIDs)
 	}
 
 	return nil
 }
 
-func (r *PreferenceCityRepository) Delete(preferenceID, organizationID string) error {
+func (r *PreferenceCityRepository) Delete(ctx context.Context, preferenceID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+
 	query := fmt.Sprintf(`
 		SELECT city_id
 		FROM preference_cities
@@ -153,9 +173,9 @@ func (r *PreferenceCityRepository) Delete(preferenceID, organizationID string) e
 		r.getPlaceholder(2),
 	)
 	var cityID int
-	err := database.QueryRow(r.db, query, preferenceID, organizationID).Scan(&cityID)
+	err = t.QueryRow(query, preferenceID, organizationID).Scan(&cityID)
 	if err == nil {
-		_ = r.DeleteTypesByCityIDAndOrganizationID(cityID, organizationID)
+		_ = r.deleteTypes(t, cityID)
 	}
 
 	query = fmt.Sprintf(`
@@ -165,11 +185,11 @@ func (r *PreferenceCityRepository) Delete(preferenceID, organizationID string) e
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	_, err = database.Exec(r.db, query, preferenceID, organizationID)
+	_, err = t.Exec(query, preferenceID, organizationID)
 	return err
 }
 
-func (r *PreferenceCityRepository) DeleteTypesByCityIDAndOrganizationID(cityID int, organizationID string) error {
+func (r *PreferenceCityRepository) deleteTypes(t *database.Tenant, cityID int) error {
 	query := fmt.Sprintf(`
 		DELETE FROM preference_city_types
 		WHERE city_id = %s AND organization_id = %s
@@ -177,12 +197,17 @@ func (r *PreferenceCityRepository) DeleteTypesByCityIDAndOrganizationID(cityID i
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	_, err := database.Exec(r.db, query, cityID, organizationID)
+	_, err := t.Exec(query, cityID, t.OrganizationID())
 	return err
 }
 
-func (r *PreferenceCityRepository) DeleteByCityAndServiceType(cityID int, serviceType int, organizationID string) error {
-	_ = r.DeleteTypesByCityIDAndOrganizationID(cityID, organizationID)
+func (r *PreferenceCityRepository) DeleteByCityAndServiceType(ctx context.Context, cityID int, serviceType int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
+	_ = r.deleteTypes(t, cityID)
 
 	query := fmt.Sprintf(`
 		DELETE FROM preference_cities
@@ -191,11 +216,16 @@ func (r *PreferenceCityRepository) DeleteByCityAndServiceType(cityID int, servic
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	_, err := database.Exec(r.db, query, cityID, organizationID)
+	_, err = t.Exec(query, cityID, t.OrganizationID())
 	return err
 }
 
-func (r *PreferenceCityRepository) GetAll(organizationID string, cityID *int) ([]model.PreferenceCity, error) {
+func (r *PreferenceCityRepository) GetAll(ctx context.Context, cityID *int) ([]model.PreferenceCity, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT preference_id, city_id, minimal_day, organization_id, created_at, created_by
 		FROM preference_cities
@@ -203,7 +233,7 @@ func (r *PreferenceCityRepository) GetAll(organizationID string, cityID *int) ([
 	`,
 		r.getPlaceholder(1),
 	)
-	args := []interface{}{organizationID}
+	args := []interface{}{t.OrganizationID()}
 	argPos := 2
 
 	if cityID != nil {
@@ -212,7 +242,7 @@ func (r *PreferenceCityRepository) GetAll(organizationID string, cityID *int) ([
 		argPos++
 	}
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +271,12 @@ func (r *PreferenceCityRepository) GetAll(organizationID string, cityID *int) ([
 	return prefs, nil
 }
 
-func (r *PreferenceCityRepository) GetTypesByCityIDAndOrganizationID(cityID int, organizationID string) ([]int, error) {
+func (r *PreferenceCityRepository) GetTypesByCityID(ctx context.Context, cityID int) ([]int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(`
 		SELECT service_type
 		FROM preference_city_types
@@ -250,7 +285,7 @@ func (r *PreferenceCityRepository) GetTypesByCityIDAndOrganizationID(cityID int,
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	rows, err := database.Query(r.db, query, cityID, organizationID)
+	rows, err := t.Query(query, cityID, t.OrganizationID())
 	if err != nil {
 		return []int{}, nil
 	}
@@ -258,11 +293,11 @@ func (r *PreferenceCityRepository) GetTypesByCityIDAndOrganizationID(cityID int,
 
 	var types []int
 	for rows.Next() {
-		var t int
-		if err := rows.Scan(&t); err != nil {
+		var serviceType int
+		if err := rows.Scan(&serviceType); err != nil {
 			return []int{}, nil
 		}
-		types = append(types, t)
+		types = append(types, serviceType)
 	}
 	if err := rows.Err(); err != nil {
 		return []int{}, nil
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPreferenceCityRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPreferenceCityRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"Create": func(ctx context.Context) error { return r.Create(ctx, 3171, 1, "", []int{1}) },
+		"Update": func(ctx context.Context) error { return r.Update(ctx, prefOfB, 3171, 2, []int{1}) },
+		"Delete": func(ctx context.Context) error { return r.Delete(ctx, prefOfB) },
+		"DeleteByCityAndServiceType": func(ctx context.Context) error {
+			return r.DeleteByCityAndServiceType(ctx, 3171, 1)
+		},
+		"GetAll":           func(ctx context.Context) error { _, err := r.GetAll(ctx, nil); return err },
+		"GetTypesByCityID": func(ctx context.Context) error { _, err := r.GetTypesByCityID(ctx, 3171); return err },
+	})
+}
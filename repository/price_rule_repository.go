@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceRuleRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewPriceRuleRepository(db *sql.DB, driver string) *PriceRuleRepository {
+	return &PriceRuleRepository{db: db, driver: driver}
+}
+
+func (r *PriceRuleRepository) placeholder(pos int) string {
+	if r.driver == "postgres" || r.driver == "pgx" {
+		return "$" + strconv.Itoa(pos)
+	}
+	return "?"
+}
+
+const selectPriceRule = `
+SELECT
+	rule_id,
+	organization_id,
+	COALESCE(name, '') AS name,
+	rule_type,
+	adjustment_type,
+	COALESCE(value, 0) AS value,
+	start_date,
+	end_date,
+	COALESCE(min_lead_days, 0) AS min_lead_days,
+	COALESCE(city_ids, '') AS city_ids,
+	COALESCE(CAST(fleet_id AS CHAR(36)), '') AS fleet_id,
+	COALESCE(rent_type, 0) AS rent_type,
+	COALESCE(priority, 0) AS priority,
+	COALESCE(status, 0) AS status,
+	created_at
+FROM price_rules
+`
+
+func splitCityIDs(raw string) []string {
+	out := make([]string, 0)
+	for _, id := range strings.Split(raw, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func (r *PriceRuleRepository) scanRules(rows *sql.Rows) ([]model.PriceRule, error) {
+	out := make([]model.PriceRule, 0)
+	for rows.Next() {
+		var it model.PriceRule
+		var startDate, endDate, createdAt sql.NullTime
+		var cityIDs string
+		var status int
+		if err := rows.Scan(
+			&it.RuleID,
+			&it.OrganizationID,
+			&it.Name,
+			&it.RuleType,
+			&it.AdjustmentType,
+			&it.Value,
+			&startDate,
+			&endDate,
+			&it.MinLeadDays,
+			&cityIDs,
+			&it.FleetID,
+			&it.RentType,
+			&it.Priority,
+			&status,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		if startDate.Valid {
+			it.StartDate = startDate.Time.Format("2006-01-02")
+		}
+		if endDate.Valid {
+			it.EndDate = endDate.Time.Format("2006-01-02")
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.CityIDs = splitCityIDs(cityIDs)
+		it.Active = status == 1
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List returns the organization's rules. activeOnly skips paused rules.
+func (r *PriceRuleRepository) List(ctx context.Context, activeOnly bool) ([]model.PriceRule, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectPriceRule + " WHERE organization_id = " + r.placeholder(1)
+	if activeOnly {
+		query += " AND status = 1"
+	} else {
+		query += " AND status IN (1, 2)"
+	}
+	query += " ORDER BY priority DESC, created_at ASC"
+
+	rows, err := t.Query(query, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanRules(rows)
+}
+
+func (r *PriceRuleRepository) GetByID(ctx context.Context, ruleID string) (*model.PriceRule, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectPriceRule + " WHERE organization_id = " + r.placeholder(1) + " AND rule_id = " + r.placeholder(2) + " AND status IN (1, 2)"
+	rows, err := t.Query(query, t.OrganizationID(), ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items, err := r.scanRules(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+func nullableString(s string) interface{} {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return s
+}
+
+func priceRuleStatus(req *model.PriceRuleUpsertRequest) int {
+	if req.Active != nil && !*req.Active {
+		return 2
+	}
+	return 1
+}
+
+func (r *PriceRuleRepository) Create(ctx context.Context, req *model.PriceRuleUpsertRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	ruleID := uuid.New().String()
+	now := time.Now()
+	query := fmt.Sprintf(`
+		INSERT INTO price_rules
+			(rule_id, organization_id, name, rule_type, adjustment_type, value, start_date, end_date, min_lead_days,
+			 city_ids, fleet_id, rent_type, priority, status, created_by, created_at, updated_by, updated_at)
+		VALUES
+			(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12),
+		r.placeholder(13), r.placeholder(14), r.placeholder(15), r.placeholder(16), r.placeholder(17), r.placeholder(18))
+	_, err = t.Exec(query,
+		ruleID,
+		req.OrganizationID,
+		req.Name,
+		req.RuleType,
+		req.AdjustmentType,
+		req.Value,
+		nullableString(req.StartDate),
+		nullableString(req.EndDate),
+		req.MinLeadDays,
+		strings.Join(req.CityIDs, ","),
+		nullableString(req.FleetID),
+		req.RentType,
+		req.Priority,
+		priceRuleStatus(req),
+		req.UserID,
+		now,
+		req.UserID,
+		now,
+	)
+	if err != nil {
+		return "", err
+	}
+	return ruleID, nil
+}
+
+func (r *PriceRuleRepository) Update(ctx context.Context, req *model.PriceRuleUpsertRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE price_rules
+		SET name = %s, rule_type = %s, adjustment_type = %s, value = %s, start_date = %s, end_date = %s, min_lead_days = %s,
+			city_ids = %s, fleet_id = %s, rent_type = %s, priority = %s, status = %s, updated_by = %s, updated_at = %s
+		WHERE rule_id = %s AND organization_id = %s AND status IN (1, 2)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12),
+		r.placeholder(13), r.placeholder(14), r.placeholder(15), r.placeholder(16))
+	result, err := t.Exec(query,
+		req.Name,
+		req.RuleType,
+		req.AdjustmentType,
+		req.Value,
+		nullableString(req.StartDate),
+		nullableString(req.EndDate),
+		req.MinLeadDays,
+		strings.Join(req.CityIDs, ","),
+		nullableString(req.FleetID),
+		req.RentType,
+		req.Priority,
+		priceRuleStatus(req),
+		req.UserID,
+		time.Now(),
+		req.RuleID,
+		req.OrganizationID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PriceRuleRepository) Delete(ctx context.Context, userID, ruleID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE price_rules SET status = 0, updated_by = %s, updated_at = %s
+		WHERE rule_id = %s AND organization_id = %s AND status IN (1, 2)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	result, err := t.Exec(query, userID, time.Now(), ruleID, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListOrderPriceRules returns the rule breakdown stored on an order.
+func (r *PriceRuleRepository) ListOrderPriceRules(ctx context.Context, orderID string) ([]model.PriceRuleLine, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(CAST(rule_id AS CHAR(36)), ''), COALESCE(rule_name, ''), rule_type, adjustment_type,
+			COALESCE(value, 0), COALESCE(unit_amount, 0), COALESCE(quantity, 0), COALESCE(amount, 0)
+		FROM fleet_order_price_rules
+		WHERE organization_id = %s AND order_id = %s
+		ORDER BY created_at ASC
+	`, r.placeholder(1), r.placeholder(2))
+	rows, err := t.Query(query, t.OrganizationID(), orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.PriceRuleLine, 0)
+	for rows.Next() {
+		var it model.PriceRuleLine
+		if err := rows.Scan(&it.RuleID, &it.RuleName, &it.RuleType, &it.AdjustmentType, &it.Value, &it.UnitAmount, &it.Quantity, &it.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// replaceOrderItemPriceRules drops the pricing rule breakdown of an order item
+// and stores the one of its new quote.
+func replaceOrderItemPriceRules(tx *database.TenantTx, placeholder func(int) string, orderID, orderItemID, fleetID, priceID string, ruleAdjustment float64, lines []model.PriceRuleLine) error {
+	orgID := tx.OrganizationID()
+	deleteQuery := fmt.Sprintf(`DELETE FROM fleet_order_price_rules WHERE order_item_id = %s AND organization_id = %s`, placeholder(1), placeholder(2))
+	if _, err := tx.Exec(deleteQuery, orderItemID, orgID); err != nil {
+		return fmt.Errorf("delete order price rules: %w", err)
+	}
+	resetQuery := fmt.Sprintf(`UPDATE fleet_order_items SET rule_adjustment = 0 WHERE order_item_id = %s AND organization_id = %s`, placeholder(1), placeholder(2))
+	if _, err := tx.Exec(resetQuery, orderItemID, orgID); err != nil {
+		return fmt.Errorf("reset order item rule adjustment: %w", err)
+	}
+	return saveOrderItemPriceRules(tx, placeholder, orderID, orderItemID, fleetID, priceID, ruleAdjustment, lines)
+}
+
+// saveOrderItemPriceRules stores the per-unit rule adjustment on an order item
+// and the breakdown of the rules that produced it.
+func saveOrderItemPriceRules(tx *database.TenantTx, placeholder func(int) string, orderID, orderItemID, fleetID, priceID string, ruleAdjustment float64, lines []model.PriceRuleLine) error {
+	if ruleAdjustment == 0 && len(lines) == 0 {
+		return nil
+	}
+	orgID := tx.OrganizationID()
+
+	updateQuery := fmt.Sprintf(`UPDATE fleet_order_items SET rule_adjustment = %s WHERE order_item_id = %s AND organization_id = %s`, placeholder(1), placeholder(2), placeholder(3))
+	if _, err := tx.Exec(updateQuery, ruleAdjustment, orderItemID, orgID); err != nil {
+		return fmt.Errorf("update order item rule adjustment: %w", err)
+	}
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO fleet_order_price_rules
+			(uuid, organization_id, order_id, order_item_id, fleet_id, price_id, rule_id, rule_name, rule_type, adjustment_type,
+			 value, unit_amount, quantity, amount, created_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, placeholder(1), placeholder(2), placeholder(3), placeholder(4), placeholder(5), placeholder(6), placeholder(7),
+		placeholder(8), placeholder(9), placeholder(10), placeholder(11), placeholder(12), placeholder(13), placeholder(14), placeholder(15))
+	now := time.Now()
+	for _, line := range lines {
+		if _, err := tx.Exec(insertQuery,
+			uuid.New().String(),
+			orgID,
+			orderID,
+			orderItemID,
+			fleetID,
+			priceID,
+			line.RuleID,
+			line.RuleName,
+			line.RuleType,
+			line.AdjustmentType,
+			line.Value,
+			line.UnitAmount,
+			line.Quantity,
+			line.Amount,
+			now,
+		); err != nil {
+			return fmt.Errorf("insert order price rule: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+)
+
+func TestPriceRuleRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPriceRuleRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"List":       func(ctx context.Context) error { _, err := r.List(ctx, false); return err },
+		"ListActive": func(ctx context.Context) error { _, err := r.List(ctx, true); return err },
+		"GetByID":    func(ctx context.Context) error { _, err := r.GetByID(ctx, ruleOfB); return err },
+		"Update": func(ctx context.Context) error {
+			organizationID, _ := database.OrganizationFromContext(ctx)
+			return r.Update(ctx, &model.PriceRuleUpsertRequest{OrganizationID: organizationID, RuleID: ruleOfB})
+		},
+		"Delete": func(ctx context.Context) error { return r.Delete(ctx, "", ruleOfB) },
+		"ListOrderPriceRules": func(ctx context.Context) error {
+			_, err := r.ListOrderPriceRules(ctx, orderOfB)
+			return err
+		},
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error {
+			_, err := r.Create(ctxA, &model.PriceRuleUpsertRequest{OrganizationID: tenantB, Name: "lebaran"})
+			return err
+		},
+		"Update": func() error {
+			return r.Update(ctxA, &model.PriceRuleUpsertRequest{OrganizationID: tenantB, RuleID: ruleOfB})
+		},
+	})
+}
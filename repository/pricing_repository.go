@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"os"
+	"service-travego/database"
 	"service-travego/model"
 	"sync"
 	"time"
@@ -60,13 +62,14 @@ func (r *PricingRepository) GetPackages() ([]model.Package, error) {
 	return r.packages, nil
 }
 
-func (r *PricingRepository) GetReviews() ([]model.Review, error) {
+// GetReviews returns the reviews of every organization, for the landing page
+func (r *PricingRepository) GetReviews(ctx context.Context) ([]model.Review, error) {
 	query := `SELECT r.review_id, r.user_id, r.stars, r.review, r.created_at, u.fullname as created_by, o.organization_name 
 	FROM travego_reviews r INNER JOIN users u ON r.user_id = u.user_id
 	INNER JOIN organization_users ou ON ou.user_id = u.user_id
 	INNER JOIN organizations o ON o.organization_id = ou.organization_id
 	ORDER BY r.stars, r.created_at DESC`
-	rows, err := r.db.Query(query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -82,12 +85,12 @@ func (r *PricingRepository) GetReviews() ([]model.Review, error) {
 	return reviews, nil
 }
 
-func (r *PricingRepository) SubmitContact(contact model.ContactSubmission) error {
+func (r *PricingRepository) SubmitContact(ctx context.Context, contact model.ContactSubmission) error {
 	query := fmt.Sprintf(`INSERT INTO travego_messages (topic_id, fullname, company_name, email, whatsapp, scale, messages, created_at, is_read)
     VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)`,
 		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
 
-	_, err := r.db.Exec(query, contact.TopicID, contact.FullName, contact.BusinessName,
+	_, err := database.ExecContext(ctx, r.db, query, contact.TopicID, contact.FullName, contact.BusinessName,
 		contact.Email, contact.Phone, contact.BusinessScale,
 		contact.Message, time.Now(), false)
 
@@ -97,13 +100,18 @@ func (r *PricingRepository) SubmitContact(contact model.ContactSubmission) error
 	return nil
 }
 
-func (r *PricingRepository) GetSubscriptionByOrgID(orgID string) (*model.Subscription, error) {
+// GetSubscription returns the subscription of the request's organization; nil when it has none
+func (r *PricingRepository) GetSubscription(ctx context.Context) (*model.Subscription, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	query := fmt.Sprintf(`SELECT package_id, activate_date, expiry_date FROM _subscription WHERE organization_id = %s`,
 		r.getPlaceholder(1))
-	row := r.db.QueryRow(query, orgID)
+	row := t.QueryRow(query, t.OrganizationID())
 
 	var sub model.Subscription
-	err := row.Scan(&sub.PackageID, &sub.ActivateDate, &sub.ExpiryDate)
+	err = row.Scan(&sub.PackageID, &sub.ActivateDate, &sub.ExpiryDate)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -113,12 +121,12 @@ func (r *PricingRepository) GetSubscriptionByOrgID(orgID string) (*model.Subscri
 	return &sub, nil
 }
 
-func (r *PricingRepository) InsertLog() error {
+func (r *PricingRepository) InsertLog(ctx context.Context) error {
 	query := `INSERT INTO travego_visitors (period, count) 
 	VALUES (CURRENT_DATE, 1) 
 	ON CONFLICT (period) 
 	DO UPDATE SET count = travego_visitors.count + 1;`
-	_, err := r.db.Exec(query)
+	_, err := database.ExecContext(ctx, r.db, query)
 	if err != nil {
 		return fmt.Errorf("gagal insert ke travego_visitors: %w", err)
 	}
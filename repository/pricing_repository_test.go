@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPricingRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPricingRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"GetSubscription": func(ctx context.Context) error { _, err := r.GetSubscription(ctx); return err },
+	})
+}
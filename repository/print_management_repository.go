@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"os"
 	"service-travego/database"
 	"service-travego/utils"
@@ -48,6 +50,7 @@ type PrintFleetOrderInfo struct {
 	AdditionalRequest  string
 	TotalAmountInDB    float64
 	InvoiceIDCandidate string
+	UpdatedAt          time.Time
 }
 
 type PrintFleetOrderItem struct {
@@ -73,6 +76,7 @@ type PrintOrganizationBank struct {
 }
 
 type PrintPaymentOrderInfo struct {
+	PaymentID       string
 	InvoiceNumber   string
 	PaymentType     int
 	PaymentMethod   int
@@ -81,6 +85,19 @@ type PrintPaymentOrderInfo struct {
 	CreatedAt       time.Time
 }
 
+type PrintOrderVoucher struct {
+	Code           string
+	Name           string
+	DiscountAmount float64
+}
+
+type PrintOrderInstallment struct {
+	Label   string
+	Amount  float64
+	DueDate time.Time
+	Status  int
+}
+
 type PrintFleetTripExpense struct {
 	TransactionItem string
 	Description     string
@@ -99,7 +116,12 @@ func (r *PrintManagementRepository) placeholder(position int) string {
 	return "?"
 }
 
-func (r *PrintManagementRepository) GetOrganizationInfo(organizationID string) (*PrintOrganizationInfo, error) {
+func (r *PrintManagementRepository) GetOrganizationInfo(ctx context.Context) (*PrintOrganizationInfo, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.placeholder(1)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.placeholder(1)
@@ -125,7 +147,7 @@ func (r *PrintManagementRepository) GetOrganizationInfo(organizationID string) (
 	var companyLogo sql.NullString
 	var companyWebsite sql.NullString
 
-	err := database.QueryRow(r.db, query, organizationID).Scan(
+	err = t.QueryRow(query, organizationID).Scan(
 		&out.OrganizationName,
 		&companyName,
 		&companyAddress,
@@ -170,7 +192,12 @@ func (r *PrintManagementRepository) GetOrganizationInfo(organizationID string) (
 	return &out, nil
 }
 
-func (r *PrintManagementRepository) GetCustomerInfo(orderID, organizationID string) (*PrintCustomerInfo, error) {
+func (r *PrintManagementRepository) GetCustomerInfo(ctx context.Context, orderID string) (*PrintCustomerInfo, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orderExpr := "co.order_id = " + r.placeholder(1)
 	orgExpr := "co.organization_id = " + r.placeholder(2)
 	customerOrgExpr := "c.organization_id = co.organization_id"
@@ -201,7 +228,7 @@ func (r *PrintManagementRepository) GetCustomerInfo(orderID, organizationID stri
 
 	var out PrintCustomerInfo
 	var customerCity sql.NullString
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(
+	if err := t.QueryRow(query, orderID, organizationID).Scan(
 		&out.CustomerName,
 		&out.CustomerAddress,
 		&customerCity,
@@ -217,7 +244,12 @@ func (r *PrintManagementRepository) GetCustomerInfo(orderID, organizationID stri
 	return &out, nil
 }
 
-func (r *PrintManagementRepository) GetPaymentOrderForInvoice(organizationID, orderID string, invoiceNumber *string) (*PrintPaymentOrderInfo, error) {
+func (r *PrintManagementRepository) GetPaymentOrderForInvoice(ctx context.Context, orderID string, invoiceNumber *string) (*PrintPaymentOrderInfo, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.placeholder(1)
 	orderExpr := "order_id = " + r.placeholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -238,7 +270,7 @@ func (r *PrintManagementRepository) GetPaymentOrderForInvoice(organizationID, or
 	}
 
 	query := fmt.Sprintf(`
-		SELECT COALESCE(invoice_number, '') as invoice_number,
+		SELECT payment_id, COALESCE(invoice_number, '') as invoice_number,
 		       COALESCE(payment_type, 0) as payment_type,
 		       COALESCE(payment_method, 0) as payment_method,
 		       COALESCE(payment_amount, 0) as payment_amount,
@@ -252,7 +284,8 @@ func (r *PrintManagementRepository) GetPaymentOrderForInvoice(organizationID, or
 
 	var out PrintPaymentOrderInfo
 	var inv sql.NullString
-	if err := database.QueryRow(r.db, query, args...).Scan(
+	if err := t.QueryRow(query, args...).Scan(
+		&out.PaymentID,
 		&inv,
 		&out.PaymentType,
 		&out.PaymentMethod,
@@ -268,7 +301,12 @@ func (r *PrintManagementRepository) GetPaymentOrderForInvoice(organizationID, or
 	return &out, nil
 }
 
-func (r *PrintManagementRepository) GetFleetOrderInfo(orderID, organizationID string) (*PrintFleetOrderInfo, error) {
+func (r *PrintManagementRepository) GetFleetOrderInfo(ctx context.Context, orderID string) (*PrintFleetOrderInfo, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orderExpr := "order_id = " + r.placeholder(1)
 	orgExpr := "organization_id = " + r.placeholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -279,7 +317,8 @@ func (r *PrintManagementRepository) GetFleetOrderInfo(orderID, organizationID st
 		SELECT order_id, created_at, start_date, end_date,
 		       pickup_city_id, pickup_location as pickup_address,
 		       COALESCE(additional_request, '') as additional_request,
-		       COALESCE(total_amount, 0) as total_amount
+		       COALESCE(total_amount, 0) as total_amount,
+		       COALESCE(updated_at, created_at) as updated_at
 		FROM fleet_orders
 		WHERE %s AND %s
 		LIMIT 1
@@ -289,7 +328,7 @@ func (r *PrintManagementRepository) GetFleetOrderInfo(orderID, organizationID st
 	var pickupCityID sql.NullString
 	var pickupAddress sql.NullString
 	var additionalRequest sql.NullString
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(
+	if err := t.QueryRow(query, orderID, organizationID).Scan(
 		&out.OrderID,
 		&out.CreatedAt,
 		&out.StartDate,
@@ -298,6 +337,7 @@ func (r *PrintManagementRepository) GetFleetOrderInfo(orderID, organizationID st
 		&pickupAddress,
 		&additionalRequest,
 		&out.TotalAmountInDB,
+		&out.UpdatedAt,
 	); err != nil {
 		return nil, err
 	}
@@ -314,17 +354,22 @@ func (r *PrintManagementRepository) GetFleetOrderInfo(orderID, organizationID st
 	return &out, nil
 }
 
-func (r *PrintManagementRepository) GetFleetOrderItems(orderID, organizationID string) ([]PrintFleetOrderItem, error) {
+func (r *PrintManagementRepository) GetFleetOrderItems(ctx context.Context, orderID string) ([]PrintFleetOrderItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orderExpr := "fo.order_id = " + r.placeholder(1)
 	orgExpr := "fo.organization_id = " + r.placeholder(2)
-	fleetJoinExpr := "f.uuid = fo.fleet_id"
-	priceJoinExpr := "fp.uuid = fo.price_id"
+	fleetJoinExpr := "f.uuid = fo.fleet_id AND f.organization_id = fo.organization_id"
+	priceJoinExpr := "fp.uuid = fo.price_id AND fp.organization_id = fo.organization_id"
 	orderItemIDExpr := "COALESCE(fo.order_item_id, '')"
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orderExpr = "fo.order_id::text = " + r.placeholder(1)
 		orgExpr = "fo.organization_id::text = " + r.placeholder(2)
-		fleetJoinExpr = "f.uuid::text = fo.fleet_id::text"
-		priceJoinExpr = "fp.uuid::text = fo.price_id::text"
+		fleetJoinExpr = "f.uuid::text = fo.fleet_id::text AND f.organization_id = fo.organization_id"
+		priceJoinExpr = "fp.uuid::text = fo.price_id::text AND fp.organization_id = fo.organization_id"
 		orderItemIDExpr = "COALESCE(fo.order_item_id::text, '')"
 	}
 
@@ -343,11 +388,11 @@ func (r *PrintManagementRepository) GetFleetOrderItems(orderID, organizationID s
 	`, orderItemIDExpr, fleetJoinExpr, priceJoinExpr, orderExpr, orgExpr)
 
 	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
-		fmt.Println("GetFleetOrderItems query:", query)
-		fmt.Println("GetFleetOrderItems args:", orderID, organizationID)
+		slog.DebugContext(ctx, "get fleet order items query", "query", query)
+		slog.DebugContext(ctx, "get fleet order items args", "order_id", orderID, "organization_id", organizationID)
 	}
 
-	rows, err := database.Query(r.db, query, orderID, organizationID)
+	rows, err := t.Query(query, orderID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -367,13 +412,13 @@ func (r *PrintManagementRepository) GetFleetOrderItems(orderID, organizationID s
 
 	orderExpr = "fo.order_id = " + r.placeholder(1)
 	orgExpr = "fo.organization_id = " + r.placeholder(2)
-	fleetJoinExpr = "f.uuid = fo.fleet_id"
-	priceJoinExpr = "fp.uuid = fo.price_id"
+	fleetJoinExpr = "f.uuid = fo.fleet_id AND f.organization_id = fo.organization_id"
+	priceJoinExpr = "fp.uuid = fo.price_id AND fp.organization_id = fo.organization_id"
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orderExpr = "fo.order_id::text = " + r.placeholder(1)
 		orgExpr = "fo.organization_id::text = " + r.placeholder(2)
-		fleetJoinExpr = "f.uuid::text = fo.fleet_id::text"
-		priceJoinExpr = "fp.uuid::text = fo.price_id::text"
+		fleetJoinExpr = "f.uuid::text = fo.fleet_id::text AND f.organization_id = fo.organization_id"
+		priceJoinExpr = "fp.uuid::text = fo.price_id::text AND fp.organization_id = fo.organization_id"
 	}
 
 	fallbackQuery := fmt.Sprintf(`
@@ -390,30 +435,35 @@ func (r *PrintManagementRepository) GetFleetOrderItems(orderID, organizationID s
 	`, fleetJoinExpr, priceJoinExpr, orderExpr, orgExpr)
 
 	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
-		fmt.Println("GetFleetOrderItems fallback query:", fallbackQuery)
-		fmt.Println("GetFleetOrderItems fallback args:", orderID, organizationID)
+		slog.DebugContext(ctx, "get fleet order items fallback query", "fallback_query", fallbackQuery)
+		slog.DebugContext(ctx, "get fleet order items fallback args", "order_id", orderID, "organization_id", organizationID)
 	}
 
 	var it PrintFleetOrderItem
 	it.OrderItemID = strings.TrimSpace(orderID)
 	it.FleetDiscount = 0
-	if err := database.QueryRow(r.db, fallbackQuery, orderID, organizationID).Scan(&it.FleetName, &it.FleetPrice, &it.FleetQty, &it.AdditionalAmount); err != nil {
+	if err := t.QueryRow(fallbackQuery, orderID, organizationID).Scan(&it.FleetName, &it.FleetPrice, &it.FleetQty, &it.AdditionalAmount); err != nil {
 		return nil, err
 	}
 	return []PrintFleetOrderItem{it}, nil
 }
 
-func (r *PrintManagementRepository) GetFleetOrderAddons(orderID, organizationID string) ([]PrintFleetOrderAddon, error) {
+func (r *PrintManagementRepository) GetFleetOrderAddons(ctx context.Context, orderID string) ([]PrintFleetOrderAddon, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "oi.organization_id = " + r.placeholder(1)
 	orderExpr := "oi.order_id = " + r.placeholder(2)
-	addonJoinExpr := "fa.uuid = foa.addon_id"
-	itemJoinExpr := "oi.order_item_id = foa.order_item_id"
+	addonJoinExpr := "fa.uuid = foa.addon_id AND fa.organization_id = oi.organization_id"
+	itemJoinExpr := "oi.order_item_id = foa.order_item_id AND foa.organization_id = oi.organization_id"
 	orderItemIDExpr := "COALESCE(foa.order_item_id, '')"
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "oi.organization_id::text = " + r.placeholder(1)
 		orderExpr = "oi.order_id::text = " + r.placeholder(2)
-		addonJoinExpr = "fa.uuid::text = foa.addon_id::text"
-		itemJoinExpr = "oi.order_item_id::text = foa.order_item_id::text"
+		addonJoinExpr = "fa.uuid::text = foa.addon_id::text AND fa.organization_id = oi.organization_id"
+		itemJoinExpr = "oi.order_item_id::text = foa.order_item_id::text AND foa.organization_id = oi.organization_id"
 		orderItemIDExpr = "COALESCE(foa.order_item_id::text, '')"
 	}
 
@@ -430,11 +480,11 @@ func (r *PrintManagementRepository) GetFleetOrderAddons(orderID, organizationID
 	`, orderItemIDExpr, itemJoinExpr, addonJoinExpr, orgExpr, orderExpr)
 
 	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
-		fmt.Println("GetFleetOrderAddons query:", query)
-		fmt.Println("GetFleetOrderAddons args:", organizationID, orderID)
+		slog.DebugContext(ctx, "get fleet order addons query", "query", query)
+		slog.DebugContext(ctx, "get fleet order addons args", "organization_id", organizationID, "order_id", orderID)
 	}
 
-	rows, err := database.Query(r.db, query, organizationID, orderID)
+	rows, err := t.Query(query, organizationID, orderID)
 	if err != nil {
 		return nil, err
 	}
@@ -453,10 +503,12 @@ func (r *PrintManagementRepository) GetFleetOrderAddons(orderID, organizationID
 	}
 
 	orderExpr = "foa.order_id = " + r.placeholder(1)
-	addonJoinExpr = "fa.uuid = foa.addon_id"
+	orgExpr = "foa.organization_id = " + r.placeholder(2)
+	addonJoinExpr = "fa.uuid = foa.addon_id AND fa.organization_id = foa.organization_id"
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orderExpr = "foa.order_id::text = " + r.placeholder(1)
-		addonJoinExpr = "fa.uuid::text = foa.addon_id::text"
+		orgExpr = "foa.organization_id::text = " + r.placeholder(2)
+		addonJoinExpr = "fa.uuid::text = foa.addon_id::text AND fa.organization_id = foa.organization_id"
 	}
 
 	fallbackQuery := fmt.Sprintf(`
@@ -466,17 +518,16 @@ func (r *PrintManagementRepository) GetFleetOrderAddons(orderID, organizationID
 			COALESCE(foa.addon_price, 0) as addon_price
 		FROM fleet_order_addons foa
 		INNER JOIN fleet_addon fa ON %s
-		WHERE %s
+		WHERE %s AND %s
 		ORDER BY fa.addon_name ASC
-	`, addonJoinExpr, orderExpr)
+	`, addonJoinExpr, orderExpr, orgExpr)
 
 	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
-		fmt.Println("GetFleetOrderAddons fallback query:", fallbackQuery)
-		fmt.Println("GetFleetOrderAddons fallback args:", orderID)
-		_ = organizationID
+		slog.DebugContext(ctx, "get fleet order addons fallback query", "fallback_query", fallbackQuery)
+		slog.DebugContext(ctx, "get fleet order addons fallback args", "order_id", orderID, "organization_id", organizationID)
 	}
 
-	fRows, err := database.Query(r.db, fallbackQuery, orderID)
+	fRows, err := t.Query(fallbackQuery, orderID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -497,7 +548,12 @@ func (r *PrintManagementRepository) GetFleetOrderAddons(orderID, organizationID
 	return fallbackItems, nil
 }
 
-func (r *PrintManagementRepository) GetOrganizationBankAccount(organizationID string) (*PrintOrganizationBank, error) {
+func (r *PrintManagementRepository) GetOrganizationBankAccount(ctx context.Context) (*PrintOrganizationBank, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.placeholder(1)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.placeholder(1)
@@ -513,13 +569,92 @@ func (r *PrintManagementRepository) GetOrganizationBankAccount(organizationID st
 	`, orgExpr)
 
 	var out PrintOrganizationBank
-	if err := database.QueryRow(r.db, query, organizationID).Scan(&out.BankCode, &out.BankAccount, &out.BankAccountName); err != nil {
+	if err := t.QueryRow(query, organizationID).Scan(&out.BankCode, &out.BankAccount, &out.BankAccountName); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOrderVoucher returns the voucher redeemed on an order, or sql.ErrNoRows.
+func (r *PrintManagementRepository) GetOrderVoucher(ctx context.Context, orderID string) (*PrintOrderVoucher, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	orderExpr := "vr.order_id = " + r.placeholder(1)
+	orgExpr := "vr.organization_id = " + r.placeholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "vr.organization_id::text = " + r.placeholder(2)
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(vr.code, '') as code,
+		       COALESCE(v.name, '') as name,
+		       COALESCE(vr.discount_amount, 0) as discount_amount
+		FROM voucher_redemptions vr
+		LEFT JOIN vouchers v ON v.voucher_id = vr.voucher_id AND v.organization_id = vr.organization_id
+		WHERE %s AND %s
+		LIMIT 1
+	`, orderExpr, orgExpr)
+
+	var out PrintOrderVoucher
+	if err := t.QueryRow(query, strings.TrimSpace(orderID), strings.TrimSpace(organizationID)).Scan(&out.Code, &out.Name, &out.DiscountAmount); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-func (r *PrintManagementRepository) GetOrderIDByScheduleNumber(scheduleNumber, organizationID string) (string, error) {
+// GetOrderInstallments returns the installment schedule of an order, empty
+// when the order has no payment plan.
+func (r *PrintManagementRepository) GetOrderInstallments(ctx context.Context, orderID string) ([]PrintOrderInstallment, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	orderExpr := "order_id = " + r.placeholder(1)
+	orgExpr := "organization_id = " + r.placeholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "organization_id::text = " + r.placeholder(2)
+	}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(label, '') as label,
+		       COALESCE(amount, 0) as amount,
+		       due_date,
+		       COALESCE(status, 0) as status
+		FROM order_installments
+		WHERE %s AND %s AND status > 0
+		ORDER BY seq ASC
+	`, orderExpr, orgExpr)
+
+	rows, err := t.Query(query, strings.TrimSpace(orderID), strings.TrimSpace(organizationID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []PrintOrderInstallment
+	for rows.Next() {
+		var it PrintOrderInstallment
+		var dueDate sql.NullTime
+		if err := rows.Scan(&it.Label, &it.Amount, &dueDate, &it.Status); err != nil {
+			return nil, err
+		}
+		it.DueDate = dueDate.Time
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *PrintManagementRepository) GetOrderIDByScheduleNumber(ctx context.Context, scheduleNumber string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	snExpr := "schedule_number = " + r.placeholder(1)
 	orgExpr := "organization_id = " + r.placeholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -528,7 +663,7 @@ func (r *PrintManagementRepository) GetOrderIDByScheduleNumber(scheduleNumber, o
 	}
 	query := fmt.Sprintf(`SELECT COALESCE(order_id, '') as order_id FROM schedule_fleets WHERE %s AND %s LIMIT 1`, snExpr, orgExpr)
 	var out sql.NullString
-	if err := database.QueryRow(r.db, query, strings.TrimSpace(scheduleNumber), strings.TrimSpace(organizationID)).Scan(&out); err != nil {
+	if err := t.QueryRow(query, strings.TrimSpace(scheduleNumber), strings.TrimSpace(organizationID)).Scan(&out); err != nil {
 		return "", err
 	}
 	if out.Valid {
@@ -539,7 +674,12 @@ func (r *PrintManagementRepository) GetOrderIDByScheduleNumber(scheduleNumber, o
 	return "", sql.ErrNoRows
 }
 
-func (r *PrintManagementRepository) GetFleetTripTotals(scheduleNumber, organizationID, referenceID string) (float64, float64, error) {
+func (r *PrintManagementRepository) GetFleetTripTotals(ctx context.Context, scheduleNumber, referenceID string) (float64, float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, 0, err
+	}
+	organizationID := t.OrganizationID()
 	snExpr := "schedule_number = " + r.placeholder(1)
 	orgExpr := "organization_id = " + r.placeholder(2)
 	refExpr := "reference_id = " + r.placeholder(3)
@@ -561,7 +701,7 @@ func (r *PrintManagementRepository) GetFleetTripTotals(scheduleNumber, organizat
 
 	var totalExpenses sql.NullFloat64
 	var totalReimburse sql.NullFloat64
-	if err := database.QueryRow(r.db, query, strings.TrimSpace(scheduleNumber), strings.TrimSpace(organizationID), strings.TrimSpace(referenceID)).Scan(&totalExpenses, &totalReimburse); err != nil {
+	if err := t.QueryRow(query, strings.TrimSpace(scheduleNumber), strings.TrimSpace(organizationID), strings.TrimSpace(referenceID)).Scan(&totalExpenses, &totalReimburse); err != nil {
 		return 0, 0, err
 	}
 
@@ -576,7 +716,12 @@ func (r *PrintManagementRepository) GetFleetTripTotals(scheduleNumber, organizat
 	return te, tr, nil
 }
 
-func (r *PrintManagementRepository) GetFleetTripOperationalFee(scheduleNumber, organizationID string) (float64, error) {
+func (r *PrintManagementRepository) GetFleetTripOperationalFee(ctx context.Context, scheduleNumber string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return 0, nil
@@ -585,11 +730,11 @@ func (r *PrintManagementRepository) GetFleetTripOperationalFee(scheduleNumber, o
 	query := fmt.Sprintf(`
 		SELECT COALESCE(SUM(amount), 0) AS operational_fee
 		FROM transactions
-		WHERE reference_id = %s
-	`, r.placeholder(1))
+		WHERE reference_id = %s AND organization_id = %s
+	`, r.placeholder(1), r.placeholder(2))
 
 	var operationalFee sql.NullFloat64
-	if err := database.QueryRow(r.db, query, scheduleNumber).Scan(&operationalFee); err != nil {
+	if err := t.QueryRow(query, scheduleNumber, organizationID).Scan(&operationalFee); err != nil {
 		return 0, err
 	}
 	if operationalFee.Valid {
@@ -598,7 +743,12 @@ func (r *PrintManagementRepository) GetFleetTripOperationalFee(scheduleNumber, o
 	return 0, nil
 }
 
-func (r *PrintManagementRepository) GetFleetTripExpenseHistory(scheduleNumber, organizationID, referenceID string) ([]PrintFleetTripExpense, error) {
+func (r *PrintManagementRepository) GetFleetTripExpenseHistory(ctx context.Context, scheduleNumber, referenceID string) ([]PrintFleetTripExpense, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	snExpr := "schedule_number = " + r.placeholder(1)
 	orgExpr := "organization_id = " + r.placeholder(2)
 	refExpr := "reference_id = " + r.placeholder(3)
@@ -623,7 +773,7 @@ func (r *PrintManagementRepository) GetFleetTripExpenseHistory(scheduleNumber, o
 		ORDER BY created_at ASC
 	`, itemExpr, snExpr, orgExpr, refExpr)
 
-	rows, err := database.Query(r.db, query, strings.TrimSpace(scheduleNumber), strings.TrimSpace(organizationID), strings.TrimSpace(referenceID))
+	rows, err := t.Query(query, strings.TrimSpace(scheduleNumber), strings.TrimSpace(organizationID), strings.TrimSpace(referenceID))
 	if err != nil {
 		return nil, err
 	}
@@ -643,29 +793,75 @@ func (r *PrintManagementRepository) GetFleetTripExpenseHistory(scheduleNumber, o
 	return items, nil
 }
 
-func (r *PrintManagementRepository) CountPaymentOrdersByOrganization(organizationID string) (int, error) {
+func (r *PrintManagementRepository) CountPaymentOrdersByOrganization(ctx context.Context) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.placeholder(1)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.placeholder(1)
 	}
 	query := fmt.Sprintf(`SELECT COUNT(1) FROM payment_orders WHERE %s AND COALESCE(status, 0) > 0`, orgExpr)
 	var count int
-	if err := database.QueryRow(r.db, query, organizationID).Scan(&count); err != nil {
+	if err := t.QueryRow(query, organizationID).Scan(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *PrintManagementRepository) GenerateInvoiceNumber(orderType int, organizationID string, now time.Time) (string, error) {
-	return utils.GenerateInvoiceNumber(r.db, r.driver, organizationID, orderType, now)
+func (r *PrintManagementRepository) GenerateInvoiceNumber(ctx context.Context, orderType int, now time.Time) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	return utils.GenerateTenantInvoiceNumber(t, r.driver, orderType, now)
+}
+
+// AssignPaymentInvoiceNumber stores invoiceNumber on a payment that has none
+// and returns the payment's invoice number, which is the one stored first
+// when two prints race
+func (r *PrintManagementRepository) AssignPaymentInvoiceNumber(ctx context.Context, paymentID, invoiceNumber string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
+	orgExpr := "organization_id = " + r.placeholder(3)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "organization_id::text = " + r.placeholder(3)
+	}
+	update := fmt.Sprintf(`
+		UPDATE payment_orders SET invoice_number = %s
+		WHERE payment_id = %s AND %s AND COALESCE(invoice_number, '') = ''
+	`, r.placeholder(1), r.placeholder(2), orgExpr)
+	if _, err := t.Exec(update, invoiceNumber, paymentID, organizationID); err != nil {
+		return "", err
+	}
+
+	orgExpr = "organization_id = " + r.placeholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "organization_id::text = " + r.placeholder(2)
+	}
+	query := fmt.Sprintf(`SELECT COALESCE(invoice_number, '') FROM payment_orders WHERE payment_id = %s AND %s`, r.placeholder(1), orgExpr)
+	var stored string
+	if err := t.QueryRow(query, paymentID, organizationID).Scan(&stored); err != nil {
+		return "", err
+	}
+	return stored, nil
 }
 
 // GetSubscriptionDetailByInvoice retrieves subscription transaction details by invoice number
-func (r *PrintManagementRepository) GetSubscriptionDetailByInvoice(invoiceNumber string) (transactionID string, packageID string, startDate time.Time, expiryDate time.Time, userID string, organizationID string, paymentMethod sql.NullString, createdAt time.Time, paymentAmount sql.NullFloat64, err error) {
-	query := fmt.Sprintf("SELECT transaction_id, package_id, start_date, expiry_date, user_id, organization_id, payment_method, created_at, payment_amount FROM travego_transactions WHERE invoice_number = %s LIMIT 1", r.placeholder(1))
+func (r *PrintManagementRepository) GetSubscriptionDetailByInvoice(ctx context.Context, invoiceNumber string) (transactionID string, packageID string, startDate time.Time, expiryDate time.Time, userID string, organizationID string, paymentMethod sql.NullString, createdAt time.Time, paymentAmount sql.NullFloat64, err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", "", time.Time{}, time.Time{}, "", "", sql.NullString{}, time.Time{}, sql.NullFloat64{}, err
+	}
+	query := fmt.Sprintf("SELECT transaction_id, package_id, start_date, expiry_date, user_id, organization_id, payment_method, created_at, payment_amount FROM travego_transactions WHERE invoice_number = %s AND organization_id = %s LIMIT 1", r.placeholder(1), r.placeholder(2))
 	var tID, pID, uID, oID sql.NullString
 	var sDate, eDate, cDate sql.NullTime
-	err = database.QueryRow(r.db, query, invoiceNumber).Scan(&tID, &pID, &sDate, &eDate, &uID, &oID, &paymentMethod, &cDate, &paymentAmount)
+	err = t.QueryRow(query, invoiceNumber, t.OrganizationID()).Scan(&tID, &pID, &sDate, &eDate, &uID, &oID, &paymentMethod, &cDate, &paymentAmount)
 	if err != nil {
 		return "", "", time.Time{}, time.Time{}, "", "", sql.NullString{}, time.Time{}, sql.NullFloat64{}, err
 	}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPrintManagementRepositoryIsTenantScoped(t *testing.T) {
+	r := NewPrintManagementRepository(openTenantFake(t), "postgres")
+	const invoiceOfB = "INV-of-b"
+	invoice := invoiceOfB
+	checkTenantIsolation(t, tenantCalls{
+		"GetOrganizationInfo": func(ctx context.Context) error { _, err := r.GetOrganizationInfo(ctx); return err },
+		"GetCustomerInfo":     func(ctx context.Context) error { _, err := r.GetCustomerInfo(ctx, orderOfB); return err },
+		"GetPaymentOrderForInvoice": func(ctx context.Context) error {
+			_, err := r.GetPaymentOrderForInvoice(ctx, orderOfB, &invoice)
+			return err
+		},
+		"GetFleetOrderInfo":   func(ctx context.Context) error { _, err := r.GetFleetOrderInfo(ctx, orderOfB); return err },
+		"GetFleetOrderItems":  func(ctx context.Context) error { _, err := r.GetFleetOrderItems(ctx, orderOfB); return err },
+		"GetFleetOrderAddons": func(ctx context.Context) error { _, err := r.GetFleetOrderAddons(ctx, orderOfB); return err },
+		"GetOrganizationBankAccount": func(ctx context.Context) error {
+			_, err := r.GetOrganizationBankAccount(ctx)
+			return err
+		},
+		"GetOrderVoucher":      func(ctx context.Context) error { _, err := r.GetOrderVoucher(ctx, orderOfB); return err },
+		"GetOrderInstallments": func(ctx context.Context) error { _, err := r.GetOrderInstallments(ctx, orderOfB); return err },
+		"GetOrderIDByScheduleNumber": func(ctx context.Context) error {
+			_, err := r.GetOrderIDByScheduleNumber(ctx, scheduleOfB)
+			return err
+		},
+		"GetFleetTripTotals": func(ctx context.Context) error {
+			_, _, err := r.GetFleetTripTotals(ctx, scheduleOfB, orderOfB)
+			return err
+		},
+		"GetFleetTripOperationalFee": func(ctx context.Context) error {
+			_, err := r.GetFleetTripOperationalFee(ctx, scheduleOfB)
+			return err
+		},
+		"GetFleetTripExpenseHistory": func(ctx context.Context) error {
+			_, err := r.GetFleetTripExpenseHistory(ctx, scheduleOfB, orderOfB)
+			return err
+		},
+		"CountPaymentOrdersByOrganization": func(ctx context.Context) error {
+			_, err := r.CountPaymentOrdersByOrganization(ctx)
+			return err
+		},
+		"GenerateInvoiceNumber": func(ctx context.Context) error {
+			_, err := r.GenerateInvoiceNumber(ctx, 1, time.Now())
+			return err
+		},
+		"AssignPaymentInvoiceNumber": func(ctx context.Context) error {
+			_, err := r.AssignPaymentInvoiceNumber(ctx, paymentOfB, invoiceOfB)
+			return err
+		},
+		"GetSubscriptionDetailByInvoice": func(ctx context.Context) error {
+			_, _, _, _, _, _, _, _, _, err := r.GetSubscriptionDetailByInvoice(ctx, invoiceOfB)
+			return err
+		},
+	})
+}
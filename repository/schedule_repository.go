@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"service-travego/database"
 	"service-travego/model"
@@ -34,7 +35,12 @@ func (r *ScheduleRepository) placeholder(position int) string {
 	return "?"
 }
 
-func (r *ScheduleRepository) OrderPaymentStatus(input model.ScheduleOrderValidationInput) (int, bool, error) {
+func (r *ScheduleRepository) OrderPaymentStatus(ctx context.Context, input model.ScheduleOrderValidationInput) (int, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, false, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.placeholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgExpr = "organization_id::text = " + r.placeholder(2)
@@ -42,7 +48,7 @@ func (r *ScheduleRepository) OrderPaymentStatus(input model.ScheduleOrderValidat
 	query := "SELECT payment_status FROM fleet_orders WHERE order_id = " + r.placeholder(1) + " AND " + orgExpr + " LIMIT 1"
 
 	var paymentStatus sql.NullInt64
-	if err := database.QueryRow(r.db, query, input.OrderID, input.OrganizationID).Scan(&paymentStatus); err != nil {
+	if err := t.QueryRow(query, input.OrderID, organizationID).Scan(&paymentStatus); err != nil {
 		if err == sql.ErrNoRows {
 			return 0, false, nil
 		}
@@ -54,21 +60,56 @@ func (r *ScheduleRepository) OrderPaymentStatus(input model.ScheduleOrderValidat
 	return int(paymentStatus.Int64), true, nil
 }
 
-func (r *ScheduleRepository) OrderItemExists(input model.ScheduleOrderItemValidationInput) (bool, error) {
+// OrderTripDates returns the start and end date of a fleet order.
+func (r *ScheduleRepository) OrderTripDates(ctx context.Context, orderID string) (time.Time, time.Time, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	organizationID := t.OrganizationID()
+	orgExpr := "organization_id = " + r.placeholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		orgExpr = "organization_id::text = " + r.placeholder(2)
+	}
+	query := "SELECT start_date, end_date FROM fleet_orders WHERE order_id = " + r.placeholder(1) + " AND " + orgExpr + " LIMIT 1"
+
+	var startDate, endDate sql.NullTime
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&startDate, &endDate); err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, time.Time{}, false, nil
+		}
+		return time.Time{}, time.Time{}, false, err
+	}
+	if !endDate.Valid {
+		endDate = startDate
+	}
+	return startDate.Time, endDate.Time, true, nil
+}
+
+func (r *ScheduleRepository) OrderItemExists(ctx context.Context, input model.ScheduleOrderItemValidationInput) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	orderExpr := "order_id::text = " + r.placeholder(2)
 	orgExpr := "organization_id::text = " + r.placeholder(1)
 	fleetExpr := "fleet_id::text = " + r.placeholder(3)
 
 	query := "SELECT COUNT(1) FROM fleet_order_items WHERE " + orgExpr + " AND " + orderExpr + " AND " + fleetExpr
 	var count int
-	if err := database.QueryRow(r.db, query, input.OrganizationID, input.OrderID, input.FleetID).Scan(&count); err != nil {
+	if err := t.QueryRow(query, organizationID, input.OrderID, input.FleetID).Scan(&count); err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepositoryInput) (string, error) {
-	tx, err := r.db.Begin()
+func (r *ScheduleRepository) CreateSchedule(ctx context.Context, input model.ScheduleCreateRepositoryInput) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return "", err
 	}
@@ -84,7 +125,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 		INSERT INTO schedules (schedule_id, order_id, organization_id, departure_time, status, created_at, created_by, order_type)
 		VALUES (` + r.placeholder(1) + `, ` + r.placeholder(2) + `, ` + r.placeholder(3) + `, ` + r.placeholder(4) + `, 1, ` + r.placeholder(5) + `, ` + r.placeholder(6) + `, 1)
 	`
-	if _, err = database.TxExec(tx, insertSchedule, scheduleID, input.OrderID, input.OrganizationID, input.DepartureTime, input.CreatedAt, input.UserID); err != nil {
+	if _, err = tx.Exec(insertSchedule, scheduleID, input.OrderID, input.OrganizationID, input.DepartureTime, input.CreatedAt, input.UserID); err != nil {
 		return "", err
 	}
 
@@ -96,7 +137,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 		LIMIT 1
 	`
 
-	if err = database.TxQueryRow(tx, selectLatestSchedule, input.OrderID, input.OrganizationID).Scan(&scheduleID); err != nil {
+	if err = tx.QueryRow(selectLatestSchedule, input.OrderID, input.OrganizationID).Scan(&scheduleID); err != nil {
 		return "", err
 	}
 
@@ -105,7 +146,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgQuery = "SELECT organization_code FROM organizations WHERE organization_id::text = " + r.placeholder(1)
 	}
-	if err = database.TxQueryRow(tx, orgQuery, input.OrganizationID).Scan(&orgCode); err != nil {
+	if err = tx.QueryRow(orgQuery, input.OrganizationID).Scan(&orgCode); err != nil {
 		return "", err
 	}
 
@@ -114,7 +155,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 	if r.driver == "postgres" || r.driver == "pgx" {
 		countQuery = "SELECT COUNT(schedule_number) FROM schedule_fleets WHERE organization_id::text = " + r.placeholder(1)
 	}
-	if err = database.TxQueryRow(tx, countQuery, input.OrganizationID).Scan(&count); err != nil {
+	if err = tx.QueryRow(countQuery, input.OrganizationID).Scan(&count); err != nil {
 		return "", err
 	}
 
@@ -127,7 +168,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 			INSERT INTO schedule_fleets (uuid, schedule_id, order_id, fleet_id, unit_id, departure_time, created_at, created_by, status, organization_id, schedule_number)
 			VALUES (` + r.placeholder(1) + `, ` + r.placeholder(2) + `, ` + r.placeholder(3) + `, ` + r.placeholder(4) + `, ` + r.placeholder(5) + `, ` + r.placeholder(6) + `, ` + r.placeholder(7) + `, ` + r.placeholder(8) + `, 1, ` + r.placeholder(9) + `, ` + r.placeholder(10) + `)
 		`
-		if _, err = database.TxExec(tx, insertScheduleFleet, scheduleFleetID, scheduleID, input.OrderID, fleet.FleetID, fleet.UnitID, input.DepartureTime, input.CreatedAt, input.UserID, input.OrganizationID, tripID); err != nil {
+		if _, err = tx.Exec(insertScheduleFleet, scheduleFleetID, scheduleID, input.OrderID, fleet.FleetID, fleet.UnitID, input.DepartureTime, input.CreatedAt, input.UserID, input.OrganizationID, tripID); err != nil {
 			return "", err
 		}
 		unitID := strings.TrimSpace(fleet.UnitID)
@@ -152,7 +193,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 		if crewID != "" {
 			crewArg = crewID
 		}
-		if _, err = database.TxExec(tx, insertTeam, uuid.New().String(), scheduleID, unitID, scheduleFleetID, driverID, crewArg, input.CreatedAt, input.UserID, input.OrganizationID); err != nil {
+		if _, err = tx.Exec(insertTeam, uuid.New().String(), scheduleID, unitID, scheduleFleetID, driverID, crewArg, input.CreatedAt, input.UserID, input.OrganizationID); err != nil {
 			return "", err
 		}
 	}
@@ -168,7 +209,7 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 	`
 
 	var endDate sql.NullTime
-	if err = database.TxQueryRow(tx, selectEndDate, input.OrderID, input.OrganizationID).Scan(&endDate); err != nil {
+	if err = tx.QueryRow(selectEndDate, input.OrderID, input.OrganizationID).Scan(&endDate); err != nil {
 		return "", err
 	}
 	scheduleEndDate := input.DepartureTime
@@ -181,18 +222,18 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 		VALUES (` + r.placeholder(1) + `, ` + r.placeholder(2) + `, ` + r.placeholder(3) + `, 1, ` + r.placeholder(4) + `, ` + r.placeholder(5) + `, ` + r.placeholder(6) + `, ` + r.placeholder(7) + `, ` + r.placeholder(8) + `, 1)
 	`
 	employees := map[string]struct{}{}
-	for _, t := range input.Teams {
-		driverID := strings.TrimSpace(t.DriverID)
+	for _, team := range input.Teams {
+		driverID := strings.TrimSpace(team.DriverID)
 		if driverID != "" {
 			employees[driverID] = struct{}{}
 		}
-		crewID := strings.TrimSpace(t.CrewID)
+		crewID := strings.TrimSpace(team.CrewID)
 		if crewID != "" {
 			employees[crewID] = struct{}{}
 		}
 	}
 	for employeeID := range employees {
-		if _, err = database.TxExec(tx, insertScheduleTeams, uuid.New().String(), employeeID, input.OrderID, input.DepartureTime, scheduleEndDate, input.CreatedAt, input.UserID, input.OrganizationID); err != nil {
+		if _, err = tx.Exec(insertScheduleTeams, uuid.New().String(), employeeID, input.OrderID, input.DepartureTime, scheduleEndDate, input.CreatedAt, input.UserID, input.OrganizationID); err != nil {
 			return "", err
 		}
 	}
@@ -203,8 +244,12 @@ func (r *ScheduleRepository) CreateSchedule(input model.ScheduleCreateRepository
 	return scheduleID, nil
 }
 
-func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepositoryInput) error {
-	tx, err := r.db.Begin()
+func (r *ScheduleRepository) UpdateSchedule(ctx context.Context, input model.ScheduleUpdateRepositoryInput) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -229,7 +274,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 		SET departure_time = ` + r.placeholder(4) + `, updated_at = ` + r.placeholder(5) + `, updated_by = ` + r.placeholder(6) + `
 		WHERE ` + scheduleExpr + ` AND ` + orgExpr + ` AND ` + orderExpr + `
 	`
-	res, execErr := database.TxExec(tx, updateSchedule, input.ScheduleID, input.OrganizationID, input.OrderID, input.DepartureTime, input.UpdatedAt, input.UserID)
+	res, execErr := tx.Exec(updateSchedule, input.ScheduleID, input.OrganizationID, input.OrderID, input.DepartureTime, input.UpdatedAt, input.UserID)
 	if execErr != nil {
 		return execErr
 	}
@@ -262,7 +307,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 		`
 	}
 
-	rows, qErr := database.TxQuery(tx, selectExisting, input.ScheduleID, input.OrganizationID)
+	rows, qErr := tx.Query(selectExisting, input.ScheduleID, input.OrganizationID)
 	if qErr != nil {
 		return qErr
 	}
@@ -290,7 +335,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 	if r.driver == "postgres" || r.driver == "pgx" {
 		orgQuery = "SELECT organization_code FROM organizations WHERE organization_id::text = " + r.placeholder(1)
 	}
-	if err = database.TxQueryRow(tx, orgQuery, input.OrganizationID).Scan(&orgCode); err != nil {
+	if err = tx.QueryRow(orgQuery, input.OrganizationID).Scan(&orgCode); err != nil {
 		return err
 	}
 
@@ -299,7 +344,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 	if r.driver == "postgres" || r.driver == "pgx" {
 		countQuery = "SELECT COUNT(schedule_number) FROM schedule_fleets WHERE organization_id::text = " + r.placeholder(1)
 	}
-	if err = database.TxQueryRow(tx, countQuery, input.OrganizationID).Scan(&count); err != nil {
+	if err = tx.QueryRow(countQuery, input.OrganizationID).Scan(&count); err != nil {
 		return err
 	}
 
@@ -319,7 +364,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 				INSERT INTO schedule_fleets (uuid, schedule_id, order_id, fleet_id, unit_id, departure_time, created_at, created_by, status, organization_id, schedule_number)
 				VALUES (` + r.placeholder(1) + `, ` + r.placeholder(2) + `, ` + r.placeholder(3) + `, ` + r.placeholder(4) + `, ` + r.placeholder(5) + `, ` + r.placeholder(6) + `, ` + r.placeholder(7) + `, ` + r.placeholder(8) + `, 1, ` + r.placeholder(9) + `, ` + r.placeholder(10) + `)
 			`
-			if _, err = database.TxExec(tx, insertScheduleFleet, scheduleFleetID, input.ScheduleID, input.OrderID, fleet.FleetID, unitID, input.DepartureTime, input.UpdatedAt, input.UserID, input.OrganizationID, tripID); err != nil {
+			if _, err = tx.Exec(insertScheduleFleet, scheduleFleetID, input.ScheduleID, input.OrderID, fleet.FleetID, unitID, input.DepartureTime, input.UpdatedAt, input.UserID, input.OrganizationID, tripID); err != nil {
 				return err
 			}
 		} else {
@@ -337,7 +382,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 					WHERE uuid::text = ` + r.placeholder(1) + ` AND organization_id::text = ` + r.placeholder(2) + `
 				`
 			}
-			if _, err = database.TxExec(tx, updateScheduleFleet, scheduleFleetID, input.OrganizationID, fleet.FleetID, input.DepartureTime); err != nil {
+			if _, err = tx.Exec(updateScheduleFleet, scheduleFleetID, input.OrganizationID, fleet.FleetID, input.DepartureTime); err != nil {
 				return err
 			}
 		}
@@ -378,13 +423,13 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 		}
 
 		if uuidText == "" {
-			if _, err = database.TxExec(tx, insertTeam, uuid.New().String(), input.ScheduleID, unitID, scheduleFleetID, driverID, crewArg, input.UpdatedAt, input.UserID, input.OrganizationID); err != nil {
+			if _, err = tx.Exec(insertTeam, uuid.New().String(), input.ScheduleID, unitID, scheduleFleetID, driverID, crewArg, input.UpdatedAt, input.UserID, input.OrganizationID); err != nil {
 				return err
 			}
 			continue
 		}
 
-		if _, err = database.TxExec(tx, updateTeam, uuidText, input.OrganizationID, scheduleFleetID, unitID, driverID, crewArg, input.UpdatedAt, input.UserID); err != nil {
+		if _, err = tx.Exec(updateTeam, uuidText, input.OrganizationID, scheduleFleetID, unitID, driverID, crewArg, input.UpdatedAt, input.UserID); err != nil {
 			return err
 		}
 	}
@@ -399,7 +444,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 		DELETE FROM schedule_teams
 		WHERE ` + orderExprTeams + ` AND ` + orgExprTeams + ` AND order_type = 1
 	`
-	if _, err = database.TxExec(tx, deleteScheduleTeams, input.OrderID, input.OrganizationID); err != nil {
+	if _, err = tx.Exec(deleteScheduleTeams, input.OrderID, input.OrganizationID); err != nil {
 		return err
 	}
 
@@ -410,7 +455,7 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 		LIMIT 1
 	`
 	var endDate sql.NullTime
-	if err = database.TxQueryRow(tx, selectEndDate, input.OrderID, input.OrganizationID).Scan(&endDate); err != nil {
+	if err = tx.QueryRow(selectEndDate, input.OrderID, input.OrganizationID).Scan(&endDate); err != nil {
 		return err
 	}
 	scheduleEndDate := input.DepartureTime
@@ -423,18 +468,18 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 		VALUES (` + r.placeholder(1) + `, ` + r.placeholder(2) + `, ` + r.placeholder(3) + `, 1, ` + r.placeholder(4) + `, ` + r.placeholder(5) + `, ` + r.placeholder(6) + `, ` + r.placeholder(7) + `, ` + r.placeholder(8) + `, 1)
 	`
 	employees := map[string]struct{}{}
-	for _, t := range input.Teams {
-		driverID := strings.TrimSpace(t.DriverID)
+	for _, team := range input.Teams {
+		driverID := strings.TrimSpace(team.DriverID)
 		if driverID != "" {
 			employees[driverID] = struct{}{}
 		}
-		crewID := strings.TrimSpace(t.CrewID)
+		crewID := strings.TrimSpace(team.CrewID)
 		if crewID != "" {
 			employees[crewID] = struct{}{}
 		}
 	}
 	for employeeID := range employees {
-		if _, err = database.TxExec(tx, insertScheduleTeams, uuid.New().String(), employeeID, input.OrderID, input.DepartureTime, scheduleEndDate, input.UpdatedAt, input.UserID, input.OrganizationID); err != nil {
+		if _, err = tx.Exec(insertScheduleTeams, uuid.New().String(), employeeID, input.OrderID, input.DepartureTime, scheduleEndDate, input.UpdatedAt, input.UserID, input.OrganizationID); err != nil {
 			return err
 		}
 	}
@@ -445,7 +490,12 @@ func (r *ScheduleRepository) UpdateSchedule(input model.ScheduleUpdateRepository
 	return nil
 }
 
-func (r *ScheduleRepository) ListScheduleFleetOrders(input model.ScheduleFleetListQuery, organizationID string, monthStart, monthEnd time.Time) ([]model.ScheduleFleetOrderRow, error) {
+func (r *ScheduleRepository) ListScheduleFleetOrders(ctx context.Context, input model.ScheduleFleetListQuery, monthStart, monthEnd time.Time) ([]model.ScheduleFleetOrderRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "s.organization_id::text = " + r.placeholder(1)
 	departureExpr := "COALESCE(s.departure_time::text, '')"
 	arrivalExpr := "COALESCE(s.arrival_time::text, '')"
@@ -470,8 +520,8 @@ func (r *ScheduleRepository) ListScheduleFleetOrders(input model.ScheduleFleetLi
 			STRING_AGG(DISTINCT foi.city_id::text, ', ') AS destination_ids,
 			` + createdByExpr + ` AS created_by
 		FROM schedules s
-		INNER JOIN fleet_orders fo ON s.order_id = fo.order_id
-		INNER JOIN fleet_order_itinerary foi ON s.order_id = foi.order_id
+		INNER JOIN fleet_orders fo ON s.order_id = fo.order_id AND fo.organization_id = s.organization_id
+		INNER JOIN fleet_order_itinerary foi ON s.order_id = foi.order_id AND foi.organization_id = s.organization_id
 		WHERE ` + orgExpr + ` AND s.order_type = 1
 	`
 
@@ -546,8 +596,8 @@ func (r *ScheduleRepository) ListScheduleFleetOrders(input model.ScheduleFleetLi
 			AND EXISTS (
 				SELECT 1
 				FROM schedule_fleets sf
-				INNER JOIN fleet_units u ON sf.unit_id = u.unit_id
-				INNER JOIN fleets f ON u.fleet_id = f.uuid
+				INNER JOIN fleet_units u ON sf.unit_id = u.unit_id AND u.organization_id = sf.organization_id
+				INNER JOIN fleets f ON u.fleet_id = f.uuid AND f.organization_id = u.organization_id
 				WHERE sf.schedule_id = s.schedule_id
 				  AND sf.organization_id = s.organization_id
 				  AND ` + strings.Join(fleetFilters, " AND ") + `
@@ -572,7 +622,7 @@ func (r *ScheduleRepository) ListScheduleFleetOrders(input model.ScheduleFleetLi
 		ORDER BY fo.start_date ASC, s.created_at DESC
 	`
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -623,7 +673,12 @@ func (r *ScheduleRepository) ListScheduleFleetOrders(input model.ScheduleFleetLi
 	return result, nil
 }
 
-func (r *ScheduleRepository) GetFleetAvailability(filter model.ScheduleFleetAvailabilityFilter, organizationID string) ([]model.ScheduleFleetAvailabilityRow, error) {
+func (r *ScheduleRepository) GetFleetAvailability(ctx context.Context, filter model.ScheduleFleetAvailabilityFilter) ([]model.ScheduleFleetAvailabilityRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "s.organization_id = " + r.placeholder(1)
 	scheduleIDExpr := "COALESCE(CAST(s.schedule_id AS CHAR), '')"
 	departureTimeExpr := "COALESCE(CAST(s.departure_time AS CHAR), '')"
@@ -655,11 +710,11 @@ func (r *ScheduleRepository) GetFleetAvailability(filter model.ScheduleFleetAvai
 			COALESCE(fu.production_year, 0) AS production_year,
 			COALESCE(fu.transmission, '') AS transmission
 		FROM schedules s
-		INNER JOIN fleet_orders fo ON fo.order_id = s.order_id
-		INNER JOIN schedule_fleets sf ON sf.schedule_id = s.schedule_id
-		INNER JOIN fleets f ON f.uuid = fo.fleet_id
-		INNER JOIN fleet_order_items foi ON foi.order_id = fo.order_id
-		INNER JOIN fleet_units fu ON sf.unit_id = fu.unit_id
+		INNER JOIN fleet_orders fo ON fo.order_id = s.order_id AND fo.organization_id = s.organization_id
+		INNER JOIN schedule_fleets sf ON sf.schedule_id = s.schedule_id AND sf.organization_id = s.organization_id
+		INNER JOIN fleets f ON f.uuid = fo.fleet_id AND f.organization_id = s.organization_id
+		INNER JOIN fleet_order_items foi ON foi.order_id = fo.order_id AND foi.organization_id = s.organization_id
+		INNER JOIN fleet_units fu ON sf.unit_id = fu.unit_id AND fu.organization_id = s.organization_id
 		INNER JOIN fleet_types ft ON ft.id = f.fleet_type
 		WHERE s.order_type = 1
 		  AND s.status = 1
@@ -707,7 +762,7 @@ func (r *ScheduleRepository) GetFleetAvailability(filter model.ScheduleFleetAvai
 
 	query := `SELECT * FROM (` + subquery + `) AS sub ORDER BY start_date ASC, departure_time ASC`
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -742,7 +797,12 @@ func (r *ScheduleRepository) GetFleetAvailability(filter model.ScheduleFleetAvai
 	return result, nil
 }
 
-func (r *ScheduleRepository) ListScheduleDetailsByDate(selectedDate time.Time, organizationID string) ([]model.ScheduleDetailByDateRow, error) {
+func (r *ScheduleRepository) ListScheduleDetailsByDate(ctx context.Context, selectedDate time.Time) ([]model.ScheduleDetailByDateRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "s.organization_id::text = " + r.placeholder(1)
 	scheduleIDExpr := "COALESCE(s.schedule_id::text, '')"
 	orderIDExpr := "COALESCE(s.order_id::text, '')"
@@ -761,12 +821,12 @@ func (r *ScheduleRepository) ListScheduleDetailsByDate(selectedDate time.Time, o
 			` + cityAggExpr + ` AS city_ids
 		FROM schedules s
 		INNER JOIN schedule_fleets sf ON s.schedule_id = sf.schedule_id AND sf.organization_id = s.organization_id
-		INNER JOIN fleets f ON sf.fleet_id = f.uuid
+		INNER JOIN fleets f ON sf.fleet_id = f.uuid AND f.organization_id = s.organization_id
 		INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id = sf.uuid AND sft.unit_id = sf.unit_id AND sft.organization_id = s.organization_id
-		INNER JOIN fleet_units fu ON fu.unit_id = sft.unit_id
-		INNER JOIN fleet_orders fo ON fo.order_id = s.order_id
-		INNER JOIN fleet_order_itinerary foi ON fo.order_id = foi.order_id
-		INNER JOIN employee e1 ON e1.uuid = sft.driver_id
+		INNER JOIN fleet_units fu ON fu.unit_id = sft.unit_id AND fu.organization_id = s.organization_id
+		INNER JOIN fleet_orders fo ON fo.order_id = s.order_id AND fo.organization_id = s.organization_id
+		INNER JOIN fleet_order_itinerary foi ON fo.order_id = foi.order_id AND foi.organization_id = s.organization_id
+		INNER JOIN employee e1 ON e1.uuid = sft.driver_id AND e1.organization_id = s.organization_id
 		WHERE ` + orgExpr + `
 		  AND fo.start_date::date <= ` + r.placeholder(2) + `
 		  AND fo.end_date::date >= ` + r.placeholder(3) + `
@@ -774,7 +834,7 @@ func (r *ScheduleRepository) ListScheduleDetailsByDate(selectedDate time.Time, o
 		ORDER BY fo.start_date ASC, s.schedule_id ASC
 	`
 
-	rows, err := database.Query(r.db, query, organizationID, selectedDate, selectedDate)
+	rows, err := t.Query(query, organizationID, selectedDate, selectedDate)
 	if err != nil {
 		return nil, err
 	}
@@ -805,7 +865,12 @@ func (r *ScheduleRepository) ListScheduleDetailsByDate(selectedDate time.Time, o
 	return result, nil
 }
 
-func (r *ScheduleRepository) ListScheduleOperationAvailabilityEmployees(organizationID string, startDate, endDate time.Time, employeeID string) ([]model.ScheduleOperationAvailabilityRow, error) {
+func (r *ScheduleRepository) ListScheduleOperationAvailabilityEmployees(ctx context.Context, startDate, endDate time.Time, employeeID string) ([]model.ScheduleOperationAvailabilityRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	uuidExpr := "COALESCE(CAST(e.uuid AS CHAR), '')"
 	employeeIDExpr := "COALESCE(CAST(e.employee_id AS CHAR), '')"
 	scheduleIDExpr := "''"
@@ -843,7 +908,7 @@ func (r *ScheduleRepository) ListScheduleOperationAvailabilityEmployees(organiza
 			SELECT 1
 			FROM schedule_fleet_teams st
 			INNER JOIN schedules s ON s.schedule_id = st.schedule_id
-			INNER JOIN fleet_orders fo ON fo.order_id = s.order_id
+			INNER JOIN fleet_orders fo ON fo.order_id = s.order_id AND fo.organization_id = s.organization_id
 			WHERE ` + orgScheduleExpr + `
 			  AND st.organization_id = s.organization_id
 			  AND (st.driver_id = e.uuid OR st.crew_id = e.uuid)
@@ -853,7 +918,7 @@ func (r *ScheduleRepository) ListScheduleOperationAvailabilityEmployees(organiza
 		ORDER BY e.fullname ASC
 	`
 
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -880,7 +945,12 @@ func (r *ScheduleRepository) ListScheduleOperationAvailabilityEmployees(organiza
 	return result, nil
 }
 
-func (r *ScheduleRepository) ListAvailableScheduleFleetUnits(organizationID string, startDate, endDate time.Time, fleetID string) ([]model.ScheduleFleetUnitAvailabilityRow, error) {
+func (r *ScheduleRepository) ListAvailableScheduleFleetUnits(ctx context.Context, startDate, endDate time.Time, fleetID string) ([]model.ScheduleFleetUnitAvailabilityRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgFleetExpr := "fu.organization_id = " + r.placeholder(1)
 	orgFleetJoinExpr := "f.organization_id = " + r.placeholder(1)
 	orgScheduleExpr := "sf.organization_id = " + r.placeholder(1)
@@ -891,7 +961,9 @@ func (r *ScheduleRepository) ListAvailableScheduleFleetUnits(organizationID stri
 	fleetIDExpr := "COALESCE(CAST(f.uuid AS CHAR), '')"
 	vehicleIDExpr := "COALESCE(CAST(fu.vehicle_id AS CHAR), '')"
 	fleetFilterExpr := "fu.fleet_id = " + r.placeholder(4)
+	maintenanceJoinExpr := "fm.unit_id = fu.unit_id"
 	if r.driver == "postgres" || r.driver == "pgx" {
+		maintenanceJoinExpr = "fm.unit_id::text = fu.unit_id::text"
 		orgFleetExpr = "fu.organization_id::text = " + r.placeholder(1)
 		orgFleetJoinExpr = "f.organization_id::text = " + r.placeholder(1)
 		orgScheduleExpr = "sf.organization_id::text = " + r.placeholder(1)
@@ -926,10 +998,19 @@ func (r *ScheduleRepository) ListAvailableScheduleFleetUnits(organizationID stri
 			  AND fo.start_date <= ` + r.placeholder(2) + `
 			  AND fo.end_date >= ` + r.placeholder(3) + `
 		  )
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM fleet_unit_maintenance fm
+			WHERE ` + maintenanceJoinExpr + `
+			  AND fm.organization_id = fu.organization_id
+			  AND fm.status IN (` + strconv.Itoa(model.FleetUnitMaintenanceStatusScheduled) + `, ` + strconv.Itoa(model.FleetUnitMaintenanceStatusInWorkshop) + `)
+			  AND fm.start_date <= ` + r.placeholder(2) + `
+			  AND (fm.status = ` + strconv.Itoa(model.FleetUnitMaintenanceStatusInWorkshop) + ` OR fm.end_date >= ` + r.placeholder(3) + `)
+		  )
 		ORDER BY f.fleet_name ASC, fu.created_at ASC
 	`
 
-	rows, err := database.Query(r.db, query, organizationID, endDate, startDate, strings.TrimSpace(fleetID))
+	rows, err := t.Query(query, organizationID, endDate, startDate, strings.TrimSpace(fleetID))
 	if err != nil {
 		return nil, err
 	}
@@ -956,7 +1037,12 @@ func (r *ScheduleRepository) ListAvailableScheduleFleetUnits(organizationID stri
 	return result, nil
 }
 
-func (r *ScheduleRepository) GetFleetWithUnitsForDailyAvailability(organizationID, fleetID string) (string, []model.DailyAvailabilityFleetUnitRow, bool, error) {
+func (r *ScheduleRepository) GetFleetWithUnitsForDailyAvailability(ctx context.Context, fleetID string) (string, []model.DailyAvailabilityFleetUnitRow, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", nil, false, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "f.organization_id = " + r.placeholder(1)
 	fleetExpr := "f.uuid = " + r.placeholder(2)
 	unitOrgJoinExpr := "fu.organization_id = f.organization_id"
@@ -985,7 +1071,7 @@ func (r *ScheduleRepository) GetFleetWithUnitsForDailyAvailability(organizationI
 		ORDER BY fu.created_at ASC
 	`
 
-	rows, err := database.Query(r.db, query, organizationID, strings.TrimSpace(fleetID))
+	rows, err := t.Query(query, organizationID, strings.TrimSpace(fleetID))
 	if err != nil {
 		return "", nil, false, err
 	}
@@ -1024,7 +1110,12 @@ func (r *ScheduleRepository) GetFleetWithUnitsForDailyAvailability(organizationI
 	return fleetName, units, true, nil
 }
 
-func (r *ScheduleRepository) ListScheduledFleetUnitDaysForDailyAvailability(organizationID string, startDate, endDate time.Time, fleetID string) ([]model.DailyAvailabilityFleetScheduledUnitDayRow, error) {
+func (r *ScheduleRepository) ListScheduledFleetUnitDaysForDailyAvailability(ctx context.Context, startDate, endDate time.Time, fleetID string) ([]model.DailyAvailabilityFleetScheduledUnitDayRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query := `
 			SELECT DISTINCT
@@ -1041,7 +1132,7 @@ func (r *ScheduleRepository) ListScheduledFleetUnitDaysForDailyAvailability(orga
 			  AND gs.day::date <= ` + r.placeholder(3) + `::date
 		`
 
-		rows, err := database.Query(r.db, query, organizationID, startDate, endDate, strings.TrimSpace(fleetID))
+		rows, err := t.Query(query, organizationID, startDate, endDate, strings.TrimSpace(fleetID))
 		if err != nil {
 			return nil, err
 		}
@@ -1083,7 +1174,7 @@ func (r *ScheduleRepository) ListScheduledFleetUnitDaysForDailyAvailability(orga
 
 	days := make(map[string]map[string]struct{})
 	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		rows, err := database.Query(r.db, query, organizationID, strings.TrimSpace(fleetID), d, d)
+		rows, err := t.Query(query, organizationID, strings.TrimSpace(fleetID), d, d)
 		if err != nil {
 			return nil, err
 		}
@@ -1122,7 +1213,12 @@ func (r *ScheduleRepository) ListScheduledFleetUnitDaysForDailyAvailability(orga
 	return result, nil
 }
 
-func (r *ScheduleRepository) GetFleetUnitForDailyAvailability(organizationID, unitID string) (model.DailyAvailabilityFleetUnitRow, bool, error) {
+func (r *ScheduleRepository) GetFleetUnitForDailyAvailability(ctx context.Context, unitID string) (model.DailyAvailabilityFleetUnitRow, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return model.DailyAvailabilityFleetUnitRow{}, false, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id::text = " + r.placeholder(1)
 	unitExpr := "unit_id::text = " + r.placeholder(2)
 	unitIDExpr := "COALESCE(unit_id::text, '')"
@@ -1139,7 +1235,7 @@ func (r *ScheduleRepository) GetFleetUnitForDailyAvailability(organizationID, un
 	`
 
 	var row model.DailyAvailabilityFleetUnitRow
-	if err := database.QueryRow(r.db, query, organizationID, strings.TrimSpace(unitID)).Scan(&row.UnitID, &row.VehicleID, &row.PlateNumber); err != nil {
+	if err := t.QueryRow(query, organizationID, strings.TrimSpace(unitID)).Scan(&row.UnitID, &row.VehicleID, &row.PlateNumber); err != nil {
 		if err == sql.ErrNoRows {
 			return model.DailyAvailabilityFleetUnitRow{}, false, nil
 		}
@@ -1148,7 +1244,12 @@ func (r *ScheduleRepository) GetFleetUnitForDailyAvailability(organizationID, un
 	return row, true, nil
 }
 
-func (r *ScheduleRepository) ListScheduledUnitDaysForDailyAvailability(organizationID string, startDate, endDate time.Time, unitID string) ([]model.DailyAvailabilityFleetUnitScheduledDayRow, error) {
+func (r *ScheduleRepository) ListScheduledUnitDaysForDailyAvailability(ctx context.Context, startDate, endDate time.Time, unitID string) ([]model.DailyAvailabilityFleetUnitScheduledDayRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	query := `
 			SELECT
 				gs.day::date AS day,
@@ -1156,7 +1257,7 @@ func (r *ScheduleRepository) ListScheduledUnitDaysForDailyAvailability(organizat
 				COALESCE(STRING_AGG(DISTINCT foi.city_id::text, ','), '') AS destination_ids
 			FROM schedule_fleets sf
 			INNER JOIN fleet_orders fo ON fo.order_id::text = sf.order_id::text AND fo.organization_id::text = sf.organization_id::text
-			LEFT JOIN fleet_order_itinerary foi ON foi.order_id::text = sf.order_id::text
+			LEFT JOIN fleet_order_itinerary foi ON foi.order_id::text = sf.order_id::text AND foi.organization_id::text = sf.organization_id::text
 			CROSS JOIN LATERAL generate_series(date_trunc('day', fo.start_date), date_trunc('day', fo.end_date), interval '1 day') gs(day)
 			WHERE sf.organization_id::text = ` + r.placeholder(1) + `
 			  AND sf.unit_id::text = ` + r.placeholder(4) + `
@@ -1166,7 +1267,7 @@ func (r *ScheduleRepository) ListScheduledUnitDaysForDailyAvailability(organizat
 			GROUP BY gs.day::date, sf.order_id::text
 		`
 
-	rows, err := database.Query(r.db, query, organizationID, startDate, endDate, strings.TrimSpace(unitID))
+	rows, err := t.Query(query, organizationID, startDate, endDate, strings.TrimSpace(unitID))
 	if err != nil {
 		return nil, err
 	}
@@ -1269,7 +1370,12 @@ func (r *ScheduleRepository) buildInClause(input inClauseInput) (string, []inter
 	return input.ColumnName + " IN (" + strings.Join(placeholders, ",") + ")", args
 }
 
-func (r *ScheduleRepository) ListScheduleFleets(scheduleID, organizationID string) ([]model.ScheduleFleetListItem, error) {
+func (r *ScheduleRepository) ListScheduleFleets(ctx context.Context, scheduleID string) ([]model.ScheduleFleetListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 
 	scheduleExpr := "sf.schedule_id::text = " + r.placeholder(1)
 	orgExpr := "sf.organization_id::text = " + r.placeholder(2)
@@ -1288,19 +1394,19 @@ func (r *ScheduleRepository) ListScheduleFleets(scheduleID, organizationID strin
 			COALESCE(sf.schedule_number, '') AS schedule_number,
 			STRING_AGG(DISTINCT foi.city_id::text, ', ') AS destination_ids
 		FROM schedule_fleets sf
-		INNER JOIN schedules s ON s.schedule_id = sf.schedule_id
-		INNER JOIN fleet_units u ON sf.unit_id = u.unit_id
-		INNER JOIN fleets f ON u.fleet_id = f.uuid
-		INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id = sf.uuid
-		INNER JOIN employee e ON sft.driver_id = e.uuid
-		INNER JOIN fleet_order_itinerary foi ON foi.order_id = sf.order_id
-		LEFT JOIN employee e2 ON sft.crew_id = e2.uuid
+		INNER JOIN schedules s ON s.schedule_id = sf.schedule_id AND s.organization_id = sf.organization_id
+		INNER JOIN fleet_units u ON sf.unit_id = u.unit_id AND u.organization_id = sf.organization_id
+		INNER JOIN fleets f ON u.fleet_id = f.uuid AND f.organization_id = sf.organization_id
+		INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id = sf.uuid AND sft.organization_id = sf.organization_id
+		INNER JOIN employee e ON sft.driver_id = e.uuid AND e.organization_id = sf.organization_id
+		INNER JOIN fleet_order_itinerary foi ON foi.order_id = sf.order_id AND foi.organization_id = sf.organization_id
+		LEFT JOIN employee e2 ON sft.crew_id = e2.uuid AND e2.organization_id = sf.organization_id
 		WHERE ` + scheduleExpr + ` AND ` + orgExpr + ` AND s.status = 1
 		GROUP BY f.uuid, f.fleet_name, u.vehicle_id, u.plate_number, u.engine, u.capacity, e.fullname, e2.fullname, sf.schedule_number
 		ORDER BY f.fleet_name ASC
 	`
 
-	rows, err := database.Query(r.db, query, scheduleID, organizationID)
+	rows, err := t.Query(query, scheduleID, organizationID)
 	if err != nil {
 		return nil, err
 	}
@@ -1332,7 +1438,12 @@ func (r *ScheduleRepository) ListScheduleFleets(scheduleID, organizationID strin
 	return result, nil
 }
 
-func (r *ScheduleRepository) LatestScheduleIDByOrderID(organizationID, orderID string) (string, bool, error) {
+func (r *ScheduleRepository) LatestScheduleIDByOrderID(ctx context.Context, orderID string) (string, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", false, err
+	}
+	organizationID := t.OrganizationID()
 	orderExpr := "order_id::text = " + r.placeholder(1)
 	orgExpr := "organization_id::text = " + r.placeholder(2)
 	scheduleIDExpr := "schedule_id::text"
@@ -1346,7 +1457,7 @@ func (r *ScheduleRepository) LatestScheduleIDByOrderID(organizationID, orderID s
 	`
 
 	var scheduleID string
-	if err := database.QueryRow(r.db, query, orderID, organizationID).Scan(&scheduleID); err != nil {
+	if err := t.QueryRow(query, orderID, organizationID).Scan(&scheduleID); err != nil {
 		if err == sql.ErrNoRows {
 			return "", false, nil
 		}
@@ -1355,7 +1466,12 @@ func (r *ScheduleRepository) LatestScheduleIDByOrderID(organizationID, orderID s
 	return scheduleID, true, nil
 }
 
-func (r *ScheduleRepository) GetScheduleDetailRows(scheduleID, organizationID, orderID string) ([]model.ScheduleDetailRow, error) {
+func (r *ScheduleRepository) GetScheduleDetailRows(ctx context.Context, scheduleID, orderID string) ([]model.ScheduleDetailRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	scheduleExpr := "s.schedule_id = " + r.placeholder(1)
 	orgExpr := "s.organization_id = " + r.placeholder(2)
 	orderExpr := "s.order_id = " + r.placeholder(3)
@@ -1402,17 +1518,17 @@ func (r *ScheduleRepository) GetScheduleDetailRows(scheduleID, organizationID, o
 			COALESCE(orole.role_name, '') AS role_name
 		FROM schedules s
 		INNER JOIN schedule_fleets sf ON sf.schedule_id = s.schedule_id AND sf.organization_id = s.organization_id
-		INNER JOIN fleets f ON f.uuid = sf.fleet_id
-		INNER JOIN fleet_units fu ON fu.unit_id = sf.unit_id
+		INNER JOIN fleets f ON f.uuid = sf.fleet_id AND f.organization_id = s.organization_id
+		INNER JOIN fleet_units fu ON fu.unit_id = sf.unit_id AND fu.organization_id = s.organization_id
 		INNER JOIN fleet_types ft ON f.fleet_type = ft.id
 		INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id = sf.uuid AND sft.unit_id = sf.unit_id AND sft.organization_id = s.organization_id
-		LEFT JOIN employee e ON sft.driver_id = e.uuid
-		LEFT JOIN employee ecrew ON sft.crew_id = ecrew.uuid
-		LEFT JOIN organization_roles orole ON orole.role_id = e.role_id
+		LEFT JOIN employee e ON sft.driver_id = e.uuid AND e.organization_id = s.organization_id
+		LEFT JOIN employee ecrew ON sft.crew_id = ecrew.uuid AND ecrew.organization_id = s.organization_id
+		LEFT JOIN organization_roles orole ON orole.role_id = e.role_id AND orole.organization_id = s.organization_id
 		WHERE ` + orgExpr + ` AND ` + orderExpr + ` AND ` + scheduleExpr + `
 	`
 
-	rows, err := database.Query(r.db, query, scheduleID, organizationID, orderID)
+	rows, err := t.Query(query, scheduleID, organizationID, orderID)
 	if err != nil {
 		return nil, err
 	}
@@ -1451,9 +1567,13 @@ func (r *ScheduleRepository) GetScheduleDetailRows(scheduleID, organizationID, o
 	return result, nil
 }
 
-func (r *ScheduleRepository) GetFleetTripDetail(input model.ScheduleFleetTripDetailServiceInput) (*model.ScheduleFleetTripDetailResponse, bool, error) {
+func (r *ScheduleRepository) GetFleetTripDetail(ctx context.Context, input model.ScheduleFleetTripDetailServiceInput) (*model.ScheduleFleetTripDetailResponse, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, false, err
+	}
 	scheduleNumber := strings.TrimSpace(input.ScheduleNumber)
-	orgID := strings.TrimSpace(input.OrganizationID)
+	orgID := t.OrganizationID()
 
 	scheduleNumberExpr := "sf.schedule_number = " + r.placeholder(1)
 	orgExpr := "sf.organization_id::text = " + r.placeholder(2)
@@ -1481,17 +1601,16 @@ func (r *ScheduleRepository) GetFleetTripDetail(input model.ScheduleFleetTripDet
 				COALESCE(e2.avatar, '') AS crew_avatar
 			FROM schedule_fleets sf
 			INNER JOIN schedules s ON s.schedule_id::text = sf.schedule_id::text AND s.organization_id::text = sf.organization_id::text
-			INNER JOIN fleets f ON f.uuid::text = sf.fleet_id::text
-			INNER JOIN fleet_units fu ON fu.unit_id::text = sf.unit_id::text
+			INNER JOIN fleets f ON f.uuid::text = sf.fleet_id::text AND f.organization_id::text = sf.organization_id::text
+			INNER JOIN fleet_units fu ON fu.unit_id::text = sf.unit_id::text AND fu.organization_id::text = sf.organization_id::text
 			INNER JOIN fleet_orders fo ON fo.order_id::text = s.order_id::text AND fo.organization_id::text = s.organization_id::text
 			INNER JOIN schedule_fleet_teams sft ON sft.schedule_fleet_id::text = sf.uuid::text AND sft.organization_id::text = sf.organization_id::text
-			INNER JOIN employee e ON sft.driver_id::text = e.uuid::text
-			INNER JOIN employee e2 ON sft.crew_id::text = e2.uuid::text
+			INNER JOIN employee e ON sft.driver_id::text = e.uuid::text AND e.organization_id::text = sf.organization_id::text
+			INNER JOIN employee e2 ON sft.crew_id::text = e2.uuid::text AND e2.organization_id::text = sf.organization_id::text
 			WHERE ` + scheduleNumberExpr + ` AND ` + orgExpr + `
 		`
 	var res model.ScheduleFleetTripDetailResponse
-	if err := database.QueryRow(
-		r.db,
+	if err := t.QueryRow(
 		query,
 		scheduleNumber,
 		orgID,
@@ -1524,3 +1643,174 @@ func (r *ScheduleRepository) GetFleetTripDetail(input model.ScheduleFleetTripDet
 
 	return &res, true, nil
 }
+
+// ListOrderFleetItems returns the fleets ordered and their unit quantity.
+func (r *ScheduleRepository) ListOrderFleetItems(ctx context.Context, orderID string) ([]model.ScheduleOrderFleetItemRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	fleetIDExpr := "COALESCE(CAST(foi.fleet_id AS CHAR), '')"
+	orgExpr := "foi.organization_id = " + r.placeholder(1)
+	fleetJoinExpr := "f.uuid = foi.fleet_id AND f.organization_id = foi.organization_id"
+	if r.driver == "postgres" || r.driver == "pgx" {
+		fleetIDExpr = "COALESCE(foi.fleet_id::text, '')"
+		orgExpr = "foi.organization_id::text = " + r.placeholder(1)
+		fleetJoinExpr = "f.uuid::text = foi.fleet_id::text AND f.organization_id::text = foi.organization_id::text"
+	}
+
+	query := `
+		SELECT
+			` + fleetIDExpr + ` AS fleet_id,
+			COALESCE(MAX(f.fleet_name), '') AS fleet_name,
+			COALESCE(SUM(foi.quantity), 0) AS quantity
+		FROM fleet_order_items foi
+		LEFT JOIN fleets f ON ` + fleetJoinExpr + `
+		WHERE ` + orgExpr + `
+		  AND foi.order_id = ` + r.placeholder(2) + `
+		GROUP BY foi.fleet_id
+		ORDER BY fleet_name ASC
+	`
+
+	rows, err := t.Query(query, organizationID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.ScheduleOrderFleetItemRow, 0)
+	for rows.Next() {
+		var item model.ScheduleOrderFleetItemRow
+		var quantity float64
+		if err := rows.Scan(&item.FleetID, &item.FleetName, &quantity); err != nil {
+			return nil, err
+		}
+		item.Quantity = int(quantity)
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListTeamTrips returns the driver/crew of every active scheduled unit whose trip
+// overlaps the given range.
+func (r *ScheduleRepository) ListTeamTrips(ctx context.Context, startDate, endDate time.Time) ([]model.ScheduleTeamTripRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	driverExpr := "COALESCE(CAST(st.driver_id AS CHAR), '')"
+	crewExpr := "COALESCE(CAST(st.crew_id AS CHAR), '')"
+	orgExpr := "sf.organization_id = " + r.placeholder(1)
+	teamJoinExpr := "st.schedule_fleet_id = sf.uuid AND st.organization_id = sf.organization_id"
+	orderJoinExpr := "fo.order_id = sf.order_id AND fo.organization_id = sf.organization_id"
+	if r.driver == "postgres" || r.driver == "pgx" {
+		driverExpr = "COALESCE(st.driver_id::text, '')"
+		crewExpr = "COALESCE(st.crew_id::text, '')"
+		orgExpr = "sf.organization_id::text = " + r.placeholder(1)
+		teamJoinExpr = "st.schedule_fleet_id::text = sf.uuid::text AND st.organization_id::text = sf.organization_id::text"
+		orderJoinExpr = "fo.order_id::text = sf.order_id::text AND fo.organization_id::text = sf.organization_id::text"
+	}
+
+	query := `
+		SELECT
+			` + driverExpr + ` AS driver_id,
+			` + crewExpr + ` AS crew_id,
+			fo.start_date,
+			fo.end_date
+		FROM schedule_fleets sf
+		INNER JOIN schedule_fleet_teams st ON ` + teamJoinExpr + `
+		INNER JOIN fleet_orders fo ON ` + orderJoinExpr + `
+		WHERE ` + orgExpr + `
+		  AND COALESCE(sf.status, 0) = 1
+		  AND fo.start_date <= ` + r.placeholder(2) + `
+		  AND fo.end_date >= ` + r.placeholder(3) + `
+	`
+
+	rows, err := t.Query(query, organizationID, endDate, startDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.ScheduleTeamTripRow, 0)
+	for rows.Next() {
+		var item model.ScheduleTeamTripRow
+		var start, end sql.NullTime
+		if err := rows.Scan(&item.DriverID, &item.CrewID, &start, &end); err != nil {
+			return nil, err
+		}
+		item.StartDate = start.Time
+		item.EndDate = end.Time
+		if !end.Valid {
+			item.EndDate = start.Time
+		}
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListUnitTrips returns every active scheduled unit whose trip overlaps the given range.
+func (r *ScheduleRepository) ListUnitTrips(ctx context.Context, startDate, endDate time.Time) ([]model.ScheduleUnitTripRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	unitExpr := "COALESCE(CAST(sf.unit_id AS CHAR), '')"
+	orgExpr := "sf.organization_id = " + r.placeholder(1)
+	orderJoinExpr := "fo.order_id = sf.order_id AND fo.organization_id = sf.organization_id"
+	if r.driver == "postgres" || r.driver == "pgx" {
+		unitExpr = "COALESCE(sf.unit_id::text, '')"
+		orgExpr = "sf.organization_id::text = " + r.placeholder(1)
+		orderJoinExpr = "fo.order_id::text = sf.order_id::text AND fo.organization_id::text = sf.organization_id::text"
+	}
+
+	query := `
+		SELECT
+			` + unitExpr + ` AS unit_id,
+			fo.start_date,
+			fo.end_date
+		FROM schedule_fleets sf
+		INNER JOIN fleet_orders fo ON ` + orderJoinExpr + `
+		WHERE ` + orgExpr + `
+		  AND COALESCE(sf.status, 0) = 1
+		  AND fo.start_date <= ` + r.placeholder(2) + `
+		  AND fo.end_date >= ` + r.placeholder(3) + `
+	`
+
+	rows, err := t.Query(query, organizationID, endDate, startDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.ScheduleUnitTripRow, 0)
+	for rows.Next() {
+		var item model.ScheduleUnitTripRow
+		var start, end sql.NullTime
+		if err := rows.Scan(&item.UnitID, &start, &end); err != nil {
+			return nil, err
+		}
+		item.StartDate = start.Time
+		item.EndDate = end.Time
+		if !end.Valid {
+			item.EndDate = start.Time
+		}
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
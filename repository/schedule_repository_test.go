@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestScheduleRepositoryIsTenantScoped(t *testing.T) {
+	r := NewScheduleRepository(openTenantFake(t), "postgres")
+	now := time.Now()
+	later := now.AddDate(0, 0, 3)
+	teams := []model.ScheduleFleetTeamUpsertItem{{FleetID: fleetOfB, UnitID: unitOfB, DriverID: employeeOfB}}
+	fleets := []model.ScheduleFleetInsertItem{{FleetID: fleetOfB, UnitID: unitOfB}}
+	checkTenantIsolation(t, tenantCalls{
+		"OrderPaymentStatus": func(ctx context.Context) error {
+			_, _, err := r.OrderPaymentStatus(ctx, model.ScheduleOrderValidationInput{OrderID: orderOfB})
+			return err
+		},
+		"OrderTripDates": func(ctx context.Context) error { _, _, _, err := r.OrderTripDates(ctx, orderOfB); return err },
+		"OrderItemExists": func(ctx context.Context) error {
+			_, err := r.OrderItemExists(ctx, model.ScheduleOrderItemValidationInput{OrderID: orderOfB, FleetID: fleetOfB})
+			return err
+		},
+		"CreateSchedule": func(ctx context.Context) error {
+			org, _ := database.OrganizationFromContext(ctx)
+			_, err := r.CreateSchedule(ctx, model.ScheduleCreateRepositoryInput{
+				OrganizationID: org, OrderID: orderOfB, DepartureTime: now, CreatedAt: now, Fleets: fleets, Teams: teams,
+			})
+			return err
+		},
+		"UpdateSchedule": func(ctx context.Context) error {
+			org, _ := database.OrganizationFromContext(ctx)
+			return r.UpdateSchedule(ctx, model.ScheduleUpdateRepositoryInput{
+				OrganizationID: org, ScheduleID: scheduleOfB, OrderID: orderOfB, DepartureTime: now, UpdatedAt: now, Fleets: fleets, Teams: teams,
+			})
+		},
+		"ListScheduleFleetOrders": func(ctx context.Context) error {
+			_, err := r.ListScheduleFleetOrders(ctx, model.ScheduleFleetListQuery{FleetName: "bus"}, now, later)
+			return err
+		},
+		"GetFleetAvailability": func(ctx context.Context) error {
+			_, err := r.GetFleetAvailability(ctx, model.ScheduleFleetAvailabilityFilter{StartDate: "2024-01-01", EndDate: "2024-01-31"})
+			return err
+		},
+		"ListScheduleDetailsByDate": func(ctx context.Context) error { _, err := r.ListScheduleDetailsByDate(ctx, now); return err },
+		"ListScheduleOperationAvailabilityEmployees": func(ctx context.Context) error {
+			_, err := r.ListScheduleOperationAvailabilityEmployees(ctx, now, later, employeeOfB)
+			return err
+		},
+		"ListAvailableScheduleFleetUnits": func(ctx context.Context) error {
+			_, err := r.ListAvailableScheduleFleetUnits(ctx, now, later, fleetOfB)
+			return err
+		},
+		"GetFleetWithUnitsForDailyAvailability": func(ctx context.Context) error {
+			_, _, _, err := r.GetFleetWithUnitsForDailyAvailability(ctx, fleetOfB)
+			return err
+		},
+		"ListScheduledFleetUnitDaysForDailyAvailability": func(ctx context.Context) error {
+			_, err := r.ListScheduledFleetUnitDaysForDailyAvailability(ctx, now, later, fleetOfB)
+			return err
+		},
+		"GetFleetUnitForDailyAvailability": func(ctx context.Context) error {
+			_, _, err := r.GetFleetUnitForDailyAvailability(ctx, unitOfB)
+			return err
+		},
+		"ListScheduledUnitDaysForDailyAvailability": func(ctx context.Context) error {
+			_, err := r.ListScheduledUnitDaysForDailyAvailability(ctx, now, later, unitOfB)
+			return err
+		},
+		"ListScheduleFleets": func(ctx context.Context) error { _, err := r.ListScheduleFleets(ctx, scheduleOfB); return err },
+		"LatestScheduleIDByOrderID": func(ctx context.Context) error {
+			_, _, err := r.LatestScheduleIDByOrderID(ctx, orderOfB)
+			return err
+		},
+		"GetScheduleDetailRows": func(ctx context.Context) error {
+			_, err := r.GetScheduleDetailRows(ctx, scheduleOfB, orderOfB)
+			return err
+		},
+		"GetFleetTripDetail": func(ctx context.Context) error {
+			_, _, err := r.GetFleetTripDetail(ctx, model.ScheduleFleetTripDetailServiceInput{ScheduleNumber: "TRIP-0001"})
+			return err
+		},
+		"ListOrderFleetItems": func(ctx context.Context) error { _, err := r.ListOrderFleetItems(ctx, orderOfB); return err },
+		"ListTeamTrips":       func(ctx context.Context) error { _, err := r.ListTeamTrips(ctx, now, later); return err },
+		"ListUnitTrips":       func(ctx context.Context) error { _, err := r.ListUnitTrips(ctx, now, later); return err },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"CreateSchedule": func() error {
+			_, err := r.CreateSchedule(ctxA, model.ScheduleCreateRepositoryInput{
+				OrganizationID: tenantB, OrderID: orderOfB, DepartureTime: now, CreatedAt: now, Fleets: fleets, Teams: teams,
+			})
+			return err
+		},
+		"UpdateSchedule": func() error {
+			return r.UpdateSchedule(ctxA, model.ScheduleUpdateRepositoryInput{
+				OrganizationID: tenantB, ScheduleID: scheduleOfB, OrderID: orderOfB, DepartureTime: now, UpdatedAt: now, Fleets: fleets, Teams: teams,
+			})
+		},
+	})
+}
@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+// SheetSyncRepository stores the Google Sheets sync setup of organizations and
+// reads the rows to push in (created_at, id) order, so a sync can continue
+// after the last row it wrote.
+type SheetSyncRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewSheetSyncRepository(db *sql.DB, driver string) *SheetSyncRepository {
+	return &SheetSyncRepository{
+		db:     db,
+		driver: driver,
+	}
+}
+
+func (r *SheetSyncRepository) getPlaceholder(pos int) string {
+	if r.driver == "mysql" {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", pos)
+}
+
+const selectSheetSyncConfigColumns = `organization_id::text, spreadsheet_id, enabled, last_synced_at, last_error, updated_at`
+
+func scanSheetSyncConfig(scan func(dest ...interface{}) error) (*model.SheetSyncConfig, error) {
+	var (
+		cfg          model.SheetSyncConfig
+		lastSyncedAt sql.NullTime
+	)
+	if err := scan(&cfg.OrganizationID, &cfg.SpreadsheetID, &cfg.Enabled, &lastSyncedAt, &cfg.LastError, &cfg.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastSyncedAt.Valid {
+		cfg.LastSyncedAt = &lastSyncedAt.Time
+	}
+	return &cfg, nil
+}
+
+// GetConfig returns the sync setup of the organization; nil when it has none
+func (r *SheetSyncRepository) GetConfig(ctx context.Context) (*model.SheetSyncConfig, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	return r.getConfig(t)
+}
+
+func (r *SheetSyncRepository) getConfig(t *database.Tenant) (*model.SheetSyncConfig, error) {
+	query := fmt.Sprintf(`SELECT %s FROM sheet_sync_configs WHERE organization_id = %s`,
+		selectSheetSyncConfigColumns, r.getPlaceholder(1))
+	cfg, err := scanSheetSyncConfig(t.QueryRow(query, t.OrganizationID()).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return cfg, err
+}
+
+// ListEnabledConfigs returns the organizations whose data is synced; it is
+// read by the cron for every organization, outside of any tenant
+func (r *SheetSyncRepository) ListEnabledConfigs(ctx context.Context) ([]model.SheetSyncConfig, error) {
+	query := fmt.Sprintf(`SELECT %s FROM sheet_sync_configs WHERE enabled = true ORDER BY organization_id`,
+		selectSheetSyncConfigColumns)
+	rows, err := database.QueryContext(ctx, r.db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.SheetSyncConfig
+	for rows.Next() {
+		cfg, err := scanSheetSyncConfig(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *cfg)
+	}
+	return items, rows.Err()
+}
+
+// SaveConfig sets the spreadsheet of the organization. Pointing it at another
+// spreadsheet starts the sync over: the cursors are removed.
+func (r *SheetSyncRepository) SaveConfig(ctx context.Context, spreadsheetID string, enabled bool, userID string, now time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+
+	current, err := r.getConfig(t)
+	if err != nil {
+		return err
+	}
+	if current != nil && current.SpreadsheetID != spreadsheetID {
+		query := fmt.Sprintf(`DELETE FROM sheet_sync_cursors WHERE organization_id = %s`, r.getPlaceholder(1))
+		if _, err := t.Exec(query, orgID); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO sheet_sync_configs (organization_id, spreadsheet_id, enabled, created_at, created_by, updated_at)
+		VALUES (%s, %s, %s, %s, %s, %s)
+		ON CONFLICT (organization_id)
+		DO UPDATE SET spreadsheet_id = EXCLUDED.spreadsheet_id, enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
+	_, err = t.Exec(query, orgID, spreadsheetID, enabled, now, nullableString(userID), now)
+	return err
+}
+
+// SetResult records the outcome of a sync; an empty syncErr is a success
+func (r *SheetSyncRepository) SetResult(ctx context.Context, syncErr string, now time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
+	if syncErr != "" {
+		query := fmt.Sprintf(`UPDATE sheet_sync_configs SET last_error = %s WHERE organization_id = %s`,
+			r.getPlaceholder(1), r.getPlaceholder(2))
+		_, err := t.Exec(query, syncErr, t.OrganizationID())
+		return err
+	}
+	query := fmt.Sprintf(`UPDATE sheet_sync_configs SET last_error = '', last_synced_at = %s WHERE organization_id = %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2))
+	_, err = t.Exec(query, now, t.OrganizationID())
+	return err
+}
+
+// ListCursors returns how far each entity of the organization was pushed
+func (r *SheetSyncRepository) ListCursors(ctx context.Context) ([]model.SheetSyncCursor, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT entity, cursor_time, cursor_id, updated_at
+		FROM sheet_sync_cursors
+		WHERE organization_id = %s
+		ORDER BY entity`, r.getPlaceholder(1))
+	rows, err := t.Query(query, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.SheetSyncCursor{}
+	for rows.Next() {
+		var c model.SheetSyncCursor
+		if err := rows.Scan(&c.Entity, &c.After, &c.AfterID, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	return items, rows.Err()
+}
+
+// GetCursor returns how far entity was pushed; the zero cursor when it never was
+func (r *SheetSyncRepository) GetCursor(ctx context.Context, entity string) (model.SheetSyncCursor, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return model.SheetSyncCursor{}, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT entity, cursor_time, cursor_id, updated_at
+		FROM sheet_sync_cursors
+		WHERE organization_id = %s AND entity = %s`, r.getPlaceholder(1), r.getPlaceholder(2))
+	c := model.SheetSyncCursor{Entity: entity}
+	err = t.QueryRow(query, t.OrganizationID(), entity).Scan(&c.Entity, &c.After, &c.AfterID, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return c, nil
+	}
+	return c, err
+}
+
+// SaveCursor records the last row of entity pushed to the sheet
+func (r *SheetSyncRepository) SaveCursor(ctx context.Context, cursor model.SheetSyncCursor, now time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO sheet_sync_cursors (organization_id, entity, cursor_time, cursor_id, updated_at)
+		VALUES (%s, %s, %s, %s, %s)
+		ON CONFLICT (organization_id, entity)
+		DO UPDATE SET cursor_time = EXCLUDED.cursor_time, cursor_id = EXCLUDED.cursor_id, updated_at = EXCLUDED.updated_at`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+	_, err = t.Exec(query, t.OrganizationID(), cursor.Entity, cursor.After, cursor.AfterID, now)
+	return err
+}
+
+// ListOrders returns up to limit fleet orders created after the cursor and
+// before until
+func (r *SheetSyncRepository) ListOrders(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]model.SheetOrderRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT fo.order_id, fo.created_at,
+			COALESCE((
+				SELECT c.customer_name
+				FROM customer_orders co
+				INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id
+				WHERE co.order_id = fo.order_id AND co.organization_id = f.organization_id
+				ORDER BY co.created_at DESC
+				LIMIT 1
+			), '') AS customer_name,
+			COALESCE((
+				SELECT c.customer_phone
+				FROM customer_orders co
+				INNER JOIN customers c ON c.customer_id = co.customer_id AND c.organization_id = f.organization_id
+				WHERE co.order_id = fo.order_id AND co.organization_id = f.organization_id
+				ORDER BY co.created_at DESC
+				LIMIT 1
+			), '') AS customer_phone,
+			COALESCE(f.fleet_name, ''), fo.start_date, fo.end_date, COALESCE(fo.unit_qty, 0),
+			COALESCE(fo.total_amount, 0), COALESCE(fo.payment_status, 0), COALESCE(fo.status, 0)
+		FROM fleet_orders fo
+		INNER JOIN fleets f ON fo.fleet_id = f.uuid AND f.organization_id = fo.organization_id
+		WHERE f.organization_id = %s AND fo.created_at IS NOT NULL
+			AND (fo.created_at, fo.order_id) > (%s, %s) AND fo.created_at < %s
+		ORDER BY fo.created_at, fo.order_id
+		LIMIT %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+
+	rows, err := t.Query(query, t.OrganizationID(), after.After, after.AfterID, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.SheetOrderRow
+	for rows.Next() {
+		var (
+			it                 model.SheetOrderRow
+			startDate, endDate sql.NullTime
+		)
+		if err := rows.Scan(&it.OrderID, &it.CreatedAt, &it.CustomerName, &it.CustomerPhone, &it.FleetName,
+			&startDate, &endDate, &it.UnitQty, &it.TotalAmount, &it.PaymentStatus, &it.Status); err != nil {
+			return nil, err
+		}
+		if startDate.Valid {
+			it.StartDate = &startDate.Time
+		}
+		if endDate.Valid {
+			it.EndDate = &endDate.Time
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// ListPayments returns up to limit fleet order payments created after the
+// cursor and before until
+func (r *SheetSyncRepository) ListPayments(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]model.SheetPaymentRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT fop.order_payment_id::text, COALESCE(fop.order_id, ''), fop.created_at, COALESCE(fop.payment_type, 0),
+			COALESCE(fop.payment_amount, 0), COALESCE(fop.payment_remaining, 0), COALESCE(fop.status, 0), fop.settled_at
+		FROM fleet_order_payment fop
+		WHERE fop.organization_id = %s AND fop.created_at IS NOT NULL
+			AND (fop.created_at, fop.order_payment_id::text) > (%s, %s) AND fop.created_at < %s
+		ORDER BY fop.created_at, fop.order_payment_id::text
+		LIMIT %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+
+	rows, err := t.Query(query, t.OrganizationID(), after.After, after.AfterID, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.SheetPaymentRow
+	for rows.Next() {
+		var (
+			it        model.SheetPaymentRow
+			settledAt sql.NullTime
+		)
+		if err := rows.Scan(&it.PaymentID, &it.OrderID, &it.CreatedAt, &it.PaymentType, &it.PaymentAmount,
+			&it.PaymentRemaining, &it.Status, &settledAt); err != nil {
+			return nil, err
+		}
+		if settledAt.Valid {
+			it.SettledAt = &settledAt.Time
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// ListExpenses returns up to limit active expense transactions created after
+// the cursor and before until
+func (r *SheetSyncRepository) ListExpenses(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]model.SheetExpenseRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT t.transaction_id::text, t.created_at, COALESCE(t.invoice_number, ''), t.transaction_date,
+			COALESCE(t.description, ''), COALESCE(t.transaction_category, ''), COALESCE(t.transaction_item, ''),
+			COALESCE(t.amount, 0)
+		FROM transactions t
+		WHERE t.organization_id = %s AND t.transaction_type = 2 AND t.status = 1 AND t.created_at IS NOT NULL
+			AND (t.created_at, t.transaction_id::text) > (%s, %s) AND t.created_at < %s
+		ORDER BY t.created_at, t.transaction_id::text
+		LIMIT %s`,
+		r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
+
+	rows, err := t.Query(query, t.OrganizationID(), after.After, after.AfterID, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.SheetExpenseRow
+	for rows.Next() {
+		var (
+			it              model.SheetExpenseRow
+			transactionDate sql.NullTime
+		)
+		if err := rows.Scan(&it.TransactionID, &it.CreatedAt, &it.InvoiceNumber, &transactionDate, &it.Description,
+			&it.TransactionCategory, &it.TransactionItem, &it.Amount); err != nil {
+			return nil, err
+		}
+		if transactionDate.Valid {
+			it.TransactionDate = &transactionDate.Time
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestSheetSyncRepositoryIsTenantScoped(t *testing.T) {
+	r := NewSheetSyncRepository(openTenantFake(t), "postgres")
+	now := time.Now()
+	cursor := model.SheetSyncCursor{Entity: model.SheetEntityOrders}
+	checkTenantIsolation(t, tenantCalls{
+		"GetConfig": func(ctx context.Context) error { _, err := r.GetConfig(ctx); return err },
+		"SaveConfig": func(ctx context.Context) error {
+			return r.SaveConfig(ctx, "sheet-1", true, "", now)
+		},
+		"SetResult":      func(ctx context.Context) error { return r.SetResult(ctx, "", now) },
+		"SetResultError": func(ctx context.Context) error { return r.SetResult(ctx, "quota exceeded", now) },
+		"ListCursors":    func(ctx context.Context) error { _, err := r.ListCursors(ctx); return err },
+		"GetCursor": func(ctx context.Context) error {
+			_, err := r.GetCursor(ctx, model.SheetEntityOrders)
+			return err
+		},
+		"SaveCursor": func(ctx context.Context) error { return r.SaveCursor(ctx, cursor, now) },
+		"ListOrders": func(ctx context.Context) error {
+			_, err := r.ListOrders(ctx, cursor, now, 200)
+			return err
+		},
+		"ListPayments": func(ctx context.Context) error {
+			_, err := r.ListPayments(ctx, cursor, now, 200)
+			return err
+		},
+		"ListExpenses": func(ctx context.Context) error {
+			_, err := r.ListExpenses(ctx, cursor, now, 200)
+			return err
+		},
+	})
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"service-travego/database"
+	"service-travego/model"
+
+	"github.com/google/uuid"
+)
+
+const subscriptionLifecycleColumns = `
+	organization_id, COALESCE(package_id, ''), COALESCE(status, 1), expiry_date, COALESCE(package_price, 0), grace_until,
+	COALESCE(reminder_days, 0), COALESCE(scheduled_package_id, ''), scheduled_expiry_date,
+	COALESCE(scheduled_package_price, 0)`
+
+func scanSubscriptionLifecycle(scan func(dest ...interface{}) error) (*model.SubscriptionLifecycle, error) {
+	var (
+		sub                      model.SubscriptionLifecycle
+		expiry, grace, scheduled sql.NullTime
+	)
+	if err := scan(&sub.OrganizationID, &sub.PackageID, &sub.Status, &expiry, &sub.PackagePrice, &grace,
+		&sub.ReminderDays, &sub.ScheduledPackageID, &scheduled, &sub.ScheduledPackagePrice); err != nil {
+		return nil, err
+	}
+	sub.ExpiryDate = expiry.Time
+	if grace.Valid {
+		sub.GraceUntil = &grace.Time
+	}
+	if scheduled.Valid {
+		sub.ScheduledExpiryDate = &scheduled.Time
+	}
+	return &sub, nil
+}
+
+// ListLifecycles returns the subscriptions that are active or in their grace
+// period, of every organization, for the daily lifecycle job
+func (r *SubscriptionRepository) ListLifecycles(ctx context.Context) ([]model.SubscriptionLifecycle, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM _subscription
+		WHERE COALESCE(status, 1) IN (%d, %d) AND expiry_date IS NOT NULL
+	`, subscriptionLifecycleColumns, model.SubscriptionStatusActive, model.SubscriptionStatusGrace)
+
+	rows, err := database.QueryContext(ctx, r.db, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.SubscriptionLifecycle
+	for rows.Next() {
+		sub, err := scanSubscriptionLifecycle(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *sub)
+	}
+	return items, rows.Err()
+}
+
+// GetLifecycle returns the subscription of the organization; nil when it has none
+func (r *SubscriptionRepository) GetLifecycle(ctx context.Context) (*model.SubscriptionLifecycle, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM _subscription
+		WHERE organization_id = %s
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, subscriptionLifecycleColumns, r.getPlaceholder(1))
+
+	sub, err := scanSubscriptionLifecycle(t.QueryRow(query, t.OrganizationID()).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return sub, err
+}
+
+// UpdateLifecycleStatus moves the subscription to status; graceUntil is only
+// kept for the grace period
+func (r *SubscriptionRepository) UpdateLifecycleStatus(ctx context.Context, status int, graceUntil *time.Time) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE _subscription
+		SET status = %s, grace_until = %s, updated_at = NOW()
+		WHERE organization_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+
+	var grace interface{}
+	if graceUntil != nil {
+		grace = graceUntil.Format("2006-01-02")
+	}
+	_, err = t.Exec(query, status, grace, t.OrganizationID())
+	return err
+}
+
+// SetReminderDays records the renewal reminder sent for the current period
+func (r *SubscriptionRepository) SetReminderDays(ctx context.Context, days int) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE _subscription
+		SET reminder_days = %s, updated_at = NOW()
+		WHERE organization_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
+
+	_, err = t.Exec(query, days, t.OrganizationID())
+	return err
+}
+
+// ApplyScheduledPackage starts the period of the downgrade paid for earlier;
+// it begins where the current period ends
+func (r *SubscriptionRepository) ApplyScheduledPackage(ctx context.Context) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE _subscription
+		SET package_id = scheduled_package_id,
+			activate_date = expiry_date,
+			expiry_date = scheduled_expiry_date,
+			package_price = scheduled_package_price,
+			status = %d,
+			grace_until = NULL,
+			reminder_days = NULL,
+			scheduled_package_id = NULL,
+			scheduled_expiry_date = NULL,
+			scheduled_package_price = NULL,
+			updated_at = NOW()
+		WHERE organization_id = %s AND scheduled_package_id IS NOT NULL
+	`, model.SubscriptionStatusActive, r.getPlaceholder(1))
+
+	_, err = t.Exec(query, t.OrganizationID())
+	return err
+}
+
+// InsertEvent adds an entry to the organization's subscription history
+func (r *SubscriptionRepository) InsertEvent(ctx context.Context, e *model.SubscriptionEvent) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	if e.EventID == "" {
+		e.EventID = uuid.New().String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO subscription_events
+		(event_id, organization_id, event, from_status, to_status, package_id, note, created_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
+		r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
+
+	_, err = t.Exec(query, e.EventID, e.OrganizationID, e.Event, e.FromStatus, e.ToStatus, e.PackageID, e.Note, e.CreatedAt)
+	return err
+}
+
+// ListEvents returns the organization's subscription history, newest first
+func (r *SubscriptionRepository) ListEvents(ctx context.Context, limit int) ([]model.SubscriptionEvent, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT event_id, organization_id, event, COALESCE(from_status, 0), COALESCE(to_status, 0),
+			COALESCE(package_id, ''), note, created_at
+		FROM subscription_events
+		WHERE organization_id = %s
+		ORDER BY created_at DESC
+		LIMIT %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2))
+
+	rows, err := t.Query(query, t.OrganizationID(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []model.SubscriptionEvent{}
+	for rows.Next() {
+		var e model.SubscriptionEvent
+		if err := rows.Scan(&e.EventID, &e.OrganizationID, &e.Event, &e.FromStatus, &e.ToStatus, &e.PackageID, &e.Note, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, e)
+	}
+	return items, rows.Err()
+}
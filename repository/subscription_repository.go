@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"service-travego/database"
 	"service-travego/model"
 	"service-travego/utils"
 )
@@ -27,10 +30,14 @@ func (r *SubscriptionRepository) getPlaceholder(pos int) string {
 }
 
 // FindAll retrieves users
-func (r *SubscriptionRepository) GetSubscriptionDetails(orgID string) ([]model.SubscriptionDetail, error) {
+func (r *SubscriptionRepository) GetSubscriptionDetails(ctx context.Context) ([]model.SubscriptionDetail, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
 	var subscriptions []model.SubscriptionDetail
 	query := fmt.Sprintf("SELECT package_id, activate_date as start_date, expiry_date, package_price FROM _subscription WHERE organization_id = %s AND expiry_date >= now() ORDER BY created_at DESC LIMIT 1", r.getPlaceholder(1))
-	rows, err := r.db.Query(query, orgID)
+	rows, err := t.Query(query, t.OrganizationID())
 
 	if err != nil {
 		return nil, err
@@ -46,7 +53,12 @@ func (r *SubscriptionRepository) GetSubscriptionDetails(orgID string) ([]model.S
 	return subscriptions, nil
 }
 
-func (r *SubscriptionRepository) GetSubscriptionHistory(userID, orgID string) ([]model.SubscriptionHistory, error) {
+func (r *SubscriptionRepository) GetSubscriptionHistory(ctx context.Context, userID string) ([]model.SubscriptionHistory, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT 
 			transaction_id, 
@@ -64,8 +76,8 @@ func (r *SubscriptionRepository) GetSubscriptionHistory(userID, orgID string) ([
 		WHERE user_id = %s AND organization_id = %s AND status = 1
 		ORDER BY created_at DESC
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
-	fmt.Println(query, userID, orgID)
-	rows, err := r.db.Query(query, userID, orgID)
+	slog.DebugContext(ctx, "get subscription history", "query", query, "user_id", userID, "organization_id", orgID)
+	rows, err := t.Query(query, userID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -101,18 +113,23 @@ func (r *SubscriptionRepository) GetSubscriptionHistory(userID, orgID string) ([
 }
 
 // InsertTravegoTransaction inserts a new subscription transaction
-func (r *SubscriptionRepository) InsertTravegoTransaction(transactionID, transactionDate, invoiceNumber, packageID, startDate, expiryDate string, status int, userID, orgID, createdAt, createdBy string) error {
+func (r *SubscriptionRepository) InsertTravegoTransaction(ctx context.Context, transactionID, transactionDate, invoiceNumber, packageID, startDate, expiryDate string, status int, userID, paymentGateway, createdAt, createdBy string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
 	query := fmt.Sprintf(`
 		INSERT INTO travego_transactions 
-		(transaction_id, transaction_date, invoice_number, package_id, start_date, expiry_date, status, user_id, organization_id, created_at, created_by) 
-		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
-	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11))
+		(transaction_id, transaction_date, invoice_number, package_id, start_date, expiry_date, status, user_id, organization_id, payment_gateway, created_at, created_by) 
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12))
 
-	_, err := r.db.Exec(query, transactionID, transactionDate, invoiceNumber, packageID, startDate, expiryDate, status, userID, orgID, createdAt, createdBy)
+	_, err = t.Exec(query, transactionID, transactionDate, invoiceNumber, packageID, startDate, expiryDate, status, userID, t.OrganizationID(), nullableString(paymentGateway), createdAt, createdBy)
 	return err
 }
 
-// GenerateSubsInvoiceID generates subscription invoice number
-func (r *SubscriptionRepository) GenerateSubsInvoiceID() (string, error) {
-	return utils.GenerateSubsInvoiceID(r.db, r.driver)
+// GenerateSubsInvoiceID generates subscription invoice number. It counts the
+// transactions of every organization: the TRV sequence is Travego's own
+func (r *SubscriptionRepository) GenerateSubsInvoiceID(ctx context.Context) (string, error) {
+	return utils.GenerateSubsInvoiceID(ctx, r.db, r.driver)
 }
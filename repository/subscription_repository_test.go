@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionRepositoryIsTenantScoped(t *testing.T) {
+	r := NewSubscriptionRepository(openTenantFake(t), "postgres")
+	graceUntil := time.Now().AddDate(0, 0, 7)
+	checkTenantIsolation(t, tenantCalls{
+		"GetSubscriptionDetails": func(ctx context.Context) error { _, err := r.GetSubscriptionDetails(ctx); return err },
+		"GetSubscriptionHistory": func(ctx context.Context) error {
+			_, err := r.GetSubscriptionHistory(ctx, employeeOfB)
+			return err
+		},
+		"InsertTravegoTransaction": func(ctx context.Context) error {
+			return r.InsertTravegoTransaction(ctx, "transaction-of-b", "2026-01-01 00:00:00", "TRV-OF-B", "trave02",
+				"2026-01-01 00:00:00", "2026-02-01 00:00:00", 2, employeeOfB, "midtrans", "2026-01-01 00:00:00", employeeOfB)
+		},
+		"GetLifecycle": func(ctx context.Context) error { _, err := r.GetLifecycle(ctx); return err },
+		"UpdateLifecycleStatus": func(ctx context.Context) error {
+			return r.UpdateLifecycleStatus(ctx, model.SubscriptionStatusGrace, &graceUntil)
+		},
+		"SetReminderDays":       func(ctx context.Context) error { return r.SetReminderDays(ctx, 7) },
+		"ApplyScheduledPackage": func(ctx context.Context) error { return r.ApplyScheduledPackage(ctx) },
+		"ListEvents":            func(ctx context.Context) error { _, err := r.ListEvents(ctx, 100); return err },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"InsertEvent": func() error {
+			return r.InsertEvent(ctxA, &model.SubscriptionEvent{
+				OrganizationID: tenantB,
+				Event:          model.SubscriptionEventReadOnly,
+			})
+		},
+	})
+}
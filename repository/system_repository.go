@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"os"
+	"service-travego/database"
 	"service-travego/model"
 	"strings"
 	"sync"
@@ -43,6 +45,8 @@ func loadPackages() {
 	})
 }
 
+// SystemRepository serves the Travego back office, which reports on every
+// organization, so its queries are not scoped to a tenant
 type SystemRepository struct {
 	db     *sql.DB
 	driver string
@@ -146,9 +150,9 @@ func calculateDateRanges(period string) (currentStart, currentEnd, lastStart, la
 	return
 }
 
-func (r *SystemRepository) getSingleCount(query string, args ...interface{}) (int64, error) {
+func (r *SystemRepository) getSingleCount(ctx context.Context, query string, args ...interface{}) (int64, error) {
 	var count sql.NullInt64
-	err := r.db.QueryRow(query, args...).Scan(&count)
+	err := database.QueryRowContext(ctx, r.db, query, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -158,9 +162,9 @@ func (r *SystemRepository) getSingleCount(query string, args ...interface{}) (in
 	return 0, nil
 }
 
-func (r *SystemRepository) getSingleFloat(query string, args ...interface{}) (float64, error) {
+func (r *SystemRepository) getSingleFloat(ctx context.Context, query string, args ...interface{}) (float64, error) {
 	var val sql.NullFloat64
-	err := r.db.QueryRow(query, args...).Scan(&val)
+	err := database.QueryRowContext(ctx, r.db, query, args...).Scan(&val)
 	if err != nil {
 		return 0, err
 	}
@@ -196,7 +200,7 @@ func getPeriodTrunc(period string, transactionDate time.Time) string {
 	}
 }
 
-func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymarizeResponse, error) {
+func (r *SystemRepository) GetSummarize(ctx context.Context, period string) (*model.SystemSummarymarizeResponse, error) {
 	if r.driver != "postgres" {
 		return nil, fmt.Errorf("unsupported driver")
 	}
@@ -212,16 +216,16 @@ func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymari
 	)
 
 	revenueQuery := "SELECT COALESCE(SUM(payment_amount), 0) FROM travego_transactions WHERE updated_at IS NOT NULL AND status = 1 AND created_at BETWEEN $1 AND $2"
-	revenueCurrent, _ = r.getSingleFloat(revenueQuery, currentStart, currentEnd)
-	revenueLast, _ = r.getSingleFloat(revenueQuery, lastStart, lastEnd)
+	revenueCurrent, _ = r.getSingleFloat(ctx, revenueQuery, currentStart, currentEnd)
+	revenueLast, _ = r.getSingleFloat(ctx, revenueQuery, lastStart, lastEnd)
 
 	totalUsersQuery := "SELECT COUNT(user_id) FROM users WHERE is_admin IS NULL AND is_active = true AND created_at BETWEEN $1 AND $2"
-	totalUsersCurrent, _ = r.getSingleCount(totalUsersQuery, currentStart, currentEnd)
-	totalUsersLast, _ = r.getSingleCount(totalUsersQuery, lastStart, lastEnd)
+	totalUsersCurrent, _ = r.getSingleCount(ctx, totalUsersQuery, currentStart, currentEnd)
+	totalUsersLast, _ = r.getSingleCount(ctx, totalUsersQuery, lastStart, lastEnd)
 
 	totalVisitQuery := "SELECT COALESCE(SUM(count), 0) FROM travego_visitors WHERE period::DATE BETWEEN $1 AND $2"
-	totalVisitCurrent, _ = r.getSingleCount(totalVisitQuery, currentStart, currentEnd)
-	totalVisitLast, _ = r.getSingleCount(totalVisitQuery, lastStart, lastEnd)
+	totalVisitCurrent, _ = r.getSingleCount(ctx, totalVisitQuery, currentStart, currentEnd)
+	totalVisitLast, _ = r.getSingleCount(ctx, totalVisitQuery, lastStart, lastEnd)
 
 	activeUsersQuery := `
 		SELECT COUNT(DISTINCT u.user_id) 
@@ -230,12 +234,12 @@ func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymari
 		INNER JOIN _subscription s ON ou.organization_id = s.organization_id 
 		WHERE u.is_admin IS NULL AND s.expiry_date >= NOW() AND u.created_at BETWEEN $1 AND $2
 	`
-	activeUsersCurrent, _ = r.getSingleCount(activeUsersQuery, currentStart, currentEnd)
-	activeUsersLast, _ = r.getSingleCount(activeUsersQuery, lastStart, lastEnd)
+	activeUsersCurrent, _ = r.getSingleCount(ctx, activeUsersQuery, currentStart, currentEnd)
+	activeUsersLast, _ = r.getSingleCount(ctx, activeUsersQuery, lastStart, lastEnd)
 
 	organizationsQuery := "SELECT COUNT(*) FROM organizations WHERE created_at BETWEEN $1 AND $2"
-	organizationsCurrent, _ = r.getSingleCount(organizationsQuery, currentStart, currentEnd)
-	organizationsLast, _ = r.getSingleCount(organizationsQuery, lastStart, lastEnd)
+	organizationsCurrent, _ = r.getSingleCount(ctx, organizationsQuery, currentStart, currentEnd)
+	organizationsLast, _ = r.getSingleCount(ctx, organizationsQuery, lastStart, lastEnd)
 
 	// Get transaction metrics
 	metricsQuery := `
@@ -248,7 +252,7 @@ func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymari
 		GROUP BY transaction_date, package_id
 		ORDER BY transaction_date ASC, package_id ASC
 	`
-	rows, err := r.db.Query(metricsQuery)
+	rows, err := database.QueryContext(ctx, r.db, metricsQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -305,7 +309,7 @@ func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymari
 
 	// Get visitor metrics
 	visitorQuery := `SELECT count, period FROM travego_visitors ORDER BY period ASC`
-	visitorRows, err := r.db.Query(visitorQuery)
+	visitorRows, err := database.QueryContext(ctx, r.db, visitorQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -338,7 +342,7 @@ func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymari
 		INNER JOIN _subscription s ON ou.organization_id = s.organization_id 
 		WHERE u.is_admin IS NULL AND s.expiry_date >= NOW()
 	`
-	activeUserRows, err := r.db.Query(activeUserQuery)
+	activeUserRows, err := database.QueryContext(ctx, r.db, activeUserQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -390,7 +394,7 @@ func (r *SystemRepository) GetSummarize(period string) (*model.SystemSummarymari
 	}, nil
 }
 
-func (r *SystemRepository) GetDeviceList(search, status string) ([]model.DeviceListItem, error) {
+func (r *SystemRepository) GetDeviceList(ctx context.Context, search, status string) ([]model.DeviceListItem, error) {
 	if r.driver != "postgres" {
 		return nil, fmt.Errorf("unsupported driver")
 	}
@@ -427,7 +431,7 @@ func (r *SystemRepository) GetDeviceList(search, status string) ([]model.DeviceL
 
 	baseQuery += ` ORDER BY COALESCE(ac.updated_at, ac.created_at) DESC`
 
-	rows, err := r.db.Query(baseQuery, args...)
+	rows, err := database.QueryContext(ctx, r.db, baseQuery, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -483,7 +487,7 @@ func (r *SystemRepository) GetDeviceList(search, status string) ([]model.DeviceL
 	return out, nil
 }
 
-func (r *SystemRepository) UpdateDevice(account string, action string, enableData *model.DeviceEnableRequest) error {
+func (r *SystemRepository) UpdateDevice(ctx context.Context, account string, action string, enableData *model.DeviceEnableRequest) error {
 	if r.driver != "postgres" {
 		return fmt.Errorf("unsupported driver")
 	}
@@ -495,7 +499,7 @@ func (r *SystemRepository) UpdateDevice(account string, action string, enableDat
 			    updated_at = NOW()
 			WHERE account = $1
 		`
-		result, err := r.db.Exec(query, account)
+		result, err := database.ExecContext(ctx, r.db, query, account)
 		if err != nil {
 			return err
 		}
@@ -516,7 +520,7 @@ func (r *SystemRepository) UpdateDevice(account string, action string, enableDat
 			    updated_at = NOW()
 			WHERE account = $4
 		`
-		result, err := r.db.Exec(query, enableData.DeviceID, enableData.DeviceName, enableData.DeviceToken, account)
+		result, err := database.ExecContext(ctx, r.db, query, enableData.DeviceID, enableData.DeviceName, enableData.DeviceToken, account)
 		if err != nil {
 			return err
 		}
@@ -547,7 +551,7 @@ type rawOrganization struct {
 	ExpiryDate       sql.NullTime
 }
 
-func (r *SystemRepository) GetOrganizations(search string, status string) ([]rawOrganization, error) {
+func (r *SystemRepository) GetOrganizations(ctx context.Context, search string, status string) ([]rawOrganization, error) {
 	if r.driver != "postgres" {
 		return nil, fmt.Errorf("unsupported driver")
 	}
@@ -578,7 +582,7 @@ func (r *SystemRepository) GetOrganizations(search string, status string) ([]raw
 
 	query += ` ORDER BY o.organization_name ASC`
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := database.QueryContext(ctx, r.db, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -612,7 +616,7 @@ type rawUser struct {
 	IsActive         sql.NullBool
 }
 
-func (r *SystemRepository) GetUsers(search string, isActive string) ([]rawUser, error) {
+func (r *SystemRepository) GetUsers(ctx context.Context, search string, isActive string) ([]rawUser, error) {
 	if r.driver != "postgres" {
 		return nil, fmt.Errorf("unsupported driver")
 	}
@@ -643,7 +647,7 @@ func (r *SystemRepository) GetUsers(search string, isActive string) ([]rawUser,
 
 	query += ` ORDER BY u.fullname ASC`
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := database.QueryContext(ctx, r.db, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -679,13 +683,13 @@ type rawSystemMessage struct {
 	IsRead     sql.NullBool
 }
 
-func (r *SystemRepository) GetMessages() ([]model.SystemMessageItem, error) {
+func (r *SystemRepository) GetMessages(ctx context.Context) ([]model.SystemMessageItem, error) {
 	query := `
 		SELECT message_id, topic_id, fullname, company_name, email, whatsapp, scale, messages, created_at, is_read
 		FROM travego_messages
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -734,9 +738,9 @@ func (r *SystemRepository) GetMessages() ([]model.SystemMessageItem, error) {
 	return out, nil
 }
 
-func (r *SystemRepository) ReadMessage(messageID string) error {
+func (r *SystemRepository) ReadMessage(ctx context.Context, messageID string) error {
 	query := "UPDATE travego_messages SET is_read = true WHERE message_id = " + r.getPlaceholder(1)
-	_, err := r.db.Exec(query, messageID)
+	_, err := database.ExecContext(ctx, r.db, query, messageID)
 	return err
 }
 
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -10,7 +11,12 @@ import (
 	"github.com/google/uuid"
 )
 
-func (r *OrganizationRepository) ListDivisions(organizationID string) ([]model.OrganizationDivision, error) {
+func (r *OrganizationRepository) ListDivisions(ctx context.Context) ([]model.OrganizationDivision, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id IN (" + r.getPlaceholder(1) + "," + r.getPlaceholder(2) + "," + r.getPlaceholder(3) + ")"
 	divisionIDExpr := "division_id"
 	createdByExpr := "COALESCE(created_by, '')"
@@ -33,7 +39,7 @@ func (r *OrganizationRepository) ListDivisions(organizationID string) ([]model.O
 		ORDER BY created_at DESC
 	`, divisionIDExpr, createdByExpr, updatedByExpr, orgExpr)
 
-	rows, err := database.Query(r.db, query, organizationID, defaultOrgID, legacyDefaultOrgID)
+	rows, err := t.Query(query, organizationID, defaultOrgID, legacyDefaultOrgID)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +74,12 @@ func (r *OrganizationRepository) ListDivisions(organizationID string) ([]model.O
 	return out, nil
 }
 
-func (r *OrganizationRepository) CreateDivision(organizationID, createdBy, divisionName, description string) (string, error) {
+func (r *OrganizationRepository) CreateDivision(ctx context.Context, createdBy, divisionName, description string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	id := uuid.New().String()
 	now := time.Now()
 
@@ -79,14 +90,19 @@ func (r *OrganizationRepository) CreateDivision(organizationID, createdBy, divis
 			(%s, %s, %s, %s, %s, %s, 1)
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
 
-	_, err := database.Exec(r.db, query, id, divisionName, description, organizationID, now, createdBy)
+	_, err = t.Exec(query, id, divisionName, description, organizationID, now, createdBy)
 	if err != nil {
 		return "", err
 	}
 	return id, nil
 }
 
-func (r *OrganizationRepository) UpdateDivision(organizationID, updatedBy, divisionID, divisionName, description string) error {
+func (r *OrganizationRepository) UpdateDivision(ctx context.Context, updatedBy, divisionID, divisionName, description string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	now := time.Now()
 
 	orgExpr := "organization_id = " + r.getPlaceholder(6)
@@ -105,7 +121,7 @@ func (r *OrganizationRepository) UpdateDivision(organizationID, updatedBy, divis
 		WHERE %s AND %s AND COALESCE(status, 0) > 0
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), divisionExpr, orgExpr)
 
-	res, err := database.Exec(r.db, query, divisionName, description, now, updatedBy, divisionID, organizationID)
+	res, err := t.Exec(query, divisionName, description, now, updatedBy, divisionID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -116,7 +132,12 @@ func (r *OrganizationRepository) UpdateDivision(organizationID, updatedBy, divis
 	return nil
 }
 
-func (r *OrganizationRepository) DeleteDivision(organizationID, updatedBy, divisionID string) error {
+func (r *OrganizationRepository) DeleteDivision(ctx context.Context, updatedBy, divisionID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	now := time.Now()
 
 	orgExpr := "organization_id = " + r.getPlaceholder(4)
@@ -134,7 +155,7 @@ func (r *OrganizationRepository) DeleteDivision(organizationID, updatedBy, divis
 		WHERE %s AND %s AND COALESCE(status, 0) > 0
 	`, r.getPlaceholder(1), r.getPlaceholder(2), divisionExpr, orgExpr)
 
-	res, err := database.Exec(r.db, query, now, updatedBy, divisionID, organizationID)
+	res, err := t.Exec(query, now, updatedBy, divisionID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -145,7 +166,12 @@ func (r *OrganizationRepository) DeleteDivision(organizationID, updatedBy, divis
 	return nil
 }
 
-func (r *OrganizationRepository) DivisionExists(organizationID, divisionID string) (bool, error) {
+func (r *OrganizationRepository) DivisionExists(ctx context.Context, divisionID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "organization_id = " + r.getPlaceholder(2)
 	divisionExpr := "division_id = " + r.getPlaceholder(1)
 	if r.driver != "mysql" {
@@ -160,14 +186,20 @@ func (r *OrganizationRepository) DivisionExists(organizationID, divisionID strin
 	`, divisionExpr, orgExpr)
 
 	var cnt int
-	if err := database.QueryRow(r.db, query, divisionID, organizationID).Scan(&cnt); err != nil {
+	if err := t.QueryRow(query, divisionID, organizationID).Scan(&cnt); err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
-func (r *OrganizationRepository) ListRoles(organizationID string) ([]model.OrganizationRole, error) {
-	orgExpr := "r.organization_id IN (" + r.getPlaceholder(1) + "," + r.getPlaceholder(2) + "," + r.getPlaceholder(3) + ")"
+func (r *OrganizationRepository) ListRoles(ctx context.Context) ([]model.OrganizationRole, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	divisionOrgExpr, args := r.sharedOrganizationFilter("d.organization_id", organizationID, nil)
+	orgExpr, args := r.sharedOrganizationFilter("r.organization_id", organizationID, args)
 	roleIDExpr := "r.role_id"
 	divisionIDExpr := "r.division_id"
 	createdByExpr := "COALESCE(r.created_by, '')"
@@ -175,7 +207,6 @@ func (r *OrganizationRepository) ListRoles(organizationID string) ([]model.Organ
 	divisionNameExpr := "COALESCE(d.division_name, '')"
 	joinExpr := "d.division_id = r.division_id"
 	if r.driver != "mysql" {
-		orgExpr = "r.organization_id::text IN (" + r.getPlaceholder(1) + "," + r.getPlaceholder(2) + "," + r.getPlaceholder(3) + ")"
 		roleIDExpr = "r.role_id::text"
 		divisionIDExpr = "COALESCE(r.division_id::text, '')"
 		createdByExpr = "COALESCE(r.created_by::text, '')"
@@ -185,19 +216,16 @@ func (r *OrganizationRepository) ListRoles(organizationID string) ([]model.Organ
 		divisionIDExpr = "COALESCE(r.division_id, '')"
 	}
 
-	defaultOrgID := "00000000-0000-0000-0000-000000000000"
-	legacyDefaultOrgID := "000"
-
 	query := fmt.Sprintf(`
 		SELECT %s AS role_id, r.role_name, COALESCE(r.description, '') AS description, %s AS division_id, %s AS division_name,
 		       COALESCE(r.status, 0) AS status, %s AS created_by, r.created_at, %s AS updated_by, r.updated_at
 		FROM organization_roles r
-		LEFT JOIN organization_divisions d ON %s
+		LEFT JOIN organization_divisions d ON %s AND %s
 		WHERE %s AND COALESCE(r.status, 0) > 0
 		ORDER BY r.created_at DESC
-	`, roleIDExpr, divisionIDExpr, divisionNameExpr, createdByExpr, updatedByExpr, joinExpr, orgExpr)
+	`, roleIDExpr, divisionIDExpr, divisionNameExpr, createdByExpr, updatedByExpr, joinExpr, divisionOrgExpr, orgExpr)
 
-	rows, err := database.Query(r.db, query, organizationID, defaultOrgID, legacyDefaultOrgID)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -234,7 +262,12 @@ func (r *OrganizationRepository) ListRoles(organizationID string) ([]model.Organ
 	return out, nil
 }
 
-func (r *OrganizationRepository) CreateRole(organizationID, createdBy, roleName, description, divisionID string) (string, error) {
+func (r *OrganizationRepository) CreateRole(ctx context.Context, createdBy, roleName, description, divisionID string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	id := uuid.New().String()
 	now := time.Now()
 
@@ -246,14 +279,19 @@ func (r *OrganizationRepository) CreateRole(organizationID, createdBy, roleName,
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5),
 		r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
 
-	_, err := database.Exec(r.db, query, id, roleName, description, organizationID, now, createdBy, now, createdBy, divisionID)
+	_, err = t.Exec(query, id, roleName, description, organizationID, now, createdBy, now, createdBy, divisionID)
 	if err != nil {
 		return "", err
 	}
 	return id, nil
 }
 
-func (r *OrganizationRepository) UpdateRole(organizationID, updatedBy, roleID, roleName, description, divisionID string) error {
+func (r *OrganizationRepository) UpdateRole(ctx context.Context, updatedBy, roleID, roleName, description, divisionID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	now := time.Now()
 
 	orgExpr := "organization_id = " + r.getPlaceholder(7)
@@ -273,7 +311,7 @@ func (r *OrganizationRepository) UpdateRole(organizationID, updatedBy, roleID, r
 		WHERE %s AND %s AND COALESCE(status, 0) > 0
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), roleExpr, orgExpr)
 
-	res, err := database.Exec(r.db, query, roleName, description, divisionID, now, updatedBy, roleID, organizationID)
+	res, err := t.Exec(query, roleName, description, divisionID, now, updatedBy, roleID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -284,7 +322,12 @@ func (r *OrganizationRepository) UpdateRole(organizationID, updatedBy, roleID, r
 	return nil
 }
 
-func (r *OrganizationRepository) DeleteRole(organizationID, updatedBy, roleID string) error {
+func (r *OrganizationRepository) DeleteRole(ctx context.Context, updatedBy, roleID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
 	now := time.Now()
 
 	orgExpr := "organization_id = " + r.getPlaceholder(4)
@@ -302,7 +345,7 @@ func (r *OrganizationRepository) DeleteRole(organizationID, updatedBy, roleID st
 		WHERE %s AND %s AND COALESCE(status, 0) > 0
 	`, r.getPlaceholder(1), r.getPlaceholder(2), roleExpr, orgExpr)
 
-	res, err := database.Exec(r.db, query, now, updatedBy, roleID, organizationID)
+	res, err := t.Exec(query, now, updatedBy, roleID, organizationID)
 	if err != nil {
 		return err
 	}
@@ -313,46 +356,149 @@ func (r *OrganizationRepository) DeleteRole(organizationID, updatedBy, roleID st
 	return nil
 }
 
-func (r *OrganizationRepository) GetDivisionOrganizationID(divisionID string) (string, error) {
+func (r *OrganizationRepository) GetDivisionOrganizationID(ctx context.Context, divisionID string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "COALESCE(organization_id, '')"
 	idExpr := "division_id = " + r.getPlaceholder(1)
 	if r.driver != "mysql" {
 		orgExpr = "COALESCE(organization_id::text, '')"
 		idExpr = "division_id::text = " + r.getPlaceholder(1)
 	}
+	sharedExpr, args := r.sharedOrganizationFilter("organization_id", organizationID, []interface{}{divisionID})
 	query := fmt.Sprintf(`
 		SELECT %s AS organization_id
 		FROM organization_divisions
-		WHERE %s AND COALESCE(status, 0) > 0
+		WHERE %s AND %s AND COALESCE(status, 0) > 0
 		LIMIT 1
-	`, orgExpr, idExpr)
+	`, orgExpr, idExpr, sharedExpr)
 
 	var orgID string
-	err := database.QueryRow(r.db, query, divisionID).Scan(&orgID)
+	err = t.QueryRow(query, args...).Scan(&orgID)
 	if err != nil {
 		return "", err
 	}
 	return orgID, nil
 }
 
-func (r *OrganizationRepository) GetRoleOrganizationID(roleID string) (string, error) {
+func (r *OrganizationRepository) GetRoleOrganizationID(ctx context.Context, roleID string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	organizationID := t.OrganizationID()
 	orgExpr := "COALESCE(organization_id, '')"
 	idExpr := "role_id = " + r.getPlaceholder(1)
 	if r.driver != "mysql" {
 		orgExpr = "COALESCE(organization_id::text, '')"
 		idExpr = "role_id::text = " + r.getPlaceholder(1)
 	}
+	sharedExpr, args := r.sharedOrganizationFilter("organization_id", organizationID, []interface{}{roleID})
 	query := fmt.Sprintf(`
 		SELECT %s AS organization_id
 		FROM organization_roles
-		WHERE %s AND COALESCE(status, 0) > 0
+		WHERE %s AND %s AND COALESCE(status, 0) > 0
 		LIMIT 1
-	`, orgExpr, idExpr)
+	`, orgExpr, idExpr, sharedExpr)
 
 	var orgID string
-	err := database.QueryRow(r.db, query, roleID).Scan(&orgID)
+	err = t.QueryRow(query, args...).Scan(&orgID)
 	if err != nil {
 		return "", err
 	}
 	return orgID, nil
 }
+
+// SetRolePermissions replaces the permissions granted by a role
+func (r *OrganizationRepository) SetRolePermissions(ctx context.Context, roleID, updatedBy string, permissions []string) (err error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	organizationID := t.OrganizationID()
+	tx, err := t.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	roleExpr := "role_id = " + r.getPlaceholder(1)
+	orgExpr := "organization_id = " + r.getPlaceholder(2)
+	if r.driver != "mysql" {
+		roleExpr = "role_id::text = " + r.getPlaceholder(1)
+		orgExpr = "organization_id::text = " + r.getPlaceholder(2)
+	}
+
+	// the permissions carry no organization, so the role must be one of ours
+	var owned int
+	if err = tx.QueryRow("SELECT COUNT(1) FROM organization_roles WHERE "+roleExpr+" AND "+orgExpr, roleID, organizationID).Scan(&owned); err != nil {
+		return err
+	}
+	if owned == 0 {
+		err = sql.ErrNoRows
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM organization_role_permissions WHERE "+roleExpr, roleID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := fmt.Sprintf(`
+		INSERT INTO organization_role_permissions (role_id, permission, created_at, created_by)
+		VALUES (%s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+	for _, p := range permissions {
+		if _, err = tx.Exec(query, roleID, p, now, updatedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListRolePermissions returns the permissions of the roles ListRoles returns,
+// keyed by role_id
+func (r *OrganizationRepository) ListRolePermissions(ctx context.Context) (map[string][]string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	organizationID := t.OrganizationID()
+	orgExpr := "r.organization_id IN (" + r.getPlaceholder(1) + "," + r.getPlaceholder(2) + "," + r.getPlaceholder(3) + ")"
+	roleIDExpr := "p.role_id"
+	joinExpr := "r.role_id = p.role_id"
+	if r.driver != "mysql" {
+		orgExpr = "r.organization_id::text IN (" + r.getPlaceholder(1) + "," + r.getPlaceholder(2) + "," + r.getPlaceholder(3) + ")"
+		roleIDExpr = "p.role_id::text"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s AS role_id, p.permission
+		FROM organization_role_permissions p
+		INNER JOIN organization_roles r ON %s
+		WHERE %s AND COALESCE(r.status, 0) > 0
+		ORDER BY p.permission
+	`, roleIDExpr, joinExpr, orgExpr)
+
+	rows, err := t.Query(query, organizationID, "00000000-0000-0000-0000-000000000000", "000")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]string)
+	for rows.Next() {
+		var roleID, permission string
+		if err := rows.Scan(&roleID, &permission); err != nil {
+			return nil, err
+		}
+		out[roleID] = append(out[roleID], permission)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTeamOrganizationRepositoryIsTenantScoped(t *testing.T) {
+	r := NewOrganizationRepository(openTenantFake(t), "postgres")
+	const divisionOfB, roleOfB = "division-of-b", "role-of-b"
+	checkTenantIsolation(t, tenantCalls{
+		"ListDivisions": func(ctx context.Context) error { _, err := r.ListDivisions(ctx); return err },
+		"CreateDivision": func(ctx context.Context) error {
+			_, err := r.CreateDivision(ctx, employeeOfB, "Operation", "")
+			return err
+		},
+		"UpdateDivision": func(ctx context.Context) error {
+			return r.UpdateDivision(ctx, employeeOfB, divisionOfB, "Operation", "")
+		},
+		"DeleteDivision": func(ctx context.Context) error { return r.DeleteDivision(ctx, employeeOfB, divisionOfB) },
+		"DivisionExists": func(ctx context.Context) error { _, err := r.DivisionExists(ctx, divisionOfB); return err },
+		"ListRoles":      func(ctx context.Context) error { _, err := r.ListRoles(ctx); return err },
+		"CreateRole": func(ctx context.Context) error {
+			_, err := r.CreateRole(ctx, employeeOfB, "Driver", "", divisionOfB)
+			return err
+		},
+		"UpdateRole": func(ctx context.Context) error {
+			return r.UpdateRole(ctx, employeeOfB, roleOfB, "Driver", "", divisionOfB)
+		},
+		"DeleteRole": func(ctx context.Context) error { return r.DeleteRole(ctx, employeeOfB, roleOfB) },
+		"GetDivisionOrganizationID": func(ctx context.Context) error {
+			_, err := r.GetDivisionOrganizationID(ctx, divisionOfB)
+			return err
+		},
+		"GetRoleOrganizationID": func(ctx context.Context) error {
+			_, err := r.GetRoleOrganizationID(ctx, roleOfB)
+			return err
+		},
+		"SetRolePermissions": func(ctx context.Context) error {
+			return r.SetRolePermissions(ctx, roleOfB, employeeOfB, []string{"fleet.read"})
+		},
+		"ListRolePermissions": func(ctx context.Context) error { _, err := r.ListRolePermissions(ctx); return err },
+	})
+}
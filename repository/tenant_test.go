@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"service-travego/database"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	tenantA = "6f1c1a52-0a0e-4c4e-9a57-6d3c3f1b0a01"
+	tenantB = "0b6a9d7e-55b2-4f0e-8d6e-2a9f4c7e0b02"
+
+	// records owned by tenantB
+	itemOfB     = "item-of-b"
+	garageOfB   = "garage-of-b"
+	requestOfB  = "request-of-b"
+	purchaseOfB = "purchase-of-b"
+	supplierOfB = "supplier-of-b"
+	employeeOfB = "employee-of-b"
+	documentOfB = "document-of-b"
+	orderOfB    = "order-of-b"
+	voucherOfB  = "voucher-of-b"
+	ruleOfB     = "rule-of-b"
+	planOfB     = "plan-of-b"
+	prefOfB     = "preference-of-b"
+	customerOfB = "customer-of-b"
+	messageOfB  = "message-of-b"
+	contentOfB  = "content-of-b"
+	fleetOfB    = "fleet-of-b"
+	unitOfB     = "unit-of-b"
+	partnerOfB  = "partner-of-b"
+	workOfB     = "maintenance-of-b"
+	scheduleOfB = "schedule-of-b"
+	tripOfB     = "trip-expense-of-b"
+	paymentOfB  = "transaction-of-b"
+
+	notificationOfB = "notification-of-b"
+)
+
+// tenantDB is a fake database where every record belongs to tenantB: a
+// statement only reaches a row when one of its arguments is tenantB, so the
+// statements sent for tenantA never see or change anything. It records the
+// statements that got past the tenant checks.
+type tenantDB struct {
+	mu         sync.Mutex
+	statements []tenantStatement
+}
+
+type tenantStatement struct {
+	query string
+	args  []driver.Value
+}
+
+var fakeTenantDB = &tenantDB{}
+
+func init() {
+	sql.Register("tenantfake", fakeTenantDriver{})
+}
+
+func (d *tenantDB) record(query string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, tenantStatement{query: query, args: args})
+}
+
+func (d *tenantDB) take() []tenantStatement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	statements := d.statements
+	d.statements = nil
+	return statements
+}
+
+func touchesTenantB(args []driver.Value) bool {
+	for _, arg := range args {
+		if s, ok := arg.(string); ok && s == tenantB {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTenantDriver struct{}
+
+func (fakeTenantDriver) Open(string) (driver.Conn, error) { return fakeTenantConn{}, nil }
+
+type fakeTenantConn struct{}
+
+func (fakeTenantConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeTenantStmt{query: query}, nil
+}
+func (fakeTenantConn) Close() error              { return nil }
+func (fakeTenantConn) Begin() (driver.Tx, error) { return fakeTenantTx{}, nil }
+
+type fakeTenantTx struct{}
+
+func (fakeTenantTx) Commit() error   { return nil }
+func (fakeTenantTx) Rollback() error { return nil }
+
+type fakeTenantStmt struct{ query string }
+
+func (s fakeTenantStmt) Close() error  { return nil }
+func (s fakeTenantStmt) NumInput() int { return -1 }
+
+func (s fakeTenantStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fakeTenantDB.record(s.query, args)
+	if touchesTenantB(args) {
+		return driver.RowsAffected(1), nil
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s fakeTenantStmt) Query(args []driver.Value) (driver.Rows, error) {
+	fakeTenantDB.record(s.query, args)
+	if strings.HasPrefix(strings.TrimSpace(s.query), "SELECT COUNT(1)") {
+		return &countRows{}, nil
+	}
+	return emptyRows{}, nil
+}
+
+// emptyRows is what tenantA gets back for tenantB's records
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+// countRows answers the numbering queries with a count of zero
+type countRows struct{ done bool }
+
+func (r *countRows) Columns() []string { return []string{"count"} }
+func (r *countRows) Close() error      { return nil }
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(0)
+	return nil
+}
+
+// tenantCalls are repository methods tried on tenantB's records with the
+// organization taken from ctx
+type tenantCalls map[string]func(ctx context.Context) error
+
+// openTenantFake opens the fake database with no statement recorded yet
+func openTenantFake(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("tenantfake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	fakeTenantDB.take()
+	return db
+}
+
+// checkTenantIsolation proves that calls made for tenantA never see or
+// change tenantB's records, that the same calls made for tenantB do reach
+// them, and that nothing is sent without an organization
+func checkTenantIsolation(t *testing.T, calls tenantCalls) {
+	t.Helper()
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	for name, call := range calls {
+		err := call(ctxA)
+		if errors.Is(err, database.ErrUnscopedQuery) {
+			t.Errorf("%s: refused its own query: %v", name, err)
+		}
+		statements := fakeTenantDB.take()
+		if len(statements) == 0 {
+			t.Errorf("%s: sent no statement", name)
+		}
+		for _, st := range statements {
+			if touchesTenantB(st.args) {
+				t.Errorf("%s: reached tenant B with %s %v", name, st.query, st.args)
+			}
+			if !strings.Contains(strings.ToLower(st.query), "organization_id") {
+				t.Errorf("%s: statement without organization filter: %s", name, st.query)
+			}
+		}
+	}
+
+	// the check above is not passing only because nothing is ever bound
+	ctxB := database.WithOrganization(context.Background(), tenantB)
+	for name, call := range calls {
+		_ = call(ctxB)
+		statements := fakeTenantDB.take()
+		if len(statements) == 0 || !touchesTenantB(statements[0].args) {
+			t.Errorf("%s: first statement is not bound to tenant B", name)
+		}
+	}
+
+	for name, call := range calls {
+		if err := call(context.Background()); !errors.Is(err, database.ErrNoOrganization) {
+			t.Errorf("%s: expected ErrNoOrganization, got %v", name, err)
+		}
+		if statements := fakeTenantDB.take(); len(statements) != 0 {
+			t.Errorf("%s: sent %d statements without an organization", name, len(statements))
+		}
+	}
+}
+
+// checkRefusedWrites proves that writes of records claiming tenantB's
+// organization, made for tenantA, are refused before reaching the database
+func checkRefusedWrites(t *testing.T, writes map[string]func() error) {
+	t.Helper()
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, database.ErrUnscopedQuery) {
+			t.Errorf("%s: expected ErrUnscopedQuery, got %v", name, err)
+		}
+		for _, st := range fakeTenantDB.take() {
+			if touchesTenantB(st.args) {
+				t.Errorf("%s: reached tenant B with %s %v", name, st.query, st.args)
+			}
+		}
+	}
+}
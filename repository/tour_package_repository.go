@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"service-travego/database"
 	"service-travego/model"
@@ -56,6 +56,7 @@ type CreateTourPackageOrderInput struct {
 	TotalPax         int
 	TotalAmount      float64
 	AddonIDs         []string
+	Voucher          *model.VoucherApplication
 }
 
 type UpdateTourPackageOrderInput struct {
@@ -160,12 +161,17 @@ func scanSingleRowToMap(rows *sql.Rows) (map[string]interface{}, error) {
 	return items[0], nil
 }
 
-func (r *TourPackageRepository) SoftDeleteTourPackage(ctx context.Context, orgID, userID, packageID string) error {
+func (r *TourPackageRepository) SoftDeleteTourPackage(ctx context.Context, userID, packageID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := softDeleteTourPackageMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = softDeleteTourPackagePostgres
 	}
-	res, err := database.ExecContext(ctx, r.db, query, time.Now(), userID, packageID, orgID)
+	res, err := t.Exec(query, time.Now(), userID, packageID, orgID)
 	if err != nil {
 		return err
 	}
@@ -176,12 +182,17 @@ func (r *TourPackageRepository) SoftDeleteTourPackage(ctx context.Context, orgID
 	return nil
 }
 
-func (r *TourPackageRepository) SetTourPackageActiveStatus(ctx context.Context, orgID, userID, packageID string, active bool) error {
+func (r *TourPackageRepository) SetTourPackageActiveStatus(ctx context.Context, userID, packageID string, active bool) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	query := setTourPackageActiveMySQL
 	if r.driver == "postgres" || r.driver == "pgx" {
 		query = setTourPackageActivePostgres
 	}
-	res, err := database.ExecContext(ctx, r.db, query, active, time.Now(), userID, packageID, orgID)
+	res, err := t.Exec(query, active, time.Now(), userID, packageID, orgID)
 	if err != nil {
 		return err
 	}
@@ -192,7 +203,12 @@ func (r *TourPackageRepository) SetTourPackageActiveStatus(ctx context.Context,
 	return nil
 }
 
-func (r *TourPackageRepository) GetTourPackagesByOrgID(orgID string) ([]model.TourPackageListItem, error) {
+func (r *TourPackageRepository) GetTourPackagesByOrgID(ctx context.Context) ([]model.TourPackageListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := `
 		SELECT 
 			tp.uuid AS package_id,
@@ -211,11 +227,11 @@ func (r *TourPackageRepository) GetTourPackagesByOrgID(orgID string) ([]model.To
 			STRING_AGG(DISTINCT tpi.city_id::text, ', ') AS city_ids
 		FROM tour_packages tp
 		LEFT JOIN tour_package_prices tpp 
-			ON tpp.package_id = tp.uuid
+			ON tpp.package_id = tp.uuid AND tpp.organization_id = tp.organization_id
 		LEFT JOIN tour_package_itineraries tpi 
-			ON tpi.package_id = tp.uuid
+			ON tpi.package_id = tp.uuid AND tpi.organization_id = tp.organization_id
 		LEFT JOIN tour_package_schedules tps 
-			ON tps.package_id = tp.uuid AND tps.active = 1 AND tps.status = 1
+			ON tps.package_id = tp.uuid AND tps.organization_id = tp.organization_id AND tps.active = 1 AND tps.status = 1
 		WHERE tp.organization_id = %s 
 		  AND tp.status = 1 AND tp.active = true
 		GROUP BY tp.uuid, tp.package_name, tp.thumbnail, tp.package_description, tp.package_type, tps.date_start, tps.date_end, tp.status, tp.active
@@ -224,7 +240,7 @@ func (r *TourPackageRepository) GetTourPackagesByOrgID(orgID string) ([]model.To
 	// Set query placeholder
 	query = fmt.Sprintf(query, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -305,7 +321,12 @@ func (r *TourPackageRepository) GetTourPackagesByOrgID(orgID string) ([]model.To
 	return items, nil
 }
 
-func (r *TourPackageRepository) ListTourPackageOrders(orgID string) ([]model.TourPackageOrderListItem, error) {
+func (r *TourPackageRepository) ListTourPackageOrders(ctx context.Context) ([]model.TourPackageOrderListItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(`
 		SELECT
 			tpo.order_id,
@@ -317,13 +338,13 @@ func (r *TourPackageRepository) ListTourPackageOrders(orgID string) ([]model.Tou
 			tpo.start_date,
 			tpo.end_date
 		FROM tour_package_orders tpo
-		INNER JOIN tour_packages tp ON tp.uuid = tpo.tour_package_id
+		INNER JOIN tour_packages tp ON tp.uuid = tpo.tour_package_id AND tp.organization_id = tpo.organization_id
 		INNER JOIN customers c ON c.customer_id = tpo.customer_id
 		WHERE tp.organization_id = %s AND c.organization_id = %s
 		ORDER BY tpo.start_date DESC
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
-	rows, err := database.Query(r.db, query, orgID, orgID)
+	rows, err := t.Query(query, orgID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -377,14 +398,19 @@ func (r *TourPackageRepository) ListTourPackageOrders(orgID string) ([]model.Tou
 	return items, nil
 }
 
-func (r *TourPackageRepository) CustomerExistsByOrgID(orgID, customerID string) (bool, error) {
+func (r *TourPackageRepository) CustomerExistsByOrgID(ctx context.Context, customerID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(
 		"SELECT 1 FROM customers WHERE customer_id = %s AND organization_id = %s LIMIT 1",
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
 	var one int
-	err := database.QueryRow(r.db, query, customerID, orgID).Scan(&one)
+	err = t.QueryRow(query, customerID, orgID).Scan(&one)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -394,14 +420,19 @@ func (r *TourPackageRepository) CustomerExistsByOrgID(orgID, customerID string)
 	return true, nil
 }
 
-func (r *TourPackageRepository) TourPackageExistsByOrgID(orgID, packageID string) (bool, error) {
+func (r *TourPackageRepository) TourPackageExistsByOrgID(ctx context.Context, packageID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(
 		"SELECT 1 FROM tour_packages WHERE uuid = %s AND organization_id = %s LIMIT 1",
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
 	var one int
-	err := database.QueryRow(r.db, query, packageID, orgID).Scan(&one)
+	err = t.QueryRow(query, packageID, orgID).Scan(&one)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -411,14 +442,19 @@ func (r *TourPackageRepository) TourPackageExistsByOrgID(orgID, packageID string
 	return true, nil
 }
 
-func (r *TourPackageRepository) TourPackageOrderExistsByOrgID(orgID, orderID string) (bool, error) {
+func (r *TourPackageRepository) TourPackageOrderExistsByOrgID(ctx context.Context, orderID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(
 		"SELECT 1 FROM tour_package_orders WHERE order_id = %s AND organization_id = %s LIMIT 1",
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
 	var one int
-	err := database.QueryRow(r.db, query, orderID, orgID).Scan(&one)
+	err = t.QueryRow(query, orderID, orgID).Scan(&one)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -428,14 +464,19 @@ func (r *TourPackageRepository) TourPackageOrderExistsByOrgID(orgID, orderID str
 	return true, nil
 }
 
-func (r *TourPackageRepository) GetTourPackagePriceByID(orgID, priceID string) (float64, bool, error) {
+func (r *TourPackageRepository) GetTourPackagePriceByID(ctx context.Context, priceID string) (float64, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, false, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf(
 		"SELECT price FROM tour_package_prices WHERE uuid = %s AND organization_id = %s LIMIT 1",
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
 	var price sql.NullFloat64
-	err := database.QueryRow(r.db, query, priceID, orgID).Scan(&price)
+	err = t.QueryRow(query, priceID, orgID).Scan(&price)
 	if err == sql.ErrNoRows {
 		return 0, false, nil
 	}
@@ -448,7 +489,12 @@ func (r *TourPackageRepository) GetTourPackagePriceByID(orgID, priceID string) (
 	return price.Float64, true, nil
 }
 
-func (r *TourPackageRepository) GetTourPackageAddonTotalByIDs(orgID string, addonIDs []string) (float64, bool, error) {
+func (r *TourPackageRepository) GetTourPackageAddonTotalByIDs(ctx context.Context, addonIDs []string) (float64, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, false, err
+	}
+	orgID := t.OrganizationID()
 	if len(addonIDs) == 0 {
 		return 0, true, nil
 	}
@@ -470,28 +516,42 @@ func (r *TourPackageRepository) GetTourPackageAddonTotalByIDs(orgID string, addo
 	)
 	var cnt int
 	var total float64
-	if err := database.QueryRow(r.db, query, args...).Scan(&cnt, &total); err != nil {
+	if err := t.QueryRow(query, args...).Scan(&cnt, &total); err != nil {
 		return 0, false, err
 	}
 	return total, cnt == len(addonIDs), nil
 }
 
-func (r *TourPackageRepository) GetTourPackageOrderCountByOrgID(orgID string) (int, error) {
+func (r *TourPackageRepository) GetTourPackageOrderCountByOrgID(ctx context.Context) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT COUNT(*) FROM tour_package_orders WHERE organization_id = %s", r.getPlaceholder(1))
 	var count int
-	err := database.QueryRow(r.db, query, orgID).Scan(&count)
+	err = t.QueryRow(query, orgID).Scan(&count)
 	return count, err
 }
 
-func (r *TourPackageRepository) GetOrganizationCodeByOrgID(orgID string) (string, error) {
+func (r *TourPackageRepository) GetOrganizationCodeByOrgID(ctx context.Context) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
 	query := fmt.Sprintf("SELECT organization_code FROM organizations WHERE organization_id = %s", r.getPlaceholder(1))
 	var code string
-	err := database.QueryRow(r.db, query, orgID).Scan(&code)
+	err = t.QueryRow(query, orgID).Scan(&code)
 	return code, err
 }
 
 func (r *TourPackageRepository) CreateTourPackageOrder(ctx context.Context, in CreateTourPackageOrderInput) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -507,9 +567,7 @@ func (r *TourPackageRepository) CreateTourPackageOrder(ctx context.Context, in C
 		query += `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	}
 
-	if _, err := database.TxExecContext(
-		ctx,
-		tx,
+	if _, err := tx.Exec(
 		query,
 		orderUUID,
 		in.OrderID,
@@ -542,17 +600,25 @@ func (r *TourPackageRepository) CreateTourPackageOrder(ctx context.Context, in C
 			addonQuery += `(?, ?, ?, ?, ?)`
 		}
 		for _, addonID := range in.AddonIDs {
-			if _, err := database.TxExecContext(ctx, tx, addonQuery, in.OrderID, in.OrganizationID, addonID, now, in.UserID); err != nil {
+			if _, err := tx.Exec(addonQuery, in.OrderID, in.OrganizationID, addonID, now, in.UserID); err != nil {
 				return err
 			}
 		}
 	}
 
+	if err := redeemVoucher(tx, r.getPlaceholder, in.OrderID, model.VoucherOrderTourPackage, in.CustomerID, in.Voucher); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 func (r *TourPackageRepository) UpdateTourPackageOrder(ctx context.Context, in UpdateTourPackageOrderInput) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -597,9 +663,7 @@ func (r *TourPackageRepository) UpdateTourPackageOrder(ctx context.Context, in U
 		r.getPlaceholder(16),
 	)
 
-	res, err := database.TxExecContext(
-		ctx,
-		tx,
+	res, err := tx.Exec(
 		updateQuery,
 		in.TourPackageID,
 		in.CustomerID,
@@ -631,7 +695,7 @@ func (r *TourPackageRepository) UpdateTourPackageOrder(ctx context.Context, in U
 		r.getPlaceholder(1),
 		r.getPlaceholder(2),
 	)
-	if _, err := database.TxExecContext(ctx, tx, delQuery, in.OrderID, in.OrganizationID); err != nil {
+	if _, err := tx.Exec(delQuery, in.OrderID, in.OrganizationID); err != nil {
 		return err
 	}
 
@@ -643,7 +707,7 @@ func (r *TourPackageRepository) UpdateTourPackageOrder(ctx context.Context, in U
 			addonQuery += `(?, ?, ?, ?, ?)`
 		}
 		for _, addonID := range in.AddonIDs {
-			if _, err := database.TxExecContext(ctx, tx, addonQuery, in.OrderID, in.OrganizationID, addonID, now, in.UserID); err != nil {
+			if _, err := tx.Exec(addonQuery, in.OrderID, in.OrganizationID, addonID, now, in.UserID); err != nil {
 				return err
 			}
 		}
@@ -652,7 +716,12 @@ func (r *TourPackageRepository) UpdateTourPackageOrder(ctx context.Context, in U
 	return tx.Commit()
 }
 
-func (r *TourPackageRepository) GetTourPackageOrderDetail(ctx context.Context, orgID, orderID string) (map[string]interface{}, []map[string]interface{}, error) {
+func (r *TourPackageRepository) GetTourPackageOrderDetail(ctx context.Context, orderID string) (map[string]interface{}, []map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, nil, err
+	}
+	orgID := t.OrganizationID()
 	orderExpr := "order_id = " + r.getPlaceholder(1)
 	orgExpr := "organization_id = " + r.getPlaceholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -660,7 +729,7 @@ func (r *TourPackageRepository) GetTourPackageOrderDetail(ctx context.Context, o
 	}
 
 	query := "SELECT * FROM tour_package_orders WHERE " + orderExpr + " AND " + orgExpr + " LIMIT 1"
-	orderRows, err := database.QueryContext(ctx, r.db, query, orderID, orgID)
+	orderRows, err := t.Query(query, orderID, orgID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -688,7 +757,7 @@ func (r *TourPackageRepository) GetTourPackageOrderDetail(ctx context.Context, o
 		WHERE oa.order_id = %s AND %s AND %s
 		ORDER BY oa.created_at ASC
 	`, r.getPlaceholder(1), oaOrgExpr, aOrgExpr)
-	addonRows, err := database.QueryContext(ctx, r.db, addonQuery, orderID, orgID, orgID)
+	addonRows, err := t.Query(addonQuery, orderID, orgID, orgID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -701,7 +770,12 @@ func (r *TourPackageRepository) GetTourPackageOrderDetail(ctx context.Context, o
 	return orderMap, addons, nil
 }
 
-func (r *TourPackageRepository) GetCustomerInfoByOrgID(ctx context.Context, orgID, customerID string) (map[string]interface{}, error) {
+func (r *TourPackageRepository) GetCustomerInfoByOrgID(ctx context.Context, customerID string) (map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	custIDExpr := "customer_id = " + r.getPlaceholder(1)
 	orgExpr := "organization_id = " + r.getPlaceholder(2)
 	if r.driver == "postgres" || r.driver == "pgx" {
@@ -714,7 +788,7 @@ func (r *TourPackageRepository) GetCustomerInfoByOrgID(ctx context.Context, orgI
 		WHERE ` + custIDExpr + ` AND ` + orgExpr + `
 		LIMIT 1
 	`
-	rows, err := database.QueryContext(ctx, r.db, query, customerID, orgID)
+	rows, err := t.Query(query, customerID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -722,8 +796,13 @@ func (r *TourPackageRepository) GetCustomerInfoByOrgID(ctx context.Context, orgI
 	return scanSingleRowToMap(rows)
 }
 
-func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *model.CreateTourPackageRequest, packageID, orgID, userID string) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *model.CreateTourPackageRequest, packageID, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -739,7 +818,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		query += `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	}
 
-	_, err = database.TxExecContext(ctx, tx, query,
+	_, err = tx.Exec(query,
 		packageID,
 		req.PackageName,
 		req.PackageType,
@@ -752,7 +831,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		1, // Default status 1
 	)
 	if err != nil {
-		log.Printf("[ERROR] CreateTourPackage failed - Path: %s, Error: %v", ctx.Value("path"), err)
+		slog.ErrorContext(ctx, "create tour package failed", "path", ctx.Value("path"), "error", err)
 		return err
 	}
 
@@ -766,7 +845,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		}
 
 		for _, addon := range req.Addons {
-			_, err = database.TxExecContext(ctx, tx, addonQuery, uuid.New().String(), packageID, orgID, addon.Description, addon.Price, now, userID)
+			_, err = tx.Exec(addonQuery, uuid.New().String(), packageID, orgID, addon.Description, addon.Price, now, userID)
 			if err != nil {
 				return err
 			}
@@ -783,7 +862,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		}
 
 		for _, facility := range req.Facilities {
-			_, err = database.TxExecContext(ctx, tx, facilityQuery, uuid.New().String(), packageID, orgID, facility, now, userID)
+			_, err = tx.Exec(facilityQuery, uuid.New().String(), packageID, orgID, facility, now, userID)
 			if err != nil {
 				return err
 			}
@@ -801,7 +880,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 
 		for _, day := range req.Itineraries {
 			for _, act := range day.Activities {
-				_, err = database.TxExecContext(ctx, tx, itinQuery, uuid.New().String(), packageID, orgID, day.Day, act.Description, act.Location, act.City.ID, now, userID)
+				_, err = tx.Exec(itinQuery, uuid.New().String(), packageID, orgID, day.Day, act.Description, act.Location, act.City.ID, now, userID)
 				if err != nil {
 					return err
 				}
@@ -819,7 +898,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		}
 
 		for _, area := range req.PickupAreas {
-			_, err = database.TxExecContext(ctx, tx, pickupQuery, uuid.New().String(), packageID, orgID, area.ID, now, userID)
+			_, err = tx.Exec(pickupQuery, uuid.New().String(), packageID, orgID, area.ID, now, userID)
 			if err != nil {
 				return err
 			}
@@ -836,7 +915,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		}
 
 		for _, price := range req.Pricing {
-			_, err = database.TxExecContext(ctx, tx, priceQuery, uuid.New().String(), packageID, orgID, price.MinPax, price.MaxPax, price.Price, now, userID)
+			_, err = tx.Exec(priceQuery, uuid.New().String(), packageID, orgID, price.MinPax, price.MaxPax, price.Price, now, userID)
 			if err != nil {
 				return err
 			}
@@ -853,7 +932,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		}
 
 		for _, img := range req.Images {
-			_, err = database.TxExecContext(ctx, tx, imageQuery, uuid.New().String(), packageID, orgID, img, now, userID)
+			_, err = tx.Exec(imageQuery, uuid.New().String(), packageID, orgID, img, now, userID)
 			if err != nil {
 				return err
 			}
@@ -870,7 +949,7 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 		}
 
 		for _, sched := range req.Schedules {
-			_, err = database.TxExecContext(ctx, tx, scheduleQuery, uuid.New().String(), packageID, orgID, sched.StartDate, sched.EndDate, 1, 1, now, userID)
+			_, err = tx.Exec(scheduleQuery, uuid.New().String(), packageID, orgID, sched.StartDate, sched.EndDate, 1, 1, now, userID)
 			if err != nil {
 				return err
 			}
@@ -880,8 +959,13 @@ func (r *TourPackageRepository) CreateTourPackage(ctx context.Context, req *mode
 	return tx.Commit()
 }
 
-func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *model.UpdateTourPackageRequest, orgID, userID string) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *model.UpdateTourPackageRequest, userID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -903,9 +987,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 		r.getPlaceholder(9),
 	)
 
-	res, err := database.TxExecContext(
-		ctx,
-		tx,
+	res, err := tx.Exec(
 		updateQuery,
 		req.PackageName,
 		req.PackageType,
@@ -936,7 +1018,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				} else {
 					ins += `(?, ?, ?, ?, ?, ?, ?)`
 				}
-				if _, err := database.TxExecContext(ctx, tx, ins, newID, req.PackageID, orgID, it.Description, it.Price, now, userID); err != nil {
+				if _, err := tx.Exec(ins, newID, req.PackageID, orgID, it.Description, it.Price, now, userID); err != nil {
 					return err
 				}
 				keep = append(keep, newID)
@@ -945,7 +1027,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 			upd := `UPDATE tour_package_addons SET description = %s, price = %s WHERE uuid = %s AND package_id = %s AND organization_id = %s`
 			upd = fmt.Sprintf(upd, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5))
-			if _, err := database.TxExecContext(ctx, tx, upd, it.Description, it.Price, it.UUID, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(upd, it.Description, it.Price, it.UUID, req.PackageID, orgID); err != nil {
 				return err
 			}
 			keep = append(keep, it.UUID)
@@ -953,7 +1035,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 		if len(keep) == 0 {
 			del := fmt.Sprintf("DELETE FROM tour_package_addons WHERE package_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExecContext(ctx, tx, del, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(del, req.PackageID, orgID); err != nil {
 				return err
 			}
 		} else {
@@ -965,7 +1047,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				args = append(args, id)
 			}
 			del := fmt.Sprintf("DELETE FROM tour_package_addons WHERE package_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(ph, ","))
-			if _, err := database.TxExecContext(ctx, tx, del, args...); err != nil {
+			if _, err := tx.Exec(del, args...); err != nil {
 				return err
 			}
 		}
@@ -982,7 +1064,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				} else {
 					ins += `(?, ?, ?, ?, ?, ?)`
 				}
-				if _, err := database.TxExecContext(ctx, tx, ins, newID, req.PackageID, orgID, it.Facility, now, userID); err != nil {
+				if _, err := tx.Exec(ins, newID, req.PackageID, orgID, it.Facility, now, userID); err != nil {
 					return err
 				}
 				keep = append(keep, newID)
@@ -990,7 +1072,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 			}
 			upd := `UPDATE tour_package_facilities SET facility = %s, updated_at = %s, updated_by = %s WHERE uuid = %s AND package_id = %s AND organization_id = %s`
 			upd = fmt.Sprintf(upd, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-			if _, err := database.TxExecContext(ctx, tx, upd, it.Facility, now, userID, it.UUID, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(upd, it.Facility, now, userID, it.UUID, req.PackageID, orgID); err != nil {
 				return err
 			}
 			keep = append(keep, it.UUID)
@@ -998,7 +1080,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 		if len(keep) == 0 {
 			del := fmt.Sprintf("DELETE FROM tour_package_facilities WHERE package_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExecContext(ctx, tx, del, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(del, req.PackageID, orgID); err != nil {
 				return err
 			}
 		} else {
@@ -1010,7 +1092,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				args = append(args, id)
 			}
 			del := fmt.Sprintf("DELETE FROM tour_package_facilities WHERE package_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(ph, ","))
-			if _, err := database.TxExecContext(ctx, tx, del, args...); err != nil {
+			if _, err := tx.Exec(del, args...); err != nil {
 				return err
 			}
 		}
@@ -1027,7 +1109,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				} else {
 					ins += `(?, ?, ?, ?, ?, ?)`
 				}
-				if _, err := database.TxExecContext(ctx, tx, ins, newID, req.PackageID, orgID, it.ID, now, userID); err != nil {
+				if _, err := tx.Exec(ins, newID, req.PackageID, orgID, it.ID, now, userID); err != nil {
 					return err
 				}
 				keep = append(keep, newID)
@@ -1035,7 +1117,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 			}
 			upd := `UPDATE tour_package_pickup SET city_id = %s, updated_at = %s, updated_by = %s WHERE uuid = %s AND package_id = %s AND organization_id = %s`
 			upd = fmt.Sprintf(upd, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6))
-			if _, err := database.TxExecContext(ctx, tx, upd, it.ID, now, userID, it.UUID, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(upd, it.ID, now, userID, it.UUID, req.PackageID, orgID); err != nil {
 				return err
 			}
 			keep = append(keep, it.UUID)
@@ -1043,7 +1125,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 		if len(keep) == 0 {
 			del := fmt.Sprintf("DELETE FROM tour_package_pickup WHERE package_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExecContext(ctx, tx, del, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(del, req.PackageID, orgID); err != nil {
 				return err
 			}
 		} else {
@@ -1055,7 +1137,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				args = append(args, id)
 			}
 			del := fmt.Sprintf("DELETE FROM tour_package_pickup WHERE package_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(ph, ","))
-			if _, err := database.TxExecContext(ctx, tx, del, args...); err != nil {
+			if _, err := tx.Exec(del, args...); err != nil {
 				return err
 			}
 		}
@@ -1072,7 +1154,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				} else {
 					ins += `(?, ?, ?, ?, ?, ?, ?, ?)`
 				}
-				if _, err := database.TxExecContext(ctx, tx, ins, newID, req.PackageID, orgID, it.MinPax, it.MaxPax, it.Price, now, userID); err != nil {
+				if _, err := tx.Exec(ins, newID, req.PackageID, orgID, it.MinPax, it.MaxPax, it.Price, now, userID); err != nil {
 					return err
 				}
 				keep = append(keep, newID)
@@ -1080,7 +1162,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 			}
 			upd := `UPDATE tour_package_prices SET min_pax = %s, max_pax = %s, price = %s, updated_at = %s, updated_by = %s WHERE uuid = %s AND package_id = %s AND organization_id = %s`
 			upd = fmt.Sprintf(upd, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8))
-			if _, err := database.TxExecContext(ctx, tx, upd, it.MinPax, it.MaxPax, it.Price, now, userID, it.UUID, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(upd, it.MinPax, it.MaxPax, it.Price, now, userID, it.UUID, req.PackageID, orgID); err != nil {
 				return err
 			}
 			keep = append(keep, it.UUID)
@@ -1088,7 +1170,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 		if len(keep) == 0 {
 			del := fmt.Sprintf("DELETE FROM tour_package_prices WHERE package_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExecContext(ctx, tx, del, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(del, req.PackageID, orgID); err != nil {
 				return err
 			}
 		} else {
@@ -1100,7 +1182,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				args = append(args, id)
 			}
 			del := fmt.Sprintf("DELETE FROM tour_package_prices WHERE package_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(ph, ","))
-			if _, err := database.TxExecContext(ctx, tx, del, args...); err != nil {
+			if _, err := tx.Exec(del, args...); err != nil {
 				return err
 			}
 		}
@@ -1117,7 +1199,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				} else {
 					ins += `(?, ?, ?, ?, ?, ?)`
 				}
-				if _, err := database.TxExecContext(ctx, tx, ins, newID, req.PackageID, orgID, it.ImagePath, now, userID); err != nil {
+				if _, err := tx.Exec(ins, newID, req.PackageID, orgID, it.ImagePath, now, userID); err != nil {
 					return err
 				}
 				keep = append(keep, newID)
@@ -1125,7 +1207,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 			}
 			upd := `UPDATE tour_package_images SET image_path = %s WHERE uuid = %s AND package_id = %s AND organization_id = %s`
 			upd = fmt.Sprintf(upd, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
-			if _, err := database.TxExecContext(ctx, tx, upd, it.ImagePath, it.UUID, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(upd, it.ImagePath, it.UUID, req.PackageID, orgID); err != nil {
 				return err
 			}
 			keep = append(keep, it.UUID)
@@ -1133,7 +1215,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 		if len(keep) == 0 {
 			del := fmt.Sprintf("DELETE FROM tour_package_images WHERE package_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExecContext(ctx, tx, del, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(del, req.PackageID, orgID); err != nil {
 				return err
 			}
 		} else {
@@ -1145,7 +1227,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				args = append(args, id)
 			}
 			del := fmt.Sprintf("DELETE FROM tour_package_images WHERE package_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(ph, ","))
-			if _, err := database.TxExecContext(ctx, tx, del, args...); err != nil {
+			if _, err := tx.Exec(del, args...); err != nil {
 				return err
 			}
 		}
@@ -1163,7 +1245,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 					} else {
 						ins += `(?, ?, ?, ?, ?, ?, ?, ?, ?)`
 					}
-					if _, err := database.TxExecContext(ctx, tx, ins, newID, req.PackageID, orgID, day.Day, act.Description, act.Location, act.City.ID, now, userID); err != nil {
+					if _, err := tx.Exec(ins, newID, req.PackageID, orgID, day.Day, act.Description, act.Location, act.City.ID, now, userID); err != nil {
 						return err
 					}
 					keep = append(keep, newID)
@@ -1172,7 +1254,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 				upd := `UPDATE tour_package_itineraries SET day = %s, activity = %s, location = %s, city_id = %s, updated_at = %s, updated_by = %s WHERE uuid = %s AND package_id = %s AND organization_id = %s`
 				upd = fmt.Sprintf(upd, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4), r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9))
-				if _, err := database.TxExecContext(ctx, tx, upd, day.Day, act.Description, act.Location, act.City.ID, now, userID, act.UUID, req.PackageID, orgID); err != nil {
+				if _, err := tx.Exec(upd, day.Day, act.Description, act.Location, act.City.ID, now, userID, act.UUID, req.PackageID, orgID); err != nil {
 					return err
 				}
 				keep = append(keep, act.UUID)
@@ -1181,7 +1263,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 
 		if len(keep) == 0 {
 			del := fmt.Sprintf("DELETE FROM tour_package_itineraries WHERE package_id = %s AND organization_id = %s", r.getPlaceholder(1), r.getPlaceholder(2))
-			if _, err := database.TxExecContext(ctx, tx, del, req.PackageID, orgID); err != nil {
+			if _, err := tx.Exec(del, req.PackageID, orgID); err != nil {
 				return err
 			}
 		} else {
@@ -1193,7 +1275,7 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 				args = append(args, id)
 			}
 			del := fmt.Sprintf("DELETE FROM tour_package_itineraries WHERE package_id = %s AND organization_id = %s AND uuid NOT IN (%s)", r.getPlaceholder(1), r.getPlaceholder(2), strings.Join(ph, ","))
-			if _, err := database.TxExecContext(ctx, tx, del, args...); err != nil {
+			if _, err := tx.Exec(del, args...); err != nil {
 				return err
 			}
 		}
@@ -1202,7 +1284,12 @@ func (r *TourPackageRepository) UpdateTourPackage(ctx context.Context, req *mode
 	return tx.Commit()
 }
 
-func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID, packageID string) (*model.TourPackageDetailResponse, error) {
+func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, packageID string) (*model.TourPackageDetailResponse, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	detail := &model.TourPackageDetailResponse{
 		Schedules:    []model.TourPackageScheduleItem{},
 		Pricing:      []model.TourPackagePricing{},
@@ -1235,7 +1322,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		status        sql.NullInt64
 	)
 
-	err := database.QueryRowContext(ctx, r.db, metaQuery, packageID, orgID).Scan(
+	err = t.QueryRow(metaQuery, packageID, orgID).Scan(
 		&metaPackageID,
 		&packageName,
 		&packageType,
@@ -1276,7 +1363,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY date_start ASC
 	`
 	scheduleQuery = fmt.Sprintf(scheduleQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	scheduleRows, err := database.QueryContext(ctx, r.db, scheduleQuery, packageID, orgID)
+	scheduleRows, err := t.Query(scheduleQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1299,7 +1386,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY min_pax ASC, max_pax ASC
 	`
 	priceQuery = fmt.Sprintf(priceQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	priceRows, err := database.QueryContext(ctx, r.db, priceQuery, packageID, orgID)
+	priceRows, err := t.Query(priceQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1326,7 +1413,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY city_id ASC
 	`
 	pickupQuery = fmt.Sprintf(pickupQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	pickupRows, err := database.QueryContext(ctx, r.db, pickupQuery, packageID, orgID)
+	pickupRows, err := t.Query(pickupQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1346,7 +1433,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY created_at ASC
 	`
 	imageQuery = fmt.Sprintf(imageQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	imageRows, err := database.QueryContext(ctx, r.db, imageQuery, packageID, orgID)
+	imageRows, err := t.Query(imageQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1368,7 +1455,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY created_at ASC
 	`
 	itinQuery = fmt.Sprintf(itinQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	itinRows, err := database.QueryContext(ctx, r.db, itinQuery, packageID, orgID)
+	itinRows, err := t.Query(itinQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1401,7 +1488,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY created_at ASC
 	`
 	facilityQuery = fmt.Sprintf(facilityQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	facilityRows, err := database.QueryContext(ctx, r.db, facilityQuery, packageID, orgID)
+	facilityRows, err := t.Query(facilityQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1423,7 +1510,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY city_id ASC
 	`
 	destQuery = fmt.Sprintf(destQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	destRows, err := database.QueryContext(ctx, r.db, destQuery, packageID, orgID)
+	destRows, err := t.Query(destQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1447,7 +1534,7 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 		ORDER BY created_at ASC
 	`
 	addonQuery = fmt.Sprintf(addonQuery, r.getPlaceholder(1), r.getPlaceholder(2))
-	addonRows, err := database.QueryContext(ctx, r.db, addonQuery, packageID, orgID)
+	addonRows, err := t.Query(addonQuery, packageID, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -1469,7 +1556,12 @@ func (r *TourPackageRepository) GetTourPackageDetail(ctx context.Context, orgID,
 	return detail, nil
 }
 
-func (r *TourPackageRepository) GetPublicTourPackages(ctx context.Context, orgID string) ([]*model.TourPackageListPublicItem, error) {
+func (r *TourPackageRepository) GetPublicTourPackages(ctx context.Context) ([]*model.TourPackageListPublicItem, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	query := `
 		SELECT 
 			tp.uuid AS package_id,
@@ -1487,11 +1579,11 @@ func (r *TourPackageRepository) GetPublicTourPackages(ctx context.Context, orgID
 			STRING_AGG(DISTINCT tpi.city_id::text, ', ') AS city_ids
 		FROM tour_packages tp
 		LEFT JOIN tour_package_prices tpp 
-			ON tpp.package_id = tp.uuid
+			ON tpp.package_id = tp.uuid AND tpp.organization_id = tp.organization_id
 		LEFT JOIN tour_package_schedules tps
-			ON tps.package_id = tp.uuid
+			ON tps.package_id = tp.uuid AND tps.organization_id = tp.organization_id
 		LEFT JOIN tour_package_itineraries tpi
-    		ON tpi.package_id = tp.uuid
+    		ON tpi.package_id = tp.uuid AND tpi.organization_id = tp.organization_id
 		WHERE tp.organization_id = %s 
 		  AND tp.status = 1 AND tp.active = true
 		GROUP BY tp.uuid, tp.package_name, tp.package_type, tp.thumbnail, tp.package_description, tp.status, tp.active
@@ -1500,7 +1592,7 @@ func (r *TourPackageRepository) GetPublicTourPackages(ctx context.Context, orgID
 	// Set query placeholder
 	query = fmt.Sprintf(query, r.getPlaceholder(1))
 
-	rows, err := database.Query(r.db, query, orgID)
+	rows, err := t.Query(query, orgID)
 	if err != nil {
 		return nil, err
 	}
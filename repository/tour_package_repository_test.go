@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"testing"
+)
+
+func TestTourPackageRepositoryIsTenantScoped(t *testing.T) {
+	r := NewTourPackageRepository(openTenantFake(t), "postgres")
+	const packageOfB, packagePriceOfB, packageAddonOfB = "package-of-b", "package-price-of-b", "package-addon-of-b"
+	checkTenantIsolation(t, tenantCalls{
+		"SoftDeleteTourPackage": func(ctx context.Context) error {
+			return r.SoftDeleteTourPackage(ctx, employeeOfB, packageOfB)
+		},
+		"SetTourPackageActiveStatus": func(ctx context.Context) error {
+			return r.SetTourPackageActiveStatus(ctx, employeeOfB, packageOfB, false)
+		},
+		"GetTourPackagesByOrgID": func(ctx context.Context) error { _, err := r.GetTourPackagesByOrgID(ctx); return err },
+		"ListTourPackageOrders":  func(ctx context.Context) error { _, err := r.ListTourPackageOrders(ctx); return err },
+		"CustomerExistsByOrgID": func(ctx context.Context) error {
+			_, err := r.CustomerExistsByOrgID(ctx, customerOfB)
+			return err
+		},
+		"TourPackageExistsByOrgID": func(ctx context.Context) error {
+			_, err := r.TourPackageExistsByOrgID(ctx, packageOfB)
+			return err
+		},
+		"TourPackageOrderExistsByOrgID": func(ctx context.Context) error {
+			_, err := r.TourPackageOrderExistsByOrgID(ctx, orderOfB)
+			return err
+		},
+		"GetTourPackagePriceByID": func(ctx context.Context) error {
+			_, _, err := r.GetTourPackagePriceByID(ctx, packagePriceOfB)
+			return err
+		},
+		"GetTourPackageAddonTotalByIDs": func(ctx context.Context) error {
+			_, _, err := r.GetTourPackageAddonTotalByIDs(ctx, []string{packageAddonOfB})
+			return err
+		},
+		"GetTourPackageOrderCountByOrgID": func(ctx context.Context) error {
+			_, err := r.GetTourPackageOrderCountByOrgID(ctx)
+			return err
+		},
+		"GetOrganizationCodeByOrgID": func(ctx context.Context) error {
+			_, err := r.GetOrganizationCodeByOrgID(ctx)
+			return err
+		},
+		"GetTourPackageOrderDetail": func(ctx context.Context) error {
+			_, _, err := r.GetTourPackageOrderDetail(ctx, orderOfB)
+			return err
+		},
+		"GetCustomerInfoByOrgID": func(ctx context.Context) error {
+			_, err := r.GetCustomerInfoByOrgID(ctx, customerOfB)
+			return err
+		},
+		"CreateTourPackage": func(ctx context.Context) error {
+			return r.CreateTourPackage(ctx, &model.CreateTourPackageRequest{PackageName: "Bromo"}, packageOfB, employeeOfB)
+		},
+		"UpdateTourPackage": func(ctx context.Context) error {
+			return r.UpdateTourPackage(ctx, &model.UpdateTourPackageRequest{PackageID: packageOfB, PackageName: "Bromo"}, employeeOfB)
+		},
+		"GetTourPackageDetail": func(ctx context.Context) error {
+			_, err := r.GetTourPackageDetail(ctx, packageOfB)
+			return err
+		},
+		"GetPublicTourPackages": func(ctx context.Context) error { _, err := r.GetPublicTourPackages(ctx); return err },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"CreateTourPackageOrder": func() error {
+			return r.CreateTourPackageOrder(ctxA, CreateTourPackageOrderInput{OrderID: orderOfB, OrganizationID: tenantB, TourPackageID: packageOfB})
+		},
+		"UpdateTourPackageOrder": func() error {
+			return r.UpdateTourPackageOrder(ctxA, UpdateTourPackageOrderInput{OrderID: orderOfB, OrganizationID: tenantB, TourPackageID: packageOfB})
+		},
+	})
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"service-travego/database"
 	"service-travego/model"
 	"service-travego/utils"
@@ -31,15 +33,20 @@ func (r *TransactionRepository) getPlaceholder(pos int) string {
 	return "?"
 }
 
-func (r *TransactionRepository) ListAllRevenue(orgID string, req *model.TransactionListRequest) ([]model.TransactionListRow, error) {
-	return r.listTransactions(orgID, 1, req)
+func (r *TransactionRepository) ListAllRevenue(ctx context.Context, req *model.TransactionListRequest) ([]model.TransactionListRow, error) {
+	return r.listTransactions(ctx, 1, req)
 }
 
-func (r *TransactionRepository) ListAllExpenses(orgID string, req *model.TransactionListRequest) ([]model.TransactionListRow, error) {
-	return r.listTransactions(orgID, 2, req)
+func (r *TransactionRepository) ListAllExpenses(ctx context.Context, req *model.TransactionListRequest) ([]model.TransactionListRow, error) {
+	return r.listTransactions(ctx, 2, req)
 }
 
-func (r *TransactionRepository) listTransactions(orgID string, TransactionItem int, req *model.TransactionListRequest) ([]model.TransactionListRow, error) {
+func (r *TransactionRepository) listTransactions(ctx context.Context, TransactionItem int, req *model.TransactionListRequest) ([]model.TransactionListRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	where := make([]string, 0, 8)
 	args := make([]interface{}, 0, 8)
 
@@ -90,7 +97,7 @@ func (r *TransactionRepository) listTransactions(orgID string, TransactionItem i
 		WHERE t.status = 1 AND %s
 		ORDER BY t.created_at DESC
 	`, strings.Join(where, " AND "))
-	rows, err := database.Query(r.db, query, args...)
+	rows, err := t.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +137,12 @@ func (r *TransactionRepository) listTransactions(orgID string, TransactionItem i
 	return out, nil
 }
 
-func (r *TransactionRepository) DeleteFleetTripExpense(orgID, scheduleNumber, transactionTripID string) error {
+func (r *TransactionRepository) DeleteFleetTripExpense(ctx context.Context, scheduleNumber, transactionTripID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	placeholder := r.getPlaceholder
 
 	transactionTripIDExpr := "transaction_trip_id = " + placeholder(1)
@@ -147,7 +159,7 @@ func (r *TransactionRepository) DeleteFleetTripExpense(orgID, scheduleNumber, tr
 		WHERE %s AND %s AND %s
 	`, transactionTripIDExpr, scheduleNumberExpr, orgExpr)
 
-	result, err := r.db.Exec(query, transactionTripID, scheduleNumber, orgID)
+	result, err := t.Exec(query, transactionTripID, scheduleNumber, orgID)
 	if err != nil {
 		return err
 	}
@@ -196,21 +208,26 @@ type UpdateExpenseTransactionRequest struct {
 	TransactionItem     string
 }
 
-func (r *TransactionRepository) CreateManualTransaction(orgID, userID string, req *CreateManualTransactionRequest) error {
-	tx, err := r.db.Begin()
+func (r *TransactionRepository) CreateManualTransaction(ctx context.Context, userID string, req *CreateManualTransactionRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
 	if err != nil {
-		return err
+		return "", err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
+	if err != nil {
+		return "", err
 	}
 	defer tx.Rollback()
 
 	transactionID, err := uuid.NewV7()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumberTx(tx, r.driver, orgID, 3, time.Now())
+	invoiceNumber, err := utils.GenerateTenantInvoiceNumber(tx, r.driver, 3, time.Now())
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	placeholder := r.getPlaceholder
@@ -273,7 +290,7 @@ func (r *TransactionRepository) CreateManualTransaction(orgID, userID string, re
 
 	_, err = tx.Exec(query, args...)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Logic for transaction_orders and transaction_fleets
@@ -282,7 +299,7 @@ func (r *TransactionRepository) CreateManualTransaction(orgID, userID string, re
 		case 1, 2:
 			transactionOrderID, err := uuid.NewV7()
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			queryOrder := fmt.Sprintf(`
@@ -307,12 +324,12 @@ func (r *TransactionRepository) CreateManualTransaction(orgID, userID string, re
 				userID,
 			)
 			if err != nil {
-				return err
+				return "", err
 			}
 		case 4:
 			transactionFleetID, err := uuid.NewV7()
 			if err != nil {
-				return err
+				return "", err
 			}
 
 			queryFleet := fmt.Sprintf(`
@@ -337,19 +354,26 @@ func (r *TransactionRepository) CreateManualTransaction(orgID, userID string, re
 				userID,
 			)
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return transactionID.String(), nil
 }
 
-func (r *TransactionRepository) ValidateFleetUnit(unitID, orgID string) (string, string, error) {
+func (r *TransactionRepository) ValidateFleetUnit(ctx context.Context, unitID string) (string, string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", "", err
+	}
+	orgID := t.OrganizationID()
 	unitID = strings.TrimSpace(unitID)
-	orgID = strings.TrimSpace(orgID)
-	if unitID == "" || orgID == "" {
-		return "", "", fmt.Errorf("unit_id and organization_id are required")
+	if unitID == "" {
+		return "", "", fmt.Errorf("unit_id is required")
 	}
 
 	placeholder := r.getPlaceholder
@@ -361,13 +385,13 @@ func (r *TransactionRepository) ValidateFleetUnit(unitID, orgID string) (string,
 	query := fmt.Sprintf(`
 		SELECT f.fleet_name, fu.vehicle_id
 		FROM fleet_units fu
-		INNER JOIN fleets f ON f.uuid = fu.fleet_id
+		INNER JOIN fleets f ON f.uuid = fu.fleet_id AND f.organization_id = fu.organization_id
 		WHERE fu.unit_id = %s AND %s
 		LIMIT 1
 	`, placeholder(1), orgExpr)
 
 	var fleetName, vehicleID string
-	err := database.QueryRow(r.db, query, unitID, orgID).Scan(&fleetName, &vehicleID)
+	err = t.QueryRow(query, unitID, orgID).Scan(&fleetName, &vehicleID)
 	if err == sql.ErrNoRows {
 		return "", "", fmt.Errorf("fleet unit not found")
 	}
@@ -377,34 +401,39 @@ func (r *TransactionRepository) ValidateFleetUnit(unitID, orgID string) (string,
 	return fleetName, vehicleID, nil
 }
 
-func (r *TransactionRepository) CreateExpenseTransaction(orgID, userID string, req *CreateExpenseTransactionRequest) error {
+func (r *TransactionRepository) CreateExpenseTransaction(ctx context.Context, userID string, req *CreateExpenseTransactionRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	orgID := t.OrganizationID()
 	orderType := 4
 	description := req.Description
 	note := ""
 	if strings.TrimSpace(req.UnitID) != "" {
 		orderType = 1
-		fleetName, vehicleID, err := r.ValidateFleetUnit(req.UnitID, orgID)
+		fleetName, vehicleID, err := r.ValidateFleetUnit(ctx, req.UnitID)
 		if err != nil {
-			return err
+			return "", err
 		}
 		note = fleetName + " - " + vehicleID
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := t.Begin()
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer tx.Rollback()
 
 	now := time.Now()
 	transactionID, err := uuid.NewV7()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumberTx(tx, r.driver, orgID, orderType, now)
+	invoiceNumber, err := utils.GenerateTenantInvoiceNumber(tx, r.driver, orderType, now)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	placeholder := r.getPlaceholder
@@ -457,13 +486,13 @@ func (r *TransactionRepository) CreateExpenseTransaction(orgID, userID string, r
 		note,
 	)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if strings.TrimSpace(req.UnitID) != "" {
 		transactionFleetID, err := uuid.NewV7()
 		if err != nil {
-			return err
+			return "", err
 		}
 		queryFleet := fmt.Sprintf(`
 			INSERT INTO transaction_fleets (
@@ -487,14 +516,22 @@ func (r *TransactionRepository) CreateExpenseTransaction(orgID, userID string, r
 			userID,
 		)
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return transactionID.String(), nil
 }
 
-func (r *TransactionRepository) SoftDeleteExpenseTransaction(orgID, transactionID string) error {
+func (r *TransactionRepository) SoftDeleteExpenseTransaction(ctx context.Context, transactionID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	placeholder := r.getPlaceholder
 
 	transactionIDExpr := "transaction_id = " + placeholder(1)
@@ -510,7 +547,7 @@ func (r *TransactionRepository) SoftDeleteExpenseTransaction(orgID, transactionI
 		WHERE %s AND %s AND transaction_type = %s AND COALESCE(status, 1) <> 0
 	`, transactionIDExpr, orgExpr, placeholder(3))
 
-	result, err := r.db.Exec(query, transactionID, orgID, 2)
+	result, err := t.Exec(query, transactionID, orgID, 2)
 	if err != nil {
 		return err
 	}
@@ -525,8 +562,60 @@ func (r *TransactionRepository) SoftDeleteExpenseTransaction(orgID, transactionI
 	return nil
 }
 
-func (r *TransactionRepository) UpdateExpenseTransaction(orgID, userID string, req *UpdateExpenseTransactionRequest) error {
-	tx, err := r.db.Begin()
+// GetTransactionFields returns the editable fields of a transaction, used to
+// describe it in the audit log
+func (r *TransactionRepository) GetTransactionFields(ctx context.Context, transactionID string) (map[string]interface{}, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
+	placeholder := r.getPlaceholder
+	transactionIDExpr := "transaction_id = " + placeholder(1)
+	orgExpr := "organization_id = " + placeholder(2)
+	if r.driver == "postgres" || r.driver == "pgx" {
+		transactionIDExpr = "transaction_id::text = " + placeholder(1)
+		orgExpr = "organization_id::text = " + placeholder(2)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT order_type, COALESCE(transaction_category, ''), COALESCE(transaction_item, ''), amount,
+			transaction_date, COALESCE(payment_method, 0), COALESCE(description, ''), COALESCE(status, 1)
+		FROM transactions
+		WHERE %s AND %s
+	`, transactionIDExpr, orgExpr)
+
+	var orderType, paymentMethod, status int
+	var category, item, description string
+	var amount float64
+	var transactionDate sql.NullTime
+	err = t.QueryRow(query, transactionID, orgID).Scan(&orderType, &category, &item, &amount,
+		&transactionDate, &paymentMethod, &description, &status)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{
+		"order_type":           orderType,
+		"transaction_category": category,
+		"transaction_item":     item,
+		"amount":               amount,
+		"payment_method":       paymentMethod,
+		"description":          description,
+		"status":               status,
+	}
+	if transactionDate.Valid {
+		fields["transaction_date"] = transactionDate.Time.Format("2006-01-02")
+	}
+	return fields, nil
+}
+
+func (r *TransactionRepository) UpdateExpenseTransaction(ctx context.Context, userID string, req *UpdateExpenseTransactionRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -654,10 +743,14 @@ func (r *TransactionRepository) UpdateExpenseTransaction(orgID, userID string, r
 	return tx.Commit()
 }
 
-func (r *TransactionRepository) GetFleetOrderIDByScheduleNumber(scheduleNumber, orgID string) (string, bool, error) {
+func (r *TransactionRepository) GetFleetOrderIDByScheduleNumber(ctx context.Context, scheduleNumber string) (string, bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", false, err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
-	orgID = strings.TrimSpace(orgID)
-	if scheduleNumber == "" || orgID == "" {
+	if scheduleNumber == "" {
 		return "", false, nil
 	}
 
@@ -675,7 +768,7 @@ func (r *TransactionRepository) GetFleetOrderIDByScheduleNumber(scheduleNumber,
 	`, placeholder(1), orgExpr)
 
 	var orderID string
-	err := database.QueryRow(r.db, query, scheduleNumber, orgID).Scan(&orderID)
+	err = t.QueryRow(query, scheduleNumber, orgID).Scan(&orderID)
 	if err == sql.ErrNoRows {
 		return "", false, nil
 	}
@@ -685,8 +778,13 @@ func (r *TransactionRepository) GetFleetOrderIDByScheduleNumber(scheduleNumber,
 	return orderID, true, nil
 }
 
-func (r *TransactionRepository) CreateFleetTripOperationalExpenseTransaction(orgID, userID, orderID, scheduleNumber string, amount float64, description string) error {
-	tx, err := r.db.Begin()
+func (r *TransactionRepository) CreateFleetTripOperationalExpenseTransaction(ctx context.Context, userID, orderID, scheduleNumber string, amount float64, description string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -698,7 +796,7 @@ func (r *TransactionRepository) CreateFleetTripOperationalExpenseTransaction(org
 		return err
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumberTx(tx, r.driver, orgID, 1, now)
+	invoiceNumber, err := utils.GenerateTenantInvoiceNumber(tx, r.driver, 1, now)
 	if err != nil {
 		return err
 	}
@@ -761,7 +859,12 @@ func (r *TransactionRepository) CreateFleetTripOperationalExpenseTransaction(org
 	return tx.Commit()
 }
 
-func (r *TransactionRepository) CreateFleetTripExpenseTransaction(orgID, userID, orderID, scheduleNumber, transactionItem string, paymentMethod int, status int, amount float64, description string) error {
+func (r *TransactionRepository) CreateFleetTripExpenseTransaction(ctx context.Context, userID, orderID, scheduleNumber, transactionItem string, paymentMethod int, status int, amount float64, description string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	now := time.Now()
 	transactionTripID, err := uuid.NewV7()
 	if err != nil {
@@ -795,7 +898,7 @@ func (r *TransactionRepository) CreateFleetTripExpenseTransaction(orgID, userID,
 		placeholder(11), placeholder(12), placeholder(13),
 	)
 
-	_, err = r.db.Exec(
+	_, err = t.Exec(
 		query,
 		transactionTripID.String(),
 		scheduleNumber,
@@ -814,7 +917,12 @@ func (r *TransactionRepository) CreateFleetTripExpenseTransaction(orgID, userID,
 	return err
 }
 
-func (r *TransactionRepository) SumTransactionsAmountByReferenceID(referenceID string) (float64, error) {
+func (r *TransactionRepository) SumTransactionsAmountByReferenceID(ctx context.Context, referenceID string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	referenceID = strings.TrimSpace(referenceID)
 	if referenceID == "" {
 		return 0, nil
@@ -824,18 +932,23 @@ func (r *TransactionRepository) SumTransactionsAmountByReferenceID(referenceID s
 	query := fmt.Sprintf(`
 		SELECT COALESCE(SUM(amount), 0) AS total_amount
 		FROM transactions
-		WHERE reference_id = %s
-	`, placeholder(1))
+		WHERE reference_id = %s AND organization_id = %s
+	`, placeholder(1), placeholder(2))
 
 	var total float64
-	err := database.QueryRow(r.db, query, referenceID).Scan(&total)
+	err = t.QueryRow(query, referenceID, orgID).Scan(&total)
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
-func (r *TransactionRepository) SumFleetTripAmountByScheduleNumberAndPaymentMethod(scheduleNumber string) (map[int]float64, error) {
+func (r *TransactionRepository) SumFleetTripAmountByScheduleNumberAndPaymentMethod(ctx context.Context, scheduleNumber string) (map[int]float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return map[int]float64{}, nil
@@ -845,11 +958,11 @@ func (r *TransactionRepository) SumFleetTripAmountByScheduleNumberAndPaymentMeth
 	query := fmt.Sprintf(`
 		SELECT COALESCE(SUM(amount), 0) AS total_amount, COALESCE(payment_type, 0) AS payment_method
 		FROM transaction_fleet_trips
-		WHERE schedule_number = %s
+		WHERE schedule_number = %s AND organization_id = %s
 		GROUP BY COALESCE(payment_type, 0)
-	`, placeholder(1))
+	`, placeholder(1), placeholder(2))
 
-	rows, err := database.Query(r.db, query, scheduleNumber)
+	rows, err := t.Query(query, scheduleNumber, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -870,11 +983,15 @@ func (r *TransactionRepository) SumFleetTripAmountByScheduleNumberAndPaymentMeth
 	return out, nil
 }
 
-func (r *TransactionRepository) GetFleetTripAmountSummary(scheduleNumber, orgID string) (model.FleetTripAmountSummary, error) {
+func (r *TransactionRepository) GetFleetTripAmountSummary(ctx context.Context, scheduleNumber string) (model.FleetTripAmountSummary, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return model.FleetTripAmountSummary{}, err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
-	orgID = strings.TrimSpace(orgID)
 	result := model.FleetTripAmountSummary{}
-	if scheduleNumber == "" || orgID == "" {
+	if scheduleNumber == "" {
 		return result, nil
 	}
 
@@ -908,7 +1025,7 @@ func (r *TransactionRepository) GetFleetTripAmountSummary(scheduleNumber, orgID
 		WHERE schedule_number = %s AND organization_id = %s
 	`, placeholder(1), placeholder(2))
 
-	err := database.QueryRow(r.db, query, scheduleNumber, orgID).Scan(
+	err = t.QueryRow(query, scheduleNumber, orgID).Scan(
 		&result.TotalExpenses,
 		&result.TotalClaimed,
 		&result.TotalReimburse,
@@ -924,7 +1041,12 @@ func (r *TransactionRepository) GetFleetTripAmountSummary(scheduleNumber, orgID
 	return result, nil
 }
 
-func (r *TransactionRepository) SumFleetTripAmountByScheduleNumber(scheduleNumber string) (float64, error) {
+func (r *TransactionRepository) SumFleetTripAmountByScheduleNumber(ctx context.Context, scheduleNumber string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return 0, nil
@@ -934,21 +1056,25 @@ func (r *TransactionRepository) SumFleetTripAmountByScheduleNumber(scheduleNumbe
 	query := fmt.Sprintf(`
 		SELECT COALESCE(SUM(amount), 0) AS total_expenses
 		FROM transaction_fleet_trips
-		WHERE schedule_number = %s
-	`, placeholder(1))
+		WHERE schedule_number = %s AND organization_id = %s
+	`, placeholder(1), placeholder(2))
 
 	var total float64
-	err := database.QueryRow(r.db, query, scheduleNumber).Scan(&total)
+	err = t.QueryRow(query, scheduleNumber, orgID).Scan(&total)
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
-func (r *TransactionRepository) ListFleetTripExpensesByScheduleNumber(scheduleNumber, orgID string) ([]model.FleetTripExpenseRow, error) {
+func (r *TransactionRepository) ListFleetTripExpensesByScheduleNumber(ctx context.Context, scheduleNumber string) ([]model.FleetTripExpenseRow, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
-	orgID = strings.TrimSpace(orgID)
-	if scheduleNumber == "" || orgID == "" {
+	if scheduleNumber == "" {
 		return []model.FleetTripExpenseRow{}, nil
 	}
 
@@ -956,7 +1082,7 @@ func (r *TransactionRepository) ListFleetTripExpensesByScheduleNumber(scheduleNu
 
 	orgExpr := "tft.organization_id::text = " + placeholder(2)
 	createdByUserJoinExpr := "u.user_id::text = tft.created_by::text"
-	createdByEmployeeJoinExpr := "e.employee_id::text = tft.created_by::text"
+	createdByEmployeeJoinExpr := "e.employee_id::text = tft.created_by::text AND e.organization_id = tft.organization_id"
 
 	query := fmt.Sprintf(`
 		SELECT
@@ -976,9 +1102,9 @@ func (r *TransactionRepository) ListFleetTripExpensesByScheduleNumber(scheduleNu
 		ORDER BY tft.created_at DESC
 	`, createdByUserJoinExpr, createdByEmployeeJoinExpr, placeholder(1), orgExpr)
 
-	rows, err := database.Query(r.db, query, scheduleNumber, orgID)
+	rows, err := t.Query(query, scheduleNumber, orgID)
 	if err != nil {
-		fmt.Printf("failed to query fleet trip expenses: %v\n", err)
+		slog.ErrorContext(ctx, "failed to query fleet trip expenses", "error", err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -1007,7 +1133,12 @@ func (r *TransactionRepository) ListFleetTripExpensesByScheduleNumber(scheduleNu
 	return out, nil
 }
 
-func (r *TransactionRepository) GetReimbursementAmount(scheduleNumber string) (float64, error) {
+func (r *TransactionRepository) GetReimbursementAmount(ctx context.Context, scheduleNumber string) (float64, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return 0, nil
@@ -1017,20 +1148,25 @@ func (r *TransactionRepository) GetReimbursementAmount(scheduleNumber string) (f
 	query := fmt.Sprintf(`
 		SELECT COALESCE(SUM(amount), 0) AS total_amount
 		FROM transaction_fleet_trips
-		WHERE schedule_number = %s AND status = 0 AND payment_type = 2
-	`, placeholder(1))
+		WHERE schedule_number = %s AND organization_id = %s AND status = 0 AND payment_type = 2
+	`, placeholder(1), placeholder(2))
 
 	var total float64
-	err := database.QueryRow(r.db, query, scheduleNumber).Scan(&total)
+	err = t.QueryRow(query, scheduleNumber, orgID).Scan(&total)
 	if err != nil {
-		fmt.Printf("failed to query reimbursement amount: %v\n", err)
+		slog.ErrorContext(ctx, "failed to query reimbursement amount", "error", err)
 		return 0, err
 	}
 	return total, nil
 }
 
-func (r *TransactionRepository) CreateFleetTripReimbursement(orgID, userID string, reimbursement *model.FleetTripReimbursement) error {
-	tx, err := r.db.Begin()
+func (r *TransactionRepository) CreateFleetTripReimbursement(ctx context.Context, userID string, reimbursement *model.FleetTripReimbursement) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
+	tx, err := t.Begin()
 	if err != nil {
 		return err
 	}
@@ -1048,7 +1184,7 @@ func (r *TransactionRepository) CreateFleetTripReimbursement(orgID, userID strin
 		return err
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumberTx(tx, r.driver, orgID, 1, now)
+	invoiceNumber, err := utils.GenerateTenantInvoiceNumber(tx, r.driver, 1, now)
 	if err != nil {
 		return err
 	}
@@ -1149,7 +1285,12 @@ func (r *TransactionRepository) CreateFleetTripReimbursement(orgID, userID strin
 	return tx.Commit()
 }
 
-func (r *TransactionRepository) MarkReimbursementPaid(scheduleNumber string) error {
+func (r *TransactionRepository) MarkReimbursementPaid(ctx context.Context, scheduleNumber string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	orgID := t.OrganizationID()
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return fmt.Errorf("schedule number is empty")
@@ -1159,12 +1300,12 @@ func (r *TransactionRepository) MarkReimbursementPaid(scheduleNumber string) err
 	query := fmt.Sprintf(`
 		UPDATE transaction_fleet_trips
 		SET status = 1
-		WHERE schedule_number = %s AND status = 0 AND payment_type = 2
-	`, placeholder(1))
+		WHERE schedule_number = %s AND organization_id = %s AND status = 0 AND payment_type = 2
+	`, placeholder(1), placeholder(2))
 
-	_, err := r.db.Exec(query, scheduleNumber)
+	_, err = t.Exec(query, scheduleNumber, orgID)
 	if err != nil {
-		fmt.Printf("failed to mark reimbursement paid: %v\n", err)
+		slog.ErrorContext(ctx, "failed to mark reimbursement paid", "error", err)
 		return err
 	}
 	return nil
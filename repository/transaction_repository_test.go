@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"service-travego/model"
+	"testing"
+	"time"
+)
+
+func TestTransactionRepositoryIsTenantScoped(t *testing.T) {
+	r := NewTransactionRepository(openTenantFake(t), "postgres")
+	now := time.Now()
+	checkTenantIsolation(t, tenantCalls{
+		"ListAllRevenue": func(ctx context.Context) error {
+			_, err := r.ListAllRevenue(ctx, &model.TransactionListRequest{Month: 1, Year: 2024, NoInvoice: "INV"})
+			return err
+		},
+		"ListAllExpenses": func(ctx context.Context) error {
+			_, err := r.ListAllExpenses(ctx, &model.TransactionListRequest{Month: 1, Year: 2024})
+			return err
+		},
+		"DeleteFleetTripExpense": func(ctx context.Context) error {
+			return r.DeleteFleetTripExpense(ctx, scheduleOfB, tripOfB)
+		},
+		"CreateManualTransaction": func(ctx context.Context) error {
+			_, err := r.CreateManualTransaction(ctx, employeeOfB, &CreateManualTransactionRequest{
+				OrderType: 1, OrderID: orderOfB, TransactionDate: "2024-01-01", Amount: 1000,
+			})
+			return err
+		},
+		"ValidateFleetUnit": func(ctx context.Context) error { _, _, err := r.ValidateFleetUnit(ctx, unitOfB); return err },
+		"CreateExpenseTransaction": func(ctx context.Context) error {
+			_, err := r.CreateExpenseTransaction(ctx, employeeOfB, &CreateExpenseTransactionRequest{
+				Amount: 1000, UnitID: unitOfB, TransactionDate: now,
+			})
+			return err
+		},
+		"SoftDeleteExpenseTransaction": func(ctx context.Context) error {
+			return r.SoftDeleteExpenseTransaction(ctx, paymentOfB)
+		},
+		"GetTransactionFields": func(ctx context.Context) error {
+			_, err := r.GetTransactionFields(ctx, paymentOfB)
+			return err
+		},
+		"UpdateExpenseTransaction": func(ctx context.Context) error {
+			return r.UpdateExpenseTransaction(ctx, employeeOfB, &UpdateExpenseTransactionRequest{
+				TransactionID: paymentOfB, Amount: 1000, UnitID: unitOfB, TransactionDate: now,
+			})
+		},
+		"GetFleetOrderIDByScheduleNumber": func(ctx context.Context) error {
+			_, _, err := r.GetFleetOrderIDByScheduleNumber(ctx, scheduleOfB)
+			return err
+		},
+		"CreateFleetTripOperationalExpenseTransaction": func(ctx context.Context) error {
+			return r.CreateFleetTripOperationalExpenseTransaction(ctx, employeeOfB, orderOfB, scheduleOfB, 1000, "fuel")
+		},
+		"CreateFleetTripExpenseTransaction": func(ctx context.Context) error {
+			return r.CreateFleetTripExpenseTransaction(ctx, employeeOfB, orderOfB, scheduleOfB, "fuel", 1, 1, 1000, "fuel")
+		},
+		"SumTransactionsAmountByReferenceID": func(ctx context.Context) error {
+			_, err := r.SumTransactionsAmountByReferenceID(ctx, orderOfB)
+			return err
+		},
+		"SumFleetTripAmountByScheduleNumberAndPaymentMethod": func(ctx context.Context) error {
+			_, err := r.SumFleetTripAmountByScheduleNumberAndPaymentMethod(ctx, scheduleOfB)
+			return err
+		},
+		"GetFleetTripAmountSummary": func(ctx context.Context) error {
+			_, err := r.GetFleetTripAmountSummary(ctx, scheduleOfB)
+			return err
+		},
+		"SumFleetTripAmountByScheduleNumber": func(ctx context.Context) error {
+			_, err := r.SumFleetTripAmountByScheduleNumber(ctx, scheduleOfB)
+			return err
+		},
+		"ListFleetTripExpensesByScheduleNumber": func(ctx context.Context) error {
+			_, err := r.ListFleetTripExpensesByScheduleNumber(ctx, scheduleOfB)
+			return err
+		},
+		"GetReimbursementAmount": func(ctx context.Context) error {
+			_, err := r.GetReimbursementAmount(ctx, scheduleOfB)
+			return err
+		},
+		"CreateFleetTripReimbursement": func(ctx context.Context) error {
+			return r.CreateFleetTripReimbursement(ctx, employeeOfB, &model.FleetTripReimbursement{
+				ScheduleNumber: scheduleOfB, Amount: 1000, RecipientID: employeeOfB, TransactionDate: "2024-01-01",
+			})
+		},
+		"MarkReimbursementPaid": func(ctx context.Context) error { return r.MarkReimbursementPaid(ctx, scheduleOfB) },
+	})
+}
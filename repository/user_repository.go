@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -29,7 +30,7 @@ func (r *UserRepository) getPlaceholder(pos int) string {
 }
 
 // FindAll retrieves users
-func (r *UserRepository) FindAll() ([]model.User, error) {
+func (r *UserRepository) FindAll(ctx context.Context) ([]model.User, error) {
 	query := `
 		SELECT user_id, fullname, email, password, phone, address, created_at, updated_at, deleted_at
 		FROM users
@@ -37,7 +38,7 @@ func (r *UserRepository) FindAll() ([]model.User, error) {
 		ORDER BY created_at DESC
 	`
 
-	rows, err := database.Query(r.db, query)
+	rows, err := database.QueryContext(ctx, r.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +95,7 @@ func (r *UserRepository) FindAll() ([]model.User, error) {
 // Implementation is in user_repository_uuid.go
 
 // Update updates user
-func (r *UserRepository) Update(user *model.User) (*model.User, error) {
+func (r *UserRepository) Update(ctx context.Context, user *model.User) (*model.User, error) {
 	user.UpdatedAt = time.Now()
 
 	if r.driver == "postgres" {
@@ -108,7 +109,8 @@ func (r *UserRepository) Update(user *model.User) (*model.User, error) {
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 			r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12))
 
-		err := database.QueryRow(
+		err := database.QueryRowContext(
+			ctx,
 			r.db,
 			query,
 			user.Name,
@@ -141,7 +143,8 @@ func (r *UserRepository) Update(user *model.User) (*model.User, error) {
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 			r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12))
 
-		_, err := database.Exec(
+		_, err := database.ExecContext(
+			ctx,
 			r.db,
 			query,
 			user.Name,
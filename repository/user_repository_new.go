@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
@@ -11,7 +12,7 @@ import (
 // UUID support methods
 
 // FindByUsername retrieves user
-func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	query := fmt.Sprintf(`
         SELECT user_id, username, fullname, email, password, phone, address, city, province, postal_code, 
                npwp, gender, date_of_birth, is_active, is_verified, is_admin, created_at, updated_at, deleted_at
@@ -24,7 +25,7 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	var fullname, address, city, province, postalCode, npwp, gender sql.NullString
 	var isAdmin sql.NullBool
 
-	err := database.QueryRow(r.db, query, username).Scan(
+	err := database.QueryRowContext(ctx, r.db, query, username).Scan(
 		&user.UserID,
 		&user.Username,
 		&fullname,
@@ -84,7 +85,7 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 }
 
 // FindByPhone retrieves user
-func (r *UserRepository) FindByPhone(phone string) (*model.User, error) {
+func (r *UserRepository) FindByPhone(ctx context.Context, phone string) (*model.User, error) {
 	query := fmt.Sprintf(`
         SELECT user_id, username, fullname, email, password, phone, address, city, province, postal_code,
                npwp, gender, date_of_birth, is_active, is_verified, is_admin, created_at, updated_at, deleted_at
@@ -97,7 +98,7 @@ func (r *UserRepository) FindByPhone(phone string) (*model.User, error) {
 	var fullname, address, city, province, postalCode, npwp, gender sql.NullString
 	var isAdmin sql.NullBool
 
-	err := database.QueryRow(r.db, query, phone).Scan(
+	err := database.QueryRowContext(ctx, r.db, query, phone).Scan(
 		&user.UserID,
 		&user.Username,
 		&fullname,
@@ -157,7 +158,7 @@ func (r *UserRepository) FindByPhone(phone string) (*model.User, error) {
 }
 
 // VerifyUser sets verified
-func (r *UserRepository) VerifyUser(userID string) error {
+func (r *UserRepository) VerifyUser(ctx context.Context, userID string) error {
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET is_verified = %s, verified_at = %s, updated_at = %s
@@ -165,7 +166,7 @@ func (r *UserRepository) VerifyUser(userID string) error {
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
 
 	now := time.Now()
-	result, err := database.Exec(r.db, query, true, now, now, userID)
+	result, err := database.ExecContext(ctx, r.db, query, true, now, now, userID)
 	if err != nil {
 		return err
 	}
@@ -183,7 +184,7 @@ func (r *UserRepository) VerifyUser(userID string) error {
 }
 
 // UpdateProfile updates user profile data
-func (r *UserRepository) UpdateProfile(user *model.User) (*model.User, error) {
+func (r *UserRepository) UpdateProfile(ctx context.Context, user *model.User) (*model.User, error) {
 	user.UpdatedAt = time.Now()
 
 	if r.driver == "postgres" {
@@ -198,7 +199,9 @@ func (r *UserRepository) UpdateProfile(user *model.User) (*model.User, error) {
 			r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12))
 
 		var dateOfBirth sql.NullTime
-		err := r.db.QueryRow(
+		err := database.QueryRowContext(
+			ctx,
+			r.db,
 			query,
 			user.Name,
 			user.Phone,
@@ -234,7 +237,9 @@ func (r *UserRepository) UpdateProfile(user *model.User) (*model.User, error) {
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8),
 			r.getPlaceholder(9), r.getPlaceholder(10), r.getPlaceholder(11), r.getPlaceholder(12))
 
-		result, err := r.db.Exec(
+		result, err := database.ExecContext(
+			ctx,
+			r.db,
 			query,
 			user.Name,
 			user.Phone,
@@ -263,7 +268,7 @@ func (r *UserRepository) UpdateProfile(user *model.User) (*model.User, error) {
 		}
 
 		// Fetch updated data
-		err = r.db.QueryRow(fmt.Sprintf(`
+		err = database.QueryRowContext(ctx, r.db, fmt.Sprintf(`
 			SELECT username, email, is_active, is_verified, created_at 
 			FROM users WHERE user_id = %s
 		`, r.getPlaceholder(1)), user.UserID).Scan(&user.Username, &user.Email, &user.IsActive, &user.IsVerified, &user.CreatedAt)
@@ -276,7 +281,7 @@ func (r *UserRepository) UpdateProfile(user *model.User) (*model.User, error) {
 }
 
 // UpdatePassword updates user password
-func (r *UserRepository) UpdatePassword(userID, hashedPassword string) error {
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID, hashedPassword string) error {
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET password = %s, updated_at = %s
@@ -284,7 +289,7 @@ func (r *UserRepository) UpdatePassword(userID, hashedPassword string) error {
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	now := time.Now()
-	result, err := r.db.Exec(query, hashedPassword, now, userID)
+	result, err := database.ExecContext(ctx, r.db, query, hashedPassword, now, userID)
 	if err != nil {
 		return err
 	}
@@ -301,7 +306,7 @@ func (r *UserRepository) UpdatePassword(userID, hashedPassword string) error {
 	return nil
 }
 
-func (r *UserRepository) UpdateAvatar(userID, avatarPath string) error {
+func (r *UserRepository) UpdateAvatar(ctx context.Context, userID, avatarPath string) error {
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET avatar = %s, updated_at = %s
@@ -309,7 +314,7 @@ func (r *UserRepository) UpdateAvatar(userID, avatarPath string) error {
 	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
 
 	now := time.Now()
-	result, err := r.db.Exec(query, avatarPath, now, userID)
+	result, err := database.ExecContext(ctx, r.db, query, avatarPath, now, userID)
 	if err != nil {
 		return err
 	}
@@ -1,16 +1,17 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"service-travego/database"
 	"service-travego/model"
 	"time"
 )
 
 // Create creates new user
-func (r *UserRepository) Create(user *model.User) (*model.User, error) {
+func (r *UserRepository) Create(ctx context.Context, user *model.User) (*model.User, error) {
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
@@ -23,7 +24,8 @@ func (r *UserRepository) Create(user *model.User) (*model.User, error) {
         `, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
 
-		err := database.QueryRow(
+		err := database.QueryRowContext(
+			ctx,
 			r.db,
 			query,
 			user.UserID,
@@ -48,7 +50,8 @@ func (r *UserRepository) Create(user *model.User) (*model.User, error) {
         `, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4),
 			r.getPlaceholder(5), r.getPlaceholder(6), r.getPlaceholder(7), r.getPlaceholder(8), r.getPlaceholder(9), r.getPlaceholder(10))
 
-		_, err := database.Exec(
+		_, err := database.ExecContext(
+			ctx,
 			r.db,
 			query,
 			user.UserID,
@@ -71,7 +74,7 @@ func (r *UserRepository) Create(user *model.User) (*model.User, error) {
 }
 
 // FindByID retrieves user
-func (r *UserRepository) FindByID(id string) (*model.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	query := fmt.Sprintf(`
         SELECT user_id, username, fullname, email, password, phone, address, city, province, postal_code,
                npwp, gender, date_of_birth, is_active, is_verified, is_admin, created_at, updated_at, deleted_at, avatar
@@ -84,7 +87,7 @@ func (r *UserRepository) FindByID(id string) (*model.User, error) {
 	var fullname, address, city, province, postalCode, npwp, gender, avatar sql.NullString
 	var isAdmin sql.NullBool
 
-	err := database.QueryRow(r.db, query, id).Scan(
+	err := database.QueryRowContext(ctx, r.db, query, id).Scan(
 		&user.UserID,
 		&user.Username,
 		&fullname,
@@ -108,10 +111,10 @@ func (r *UserRepository) FindByID(id string) (*model.User, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Printf("[DEBUG] FindByID no rows - Query: %s | Param: %s", query, id)
+			slog.DebugContext(ctx, "find user by id: no rows", "user_id", id)
 			return nil, sql.ErrNoRows
 		}
-		log.Printf("[ERROR] FindByID query error - Query: %s | Param: %s | Error: %v", query, id, err)
+		slog.ErrorContext(ctx, "find user by id failed", "query", query, "user_id", id, "error", err)
 		return nil, err
 	}
 
@@ -150,12 +153,12 @@ func (r *UserRepository) FindByID(id string) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) FindByUserID(id string) (*model.User, error) {
-	return r.FindByID(id)
+func (r *UserRepository) FindByUserID(ctx context.Context, id string) (*model.User, error) {
+	return r.FindByID(ctx, id)
 }
 
 // FindByEmail retrieves user
-func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := fmt.Sprintf(`
         SELECT user_id, username, fullname, email, password, phone, address, city, province, postal_code,
                npwp, gender, date_of_birth, is_active, is_verified, is_admin, created_at, updated_at, deleted_at
@@ -168,7 +171,7 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 	var fullname, address, city, province, postalCode, npwp, gender sql.NullString
 	var isAdmin sql.NullBool
 
-	err := database.QueryRow(r.db, query, email).Scan(
+	err := database.QueryRowContext(ctx, r.db, query, email).Scan(
 		&user.UserID,
 		&user.Username,
 		&fullname,
@@ -231,7 +234,7 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 }
 
 // Delete soft deletes user
-func (r *UserRepository) Delete(userID string) error {
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET deleted_at = %s
@@ -239,7 +242,7 @@ func (r *UserRepository) Delete(userID string) error {
 	`, r.getPlaceholder(1), r.getPlaceholder(2))
 
 	now := time.Now()
-	result, err := database.Exec(r.db, query, now, userID)
+	result, err := database.ExecContext(ctx, r.db, query, now, userID)
 	if err != nil {
 		return err
 	}
@@ -256,13 +259,13 @@ func (r *UserRepository) Delete(userID string) error {
 	return nil
 }
 
-func (r *UserRepository) SetStatusDeleted(userID string) error {
+func (r *UserRepository) SetStatusDeleted(ctx context.Context, userID string) error {
 	query := fmt.Sprintf(`
 		UPDATE users
 		SET status = 1
 		WHERE user_id = %s
 	`, r.getPlaceholder(1))
 
-	_, err := database.Exec(r.db, query, userID)
+	_, err := database.ExecContext(ctx, r.db, query, userID)
 	return err
 }
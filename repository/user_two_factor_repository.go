@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+	"time"
+)
+
+// GetTwoFactor returns the user's two-factor settings, or nil when two-factor
+// was never set up
+func (r *UserRepository) GetTwoFactor(ctx context.Context, userID string) (*model.UserTwoFactor, error) {
+	query := fmt.Sprintf(`
+		SELECT t.user_id, t.totp_secret, t.enabled_at,
+			(SELECT COUNT(1) FROM user_recovery_codes c WHERE c.user_id = t.user_id AND c.used_at IS NULL)
+		FROM user_two_factor t
+		WHERE t.user_id = %s
+	`, r.getPlaceholder(1))
+
+	var tf model.UserTwoFactor
+	var enabledAt sql.NullTime
+	err := database.QueryRowContext(ctx, r.db, query, userID).Scan(&tf.UserID, &tf.TOTPSecret, &enabledAt, &tf.RecoveryCodesLeft)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if enabledAt.Valid {
+		tf.Enabled = true
+		tf.EnabledAt = &enabledAt.Time
+	}
+	return &tf, nil
+}
+
+// SaveTwoFactorSecret stores a new, not yet enabled TOTP secret
+func (r *UserRepository) SaveTwoFactorSecret(ctx context.Context, userID, encryptedSecret string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = database.TxExecContext(ctx, tx, "DELETE FROM user_two_factor WHERE user_id = "+r.getPlaceholder(1), userID); err != nil {
+		return err
+	}
+	now := time.Now()
+	query := fmt.Sprintf(`
+		INSERT INTO user_two_factor (user_id, totp_secret, created_at, updated_at)
+		VALUES (%s, %s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3), r.getPlaceholder(4))
+	if _, err = database.TxExecContext(ctx, tx, query, userID, encryptedSecret, now, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *UserRepository) replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, codeHashes []string, now time.Time) error {
+	if _, err := database.TxExecContext(ctx, tx, "DELETE FROM user_recovery_codes WHERE user_id = "+r.getPlaceholder(1), userID); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO user_recovery_codes (user_id, code_hash, created_at)
+		VALUES (%s, %s, %s)
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	for _, h := range codeHashes {
+		if _, err := database.TxExecContext(ctx, tx, query, userID, h, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableTwoFactor turns two-factor on and replaces the recovery codes
+func (r *UserRepository) EnableTwoFactor(ctx context.Context, userID string, codeHashes []string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	query := fmt.Sprintf(`
+		UPDATE user_two_factor SET enabled_at = %s, updated_at = %s
+		WHERE user_id = %s
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	result, err := database.TxExecContext(ctx, tx, query, now, now, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		err = sql.ErrNoRows
+		return err
+	}
+	if err = r.replaceRecoveryCodes(ctx, tx, userID, codeHashes, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes invalidates the user's recovery codes and stores new ones
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = r.replaceRecoveryCodes(ctx, tx, userID, codeHashes, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DisableTwoFactor removes the user's TOTP secret and recovery codes
+func (r *UserRepository) DisableTwoFactor(ctx context.Context, userID string) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = database.TxExecContext(ctx, tx, "DELETE FROM user_recovery_codes WHERE user_id = "+r.getPlaceholder(1), userID); err != nil {
+		return err
+	}
+	if _, err = database.TxExecContext(ctx, tx, "DELETE FROM user_two_factor WHERE user_id = "+r.getPlaceholder(1), userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false when
+// the user has no such unused code.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE user_recovery_codes SET used_at = %s
+		WHERE user_id = %s AND code_hash = %s AND used_at IS NULL
+	`, r.getPlaceholder(1), r.getPlaceholder(2), r.getPlaceholder(3))
+	result, err := database.ExecContext(ctx, r.db, query, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
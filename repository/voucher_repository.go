@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"service-travego/database"
+	"service-travego/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrVoucherUsageLimit is returned when a voucher reached its total or
+// per-customer usage cap while the order was being saved.
+var ErrVoucherUsageLimit = errors.New("voucher usage limit reached")
+
+type VoucherRepository struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewVoucherRepository(db *sql.DB, driver string) *VoucherRepository {
+	return &VoucherRepository{db: db, driver: driver}
+}
+
+func (r *VoucherRepository) placeholder(pos int) string {
+	if r.driver == "postgres" || r.driver == "pgx" {
+		return "$" + strconv.Itoa(pos)
+	}
+	return "?"
+}
+
+const selectVoucher = `
+SELECT
+	voucher_id,
+	organization_id,
+	code,
+	COALESCE(name, '') AS name,
+	COALESCE(description, '') AS description,
+	scope,
+	COALESCE(CAST(scope_id AS CHAR(36)), '') AS scope_id,
+	discount_type,
+	COALESCE(discount_value, 0) AS discount_value,
+	COALESCE(max_discount, 0) AS max_discount,
+	COALESCE(min_spend, 0) AS min_spend,
+	start_date,
+	end_date,
+	COALESCE(max_uses, 0) AS max_uses,
+	COALESCE(max_uses_per_customer, 0) AS max_uses_per_customer,
+	COALESCE(used_count, 0) AS used_count,
+	COALESCE(status, 0) AS status,
+	created_at
+FROM vouchers
+`
+
+func (r *VoucherRepository) scanVouchers(rows *sql.Rows) ([]model.Voucher, error) {
+	out := make([]model.Voucher, 0)
+	for rows.Next() {
+		var it model.Voucher
+		var startDate, endDate, createdAt sql.NullTime
+		var status int
+		if err := rows.Scan(
+			&it.VoucherID,
+			&it.OrganizationID,
+			&it.Code,
+			&it.Name,
+			&it.Description,
+			&it.Scope,
+			&it.ScopeID,
+			&it.DiscountType,
+			&it.DiscountValue,
+			&it.MaxDiscount,
+			&it.MinSpend,
+			&startDate,
+			&endDate,
+			&it.MaxUses,
+			&it.MaxUsesPerCustomer,
+			&it.UsedCount,
+			&status,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		if startDate.Valid {
+			it.StartDate = startDate.Time.Format("2006-01-02")
+		}
+		if endDate.Valid {
+			it.EndDate = endDate.Time.Format("2006-01-02")
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		it.ScopeLabel = model.VoucherScopeLabel[it.Scope]
+		it.Active = status == 1
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *VoucherRepository) queryOne(t *database.Tenant, query string, args ...interface{}) (*model.Voucher, error) {
+	rows, err := t.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items, err := r.scanVouchers(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &items[0], nil
+}
+
+// List returns the organization's vouchers, paused ones included.
+func (r *VoucherRepository) List(ctx context.Context) ([]model.Voucher, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectVoucher + " WHERE organization_id = " + r.placeholder(1) + " AND status IN (1, 2) ORDER BY created_at DESC"
+	rows, err := t.Query(query, t.OrganizationID())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanVouchers(rows)
+}
+
+func (r *VoucherRepository) GetByID(ctx context.Context, voucherID string) (*model.Voucher, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectVoucher + " WHERE organization_id = " + r.placeholder(1) + " AND voucher_id = " + r.placeholder(2) + " AND status IN (1, 2)"
+	return r.queryOne(t, query, t.OrganizationID(), voucherID)
+}
+
+// GetByCode looks a voucher up by its code, case-insensitively.
+func (r *VoucherRepository) GetByCode(ctx context.Context, code string) (*model.Voucher, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := selectVoucher + " WHERE organization_id = " + r.placeholder(1) + " AND UPPER(code) = " + r.placeholder(2) + " AND status IN (1, 2)"
+	return r.queryOne(t, query, t.OrganizationID(), strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// CodeExists reports whether another voucher of the organization uses code.
+func (r *VoucherRepository) CodeExists(ctx context.Context, code, excludeVoucherID string) (bool, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return false, err
+	}
+	query := fmt.Sprintf(`
+		SELECT COUNT(1) FROM vouchers
+		WHERE organization_id = %s AND UPPER(code) = %s AND status IN (1, 2) AND CAST(voucher_id AS CHAR(36)) <> %s
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3))
+	var count int
+	if err := t.QueryRow(query, t.OrganizationID(), strings.ToUpper(strings.TrimSpace(code)), excludeVoucherID).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func voucherStatus(req *model.VoucherUpsertRequest) int {
+	if req.Active != nil && !*req.Active {
+		return 2
+	}
+	return 1
+}
+
+func (r *VoucherRepository) Create(ctx context.Context, req *model.VoucherUpsertRequest) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	voucherID := uuid.New().String()
+	now := time.Now()
+	query := fmt.Sprintf(`
+		INSERT INTO vouchers
+			(voucher_id, organization_id, code, name, description, scope, scope_id, discount_type, discount_value, max_discount,
+			 min_spend, start_date, end_date, max_uses, max_uses_per_customer, used_count, status, created_by, created_at, updated_by, updated_at)
+		VALUES
+			(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, 0, %s, %s, %s, %s, %s)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12),
+		r.placeholder(13), r.placeholder(14), r.placeholder(15), r.placeholder(16), r.placeholder(17), r.placeholder(18),
+		r.placeholder(19), r.placeholder(20))
+	_, err = t.Exec(query,
+		voucherID,
+		req.OrganizationID,
+		req.Code,
+		req.Name,
+		req.Description,
+		req.Scope,
+		nullableString(req.ScopeID),
+		req.DiscountType,
+		req.DiscountValue,
+		req.MaxDiscount,
+		req.MinSpend,
+		nullableString(req.StartDate),
+		nullableString(req.EndDate),
+		req.MaxUses,
+		req.MaxUsesPerCustomer,
+		voucherStatus(req),
+		req.UserID,
+		now,
+		req.UserID,
+		now,
+	)
+	if err != nil {
+		return "", err
+	}
+	return voucherID, nil
+}
+
+func (r *VoucherRepository) Update(ctx context.Context, req *model.VoucherUpsertRequest) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE vouchers
+		SET code = %s, name = %s, description = %s, scope = %s, scope_id = %s, discount_type = %s, discount_value = %s,
+			max_discount = %s, min_spend = %s, start_date = %s, end_date = %s, max_uses = %s, max_uses_per_customer = %s,
+			status = %s, updated_by = %s, updated_at = %s
+		WHERE voucher_id = %s AND organization_id = %s AND status IN (1, 2)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4), r.placeholder(5), r.placeholder(6),
+		r.placeholder(7), r.placeholder(8), r.placeholder(9), r.placeholder(10), r.placeholder(11), r.placeholder(12),
+		r.placeholder(13), r.placeholder(14), r.placeholder(15), r.placeholder(16), r.placeholder(17), r.placeholder(18))
+	result, err := t.Exec(query,
+		req.Code,
+		req.Name,
+		req.Description,
+		req.Scope,
+		nullableString(req.ScopeID),
+		req.DiscountType,
+		req.DiscountValue,
+		req.MaxDiscount,
+		req.MinSpend,
+		nullableString(req.StartDate),
+		nullableString(req.EndDate),
+		req.MaxUses,
+		req.MaxUsesPerCustomer,
+		voucherStatus(req),
+		req.UserID,
+		time.Now(),
+		req.VoucherID,
+		req.OrganizationID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *VoucherRepository) Delete(ctx context.Context, userID, voucherID string) error {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`
+		UPDATE vouchers SET status = 0, updated_by = %s, updated_at = %s
+		WHERE voucher_id = %s AND organization_id = %s AND status IN (1, 2)
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	result, err := t.Exec(query, userID, time.Now(), voucherID, t.OrganizationID())
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// FindCustomerID returns the customer matching the phone/email the same way
+// order creation does, or "" when the customer is new.
+func (r *VoucherRepository) FindCustomerID(ctx context.Context, phone, email string) (string, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return "", err
+	}
+	query := fmt.Sprintf(`
+		SELECT customer_id FROM customers
+		WHERE organization_id = %s
+		  AND customer_phone = %s
+		  AND (%s = '' OR customer_email = %s)
+		LIMIT 1
+	`, r.placeholder(1), r.placeholder(2), r.placeholder(3), r.placeholder(4))
+	var customerID string
+	err = t.QueryRow(query, t.OrganizationID(), phone, email, email).Scan(&customerID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return customerID, nil
+}
+
+// CountCustomerRedemptions returns how many times a customer used a voucher.
+func (r *VoucherRepository) CountCustomerRedemptions(ctx context.Context, voucherID, customerID string) (int, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf(`SELECT COUNT(1) FROM voucher_redemptions WHERE voucher_id = %s AND customer_id = %s AND organization_id = %s`, r.placeholder(1), r.placeholder(2), r.placeholder(3))
+	var count int
+	if err := t.QueryRow(query, voucherID, customerID, t.OrganizationID()).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *VoucherRepository) ListRedemptions(ctx context.Context, voucherID string) ([]model.VoucherRedemption, error) {
+	t, err := database.ForTenant(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT vr.redemption_id, vr.voucher_id, COALESCE(vr.code, ''), vr.order_id, COALESCE(vr.order_type, 0),
+			COALESCE(CAST(vr.customer_id AS CHAR(36)), ''), COALESCE(c.customer_name, ''), COALESCE(vr.discount_amount, 0), vr.created_at
+		FROM voucher_redemptions vr
+		LEFT JOIN customers c ON c.customer_id = vr.customer_id AND c.organization_id = vr.organization_id
+		WHERE vr.organization_id = %s AND vr.voucher_id = %s
+		ORDER BY vr.created_at DESC
+	`, r.placeholder(1), r.placeholder(2))
+	rows, err := t.Query(query, t.OrganizationID(), voucherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]model.VoucherRedemption, 0)
+	for rows.Next() {
+		var it model.VoucherRedemption
+		var createdAt sql.NullTime
+		if err := rows.Scan(&it.RedemptionID, &it.VoucherID, &it.Code, &it.OrderID, &it.OrderType, &it.CustomerID, &it.CustomerName, &it.DiscountAmount, &createdAt); err != nil {
+			return nil, err
+		}
+		if createdAt.Valid {
+			it.CreatedDate = createdAt.Time.Format("2006-01-02 15:04:05")
+		}
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// redeemVoucher books one use of a voucher inside the order transaction. The
+// usage caps are checked again here so concurrent orders cannot exceed them:
+// the voucher row is locked by its usage update before the redemptions of the
+// customer are counted. The caller rolls back on an error.
+func redeemVoucher(tx *database.TenantTx, placeholder func(int) string, orderID string, orderType int, customerID string, app *model.VoucherApplication) error {
+	if app == nil || app.VoucherID == "" {
+		return nil
+	}
+	orgID := tx.OrganizationID()
+
+	now := time.Now()
+	useQuery := fmt.Sprintf(`
+		UPDATE vouchers SET used_count = COALESCE(used_count, 0) + 1, updated_at = %s
+		WHERE voucher_id = %s AND organization_id = %s AND status = 1 AND (COALESCE(max_uses, 0) = 0 OR COALESCE(used_count, 0) < max_uses)
+	`, placeholder(1), placeholder(2), placeholder(3))
+	result, err := tx.Exec(useQuery, now, app.VoucherID, orgID)
+	if err != nil {
+		return fmt.Errorf("update voucher usage: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrVoucherUsageLimit
+	}
+
+	// counted only once the update above holds the voucher row, so a
+	// concurrent order of the same customer waits for this one to commit
+	if app.MaxUsesPerCustomer > 0 && customerID != "" {
+		countQuery := fmt.Sprintf(`SELECT COUNT(1) FROM voucher_redemptions WHERE voucher_id = %s AND customer_id = %s AND organization_id = %s`, placeholder(1), placeholder(2), placeholder(3))
+		var used int
+		if err := tx.QueryRow(countQuery, app.VoucherID, customerID, orgID).Scan(&used); err != nil {
+			return fmt.Errorf("count voucher redemptions: %w", err)
+		}
+		if used >= app.MaxUsesPerCustomer {
+			return ErrVoucherUsageLimit
+		}
+	}
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO voucher_redemptions
+			(redemption_id, voucher_id, organization_id, code, order_id, order_type, customer_id, order_amount, discount_amount, created_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, placeholder(1), placeholder(2), placeholder(3), placeholder(4), placeholder(5), placeholder(6), placeholder(7),
+		placeholder(8), placeholder(9), placeholder(10))
+	if _, err := tx.Exec(insertQuery,
+		uuid.New().String(),
+		app.VoucherID,
+		orgID,
+		app.Code,
+		orderID,
+		orderType,
+		nullableString(customerID),
+		app.Amount,
+		app.DiscountAmount,
+		now,
+	); err != nil {
+		return fmt.Errorf("insert voucher redemption: %w", err)
+	}
+	return nil
+}
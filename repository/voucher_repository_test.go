@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"service-travego/database"
+	"service-travego/model"
+	"strings"
+	"testing"
+)
+
+func TestVoucherRepositoryIsTenantScoped(t *testing.T) {
+	r := NewVoucherRepository(openTenantFake(t), "postgres")
+	checkTenantIsolation(t, tenantCalls{
+		"List":      func(ctx context.Context) error { _, err := r.List(ctx); return err },
+		"GetByID":   func(ctx context.Context) error { _, err := r.GetByID(ctx, voucherOfB); return err },
+		"GetByCode": func(ctx context.Context) error { _, err := r.GetByCode(ctx, "HEMAT10"); return err },
+		"CodeExists": func(ctx context.Context) error {
+			_, err := r.CodeExists(ctx, "HEMAT10", "")
+			return err
+		},
+		"Update": func(ctx context.Context) error {
+			organizationID, _ := database.OrganizationFromContext(ctx)
+			return r.Update(ctx, &model.VoucherUpsertRequest{OrganizationID: organizationID, VoucherID: voucherOfB})
+		},
+		"Delete": func(ctx context.Context) error { return r.Delete(ctx, "", voucherOfB) },
+		"FindCustomerID": func(ctx context.Context) error {
+			_, err := r.FindCustomerID(ctx, "08123456789", "")
+			return err
+		},
+		"CountCustomerRedemptions": func(ctx context.Context) error {
+			_, err := r.CountCustomerRedemptions(ctx, voucherOfB, "customer-of-b")
+			return err
+		},
+		"ListRedemptions": func(ctx context.Context) error { _, err := r.ListRedemptions(ctx, voucherOfB); return err },
+	})
+
+	ctxA := database.WithOrganization(context.Background(), tenantA)
+	checkRefusedWrites(t, map[string]func() error{
+		"Create": func() error {
+			_, err := r.Create(ctxA, &model.VoucherUpsertRequest{OrganizationID: tenantB, Code: "HEMAT10"})
+			return err
+		},
+		"Update": func() error {
+			return r.Update(ctxA, &model.VoucherUpsertRequest{OrganizationID: tenantB, VoucherID: voucherOfB})
+		},
+	})
+}
+
+func TestRedeemVoucherLocksTheVoucherBeforeCountingRedemptions(t *testing.T) {
+	r := NewVoucherRepository(openTenantFake(t), "postgres")
+	tenant, err := database.ForTenant(database.WithOrganization(context.Background(), tenantB), r.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := tenant.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	app := &model.VoucherApplication{VoucherID: voucherOfB, Code: "HEMAT10", MaxUsesPerCustomer: 1}
+	if err := redeemVoucher(tx, r.placeholder, orderOfB, model.VoucherOrderFleet, customerOfB, app); err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	for _, st := range fakeTenantDB.take() {
+		order = append(order, strings.Fields(st.query)[0]+" "+strings.Fields(st.query)[1])
+	}
+	want := []string{"UPDATE vouchers", "SELECT COUNT(1)", "INSERT INTO"}
+	if strings.Join(order, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+}
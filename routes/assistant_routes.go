@@ -2,7 +2,7 @@ package routes
 
 import (
 	"database/sql"
-	"log"
+	"log/slog"
 	"service-travego/internal/waai"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,7 +13,7 @@ import (
 func SetupAssistantRoutes(api fiber.Router, db *sql.DB, driver string, rdb *redis.Client) {
 	waaiCfg := waai.LoadConfig()
 	if err := waaiCfg.Validate(); err != nil {
-		log.Printf("Warning: Failed to register assistant routes: %v", err)
+		slog.Warn("failed to register assistant routes", "error", err)
 		return
 	}
 
@@ -22,17 +22,29 @@ func SetupAuthRoutes(api fiber.Router, db *sql.DB, driver string, cfg *configs.C
 	// Initialize auth service and handler
 	authService := service.NewAuthService(userRepo, &cfg.Email)
 	authService.SetOrganizationUserRepository(orgUserRepo)
+	authService.SetOutboxService(service.NewOutboxService(repository.NewOutboxRepository(db, driver)))
 	authHandler := handler.NewAuthHandler(authService)
 
 	// Auth routes
 	auth := api.Group("/auth")
 	auth.Post("/register", helper.AuthRateLimiter(), authHandler.Register)
-	auth.Post("/verify-otp", authHandler.VerifyOTP)
-	auth.Post("/resend-otp", authHandler.ResendOTP)
+	auth.Post("/verify-otp", helper.RateLimiter("otp"), authHandler.VerifyOTP)
+	auth.Post("/resend-otp", helper.RateLimiter("otp"), authHandler.ResendOTP)
 	auth.Post("/login", helper.AuthRateLimiter(), authHandler.Login)
-	auth.Post("/reset-password", authHandler.RequestResetPassword)
+	auth.Post("/login/2fa", helper.AuthRateLimiter(), authHandler.LoginTwoFactor)
+	auth.Post("/reset-password", helper.RateLimiter("password_reset"), authHandler.RequestResetPassword)
 	auth.Post("/update-password", authHandler.UpdatePassword)
 
 	auth.Post("/logout", authHandler.Logout)
 	auth.Post("/refresh", authHandler.RefreshToken)
+
+	// Two-factor authentication and login sessions of the logged in user
+	auth.Get("/2fa", helper.JWTAuthorizationMiddleware(), authHandler.TwoFactorStatus)
+	auth.Post("/2fa/setup", helper.JWTAuthorizationMiddleware(), authHandler.TwoFactorSetup)
+	auth.Post("/2fa/enable", helper.JWTAuthorizationMiddleware(), authHandler.TwoFactorEnable)
+	auth.Post("/2fa/disable", helper.JWTAuthorizationMiddleware(), authHandler.TwoFactorDisable)
+	auth.Post("/2fa/recovery-codes", helper.JWTAuthorizationMiddleware(), authHandler.TwoFactorRecoveryCodes)
+	auth.Get("/sessions", helper.JWTAuthorizationMiddleware(), authHandler.ListSessions)
+	auth.Post("/sessions/revoke", helper.JWTAuthorizationMiddleware(), authHandler.RevokeSession)
+	auth.Post("/sessions/revoke-others", helper.JWTAuthorizationMiddleware(), authHandler.RevokeOtherSessions)
 }
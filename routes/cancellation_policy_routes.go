@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupCancellationPolicyRoutes(api fiber.Router, db *sql.DB, driver string) {
+	srv := service.NewCancellationPolicyService(repository.NewCancellationPolicyRepository(db, driver), repository.NewFleetRepository(db, driver))
+	h := handler.NewCancellationPolicyHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	settingsManage := helper.RequirePermission(orgUserRepo, configs.PermissionSettingsManage)
+
+	services := api.Group("/services")
+	policy := services.Group("/cancellation-policy")
+
+	policy.Get("", helper.JWTAuthorizationMiddleware(), h.Get)
+	policy.Get("/history", helper.JWTAuthorizationMiddleware(), h.History)
+	policy.Post("/save", helper.JWTAuthorizationMiddleware(), settingsManage, h.Save)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupDocumentRoutes(api fiber.Router, db *sql.DB, driver string) {
+	repo := repository.NewDocumentRepository(db, driver)
+	srv := service.NewDocumentService(repo, service.NewUploadService())
+	h := handler.NewDocumentHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	fleetManage := helper.RequirePermission(orgUserRepo, configs.PermissionFleetManage)
+
+	services := api.Group("/services")
+	documents := services.Group("/documents")
+
+	documents.Get("", helper.JWTAuthorizationMiddleware(), h.List)
+	documents.Get("/detail/:document_id", helper.JWTAuthorizationMiddleware(), h.Detail)
+	documents.Post("/create", helper.JWTAuthorizationMiddleware(), fleetManage, h.Create)
+	documents.Post("/update", helper.JWTAuthorizationMiddleware(), fleetManage, h.Update)
+	documents.Post("/delete", helper.JWTAuthorizationMiddleware(), fleetManage, h.Delete)
+}
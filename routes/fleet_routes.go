@@ -2,6 +2,7 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
 	"service-travego/repository"
@@ -14,29 +15,44 @@ func SetupFleetRoutes(api fiber.Router, db *sql.DB, driver string) {
 	repo := repository.NewFleetRepository(db, driver)
 	orgRepo := repository.NewOrganizationRepository(db, driver)
 	srv := service.NewFleetService(repo)
+	srv.SetPriceRuleService(service.NewPriceRuleService(repository.NewPriceRuleRepository(db, driver), repo))
+	srv.SetPaymentPlanService(service.NewPaymentPlanService(repository.NewPaymentPlanRepository(db, driver)))
+	srv.SetCancellationPolicyService(service.NewCancellationPolicyService(repository.NewCancellationPolicyRepository(db, driver), repo))
+	srv.SetAuditService(service.NewAuditService(repository.NewAuditLogRepository(db, driver)))
+	srv.SetEntitlementService(service.NewEntitlementService(repository.NewEntitlementRepository(db, driver)))
 	h := handler.NewFleetHandler(srv, orgRepo)
+	h.SetOutboxService(service.NewOutboxService(repository.NewOutboxRepository(db, driver)))
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	ordersView := helper.RequirePermission(orgUserRepo, configs.PermissionOrdersView)
+	ordersManage := helper.RequirePermission(orgUserRepo, configs.PermissionOrdersManage)
+	ordersCancel := helper.RequirePermission(orgUserRepo, configs.PermissionOrdersCancel)
+	fleetManage := helper.RequirePermission(orgUserRepo, configs.PermissionFleetManage)
+	financeView := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceView)
 
 	services := api.Group("/services")
 	fleet := services.Group("/fleet")
 
-	fleet.Post("/create", helper.JWTAuthorizationMiddleware(), h.CreateFleet)
-	fleet.Post("/delete", helper.JWTAuthorizationMiddleware(), h.DeleteFleet)
-	fleet.Post("/update", helper.JWTAuthorizationMiddleware(), h.UpdateFleet)
-	fleet.Post("/activate", helper.JWTAuthorizationMiddleware(), h.SetFleetActiveStatus)
-	fleet.Get("/list", helper.JWTAuthorizationMiddleware(), h.ListFleets)
-	fleet.Get("/availibility", helper.JWTAuthorizationMiddleware(), h.FleetAvailibility)
-	fleet.Post("/detail", helper.JWTAuthorizationMiddleware(), h.FleetDetail)
-	fleet.Post("/revenue", helper.JWTAuthorizationMiddleware(), h.FleetRevenue)
-	fleet.Get("/addon/:fleetid", helper.JWTAuthorizationMiddleware(), h.GetFleetAddonList)
-	fleet.Get("/prices/:fleetid/:typeid", helper.JWTAuthorizationMiddleware(), h.GetFleetPricesByFleetID)
-	fleet.Get("/facilities", helper.JWTAuthorizationMiddleware(), h.GetFacilityList)
+	fleet.Post("/create", helper.JWTAuthorizationMiddleware(), fleetManage, h.CreateFleet)
+	fleet.Post("/delete", helper.JWTAuthorizationMiddleware(), fleetManage, h.DeleteFleet)
+	fleet.Post("/update", helper.JWTAuthorizationMiddleware(), fleetManage, h.UpdateFleet)
+	fleet.Post("/activate", helper.JWTAuthorizationMiddleware(), fleetManage, h.SetFleetActiveStatus)
+	fleet.Get("/list", helper.JWTAuthorizationMiddleware(), ordersView, h.ListFleets)
+	fleet.Get("/availibility", helper.JWTAuthorizationMiddleware(), ordersView, h.FleetAvailibility)
+	fleet.Post("/detail", helper.JWTAuthorizationMiddleware(), ordersView, h.FleetDetail)
+	fleet.Post("/revenue", helper.JWTAuthorizationMiddleware(), financeView, h.FleetRevenue)
+	fleet.Get("/addon/:fleetid", helper.JWTAuthorizationMiddleware(), ordersView, h.GetFleetAddonList)
+	fleet.Get("/prices/:fleetid/:typeid", helper.JWTAuthorizationMiddleware(), ordersView, h.GetFleetPricesByFleetID)
+	fleet.Get("/facilities", helper.JWTAuthorizationMiddleware(), ordersView, h.GetFacilityList)
 
 	// Orders
-	fleet.Post("/orders/create", helper.JWTAuthorizationMiddleware(), h.CreatePartnerOrder)
-	fleet.Get("/order/detail/:order_id", helper.JWTAuthorizationMiddleware(), h.GetPartnerOrderDetail)
-	fleet.Post("/order/update", helper.JWTAuthorizationMiddleware(), h.UpdatePartnerOrder)
-	fleet.Post("/order/delete-addon", helper.JWTAuthorizationMiddleware(), h.DeleteFleetOrderAddon)
-	fleet.Post("/order/process/:processType/:order_id", helper.JWTAuthorizationMiddleware(), h.ProcessFleetOrder)
-	fleet.Post("/order/cancel", helper.JWTAuthorizationMiddleware(), h.CancelPartnerOrder)
-	fleet.Post("/order/cancelation-detail", helper.JWTAuthorizationMiddleware(), h.CancelPartnerOrderDetail)
+	fleet.Post("/orders/create", helper.JWTAuthorizationMiddleware(), ordersManage, h.CreatePartnerOrder)
+	fleet.Get("/order/detail/:order_id", helper.JWTAuthorizationMiddleware(), ordersView, h.GetPartnerOrderDetail)
+	fleet.Post("/order/update", helper.JWTAuthorizationMiddleware(), ordersManage, h.UpdatePartnerOrder)
+	fleet.Post("/order/delete-addon", helper.JWTAuthorizationMiddleware(), ordersManage, h.DeleteFleetOrderAddon)
+	fleet.Post("/order/process/:processType/:order_id", helper.JWTAuthorizationMiddleware(), ordersManage, h.ProcessFleetOrder)
+	fleet.Post("/order/cancel", helper.JWTAuthorizationMiddleware(), ordersCancel, h.CancelPartnerOrder)
+	fleet.Post("/order/cancelation-detail", helper.JWTAuthorizationMiddleware(), ordersView, h.CancelPartnerOrderDetail)
+	fleet.Get("/order/cancelation-requests", helper.JWTAuthorizationMiddleware(), ordersView, h.GetCancellationRequests)
+	fleet.Post("/order/cancelation-request/approve", helper.JWTAuthorizationMiddleware(), ordersCancel, h.ApproveCancellationRequest)
+	fleet.Post("/order/cancelation-request/reject", helper.JWTAuthorizationMiddleware(), ordersCancel, h.RejectCancellationRequest)
 }
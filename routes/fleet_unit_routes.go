@@ -2,6 +2,7 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
 	"service-travego/repository"
@@ -15,17 +16,35 @@ func SetupFleetUnitRoutes(api fiber.Router, db *sql.DB, driver string) {
 	partnerRepo := repository.NewPartnerRepository(db, driver)
 	orgRepo := repository.NewOrganizationRepository(db, driver)
 	srv := service.NewFleetUnitService(repo, partnerRepo, orgRepo)
+	srv.SetEntitlementService(service.NewEntitlementService(repository.NewEntitlementRepository(db, driver)))
 	h := handler.NewFleetUnitHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	fleetManage := helper.RequirePermission(orgUserRepo, configs.PermissionFleetManage)
+	financeView := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceView)
 
 	services := api.Group("/services")
 	units := services.Group("/fleet-units")
 
 	units.Get("", helper.JWTAuthorizationMiddleware(), h.List)
-	units.Post("/create", helper.JWTAuthorizationMiddleware(), h.Create)
-	units.Post("/update", helper.JWTAuthorizationMiddleware(), h.Update)
+	units.Post("/create", helper.JWTAuthorizationMiddleware(), fleetManage, h.Create)
+	units.Post("/update", helper.JWTAuthorizationMiddleware(), fleetManage, h.Update)
 	units.Get("/detail/:unit_id", helper.JWTAuthorizationMiddleware(), h.Detail)
-	units.Post("/revenue", helper.JWTAuthorizationMiddleware(), h.UnitRevenue)
-	units.Post("/expenses", helper.JWTAuthorizationMiddleware(), h.UnitExpenses)
+	units.Post("/revenue", helper.JWTAuthorizationMiddleware(), financeView, h.UnitRevenue)
+	units.Post("/expenses", helper.JWTAuthorizationMiddleware(), financeView, h.UnitExpenses)
+
+	// Odometer & maintenance
+	units.Post("/odometer", helper.JWTAuthorizationMiddleware(), h.RecordOdometer)
+	units.Get("/odometer/:unit_id", helper.JWTAuthorizationMiddleware(), h.OdometerHistory)
+	units.Get("/service-plans", helper.JWTAuthorizationMiddleware(), h.ServicePlans)
+	units.Post("/service-plans/create", helper.JWTAuthorizationMiddleware(), fleetManage, h.CreateServicePlan)
+	units.Post("/service-plans/update", helper.JWTAuthorizationMiddleware(), fleetManage, h.UpdateServicePlan)
+	units.Post("/service-plans/delete", helper.JWTAuthorizationMiddleware(), fleetManage, h.DeleteServicePlan)
+	units.Get("/maintenance", helper.JWTAuthorizationMiddleware(), h.MaintenanceHistory)
+	units.Get("/maintenance/detail/:maintenance_id", helper.JWTAuthorizationMiddleware(), h.MaintenanceDetail)
+	units.Post("/maintenance/create", helper.JWTAuthorizationMiddleware(), fleetManage, h.CreateMaintenance)
+	units.Post("/maintenance/check-in", helper.JWTAuthorizationMiddleware(), fleetManage, h.CheckInMaintenance)
+	units.Post("/maintenance/complete", helper.JWTAuthorizationMiddleware(), fleetManage, h.CompleteMaintenance)
+	units.Post("/maintenance/cancel", helper.JWTAuthorizationMiddleware(), fleetManage, h.CancelMaintenance)
 
 	fleetUnits := api.Group("/fleet-units")
 	fleetUnits.Post("/order/history", helper.JWTAuthorizationMiddleware(), h.OrderHistory)
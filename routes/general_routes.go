@@ -30,6 +30,7 @@ func SetupGeneralRoutes(api fiber.Router, db *sql.DB, driver string) {
 	pcRepo := repository.NewPreferenceCityRepository(db, driver)
 	pcService := service.NewPreferenceCityService(pcRepo, "config/location.json")
 	generalHandler.SetPreferenceCityService(pcService)
+	generalHandler.SetOrganizationUserRepository(repository.NewOrganizationUserRepository(db, driver))
 
 	orgRepo := repository.NewOrganizationRepository(db, driver)
 
@@ -37,7 +38,7 @@ func SetupGeneralRoutes(api fiber.Router, db *sql.DB, driver string) {
 	general := api.Group("/general")
 	general.Get("/config", generalHandler.GetGeneralConfig)
 	general.Get("/bank-list", generalHandler.GetBankList)
-	general.Get("/web-menu", generalHandler.GetWebMenu)
+	general.Get("/web-menu", helper.OptionalJWTAuthorizationMiddleware(), generalHandler.GetWebMenu)
 	general.Get("/fuel-type", generalHandler.GetFuelTypes)
 	general.Get("/fleet-transmission", generalHandler.GetFleetTransmissions)
 	general.Get("/contract-type", generalHandler.GetContractTypes)
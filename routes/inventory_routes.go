@@ -2,56 +2,62 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
-	"service-travego/internal/wagy"
 	"service-travego/repository"
 	"service-travego/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupInventoryRoutes(api fiber.Router, db *sql.DB, driver string, notificationService *service.NotificationService, wagyClient *wagy.WagyClient) {
+func SetupInventoryRoutes(api fiber.Router, db *sql.DB, driver string, notificationService *service.NotificationService) {
 	repo := repository.NewInventoryRepository(db, driver)
 	srv := service.NewInventoryService(repo, notificationService)
+	srv.SetAuditService(service.NewAuditService(repository.NewAuditLogRepository(db, driver)))
 
 	h := handler.NewInventoryHandler(srv)
-	h.SetWagyClient(wagyClient)
+	h.SetOutboxService(service.NewOutboxService(repository.NewOutboxRepository(db, driver)))
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	inventoryView := helper.RequirePermission(orgUserRepo, configs.PermissionInventoryView)
+	inventoryManage := helper.RequirePermission(orgUserRepo, configs.PermissionInventoryManage)
+	inventoryApprove := helper.RequirePermission(orgUserRepo, configs.PermissionInventoryApprove)
 
 	inventories := api.Group("/inventories")
 
 	items := inventories.Group("/items")
-	items.Get("/", helper.JWTAuthorizationMiddleware(), h.GetItems)
-	items.Get("/all", helper.JWTAuthorizationMiddleware(), h.GetAllItems)
-	items.Get("/generate-sku", helper.JWTAuthorizationMiddleware(), h.GenerateSKU)
-	items.Post("/create", helper.JWTAuthorizationMiddleware(), h.CreateItem)
-	items.Post("/update", helper.JWTAuthorizationMiddleware(), h.UpdateItem)
-	items.Post("/delete", helper.JWTAuthorizationMiddleware(), h.DeleteItem)
-	items.Post("/transfer", helper.JWTAuthorizationMiddleware(), h.TransferItem)
-	items.Post("/detail", helper.JWTAuthorizationMiddleware(), h.GetItemDetail)
-	items.Post("/order-history", helper.JWTAuthorizationMiddleware(), h.GetItemOrderHistory)
-	items.Post("/movement", helper.JWTAuthorizationMiddleware(), h.GetItemMovements)
+	items.Get("/", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetItems)
+	items.Get("/all", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetAllItems)
+	items.Get("/generate-sku", helper.JWTAuthorizationMiddleware(), inventoryManage, h.GenerateSKU)
+	items.Post("/create", helper.JWTAuthorizationMiddleware(), inventoryManage, h.CreateItem)
+	items.Post("/update", helper.JWTAuthorizationMiddleware(), inventoryManage, h.UpdateItem)
+	items.Post("/delete", helper.JWTAuthorizationMiddleware(), inventoryManage, h.DeleteItem)
+	items.Post("/transfer", helper.JWTAuthorizationMiddleware(), inventoryManage, h.TransferItem)
+	items.Post("/detail", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetItemDetail)
+	items.Post("/order-history", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetItemOrderHistory)
+	items.Post("/movement", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetItemMovements)
+	items.Get("/movement/export", helper.JWTAuthorizationMiddleware(), inventoryView, h.ExportItemMovements)
 
 	request := inventories.Group("/request")
-	request.Get("/list", helper.JWTAuthorizationMiddleware(), h.GetRequests)
-	request.Post("/create", helper.JWTAuthorizationMiddleware(), h.CreateRequest)
-	request.Post("/detail", helper.JWTAuthorizationMiddleware(), h.GetRequestDetail)
-	request.Post("/update", helper.JWTAuthorizationMiddleware(), h.UpdateRequest)
-	request.Post("/submit-orders", helper.JWTAuthorizationMiddleware(), h.SubmitRequestOrders)
-	request.Post("/approve", helper.JWTAuthorizationMiddleware(), h.ApproveRequest)
-	request.Post("/completed", helper.JWTAuthorizationMiddleware(), h.CompleteRequest)
-	request.Post("/reject", helper.JWTAuthorizationMiddleware(), h.RejectRequest)
+	request.Get("/list", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetRequests)
+	request.Post("/create", helper.JWTAuthorizationMiddleware(), inventoryManage, h.CreateRequest)
+	request.Post("/detail", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetRequestDetail)
+	request.Post("/update", helper.JWTAuthorizationMiddleware(), inventoryManage, h.UpdateRequest)
+	request.Post("/submit-orders", helper.JWTAuthorizationMiddleware(), inventoryManage, h.SubmitRequestOrders)
+	request.Post("/approve", helper.JWTAuthorizationMiddleware(), inventoryApprove, h.ApproveRequest)
+	request.Post("/completed", helper.JWTAuthorizationMiddleware(), inventoryManage, h.CompleteRequest)
+	request.Post("/reject", helper.JWTAuthorizationMiddleware(), inventoryApprove, h.RejectRequest)
 
 	supliers := inventories.Group("/supliers")
-	supliers.Get("/list", helper.JWTAuthorizationMiddleware(), h.GetSuppliers)
-	supliers.Post("/create", helper.JWTAuthorizationMiddleware(), h.CreateSupplier)
-	supliers.Post("/detail", helper.JWTAuthorizationMiddleware(), h.GetSupplierDetail)
-	supliers.Post("/delete", helper.JWTAuthorizationMiddleware(), h.DeleteSupplier)
+	supliers.Get("/list", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetSuppliers)
+	supliers.Post("/create", helper.JWTAuthorizationMiddleware(), inventoryManage, h.CreateSupplier)
+	supliers.Post("/detail", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetSupplierDetail)
+	supliers.Post("/delete", helper.JWTAuthorizationMiddleware(), inventoryManage, h.DeleteSupplier)
 
 	orders := inventories.Group("/orders")
-	orders.Get("/list", helper.JWTAuthorizationMiddleware(), h.GetOrders)
-	orders.Post("/submit", helper.JWTAuthorizationMiddleware(), h.SubmitOrder)
-	orders.Post("/detail", helper.JWTAuthorizationMiddleware(), h.GetOrderDetail)
-	orders.Post("/completed", helper.JWTAuthorizationMiddleware(), h.CompleteOrder)
-	orders.Post("/canceled", helper.JWTAuthorizationMiddleware(), h.CancelOrder)
+	orders.Get("/list", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetOrders)
+	orders.Post("/submit", helper.JWTAuthorizationMiddleware(), inventoryManage, h.SubmitOrder)
+	orders.Post("/detail", helper.JWTAuthorizationMiddleware(), inventoryView, h.GetOrderDetail)
+	orders.Post("/completed", helper.JWTAuthorizationMiddleware(), inventoryManage, h.CompleteOrder)
+	orders.Post("/canceled", helper.JWTAuthorizationMiddleware(), inventoryManage, h.CancelOrder)
 }
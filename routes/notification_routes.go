@@ -2,8 +2,10 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/config"
 	"service-travego/handler"
 	"service-travego/helper"
+	"service-travego/internal/paymentgateway"
 	"service-travego/repository"
 	"service-travego/service"
 
@@ -11,15 +13,19 @@ import (
 )
 
 // SetupNotificationRoutes mendaftarkan route untuk webhook publik
-func SetupNotificationRoutes(app *fiber.App, db *sql.DB, driver string) {
+func SetupNotificationRoutes(app *fiber.App, db *sql.DB, driver string, midtransCfg *config.MidtransConfig, gateways *paymentgateway.Registry) {
 	paymentRepo := repository.NewPaymentRepository(db, driver)
 	orgRepo := repository.NewOrganizationRepository(db, driver)
-	paymentSvc := service.NewPaymentService(paymentRepo, orgRepo, nil)
+	outbox := service.NewOutboxService(repository.NewOutboxRepository(db, driver))
+	paymentSvc := service.NewPaymentService(paymentRepo, orgRepo, midtransCfg, gateways, outbox)
 	paymentHandler := handler.NewPaymentHandler(paymentSvc)
-	notificationSvc := service.NewNotificationService(db, driver)
+	notificationSvc := service.NewNotificationService(repository.NewNotificationRepository(db, driver))
 	notificationHandler := handler.NewNotificationHandler(notificationSvc)
 
+	// Webhook per payment gateway; /api/notification/payment tetap untuk Midtrans
 	app.Post("/api/notification/payment", paymentHandler.HandlePaymentNotification)
+	app.Post("/api/notification/payment/midtrans", paymentHandler.HandlePaymentNotification)
+	app.Post("/api/notification/payment/xendit", paymentHandler.HandleXenditNotification)
 
 	notifications := app.Group("/api/notifications")
 	notifications.Get("/all", helper.JWTAuthorizationMiddleware(), notificationHandler.GetAllNotifications)
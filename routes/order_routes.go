@@ -5,42 +5,66 @@ import (
 	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
+	"service-travego/internal/paymentgateway"
 	"service-travego/repository"
 	"service-travego/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupOrderRoutes(api fiber.Router, db *sql.DB, driver string, cfg *configs.Config) {
+func SetupOrderRoutes(api fiber.Router, db *sql.DB, driver string, cfg *configs.Config, gateways *paymentgateway.Registry) {
 	fleetRepo := repository.NewFleetRepository(db, driver)
 	orgRepo := repository.NewOrganizationRepository(db, driver)
 	contentRepo := repository.NewContentRepository(db, driver)
+	outbox := service.NewOutboxService(repository.NewOutboxRepository(db, driver))
 	orderService := service.NewOrderService(fleetRepo, contentRepo, orgRepo, &cfg.Email)
+	orderService.SetOutboxService(outbox)
+	orderService.SetPriceRuleService(service.NewPriceRuleService(repository.NewPriceRuleRepository(db, driver), fleetRepo))
+	orderService.SetVoucherService(service.NewVoucherService(repository.NewVoucherRepository(db, driver)))
+	paymentPlanService := service.NewPaymentPlanService(repository.NewPaymentPlanRepository(db, driver))
+	orderService.SetPaymentPlanService(paymentPlanService)
+	orderService.SetPaymentGateways(gateways, repository.NewPaymentRepository(db, driver))
+	orderService.SetCancellationPolicyService(service.NewCancellationPolicyService(repository.NewCancellationPolicyRepository(db, driver), fleetRepo))
 	orderHandler := handler.NewOrderHandler(orderService)
+	paymentPlanHandler := handler.NewPaymentPlanHandler(paymentPlanService)
 	// Reuse fleet repository for partner order listing handler
 	fleetService := service.NewFleetService(fleetRepo)
+	fleetService.SetAuditService(service.NewAuditService(repository.NewAuditLogRepository(db, driver)))
 	fleetHandler := handler.NewFleetHandler(fleetService, orgRepo)
+	fleetHandler.SetOutboxService(outbox)
 
 	orderGroup := api.Group("/order")
-	orderGroup.Use(helper.DualAuthMiddleware(orgRepo))
-	orderGroup.Post("/fleet/summary", orderHandler.GetFleetOrderSummary)
-	orderGroup.Post("/fleet/create", orderHandler.CreateOrder)
-	orderGroup.Post("/fleet/payment", orderHandler.CreateOrderPayment)
-	orderGroup.Get("/fleet/list", orderHandler.GetOrderList)
-	orderGroup.Get("/fleet/detail/:encryptOrderId", orderHandler.GetOrderDetail)
-	orderGroup.Post("/fleet/detail", orderHandler.GetFleetOrderDetailByPrefix)
-	orderGroup.Get("/fleet/find/:order_id", orderHandler.FindOrder)
-	orderGroup.Post("/payment-confirmation", orderHandler.ConfirmPayment)
-	orderGroup.Post("/payment/confirmation/upload", orderHandler.UploadPaymentEvidence)
-	orderGroup.Get("/payment-method", orderHandler.GetPaymentMethods)
+	orderGroup.Use(helper.DualAuthMiddleware(orgRepo, configs.APIKeyScopeOrdersRead, configs.APIKeyScopeOrdersCreate,
+		configs.APIKeyScopeOrdersCancel, configs.APIKeyScopePaymentsWrite))
+	ordersRead := helper.RequireAPIKeyScope(configs.APIKeyScopeOrdersRead)
+	ordersCreate := helper.RequireAPIKeyScope(configs.APIKeyScopeOrdersCreate)
+	ordersCancel := helper.RequireAPIKeyScope(configs.APIKeyScopeOrdersCancel)
+	paymentsWrite := helper.RequireAPIKeyScope(configs.APIKeyScopePaymentsWrite)
+	orderGroup.Post("/fleet/summary", ordersCreate, orderHandler.GetFleetOrderSummary)
+	orderGroup.Post("/fleet/create", ordersCreate, orderHandler.CreateOrder)
+	orderGroup.Post("/fleet/payment", ordersCreate, orderHandler.CreateOrderPayment)
+	orderGroup.Get("/fleet/list", ordersRead, orderHandler.GetOrderList)
+	orderGroup.Get("/fleet/detail/:encryptOrderId", ordersRead, orderHandler.GetOrderDetail)
+	orderGroup.Post("/fleet/detail", ordersRead, orderHandler.GetFleetOrderDetailByPrefix)
+	orderGroup.Get("/fleet/find/:order_id", ordersRead, orderHandler.FindOrder)
+	orderGroup.Post("/fleet/cancel-quote", ordersCancel, orderHandler.GetCancellationQuote)
+	orderGroup.Post("/fleet/cancel-request", ordersCancel, orderHandler.RequestCancellation)
+	orderGroup.Post("/payment-confirmation", paymentsWrite, orderHandler.ConfirmPayment)
+	orderGroup.Post("/payment/confirmation/upload", paymentsWrite, orderHandler.UploadPaymentEvidence)
+	orderGroup.Get("/payment-method", ordersCreate, orderHandler.GetPaymentMethods)
+	orderGroup.Get("/payment-plans", ordersCreate, paymentPlanHandler.ListActive)
 
 	// Move /api/services/fleet/orders registration here to keep path consistent
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	ordersView := helper.RequirePermission(orgUserRepo, configs.PermissionOrdersView)
+	ordersManage := helper.RequirePermission(orgUserRepo, configs.PermissionOrdersManage)
 	services := api.Group("/services")
 	fleet := services.Group("/fleet")
-	fleet.Get("/orders", helper.JWTAuthorizationMiddleware(), fleetHandler.GetPartnerOrderList)
+	fleet.Get("/orders", helper.JWTAuthorizationMiddleware(), ordersView, fleetHandler.GetPartnerOrderList)
+	fleet.Get("/orders/export", helper.JWTAuthorizationMiddleware(), ordersView, fleetHandler.ExportPartnerOrderList)
 
 	orderServices := services.Group("/order")
-	orderServices.Post("/payment", helper.JWTAuthorizationMiddleware(), orderHandler.CreateServiceOrderPayment)
-	orderServices.Post("/payment-history", helper.JWTAuthorizationMiddleware(), orderHandler.GetServiceOrderPaymentHistory)
-	orderServices.Get("/list", helper.JWTAuthorizationMiddleware(), orderHandler.GetServiceOrderList)
+	orderServices.Post("/payment", helper.JWTAuthorizationMiddleware(), ordersManage, orderHandler.CreateServiceOrderPayment)
+	orderServices.Post("/payment-history", helper.JWTAuthorizationMiddleware(), ordersView, orderHandler.GetServiceOrderPaymentHistory)
+	orderServices.Get("/list", helper.JWTAuthorizationMiddleware(), ordersView, orderHandler.GetServiceOrderList)
 }
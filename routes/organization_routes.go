@@ -5,8 +5,7 @@ import (
 	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
-	"service-travego/internal/waai"
-	"service-travego/internal/wagy"
+	"service-travego/internal/paymentgateway"
 	"service-travego/repository"
 	"service-travego/service"
 
@@ -14,7 +13,7 @@ import (
 )
 
 // SetupOrganizationRoutes configures organization routes
-func SetupOrganizationRoutes(api fiber.Router, db *sql.DB, driver string, cfg *configs.Config) {
+func SetupOrganizationRoutes(api fiber.Router, db *sql.DB, driver string, cfg *configs.Config, gateways *paymentgateway.Registry) {
 	// Initialize repositories
 	orgRepo := repository.NewOrganizationRepository(db, driver)
 	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
@@ -27,8 +26,14 @@ func SetupOrganizationRoutes(api fiber.Router, db *sql.DB, driver string, cfg *c
 	orgService.SetOrganizationUserRepository(orgUserRepo)
 	orgService.SetOrganizationTypeRepository(orgTypeRepo)
 	orgService.SetSubscriptionRepository(subscriptionRepo)
-	notificationSvc := service.NewNotificationService(db, driver)
+	orgService.SetPaymentGateways(gateways)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(db, driver))
+	orgService.SetAuditService(auditService)
+	orgService.SetEntitlementService(service.NewEntitlementService(repository.NewEntitlementRepository(db, driver)))
+	notificationSvc := service.NewNotificationService(repository.NewNotificationRepository(db, driver))
+	outbox := service.NewOutboxService(repository.NewOutboxRepository(db, driver))
 	orgJoinService := service.NewOrganizationJoinService(orgRepo, orgUserRepo, userRepo, notificationSvc, &cfg.Email)
+	orgJoinService.SetOutboxService(outbox)
 	orgTypeService := service.NewOrganizationTypeService(orgTypeRepo)
 	garageService := service.NewGarageService(repository.NewGarageRepository(db, driver))
 	garageHandler := handler.NewGarageHandler(garageService)
@@ -36,32 +41,37 @@ func SetupOrganizationRoutes(api fiber.Router, db *sql.DB, driver string, cfg *c
 	// Initialize handlers
 	authService := service.NewAuthService(userRepo, &cfg.Email)
 	authService.SetOrganizationUserRepository(orgUserRepo)
+	authService.SetOutboxService(outbox)
 	orgHandler := handler.NewOrganizationHandler(orgService)
+	settingsManage := helper.RequirePermission(orgUserRepo, configs.PermissionSettingsManage)
+	fleetManage := helper.RequirePermission(orgUserRepo, configs.PermissionFleetManage)
 	orgHandler.SetAuthService(authService)
 	orgHandler.SetJoinService(orgJoinService)
 	orgHandler.SetOrganizationTypeService(orgTypeService)
-
-	// Set WagyClient if config is available
-	waaiCfg := waai.LoadConfig()
-	if waaiCfg.WagyDeviceID != "" && waaiCfg.WagyToken != "" {
-		wagyClient := wagy.NewWagyClient(waaiCfg.WagyDeviceID, waaiCfg.WagyToken)
-		orgHandler.SetWagyClient(wagyClient)
-	}
+	orgHandler.SetAuditService(auditService)
+	orgHandler.SetOutboxService(outbox)
 
 	// Organization routes
 	organization := api.Group("/organization")
 	organization.Post("/create", helper.JWTAuthorizationMiddleware(), orgHandler.CreateOrganization)
 	organization.Post("/join", helper.JWTAuthorizationMiddleware(), orgHandler.JoinOrganization)
 	organization.Get("/api-config", helper.JWTAuthorizationMiddleware(), orgHandler.GetAPIConfig)
+	organization.Get("/api-config/keys", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.ListAPIKeys)
+	organization.Post("/api-config/keys/create", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.CreateAPIKey)
+	organization.Post("/api-config/keys/rotate", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.RotateAPIKey)
+	organization.Post("/api-config/keys/revoke", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.RevokeAPIKey)
 	organization.Post("/update/domain-url", helper.JWTAuthorizationMiddleware(), orgHandler.UpdateDomainURL)
+	organization.Get("/payment-gateway", helper.JWTAuthorizationMiddleware(), orgHandler.GetPaymentGateway)
+	organization.Post("/update/payment-gateway", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.UpdatePaymentGateway)
 	organization.Get("/bank-accounts", helper.JWTAuthorizationMiddleware(), orgHandler.GetBankAccounts)
 	organization.Get("/detail", helper.JWTAuthorizationMiddleware(), orgHandler.GetOrganizationDetail)
+	organization.Get("/audit", helper.JWTAuthorizationMiddleware(), helper.RequirePermission(orgUserRepo, configs.PermissionAuditView), orgHandler.ListAuditLogs)
 	organization.Get("/employee/whatsapp/:employee_id", helper.JWTAuthorizationMiddleware(), orgHandler.EmployeeWhatsApp)
-	organization.Post("/update", helper.JWTAuthorizationMiddleware(), orgHandler.UpdateOrganizationDetail)
-	organization.Post("/update/logo", helper.JWTAuthorizationMiddleware(), orgHandler.UpdateOrganizationLogo)
-	organization.Post("/bank-account/create", helper.JWTAuthorizationMiddleware(), orgHandler.CreateBankAccount)
-	organization.Post("/bank-account/update", helper.JWTAuthorizationMiddleware(), orgHandler.UpdateBankAccount)
-	organization.Post("/bank-account/delete", helper.JWTAuthorizationMiddleware(), orgHandler.DeleteBankAccount)
+	organization.Post("/update", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.UpdateOrganizationDetail)
+	organization.Post("/update/logo", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.UpdateOrganizationLogo)
+	organization.Post("/bank-account/create", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.CreateBankAccount)
+	organization.Post("/bank-account/update", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.UpdateBankAccount)
+	organization.Post("/bank-account/delete", helper.JWTAuthorizationMiddleware(), settingsManage, orgHandler.DeleteBankAccount)
 	organization.Get("/types", orgHandler.GetOrganizationTypes)
 	organization.Get("/users", helper.JWTAuthorizationMiddleware(), orgHandler.GetUsers)
 	organization.Put("/join/:action/:user_id", helper.JWTAuthorizationMiddleware(), orgHandler.HandleJoinAction)
@@ -75,7 +85,7 @@ func SetupOrganizationRoutes(api fiber.Router, db *sql.DB, driver string, cfg *c
 	organization.Post("/assistant/whatsapp-business/update", helper.JWTAuthorizationMiddleware(), orgHandler.AssistantWhatsAppBusinessUpdate)
 	// Garage routes
 	organization.Get("/garage/list", helper.JWTAuthorizationMiddleware(), garageHandler.GetGarages)
-	organization.Post("/garage/create", helper.JWTAuthorizationMiddleware(), garageHandler.CreateGarage)
-	organization.Post("/garage/update", helper.JWTAuthorizationMiddleware(), garageHandler.UpdateGarage)
-	organization.Post("/garage/delete", helper.JWTAuthorizationMiddleware(), garageHandler.DeleteGarage)
+	organization.Post("/garage/create", helper.JWTAuthorizationMiddleware(), fleetManage, garageHandler.CreateGarage)
+	organization.Post("/garage/update", helper.JWTAuthorizationMiddleware(), fleetManage, garageHandler.UpdateGarage)
+	organization.Post("/garage/delete", helper.JWTAuthorizationMiddleware(), fleetManage, garageHandler.DeleteGarage)
 }
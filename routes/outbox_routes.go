@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/internal/supervisor"
+	"service-travego/internal/wagy"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupOutboxRoutes starts the worker sending queued WhatsApp messages and
+// emails and registers the routes Travego staff inspect the queue with
+func SetupOutboxRoutes(api fiber.Router, db *sql.DB, driver string, emailCfg *configs.EmailConfig, wagyClient *wagy.WagyClient) {
+	repo := repository.NewOutboxRepository(db, driver)
+	worker := service.NewOutboxWorker(repo, wagyClient, emailCfg)
+	supervisor.Loop("outbox", worker.Run)
+
+	h := handler.NewOutboxHandler(service.NewOutboxService(repo))
+
+	outbox := api.Group("/system/outbox")
+	outbox.Get("/", helper.JWTAuthorizationMiddleware(), h.GetOutboxMessages)
+	outbox.Post("/:message_id/resend", helper.JWTAuthorizationMiddleware(), h.ResendOutboxMessage)
+}
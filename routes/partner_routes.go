@@ -13,6 +13,7 @@ import (
 func SetupPartnerRoutes(api fiber.Router, db *sql.DB, driver string) {
 	repo := repository.NewPartnerRepository(db, driver)
 	srv := service.NewPartnerService(repo)
+	srv.SetEntitlementService(service.NewEntitlementService(repository.NewEntitlementRepository(db, driver)))
 	h := handler.NewPartnerHandler(srv)
 
 	services := api.Group("/services")
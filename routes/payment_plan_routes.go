@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupPaymentPlanRoutes(api fiber.Router, db *sql.DB, driver string) {
+	srv := service.NewPaymentPlanService(repository.NewPaymentPlanRepository(db, driver))
+	h := handler.NewPaymentPlanHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	financeManage := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceManage)
+
+	services := api.Group("/services")
+	plans := services.Group("/payment-plans")
+
+	plans.Get("", helper.JWTAuthorizationMiddleware(), h.List)
+	plans.Get("/order/:order_id", helper.JWTAuthorizationMiddleware(), h.OrderInstallments)
+	plans.Post("/create", helper.JWTAuthorizationMiddleware(), financeManage, h.Create)
+	plans.Post("/update", helper.JWTAuthorizationMiddleware(), financeManage, h.Update)
+	plans.Post("/delete", helper.JWTAuthorizationMiddleware(), financeManage, h.Delete)
+	plans.Post("/apply", helper.JWTAuthorizationMiddleware(), financeManage, h.Apply)
+}
@@ -5,6 +5,7 @@ import (
 	"service-travego/config"
 	"service-travego/handler"
 	"service-travego/helper"
+	"service-travego/internal/paymentgateway"
 	"service-travego/repository"
 	"service-travego/service"
 
@@ -12,10 +13,11 @@ import (
 )
 
 // SetupPaymentRoutes mendaftarkan route untuk integrasi payment
-func SetupPaymentRoutes(api fiber.Router, db *sql.DB, driver string, midtransCfg *config.MidtransConfig) {
+func SetupPaymentRoutes(api fiber.Router, db *sql.DB, driver string, midtransCfg *config.MidtransConfig, gateways *paymentgateway.Registry) {
 	repo := repository.NewPaymentRepository(db, driver)
 	orgRepo := repository.NewOrganizationRepository(db, driver)
-	svc := service.NewPaymentService(repo, orgRepo, midtransCfg)
+	outbox := service.NewOutboxService(repository.NewOutboxRepository(db, driver))
+	svc := service.NewPaymentService(repo, orgRepo, midtransCfg, gateways, outbox)
 	h := handler.NewPaymentHandler(svc)
 
 	serviceGroup := api.Group("/services")
@@ -25,4 +27,9 @@ func SetupPaymentRoutes(api fiber.Router, db *sql.DB, driver string, midtransCfg
 
 	paymentGroup.Post("/submit", h.CreatePayment)
 	paymentGroup.Post("/notification", h.PaymentNotifications)
+
+	// Notifikasi payment gateway yang tersimpan, untuk staff Travego
+	systemGroup := api.Group("/system/payment")
+	systemGroup.Get("/notifications", helper.JWTAuthorizationMiddleware(), h.GetPaymentNotifications)
+	systemGroup.Post("/notifications/:notification_id/replay", helper.JWTAuthorizationMiddleware(), h.ReplayPaymentNotification)
 }
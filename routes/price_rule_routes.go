@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupPriceRuleRoutes(api fiber.Router, db *sql.DB, driver string) {
+	srv := service.NewPriceRuleService(repository.NewPriceRuleRepository(db, driver), repository.NewFleetRepository(db, driver))
+	h := handler.NewPriceRuleHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	financeManage := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceManage)
+
+	services := api.Group("/services")
+	rules := services.Group("/fleet/price-rules")
+
+	rules.Get("", helper.JWTAuthorizationMiddleware(), h.List)
+	rules.Post("/create", helper.JWTAuthorizationMiddleware(), financeManage, h.Create)
+	rules.Post("/update", helper.JWTAuthorizationMiddleware(), financeManage, h.Update)
+	rules.Post("/delete", helper.JWTAuthorizationMiddleware(), financeManage, h.Delete)
+	rules.Post("/quote", helper.JWTAuthorizationMiddleware(), h.Quote)
+}
@@ -1,13 +1,16 @@
 package routes
 
 import (
-	"log"
+	"log/slog"
 	"service-travego/config"
 	"service-travego/configs"
 	"service-travego/database"
 	"service-travego/helper"
+	"service-travego/internal/pdfrender"
+	"service-travego/internal/supervisor"
 	"service-travego/internal/waai"
 	"service-travego/internal/wagy"
+	"service-travego/repository"
 	"service-travego/service"
 
 	cronjobs "service-travego/cron"
@@ -28,51 +31,80 @@ func SetupRoutes(app *fiber.App, cfg *configs.Config) {
 
 	// Health check
 	api.Get("/health", func(c *fiber.Ctx) error {
+		jobs := supervisor.Current()
+		if jobs.State != supervisor.StateRunning {
+			return helper.SuccessResponse(c, fiber.StatusServiceUnavailable, "Service is shutting down", fiber.Map{
+				"status": jobs.State,
+				"jobs":   jobs,
+			})
+		}
 		return helper.SuccessResponse(c, fiber.StatusOK, "Service is running", fiber.Map{
 			"status": "ok",
+			"jobs":   jobs,
 		})
 	})
 
+	// Prometheus metrics
+	app.Get("/metrics", helper.MetricsHandler())
+
 	// Initialize Redis
 	_, err = helper.InitRedis(&cfg.Redis)
 	if err != nil {
 		panic("Failed to connect to Redis: " + err.Error())
 	}
+	// Rate limits and lockouts are read when the routes are set up
+	helper.SetSecurityConfig(&cfg.Security)
+	// Organizations whose subscription lapsed past the grace period are read only
+	helper.SetSubscriptionRepository(repository.NewSubscriptionRepository(db, cfg.Database.Driver))
 
 	// Initialize Midtrans
 	midtransCfg := config.InitMidtrans()
+	gateways := config.InitPaymentGateways(midtransCfg)
+	sheetsClient := config.InitGoogleSheets()
 	rdb := helper.GetRedisClient()
 
 	// Initialize services
-	notificationSvc := service.NewNotificationService(db, cfg.Database.Driver)
+	notificationSvc := service.NewNotificationService(repository.NewNotificationRepository(db, cfg.Database.Driver))
+	// Invoices, orders and surat jalan are printed by a shared headless Chrome
+	pdfPool := pdfrender.NewPool(pdfrender.LoadOptions())
+	supervisor.Loop("pdf_renderer", pdfPool.Run)
+	service.SetPDFRenderer(pdfPool)
 
 	// Setup route groups
-	SetupNotificationRoutes(app, db, cfg.Database.Driver) // Register public routes first
-	SetupPricingRoutes(app, db, cfg.Database.Driver)      // Public pricing endpoint - must be before other /services routes
+	SetupNotificationRoutes(app, db, cfg.Database.Driver, midtransCfg, gateways) // Register public routes first
+	// Every route below is limited per client IP; the payment gateway callbacks
+	// above are not, and the auth routes add stricter limits of their own
+	api.Use(helper.RateLimiter("api"))
+	SetupPricingRoutes(app, db, cfg.Database.Driver) // Public pricing endpoint - must be before other /services routes
 	SetupGeneralRoutes(api, db, cfg.Database.Driver)
 	SetupAuthRoutes(api, db, cfg.Database.Driver, cfg)
 	SetupBookingRoutes(api)
-	SetupOrganizationRoutes(api, db, cfg.Database.Driver, cfg)
+	SetupOrganizationRoutes(api, db, cfg.Database.Driver, cfg, gateways)
 	SetupTeamRoutes(api, db, cfg.Database.Driver)
 	SetupEmployeeRoutes(api, db, cfg.Database.Driver)
 	SetupUserRoutes(api, db, cfg.Database.Driver)
-	SetupSubscriptionRoutes(api, db, cfg.Database.Driver, midtransCfg)
+	SetupSubscriptionRoutes(api, db, cfg.Database.Driver, gateways)
 	SetupUploadRoutes(api, db, cfg.Database.Driver)
 	SetupFleetRoutes(api, db, cfg.Database.Driver)
+	SetupPriceRuleRoutes(api, db, cfg.Database.Driver)
+	SetupVoucherRoutes(api, db, cfg.Database.Driver)
+	SetupPaymentPlanRoutes(api, db, cfg.Database.Driver)
+	SetupCancellationPolicyRoutes(api, db, cfg.Database.Driver)
 	SetupFleetUnitRoutes(api, db, cfg.Database.Driver)
 	SetupPartnerRoutes(api, db, cfg.Database.Driver)
 	SetupScheduleRoutes(api, db, cfg.Database.Driver)
+	SetupDocumentRoutes(api, db, cfg.Database.Driver)
 	SetupContentRoutes(api, db, cfg.Database.Driver)
 	SetupServiceRoutes(api, db, cfg.Database.Driver)
 	SetupCustomersRoutes(api, db, cfg.Database.Driver)
 	SetupMessagesRoutes(api, db, cfg.Database.Driver)
-	SetupOrderRoutes(api, db, cfg.Database.Driver, cfg)
+	SetupOrderRoutes(api, db, cfg.Database.Driver, cfg, gateways)
 	SetupDashboardRoutes(api, db, cfg.Database.Driver)
 	SetupTransactionRoutes(api, db, cfg.Database.Driver, notificationSvc)
 	SetupTourPackageRoutes(api, db, cfg.Database.Driver)
 	SetupLeaveManagementRoutes(api, db, cfg.Database.Driver)
 	SetupPrintManagementRoutes(api, db, cfg.Database.Driver)
-	SetupPaymentRoutes(api, db, cfg.Database.Driver, midtransCfg)
+	SetupPaymentRoutes(api, db, cfg.Database.Driver, midtransCfg, gateways)
 	SetupPreferenceCityRoutes(api, db, cfg.Database.Driver)
 	SetupSystemRoutes(api, db, cfg.Database.Driver)
 
@@ -82,16 +114,18 @@ func SetupRoutes(app *fiber.App, cfg *configs.Config) {
 		wagyClient = wagy.NewWagyClient(waaiCfg.WagyDeviceID, waaiCfg.WagyToken)
 	}
 
-	SetupInventoryRoutes(api, db, cfg.Database.Driver, notificationSvc, wagyClient)
+	SetupOutboxRoutes(api, db, cfg.Database.Driver, &cfg.Email, wagyClient)
+	SetupInventoryRoutes(api, db, cfg.Database.Driver, notificationSvc)
 	SetupAssistantRoutes(api, db, cfg.Database.Driver, rdb)
+	SetupSheetSyncRoutes(api, db, cfg.Database.Driver, sheetsClient)
 
 	// Setup WhatsApp AI Assistant module (WAAI)
 	if rdb == nil {
-		log.Printf("Warning: Redis client is nil, WAAI may not work properly")
+		slog.Warn("redis client is nil, the assistant may not work properly")
 	} else {
 		waaiCfg := waai.LoadConfig()
 		if err := waai.RegisterRoutes(app, waaiCfg, db, cfg.Database.Driver, rdb); err != nil {
-			log.Printf("Warning: Failed to register WAAI routes: %v", err)
+			slog.Warn("failed to register assistant routes", "error", err)
 		}
 	}
 
@@ -99,4 +133,13 @@ func SetupRoutes(app *fiber.App, cfg *configs.Config) {
 	cronjobs.StartFleetAvailabilityCron(db, cfg.Database.Driver, wagyClient)
 	// Start unpaid orders cron (every day at 07:00)
 	cronjobs.StartUnpaidOrdersCron(db, cfg.Database.Driver, wagyClient)
+	// Start document expiry reminder cron (every day at 08:00)
+	cronjobs.StartDocumentExpiryCron(db, cfg.Database.Driver, wagyClient)
+	// Start Midtrans payment reconciliation & refund cron (every 30 minutes)
+	cronjobs.StartPaymentReconcileCron(db, cfg.Database.Driver, midtransCfg)
+	// Start subscription lifecycle cron: reminders, grace period, read-only mode (every day at 00:30)
+	cronjobs.StartSubscriptionLifecycleCron(db, cfg.Database.Driver)
+	// Start Google Sheets sync of orders, payments and expenses (every 15 minutes)
+	cronjobs.StartSheetSyncCron(db, cfg.Database.Driver, sheetsClient)
+	supervisor.Start()
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
 	"service-travego/repository"
@@ -13,15 +14,22 @@ import (
 func SetupScheduleRoutes(api fiber.Router, db *sql.DB, driver string) {
 	repo := repository.NewScheduleRepository(db, driver)
 	srv := service.NewScheduleService(repo)
+	srv.SetDocumentRepository(repository.NewDocumentRepository(db, driver))
+	srv.SetLeaveRepository(repository.NewLeaveManagementRepository(db, driver))
+	srv.SetAuditService(service.NewAuditService(repository.NewAuditLogRepository(db, driver)))
 	h := handler.NewScheduleHandler(srv, db, driver)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	fleetManage := helper.RequirePermission(orgUserRepo, configs.PermissionFleetManage)
 
 	services := api.Group("/services")
 	schedule := services.Group("/schedule")
-	schedule.Post("/create", helper.JWTAuthorizationMiddleware(), h.Create)
-	schedule.Post("/update", helper.JWTAuthorizationMiddleware(), h.Update)
+	schedule.Post("/create", helper.JWTAuthorizationMiddleware(), fleetManage, h.Create)
+	schedule.Post("/update", helper.JWTAuthorizationMiddleware(), fleetManage, h.Update)
+	schedule.Post("/auto-assign", helper.JWTAuthorizationMiddleware(), fleetManage, h.AutoAssign)
 	schedule.Get("/fleet", helper.JWTAuthorizationMiddleware(), h.GetFleetSchedule)
+	schedule.Get("/fleet/export", helper.JWTAuthorizationMiddleware(), h.ExportFleetSchedule)
 	schedule.Get("/fleet-trip/detail/:schedule_number", helper.JWTAuthorizationMiddleware(), h.GetFleetTripDetail)
-	schedule.Post("/fleet-trip/update", helper.JWTAuthorizationMiddleware(), h.UpdateFleetTrip)
+	schedule.Post("/fleet-trip/update", helper.JWTAuthorizationMiddleware(), fleetManage, h.UpdateFleetTrip)
 	schedule.Post("/fleet/availibility", helper.JWTAuthorizationMiddleware(), h.GetFleetAvailability)
 	schedule.Post("/daily-availibility/fleet", helper.JWTAuthorizationMiddleware(), h.GetDailyAvailabilityFleet)
 	schedule.Post("/daily-availibility/fleet-unit", helper.JWTAuthorizationMiddleware(), h.GetDailyAvailabilityFleetUnit)
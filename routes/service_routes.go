@@ -3,6 +3,7 @@ package routes
 import (
 	"database/sql"
 	"os"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
 	"service-travego/repository"
@@ -25,6 +26,7 @@ func SetupServiceRoutes(api fiber.Router, db *sql.DB, driver string) {
 	tourRepo := repository.NewTourPackageRepository(db, driver)
 
 	srv := service.NewFleetService(repo)
+	srv.SetAuditService(service.NewAuditService(repository.NewAuditLogRepository(db, driver)))
 	custSrv := service.NewCustomersService(custRepo)
 	tourSrv := service.NewTourPackageService(tourRepo, serviceBaseURL())
 
@@ -39,24 +41,28 @@ func SetupServiceRoutes(api fiber.Router, db *sql.DB, driver string) {
 	// Route: /api/service/fleet
 
 	svcGroup := api.Group("/service")
-	svcGroup.Use(helper.DualAuthMiddleware(orgRepo))
-	svcGroup.Get("/fleet", h.GetServiceFleets)
-	svcGroup.Post("/fleet/detail", h.GetServiceFleetDetail)
-	svcGroup.Post("/fleet/availibility", h.GetServiceFleetAvailibility)
-	svcGroup.Post("/fleet/order/availibility", h.OrderAvailability)
-	svcGroup.Get("/fleet/addon/:fleetid", h.GetServiceFleetAddons)
-	svcGroup.Get("/available-city", h.GetAvailableCities)
+	svcGroup.Use(helper.DualAuthMiddleware(orgRepo, configs.APIKeyScopeAvailabilityRead,
+		configs.APIKeyScopeReviewsCreate, configs.APIKeyScopeDocumentsPrint))
+	availabilityRead := helper.RequireAPIKeyScope(configs.APIKeyScopeAvailabilityRead)
+	reviewsCreate := helper.RequireAPIKeyScope(configs.APIKeyScopeReviewsCreate)
+	documentsPrint := helper.RequireAPIKeyScope(configs.APIKeyScopeDocumentsPrint)
+	svcGroup.Get("/fleet", availabilityRead, h.GetServiceFleets)
+	svcGroup.Post("/fleet/detail", availabilityRead, h.GetServiceFleetDetail)
+	svcGroup.Post("/fleet/availibility", availabilityRead, h.GetServiceFleetAvailibility)
+	svcGroup.Post("/fleet/order/availibility", availabilityRead, h.OrderAvailability)
+	svcGroup.Get("/fleet/addon/:fleetid", availabilityRead, h.GetServiceFleetAddons)
+	svcGroup.Get("/available-city", availabilityRead, h.GetAvailableCities)
 
 	// customers
-	svcGroup.Post("/customer/availibility", h.CheckCustomerAvailibility)
+	svcGroup.Post("/customer/availibility", availabilityRead, h.CheckCustomerAvailibility)
 
-	svcGroup.Post("/review/submit", h.SubmitReview)
+	svcGroup.Post("/review/submit", reviewsCreate, h.SubmitReview)
 
 	// tour packages
-	svcGroup.Get("/tour-packages", tourH.GetTourPackages)
-	svcGroup.Post("/tour-packages/detail", tourH.TourPackageDetail)
+	svcGroup.Get("/tour-packages", availabilityRead, tourH.GetTourPackages)
+	svcGroup.Post("/tour-packages/detail", availabilityRead, tourH.TourPackageDetail)
 
 	// Public Print Document
-	svcGroup.Post("/print/fleet/order", pmH.GenerateOrderFleetDocument)
-	svcGroup.Post("/print/fleet/invoice", pmH.GenerateFleetInvoiceDocument)
+	svcGroup.Post("/print/fleet/order", documentsPrint, pmH.GenerateOrderFleetDocument)
+	svcGroup.Post("/print/fleet/invoice", documentsPrint, pmH.GenerateFleetInvoiceDocument)
 }
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/internal/sheetsapi"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupSheetSyncRoutes registers the routes an organization sets up and
+// resyncs its Google Sheet with
+func SetupSheetSyncRoutes(api fiber.Router, db *sql.DB, driver string, client *sheetsapi.Client) {
+	srv := service.NewSheetSyncService(repository.NewSheetSyncRepository(db, driver), client)
+	h := handler.NewSheetSyncHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	financeView := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceView)
+	financeManage := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceManage)
+
+	sheets := api.Group("/services/integrations/google-sheets")
+	sheets.Get("/", helper.JWTAuthorizationMiddleware(), financeView, h.GetSheetSync)
+	sheets.Post("/", helper.JWTAuthorizationMiddleware(), financeManage, h.ConfigureSheetSync)
+	sheets.Post("/sync", helper.JWTAuthorizationMiddleware(), financeManage, h.SyncSheet)
+	sheets.Post("/resync", helper.JWTAuthorizationMiddleware(), financeManage, h.ResyncSheet)
+}
@@ -2,9 +2,9 @@ package routes
 
 import (
 	"database/sql"
-	"service-travego/config"
 	"service-travego/handler"
 	"service-travego/helper"
+	"service-travego/internal/paymentgateway"
 	"service-travego/repository"
 	"service-travego/service"
 
@@ -12,7 +12,7 @@ import (
 )
 
 // SetupSubscriptionRoutes configures subscription routes
-func SetupSubscriptionRoutes(api fiber.Router, db *sql.DB, driver string, midtransCfg *config.MidtransConfig) {
+func SetupSubscriptionRoutes(api fiber.Router, db *sql.DB, driver string, gateways *paymentgateway.Registry) {
 	orgRepo := repository.NewOrganizationRepository(db, driver)
 	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
 	subscriptionRepo := repository.NewSubscriptionRepository(db, driver)
@@ -21,13 +21,16 @@ func SetupSubscriptionRoutes(api fiber.Router, db *sql.DB, driver string, midtra
 	subscriptionService.SetOrganizationUserRepository(orgUserRepo)
 	subscriptionService.SetOrganizationRepository(orgRepo)
 	subscriptionService.SetPaymentRepository(&paymentRepo)
-	subscriptionService.SetMidtransConfig(midtransCfg)
+	subscriptionService.SetPaymentGateways(gateways)
 	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	subscriptionHandler.SetEntitlementService(service.NewEntitlementService(repository.NewEntitlementRepository(db, driver)))
 
 	// account routes
 	account := api.Group("/account")
 	account.Get("/subscription", helper.JWTAuthorizationMiddleware(), subscriptionHandler.GetSubscription)
 	account.Get("/subscription/history", helper.JWTAuthorizationMiddleware(), subscriptionHandler.GetSubscriptionHistory)
+	account.Get("/subscription/usage", helper.JWTAuthorizationMiddleware(), subscriptionHandler.GetSubscriptionUsage)
+	account.Get("/subscription/events", helper.JWTAuthorizationMiddleware(), subscriptionHandler.GetSubscriptionEvents)
 
 	subscriptionGroup := api.Group("/subscription")
 	subscriptionGroup.Post("/submit", helper.JWTAuthorizationMiddleware(), subscriptionHandler.SubmitSubscription)
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
 	"service-travego/repository"
@@ -12,20 +13,27 @@ import (
 
 func SetupTeamRoutes(api fiber.Router, db *sql.DB, driver string) {
 	orgRepo := repository.NewOrganizationRepository(db, driver)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
 	userRepo := repository.NewUserRepository(db, driver)
 	orgService := service.NewOrganizationService(orgRepo, userRepo)
+	orgService.SetOrganizationUserRepository(orgUserRepo)
 	orgHandler := handler.NewOrganizationHandler(orgService)
 
+	teamManage := helper.RequirePermission(orgUserRepo, configs.PermissionTeamManage)
+
 	services := api.Group("/services")
 	team := services.Group("/team")
 
 	team.Get("/divisions", helper.JWTAuthorizationMiddleware(), orgHandler.TeamListDivisions)
-	team.Post("/divisions/create", helper.JWTAuthorizationMiddleware(), orgHandler.TeamCreateDivision)
-	team.Post("/divisions/update", helper.JWTAuthorizationMiddleware(), orgHandler.TeamUpdateDivision)
-	team.Post("/divisions/delete", helper.JWTAuthorizationMiddleware(), orgHandler.TeamDeleteDivision)
+	team.Post("/divisions/create", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamCreateDivision)
+	team.Post("/divisions/update", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamUpdateDivision)
+	team.Post("/divisions/delete", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamDeleteDivision)
 
 	team.Get("/roles", helper.JWTAuthorizationMiddleware(), orgHandler.TeamListRoles)
-	team.Post("/roles/create", helper.JWTAuthorizationMiddleware(), orgHandler.TeamCreateRole)
-	team.Post("/roles/update", helper.JWTAuthorizationMiddleware(), orgHandler.TeamUpdateRole)
-	team.Post("/roles/delete", helper.JWTAuthorizationMiddleware(), orgHandler.TeamDeleteRole)
+	team.Post("/roles/create", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamCreateRole)
+	team.Post("/roles/update", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamUpdateRole)
+	team.Post("/roles/delete", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamDeleteRole)
+
+	team.Get("/permissions", helper.JWTAuthorizationMiddleware(), helper.LoadPermissions(orgUserRepo), orgHandler.TeamListPermissions)
+	team.Post("/members/role", helper.JWTAuthorizationMiddleware(), teamManage, orgHandler.TeamAssignMemberRole)
 }
@@ -13,6 +13,7 @@ import (
 func SetupTourPackageRoutes(api fiber.Router, db *sql.DB, driver string) {
 	repo := repository.NewTourPackageRepository(db, driver)
 	srv := service.NewTourPackageService(repo, "")
+	srv.SetVoucherService(service.NewVoucherService(repository.NewVoucherRepository(db, driver)))
 	h := handler.NewTourPackageHandler(srv)
 
 	services := api.Group("/services")
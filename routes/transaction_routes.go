@@ -2,6 +2,7 @@ package routes
 
 import (
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/handler"
 	"service-travego/helper"
 	"service-travego/repository"
@@ -13,17 +14,23 @@ import (
 func SetupTransactionRoutes(api fiber.Router, db *sql.DB, driver string, notificationSvc *service.NotificationService) {
 	repo := repository.NewTransactionRepository(db, driver)
 	srv := service.NewTransactionService(repo, notificationSvc)
+	srv.SetAuditService(service.NewAuditService(repository.NewAuditLogRepository(db, driver)))
 	h := handler.NewTransactionHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	financeView := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceView)
+	financeManage := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceManage)
 
 	services := api.Group("/services")
 	transactions := services.Group("/transactions")
 
-	transactions.Get("/revenue", helper.JWTAuthorizationMiddleware(), h.ListAllRevenue)
-	transactions.Get("/expenses", helper.JWTAuthorizationMiddleware(), h.ListAllExpenses)
-	transactions.Post("/create", helper.JWTAuthorizationMiddleware(), h.CreateManualRevenue)
-	transactions.Post("/expenses/submit", helper.JWTAuthorizationMiddleware(), h.SubmitExpenseTransaction)
-	transactions.Post("/expenses/delete", helper.JWTAuthorizationMiddleware(), h.DeleteExpenseTransaction)
-	transactions.Post("/expenses/update", helper.JWTAuthorizationMiddleware(), h.UpdateExpenseTransaction)
+	transactions.Get("/revenue", helper.JWTAuthorizationMiddleware(), financeView, h.ListAllRevenue)
+	transactions.Get("/expenses", helper.JWTAuthorizationMiddleware(), financeView, h.ListAllExpenses)
+	transactions.Get("/revenue/export", helper.JWTAuthorizationMiddleware(), financeView, h.ExportRevenue)
+	transactions.Get("/expenses/export", helper.JWTAuthorizationMiddleware(), financeView, h.ExportExpenses)
+	transactions.Post("/create", helper.JWTAuthorizationMiddleware(), financeManage, h.CreateManualRevenue)
+	transactions.Post("/expenses/submit", helper.JWTAuthorizationMiddleware(), financeManage, h.SubmitExpenseTransaction)
+	transactions.Post("/expenses/delete", helper.JWTAuthorizationMiddleware(), financeManage, h.DeleteExpenseTransaction)
+	transactions.Post("/expenses/update", helper.JWTAuthorizationMiddleware(), financeManage, h.UpdateExpenseTransaction)
 	transactions.Get("/labels", helper.JWTAuthorizationMiddleware(), h.ListTransactionLabels)
 	transactions.Get("/types", helper.JWTAuthorizationMiddleware(), h.GetTransactionTypes)
 	transactions.Get("/fleet-trip", helper.JWTAuthorizationMiddleware(), h.GetFleetTripSummary)
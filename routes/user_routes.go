@@ -21,6 +21,7 @@ func SetupUserRoutes(api fiber.Router, db *sql.DB, driver string) {
 	userService := service.NewUserService(userRepo)
 	userService.SetOrganizationUserRepository(orgUserRepo)
 	userService.SetOrganizationRepository(orgRepo)
+	userService.SetOutboxService(service.NewOutboxService(repository.NewOutboxRepository(db, driver)))
 
 	// Initialize handlers
 	userHandler := handler.NewUserHandler(userService)
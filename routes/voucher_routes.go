@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"database/sql"
+	"service-travego/configs"
+	"service-travego/handler"
+	"service-travego/helper"
+	"service-travego/repository"
+	"service-travego/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupVoucherRoutes(api fiber.Router, db *sql.DB, driver string) {
+	srv := service.NewVoucherService(repository.NewVoucherRepository(db, driver))
+	h := handler.NewVoucherHandler(srv)
+	orgUserRepo := repository.NewOrganizationUserRepository(db, driver)
+	financeManage := helper.RequirePermission(orgUserRepo, configs.PermissionFinanceManage)
+
+	services := api.Group("/services")
+	vouchers := services.Group("/vouchers")
+
+	vouchers.Get("", helper.JWTAuthorizationMiddleware(), h.List)
+	vouchers.Get("/redemptions/:voucher_id", helper.JWTAuthorizationMiddleware(), h.Redemptions)
+	vouchers.Post("/create", helper.JWTAuthorizationMiddleware(), financeManage, h.Create)
+	vouchers.Post("/update", helper.JWTAuthorizationMiddleware(), financeManage, h.Update)
+	vouchers.Post("/delete", helper.JWTAuthorizationMiddleware(), financeManage, h.Delete)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"service-travego/model"
+	"service-travego/repository"
+
+	"github.com/google/uuid"
+)
+
+// AuditService records who changed what in an organization
+type AuditService struct {
+	repo *repository.AuditLogRepository
+}
+
+func NewAuditService(repo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// AuditEntry describes one mutation. Before and After are snapshots of the
+// entity (structs or maps); Before is nil for a create and After is nil for a
+// delete.
+type AuditEntry struct {
+	ActorID    string
+	EntityType string
+	EntityID   string
+	Action     string
+	Before     interface{}
+	After      interface{}
+}
+
+// auditRedactedFields are never written to the audit log
+var auditRedactedFields = []string{"password", "secret", "token", "key_hash", "server_key", "api_key"}
+
+// toAuditFields flattens a snapshot into its JSON fields
+func toAuditFields(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.IsNil() {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		var value interface{}
+		_ = json.Unmarshal(data, &value)
+		return map[string]interface{}{"value": value}
+	}
+	for k := range fields {
+		name := strings.ToLower(k)
+		for _, redacted := range auditRedactedFields {
+			if strings.Contains(name, redacted) {
+				fields[k] = "[redacted]"
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// auditDiff returns the fields that differ between two snapshots
+func auditDiff(before, after interface{}) map[string]model.AuditChange {
+	b, a := toAuditFields(before), toAuditFields(after)
+	changes := map[string]model.AuditChange{}
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok && a != nil {
+			// fields missing from the after snapshot were not part of the change
+			continue
+		}
+		if !reflect.DeepEqual(bv, av) {
+			changes[k] = model.AuditChange{Before: bv, After: av}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; ok {
+			continue
+		}
+		if b != nil && av == nil {
+			continue
+		}
+		changes[k] = model.AuditChange{Before: nil, After: av}
+	}
+	return changes
+}
+
+// Record stores an audit log entry in the organization of ctx. Failures are
+// logged and never fail the mutation itself; an update that changed nothing is
+// not recorded.
+func (s *AuditService) Record(ctx context.Context, e AuditEntry) {
+	organizationID := organizationFromContext(ctx)
+	if s == nil || s.repo == nil || organizationID == "" {
+		return
+	}
+	changes := auditDiff(e.Before, e.After)
+	if e.Action == model.AuditActionUpdate && len(changes) == 0 {
+		return
+	}
+	entry := &model.AuditLog{
+		AuditID:        uuid.New().String(),
+		OrganizationID: organizationID,
+		ActorID:        e.ActorID,
+		EntityType:     e.EntityType,
+		EntityID:       e.EntityID,
+		Action:         e.Action,
+		Changes:        changes,
+	}
+	if err := s.repo.Create(ctx, entry, time.Now()); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit log", "organization_id", organizationID, "entity_type", e.EntityType, "entity_id", e.EntityID, "action", e.Action, "error", err)
+	}
+}
+
+// List returns a page of the organization's audit log
+func (s *AuditService) List(ctx context.Context, filter model.AuditLogFilter) (*model.AuditLogList, error) {
+	filter.EntityType = strings.TrimSpace(filter.EntityType)
+	filter.EntityID = strings.TrimSpace(filter.EntityID)
+	filter.ActorID = strings.TrimSpace(filter.ActorID)
+	filter.Action = strings.ToLower(strings.TrimSpace(filter.Action))
+	for _, d := range []string{filter.StartDate, filter.EndDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date and end_date must be YYYY-MM-DD")
+		}
+	}
+	if filter.ActorID != "" {
+		if _, err := uuid.Parse(filter.ActorID); err != nil {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "actor_id must be a user id")
+		}
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage < 1 || filter.PerPage > 100 {
+		filter.PerPage = 20
+	}
+
+	items, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list audit logs", "organization_id", organizationFromContext(ctx), "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get audit logs")
+	}
+	return &model.AuditLogList{Items: items, Page: filter.Page, PerPage: filter.PerPage, Total: total}, nil
+}
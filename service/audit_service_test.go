@@ -0,0 +1,58 @@
+package service
+
+import "testing"
+
+type auditTestEntity struct {
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Password string  `json:"password"`
+}
+
+func TestAuditDiffCreateAndDelete(t *testing.T) {
+	e := auditTestEntity{Name: "Bus A", Price: 100}
+
+	created := auditDiff(nil, e)
+	if len(created) != 3 || created["name"].Before != nil || created["name"].After != "Bus A" {
+		t.Fatalf("unexpected create diff %+v", created)
+	}
+
+	var none *auditTestEntity
+	deleted := auditDiff(e, none)
+	if len(deleted) != 3 || deleted["price"].Before != float64(100) || deleted["price"].After != nil {
+		t.Fatalf("unexpected delete diff %+v", deleted)
+	}
+}
+
+func TestAuditDiffUpdateKeepsChangedFieldsOnly(t *testing.T) {
+	before := auditTestEntity{Name: "Bus A", Price: 100}
+	after := auditTestEntity{Name: "Bus A", Price: 120}
+
+	changes := auditDiff(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("expected only price to change, got %+v", changes)
+	}
+	if c := changes["price"]; c.Before != float64(100) || c.After != float64(120) {
+		t.Fatalf("unexpected price change %+v", c)
+	}
+
+	if changes := auditDiff(before, before); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestAuditDiffRedactsSecrets(t *testing.T) {
+	changes := auditDiff(
+		auditTestEntity{Password: "old"},
+		auditTestEntity{Password: "new"},
+	)
+	if len(changes) != 0 {
+		t.Fatalf("a changed secret must not be written out, got %+v", changes)
+	}
+
+	created := auditDiff(nil, map[string]interface{}{"api_key": "trv_live_123", "server_key": "x"})
+	for field, c := range created {
+		if c.After != "[redacted]" {
+			t.Fatalf("%s was not redacted: %+v", field, c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"service-travego/helper"
+)
+
+func tooManyAttempts(retryAfter time.Duration) error {
+	return NewServiceError(ErrTooManyAttempts, http.StatusTooManyRequests, (&helper.AttemptLimitError{RetryAfter: retryAfter}).Error())
+}
+
+// checkLoginLock rejects a login while the account is locked. Redis errors
+// are logged and let the login through.
+func (s *AuthService) checkLoginLock(ctx context.Context, userID string) error {
+	locked, err := helper.LoginLockedFor(userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check login lockout", "user_id", userID, "error", err)
+		return nil
+	}
+	if locked > 0 {
+		slog.InfoContext(ctx, "login attempt on locked account", "user_id", userID)
+		return tooManyAttempts(locked)
+	}
+	return nil
+}
+
+// loginFailed counts a wrong password or two-factor code and returns the
+// error for the client: a lockout once too many failed, otherwise invalid.
+func (s *AuthService) loginFailed(ctx context.Context, userID string, invalid error) error {
+	lockedFor, err := helper.RecordLoginFailure(userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record failed login", "user_id", userID, "error", err)
+		return invalid
+	}
+	if lockedFor > 0 {
+		slog.InfoContext(ctx, "account locked after failed logins", "user_id", userID)
+		return tooManyAttempts(lockedFor)
+	}
+	return invalid
+}
+
+// otpAttemptError converts an OTP limit from helper into a service error
+func otpAttemptError(err error) error {
+	var limitErr *helper.AttemptLimitError
+	if errors.As(err, &limitErr) {
+		return tooManyAttempts(limitErr.RetryAfter)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"service-travego/helper"
+)
+
+func TestOTPAttemptError(t *testing.T) {
+	err := otpAttemptError(&helper.AttemptLimitError{RetryAfter: 90 * time.Second})
+	if GetStatusCode(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %v", err)
+	}
+	if err.Error() != "too many attempts, try again in 2 minutes" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if otpAttemptError(errors.New("redis down")) != nil {
+		t.Fatal("other errors should not be reported as a limit")
+	}
+}
@@ -1,22 +1,27 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"service-travego/configs"
+	"service-travego/database"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/repository"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type AuthService struct {
 	userRepo               *repository.UserRepository
 	orgUserRepo            *repository.OrganizationUserRepository
 	emailCfg               *configs.EmailConfig
+	outbox                 *OutboxService
 	authTokenExpiryMinutes int
 }
 
@@ -33,52 +38,58 @@ func (s *AuthService) SetOrganizationUserRepository(orgUserRepo *repository.Orga
 	s.orgUserRepo = orgUserRepo
 }
 
-// CreateSubscription calls the repository to create a subscription for an organization
-func (s *AuthService) CreateSubscription(organizationID string) error {
+// SetOutboxService sets the outbox the account emails are queued in
+func (s *AuthService) SetOutboxService(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
+// CreateSubscription calls the repository to create a subscription for the
+// organization a user was just registered with
+func (s *AuthService) CreateSubscription(ctx context.Context, organizationID string) error {
 	if s.orgUserRepo == nil {
 		return fmt.Errorf("organization user repository not initialized")
 	}
-	return s.orgUserRepo.CreateSubscription(organizationID)
+	return s.orgUserRepo.CreateSubscription(database.WithOrganization(ctx, organizationID))
 }
 
-func (s *AuthService) Register(username, fullname, email, password, phone string) (*model.User, string, error) {
+func (s *AuthService) Register(ctx context.Context, username, fullname, email, password, phone string) (*model.User, string, error) {
 	// Normalize email to lowercase for consistent checking
 	email = strings.ToLower(strings.TrimSpace(email))
 
 	// Check if email already exists
-	existingUser, err := s.userRepo.FindByEmail(email)
+	existingUser, err := s.userRepo.FindByEmail(ctx, email)
 	if err == nil && existingUser != nil {
-		log.Printf("[INFO] Register attempt with existing email - Email: %s", email)
+		slog.InfoContext(ctx, "register attempt with existing email", "email", email)
 		return nil, "", NewServiceError(ErrEmailExists, http.StatusBadRequest, "email already exists")
 	}
 	if err != nil && err != sql.ErrNoRows {
-		log.Printf("[ERROR] Error checking email existence - Email: %s, Error: %v", email, err)
+		slog.ErrorContext(ctx, "error checking email existence", "email", email, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate email")
 	}
 
 	// Check if username already exists
-	existingUsername, err := s.userRepo.FindByUsername(username)
+	existingUsername, err := s.userRepo.FindByUsername(ctx, username)
 	if err == nil && existingUsername != nil {
-		log.Printf("[INFO] Register attempt with existing username - Username: %s", username)
+		slog.InfoContext(ctx, "register attempt with existing username", "username", username)
 		return nil, "", NewServiceError(ErrUsernameExists, http.StatusBadRequest, "username already exists")
 	}
 	if err != nil && err != sql.ErrNoRows {
-		log.Printf("[ERROR] Error checking username existence - Username: %s, Error: %v", username, err)
+		slog.ErrorContext(ctx, "error checking username existence", "username", username, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate username")
 	}
 
 	// Normalize phone number: if starts with 0, replace with 62
 	normalizedPhone := helper.NormalizePhoneNumber(phone)
-	log.Printf("[INFO] Phone normalization - Original: %s, Normalized: %s", phone, normalizedPhone)
+	slog.InfoContext(ctx, "phone normalization", "original", phone, "normalized", normalizedPhone)
 
 	// Check if phone already exists (after normalization)
-	existingPhone, err := s.userRepo.FindByPhone(normalizedPhone)
+	existingPhone, err := s.userRepo.FindByPhone(ctx, normalizedPhone)
 	if err == nil && existingPhone != nil {
-		log.Printf("[INFO] Register attempt with existing phone - Phone: %s (normalized: %s)", phone, normalizedPhone)
+		slog.InfoContext(ctx, "register attempt with existing phone", "phone", phone, "normalized", normalizedPhone)
 		return nil, "", NewServiceError(ErrPhoneExists, http.StatusBadRequest, "phone already exists")
 	}
 	if err != nil && err != sql.ErrNoRows {
-		log.Printf("[ERROR] Error checking phone existence - Phone: %s, Error: %v", normalizedPhone, err)
+		slog.ErrorContext(ctx, "error checking phone existence", "phone", normalizedPhone, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate phone")
 	}
 
@@ -100,9 +111,9 @@ func (s *AuthService) Register(username, fullname, email, password, phone string
 		IsVerified: false,
 	}
 
-	user, err = s.userRepo.Create(user)
+	user, err = s.userRepo.Create(ctx, user)
 	if err != nil {
-		log.Printf("[ERROR] Failed to create user - Username: %s, Email: %s, Error: %v", username, email, err)
+		slog.ErrorContext(ctx, "failed to create user", "username", username, "email", email, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create user")
 	}
 
@@ -111,32 +122,44 @@ func (s *AuthService) Register(username, fullname, email, password, phone string
 	// Generate token from email and user_id (will be used as Redis key)
 	registerToken, err := helper.EncryptData(email, userID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate token - Email: %s, UserID: %s, Error: %v", email, userID, err)
+		slog.ErrorContext(ctx, "failed to generate token", "email", email, "user_id", userID, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
 	}
 
 	// Store OTP with token as key (token contains email and user_id)
 	if err = helper.SetOTP(registerToken, otp); err != nil {
-		log.Printf("[ERROR] Failed to store OTP - Token: %s, Error: %v", registerToken, err)
+		slog.ErrorContext(ctx, "failed to store OTP", "token", registerToken, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store OTP")
 	}
 
-	if err = helper.SendOTPEmail(s.emailCfg, email, username, otp); err != nil {
-		log.Printf("[ERROR] Failed to send OTP email - Email: %s, Username: %s, Error: %v", email, username, err)
+	otpEmail, err := helper.NewOTPEmail(email, username, otp)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, "", OutboxKindOTPRegister, otpEmail)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send OTP email", "email", email, "username", username, "error", err)
 		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to send OTP email")
 	}
 
 	return user, registerToken, nil
 }
 
-func (s *AuthService) VerifyOTP(token, otp string) error {
+func (s *AuthService) VerifyOTP(ctx context.Context, token, otp string) error {
 	// Decrypt token to get email and user_id
 	email, userID, err := helper.DecryptData(token)
 	if err != nil {
-		log.Printf("[ERROR] Failed to decrypt token - Error: %v", err)
+		slog.ErrorContext(ctx, "failed to decrypt token", "error", err)
 		return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "invalid token")
 	}
 
+	// Stop guessing once too many wrong OTPs were entered for the token or email
+	if err := helper.CheckOTPAttempts(email, token); err != nil {
+		if limitErr := otpAttemptError(err); limitErr != nil {
+			return limitErr
+		}
+		slog.ErrorContext(ctx, "failed to check OTP attempts", "user_id", userID, "error", err)
+	}
+
 	// Get OTP from Redis using token as key (token contains email and user_id)
 	storedOTP, err := helper.GetOTP(token)
 	if err != nil {
@@ -145,17 +168,20 @@ func (s *AuthService) VerifyOTP(token, otp string) error {
 		if errStr == "redis: nil" || errStr == "redis: nil: key does not exist" {
 			return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "OTP expired")
 		}
-		log.Printf("[ERROR] Failed to get OTP from Redis - UserID: %s, Error: %v", userID, err)
+		slog.ErrorContext(ctx, "failed to get OTP from Redis", "user_id", userID, "error", err)
 		return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "OTP expired")
 	}
 
 	// Check if OTP matches
 	if storedOTP != otp {
+		if err := helper.RecordOTPFailure(email, token); err != nil {
+			slog.ErrorContext(ctx, "failed to record OTP failure", "user_id", userID, "error", err)
+		}
 		return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "missmatch")
 	}
 
 	// Verify user exists
-	user, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -166,33 +192,38 @@ func (s *AuthService) VerifyOTP(token, otp string) error {
 	}
 
 	// Update is_verified to true
-	if err = s.userRepo.VerifyUser(userID); err != nil {
-		log.Printf("[ERROR] Failed to verify user - UserID: %s, Error: %v", userID, err)
+	if err = s.userRepo.VerifyUser(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "failed to verify user", "user_id", userID, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to verify user")
 	}
 
 	// Delete OTP from Redis using token as key
 	helper.DeleteOTP(token)
+	helper.ClearOTPAttempts(email, token)
 
 	// Send success email
-	if err = helper.SendRegisterSuccessEmail(s.emailCfg, email, user.Username); err != nil {
-		log.Printf("[ERROR] Failed to send success email - Email: %s, Error: %v", email, err)
+	successEmail, err := helper.NewRegisterSuccessEmail(email, user.Username)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, "", OutboxKindRegisterSuccess, successEmail)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send success email", "email", email, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to send success email")
 	}
 
 	return nil
 }
 
-func (s *AuthService) ResendOTP(email, token string) (string, error) {
+func (s *AuthService) ResendOTP(ctx context.Context, email, token string) (string, error) {
 	var userEmail, userID string
 	var err error
 
 	// If email is provided, use email directly (no need for token)
 	if email != "" {
 		// Find user by email
-		user, err := s.userRepo.FindByEmail(email)
+		user, err := s.userRepo.FindByEmail(ctx, email)
 		if err != nil {
-			log.Printf("[DEBUG] ResendOTP user not found by email - Email: %s", email)
+			slog.DebugContext(ctx, "resend OTP user not found by email", "email", email)
 			return "", NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 		}
 		userEmail = user.Email
@@ -201,29 +232,29 @@ func (s *AuthService) ResendOTP(email, token string) (string, error) {
 		// If token is provided, decrypt it to get email and user_id
 		userEmail, userID, err = helper.DecryptData(token)
 		if err != nil {
-			log.Printf("[ERROR] Failed to decrypt token - Error: %v", err)
+			slog.ErrorContext(ctx, "failed to decrypt token", "error", err)
 			return "", NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "invalid token")
 		}
 		tp := token
 		if len(tp) > 16 {
 			tp = tp[:8] + "..." + tp[len(tp)-8:]
 		}
-		log.Printf("[DEBUG] ResendOTP token decrypted - TokenPreview: %s, Email: %s, UserID: %s", tp, userEmail, userID)
+		slog.DebugContext(ctx, "resend OTP token decrypted", "token_preview", tp, "email", userEmail, "user_id", userID)
 	} else {
 		return "", NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "either email or token is required")
 	}
 
 	// Find user by email and verify user_id matches
-	user, err := s.userRepo.FindByEmail(userEmail)
+	user, err := s.userRepo.FindByEmail(ctx, userEmail)
 	if err != nil {
-		log.Printf("[DEBUG] ResendOTP user not found on confirm - Email: %s", userEmail)
+		slog.DebugContext(ctx, "resend OTP user not found on confirm", "email", userEmail)
 		return "", NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
 
 	// If using token, verify user_id from decrypt matches user_id in database
 	if token != "" && email == "" {
 		if user.UserID != userID {
-			log.Printf("[DEBUG] ResendOTP user_id mismatch - Decrypted: %s, DB: %s", userID, user.UserID)
+			slog.DebugContext(ctx, "resend OTP user id mismatch", "decrypted_user_id", userID, "user_id", user.UserID)
 			return "", NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 		}
 	}
@@ -238,10 +269,18 @@ func (s *AuthService) ResendOTP(email, token string) (string, error) {
 		return "", NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "user inactive")
 	}
 
+	// Enforce the resend cooldown per email and per token
+	if err := helper.ClaimOTPResend(userEmail, token); err != nil {
+		if limitErr := otpAttemptError(err); limitErr != nil {
+			return "", limitErr
+		}
+		slog.ErrorContext(ctx, "failed to claim OTP resend", "email", userEmail, "error", err)
+	}
+
 	// Generate token from email and user_id (will be used as Redis key)
 	newToken, err := helper.EncryptData(userEmail, userID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate token - Email: %s, UserID: %s, Error: %v", userEmail, userID, err)
+		slog.ErrorContext(ctx, "failed to generate token", "email", userEmail, "user_id", userID, "error", err)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
 	}
 
@@ -250,13 +289,17 @@ func (s *AuthService) ResendOTP(email, token string) (string, error) {
 
 	// Store OTP with token as key (token contains email and user_id)
 	if err = helper.SetOTP(newToken, otp); err != nil {
-		log.Printf("[ERROR] Failed to store OTP - Token: %s, Error: %v", newToken, err)
+		slog.ErrorContext(ctx, "failed to store OTP", "token", newToken, "error", err)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store OTP")
 	}
 
 	// Send OTP email
-	if err = helper.SendOTPEmail(s.emailCfg, userEmail, user.Username, otp); err != nil {
-		log.Printf("[ERROR] Failed to send OTP email - Email: %s, Username: %s, Error: %v", userEmail, user.Username, err)
+	otpEmail, err := helper.NewOTPEmail(userEmail, user.Username, otp)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, "", OutboxKindOTPRegister, otpEmail)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send OTP email", "email", userEmail, "username", user.Username, "error", err)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to send OTP email")
 	}
 
@@ -265,14 +308,21 @@ func (s *AuthService) ResendOTP(email, token string) (string, error) {
 
 // LoginResponse represents login response data
 type LoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
-	Username     string `json:"username"`
-	Fullname     string `json:"fullname"`
-	Avatar       string `json:"avatar"`
+	Token             string `json:"token"`
+	RefreshToken      string `json:"refresh_token"`
+	SessionID         string `json:"session_id"`
+	Username          string `json:"username"`
+	Fullname          string `json:"fullname"`
+	Avatar            string `json:"avatar"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
 }
 
-func (s *AuthService) Login(email, phone, password, userID string) (*LoginResponse, error) {
+// Login authenticates a user by password, or by userID for a user who is
+// already authenticated (e.g. after creating an organization). A password
+// login of a user with two-factor on returns a challenge instead of tokens;
+// see VerifyLoginTwoFactor.
+func (s *AuthService) Login(ctx context.Context, email, phone, password, userID string, device LoginDevice) (*LoginResponse, error) {
 	email = strings.ToLower(strings.TrimSpace(email))
 	phone = strings.TrimSpace(phone)
 	password = strings.TrimSpace(password)
@@ -286,40 +336,43 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 	var err error
 
 	if userID != "" {
-		user, err = s.userRepo.FindByUserID(userID)
+		user, err = s.userRepo.FindByUserID(ctx, userID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return nil, NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 			}
-			log.Printf("[ERROR] Error finding user by user_id - UserID: %s, Error: %v", userID, err)
+			slog.ErrorContext(ctx, "error finding user by user_id", "user_id", userID, "error", err)
 			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
 		}
 	} else {
 		if email != "" {
 
-			user, err = s.userRepo.FindByEmail(email)
+			user, err = s.userRepo.FindByEmail(ctx, email)
 			if err != nil {
 				if err == sql.ErrNoRows {
 					return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid credentials")
 				}
-				log.Printf("[ERROR] Error finding user by email - Email: %s, Error: %v", email, err)
+				slog.ErrorContext(ctx, "error finding user by email", "email", email, "error", err)
 				return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
 			}
 		} else if phone != "" {
 			phone = helper.NormalizePhoneNumber(phone)
-			user, err = s.userRepo.FindByPhone(phone)
+			user, err = s.userRepo.FindByPhone(ctx, phone)
 			if err != nil {
 				if err == sql.ErrNoRows {
 					return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid credentials")
 				}
-				log.Printf("[ERROR] Error finding user by phone - Phone: %s, Error: %v", phone, err)
+				slog.ErrorContext(ctx, "error finding user by phone", "phone", phone, "error", err)
 				return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
 			}
 		}
 
+		if err := s.checkLoginLock(ctx, user.UserID); err != nil {
+			return nil, err
+		}
 		if !helper.CheckPasswordHash(password, user.Password) {
-			log.Printf("[INFO] Invalid password attempt - UserID: %s", user.UserID)
-			return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid credentials")
+			slog.InfoContext(ctx, "invalid password attempt", "user_id", user.UserID)
+			return nil, s.loginFailed(ctx, user.UserID, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid credentials"))
 		}
 	}
 
@@ -331,6 +384,22 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 		return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "user is not verified")
 	}
 
+	if userID == "" {
+		tf, err := s.userRepo.GetTwoFactor(ctx, user.UserID)
+		if err != nil {
+			slog.ErrorContext(ctx, "error getting two-factor settings", "user_id", user.UserID, "error", err)
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
+		}
+		if tf != nil && tf.Enabled {
+			return s.startLoginChallenge(ctx, user, device)
+		}
+	}
+
+	return s.completeLogin(ctx, user, device)
+}
+
+// buildAccessToken generates the access token of a user for a login session
+func (s *AuthService) buildAccessToken(ctx context.Context, user *model.User, sessionID string) (string, error) {
 	organizationID := ""
 	organizationName := ""
 	orgRole := 0
@@ -339,9 +408,9 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 		organizationName = "SuperAdmin"
 	}
 	if s.orgUserRepo != nil && !user.IsAdmin {
-		orgID, role, err := s.orgUserRepo.GetOrganizationAndRoleByUserID(user.UserID)
+		orgID, role, err := s.orgUserRepo.GetOrganizationAndRoleByUserID(ctx, user.UserID)
 		if err != nil && err != sql.ErrNoRows {
-			log.Printf("[ERROR] Error getting organization and role - UserID: %s, Error: %v", user.UserID, err)
+			slog.ErrorContext(ctx, "error getting organization and role", "user_id", user.UserID, "error", err)
 		} else if err == nil {
 			organizationID = orgID
 			orgRole = role
@@ -351,7 +420,7 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 			user.IsAdmin = true
 		}
 
-		orgCode, orgName, _, _, _, err := s.orgUserRepo.GetOrganizationWithJoinDateByUserID(user.UserID)
+		orgCode, orgName, _, _, _, err := s.orgUserRepo.GetOrganizationWithJoinDateByUserID(ctx, user.UserID)
 		if err == nil {
 			organizationName = orgName
 			_ = orgCode
@@ -363,12 +432,13 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 		IsAdmin:          user.IsAdmin,
 		UserID:           user.UserID,
 		OrganizationRole: orgRole,
+		SessionID:        sessionID,
 	}
 
 	encToken, errEnc := helper.EncryptAuthSensitiveData(sensitive)
 	if errEnc != nil {
-		log.Printf("[ERROR] Failed to encrypt auth sensitive data - UserID: %s, Error: %v", user.UserID, errEnc)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
+		slog.ErrorContext(ctx, "failed to encrypt auth sensitive data", "user_id", user.UserID, "error", errEnc)
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
 	}
 
 	token, err := helper.GenerateAuthToken(
@@ -381,23 +451,64 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 		s.authTokenExpiryMinutes,
 	)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate auth token - UserID: %s, Error: %v", user.UserID, err)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
+		slog.ErrorContext(ctx, "failed to generate auth token", "user_id", user.UserID, "error", err)
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
+	}
+	return token, nil
+}
+
+// completeLogin issues the tokens of an authenticated user. It starts a
+// session for the device, or continues device.SessionID when it is active.
+func (s *AuthService) completeLogin(ctx context.Context, user *model.User, device LoginDevice) (*LoginResponse, error) {
+	device = normalizeLoginDevice(device)
+	now := time.Now()
+
+	var session *helper.AuthSession
+	if device.SessionID != "" {
+		existing, err := helper.GetAuthSession(user.UserID, device.SessionID)
+		if err == nil {
+			session = existing
+		} else if err != helper.ErrSessionNotFound {
+			slog.ErrorContext(ctx, "failed to get session", "user_id", user.UserID, "error", err)
+		}
+	}
+	newSession := session == nil
+	if newSession {
+		session = &helper.AuthSession{
+			SessionID:  uuid.New().String(),
+			UserID:     user.UserID,
+			DeviceID:   device.DeviceID,
+			DeviceName: device.DeviceName,
+			CreatedAt:  now,
+		}
+	}
+
+	token, err := s.buildAccessToken(ctx, user, session.SessionID)
+	if err != nil {
+		return nil, err
 	}
 
 	refreshToken, err := helper.GenerateRefreshToken()
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate refresh token - UserID: %s, Error: %v", user.UserID, err)
+		slog.ErrorContext(ctx, "failed to generate refresh token", "user_id", user.UserID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate refresh token")
 	}
-	refreshTTL := 24 * time.Hour
-	if err := helper.SetRefreshToken(user.UserID, refreshToken, refreshTTL); err != nil {
-		log.Printf("[ERROR] Failed to store refresh token - UserID: %s, Error: %v", user.UserID, err)
+	previousRefreshToken := session.RefreshToken
+	session.RefreshToken = refreshToken
+	session.IPAddress = device.IPAddress
+	session.LastSeenAt = now
+	if err := helper.SaveAuthSession(session, sessionTTL); err != nil {
+		slog.ErrorContext(ctx, "failed to store session", "user_id", user.UserID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store refresh token")
 	}
-	if err := helper.SetRefreshTokenReverse(refreshToken, user.UserID, refreshTTL); err != nil {
-		log.Printf("[ERROR] Failed to store refresh token reverse mapping - UserID: %s, Error: %v", user.UserID, err)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store refresh token")
+	if previousRefreshToken != "" {
+		helper.DeleteRefreshTokenSession(previousRefreshToken)
+	}
+	if newSession {
+		s.notifyNewDevice(ctx, user, session)
+	}
+	if err := helper.ClearLoginFailures(user.UserID); err != nil {
+		slog.ErrorContext(ctx, "failed to clear failed logins", "user_id", user.UserID, "error", err)
 	}
 
 	avatar := user.Avatar
@@ -414,6 +525,7 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 	return &LoginResponse{
 		Token:        token,
 		RefreshToken: refreshToken,
+		SessionID:    session.SessionID,
 		Username:     user.Username,
 		Fullname:     user.Name,
 		Avatar:       avatar,
@@ -422,18 +534,18 @@ func (s *AuthService) Login(email, phone, password, userID string) (*LoginRespon
 
 // RequestResetPassword handles password reset request
 // Validates user is active, verified, and not deleted, then sends reset password email
-func (s *AuthService) RequestResetPassword(email string, resetPasswordURL string, expiryMinutes int) error {
+func (s *AuthService) RequestResetPassword(ctx context.Context, email string, resetPasswordURL string, expiryMinutes int) error {
 	// Normalize email to lowercase
 	email = strings.ToLower(strings.TrimSpace(email))
 
 	// Find user by email
-	user, err := s.userRepo.FindByEmail(email)
+	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Don't reveal if user exists or not for security
 			return nil // Return success even if user not found
 		}
-		log.Printf("[ERROR] Error finding user by email for reset password - Email: %s, Error: %v", email, err)
+		slog.ErrorContext(ctx, "error finding user by email for reset password", "email", email, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to process reset password request")
 	}
 
@@ -458,7 +570,7 @@ func (s *AuthService) RequestResetPassword(email string, resetPasswordURL string
 	// Generate reset password token
 	token, err := helper.EncryptResetPasswordToken(email, user.UserID, expiryMinutes)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate reset password token - Email: %s, UserID: %s, Error: %v", email, user.UserID, err)
+		slog.ErrorContext(ctx, "failed to generate reset password token", "email", email, "user_id", user.UserID, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate reset password token")
 	}
 
@@ -466,8 +578,12 @@ func (s *AuthService) RequestResetPassword(email string, resetPasswordURL string
 	resetLink := fmt.Sprintf("%s?token=%s", resetPasswordURL, token)
 
 	// Send reset password email
-	if err = helper.SendResetPasswordEmail(s.emailCfg, email, user.Username, resetLink, expiryMinutes); err != nil {
-		log.Printf("[ERROR] Failed to send reset password email - Email: %s, Username: %s, Error: %v", email, user.Username, err)
+	resetEmail, err := helper.NewResetPasswordEmail(email, user.Username, resetLink, expiryMinutes)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, "", OutboxKindResetPassword, resetEmail)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send reset password email", "email", email, "username", user.Username, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to send reset password email")
 	}
 
@@ -476,14 +592,14 @@ func (s *AuthService) RequestResetPassword(email string, resetPasswordURL string
 
 // UpdatePassword updates user password using reset password token
 // Validates token, expiry, and password match before updating
-func (s *AuthService) UpdatePassword(token, newPassword, confirmPassword string) error {
+func (s *AuthService) UpdatePassword(ctx context.Context, token, newPassword, confirmPassword string) error {
 	// Decrypt token to get email, user_id, and expiry
 	email, userID, expiryTimestamp, err := helper.DecryptResetPasswordToken(token)
 	if err != nil {
 		if err.Error() == "token expired" {
 			return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "reset password link has expired")
 		}
-		log.Printf("[ERROR] Failed to decrypt reset password token - Error: %v", err)
+		slog.ErrorContext(ctx, "failed to decrypt reset password token", "error", err)
 		return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "invalid reset password link")
 	}
 
@@ -504,12 +620,12 @@ func (s *AuthService) UpdatePassword(token, newPassword, confirmPassword string)
 	}
 
 	// Find user by email and user_id
-	user, err := s.userRepo.FindByEmail(email)
+	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 		}
-		log.Printf("[ERROR] Error finding user for password update - Email: %s, UserID: %s, Error: %v", email, userID, err)
+		slog.ErrorContext(ctx, "error finding user for password update", "email", email, "user_id", userID, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update password")
 	}
 
@@ -541,13 +657,13 @@ func (s *AuthService) UpdatePassword(token, newPassword, confirmPassword string)
 	// Hash new password
 	hashedPassword, err := helper.HashPassword(newPassword)
 	if err != nil {
-		log.Printf("[ERROR] Failed to hash password - UserID: %s, Error: %v", userID, err)
+		slog.ErrorContext(ctx, "failed to hash password", "user_id", userID, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update password")
 	}
 
 	// Update password in database
-	if err = s.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
-		log.Printf("[ERROR] Failed to update password in database - UserID: %s, Error: %v", userID, err)
+	if err = s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		slog.ErrorContext(ctx, "failed to update password in database", "user_id", userID, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update password")
 	}
 
@@ -560,30 +676,27 @@ type RefreshTokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
-// RefreshToken validates a refresh token and issues new access + refresh tokens.
-// Implements sliding expiration: each successful refresh resets the 24-hour TTL.
-func (s *AuthService) RefreshToken(refreshToken string) (*RefreshTokenResponse, error) {
+// RefreshToken validates a refresh token and issues new access + refresh tokens
+// for its session. Implements sliding expiration: each successful refresh
+// resets the session's 24-hour TTL.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*RefreshTokenResponse, error) {
 	if refreshToken == "" {
 		return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "refresh token is required")
 	}
 
-	userID, err := helper.GetRefreshTokenUserID(refreshToken)
-	if err != nil {
-		log.Printf("[ERROR] Invalid or expired refresh token - Error: %v", err)
-		return nil, NewServiceError(ErrInvalidCredentials, http.StatusUnauthorized, "invalid or expired refresh token")
+	session, err := helper.GetAuthSessionByRefreshToken(refreshToken)
+	if err == helper.ErrSessionNotFound {
+		session, err = s.migrateLegacyRefreshToken(refreshToken)
 	}
-
-	// Validate the stored token matches
-	storedToken, err := helper.GetRefreshToken(userID)
-	if err != nil || storedToken != refreshToken {
-		log.Printf("[ERROR] Refresh token mismatch or expired - UserID: %s, Error: %v", userID, err)
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid or expired refresh token", "error", err)
 		return nil, NewServiceError(ErrInvalidCredentials, http.StatusUnauthorized, "invalid or expired refresh token")
 	}
 
 	// Find user to regenerate access token
-	user, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
 	if err != nil {
-		log.Printf("[ERROR] User not found for refresh - UserID: %s, Error: %v", userID, err)
+		slog.ErrorContext(ctx, "user not found for refresh", "user_id", session.UserID, "error", err)
 		return nil, NewServiceError(ErrUserNotFound, http.StatusUnauthorized, "user not found")
 	}
 
@@ -591,81 +704,25 @@ func (s *AuthService) RefreshToken(refreshToken string) (*RefreshTokenResponse,
 		return nil, NewServiceError(ErrInvalidCredentials, http.StatusUnauthorized, "user is inactive or not verified")
 	}
 
-	// Get organization info (same logic as Login())
-	organizationID := ""
-	organizationName := ""
-	orgRole := 0
-	if user.IsAdmin {
-		organizationID = "00"
-		organizationName = "SuperAdmin"
-	}
-	if s.orgUserRepo != nil && !user.IsAdmin {
-		orgID, role, err := s.orgUserRepo.GetOrganizationAndRoleByUserID(user.UserID)
-		if err != nil && err != sql.ErrNoRows {
-			log.Printf("[ERROR] Error getting organization and role - UserID: %s, Error: %v", user.UserID, err)
-		} else if err == nil {
-			organizationID = orgID
-			orgRole = role
-		}
-
-		if role == 1 {
-			user.IsAdmin = true
-		}
-
-		orgCode, orgName, _, _, _, err := s.orgUserRepo.GetOrganizationWithJoinDateByUserID(user.UserID)
-		if err == nil {
-			organizationName = orgName
-			_ = orgCode
-		}
-	}
-
-	// Generate new access token (same payload as Login())
-	sensitive := helper.AuthSensitiveData{
-		OrganizationID:   organizationID,
-		IsAdmin:          user.IsAdmin,
-		UserID:           user.UserID,
-		OrganizationRole: orgRole,
-	}
-	encToken, errEnc := helper.EncryptAuthSensitiveData(sensitive)
-	if errEnc != nil {
-		log.Printf("[ERROR] Failed to encrypt auth sensitive data on refresh - UserID: %s, Error: %v", user.UserID, errEnc)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
-	}
-
-	newAccessToken, err := helper.GenerateAuthToken(
-		user.Name,
-		organizationName,
-		organizationID,
-		user.Email,
-		user.Username,
-		encToken,
-		s.authTokenExpiryMinutes,
-	)
+	newAccessToken, err := s.buildAccessToken(ctx, user, session.SessionID)
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate auth token on refresh - UserID: %s, Error: %v", user.UserID, err)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate token")
+		return nil, err
 	}
 
 	// Generate new refresh token (rotate) and store with sliding 24h TTL
 	newRefreshToken, err := helper.GenerateRefreshToken()
 	if err != nil {
-		log.Printf("[ERROR] Failed to generate new refresh token - UserID: %s, Error: %v", user.UserID, err)
+		slog.ErrorContext(ctx, "failed to generate new refresh token", "user_id", user.UserID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate refresh token")
 	}
 
-	refreshTTL := 24 * time.Hour
-	// Delete old reverse mapping and forward mapping
-	helper.DeleteRefreshTokenReverse(refreshToken)
-	// Store new refresh token (forward: userID -> token)
-	if err := helper.SetRefreshToken(user.UserID, newRefreshToken, refreshTTL); err != nil {
-		log.Printf("[ERROR] Failed to store new refresh token - UserID: %s, Error: %v", user.UserID, err)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store refresh token")
-	}
-	// Store reverse mapping (token -> userID)
-	if err := helper.SetRefreshTokenReverse(newRefreshToken, user.UserID, refreshTTL); err != nil {
-		log.Printf("[ERROR] Failed to store refresh token reverse mapping - UserID: %s, Error: %v", user.UserID, err)
+	session.RefreshToken = newRefreshToken
+	session.LastSeenAt = time.Now()
+	if err := helper.SaveAuthSession(session, sessionTTL); err != nil {
+		slog.ErrorContext(ctx, "failed to store session", "user_id", user.UserID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store refresh token")
 	}
+	helper.DeleteRefreshTokenSession(refreshToken)
 
 	return &RefreshTokenResponse{
 		Token:        newAccessToken,
@@ -673,8 +730,40 @@ func (s *AuthService) RefreshToken(refreshToken string) (*RefreshTokenResponse,
 	}, nil
 }
 
-// Logout invalidates the refresh token for the given user.
-func (s *AuthService) Logout(userID string) error {
+// migrateLegacyRefreshToken moves a refresh token issued before login
+// sessions (one per user) into a session of its own
+func (s *AuthService) migrateLegacyRefreshToken(refreshToken string) (*helper.AuthSession, error) {
+	userID, err := helper.GetRefreshTokenUserID(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	storedToken, err := helper.GetRefreshToken(userID)
+	if err != nil || storedToken != refreshToken {
+		return nil, helper.ErrSessionNotFound
+	}
+	helper.DeleteRefreshTokenReverse(refreshToken)
+	helper.DeleteRefreshToken(userID)
+
+	device := normalizeLoginDevice(LoginDevice{})
+	now := time.Now()
+	return &helper.AuthSession{
+		SessionID:  uuid.New().String(),
+		UserID:     userID,
+		DeviceID:   device.DeviceID,
+		DeviceName: device.DeviceName,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}, nil
+}
+
+// Logout ends the given login session. Refresh tokens issued before login
+// sessions are removed too.
+func (s *AuthService) Logout(userID, sessionID string) error {
+	if sessionID != "" {
+		if err := helper.DeleteAuthSession(userID, sessionID); err != nil && err != helper.ErrSessionNotFound {
+			return err
+		}
+	}
 	// Get stored refresh token to delete reverse mapping
 	storedToken, err := helper.GetRefreshToken(userID)
 	if err == nil && storedToken != "" {
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"service-travego/helper"
+	"service-travego/model"
+)
+
+const (
+	// sessionTTL is how long a login session lasts without a refresh
+	sessionTTL = 24 * time.Hour
+	// loginChallengeTTL is how long a user has to enter their second factor
+	loginChallengeTTL = 5 * time.Minute
+	// maxLoginChallengeAttempts is how many wrong codes a challenge accepts
+	maxLoginChallengeAttempts = 5
+)
+
+// LoginDevice identifies the device a login comes from
+type LoginDevice struct {
+	DeviceID   string
+	DeviceName string
+	IPAddress  string
+	// SessionID continues an existing session instead of starting a new one
+	SessionID string
+}
+
+// normalizeLoginDevice fills in a device name and ID when the client sent
+// none; the ID is then derived from the name (usually the User-Agent)
+func normalizeLoginDevice(d LoginDevice) LoginDevice {
+	d.DeviceName = strings.TrimSpace(d.DeviceName)
+	if d.DeviceName == "" {
+		d.DeviceName = "Unknown device"
+	}
+	if len(d.DeviceName) > 200 {
+		d.DeviceName = d.DeviceName[:200]
+	}
+	d.DeviceID = strings.TrimSpace(d.DeviceID)
+	if d.DeviceID == "" {
+		sum := sha256.Sum256([]byte(d.DeviceName))
+		d.DeviceID = hex.EncodeToString(sum[:8])
+	}
+	return d
+}
+
+// notifyNewDevice emails the user when they log in on a device they never
+// used before. The very first login of a user is not reported.
+func (s *AuthService) notifyNewDevice(ctx context.Context, user *model.User, session *helper.AuthSession) {
+	isNew, firstDevice, err := helper.RememberDevice(user.UserID, session.DeviceID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to remember device", "user_id", user.UserID, "error", err)
+		return
+	}
+	if !isNew || firstDevice || s.emailCfg == nil || user.Email == "" {
+		return
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Username
+	}
+	data := helper.NewDeviceLoginEmailData{
+		Username:   name,
+		DeviceName: session.DeviceName,
+		IPAddress:  session.IPAddress,
+		LoginTime:  session.CreatedAt.Format("02 Jan 2006 15:04 MST"),
+	}
+	email, err := helper.NewDeviceLoginEmail(user.Email, data)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, "", OutboxKindNewDeviceLogin, email)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to queue new device login email", "user_id", user.UserID, "error", err)
+	}
+}
+
+// startLoginChallenge holds a password login until the user enters their
+// second factor
+func (s *AuthService) startLoginChallenge(ctx context.Context, user *model.User, device LoginDevice) (*LoginResponse, error) {
+	device = normalizeLoginDevice(device)
+	token, err := helper.GenerateRefreshToken()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate login challenge", "user_id", user.UserID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
+	}
+	challenge := &helper.LoginChallenge{
+		UserID:     user.UserID,
+		DeviceID:   device.DeviceID,
+		DeviceName: device.DeviceName,
+		IPAddress:  device.IPAddress,
+	}
+	if err := helper.SetLoginChallenge(token, challenge, loginChallengeTTL); err != nil {
+		slog.ErrorContext(ctx, "failed to store login challenge", "user_id", user.UserID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
+	}
+	return &LoginResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		Username:          user.Username,
+	}, nil
+}
+
+// VerifyLoginTwoFactor completes a login challenge with a TOTP or recovery
+// code
+func (s *AuthService) VerifyLoginTwoFactor(ctx context.Context, challengeToken, code string) (*LoginResponse, error) {
+	challenge, err := helper.GetLoginChallenge(challengeToken)
+	if err != nil {
+		if err == helper.ErrSessionNotFound {
+			return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "login challenge expired, please log in again")
+		}
+		slog.ErrorContext(ctx, "failed to get login challenge", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		helper.DeleteLoginChallenge(challengeToken)
+		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "login challenge expired, please log in again")
+	}
+	if err := s.checkLoginLock(ctx, user.UserID); err != nil {
+		helper.DeleteLoginChallenge(challengeToken)
+		return nil, err
+	}
+	tf, err := s.userRepo.GetTwoFactor(ctx, user.UserID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error getting two-factor settings", "user_id", user.UserID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to authenticate")
+	}
+
+	ok := true
+	if tf != nil && tf.Enabled {
+		if ok, err = s.verifySecondFactor(ctx, tf, code, true); err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		invalid := s.loginFailed(ctx, user.UserID, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid two-factor code"))
+		if GetStatusCode(invalid) == http.StatusTooManyRequests {
+			helper.DeleteLoginChallenge(challengeToken)
+			return nil, invalid
+		}
+		challenge.Attempts++
+		if challenge.Attempts >= maxLoginChallengeAttempts {
+			helper.DeleteLoginChallenge(challengeToken)
+			return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "too many invalid codes, please log in again")
+		}
+		if err := helper.UpdateLoginChallenge(challengeToken, challenge); err != nil {
+			slog.ErrorContext(ctx, "failed to update login challenge", "user_id", user.UserID, "error", err)
+		}
+		return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid two-factor code")
+	}
+
+	helper.DeleteLoginChallenge(challengeToken)
+	return s.completeLogin(ctx, user, LoginDevice{
+		DeviceID:   challenge.DeviceID,
+		DeviceName: challenge.DeviceName,
+		IPAddress:  challenge.IPAddress,
+	})
+}
+
+// ListSessions returns the devices the user is logged in on
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentSessionID string) ([]model.LoginSession, error) {
+	sessions, err := helper.ListAuthSessions(userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list sessions", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get sessions")
+	}
+	items := make([]model.LoginSession, 0, len(sessions))
+	for _, it := range sessions {
+		items = append(items, model.LoginSession{
+			SessionID:  it.SessionID,
+			DeviceName: it.DeviceName,
+			IPAddress:  it.IPAddress,
+			CreatedAt:  it.CreatedAt.Format("2006-01-02 15:04:05"),
+			LastSeenAt: it.LastSeenAt.Format("2006-01-02 15:04:05"),
+			Current:    it.SessionID == currentSessionID,
+		})
+	}
+	return items, nil
+}
+
+// RevokeSession logs the user out on one device
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := helper.DeleteAuthSession(userID, strings.TrimSpace(sessionID)); err != nil {
+		if err == helper.ErrSessionNotFound {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "session not found")
+		}
+		slog.ErrorContext(ctx, "failed to revoke session", "user_id", userID, "error", err)
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to revoke session")
+	}
+	return nil
+}
+
+// RevokeOtherSessions logs the user out everywhere except the current
+// session and returns how many sessions were revoked
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	sessions, err := helper.ListAuthSessions(userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list sessions", "user_id", userID, "error", err)
+		return 0, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to revoke sessions")
+	}
+	revoked := 0
+	for _, it := range sessions {
+		if it.SessionID == currentSessionID {
+			continue
+		}
+		if err := helper.DeleteAuthSession(userID, it.SessionID); err != nil && err != helper.ErrSessionNotFound {
+			slog.ErrorContext(ctx, "failed to revoke session", "user_id", userID, "error", err)
+			return revoked, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to revoke sessions")
+		}
+		revoked++
+	}
+	return revoked, nil
+}
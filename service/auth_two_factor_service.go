@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"service-travego/helper"
+	"service-travego/internal/totp"
+	"service-travego/model"
+)
+
+const (
+	twoFactorIssuer    = "TraveGO"
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// generateRecoveryCodes returns recovery codes formatted as XXXXX-XXXXX
+func generateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	buf := make([]byte, recoveryCodeLength)
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == recoveryCodeLength/2 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeChars[int(b)%len(recoveryCodeChars)])
+		}
+		codes = append(codes, sb.String())
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode returns the hash a recovery code is stored by. Case,
+// dashes and spaces are ignored so codes can be typed loosely.
+func hashRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashRecoveryCodes(codes []string) []string {
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, hashRecoveryCode(c))
+	}
+	return hashes
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// verifySecondFactor checks a TOTP code, or a recovery code when
+// allowRecovery is set. A TOTP code is accepted once; a recovery code is
+// used up.
+func (s *AuthService) verifySecondFactor(ctx context.Context, tf *model.UserTwoFactor, code string, allowRecovery bool) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	if isTOTPCode(code) {
+		secret, err := helper.DecryptString(tf.TOTPSecret)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to decrypt TOTP secret", "user_id", tf.UserID, "error", err)
+			return false, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to verify code")
+		}
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		claimed, err := helper.ClaimTOTPStep(tf.UserID, step, time.Duration(2*totp.Skew+1)*totp.Period*time.Second)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to claim TOTP step", "user_id", tf.UserID, "error", err)
+			return false, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to verify code")
+		}
+		return claimed, nil
+	}
+
+	if !allowRecovery {
+		return false, nil
+	}
+	used, err := s.userRepo.UseRecoveryCode(ctx, tf.UserID, hashRecoveryCode(code))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to use recovery code", "user_id", tf.UserID, "error", err)
+		return false, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to verify code")
+	}
+	return used, nil
+}
+
+// enabledTwoFactor returns the user's two-factor settings, or a 400 error when
+// two-factor authentication is not enabled
+func (s *AuthService) enabledTwoFactor(ctx context.Context, userID string) (*model.UserTwoFactor, error) {
+	tf, err := s.userRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error getting two-factor settings", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get two-factor settings")
+	}
+	if tf == nil || !tf.Enabled {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "two-factor authentication is not enabled")
+	}
+	return tf, nil
+}
+
+// TwoFactorStatus returns whether the user has two-factor authentication on
+func (s *AuthService) TwoFactorStatus(ctx context.Context, userID string) (*model.TwoFactorStatus, error) {
+	tf, err := s.userRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error getting two-factor settings", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get two-factor settings")
+	}
+	status := &model.TwoFactorStatus{}
+	if tf != nil && tf.Enabled {
+		status.Enabled = true
+		status.RecoveryCodesLeft = tf.RecoveryCodesLeft
+		if tf.EnabledAt != nil {
+			status.EnabledAt = tf.EnabledAt.Format("2006-01-02 15:04:05")
+		}
+	}
+	return status, nil
+}
+
+// SetupTwoFactor creates a new TOTP secret for the user's authenticator app.
+// Two-factor authentication only turns on after EnableTwoFactor.
+func (s *AuthService) SetupTwoFactor(ctx context.Context, userID string) (*model.TwoFactorSetup, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
+	}
+	tf, err := s.userRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error getting two-factor settings", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to set up two-factor authentication")
+	}
+	if tf != nil && tf.Enabled {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to set up two-factor authentication")
+	}
+	encrypted, err := helper.EncryptString(secret)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encrypt TOTP secret", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to set up two-factor authentication")
+	}
+	if err := s.userRepo.SaveTwoFactorSecret(ctx, userID, encrypted); err != nil {
+		slog.ErrorContext(ctx, "failed to save TOTP secret", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to set up two-factor authentication")
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	return &model.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURL: totp.URL(twoFactorIssuer, account, secret),
+	}, nil
+}
+
+// EnableTwoFactor turns two-factor authentication on once the user proves
+// their authenticator app works, and returns their recovery codes
+func (s *AuthService) EnableTwoFactor(ctx context.Context, userID, code string) (*model.TwoFactorRecoveryCodes, error) {
+	tf, err := s.userRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "error getting two-factor settings", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to enable two-factor authentication")
+	}
+	if tf == nil {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "set up two-factor authentication first")
+	}
+	if tf.Enabled {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "two-factor authentication is already enabled")
+	}
+	ok, err := s.verifySecondFactor(ctx, tf, code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid two-factor code")
+	}
+
+	codes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to enable two-factor authentication")
+	}
+	if err := s.userRepo.EnableTwoFactor(ctx, userID, hashRecoveryCodes(codes)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "set up two-factor authentication first")
+		}
+		slog.ErrorContext(ctx, "failed to enable two-factor", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to enable two-factor authentication")
+	}
+	return &model.TwoFactorRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off. It needs the user's
+// password and a current TOTP or recovery code.
+func (s *AuthService) DisableTwoFactor(ctx context.Context, userID, password, code string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
+	}
+	if !helper.CheckPasswordHash(password, user.Password) {
+		return NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid password")
+	}
+	tf, err := s.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.verifySecondFactor(ctx, tf, code, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid two-factor code")
+	}
+	if err := s.userRepo.DisableTwoFactor(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "failed to disable two-factor", "user_id", userID, "error", err)
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to disable two-factor authentication")
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes. It needs a
+// current TOTP code.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*model.TwoFactorRecoveryCodes, error) {
+	tf, err := s.enabledTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.verifySecondFactor(ctx, tf, code, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, NewServiceError(ErrInvalidCredentials, http.StatusBadRequest, "invalid two-factor code")
+	}
+
+	codes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate recovery codes")
+	}
+	if err := s.userRepo.ReplaceRecoveryCodes(ctx, userID, hashRecoveryCodes(codes)); err != nil {
+		slog.ErrorContext(ctx, "failed to replace recovery codes", "user_id", userID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate recovery codes")
+	}
+	return &model.TwoFactorRecoveryCodes{RecoveryCodes: codes}, nil
+}
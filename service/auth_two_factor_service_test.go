@@ -0,0 +1,57 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("expected %d codes, got %d", recoveryCodeCount, len(codes))
+	}
+	format := regexp.MustCompile(`^[A-Z2-9]{5}-[A-Z2-9]{5}$`)
+	seen := map[string]bool{}
+	for _, c := range codes {
+		if !format.MatchString(c) {
+			t.Fatalf("unexpected code format %q", c)
+		}
+		if seen[c] {
+			t.Fatalf("duplicate code %q", c)
+		}
+		seen[c] = true
+	}
+}
+
+func TestHashRecoveryCodeIgnoresFormatting(t *testing.T) {
+	want := hashRecoveryCode("ABCDE-FGHJK")
+	for _, typed := range []string{"abcde-fghjk", "ABCDEFGHJK", "abcde fghjk"} {
+		if got := hashRecoveryCode(typed); got != want {
+			t.Fatalf("hash of %q differs", typed)
+		}
+	}
+	if hashRecoveryCode("ABCDE-FGHJM") == want {
+		t.Fatal("different codes must not share a hash")
+	}
+}
+
+func TestNormalizeLoginDevice(t *testing.T) {
+	d := normalizeLoginDevice(LoginDevice{})
+	if d.DeviceName != "Unknown device" || d.DeviceID == "" {
+		t.Fatalf("unexpected defaults %+v", d)
+	}
+
+	a := normalizeLoginDevice(LoginDevice{DeviceName: "Mozilla/5.0 (Android)"})
+	b := normalizeLoginDevice(LoginDevice{DeviceName: "Mozilla/5.0 (Android)"})
+	if a.DeviceID != b.DeviceID {
+		t.Fatal("the same device name should map to the same device id")
+	}
+
+	c := normalizeLoginDevice(LoginDevice{DeviceID: " phone-1 ", DeviceName: "Pixel"})
+	if c.DeviceID != "phone-1" {
+		t.Fatalf("expected the client device id to be kept, got %q", c.DeviceID)
+	}
+}
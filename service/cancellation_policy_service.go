@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"service-travego/configs"
+	"service-travego/model"
+	"service-travego/repository"
+	"strings"
+	"time"
+)
+
+type CancellationPolicyService struct {
+	repo      *repository.CancellationPolicyRepository
+	fleetRepo *repository.FleetRepository
+}
+
+func NewCancellationPolicyService(repo *repository.CancellationPolicyRepository, fleetRepo *repository.FleetRepository) *CancellationPolicyService {
+	return &CancellationPolicyService{repo: repo, fleetRepo: fleetRepo}
+}
+
+func (s *CancellationPolicyService) internalMessage(base string, err error) string {
+	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
+		return fmt.Sprintf("%s: %v", base, err)
+	}
+	return base
+}
+
+func (s *CancellationPolicyService) validate(ctx context.Context, userID string, req *model.CancellationPolicySaveRequest) error {
+	req.OrganizationID = organizationFromContext(ctx)
+	req.UserID = userID
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "name is required")
+	}
+	if len(req.Tiers) == 0 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "tiers is required")
+	}
+
+	seen := make(map[int]struct{}, len(req.Tiers))
+	for i := range req.Tiers {
+		t := &req.Tiers[i]
+		t.Seq = i + 1
+		if t.MinDaysBefore < 0 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "min_days_before must not be negative")
+		}
+		if t.RefundPercentage < 0 || t.RefundPercentage > 100 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "refund_percentage must be between 0 and 100")
+		}
+		if _, ok := seen[t.MinDaysBefore]; ok {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "min_days_before must be unique per tier")
+		}
+		seen[t.MinDaysBefore] = struct{}{}
+	}
+	return nil
+}
+
+// GetPolicy returns the policy version in force, or nil when the organization
+// has not set one up.
+func (s *CancellationPolicyService) GetPolicy(ctx context.Context) (*model.CancellationPolicy, error) {
+	policy, err := s.repo.GetActive(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get cancellation policy", err))
+	}
+	return policy, nil
+}
+
+func (s *CancellationPolicyService) ListVersions(ctx context.Context) ([]model.CancellationPolicy, error) {
+	items, err := s.repo.ListVersions(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get cancellation policy history", err))
+	}
+	return items, nil
+}
+
+// Save publishes req as a new policy version. Refunds already recorded keep
+// the version they were computed with.
+func (s *CancellationPolicyService) Save(ctx context.Context, userID string, req *model.CancellationPolicySaveRequest) (*model.CancellationPolicy, error) {
+	if err := s.validate(ctx, userID, req); err != nil {
+		return nil, err
+	}
+	if _, _, err := s.repo.Save(ctx, req); err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to save cancellation policy", err))
+	}
+	return s.GetPolicy(ctx)
+}
+
+// refundPercentage picks the tier of the most days before start that the
+// cancellation still meets. Tiers are ordered by min_days_before descending.
+func refundPercentage(tiers []model.CancellationPolicyTier, daysBefore int) float64 {
+	for _, t := range tiers {
+		if daysBefore >= t.MinDaysBefore {
+			return t.RefundPercentage
+		}
+	}
+	return 0
+}
+
+// Quote computes the refund of cancelling orderID at the given time with the
+// organization's policy in force. Without a policy everything paid is refunded.
+func (s *CancellationPolicyService) Quote(ctx context.Context, orderID string, at time.Time) (*model.CancellationRefundQuote, error) {
+	orderID = strings.TrimSpace(orderID)
+	if orderID == "" {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
+	}
+	order, err := s.fleetRepo.GetPartnerOrderDetail(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
+	}
+	if order.Status == int(configs.OrderStatusCancelled) {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_ALREADY_CANCELLED")
+	}
+
+	paid, err := s.fleetRepo.GetPaidAmount(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get paid amount", err))
+	}
+
+	policy, err := s.GetPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var addons float64
+	if policy != nil && policy.NonRefundableAddons {
+		if addons, err = s.fleetRepo.GetOrderAddonAmount(ctx, orderID); err != nil {
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get order addons", err))
+		}
+	}
+	return refundQuote(orderID, order.StartDate, paid, addons, policy, at), nil
+}
+
+// refundQuote applies policy to an order starting on startDate (yyyy-mm-dd),
+// given what has been paid for it and what its addons cost
+func refundQuote(orderID, startDate string, paid, addons float64, policy *model.CancellationPolicy, at time.Time) *model.CancellationRefundQuote {
+	quote := &model.CancellationRefundQuote{
+		OrderID:          orderID,
+		StartDate:        startDate,
+		PaidAmount:       paid,
+		RefundPercentage: 100,
+	}
+	if start, err := time.ParseInLocation("2006-01-02", startDate, time.Local); err == nil {
+		today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.Local)
+		quote.DaysBeforeStart = int(math.Round(start.Sub(today).Hours() / 24))
+	}
+
+	if policy != nil {
+		quote.PolicyID = policy.PolicyID
+		quote.PolicyVersion = policy.Version
+		quote.PolicyName = policy.Name
+		quote.RefundPercentage = refundPercentage(policy.Tiers, quote.DaysBeforeStart)
+		if policy.NonRefundableAddons {
+			quote.NonRefundableAmount = math.Min(addons, paid)
+		}
+	}
+
+	quote.RefundableAmount = paid - quote.NonRefundableAmount
+	quote.RefundAmount = math.Round(quote.RefundableAmount * quote.RefundPercentage / 100)
+	return quote
+}
+
+// SubmitRequest records a customer's cancellation request for staff to
+// approve, together with the refund it would get right now.
+func (s *CancellationPolicyService) SubmitRequest(ctx context.Context, req *model.OrderCancellationSubmitRequest) (*model.OrderCancellationRequest, error) {
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "reason is required")
+	}
+	quote, err := s.Quote(ctx, req.OrderID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetPendingRequest(ctx, quote.OrderID); err == nil {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusConflict, "CANCELLATION_ALREADY_REQUESTED")
+	} else if err != sql.ErrNoRows {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get cancellation request", err))
+	}
+
+	item := &model.OrderCancellationRequest{
+		OrderID:          quote.OrderID,
+		Source:           req.Source,
+		Reason:           strings.TrimSpace(req.Reason),
+		PaymentMethod:    req.PaymentMethod,
+		BankCode:         strings.TrimSpace(req.BankCode),
+		BankAccount:      strings.TrimSpace(req.BankAccount),
+		BankAccountName:  strings.TrimSpace(req.BankAccountName),
+		PolicyVersion:    quote.PolicyVersion,
+		RefundPercentage: quote.RefundPercentage,
+		RefundAmount:     quote.RefundAmount,
+		Status:           model.CancellationRequestPending,
+	}
+	if err := s.repo.CreateRequest(ctx, item); err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to save cancellation request", err))
+	}
+	item.SourceLabel = model.CancellationSourceLabel[item.Source]
+	item.StatusLabel = model.CancellationRequestStatusLabel[item.Status]
+	item.RequestedDate = time.Now().Format("2006-01-02 15:04:05")
+	return item, nil
+}
+
+func (s *CancellationPolicyService) ListRequests(ctx context.Context, status int) ([]model.OrderCancellationRequest, error) {
+	items, err := s.repo.ListRequests(ctx, status)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get cancellation requests", err))
+	}
+	return items, nil
+}
+
+// GetPendingRequest returns the order's request waiting for approval, or nil.
+func (s *CancellationPolicyService) GetPendingRequest(ctx context.Context, orderID string) (*model.OrderCancellationRequest, error) {
+	item, err := s.repo.GetPendingRequest(ctx, orderID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get cancellation request", err))
+	}
+	return item, nil
+}
+
+// GetOpenRequest returns a request that is still waiting for approval.
+func (s *CancellationPolicyService) GetOpenRequest(ctx context.Context, requestID string) (*model.OrderCancellationRequest, error) {
+	item, err := s.repo.GetRequest(ctx, requestID)
+	if err == sql.ErrNoRows {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "cancellation request not found")
+	}
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get cancellation request", err))
+	}
+	if item.Status != model.CancellationRequestPending {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "CANCELLATION_REQUEST_ALREADY_REVIEWED")
+	}
+	return item, nil
+}
+
+func (s *CancellationPolicyService) review(ctx context.Context, userID, requestID string, status int, note string) error {
+	if err := s.repo.ReviewRequest(ctx, requestID, userID, status, strings.TrimSpace(note)); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "CANCELLATION_REQUEST_ALREADY_REVIEWED")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update cancellation request", err))
+	}
+	return nil
+}
+
+// MarkApproved closes a request once its order was cancelled and refunded.
+func (s *CancellationPolicyService) MarkApproved(ctx context.Context, userID, requestID, note string) error {
+	return s.review(ctx, userID, requestID, model.CancellationRequestApproved, note)
+}
+
+func (s *CancellationPolicyService) Reject(ctx context.Context, userID string, req *model.OrderCancellationReviewRequest) error {
+	if _, err := s.GetOpenRequest(ctx, req.RequestID); err != nil {
+		return err
+	}
+	return s.review(ctx, userID, req.RequestID, model.CancellationRequestRejected, req.Note)
+}
+
+// ApprovePendingRequests closes the requests of an order staff cancelled directly.
+func (s *CancellationPolicyService) ApprovePendingRequests(ctx context.Context, orderID, userID string) error {
+	if err := s.repo.ApprovePendingRequests(ctx, orderID, userID); err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update cancellation requests", err))
+	}
+	return nil
+}
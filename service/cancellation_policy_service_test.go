@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"service-travego/model"
+)
+
+func TestRefundQuoteKeepsNonRefundableAddons(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	policy := &model.CancellationPolicy{
+		NonRefundableAddons: true,
+		Tiers: []model.CancellationPolicyTier{
+			{Seq: 1, MinDaysBefore: 7, RefundPercentage: 100},
+			{Seq: 2, MinDaysBefore: 3, RefundPercentage: 50},
+		},
+	}
+
+	// two addons of 75.000 and one of 50.000 on an order paid in full
+	quote := refundQuote("order-1", "2026-03-05", 1000000, 200000, policy, at)
+	if quote.DaysBeforeStart != 4 || quote.RefundPercentage != 50 {
+		t.Fatalf("days before %d at %v%%, want 4 days at 50%%", quote.DaysBeforeStart, quote.RefundPercentage)
+	}
+	if quote.NonRefundableAmount != 200000 || quote.RefundableAmount != 800000 || quote.RefundAmount != 400000 {
+		t.Fatalf("non refundable %v, refundable %v, refund %v", quote.NonRefundableAmount, quote.RefundableAmount, quote.RefundAmount)
+	}
+
+	// only part of the order is paid: the addons cannot keep more than that
+	quote = refundQuote("order-1", "2026-03-05", 150000, 200000, policy, at)
+	if quote.NonRefundableAmount != 150000 || quote.RefundAmount != 0 {
+		t.Fatalf("non refundable %v, refund %v", quote.NonRefundableAmount, quote.RefundAmount)
+	}
+
+	policy.NonRefundableAddons = false
+	quote = refundQuote("order-1", "2026-03-20", 1000000, 200000, policy, at)
+	if quote.NonRefundableAmount != 0 || quote.RefundAmount != 1000000 {
+		t.Fatalf("non refundable %v, refund %v", quote.NonRefundableAmount, quote.RefundAmount)
+	}
+}
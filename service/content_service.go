@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -74,7 +75,7 @@ func NewContentService(repo *repository.ContentRepository) *ContentService {
 }
 
 // UpsertGeneralContent handles insert or update of general content
-func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, userID string) error {
+func (s *ContentService) UpsertGeneralContent(ctx context.Context, req model.ContentRequest, userID string) error {
 	if req.SectionTag == "" {
 		return errors.New("section_tag is required")
 	}
@@ -83,7 +84,7 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 	}
 
 	// Check if content exists
-	existingContent, err := s.repo.FindByTagParentAndOrgID(req.SectionTag, req.Parent, orgID)
+	existingContent, err := s.repo.FindByTagAndParent(ctx, req.SectionTag, req.Parent)
 	if err != nil {
 		return err
 	}
@@ -100,7 +101,7 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 		existingContent.IsActive = isActive
 		existingContent.UpdatedAt = now
 		existingContent.UpdatedBy = userID
-		if err := s.repo.Update(existingContent); err != nil {
+		if err := s.repo.Update(ctx, existingContent); err != nil {
 			return err
 		}
 		if req.Type == "list" && len(req.List) > 0 {
@@ -110,7 +111,7 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 					return errors.New("list.label is required")
 				}
 				if li.ListID != "" {
-					if err := s.repo.UpdateContentListItemByUUID(li.ListID, li.Label, li.Icon, li.SubLabel, now); err != nil {
+					if err := s.repo.UpdateContentListItemByUUID(ctx, li.ListID, li.Label, li.Icon, li.SubLabel, now); err != nil {
 						return err
 					}
 					continue
@@ -126,7 +127,7 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 				})
 			}
 			if len(toInsert) > 0 {
-				if err := s.repo.InsertContentListItems(toInsert); err != nil {
+				if err := s.repo.InsertContentListItems(ctx, toInsert); err != nil {
 					return err
 				}
 			}
@@ -142,13 +143,13 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 		Type:           req.Type,
 		IsActive:       isActive,
 		Content:        req.Content,
-		OrganizationID: orgID,
+		OrganizationID: organizationFromContext(ctx),
 		CreatedAt:      now,
 		CreatedBy:      userID,
 		UpdatedAt:      now,
 		UpdatedBy:      userID,
 	}
-	if err := s.repo.Create(newContent); err != nil {
+	if err := s.repo.Create(ctx, newContent); err != nil {
 		return err
 	}
 	if req.Type == "list" && len(req.List) > 0 {
@@ -167,7 +168,7 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 				UpdatedAt: now,
 			})
 		}
-		if err := s.repo.InsertContentListItems(items); err != nil {
+		if err := s.repo.InsertContentListItems(ctx, items); err != nil {
 			return err
 		}
 	}
@@ -175,7 +176,7 @@ func (s *ContentService) UpsertGeneralContent(req model.ContentRequest, orgID, u
 }
 
 // GetGeneralContent retrieves content by section_tag and organization_id
-func (s *ContentService) GetGeneralContent(sectionTag, orgID string) (*model.ContentResponse, error) {
+func (s *ContentService) GetGeneralContent(ctx context.Context, sectionTag string) (*model.ContentResponse, error) {
 	// For backward compatibility or specific logic, parent might be empty or handled differently
 	// Assuming empty parent for now if not provided in signature.
 	// To fully support parent in Get, we'd need to update the signature or method.
@@ -195,7 +196,7 @@ func (s *ContentService) GetGeneralContent(sectionTag, orgID string) (*model.Con
 	// This might break existing functionality if rows have NULL parent (which we handle as string/empty?).
 	// Let's assume we pass "" for parent.
 
-	content, err := s.repo.FindByTagAndOrgID(sectionTag, orgID)
+	content, err := s.repo.FindByTag(ctx, sectionTag)
 	if err != nil {
 		return nil, err
 	}
@@ -214,8 +215,8 @@ func (s *ContentService) GetGeneralContent(sectionTag, orgID string) (*model.Con
 }
 
 // GetContentByParent retrieves content by parent and organization_id
-func (s *ContentService) GetContentByParent(parent, orgID string) ([]model.ContentResponse, error) {
-	contents, err := s.repo.FindByParentAndOrgID(parent, orgID)
+func (s *ContentService) GetContentByParent(ctx context.Context, parent string) ([]model.ContentResponse, error) {
+	contents, err := s.repo.FindByParent(ctx, parent)
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +231,7 @@ func (s *ContentService) GetContentByParent(parent, orgID string) ([]model.Conte
 			Content:    c.Content,
 		}
 		if c.Type == "list" {
-			listItems, err := s.repo.FindContentListByContentID(c.UUID)
+			listItems, err := s.repo.FindContentListByContentID(ctx, c.UUID)
 			if err != nil {
 				return nil, err
 			}
@@ -245,8 +246,8 @@ func (s *ContentService) GetContentByParent(parent, orgID string) ([]model.Conte
 	return response, nil
 }
 
-func (s *ContentService) GetContentDetail(parent, sectionTag, orgID string) (*model.ContentResponse, error) {
-	content, err := s.repo.FindByTagParentAndOrgID(sectionTag, parent, orgID)
+func (s *ContentService) GetContentDetail(ctx context.Context, parent, sectionTag string) (*model.ContentResponse, error) {
+	content, err := s.repo.FindByTagAndParent(ctx, sectionTag, parent)
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +262,7 @@ func (s *ContentService) GetContentDetail(parent, sectionTag, orgID string) (*mo
 		Content:    content.Content,
 	}
 	if content.Type == "list" {
-		listItems, err := s.repo.FindContentListByContentID(content.UUID)
+		listItems, err := s.repo.FindContentListByContentID(ctx, content.UUID)
 		if err != nil {
 			return nil, err
 		}
@@ -274,9 +275,11 @@ func (s *ContentService) GetContentDetail(parent, sectionTag, orgID string) (*mo
 	return res, nil
 }
 
-func (s *ContentService) UploadContent(fileHeader *multipart.FileHeader, parent, sectionTag, orgID, userID string) (string, error) {
+func (s *ContentService) UploadContent(ctx context.Context, fileHeader *multipart.FileHeader, parent, sectionTag, userID string) (string, error) {
+	orgID := organizationFromContext(ctx)
+
 	// 1. Check existing content
-	existingContent, err := s.repo.FindByTagParentAndOrgID(sectionTag, parent, orgID)
+	existingContent, err := s.repo.FindByTagAndParent(ctx, sectionTag, parent)
 	if err != nil {
 		return "", err
 	}
@@ -340,7 +343,7 @@ func (s *ContentService) UploadContent(fileHeader *multipart.FileHeader, parent,
 		existingContent.UpdatedAt = now
 		existingContent.UpdatedBy = userID
 		existingContent.Type = "image"
-		if err := s.repo.Update(existingContent); err != nil {
+		if err := s.repo.Update(ctx, existingContent); err != nil {
 			return "", err
 		}
 		return fullURL, nil
@@ -353,21 +356,21 @@ func (s *ContentService) UploadContent(fileHeader *multipart.FileHeader, parent,
 		Type:           "image",
 		IsActive:       true,
 		Content:        fullURL,
-		OrganizationID: orgID,
+		OrganizationID: organizationFromContext(ctx),
 		CreatedAt:      now,
 		CreatedBy:      userID,
 		UpdatedAt:      now,
 		UpdatedBy:      userID,
 	}
-	if err := s.repo.Create(newContent); err != nil {
+	if err := s.repo.Create(ctx, newContent); err != nil {
 		return "", err
 	}
 	return fullURL, nil
 }
 
 // GetAllGeneralContent retrieves all content for an organization grouped by parent
-func (s *ContentService) GetAllGeneralContent(orgID string) (map[string]interface{}, error) {
-	contents, err := s.repo.FindAllByOrgID(orgID)
+func (s *ContentService) GetAllGeneralContent(ctx context.Context) (map[string]interface{}, error) {
+	contents, err := s.repo.FindAll(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -383,7 +386,7 @@ func (s *ContentService) GetAllGeneralContent(orgID string) (map[string]interfac
 		}
 
 		if c.Type == "list" {
-			listItems, err := s.repo.FindContentListByContentID(c.UUID)
+			listItems, err := s.repo.FindContentListByContentID(ctx, c.UUID)
 			if err != nil {
 				return nil, err
 			}
@@ -402,7 +405,7 @@ func (s *ContentService) GetAllGeneralContent(orgID string) (map[string]interfac
 		content[parentKey] = append(content[parentKey], item)
 	}
 
-	orgContact, err := s.repo.GetOrganizationContact(orgID)
+	orgContact, err := s.repo.GetOrganizationContact(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -450,6 +453,6 @@ func (s *ContentService) GetAllGeneralContent(orgID string) (map[string]interfac
 	return mergedContent, nil
 }
 
-func (s *ContentService) DeleteContentByUUID(uuid, orgID string) error {
-	return s.repo.DeleteContentByUUID(uuid, orgID)
+func (s *ContentService) DeleteContentByUUID(ctx context.Context, uuid string) error {
+	return s.repo.DeleteContentByUUID(ctx, uuid)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -23,8 +24,8 @@ func NewCustomersService(repo *repository.CustomersRepository) *CustomersService
 	return &CustomersService{repo: repo}
 }
 
-func (s *CustomersService) ListCustomers(orgID, customerName string) ([]model.CustomerListItem, error) {
-	items, err := s.repo.ListCustomers(orgID, customerName)
+func (s *CustomersService) ListCustomers(ctx context.Context, customerName string) ([]model.CustomerListItem, error) {
+	items, err := s.repo.ListCustomers(ctx, customerName)
 	if err != nil {
 		return nil, err
 	}
@@ -46,12 +47,12 @@ func (s *CustomersService) ListCustomers(orgID, customerName string) ([]model.Cu
 	return items, nil
 }
 
-func (s *CustomersService) CreateCustomer(orgID string, req *model.CustomerCreateRequest, customerID string) error {
-	return s.repo.CreateCustomer(orgID, req, customerID)
+func (s *CustomersService) CreateCustomer(ctx context.Context, req *model.CustomerCreateRequest, customerID string) error {
+	return s.repo.CreateCustomer(ctx, req, customerID)
 }
 
-func (s *CustomersService) UpdateCustomer(orgID, customerID string, req *model.CustomerCreateRequest) error {
-	if err := s.repo.UpdateCustomer(orgID, customerID, req); err != nil {
+func (s *CustomersService) UpdateCustomer(ctx context.Context, customerID string, req *model.CustomerCreateRequest) error {
+	if err := s.repo.UpdateCustomer(ctx, customerID, req); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "customer not found")
 		}
@@ -60,8 +61,8 @@ func (s *CustomersService) UpdateCustomer(orgID, customerID string, req *model.C
 	return nil
 }
 
-func (s *CustomersService) GetCustomerDetail(orgID, customerID string) (map[string]interface{}, error) {
-	data, err := s.repo.GetCustomerDetail(orgID, customerID)
+func (s *CustomersService) GetCustomerDetail(ctx context.Context, customerID string) (map[string]interface{}, error) {
+	data, err := s.repo.GetCustomerDetail(ctx, customerID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "customer not found")
@@ -124,8 +125,8 @@ func (s *CustomersService) GetCustomerDetail(orgID, customerID string) (map[stri
 	return data, nil
 }
 
-func (s *CustomersService) CheckCustomerAvailibility(orgID, email, phone string) (map[string]interface{}, error) {
-	data, err := s.repo.CheckCustomerAvailibility(orgID, email, phone)
+func (s *CustomersService) CheckCustomerAvailibility(ctx context.Context, email, phone string) (map[string]interface{}, error) {
+	data, err := s.repo.CheckCustomerAvailibility(ctx, email, phone)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -157,8 +158,8 @@ func (s *CustomersService) ensureLocationsLoaded() {
 	s.citiesName = cm
 }
 
-func (s *CustomersService) GetCustomerOrders(orgID, customerID string, req *model.CustomerOrdersRequest) ([]map[string]interface{}, error) {
-	data, err := s.repo.GetCustomerOrders(orgID, customerID, req)
+func (s *CustomersService) GetCustomerOrders(ctx context.Context, customerID string, req *model.CustomerOrdersRequest) ([]map[string]interface{}, error) {
+	data, err := s.repo.GetCustomerOrders(ctx, customerID, req)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"service-travego/model"
 	"service-travego/repository"
 	"time"
@@ -16,36 +17,36 @@ func NewDashboardService(repo *repository.DashboardRepository) *DashboardService
 	}
 }
 
-func (s *DashboardService) GetPartnerSummary(orgID string) (*model.DashboardPartnerSummaryResponse, error) {
-	return s.repo.GetPartnerSummary(orgID)
+func (s *DashboardService) GetPartnerSummary(ctx context.Context) (*model.DashboardPartnerSummaryResponse, error) {
+	return s.repo.GetPartnerSummary(ctx)
 }
 
-func (s *DashboardService) GetDashboard(orgID string) (*model.DashboardResponse, error) {
-	return s.repo.GetDashboard(orgID)
+func (s *DashboardService) GetDashboard(ctx context.Context) (*model.DashboardResponse, error) {
+	return s.repo.GetDashboard(ctx)
 }
 
-func (s *DashboardService) GetTopDestinations(orgID string) ([]model.DashboardTopDestination, error) {
-	return s.repo.GetTopDestinations(orgID)
+func (s *DashboardService) GetTopDestinations(ctx context.Context) ([]model.DashboardTopDestination, error) {
+	return s.repo.GetTopDestinations(ctx)
 }
 
-func (s *DashboardService) GetTopPickupCity(orgID string) ([]model.DashboardTopPickupCity, error) {
-	return s.repo.GetTopPickupCity(orgID)
+func (s *DashboardService) GetTopPickupCity(ctx context.Context) ([]model.DashboardTopPickupCity, error) {
+	return s.repo.GetTopPickupCity(ctx)
 }
 
-func (s *DashboardService) GetTopFleets(orgID string) ([]model.DashboardTopFleet, error) {
-	return s.repo.GetTopFleets(orgID)
+func (s *DashboardService) GetTopFleets(ctx context.Context) ([]model.DashboardTopFleet, error) {
+	return s.repo.GetTopFleets(ctx)
 }
 
-func (s *DashboardService) GetTopTourPackages(orgID string) ([]model.DashboardTopTourPackage, error) {
-	return s.repo.GetTopTourPackages(orgID)
+func (s *DashboardService) GetTopTourPackages(ctx context.Context) ([]model.DashboardTopTourPackage, error) {
+	return s.repo.GetTopTourPackages(ctx)
 }
 
-func (s *DashboardService) GetTopDrivers(orgID string) ([]model.DashboardTopDriver, error) {
-	return s.repo.GetTopDrivers(orgID)
+func (s *DashboardService) GetTopDrivers(ctx context.Context) ([]model.DashboardTopDriver, error) {
+	return s.repo.GetTopDrivers(ctx)
 }
 
-func (s *DashboardService) GetTopCustomers(orgID string) ([]model.DashboardTopCustomer, error) {
-	return s.repo.GetTopCustomers(orgID)
+func (s *DashboardService) GetTopCustomers(ctx context.Context) ([]model.DashboardTopCustomer, error) {
+	return s.repo.GetTopCustomers(ctx)
 }
 
 type DashboardFinanceResponse struct {
@@ -64,9 +65,10 @@ type DashboardFinanceSummary struct {
 	TotalRevenue  float64 `json:"total_revenue"`
 	TotalExpenses float64 `json:"total_expenses"`
 	Net           float64 `json:"net"`
+	TotalVoucher  float64 `json:"total_voucher"`
 }
 
-func (s *DashboardService) GetFinance(orgID string, startDate time.Time, endDate time.Time) (*DashboardFinanceResponse, error) {
+func (s *DashboardService) GetFinance(ctx context.Context, startDate time.Time, endDate time.Time) (*DashboardFinanceResponse, error) {
 	diffDays := int(endDate.Sub(startDate).Hours() / 24)
 
 	groupBy := "month"
@@ -82,7 +84,11 @@ func (s *DashboardService) GetFinance(orgID string, startDate time.Time, endDate
 	endDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.Local)
 	end := endDay.AddDate(0, 0, 1).Add(-time.Nanosecond)
 
-	rows, err := s.repo.GetFinance(orgID, groupBy, start, end)
+	rows, err := s.repo.GetFinance(ctx, groupBy, start, end)
+	if err != nil {
+		return nil, err
+	}
+	voucherRows, err := s.repo.GetVoucherDiscounts(ctx, groupBy, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -116,13 +122,19 @@ func (s *DashboardService) GetFinance(orgID string, startDate time.Time, endDate
 		label := period.Format(labelFormat)
 		byLabel[label] = [2]float64{r.Revenue, r.Expenses}
 	}
+	voucherByLabel := make(map[string]float64, len(voucherRows))
+	for _, r := range voucherRows {
+		voucherByLabel[r.Period.In(time.Local).Format(labelFormat)] += r.Discount
+	}
 
 	labels := make([]string, 0)
 	revenueData := make([]float64, 0)
 	expensesData := make([]float64, 0)
+	voucherData := make([]float64, 0)
 
 	var totalRevenue float64
 	var totalExpenses float64
+	var totalVoucher float64
 
 	for t := startPeriod; !t.After(endPeriod); t = addPeriod(t, groupBy) {
 		label := t.Format(labelFormat)
@@ -132,8 +144,11 @@ func (s *DashboardService) GetFinance(orgID string, startDate time.Time, endDate
 		revenueData = append(revenueData, v[0])
 		expensesData = append(expensesData, v[1])
 
+		voucherData = append(voucherData, voucherByLabel[label])
+
 		totalRevenue += v[0]
 		totalExpenses += v[1]
+		totalVoucher += voucherByLabel[label]
 	}
 
 	return &DashboardFinanceResponse{
@@ -142,11 +157,13 @@ func (s *DashboardService) GetFinance(orgID string, startDate time.Time, endDate
 		Series: []DashboardFinanceSerie{
 			{Name: "Revenue", Data: revenueData},
 			{Name: "Expenses", Data: expensesData},
+			{Name: "Voucher Discount", Data: voucherData},
 		},
 		Summary: DashboardFinanceSummary{
 			TotalRevenue:  totalRevenue,
 			TotalExpenses: totalExpenses,
 			Net:           totalRevenue - totalExpenses,
+			TotalVoucher:  totalVoucher,
 		},
 	}, nil
 }
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"service-travego/configs"
+	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/repository"
+	"strings"
+	"time"
+)
+
+type DocumentService struct {
+	repo          *repository.DocumentRepository
+	uploadService *UploadService
+}
+
+func NewDocumentService(repo *repository.DocumentRepository, uploadService *UploadService) *DocumentService {
+	return &DocumentService{repo: repo, uploadService: uploadService}
+}
+
+func (s *DocumentService) internalMessage(base string, err error) string {
+	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
+		return fmt.Sprintf("%s: %v", base, err)
+	}
+	return base
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (s *DocumentService) decorate(doc *model.Document, now time.Time) {
+	doc.DocumentLabel = model.DocumentTypeLabel[doc.DocumentType]
+	if doc.FilePath != "" {
+		doc.FileURL = helper.GetAssetURL(doc.FilePath)
+	}
+	if t, err := time.ParseInLocation("2006-01-02", doc.ExpiryDate, now.Location()); err == nil {
+		doc.DaysToExpiry = int(t.Sub(startOfDay(now)).Hours() / 24)
+		doc.IsExpired = doc.DaysToExpiry < 0
+	}
+}
+
+// UploadAttachment stores a document scan through the common upload pipeline and
+// returns the stored asset path.
+func (s *DocumentService) UploadAttachment(tempFilePath string) (string, error) {
+	return s.uploadService.UploadCommon(tempFilePath, configs.UploadTypeDocument.String())
+}
+
+func (s *DocumentService) validate(ctx context.Context, userID string, req *model.DocumentUpsertRequest) error {
+	req.OrganizationID = organizationFromContext(ctx)
+	req.UserID = userID
+	req.OwnerType = strings.ToUpper(strings.TrimSpace(req.OwnerType))
+	req.DocumentType = strings.ToUpper(strings.TrimSpace(req.DocumentType))
+	req.OwnerID = strings.TrimSpace(req.OwnerID)
+	req.DocumentNumber = strings.TrimSpace(req.DocumentNumber)
+
+	allowed, ok := model.DocumentOwnerTypes[req.OwnerType]
+	if !ok {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "owner_type must be UNIT or EMPLOYEE")
+	}
+	validType := false
+	for _, t := range allowed {
+		if t == req.DocumentType {
+			validType = true
+			break
+		}
+	}
+	if !validType {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, fmt.Sprintf("document_type must be one of: %s", strings.Join(allowed, ", ")))
+	}
+
+	expiryAt, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.ExpiryDate), time.Local)
+	if err != nil {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "expiry_date must be YYYY-MM-DD")
+	}
+	req.ExpiryAt = expiryAt
+	req.IssuedAt = time.Time{}
+	if strings.TrimSpace(req.IssuedDate) != "" {
+		issuedAt, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.IssuedDate), time.Local)
+		if err != nil {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "issued_date must be YYYY-MM-DD")
+		}
+		if issuedAt.After(expiryAt) {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "issued_date must be before expiry_date")
+		}
+		req.IssuedAt = issuedAt
+	}
+
+	exists, err := s.repo.OwnerExists(ctx, req.OwnerType, req.OwnerID)
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate document owner", err))
+	}
+	if !exists {
+		return NewServiceError(ErrNotFound, http.StatusNotFound, "document owner not found")
+	}
+	return nil
+}
+
+func (s *DocumentService) Create(ctx context.Context, userID string, req *model.DocumentUpsertRequest) (string, error) {
+	if err := s.validate(ctx, userID, req); err != nil {
+		return "", err
+	}
+	id, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create document", err))
+	}
+	return id, nil
+}
+
+func (s *DocumentService) Update(ctx context.Context, userID string, req *model.DocumentUpsertRequest) error {
+	if strings.TrimSpace(req.DocumentID) == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "document_id is required")
+	}
+	existing, err := s.repo.GetByID(ctx, req.DocumentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "document not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get document", err))
+	}
+	if err := s.validate(ctx, userID, req); err != nil {
+		return err
+	}
+	if req.OwnerType != existing.OwnerType || req.OwnerID != existing.OwnerID {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "document owner cannot be changed")
+	}
+	if strings.TrimSpace(req.FilePath) == "" {
+		req.FilePath = existing.FilePath
+	}
+
+	expiryChanged := existing.ExpiryDate != req.ExpiryAt.Format("2006-01-02")
+	if err := s.repo.Update(ctx, req, expiryChanged); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "document not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update document", err))
+	}
+	return nil
+}
+
+func (s *DocumentService) Delete(ctx context.Context, userID, documentID string) error {
+	if err := s.repo.Delete(ctx, userID, documentID); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "document not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete document")
+	}
+	return nil
+}
+
+func (s *DocumentService) Detail(ctx context.Context, documentID string) (*model.Document, error) {
+	doc, err := s.repo.GetByID(ctx, documentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "document not found")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get document", err))
+	}
+	s.decorate(doc, time.Now())
+	return doc, nil
+}
+
+func (s *DocumentService) List(ctx context.Context, filter model.DocumentListFilter) ([]model.Document, error) {
+	filter.OwnerType = strings.ToUpper(strings.TrimSpace(filter.OwnerType))
+	filter.OwnerID = strings.TrimSpace(filter.OwnerID)
+	items, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get documents", err))
+	}
+	now := time.Now()
+	for i := range items {
+		s.decorate(&items[i], now)
+	}
+	return items, nil
+}
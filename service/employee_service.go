@@ -1,9 +1,10 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"os"
 	"service-travego/configs"
 	"service-travego/model"
@@ -37,8 +38,8 @@ func (s *OrganizationService) ensureContractTypesLoaded() {
 	s.contractTypeLabels = out
 }
 
-func (s *OrganizationService) EmployeeAll(organizationID, divisionName string) ([]model.EmployeeListItem, error) {
-	items, err := s.orgRepo.ListEmployees(organizationID, divisionName)
+func (s *OrganizationService) EmployeeAll(ctx context.Context, divisionName string) ([]model.EmployeeListItem, error) {
+	items, err := s.orgRepo.ListEmployees(ctx, divisionName)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get employees")
 	}
@@ -53,8 +54,8 @@ func (s *OrganizationService) EmployeeAll(organizationID, divisionName string) (
 	return items, nil
 }
 
-func (s *OrganizationService) EmployeeDetail(organizationID, uuid string) (*model.EmployeeDetailResponse, error) {
-	it, err := s.orgRepo.EmployeeDetail(organizationID, uuid)
+func (s *OrganizationService) EmployeeDetail(ctx context.Context, uuid string) (*model.EmployeeDetailResponse, error) {
+	it, err := s.orgRepo.EmployeeDetail(ctx, uuid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, 404, "employee not found")
@@ -66,7 +67,7 @@ func (s *OrganizationService) EmployeeDetail(organizationID, uuid string) (*mode
 	return it, nil
 }
 
-func (s *OrganizationService) EmployeeOperationsHistory(organizationID, employeeID, period string) (*model.EmployeeOperationsHistoryResponse, error) {
+func (s *OrganizationService) EmployeeOperationsHistory(ctx context.Context, employeeID, period string) (*model.EmployeeOperationsHistoryResponse, error) {
 	var startDate, endDate *time.Time
 	if period != "" {
 		if t, err := time.Parse("2006-01", period); err == nil {
@@ -79,12 +80,12 @@ func (s *OrganizationService) EmployeeOperationsHistory(organizationID, employee
 		}
 	}
 
-	total, err := s.orgRepo.EmployeeOperationsHistoryTotal(organizationID, employeeID, startDate, endDate)
+	total, err := s.orgRepo.EmployeeOperationsHistoryTotal(ctx, employeeID, startDate, endDate)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get total schedules")
 	}
 
-	rows, err := s.orgRepo.EmployeeOperationsHistory(organizationID, employeeID, startDate, endDate)
+	rows, err := s.orgRepo.EmployeeOperationsHistory(ctx, employeeID, startDate, endDate)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get order history")
 	}
@@ -122,8 +123,8 @@ func (s *OrganizationService) EmployeeOperationsHistory(organizationID, employee
 	}, nil
 }
 
-func (s *OrganizationService) EmployeeCreate(organizationID, userID string, req *model.CreateEmployeeRequest) (string, error) {
-	ok, err := s.orgRepo.RoleExistsForOrgOrDefault(organizationID, strings.TrimSpace(req.RoleID))
+func (s *OrganizationService) EmployeeCreate(ctx context.Context, userID string, req *model.CreateEmployeeRequest) (string, error) {
+	ok, err := s.orgRepo.RoleExistsForOrgOrDefault(ctx, strings.TrimSpace(req.RoleID))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, 500, "failed to validate role_id")
 	}
@@ -131,21 +132,21 @@ func (s *OrganizationService) EmployeeCreate(organizationID, userID string, req
 		return "", NewServiceError(ErrInvalidInput, 400, "role_id tidak ditemukan")
 	}
 
-	if ok, err := s.orgRepo.EmployeeIDExists(organizationID, strings.TrimSpace(req.EmployeeID)); err != nil {
+	if ok, err := s.orgRepo.EmployeeIDExists(ctx, strings.TrimSpace(req.EmployeeID)); err != nil {
 		return "", NewServiceError(ErrInternalServer, 500, "failed to validate employee_id")
 	} else if ok {
 		return "", NewServiceError(ErrInvalidInput, 400, "DUPLICATE_EMPLOYEE_ID")
 	}
 
 	if nik := strings.TrimSpace(req.NIK); nik != "" {
-		if ok, err := s.orgRepo.NIKExists(organizationID, nik); err != nil {
+		if ok, err := s.orgRepo.NIKExists(ctx, nik); err != nil {
 			return "", NewServiceError(ErrInternalServer, 500, "failed to validate nik")
 		} else if ok {
 			return "", NewServiceError(ErrInvalidInput, 400, "DUPLICATE_NIK")
 		}
 	}
 
-	id, err := s.orgRepo.CreateEmployee(organizationID, userID, req)
+	id, err := s.orgRepo.CreateEmployee(ctx, userID, req)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "invalid birth_date") {
 			return "", NewServiceError(ErrInvalidInput, 400, "invalid birth_date")
@@ -161,8 +162,8 @@ func (s *OrganizationService) EmployeeCreate(organizationID, userID string, req
 	return id, nil
 }
 
-func (s *OrganizationService) EmployeeUpdate(organizationID, userID string, req *model.UpdateEmployeeRequest) error {
-	ok, err := s.orgRepo.RoleExistsForOrgOrDefault(organizationID, strings.TrimSpace(req.RoleID))
+func (s *OrganizationService) EmployeeUpdate(ctx context.Context, userID string, req *model.UpdateEmployeeRequest) error {
+	ok, err := s.orgRepo.RoleExistsForOrgOrDefault(ctx, strings.TrimSpace(req.RoleID))
 	if err != nil {
 		return NewServiceError(ErrInternalServer, 500, "failed to validate role_id")
 	}
@@ -170,7 +171,7 @@ func (s *OrganizationService) EmployeeUpdate(organizationID, userID string, req
 		return NewServiceError(ErrInvalidInput, 400, "role_id tidak ditemukan")
 	}
 
-	err = s.orgRepo.UpdateEmployee(organizationID, userID, req)
+	err = s.orgRepo.UpdateEmployee(ctx, userID, req)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "employee not found")
@@ -189,11 +190,11 @@ func (s *OrganizationService) EmployeeUpdate(organizationID, userID string, req
 	return nil
 }
 
-func (s *OrganizationService) EmployeeDelete(organizationID, userID, uuid string) error {
+func (s *OrganizationService) EmployeeDelete(ctx context.Context, userID, uuid string) error {
 	if strings.TrimSpace(uuid) == "" {
 		return NewServiceError(ErrInvalidInput, 400, "ID is required")
 	}
-	err := s.orgRepo.DeactivateEmployeeByEmployeeID(organizationID, userID, strings.TrimSpace(uuid))
+	err := s.orgRepo.DeactivateEmployeeByEmployeeID(ctx, userID, strings.TrimSpace(uuid))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "employee not found")
@@ -203,7 +204,7 @@ func (s *OrganizationService) EmployeeDelete(organizationID, userID, uuid string
 	return nil
 }
 
-func (s *OrganizationService) EmployeeShiftSchedule(organizationID string, req *model.EmployeeShiftScheduleRequest) (*model.EmployeeShiftScheduleResponse, error) {
+func (s *OrganizationService) EmployeeShiftSchedule(ctx context.Context, req *model.EmployeeShiftScheduleRequest) (*model.EmployeeShiftScheduleResponse, error) {
 	now := time.Now()
 	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endDate := startDate.AddDate(0, 0, 6)
@@ -235,7 +236,7 @@ func (s *OrganizationService) EmployeeShiftSchedule(organizationID string, req *
 	}
 
 	rows, err := s.orgRepo.EmployeeShiftSchedule(
-		organizationID,
+		ctx,
 		strings.TrimSpace(req.RoleID),
 		strings.TrimSpace(req.DivisionID),
 		startDate,
@@ -274,7 +275,7 @@ func (s *OrganizationService) EmployeeShiftSchedule(organizationID string, req *
 	}
 
 	monthStart, monthEnd := dominantMonthRange(startDate, endDate)
-	offdays, err := s.orgRepo.EmployeeShiftOffdayCounts(organizationID, employeeUUIDs, monthStart, monthEnd)
+	offdays, err := s.orgRepo.EmployeeShiftOffdayCounts(ctx, employeeUUIDs, monthStart, monthEnd)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get employee shift offday")
 	}
@@ -350,7 +351,8 @@ func minTime(a, b time.Time) time.Time {
 	return b
 }
 
-func (s *OrganizationService) EmployeeShiftSetSchedule(organizationID, userID string, req *model.EmployeeShiftSetScheduleRequest) (interface{}, error) {
+func (s *OrganizationService) EmployeeShiftSetSchedule(ctx context.Context, userID string, req *model.EmployeeShiftSetScheduleRequest) (interface{}, error) {
+	organizationID := organizationFromContext(ctx)
 	typ := strings.ToLower(strings.TrimSpace(req.Type))
 	if typ == "delete" {
 		if strings.TrimSpace(req.ShiftID) == "" {
@@ -359,7 +361,7 @@ func (s *OrganizationService) EmployeeShiftSetSchedule(organizationID, userID st
 		if strings.TrimSpace(req.EmployeeID) == "" {
 			return nil, NewServiceError(ErrInvalidInput, 400, "employee_id is required")
 		}
-		if err := s.orgRepo.DeleteEmployeeShiftSchedule(organizationID, strings.TrimSpace(req.EmployeeID), strings.TrimSpace(req.ShiftID)); err != nil {
+		if err := s.orgRepo.DeleteEmployeeShiftSchedule(ctx, strings.TrimSpace(req.EmployeeID), strings.TrimSpace(req.ShiftID)); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, NewServiceError(ErrNotFound, 404, "shift not found")
 			}
@@ -389,17 +391,16 @@ func (s *OrganizationService) EmployeeShiftSetSchedule(organizationID, userID st
 		}
 	}
 
-	ids, err := s.orgRepo.CreateEmployeeShiftSchedules(organizationID, userID, items)
+	ids, err := s.orgRepo.CreateEmployeeShiftSchedules(ctx, userID, items)
 	if err != nil {
-		fmt.Println("CreateEmployeeShiftSchedules error:", err)
-		fmt.Println("CreateEmployeeShiftSchedules org_id:", organizationID, "user_id:", userID)
+		slog.ErrorContext(ctx, "create employee shift schedules failed", "organization_id", organizationID, "user_id", userID, "error", err)
 		if strings.Contains(strings.ToLower(err.Error()), "invalid shift_date") {
 			return nil, NewServiceError(ErrInvalidInput, 400, "invalid shift_date")
 		}
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to create shift schedule")
 	}
 	if len(ids) == 0 {
-		fmt.Println("CreateEmployeeShiftSchedules inserted 0 rows org_id:", organizationID, "user_id:", userID)
+		slog.ErrorContext(ctx, "create employee shift schedules inserted no rows", "organization_id", organizationID, "user_id", userID)
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to create shift schedule")
 	}
 	return map[string]interface{}{"shift_id": ids[0]}, nil
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+
+	"service-travego/model"
+	"service-travego/repository"
+)
+
+// trialPackageID is the package whose limits apply to organizations without
+// an active subscription
+const trialPackageID = "trave01"
+
+// EntitlementService enforces the quotas of the subscription packages in
+// config/packages.json. A nil EntitlementService allows everything.
+type EntitlementService struct {
+	repo     *repository.EntitlementRepository
+	packages []model.Package
+	once     sync.Once
+	loadErr  error
+}
+
+func NewEntitlementService(repo *repository.EntitlementRepository) *EntitlementService {
+	return &EntitlementService{repo: repo}
+}
+
+func (s *EntitlementService) loadPackages() error {
+	s.once.Do(func() {
+		f, err := os.Open("config/packages.json")
+		if err != nil {
+			s.loadErr = err
+			return
+		}
+		defer f.Close()
+
+		var data struct {
+			Packages []model.Package `json:"packages"`
+		}
+		if err := json.NewDecoder(f).Decode(&data); err != nil {
+			s.loadErr = err
+			return
+		}
+		s.packages = data.Packages
+	})
+	return s.loadErr
+}
+
+// packageOf returns the package of the organization's active subscription,
+// or the trial package when it has none
+func (s *EntitlementService) packageOf(ctx context.Context) (*model.Package, error) {
+	if err := s.loadPackages(); err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load packages")
+	}
+	packageID, err := s.repo.GetActivePackageID(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get subscription")
+	}
+
+	var trial *model.Package
+	for i := range s.packages {
+		if s.packages[i].PackageID == packageID {
+			return &s.packages[i], nil
+		}
+		if s.packages[i].PackageID == trialPackageID {
+			trial = &s.packages[i]
+		}
+	}
+	if packageID != "" {
+		slog.WarnContext(ctx, "unknown package, applying the trial package", "package_id", packageID, "organization_id", organizationFromContext(ctx))
+	}
+	if trial == nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "trial package is not configured")
+	}
+	return trial, nil
+}
+
+// checkQuota returns a quota exceeded error when adding n records to the
+// used ones goes over the package's limit of quota
+func checkQuota(pkg *model.Package, quota string, used, n int) error {
+	limit, limited := pkg.Entitlements.Limit(quota)
+	if !limited || used+n <= limit {
+		return nil
+	}
+	return NewServiceError(ErrQuotaExceeded, http.StatusForbidden, fmt.Sprintf(
+		"quota exceeded: the %s package allows %d %s (%d in use), upgrade the package to add more",
+		pkg.PackageName, limit, model.QuotaLabels[quota], used,
+	))
+}
+
+// Reserve runs create when the organization can add the records in need
+// (how many of each quota) under its package, and returns a quota exceeded
+// error otherwise. The quotas are counted under the organization's row lock,
+// held until create returns, so two requests cannot both take the last free
+// record.
+func (s *EntitlementService) Reserve(ctx context.Context, need map[string]int, create func() error) error {
+	if s == nil {
+		return create()
+	}
+	organizationID := organizationFromContext(ctx)
+	pkg, err := s.packageOf(ctx)
+	if err != nil {
+		return err
+	}
+	var quotas []string
+	for _, quota := range model.Quotas {
+		if _, limited := pkg.Entitlements.Limit(quota); limited && need[quota] > 0 {
+			quotas = append(quotas, quota)
+		}
+	}
+	if len(quotas) == 0 {
+		return create()
+	}
+
+	tx, err := s.repo.LockOrganization(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to lock organization", "organization_id", organizationID, "error", err)
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to check package quota")
+	}
+	// nothing is written in tx, rolling it back releases the lock
+	defer tx.Rollback()
+
+	for _, quota := range quotas {
+		used, err := s.repo.CountUsageTx(tx, quota)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to count quota usage", "quota", quota, "organization_id", organizationID, "error", err)
+			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to check package quota")
+		}
+		if err := checkQuota(pkg, quota, used, need[quota]); err != nil {
+			return err
+		}
+	}
+	return create()
+}
+
+// Limit returns the organization's limit of quota; false when it is unlimited
+func (s *EntitlementService) Limit(ctx context.Context, quota string) (int, bool, error) {
+	if s == nil {
+		return 0, false, nil
+	}
+	pkg, err := s.packageOf(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	limit, limited := pkg.Entitlements.Limit(quota)
+	return limit, limited, nil
+}
+
+// Usage returns the used and allowed number of records of every quota
+func (s *EntitlementService) Usage(ctx context.Context) (*model.SubscriptionUsage, error) {
+	organizationID := organizationFromContext(ctx)
+	pkg, err := s.packageOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &model.SubscriptionUsage{
+		PackageID:   pkg.PackageID,
+		PackageName: pkg.PackageName,
+		Quotas:      make([]model.QuotaUsage, 0, len(model.Quotas)),
+	}
+	for _, quota := range model.Quotas {
+		used, err := s.repo.CountUsage(ctx, quota)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to count quota usage", "quota", quota, "organization_id", organizationID, "error", err)
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get subscription usage")
+		}
+		usage.Quotas = append(usage.Quotas, quotaUsage(pkg, quota, used))
+	}
+	return usage, nil
+}
+
+func quotaUsage(pkg *model.Package, quota string, used int) model.QuotaUsage {
+	item := model.QuotaUsage{Quota: quota, Label: model.QuotaLabels[quota], Used: used}
+	if limit, limited := pkg.Entitlements.Limit(quota); limited {
+		remaining := limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		item.Limit = &limit
+		item.Remaining = &remaining
+	}
+	return item
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"service-travego/database"
+	"service-travego/model"
+)
+
+func intPtr(n int) *int { return &n }
+
+func testPackage() *model.Package {
+	return &model.Package{
+		PackageID:   "trave01",
+		PackageName: "Basic Class",
+		Entitlements: model.PackageEntitlements{
+			model.QuotaFleets:   intPtr(3),
+			model.QuotaPartners: nil,
+		},
+	}
+}
+
+func TestCheckQuotaAllowsUpToTheLimit(t *testing.T) {
+	pkg := testPackage()
+	if err := checkQuota(pkg, model.QuotaFleets, 2, 1); err != nil {
+		t.Fatalf("third fleet should be allowed, got %v", err)
+	}
+
+	err := checkQuota(pkg, model.QuotaFleets, 3, 1)
+	if !errors.Is(err, ErrQuotaExceeded) || GetStatusCode(err) != http.StatusForbidden {
+		t.Fatalf("expected a 403 quota exceeded error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Basic Class package allows 3 Armada Terdaftar") {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	// a batch counts as a whole
+	if err := checkQuota(pkg, model.QuotaFleets, 1, 3); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected the batch to exceed the quota, got %v", err)
+	}
+}
+
+func TestCheckQuotaNullOrMissingIsUnlimited(t *testing.T) {
+	pkg := testPackage()
+	if err := checkQuota(pkg, model.QuotaPartners, 1000, 1); err != nil {
+		t.Fatalf("null quota should be unlimited, got %v", err)
+	}
+	if err := checkQuota(pkg, model.QuotaFleetUnits, 1000, 1); err != nil {
+		t.Fatalf("missing quota should be unlimited, got %v", err)
+	}
+}
+
+func TestQuotaUsageRemaining(t *testing.T) {
+	pkg := testPackage()
+
+	item := quotaUsage(pkg, model.QuotaFleets, 5)
+	if item.Limit == nil || *item.Limit != 3 || item.Remaining == nil || *item.Remaining != 0 {
+		t.Fatalf("expected limit 3 and nothing remaining, got %+v", item)
+	}
+
+	item = quotaUsage(pkg, model.QuotaPartners, 5)
+	if item.Used != 5 || item.Limit != nil || item.Remaining != nil {
+		t.Fatalf("expected an unlimited quota, got %+v", item)
+	}
+}
+
+func TestNilEntitlementServiceAllowsEverything(t *testing.T) {
+	var s *EntitlementService
+	ctx := database.WithOrganization(context.Background(), "org-1")
+	created := false
+	err := s.Reserve(ctx, map[string]int{model.QuotaFleets: 100}, func() error {
+		created = true
+		return nil
+	})
+	if err != nil || !created {
+		t.Fatalf("expected the records to be created, got %v", err)
+	}
+	if _, limited, err := s.Limit(ctx, model.QuotaFleets); limited || err != nil {
+		t.Fatalf("expected no limit, got %v, %v", limited, err)
+	}
+}
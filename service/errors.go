@@ -16,6 +16,8 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUnauthorized       = errors.New("unauthorized")
 	ErrInternalServer     = errors.New("internal server error")
+	ErrTooManyAttempts    = errors.New("too many attempts")
+	ErrQuotaExceeded      = errors.New("quota exceeded")
 )
 
 // ServiceError represents a service error with HTTP status code
@@ -32,6 +34,11 @@ func (e *ServiceError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap lets errors.Is match the sentinel error of a ServiceError
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
 // NewServiceError creates a new service error
 func NewServiceError(err error, statusCode int, message string) *ServiceError {
 	return &ServiceError{
@@ -59,6 +66,10 @@ func GetStatusCode(err error) int {
 		return http.StatusBadRequest
 	case errors.Is(err, ErrUnauthorized):
 		return http.StatusUnauthorized
+	case errors.Is(err, ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrQuotaExceeded):
+		return http.StatusForbidden
 	default:
 		return http.StatusInternalServerError
 	}
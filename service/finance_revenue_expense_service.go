@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"net/http"
 	"service-travego/model"
 	"service-travego/repository"
@@ -56,7 +57,8 @@ func GetOrderTypeLabel(orderType int) string {
 }
 
 // GetGroupedRevenueByOrderType returns revenue grouped by each order_type
-func (s *FinanceRevenueExpenseService) GetGroupedRevenueByOrderType(orgID string, req *model.TransactionListRequest) ([]GroupedRevenueEntry, error) {
+func (s *FinanceRevenueExpenseService) GetGroupedRevenueByOrderType(ctx context.Context, req *model.TransactionListRequest) ([]GroupedRevenueEntry, error) {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
 	}
@@ -68,7 +70,7 @@ func (s *FinanceRevenueExpenseService) GetGroupedRevenueByOrderType(orgID string
 	}
 
 	// Get all revenue transactions
-	rows, err := s.txnRepo.ListAllRevenue(orgID, req)
+	rows, err := s.txnRepo.ListAllRevenue(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +106,8 @@ func (s *FinanceRevenueExpenseService) GetGroupedRevenueByOrderType(orgID string
 }
 
 // GetGroupedExpensesByOrderType returns expenses grouped by each order_type
-func (s *FinanceRevenueExpenseService) GetGroupedExpensesByOrderType(orgID string, req *model.TransactionListRequest) ([]GroupedExpenseEntry, error) {
+func (s *FinanceRevenueExpenseService) GetGroupedExpensesByOrderType(ctx context.Context, req *model.TransactionListRequest) ([]GroupedExpenseEntry, error) {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
 	}
@@ -116,7 +119,7 @@ func (s *FinanceRevenueExpenseService) GetGroupedExpensesByOrderType(orgID strin
 	}
 
 	// Get all expense transactions
-	rows, err := s.txnRepo.ListAllExpenses(orgID, req)
+	rows, err := s.txnRepo.ListAllExpenses(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,9 @@
 package service
 
-import "service-travego/repository"
+import (
+	"context"
+	"service-travego/repository"
+)
 
 type FleetMetaService struct {
 	repo *repository.FleetMetaRepository
@@ -10,10 +13,10 @@ func NewFleetMetaService(repo *repository.FleetMetaRepository) *FleetMetaService
 	return &FleetMetaService{repo: repo}
 }
 
-func (s *FleetMetaService) GetBodies(orgID string, search string) ([]string, error) {
-	return s.repo.FindBodies(orgID, search)
+func (s *FleetMetaService) GetBodies(ctx context.Context, search string) ([]string, error) {
+	return s.repo.FindBodies(ctx, search)
 }
 
-func (s *FleetMetaService) GetEngines(orgID string, search string) ([]string, error) {
-	return s.repo.FindEngines(orgID, search)
+func (s *FleetMetaService) GetEngines(ctx context.Context, search string) ([]string, error) {
+	return s.repo.FindEngines(ctx, search)
 }
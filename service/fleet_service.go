@@ -1,12 +1,16 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"service-travego/configs"
+	"service-travego/database"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/repository"
@@ -20,6 +24,11 @@ import (
 
 type FleetService struct {
 	repo                *repository.FleetRepository
+	priceRuleService    *PriceRuleService
+	paymentPlanService  *PaymentPlanService
+	cancellationService *CancellationPolicyService
+	audit               *AuditService
+	entitlements        *EntitlementService
 	citiesName          map[string]string
 	paymentMethodLabels map[int]string
 	paymentTypeLabels   map[int]string
@@ -72,33 +81,52 @@ func NewFleetService(repo *repository.FleetRepository) *FleetService {
 	return &FleetService{repo: repo}
 }
 
-func (s *FleetService) CreateFleet(createdBy, organizationID string, req *model.CreateFleetRequest) (string, error) {
+func (s *FleetService) CreateFleet(ctx context.Context, createdBy string, req *model.CreateFleetRequest) (string, error) {
 	if req.FleetName == "" || req.FleetType == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleet_name and fleet_type are required")
 	}
+	var id string
+	err := s.entitlements.Reserve(ctx, map[string]int{model.QuotaFleets: 1}, func() (err error) {
+		id, err = s.createFleet(ctx, createdBy, req)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    createdBy,
+		EntityType: model.AuditEntityFleet, EntityID: id, Action: model.AuditActionCreate,
+		After: s.fleetAuditSnapshot(ctx, id),
+	})
+	return id, nil
+}
+
+func (s *FleetService) createFleet(ctx context.Context, createdBy string, req *model.CreateFleetRequest) (string, error) {
+	organizationID := organizationFromContext(ctx)
 	req.CreatedBy = createdBy
 	req.OrganizationID = organizationID
 
 	if len(req.Facilities) > 0 {
-		facilityIDs, err := s.repo.InsertFacilities(organizationID, req.Facilities)
-		fmt.Println("--- check facilityIDs ", err)
+		facilityIDs, err := s.repo.InsertFacilities(ctx, req.Facilities)
 		if err != nil {
+			slog.ErrorContext(ctx, "insert fleet facilities failed", "error", err)
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create facilities")
 		}
-		fmt.Println("--- check facilityIDs ", facilityIDs)
+		slog.DebugContext(ctx, "fleet facilities inserted", "facility_ids", facilityIDs)
 		req.FacilityIDs = append(req.FacilityIDs, facilityIDs...)
 	}
-	fmt.Println("--- check req.FacilityIDs ", req.FacilityIDs)
+	slog.DebugContext(ctx, "fleet facilities", "facility_ids", req.FacilityIDs)
 
-	id, err := s.repo.CreateFleet(req)
+	id, err := s.repo.CreateFleet(ctx, req)
 	if err != nil {
-		fmt.Println("--- Error creating fleet:", err)
+		slog.ErrorContext(ctx, "error creating fleet", "error", err)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create fleet")
 	}
 	return id, nil
 }
 
-func (s *FleetService) UpdateFleet(updatedBy, organizationID string, req *model.UpdateFleetRequest) error {
+func (s *FleetService) UpdateFleet(ctx context.Context, updatedBy string, req *model.UpdateFleetRequest) error {
+	organizationID := organizationFromContext(ctx)
 	if req.FleetID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleet_id is required")
 	}
@@ -109,7 +137,7 @@ func (s *FleetService) UpdateFleet(updatedBy, organizationID string, req *model.
 		facilityIDs := make([]string, 0, len(req.Facilities)+len(req.FacilityIDs))
 		for _, it := range req.Facilities {
 			if strings.TrimSpace(it.UUID) == "" && strings.TrimSpace(it.Facility) != "" {
-				newIDs, err := s.repo.InsertFacilities(organizationID, []string{it.Facility})
+				newIDs, err := s.repo.InsertFacilities(ctx, []string{it.Facility})
 				if err != nil {
 					return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create facility")
 				}
@@ -122,19 +150,25 @@ func (s *FleetService) UpdateFleet(updatedBy, organizationID string, req *model.
 		req.FacilityIDs = dedupeStrings(facilityIDs)
 	}
 
-	if err := s.repo.UpdateFleet(req); err != nil {
+	before := s.fleetAuditSnapshot(ctx, req.FleetID)
+	if err := s.repo.UpdateFleet(ctx, req); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "fleet not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update fleet")
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityFleet, EntityID: req.FleetID, Action: model.AuditActionUpdate,
+		Before: before, After: s.fleetAuditSnapshot(ctx, req.FleetID),
+	})
 	return nil
 }
 
-func (s *FleetService) GetServiceFleets(page, perPage int) ([]model.ServiceFleetItem, error) {
-	items, err := s.repo.GetServiceFleets(page, perPage)
+func (s *FleetService) GetServiceFleets(ctx context.Context, page, perPage int) ([]model.ServiceFleetItem, error) {
+	items, err := s.repo.GetServiceFleets(ctx, page, perPage)
 	if err != nil {
-		fmt.Println("Error fetching service fleets:", err)
+		slog.ErrorContext(ctx, "error fetching service fleets", "error", err)
 		return nil, err
 	}
 
@@ -168,8 +202,8 @@ func (s *FleetService) GetServiceFleets(page, perPage int) ([]model.ServiceFleet
 	return items, nil
 }
 
-func (s *FleetService) GetAvailableCities(orgID string) ([]model.ServiceFleetPickupItem, error) {
-	cityIDs, err := s.repo.GetAvailableCities(orgID)
+func (s *FleetService) GetAvailableCities(ctx context.Context) ([]model.ServiceFleetPickupItem, error) {
+	cityIDs, err := s.repo.GetAvailableCities(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -216,40 +250,43 @@ func (s *FleetService) GetAvailableCities(orgID string) ([]model.ServiceFleetPic
 	return cities, nil
 }
 
-func (s *FleetService) GetServiceFleetDetail(fleetID string) (*model.ServiceFleetDetailResponse, error) {
+func (s *FleetService) GetServiceFleetDetail(ctx context.Context, fleetID string) (*model.ServiceFleetDetailResponse, error) {
 	// First resolve OrgID
-	orgID, err := s.repo.GetFleetOrgID(fleetID)
+	orgID, err := s.repo.GetFleetOrgID(ctx, fleetID)
 	if err != nil {
-		fmt.Println("Error fetching fleet org ID:", err)
+		slog.ErrorContext(ctx, "error fetching fleet org ID", "error", err)
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "fleet not found")
 	}
+	// the fleet may be a public one of another organization; its details are
+	// read for the organization that owns it
+	ctx = database.WithOrganization(ctx, orgID)
 
-	meta, err := s.repo.GetFleetDetailMeta(orgID, fleetID)
+	meta, err := s.repo.GetFleetDetailMeta(ctx, fleetID)
 	if err != nil {
-		fmt.Println("Error fetching fleet detail meta:", err)
+		slog.ErrorContext(ctx, "error fetching fleet detail meta", "error", err)
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "fleet not found")
 	}
-	fac, err := s.repo.GetFleetFacilities(fleetID)
+	fac, err := s.repo.GetFleetFacilities(ctx, fleetID)
 	if err != nil {
-		fmt.Println("Error fetching fleet facilities:", err)
+		slog.ErrorContext(ctx, "error fetching fleet facilities", "error", err)
 		fac = []model.FacilityItem{}
 	}
-	pickup, err := s.repo.GetFleetPickup(orgID, fleetID)
+	pickup, err := s.repo.GetFleetPickup(ctx, fleetID)
 	if err != nil {
-		fmt.Println("Error fetching fleet pickup:", err)
+		slog.ErrorContext(ctx, "error fetching fleet pickup", "error", err)
 		pickup = []model.FleetPickupItem{}
 	}
-	addon, err := s.repo.GetFleetAddon(orgID, fleetID)
+	addon, err := s.repo.GetFleetAddon(ctx, fleetID)
 	if err != nil {
-		fmt.Println("Error fetching fleet addon:", err)
+		slog.ErrorContext(ctx, "error fetching fleet addon", "error", err)
 		addon = []model.FleetAddonItem{}
 	}
-	prices, err := s.repo.GetFleetPrices(orgID, fleetID)
+	prices, err := s.repo.GetFleetPrices(ctx, fleetID)
 	if err != nil {
-		fmt.Println("Error fetching fleet prices:", err)
+		slog.ErrorContext(ctx, "error fetching fleet prices", "error", err)
 		prices = []model.FleetPriceItem{}
 	}
-	images, err := s.repo.GetFleetImages(fleetID)
+	images, err := s.repo.GetFleetImages(ctx, fleetID)
 	if err != nil {
 		images = []model.FleetImageItem{}
 	}
@@ -296,8 +333,8 @@ func (s *FleetService) GetServiceFleetDetail(fleetID string) (*model.ServiceFlee
 	return resp, nil
 }
 
-func (s *FleetService) GetPartnerOrderList(orgID string, filter *model.PartnerOrderListFilter) ([]model.PartnerOrderListItem, error) {
-	items, err := s.repo.GetPartnerOrderList(orgID, filter)
+func (s *FleetService) GetPartnerOrderList(ctx context.Context, filter *model.PartnerOrderListFilter) ([]model.PartnerOrderListItem, error) {
+	items, err := s.repo.GetPartnerOrderList(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -306,33 +343,15 @@ func (s *FleetService) GetPartnerOrderList(orgID string, filter *model.PartnerOr
 		if token, err := helper.EncryptString(items[i].OrderID); err == nil {
 			items[i].TransactionID = token
 		}
-		PaymentStatusLabel := "Belum dibayar"
-		if items[i].PaymentStatus == 1 {
-			PaymentStatusLabel = "Lunas"
-		}
-		if items[i].PaymentStatus == 2 {
-			PaymentStatusLabel = "Belum Dibayar"
-		}
-		if items[i].PaymentStatus == 3 {
-			PaymentStatusLabel = "Menunggi verifikasi"
-		}
-		if items[i].PaymentStatus == 4 {
-			PaymentStatusLabel = "Belum Lunas"
-		}
-		items[i].PaymentStatusLabel = PaymentStatusLabel
-		if items[i].PaymentStatus == 3 || items[i].PaymentStatus == 4 {
-			items[i].LatestPaymentStatus = s.paymentTypeLabels[items[i].LatestPaymentType]
-		} else {
-			items[i].LatestPaymentStatus = ""
-		}
+		s.labelPartnerOrder(&items[i])
 	}
 	return items, nil
 }
 
-func (s *FleetService) GetPartnerOrdersWithSummary(orgID string, filter *model.PartnerOrderListFilter) (*model.PartnerOrderListResponse, error) {
+func (s *FleetService) GetPartnerOrdersWithSummary(ctx context.Context, filter *model.PartnerOrderListFilter) (*model.PartnerOrderListResponse, error) {
 	normalizedFilter := normalizePartnerOrderListFilter(filter)
 
-	items, err := s.repo.GetPartnerOrderList(orgID, normalizedFilter)
+	items, err := s.repo.GetPartnerOrderList(ctx, normalizedFilter)
 	if err != nil {
 		msg := "failed to get order list"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -345,27 +364,9 @@ func (s *FleetService) GetPartnerOrdersWithSummary(orgID string, filter *model.P
 		if token, err := helper.EncryptString(items[i].OrderID); err == nil {
 			items[i].TransactionID = token
 		}
-		PaymentStatusLabel := "Belum dibayar"
-		if items[i].PaymentStatus == 1 {
-			PaymentStatusLabel = "Lunas"
-		}
-		if items[i].PaymentStatus == 2 {
-			PaymentStatusLabel = "Belum Dibayar"
-		}
-		if items[i].PaymentStatus == 3 {
-			PaymentStatusLabel = "Menunggi verifikasi"
-		}
-		if items[i].PaymentStatus == 4 {
-			PaymentStatusLabel = "Belum Lunas"
-		}
-		items[i].PaymentStatusLabel = PaymentStatusLabel
-		if items[i].PaymentStatus == 3 || items[i].PaymentStatus == 4 {
-			items[i].LatestPaymentStatus = s.paymentTypeLabels[items[i].LatestPaymentType]
-		} else {
-			items[i].LatestPaymentStatus = ""
-		}
+		s.labelPartnerOrder(&items[i])
 	}
-	summary, err := s.repo.GetPartnerOrderSummary(orgID, normalizedFilter)
+	summary, err := s.repo.GetPartnerOrderSummary(ctx, normalizedFilter)
 	if err != nil {
 		msg := "failed to get order summary"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -379,6 +380,58 @@ func (s *FleetService) GetPartnerOrdersWithSummary(orgID string, filter *model.P
 	}, nil
 }
 
+// labelPartnerOrder sets the payment status labels of an order list item
+func (s *FleetService) labelPartnerOrder(item *model.PartnerOrderListItem) {
+	PaymentStatusLabel := "Belum dibayar"
+	if item.PaymentStatus == 1 {
+		PaymentStatusLabel = "Lunas"
+	}
+	if item.PaymentStatus == 2 {
+		PaymentStatusLabel = "Belum Dibayar"
+	}
+	if item.PaymentStatus == 3 {
+		PaymentStatusLabel = "Menunggi verifikasi"
+	}
+	if item.PaymentStatus == 4 {
+		PaymentStatusLabel = "Belum Lunas"
+	}
+	item.PaymentStatusLabel = PaymentStatusLabel
+	if item.PaymentStatus == 3 || item.PaymentStatus == 4 {
+		item.LatestPaymentStatus = s.paymentTypeLabels[item.LatestPaymentType]
+	} else {
+		item.LatestPaymentStatus = ""
+	}
+}
+
+// partnerOrderExportPage is how many orders ExportPartnerOrders reads at once
+const partnerOrderExportPage = 500
+
+// ExportPartnerOrders calls fn with every order of the order list, newest
+// first. The orders are read a page at a time, so an export never holds the
+// whole list in memory.
+func (s *FleetService) ExportPartnerOrders(ctx context.Context, filter *model.PartnerOrderListFilter, fn func(model.PartnerOrderListItem) error) error {
+	normalizedFilter := normalizePartnerOrderListFilter(filter)
+	s.ensurePaymentTypesLoaded()
+
+	var after model.PartnerOrderCursor
+	for {
+		items, next, err := s.repo.GetPartnerOrderPage(ctx, normalizedFilter, after, partnerOrderExportPage)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			s.labelPartnerOrder(&items[i])
+			if err := fn(items[i]); err != nil {
+				return err
+			}
+		}
+		if len(items) < partnerOrderExportPage {
+			return nil
+		}
+		after = next
+	}
+}
+
 func normalizePartnerOrderListFilter(filter *model.PartnerOrderListFilter) *model.PartnerOrderListFilter {
 	if filter == nil {
 		return nil
@@ -398,8 +451,8 @@ func normalizePartnerOrderListFilter(filter *model.PartnerOrderListFilter) *mode
 	return &normalized
 }
 
-func (s *FleetService) GetPartnerOrderDetail(orderID, orgID string) (*model.OrderDetailResponse, error) {
-	res, err := s.repo.GetPartnerOrderDetail(orderID, orgID)
+func (s *FleetService) GetPartnerOrderDetail(ctx context.Context, orderID string) (*model.OrderDetailResponse, error) {
+	res, err := s.repo.GetPartnerOrderDetail(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
@@ -454,16 +507,23 @@ func (s *FleetService) GetPartnerOrderDetail(orderID, orgID string) (*model.Orde
 	}
 
 	// Get fleet items
-	fleetItems, err := s.repo.GetPartnerOrderFleetItems(orgID, orderID)
+	fleetItems, err := s.repo.GetPartnerOrderFleetItems(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order fleet items")
 	}
 	res.Fleets = fleetItems
 
+	res.PriceRules = []model.PriceRuleLine{}
+	if s.priceRuleService != nil {
+		if lines, err := s.priceRuleService.OrderPriceRules(ctx, orderID); err == nil {
+			res.PriceRules = lines
+		}
+	}
+
 	// Get schedule info
 	res.Scheduled = false
 	res.PaymentStatusLabel = PaymentStatusLabel
-	schedule, err := s.repo.GetScheduleByOrderID(orderID)
+	schedule, err := s.repo.GetScheduleByOrderID(ctx, orderID)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get schedule")
 	}
@@ -473,14 +533,21 @@ func (s *FleetService) GetPartnerOrderDetail(orderID, orgID string) (*model.Orde
 	return res, nil
 }
 
-func (s *FleetService) GetPartnerOrderPaymentSummary(orderID, orgID string, totalAmount float64) (*model.PaymentSummary, error) {
-	totalAddon, totalDiscount, totalCharge, totalPayment, err := s.repo.GetFleetOrderItemTotals(orderID, orgID)
+func (s *FleetService) GetPartnerOrderPaymentSummary(ctx context.Context, orderID string, totalAmount float64) (*model.PaymentSummary, error) {
+	totalAddon, totalDiscount, totalCharge, totalPayment, err := s.repo.GetFleetOrderItemTotals(ctx, orderID)
 	if err != nil {
-		fmt.Println("GetFleetOrderItemTotals error:", err)
+		slog.ErrorContext(ctx, "get fleet order item totals failed", "error", err)
 		return nil, err
 	}
 
-	row, err := s.repo.GetLatestPaymentOrder(orderID, 1, orgID)
+	var installments []model.OrderInstallment
+	if s.paymentPlanService != nil {
+		if items, err := s.paymentPlanService.OrderInstallments(ctx, orderID); err == nil && len(items) > 0 {
+			installments = items
+		}
+	}
+
+	row, err := s.repo.GetLatestPaymentOrder(ctx, orderID, 1)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return &model.PaymentSummary{
@@ -490,6 +557,7 @@ func (s *FleetService) GetPartnerOrderPaymentSummary(orderID, orgID string, tota
 				TotalDiscount:    totalDiscount,
 				TotalCharge:      totalCharge,
 				PaymentStatus:    "unpaid",
+				Installments:     installments,
 			}, nil
 		}
 		return nil, err
@@ -515,14 +583,15 @@ func (s *FleetService) GetPartnerOrderPaymentSummary(orderID, orgID string, tota
 		PaymentMethodLabel: s.paymentMethodLabels[row.PaymentMethod],
 		PaymentStatus:      status,
 		PaymentDate:        row.CreatedAt.Format("2006-01-02 15:04:05"),
+		Installments:       installments,
 	}, nil
 }
 
-func (s *FleetService) GetFleetAddonList(orgID, fleetID string) ([]model.FleetAddonListItem, error) {
+func (s *FleetService) GetFleetAddonList(ctx context.Context, fleetID string) ([]model.FleetAddonListItem, error) {
 	if fleetID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleetid is required")
 	}
-	addons, err := s.repo.GetFleetAddon(orgID, fleetID)
+	addons, err := s.repo.GetFleetAddon(ctx, fleetID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get fleet addon")
 	}
@@ -537,7 +606,8 @@ func (s *FleetService) GetFleetAddonList(orgID, fleetID string) ([]model.FleetAd
 	return items, nil
 }
 
-func (s *FleetService) GetFleetPricesByFleetID(orgID, fleetID, typeID string) ([]model.FleetPriceListItem, error) {
+func (s *FleetService) GetFleetPricesByFleetID(ctx context.Context, fleetID, typeID string) ([]model.FleetPriceListItem, error) {
+	orgID := organizationFromContext(ctx)
 	if fleetID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleetid is required")
 	}
@@ -550,7 +620,7 @@ func (s *FleetService) GetFleetPricesByFleetID(orgID, fleetID, typeID string) ([
 	}
 	_ = orgID
 
-	items, err := s.repo.GetFleetPriceListByRentType(fleetID, rentType)
+	items, err := s.repo.GetFleetPriceListByRentType(ctx, fleetID, rentType)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get fleet prices")
 	}
@@ -561,7 +631,57 @@ func (s *FleetService) GetFleetPricesByFleetID(orgID, fleetID, typeID string) ([
 	return items, nil
 }
 
-func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.FleetOrderCreateRequest) (string, error) {
+// SetPriceRuleService enables dynamic pricing rules on partner order creation.
+func (s *FleetService) SetPriceRuleService(priceRuleService *PriceRuleService) {
+	s.priceRuleService = priceRuleService
+}
+
+// SetPaymentPlanService adds the installment schedule to the order payment summary.
+func (s *FleetService) SetPaymentPlanService(paymentPlanService *PaymentPlanService) {
+	s.paymentPlanService = paymentPlanService
+}
+
+// SetAuditService records fleet and order changes in the audit log
+func (s *FleetService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetEntitlementService limits new fleets to the organization's package
+func (s *FleetService) SetEntitlementService(entitlements *EntitlementService) {
+	s.entitlements = entitlements
+}
+
+// fleetAuditSnapshot loads a fleet for the audit log; nil when auditing is off
+func (s *FleetService) fleetAuditSnapshot(ctx context.Context, fleetID string) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	detail, err := s.GetFleetDetail(ctx, fleetID)
+	if err != nil {
+		return nil
+	}
+	return detail
+}
+
+// orderAuditSnapshot loads an order for the audit log; nil when auditing is off
+func (s *FleetService) orderAuditSnapshot(ctx context.Context, orderID string) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	detail, err := s.GetPartnerOrderDetail(ctx, orderID)
+	if err != nil {
+		return nil
+	}
+	return detail
+}
+
+// SetCancellationPolicyService computes cancellation refunds from the organization's policy.
+func (s *FleetService) SetCancellationPolicyService(cancellationService *CancellationPolicyService) {
+	s.cancellationService = cancellationService
+}
+
+func (s *FleetService) CreatePartnerOrder(ctx context.Context, userID string, req *model.FleetOrderCreateRequest) (string, error) {
+	orgID := organizationFromContext(ctx)
 	if req.FleetID == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleet_id is required")
 	}
@@ -571,7 +691,7 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 		if strings.TrimSpace(req.CustomerName) == "" {
 			return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "customer_name is required when customer_id is not provided")
 		}
-		newCustomerID, err := s.repo.CreateCustomer(req.CustomerName, req.CustomerPhone, req.CustomerCompany, orgID)
+		newCustomerID, err := s.repo.CreateCustomer(ctx, req.CustomerName, req.CustomerPhone, req.CustomerCompany)
 		if err != nil {
 			msg := "failed to create customer"
 			if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -655,7 +775,7 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 	}
 
 	price := req.Price
-	dbPrice, _, err := s.repo.GetPriceByID(req.PriceID)
+	dbPrice, _, err := s.repo.GetPriceByID(ctx, req.PriceID)
 	if err != nil {
 		if price <= 0 {
 			return "", NewServiceError(ErrNotFound, http.StatusNotFound, "price not found")
@@ -686,14 +806,17 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 		}
 	}
 
-	priceMap, _ := s.repo.GetFleetPricesByIDs(priceIDs)
-	addonPriceMap, err := s.repo.GetAddonPrices(addonIDs)
+	priceMap, _ := s.repo.GetFleetPricesByIDs(ctx, priceIDs)
+	addonPriceMap, err := s.repo.GetAddonPrices(ctx, addonIDs)
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to calc addons")
 	}
 
+	pricingStart, _ := parsePricingDate(startDate)
+	pricingEnd, _ := parsePricingDate(endDate)
+
 	itemsTotal := 0.0
-	for _, f := range req.Fleets {
+	for i, f := range req.Fleets {
 		q := f.Qty
 		if q <= 0 {
 			q = 1
@@ -702,11 +825,44 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 		if strings.TrimSpace(f.PriceID) != "" {
 			if p, ok := priceMap[strings.TrimSpace(f.PriceID)]; ok {
 				unitPrice = p
-			} else if p, _, e := s.repo.GetPriceByID(strings.TrimSpace(f.PriceID)); e == nil {
+			} else if p, _, e := s.repo.GetPriceByID(ctx, strings.TrimSpace(f.PriceID)); e == nil {
 				unitPrice = p
 			}
 		}
 
+		// Apply pricing rules (season, weekend, lead time, pickup city) on the unit price
+		if s.priceRuleService != nil {
+			linePriceID := strings.TrimSpace(f.PriceID)
+			if linePriceID == "" {
+				linePriceID = strings.TrimSpace(req.PriceID)
+			}
+			rentType := req.RentType
+			if _, rt, e := s.repo.GetPriceByID(ctx, linePriceID); e == nil {
+				rentType = rt
+			}
+			fleetID := strings.TrimSpace(f.ArmadaID)
+			if fleetID == "" {
+				fleetID = strings.TrimSpace(req.FleetID)
+			}
+			pricing, err := s.priceRuleService.Quote(ctx, model.PriceQuoteInput{
+				FleetID:      fleetID,
+				PriceID:      linePriceID,
+				RentType:     rentType,
+				BasePrice:    unitPrice,
+				Qty:          q,
+				StartDate:    pricingStart,
+				EndDate:      pricingEnd,
+				PickupCityID: req.PickupCityID,
+				OrderedAt:    time.Now(),
+			})
+			if err != nil {
+				return "", err
+			}
+			req.Fleets[i].RuleAdjustment = pricing.UnitAdjustment
+			req.Fleets[i].PriceRuleLines = pricing.Lines
+			f = req.Fleets[i]
+		}
+
 		ids := make([]string, 0, len(f.Addons)+1)
 		for _, id := range f.Addons {
 			id = strings.TrimSpace(id)
@@ -727,7 +883,7 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 			addonAmount += addonPriceMap[id]
 		}
 
-		subTotal := (unitPrice * float64(q)) + (f.BiayaLain * float64(q)) + (addonAmount * float64(q)) - (f.Discount * float64(q))
+		subTotal := (unitPrice * float64(q)) + (f.RuleAdjustment * float64(q)) + (f.BiayaLain * float64(q)) + (addonAmount * float64(q)) - (f.Discount * float64(q))
 		if subTotal < 0 {
 			subTotal = 0
 		}
@@ -742,19 +898,19 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 	if orgID == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization context missing")
 	}
-	orgCode, err := s.repo.GetOrganizationCodeByOrgID(orgID)
+	orgCode, err := s.repo.GetOrganizationCodeByOrgID(ctx)
 	if err != nil || strings.TrimSpace(orgCode) == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization context missing")
 	}
 
-	count, err := s.repo.GetOrderCountByOrgID(orgID)
+	count, err := s.repo.GetOrderCountByOrgID(ctx)
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order count")
 	}
 
 	orderID := utils.GenerateOrderID(1, orgCode, count)
 
-	if err := s.repo.CreatePartnerOrder(orderID, req.FleetID, startDate, endDate, req.PickupCityID, pickupLoc, qty, req.PriceID, totalAmount, req.AdditionalAmount, req.CustomerID, orgID, userID, req.Itinerary, req.Addons, req.AdditionalRequest, req.Fleets); err != nil {
+	if err := s.repo.CreatePartnerOrder(ctx, orderID, req.FleetID, startDate, endDate, req.PickupCityID, pickupLoc, qty, req.PriceID, totalAmount, req.AdditionalAmount, req.CustomerID, userID, req.Itinerary, req.Addons, req.AdditionalRequest, req.Fleets); err != nil {
 		msg := "failed to create order"
 		env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))
 		if env != "production" && env != "prod" {
@@ -762,10 +918,41 @@ func (s *FleetService) CreatePartnerOrder(orgID, userID string, req *model.Fleet
 		}
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, msg)
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrder, EntityID: orderID, Action: model.AuditActionCreate,
+		After: s.orderAuditSnapshot(ctx, orderID),
+	})
 	return orderID, nil
 }
 
-func (s *FleetService) UpdatePartnerOrder(orgID, userID string, req *FleetOrderUpdateRequest) error {
+// samePricingDay reports whether two trip dates fall on the same day, the unit
+// the pricing rules are evaluated in
+func samePricingDay(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() == b.IsZero()
+	}
+	return a.In(time.Local).Format("2006-01-02") == b.In(time.Local).Format("2006-01-02")
+}
+
+// needsRequote reports whether the pricing rules of an order item have to be
+// evaluated again: new items, and items whose trip, pickup city, fleet, price
+// or quantity changed since they were quoted
+func needsRequote(before *repository.PartnerOrderPricing, orderItemID string, after repository.PartnerOrderItemPricing, start, end time.Time, pickupCityID string) bool {
+	item, ok := before.Items[orderItemID]
+	if !ok {
+		return true
+	}
+	return !strings.EqualFold(item.FleetID, after.FleetID) ||
+		!strings.EqualFold(item.PriceID, after.PriceID) ||
+		item.Quantity != after.Quantity ||
+		!samePricingDay(before.StartDate, start) ||
+		!samePricingDay(before.EndDate, end) ||
+		before.PickupCityID != pickupCityID
+}
+
+func (s *FleetService) UpdatePartnerOrder(ctx context.Context, userID string, req *FleetOrderUpdateRequest) error {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(req.OrderID) == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
@@ -810,16 +997,33 @@ func (s *FleetService) UpdatePartnerOrder(orgID, userID string, req *FleetOrderU
 				}
 			}
 		}
-		priceMap, err := s.repo.GetFleetPricesByIDs(priceIDs)
+		priceMap, err := s.repo.GetFleetPricesByIDs(ctx, priceIDs)
 		if err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to calc prices")
 		}
 
-		addonPriceMap, err := s.repo.GetAddonPrices(addonIDs)
+		addonPriceMap, err := s.repo.GetAddonPrices(ctx, addonIDs)
 		if err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to calc addons")
 		}
 
+		// Pricing rules applied before stay on items whose trip and price did
+		// not change, the others are quoted again
+		pricing, err := s.repo.GetPartnerOrderPricing(ctx, req.OrderID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
+			}
+			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order pricing")
+		}
+		voucherDiscounts, err := s.repo.GetOrderItemVoucherDiscounts(ctx, req.OrderID)
+		if err != nil {
+			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order vouchers")
+		}
+		pricingStart, _ := parsePricingDate(startDate)
+		pricingEnd, _ := parsePricingDate(endDate)
+		pickupCityID := strings.TrimSpace(req.PickupCityID)
+
 		for _, it := range req.Fleets {
 			q := it.Qty
 			if q <= 0 {
@@ -831,7 +1035,7 @@ func (s *FleetService) UpdatePartnerOrder(orgID, userID string, req *FleetOrderU
 				if p, ok := priceMap[it.PriceID]; ok {
 					unitPrice = p
 				} else {
-					p, _, err := s.repo.GetPriceByID(it.PriceID)
+					p, _, err := s.repo.GetPriceByID(ctx, it.PriceID)
 					if err == nil {
 						unitPrice = p
 					}
@@ -851,28 +1055,57 @@ func (s *FleetService) UpdatePartnerOrder(orgID, userID string, req *FleetOrderU
 				addonAmount += addonPriceMap[id]
 			}
 
-			subTotal := (unitPrice * float64(q)) + (it.BiayaLain * float64(q)) + (addonAmount * float64(q)) - (it.Discount * float64(q))
+			orderItemID := strings.TrimSpace(it.OrderItemID)
+			item := repository.UpdatePartnerOrderFleetItem{
+				OrderItemID:    orderItemID,
+				FleetID:        strings.TrimSpace(it.ArmadaID),
+				PriceID:        strings.TrimSpace(it.PriceID),
+				Qty:            q,
+				ChargeAmount:   it.BiayaLain,
+				AddonAmount:    addonAmount,
+				Discount:       it.Discount,
+				Addons:         it.Addons,
+				RuleAdjustment: pricing.Items[orderItemID].RuleAdjustment,
+			}
+			after := repository.PartnerOrderItemPricing{FleetID: item.FleetID, PriceID: item.PriceID, Quantity: q}
+			if s.priceRuleService != nil && needsRequote(pricing, orderItemID, after, pricingStart, pricingEnd, pickupCityID) {
+				rentType := req.RentType
+				if _, rt, e := s.repo.GetPriceByID(ctx, item.PriceID); e == nil {
+					rentType = rt
+				}
+				quote, err := s.priceRuleService.Quote(ctx, model.PriceQuoteInput{
+					FleetID:      item.FleetID,
+					PriceID:      item.PriceID,
+					RentType:     rentType,
+					BasePrice:    unitPrice,
+					Qty:          q,
+					StartDate:    pricingStart,
+					EndDate:      pricingEnd,
+					PickupCityID: pickupCityID,
+					OrderedAt:    pricing.OrderedAt,
+				})
+				if err != nil {
+					return err
+				}
+				item.Repriced = true
+				item.RuleAdjustment = quote.UnitAdjustment
+				item.PriceRuleLines = quote.Lines
+			}
+
+			subTotal := (unitPrice * float64(q)) + (item.RuleAdjustment * float64(q)) + (it.BiayaLain * float64(q)) + (addonAmount * float64(q)) - (it.Discount * float64(q))
+			subTotal -= voucherDiscounts[orderItemID]
 			if subTotal < 0 {
 				subTotal = 0
 			}
 			itemsTotal += subTotal
+			item.SubTotal = subTotal
 
-			updateItems = append(updateItems, repository.UpdatePartnerOrderFleetItem{
-				OrderItemID:  strings.TrimSpace(it.OrderItemID),
-				FleetID:      strings.TrimSpace(it.ArmadaID),
-				PriceID:      strings.TrimSpace(it.PriceID),
-				Qty:          q,
-				ChargeAmount: it.BiayaLain,
-				AddonAmount:  addonAmount,
-				Discount:     it.Discount,
-				SubTotal:     subTotal,
-				Addons:       it.Addons,
-			})
+			updateItems = append(updateItems, item)
 		}
 	} else {
 		price := req.Price
 		if strings.TrimSpace(req.PriceID) != "" {
-			dbPrice, _, err := s.repo.GetPriceByID(req.PriceID)
+			dbPrice, _, err := s.repo.GetPriceByID(ctx, req.PriceID)
 			if err != nil {
 				if price <= 0 {
 					return NewServiceError(ErrNotFound, http.StatusNotFound, "price not found")
@@ -926,11 +1159,15 @@ func (s *FleetService) UpdatePartnerOrder(orgID, userID string, req *FleetOrderU
 		Itinerary:         updateItinerary,
 	}
 
-	if err := s.repo.UpdatePartnerOrder(in); err != nil {
-		fmt.Println(err)
+	before := s.orderAuditSnapshot(ctx, req.OrderID)
+	if err := s.repo.UpdatePartnerOrder(ctx, in); err != nil {
+		slog.ErrorContext(ctx, "update partner order failed", "error", err)
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
 		}
+		if errors.Is(err, repository.ErrInstallmentsLocked) {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "INSTALLMENTS_ALREADY_IN_PAYMENT")
+		}
 		msg := "failed to update order"
 		env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV")))
 		if env != "production" && env != "prod" {
@@ -938,11 +1175,16 @@ func (s *FleetService) UpdatePartnerOrder(orgID, userID string, req *FleetOrderU
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, msg)
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrder, EntityID: req.OrderID, Action: model.AuditActionUpdate,
+		Before: before, After: s.orderAuditSnapshot(ctx, req.OrderID),
+	})
 	return nil
 }
 
-func (s *FleetService) ListFleets(req *model.ListFleetRequest) ([]model.FleetListItem, error) {
-	items, err := s.repo.ListFleets(req)
+func (s *FleetService) ListFleets(ctx context.Context, req *model.ListFleetRequest) ([]model.FleetListItem, error) {
+	items, err := s.repo.ListFleets(ctx, req)
 	if err != nil {
 		msg := "failed to list fleets"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -953,15 +1195,16 @@ func (s *FleetService) ListFleets(req *model.ListFleetRequest) ([]model.FleetLis
 	return items, nil
 }
 
-func (s *FleetService) ListFleetsForUnit(orgID, searchFor string) ([]model.FleetUnitSearchItem, error) {
-	items, err := s.repo.ListFleetsForUnit(orgID, searchFor)
+func (s *FleetService) ListFleetsForUnit(ctx context.Context, searchFor string) ([]model.FleetUnitSearchItem, error) {
+	items, err := s.repo.ListFleetsForUnit(ctx, searchFor)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to list fleets")
 	}
 	return items, nil
 }
 
-func (s *FleetService) GetFleetAvailibility(orgID string, startDate time.Time, endDate time.Time, fleetID string) (bool, []repository.FleetAvailibilityItem, error) {
+func (s *FleetService) GetFleetAvailibility(ctx context.Context, startDate time.Time, endDate time.Time, fleetID string) (bool, []repository.FleetAvailibilityItem, error) {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return false, nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
@@ -969,7 +1212,7 @@ func (s *FleetService) GetFleetAvailibility(orgID string, startDate time.Time, e
 		return false, nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid start_date or end_date")
 	}
 
-	items, err := s.repo.GetFleetAvailibility(orgID, startDate, endDate, fleetID)
+	items, err := s.repo.GetFleetAvailibility(ctx, startDate, endDate, fleetID)
 	if err != nil {
 		msg := "failed to get fleet availibility"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -988,20 +1231,26 @@ func (s *FleetService) GetFleetAvailibility(orgID string, startDate time.Time, e
 	return available, items, nil
 }
 
-func (s *FleetService) DeleteFleet(orgID, userID, fleetID string) error {
+func (s *FleetService) DeleteFleet(ctx context.Context, userID, fleetID string) error {
 	if strings.TrimSpace(fleetID) == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleet_id is required")
 	}
-	if err := s.repo.SoftDeleteFleet(orgID, userID, fleetID); err != nil {
+	before := s.fleetAuditSnapshot(ctx, fleetID)
+	if err := s.repo.SoftDeleteFleet(ctx, userID, fleetID); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "fleet not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete fleet")
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityFleet, EntityID: fleetID, Action: model.AuditActionDelete,
+		Before: before,
+	})
 	return nil
 }
 
-func (s *FleetService) SetFleetActiveStatus(orgID, userID, action, fleetID string) error {
+func (s *FleetService) SetFleetActiveStatus(ctx context.Context, userID, action, fleetID string) error {
 	fleetID = strings.TrimSpace(fleetID)
 	if fleetID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "fleet_id is required")
@@ -1016,12 +1265,17 @@ func (s *FleetService) SetFleetActiveStatus(orgID, userID, action, fleetID strin
 	default:
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "action must be active or inactive")
 	}
-	if err := s.repo.SetFleetActiveStatus(orgID, userID, fleetID, active); err != nil {
+	if err := s.repo.SetFleetActiveStatus(ctx, userID, fleetID, active); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "fleet not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update fleet status")
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityFleet, EntityID: fleetID, Action: model.AuditActionUpdate,
+		Before: map[string]interface{}{"active": !active}, After: map[string]interface{}{"active": active},
+	})
 	return nil
 }
 
@@ -1045,28 +1299,28 @@ func normalizeDateTime(v string) (string, error) {
 	return "", fmt.Errorf("invalid datetime")
 }
 
-func (s *FleetService) GetFleetDetail(orgID, fleetID string) (*model.FleetDetailResponse, error) {
-	meta, err := s.repo.GetFleetDetailMeta(orgID, fleetID)
+func (s *FleetService) GetFleetDetail(ctx context.Context, fleetID string) (*model.FleetDetailResponse, error) {
+	meta, err := s.repo.GetFleetDetailMeta(ctx, fleetID)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "fleet not found")
 	}
-	fac, err := s.repo.GetFleetFacilities(fleetID)
+	fac, err := s.repo.GetFleetFacilities(ctx, fleetID)
 	if err != nil {
 		fac = []model.FacilityItem{}
 	}
-	pickup, err := s.repo.GetFleetPickup(orgID, fleetID)
+	pickup, err := s.repo.GetFleetPickup(ctx, fleetID)
 	if err != nil {
 		pickup = []model.FleetPickupItem{}
 	}
-	addon, err := s.repo.GetFleetAddon(orgID, fleetID)
+	addon, err := s.repo.GetFleetAddon(ctx, fleetID)
 	if err != nil {
 		addon = []model.FleetAddonItem{}
 	}
-	prices, err := s.repo.GetFleetPrices(orgID, fleetID)
+	prices, err := s.repo.GetFleetPrices(ctx, fleetID)
 	if err != nil {
 		prices = []model.FleetPriceItem{}
 	}
-	images, err := s.repo.GetFleetImages(fleetID)
+	images, err := s.repo.GetFleetImages(ctx, fleetID)
 	if err != nil {
 		images = []model.FleetImageItem{}
 	}
@@ -1097,8 +1351,8 @@ func (s *FleetService) GetFleetDetail(orgID, fleetID string) (*model.FleetDetail
 	return resp, nil
 }
 
-func (s *FleetService) GetServiceFleetAddons(orgID, fleetID string) ([]model.ServiceFleetAddonItem, error) {
-	addons, err := s.repo.GetFleetAddon(orgID, fleetID)
+func (s *FleetService) GetServiceFleetAddons(ctx context.Context, fleetID string) ([]model.ServiceFleetAddonItem, error) {
+	addons, err := s.repo.GetFleetAddon(ctx, fleetID)
 	if err != nil {
 		return nil, err
 	}
@@ -1228,14 +1482,21 @@ func calculateDurationString(startDateStr, endDateStr string) string {
 	}
 }
 
-func (s *FleetService) ProcessFleetOrder(orgID, userID, orderID string, processTypeId int) error {
-	if err := s.repo.ProcessFleetOrder(orgID, userID, orderID, processTypeId); err != nil {
+func (s *FleetService) ProcessFleetOrder(ctx context.Context, userID, orderID string, processTypeId int) error {
+	before := s.orderAuditSnapshot(ctx, orderID)
+	if err := s.repo.ProcessFleetOrder(ctx, userID, orderID, processTypeId); err != nil {
 		return err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrder, EntityID: orderID, Action: model.AuditActionProcess,
+		Before: before, After: s.orderAuditSnapshot(ctx, orderID),
+	})
 	return nil
 }
 
-func (s *FleetService) SubmitOrderReview(orgID, orderID string, star int, review string) error {
+func (s *FleetService) SubmitOrderReview(ctx context.Context, orderID string, star int, review string) error {
+	orgID := organizationFromContext(ctx)
 	if orgID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization_id is required")
 	}
@@ -1249,7 +1510,7 @@ func (s *FleetService) SubmitOrderReview(orgID, orderID string, star int, review
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "review is required")
 	}
 
-	customerID, orderType, err := s.repo.GetCustomerOrderMeta(orderID, orgID)
+	customerID, orderType, err := s.repo.GetCustomerOrderMeta(ctx, orderID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
@@ -1262,54 +1523,55 @@ func (s *FleetService) SubmitOrderReview(orgID, orderID string, star int, review
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate review_id")
 	}
 
-	if err := s.repo.InsertOrderReview(reviewID.String(), orderID, star, review, orgID, customerID, orderType, time.Now()); err != nil {
+	if err := s.repo.InsertOrderReview(ctx, reviewID.String(), orderID, star, review, customerID, orderType, time.Now()); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to submit review")
 	}
 	return nil
 }
 
-func (s *FleetService) GetOrderReviews(orderID, orgID string) ([]model.OrderReviewItem, error) {
-	items, err := s.repo.GetOrderReviews(orderID, orgID)
+func (s *FleetService) GetOrderReviews(ctx context.Context, orderID string) ([]model.OrderReviewItem, error) {
+	items, err := s.repo.GetOrderReviews(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load reviews")
 	}
 	return items, nil
 }
 
-func (s *FleetService) GetOrderRatingSummary(orderID, orgID string) (*model.OrderRatingSummary, error) {
-	rating, err := s.repo.GetOrderRatingSummary(orderID, orgID)
+func (s *FleetService) GetOrderRatingSummary(ctx context.Context, orderID string) (*model.OrderRatingSummary, error) {
+	rating, err := s.repo.GetOrderRatingSummary(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load rating")
 	}
 	return rating, nil
 }
 
-func (s *FleetService) GetFleetRatings(orgID string, fleetIDs []string) (map[string]model.FleetRatingSummary, error) {
-	ratings, err := s.repo.GetFleetRatings(orgID, fleetIDs)
+func (s *FleetService) GetFleetRatings(ctx context.Context, fleetIDs []string) (map[string]model.FleetRatingSummary, error) {
+	ratings, err := s.repo.GetFleetRatings(ctx, fleetIDs)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load rating")
 	}
 	return ratings, nil
 }
 
-func (s *FleetService) GetFleetReviews(fleetID, orgID string) ([]model.OrderReviewItem, error) {
-	items, err := s.repo.GetFleetReviews(fleetID, orgID)
+func (s *FleetService) GetFleetReviews(ctx context.Context, fleetID string) ([]model.OrderReviewItem, error) {
+	items, err := s.repo.GetFleetReviews(ctx, fleetID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load reviews")
 	}
 	return items, nil
 }
 
-func (s *FleetService) GetFleetRevenue(orgID, fleetID string, startDate, endDate string) (*model.FleetRevenue, error) {
-	revenue, err := s.repo.GetFleetRevenue(orgID, fleetID, startDate, endDate)
+func (s *FleetService) GetFleetRevenue(ctx context.Context, fleetID string, startDate, endDate string) (*model.FleetRevenue, error) {
+	revenue, err := s.repo.GetFleetRevenue(ctx, fleetID, startDate, endDate)
 	if err != nil {
-		fmt.Println(err)
+		slog.ErrorContext(ctx, "get fleet revenue failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load revenue")
 	}
 	return revenue, nil
 }
 
-func (s *FleetService) GetOrderAvailability(orgID, fleetID string, cityID int, startDateStr, endDateStr string, serviceType *int) (*model.OrderAvailabilityResponse, error) {
+func (s *FleetService) GetOrderAvailability(ctx context.Context, fleetID string, cityID int, startDateStr, endDateStr string, serviceType *int) (*model.OrderAvailabilityResponse, error) {
+	orgID := organizationFromContext(ctx)
 	if orgID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
@@ -1342,7 +1604,7 @@ func (s *FleetService) GetOrderAvailability(orgID, fleetID string, cityID int, s
 	}
 
 	// Call repository
-	repoResult, err := s.repo.GetOrderAvailability(orgID, fleetID, cityID, startDate, endDate, daysCount, serviceType)
+	repoResult, err := s.repo.GetOrderAvailability(ctx, fleetID, cityID, startDate, endDate, daysCount, serviceType)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get availability")
 	}
@@ -1356,7 +1618,7 @@ func (s *FleetService) GetOrderAvailability(orgID, fleetID string, cityID int, s
 	return response, nil
 }
 
-func (s *FleetService) DeleteFleetOrderAddon(orgID string, req *FleetOrderDeleteAddonRequest) error {
+func (s *FleetService) DeleteFleetOrderAddon(ctx context.Context, userID string, req *FleetOrderDeleteAddonRequest) error {
 	if req.OrderID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
@@ -1366,59 +1628,147 @@ func (s *FleetService) DeleteFleetOrderAddon(orgID string, req *FleetOrderDelete
 	if req.AddonID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "addon_id is required")
 	}
-	err := s.repo.DeleteFleetOrderAddon(req.OrderID, req.OrderItemID, req.AddonID, orgID)
+	before := s.orderAuditSnapshot(ctx, req.OrderID)
+	err := s.repo.DeleteFleetOrderAddon(ctx, req.OrderID, req.OrderItemID, req.AddonID)
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete addon: "+err.Error())
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrder, EntityID: req.OrderID, Action: model.AuditActionUpdate,
+		Before: before, After: s.orderAuditSnapshot(ctx, req.OrderID),
+	})
 	return nil
 }
 
-func (s *FleetService) CancelPartnerOrder(orgID string, userID string, req *model.FleetOrderCancelRequest) error {
+func (s *FleetService) CancelPartnerOrder(ctx context.Context, userID string, req *model.FleetOrderCancelRequest) error {
+	if err := s.cancelOrder(ctx, userID, req); err != nil {
+		return err
+	}
+	// A customer request for this order is settled by the staff cancellation
+	if err := s.cancellationService.ApprovePendingRequests(ctx, req.OrderID, userID); err != nil {
+		slog.ErrorContext(ctx, "approve pending requests failed", "error", err)
+	}
+	return nil
+}
+
+// cancelOrder cancels the order and its schedules and refunds what the
+// cancellation policy allows.
+func (s *FleetService) cancelOrder(ctx context.Context, userID string, req *model.FleetOrderCancelRequest) error {
 	if req.OrderID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
-	paidAmount, err := s.repo.GetPaidAmount(req.OrderID, orgID)
+	if s.cancellationService == nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "cancellation policy service is not configured")
+	}
+	// Quote before cancelling, the paid amount only counts active orders
+	quote, err := s.cancellationService.Quote(ctx, req.OrderID, time.Now())
 	if err != nil {
-		fmt.Println("GetPaidAmount err:", err)
-		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get paid amount")
+		return err
 	}
+	before := s.orderAuditSnapshot(ctx, req.OrderID)
 
-	err = s.repo.FleetOrderCancelation(userID, req.OrderID, orgID)
+	err = s.repo.FleetOrderCancelation(ctx, userID, req.OrderID)
 	if err != nil {
-		fmt.Println("FleetOrderCancelation err:", err)
+		slog.ErrorContext(ctx, "fleet order cancelation failed", "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to cancel order: "+err.Error())
 	}
 
-	err = s.repo.CancelSchedulesAndRelated(userID, req.OrderID, orgID)
+	err = s.repo.CancelSchedulesAndRelated(ctx, userID, req.OrderID)
 	if err != nil {
-		fmt.Println("CancelSchedulesAndRelated err:", err)
+		slog.ErrorContext(ctx, "cancel schedules and related failed", "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to cancel schedules: "+err.Error())
 	}
 
-	if paidAmount > 0 {
-		refundAmount := paidAmount * float64(req.RefundPercentage) / 100
-		err = s.repo.RefundOrderTransactions(req.OrderID, refundAmount, req.Reason, strconv.Itoa(req.PaymentMethod), req.BankId, req.BankAccount, req.BankAccountName, orgID, userID)
+	if quote.RefundAmount > 0 {
+		err = s.repo.RefundOrderTransactions(ctx, req.OrderID, quote, req.Reason, strconv.Itoa(req.PaymentMethod), req.BankId, req.BankAccount, req.BankAccountName, userID)
 		if err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to refund order: "+err.Error())
 		}
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrder, EntityID: req.OrderID, Action: model.AuditActionCancel,
+		Before: before, After: s.orderAuditSnapshot(ctx, req.OrderID),
+	})
 	return nil
 }
 
-func (s *FleetService) CancelPartnerOrderDetail(orgID string, userID string, orderID string) (*model.OrderDetailResponse, error) {
+func (s *FleetService) CancelPartnerOrderDetail(ctx context.Context, userID string, orderID string) (*model.OrderDetailResponse, error) {
 	if strings.TrimSpace(orderID) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
-	res, err := s.GetPartnerOrderDetail(orderID, orgID)
+	res, err := s.GetPartnerOrderDetail(ctx, orderID)
 	if err != nil {
 		return nil, err
 	}
+	if s.cancellationService != nil {
+		quote, err := s.cancellationService.Quote(ctx, orderID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		res.CancellationRefund = quote
+		if pending, err := s.cancellationService.GetPendingRequest(ctx, orderID); err == nil {
+			res.CancellationReq = pending
+		}
+	}
 	return res, nil
 }
 
-func (s *FleetService) GetFacilityList(orgID string) ([]model.FacilityItem, error) {
-	items, err := s.repo.GetFacilityList(orgID)
+func (s *FleetService) GetCancellationRequests(ctx context.Context, status int) ([]model.OrderCancellationRequest, error) {
+	if s.cancellationService == nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "cancellation policy service is not configured")
+	}
+	return s.cancellationService.ListRequests(ctx, status)
+}
+
+// ApproveCancellationRequest cancels the order of a customer request with the
+// refund account the customer gave, then closes the request.
+func (s *FleetService) ApproveCancellationRequest(ctx context.Context, userID string, req *model.OrderCancellationReviewRequest) error {
+	if s.cancellationService == nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "cancellation policy service is not configured")
+	}
+	item, err := s.cancellationService.GetOpenRequest(ctx, req.RequestID)
+	if err != nil {
+		return err
+	}
+	if err := s.cancelOrder(ctx, userID, &model.FleetOrderCancelRequest{
+		OrderID:         item.OrderID,
+		PaymentMethod:   item.PaymentMethod,
+		Reason:          item.Reason,
+		BankId:          item.BankCode,
+		BankAccount:     item.BankAccount,
+		BankAccountName: item.BankAccountName,
+	}); err != nil {
+		return err
+	}
+	return s.cancellationService.MarkApproved(ctx, userID, item.RequestID, req.Note)
+}
+
+func (s *FleetService) RejectCancellationRequest(ctx context.Context, userID string, req *model.OrderCancellationReviewRequest) error {
+	if s.cancellationService == nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "cancellation policy service is not configured")
+	}
+	var item *model.OrderCancellationRequest
+	if s.audit != nil {
+		item, _ = s.cancellationService.GetOpenRequest(ctx, req.RequestID)
+	}
+	if err := s.cancellationService.Reject(ctx, userID, req); err != nil {
+		return err
+	}
+	if item != nil {
+		s.audit.Record(ctx, AuditEntry{
+			ActorID:    userID,
+			EntityType: model.AuditEntityOrder, EntityID: item.OrderID, Action: model.AuditActionReject,
+			After: map[string]interface{}{"cancellation_request_id": req.RequestID, "reason": item.Reason, "note": req.Note},
+		})
+	}
+	return nil
+}
+
+func (s *FleetService) GetFacilityList(ctx context.Context) ([]model.FacilityItem, error) {
+	items, err := s.repo.GetFacilityList(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get facility list")
 	}
@@ -1,6 +1,9 @@
 package service
 
-import "service-travego/repository"
+import (
+	"context"
+	"service-travego/repository"
+)
 
 type FleetTypeService struct {
 	repo *repository.FleetTypeRepository
@@ -10,6 +13,6 @@ func NewFleetTypeService(repo *repository.FleetTypeRepository) *FleetTypeService
 	return &FleetTypeService{repo: repo}
 }
 
-func (s *FleetTypeService) GetAllFleetTypes() ([]map[string]interface{}, error) {
-	return s.repo.FindAll()
+func (s *FleetTypeService) GetAllFleetTypes(ctx context.Context) ([]map[string]interface{}, error) {
+	return s.repo.FindAll(ctx)
 }
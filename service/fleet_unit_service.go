@@ -1,9 +1,11 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"service-travego/model"
@@ -25,12 +27,18 @@ type FleetUnitService struct {
 	paymentStatusLabels       map[int]string
 	transactionCategoryLabels map[string]string
 	transactionItemLabels     map[string]string
+	entitlements              *EntitlementService
 }
 
 func NewFleetUnitService(repo *repository.FleetUnitRepository, partnerRepo *repository.PartnerRepository, orgRepo *repository.OrganizationRepository) *FleetUnitService {
 	return &FleetUnitService{repo: repo, partnerRepo: partnerRepo, orgRepo: orgRepo}
 }
 
+// SetEntitlementService limits new fleet units to the organization's package
+func (s *FleetUnitService) SetEntitlementService(entitlements *EntitlementService) {
+	s.entitlements = entitlements
+}
+
 func (s *FleetUnitService) ensureCitiesLoaded() {
 	if s.citiesName != nil {
 		return
@@ -128,8 +136,8 @@ func (s *FleetUnitService) ensureCommonLoaded() {
 	})
 }
 
-func (s *FleetUnitService) List(orgID, fleetId, orderID, search string) ([]model.FleetUnitListItem, error) {
-	items, err := s.repo.List(orgID, fleetId, orderID, search)
+func (s *FleetUnitService) List(ctx context.Context, fleetId, orderID, search string) ([]model.FleetUnitListItem, error) {
+	items, err := s.repo.List(ctx, fleetId, orderID, search)
 	if err != nil {
 		msg := "failed to get fleet units"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -140,13 +148,54 @@ func (s *FleetUnitService) List(orgID, fleetId, orderID, search string) ([]model
 	return items, nil
 }
 
-func (s *FleetUnitService) Create(orgID, userID string, req *model.FleetUnitCreateRequest) (string, error) {
+func (s *FleetUnitService) Create(ctx context.Context, userID string, req *model.FleetUnitCreateRequest) (string, error) {
+	newPartners, err := s.countNewPartners(ctx, []*model.FleetUnitCreateRequest{req})
+	if err != nil {
+		return "", err
+	}
+	var id string
+	err = s.entitlements.Reserve(ctx, map[string]int{model.QuotaFleetUnits: 1, model.QuotaPartners: newPartners}, func() (err error) {
+		id, err = s.create(ctx, userID, req)
+		return err
+	})
+	return id, err
+}
+
+// countNewPartners returns how many partners creating the units adds, i.e.
+// the distinct partners given by name and phone that do not exist yet
+func (s *FleetUnitService) countNewPartners(ctx context.Context, reqs []*model.FleetUnitCreateRequest) (int, error) {
+	seen := map[[2]string]struct{}{}
+	count := 0
+	for _, req := range reqs {
+		if req.PartnerID != nil || req.PartnerName == nil || req.PartnerPhone == nil {
+			continue
+		}
+		key := [2]string{*req.PartnerName, *req.PartnerPhone}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		partnerID, err := s.partnerRepo.FindIDByNamePhone(ctx, *req.PartnerName, *req.PartnerPhone)
+		if err != nil {
+			return 0, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to handle partner")
+		}
+		if partnerID == "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// create inserts the unit and the partner it names. Callers reserve the
+// quotas of both.
+func (s *FleetUnitService) create(ctx context.Context, userID string, req *model.FleetUnitCreateRequest) (string, error) {
+	orgID := organizationFromContext(ctx)
 	req.OrganizationID = orgID
 	req.CreatedBy = userID
 
 	var partnerID *string
 	if req.PartnerID == nil && req.PartnerName != nil && req.PartnerPhone != nil {
-		partnerIDStr, err := s.partnerRepo.GetOrCreateByNamePhone(orgID, userID, *req.PartnerName, *req.PartnerPhone, req.PartnerEmail)
+		partnerIDStr, err := s.partnerRepo.GetOrCreateByNamePhone(ctx, userID, *req.PartnerName, *req.PartnerPhone, req.PartnerEmail)
 		if err != nil {
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to handle partner")
 		}
@@ -158,7 +207,7 @@ func (s *FleetUnitService) Create(orgID, userID string, req *model.FleetUnitCrea
 	vehicleID := strings.ToUpper(strings.TrimSpace(req.VehicleID))
 	plateNumber := strings.ToUpper(strings.TrimSpace(req.PlateNumber))
 	if vehicleID != "" {
-		existing, err := s.repo.FindExistingVehicleIDs(orgID, []string{vehicleID})
+		existing, err := s.repo.FindExistingVehicleIDs(ctx, []string{vehicleID})
 		if err != nil {
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create fleet unit")
 		}
@@ -167,7 +216,7 @@ func (s *FleetUnitService) Create(orgID, userID string, req *model.FleetUnitCrea
 		}
 	}
 	if plateNumber != "" {
-		existing, err := s.repo.FindExistingPlateNumbers(orgID, []string{plateNumber})
+		existing, err := s.repo.FindExistingPlateNumbers(ctx, []string{plateNumber})
 		if err != nil {
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create fleet unit")
 		}
@@ -176,7 +225,7 @@ func (s *FleetUnitService) Create(orgID, userID string, req *model.FleetUnitCrea
 		}
 	}
 
-	id, err := s.repo.Create(req)
+	id, err := s.repo.Create(ctx, req)
 	if err != nil {
 		msg := "failed to create fleet unit"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -186,7 +235,7 @@ func (s *FleetUnitService) Create(orgID, userID string, req *model.FleetUnitCrea
 	}
 
 	if req.OwnershipType != nil && *req.OwnershipType == 1 && partnerID != nil {
-		if err := s.repo.SetUnitOwnership(id, *partnerID, orgID, userID); err != nil {
+		if err := s.repo.SetUnitOwnership(ctx, id, *partnerID, userID); err != nil {
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to set unit ownership")
 		}
 	}
@@ -194,7 +243,7 @@ func (s *FleetUnitService) Create(orgID, userID string, req *model.FleetUnitCrea
 	return id, nil
 }
 
-func (s *FleetUnitService) CreateBatch(orgID, userID, fleetID string, units []model.FleetUnitCreateUnit) ([]string, error) {
+func (s *FleetUnitService) CreateBatch(ctx context.Context, userID, fleetID string, units []model.FleetUnitCreateUnit) ([]string, error) {
 	seenVehicle := map[string]struct{}{}
 	seenPlate := map[string]struct{}{}
 
@@ -220,7 +269,7 @@ func (s *FleetUnitService) CreateBatch(orgID, userID, fleetID string, units []mo
 		}
 	}
 
-	existingVehicles, err := s.repo.FindExistingVehicleIDs(orgID, vehicleIDs)
+	existingVehicles, err := s.repo.FindExistingVehicleIDs(ctx, vehicleIDs)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create fleet unit")
 	}
@@ -228,7 +277,7 @@ func (s *FleetUnitService) CreateBatch(orgID, userID, fleetID string, units []mo
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "DUPLICATE_VEHICLE_ID")
 	}
 
-	existingPlates, err := s.repo.FindExistingPlateNumbers(orgID, plateNumbers)
+	existingPlates, err := s.repo.FindExistingPlateNumbers(ctx, plateNumbers)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create fleet unit")
 	}
@@ -236,9 +285,9 @@ func (s *FleetUnitService) CreateBatch(orgID, userID, fleetID string, units []mo
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "DUPLICATE_PLATE_NUMBER")
 	}
 
-	ids := make([]string, 0, len(units))
+	reqs := make([]*model.FleetUnitCreateRequest, 0, len(units))
 	for _, u := range units {
-		req := &model.FleetUnitCreateRequest{
+		reqs = append(reqs, &model.FleetUnitCreateRequest{
 			VehicleID:      u.VehicleID,
 			PlateNumber:    u.PlateNumber,
 			FleetID:        fleetID,
@@ -251,45 +300,74 @@ func (s *FleetUnitService) CreateBatch(orgID, userID, fleetID string, units []mo
 			PartnerName:    u.PartnerName,
 			PartnerPhone:   u.PartnerPhone,
 			PartnerEmail:   u.PartnerEmail,
+		})
+	}
+	newPartners, err := s.countNewPartners(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(units))
+	err = s.entitlements.Reserve(ctx, map[string]int{model.QuotaFleetUnits: len(units), model.QuotaPartners: newPartners}, func() error {
+		for _, req := range reqs {
+			id, err := s.create(ctx, userID, req)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
 		}
-		id, err := s.Create(orgID, userID, req)
-		if err != nil {
-			return nil, err
-		}
-		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return ids, nil
 }
 
-func (s *FleetUnitService) Update(orgID, userID string, req *model.FleetUnitUpdateRequest) error {
+func (s *FleetUnitService) Update(ctx context.Context, userID string, req *model.FleetUnitUpdateRequest) error {
+	orgID := organizationFromContext(ctx)
 	req.OrganizationID = orgID
 	req.UpdatedBy = userID
 
 	var partnerID *string
 	if req.PartnerID == nil && req.PartnerName != nil && req.PartnerPhone != nil {
-		partnerIDStr, err := s.partnerRepo.GetOrCreateByNamePhone(orgID, userID, *req.PartnerName, *req.PartnerPhone, req.PartnerPic)
+		existing, err := s.partnerRepo.FindIDByNamePhone(ctx, *req.PartnerName, *req.PartnerPhone)
 		if err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to handle partner")
 		}
-		partnerID = &partnerIDStr
+		newPartners := 0
+		if existing == "" {
+			newPartners = 1
+		}
+		err = s.entitlements.Reserve(ctx, map[string]int{model.QuotaPartners: newPartners}, func() error {
+			partnerIDStr, err := s.partnerRepo.GetOrCreateByNamePhone(ctx, userID, *req.PartnerName, *req.PartnerPhone, req.PartnerPic)
+			if err != nil {
+				return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to handle partner")
+			}
+			partnerID = &partnerIDStr
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	} else if req.PartnerID != nil {
 		partnerID = req.PartnerID
 	}
 
 	if req.OwnershipType != nil && *req.OwnershipType == 0 {
-		if err := s.repo.DeleteUnitOwnership(orgID, req.UnitID); err != nil {
+		if err := s.repo.DeleteUnitOwnership(ctx, req.UnitID); err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete unit ownership")
 		}
 	}
 
 	if req.PartnerID != nil && req.PartnerName != nil && req.PartnerPhone != nil {
-		fmt.Println("---- masuk kondisi ini")
-		if errUpdatePartner := s.repo.UpdateOwnerInformation(orgID, req.UnitID, *partnerID, *req.PartnerName, *req.PartnerPhone, *req.PartnerPic); errUpdatePartner != nil {
+		slog.DebugContext(ctx, "update unit owner information", "unit_id", req.UnitID, "partner_id", *partnerID)
+		if errUpdatePartner := s.repo.UpdateOwnerInformation(ctx, req.UnitID, *partnerID, *req.PartnerName, *req.PartnerPhone, *req.PartnerPic); errUpdatePartner != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update partner information")
 		}
 	}
 
-	if err := s.repo.Update(req); err != nil {
+	if err := s.repo.Update(ctx, req); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "fleet unit not found")
 		}
@@ -297,7 +375,7 @@ func (s *FleetUnitService) Update(orgID, userID string, req *model.FleetUnitUpda
 	}
 
 	if req.OwnershipType != nil && *req.OwnershipType == 1 && partnerID != nil {
-		if err := s.repo.SetUnitOwnership(req.UnitID, *partnerID, orgID, userID); err != nil {
+		if err := s.repo.SetUnitOwnership(ctx, req.UnitID, *partnerID, userID); err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to set unit ownership")
 		}
 	}
@@ -305,8 +383,8 @@ func (s *FleetUnitService) Update(orgID, userID string, req *model.FleetUnitUpda
 	return nil
 }
 
-func (s *FleetUnitService) Detail(orgID, uuid string) (*model.FleetUnitDetailResponse, error) {
-	res, err := s.repo.Detail(orgID, uuid)
+func (s *FleetUnitService) Detail(ctx context.Context, uuid string) (*model.FleetUnitDetailResponse, error) {
+	res, err := s.repo.Detail(ctx, uuid)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "fleet unit not found")
@@ -321,7 +399,7 @@ func (s *FleetUnitService) Detail(orgID, uuid string) (*model.FleetUnitDetailRes
 	}
 
 	if strings.TrimSpace(res.FleetID) != "" {
-		cityIDs, err := s.repo.GetFleetPickupCityIDs(orgID, res.FleetID)
+		cityIDs, err := s.repo.GetFleetPickupCityIDs(ctx, res.FleetID)
 		if err == nil && len(cityIDs) > 0 {
 			s.ensureCitiesLoaded()
 			out := make([]string, 0, len(cityIDs))
@@ -344,12 +422,12 @@ func (s *FleetUnitService) Detail(orgID, uuid string) (*model.FleetUnitDetailRes
 
 	if res.OwnershipType != nil {
 		if *res.OwnershipType == 1 {
-			ownershipInfo, err := s.repo.GetOwnershipInformation(orgID, uuid)
+			ownershipInfo, err := s.repo.GetOwnershipInformation(ctx, uuid)
 			if err == nil && ownershipInfo != nil {
 				res.OwnershipInformation = ownershipInfo
 			}
 		} else if *res.OwnershipType == 2 {
-			org, err := s.orgRepo.FindByID(orgID)
+			org, err := s.orgRepo.FindCurrent(ctx)
 			if err == nil && org != nil {
 				res.OwnershipInformation = &model.FleetUnitOwnershipInformation{
 					PartnerName: org.OrganizationName,
@@ -361,8 +439,8 @@ func (s *FleetUnitService) Detail(orgID, uuid string) (*model.FleetUnitDetailRes
 	return res, nil
 }
 
-func (s *FleetUnitService) UnitOrderHistory(orgID, unitID, startDate, endDate string) ([]model.FleetUnitOrderHistoryItem, error) {
-	items, err := s.repo.UnitOrderHistory(orgID, unitID, startDate, endDate)
+func (s *FleetUnitService) UnitOrderHistory(ctx context.Context, unitID, startDate, endDate string) ([]model.FleetUnitOrderHistoryItem, error) {
+	items, err := s.repo.UnitOrderHistory(ctx, unitID, startDate, endDate)
 	if err != nil {
 		msg := "failed to get fleet unit order history"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -385,7 +463,7 @@ func (s *FleetUnitService) UnitOrderHistory(orgID, unitID, startDate, endDate st
 		}
 	}
 
-	destCityIDs, err := s.repo.GetOrderDestinationCityIDs(orderIDs)
+	destCityIDs, err := s.repo.GetOrderDestinationCityIDs(ctx, orderIDs)
 	if err != nil {
 		msg := "failed to get fleet unit order history"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -439,10 +517,10 @@ func (s *FleetUnitService) UnitOrderHistory(orgID, unitID, startDate, endDate st
 	return items, nil
 }
 
-func (s *FleetUnitService) GetUnitRevenue(orgID, unitID, startDate, endDate string) (*model.FleetUnitRevenue, error) {
-	revenue, err := s.repo.GetUnitRevenue(orgID, unitID, startDate, endDate)
+func (s *FleetUnitService) GetUnitRevenue(ctx context.Context, unitID, startDate, endDate string) (*model.FleetUnitRevenue, error) {
+	revenue, err := s.repo.GetUnitRevenue(ctx, unitID, startDate, endDate)
 	if err != nil {
-		fmt.Println(err)
+		slog.ErrorContext(ctx, "get unit revenue failed", "error", err)
 		return &model.FleetUnitRevenue{TotalRevenue: 0, TotalBooking: 0}, nil
 	}
 	if revenue == nil {
@@ -451,10 +529,10 @@ func (s *FleetUnitService) GetUnitRevenue(orgID, unitID, startDate, endDate stri
 	return revenue, nil
 }
 
-func (s *FleetUnitService) GetUnitRevenueHistory(orgID, unitID, startDate, endDate string) ([]model.FleetUnitRevenueHistoryItem, error) {
-	rows, err := s.repo.ListUnitRevenueHistory(orgID, strings.TrimSpace(unitID), startDate, endDate)
+func (s *FleetUnitService) GetUnitRevenueHistory(ctx context.Context, unitID, startDate, endDate string) ([]model.FleetUnitRevenueHistoryItem, error) {
+	rows, err := s.repo.ListUnitRevenueHistory(ctx, strings.TrimSpace(unitID), startDate, endDate)
 	if err != nil {
-		fmt.Println(err)
+		slog.ErrorContext(ctx, "get unit revenue history failed", "error", err)
 		return []model.FleetUnitRevenueHistoryItem{}, nil
 	}
 
@@ -477,7 +555,7 @@ func (s *FleetUnitService) GetUnitRevenueHistory(orgID, unitID, startDate, endDa
 	return rows, nil
 }
 
-func (s *FleetUnitService) UnitExpenses(orgID, unitID, period string) ([]model.FleetUnitExpenseItem, error) {
+func (s *FleetUnitService) UnitExpenses(ctx context.Context, unitID, period string) ([]model.FleetUnitExpenseItem, error) {
 	t, err := time.Parse("2006-01", strings.TrimSpace(period))
 	if err != nil {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "Invalid period format. Use YYYY-MM")
@@ -486,7 +564,7 @@ func (s *FleetUnitService) UnitExpenses(orgID, unitID, period string) ([]model.F
 	startDate := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, 0)
 
-	rows, err := s.repo.ListUnitExpenses(orgID, strings.TrimSpace(unitID), startDate, endDate)
+	rows, err := s.repo.ListUnitExpenses(ctx, strings.TrimSpace(unitID), startDate, endDate)
 	if err != nil {
 		msg := "failed to get fleet unit expenses"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -528,8 +606,8 @@ func (s *FleetUnitService) UnitExpenses(orgID, unitID, period string) ([]model.F
 	return rows, nil
 }
 
-func (s *FleetUnitService) UnitRating(orgID, unitID string) (float64, error) {
-	rating, err := s.repo.UnitRating(orgID, unitID)
+func (s *FleetUnitService) UnitRating(ctx context.Context, unitID string) (float64, error) {
+	rating, err := s.repo.UnitRating(ctx, unitID)
 	if err != nil {
 		msg := "failed to get fleet unit rating"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -540,8 +618,8 @@ func (s *FleetUnitService) UnitRating(orgID, unitID string) (float64, error) {
 	return rating, nil
 }
 
-func (s *FleetUnitService) UnitReviews(orgID, unitID string) ([]model.OrderReviewItem, error) {
-	items, err := s.repo.UnitReviews(orgID, unitID)
+func (s *FleetUnitService) UnitReviews(ctx context.Context, unitID string) ([]model.OrderReviewItem, error) {
+	items, err := s.repo.UnitReviews(ctx, unitID)
 	if err != nil {
 		msg := "failed to get fleet unit reviews"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -552,8 +630,8 @@ func (s *FleetUnitService) UnitReviews(orgID, unitID string) ([]model.OrderRevie
 	return items, nil
 }
 
-func (s *FleetUnitService) UnitScheduleStats(orgID, unitID string) (int64, *model.FleetUnitScheduleRange, *model.FleetUnitScheduleRange, error) {
-	total, err := s.repo.UnitTotalSchedules(orgID, unitID)
+func (s *FleetUnitService) UnitScheduleStats(ctx context.Context, unitID string) (int64, *model.FleetUnitScheduleRange, *model.FleetUnitScheduleRange, error) {
+	total, err := s.repo.UnitTotalSchedules(ctx, unitID)
 	if err != nil {
 		msg := "failed to get fleet unit schedules"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -563,7 +641,7 @@ func (s *FleetUnitService) UnitScheduleStats(orgID, unitID string) (int64, *mode
 	}
 
 	now := time.Now()
-	latest, err := s.repo.UnitLatestSchedule(orgID, unitID, now)
+	latest, err := s.repo.UnitLatestSchedule(ctx, unitID, now)
 	if err != nil {
 		msg := "failed to get fleet unit schedules"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -571,7 +649,7 @@ func (s *FleetUnitService) UnitScheduleStats(orgID, unitID string) (int64, *mode
 		}
 		return 0, nil, nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, msg)
 	}
-	upcoming, err := s.repo.UnitUpcomingSchedule(orgID, unitID, now)
+	upcoming, err := s.repo.UnitUpcomingSchedule(ctx, unitID, now)
 	if err != nil {
 		msg := "failed to get fleet unit schedules"
 		if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
@@ -582,3 +660,388 @@ func (s *FleetUnitService) UnitScheduleStats(orgID, unitID string) (int64, *mode
 
 	return total, latest, upcoming, nil
 }
+
+func parseMaintenanceDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// computeServicePlanNextDue returns the next due odometer and date for a plan.
+// A zero interval disables that trigger.
+func computeServicePlanNextDue(intervalKm int64, intervalDays int, lastServiceKm int64, lastServiceAt time.Time) (int64, *time.Time) {
+	var nextDueKm int64
+	if intervalKm > 0 {
+		nextDueKm = lastServiceKm + intervalKm
+	}
+	if intervalDays > 0 && !lastServiceAt.IsZero() {
+		t := lastServiceAt.AddDate(0, 0, intervalDays)
+		return nextDueKm, &t
+	}
+	return nextDueKm, nil
+}
+
+// servicePlanIsDue marks a plan as due when the odometer is within 500 km of the
+// next due reading or the next due date falls within the coming 14 days.
+func servicePlanIsDue(plan model.FleetUnitServicePlan, now time.Time) bool {
+	if plan.NextDueKm > 0 && plan.CurrentKm >= plan.NextDueKm-500 {
+		return true
+	}
+	if plan.NextDueDate != "" {
+		if t, err := time.Parse("2006-01-02", plan.NextDueDate); err == nil && !t.After(now.AddDate(0, 0, 14)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *FleetUnitService) ensureUnitExists(ctx context.Context, unitID string) error {
+	exists, err := s.repo.UnitExists(ctx, unitID)
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get fleet unit")
+	}
+	if !exists {
+		return NewServiceError(ErrNotFound, http.StatusNotFound, "fleet unit not found")
+	}
+	return nil
+}
+
+func (s *FleetUnitService) RecordOdometer(ctx context.Context, userID string, req *model.FleetUnitOdometerRequest) (string, error) {
+	if err := s.ensureUnitExists(ctx, req.UnitID); err != nil {
+		return "", err
+	}
+	if req.OdometerKm <= 0 {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "odometer_km must be greater than 0")
+	}
+
+	recordedAt := time.Now()
+	if strings.TrimSpace(req.RecordedAt) != "" {
+		t, err := parseMaintenanceDate(req.RecordedAt)
+		if err != nil {
+			return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "recorded_at must be YYYY-MM-DD")
+		}
+		recordedAt = t
+	}
+
+	latest, err := s.repo.LatestOdometer(ctx, req.UnitID)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to record odometer")
+	}
+	if req.OdometerKm < latest {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ODOMETER_LOWER_THAN_LATEST")
+	}
+
+	id, err := s.repo.CreateOdometerReading(ctx, userID, req.UnitID, req.OdometerKm, recordedAt, strings.TrimSpace(req.Notes))
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to record odometer")
+	}
+	return id, nil
+}
+
+func (s *FleetUnitService) ListOdometer(ctx context.Context, unitID string) ([]model.FleetUnitOdometerReading, error) {
+	items, err := s.repo.ListOdometerReadings(ctx, unitID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get odometer readings")
+	}
+	return items, nil
+}
+
+func (s *FleetUnitService) prepareServicePlan(ctx context.Context, userID string, req *model.FleetUnitServicePlanRequest) (int64, *time.Time, error) {
+	orgID := organizationFromContext(ctx)
+	req.OrganizationID = orgID
+	req.UserID = userID
+	req.ServiceType = strings.ToUpper(strings.TrimSpace(req.ServiceType))
+	if _, ok := model.FleetUnitServiceTypeLabel[req.ServiceType]; !ok {
+		return 0, nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid service_type")
+	}
+	if req.IntervalKm <= 0 && req.IntervalDays <= 0 {
+		return 0, nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "interval_km or interval_days is required")
+	}
+	if req.IntervalKm < 0 || req.IntervalDays < 0 {
+		return 0, nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "interval must not be negative")
+	}
+	if err := s.ensureUnitExists(ctx, req.UnitID); err != nil {
+		return 0, nil, err
+	}
+
+	req.LastServiceAt = time.Time{}
+	if strings.TrimSpace(req.LastServiceDate) != "" {
+		t, err := parseMaintenanceDate(req.LastServiceDate)
+		if err != nil {
+			return 0, nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "last_service_date must be YYYY-MM-DD")
+		}
+		req.LastServiceAt = t
+	} else if req.IntervalDays > 0 {
+		req.LastServiceAt = time.Now()
+	}
+
+	nextDueKm, nextDueDate := computeServicePlanNextDue(req.IntervalKm, req.IntervalDays, req.LastServiceKm, req.LastServiceAt)
+	return nextDueKm, nextDueDate, nil
+}
+
+func (s *FleetUnitService) CreateServicePlan(ctx context.Context, userID string, req *model.FleetUnitServicePlanRequest) (string, error) {
+	nextDueKm, nextDueDate, err := s.prepareServicePlan(ctx, userID, req)
+	if err != nil {
+		return "", err
+	}
+	id, err := s.repo.CreateServicePlan(ctx, req, nextDueKm, nextDueDate)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create service plan")
+	}
+	return id, nil
+}
+
+func (s *FleetUnitService) UpdateServicePlan(ctx context.Context, userID string, req *model.FleetUnitServicePlanRequest) error {
+	if strings.TrimSpace(req.PlanID) == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "plan_id is required")
+	}
+	nextDueKm, nextDueDate, err := s.prepareServicePlan(ctx, userID, req)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateServicePlan(ctx, req, nextDueKm, nextDueDate); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "service plan not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update service plan")
+	}
+	return nil
+}
+
+func (s *FleetUnitService) DeleteServicePlan(ctx context.Context, userID, planID string) error {
+	if err := s.repo.DeleteServicePlan(ctx, userID, planID); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "service plan not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete service plan")
+	}
+	return nil
+}
+
+// ListServicePlans returns the service plans of a unit (or of the whole organization
+// when unitID is empty). When dueOnly is set only plans that are due are returned.
+func (s *FleetUnitService) ListServicePlans(ctx context.Context, unitID string, dueOnly bool) ([]model.FleetUnitServicePlan, error) {
+	plans, err := s.repo.ListServicePlans(ctx, unitID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.maintenanceMessage("failed to get service plans", err))
+	}
+	now := time.Now()
+	out := make([]model.FleetUnitServicePlan, 0, len(plans))
+	for _, p := range plans {
+		p.ServiceLabel = model.FleetUnitServiceTypeLabel[p.ServiceType]
+		p.IsDue = servicePlanIsDue(p, now)
+		if dueOnly && !p.IsDue {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (s *FleetUnitService) CreateMaintenance(ctx context.Context, userID string, req *model.FleetUnitMaintenanceCreateRequest) (string, error) {
+	orgID := organizationFromContext(ctx)
+	req.OrganizationID = orgID
+	req.UserID = userID
+	req.ServiceType = strings.ToUpper(strings.TrimSpace(req.ServiceType))
+	if _, ok := model.FleetUnitServiceTypeLabel[req.ServiceType]; !ok {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid service_type")
+	}
+	if err := s.ensureUnitExists(ctx, req.UnitID); err != nil {
+		return "", err
+	}
+	if err := s.ensureMaintenanceRefs(ctx, req); err != nil {
+		return "", err
+	}
+
+	startAt, err := parseMaintenanceDate(req.StartDate)
+	if err != nil {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date must be YYYY-MM-DD")
+	}
+	endAt, err := parseMaintenanceDate(req.EndDate)
+	if err != nil {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be YYYY-MM-DD")
+	}
+	if endAt.Before(startAt) {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
+	}
+	req.StartAt = startAt
+	req.EndAt = endAt
+
+	if strings.TrimSpace(req.PlanID) != "" {
+		plan, err := s.repo.GetServicePlan(ctx, strings.TrimSpace(req.PlanID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return "", NewServiceError(ErrNotFound, http.StatusNotFound, "service plan not found")
+			}
+			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get service plan")
+		}
+		if plan.UnitID != req.UnitID {
+			return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "service plan does not belong to unit")
+		}
+	}
+
+	overlap, err := s.repo.HasOverlappingMaintenance(ctx, req.UnitID, startAt, endAt)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.maintenanceMessage("failed to create maintenance", err))
+	}
+	if overlap {
+		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "MAINTENANCE_ALREADY_SCHEDULED")
+	}
+
+	status := model.FleetUnitMaintenanceStatusScheduled
+	if req.InWorkshop {
+		status = model.FleetUnitMaintenanceStatusInWorkshop
+	}
+	id, err := s.repo.CreateMaintenance(ctx, req, status)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.maintenanceMessage("failed to create maintenance", err))
+	}
+	return id, nil
+}
+
+// ensureMaintenanceRefs checks that the garage and the part items of a work
+// order belong to the organization
+func (s *FleetUnitService) ensureMaintenanceRefs(ctx context.Context, req *model.FleetUnitMaintenanceCreateRequest) error {
+	exists, err := s.repo.GarageExists(ctx, req.GarageID)
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get garage")
+	}
+	if !exists {
+		return NewServiceError(ErrNotFound, http.StatusNotFound, "garage not found")
+	}
+
+	seen := make(map[string]bool, len(req.Parts))
+	itemIDs := make([]string, 0, len(req.Parts))
+	for _, part := range req.Parts {
+		if !seen[part.ItemID] {
+			seen[part.ItemID] = true
+			itemIDs = append(itemIDs, part.ItemID)
+		}
+	}
+	found, err := s.repo.CountItems(ctx, itemIDs)
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get inventory items")
+	}
+	if found != len(itemIDs) {
+		return NewServiceError(ErrNotFound, http.StatusNotFound, "item not found")
+	}
+	return nil
+}
+
+func (s *FleetUnitService) MaintenanceDetail(ctx context.Context, maintenanceID string) (*model.FleetUnitMaintenance, error) {
+	m, err := s.repo.GetMaintenance(ctx, maintenanceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "maintenance not found")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.maintenanceMessage("failed to get maintenance", err))
+	}
+	m.ServiceLabel = model.FleetUnitServiceTypeLabel[m.ServiceType]
+	m.StatusLabel = model.FleetUnitMaintenanceStatusLabel[m.Status]
+	return m, nil
+}
+
+func (s *FleetUnitService) MaintenanceHistory(ctx context.Context, unitID string, status *int) ([]model.FleetUnitMaintenance, error) {
+	items, err := s.repo.ListMaintenance(ctx, unitID, status)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.maintenanceMessage("failed to get maintenance history", err))
+	}
+	for i := range items {
+		items[i].ServiceLabel = model.FleetUnitServiceTypeLabel[items[i].ServiceType]
+		items[i].StatusLabel = model.FleetUnitMaintenanceStatusLabel[items[i].Status]
+	}
+	return items, nil
+}
+
+// CheckInMaintenance moves a scheduled work order into the workshop.
+func (s *FleetUnitService) CheckInMaintenance(ctx context.Context, userID, maintenanceID string) error {
+	err := s.repo.UpdateMaintenanceStatus(ctx, userID, maintenanceID, []int{model.FleetUnitMaintenanceStatusScheduled}, model.FleetUnitMaintenanceStatusInWorkshop)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "scheduled maintenance not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update maintenance")
+	}
+	return nil
+}
+
+func (s *FleetUnitService) CancelMaintenance(ctx context.Context, userID, maintenanceID string) error {
+	err := s.repo.UpdateMaintenanceStatus(ctx, userID, maintenanceID, []int{model.FleetUnitMaintenanceStatusScheduled, model.FleetUnitMaintenanceStatusInWorkshop}, model.FleetUnitMaintenanceStatusCancelled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "open maintenance not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to cancel maintenance")
+	}
+	return nil
+}
+
+func (s *FleetUnitService) CompleteMaintenance(ctx context.Context, userID string, req *model.FleetUnitMaintenanceCompleteRequest) error {
+	m, err := s.MaintenanceDetail(ctx, req.MaintenanceID)
+	if err != nil {
+		return err
+	}
+	if m.Status != model.FleetUnitMaintenanceStatusScheduled && m.Status != model.FleetUnitMaintenanceStatusInWorkshop {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "MAINTENANCE_NOT_OPEN")
+	}
+	if req.LaborCost < 0 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "labor_cost must not be negative")
+	}
+
+	completedAt := time.Now()
+	if strings.TrimSpace(req.CompletedDate) != "" {
+		t, err := parseMaintenanceDate(req.CompletedDate)
+		if err != nil {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "completed_date must be YYYY-MM-DD")
+		}
+		completedAt = t
+	}
+
+	odometerKm := req.OdometerKm
+	if odometerKm > 0 {
+		latest, err := s.repo.LatestOdometer(ctx, m.UnitID)
+		if err != nil {
+			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to complete maintenance")
+		}
+		if odometerKm < latest {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ODOMETER_LOWER_THAN_LATEST")
+		}
+	} else {
+		odometerKm = m.OdometerKm
+	}
+
+	var plan *model.FleetUnitServicePlan
+	if m.PlanID != "" {
+		p, err := s.repo.GetServicePlan(ctx, m.PlanID)
+		if err != nil && err != sql.ErrNoRows {
+			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get service plan")
+		}
+		if err == nil {
+			nextDueKm, nextDueDate := computeServicePlanNextDue(p.IntervalKm, p.IntervalDays, odometerKm, completedAt)
+			p.LastServiceKm = odometerKm
+			p.NextDueKm = nextDueKm
+			p.NextDueDate = ""
+			if nextDueDate != nil {
+				p.NextDueDate = nextDueDate.Format("2006-01-02")
+			}
+			plan = p
+		}
+	}
+
+	if err := s.repo.CompleteMaintenance(ctx, userID, m, req, completedAt, plan); err != nil {
+		if strings.HasPrefix(err.Error(), "insufficient stock") {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "INSUFFICIENT_PART_STOCK")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.maintenanceMessage("failed to complete maintenance", err))
+	}
+	return nil
+}
+
+func (s *FleetUnitService) maintenanceMessage(base string, err error) string {
+	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
+		return fmt.Sprintf("%s: %v", base, err)
+	}
+	return base
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -41,8 +42,8 @@ func (s *GarageService) ensureLocationsLoaded() {
 	}
 }
 
-func (s *GarageService) GetGarages(organizationID, itemID string) ([]model.GarageWithLabel, error) {
-	garages, err := s.garageRepo.GetAll(organizationID, itemID)
+func (s *GarageService) GetGarages(ctx context.Context, itemID string) ([]model.GarageWithLabel, error) {
+	garages, err := s.garageRepo.GetAll(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +73,7 @@ func (s *GarageService) GetGarages(organizationID, itemID string) ([]model.Garag
 	return result, nil
 }
 
-func (s *GarageService) CreateGarage(organizationID, createdBy string, req *model.CreateGarageRequest) (*model.Garage, error) {
+func (s *GarageService) CreateGarage(ctx context.Context, createdBy string, req *model.CreateGarageRequest) (*model.Garage, error) {
 	if req.GarageName == "" {
 		return nil, errors.New("garage_name is required")
 	}
@@ -81,7 +82,7 @@ func (s *GarageService) CreateGarage(organizationID, createdBy string, req *mode
 	}
 
 	garage := &model.Garage{
-		OrganizationID: organizationID,
+		OrganizationID: organizationFromContext(ctx),
 		GarageName:     req.GarageName,
 		GarageAddress:  req.GarageAddress,
 		GarageCity:     req.GarageCity,
@@ -89,14 +90,14 @@ func (s *GarageService) CreateGarage(organizationID, createdBy string, req *mode
 		UpdatedBy:      createdBy,
 	}
 
-	if err := s.garageRepo.Create(garage); err != nil {
+	if err := s.garageRepo.Create(ctx, garage); err != nil {
 		return nil, err
 	}
 
 	return garage, nil
 }
 
-func (s *GarageService) UpdateGarage(garageID, organizationID, updatedBy string, req *model.UpdateGarageRequest) (*model.Garage, error) {
+func (s *GarageService) UpdateGarage(ctx context.Context, garageID, updatedBy string, req *model.UpdateGarageRequest) (*model.Garage, error) {
 	if req.GarageName == "" {
 		return nil, errors.New("garage_name is required")
 	}
@@ -104,7 +105,7 @@ func (s *GarageService) UpdateGarage(garageID, organizationID, updatedBy string,
 		return nil, errors.New("garage_city is required")
 	}
 
-	existing, err := s.garageRepo.GetByID(garageID, organizationID)
+	existing, err := s.garageRepo.GetByID(ctx, garageID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("garage not found")
@@ -119,7 +120,7 @@ func (s *GarageService) UpdateGarage(garageID, organizationID, updatedBy string,
 		"updated_by":     updatedBy,
 	}
 
-	if err := s.garageRepo.Update(garageID, organizationID, updates); err != nil {
+	if err := s.garageRepo.Update(ctx, garageID, updates); err != nil {
 		return nil, err
 	}
 
@@ -131,6 +132,6 @@ func (s *GarageService) UpdateGarage(garageID, organizationID, updatedBy string,
 	return existing, nil
 }
 
-func (s *GarageService) DeleteGarage(garageID, organizationID string) error {
-	return s.garageRepo.Delete(garageID, organizationID)
+func (s *GarageService) DeleteGarage(ctx context.Context, garageID string) error {
+	return s.garageRepo.Delete(ctx, garageID)
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"service-travego/model"
@@ -64,6 +65,36 @@ func (s *GeneralService) GetWebMenu() (*model.WebMenu, error) {
 	return &menu, nil
 }
 
+// GetWebMenuFor returns the web menu with the dashboard items the member has
+// no permission for left out. Anonymous callers (nil permissions) only get the
+// items that need no permission.
+func (s *GeneralService) GetWebMenuFor(permissions []string) (*model.WebMenu, error) {
+	menu, err := s.GetWebMenu()
+	if err != nil {
+		return nil, err
+	}
+	granted := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		granted[p] = true
+	}
+	menu.Dashboard = filterMenuItems(menu.Dashboard, granted)
+	return menu, nil
+}
+
+func filterMenuItems(items []model.MenuItem, granted map[string]bool) []model.MenuItem {
+	out := make([]model.MenuItem, 0, len(items))
+	for _, it := range items {
+		if it.Permission != "" && !granted[it.Permission] {
+			continue
+		}
+		if len(it.SubMenus) > 0 {
+			it.SubMenus = filterMenuItems(it.SubMenus, granted)
+		}
+		out = append(out, it)
+	}
+	return out
+}
+
 func (s *GeneralService) GetFuelTypes() ([]model.FleetFuelType, error) {
 	file, err := os.Open("config/fleet-config.json")
 	if err != nil {
@@ -155,12 +186,12 @@ func (s *GeneralService) GetPaymentMethods() ([]model.CommonItem, error) {
 }
 
 // GetBankList reads and returns bank list from database sorted by name
-func (s *GeneralService) GetBankList() ([]model.Bank, error) {
-	return s.generalRepo.GetBankList()
+func (s *GeneralService) GetBankList(ctx context.Context) ([]model.Bank, error) {
+	return s.generalRepo.GetBankList(ctx)
 }
 
-func (s *GeneralService) GetPreferenceCities(organizationID string, cityID *int, serviceType *int) ([]model.PreferenceCityWithLabels, error) {
-	prefs, err := s.generalRepo.GetPreferenceCities(organizationID, cityID, serviceType)
+func (s *GeneralService) GetPreferenceCities(ctx context.Context, cityID *int, serviceType *int) ([]model.PreferenceCityWithLabels, error) {
+	prefs, err := s.generalRepo.GetPreferenceCities(ctx, cityID, serviceType)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +237,7 @@ func (s *GeneralService) GetPreferenceCities(organizationID string, cityID *int,
 			}
 		}
 
-		if types, err := s.generalRepo.GetPreferenceCityTypesByCityIDAndOrganizationID(pref.CityID, pref.OrganizationID); err == nil {
+		if types, err := s.generalRepo.GetPreferenceCityTypesByCityID(ctx, pref.CityID); err == nil {
 			for _, t := range types {
 				if label, ok := model.ServiceTypeLabels[t]; ok {
 					withLabels.ServiceTypes = append(withLabels.ServiceTypes, label)
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+
+	"service-travego/model"
+)
+
+func TestFilterMenuItemsDropsItemsWithoutPermission(t *testing.T) {
+	items := []model.MenuItem{
+		{Title: "Dashboard"},
+		{Title: "Trips", Permission: "orders.view", SubMenus: []model.MenuItem{
+			{Title: "All Trips"},
+			{Title: "Create Trip", Permission: "orders.manage"},
+		}},
+		{Title: "Settings", Permission: "settings.manage"},
+	}
+
+	got := filterMenuItems(items, map[string]bool{"orders.view": true})
+	if len(got) != 2 || got[1].Title != "Trips" {
+		t.Fatalf("expected Dashboard and Trips, got %+v", got)
+	}
+	if len(got[1].SubMenus) != 1 || got[1].SubMenus[0].Title != "All Trips" {
+		t.Fatalf("expected only All Trips under Trips, got %+v", got[1].SubMenus)
+	}
+
+	if anon := filterMenuItems(items, map[string]bool{}); len(anon) != 1 {
+		t.Fatalf("expected anonymous menu to keep only Dashboard, got %+v", anon)
+	}
+}
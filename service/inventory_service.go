@@ -1,13 +1,15 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"service-travego/database"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/repository"
-	"service-travego/utils"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,7 @@ import (
 type InventoryService struct {
 	repo                *repository.InventoryRepository
 	notificationService *NotificationService
+	audit               *AuditService
 }
 
 func NewInventoryService(repo *repository.InventoryRepository, notificationService *NotificationService) *InventoryService {
@@ -25,6 +28,44 @@ func NewInventoryService(repo *repository.InventoryRepository, notificationServi
 	}
 }
 
+// SetAuditService records inventory changes in the audit log
+func (s *InventoryService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// organizationFromContext returns the organization the inventory calls are
+// scoped to, for the audit log, notifications and numbering
+func organizationFromContext(ctx context.Context) string {
+	organizationID, _ := database.OrganizationFromContext(ctx)
+	return organizationID
+}
+
+// requestAuditSnapshot loads an inventory request for the audit log; nil when
+// auditing is off
+func (s *InventoryService) requestAuditSnapshot(ctx context.Context, requestID string) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	req, err := s.repo.GetRequestByID(ctx, requestID)
+	if err != nil {
+		return nil
+	}
+	return req
+}
+
+// orderAuditSnapshot loads a purchase order for the audit log; nil when
+// auditing is off
+func (s *InventoryService) orderAuditSnapshot(ctx context.Context, purchaseID string) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	order, err := s.repo.GetOrderByPurchaseID(ctx, purchaseID)
+	if err != nil {
+		return nil
+	}
+	return order
+}
+
 func (s *InventoryService) GetMovementNotes(movementType int) string {
 	if movementType == 1 {
 		return "Item ditambahkan dari menu inventory items"
@@ -35,20 +76,20 @@ func (s *InventoryService) GetMovementNotes(movementType int) string {
 	return ""
 }
 
-func (s *InventoryService) GetItems(organizationID string, itemCategory int) ([]model.InventoryItemWithLabel, error) {
-	return s.repo.GetAllItems(organizationID, itemCategory, "")
+func (s *InventoryService) GetItems(ctx context.Context, itemCategory int) ([]model.InventoryItemWithLabel, error) {
+	return s.repo.GetAllItems(ctx, itemCategory, "")
 }
 
-func (s *InventoryService) GetAllItems(organizationID string, itemCategory int, status string) ([]model.InventoryItemWithLabel, error) {
-	return s.repo.GetAllItems(organizationID, itemCategory, status)
+func (s *InventoryService) GetAllItems(ctx context.Context, itemCategory int, status string) ([]model.InventoryItemWithLabel, error) {
+	return s.repo.GetAllItems(ctx, itemCategory, status)
 }
 
-func (s *InventoryService) GenerateItemSKU(organizationID string) (string, error) {
-	return s.repo.GenerateItemSKU(organizationID)
+func (s *InventoryService) GenerateItemSKU(ctx context.Context) (string, error) {
+	return s.repo.GenerateItemSKU(ctx)
 }
 
-func (s *InventoryService) GetItem(itemID, organizationID string) (*model.InventoryItem, error) {
-	item, err := s.repo.GetItemByID(itemID, organizationID)
+func (s *InventoryService) GetItem(ctx context.Context, itemID string) (*model.InventoryItem, error) {
+	item, err := s.repo.GetItemByID(ctx, itemID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "item not found")
@@ -58,18 +99,30 @@ func (s *InventoryService) GetItem(itemID, organizationID string) (*model.Invent
 	return item, nil
 }
 
-func (s *InventoryService) CreateItem(organizationID, createdBy string, req *model.CreateInventoryItemRequest) (*model.InventoryItem, error) {
+func (s *InventoryService) CreateItem(ctx context.Context, createdBy string, req *model.CreateInventoryItemRequest) (*model.InventoryItem, error) {
+	var item *model.InventoryItem
+	var err error
 	switch req.TransactionType {
 	case "1":
-		return s.createItemTransactionType1(organizationID, createdBy, req)
+		item, err = s.createItemTransactionType1(ctx, createdBy, req)
 	case "2":
-		return s.createItemTransactionType2(organizationID, createdBy, req)
+		item, err = s.createItemTransactionType2(ctx, createdBy, req)
 	default:
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid transaction_type")
 	}
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    createdBy,
+		EntityType: model.AuditEntityInventoryItem, EntityID: item.ItemID, Action: model.AuditActionCreate,
+		After: item,
+	})
+	return item, nil
 }
 
-func (s *InventoryService) createItemTransactionType1(organizationID, createdBy string, req *model.CreateInventoryItemRequest) (*model.InventoryItem, error) {
+func (s *InventoryService) createItemTransactionType1(ctx context.Context, createdBy string, req *model.CreateInventoryItemRequest) (*model.InventoryItem, error) {
+	organizationID := organizationFromContext(ctx)
 	if req.ItemName == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "item_name is required")
 	}
@@ -77,7 +130,7 @@ func (s *InventoryService) createItemTransactionType1(organizationID, createdBy
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "item_uom is required")
 	}
 
-	itemID, err := s.repo.GetItemIDByName(req.ItemName, organizationID)
+	itemID, err := s.repo.GetItemIDByName(ctx, req.ItemName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			item := &model.InventoryItem{
@@ -89,17 +142,17 @@ func (s *InventoryService) createItemTransactionType1(organizationID, createdBy
 				UpdatedBy:      createdBy,
 			}
 
-			if err := s.repo.CreateItem(item); err != nil {
+			if err := s.repo.CreateItem(ctx, item); err != nil {
 				return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create item")
 			}
 
 			itemID = item.ItemID
 
 			if req.GarageID != "" {
-				s.repo.UpsertItemGarage(itemID, req.GarageID, organizationID, createdBy, req.Stock)
+				s.repo.UpsertItemGarage(ctx, itemID, req.GarageID, createdBy, req.Stock)
 
 				movementType := 1
-				s.repo.CreateInventoryMovement(organizationID, &model.InventoryMovement{
+				s.repo.CreateInventoryMovement(ctx, &model.InventoryMovement{
 					MovementID:   uuid.New().String(),
 					ItemID:       itemID,
 					GarageID:     req.GarageID,
@@ -119,9 +172,9 @@ func (s *InventoryService) createItemTransactionType1(organizationID, createdBy
 	}
 
 	if req.GarageID != "" {
-		s.repo.UpsertItemGarage(itemID, req.GarageID, organizationID, createdBy, req.Stock)
+		s.repo.UpsertItemGarage(ctx, itemID, req.GarageID, createdBy, req.Stock)
 
-		s.repo.CreateInventoryMovement(organizationID, &model.InventoryMovement{
+		s.repo.CreateInventoryMovement(ctx, &model.InventoryMovement{
 			MovementID:   uuid.New().String(),
 			ItemID:       itemID,
 			GarageID:     req.GarageID,
@@ -135,7 +188,7 @@ func (s *InventoryService) createItemTransactionType1(organizationID, createdBy
 		})
 	}
 
-	item, err := s.repo.GetItemByID(itemID, organizationID)
+	item, err := s.repo.GetItemByID(ctx, itemID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get item")
 	}
@@ -143,21 +196,22 @@ func (s *InventoryService) createItemTransactionType1(organizationID, createdBy
 	return item, nil
 }
 
-func (s *InventoryService) createItemTransactionType2(organizationID, createdBy string, req *model.CreateInventoryItemRequest) (*model.InventoryItem, error) {
+func (s *InventoryService) createItemTransactionType2(ctx context.Context, createdBy string, req *model.CreateInventoryItemRequest) (*model.InventoryItem, error) {
+	organizationID := organizationFromContext(ctx)
 	if req.ItemID == "" && req.ItemName == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "item_id or item_name is required")
 	}
 
 	totalAmount := float64(req.Stock) * req.ItemPrice
 
-	purchaseID, err := utils.GeneratePurchaseOrderID(s.repo.GetDB(), s.repo.GetDriver(), organizationID)
+	purchaseID, err := s.repo.GeneratePurchaseOrderID(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate purchase order ID")
 	}
 
 	var item *model.InventoryItem
 	var itemID string
-	_, err = s.repo.GetItemByID(req.ItemID, organizationID)
+	_, err = s.repo.GetItemByID(ctx, req.ItemID)
 	if err != nil {
 		if err == sql.ErrNoRows || req.ItemID == "" {
 			if req.ItemName == "" {
@@ -176,7 +230,7 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 				UpdatedBy:      createdBy,
 			}
 
-			if err := s.repo.CreateItemWithoutStock(newItem); err != nil {
+			if err := s.repo.CreateItemWithoutStock(ctx, newItem); err != nil {
 				return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create item")
 			}
 
@@ -187,7 +241,7 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 		}
 	} else {
 		itemID = req.ItemID
-		item, err = s.repo.GetItemByID(req.ItemID, organizationID)
+		item, err = s.repo.GetItemByID(ctx, req.ItemID)
 		if err != nil {
 			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get item")
 		}
@@ -197,7 +251,7 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 	if req.SupplierID != "" {
 		supplierID = req.SupplierID
 	} else {
-		supplierID, err = s.repo.GetSupplierIDByName(req.SupplierName)
+		supplierID, err = s.repo.GetSupplierIDByName(ctx, req.SupplierName)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				newSupplier := &model.Supplier{
@@ -208,7 +262,7 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 					UpdatedBy:     createdBy,
 				}
 				var createErr error
-				supplierID, createErr = s.repo.CreateSupplierFromExternal(newSupplier)
+				supplierID, createErr = s.repo.CreateSupplierFromExternal(ctx, newSupplier)
 				if createErr != nil {
 					return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create supplier")
 				}
@@ -219,7 +273,7 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 	}
 
 	// if req.GarageID != "" {
-	// 	if err := s.repo.UpsertItemGarage(itemID, req.GarageID, organizationID, createdBy, req.Stock); err != nil {
+	// 	if err := s.repo.UpsertItemGarage(ctx, itemID, req.GarageID, createdBy, req.Stock); err != nil {
 	// 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to upsert garage stock")
 	// 	}
 	// }
@@ -238,12 +292,12 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 		CreatedBy:       createdBy,
 		UpdatedBy:       createdBy,
 	}
-	if err := s.repo.CreatePurchaseOrder(order); err != nil {
-		fmt.Println("err create purchase ", err)
+	if err := s.repo.CreatePurchaseOrder(ctx, order); err != nil {
+		slog.ErrorContext(ctx, "create purchase order failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create purchase order")
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumber(s.repo.GetDB(), s.repo.GetDriver(), organizationID, 1, time.Now())
+	invoiceNumber, err := s.repo.GenerateInvoiceNumber(ctx, 1, time.Now())
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate invoice number")
 	}
@@ -267,20 +321,20 @@ func (s *InventoryService) createItemTransactionType2(organizationID, createdBy
 		CreatedAt:           time.Now(),
 		Status:              0,
 	}
-	if err := s.repo.CreateInventoryTransaction(organizationID, transaction); err != nil {
-		fmt.Println("err create transaction ", err)
+	if err := s.repo.CreateInventoryTransaction(ctx, transaction); err != nil {
+		slog.ErrorContext(ctx, "create inventory transaction failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create transaction")
 	}
 
 	return item, nil
 }
 
-func (s *InventoryService) UpdateItem(organizationID, updatedBy string, req *model.UpdateInventoryItemRequest) (*model.InventoryItem, error) {
+func (s *InventoryService) UpdateItem(ctx context.Context, updatedBy string, req *model.UpdateInventoryItemRequest) (*model.InventoryItem, error) {
 	if req.ItemID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "item_id is required")
 	}
 
-	existing, err := s.repo.GetItemByID(req.ItemID, organizationID)
+	existing, err := s.repo.GetItemByID(ctx, req.ItemID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "item not found")
@@ -307,10 +361,11 @@ func (s *InventoryService) UpdateItem(organizationID, updatedBy string, req *mod
 		return existing, nil
 	}
 
-	if err := s.repo.UpdateItem(req.ItemID, organizationID, updatedBy, updates); err != nil {
+	if err := s.repo.UpdateItem(ctx, req.ItemID, updatedBy, updates); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update item")
 	}
 
+	before := *existing
 	existing.UpdatedBy = updatedBy
 	for k, v := range updates {
 		switch k {
@@ -325,14 +380,31 @@ func (s *InventoryService) UpdateItem(organizationID, updatedBy string, req *mod
 		}
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityInventoryItem, EntityID: req.ItemID, Action: model.AuditActionUpdate,
+		Before: before, After: existing,
+	})
 	return existing, nil
 }
 
-func (s *InventoryService) DeleteItem(organizationID, itemID string) error {
-	return s.repo.DeleteItem(itemID, organizationID)
+func (s *InventoryService) DeleteItem(ctx context.Context, deletedBy, itemID string) error {
+	var before *model.InventoryItem
+	if s.audit != nil {
+		before, _ = s.repo.GetItemByID(ctx, itemID)
+	}
+	if err := s.repo.DeleteItem(ctx, itemID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    deletedBy,
+		EntityType: model.AuditEntityInventoryItem, EntityID: itemID, Action: model.AuditActionDelete,
+		Before: before,
+	})
+	return nil
 }
 
-func (s *InventoryService) TransferItem(organizationID, createdBy string, req *model.TransferInventoryItemRequest) error {
+func (s *InventoryService) TransferItem(ctx context.Context, createdBy string, req *model.TransferInventoryItemRequest) error {
 	if req.ItemID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "item_id is required")
 	}
@@ -349,7 +421,7 @@ func (s *InventoryService) TransferItem(organizationID, createdBy string, req *m
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "stock is required")
 	}
 
-	currentStockFrom, err := s.repo.GetItemGarageStockWithGarageName(req.ItemID, req.GarageFrom, organizationID)
+	currentStockFrom, err := s.repo.GetItemGarageStockWithGarageName(ctx, req.ItemID, req.GarageFrom)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "garage_from not found")
@@ -357,7 +429,7 @@ func (s *InventoryService) TransferItem(organizationID, createdBy string, req *m
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get garage_from stock")
 	}
 
-	currentStockDest, err := s.repo.GetItemGarageStockWithGarageName(req.ItemID, req.GarageDestination, organizationID)
+	currentStockDest, err := s.repo.GetItemGarageStockWithGarageName(ctx, req.ItemID, req.GarageDestination)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "garage_destination not found")
@@ -369,18 +441,30 @@ func (s *InventoryService) TransferItem(organizationID, createdBy string, req *m
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "Stok yang ditransfer melebihi jumlah stok yang tersedia")
 	}
 
-	if err := s.repo.TransferItemStock(organizationID, createdBy, req, currentStockFrom, currentStockDest); err != nil {
+	if err := s.repo.TransferItemStock(ctx, createdBy, req, currentStockFrom, currentStockDest); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "garage stock not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to transfer stock")
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    createdBy,
+		EntityType: model.AuditEntityInventoryItem, EntityID: req.ItemID, Action: model.AuditActionUpdate,
+		Before: map[string]interface{}{
+			"stock_" + req.GarageFrom:        currentStockFrom.Stock,
+			"stock_" + req.GarageDestination: currentStockDest.Stock,
+		},
+		After: map[string]interface{}{
+			"stock_" + req.GarageFrom:        currentStockFrom.Stock - req.Stock,
+			"stock_" + req.GarageDestination: currentStockDest.Stock + req.Stock,
+		},
+	})
 	return nil
 }
 
-func (s *InventoryService) GetItemDetail(itemID string) (*model.InventoryItemDetail, error) {
-	item, err := s.repo.GetItemDetail(itemID)
+func (s *InventoryService) GetItemDetail(ctx context.Context, itemID string) (*model.InventoryItemDetail, error) {
+	item, err := s.repo.GetItemDetail(ctx, itemID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "item not found")
@@ -390,24 +474,24 @@ func (s *InventoryService) GetItemDetail(itemID string) (*model.InventoryItemDet
 	return item, nil
 }
 
-func (s *InventoryService) GetItemMovements(organizationID, itemID, startDate, endDate, garageID string) ([]model.InventoryItemMovement, error) {
-	return s.repo.GetItemMovements(organizationID, itemID, startDate, endDate, garageID)
+func (s *InventoryService) GetItemMovements(ctx context.Context, itemID, startDate, endDate, garageID string) ([]model.InventoryItemMovement, error) {
+	return s.repo.GetItemMovements(ctx, itemID, startDate, endDate, garageID)
 }
 
-func (s *InventoryService) GetRequests(organizationID string) ([]model.InventoryRequestWithLabel, error) {
-	return s.repo.GetRequestList(organizationID)
+func (s *InventoryService) GetRequests(ctx context.Context) ([]model.InventoryRequestWithLabel, error) {
+	return s.repo.GetRequestList(ctx)
 }
 
-func (s *InventoryService) GetRequest(requestID, organizationID string) (*model.InventoryRequestWithLabel, error) {
-	req, err := s.repo.GetRequestByID(requestID, organizationID)
+func (s *InventoryService) GetRequest(ctx context.Context, requestID string) (*model.InventoryRequestWithLabel, error) {
+	req, err := s.repo.GetRequestByID(ctx, requestID)
 	if err != nil {
-		fmt.Println("check error", err)
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "request not found")
 		}
+		slog.ErrorContext(ctx, "get inventory request failed", "request_id", requestID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get request")
 	}
-	currentStock, err := s.repo.GetCurrentGarageItemStock(req.ItemID, req.GarageID, organizationID)
+	currentStock, err := s.repo.GetCurrentGarageItemStock(ctx, req.ItemID, req.GarageID)
 	if err == sql.ErrNoRows {
 		req.Stock = 0
 	}
@@ -417,7 +501,8 @@ func (s *InventoryService) GetRequest(requestID, organizationID string) (*model.
 	return req, nil
 }
 
-func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *model.CreateInventoryRequestRequest) (*model.InventoryRequest, error) {
+func (s *InventoryService) CreateRequest(ctx context.Context, createdBy string, req *model.CreateInventoryRequestRequest) (*model.InventoryRequest, error) {
+	organizationID := organizationFromContext(ctx)
 	if req.ItemID == "" && req.ItemName == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "item_id or item_name is required")
 	}
@@ -439,7 +524,7 @@ func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *
 
 	if req.ItemID != "" {
 		itemID = req.ItemID
-		item, err := s.repo.GetItemByID(itemID, organizationID)
+		item, err := s.repo.GetItemByID(ctx, itemID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "item not found")
@@ -450,7 +535,7 @@ func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *
 		itemUOM = item.ItemUOM
 		itemCategory = item.ItemCategory
 	} else {
-		itemID, err = s.repo.GetItemIDByName(req.ItemName, organizationID)
+		itemID, err = s.repo.GetItemIDByName(ctx, req.ItemName)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				newItem := &model.InventoryItem{
@@ -461,7 +546,7 @@ func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *
 					CreatedBy:      createdBy,
 					UpdatedBy:      createdBy,
 				}
-				if createErr := s.repo.CreateItemWithoutStock(newItem); createErr != nil {
+				if createErr := s.repo.CreateItemWithoutStock(ctx, newItem); createErr != nil {
 					return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create item")
 				}
 				itemID = newItem.ItemID
@@ -472,7 +557,7 @@ func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *
 				return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to find item")
 			}
 		} else {
-			item, err := s.repo.GetItemByID(itemID, organizationID)
+			item, err := s.repo.GetItemByID(ctx, itemID)
 			if err != nil {
 				if err != sql.ErrNoRows {
 					return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get item")
@@ -485,7 +570,7 @@ func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *
 		}
 	}
 
-	requestNumber, err := utils.GenerateRequestNumber(s.repo.GetDB(), s.repo.GetDriver(), organizationID)
+	requestNumber, err := s.repo.GenerateRequestNumber(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate request number")
 	}
@@ -504,23 +589,28 @@ func (s *InventoryService) CreateRequest(organizationID, createdBy string, req *
 		CreatedBy:      createdBy,
 		UpdatedBy:      createdBy,
 	}
-	if err := s.repo.CreateRequest(request); err != nil {
-		fmt.Println("err - req ", err)
+	if err := s.repo.CreateRequest(ctx, request); err != nil {
+		slog.ErrorContext(ctx, "create inventory request failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create request")
 	}
 
 	if req.UnitID != "" {
-		if err := s.repo.CreateRequestFleet(request.RequestNumber, req.UnitID, createdBy); err != nil {
+		if err := s.repo.CreateRequestFleet(ctx, request.RequestNumber, req.UnitID, createdBy); err != nil {
 			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create request fleet")
 		}
 	}
 
-	_ = s.sendRequestNotification(organizationID, request)
+	_ = s.sendRequestNotification(ctx, request)
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    createdBy,
+		EntityType: model.AuditEntityInventoryRequest, EntityID: request.RequestNumber, Action: model.AuditActionCreate,
+		After: request,
+	})
 	return request, nil
 }
 
-func (s *InventoryService) sendRequestNotification(organizationID string, request *model.InventoryRequest) error {
+func (s *InventoryService) sendRequestNotification(ctx context.Context, request *model.InventoryRequest) error {
 	if s.notificationService == nil {
 		return nil
 	}
@@ -530,7 +620,7 @@ func (s *InventoryService) sendRequestNotification(organizationID string, reques
 		return nil
 	}
 
-	_, _ = s.notificationService.CreateNotification(organizationID, NotificationPayload{
+	_, _ = s.notificationService.CreateNotification(ctx, NotificationPayload{
 		Title:   "Permintaan Asset Baru",
 		Message: "Tinjau permintaan asset baru",
 		URL:     fmt.Sprintf("%s/dashboard/inventories/request/detail/%s", baseURL, request.RequestID),
@@ -539,12 +629,12 @@ func (s *InventoryService) sendRequestNotification(organizationID string, reques
 	return nil
 }
 
-func (s *InventoryService) ApproveRequest(organizationID, updatedBy string, req *model.ApproveInventoryRequestRequest) error {
+func (s *InventoryService) ApproveRequest(ctx context.Context, updatedBy string, req *model.ApproveInventoryRequestRequest) error {
 	if req.RequestID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "request_id is required")
 	}
 
-	_, err := s.repo.GetRequestByID(req.RequestID, organizationID)
+	before, err := s.repo.GetRequestByID(ctx, req.RequestID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "request not found")
@@ -552,19 +642,24 @@ func (s *InventoryService) ApproveRequest(organizationID, updatedBy string, req
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get request")
 	}
 
-	if err := s.repo.ApproveInventoryRequest(req.RequestID, organizationID, req.ItemID, updatedBy, updatedBy); err != nil {
+	if err := s.repo.ApproveInventoryRequest(ctx, req.RequestID, req.ItemID, updatedBy, updatedBy); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to approve request")
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityInventoryRequest, EntityID: req.RequestID, Action: model.AuditActionApprove,
+		Before: before, After: s.requestAuditSnapshot(ctx, req.RequestID),
+	})
 	return nil
 }
 
-func (s *InventoryService) RejectRequest(organizationID, updatedBy string, req *model.RejectInventoryRequestRequest) error {
+func (s *InventoryService) RejectRequest(ctx context.Context, updatedBy string, req *model.RejectInventoryRequestRequest) error {
 	if req.RequestID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "request_id is required")
 	}
 
-	_, err := s.repo.GetRequestForApprove(req.RequestID, organizationID)
+	_, err := s.repo.GetRequestForApprove(ctx, req.RequestID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "request not found")
@@ -572,12 +667,18 @@ func (s *InventoryService) RejectRequest(organizationID, updatedBy string, req *
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get request")
 	}
 
-	if err := s.repo.RejectInventoryRequest(req.RequestID, organizationID, updatedBy); err != nil {
+	before := s.requestAuditSnapshot(ctx, req.RequestID)
+	if err := s.repo.RejectInventoryRequest(ctx, req.RequestID, updatedBy); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to reject request")
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityInventoryRequest, EntityID: req.RequestID, Action: model.AuditActionReject,
+		Before: before, After: s.requestAuditSnapshot(ctx, req.RequestID),
+	})
 
 	if s.notificationService != nil {
-		_, _ = s.notificationService.CreateNotification(organizationID, NotificationPayload{
+		_, _ = s.notificationService.CreateNotification(ctx, NotificationPayload{
 			Title:   "Permintaan Ditolak",
 			Message: fmt.Sprintf("Permintaan dengan request_id %s telah ditolak", req.RequestID),
 		})
@@ -586,7 +687,7 @@ func (s *InventoryService) RejectRequest(organizationID, updatedBy string, req *
 	return nil
 }
 
-func (s *InventoryService) UpdateRequest(organizationID, updatedBy string, req *model.UpdateInventoryRequestRequest) error {
+func (s *InventoryService) UpdateRequest(ctx context.Context, updatedBy string, req *model.UpdateInventoryRequestRequest) error {
 	if req.RequestID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "request_id is required")
 	}
@@ -596,41 +697,52 @@ func (s *InventoryService) UpdateRequest(organizationID, updatedBy string, req *
 		action = "delete"
 	}
 
+	before := s.requestAuditSnapshot(ctx, req.RequestID)
+	auditAction := model.AuditActionDelete
 	switch action {
 	case "delete":
-		return s.repo.UpdateRequestStatus(req.RequestID, organizationID, updatedBy, 0)
+		if err := s.repo.UpdateRequestStatus(ctx, req.RequestID, updatedBy, 0); err != nil {
+			return err
+		}
 	case "approve":
-		if err := s.repo.UpdateRequestApprove(req.RequestID, organizationID, updatedBy, time.Now()); err != nil {
+		if err := s.repo.UpdateRequestApprove(ctx, req.RequestID, updatedBy, time.Now()); err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to approve request")
 		}
-		return nil
+		auditAction = model.AuditActionApprove
 	default:
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid action")
 	}
+
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityInventoryRequest, EntityID: req.RequestID, Action: auditAction,
+		Before: before, After: s.requestAuditSnapshot(ctx, req.RequestID),
+	})
+	return nil
 }
 
-func (s *InventoryService) GetOrders(organizationID string) ([]model.InventoryOrderWithDetail, error) {
-	return s.repo.GetOrdersList(organizationID)
+func (s *InventoryService) GetOrders(ctx context.Context) ([]model.InventoryOrderWithDetail, error) {
+	return s.repo.GetOrdersList(ctx)
 }
 
-func (s *InventoryService) GetOrder(purchaseID, organizationID string) (*model.InventoryOrderWithDetail, error) {
-	order, err := s.repo.GetOrderByPurchaseID(purchaseID, organizationID)
+func (s *InventoryService) GetOrder(ctx context.Context, purchaseID string) (*model.InventoryOrderWithDetail, error) {
+	order, err := s.repo.GetOrderByPurchaseID(ctx, purchaseID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
 		}
-		fmt.Println("err get order ", err)
+		slog.ErrorContext(ctx, "get purchase order failed", "purchase_id", purchaseID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order")
 	}
 	return order, nil
 }
 
-func (s *InventoryService) SubmitOrder(organizationID, userID string, req *model.SubmitInventoryOrderRequest) (*model.InventoryOrderWithDetail, error) {
+func (s *InventoryService) SubmitOrder(ctx context.Context, userID string, req *model.SubmitInventoryOrderRequest) (*model.InventoryOrderWithDetail, error) {
 	if req.PurchaseID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "purchase_id is required")
 	}
 
-	order, err := s.repo.GetOrderByPurchaseID(req.PurchaseID, organizationID)
+	order, err := s.repo.GetOrderByPurchaseID(ctx, req.PurchaseID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
@@ -639,7 +751,7 @@ func (s *InventoryService) SubmitOrder(organizationID, userID string, req *model
 	}
 
 	if req.SupplierName != "" {
-		supplierID, err := s.repo.GetSupplierIDByName(req.SupplierName)
+		supplierID, err := s.repo.GetSupplierIDByName(ctx, req.SupplierName)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				newSupplier := &model.Supplier{
@@ -647,7 +759,7 @@ func (s *InventoryService) SubmitOrder(organizationID, userID string, req *model
 					CreatedBy:    userID,
 					UpdatedBy:    userID,
 				}
-				if err := s.repo.CreateSupplier(newSupplier); err != nil {
+				if err := s.repo.CreateSupplier(ctx, newSupplier); err != nil {
 					return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create supplier")
 				}
 				supplierID = newSupplier.SupplierID
@@ -655,32 +767,38 @@ func (s *InventoryService) SubmitOrder(organizationID, userID string, req *model
 				return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to find supplier")
 			}
 		}
-		if err := s.repo.UpdateOrderSupplier(req.PurchaseID, organizationID, supplierID, userID); err != nil {
+		if err := s.repo.UpdateOrderSupplier(ctx, req.PurchaseID, supplierID, userID); err != nil {
 			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update supplier")
 		}
+		s.audit.Record(ctx, AuditEntry{
+			ActorID:    userID,
+			EntityType: model.AuditEntityInventoryOrder, EntityID: req.PurchaseID, Action: model.AuditActionUpdate,
+			Before: map[string]interface{}{"suplier_id": order.SupplierID},
+			After:  map[string]interface{}{"suplier_id": supplierID},
+		})
 		order.SupplierID = supplierID
 	}
 
 	return order, nil
 }
 
-func (s *InventoryService) GetSuppliers(organizationID string) ([]model.Supplier, error) {
-	return s.repo.GetSuppliers(organizationID)
+func (s *InventoryService) GetSuppliers(ctx context.Context) ([]model.Supplier, error) {
+	return s.repo.GetSuppliers(ctx)
 }
 
-func (s *InventoryService) GetSupplier(supplierID string) (*model.Supplier, error) {
-	supplier, err := s.repo.GetSupplierByID(supplierID)
+func (s *InventoryService) GetSupplier(ctx context.Context, supplierID string) (*model.Supplier, error) {
+	supplier, err := s.repo.GetSupplierByID(ctx, supplierID)
 	if err != nil {
-		fmt.Println("err==== ", err)
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "supplier not found")
 		}
+		slog.ErrorContext(ctx, "get supplier failed", "supplier_id", supplierID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get supplier")
 	}
 	return supplier, nil
 }
 
-func (s *InventoryService) CreateSupplier(organizationID, createdBy string, req *model.CreateSupplierRequest) (*model.Supplier, error) {
+func (s *InventoryService) CreateSupplier(ctx context.Context, createdBy string, req *model.CreateSupplierRequest) (*model.Supplier, error) {
 	if req.SupplierName == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "suplier_name is required")
 	}
@@ -695,49 +813,82 @@ func (s *InventoryService) CreateSupplier(organizationID, createdBy string, req
 		UpdatedBy:       createdBy,
 	}
 
-	if err := s.repo.CreateSupplier(supplier); err != nil {
+	if err := s.repo.CreateSupplier(ctx, supplier); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create supplier")
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    createdBy,
+		EntityType: model.AuditEntitySupplier, EntityID: supplier.SupplierID, Action: model.AuditActionCreate,
+		After: supplier,
+	})
 	return supplier, nil
 }
 
-func (s *InventoryService) DeleteSupplier(organizationID, supplierID string) error {
-	return s.repo.DeleteSupplier(supplierID)
+func (s *InventoryService) DeleteSupplier(ctx context.Context, deletedBy, supplierID string) error {
+	var before *model.Supplier
+	if s.audit != nil {
+		before, _ = s.repo.GetSupplierByID(ctx, supplierID)
+	}
+	if err := s.repo.DeleteSupplier(ctx, supplierID); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "supplier not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete supplier")
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    deletedBy,
+		EntityType: model.AuditEntitySupplier, EntityID: supplierID, Action: model.AuditActionDelete,
+		Before: before,
+	})
+	return nil
 }
 
-func (s *InventoryService) ReceiveRequest(organizationID, updatedBy string, req *model.ReceiveInventoryOrderRequest) error {
+func (s *InventoryService) ReceiveRequest(ctx context.Context, updatedBy string, req *model.ReceiveInventoryOrderRequest) error {
 	if req.PurchaseID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "purchase_id is required")
 	}
 
-	if err := s.repo.ReceivePurchaseOrder(req.PurchaseID, organizationID, updatedBy); err != nil {
+	before := s.orderAuditSnapshot(ctx, req.PurchaseID)
+	if err := s.repo.ReceivePurchaseOrder(ctx, req.PurchaseID, updatedBy); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to receive purchase order")
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityInventoryOrder, EntityID: req.PurchaseID, Action: model.AuditActionReceive,
+		Before: before, After: s.orderAuditSnapshot(ctx, req.PurchaseID),
+	})
 
 	return nil
 }
 
-func (s *InventoryService) ReceiveRequestItem(organizationID, userID string, employeeID string, requestID string) error {
+func (s *InventoryService) ReceiveRequestItem(ctx context.Context, userID string, employeeID string, requestID string) error {
 	if requestID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "request_id is required")
 	}
 
-	if err := s.repo.ReceiveOrderItem(organizationID, userID, employeeID, requestID); err != nil {
+	before := s.requestAuditSnapshot(ctx, requestID)
+	if err := s.repo.ReceiveOrderItem(ctx, userID, employeeID, requestID); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to receive purchase order item")
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityInventoryRequest, EntityID: requestID, Action: model.AuditActionReceive,
+		Before: before, After: s.requestAuditSnapshot(ctx, requestID),
+	})
 
 	return nil
 }
 
-func (s *InventoryService) SubmitRequestOrder(organizationID, userID string, req *model.SubmitRequestOrderRequest) (*model.InventoryOrder, error) {
-	inventoryReq, err := s.repo.GetRequestByID(req.RequestID, organizationID)
+func (s *InventoryService) SubmitRequestOrder(ctx context.Context, userID string, req *model.SubmitRequestOrderRequest) (*model.InventoryOrder, error) {
+	organizationID := organizationFromContext(ctx)
+	inventoryReq, err := s.repo.GetRequestByID(ctx, req.RequestID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "request not found")
@@ -751,7 +902,7 @@ func (s *InventoryService) SubmitRequestOrder(organizationID, userID string, req
 	if req.SupplierID != "" {
 		supplierID = req.SupplierID
 	} else {
-		supplierID, err = s.repo.GetSupplierIDByName(req.SupplierName)
+		supplierID, err = s.repo.GetSupplierIDByName(ctx, req.SupplierName)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				newSupplier := &model.Supplier{
@@ -760,7 +911,7 @@ func (s *InventoryService) SubmitRequestOrder(organizationID, userID string, req
 					CreatedBy:     userID,
 					UpdatedBy:     userID,
 				}
-				supplierID, err = s.repo.CreateSupplierFromExternal(newSupplier)
+				supplierID, err = s.repo.CreateSupplierFromExternal(ctx, newSupplier)
 				if err != nil {
 					return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create supplier")
 				}
@@ -770,7 +921,7 @@ func (s *InventoryService) SubmitRequestOrder(organizationID, userID string, req
 		}
 	}
 
-	purchaseID, err := utils.GeneratePurchaseOrderID(s.repo.GetDB(), s.repo.GetDriver(), organizationID)
+	purchaseID, err := s.repo.GeneratePurchaseOrderID(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate purchase order ID")
 	}
@@ -788,12 +939,12 @@ func (s *InventoryService) SubmitRequestOrder(organizationID, userID string, req
 		CreatedBy:      userID,
 		UpdatedBy:      userID,
 	}
-	if err := s.repo.CreatePurchaseOrder(order); err != nil {
-		fmt.Println("err==== ", err)
+	if err := s.repo.CreatePurchaseOrder(ctx, order); err != nil {
+		slog.ErrorContext(ctx, "create purchase order failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create purchase order")
 	}
 
-	invoiceNumber, err := utils.GenerateInvoiceNumber(s.repo.GetDB(), s.repo.GetDriver(), organizationID, 1, time.Now())
+	invoiceNumber, err := s.repo.GenerateInvoiceNumber(ctx, 1, time.Now())
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate invoice number")
 	}
@@ -813,31 +964,36 @@ func (s *InventoryService) SubmitRequestOrder(organizationID, userID string, req
 		TransactionDateStr:  time.Now().Format("2006-01-02"),
 		Status:              0,
 	}
-	if err := s.repo.CreateInventoryTransaction(organizationID, transaction); err != nil {
+	if err := s.repo.CreateInventoryTransaction(ctx, transaction); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create transaction")
 	}
 
-	if err := s.repo.UpdateRequestOrderStatus(req.RequestID, organizationID, userID, 3); err != nil {
+	if err := s.repo.UpdateRequestOrderStatus(ctx, req.RequestID, userID, 3); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update request order status")
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityInventoryOrder, EntityID: purchaseID, Action: model.AuditActionCreate,
+		After: order,
+	})
 	return order, nil
 }
 
-func (s *InventoryService) GetItemOrderHistory(organizationID, itemID string, startDate, endDate string) ([]model.InventoryHistory, error) {
-	return s.repo.GetItemOrderHistory(organizationID, itemID, startDate, endDate)
+func (s *InventoryService) GetItemOrderHistory(ctx context.Context, itemID string, startDate, endDate string) ([]model.InventoryHistory, error) {
+	return s.repo.GetItemOrderHistory(ctx, itemID, startDate, endDate)
 }
 
-func (s *InventoryService) GetAdminPhone(organizationID string) (string, error) {
-	return s.repo.GetAdminAccountNumber(organizationID)
+func (s *InventoryService) GetAdminPhone(ctx context.Context) (string, error) {
+	return s.repo.GetAdminAccountNumber(ctx)
 }
 
-func (s *InventoryService) GetEmployeePhone(employeeUUID string) (string, error) {
-	return s.repo.GetEmployeePhoneByUUID(employeeUUID)
+func (s *InventoryService) GetEmployeePhone(ctx context.Context, employeeUUID string) (string, error) {
+	return s.repo.GetEmployeePhoneByUUID(ctx, employeeUUID)
 }
 
-func (s *InventoryService) GetRequestForApprove(requestID, organizationID string) (*model.InventoryRequest, error) {
-	req, err := s.repo.GetRequestForApprove(requestID, organizationID)
+func (s *InventoryService) GetRequestForApprove(ctx context.Context, requestID string) (*model.InventoryRequest, error) {
+	req, err := s.repo.GetRequestForApprove(ctx, requestID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "request not found")
@@ -847,25 +1003,31 @@ func (s *InventoryService) GetRequestForApprove(requestID, organizationID string
 	return req, nil
 }
 
-func (s *InventoryService) IsAdminByAccountNumber(accountNumber string) (bool, error) {
-	return s.repo.IsAdminByAccountNumber(accountNumber)
+func (s *InventoryService) IsAdminByAccountNumber(ctx context.Context, accountNumber string) (bool, error) {
+	return s.repo.IsAdminByAccountNumber(ctx, accountNumber)
 }
 
-func (s *InventoryService) CancelOrder(organizationID, updatedBy, purchaseID string) error {
+func (s *InventoryService) CancelOrder(ctx context.Context, updatedBy, purchaseID string) error {
 	if purchaseID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "purchase_id is required")
 	}
 
-	if err := s.repo.CancelPurchaseOrder(purchaseID, organizationID, updatedBy); err != nil {
+	before := s.orderAuditSnapshot(ctx, purchaseID)
+	if err := s.repo.CancelPurchaseOrder(ctx, purchaseID, updatedBy); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
 		}
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to cancel purchase order")
 	}
 
-	if errTrx := s.repo.CancelInventoryTransaction(organizationID, updatedBy, purchaseID); errTrx != nil {
+	if errTrx := s.repo.CancelInventoryTransaction(ctx, updatedBy, purchaseID); errTrx != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to cancel transaction")
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityInventoryOrder, EntityID: purchaseID, Action: model.AuditActionCancel,
+		Before: before, After: s.orderAuditSnapshot(ctx, purchaseID),
+	})
 	return nil
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"image"
 	"image/jpeg"
 	_ "image/png"
@@ -27,11 +28,11 @@ func NewLeaveManagementService(repo *repository.LeaveManagementRepository) *Leav
 	return &LeaveManagementService{repo: repo}
 }
 
-func (s *LeaveManagementService) GetLeaveTypes() ([]model.LeaveManagementTypeItem, error) {
-	return s.repo.ListLeaveTypes()
+func (s *LeaveManagementService) GetLeaveTypes(ctx context.Context) ([]model.LeaveManagementTypeItem, error) {
+	return s.repo.ListLeaveTypes(ctx)
 }
 
-func (s *LeaveManagementService) ListLeaveManagement(orgID, month, year string) ([]model.LeaveManagementListItem, error) {
+func (s *LeaveManagementService) ListLeaveManagement(ctx context.Context, month, year string) ([]model.LeaveManagementListItem, error) {
 	var start *time.Time
 	var end *time.Time
 
@@ -56,17 +57,17 @@ func (s *LeaveManagementService) ListLeaveManagement(orgID, month, year string)
 		end = &endTime
 	}
 
-	return s.repo.ListEmployeeLeaves(orgID, start, end)
+	return s.repo.ListEmployeeLeaves(ctx, start, end)
 }
 
-func (s *LeaveManagementService) CreateLeave(organizationID, userID string, req *model.LeaveManagementCreateRequest) (string, error) {
+func (s *LeaveManagementService) CreateLeave(ctx context.Context, userID string, req *model.LeaveManagementCreateRequest) (string, error) {
 	employeeID := strings.TrimSpace(req.EmployeeID)
 	substituteID := strings.TrimSpace(req.SubstituteID)
 	if employeeID == "" || substituteID == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "employee_id and substitute_id is required")
 	}
 
-	employeeExists, err := s.repo.EmployeeUUIDExists(organizationID, employeeID)
+	employeeExists, err := s.repo.EmployeeUUIDExists(ctx, employeeID)
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate employee_id")
 	}
@@ -74,7 +75,7 @@ func (s *LeaveManagementService) CreateLeave(organizationID, userID string, req
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "employee_id not found")
 	}
 
-	subExists, err := s.repo.EmployeeUUIDExists(organizationID, substituteID)
+	subExists, err := s.repo.EmployeeUUIDExists(ctx, substituteID)
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate substitute_id")
 	}
@@ -95,7 +96,7 @@ func (s *LeaveManagementService) CreateLeave(organizationID, userID string, req
 	}
 
 	leaveID := uuid.New().String()
-	if err := s.repo.CreateEmployeeLeave(leaveID, organizationID, employeeID, substituteID, startDate, endDate, req.LeaveType, time.Now(), userID); err != nil {
+	if err := s.repo.CreateEmployeeLeave(ctx, leaveID, employeeID, substituteID, startDate, endDate, req.LeaveType, time.Now(), userID); err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create leave")
 	}
 	return leaveID, nil
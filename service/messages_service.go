@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"service-travego/configs"
@@ -18,8 +19,8 @@ func NewMessagesService(repo *repository.MessagesRepository) *MessagesService {
 	return &MessagesService{repo: repo}
 }
 
-func (s *MessagesService) SubmitMessage(orgID string, req *model.MessageSubmitRequest) (string, error) {
-	if orgID == "" {
+func (s *MessagesService) SubmitMessage(ctx context.Context, req *model.MessageSubmitRequest) (string, error) {
+	if organizationFromContext(ctx) == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
 	if req == nil {
@@ -37,17 +38,17 @@ func (s *MessagesService) SubmitMessage(orgID string, req *model.MessageSubmitRe
 	}
 
 	messageID := helper.GenerateUUID()
-	if err := s.repo.CreateMessage(orgID, messageID, req); err != nil {
+	if err := s.repo.CreateMessage(ctx, messageID, req); err != nil {
 		return "", err
 	}
 	return messageID, nil
 }
 
-func (s *MessagesService) ListMessages(orgID string) ([]model.MessageListItem, error) {
-	if orgID == "" {
+func (s *MessagesService) ListMessages(ctx context.Context) ([]model.MessageListItem, error) {
+	if organizationFromContext(ctx) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
-	items, err := s.repo.ListMessages(orgID)
+	items, err := s.repo.ListMessages(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,14 +58,14 @@ func (s *MessagesService) ListMessages(orgID string) ([]model.MessageListItem, e
 	return items, nil
 }
 
-func (s *MessagesService) ReadMessage(orgID, messageID string) error {
-	if orgID == "" {
+func (s *MessagesService) ReadMessage(ctx context.Context, messageID string) error {
+	if organizationFromContext(ctx) == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
 	if strings.TrimSpace(messageID) == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "message_id is required")
 	}
-	if err := s.repo.MarkMessageRead(orgID, messageID); err != nil {
+	if err := s.repo.MarkMessageRead(ctx, messageID); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, http.StatusNotFound, "message not found")
 		}
@@ -1,11 +1,11 @@
 package service
 
 import (
-	"database/sql"
-	"fmt"
+	"context"
 	"net/http"
-	"service-travego/database"
 	"service-travego/helper"
+	"service-travego/model"
+	"service-travego/repository"
 	"strings"
 	"time"
 )
@@ -16,155 +16,67 @@ type NotificationPayload struct {
 	URL     string `json:"url"`
 }
 
-type NotificationItem struct {
-	NotificationID string    `json:"notification_id"`
-	ReferenceURL   string    `json:"reference_url"`
-	Title          string    `json:"title"`
-	Message        string    `json:"message"`
-	CreatedAt      time.Time `json:"created_at"`
-	IsRead         bool      `json:"is_read"`
-}
-
 type NotificationService struct {
-	db     *sql.DB
-	driver string
+	repo *repository.NotificationRepository
 }
 
-func NewNotificationService(db *sql.DB, driver string) *NotificationService {
-	return &NotificationService{
-		db:     db,
-		driver: driver,
-	}
+func NewNotificationService(repo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
 }
 
-func (s *NotificationService) CreateNotification(orgID string, payload NotificationPayload) (string, error) {
-	orgID = strings.TrimSpace(orgID)
+// CreateNotification notifies the organization of ctx
+func (s *NotificationService) CreateNotification(ctx context.Context, payload NotificationPayload) (string, error) {
 	payload.Title = strings.TrimSpace(payload.Title)
 	payload.Message = strings.TrimSpace(payload.Message)
 	payload.URL = strings.TrimSpace(payload.URL)
 
-	if orgID == "" {
+	if organizationFromContext(ctx) == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
 	if payload.Title == "" || payload.Message == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "title and message are required")
 	}
 
-	notificationID := helper.GenerateUUID()
-	query := fmt.Sprintf(
-		`INSERT INTO notifications
-			(notification_id, organization_id, reference_url, title, message, created_at, is_read)
-		 VALUES
-			(%s, %s, %s, %s, %s, %s, %s)`,
-		s.getPlaceholder(1),
-		s.getPlaceholder(2),
-		s.getPlaceholder(3),
-		s.getPlaceholder(4),
-		s.getPlaceholder(5),
-		s.getPlaceholder(6),
-		s.getPlaceholder(7),
-	)
-
-	if _, err := database.Exec(
-		s.db,
-		query,
-		notificationID,
-		orgID,
-		payload.URL,
-		payload.Title,
-		payload.Message,
-		time.Now(),
-		false,
-	); err != nil {
+	notification := &model.Notification{
+		NotificationID: helper.GenerateUUID(),
+		ReferenceURL:   payload.URL,
+		Title:          payload.Title,
+		Message:        payload.Message,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.repo.Create(ctx, notification); err != nil {
 		return "", err
 	}
 
-	return notificationID, nil
+	return notification.NotificationID, nil
 }
 
-func (s *NotificationService) GetNotifications(orgID string) ([]NotificationItem, error) {
-	orgID = strings.TrimSpace(orgID)
-	if orgID == "" {
+// GetNotifications lists the latest notifications of the organization of ctx
+func (s *NotificationService) GetNotifications(ctx context.Context) ([]model.Notification, error) {
+	if organizationFromContext(ctx) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
-
-	query := fmt.Sprintf(
-		`SELECT notification_id, reference_url, title, message, created_at, is_read
-		 FROM notifications
-		 WHERE organization_id = %s
-		 ORDER BY created_at DESC
-		 LIMIT 50`,
-		s.getPlaceholder(1),
-	)
-
-	rows, err := database.Query(s.db, query, orgID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	items := make([]NotificationItem, 0)
-	for rows.Next() {
-		var item NotificationItem
-		if err := rows.Scan(
-			&item.NotificationID,
-			&item.ReferenceURL,
-			&item.Title,
-			&item.Message,
-			&item.CreatedAt,
-			&item.IsRead,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, item)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return items, nil
+	return s.repo.List(ctx, 50)
 }
 
-func (s *NotificationService) MarkAsRead(orgID, notificationID string) error {
-	orgID = strings.TrimSpace(orgID)
+// MarkAsRead marks a notification of the organization of ctx as read
+func (s *NotificationService) MarkAsRead(ctx context.Context, notificationID string) error {
 	notificationID = strings.TrimSpace(notificationID)
 
-	if orgID == "" {
+	if organizationFromContext(ctx) == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "missing organization context")
 	}
 	if notificationID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "notification_id is required")
 	}
 
-	query := fmt.Sprintf(
-		`UPDATE notifications
-		 SET is_read = %s
-		 WHERE notification_id = %s AND organization_id = %s`,
-		s.getPlaceholder(1),
-		s.getPlaceholder(2),
-		s.getPlaceholder(3),
-	)
-
-	result, err := database.Exec(s.db, query, true, notificationID, orgID)
+	found, err := s.repo.MarkAsRead(ctx, notificationID)
 	if err != nil {
 		return err
 	}
-
-	affected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if affected == 0 {
+	if !found {
 		return NewServiceError(ErrNotFound, http.StatusNotFound, "notification not found")
 	}
 
 	return nil
 }
-
-func (s *NotificationService) getPlaceholder(pos int) string {
-	if s.driver == "postgres" || s.driver == "pgx" {
-		return fmt.Sprintf("$%d", pos)
-	}
-	return "?"
-}
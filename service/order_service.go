@@ -1,17 +1,18 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"math/rand"
 	"net/http"
 	"os"
 	"service-travego/configs"
 	"service-travego/helper"
-	"service-travego/internal/wagy"
+	"service-travego/internal/paymentgateway"
 	"service-travego/model"
 	"service-travego/repository"
 	"service-travego/utils"
@@ -27,9 +28,16 @@ type OrderService struct {
 	contentRepo         *repository.ContentRepository
 	orgRepo             *repository.OrganizationRepository
 	emailCfg            *configs.EmailConfig
+	priceRuleService    *PriceRuleService
+	voucherService      *VoucherService
+	paymentPlanService  *PaymentPlanService
+	cancellationService *CancellationPolicyService
+	paymentRepo         repository.PaymentRepository
+	gateways            *paymentgateway.Registry
 	citiesName          map[string]string
 	paymentTypeLabels   map[int]string
 	paymentMethodLabels map[int]string
+	outbox              *OutboxService
 }
 
 func NewOrderService(fleetRepo *repository.FleetRepository, contentRepo *repository.ContentRepository, orgRepo *repository.OrganizationRepository, emailCfg *configs.EmailConfig) *OrderService {
@@ -41,14 +49,49 @@ func NewOrderService(fleetRepo *repository.FleetRepository, contentRepo *reposit
 	}
 }
 
-func (s *OrderService) GetFleetOrderItemTotals(orderID, orgID string) (float64, float64, float64, float64, error) {
-	return s.fleetRepo.GetFleetOrderItemTotals(orderID, orgID)
+// SetPriceRuleService enables dynamic pricing rules on order summary and creation.
+func (s *OrderService) SetPriceRuleService(priceRuleService *PriceRuleService) {
+	s.priceRuleService = priceRuleService
 }
 
-func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.CreateOrderResponse, error) {
+// SetVoucherService enables voucher codes on order summary and creation.
+func (s *OrderService) SetVoucherService(voucherService *VoucherService) {
+	s.voucherService = voucherService
+}
+
+// SetPaymentPlanService enables installment schedules on order creation and
+// installment payments on CreateOrderPayment.
+func (s *OrderService) SetPaymentPlanService(paymentPlanService *PaymentPlanService) {
+	s.paymentPlanService = paymentPlanService
+}
+
+// SetPaymentGateways sets the payment gateways and the payment repository used
+// to issue an invoice per installment.
+func (s *OrderService) SetPaymentGateways(gateways *paymentgateway.Registry, paymentRepo repository.PaymentRepository) {
+	s.gateways = gateways
+	s.paymentRepo = paymentRepo
+}
+
+// SetCancellationPolicyService lets customers preview the refund and request a
+// cancellation of their order.
+func (s *OrderService) SetCancellationPolicyService(cancellationService *CancellationPolicyService) {
+	s.cancellationService = cancellationService
+}
+
+// SetOutboxService sets the outbox the order emails and WhatsApp
+// notifications are queued in.
+func (s *OrderService) SetOutboxService(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
+func (s *OrderService) GetFleetOrderItemTotals(ctx context.Context, orderID string) (float64, float64, float64, float64, error) {
+	return s.fleetRepo.GetFleetOrderItemTotals(ctx, orderID)
+}
+
+func (s *OrderService) CreateOrder(ctx context.Context, req *model.CreateOrderRequest) (*model.CreateOrderResponse, error) {
 	// 1. Calculate Total Amount
 	// Get Fleet Price
-	price, rentType, err := s.fleetRepo.GetPriceByID(req.PriceID)
+	price, rentType, err := s.fleetRepo.GetPriceByID(ctx, req.PriceID)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "price not found")
 	}
@@ -62,26 +105,82 @@ func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.Create
 	}
 
 	// Get Addon Price Sum
-	addonTotal, err := s.fleetRepo.GetAddonPriceSum(req.Addons)
+	addonTotal, err := s.fleetRepo.GetAddonPriceSum(ctx, req.Addons)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to calc addons")
 	}
 
-	// Formula: unit_qty * (price + total_addon_price)
-	totalAmount := float64(req.Qty) * (price + addonTotal)
+	// Apply pricing rules (season, weekend, lead time, pickup city) on the unit price
+	var pricing *model.PriceQuote
+	if s.priceRuleService != nil {
+		startDate, _ := parsePricingDate(req.StartDate)
+		endDate, _ := parsePricingDate(req.EndDate)
+		pricing, err = s.priceRuleService.Quote(ctx, model.PriceQuoteInput{
+			FleetID:      req.FleetID,
+			PriceID:      req.PriceID,
+			RentType:     rentType,
+			BasePrice:    price,
+			Qty:          req.Qty,
+			StartDate:    startDate,
+			EndDate:      endDate,
+			PickupCityID: req.PickupCityID,
+			OrderedAt:    time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		req.RuleAdjustment = pricing.UnitAdjustment
+		req.PriceRuleLines = pricing.Lines
+	}
+
+	// Formula: unit_qty * (price + rule_adjustment + total_addon_price)
+	totalAmount := float64(req.Qty) * (price + req.RuleAdjustment + addonTotal)
 	if req.AdditionalAmount > 0 {
 		totalAmount += req.AdditionalAmount
 	}
 
-	// 2. Generate Order ID
-	// {orgcode}{YYDDMMhh:mm}{count}-FRT
-
 	if req.OrganizationID == "" {
 		return nil, NewServiceError(ErrInternalServer, http.StatusBadRequest, "organization_id is missing")
 	}
 
+	// Apply the voucher on the order total
+	if s.voucherService != nil && strings.TrimSpace(req.VoucherCode) != "" {
+		customerID, err := s.voucherService.FindCustomerID(ctx, req.Phone, req.Email)
+		if err != nil {
+			return nil, err
+		}
+		req.Voucher, err = s.voucherService.Apply(ctx, model.VoucherApplyInput{
+			Code:       req.VoucherCode,
+			OrderType:  model.VoucherOrderFleet,
+			ProductID:  req.FleetID,
+			CustomerID: customerID,
+			Amount:     totalAmount,
+			At:         time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if req.Voucher != nil {
+			totalAmount = req.Voucher.TotalAmount
+		}
+	}
+
+	// Split the total over the payment plan picked on the order, or the
+	// organization's default plan
+	if s.paymentPlanService != nil {
+		plan, err := s.paymentPlanService.ResolvePlan(ctx, req.PaymentPlanID)
+		if err != nil {
+			return nil, err
+		}
+		startDate, _ := parsePricingDate(req.StartDate)
+		req.Installments = BuildInstallments(plan, totalAmount, time.Now(), startDate)
+	}
+
+	// 2. Generate Order ID
+	// {orgcode}{YYDDMMhh:mm}{count}-FRT
+
 	// Get Order Count
-	count, err := s.fleetRepo.GetOrderCountByOrgID(req.OrganizationID)
+	count, err := s.fleetRepo.GetOrderCountByOrgID(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, fmt.Sprintf("failed to get order count: %v", err))
 	}
@@ -91,9 +190,12 @@ func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.Create
 	// 3. Save to DB
 	req.OrderID = orderID
 	req.TotalAmount = totalAmount
-	err = s.fleetRepo.CreateOrder(req)
+	err = s.fleetRepo.CreateOrder(ctx, req)
 	if err != nil {
-		fmt.Printf("---- CreateOrder failed: %v\n", err)
+		slog.ErrorContext(ctx, "create order failed", "error", err)
+		if verr := voucherUsageError(err); verr != nil {
+			return nil, verr
+		}
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create order")
 	}
 
@@ -110,10 +212,10 @@ func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.Create
 
 	// 4. Send Email Notification
 	// Fetch fleet details for email
-	fleetSummary, err := s.fleetRepo.GetFleetOrderSummary(req.FleetID, req.PriceID)
+	fleetSummary, err := s.fleetRepo.GetFleetOrderSummary(ctx, req.FleetID, req.PriceID)
 	if err != nil {
 		// Log error but don't fail the order
-		log.Printf("[WARN] Failed to get fleet summary for email: %v", err)
+		slog.WarnContext(ctx, "failed to get fleet summary for email", "error", err)
 	} else {
 		// Construct email data
 		facilities := strings.Join(fleetSummary.Facilities, ", ")
@@ -128,26 +230,26 @@ func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.Create
 		var orgLogo, brandName, companyName string
 
 		// Logo
-		if content, err := s.contentRepo.FindByTagAndOrgID("brand-logo", req.OrganizationID); err == nil && content != nil {
+		if content, err := s.contentRepo.FindByTag(ctx, "brand-logo"); err == nil && content != nil {
 			orgLogo = content.Content
 		}
 
 		// Brand Name
-		if content, err := s.contentRepo.FindByTagAndOrgID("brand-name", req.OrganizationID); err == nil && content != nil {
+		if content, err := s.contentRepo.FindByTag(ctx, "brand-name"); err == nil && content != nil {
 			brandName = content.Content
 		}
 
 		// Company Name
-		if content, err := s.contentRepo.FindByTagAndOrgID("company-name", req.OrganizationID); err == nil && content != nil {
+		if content, err := s.contentRepo.FindByTag(ctx, "company-name"); err == nil && content != nil {
 			companyName = content.Content
 		}
 
 		// Contact List
-		contactList, _ := s.contentRepo.GetContentListByTag("contact", req.OrganizationID)
+		contactList, _ := s.contentRepo.GetContentListByTag(ctx, "contact")
 
-		orgEmail, orgName, domainURL, oerr := s.orgRepo.GetOrganizationEmailAndName(req.OrganizationID)
+		orgEmail, orgName, domainURL, oerr := s.orgRepo.GetOrganizationEmailAndName(ctx)
 		if oerr != nil {
-			log.Printf("[WARN] Failed to get organization email/name for org %s: %v", req.OrganizationID, oerr)
+			slog.WarnContext(ctx, "failed to get organization email/name", "organization_id", req.OrganizationID, "error", oerr)
 		}
 
 		baseCustomerURL := strings.TrimSuffix(strings.TrimSpace(domainURL), "/")
@@ -180,12 +282,13 @@ func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.Create
 			OrderDetailUrl:   orderDetailUrl,
 		}
 
-		// Send email asynchronously
-		go func() {
-			if err := helper.SendOrderSuccessEmail(s.emailCfg, req.Email, emailData); err != nil {
-				log.Printf("[ERROR] Failed to send order success email to %s: %v", req.Email, err)
-			}
-		}()
+		customerEmail, err := helper.NewOrderSuccessEmail(req.Email, emailData)
+		if err == nil {
+			err = s.outbox.EnqueueEmail(ctx, req.OrganizationID, OutboxKindOrderSuccess, customerEmail)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to queue order success email", "email", req.Email, "error", err)
+		}
 
 		if oerr == nil && strings.TrimSpace(orgEmail) != "" {
 			orgEmailData := helper.OrderReceivedEmailData{
@@ -198,51 +301,50 @@ func (s *OrderService) CreateOrder(req *model.CreateOrderRequest) (*model.Create
 				DashboardOrderDetailUrl: dashboardOrderDetailUrl,
 			}
 
-			go func() {
-				if err := helper.SendOrderReceivedEmail(s.emailCfg, orgEmail, orgEmailData); err != nil {
-					log.Printf("[ERROR] Failed to send order received email to organization %s: %v", orgEmail, err)
-				}
-			}()
+			receivedEmail, err := helper.NewOrderReceivedEmail(orgEmail, orgEmailData)
+			if err == nil {
+				err = s.outbox.EnqueueEmail(ctx, req.OrganizationID, OutboxKindOrderReceived, receivedEmail)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to queue order received email", "org_email", orgEmail, "error", err)
+			}
 		}
 	}
 
-	adminAccountNumber, err := s.orgRepo.GetAdminAccountNumber(req.OrganizationID)
+	adminAccountNumber, err := s.orgRepo.GetAdminAccountNumber(ctx)
 	if err != nil {
-		log.Printf("[WARN] Failed to get admin account number for org %s: %v", req.OrganizationID, err)
+		slog.WarnContext(ctx, "failed to get admin account number", "organization_id", req.OrganizationID, "error", err)
 	} else if strings.TrimSpace(adminAccountNumber) != "" {
-		wagyDeviceID := strings.TrimSpace(os.Getenv("WAGY_DEVICE_ID"))
-		wagyToken := strings.TrimSpace(os.Getenv("WAGY_TOKEN"))
-		if wagyDeviceID == "" || wagyToken == "" {
-			log.Printf("[WARN] WAGY_DEVICE_ID or WAGY_TOKEN is empty, skip order WhatsApp notification for order %s", orderID)
-		} else {
-			waClient := wagy.NewWagyClient(wagyDeviceID, wagyToken)
-			message := fmt.Sprintf(
-				"Pesanan baru berhasil dibuat.\n\nOrder ID: %s\nNama Customer: %s\nNo. HP: %s\nTanggal Sewa: %s s/d %s\nPickup: %s",
-				orderID,
-				req.Fullname,
-				req.Phone,
-				req.StartDate,
-				req.EndDate,
-				req.PickupLocation,
-			)
-			if _, err := waClient.SendMessage(NormalizeAssistantAccountNumber(adminAccountNumber), message); err != nil {
-				log.Printf("[WARN] Failed to send order WhatsApp notification to %s for order %s: %v", adminAccountNumber, orderID, err)
-			}
+		message := fmt.Sprintf(
+			"Pesanan baru berhasil dibuat.\n\nOrder ID: %s\nNama Customer: %s\nNo. HP: %s\nTanggal Sewa: %s s/d %s\nPickup: %s",
+			orderID,
+			req.Fullname,
+			req.Phone,
+			req.StartDate,
+			req.EndDate,
+			req.PickupLocation,
+		)
+		if err := s.outbox.EnqueueWhatsApp(ctx, req.OrganizationID, OutboxKindOrderAdminNotice, NormalizeAssistantAccountNumber(adminAccountNumber), message); err != nil {
+			slog.WarnContext(ctx, "failed to queue order WhatsApp notification", "admin_account_number", adminAccountNumber, "order_id", orderID, "error", err)
 		}
 	}
 
 	return &model.CreateOrderResponse{
 		Token:   token,
 		OrderID: orderID,
+		Pricing: pricing,
+		Voucher: req.Voucher,
+
+		Installments: req.Installments,
 	}, nil
 }
 
-func (s *OrderService) GetPaymentMethods(organizationID string) (*model.PaymentMethodGroupedResponse, error) {
-	return s.orgRepo.GetPaymentMethods(organizationID)
+func (s *OrderService) GetPaymentMethods(ctx context.Context) (*model.PaymentMethodGroupedResponse, error) {
+	return s.orgRepo.GetPaymentMethods(ctx)
 }
 
-func (s *OrderService) GetFleetOrderSummary(req *model.OrderFleetSummaryRequest) (*model.OrderFleetSummaryResponse, error) {
-	res, err := s.fleetRepo.GetFleetOrderSummary(req.FleetID, req.PriceID)
+func (s *OrderService) GetFleetOrderSummary(ctx context.Context, req *model.OrderFleetSummaryRequest) (*model.OrderFleetSummaryResponse, error) {
+	res, err := s.fleetRepo.GetFleetOrderSummary(ctx, req.FleetID, req.PriceID)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "fleet or price not found")
 	}
@@ -265,6 +367,55 @@ func (s *OrderService) GetFleetOrderSummary(req *model.OrderFleetSummaryRequest)
 		}
 	}
 
+	if s.priceRuleService != nil && strings.TrimSpace(req.OrganizationID) != "" {
+		startDate, _ := parsePricingDate(req.StartDate)
+		endDate, _ := parsePricingDate(req.EndDate)
+		pricing, err := s.priceRuleService.Quote(ctx, model.PriceQuoteInput{
+			FleetID:      req.FleetID,
+			PriceID:      req.PriceID,
+			RentType:     res.RentType,
+			BasePrice:    res.Price,
+			Qty:          req.Qty,
+			StartDate:    startDate,
+			EndDate:      endDate,
+			PickupCityID: req.PickupCityID,
+			OrderedAt:    time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		res.Pricing = pricing
+	}
+
+	if s.voucherService != nil && strings.TrimSpace(req.OrganizationID) != "" && strings.TrimSpace(req.VoucherCode) != "" {
+		qty := req.Qty
+		if qty < 1 {
+			qty = 1
+		}
+		amount := res.Price * float64(qty)
+		if res.Pricing != nil {
+			amount = res.Pricing.Total
+		}
+		customerID := ""
+		if strings.TrimSpace(req.Phone) != "" {
+			customerID, err = s.voucherService.FindCustomerID(ctx, req.Phone, req.Email)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res.Voucher, err = s.voucherService.Apply(ctx, model.VoucherApplyInput{
+			Code:       req.VoucherCode,
+			OrderType:  model.VoucherOrderFleet,
+			ProductID:  req.FleetID,
+			CustomerID: customerID,
+			Amount:     amount,
+			At:         time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return res, nil
 }
 
@@ -322,8 +473,8 @@ func (s *OrderService) ensurePaymentCommonLoaded() {
 	s.paymentMethodLabels = pm
 }
 
-func (s *OrderService) GetOrderList(req *model.GetOrderListRequest) (*model.GetOrderListResponse, error) {
-	data, total, err := s.fleetRepo.GetOrderList(req)
+func (s *OrderService) GetOrderList(ctx context.Context, req *model.GetOrderListRequest) (*model.GetOrderListResponse, error) {
+	data, total, err := s.fleetRepo.GetOrderList(ctx, req)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch orders")
 	}
@@ -346,7 +497,7 @@ func (s *OrderService) GetOrderList(req *model.GetOrderListRequest) (*model.GetO
 	}, nil
 }
 
-func (s *OrderService) GetOrderDetail(encryptedOrderID, organizationID string) (*model.OrderDetailResponse, error) {
+func (s *OrderService) GetOrderDetail(ctx context.Context, encryptedOrderID string) (*model.OrderDetailResponse, error) {
 	// Decrypt Order ID
 	decrypted, err := helper.DecryptString(encryptedOrderID)
 	if err != nil {
@@ -365,26 +516,32 @@ func (s *OrderService) GetOrderDetail(encryptedOrderID, organizationID string) (
 		orderID = decrypted
 	}
 
-	res, err := s.fleetRepo.GetOrderDetail(orderID, priceID, organizationID)
+	res, err := s.fleetRepo.GetOrderDetail(ctx, orderID, priceID)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusBadRequest, "order not found")
 	}
 
 	s.mapOrderDetailLabels(res)
 
+	if s.paymentPlanService != nil {
+		if items, err := s.paymentPlanService.OrderInstallments(ctx, orderID); err == nil && len(items) > 0 {
+			res.Installments = items
+		}
+	}
+
 	return res, nil
 }
 
-func (s *OrderService) GetOrderReviews(orderID, organizationID string) ([]model.OrderReviewItem, error) {
-	items, err := s.fleetRepo.GetOrderReviews(orderID, organizationID)
+func (s *OrderService) GetOrderReviews(ctx context.Context, orderID string) ([]model.OrderReviewItem, error) {
+	items, err := s.fleetRepo.GetOrderReviews(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load reviews")
 	}
 	return items, nil
 }
 
-func (s *OrderService) GetOrderRatingSummary(orderID, organizationID string) (*model.OrderRatingSummary, error) {
-	rating, err := s.fleetRepo.GetOrderRatingSummary(orderID, organizationID)
+func (s *OrderService) GetOrderRatingSummary(ctx context.Context, orderID string) (*model.OrderRatingSummary, error) {
+	rating, err := s.fleetRepo.GetOrderRatingSummary(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load rating")
 	}
@@ -418,13 +575,13 @@ func (s *OrderService) mapOrderDetailLabels(res *model.OrderDetailResponse) {
 	}
 }
 
-func (s *OrderService) GetFleetOrderDetailByPrefix(orderIDPrefix, organizationID string) (*model.FleetOrderDetailByPrefixResponse, error) {
+func (s *OrderService) GetFleetOrderDetailByPrefix(ctx context.Context, orderIDPrefix string) (*model.FleetOrderDetailByPrefixResponse, error) {
 	orderIDPrefix = strings.TrimSpace(orderIDPrefix)
 	if orderIDPrefix == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
 
-	fullOrderID, err := s.fleetRepo.FindFleetOrderIDByPrefix(orderIDPrefix, organizationID)
+	fullOrderID, err := s.fleetRepo.FindFleetOrderIDByPrefix(ctx, orderIDPrefix)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusBadRequest, "order not found")
@@ -435,16 +592,16 @@ func (s *OrderService) GetFleetOrderDetailByPrefix(orderIDPrefix, organizationID
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to find order")
 	}
 
-	res, err := s.fleetRepo.FindOrderDetail(fullOrderID, organizationID)
+	res, err := s.fleetRepo.FindOrderDetail(ctx, fullOrderID)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusBadRequest, "order not found")
 	}
 
 	s.mapOrderDetailLabels(res)
 
-	paymentRows, err := s.fleetRepo.ListFleetOrderPaymentHistory(fullOrderID, organizationID)
+	paymentRows, err := s.fleetRepo.ListFleetOrderPaymentHistory(ctx, fullOrderID)
 	if err != nil {
-		fmt.Println("err ---> ", err)
+		slog.ErrorContext(ctx, "list fleet order payment history failed", "order_id", fullOrderID, "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get payment history")
 	}
 
@@ -494,8 +651,8 @@ func (s *OrderService) GetFleetOrderDetailByPrefix(orderIDPrefix, organizationID
 	}, nil
 }
 
-func (s *OrderService) FindOrderDetail(orderID, organizationID string) (*model.OrderDetailResponse, error) {
-	res, err := s.fleetRepo.FindOrderDetail(orderID, organizationID)
+func (s *OrderService) FindOrderDetail(ctx context.Context, orderID string) (*model.OrderDetailResponse, error) {
+	res, err := s.fleetRepo.FindOrderDetail(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusBadRequest, "order not found")
 	}
@@ -512,7 +669,7 @@ func (s *OrderService) FindOrderDetail(orderID, organizationID string) (*model.O
 	return res, nil
 }
 
-func (s *OrderService) CreateOrderPayment(req *model.CreatePaymentRequest) (*model.FleetOrderPayment, error) {
+func (s *OrderService) CreateOrderPayment(ctx context.Context, req *model.CreatePaymentRequest) (*model.FleetOrderPayment, error) {
 	// 1. Decrypt Token
 	decrypted, err := helper.DecryptString(req.Token)
 	if err != nil {
@@ -530,11 +687,16 @@ func (s *OrderService) CreateOrderPayment(req *model.CreatePaymentRequest) (*mod
 		orderID = decrypted
 	}
 
+	// Installments of a payment plan are paid through Midtrans
+	if strings.TrimSpace(req.InstallmentID) != "" {
+		return s.createInstallmentPayment(ctx, orderID, req)
+	}
+
 	// 2. Get Order Total Amount
-	totalAmount, err := s.fleetRepo.GetFleetOrderTotalAmount(orderID, priceID, req.OrganizationID)
+	totalAmount, err := s.fleetRepo.GetFleetOrderTotalAmount(ctx, orderID, priceID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			fmt.Println("Error: order not found or invalid organization:", err)
+			slog.ErrorContext(ctx, "order not found or invalid organization", "error", err)
 			return nil, NewServiceError(ErrNotFound, http.StatusBadRequest, "order not found or invalid organization")
 		}
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order amount")
@@ -544,7 +706,7 @@ func (s *OrderService) CreateOrderPayment(req *model.CreatePaymentRequest) (*mod
 	var paymentAmount, remaining float64
 
 	// Check existing payments
-	existingPayments, err := s.fleetRepo.GetFleetOrderPaymentsByOrderID(orderID, req.OrganizationID)
+	existingPayments, err := s.fleetRepo.GetFleetOrderPaymentsByOrderID(ctx, orderID)
 	if err == nil && len(existingPayments) > 0 {
 		var totalPaid float64
 		var existingType2Percent float64
@@ -630,7 +792,7 @@ func (s *OrderService) CreateOrderPayment(req *model.CreatePaymentRequest) (*mod
 	}
 
 	// Fetch Bank Account details
-	bankAccount, err := s.orgRepo.GetBankAccountByID(req.PaymentMethod, req.OrganizationID)
+	bankAccount, err := s.orgRepo.GetBankAccountByID(ctx, req.PaymentMethod)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusBadRequest, "payment method (bank account) not found")
@@ -675,15 +837,155 @@ func (s *OrderService) CreateOrderPayment(req *model.CreatePaymentRequest) (*mod
 	}
 
 	// 6. Save
-	if err := s.fleetRepo.CreateOrderPayment(payment, paymentHistory); err != nil {
-		fmt.Println("Error: failed to create payment record:", err)
+	if err := s.fleetRepo.CreateOrderPayment(ctx, payment, paymentHistory); err != nil {
+		slog.ErrorContext(ctx, "failed to create payment record", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create payment record")
+	}
+
+	return payment, nil
+}
+
+// createInstallmentPayment issues an invoice on the organization's payment
+// gateway for one installment of the order. A link issued less than a day ago is handed out again instead of
+// opening a second invoice for the same installment.
+func (s *OrderService) createInstallmentPayment(ctx context.Context, orderID string, req *model.CreatePaymentRequest) (*model.FleetOrderPayment, error) {
+	if s.paymentPlanService == nil || s.gateways == nil || s.paymentRepo == nil {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "installment payment is not available")
+	}
+
+	installments, err := s.paymentPlanService.OrderInstallments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var inst *model.OrderInstallment
+	var totalAmount, paidAmount float64
+	for i := range installments {
+		totalAmount += installments[i].Amount
+		if installments[i].Status == model.InstallmentStatusPaid {
+			paidAmount += installments[i].Amount
+		}
+		if installments[i].InstallmentID == strings.TrimSpace(req.InstallmentID) {
+			inst = &installments[i]
+		}
+	}
+	if inst == nil {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "installment not found")
+	}
+	if inst.Status == model.InstallmentStatusPaid {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "Pembayaran anda telah berhasil")
+	}
+
+	remaining := totalAmount - paidAmount - inst.Amount
+	if remaining < 0 {
+		remaining = 0
+	}
+	paymentType := 2
+	if inst.Percentage >= 100 {
+		paymentType = 1
+	}
+	now := time.Now()
+	payment := &model.FleetOrderPayment{
+		OrderPaymentID:    uuid.New().String(),
+		OrderID:           orderID,
+		OrganizationID:    req.OrganizationID,
+		PaymentType:       paymentType,
+		PaymentPercentage: inst.Percentage,
+		PaymentAmount:     inst.Amount,
+		TotalAmount:       totalAmount,
+		PaymentRemaining:  remaining,
+		Status:            model.PaymentStatusPendingVerification,
+		CreatedAt:         now,
+		InstallmentID:     inst.InstallmentID,
+	}
+
+	if inst.Status == model.InstallmentStatusPending && inst.SnapToken != "" {
+		if linkAt, err := time.ParseInLocation("2006-01-02 15:04:05", inst.LinkCreatedAt, time.Local); err == nil && now.Sub(linkAt) < 24*time.Hour {
+			payment.CreatedAt = linkAt
+			payment.InvoiceNumber = inst.InvoiceNumber
+			payment.SnapToken = inst.SnapToken
+			payment.RedirectURL = inst.RedirectURL
+			payment.PaymentGateway = inst.PaymentGateway
+			return payment, nil
+		}
+	}
+
+	gateway, err := gatewayForOrganization(ctx, s.gateways, s.orgRepo)
+	if err != nil {
+		slog.ErrorContext(ctx, "payment gateway", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "payment gateway is not available")
+	}
+
+	invoiceNumber, err := s.paymentRepo.GetNextInvoiceNumber(ctx, model.InstallmentOrderFleet)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate invoice number", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate invoice number")
+	}
+	if err := s.paymentRepo.InsertPaymentOrder(ctx, payment.OrderPaymentID, model.InstallmentOrderFleet, orderID, paymentType, 1004, invoiceNumber, gateway.Provider(), now.Format("2006-01-02 15:04:05"), ""); err != nil {
+		slog.ErrorContext(ctx, "failed to insert payment order", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create payment record")
 	}
 
+	charge, err := gateway.CreateCharge(paymentgateway.ChargeRequest{
+		InvoiceNumber: invoiceNumber,
+		Amount:        int64(math.Round(inst.Amount)),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "payment gateway error", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "payment gateway error")
+	}
+
+	if err := s.paymentPlanService.SetPaymentLink(ctx, inst.InstallmentID, invoiceNumber, charge.Provider, charge.Token, charge.RedirectURL, now); err != nil {
+		return nil, err
+	}
+
+	payment.InvoiceNumber = invoiceNumber
+	payment.SnapToken = charge.Token
+	payment.RedirectURL = charge.RedirectURL
+	payment.PaymentGateway = charge.Provider
 	return payment, nil
 }
 
-func (s *OrderService) ConfirmPayment(req *model.PaymentConfirmationRequest) error {
+// orderIDFromToken returns the order ID of an encrypted order token.
+func orderIDFromToken(token string) (string, error) {
+	decrypted, err := helper.DecryptString(token)
+	if err != nil {
+		return "", NewServiceError(ErrNotFound, http.StatusBadRequest, "invalid order token")
+	}
+	var payload model.OrderTokenPayload
+	if err := json.Unmarshal([]byte(decrypted), &payload); err == nil && payload.OrderID != "" {
+		return payload.OrderID, nil
+	}
+	return decrypted, nil
+}
+
+// GetCancellationQuote previews the refund the customer gets when the order is
+// cancelled now.
+func (s *OrderService) GetCancellationQuote(ctx context.Context, token string) (*model.CancellationRefundQuote, error) {
+	if s.cancellationService == nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "cancellation policy service is not configured")
+	}
+	orderID, err := orderIDFromToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.cancellationService.Quote(ctx, orderID, time.Now())
+}
+
+// RequestCancellation records the customer's cancellation request for staff approval.
+func (s *OrderService) RequestCancellation(ctx context.Context, req *model.OrderCancellationSubmitRequest) (*model.OrderCancellationRequest, error) {
+	if s.cancellationService == nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "cancellation policy service is not configured")
+	}
+	orderID, err := orderIDFromToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	req.OrderID = orderID
+	req.Source = model.CancellationSourceWeb
+	return s.cancellationService.SubmitRequest(ctx, req)
+}
+
+func (s *OrderService) ConfirmPayment(ctx context.Context, req *model.PaymentConfirmationRequest) error {
 	// 1. Decrypt Token
 	decrypted, err := helper.DecryptString(req.Token)
 	if err != nil {
@@ -700,9 +1002,9 @@ func (s *OrderService) ConfirmPayment(req *model.PaymentConfirmationRequest) err
 
 	if req.OrderType == "fleet" {
 		// Update status from 2 (PendingVerification) to 10 (WaitingApproval)
-		err = s.fleetRepo.UpdateFleetOrderPaymentStatus(orderID, req.OrganizationID, int(model.PaymentStatusPendingVerification), int(model.PaymentStatusWaitingApproval))
+		err = s.fleetRepo.UpdateFleetOrderPaymentStatus(ctx, orderID, int(model.PaymentStatusPendingVerification), int(model.PaymentStatusWaitingApproval))
 		if err != nil {
-			fmt.Println("Error updating fleet payment status:", err)
+			slog.ErrorContext(ctx, "error updating fleet payment status", "error", err)
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update payment status")
 		}
 	} else {
@@ -712,11 +1014,11 @@ func (s *OrderService) ConfirmPayment(req *model.PaymentConfirmationRequest) err
 	return nil
 }
 
-func (s *OrderService) UploadPaymentEvidence(orderID, organizationID, filePath string) error {
-	return s.fleetRepo.UpdatePaymentEvidence(orderID, organizationID, filePath)
+func (s *OrderService) UploadPaymentEvidence(ctx context.Context, orderID, filePath string) error {
+	return s.fleetRepo.UpdatePaymentEvidence(ctx, orderID, filePath)
 }
 
-func (s *OrderService) CreateServiceOrderPayment(req *model.CreateServiceOrderPaymentRequest) (*model.ServiceOrderPaymentCreateResult, error) {
+func (s *OrderService) CreateServiceOrderPayment(ctx context.Context, req *model.CreateServiceOrderPaymentRequest) (*model.ServiceOrderPaymentCreateResult, error) {
 	if strings.TrimSpace(req.OrderID) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id wajib")
 	}
@@ -745,14 +1047,14 @@ func (s *OrderService) CreateServiceOrderPayment(req *model.CreateServiceOrderPa
 		err         error
 	)
 	if req.OrderType == 1 {
-		if synced, e := s.fleetRepo.SyncFleetOrderTotalAmountFromItems(req.OrderID, req.OrganizationID); e == nil {
+		if synced, e := s.fleetRepo.SyncFleetOrderTotalAmountFromItems(ctx, req.OrderID); e == nil {
 			totalAmount = synced
 			err = nil
 		} else {
-			totalAmount, err = s.fleetRepo.GetOrderTotalAmountByType(req.OrderType, req.OrderID, req.OrganizationID)
+			totalAmount, err = s.fleetRepo.GetOrderTotalAmountByType(ctx, req.OrderType, req.OrderID)
 		}
 	} else {
-		totalAmount, err = s.fleetRepo.GetOrderTotalAmountByType(req.OrderType, req.OrderID, req.OrganizationID)
+		totalAmount, err = s.fleetRepo.GetOrderTotalAmountByType(ctx, req.OrderType, req.OrderID)
 	}
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -764,7 +1066,7 @@ func (s *OrderService) CreateServiceOrderPayment(req *model.CreateServiceOrderPa
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "gagal mengambil total_amount order")
 	}
 
-	stats, err := s.fleetRepo.GetServiceOrderPaymentStats(req.OrderID, req.OrganizationID)
+	stats, err := s.fleetRepo.GetServiceOrderPaymentStats(ctx, req.OrderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "gagal mengambil histori pembayaran")
 	}
@@ -794,14 +1096,14 @@ func (s *OrderService) CreateServiceOrderPayment(req *model.CreateServiceOrderPa
 	if remaining < 0 && math.Abs(remaining) < 0.0001 {
 		remaining = 0
 	}
-	fmt.Println("remaining:", remaining)
+	slog.DebugContext(ctx, "payment remaining amount", "remaining", remaining)
 	if remaining < 0 {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "remaining_amount tidak valid")
 	}
 
-	paymentID, invoiceNumber, err := s.fleetRepo.InsertServiceOrderPayment(req, totalAmount, remaining)
+	paymentID, invoiceNumber, err := s.fleetRepo.InsertServiceOrderPayment(ctx, req, totalAmount, remaining)
 	if err != nil {
-		fmt.Println("Error creating payment order:", err)
+		slog.ErrorContext(ctx, "error creating payment order", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "gagal menyimpan payment order")
 	}
 	if req.OrderType == 1 {
@@ -809,7 +1111,7 @@ func (s *OrderService) CreateServiceOrderPayment(req *model.CreateServiceOrderPa
 		if remaining == 0 {
 			nextStatus = int(configs.PaymentStatusPaid)
 		}
-		if err := s.fleetRepo.UpdateFleetOrderPaymentStatusOnOrder(req.OrderID, req.OrganizationID, nextStatus); err != nil {
+		if err := s.fleetRepo.UpdateFleetOrderPaymentStatusOnOrder(ctx, req.OrderID, nextStatus); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order tidak ditemukan")
 			}
@@ -830,7 +1132,7 @@ func (s *OrderService) CreateServiceOrderPayment(req *model.CreateServiceOrderPa
 	}, nil
 }
 
-func (s *OrderService) GetServiceOrderPaymentHistory(organizationID string, req *model.ServiceOrderPaymentHistoryRequest) ([]model.ServiceOrderPaymentHistoryItem, error) {
+func (s *OrderService) GetServiceOrderPaymentHistory(ctx context.Context, req *model.ServiceOrderPaymentHistoryRequest) ([]model.ServiceOrderPaymentHistoryItem, error) {
 	if strings.TrimSpace(req.OrderID) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id wajib")
 	}
@@ -838,9 +1140,9 @@ func (s *OrderService) GetServiceOrderPaymentHistory(organizationID string, req
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_type tidak valid")
 	}
 
-	rows, err := s.fleetRepo.ListPaymentOrders(strings.TrimSpace(req.OrderID), req.OrderType, organizationID)
+	rows, err := s.fleetRepo.ListPaymentOrders(ctx, strings.TrimSpace(req.OrderID), req.OrderType)
 	if err != nil {
-		fmt.Println("Error listing payment orders:", err)
+		slog.ErrorContext(ctx, "error listing payment orders", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get payment history")
 	}
 
@@ -891,7 +1193,7 @@ func (s *OrderService) GetServiceOrderPaymentHistory(organizationID string, req
 	return out, nil
 }
 
-func (s *OrderService) GetServiceOrderList(orgID string, req *model.ServiceOrderListRequest) ([]model.ServiceOrderListItem, error) {
+func (s *OrderService) GetServiceOrderList(ctx context.Context, req *model.ServiceOrderListRequest) ([]model.ServiceOrderListItem, error) {
 	orderType := strings.ToLower(strings.TrimSpace(req.OrderType))
 	if orderType == "1" {
 		orderType = "fleet"
@@ -899,5 +1201,5 @@ func (s *OrderService) GetServiceOrderList(orgID string, req *model.ServiceOrder
 	if orderType != "fleet" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_type tidak valid")
 	}
-	return s.fleetRepo.ListServiceOrderFleet(orgID, req.ProcessType)
+	return s.fleetRepo.ListServiceOrderFleet(ctx, req.ProcessType)
 }
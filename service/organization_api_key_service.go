@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"service-travego/configs"
+	"service-travego/helper"
+	"service-travego/model"
+
+	"github.com/google/uuid"
+)
+
+// ListAPIKeys returns the organization's API keys and the scopes they can have
+func (s *OrganizationService) ListAPIKeys(ctx context.Context) (*model.OrganizationAPIKeyList, error) {
+	keys, err := s.orgRepo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get api keys")
+	}
+	res := &model.OrganizationAPIKeyList{Keys: keys, Scopes: make([]model.APIKeyScopeOption, 0, len(configs.APIKeyScopeLabel))}
+	for _, it := range configs.APIKeyScopeLabel {
+		res.Scopes = append(res.Scopes, model.APIKeyScopeOption{Scope: string(it.Scope), Label: it.Label})
+	}
+	return res, nil
+}
+
+// normalizeAPIKeyScopes validates and de-duplicates the scopes of an API key
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	out := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, sc := range scopes {
+		sc = strings.ToLower(strings.TrimSpace(sc))
+		if !configs.APIKeyScope(sc).IsValid() {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "scope tidak dikenal: "+sc)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	if len(out) == 0 {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "scopes wajib")
+	}
+	return out, nil
+}
+
+// parseAPIKeyExpiry parses expires_at (YYYY-MM-DD, the key stops working at
+// the end of that day). An empty value means the key does not expire.
+func parseAPIKeyExpiry(value string, now time.Time) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "expires_at harus berformat YYYY-MM-DD")
+	}
+	expiresAt := day.AddDate(0, 0, 1)
+	if !expiresAt.After(now) {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "expires_at sudah lewat")
+	}
+	return &expiresAt, nil
+}
+
+func newOrganizationAPIKey(organizationID, userID, name string, scopes []string) (*model.CreatedOrganizationAPIKey, string, error) {
+	raw, prefix, err := helper.GenerateAPIKey()
+	if err != nil {
+		return nil, "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate api key")
+	}
+	key := &model.CreatedOrganizationAPIKey{
+		OrganizationAPIKey: model.OrganizationAPIKey{
+			APIKeyID:       uuid.New().String(),
+			OrganizationID: organizationID,
+			Name:           name,
+			KeyPrefix:      prefix,
+			Scopes:         scopes,
+			Status:         model.APIKeyStatusActive,
+			CreatedBy:      userID,
+		},
+		APIKey: raw,
+	}
+	return key, helper.HashAPIKey(raw), nil
+}
+
+// CreateAPIKey mints a new API key. The key itself is only returned here.
+func (s *OrganizationService) CreateAPIKey(ctx context.Context, userID string, req *model.CreateOrganizationAPIKeyRequest) (*model.CreatedOrganizationAPIKey, error) {
+	organizationID := organizationFromContext(ctx)
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "name wajib")
+	}
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expiresAt, err := parseAPIKeyExpiry(req.ExpiresAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	key, keyHash, err := newOrganizationAPIKey(organizationID, userID, name, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.CreateAPIKey(ctx, &key.OrganizationAPIKey, keyHash, expiresAt, now); err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create api key")
+	}
+	key.CreatedAt = now.Format("2006-01-02 15:04:05")
+	if expiresAt != nil {
+		key.ExpiresAt = expiresAt.Format("2006-01-02 15:04:05")
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityAPIKey, EntityID: key.APIKeyID, Action: model.AuditActionCreate,
+		After: key.OrganizationAPIKey,
+	})
+	return key, nil
+}
+
+// RotateAPIKey replaces a key with a new one with the same name, scopes and
+// expiry. The old key stops working immediately.
+func (s *OrganizationService) RotateAPIKey(ctx context.Context, userID, apiKeyID string) (*model.CreatedOrganizationAPIKey, error) {
+	organizationID := organizationFromContext(ctx)
+	old, err := s.orgRepo.GetAPIKey(ctx, strings.TrimSpace(apiKeyID))
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get api key")
+	}
+	if old == nil || old.Status == model.APIKeyStatusRevoked {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "api key not found")
+	}
+	if old.Status == model.APIKeyStatusExpired {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "api key sudah kedaluwarsa, buat key baru")
+	}
+
+	var expiresAt *time.Time
+	if old.ExpiresAt != "" {
+		t, err := time.ParseInLocation("2006-01-02 15:04:05", old.ExpiresAt, time.Local)
+		if err != nil {
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to rotate api key")
+		}
+		expiresAt = &t
+	}
+
+	key, keyHash, err := newOrganizationAPIKey(organizationID, userID, old.Name, old.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.orgRepo.RotateAPIKey(ctx, old.APIKeyID, &key.OrganizationAPIKey, keyHash, expiresAt, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "api key not found")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to rotate api key")
+	}
+	key.CreatedAt = now.Format("2006-01-02 15:04:05")
+	key.ExpiresAt = old.ExpiresAt
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityAPIKey, EntityID: old.APIKeyID, Action: model.AuditActionRotate,
+		Before: old, After: key.OrganizationAPIKey,
+	})
+	return key, nil
+}
+
+// RevokeAPIKey stops a key from working
+func (s *OrganizationService) RevokeAPIKey(ctx context.Context, userID, apiKeyID string) error {
+	apiKeyID = strings.TrimSpace(apiKeyID)
+	var before *model.OrganizationAPIKey
+	if s.audit != nil {
+		before, _ = s.orgRepo.GetAPIKey(ctx, apiKeyID)
+	}
+	if err := s.orgRepo.RevokeAPIKey(ctx, apiKeyID, userID, time.Now()); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "api key not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to revoke api key")
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityAPIKey, EntityID: apiKeyID, Action: model.AuditActionRevoke,
+		Before: before, After: map[string]interface{}{"status": model.APIKeyStatusRevoked},
+	})
+	return nil
+}
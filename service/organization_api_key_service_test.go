@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeAPIKeyScopes(t *testing.T) {
+	got, err := normalizeAPIKeyScopes([]string{" Orders.Read", "orders.read", "availability.read"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "orders.read" || got[1] != "availability.read" {
+		t.Fatalf("unexpected scopes %v", got)
+	}
+
+	if _, err := normalizeAPIKeyScopes([]string{"orders.delete"}); GetStatusCode(err) != 400 {
+		t.Fatalf("expected 400 for an unknown scope, got %v", err)
+	}
+	if _, err := normalizeAPIKeyScopes(nil); GetStatusCode(err) != 400 {
+		t.Fatalf("expected 400 without scopes, got %v", err)
+	}
+}
+
+func TestParseAPIKeyExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	if got, err := parseAPIKeyExpiry("", now); err != nil || got != nil {
+		t.Fatalf("expected no expiry, got %v, %v", got, err)
+	}
+
+	got, err := parseAPIKeyExpiry("2026-03-10", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected key to expire at %v, got %v", want, got)
+	}
+
+	if _, err := parseAPIKeyExpiry("2026-03-09", now); GetStatusCode(err) != 400 {
+		t.Fatalf("expected 400 for a past date, got %v", err)
+	}
+}
@@ -1,8 +1,9 @@
 package service
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"log/slog"
 	"service-travego/model"
 	"strings"
 )
@@ -15,26 +16,28 @@ func NormalizeAssistantAccountNumber(accountNumber string) string {
 	return accountNumber
 }
 
-func (s *OrganizationService) assistantAccountLimitOrZero(organizationID string) (int, error) {
-	accountLimit, err := s.orgRepo.GetAssistantAccountLimit(organizationID)
-	if err != nil {
+// assistantAccountLimitOrZero returns the assistant users allowed by the
+// organization's package, zero when it is unlimited
+func (s *OrganizationService) assistantAccountLimitOrZero(ctx context.Context) (int, error) {
+	accountLimit, limited, err := s.entitlements.Limit(ctx, model.QuotaAssistantUsers)
+	if err != nil || !limited {
 		return 0, err
 	}
 	return accountLimit, nil
 }
 
-func (s *OrganizationService) AssistantList(organizationID string) (map[string]interface{}, error) {
-	totalAccount, err := s.orgRepo.CountActiveAssistantAccounts(organizationID)
+func (s *OrganizationService) AssistantList(ctx context.Context) (map[string]interface{}, error) {
+	totalAccount, err := s.orgRepo.CountActiveAssistantAccounts(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to count assistant accounts")
 	}
 
-	accountLimit, err := s.assistantAccountLimitOrZero(organizationID)
+	accountLimit, err := s.assistantAccountLimitOrZero(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	items, err := s.orgRepo.ListAssistantAccounts(organizationID)
+	items, err := s.orgRepo.ListAssistantAccounts(ctx)
 	if err != nil {
 		items = []model.AssistantAccountListItem{}
 	}
@@ -54,30 +57,12 @@ func (s *OrganizationService) AssistantList(organizationID string) (map[string]i
 	}, nil
 }
 
-func (s *OrganizationService) AssistantSubmit(organizationID, userID string, req *model.AssistantSubmitRequest) (map[string]interface{}, error) {
+func (s *OrganizationService) AssistantSubmit(ctx context.Context, userID string, req *model.AssistantSubmitRequest) (map[string]interface{}, error) {
 	userType := req.UserType
 	if userType != 1 && userType != 2 {
 		return nil, NewServiceError(ErrInvalidInput, 400, "user_type harus 1 atau 2")
 	}
 
-	accountLimit, err := s.assistantAccountLimitOrZero(organizationID)
-	if err != nil {
-		return nil, err
-	}
-
-	if accountLimit > 0 {
-		currentTotal, err := s.orgRepo.CountActiveAssistantAccounts(organizationID)
-		fmt.Println(currentTotal, " - currentTotal")
-		fmt.Println(accountLimit, " - accountLimit")
-		fmt.Println(err, " - err")
-		if err != nil {
-			return nil, NewServiceError(ErrInternalServer, 500, "failed to count assistant accounts")
-		}
-		if currentTotal >= accountLimit {
-			return nil, NewServiceError(ErrInvalidInput, 400, "assistant account limit reached")
-		}
-	}
-
 	accountNumber := NormalizeAssistantAccountNumber(req.AccountNumber)
 	accountName := strings.TrimSpace(req.AccountName)
 
@@ -95,7 +80,7 @@ func (s *OrganizationService) AssistantSubmit(organizationID, userID string, req
 		}
 
 		var err error
-		employee, err = s.orgRepo.GetAssistantEmployeeTarget(organizationID, employeeID)
+		employee, err = s.orgRepo.GetAssistantEmployeeTarget(ctx, employeeID)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return nil, NewServiceError(ErrNotFound, 404, "employee not found")
@@ -109,10 +94,18 @@ func (s *OrganizationService) AssistantSubmit(organizationID, userID string, req
 		}
 	}
 
-	assistantID, err := s.orgRepo.CreateAssistantAccount(organizationID, userID, userType, assistantUserID, accountNumber, accountName)
+	var assistantID string
+	err := s.entitlements.Reserve(ctx, map[string]int{model.QuotaAssistantUsers: 1}, func() error {
+		id, err := s.orgRepo.CreateAssistantAccount(ctx, userID, userType, assistantUserID, accountNumber, accountName)
+		if err != nil {
+			slog.ErrorContext(ctx, "assistant submit failed", "error", err)
+			return NewServiceError(ErrInternalServer, 500, "failed to create assistant account")
+		}
+		assistantID = id
+		return nil
+	})
 	if err != nil {
-		fmt.Println(err, " - err")
-		return nil, NewServiceError(ErrInternalServer, 500, "failed to create assistant account")
+		return nil, err
 	}
 
 	response := map[string]interface{}{
@@ -130,8 +123,8 @@ func (s *OrganizationService) AssistantSubmit(organizationID, userID string, req
 	return response, nil
 }
 
-func (s *OrganizationService) GetAssistantAccountByID(organizationID, assistantID string) (*model.AssistantAccountListItem, error) {
-	item, err := s.orgRepo.GetAssistantAccountByID(organizationID, assistantID)
+func (s *OrganizationService) GetAssistantAccountByID(ctx context.Context, assistantID string) (*model.AssistantAccountListItem, error) {
+	item, err := s.orgRepo.GetAssistantAccountByID(ctx, assistantID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, 404, "assistant account not found")
@@ -141,7 +134,7 @@ func (s *OrganizationService) GetAssistantAccountByID(organizationID, assistantI
 	return item, nil
 }
 
-func (s *OrganizationService) AssistantUpdate(organizationID string, req *model.AssistantUpdateRequest) error {
+func (s *OrganizationService) AssistantUpdate(ctx context.Context, req *model.AssistantUpdateRequest) error {
 	if req.AccountName == nil && req.AccountNumber == nil {
 		return NewServiceError(ErrInvalidInput, 400, "account_name atau account_number wajib diisi")
 	}
@@ -152,7 +145,7 @@ func (s *OrganizationService) AssistantUpdate(organizationID string, req *model.
 		accountNumber = &normalized
 	}
 
-	if err := s.orgRepo.UpdateAssistantAccount(organizationID, strings.TrimSpace(req.AssistantID), req.AccountName, accountNumber); err != nil {
+	if err := s.orgRepo.UpdateAssistantAccount(ctx, strings.TrimSpace(req.AssistantID), req.AccountName, accountNumber); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "assistant account not found")
 		}
@@ -162,8 +155,8 @@ func (s *OrganizationService) AssistantUpdate(organizationID string, req *model.
 	return nil
 }
 
-func (s *OrganizationService) AssistantDelete(organizationID, employeeID string) error {
-	employee, err := s.orgRepo.GetAssistantEmployeeTarget(organizationID, strings.TrimSpace(employeeID))
+func (s *OrganizationService) AssistantDelete(ctx context.Context, employeeID string) error {
+	employee, err := s.orgRepo.GetAssistantEmployeeTarget(ctx, strings.TrimSpace(employeeID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "employee not found")
@@ -171,7 +164,7 @@ func (s *OrganizationService) AssistantDelete(organizationID, employeeID string)
 		return NewServiceError(ErrInternalServer, 500, "failed to validate employee")
 	}
 
-	if err := s.orgRepo.DeleteAssistantAccountByUserID(organizationID, employee.UUID); err != nil {
+	if err := s.orgRepo.DeleteAssistantAccountByUserID(ctx, employee.UUID); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "assistant account not found")
 		}
@@ -181,8 +174,8 @@ func (s *OrganizationService) AssistantDelete(organizationID, employeeID string)
 	return nil
 }
 
-func (s *OrganizationService) EmployeeWhatsApp(organizationID, employeeID string) (*model.EmployeeWhatsAppResponse, error) {
-	employee, err := s.orgRepo.GetAssistantEmployeeTarget(organizationID, strings.TrimSpace(employeeID))
+func (s *OrganizationService) EmployeeWhatsApp(ctx context.Context, employeeID string) (*model.EmployeeWhatsAppResponse, error) {
+	employee, err := s.orgRepo.GetAssistantEmployeeTarget(ctx, strings.TrimSpace(employeeID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, 404, "employee not found")
@@ -198,10 +191,10 @@ func (s *OrganizationService) EmployeeWhatsApp(organizationID, employeeID string
 	}, nil
 }
 
-func (s *OrganizationService) AssistantWhatsAppBusinessList(organizationID string) (*model.AssistantWhatsAppBusinessListResponse, error) {
-	data, err := s.orgRepo.GetAssistantWhatsAppBusinessList(organizationID)
+func (s *OrganizationService) AssistantWhatsAppBusinessList(ctx context.Context) (*model.AssistantWhatsAppBusinessListResponse, error) {
+	data, err := s.orgRepo.GetAssistantWhatsAppBusinessList(ctx)
 	if err != nil {
-		fmt.Println(err, " - err")
+		slog.ErrorContext(ctx, "assistant WhatsApp business list failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get assistant whatsapp business list")
 	}
 
@@ -219,7 +212,7 @@ func (s *OrganizationService) AssistantWhatsAppBusinessList(organizationID strin
 	available := false
 
 	if s.subscriptionRepo != nil {
-		subscriptions, subErr := s.subscriptionRepo.GetSubscriptionDetails(organizationID)
+		subscriptions, subErr := s.subscriptionRepo.GetSubscriptionDetails(ctx)
 		if subErr == nil && len(subscriptions) > 0 {
 			packageID := subscriptions[0].PackageID
 			// If package_id != trave01, available is true
@@ -247,8 +240,8 @@ func (s *OrganizationService) AssistantWhatsAppBusinessList(organizationID strin
 	return &response, nil
 }
 
-func (s *OrganizationService) AssistantWhatsAppBusinessUpdate(organizationID, accountNumber string) error {
-	existingAccount, err := s.orgRepo.GetAssistantWhatsAppBusinessList(organizationID)
+func (s *OrganizationService) AssistantWhatsAppBusinessUpdate(ctx context.Context, accountNumber string) error {
+	existingAccount, err := s.orgRepo.GetAssistantWhatsAppBusinessList(ctx)
 	if err != nil {
 		return NewServiceError(ErrInternalServer, 500, "failed to check existing whatsapp business account")
 	}
@@ -256,17 +249,17 @@ func (s *OrganizationService) AssistantWhatsAppBusinessUpdate(organizationID, ac
 	hasExisting := existingAccount != nil && existingAccount.AccountNumber != ""
 	if !hasExisting {
 		// No existing record, create new
-		err = s.orgRepo.CreateAssistantWhatsappBusiness(organizationID, accountNumber)
+		err = s.orgRepo.CreateAssistantWhatsappBusiness(ctx, accountNumber)
 		if err != nil {
 			return NewServiceError(ErrInternalServer, 500, "failed to create whatsapp business account")
 		}
 	} else {
 		// Existing record, update
-		err = s.orgRepo.UpdateAssistantWhatsappBusiness(organizationID, accountNumber)
+		err = s.orgRepo.UpdateAssistantWhatsappBusiness(ctx, accountNumber)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				// Maybe the record was deleted after check, try creating
-				err = s.orgRepo.CreateAssistantWhatsappBusiness(organizationID, accountNumber)
+				err = s.orgRepo.CreateAssistantWhatsappBusiness(ctx, accountNumber)
 				if err != nil {
 					return NewServiceError(ErrInternalServer, 500, "failed to create whatsapp business account")
 				}
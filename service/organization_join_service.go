@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"service-travego/configs"
+	"service-travego/database"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/repository"
@@ -20,6 +22,7 @@ type OrganizationJoinService struct {
 	userRepo        *repository.UserRepository
 	notificationSvc *NotificationService
 	emailCfg        *configs.EmailConfig
+	outbox          *OutboxService
 }
 
 func NewOrganizationJoinService(orgRepo *repository.OrganizationRepository, orgUserRepo *repository.OrganizationUserRepository, userRepo *repository.UserRepository, notificationSvc *NotificationService, emailCfg *configs.EmailConfig) *OrganizationJoinService {
@@ -32,28 +35,35 @@ func NewOrganizationJoinService(orgRepo *repository.OrganizationRepository, orgU
 	}
 }
 
+// SetOutboxService sets the outbox the approval emails are queued in
+func (s *OrganizationJoinService) SetOutboxService(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
 // JoinOrganization handles user joining an organization
-func (s *OrganizationJoinService) JoinOrganization(userID, organizationCode string) error {
-	org, err := s.orgRepo.FindByCode(organizationCode)
+func (s *OrganizationJoinService) JoinOrganization(ctx context.Context, userID, organizationCode string) error {
+	org, err := s.orgRepo.FindByCode(ctx, organizationCode)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrUserNotFound, http.StatusBadRequest, "organization not found")
 		}
-		log.Printf("[ERROR] Error finding organization by code - Code: %s, Error: %v", organizationCode, err)
+		slog.ErrorContext(ctx, "error finding organization by code", "code", organizationCode, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to find organization")
 	}
+	// the membership rows belong to the organization being joined
+	ctx = database.WithOrganization(ctx, org.OrganizationId)
 
 	// Check if user already exists in organization_users for this organization
-	exists, err := s.orgUserRepo.CheckUserInOrganization(userID, org.OrganizationId)
+	exists, err := s.orgUserRepo.CheckUserInOrganization(ctx, userID)
 	if err != nil {
-		log.Printf("[ERROR] Error checking user in organization - UserID: %s, OrgID: %s, Error: %v", userID, org.OrganizationId, err)
+		slog.ErrorContext(ctx, "error checking user in organization", "user_id", userID, "organization_id", org.OrganizationId, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to check organization membership")
 	}
 
 	// Get current user for created_by
-	currentUser, err := s.userRepo.FindByID(userID)
+	currentUser, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		log.Printf("[ERROR] Error finding current user - UserID: %s, Error: %v", userID, err)
+		slog.ErrorContext(ctx, "error finding current user", "user_id", userID, "error", err)
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to find user")
 	}
 
@@ -73,14 +83,14 @@ func (s *OrganizationJoinService) JoinOrganization(userID, organizationCode stri
 			UpdatedBy:        userID,
 		}
 
-		if err = s.orgUserRepo.CreateOrganizationUser(orgUser); err != nil {
-			log.Printf("[ERROR] Failed to create organization user - UserID: %s, OrgID: %s, Error: %v", userID, org.OrganizationId, err)
+		if err = s.orgUserRepo.CreateOrganizationUser(ctx, orgUser); err != nil {
+			slog.ErrorContext(ctx, "failed to create organization user", "user_id", userID, "organization_id", org.OrganizationId, "error", err)
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to join organization")
 		}
 	} else {
 		// User already exists, update role to 2
-		if err = s.orgUserRepo.UpdateOrganizationUserRole(userID, org.OrganizationId, 2); err != nil {
-			log.Printf("[ERROR] Failed to update organization user role - UserID: %s, OrgID: %s, Error: %v", userID, org.OrganizationId, err)
+		if err = s.orgUserRepo.UpdateOrganizationUserRole(ctx, userID, 2); err != nil {
+			slog.ErrorContext(ctx, "failed to update organization user role", "user_id", userID, "organization_id", org.OrganizationId, "error", err)
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update organization role")
 		}
 	}
@@ -99,28 +109,32 @@ func (s *OrganizationJoinService) JoinOrganization(userID, organizationCode stri
 	notificationCreated := false
 
 	// Get all users in the organization (excluding the current user)
-	orgUsers, err := s.orgUserRepo.GetUsersByOrganizationID(org.OrganizationId)
+	orgUsers, err := s.orgUserRepo.GetUsersByOrganizationID(ctx)
 	if err != nil {
-		log.Printf("[ERROR] Failed to get organization users - OrgID: %s, Error: %v", org.OrganizationId, err)
+		slog.ErrorContext(ctx, "failed to get organization users", "organization_id", org.OrganizationId, "error", err)
 		// Continue even if this fails, as the join was successful
 	} else {
 		// Send email to existing users (excluding current user) for approval
 		for _, orgUser := range orgUsers {
 			if orgUser.UserID != userID {
 				// Get user details for email
-				user, err := s.userRepo.FindByID(orgUser.UserID)
+				user, err := s.userRepo.FindByID(ctx, orgUser.UserID)
 				if err != nil {
-					log.Printf("[ERROR] Failed to get user for email - UserID: %s, Error: %v", orgUser.UserID, err)
+					slog.ErrorContext(ctx, "failed to get user for email", "user_id", orgUser.UserID, "error", err)
 					continue
 				}
 
-				// Send approval email
-				if err = helper.SendJoinOrganizationApprovalEmail(s.emailCfg, user.Email, user.Username, currentUser.Username, org.OrganizationName, approveURL); err != nil {
-					log.Printf("[ERROR] Failed to send approval email - Email: %s, Error: %v", user.Email, err)
+				// Queue approval email
+				approvalEmail, err := helper.NewJoinOrganizationApprovalEmail(user.Email, user.Username, currentUser.Username, org.OrganizationName, approveURL)
+				if err == nil {
+					err = s.outbox.EnqueueEmail(ctx, org.OrganizationId, OutboxKindJoinApproval, approvalEmail)
+				}
+				if err != nil {
+					slog.ErrorContext(ctx, "failed to queue approval email", "email", user.Email, "error", err)
 					// Continue even if email fails
 				} else if !notificationCreated && s.notificationSvc != nil {
 					// Create notification only once after first successful email
-					_, _ = s.notificationSvc.CreateNotification(org.OrganizationId, NotificationPayload{
+					_, _ = s.notificationSvc.CreateNotification(database.WithOrganization(ctx, org.OrganizationId), NotificationPayload{
 						Title:   "Permintaan User Baru",
 						Message: "Tinjau user sebelum memberikan persetujuan akses",
 						URL:     approveURL,
@@ -1,14 +1,18 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"service-travego/database"
 	"service-travego/helper"
+	"service-travego/internal/paymentgateway"
 	"service-travego/model"
 	"service-travego/repository"
 	"strings"
@@ -23,9 +27,12 @@ type OrganizationService struct {
 	userRepo           *repository.UserRepository
 	orgTypeRepo        *repository.OrganizationTypeRepository
 	subscriptionRepo   *repository.SubscriptionRepository
+	gateways           *paymentgateway.Registry
 	citiesName         map[string]string
 	provincesName      map[string]string
 	contractTypeLabels map[int]string
+	audit              *AuditService
+	entitlements       *EntitlementService
 }
 
 func NewOrganizationService(orgRepo *repository.OrganizationRepository, userRepo *repository.UserRepository) *OrganizationService {
@@ -50,7 +57,54 @@ func (s *OrganizationService) SetSubscriptionRepository(subscriptionRepo *reposi
 	s.subscriptionRepo = subscriptionRepo
 }
 
-func (s *OrganizationService) generateOrganizationCode(orgName string) (string, error) {
+// SetPaymentGateways sets the payment gateways organizations can choose from
+func (s *OrganizationService) SetPaymentGateways(gateways *paymentgateway.Registry) {
+	s.gateways = gateways
+}
+
+// SetAuditService records organization setting changes in the audit log
+func (s *OrganizationService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetEntitlementService limits active dashboard users to the organization's package
+func (s *OrganizationService) SetEntitlementService(entitlements *EntitlementService) {
+	s.entitlements = entitlements
+}
+
+// organizationAuditSnapshot loads an organization for the audit log; nil when
+// auditing is off
+func (s *OrganizationService) organizationAuditSnapshot(ctx context.Context) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	org, err := s.orgRepo.FindCurrent(ctx)
+	if err != nil {
+		return nil
+	}
+	return org
+}
+
+// bankAccountAuditSnapshot loads a bank account, by ID or else by bank code,
+// for the audit log; nil when auditing is off
+func (s *OrganizationService) bankAccountAuditSnapshot(ctx context.Context, bankAccountID, bankCode string) *model.OrganizationBankAccountResponse {
+	if s.audit == nil {
+		return nil
+	}
+	accounts, err := s.orgRepo.GetBankAccounts(ctx)
+	if err != nil {
+		return nil
+	}
+	for i := range accounts {
+		if (bankAccountID != "" && accounts[i].BankAccountID == bankAccountID) ||
+			(bankAccountID == "" && accounts[i].BankCode == bankCode) {
+			return &accounts[i]
+		}
+	}
+	return nil
+}
+
+func (s *OrganizationService) generateOrganizationCode(ctx context.Context, orgName string) (string, error) {
 	vowels := "aeiouAEIOU "
 	var extractedConsonants []string
 
@@ -99,7 +153,7 @@ func (s *OrganizationService) generateOrganizationCode(orgName string) (string,
 	consonantPart := code[:len(code)-4] // Store the consonant part before adding digits
 
 	for attempts < maxAttempts {
-		existingOrg, err := s.orgRepo.FindByCode(code)
+		existingOrg, err := s.orgRepo.FindByCode(ctx, code)
 		if err != nil {
 			// Check if it's "not found" error
 			if err == sql.ErrNoRows {
@@ -122,34 +176,35 @@ func (s *OrganizationService) generateOrganizationCode(orgName string) (string,
 }
 
 // CreateOrganization creates a new organization
-func (s *OrganizationService) CreateOrganization(userID string, org *model.Organization) (*model.Organization, error) {
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID string, org *model.Organization) (*model.Organization, error) {
 	if org.OrganizationCode == "" {
-		code, err := s.generateOrganizationCode(org.OrganizationName)
+		code, err := s.generateOrganizationCode(ctx, org.OrganizationName)
 		if err != nil {
-			fmt.Println("Error generating organization code:", err, org.OrganizationName)
+			slog.ErrorContext(ctx, "error generating organization code", "error", err, "organization_name", org.OrganizationName)
 			return nil, err
 		}
 		org.OrganizationCode = code
 	} else {
 		// Check if provided code exists
-		existingOrg, err := s.orgRepo.FindByCode(org.OrganizationCode)
+		existingOrg, err := s.orgRepo.FindByCode(ctx, org.OrganizationCode)
 		if err != nil && err != sql.ErrNoRows {
-			fmt.Println("Error checking organization code:", err, org.OrganizationCode)
+			slog.ErrorContext(ctx, "error checking organization code", "error", err, "organization_code", org.OrganizationCode)
 			return nil, err
 		}
 		if existingOrg != nil {
-			fmt.Println("Organization code already exists:", org.OrganizationCode)
+			slog.DebugContext(ctx, "organization code already exists", "organization_code", org.OrganizationCode)
 			return nil, errors.New("organization code already exists")
 		}
 	}
 
 	org.CreatedBy = userID
 	org.OrganizationId = uuid.New().String()
-	createdOrg, err := s.orgRepo.Create(org)
-	fmt.Println("Created organization:", createdOrg.OrganizationId, "Error:", err)
+	createdOrg, err := s.orgRepo.Create(ctx, org)
 	if err != nil {
+		slog.ErrorContext(ctx, "create organization failed", "error", err)
 		return nil, err
 	}
+	slog.DebugContext(ctx, "organization created", "organization_id", createdOrg.OrganizationId)
 
 	if s.orgUserRepo == nil {
 		return nil, errors.New("organization user repository not initialized")
@@ -168,27 +223,32 @@ func (s *OrganizationService) CreateOrganization(userID string, org *model.Organ
 		UpdatedBy:        userID,
 	}
 
-	if err = s.orgUserRepo.CreateOrganizationUser(orgUser); err != nil {
+	if err = s.orgUserRepo.CreateOrganizationUser(database.WithOrganization(ctx, createdOrg.OrganizationId), orgUser); err != nil {
 		return nil, fmt.Errorf("failed to create organization user: %w", err)
 	}
 
 	return createdOrg, nil
 }
 
-func (s *OrganizationService) CreateOrganizationSubscription(organizationID string) error {
+// CreateOrganizationSubscription starts the trial of an organization just
+// created
+func (s *OrganizationService) CreateOrganizationSubscription(ctx context.Context, organizationID string) error {
 	if s.orgUserRepo == nil {
 		return errors.New("organization user repository not initialized")
 	}
-	return s.orgUserRepo.CreateSubscriptionWithDuration(organizationID, 60)
+	return s.orgUserRepo.CreateSubscriptionWithDuration(database.WithOrganization(ctx, organizationID), 60)
 }
 
-func (s *OrganizationService) CreateDefaultAssistantAccount(organizationID, createdBy, accountNumber string) (string, error) {
+// CreateDefaultAssistantAccount registers the admin number of an organization
+// just created, so the organization is taken from organizationID rather than
+// from the request
+func (s *OrganizationService) CreateDefaultAssistantAccount(ctx context.Context, organizationID, createdBy, accountNumber string) (string, error) {
 	accountNumber = strings.TrimSpace(accountNumber)
 	if accountNumber == "" {
 		return "", errors.New("account_number is required")
 	}
 
-	assistantID, err := s.orgRepo.CreateAssistantAccount(organizationID, createdBy, 1, nil, accountNumber, "Admin")
+	assistantID, err := s.orgRepo.CreateAssistantAccount(database.WithOrganization(ctx, organizationID), createdBy, 1, nil, accountNumber, "Admin")
 	if err != nil {
 		return "", fmt.Errorf("failed to create assistant account: %w", err)
 	}
@@ -196,15 +256,18 @@ func (s *OrganizationService) CreateDefaultAssistantAccount(organizationID, crea
 }
 
 // UpdateOrganization updates an existing organization
-func (s *OrganizationService) UpdateOrganization(userID string, org *model.Organization) (*model.Organization, error) {
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, userID string, org *model.Organization) (*model.Organization, error) {
 	// Check if organization exists
-	existingOrg, err := s.orgRepo.FindByID(org.OrganizationId)
+	existingOrg, err := s.orgRepo.FindCurrent(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("organization not found")
 		}
 		return nil, err
 	}
+	if existingOrg.OrganizationId != org.OrganizationId {
+		return nil, errors.New("organization not found")
+	}
 
 	// Check if user is authorized to update (must be the creator or admin)
 	// Here we check if the user is the creator. In real app, check roles.
@@ -223,10 +286,20 @@ func (s *OrganizationService) UpdateOrganization(userID string, org *model.Organ
 	existingOrg.Phone = org.Phone
 	existingOrg.Email = org.Email
 
-	return s.orgRepo.Update(existingOrg)
+	before := s.organizationAuditSnapshot(ctx)
+	updated, err := s.orgRepo.Update(ctx, existingOrg)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrganization, EntityID: org.OrganizationId, Action: model.AuditActionUpdate,
+		Before: before, After: s.organizationAuditSnapshot(ctx),
+	})
+	return updated, nil
 }
 
-func (s *OrganizationService) UpdateOrganizationDetail(orgID string, req *model.UpdateOrganizationDetailRequest) error {
+func (s *OrganizationService) UpdateOrganizationDetail(ctx context.Context, userID string, req *model.UpdateOrganizationDetailRequest) error {
 	updates := make(map[string]interface{})
 
 	if req.OrganizationName != nil {
@@ -287,16 +360,23 @@ func (s *OrganizationService) UpdateOrganizationDetail(orgID string, req *model.
 		return nil // Nothing to update
 	}
 
-	if err := s.orgRepo.UpdateByID(orgID, updates); err != nil {
+	orgID := organizationFromContext(ctx)
+	before := s.organizationAuditSnapshot(ctx)
+	if err := s.orgRepo.UpdateByID(ctx, updates); err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "organization tidak ditemukan")
 		}
 		return err
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrganization, EntityID: orgID, Action: model.AuditActionUpdate,
+		Before: before, After: s.organizationAuditSnapshot(ctx),
+	})
 	return nil
 }
 
-func (s *OrganizationService) UpdateOrganizationLogo(orgID, sourceFilePath string) (string, error) {
+func (s *OrganizationService) UpdateOrganizationLogo(ctx context.Context, userID, sourceFilePath string) (string, error) {
 	if sourceFilePath == "" {
 		return "", NewServiceError(ErrInvalidInput, 400, "file_path wajib")
 	}
@@ -304,7 +384,7 @@ func (s *OrganizationService) UpdateOrganizationLogo(orgID, sourceFilePath strin
 		return "", NewServiceError(ErrInvalidInput, 400, "file_path tidak ditemukan")
 	}
 
-	org, err := s.orgRepo.FindByID(orgID)
+	org, err := s.orgRepo.FindCurrent(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", NewServiceError(ErrNotFound, 404, "organization tidak ditemukan")
@@ -332,16 +412,23 @@ func (s *OrganizationService) UpdateOrganizationLogo(orgID, sourceFilePath strin
 
 	// Store path in DB as web path
 	webPath := "/assets/logo/" + filename
-	if err := s.orgRepo.UpdateLogo(orgID, webPath); err != nil {
+	if err := s.orgRepo.UpdateLogo(ctx, webPath); err != nil {
 		return "", err
 	}
+	// the stored path rarely changes, so the uploaded file name marks the change
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrganization, EntityID: org.OrganizationId, Action: model.AuditActionUpdate,
+		Before: map[string]interface{}{"logo": org.Logo},
+		After:  map[string]interface{}{"logo": webPath, "logo_file": filepath.Base(sourceFilePath)},
+	})
 
 	return helper.GetAssetURL(webPath), nil
 }
 
 // GetAPIConfig retrieves API configuration for an organization
-func (s *OrganizationService) GetAPIConfig(userID string) (map[string]interface{}, error) {
-	orgs, err := s.orgRepo.FindByUsernameUserID(userID)
+func (s *OrganizationService) GetAPIConfig(ctx context.Context, userID string) (map[string]interface{}, error) {
+	orgs, err := s.orgRepo.FindByUsernameUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -352,7 +439,7 @@ func (s *OrganizationService) GetAPIConfig(userID string) (map[string]interface{
 
 	role := 0
 	if s.orgUserRepo != nil {
-		r, roleErr := s.orgUserRepo.GetRoleByUserIDAndOrgID(userID, orgs[0].OrganizationId)
+		r, roleErr := s.orgUserRepo.GetRoleByUserIDAndOrgID(database.WithOrganization(ctx, orgs[0].OrganizationId), userID)
 		if roleErr == nil {
 			role = r
 		} else if roleErr != sql.ErrNoRows {
@@ -380,14 +467,72 @@ func (s *OrganizationService) GetAPIConfig(userID string) (map[string]interface{
 	return res, nil
 }
 
+// GetPaymentGatewaySetting returns the organization's payment gateway and the
+// gateways it can switch to
+func (s *OrganizationService) GetPaymentGatewaySetting(ctx context.Context) (*model.PaymentGatewaySetting, error) {
+	if s.gateways == nil {
+		return nil, errors.New("payment gateways not initialized")
+	}
+	provider, err := s.orgRepo.GetPaymentGateway(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment gateway: %w", err)
+	}
+	if provider == "" {
+		provider = s.gateways.DefaultProvider()
+	}
+
+	res := &model.PaymentGatewaySetting{PaymentGateway: provider}
+	for _, p := range s.gateways.Providers() {
+		res.Options = append(res.Options, model.PaymentGatewayOption{Provider: p, Label: paymentgateway.Label(p)})
+	}
+	return res, nil
+}
+
+// UpdatePaymentGateway switches the gateway new invoices of the organization
+// are issued with. Invoices already issued keep their gateway.
+func (s *OrganizationService) UpdatePaymentGateway(ctx context.Context, userID, provider string) error {
+	organizationID := organizationFromContext(ctx)
+	if s.orgUserRepo == nil || s.gateways == nil {
+		return errors.New("organization service not initialized")
+	}
+
+	role, err := s.orgUserRepo.GetRoleByUserIDAndOrgID(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New("user not found in organization")
+		}
+		return fmt.Errorf("failed to check role: %w", err)
+	}
+	if role != 1 {
+		return errors.New("access denied: only admin can update payment gateway")
+	}
+
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if _, err := s.gateways.Get(provider); err != nil {
+		return err
+	}
+	before, _ := s.orgRepo.GetPaymentGateway(ctx)
+	if err := s.orgRepo.UpdatePaymentGateway(ctx, provider); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrganization, EntityID: organizationID, Action: model.AuditActionUpdate,
+		Before: map[string]interface{}{"payment_gateway": before},
+		After:  map[string]interface{}{"payment_gateway": provider},
+	})
+	return nil
+}
+
 // UpdateDomainURL updates the domain URL for an organization
-func (s *OrganizationService) UpdateDomainURL(userID, organizationID, domainURL string) error {
+func (s *OrganizationService) UpdateDomainURL(ctx context.Context, userID, domainURL string) error {
+	organizationID := organizationFromContext(ctx)
 	// Verify user belongs to organization and is admin
 	if s.orgUserRepo == nil {
 		return errors.New("organization user repository not initialized")
 	}
 
-	role, err := s.orgUserRepo.GetRoleByUserIDAndOrgID(userID, organizationID)
+	role, err := s.orgUserRepo.GetRoleByUserIDAndOrgID(ctx, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return errors.New("user not found in organization")
@@ -400,12 +545,21 @@ func (s *OrganizationService) UpdateDomainURL(userID, organizationID, domainURL
 		return errors.New("access denied: only admin can update domain url")
 	}
 
-	return s.orgRepo.UpdateDomainURL(organizationID, domainURL)
+	before := s.organizationAuditSnapshot(ctx)
+	if err := s.orgRepo.UpdateDomainURL(ctx, domainURL); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityOrganization, EntityID: organizationID, Action: model.AuditActionUpdate,
+		Before: before, After: s.organizationAuditSnapshot(ctx),
+	})
+	return nil
 }
 
 // GetBankAccounts retrieves bank accounts for an organization with payment method logic
-func (s *OrganizationService) GetBankAccounts(organizationID string) ([]model.OrganizationBankAccountResponse, error) {
-	accounts, err := s.orgRepo.GetBankAccounts(organizationID)
+func (s *OrganizationService) GetBankAccounts(ctx context.Context) ([]model.OrganizationBankAccountResponse, error) {
+	accounts, err := s.orgRepo.GetBankAccounts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -431,7 +585,7 @@ func (s *OrganizationService) GetBankAccounts(organizationID string) ([]model.Or
 }
 
 // CreateBankAccount creates a new bank account for an organization
-func (s *OrganizationService) CreateBankAccount(req *model.CreateOrganizationBankAccountRequest, organizationID, createdBy, createdProxy, createdIP string) error {
+func (s *OrganizationService) CreateBankAccount(ctx context.Context, req *model.CreateOrganizationBankAccountRequest, createdBy, createdProxy, createdIP string) error {
 	// Validation for QRIS payment method
 	if req.PaymentMethod == model.BankAccountPaymentMethodQRIS {
 		if req.MerchantName == "" || req.MerchantMCC == "" || req.MerchantAddress == "" || req.MerchantCity == "" || req.MerchantPostalCode == "" || req.AccountType == 0 {
@@ -440,7 +594,7 @@ func (s *OrganizationService) CreateBankAccount(req *model.CreateOrganizationBan
 	}
 
 	// Check if bank account already exists for the organization and bank code
-	existingBankName, err := s.orgRepo.CheckBankAccountExists(organizationID, req.BankCode)
+	existingBankName, err := s.orgRepo.CheckBankAccountExists(ctx, req.BankCode)
 	if err != nil {
 		return err
 	}
@@ -448,11 +602,21 @@ func (s *OrganizationService) CreateBankAccount(req *model.CreateOrganizationBan
 		return fmt.Errorf("%s sudah terdaftar", existingBankName)
 	}
 
-	return s.orgRepo.CreateBankAccount(req, organizationID, createdBy, createdProxy, createdIP)
+	if err := s.orgRepo.CreateBankAccount(ctx, req, createdBy, createdProxy, createdIP); err != nil {
+		return err
+	}
+	if account := s.bankAccountAuditSnapshot(ctx, "", req.BankCode); account != nil {
+		s.audit.Record(ctx, AuditEntry{
+			ActorID:    createdBy,
+			EntityType: model.AuditEntityBankAccount, EntityID: account.BankAccountID, Action: model.AuditActionCreate,
+			After: account,
+		})
+	}
+	return nil
 }
 
 // UpdateBankAccount updates an existing bank account for an organization
-func (s *OrganizationService) UpdateBankAccount(req *model.UpdateOrganizationBankAccountRequest, organizationID, updatedProxy, updatedIP string) error {
+func (s *OrganizationService) UpdateBankAccount(ctx context.Context, req *model.UpdateOrganizationBankAccountRequest, updatedBy, updatedProxy, updatedIP string) error {
 	// Mutually exclusive validation
 	if req.Active != nil {
 		if req.AccountNumber != "" || req.AccountName != "" {
@@ -464,12 +628,30 @@ func (s *OrganizationService) UpdateBankAccount(req *model.UpdateOrganizationBan
 		}
 	}
 
-	return s.orgRepo.UpdateBankAccount(req.BankAccountID, organizationID, req.Active, req.AccountNumber, req.AccountName, updatedProxy, updatedIP)
+	before := s.bankAccountAuditSnapshot(ctx, req.BankAccountID, "")
+	if err := s.orgRepo.UpdateBankAccount(ctx, req.BankAccountID, req.Active, req.AccountNumber, req.AccountName, updatedProxy, updatedIP); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    updatedBy,
+		EntityType: model.AuditEntityBankAccount, EntityID: req.BankAccountID, Action: model.AuditActionUpdate,
+		Before: before, After: s.bankAccountAuditSnapshot(ctx, req.BankAccountID, ""),
+	})
+	return nil
 }
 
 // DeleteBankAccount deletes a bank account for an organization
-func (s *OrganizationService) DeleteBankAccount(bankAccountID, organizationID string) error {
-	return s.orgRepo.DeleteBankAccount(bankAccountID, organizationID)
+func (s *OrganizationService) DeleteBankAccount(ctx context.Context, bankAccountID, deletedBy string) error {
+	before := s.bankAccountAuditSnapshot(ctx, bankAccountID, "")
+	if err := s.orgRepo.DeleteBankAccount(ctx, bankAccountID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    deletedBy,
+		EntityType: model.AuditEntityBankAccount, EntityID: bankAccountID, Action: model.AuditActionDelete,
+		Before: before,
+	})
+	return nil
 }
 func (s *OrganizationService) ensureLocationsLoaded() {
 	if s.citiesName != nil && s.provincesName != nil {
@@ -509,7 +691,7 @@ func (s *OrganizationService) convertUserCitiesToLabels(users []model.User) []mo
 	return users
 }
 
-func (s *OrganizationService) GetOrganizationUsers(organizationID, status string) ([]model.User, error) {
+func (s *OrganizationService) GetOrganizationUsers(ctx context.Context, status string) ([]model.User, error) {
 	var statusFilter interface{}
 	if status != "" {
 		if status == "1" {
@@ -519,7 +701,7 @@ func (s *OrganizationService) GetOrganizationUsers(organizationID, status string
 		}
 	}
 
-	users, err := s.orgUserRepo.GetUsers(organizationID, statusFilter)
+	users, err := s.orgUserRepo.GetUsers(ctx, statusFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -533,20 +715,56 @@ func (s *OrganizationService) GetOrganizationUsers(organizationID, status string
 	return users, nil
 }
 
-func (s *OrganizationService) ApproveJoinRequest(organizationID, userID string) error {
-	return s.orgUserRepo.UpdateOrganizationUserActiveByUserID(userID, organizationID, true)
+func (s *OrganizationService) ApproveJoinRequest(ctx context.Context, actorID, userID string) error {
+	err := s.entitlements.Reserve(ctx, map[string]int{model.QuotaDashboardUsers: 1}, func() error {
+		return s.orgUserRepo.UpdateOrganizationUserActiveByUserID(ctx, userID, true)
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    actorID,
+		EntityType: model.AuditEntityMember, EntityID: userID, Action: model.AuditActionApprove,
+		After: map[string]interface{}{"is_active": true},
+	})
+	return nil
 }
 
-func (s *OrganizationService) RejectJoinRequest(organizationID, userID string) error {
-	return s.orgUserRepo.DeleteOrganizationUserByUserID(userID, organizationID)
+func (s *OrganizationService) RejectJoinRequest(ctx context.Context, actorID, userID string) error {
+	if err := s.orgUserRepo.DeleteOrganizationUserByUserID(ctx, userID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    actorID,
+		EntityType: model.AuditEntityMember, EntityID: userID, Action: model.AuditActionReject,
+	})
+	return nil
 }
 
-func (s *OrganizationService) ToggleUserStatus(organizationID, userID string, enable bool) error {
-	return s.orgUserRepo.UpdateUserIsActive(userID, organizationID, enable)
+func (s *OrganizationService) ToggleUserStatus(ctx context.Context, actorID, userID string, enable bool) error {
+	update := func() error {
+		return s.orgUserRepo.UpdateUserIsActive(ctx, userID, enable)
+	}
+	var err error
+	if enable {
+		err = s.entitlements.Reserve(ctx, map[string]int{model.QuotaDashboardUsers: 1}, update)
+	} else {
+		err = update()
+	}
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    actorID,
+		EntityType: model.AuditEntityMember, EntityID: userID, Action: model.AuditActionUpdate,
+		Before: map[string]interface{}{"is_active": !enable},
+		After:  map[string]interface{}{"is_active": enable},
+	})
+	return nil
 }
 
-func (s *OrganizationService) GetOrganizationDetail(organizationID string) (map[string]interface{}, error) {
-	org, err := s.orgRepo.FindByID(organizationID)
+func (s *OrganizationService) GetOrganizationDetail(ctx context.Context) (map[string]interface{}, error) {
+	org, err := s.orgRepo.FindCurrent(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, 404, "organization not found")
@@ -600,6 +818,6 @@ func (s *OrganizationService) GetOrganizationDetail(organizationID string) (map[
 	return res, nil
 }
 
-func (s *OrganizationService) GetAdminAccountNumber(organizationID string) (string, error) {
-	return s.orgRepo.GetAdminAccountNumber(organizationID)
+func (s *OrganizationService) GetAdminAccountNumber(ctx context.Context) (string, error) {
+	return s.orgRepo.GetAdminAccountNumber(ctx)
 }
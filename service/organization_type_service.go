@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"service-travego/model"
 	"service-travego/repository"
 )
@@ -16,6 +17,6 @@ func NewOrganizationTypeService(orgTypeRepo *repository.OrganizationTypeReposito
 }
 
 // GetAllOrganizationTypes retrieves all organization types ordered by name ascending
-func (s *OrganizationTypeService) GetAllOrganizationTypes() ([]model.OrganizationType, error) {
-	return s.orgTypeRepo.FindAll()
+func (s *OrganizationTypeService) GetAllOrganizationTypes(ctx context.Context) ([]model.OrganizationType, error) {
+	return s.orgTypeRepo.FindAll(ctx)
 }
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service-travego/configs"
+	"service-travego/database"
+	"service-travego/helper"
+	"service-travego/internal/wagy"
+	"service-travego/model"
+	"service-travego/repository"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of outgoing messages, shown and filtered on in the outbox list
+const (
+	OutboxKindOTPRegister         = "otp_register"
+	OutboxKindRegisterSuccess     = "register_success"
+	OutboxKindResetPasswordOTP    = "reset_password_otp"
+	OutboxKindResetPassword       = "reset_password"
+	OutboxKindJoinApproval        = "join_organization_approval"
+	OutboxKindNewDeviceLogin      = "new_device_login"
+	OutboxKindOrderSuccess        = "order_success"
+	OutboxKindOrderReceived       = "order_received"
+	OutboxKindOrderApproved       = "order_approved"
+	OutboxKindOrderAdminNotice    = "order_admin_notice"
+	OutboxKindPaymentSuccess      = "payment_success"
+	OutboxKindPaymentReceived     = "payment_received"
+	OutboxKindPaymentAdminNotice  = "payment_admin_notice"
+	OutboxKindAssistantWelcome    = "assistant_welcome"
+	OutboxKindInventoryRequest    = "inventory_request"
+	OutboxKindInventoryRejected   = "inventory_request_rejected"
+	OutboxKindFleetAvailability   = "fleet_availability"
+	OutboxKindUnpaidOrders        = "unpaid_orders"
+	OutboxKindDocumentExpiry      = "document_expiry"
+	OutboxKindAssistantOrderAlert = "assistant_order_failed"
+	OutboxKindSubscriptionNotice  = "subscription_notice"
+)
+
+// outboxMaxAttempts is how often a message is tried before it is dead,
+// OUTBOX_MAX_ATTEMPTS (default 8, the last one about two hours after the first)
+var outboxMaxAttempts = func() int {
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && n > 0 {
+		return n
+	}
+	return 8
+}()
+
+// errOutboxUnavailable is returned by a nil OutboxService
+var errOutboxUnavailable = errors.New("outbox is not configured")
+
+// OutboxService queues WhatsApp messages and emails. Call sites only store the
+// message; OutboxWorker sends it and retries failures.
+type OutboxService struct {
+	repo *repository.OutboxRepository
+}
+
+func NewOutboxService(repo *repository.OutboxRepository) *OutboxService {
+	return &OutboxService{repo: repo}
+}
+
+// EnqueueWhatsApp queues a WhatsApp message. Messages of an organization count
+// against its WhatsApp send limit; organizationID may be empty for messages
+// that are not sent on behalf of one.
+func (s *OutboxService) EnqueueWhatsApp(ctx context.Context, organizationID, kind, phone, message string) error {
+	return s.enqueue(ctx, &model.OutboxMessage{
+		OrganizationID: organizationID,
+		Channel:        model.OutboxChannelWhatsApp,
+		Kind:           kind,
+		Recipient:      strings.TrimSpace(phone),
+		Body:           message,
+	})
+}
+
+// EnqueueEmail queues a rendered email
+func (s *OutboxService) EnqueueEmail(ctx context.Context, organizationID, kind string, email *helper.Email) error {
+	return s.enqueue(ctx, &model.OutboxMessage{
+		OrganizationID: organizationID,
+		Channel:        model.OutboxChannelEmail,
+		Kind:           kind,
+		Recipient:      strings.TrimSpace(email.To),
+		Subject:        email.Subject,
+		Body:           email.HTMLBody,
+	})
+}
+
+func (s *OutboxService) enqueue(ctx context.Context, msg *model.OutboxMessage) error {
+	if s == nil {
+		return errOutboxUnavailable
+	}
+	if msg.Recipient == "" {
+		return fmt.Errorf("outbox %s message has no recipient", msg.Kind)
+	}
+	msg.MessageID = uuid.New().String()
+	msg.MaxAttempts = outboxMaxAttempts
+	if err := s.repo.Create(ctx, msg, time.Now()); err != nil {
+		return fmt.Errorf("queue %s message: %w", msg.Kind, err)
+	}
+	return nil
+}
+
+// List returns queued, sent and dead messages for the admin endpoint
+func (s *OutboxService) List(ctx context.Context, filter model.OutboxMessageFilter) ([]model.OutboxMessage, error) {
+	items, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get outbox messages")
+	}
+	return items, nil
+}
+
+// Resend queues a dead message again with a fresh set of attempts
+func (s *OutboxService) Resend(ctx context.Context, messageID string) (*model.OutboxMessage, error) {
+	msg, err := s.repo.Get(ctx, messageID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get outbox message")
+	}
+	if msg == nil {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "outbox message not found")
+	}
+	if msg.Status != model.OutboxStatusDead {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "only dead messages can be resent")
+	}
+	requeued, err := s.repo.Requeue(ctx, messageID, time.Now())
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to resend outbox message")
+	}
+	if !requeued {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusConflict, "message was resent already")
+	}
+	return s.repo.Get(ctx, messageID)
+}
+
+const (
+	outboxPollInterval = 2 * time.Second
+	outboxLease        = 2 * time.Minute
+	outboxFirstRetry   = 30 * time.Second
+	outboxMaxRetry     = time.Hour
+)
+
+// outboxRetryDelay is the wait after the given number of failed attempts:
+// 30s, 1m, 2m, ... up to an hour
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxFirstRetry
+	for i := 1; i < attempts && delay < outboxMaxRetry; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxRetry {
+		delay = outboxMaxRetry
+	}
+	return delay
+}
+
+// OutboxWorker sends due outbox messages with a pool of OUTBOX_WORKERS
+// goroutines (default 4)
+type OutboxWorker struct {
+	repo    *repository.OutboxRepository
+	workers int
+
+	sendWhatsApp func(phone, message string) error
+	sendEmail    func(email *helper.Email) error
+	allowSend    func(organizationID string) (bool, time.Duration, error)
+}
+
+// NewOutboxWorker sends WhatsApp messages through wagyClient and emails with
+// emailCfg. Without a Wagy client WhatsApp messages fail until they are dead.
+func NewOutboxWorker(repo *repository.OutboxRepository, wagyClient *wagy.WagyClient, emailCfg *configs.EmailConfig) *OutboxWorker {
+	workers := 4
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_WORKERS")); err == nil && n > 0 {
+		workers = n
+	}
+	return &OutboxWorker{
+		repo:    repo,
+		workers: workers,
+		sendWhatsApp: func(phone, message string) error {
+			if wagyClient == nil {
+				return errors.New("wagy client is not configured")
+			}
+			_, err := wagyClient.SendMessage(phone, message)
+			return err
+		},
+		sendEmail: func(email *helper.Email) error {
+			return helper.SendEmail(emailCfg, email)
+		},
+		allowSend: func(organizationID string) (bool, time.Duration, error) {
+			return helper.AllowOrganizationSend(model.OutboxChannelWhatsApp, organizationID)
+		},
+	}
+}
+
+// Run polls for due messages until stop is cancelled. A batch that is being
+// sent is finished first.
+func (w *OutboxWorker) Run(stop context.Context) {
+	batchSize := w.workers * 4
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop.Done():
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := w.repo.ClaimDue(stop, batchSize, time.Now(), outboxLease)
+		if err != nil {
+			slog.ErrorContext(stop, "outbox: failed to claim messages", "error", err)
+		}
+		w.sendBatch(claimed)
+
+		// a full batch means more are probably due
+		if len(claimed) == batchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(outboxPollInterval)
+		}
+	}
+}
+
+func (w *OutboxWorker) sendBatch(messages []model.OutboxMessage) {
+	sem := make(chan struct{}, w.workers)
+	var wg sync.WaitGroup
+	for i := range messages {
+		msg := messages[i]
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			w.process(&msg)
+		}()
+	}
+	wg.Wait()
+}
+
+// attempt sends msg once. When the organization is over its WhatsApp limit it
+// is not sent and the wait until it may be tried again is returned.
+func (w *OutboxWorker) attempt(ctx context.Context, msg *model.OutboxMessage) (time.Duration, error) {
+	switch msg.Channel {
+	case model.OutboxChannelWhatsApp:
+		if msg.OrganizationID != "" {
+			allowed, retryAfter, err := w.allowSend(msg.OrganizationID)
+			if err != nil {
+				slog.ErrorContext(ctx, "outbox: WhatsApp rate limit unavailable", "error", err)
+			}
+			if !allowed {
+				return retryAfter, nil
+			}
+		}
+		return 0, w.sendWhatsApp(msg.Recipient, msg.Body)
+	case model.OutboxChannelEmail:
+		return 0, w.sendEmail(&helper.Email{To: msg.Recipient, Subject: msg.Subject, HTMLBody: msg.Body})
+	default:
+		return 0, fmt.Errorf("unknown outbox channel %q", msg.Channel)
+	}
+}
+
+func (w *OutboxWorker) process(msg *model.OutboxMessage) {
+	// the batch is finished even when the worker is being stopped
+	ctx := context.Background()
+	deferFor, sendErr := w.attempt(ctx, msg)
+	now := time.Now()
+
+	var err error
+	switch {
+	case deferFor > 0:
+		err = w.repo.Reschedule(ctx, msg.MessageID, msg.Attempts, msg.LastError, now.Add(deferFor), now)
+	case sendErr == nil:
+		err = w.repo.MarkSent(ctx, msg.MessageID, msg.Attempts+1, now)
+		w.recordAssistantStat(ctx, msg, 1)
+	case msg.Attempts+1 >= msg.MaxAttempts:
+		slog.ErrorContext(ctx, "outbox: giving up", "kind", msg.Kind, "message_id", msg.MessageID, "recipient", msg.Recipient, "attempts", msg.Attempts+1, "error", sendErr)
+		err = w.repo.MarkDead(ctx, msg.MessageID, msg.Attempts+1, sendErr.Error(), now)
+		w.recordAssistantStat(ctx, msg, 2)
+	default:
+		err = w.repo.Reschedule(ctx, msg.MessageID, msg.Attempts+1, sendErr.Error(), now.Add(outboxRetryDelay(msg.Attempts+1)), now)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "outbox: failed to update message", "message_id", msg.MessageID, "error", err)
+	}
+}
+
+// recordAssistantStat counts an organization's WhatsApp message once it is
+// delivered or given up on
+func (w *OutboxWorker) recordAssistantStat(ctx context.Context, msg *model.OutboxMessage, status int) {
+	if msg.Channel != model.OutboxChannelWhatsApp || msg.OrganizationID == "" {
+		return
+	}
+	if err := w.repo.RecordAssistantStat(database.WithOrganization(ctx, msg.OrganizationID), status); err != nil {
+		slog.ErrorContext(ctx, "outbox: failed to record assistant stat", "organization_id", msg.OrganizationID, "error", err)
+	}
+}
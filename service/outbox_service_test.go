@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-travego/helper"
+	"service-travego/model"
+)
+
+func TestOutboxRetryDelayDoublesUpToAnHour(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		8:  time.Hour,
+		20: time.Hour,
+	}
+	for attempts, want := range cases {
+		if got := outboxRetryDelay(attempts); got != want {
+			t.Errorf("outboxRetryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestOutboxAttemptDefersRateLimitedWhatsApp(t *testing.T) {
+	sent := 0
+	w := &OutboxWorker{
+		sendWhatsApp: func(phone, message string) error { sent++; return nil },
+		allowSend: func(organizationID string) (bool, time.Duration, error) {
+			return false, 40 * time.Second, nil
+		},
+	}
+
+	deferFor, err := w.attempt(context.Background(), &model.OutboxMessage{Channel: model.OutboxChannelWhatsApp, OrganizationID: "org-1", Recipient: "62811"})
+	if err != nil || deferFor != 40*time.Second {
+		t.Fatalf("expected the message to wait 40s, got %v, %v", deferFor, err)
+	}
+	if sent != 0 {
+		t.Fatal("rate limited message was sent")
+	}
+
+	// messages not sent on behalf of an organization are not limited
+	if deferFor, err = w.attempt(context.Background(), &model.OutboxMessage{Channel: model.OutboxChannelWhatsApp, Recipient: "62811"}); err != nil || deferFor != 0 || sent != 1 {
+		t.Fatalf("expected the message to be sent, got %v, %v, sent %d", deferFor, err, sent)
+	}
+}
+
+func TestOutboxAttemptSendsWhenRateLimitIsUnavailable(t *testing.T) {
+	w := &OutboxWorker{
+		sendWhatsApp: func(phone, message string) error { return errors.New("wagy down") },
+		allowSend: func(organizationID string) (bool, time.Duration, error) {
+			return true, 0, errors.New("redis down")
+		},
+	}
+
+	deferFor, err := w.attempt(context.Background(), &model.OutboxMessage{Channel: model.OutboxChannelWhatsApp, OrganizationID: "org-1", Recipient: "62811"})
+	if deferFor != 0 || err == nil || err.Error() != "wagy down" {
+		t.Fatalf("expected the send error, got %v, %v", deferFor, err)
+	}
+}
+
+func TestOutboxAttemptSendsEmail(t *testing.T) {
+	var got *helper.Email
+	w := &OutboxWorker{sendEmail: func(email *helper.Email) error { got = email; return nil }}
+
+	msg := &model.OutboxMessage{Channel: model.OutboxChannelEmail, Recipient: "a@b.c", Subject: "Hi", Body: "<p>Hi</p>"}
+	if _, err := w.attempt(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.To != "a@b.c" || got.Subject != "Hi" || got.HTMLBody != "<p>Hi</p>" {
+		t.Fatalf("unexpected email %+v", got)
+	}
+
+	if _, err := w.attempt(context.Background(), &model.OutboxMessage{Channel: "fax"}); err == nil {
+		t.Fatal("expected an error for an unknown channel")
+	}
+}
+
+func TestNilOutboxServiceRefusesToQueue(t *testing.T) {
+	var s *OutboxService
+	if err := s.EnqueueWhatsApp(context.Background(), "org-1", OutboxKindUnpaidOrders, "62811", "hi"); err == nil {
+		t.Fatal("expected an error from a nil outbox")
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"service-travego/model"
 	"service-travego/repository"
@@ -8,33 +9,44 @@ import (
 )
 
 type PartnerService struct {
-	repo *repository.PartnerRepository
+	repo         *repository.PartnerRepository
+	entitlements *EntitlementService
 }
 
 func NewPartnerService(repo *repository.PartnerRepository) *PartnerService {
 	return &PartnerService{repo: repo}
 }
 
-func (s *PartnerService) List(orgID, partnerName, startDate, endDate string) ([]model.OperationPartner, error) {
-	return s.repo.List(orgID, partnerName, startDate, endDate)
+// SetEntitlementService limits new partners to the organization's package
+func (s *PartnerService) SetEntitlementService(entitlements *EntitlementService) {
+	s.entitlements = entitlements
 }
 
-func (s *PartnerService) Create(req model.CreateOperationPartnerRequest, orgID, userID string) (*model.OperationPartner, error) {
-	return s.repo.Create(req, orgID, userID)
+func (s *PartnerService) List(ctx context.Context, partnerName, startDate, endDate string) ([]model.OperationPartner, error) {
+	return s.repo.List(ctx, partnerName, startDate, endDate)
 }
 
-func (s *PartnerService) Update(req model.UpdateOperationPartnerRequest, orgID, userID string) (*model.OperationPartner, error) {
-	partner, err := s.repo.GetByID(req.PartnerID, orgID, nil)
+func (s *PartnerService) Create(ctx context.Context, req model.CreateOperationPartnerRequest, userID string) (*model.OperationPartner, error) {
+	var partner *model.OperationPartner
+	err := s.entitlements.Reserve(ctx, map[string]int{model.QuotaPartners: 1}, func() (err error) {
+		partner, err = s.repo.Create(ctx, req, userID)
+		return err
+	})
+	return partner, err
+}
+
+func (s *PartnerService) Update(ctx context.Context, req model.UpdateOperationPartnerRequest, userID string) (*model.OperationPartner, error) {
+	partner, err := s.repo.GetByID(ctx, req.PartnerID, nil)
 	if err != nil {
 		return nil, err
 	}
 	if partner == nil {
 		return nil, errors.New("partner not found")
 	}
-	return s.repo.Update(req, orgID, userID)
+	return s.repo.Update(ctx, req, userID)
 }
 
-func (s *PartnerService) Detail(req *model.OperationPartnerDetailRequest, orgID string) (*model.OperationPartnerDetailResponse, error) {
+func (s *PartnerService) Detail(ctx context.Context, req *model.OperationPartnerDetailRequest) (*model.OperationPartnerDetailResponse, error) {
 	if req == nil || req.PartnerID == "" {
 		return nil, errors.New("partner not found")
 	}
@@ -58,17 +70,17 @@ func (s *PartnerService) Detail(req *model.OperationPartnerDetailRequest, orgID
 
 	go func() {
 		defer wg.Done()
-		partner, partnerErr = s.repo.GetByID(req.PartnerID, orgID, req)
+		partner, partnerErr = s.repo.GetByID(ctx, req.PartnerID, req)
 	}()
 
 	go func() {
 		defer wg.Done()
-		totalRevenue, totalExpenses, totalBooking, metricsErr = s.repo.GetDetailMetrics(req.PartnerID, orgID, req)
+		totalRevenue, totalExpenses, totalBooking, metricsErr = s.repo.GetDetailMetrics(ctx, req.PartnerID, req)
 	}()
 
 	go func() {
 		defer wg.Done()
-		units, err := s.repo.GetPartnerFleetUnits(req.PartnerID, orgID, req)
+		units, err := s.repo.GetPartnerFleetUnits(ctx, req.PartnerID, req)
 		if err == nil {
 			fleetUnits = units
 		} else {
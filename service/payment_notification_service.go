@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"service-travego/internal/paymentgateway"
+	"service-travego/model"
+
+	"github.com/google/uuid"
+)
+
+func (s *paymentService) midtransServerKey() string {
+	if s.midtransConfig != nil && s.midtransConfig.Client.ServerKey != "" {
+		return s.midtransConfig.Client.ServerKey
+	}
+	return os.Getenv("MIDTRANS_SERVER_KEY")
+}
+
+// verifyMidtransSignature checks signature_key, the SHA512 of order_id +
+// status_code + gross_amount + server key. Without a server key nothing
+// verifies.
+func (s *paymentService) verifyMidtransSignature(req *model.MidtransWebhookRequest) bool {
+	serverKey := s.midtransServerKey()
+	if serverKey == "" || req.SignatureKey == "" {
+		return false
+	}
+	sum := sha512.Sum512([]byte(req.OrderID + req.StatusCode + req.GrossAmount + serverKey))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(req.SignatureKey))) == 1
+}
+
+// midtransNotificationRecord describes a Midtrans notification for
+// payment_notifications. Notifications without a transaction_id (a link
+// Midtrans never saw) are keyed on the order ID instead.
+func midtransNotificationRecord(source string, payload []byte, req *model.MidtransWebhookRequest) *model.PaymentNotificationRecord {
+	txID := req.TransactionID
+	if txID == "" {
+		txID = req.OrderID
+	}
+	status := strings.ToLower(req.TransactionStatus)
+	return &model.PaymentNotificationRecord{
+		NotificationID:    uuid.New().String(),
+		Provider:          paymentgateway.ProviderMidtrans,
+		Source:            source,
+		EventKey:          paymentgateway.ProviderMidtrans + ":" + txID + ":" + status,
+		InvoiceNumber:     req.OrderID,
+		TransactionID:     req.TransactionID,
+		TransactionStatus: status,
+		Payload:           string(payload),
+	}
+}
+
+func xenditNotificationRecord(payload []byte, req *model.XenditInvoiceCallback) *model.PaymentNotificationRecord {
+	status := strings.ToUpper(req.Status)
+	return &model.PaymentNotificationRecord{
+		NotificationID:    uuid.New().String(),
+		Provider:          paymentgateway.ProviderXendit,
+		Source:            model.NotificationSourceWebhook,
+		EventKey:          paymentgateway.ProviderXendit + ":" + req.ID + ":" + status,
+		InvoiceNumber:     req.ExternalID,
+		TransactionID:     req.ID,
+		TransactionStatus: status,
+		Payload:           string(payload),
+	}
+}
+
+// rejectNotification stores a notification that failed verification; it is
+// never processed or replayed.
+func (s *paymentService) rejectNotification(ctx context.Context, rec *model.PaymentNotificationRecord, reason string) {
+	rec.EventKey = ""
+	rec.Outcome = model.NotificationOutcomeRejected
+	rec.Message = reason
+	if _, err := s.repo.InsertPaymentNotification(ctx, rec); err != nil {
+		slog.ErrorContext(ctx, "payment notification: store rejected", "invoice_number", rec.InvoiceNumber, "error", err)
+	}
+}
+
+// receiveNotification stores a verified notification and applies it once per
+// event. A repeated delivery is stored as a duplicate and not applied again; a
+// failed one releases the event so the gateway's retry is applied.
+func (s *paymentService) receiveNotification(ctx context.Context, rec *model.PaymentNotificationRecord, apply func() error) error {
+	rec.Outcome = model.NotificationOutcomeProcessing
+	claimed, err := s.repo.InsertPaymentNotification(ctx, rec)
+	if err != nil {
+		return fmt.Errorf("failed to store payment notification: %w", err)
+	}
+	if !claimed {
+		rec.EventKey = ""
+		rec.Outcome = model.NotificationOutcomeDuplicate
+		if _, err := s.repo.InsertPaymentNotification(ctx, rec); err != nil {
+			slog.ErrorContext(ctx, "payment notification: store duplicate", "invoice_number", rec.InvoiceNumber, "error", err)
+		}
+		return nil
+	}
+
+	if applyErr := apply(); applyErr != nil {
+		if err := s.repo.UpdatePaymentNotificationOutcome(ctx, rec.NotificationID, model.NotificationOutcomeFailed, applyErr.Error(), true); err != nil {
+			slog.ErrorContext(ctx, "payment notification: update", "notification_id", rec.NotificationID, "error", err)
+		}
+		return applyErr
+	}
+	if err := s.repo.UpdatePaymentNotificationOutcome(ctx, rec.NotificationID, model.NotificationOutcomeProcessed, "", false); err != nil {
+		slog.ErrorContext(ctx, "payment notification: update", "notification_id", rec.NotificationID, "error", err)
+	}
+	return nil
+}
+
+// HandleMidtransNotification verifies and applies a Midtrans webhook.
+func (s *paymentService) HandleMidtransNotification(ctx context.Context, payload []byte, req *model.MidtransWebhookRequest) error {
+	rec := midtransNotificationRecord(model.NotificationSourceWebhook, payload, req)
+	if !s.verifyMidtransSignature(req) {
+		s.rejectNotification(ctx, rec, "invalid signature key")
+		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "invalid signature key")
+	}
+	return s.receiveNotification(ctx, rec, func() error {
+		return s.applyPaymentNotification(ctx, midtransNotification(req))
+	})
+}
+
+// ListPaymentNotifications returns stored notifications for staff.
+func (s *paymentService) ListPaymentNotifications(ctx context.Context, filter model.PaymentNotificationFilter) ([]model.PaymentNotificationRecord, error) {
+	items, err := s.repo.ListPaymentNotifications(ctx, filter)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get payment notifications")
+	}
+	return items, nil
+}
+
+// ReplayPaymentNotification applies a stored notification again, e.g. one
+// that failed while the order was being fixed. Only failed notifications can
+// be replayed, and only while no other notification of the same event was
+// processed; processed, duplicate and rejected ones would be applied twice or
+// were never verified.
+func (s *paymentService) ReplayPaymentNotification(ctx context.Context, notificationID string) (*model.PaymentNotificationRecord, error) {
+	rec, err := s.repo.GetPaymentNotification(ctx, notificationID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get payment notification")
+	}
+	if rec == nil {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "payment notification not found")
+	}
+	if rec.Outcome != model.NotificationOutcomeFailed {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "only failed notifications can be replayed")
+	}
+
+	eventKey := rec.Provider + ":" + rec.TransactionID + ":" + rec.TransactionStatus
+	if rec.Provider == paymentgateway.ProviderMidtrans && rec.TransactionID == "" {
+		eventKey = rec.Provider + ":" + rec.InvoiceNumber + ":" + rec.TransactionStatus
+	}
+	// the event key is claimed before applying so a delivery or replay of the
+	// same event that is processed meanwhile cannot be applied as well
+	claimed, err := s.repo.ClaimPaymentNotificationEvent(ctx, rec.NotificationID, eventKey)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to replay payment notification")
+	}
+	if !claimed {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusConflict, "this payment event was already processed")
+	}
+
+	if applyErr := s.applyStoredNotification(ctx, rec); applyErr != nil {
+		if err := s.repo.UpdatePaymentNotificationOutcome(ctx, rec.NotificationID, model.NotificationOutcomeFailed, applyErr.Error(), true); err != nil {
+			slog.ErrorContext(ctx, "payment notification: update", "notification_id", rec.NotificationID, "error", err)
+		}
+		rec.Outcome = model.NotificationOutcomeFailed
+		rec.Message = applyErr.Error()
+	} else {
+		if err := s.repo.UpdatePaymentNotificationOutcome(ctx, rec.NotificationID, model.NotificationOutcomeProcessed, "", false); err != nil {
+			slog.ErrorContext(ctx, "payment notification: update", "notification_id", rec.NotificationID, "error", err)
+		}
+		rec.EventKey = eventKey
+		rec.Outcome = model.NotificationOutcomeProcessed
+		rec.Message = ""
+	}
+	rec.Attempts++
+	return rec, nil
+}
+
+func (s *paymentService) applyStoredNotification(ctx context.Context, rec *model.PaymentNotificationRecord) error {
+	switch rec.Provider {
+	case paymentgateway.ProviderMidtrans:
+		var req model.MidtransWebhookRequest
+		if err := json.Unmarshal([]byte(rec.Payload), &req); err != nil {
+			return fmt.Errorf("invalid stored payload: %w", err)
+		}
+		if rec.Source == model.NotificationSourceOrderWebhook {
+			return s.markOrderWaitingApproval(ctx, &req)
+		}
+		return s.applyPaymentNotification(ctx, midtransNotification(&req))
+	case paymentgateway.ProviderXendit:
+		var req model.XenditInvoiceCallback
+		if err := json.Unmarshal([]byte(rec.Payload), &req); err != nil {
+			return fmt.Errorf("invalid stored payload: %w", err)
+		}
+		return s.applyPaymentNotification(ctx, xenditNotification(&req))
+	}
+	return fmt.Errorf("unknown payment provider %q", rec.Provider)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"service-travego/internal/paymentgateway"
+	"service-travego/model"
+)
+
+func signedMidtransWebhook(serverKey string) *model.MidtransWebhookRequest {
+	req := &model.MidtransWebhookRequest{
+		OrderID:           "TRV-0001",
+		TransactionID:     "tx-1",
+		TransactionStatus: "expire",
+		StatusCode:        "407",
+		GrossAmount:       "150000.00",
+	}
+	sum := sha512.Sum512([]byte(req.OrderID + req.StatusCode + req.GrossAmount + serverKey))
+	req.SignatureKey = hex.EncodeToString(sum[:])
+	return req
+}
+
+func TestHandleMidtransNotificationRejectsInvalidSignature(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &stubPaymentRepo{}
+	svc := &paymentService{repo: repo}
+
+	req := signedMidtransWebhook("other-key")
+	err := svc.HandleMidtransNotification(context.Background(), []byte(`{}`), req)
+	if GetStatusCode(err) != 401 {
+		t.Fatalf("expected 401, got %v", err)
+	}
+	if len(repo.expired) != 0 {
+		t.Fatalf("rejected notification was applied: %v", repo.expired)
+	}
+	if len(repo.notifications) != 1 || repo.notifications[0].Outcome != model.NotificationOutcomeRejected {
+		t.Fatalf("expected one rejected notification, got %+v", repo.notifications)
+	}
+}
+
+func TestHandleMidtransNotificationIsIdempotent(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &stubPaymentRepo{}
+	svc := &paymentService{repo: repo}
+
+	for i := 0; i < 2; i++ {
+		if err := svc.HandleMidtransNotification(context.Background(), []byte(`{}`), signedMidtransWebhook("server-key")); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+	if len(repo.expired) != 1 {
+		t.Fatalf("expected the notification to be applied once, got %v", repo.expired)
+	}
+	if len(repo.notifications) != 2 ||
+		repo.notifications[0].Outcome != model.NotificationOutcomeProcessed ||
+		repo.notifications[1].Outcome != model.NotificationOutcomeDuplicate {
+		t.Fatalf("unexpected stored notifications %+v", repo.notifications)
+	}
+}
+
+// failedMidtransNotification is a stored webhook that failed to apply, with
+// its event key released as receiveNotification leaves it
+func failedMidtransNotification(t *testing.T, id string) model.PaymentNotificationRecord {
+	t.Helper()
+	payload, err := json.Marshal(signedMidtransWebhook("server-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model.PaymentNotificationRecord{
+		NotificationID:    id,
+		Provider:          paymentgateway.ProviderMidtrans,
+		Source:            model.NotificationSourceWebhook,
+		InvoiceNumber:     "TRV-0001",
+		TransactionID:     "tx-1",
+		TransactionStatus: "expire",
+		Payload:           string(payload),
+		Outcome:           model.NotificationOutcomeFailed,
+	}
+}
+
+func TestReplayPaymentNotificationAppliesAFailedNotification(t *testing.T) {
+	repo := &stubPaymentRepo{notifications: []model.PaymentNotificationRecord{failedMidtransNotification(t, "n-1")}}
+	svc := &paymentService{repo: repo}
+
+	rec, err := svc.ReplayPaymentNotification(context.Background(), "n-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Outcome != model.NotificationOutcomeProcessed || len(repo.expired) != 1 {
+		t.Fatalf("outcome %s, applied %v", rec.Outcome, repo.expired)
+	}
+	if repo.notifications[0].EventKey != "midtrans:tx-1:expire" {
+		t.Fatalf("event key = %q, want it claimed", repo.notifications[0].EventKey)
+	}
+}
+
+func TestReplayPaymentNotificationRejectsAProcessedNotification(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &stubPaymentRepo{}
+	svc := &paymentService{repo: repo}
+	if err := svc.HandleMidtransNotification(context.Background(), []byte(`{}`), signedMidtransWebhook("server-key")); err != nil {
+		t.Fatal(err)
+	}
+	processed := repo.notifications[0].NotificationID
+
+	if _, err := svc.ReplayPaymentNotification(context.Background(), processed); GetStatusCode(err) != 400 {
+		t.Fatalf("expected 400, got %v", err)
+	}
+	if len(repo.expired) != 1 {
+		t.Fatalf("replay applied the notification again: %v", repo.expired)
+	}
+}
+
+func TestReplayPaymentNotificationRejectsAnEventProcessedByAnotherNotification(t *testing.T) {
+	t.Setenv("MIDTRANS_SERVER_KEY", "server-key")
+	repo := &stubPaymentRepo{notifications: []model.PaymentNotificationRecord{failedMidtransNotification(t, "n-1")}}
+	svc := &paymentService{repo: repo}
+	// the gateway's retry of the same event went through
+	if err := svc.HandleMidtransNotification(context.Background(), []byte(`{}`), signedMidtransWebhook("server-key")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ReplayPaymentNotification(context.Background(), "n-1"); GetStatusCode(err) != 409 {
+		t.Fatalf("expected 409, got %v", err)
+	}
+	if len(repo.expired) != 1 {
+		t.Fatalf("replay applied the event again: %v", repo.expired)
+	}
+}
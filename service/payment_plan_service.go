@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"service-travego/model"
+	"service-travego/repository"
+	"strings"
+	"time"
+)
+
+type PaymentPlanService struct {
+	repo *repository.PaymentPlanRepository
+}
+
+func NewPaymentPlanService(repo *repository.PaymentPlanRepository) *PaymentPlanService {
+	return &PaymentPlanService{repo: repo}
+}
+
+func (s *PaymentPlanService) internalMessage(base string, err error) string {
+	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
+		return fmt.Sprintf("%s: %v", base, err)
+	}
+	return base
+}
+
+func (s *PaymentPlanService) validate(ctx context.Context, userID string, req *model.PaymentPlanUpsertRequest) error {
+	req.OrganizationID = organizationFromContext(ctx)
+	req.UserID = userID
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "name is required")
+	}
+	if len(req.Terms) == 0 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "terms is required")
+	}
+
+	var total float64
+	for i := range req.Terms {
+		t := &req.Terms[i]
+		t.Seq = i + 1
+		t.Label = strings.TrimSpace(t.Label)
+		t.DueType = strings.ToUpper(strings.TrimSpace(t.DueType))
+		if _, ok := model.PaymentTermDueTypeLabel[t.DueType]; !ok {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "due_type must be BOOKING or BEFORE_START")
+		}
+		if t.Percentage <= 0 || t.Percentage > 100 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "percentage must be between 0 and 100")
+		}
+		if t.DueDays < 0 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "due_days must not be negative")
+		}
+		if t.Label == "" {
+			t.Label = defaultTermLabel(i, len(req.Terms))
+		}
+		total += t.Percentage
+	}
+	if math.Abs(total-100) > 0.01 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "terms percentage must add up to 100")
+	}
+	return nil
+}
+
+func defaultTermLabel(idx, count int) string {
+	switch {
+	case count == 1 || idx == count-1:
+		return "Pelunasan"
+	case idx == 0:
+		return "DP"
+	default:
+		return fmt.Sprintf("Termin %d", idx+1)
+	}
+}
+
+func (s *PaymentPlanService) List(ctx context.Context) ([]model.PaymentPlan, error) {
+	items, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get payment plans", err))
+	}
+	return items, nil
+}
+
+// ListActive returns the plans offered to customers on the order form.
+func (s *PaymentPlanService) ListActive(ctx context.Context) ([]model.PaymentPlan, error) {
+	items, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get payment plans", err))
+	}
+	return items, nil
+}
+
+func (s *PaymentPlanService) Create(ctx context.Context, userID string, req *model.PaymentPlanUpsertRequest) (string, error) {
+	req.PlanID = ""
+	if err := s.validate(ctx, userID, req); err != nil {
+		return "", err
+	}
+	id, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create payment plan", err))
+	}
+	return id, nil
+}
+
+func (s *PaymentPlanService) Update(ctx context.Context, userID string, req *model.PaymentPlanUpsertRequest) error {
+	if strings.TrimSpace(req.PlanID) == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "plan_id is required")
+	}
+	if err := s.validate(ctx, userID, req); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, req); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "payment plan not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update payment plan", err))
+	}
+	return nil
+}
+
+func (s *PaymentPlanService) Delete(ctx context.Context, userID, planID string) error {
+	if err := s.repo.Delete(ctx, userID, planID); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "payment plan not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete payment plan")
+	}
+	return nil
+}
+
+// ResolvePlan returns the plan picked on an order, or the organization's
+// default plan when none was picked. It returns nil when neither exists.
+func (s *PaymentPlanService) ResolvePlan(ctx context.Context, planID string) (*model.PaymentPlan, error) {
+	planID = strings.TrimSpace(planID)
+	if planID == "" {
+		plan, err := s.repo.GetDefault(ctx)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get payment plan", err))
+		}
+		return plan, nil
+	}
+
+	plan, err := s.repo.GetByID(ctx, planID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "PAYMENT_PLAN_NOT_FOUND")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get payment plan", err))
+	}
+	if !plan.Active {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "PAYMENT_PLAN_INACTIVE")
+	}
+	return plan, nil
+}
+
+// BuildInstallments splits an order total over the plan terms. Due dates are
+// kept between the booking date and the start date, and the last installment
+// absorbs the rounding so the schedule always adds up to the total.
+func BuildInstallments(plan *model.PaymentPlan, total float64, createdAt, startDate time.Time) []model.OrderInstallment {
+	if plan == nil || len(plan.Terms) == 0 {
+		return nil
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	bookingDay := time.Date(createdAt.Year(), createdAt.Month(), createdAt.Day(), 0, 0, 0, 0, createdAt.Location())
+	startDay := time.Time{}
+	if !startDate.IsZero() {
+		startDay = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	}
+
+	out := make([]model.OrderInstallment, 0, len(plan.Terms))
+	var allocated float64
+	for i, t := range plan.Terms {
+		amount := math.Round(total * t.Percentage / 100)
+		if i == len(plan.Terms)-1 {
+			amount = total - allocated
+		}
+		if amount < 0 {
+			amount = 0
+		}
+		allocated += amount
+
+		due := bookingDay.AddDate(0, 0, t.DueDays)
+		if t.DueType == model.PaymentTermDueBeforeStart && !startDay.IsZero() {
+			due = startDay.AddDate(0, 0, -t.DueDays)
+		}
+		if !startDay.IsZero() && due.After(startDay) {
+			due = startDay
+		}
+		if due.Before(bookingDay) {
+			due = bookingDay
+		}
+
+		out = append(out, model.OrderInstallment{
+			PlanID:      plan.PlanID,
+			Seq:         i + 1,
+			Label:       t.Label,
+			Percentage:  t.Percentage,
+			Amount:      amount,
+			DueDate:     due.Format("2006-01-02"),
+			Status:      model.InstallmentStatusUnpaid,
+			StatusLabel: model.InstallmentStatusLabel[model.InstallmentStatusUnpaid],
+		})
+	}
+	return out
+}
+
+// OrderInstallments returns the installment schedule of an order.
+func (s *PaymentPlanService) OrderInstallments(ctx context.Context, orderID string) ([]model.OrderInstallment, error) {
+	items, err := s.repo.ListOrderInstallments(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get order installments", err))
+	}
+	return items, nil
+}
+
+// ApplyToOrder (re)builds the installment schedule of an existing fleet order.
+func (s *PaymentPlanService) ApplyToOrder(ctx context.Context, req *model.PaymentPlanApplyRequest) ([]model.OrderInstallment, error) {
+	orderID := strings.TrimSpace(req.OrderID)
+	plan, err := s.ResolvePlan(ctx, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	total, createdAt, startDate, err := s.repo.GetFleetOrderSchedule(ctx, orderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get order", err))
+	}
+
+	items := BuildInstallments(plan, total, createdAt, startDate)
+	if err := s.repo.ReplaceOrderInstallments(ctx, orderID, model.InstallmentOrderFleet, items); err != nil {
+		if errors.Is(err, repository.ErrInstallmentsLocked) {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "INSTALLMENTS_ALREADY_IN_PAYMENT")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to save order installments", err))
+	}
+	return s.OrderInstallments(ctx, orderID)
+}
+
+// GetInstallment returns one installment of an order.
+func (s *PaymentPlanService) GetInstallment(ctx context.Context, orderID, installmentID string) (*model.OrderInstallment, error) {
+	it, err := s.repo.GetInstallment(ctx, orderID, strings.TrimSpace(installmentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "installment not found")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get installment", err))
+	}
+	return it, nil
+}
+
+// SetPaymentLink stores the payment gateway invoice issued for an installment.
+func (s *PaymentPlanService) SetPaymentLink(ctx context.Context, installmentID, invoiceNumber, paymentGateway, snapToken, redirectURL string, at time.Time) error {
+	if err := s.repo.SetInstallmentPaymentLink(ctx, installmentID, invoiceNumber, paymentGateway, snapToken, redirectURL, at); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "installment already paid")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to save installment payment link", err))
+	}
+	return nil
+}
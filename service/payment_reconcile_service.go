@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"service-travego/database"
+	"service-travego/internal/midtransapi"
+	"service-travego/model"
+)
+
+// Invoices younger than reconcileMinAge are left to the webhook; Snap links
+// Midtrans never saw a payment attempt for are expired after snapLinkLifetime.
+const (
+	reconcileMinAge  = 15 * time.Minute
+	snapLinkLifetime = 24 * time.Hour
+)
+
+// classifyMidtransStatus maps a Midtrans transaction_status to the payment
+// status. A capture is only paid once the fraud check accepted it.
+func classifyMidtransStatus(transactionStatus, fraudStatus string) string {
+	switch strings.ToLower(transactionStatus) {
+	case midtransapi.StatusSettlement:
+		return model.PaymentNotificationPaid
+	case midtransapi.StatusCapture:
+		if fraudStatus == "" || strings.EqualFold(fraudStatus, "accept") {
+			return model.PaymentNotificationPaid
+		}
+		return model.PaymentNotificationPending
+	case midtransapi.StatusExpire, midtransapi.StatusDeny, midtransapi.StatusCancel, midtransapi.StatusFailure:
+		return model.PaymentNotificationFailed
+	case midtransapi.StatusRefund, midtransapi.StatusPartialRefund:
+		return model.PaymentNotificationRefunded
+	}
+	return model.PaymentNotificationPending
+}
+
+func (s *paymentService) midtransAPI() (*midtransapi.Client, error) {
+	if s.midtransConfig == nil || s.midtransConfig.API == nil {
+		return nil, errors.New("midtrans api is not configured")
+	}
+	return s.midtransConfig.API, nil
+}
+
+// processFailedPayment closes an invoice the gateway expired, denied or cancelled.
+func (s *paymentService) processFailedPayment(ctx context.Context, n *model.PaymentNotification) error {
+	if strings.HasPrefix(n.InvoiceNumber, "TRV") {
+		orgID, err := s.repo.GetSubscriptionOrganization(ctx, n.InvoiceNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get subscription detail: %w", err)
+		}
+		if err := s.repo.ExpireTravegoTransaction(database.WithOrganization(ctx, orgID), n.InvoiceNumber); err != nil {
+			return fmt.Errorf("failed to expire travego transaction: %w", err)
+		}
+		return nil
+	}
+
+	orgID, _, _, _, err := s.repo.GetOrderDetails(ctx, n.InvoiceNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get order details: %w", err)
+	}
+	if err := s.repo.FailGatewayPaymentOrder(database.WithOrganization(ctx, orgID), n.InvoiceNumber); err != nil {
+		return fmt.Errorf("failed to close payment order: %w", err)
+	}
+	return nil
+}
+
+// processRefundNotification confirms the refunds still being processed for
+// the invoice once Midtrans reports it refunded.
+func (s *paymentService) processRefundNotification(ctx context.Context, n *model.PaymentNotification) error {
+	refunds, err := s.repo.ListMidtransRefunds(ctx, model.MidtransRefundRequested, n.InvoiceNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get midtrans refunds: %w", err)
+	}
+	for i := range refunds {
+		if err := s.completeMidtransRefund(ctx, &refunds[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeMidtransRefund marks the refund refunded, for the organization it
+// was issued for.
+func (s *paymentService) completeMidtransRefund(ctx context.Context, refund *model.MidtransRefund) error {
+	ctx = database.WithOrganization(ctx, refund.OrganizationID)
+	refund.Status = model.MidtransRefundRefunded
+	if err := s.repo.SaveMidtransRefund(ctx, refund); err != nil {
+		return fmt.Errorf("failed to update midtrans refund: %w", err)
+	}
+	if err := s.repo.SyncRefundMidtransStatus(ctx, refund.RefundID); err != nil {
+		return fmt.Errorf("failed to update refund status: %w", err)
+	}
+	return nil
+}
+
+// webhookFromStatus turns a status API response into the notification
+// Midtrans would have sent for it.
+func webhookFromStatus(st *midtransapi.TransactionStatus) *model.MidtransWebhookRequest {
+	req := &model.MidtransWebhookRequest{
+		TransactionTime:   st.TransactionTime,
+		TransactionStatus: st.TransactionStatus,
+		TransactionID:     st.TransactionID,
+		StatusMessage:     st.StatusMessage,
+		StatusCode:        st.StatusCode,
+		SettlementTime:    st.SettlementTime,
+		PaymentType:       st.PaymentType,
+		OrderID:           st.OrderID,
+		MerchantID:        st.MerchantID,
+		GrossAmount:       st.GrossAmount,
+		FraudStatus:       st.FraudStatus,
+		Currency:          st.Currency,
+	}
+	for _, va := range st.VANumbers {
+		req.VaNumbers = append(req.VaNumbers, model.VaNumber{VaNumber: va.VANumber, Bank: va.Bank})
+	}
+	return req
+}
+
+// ReconcileMidtransPayments polls Midtrans for invoices still pending on our
+// side and applies their status as if the webhook had arrived. A status the
+// webhook already delivered is not applied twice.
+func (s *paymentService) ReconcileMidtransPayments() error {
+	api, err := s.midtransAPI()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	pending, err := s.repo.ListPendingMidtransPayments(ctx, now.Add(-reconcileMinAge))
+	if err != nil {
+		return fmt.Errorf("failed to get pending payments: %w", err)
+	}
+
+	for _, p := range pending {
+		st, err := api.Status(p.InvoiceNumber)
+		if errors.Is(err, midtransapi.ErrTransactionNotFound) {
+			if now.Sub(p.CreatedAt) < snapLinkLifetime {
+				continue
+			}
+			st = &midtransapi.TransactionStatus{OrderID: p.InvoiceNumber, TransactionStatus: midtransapi.StatusExpire}
+		} else if err != nil {
+			slog.ErrorContext(ctx, "payment reconcile: status", "invoice_number", p.InvoiceNumber, "error", err)
+			continue
+		}
+
+		if classifyMidtransStatus(st.TransactionStatus, st.FraudStatus) == model.PaymentNotificationPending {
+			continue
+		}
+
+		req := webhookFromStatus(st)
+		payload, _ := json.Marshal(req)
+		rec := midtransNotificationRecord(model.NotificationSourceReconcile, payload, req)
+		if err := s.receiveNotification(ctx, rec, func() error {
+			return s.applyPaymentNotification(ctx, midtransNotification(req))
+		}); err != nil {
+			slog.ErrorContext(ctx, "payment reconcile: apply", "invoice_number", p.InvoiceNumber, "transaction_status", st.TransactionStatus, "error", err)
+		}
+	}
+	return nil
+}
+
+// IssueMidtransRefunds pays recorded order refunds back through Midtrans. The
+// refund amount is taken from the order's Midtrans payments, the newest first;
+// what the order paid outside Midtrans is left to be refunded manually. Refund
+// keys are derived from the refund ID, so a retried run never refunds twice.
+func (s *paymentService) IssueMidtransRefunds() error {
+	api, err := s.midtransAPI()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	refunds, err := s.repo.ListPendingMidtransRefunds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pending refunds: %w", err)
+	}
+	for _, rf := range refunds {
+		if err := s.issueMidtransRefund(database.WithOrganization(ctx, rf.OrganizationID), api, rf); err != nil {
+			slog.ErrorContext(ctx, "payment reconcile: refund", "refund_id", rf.RefundID, "error", err)
+		}
+	}
+
+	requested, err := s.repo.ListMidtransRefunds(ctx, model.MidtransRefundRequested, "")
+	if err != nil {
+		return fmt.Errorf("failed to get requested refunds: %w", err)
+	}
+	for i := range requested {
+		refund := &requested[i]
+		st, err := api.Status(refund.InvoiceNumber)
+		if err != nil {
+			slog.ErrorContext(ctx, "payment reconcile: refund status", "invoice_number", refund.InvoiceNumber, "error", err)
+			continue
+		}
+		for _, done := range st.Refunds {
+			if done.RefundKey == refund.RefundKey {
+				if err := s.completeMidtransRefund(ctx, refund); err != nil {
+					slog.ErrorContext(ctx, "payment reconcile: refund", "refund_key", refund.RefundKey, "error", err)
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *paymentService) issueMidtransRefund(ctx context.Context, api *midtransapi.Client, rf model.PendingMidtransRefund) error {
+	payments, err := s.repo.ListMidtransSettledPayments(ctx, rf.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get midtrans payments: %w", err)
+	}
+
+	remaining := math.Round(rf.Amount - rf.IssuedAmount)
+	issued := rf.IssuedCount
+	for _, p := range payments {
+		if remaining <= 0 {
+			break
+		}
+		available := math.Round(p.PaymentAmount - p.RefundedAmount)
+		if available <= 0 {
+			continue
+		}
+		amount := math.Min(remaining, available)
+
+		issued++
+		refund := &model.MidtransRefund{
+			RefundKey:      fmt.Sprintf("%s-%d", rf.RefundID, issued),
+			RefundID:       rf.RefundID,
+			OrganizationID: rf.OrganizationID,
+			OrderID:        rf.OrderID,
+			InvoiceNumber:  p.InvoiceNumber,
+			Amount:         amount,
+		}
+		res, err := api.Refund(p.InvoiceNumber, midtransapi.RefundRequest{
+			RefundKey: refund.RefundKey,
+			Amount:    int64(amount),
+			Reason:    rf.Description,
+		})
+		if err != nil {
+			// Left pending: the next run retries with the same refund key.
+			return err
+		}
+
+		refund.StatusMessage = res.StatusMessage
+		switch res.StatusCode {
+		case "200":
+			refund.Status = model.MidtransRefundRefunded
+			remaining -= amount
+		case "201":
+			refund.Status = model.MidtransRefundRequested
+			remaining -= amount
+		default:
+			refund.Status = model.MidtransRefundFailed
+			refund.StatusMessage = res.StatusCode + " " + res.StatusMessage
+		}
+		if err := s.repo.SaveMidtransRefund(ctx, refund); err != nil {
+			return fmt.Errorf("failed to save midtrans refund: %w", err)
+		}
+	}
+
+	if issued == 0 {
+		if err := s.repo.UpdateRefundMidtransStatus(ctx, rf.RefundID, model.MidtransRefundManual, 0, ""); err != nil {
+			return fmt.Errorf("failed to update refund status: %w", err)
+		}
+		return nil
+	}
+	if err := s.repo.SyncRefundMidtransStatus(ctx, rf.RefundID); err != nil {
+		return fmt.Errorf("failed to update refund status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-travego/config"
+	"service-travego/database"
+	"service-travego/internal/midtransapi"
+	"service-travego/internal/midtransapi/midtranstest"
+	"service-travego/model"
+	"service-travego/repository"
+)
+
+// stubPaymentRepo implements the PaymentRepository methods the
+// reconciliation job uses; any other call panics. Like the repository, the
+// methods for one organization refuse a context without one.
+type stubPaymentRepo struct {
+	repository.PaymentRepository
+
+	pendingPayments []model.PendingMidtransPayment
+	orders          map[string]string
+	expired         []string
+	failed          []string
+
+	pendingRefunds []model.PendingMidtransRefund
+	settled        map[string][]model.MidtransSettledPayment
+	refunds        map[string]*model.MidtransRefund
+	refundStatus   map[string]string
+
+	notifications []model.PaymentNotificationRecord
+}
+
+func requireOrganization(ctx context.Context) error {
+	if _, ok := database.OrganizationFromContext(ctx); !ok {
+		return database.ErrNoOrganization
+	}
+	return nil
+}
+
+func (r *stubPaymentRepo) ListPendingMidtransPayments(context.Context, time.Time) ([]model.PendingMidtransPayment, error) {
+	return r.pendingPayments, nil
+}
+
+func (r *stubPaymentRepo) GetOrderDetails(_ context.Context, invoice string) (string, int64, int64, string, error) {
+	return "org-1", 0, 1, r.orders[invoice], nil
+}
+
+func (r *stubPaymentRepo) GetSubscriptionOrganization(context.Context, string) (string, error) {
+	return "org-1", nil
+}
+
+func (r *stubPaymentRepo) ExpireTravegoTransaction(ctx context.Context, invoice string) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	r.expired = append(r.expired, invoice)
+	return nil
+}
+
+func (r *stubPaymentRepo) FailGatewayPaymentOrder(ctx context.Context, invoice string) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	r.failed = append(r.failed, invoice)
+	return nil
+}
+
+func (r *stubPaymentRepo) ListPendingMidtransRefunds(context.Context) ([]model.PendingMidtransRefund, error) {
+	return r.pendingRefunds, nil
+}
+
+func (r *stubPaymentRepo) ListMidtransSettledPayments(ctx context.Context, orderID string) ([]model.MidtransSettledPayment, error) {
+	if err := requireOrganization(ctx); err != nil {
+		return nil, err
+	}
+	return r.settled[orderID], nil
+}
+
+func (r *stubPaymentRepo) SaveMidtransRefund(ctx context.Context, refund *model.MidtransRefund) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	saved := *refund
+	r.refunds[refund.RefundKey] = &saved
+	return nil
+}
+
+func (r *stubPaymentRepo) ListMidtransRefunds(_ context.Context, status, invoice string) ([]model.MidtransRefund, error) {
+	var out []model.MidtransRefund
+	for _, rf := range r.refunds {
+		if rf.Status == status && (invoice == "" || rf.InvoiceNumber == invoice) {
+			out = append(out, *rf)
+		}
+	}
+	return out, nil
+}
+
+func (r *stubPaymentRepo) UpdateRefundMidtransStatus(ctx context.Context, refundID, status string, amount float64, message string) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	r.refundStatus[refundID] = status
+	return nil
+}
+
+func (r *stubPaymentRepo) SyncRefundMidtransStatus(ctx context.Context, refundID string) error {
+	if err := requireOrganization(ctx); err != nil {
+		return err
+	}
+	status := model.MidtransRefundRefunded
+	for _, rf := range r.refunds {
+		if rf.RefundID == refundID && rf.Status == model.MidtransRefundFailed {
+			status = model.MidtransRefundFailed
+		}
+	}
+	r.refundStatus[refundID] = status
+	return nil
+}
+
+func (r *stubPaymentRepo) InsertPaymentNotification(_ context.Context, rec *model.PaymentNotificationRecord) (bool, error) {
+	for _, n := range r.notifications {
+		if rec.EventKey != "" && n.EventKey == rec.EventKey {
+			return false, nil
+		}
+	}
+	r.notifications = append(r.notifications, *rec)
+	return true, nil
+}
+
+func (r *stubPaymentRepo) UpdatePaymentNotificationOutcome(_ context.Context, id, outcome, message string, releaseEvent bool) error {
+	for i := range r.notifications {
+		if r.notifications[i].NotificationID == id {
+			r.notifications[i].Outcome = outcome
+			r.notifications[i].Message = message
+			if releaseEvent {
+				r.notifications[i].EventKey = ""
+			}
+		}
+	}
+	return nil
+}
+
+func (r *stubPaymentRepo) GetPaymentNotification(_ context.Context, id string) (*model.PaymentNotificationRecord, error) {
+	for i := range r.notifications {
+		if r.notifications[i].NotificationID == id {
+			rec := r.notifications[i]
+			return &rec, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubPaymentRepo) ClaimPaymentNotificationEvent(_ context.Context, id, eventKey string) (bool, error) {
+	for _, n := range r.notifications {
+		if n.EventKey == eventKey {
+			return false, nil
+		}
+	}
+	for i := range r.notifications {
+		if r.notifications[i].NotificationID == id && r.notifications[i].EventKey == "" {
+			r.notifications[i].EventKey = eventKey
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newReconcileService(t *testing.T, repo *stubPaymentRepo) (*midtranstest.Fake, *paymentService) {
+	t.Helper()
+	fake, srv := midtranstest.NewServer("server-key")
+	t.Cleanup(srv.Close)
+	if repo.refunds == nil {
+		repo.refunds = make(map[string]*model.MidtransRefund)
+	}
+	if repo.refundStatus == nil {
+		repo.refundStatus = make(map[string]string)
+	}
+	return fake, &paymentService{
+		repo:           repo,
+		midtransConfig: &config.MidtransConfig{API: midtransapi.NewClient(srv.URL, "server-key")},
+	}
+}
+
+func TestClassifyMidtransStatus(t *testing.T) {
+	cases := []struct {
+		status, fraud string
+		want          string
+	}{
+		{"settlement", "", model.PaymentNotificationPaid},
+		{"capture", "accept", model.PaymentNotificationPaid},
+		{"capture", "challenge", model.PaymentNotificationPending},
+		{"pending", "", model.PaymentNotificationPending},
+		{"expire", "", model.PaymentNotificationFailed},
+		{"deny", "", model.PaymentNotificationFailed},
+		{"cancel", "", model.PaymentNotificationFailed},
+		{"refund", "", model.PaymentNotificationRefunded},
+		{"partial_refund", "", model.PaymentNotificationRefunded},
+	}
+	for _, c := range cases {
+		if got := classifyMidtransStatus(c.status, c.fraud); got != c.want {
+			t.Errorf("classifyMidtransStatus(%q, %q) = %s, want %s", c.status, c.fraud, got, c.want)
+		}
+	}
+}
+
+func TestClassifyXenditStatus(t *testing.T) {
+	cases := map[string]string{
+		"PAID":    model.PaymentNotificationPaid,
+		"SETTLED": model.PaymentNotificationPaid,
+		"EXPIRED": model.PaymentNotificationFailed,
+		"PENDING": model.PaymentNotificationPending,
+	}
+	for status, want := range cases {
+		if got := classifyXenditStatus(status); got != want {
+			t.Errorf("classifyXenditStatus(%q) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestReconcileMidtransPaymentsClosesExpiredInvoices(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	repo := &stubPaymentRepo{
+		pendingPayments: []model.PendingMidtransPayment{
+			{InvoiceNumber: "INV-EXPIRED", OrderID: "ORD-1", OrderType: 1, CreatedAt: time.Now().Add(-time.Hour)},
+			{InvoiceNumber: "INV-UNUSED", OrderID: "ORD-2", OrderType: 1, CreatedAt: old},
+			{InvoiceNumber: "INV-NEW", OrderID: "ORD-3", OrderType: 1, CreatedAt: time.Now().Add(-time.Hour)},
+			{InvoiceNumber: "TRV-1", CreatedAt: time.Now().Add(-time.Hour)},
+			{InvoiceNumber: "INV-PENDING", OrderID: "ORD-4", OrderType: 1, CreatedAt: time.Now().Add(-time.Hour)},
+		},
+	}
+	fake, svc := newReconcileService(t, repo)
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "INV-EXPIRED", TransactionStatus: midtransapi.StatusExpire, GrossAmount: "100000.00"})
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "TRV-1", TransactionStatus: midtransapi.StatusDeny, GrossAmount: "100000.00"})
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "INV-PENDING", TransactionStatus: midtransapi.StatusPending, GrossAmount: "100000.00"})
+
+	if err := svc.ReconcileMidtransPayments(); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	if len(repo.failed) != 2 || repo.failed[0] != "INV-EXPIRED" || repo.failed[1] != "INV-UNUSED" {
+		t.Fatalf("expected INV-EXPIRED and INV-UNUSED to be closed, got %v", repo.failed)
+	}
+	if len(repo.expired) != 1 || repo.expired[0] != "TRV-1" {
+		t.Fatalf("expected TRV-1 to be expired, got %v", repo.expired)
+	}
+}
+
+func TestIssueMidtransRefundsSplitsAcrossPayments(t *testing.T) {
+	repo := &stubPaymentRepo{
+		pendingRefunds: []model.PendingMidtransRefund{
+			{RefundID: "rf-1", OrganizationID: "org-1", OrderID: "ORD-1", Amount: 130000, Description: "cancelled"},
+			{RefundID: "rf-2", OrganizationID: "org-1", OrderID: "ORD-2", Amount: 50000},
+		},
+		settled: map[string][]model.MidtransSettledPayment{
+			"ORD-1": {
+				{InvoiceNumber: "INV-2", PaymentAmount: 100000},
+				{InvoiceNumber: "INV-1", PaymentAmount: 50000},
+			},
+		},
+	}
+	fake, svc := newReconcileService(t, repo)
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "INV-1", TransactionStatus: midtransapi.StatusSettlement, GrossAmount: "50000.00"})
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "INV-2", TransactionStatus: midtransapi.StatusSettlement, GrossAmount: "100000.00"})
+
+	if err := svc.IssueMidtransRefunds(); err != nil {
+		t.Fatalf("issue refunds: %v", err)
+	}
+
+	inv2, _ := fake.Transaction("INV-2")
+	inv1, _ := fake.Transaction("INV-1")
+	if inv2.TransactionStatus != midtransapi.StatusRefund || inv1.RefundAmount != "30000.00" {
+		t.Fatalf("expected 100000 refunded on INV-2 and 30000 on INV-1, got %s / %s", inv2.RefundAmount, inv1.RefundAmount)
+	}
+	if repo.refundStatus["rf-1"] != model.MidtransRefundRefunded {
+		t.Fatalf("expected rf-1 refunded, got %q", repo.refundStatus["rf-1"])
+	}
+	if repo.refundStatus["rf-2"] != model.MidtransRefundManual {
+		t.Fatalf("expected rf-2 without Midtrans payments to be manual, got %q", repo.refundStatus["rf-2"])
+	}
+}
+
+func TestIssueMidtransRefundsRecordsRejectedRefund(t *testing.T) {
+	repo := &stubPaymentRepo{
+		pendingRefunds: []model.PendingMidtransRefund{
+			{RefundID: "rf-1", OrganizationID: "org-1", OrderID: "ORD-1", Amount: 50000},
+		},
+		settled: map[string][]model.MidtransSettledPayment{
+			"ORD-1": {{InvoiceNumber: "INV-1", PaymentAmount: 50000}},
+		},
+	}
+	fake, svc := newReconcileService(t, repo)
+	fake.SetTransaction(midtransapi.TransactionStatus{OrderID: "INV-1", TransactionStatus: midtransapi.StatusSettlement, GrossAmount: "50000.00"})
+	fake.RejectRefunds("INV-1", "Payment type does not support refund")
+
+	if err := svc.IssueMidtransRefunds(); err != nil {
+		t.Fatalf("issue refunds: %v", err)
+	}
+
+	if repo.refundStatus["rf-1"] != model.MidtransRefundFailed {
+		t.Fatalf("expected rf-1 failed, got %q", repo.refundStatus["rf-1"])
+	}
+	if rf := repo.refunds["rf-1-1"]; rf == nil || rf.Status != model.MidtransRefundFailed {
+		t.Fatalf("expected refund rf-1-1 to be recorded as failed, got %+v", rf)
+	}
+}
@@ -1,15 +1,17 @@
 package service
 
 import (
-	"crypto/sha512"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"service-travego/config"
 	"service-travego/configs"
+	"service-travego/database"
 	"service-travego/helper"
-	"service-travego/internal/wagy"
+	"service-travego/internal/paymentgateway"
 	"service-travego/model"
 	"service-travego/repository"
 	"strconv"
@@ -17,50 +19,74 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/veritrans/go-midtrans"
 )
 
 // PaymentService adalah interface untuk logika bisnis payment
 type PaymentService interface {
-	CreatePayment(req *model.PaymentRequest) (*model.PaymentResponse, error)
-	PaymentNotifications(req *model.MidtransWebhookRequest) error
-	UpdatePaymentStatus(orderID string, orderType int64, status int, paymentStatus int) error
-	ProcessPaymentNotification(req *model.MidtransWebhookRequest) error
+	CreatePayment(ctx context.Context, req *model.PaymentRequest) (*model.PaymentResponse, error)
+	PaymentNotifications(ctx context.Context, payload []byte, req *model.MidtransWebhookRequest) error
+	UpdatePaymentStatus(ctx context.Context, orderID string, orderType int64, status int, paymentStatus int) error
+	HandleMidtransNotification(ctx context.Context, payload []byte, req *model.MidtransWebhookRequest) error
+	ProcessXenditNotification(ctx context.Context, callbackToken string, payload []byte, req *model.XenditInvoiceCallback) error
+	ListPaymentNotifications(ctx context.Context, filter model.PaymentNotificationFilter) ([]model.PaymentNotificationRecord, error)
+	ReplayPaymentNotification(ctx context.Context, notificationID string) (*model.PaymentNotificationRecord, error)
+	ReconcileMidtransPayments() error
+	IssueMidtransRefunds() error
 }
 
 type paymentService struct {
 	repo           repository.PaymentRepository
 	orgRepo        *repository.OrganizationRepository
 	midtransConfig *config.MidtransConfig
+	gateways       *paymentgateway.Registry
+	outbox         *OutboxService
 }
 
 // NewPaymentService membuat instance baru dari PaymentService
-func NewPaymentService(repo repository.PaymentRepository, orgRepo *repository.OrganizationRepository, midtransConfig *config.MidtransConfig) PaymentService {
+func NewPaymentService(repo repository.PaymentRepository, orgRepo *repository.OrganizationRepository, midtransConfig *config.MidtransConfig, gateways *paymentgateway.Registry, outbox *OutboxService) PaymentService {
 	return &paymentService{
 		repo:           repo,
 		orgRepo:        orgRepo,
 		midtransConfig: midtransConfig,
+		gateways:       gateways,
+		outbox:         outbox,
 	}
 }
 
-func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRequest) error {
-	if req.StatusCode != "200" {
+// applyPaymentNotification updates the invoice from a payment gateway
+// notification, whichever gateway it came from. The notification is applied
+// for the organization the invoice was issued to.
+func (s *paymentService) applyPaymentNotification(ctx context.Context, n *model.PaymentNotification) error {
+	switch n.Status {
+	case model.PaymentNotificationPaid:
+	case model.PaymentNotificationFailed:
+		return s.processFailedPayment(ctx, n)
+	case model.PaymentNotificationRefunded:
+		return s.processRefundNotification(ctx, n)
+	default:
 		return nil
 	}
 
-	paymentMethod := determinePaymentMethod(req)
+	paymentMethod := n.PaymentMethod
+	gatewayLabel := paymentgateway.Label(n.Provider)
 
 	// Check if it's a subscription order (starts with TRV)
-	if strings.HasPrefix(req.OrderID, "TRV") {
+	if strings.HasPrefix(n.InvoiceNumber, "TRV") {
+		organizationID, err := s.repo.GetSubscriptionOrganization(ctx, n.InvoiceNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get subscription detail: %w", err)
+		}
+		ctx = database.WithOrganization(ctx, organizationID)
+
 		// Get subscription detail
-		_, packageID, _, expiryDate, _, organizationID, _, _, _, err := s.repo.GetSubscriptionDetail(req.OrderID)
+		_, packageID, startDate, expiryDate, _, _, _, _, _, err := s.repo.GetSubscriptionDetail(ctx, n.InvoiceNumber)
 		if err != nil {
 			return fmt.Errorf("failed to get subscription detail: %w", err)
 		}
 
-		grossAmount, err := strconv.ParseFloat(req.GrossAmount, 64)
+		grossAmount, err := strconv.ParseFloat(n.GrossAmount, 64)
 		// Update travego_transactions
-		if err := s.repo.UpdateTravegoTransactionStatus(req.OrderID, paymentMethod, grossAmount); err != nil {
+		if err := s.repo.UpdateTravegoTransactionStatus(ctx, n.InvoiceNumber, paymentMethod, grossAmount); err != nil {
 			return fmt.Errorf("failed to update travego transaction: %w", err)
 		}
 
@@ -69,43 +95,61 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 			return fmt.Errorf("invalid gross amount: %w", err)
 		}
 
-		// Check if subscription exists
-		subscriptionExists, err := s.repo.GetSubscriptionByOrganization(organizationID)
+		current, err := s.repo.GetSubscriptionLifecycle(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to check subscription existence: %w", err)
+			return fmt.Errorf("failed to get subscription: %w", err)
 		}
 
 		activateDate := time.Now()
-		if subscriptionExists {
-			// Update existing subscription
-			if err := s.repo.UpdateSubscription(organizationID, packageID, activateDate, expiryDate, grossAmount); err != nil {
+		event := &model.SubscriptionEvent{
+			OrganizationID: organizationID,
+			Event:          model.SubscriptionEventActivated,
+			ToStatus:       model.SubscriptionStatusActive,
+			PackageID:      packageID,
+			Note:           n.InvoiceNumber,
+		}
+		switch {
+		case current != nil && daysUntil(activateDate, startDate) > 0:
+			// A downgrade starts when the current period ends
+			if err := s.repo.ScheduleSubscription(ctx, packageID, expiryDate, grossAmount); err != nil {
+				return fmt.Errorf("failed to schedule subscription: %w", err)
+			}
+			event.Event = model.SubscriptionEventDowngradeScheduled
+			event.FromStatus, event.ToStatus = current.Status, current.Status
+		case current != nil:
+			if err := s.repo.UpdateSubscription(ctx, packageID, activateDate, expiryDate, grossAmount); err != nil {
 				return fmt.Errorf("failed to update subscription: %w", err)
 			}
-		} else {
-			// Insert new subscription
+			event.FromStatus = current.Status
+			if current.PackageID == packageID {
+				event.Event = model.SubscriptionEventRenewed
+			} else if daysUntil(activateDate, current.ExpiryDate) >= 0 {
+				event.Event = model.SubscriptionEventUpgraded
+			}
+		default:
 			subscriptionID, err := uuid.NewV7()
 			if err != nil {
 				return fmt.Errorf("failed to generate subscription ID: %w", err)
 			}
-			if err := s.repo.InsertSubscription(subscriptionID.String(), organizationID, packageID, activateDate, expiryDate, grossAmount, activateDate); err != nil {
+			if err := s.repo.InsertSubscription(ctx, subscriptionID.String(), packageID, activateDate, expiryDate, grossAmount, activateDate); err != nil {
 				return fmt.Errorf("failed to insert subscription: %w", err)
 			}
 		}
+		if err := s.repo.InsertSubscriptionEvent(ctx, event); err != nil {
+			slog.WarnContext(ctx, "failed to record subscription event", "error", err)
+		}
+		helper.ForgetSubscriptionState(organizationID)
 
 		// Get organization name
-		_, orgName, _, err := s.orgRepo.GetOrganizationEmailAndName(organizationID)
+		_, orgName, _, err := s.orgRepo.GetOrganizationEmailAndName(ctx)
 		if err != nil {
-			fmt.Printf("warning: failed to get organization name: %v\n", err)
+			slog.WarnContext(ctx, "failed to get organization name", "error", err)
 		}
 
-		// Send WhatsApp notification
-		go func() {
-			waClient := wagy.NewWagyClient(os.Getenv("WAGY_DEVICE_ID"), os.Getenv("WAGY_TOKEN"))
-			phone := os.Getenv("ADMINISTRATOR_PHONE")
-			if phone == "" {
-				fmt.Printf("warning: ADMINISTRATOR_PHONE environment variable not set\n")
-				return
-			}
+		// Queue WhatsApp notification
+		if phone := os.Getenv("ADMINISTRATOR_PHONE"); phone == "" {
+			slog.WarnContext(ctx, "ADMINISTRATOR_PHONE environment variable not set")
+		} else {
 			message := fmt.Sprintf(
 				"[PAYMENT SUCCESS]\n"+
 					"Organization: %s\n"+
@@ -113,37 +157,42 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 					"Amount: Rp %s\n"+
 					"Thank you!",
 				orgName,
-				req.OrderID,
+				n.InvoiceNumber,
 				helper.FormatRupiah(grossAmount),
 			)
-			if _, err := waClient.SendMessage(phone, message); err != nil {
-				fmt.Printf("warning: failed to send WhatsApp notification: %v\n", err)
+			if err := s.outbox.EnqueueWhatsApp(ctx, "", OutboxKindPaymentAdminNotice, phone, message); err != nil {
+				slog.WarnContext(ctx, "failed to queue WhatsApp notification", "error", err)
 			}
-		}()
+		}
 
 		return nil
 	}
 
-	orgID, _, orderTypeFromOrder, orderID, err := s.repo.GetOrderDetails(req.OrderID)
+	orgID, _, orderTypeFromOrder, orderID, err := s.repo.GetOrderDetails(ctx, n.InvoiceNumber)
 	if err != nil {
 		return fmt.Errorf("failed to get order details: %w", err)
 	}
+	ctx = database.WithOrganization(ctx, orgID)
 
-	totalAmount, err := s.repo.GetOrderTotalAmount(orderID, orderTypeFromOrder)
+	totalAmount, err := s.repo.GetOrderTotalAmount(ctx, orderID, orderTypeFromOrder)
 	if err != nil {
 		return fmt.Errorf("failed to get order total amount: %w", err)
 	}
 
-	grossAmount, err := strconv.ParseFloat(req.GrossAmount, 64)
+	grossAmount, err := strconv.ParseFloat(n.GrossAmount, 64)
 	if err != nil {
 		return fmt.Errorf("invalid gross amount: %w", err)
 	}
 
-	if err := s.repo.UpdatePaymentOrderNotification(req.OrderID, orgID, totalAmount, grossAmount, req.TransactionID, req.PaymentType); err != nil {
+	if err := s.repo.UpdatePaymentOrderNotification(ctx, n.InvoiceNumber, totalAmount, grossAmount, n.TransactionID, gatewayLabel+" - "+n.PaymentType); err != nil {
 		return fmt.Errorf("failed to update payment order: %w", err)
 	}
 
-	totalPaid, err := s.repo.GetOrderTotalPaidAmount(orderID, orderTypeFromOrder, orgID)
+	if err := s.repo.MarkOrderInstallmentPaid(ctx, n.InvoiceNumber, parseMidtransTransactionTime(n.SettlementTime)); err != nil {
+		return fmt.Errorf("failed to update order installment: %w", err)
+	}
+
+	totalPaid, err := s.repo.GetOrderTotalPaidAmount(ctx, orderID, orderTypeFromOrder)
 	if err != nil {
 		return fmt.Errorf("failed to get total paid amount: %w", err)
 	}
@@ -154,16 +203,16 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 		paymentStatus = 1
 	}
 
-	if err := s.repo.UpdateOrderPaymentStatus(orderID, orderTypeFromOrder, paymentStatus); err != nil {
+	if err := s.repo.UpdateOrderPaymentStatus(ctx, orderID, orderTypeFromOrder, paymentStatus); err != nil {
 		return fmt.Errorf("failed to update order payment status: %w", err)
 	}
 
 	createdAt := time.Now().Format("2006-01-02 15:04:05")
-	if err := s.repo.InsertPaymentMidtrans(req, createdAt); err != nil {
-		return fmt.Errorf("failed to insert payment midtrans: %w", err)
+	if err := s.logPaymentNotification(ctx, n, createdAt); err != nil {
+		return fmt.Errorf("failed to insert payment notification: %w", err)
 	}
 
-	invoiceNumber, orderTypeFromPaymentOrder, paymentTypeFromPaymentOrder, paymentMethodFromPaymentOrder, createdBy, err := s.repo.GetPaymentOrderMeta(orderID, orgID)
+	invoiceNumber, orderTypeFromPaymentOrder, paymentTypeFromPaymentOrder, paymentMethodFromPaymentOrder, createdBy, err := s.repo.GetPaymentOrderMeta(ctx, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to get payment order meta: %w", err)
 	}
@@ -171,7 +220,7 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 	orderType := orderTypeFromPaymentOrder
 
 	if invoiceNumber != "" {
-		exists, err := s.repo.TransactionExistsByInvoice(orgID, invoiceNumber)
+		exists, err := s.repo.TransactionExistsByInvoice(ctx, invoiceNumber)
 		if err != nil {
 			return fmt.Errorf("failed to check existing transaction: %w", err)
 		}
@@ -180,7 +229,7 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 		}
 	}
 
-	transactionDate := parseMidtransTransactionTime(req.TransactionTime)
+	transactionDate := parseMidtransTransactionTime(n.TransactionTime)
 	formattedPaymentDate := formatPaymentDate(transactionDate)
 
 	transactionCategory := ""
@@ -197,15 +246,15 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 	}
 
 	if err := s.repo.InsertTransactionMidtrans(
+		ctx,
 		transactionID.String(),
 		orderType,
 		invoiceNumber,
-		"Midtrans - Order ID "+req.OrderID,
+		gatewayLabel+" - Order ID "+n.InvoiceNumber,
 		transactionDate,
 		paymentTypeFromPaymentOrder,
 		paymentMethodFromPaymentOrder,
 		grossAmount,
-		orgID,
 		transactionCategory,
 		time.Now(),
 		createdBy,
@@ -225,7 +274,7 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 		baseURL = strings.TrimSuffix(baseURL, "/")
 
 		tokenPayload := model.OrderTokenPayload{
-			OrderID: req.OrderID,
+			OrderID: n.InvoiceNumber,
 			PriceID: "",
 		}
 		tokenBytes, _ := json.Marshal(tokenPayload)
@@ -233,30 +282,32 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 		orderDetailUrl := ""
 		dashboardOrderDetailUrl := ""
 
-		orgEmail, orgName, domainURL, oerr := s.orgRepo.GetOrganizationEmailAndName(orgID)
-		dashboardOrderDetailUrl = fmt.Sprintf("%s/dashboard/orders/fleet/detail/%s", baseURL, req.OrderID)
+		orgEmail, orgName, domainURL, oerr := s.orgRepo.GetOrganizationEmailAndName(ctx)
+		dashboardOrderDetailUrl = fmt.Sprintf("%s/dashboard/orders/fleet/detail/%s", baseURL, n.InvoiceNumber)
 		if terr == nil && strings.TrimSpace(token) != "" && strings.TrimSpace(domainURL) != "" {
 			orderDetailUrl = fmt.Sprintf("%s/order/detail/armada/%s", domainURL, token)
 		}
 		if oerr == nil && strings.TrimSpace(orgEmail) != "" {
 			orgEmailData := helper.PaymentSuccessEmailData{
 				OrganizationName:        orgName,
-				TransactionID:           req.TransactionID,
+				TransactionID:           n.TransactionID,
 				OrderID:                 orderID,
-				PaymentMethod:           req.PaymentType,
+				PaymentMethod:           n.PaymentType,
 				PaymentDate:             formattedPaymentDate,
 				TotalPrice:              helper.FormatRupiah(grossAmount),
 				DashboardOrderDetailUrl: dashboardOrderDetailUrl,
 			}
 
-			go func() {
-				if err := helper.SendPaymentReceivedEmail(emailCfg, orgEmail, orgEmailData); err != nil {
-					fmt.Println("failed to send payment received email to organization:", err)
-				}
-			}()
+			receivedEmail, err := helper.NewPaymentReceivedEmail(orgEmail, orgEmailData)
+			if err == nil {
+				err = s.outbox.EnqueueEmail(ctx, orgID, OutboxKindPaymentReceived, receivedEmail)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to queue payment received email to organization", "error", err)
+			}
 		}
 
-		customerName, customerEmail, fleetName, pickupLocation, startDate, endDate, destination, ferr := s.repo.GetFleetOrderEmailData(req.OrderID, orgID)
+		customerName, customerEmail, fleetName, pickupLocation, startDate, endDate, destination, ferr := s.repo.GetFleetOrderEmailData(ctx, n.InvoiceNumber)
 		if ferr == nil && strings.TrimSpace(customerEmail) != "" {
 			duration := ""
 			if !startDate.IsZero() && !endDate.IsZero() {
@@ -269,9 +320,9 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 
 			customerEmailData := helper.PaymentSuccessEmailData{
 				CustomerName:   customerName,
-				TransactionID:  req.TransactionID,
-				OrderID:        req.OrderID,
-				PaymentMethod:  req.PaymentType,
+				TransactionID:  n.TransactionID,
+				OrderID:        n.InvoiceNumber,
+				PaymentMethod:  n.PaymentType,
 				PaymentDate:    formattedPaymentDate,
 				TotalPrice:     helper.FormatRupiah(grossAmount),
 				FleetName:      fleetName,
@@ -282,17 +333,31 @@ func (s *paymentService) ProcessPaymentNotification(req *model.MidtransWebhookRe
 				ReviewUrl:      fmt.Sprintf("%s/order/review", domainURL),
 			}
 
-			go func() {
-				if err := helper.SendPaymentSuccessEmail(emailCfg, customerEmail, customerEmailData); err != nil {
-					fmt.Println("failed to send payment success email:", err)
-				}
-			}()
+			successEmail, err := helper.NewPaymentSuccessEmail(customerEmail, customerEmailData)
+			if err == nil {
+				err = s.outbox.EnqueueEmail(ctx, orgID, OutboxKindPaymentSuccess, successEmail)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to queue payment success email", "error", err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// logPaymentNotification keeps the raw notification in the log table of its
+// gateway.
+func (s *paymentService) logPaymentNotification(ctx context.Context, n *model.PaymentNotification, createdAt string) error {
+	switch {
+	case n.Midtrans != nil:
+		return s.repo.InsertPaymentMidtrans(ctx, n.Midtrans, createdAt)
+	case n.Xendit != nil:
+		return s.repo.InsertPaymentXendit(ctx, n.Xendit, createdAt)
+	}
+	return nil
+}
+
 func determinePaymentMethod(req *model.MidtransWebhookRequest) string {
 	if len(req.VaNumbers) > 0 && req.VaNumbers[0].Bank != "" {
 		return fmt.Sprintf("Midtrans - Virtual Account - %s", req.VaNumbers[0].Bank)
@@ -340,16 +405,16 @@ func parseMidtransTransactionTime(s string) time.Time {
 	return time.Now()
 }
 
-func (s *paymentService) UpdatePaymentStatus(orderID string, orderType int64, status int, paymentStatus int) error {
-	return s.repo.UpdateOrderStatus(orderID, orderType, status, paymentStatus)
+func (s *paymentService) UpdatePaymentStatus(ctx context.Context, orderID string, orderType int64, status int, paymentStatus int) error {
+	return s.repo.UpdateOrderStatus(ctx, orderID, orderType, status, paymentStatus)
 }
 
-func (s *paymentService) CreatePayment(req *model.PaymentRequest) (*model.PaymentResponse, error) {
+func (s *paymentService) CreatePayment(ctx context.Context, req *model.PaymentRequest) (*model.PaymentResponse, error) {
 
 	var paymentAmount int64
 
 	if req.PaymentType == 1004 {
-		amount, err := s.repo.GetOrderTotalAmount(req.OrderID, req.OrderType)
+		amount, err := s.repo.GetOrderTotalAmount(ctx, req.OrderID, req.OrderType)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get order amount: %w", err)
 		}
@@ -359,74 +424,87 @@ func (s *paymentService) CreatePayment(req *model.PaymentRequest) (*model.Paymen
 		paymentAmount = req.PaymentAmount
 	}
 
+	gateway, err := gatewayForOrganization(ctx, s.gateways, s.orgRepo)
+	if err != nil {
+		return nil, fmt.Errorf("payment gateway error: %w", err)
+	}
+
 	// 4. Update status_payment menjadi 3 di tabel order yang sesuai
-	err := s.repo.UpdatePaymentStatus(req.OrderID, req.OrderType, 3)
+	err = s.repo.UpdatePaymentStatus(ctx, req.OrderID, req.OrderType, 3)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update order payment status: %w", err)
 	}
 
 	// 5. Generate invoice number
-	invoiceNumber, err := s.repo.GetNextInvoiceNumber(req.OrganizationID, int(req.OrderType))
+	invoiceNumber, err := s.repo.GetNextInvoiceNumber(ctx, int(req.OrderType))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate invoice number: %w", err)
 	}
-	fmt.Println("invoiceNumber:", invoiceNumber)
+	slog.DebugContext(ctx, "invoice number", "invoice_number", invoiceNumber)
 
 	// 6. Insert ke payment_orders
 	paymentID := uuid.New().String()
 	now := time.Now().Format("2006-01-02 15:04:05")
 
-	err = s.repo.InsertPaymentOrder(paymentID, req.OrderType, req.OrderID, req.OrganizationID, req.PaymentType, 1004, invoiceNumber, now, req.UserID)
+	err = s.repo.InsertPaymentOrder(ctx, paymentID, req.OrderType, req.OrderID, req.PaymentType, 1004, invoiceNumber, gateway.Provider(), now, req.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert payment order: %w", err)
 	}
 
-	// 7. Panggil Midtrans Snap API untuk generate snap_token
-	snapReq := &midtrans.SnapReq{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  invoiceNumber,
-			GrossAmt: paymentAmount,
-		},
-	}
-
-	snapResp, err := s.midtransConfig.Snap.GetToken(snapReq)
+	// 7. Buat invoice di payment gateway organisasi (snap token / invoice Xendit)
+	charge, err := gateway.CreateCharge(paymentgateway.ChargeRequest{
+		InvoiceNumber: invoiceNumber,
+		Amount:        paymentAmount,
+	})
 	if err != nil {
-		fmt.Println("Midtrans error:", err)
-		return nil, fmt.Errorf("midtrans error: %w", err)
+		slog.ErrorContext(ctx, "payment gateway error", "error", err)
+		return nil, fmt.Errorf("%s error: %w", gateway.Provider(), err)
 	}
 
 	return &model.PaymentResponse{
-		SnapToken: snapResp.Token,
-		OrderID:   invoiceNumber,
+		SnapToken:      charge.Token,
+		OrderID:        invoiceNumber,
+		PaymentGateway: charge.Provider,
+		RedirectURL:    charge.RedirectURL,
 	}, nil
 }
 
 // PaymentNotifications menangani notifikasi dari Midtrans
-func (s *paymentService) PaymentNotifications(req *model.MidtransWebhookRequest) error {
+func (s *paymentService) PaymentNotifications(ctx context.Context, payload []byte, req *model.MidtransWebhookRequest) error {
 	// 1. Verifikasi signature key (SHA512: order_id + status_code + gross_amount + server_key)
-	serverKey := os.Getenv("MIDTRANS_SERVER_KEY")
-	payload := req.OrderID + req.StatusCode + req.GrossAmount + serverKey
-
-	h := sha512.New()
-	h.Write([]byte(payload))
-	signature := hex.EncodeToString(h.Sum(nil))
-
-	if signature != req.SignatureKey {
-		return fmt.Errorf("invalid signature key")
-	}
+	rec := midtransNotificationRecord(model.NotificationSourceOrderWebhook, payload, req)
+	if !s.verifyMidtransSignature(req) {
+		s.rejectNotification(ctx, rec, "invalid signature key")
+		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "invalid signature key")
+	}
+	return s.receiveNotification(ctx, rec, func() error {
+		return s.markOrderWaitingApproval(ctx, req)
+	})
+}
 
+// markOrderWaitingApproval menandai order menunggu persetujuan pembayaran.
+// A replayed notification is applied for the organization of the order.
+func (s *paymentService) markOrderWaitingApproval(ctx context.Context, req *model.MidtransWebhookRequest) error {
 	// Ambil gross amount dari webhook
 	_, err := strconv.ParseFloat(req.GrossAmount, 64)
 	if err != nil {
 		return fmt.Errorf("invalid gross amount format: %w", err)
 	}
 
+	if _, ok := database.OrganizationFromContext(ctx); !ok {
+		orgID, err := s.repo.GetOrderOrganization(ctx, req.OrderID)
+		if err != nil {
+			return fmt.Errorf("order not found: %w", err)
+		}
+		ctx = database.WithOrganization(ctx, orgID)
+	}
+
 	// Ambil orderType dengan mengecek tabel
 	orderType := int64(1)
-	_, err = s.repo.GetOrderTotalAmount(req.OrderID, 1) // orderType 1 = fleet
+	_, err = s.repo.GetOrderTotalAmount(ctx, req.OrderID, 1) // orderType 1 = fleet
 	if err != nil {
 		// Jika tidak ketemu di fleet_orders, coba di tour_package_orders
-		_, err = s.repo.GetOrderTotalAmount(req.OrderID, 2) // orderType 2 = tour
+		_, err = s.repo.GetOrderTotalAmount(ctx, req.OrderID, 2) // orderType 2 = tour
 		orderType = 2
 		if err != nil {
 			return fmt.Errorf("order not found: %w", err)
@@ -435,7 +513,7 @@ func (s *paymentService) PaymentNotifications(req *model.MidtransWebhookRequest)
 
 	// Update status_payment menjadi 3 sesuai logic yang diminta
 	status := 3
-	err = s.repo.UpdatePaymentStatus(req.OrderID, orderType, status)
+	err = s.repo.UpdatePaymentStatus(ctx, req.OrderID, orderType, status)
 	if err != nil {
 		return fmt.Errorf("failed to update payment status: %w", err)
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"service-travego/model"
@@ -21,29 +22,29 @@ func NewPreferenceCityService(prefRepo *repository.PreferenceCityRepository, loc
 	}
 }
 
-func (s *PreferenceCityService) Create(cityIDs []int, minimalDay int, organizationID string, createdBy string, serviceTypes []int) error {
+func (s *PreferenceCityService) Create(ctx context.Context, cityIDs []int, minimalDay int, createdBy string, serviceTypes []int) error {
 	for _, cityID := range cityIDs {
-		if err := s.prefRepo.Create(cityID, minimalDay, organizationID, createdBy, serviceTypes); err != nil {
+		if err := s.prefRepo.Create(ctx, cityID, minimalDay, createdBy, serviceTypes); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *PreferenceCityService) Update(preferenceID string, cityID int, minimalDay int, organizationID string, serviceTypeIDs []int) error {
-	return s.prefRepo.Update(preferenceID, cityID, minimalDay, organizationID, serviceTypeIDs)
+func (s *PreferenceCityService) Update(ctx context.Context, preferenceID string, cityID int, minimalDay int, serviceTypeIDs []int) error {
+	return s.prefRepo.Update(ctx, preferenceID, cityID, minimalDay, serviceTypeIDs)
 }
 
-func (s *PreferenceCityService) Delete(preferenceID, organizationID string) error {
-	return s.prefRepo.Delete(preferenceID, organizationID)
+func (s *PreferenceCityService) Delete(ctx context.Context, preferenceID string) error {
+	return s.prefRepo.Delete(ctx, preferenceID)
 }
 
-func (s *PreferenceCityService) DeleteByCityAndServiceType(cityID int, serviceType int, organizationID string) error {
-	return s.prefRepo.DeleteByCityAndServiceType(cityID, serviceType, organizationID)
+func (s *PreferenceCityService) DeleteByCityAndServiceType(ctx context.Context, cityID int, serviceType int) error {
+	return s.prefRepo.DeleteByCityAndServiceType(ctx, cityID, serviceType)
 }
 
-func (s *PreferenceCityService) GetAll(organizationID string, cityID *int) ([]model.PreferenceCityWithLabels, error) {
-	prefs, err := s.prefRepo.GetAll(organizationID, cityID)
+func (s *PreferenceCityService) GetAll(ctx context.Context, cityID *int) ([]model.PreferenceCityWithLabels, error) {
+	prefs, err := s.prefRepo.GetAll(ctx, cityID)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +91,7 @@ func (s *PreferenceCityService) GetAll(organizationID string, cityID *int) ([]mo
 			}
 		}
 
-		if types, err := s.prefRepo.GetTypesByCityIDAndOrganizationID(pref.CityID, pref.OrganizationID); err == nil {
+		if types, err := s.prefRepo.GetTypesByCityID(ctx, pref.CityID); err == nil {
 			for _, t := range types {
 				if label, ok := model.ServiceTypeLabels[t]; ok {
 					withLabels.ServiceTypes = append(withLabels.ServiceTypes, label)
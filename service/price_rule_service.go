@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"service-travego/model"
+	"service-travego/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+type PriceRuleService struct {
+	repo      *repository.PriceRuleRepository
+	fleetRepo *repository.FleetRepository
+}
+
+func NewPriceRuleService(repo *repository.PriceRuleRepository, fleetRepo *repository.FleetRepository) *PriceRuleService {
+	return &PriceRuleService{repo: repo, fleetRepo: fleetRepo}
+}
+
+func (s *PriceRuleService) internalMessage(base string, err error) string {
+	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
+		return fmt.Sprintf("%s: %v", base, err)
+	}
+	return base
+}
+
+// parsePricingDate accepts the date formats used by the order endpoints.
+func parsePricingDate(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.In(time.Local), true
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *PriceRuleService) validate(ctx context.Context, userID string, req *model.PriceRuleUpsertRequest) error {
+	req.OrganizationID = organizationFromContext(ctx)
+	req.UserID = userID
+	req.Name = strings.TrimSpace(req.Name)
+	req.RuleType = strings.ToUpper(strings.TrimSpace(req.RuleType))
+	req.AdjustmentType = strings.ToUpper(strings.TrimSpace(req.AdjustmentType))
+	req.FleetID = strings.TrimSpace(req.FleetID)
+
+	if _, ok := model.PriceRuleTypeLabel[req.RuleType]; !ok {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "rule_type must be one of: SEASONAL, WEEKEND, LEAD_TIME, PICKUP_CITY")
+	}
+	if req.AdjustmentType != model.PriceAdjustmentPercent && req.AdjustmentType != model.PriceAdjustmentFixed {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "adjustment_type must be PERCENT or FIXED")
+	}
+	if req.Value == 0 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "value must not be zero")
+	}
+	if req.AdjustmentType == model.PriceAdjustmentPercent && req.Value < -100 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "percent discount cannot exceed 100")
+	}
+
+	switch req.RuleType {
+	case model.PriceRuleTypeSeasonal:
+		start, okStart := parsePricingDate(req.StartDate)
+		end, okEnd := parsePricingDate(req.EndDate)
+		if !okStart || !okEnd {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date and end_date are required (YYYY-MM-DD)")
+		}
+		if end.Before(start) {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
+		}
+		req.StartDate = start.Format("2006-01-02")
+		req.EndDate = end.Format("2006-01-02")
+	case model.PriceRuleTypeLeadTime:
+		if req.MinLeadDays <= 0 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "min_lead_days must be greater than 0")
+		}
+		req.StartDate, req.EndDate = "", ""
+	case model.PriceRuleTypePickupCity:
+		cityIDs := make([]string, 0, len(req.CityIDs))
+		for _, id := range req.CityIDs {
+			if id = strings.TrimSpace(id); id != "" {
+				cityIDs = append(cityIDs, id)
+			}
+		}
+		if len(cityIDs) == 0 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "city_ids is required")
+		}
+		req.CityIDs = cityIDs
+		req.StartDate, req.EndDate = "", ""
+	default:
+		req.StartDate, req.EndDate = "", ""
+	}
+	if req.RuleType != model.PriceRuleTypePickupCity {
+		req.CityIDs = nil
+	}
+	if req.RuleType != model.PriceRuleTypeLeadTime {
+		req.MinLeadDays = 0
+	}
+	return nil
+}
+
+func (s *PriceRuleService) List(ctx context.Context) ([]model.PriceRule, error) {
+	items, err := s.repo.List(ctx, false)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get price rules", err))
+	}
+	for i := range items {
+		items[i].RuleTypeLabel = model.PriceRuleTypeLabel[items[i].RuleType]
+	}
+	return items, nil
+}
+
+func (s *PriceRuleService) Create(ctx context.Context, userID string, req *model.PriceRuleUpsertRequest) (string, error) {
+	if err := s.validate(ctx, userID, req); err != nil {
+		return "", err
+	}
+	id, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create price rule", err))
+	}
+	return id, nil
+}
+
+func (s *PriceRuleService) Update(ctx context.Context, userID string, req *model.PriceRuleUpsertRequest) error {
+	if strings.TrimSpace(req.RuleID) == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "rule_id is required")
+	}
+	if err := s.validate(ctx, userID, req); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, req); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "price rule not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update price rule", err))
+	}
+	return nil
+}
+
+func (s *PriceRuleService) Delete(ctx context.Context, userID, ruleID string) error {
+	if err := s.repo.Delete(ctx, userID, ruleID); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "price rule not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete price rule")
+	}
+	return nil
+}
+
+// OrderPriceRules returns the pricing rule breakdown stored on an order.
+func (s *PriceRuleService) OrderPriceRules(ctx context.Context, orderID string) ([]model.PriceRuleLine, error) {
+	lines, err := s.repo.ListOrderPriceRules(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get order price rules", err))
+	}
+	return lines, nil
+}
+
+// QuoteByPrice evaluates the organization's rules for a fleet price list item.
+func (s *PriceRuleService) QuoteByPrice(ctx context.Context, req *model.PriceQuoteRequest) (*model.PriceQuote, error) {
+	price, rentType, err := s.fleetRepo.GetPriceByID(ctx, req.PriceID)
+	if err != nil {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "price not found")
+	}
+	start, _ := parsePricingDate(req.StartDate)
+	end, _ := parsePricingDate(req.EndDate)
+	return s.Quote(ctx, model.PriceQuoteInput{
+		FleetID:      req.FleetID,
+		PriceID:      req.PriceID,
+		RentType:     rentType,
+		BasePrice:    price,
+		Qty:          req.Qty,
+		StartDate:    start,
+		EndDate:      end,
+		PickupCityID: req.PickupCityID,
+		OrderedAt:    time.Now(),
+	})
+}
+
+// pricingDays lists the calendar days of a trip. An unknown or inverted end
+// date counts as a single day trip.
+func pricingDays(start, end time.Time) []time.Time {
+	start = startOfDay(start)
+	end = startOfDay(end)
+	if end.Before(start) {
+		end = start
+	}
+	days := make([]time.Time, 0)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+func priceRuleMatchesScope(rule model.PriceRule, in model.PriceQuoteInput) bool {
+	if rule.FleetID != "" && rule.FleetID != in.FleetID {
+		return false
+	}
+	if rule.RentType != 0 && rule.RentType != in.RentType {
+		return false
+	}
+	return true
+}
+
+// Quote applies the organization's active pricing rules to a base unit price.
+func (s *PriceRuleService) Quote(ctx context.Context, in model.PriceQuoteInput) (*model.PriceQuote, error) {
+	if organizationFromContext(ctx) == "" {
+		return applyPriceRules(nil, in), nil
+	}
+	rules, err := s.repo.List(ctx, true)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get price rules", err))
+	}
+	return applyPriceRules(rules, in), nil
+}
+
+// applyPriceRules applies rules to a base unit price. For each rule type only
+// the highest priority matching rule applies (for lead time: the longest lead
+// satisfied), so overlapping seasons or tiers never stack. Seasonal and weekend
+// rules are prorated over the trip days they cover.
+func applyPriceRules(rules []model.PriceRule, in model.PriceQuoteInput) *model.PriceQuote {
+	qty := in.Qty
+	if qty <= 0 {
+		qty = 1
+	}
+	quote := &model.PriceQuote{
+		PriceID:   in.PriceID,
+		BasePrice: in.BasePrice,
+		UnitPrice: in.BasePrice,
+		Quantity:  qty,
+		Total:     in.BasePrice * float64(qty),
+		Lines:     make([]model.PriceRuleLine, 0),
+	}
+
+	hasDates := !in.StartDate.IsZero()
+	var days []time.Time
+	if hasDates {
+		days = pricingDays(in.StartDate, in.EndDate)
+	}
+
+	type candidate struct {
+		rule      model.PriceRule
+		coverDays int
+	}
+	best := make(map[string]candidate)
+	better := func(a, b model.PriceRule) bool {
+		if a.RuleType == model.PriceRuleTypeLeadTime && a.MinLeadDays != b.MinLeadDays {
+			return a.MinLeadDays > b.MinLeadDays
+		}
+		return a.Priority > b.Priority
+	}
+
+	for _, rule := range rules {
+		if !priceRuleMatchesScope(rule, in) {
+			continue
+		}
+		coverDays := 0
+		switch rule.RuleType {
+		case model.PriceRuleTypeSeasonal:
+			if !hasDates {
+				continue
+			}
+			from, _ := parsePricingDate(rule.StartDate)
+			to, _ := parsePricingDate(rule.EndDate)
+			for _, d := range days {
+				if !d.Before(from) && !d.After(to) {
+					coverDays++
+				}
+			}
+		case model.PriceRuleTypeWeekend:
+			if !hasDates {
+				continue
+			}
+			for _, d := range days {
+				if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+					coverDays++
+				}
+			}
+		case model.PriceRuleTypeLeadTime:
+			if !hasDates {
+				continue
+			}
+			orderedAt := in.OrderedAt
+			if orderedAt.IsZero() {
+				orderedAt = time.Now()
+			}
+			leadDays := int(startOfDay(in.StartDate).Sub(startOfDay(orderedAt)).Hours() / 24)
+			if leadDays < rule.MinLeadDays {
+				continue
+			}
+		case model.PriceRuleTypePickupCity:
+			matched := false
+			for _, id := range rule.CityIDs {
+				if id == strings.TrimSpace(in.PickupCityID) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		default:
+			continue
+		}
+		if (rule.RuleType == model.PriceRuleTypeSeasonal || rule.RuleType == model.PriceRuleTypeWeekend) && coverDays == 0 {
+			continue
+		}
+		if current, ok := best[rule.RuleType]; ok && !better(rule, current.rule) {
+			continue
+		}
+		best[rule.RuleType] = candidate{rule: rule, coverDays: coverDays}
+	}
+
+	types := make([]string, 0, len(best))
+	for t := range best {
+		types = append(types, t)
+	}
+	// Keep the breakdown order stable: season, weekend, pickup city, lead time
+	order := map[string]int{
+		model.PriceRuleTypeSeasonal:   1,
+		model.PriceRuleTypeWeekend:    2,
+		model.PriceRuleTypePickupCity: 3,
+		model.PriceRuleTypeLeadTime:   4,
+	}
+	sort.Slice(types, func(i, j int) bool { return order[types[i]] < order[types[j]] })
+
+	unitAdjustment := 0.0
+	for _, t := range types {
+		c := best[t]
+		prorated := c.rule.RuleType == model.PriceRuleTypeSeasonal || c.rule.RuleType == model.PriceRuleTypeWeekend
+
+		var unitAmount float64
+		if c.rule.AdjustmentType == model.PriceAdjustmentPercent {
+			unitAmount = in.BasePrice * c.rule.Value / 100
+			if prorated {
+				unitAmount = unitAmount * float64(c.coverDays) / float64(len(days))
+			}
+		} else {
+			unitAmount = c.rule.Value
+			if prorated {
+				unitAmount = c.rule.Value * float64(c.coverDays)
+			}
+		}
+		unitAmount = math.Round(unitAmount)
+		if unitAmount == 0 {
+			continue
+		}
+
+		unitAdjustment += unitAmount
+		quote.Lines = append(quote.Lines, model.PriceRuleLine{
+			RuleID:         c.rule.RuleID,
+			RuleName:       c.rule.Name,
+			RuleType:       c.rule.RuleType,
+			AdjustmentType: c.rule.AdjustmentType,
+			Value:          c.rule.Value,
+			UnitAmount:     unitAmount,
+			Quantity:       qty,
+			Amount:         unitAmount * float64(qty),
+		})
+	}
+
+	// Discounts never bring the unit price below zero
+	if in.BasePrice+unitAdjustment < 0 {
+		unitAdjustment = -in.BasePrice
+	}
+	quote.UnitAdjustment = unitAdjustment
+	quote.UnitPrice = in.BasePrice + unitAdjustment
+	quote.Total = quote.UnitPrice * float64(qty)
+	return quote
+}
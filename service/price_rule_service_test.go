@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"service-travego/model"
+	"service-travego/repository"
+)
+
+func TestApplyPriceRulesWithoutRules(t *testing.T) {
+	quote := applyPriceRules(nil, model.PriceQuoteInput{BasePrice: 500000, Qty: 2})
+	if quote.UnitPrice != 500000 || quote.Total != 1000000 || len(quote.Lines) != 0 {
+		t.Fatalf("expected the base price, got %+v", quote)
+	}
+}
+
+func TestApplyPriceRulesProratesSeasonalAndWeekend(t *testing.T) {
+	rules := []model.PriceRule{
+		{RuleID: "season", RuleType: model.PriceRuleTypeSeasonal, AdjustmentType: model.PriceAdjustmentPercent, Value: 20, StartDate: "2026-12-25", EndDate: "2026-12-31"},
+		{RuleID: "weekend", RuleType: model.PriceRuleTypeWeekend, AdjustmentType: model.PriceAdjustmentFixed, Value: 50000},
+	}
+	// Thursday 24 to Sunday 27 December: three season days, two weekend days
+	quote := applyPriceRules(rules, model.PriceQuoteInput{
+		BasePrice: 1000000,
+		Qty:       2,
+		StartDate: day("2026-12-24"),
+		EndDate:   day("2026-12-27"),
+	})
+	if len(quote.Lines) != 2 {
+		t.Fatalf("expected two rules applied, got %+v", quote.Lines)
+	}
+	if quote.Lines[0].RuleID != "season" || quote.Lines[0].UnitAmount != 150000 {
+		t.Errorf("expected 20%% over 3 of 4 days, got %+v", quote.Lines[0])
+	}
+	if quote.Lines[1].RuleID != "weekend" || quote.Lines[1].UnitAmount != 100000 || quote.Lines[1].Amount != 200000 {
+		t.Errorf("expected 50000 per weekend day and unit, got %+v", quote.Lines[1])
+	}
+	if quote.UnitAdjustment != 250000 || quote.Total != 2500000 {
+		t.Errorf("unexpected totals %+v", quote)
+	}
+}
+
+func TestApplyPriceRulesDoesNotStackOneType(t *testing.T) {
+	rules := []model.PriceRule{
+		{RuleID: "low", RuleType: model.PriceRuleTypeSeasonal, AdjustmentType: model.PriceAdjustmentPercent, Value: 10, StartDate: "2026-12-01", EndDate: "2026-12-31", Priority: 1},
+		{RuleID: "high", RuleType: model.PriceRuleTypeSeasonal, AdjustmentType: model.PriceAdjustmentPercent, Value: 30, StartDate: "2026-12-20", EndDate: "2026-12-31", Priority: 5},
+	}
+	quote := applyPriceRules(rules, model.PriceQuoteInput{
+		BasePrice: 1000000,
+		StartDate: day("2026-12-24"),
+		EndDate:   day("2026-12-24"),
+	})
+	if len(quote.Lines) != 1 || quote.Lines[0].RuleID != "high" || quote.UnitAdjustment != 300000 {
+		t.Fatalf("expected only the highest priority season, got %+v", quote.Lines)
+	}
+}
+
+func TestApplyPriceRulesTakesTheLongestLeadTime(t *testing.T) {
+	rules := []model.PriceRule{
+		{RuleID: "week", RuleType: model.PriceRuleTypeLeadTime, AdjustmentType: model.PriceAdjustmentPercent, Value: -5, MinLeadDays: 7, Priority: 9},
+		{RuleID: "month", RuleType: model.PriceRuleTypeLeadTime, AdjustmentType: model.PriceAdjustmentPercent, Value: -10, MinLeadDays: 30},
+	}
+	in := model.PriceQuoteInput{BasePrice: 1000000, OrderedAt: day("2026-10-01")}
+
+	in.StartDate, in.EndDate = day("2026-11-15"), day("2026-11-15")
+	if quote := applyPriceRules(rules, in); len(quote.Lines) != 1 || quote.Lines[0].RuleID != "month" || quote.UnitAdjustment != -100000 {
+		t.Errorf("45 days ahead: expected the 30 day discount, got %+v", quote.Lines)
+	}
+	in.StartDate, in.EndDate = day("2026-10-10"), day("2026-10-10")
+	if quote := applyPriceRules(rules, in); len(quote.Lines) != 1 || quote.Lines[0].RuleID != "week" {
+		t.Errorf("9 days ahead: expected the 7 day discount, got %+v", quote.Lines)
+	}
+	in.StartDate, in.EndDate = day("2026-10-03"), day("2026-10-03")
+	if quote := applyPriceRules(rules, in); len(quote.Lines) != 0 {
+		t.Errorf("2 days ahead: expected no discount, got %+v", quote.Lines)
+	}
+}
+
+func TestApplyPriceRulesMatchesPickupCityAndScope(t *testing.T) {
+	rules := []model.PriceRule{
+		{RuleID: "city", RuleType: model.PriceRuleTypePickupCity, AdjustmentType: model.PriceAdjustmentFixed, Value: 75000, CityIDs: []string{"3171", "3273"}},
+		{RuleID: "other-fleet", RuleType: model.PriceRuleTypeWeekend, AdjustmentType: model.PriceAdjustmentFixed, Value: 50000, FleetID: "fleet-2"},
+	}
+	quote := applyPriceRules(rules, model.PriceQuoteInput{
+		FleetID:      "fleet-1",
+		BasePrice:    1000000,
+		StartDate:    day("2026-10-17"),
+		EndDate:      day("2026-10-18"),
+		PickupCityID: "3273",
+	})
+	if len(quote.Lines) != 1 || quote.Lines[0].RuleID != "city" || quote.UnitAdjustment != 75000 {
+		t.Fatalf("expected only the pickup city rule, got %+v", quote.Lines)
+	}
+
+	quote = applyPriceRules(rules[:1], model.PriceQuoteInput{BasePrice: 1000000, PickupCityID: "1101"})
+	if len(quote.Lines) != 0 {
+		t.Fatalf("expected no rule for another city, got %+v", quote.Lines)
+	}
+}
+
+func TestApplyPriceRulesNeverGoesBelowZero(t *testing.T) {
+	rules := []model.PriceRule{
+		{RuleID: "city", RuleType: model.PriceRuleTypePickupCity, AdjustmentType: model.PriceAdjustmentFixed, Value: -1500000, CityIDs: []string{"3171"}},
+	}
+	quote := applyPriceRules(rules, model.PriceQuoteInput{BasePrice: 1000000, Qty: 3, PickupCityID: "3171"})
+	if quote.UnitAdjustment != -1000000 || quote.UnitPrice != 0 || quote.Total != 0 {
+		t.Fatalf("expected a free unit at most, got %+v", quote)
+	}
+}
+
+func TestNeedsRequote(t *testing.T) {
+	before := &repository.PartnerOrderPricing{
+		StartDate:    day("2026-12-24"),
+		EndDate:      day("2026-12-27"),
+		PickupCityID: "3171",
+		Items: map[string]repository.PartnerOrderItemPricing{
+			"item-1": {FleetID: "fleet-1", PriceID: "price-1", Quantity: 2, RuleAdjustment: 150000},
+		},
+	}
+	same := repository.PartnerOrderItemPricing{FleetID: "FLEET-1", PriceID: "price-1", Quantity: 2}
+	start, end := before.StartDate, before.EndDate
+
+	tests := []struct {
+		name   string
+		itemID string
+		after  repository.PartnerOrderItemPricing
+		start  time.Time
+		end    time.Time
+		city   string
+		want   bool
+	}{
+		{"unchanged", "item-1", same, start, end, "3171", false},
+		{"another time of the same day", "item-1", same, start.Add(2 * time.Hour), end, "3171", false},
+		{"new item", "", same, start, end, "3171", true},
+		{"moved trip", "item-1", same, start.AddDate(0, 0, 1), end, "3171", true},
+		{"longer trip", "item-1", same, start, end.AddDate(0, 0, 1), "3171", true},
+		{"other pickup city", "item-1", same, start, end, "3273", true},
+		{"other price", "item-1", repository.PartnerOrderItemPricing{FleetID: "fleet-1", PriceID: "price-2", Quantity: 2}, start, end, "3171", true},
+		{"other fleet", "item-1", repository.PartnerOrderItemPricing{FleetID: "fleet-2", PriceID: "price-1", Quantity: 2}, start, end, "3171", true},
+		{"other quantity", "item-1", repository.PartnerOrderItemPricing{FleetID: "fleet-1", PriceID: "price-1", Quantity: 3}, start, end, "3171", true},
+	}
+	for _, tt := range tests {
+		if got := needsRequote(before, tt.itemID, tt.after, tt.start, tt.end, tt.city); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
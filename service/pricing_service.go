@@ -1,8 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"log/slog"
 	"service-travego/helper"
 	"service-travego/model"
 	"service-travego/repository"
@@ -17,17 +18,18 @@ func NewPricingService(repo *repository.PricingRepository) *PricingService {
 	return &PricingService{repo: repo}
 }
 
-func (s *PricingService) GetPackages(orgID, userID string) ([]model.PackageResponse, error) {
+func (s *PricingService) GetPackages(ctx context.Context, userID string) ([]model.PackageResponse, error) {
 	packages, err := s.repo.GetPackages()
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.repo.InsertLog()
+	err = s.repo.InsertLog(ctx)
 
 	var subscription *model.Subscription
-	if orgID != "" {
-		subscription, err = s.repo.GetSubscriptionByOrgID(orgID)
+	// the landing page asks without an organization
+	if organizationFromContext(ctx) != "" {
+		subscription, err = s.repo.GetSubscription(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -66,15 +68,15 @@ func (s *PricingService) GetPackages(orgID, userID string) ([]model.PackageRespo
 	return resp, nil
 }
 
-func (s *PricingService) GetPackageDetail(packageID, orgID, userID string) (model.PackageDetail, error) {
+func (s *PricingService) GetPackageDetail(ctx context.Context, packageID, userID string) (model.PackageDetail, error) {
 	packages, err := s.repo.GetPackages()
 	if err != nil {
 		return model.PackageDetail{}, err
 	}
 
 	var subscription *model.Subscription
-	if orgID != "" {
-		subscription, err = s.repo.GetSubscriptionByOrgID(orgID)
+	if organizationFromContext(ctx) != "" {
+		subscription, err = s.repo.GetSubscription(ctx)
 		if err != nil {
 			return model.PackageDetail{}, err
 		}
@@ -87,8 +89,8 @@ func (s *PricingService) GetPackageDetail(packageID, orgID, userID string) (mode
 
 	today := time.Now()
 	for _, p := range packages {
-		fmt.Println("decryptedInputPackageID ", decryptedInputPackageID)
-		fmt.Println("p.PackageID ", p.PackageID)
+		slog.DebugContext(ctx, "decrypted input package ID", "decrypted_input_package_id", decryptedInputPackageID)
+		slog.DebugContext(ctx, "package", "package_id", p.PackageID)
 		if p.PackageID == decryptedInputPackageID {
 			isCurrentPackage := false
 			if subscription != nil && subscription.PackageID == p.PackageID {
@@ -122,8 +124,8 @@ func (s *PricingService) GetPackageDetail(packageID, orgID, userID string) (mode
 	return model.PackageDetail{}, errors.New("package not found")
 }
 
-func (s *PricingService) GetReviews() ([]model.Review, error) {
-	reviews, err := s.repo.GetReviews()
+func (s *PricingService) GetReviews(ctx context.Context) ([]model.Review, error) {
+	reviews, err := s.repo.GetReviews(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +144,6 @@ func (s *PricingService) GetReviews() ([]model.Review, error) {
 	return resp, nil
 }
 
-func (s *PricingService) SubmitContact(contact model.ContactSubmission) error {
-	return s.repo.SubmitContact(contact)
+func (s *PricingService) SubmitContact(ctx context.Context, contact model.ContactSubmission) error {
+	return s.repo.SubmitContact(ctx, contact)
 }
@@ -5,15 +5,17 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"io"
-	"log"
+	"log/slog"
 	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"service-travego/internal/pdfrender"
 	"service-travego/model"
 	"service-travego/repository"
 	"strconv"
@@ -21,8 +23,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/skip2/go-qrcode"
 )
 
@@ -165,7 +165,8 @@ func (s *PrintManagementService) ensureTransactionItemsLoaded() {
 	})
 }
 
-func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID string) ([]byte, error) {
+func (s *PrintManagementService) GenerateOrderFleetPDF(ctx context.Context, orderID string) ([]byte, error) {
+	organizationID := organizationFromContext(ctx)
 	orderID = strings.TrimSpace(orderID)
 	if orderID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
@@ -174,7 +175,7 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "missing organization context")
 	}
 
-	org, err := s.repo.GetOrganizationInfo(organizationID)
+	org, err := s.repo.GetOrganizationInfo(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "organization not found")
@@ -182,7 +183,7 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch organization")
 	}
 
-	order, err := s.repo.GetFleetOrderInfo(orderID, organizationID)
+	order, err := s.repo.GetFleetOrderInfo(ctx, orderID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
@@ -190,7 +191,7 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch order")
 	}
 
-	customer, err := s.repo.GetCustomerInfo(orderID, organizationID)
+	customer, err := s.repo.GetCustomerInfo(ctx, orderID)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch customer")
 	}
@@ -198,12 +199,12 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 		customer = &repository.PrintCustomerInfo{}
 	}
 
-	items, err := s.repo.GetFleetOrderItems(orderID, organizationID)
+	items, err := s.repo.GetFleetOrderItems(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch order items")
 	}
 
-	addons, err := s.repo.GetFleetOrderAddons(orderID, organizationID)
+	addons, err := s.repo.GetFleetOrderAddons(ctx, orderID)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch addons")
 	}
@@ -225,7 +226,7 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 		}
 	}
 
-	bank, err := s.repo.GetOrganizationBankAccount(organizationID)
+	bank, err := s.repo.GetOrganizationBankAccount(ctx)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch bank account")
 	}
@@ -295,9 +296,26 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 
 	fullPaymentDue, dpDue := computeDueDates(order.CreatedAt, order.StartDate)
 
+	// Orders with a payment plan print their real installment schedule
+	installments, err := s.repo.GetOrderInstallments(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch installments")
+	}
+	if len(installments) > 0 {
+		minimumPayment = installments[0].Amount
+		dpDue = installments[0].DueDate
+		remainingAmount = totalAmount - minimumPayment
+		if remainingAmount < 0 {
+			remainingAmount = 0
+		}
+		fullPaymentDue = installments[len(installments)-1].DueDate
+	}
+	paymentTermRows := buildPaymentTermRows(installments, minimumPayment, dpDue, remainingAmount, fullPaymentDue)
+
+	// the QR only changes with the order, so an unchanged order prints the
+	// same document and is served from the PDF cache
 	invoiceID := order.InvoiceIDCandidate
-	ts := time.Now().Unix()
-	qrPayload := fmt.Sprintf("%s|%s|%d", orderID, invoiceID, ts)
+	qrPayload := fmt.Sprintf("%s|%s|%d", orderID, invoiceID, order.UpdatedAt.Unix())
 	qrPNG, err := qrcode.Encode(qrPayload, qrcode.Medium, 256)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate qr")
@@ -331,12 +349,12 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 
 	companyLogoURL, companyLogoBase := resolveAssetURL(org.CompanyWebsite, org.CompanyLogo)
 	if shouldLogDev() {
-		log.Printf("[PRINT] company_logo raw=%q base=%q resolved=%q", strings.TrimSpace(org.CompanyLogo), companyLogoBase, companyLogoURL)
+		slog.DebugContext(ctx, "print: company logo", "raw", strings.TrimSpace(org.CompanyLogo), "base", companyLogoBase, "resolved", companyLogoURL)
 	}
 	if dataURL, ok, err := fetchImageAsDataURL(companyLogoURL); ok {
 		companyLogoURL = dataURL
 	} else if shouldLogDev() && err != nil {
-		log.Printf("[PRINT] company_logo fetch failed resolved=%q err=%v", companyLogoURL, err)
+		slog.ErrorContext(ctx, "print: company logo fetch failed", "resolved", companyLogoURL, "error", err)
 	}
 
 	vars := map[string]string{
@@ -381,6 +399,7 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 		"remaining_amount":       html.EscapeString(formatIDR(remainingAmount)),
 		"dp_due_date":            html.EscapeString(formatDateLong(dpDue)),
 		"full_payment_due_date":  html.EscapeString(formatDateLong(fullPaymentDue)),
+		"payment_term_rows":      paymentTermRows,
 		"bank_name":              html.EscapeString(bankName),
 		"bank_code":              html.EscapeString(bank.BankCode),
 		"bank_account":           html.EscapeString(bank.BankAccount),
@@ -403,14 +422,11 @@ func (s *PrintManagementService) GenerateOrderFleetPDF(organizationID, orderID s
 
 	htmlDoc := applyTemplateVars(string(rawTpl), vars)
 
-	pdf, err := renderHTMLToPDF(htmlDoc)
-	if err != nil {
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to render pdf")
-	}
-	return pdf, nil
+	return renderHTMLToPDF(ctx, htmlDoc)
 }
 
-func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID string, invoiceNumber *string) ([]byte, error) {
+func (s *PrintManagementService) GenerateFleetInvoicePDF(ctx context.Context, orderID string, invoiceNumber *string) ([]byte, error) {
+	organizationID := organizationFromContext(ctx)
 	orderID = strings.TrimSpace(orderID)
 	if orderID == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
@@ -419,7 +435,7 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "missing organization context")
 	}
 
-	org, err := s.repo.GetOrganizationInfo(organizationID)
+	org, err := s.repo.GetOrganizationInfo(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "organization not found")
@@ -427,7 +443,7 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch organization")
 	}
 
-	order, err := s.repo.GetFleetOrderInfo(orderID, organizationID)
+	order, err := s.repo.GetFleetOrderInfo(ctx, orderID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "order not found")
@@ -435,7 +451,7 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch order")
 	}
 
-	customer, err := s.repo.GetCustomerInfo(orderID, organizationID)
+	customer, err := s.repo.GetCustomerInfo(ctx, orderID)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch customer")
 	}
@@ -443,12 +459,12 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		customer = &repository.PrintCustomerInfo{}
 	}
 
-	items, err := s.repo.GetFleetOrderItems(orderID, organizationID)
+	items, err := s.repo.GetFleetOrderItems(ctx, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch order items")
 	}
 
-	addons, err := s.repo.GetFleetOrderAddons(orderID, organizationID)
+	addons, err := s.repo.GetFleetOrderAddons(ctx, orderID)
 	if err != nil && err != sql.ErrNoRows {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch addons")
 	}
@@ -470,7 +486,7 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		}
 	}
 
-	pay, err := s.repo.GetPaymentOrderForInvoice(organizationID, orderID, invoiceNumber)
+	pay, err := s.repo.GetPaymentOrderForInvoice(ctx, orderID, invoiceNumber)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "payment not found")
@@ -516,7 +532,18 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		}
 	}
 
-	totalAmount := subtotalFleet + totalAdditionalFee - totalDiscount
+	voucherRow := ""
+	var voucherDiscount float64
+	voucher, err := s.repo.GetOrderVoucher(ctx, orderID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch voucher")
+	}
+	if voucher != nil && voucher.DiscountAmount > 0 {
+		voucherDiscount = voucher.DiscountAmount
+		voucherRow = buildFleetInvoiceVoucherRow(voucher)
+	}
+
+	totalAmount := subtotalFleet + totalAdditionalFee - totalDiscount - voucherDiscount
 	if totalAmount < 0 {
 		totalAmount = 0
 	}
@@ -547,17 +574,25 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 
 	companyLogoURL, companyLogoBase := resolveAssetURL(org.CompanyWebsite, org.CompanyLogo)
 	if shouldLogDev() {
-		log.Printf("[PRINT] company_logo raw=%q base=%q resolved=%q", strings.TrimSpace(org.CompanyLogo), companyLogoBase, companyLogoURL)
+		slog.DebugContext(ctx, "print: company logo", "raw", strings.TrimSpace(org.CompanyLogo), "base", companyLogoBase, "resolved", companyLogoURL)
 	}
 	if dataURL, ok, err := fetchImageAsDataURL(companyLogoURL); ok {
 		companyLogoURL = dataURL
 	} else if shouldLogDev() && err != nil {
-		log.Printf("[PRINT] company_logo fetch failed resolved=%q err=%v", companyLogoURL, err)
+		slog.ErrorContext(ctx, "print: company logo fetch failed", "resolved", companyLogoURL, "error", err)
 	}
 
+	// a payment without an invoice number gets one the first time it is
+	// printed, and keeps it for every later print
 	inv := strings.TrimSpace(pay.InvoiceNumber)
 	if inv == "" {
-		inv, _ = s.repo.GenerateInvoiceNumber(1, organizationID, time.Now())
+		if generated, err := s.repo.GenerateInvoiceNumber(ctx, 1, pay.CreatedAt); err == nil && generated != "" {
+			if stored, err := s.repo.AssignPaymentInvoiceNumber(ctx, pay.PaymentID, generated); err == nil {
+				inv = stored
+			} else {
+				slog.ErrorContext(ctx, "print: failed to store invoice number", "payment_id", pay.PaymentID, "error", err)
+			}
+		}
 		if inv == "" {
 			inv = "-"
 		}
@@ -596,6 +631,7 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 		"additional_charges": html.EscapeString(formatNumberIDR(totalAdditionalFee)),
 		"total_addon":        html.EscapeString(formatNumberIDR(totalAddon)),
 		"total_discount":     html.EscapeString(formatNumberIDR(totalDiscount)),
+		"voucher_row":        voucherRow,
 		"total_amount":       html.EscapeString(formatNumberIDR(totalAmount)),
 		"payment_type":       html.EscapeString(paymentTypeLabel),
 		"payment_amount":     html.EscapeString(formatNumberIDR(pay.PaymentAmount)),
@@ -605,14 +641,11 @@ func (s *PrintManagementService) GenerateFleetInvoicePDF(organizationID, orderID
 	}
 
 	htmlDoc := applyTemplateVars(string(rawTpl), vars)
-	pdf, err := renderHTMLToPDF(htmlDoc)
-	if err != nil {
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to render pdf")
-	}
-	return pdf, nil
+	return renderHTMLToPDF(ctx, htmlDoc)
 }
 
-func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleNumber string) ([]byte, error) {
+func (s *PrintManagementService) GenerateFleetTripsPDF(ctx context.Context, scheduleNumber string) ([]byte, error) {
+	organizationID := organizationFromContext(ctx)
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "schedule_number is required")
@@ -621,7 +654,7 @@ func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleN
 		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "missing organization context")
 	}
 
-	org, err := s.repo.GetOrganizationInfo(organizationID)
+	org, err := s.repo.GetOrganizationInfo(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "organization not found")
@@ -629,7 +662,7 @@ func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleN
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch organization")
 	}
 
-	orderID, err := s.repo.GetOrderIDByScheduleNumber(scheduleNumber, organizationID)
+	orderID, err := s.repo.GetOrderIDByScheduleNumber(ctx, scheduleNumber)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "schedule not found")
@@ -637,17 +670,17 @@ func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleN
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch schedule")
 	}
 
-	totalExpenses, totalReimburse, err := s.repo.GetFleetTripTotals(scheduleNumber, organizationID, orderID)
+	totalExpenses, totalReimburse, err := s.repo.GetFleetTripTotals(ctx, scheduleNumber, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch totals")
 	}
 
-	operationalFee, err := s.repo.GetFleetTripOperationalFee(scheduleNumber, organizationID)
+	operationalFee, err := s.repo.GetFleetTripOperationalFee(ctx, scheduleNumber)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch operational fee")
 	}
 
-	history, err := s.repo.GetFleetTripExpenseHistory(scheduleNumber, organizationID, orderID)
+	history, err := s.repo.GetFleetTripExpenseHistory(ctx, scheduleNumber, orderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch expenses history")
 	}
@@ -675,8 +708,7 @@ func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleN
 		companyName = org.OrganizationName
 	}
 
-	ts := time.Now().Unix()
-	qrPayload := fmt.Sprintf("%s|%s|%d", scheduleNumber, orderID, ts)
+	qrPayload := fmt.Sprintf("%s|%s", scheduleNumber, orderID)
 	qrPNG, err := qrcode.Encode(qrPayload, qrcode.Medium, 256)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate qr")
@@ -685,12 +717,12 @@ func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleN
 
 	companyLogoURL, companyLogoBase := resolveAssetURL(org.CompanyWebsite, org.CompanyLogo)
 	if shouldLogDev() {
-		log.Printf("[PRINT] company_logo raw=%q base=%q resolved=%q", strings.TrimSpace(org.CompanyLogo), companyLogoBase, companyLogoURL)
+		slog.DebugContext(ctx, "print: company logo", "raw", strings.TrimSpace(org.CompanyLogo), "base", companyLogoBase, "resolved", companyLogoURL)
 	}
 	if dataURL, ok, err := fetchImageAsDataURL(companyLogoURL); ok {
 		companyLogoURL = dataURL
 	} else if shouldLogDev() && err != nil {
-		log.Printf("[PRINT] company_logo fetch failed resolved=%q err=%v", companyLogoURL, err)
+		slog.ErrorContext(ctx, "print: company logo fetch failed", "resolved", companyLogoURL, "error", err)
 	}
 
 	expenseBalance := operationalFee - totalExpenses
@@ -723,21 +755,17 @@ func (s *PrintManagementService) GenerateFleetTripsPDF(organizationID, scheduleN
 	}
 
 	htmlDoc := applyTemplateVars(string(rawTpl), vars)
-	pdf, err := renderHTMLToPDF(htmlDoc)
-	if err != nil {
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to render pdf")
-	}
-	return pdf, nil
+	return renderHTMLToPDF(ctx, htmlDoc)
 }
 
-func (s *PrintManagementService) GenerateSubscriptionPDF(organizationID, invoiceNumber string) ([]byte, error) {
+func (s *PrintManagementService) GenerateSubscriptionPDF(ctx context.Context, invoiceNumber string) ([]byte, error) {
 	invoiceNumber = strings.TrimSpace(invoiceNumber)
 	if invoiceNumber == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invoice_number is required")
 	}
 
 	// Get subscription transaction details
-	_, packageID, startDate, expiryDate, _, organizationID, paymentMethod, createdAt, paymentAmount, err := s.repo.GetSubscriptionDetailByInvoice(invoiceNumber)
+	_, packageID, startDate, expiryDate, _, _, paymentMethod, createdAt, paymentAmount, err := s.repo.GetSubscriptionDetailByInvoice(ctx, invoiceNumber)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "subscription transaction not found")
@@ -746,7 +774,7 @@ func (s *PrintManagementService) GenerateSubscriptionPDF(organizationID, invoice
 	}
 
 	// Get organization info
-	org, err := s.repo.GetOrganizationInfo(organizationID)
+	org, err := s.repo.GetOrganizationInfo(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "organization not found")
@@ -845,14 +873,10 @@ func (s *PrintManagementService) GenerateSubscriptionPDF(organizationID, invoice
 		"expiration_date":        html.EscapeString(formatDateLong(expiryDate)),
 	}
 
-	fmt.Println(vars)
+	slog.DebugContext(ctx, "print: subscription pdf", "vars", vars)
 
 	htmlDoc := applyTemplateVars(string(rawTpl), vars)
-	pdf, err := renderHTMLToPDF(htmlDoc)
-	if err != nil {
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to render pdf")
-	}
-	return pdf, nil
+	return renderHTMLToPDF(ctx, htmlDoc)
 }
 
 func applyTemplateVars(tpl string, vars map[string]string) string {
@@ -984,6 +1008,20 @@ func buildFleetRows(items []repository.PrintFleetOrderItem, addonsByItem map[str
 	return b.String(), subtotals
 }
 
+func buildFleetInvoiceVoucherRow(v *repository.PrintOrderVoucher) string {
+	label := "Voucher " + strings.TrimSpace(v.Code)
+	if name := strings.TrimSpace(v.Name); name != "" {
+		label += " (" + name + ")"
+	}
+	var b strings.Builder
+	b.WriteString(`<tr><td colspan="3"></td><td>`)
+	b.WriteString(html.EscapeString(label))
+	b.WriteString(`</td><td class="r" style="text-align: right;">Rp `)
+	b.WriteString(html.EscapeString(formatNumberIDR(v.DiscountAmount)))
+	b.WriteString(`</td></tr>`)
+	return b.String()
+}
+
 func buildFleetInvoiceRows(items []repository.PrintFleetOrderItem, addonsByItem map[string][]repository.PrintFleetOrderAddon) (string, []float64) {
 	if len(items) == 0 {
 		return `<tr><td class="c">1</td><td>-</td><td class="c">0 unit</td><td class="r">Rp 0</td><td class="r"><strong>Rp 0</strong></td></tr>`, []float64{0}
@@ -1046,6 +1084,31 @@ func buildFleetInvoiceRows(items []repository.PrintFleetOrderItem, addonsByItem
 	return b.String(), subtotals
 }
 
+// buildPaymentTermRows renders one row per installment, or the default DP and
+// pelunasan rows when the order has no payment plan.
+func buildPaymentTermRows(installments []repository.PrintOrderInstallment, minimumPayment float64, dpDue time.Time, remainingAmount float64, fullPaymentDue time.Time) string {
+	if len(installments) == 0 {
+		installments = []repository.PrintOrderInstallment{
+			{Label: "DP Minimum 20%", Amount: minimumPayment, DueDate: dpDue},
+			{Label: "Pelunasan", Amount: remainingAmount, DueDate: fullPaymentDue},
+		}
+	}
+	var b strings.Builder
+	for _, it := range installments {
+		b.WriteString(`<div class="pay-row"><span>`)
+		b.WriteString(html.EscapeString(it.Label))
+		if it.Status == model.InstallmentStatusPaid {
+			b.WriteString(" (Lunas)")
+		}
+		b.WriteString(`</span><div style="text-align:right"><strong>`)
+		b.WriteString(html.EscapeString(formatIDR(it.Amount)))
+		b.WriteString(`</strong><div class="due">Jatuh tempo: `)
+		b.WriteString(html.EscapeString(formatDateLong(it.DueDate)))
+		b.WriteString(`</div></div></div>`)
+	}
+	return b.String()
+}
+
 func computeDueDates(createdAt, startDate time.Time) (time.Time, time.Time) {
 	daysUntilStart := startDate.Sub(createdAt).Hours() / 24
 	if daysUntilStart < 7 {
@@ -1057,61 +1120,29 @@ func computeDueDates(createdAt, startDate time.Time) (time.Time, time.Time) {
 	return startDate.AddDate(0, 0, -7), createdAt.AddDate(0, 0, 7)
 }
 
-func renderHTMLToPDF(htmlDoc string) ([]byte, error) {
-	allocatorCtx, cancelAllocator := chromedp.NewExecAllocator(
-		context.Background(),
-		append(chromedp.DefaultExecAllocatorOptions[:],
-			chromedp.Flag("headless", true),
-			chromedp.Flag("disable-gpu", true),
-			chromedp.Flag("no-sandbox", true),
-			chromedp.Flag("disable-dev-shm-usage", true),
-		)...,
-	)
-	defer cancelAllocator()
-
-	ctx, cancel := chromedp.NewContext(allocatorCtx)
-	defer cancel()
-
-	ctx, cancelTimeout := context.WithTimeout(ctx, 45*time.Second)
-	defer cancelTimeout()
-
-	var frameTree *page.FrameTree
-	var pdfBuf []byte
-
-	err := chromedp.Run(
-		ctx,
-		chromedp.Navigate("about:blank"),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			ft, err := page.GetFrameTree().Do(ctx)
-			if err != nil {
-				return err
-			}
-			frameTree = ft
-			return nil
-		}),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			if frameTree == nil {
-				return fmt.Errorf("missing frame tree")
-			}
-			return page.SetDocumentContent(frameTree.Frame.ID, htmlDoc).Do(ctx)
-		}),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			buf, _, err := page.PrintToPDF().
-				WithPrintBackground(true).
-				WithPaperWidth(8.27).
-				WithPaperHeight(11.69).
-				Do(ctx)
-			if err != nil {
-				return err
-			}
-			pdfBuf = buf
-			return nil
-		}),
-	)
+// pdfRenderer prints every document of the service; it is shared so all
+// prints go through the same Chrome tabs, queue and cache
+var pdfRenderer *pdfrender.Pool
+
+// SetPDFRenderer sets the pool PDFs are printed with. Call it before the
+// routes are set up.
+func SetPDFRenderer(pool *pdfrender.Pool) {
+	pdfRenderer = pool
+}
+
+func renderHTMLToPDF(ctx context.Context, htmlDoc string) ([]byte, error) {
+	if pdfRenderer == nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "pdf renderer is not configured")
+	}
+	pdf, err := pdfRenderer.Render(ctx, htmlDoc)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, pdfrender.ErrBusy) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, NewServiceError(ErrInternalServer, http.StatusServiceUnavailable, "too many documents are being printed, please try again")
+		}
+		slog.ErrorContext(ctx, "print: render pdf failed", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to render pdf")
 	}
-	return pdfBuf, nil
+	return pdf, nil
 }
 
 func formatDate(t time.Time) string {
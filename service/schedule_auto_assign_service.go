@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"service-travego/configs"
+	"service-travego/model"
+	"service-travego/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// autoAssignWorkloadDays is the window before and after the trip used to
+	// balance driver/crew and unit workload.
+	autoAssignWorkloadDays = 30
+	// autoAssignHistoryDays is how far back past driving/crew experience is read.
+	autoAssignHistoryDays = 365
+)
+
+// SetLeaveRepository enables leave-aware auto assignment.
+func (s *ScheduleService) SetLeaveRepository(leaveRepo *repository.LeaveManagementRepository) {
+	s.leaveRepo = leaveRepo
+}
+
+type autoAssignEmployee struct {
+	model.ScheduleOperationAvailabilityItem
+	DriverTrips  int
+	CrewTrips    int
+	WorkloadDays int
+}
+
+// overlapDays returns the number of calendar days [start, end] shares with [from, to].
+func overlapDays(start, end, from, to time.Time) int {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	start = startOfDay(start)
+	end = startOfDay(end)
+	if end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// employeesOnLeave describes the approved leave of each employee who is away,
+// substituted or not. Pending and rejected leaves do not keep anyone away.
+func employeesOnLeave(leaves []model.EmployeeLeavePeriod) map[string]string {
+	out := make(map[string]string)
+	for _, leave := range leaves {
+		if leave.Status != model.LeaveStatusApproved {
+			continue
+		}
+		out[leave.EmployeeID] = fmt.Sprintf("%s %s - %s", leave.LeaveTypeLabel, leave.StartDate.Format("2006-01-02"), leave.EndDate.Format("2006-01-02"))
+	}
+	return out
+}
+
+// AutoAssignSchedule proposes units, drivers and crews for every ordered fleet
+// of an order. The proposal is not saved; the dispatcher accepts or adjusts it
+// and submits it through CreateSchedule.
+func (s *ScheduleService) AutoAssignSchedule(ctx context.Context, input model.ScheduleAutoAssignServiceInput) (*model.ScheduleAutoAssignResponse, error) {
+	orderID := strings.TrimSpace(input.Request.OrderID)
+	warnings := make([]string, 0)
+
+	paymentStatus, exists, err := s.repo.OrderPaymentStatus(ctx, model.ScheduleOrderValidationInput{
+		OrderID: orderID,
+	})
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to auto assign schedule", err))
+	}
+	if !exists {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_ID_NOT_FOUND")
+	}
+	if paymentStatus == int(configs.PaymentStatusCancelled) {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_CANCELLED")
+	}
+	if paymentStatus == int(configs.PaymentStatusWaitingPayment) {
+		warnings = append(warnings, "order is unpaid; the schedule cannot be saved until it is paid")
+	}
+
+	startDate, endDate, _, err := s.repo.OrderTripDates(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get order dates", err))
+	}
+	startText := startDate.Format("2006-01-02")
+	endText := endDate.Format("2006-01-02")
+
+	fleetItems, err := s.repo.ListOrderFleetItems(ctx, orderID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get order fleets", err))
+	}
+	if len(fleetItems) == 0 {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_HAS_NO_FLEET")
+	}
+
+	// Crew availability: free on the trip dates, not on leave, documents valid
+	employees, err := s.GetScheduleOperationAvailability(ctx, startText, endText, "")
+	if err != nil {
+		return nil, err
+	}
+
+	onLeave := make(map[string]string)
+	if s.leaveRepo != nil {
+		leaves, err := s.leaveRepo.ListApprovedLeaves(ctx, startDate, endDate)
+		if err != nil {
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get employee leaves", err))
+		}
+		onLeave = employeesOnLeave(leaves)
+	}
+
+	unitsByFleet := make(map[string][]model.ScheduleFleetUnitAvailabilityItem, len(fleetItems))
+	ownerIDs := make([]string, 0, len(employees))
+	for _, item := range fleetItems {
+		units, err := s.GetScheduleFleetUnitAvailability(ctx, model.ScheduleFleetUnitAvailabilityServiceInput{
+			StartDate: startText,
+			EndDate:   endText,
+			FleetID:   item.FleetID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		unitsByFleet[item.FleetID] = units
+		for _, unit := range units {
+			ownerIDs = append(ownerIDs, unit.UnitID)
+		}
+	}
+	for _, emp := range employees {
+		ownerIDs = append(ownerIDs, emp.UUID)
+	}
+
+	expiredDocs := make(map[string]string)
+	if s.documentRepo != nil {
+		rows, err := s.documentRepo.ListExpiredForOwners(ctx, ownerIDs, endDate)
+		if err != nil {
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate documents", err))
+		}
+		for _, row := range rows {
+			label := model.DocumentTypeLabel[row.DocumentType]
+			if label == "" {
+				label = row.DocumentType
+			}
+			expiredDocs[row.OwnerID] = fmt.Sprintf("%s valid until %s", label, row.ExpiryDate.Format("2006-01-02"))
+		}
+	}
+
+	// Workload: trip days in the window around the order, experience from the last year
+	workloadFrom := startDate.AddDate(0, 0, -autoAssignWorkloadDays)
+	workloadTo := endDate.AddDate(0, 0, autoAssignWorkloadDays)
+	teamTrips, err := s.repo.ListTeamTrips(ctx, startDate.AddDate(0, 0, -autoAssignHistoryDays), workloadTo)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get crew workload", err))
+	}
+	unitTrips, err := s.repo.ListUnitTrips(ctx, workloadFrom, workloadTo)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get unit workload", err))
+	}
+
+	type employeeStat struct{ driverTrips, crewTrips, days int }
+	stats := make(map[string]*employeeStat)
+	statFor := func(id string) *employeeStat {
+		if stats[id] == nil {
+			stats[id] = &employeeStat{}
+		}
+		return stats[id]
+	}
+	for _, trip := range teamTrips {
+		days := overlapDays(trip.StartDate, trip.EndDate, workloadFrom, workloadTo)
+		if trip.DriverID != "" {
+			st := statFor(trip.DriverID)
+			st.driverTrips++
+			st.days += days
+		}
+		if trip.CrewID != "" {
+			st := statFor(trip.CrewID)
+			st.crewTrips++
+			st.days += days
+		}
+	}
+	unitDays := make(map[string]int)
+	for _, trip := range unitTrips {
+		unitDays[trip.UnitID] += overlapDays(trip.StartDate, trip.EndDate, workloadFrom, workloadTo)
+	}
+
+	pool := make([]autoAssignEmployee, 0, len(employees))
+	for _, emp := range employees {
+		if reason, ok := onLeave[emp.UUID]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s skipped: on leave (%s)", emp.Fullname, reason))
+			continue
+		}
+		if reason, ok := expiredDocs[emp.UUID]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s skipped: %s", emp.Fullname, reason))
+			continue
+		}
+		candidate := autoAssignEmployee{ScheduleOperationAvailabilityItem: emp}
+		if st := stats[emp.UUID]; st != nil {
+			candidate.DriverTrips = st.driverTrips
+			candidate.CrewTrips = st.crewTrips
+			candidate.WorkloadDays = st.days
+		}
+		pool = append(pool, candidate)
+	}
+
+	// pick removes and returns the best candidate for a role: experienced in the
+	// role first, then the lightest workload.
+	pick := func(asDriver bool) (autoAssignEmployee, bool) {
+		if len(pool) == 0 {
+			return autoAssignEmployee{}, false
+		}
+		experience := func(e autoAssignEmployee) int {
+			if asDriver {
+				return e.DriverTrips
+			}
+			return e.CrewTrips
+		}
+		sort.SliceStable(pool, func(i, j int) bool {
+			ei, ej := experience(pool[i]) > 0, experience(pool[j]) > 0
+			if ei != ej {
+				return ei
+			}
+			if pool[i].WorkloadDays != pool[j].WorkloadDays {
+				return pool[i].WorkloadDays < pool[j].WorkloadDays
+			}
+			return pool[i].Fullname < pool[j].Fullname
+		})
+		chosen := pool[0]
+		pool = pool[1:]
+		return chosen, true
+	}
+
+	explain := func(role string, e autoAssignEmployee, asDriver bool) string {
+		trips := e.CrewTrips
+		if asDriver {
+			trips = e.DriverTrips
+		}
+		experience := fmt.Sprintf("%d previous trips as %s", trips, role)
+		if trips == 0 {
+			experience = fmt.Sprintf("no previous trips as %s", role)
+		}
+		return fmt.Sprintf("%s %s: available and not on leave, %s, %d trip days within %d days of this order", role, e.Fullname, experience, e.WorkloadDays, autoAssignWorkloadDays)
+	}
+
+	complete := true
+	assignments := make([]model.ScheduleAutoAssignUnit, 0)
+	for _, item := range fleetItems {
+		units := make([]model.ScheduleFleetUnitAvailabilityItem, 0, len(unitsByFleet[item.FleetID]))
+		for _, unit := range unitsByFleet[item.FleetID] {
+			if reason, ok := expiredDocs[unit.UnitID]; ok {
+				warnings = append(warnings, fmt.Sprintf("unit %s skipped: %s", unit.PlateNumber, reason))
+				continue
+			}
+			units = append(units, unit)
+		}
+		sort.SliceStable(units, func(i, j int) bool {
+			return unitDays[units[i].UnitID] < unitDays[units[j].UnitID]
+		})
+
+		if len(units) < item.Quantity {
+			complete = false
+			warnings = append(warnings, fmt.Sprintf("only %d of %d units of %s are available", len(units), item.Quantity, item.FleetName))
+		}
+
+		for i := 0; i < item.Quantity && i < len(units); i++ {
+			unit := units[i]
+			assignment := model.ScheduleAutoAssignUnit{
+				FleetID:     item.FleetID,
+				FleetName:   item.FleetName,
+				UnitID:      unit.UnitID,
+				PlateNumber: unit.PlateNumber,
+				Reasons: []string{
+					fmt.Sprintf("unit %s: free and not in maintenance on %s - %s, %d trip days within %d days of this order", unit.PlateNumber, startText, endText, unitDays[unit.UnitID], autoAssignWorkloadDays),
+				},
+			}
+
+			if driver, ok := pick(true); ok {
+				assignment.DriverID = driver.UUID
+				assignment.DriverName = driver.Fullname
+				assignment.Reasons = append(assignment.Reasons, explain("driver", driver, true))
+			} else {
+				complete = false
+				warnings = append(warnings, fmt.Sprintf("no driver available for unit %s", unit.PlateNumber))
+			}
+
+			if crew, ok := pick(false); ok {
+				assignment.CrewID = crew.UUID
+				assignment.CrewName = crew.Fullname
+				assignment.Reasons = append(assignment.Reasons, explain("crew", crew, false))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("no crew available for unit %s", unit.PlateNumber))
+			}
+
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	return &model.ScheduleAutoAssignResponse{
+		OrderID:       orderID,
+		StartDate:     startText,
+		EndDate:       endText,
+		DepartureTime: strings.TrimSpace(input.Request.DepartureTime),
+		Complete:      complete,
+		ScheduleUnits: assignments,
+		Warnings:      warnings,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+
+	"service-travego/model"
+)
+
+func TestEmployeesOnLeave(t *testing.T) {
+	leaves := []model.EmployeeLeavePeriod{
+		{EmployeeID: "unsubstituted", Status: model.LeaveStatusApproved, StartDate: day("2026-12-24"), EndDate: day("2026-12-26"), LeaveTypeLabel: "Cuti"},
+		{EmployeeID: "substituted", SubstitutedBy: "colleague", Status: model.LeaveStatusApproved, StartDate: day("2026-12-25"), EndDate: day("2026-12-25"), LeaveTypeLabel: "Sakit"},
+		{EmployeeID: "rejected", Status: model.LeaveStatusRejected, StartDate: day("2026-12-24"), EndDate: day("2026-12-26")},
+		{EmployeeID: "pending", Status: model.LeaveStatusPending, StartDate: day("2026-12-24"), EndDate: day("2026-12-26")},
+	}
+	onLeave := employeesOnLeave(leaves)
+
+	if got := onLeave["unsubstituted"]; got != "Cuti 2026-12-24 - 2026-12-26" {
+		t.Errorf("a leave without a substitute must keep the employee away, got %q", got)
+	}
+	if _, ok := onLeave["substituted"]; !ok {
+		t.Error("a substituted leave must keep the employee away")
+	}
+	for _, id := range []string{"rejected", "pending"} {
+		if reason, ok := onLeave[id]; ok {
+			t.Errorf("%s leave keeps the employee away: %q", id, reason)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"service-travego/configs"
@@ -15,21 +17,98 @@ import (
 )
 
 type ScheduleService struct {
-	repo      *repository.ScheduleRepository
-	citiesMap map[string]string
+	repo         *repository.ScheduleRepository
+	documentRepo *repository.DocumentRepository
+	leaveRepo    *repository.LeaveManagementRepository
+	audit        *AuditService
+	citiesMap    map[string]string
 }
 
 func NewScheduleService(repo *repository.ScheduleRepository) *ScheduleService {
 	return &ScheduleService{repo: repo}
 }
 
-func (s *ScheduleService) CreateSchedule(input model.ScheduleCreateServiceInput) (string, error) {
-	paymentStatus, exists, err := s.repo.OrderPaymentStatus(model.ScheduleOrderValidationInput{
-		OrganizationID: input.OrganizationID,
-		OrderID:        input.Request.OrderID,
+// SetDocumentRepository enables the document expiry check on schedule create/update.
+func (s *ScheduleService) SetDocumentRepository(documentRepo *repository.DocumentRepository) {
+	s.documentRepo = documentRepo
+}
+
+// SetAuditService records schedule changes in the audit log
+func (s *ScheduleService) SetAuditService(audit *AuditService) {
+	s.audit = audit
+}
+
+// scheduleAuditSnapshot loads the schedule of an order for the audit log; nil
+// when auditing is off
+func (s *ScheduleService) scheduleAuditSnapshot(ctx context.Context, orderID string) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	detail, err := s.GetScheduleDetail(ctx, model.ScheduleDetailServiceInput{OrderID: orderID})
+	if err != nil {
+		return nil
+	}
+	return detail
+}
+
+// validateScheduleDocuments refuses units, drivers and crews whose documents
+// (STNK, KIR, insurance, SIM, ...) lapse before the trip ends.
+func (s *ScheduleService) validateScheduleDocuments(ctx context.Context, orderID string, units []model.ScheduleUnitRequest) error {
+	if s.documentRepo == nil {
+		return nil
+	}
+
+	_, tripEnd, exists, err := s.repo.OrderTripDates(ctx, orderID)
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate documents", err))
+	}
+	if !exists {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_ID_NOT_FOUND")
+	}
+
+	ownerIDs := make([]string, 0, len(units)*3)
+	seen := map[string]struct{}{}
+	for _, unit := range units {
+		for _, id := range []string{unit.UnitID, unit.DriverID, unit.CrewID} {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ownerIDs = append(ownerIDs, id)
+		}
+	}
+
+	// A document is valid for the trip when it is still valid on the last trip day.
+	tripDay := time.Date(tripEnd.Year(), tripEnd.Month(), tripEnd.Day(), 0, 0, 0, 0, tripEnd.Location())
+	expired, err := s.documentRepo.ListExpiredForOwners(ctx, ownerIDs, tripDay)
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate documents", err))
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	parts := make([]string, 0, len(expired))
+	for _, doc := range expired {
+		label := model.DocumentTypeLabel[doc.DocumentType]
+		if label == "" {
+			label = doc.DocumentType
+		}
+		parts = append(parts, fmt.Sprintf("%s %s (%s)", label, doc.OwnerName, doc.ExpiryDate.Format("2006-01-02")))
+	}
+	return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "DOCUMENT_EXPIRED: "+strings.Join(parts, ", "))
+}
+
+func (s *ScheduleService) CreateSchedule(ctx context.Context, input model.ScheduleCreateServiceInput) (string, error) {
+	paymentStatus, exists, err := s.repo.OrderPaymentStatus(ctx, model.ScheduleOrderValidationInput{
+		OrderID: input.Request.OrderID,
 	})
 	if err != nil {
-		fmt.Println("OrderPaymentStatus error:", err)
+		slog.ErrorContext(ctx, "order payment status failed", "error", err)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create schedule", err))
 	}
 	if !exists {
@@ -42,16 +121,19 @@ func (s *ScheduleService) CreateSchedule(input model.ScheduleCreateServiceInput)
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_CANCELLED")
 	}
 
+	if err := s.validateScheduleDocuments(ctx, input.Request.OrderID, input.Request.ScheduleUnits); err != nil {
+		return "", err
+	}
+
 	fleets := make([]model.ScheduleFleetInsertItem, 0, len(input.Request.ScheduleUnits))
 	teams := make([]model.ScheduleFleetTeamUpsertItem, 0, len(input.Request.ScheduleUnits))
 	for _, unit := range input.Request.ScheduleUnits {
-		itemExists, checkErr := s.repo.OrderItemExists(model.ScheduleOrderItemValidationInput{
-			OrganizationID: input.OrganizationID,
-			OrderID:        input.Request.OrderID,
-			FleetID:        unit.FleetID,
+		itemExists, checkErr := s.repo.OrderItemExists(ctx, model.ScheduleOrderItemValidationInput{
+			OrderID: input.Request.OrderID,
+			FleetID: unit.FleetID,
 		})
 		if checkErr != nil {
-			fmt.Println("OrderItemExists error:", checkErr)
+			slog.ErrorContext(ctx, "order item exists failed", "error", checkErr)
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create schedule", checkErr))
 		}
 		if !itemExists {
@@ -90,8 +172,8 @@ func (s *ScheduleService) CreateSchedule(input model.ScheduleCreateServiceInput)
 		}
 	}
 
-	scheduleID, createErr := s.repo.CreateSchedule(model.ScheduleCreateRepositoryInput{
-		OrganizationID: input.OrganizationID,
+	scheduleID, createErr := s.repo.CreateSchedule(ctx, model.ScheduleCreateRepositoryInput{
+		OrganizationID: organizationFromContext(ctx),
 		UserID:         input.UserID,
 		OrderID:        input.Request.OrderID,
 		DepartureTime:  departureTime,
@@ -100,37 +182,45 @@ func (s *ScheduleService) CreateSchedule(input model.ScheduleCreateServiceInput)
 		Teams:          teams,
 	})
 	if createErr != nil {
-		fmt.Println("CreateSchedule error:", createErr)
+		slog.ErrorContext(ctx, "create schedule failed", "error", createErr)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create schedule", createErr))
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    input.UserID,
+		EntityType: model.AuditEntitySchedule, EntityID: scheduleID, Action: model.AuditActionCreate,
+		After: s.scheduleAuditSnapshot(ctx, input.Request.OrderID),
+	})
 	return scheduleID, nil
 }
 
-func (s *ScheduleService) UpdateSchedule(input model.ScheduleUpdateServiceInput) (string, error) {
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, input model.ScheduleUpdateServiceInput) (string, error) {
 	if strings.TrimSpace(input.Request.OrderID) == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
 
-	scheduleID, exists, err := s.repo.LatestScheduleIDByOrderID(input.OrganizationID, input.Request.OrderID)
+	scheduleID, exists, err := s.repo.LatestScheduleIDByOrderID(ctx, input.Request.OrderID)
 	if err != nil {
-		fmt.Println("LatestScheduleIDByOrderID error:", err)
+		slog.ErrorContext(ctx, "latest schedule id by order id failed", "error", err)
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update schedule", err))
 	}
 	if !exists {
 		return "", NewServiceError(ErrNotFound, http.StatusNotFound, "SCHEDULE_NOT_FOUND")
 	}
 
+	if err := s.validateScheduleDocuments(ctx, input.Request.OrderID, input.Request.ScheduleUnits); err != nil {
+		return "", err
+	}
+
 	fleets := make([]model.ScheduleFleetInsertItem, 0, len(input.Request.ScheduleUnits))
 	teams := make([]model.ScheduleFleetTeamUpsertItem, 0, len(input.Request.ScheduleUnits))
 	for _, unit := range input.Request.ScheduleUnits {
-		itemExists, checkErr := s.repo.OrderItemExists(model.ScheduleOrderItemValidationInput{
-			OrganizationID: input.OrganizationID,
-			OrderID:        input.Request.OrderID,
-			FleetID:        unit.FleetID,
+		itemExists, checkErr := s.repo.OrderItemExists(ctx, model.ScheduleOrderItemValidationInput{
+			OrderID: input.Request.OrderID,
+			FleetID: unit.FleetID,
 		})
 		if checkErr != nil {
-			fmt.Println("OrderItemExists error:", checkErr)
+			slog.ErrorContext(ctx, "order item exists failed", "error", checkErr)
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update schedule", checkErr))
 		}
 		if !itemExists {
@@ -164,8 +254,9 @@ func (s *ScheduleService) UpdateSchedule(input model.ScheduleUpdateServiceInput)
 		}
 	}
 
-	updateErr := s.repo.UpdateSchedule(model.ScheduleUpdateRepositoryInput{
-		OrganizationID: input.OrganizationID,
+	before := s.scheduleAuditSnapshot(ctx, input.Request.OrderID)
+	updateErr := s.repo.UpdateSchedule(ctx, model.ScheduleUpdateRepositoryInput{
+		OrganizationID: organizationFromContext(ctx),
 		UserID:         input.UserID,
 		ScheduleID:     scheduleID,
 		OrderID:        input.Request.OrderID,
@@ -175,17 +266,22 @@ func (s *ScheduleService) UpdateSchedule(input model.ScheduleUpdateServiceInput)
 		Teams:          teams,
 	})
 	if updateErr != nil {
-		fmt.Println("UpdateSchedule error:", updateErr)
+		slog.ErrorContext(ctx, "update schedule failed", "error", updateErr)
 		if errors.Is(updateErr, sql.ErrNoRows) {
 			return "", NewServiceError(ErrNotFound, http.StatusNotFound, "SCHEDULE_NOT_FOUND")
 		}
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update schedule", updateErr))
 	}
 
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    input.UserID,
+		EntityType: model.AuditEntitySchedule, EntityID: scheduleID, Action: model.AuditActionUpdate,
+		Before: before, After: s.scheduleAuditSnapshot(ctx, input.Request.OrderID),
+	})
 	return scheduleID, nil
 }
 
-func (s *ScheduleService) GetScheduleFleetList(input model.ScheduleFleetListServiceInput) (*model.ScheduleFleetListResponse, error) {
+func (s *ScheduleService) GetScheduleFleetList(ctx context.Context, input model.ScheduleFleetListServiceInput) (*model.ScheduleFleetListResponse, error) {
 	periodDate := time.Now()
 	if strings.TrimSpace(input.Query.Period) != "" {
 		parsedPeriod, err := time.Parse("2006-01", input.Query.Period)
@@ -197,7 +293,7 @@ func (s *ScheduleService) GetScheduleFleetList(input model.ScheduleFleetListServ
 	monthStart := time.Date(periodDate.Year(), periodDate.Month(), 1, 0, 0, 0, 0, periodDate.Location())
 	monthEnd := monthStart.AddDate(0, 1, -1)
 
-	rows, err := s.repo.ListScheduleFleetOrders(input.Query, input.OrganizationID, monthStart, monthEnd)
+	rows, err := s.repo.ListScheduleFleetOrders(ctx, input.Query, monthStart, monthEnd)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get schedule fleets", err))
 	}
@@ -209,7 +305,7 @@ func (s *ScheduleService) GetScheduleFleetList(input model.ScheduleFleetListServ
 	}
 
 	for _, row := range rows {
-		fleets, fleetErr := s.repo.ListScheduleFleets(row.ScheduleID, input.OrganizationID)
+		fleets, fleetErr := s.repo.ListScheduleFleets(ctx, row.ScheduleID)
 		if fleetErr != nil {
 			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get schedule fleets", fleetErr))
 		}
@@ -268,7 +364,7 @@ func scheduleDestinationsLabel(destinationIDs string, citiesMap map[string]strin
 	return strings.Join(out, ", ")
 }
 
-func (s *ScheduleService) GetFleetAvailability(input model.ScheduleFleetAvailabilityServiceInput) ([]model.ScheduleFleetAvailabilityItem, error) {
+func (s *ScheduleService) GetFleetAvailability(ctx context.Context, input model.ScheduleFleetAvailabilityServiceInput) ([]model.ScheduleFleetAvailabilityItem, error) {
 	startDate, startErr := time.Parse("2006-01-02", input.Filter.StartDate)
 	if startErr != nil {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date must be YYYY-MM-DD")
@@ -281,7 +377,7 @@ func (s *ScheduleService) GetFleetAvailability(input model.ScheduleFleetAvailabi
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
 	}
 
-	rows, err := s.repo.GetFleetAvailability(input.Filter, input.OrganizationID)
+	rows, err := s.repo.GetFleetAvailability(ctx, input.Filter)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get fleet availability", err))
 	}
@@ -307,12 +403,12 @@ func (s *ScheduleService) GetFleetAvailability(input model.ScheduleFleetAvailabi
 	return items, nil
 }
 
-func (s *ScheduleService) GetScheduleDetail(input model.ScheduleDetailServiceInput) (*model.ScheduleDetailResponse, error) {
+func (s *ScheduleService) GetScheduleDetail(ctx context.Context, input model.ScheduleDetailServiceInput) (*model.ScheduleDetailResponse, error) {
 	if strings.TrimSpace(input.OrderID) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
 
-	scheduleID, exists, err := s.repo.LatestScheduleIDByOrderID(input.OrganizationID, input.OrderID)
+	scheduleID, exists, err := s.repo.LatestScheduleIDByOrderID(ctx, input.OrderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get schedule detail", err))
 	}
@@ -320,7 +416,7 @@ func (s *ScheduleService) GetScheduleDetail(input model.ScheduleDetailServiceInp
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "SCHEDULE_NOT_FOUND")
 	}
 
-	rows, err := s.repo.GetScheduleDetailRows(scheduleID, input.OrganizationID, input.OrderID)
+	rows, err := s.repo.GetScheduleDetailRows(ctx, scheduleID, input.OrderID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get schedule detail", err))
 	}
@@ -388,23 +484,23 @@ func (s *ScheduleService) GetScheduleDetail(input model.ScheduleDetailServiceInp
 	return response, nil
 }
 
-func (s *ScheduleService) GetFleetTripDetail(input model.ScheduleFleetTripDetailServiceInput) (*model.ScheduleFleetTripDetailResponse, error) {
+func (s *ScheduleService) GetFleetTripDetail(ctx context.Context, input model.ScheduleFleetTripDetailServiceInput) (*model.ScheduleFleetTripDetailResponse, error) {
 	if strings.TrimSpace(input.ScheduleNumber) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "schedule_number is required")
 	}
 
-	res, exists, err := s.repo.GetFleetTripDetail(input)
+	res, exists, err := s.repo.GetFleetTripDetail(ctx, input)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get fleet trip detail", err))
 	}
 	if !exists || res == nil {
-		fmt.Println(res)
+		slog.DebugContext(ctx, "get fleet trip detail", "res", res)
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "DATA_NOT_FOUND")
 	}
 	return res, nil
 }
 
-func (s *ScheduleService) GetDailyAvailabilityFleet(input model.DailyAvailabilityFleetServiceInput) ([]model.DailyAvailabilityFleetScheduleItem, error) {
+func (s *ScheduleService) GetDailyAvailabilityFleet(ctx context.Context, input model.DailyAvailabilityFleetServiceInput) ([]model.DailyAvailabilityFleetScheduleItem, error) {
 	startDate, startErr := time.Parse("2006-01-02", strings.TrimSpace(input.StartDate))
 	if startErr != nil {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date must be YYYY-MM-DD")
@@ -417,7 +513,7 @@ func (s *ScheduleService) GetDailyAvailabilityFleet(input model.DailyAvailabilit
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
 	}
 
-	fleetName, units, exists, err := s.repo.GetFleetWithUnitsForDailyAvailability(input.OrganizationID, strings.TrimSpace(input.FleetID))
+	fleetName, units, exists, err := s.repo.GetFleetWithUnitsForDailyAvailability(ctx, strings.TrimSpace(input.FleetID))
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get fleet", err))
 	}
@@ -425,7 +521,7 @@ func (s *ScheduleService) GetDailyAvailabilityFleet(input model.DailyAvailabilit
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "FLEET_NOT_FOUND")
 	}
 
-	scheduledRows, err := s.repo.ListScheduledFleetUnitDaysForDailyAvailability(input.OrganizationID, startDate, endDate, strings.TrimSpace(input.FleetID))
+	scheduledRows, err := s.repo.ListScheduledFleetUnitDaysForDailyAvailability(ctx, startDate, endDate, strings.TrimSpace(input.FleetID))
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get daily fleet availability", err))
 	}
@@ -472,7 +568,7 @@ func (s *ScheduleService) GetDailyAvailabilityFleet(input model.DailyAvailabilit
 	return schedules, nil
 }
 
-func (s *ScheduleService) GetDailyAvailabilityFleetUnit(input model.DailyAvailabilityFleetUnitServiceInput) ([]model.DailyAvailabilityFleetUnitScheduleItem, error) {
+func (s *ScheduleService) GetDailyAvailabilityFleetUnit(ctx context.Context, input model.DailyAvailabilityFleetUnitServiceInput) ([]model.DailyAvailabilityFleetUnitScheduleItem, error) {
 	startDate, startErr := time.Parse("2006-01-02", strings.TrimSpace(input.StartDate))
 	if startErr != nil {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date must be YYYY-MM-DD")
@@ -485,7 +581,7 @@ func (s *ScheduleService) GetDailyAvailabilityFleetUnit(input model.DailyAvailab
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
 	}
 
-	unit, exists, err := s.repo.GetFleetUnitForDailyAvailability(input.OrganizationID, strings.TrimSpace(input.UnitID))
+	unit, exists, err := s.repo.GetFleetUnitForDailyAvailability(ctx, strings.TrimSpace(input.UnitID))
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get fleet unit", err))
 	}
@@ -493,7 +589,7 @@ func (s *ScheduleService) GetDailyAvailabilityFleetUnit(input model.DailyAvailab
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "UNIT_NOT_FOUND")
 	}
 
-	scheduledRows, err := s.repo.ListScheduledUnitDaysForDailyAvailability(input.OrganizationID, startDate, endDate, strings.TrimSpace(input.UnitID))
+	scheduledRows, err := s.repo.ListScheduledUnitDaysForDailyAvailability(ctx, startDate, endDate, strings.TrimSpace(input.UnitID))
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get daily fleet unit availability", err))
 	}
@@ -562,7 +658,7 @@ func (s *ScheduleService) GetDailyAvailabilityFleetUnit(input model.DailyAvailab
 	return items, nil
 }
 
-func (s *ScheduleService) GetScheduleDetailByDate(input model.ScheduleDetailByDateServiceInput) ([]model.ScheduleDetailByDateItem, error) {
+func (s *ScheduleService) GetScheduleDetailByDate(ctx context.Context, input model.ScheduleDetailByDateServiceInput) ([]model.ScheduleDetailByDateItem, error) {
 	if strings.TrimSpace(input.Date) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "date is required")
 	}
@@ -572,7 +668,7 @@ func (s *ScheduleService) GetScheduleDetailByDate(input model.ScheduleDetailByDa
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "date must be YYYY-MM-DD")
 	}
 
-	rows, err := s.repo.ListScheduleDetailsByDate(selectedDate, input.OrganizationID)
+	rows, err := s.repo.ListScheduleDetailsByDate(ctx, selectedDate)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get schedule detail", err))
 	}
@@ -606,7 +702,7 @@ func (s *ScheduleService) GetScheduleDetailByDate(input model.ScheduleDetailByDa
 	return items, nil
 }
 
-func (s *ScheduleService) GetScheduleOperationAvailability(organizationID, startDateText, endDateText, employeeID string) ([]model.ScheduleOperationAvailabilityItem, error) {
+func (s *ScheduleService) GetScheduleOperationAvailability(ctx context.Context, startDateText, endDateText, employeeID string) ([]model.ScheduleOperationAvailabilityItem, error) {
 	if strings.TrimSpace(startDateText) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date is required")
 	}
@@ -626,7 +722,7 @@ func (s *ScheduleService) GetScheduleOperationAvailability(organizationID, start
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
 	}
 
-	rows, err := s.repo.ListScheduleOperationAvailabilityEmployees(organizationID, startDate, endDate, employeeID)
+	rows, err := s.repo.ListScheduleOperationAvailabilityEmployees(ctx, startDate, endDate, employeeID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get operations availability", err))
 	}
@@ -645,7 +741,7 @@ func (s *ScheduleService) GetScheduleOperationAvailability(organizationID, start
 	return items, nil
 }
 
-func (s *ScheduleService) GetScheduleFleetUnitAvailability(input model.ScheduleFleetUnitAvailabilityServiceInput) ([]model.ScheduleFleetUnitAvailabilityItem, error) {
+func (s *ScheduleService) GetScheduleFleetUnitAvailability(ctx context.Context, input model.ScheduleFleetUnitAvailabilityServiceInput) ([]model.ScheduleFleetUnitAvailabilityItem, error) {
 	if strings.TrimSpace(input.StartDate) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date is required")
 	}
@@ -665,7 +761,7 @@ func (s *ScheduleService) GetScheduleFleetUnitAvailability(input model.ScheduleF
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
 	}
 
-	rows, err := s.repo.ListAvailableScheduleFleetUnits(input.OrganizationID, startDate, endDate, input.FleetID)
+	rows, err := s.repo.ListAvailableScheduleFleetUnits(ctx, startDate, endDate, input.FleetID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get fleet units availability", err))
 	}
@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"service-travego/database"
+	"service-travego/internal/sheetsapi"
+	"service-travego/model"
+	"service-travego/repository"
+)
+
+// sheetSyncBatchSize is how many rows are read and pushed at a time; the
+// cursor moves after every batch
+const sheetSyncBatchSize = 200
+
+// sheetSyncLag keeps the incremental sync behind the clock: a row is stamped
+// with created_at when its transaction starts, so it may only be visible
+// minutes later, by when the cursor must not have moved past it
+const sheetSyncLag = 5 * time.Minute
+
+// sheetRow is a row pushed to a sheet; its first value is the key the row is
+// replaced by when it is pushed again
+type sheetRow struct {
+	id        string
+	createdAt time.Time
+	values    []interface{}
+}
+
+// sheetEntity is how one entity is read and laid out in its sheet
+type sheetEntity struct {
+	sheet  string
+	header []string
+	fetch  func(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]sheetRow, error)
+}
+
+// SheetSyncService pushes the orders, payments and expenses of organizations
+// to the Google Sheet they configured, incrementally from a cursor per entity
+// or again for a date range.
+type SheetSyncService struct {
+	repo     *repository.SheetSyncRepository
+	client   *sheetsapi.Client
+	entities map[string]sheetEntity
+
+	labelsOnce        sync.Once
+	paymentTypeLabels map[int]string
+	categoryLabels    map[string]string
+	itemLabels        map[string]string
+}
+
+// sheetSyncRunning holds the organizations being synced, shared by the cron
+// and the manual sync so the two never push the same sheet at once
+var (
+	sheetSyncRunningMu sync.Mutex
+	sheetSyncRunning   = map[string]bool{}
+)
+
+// NewSheetSyncService creates the service; a nil client (no service account
+// configured) turns the sync off
+func NewSheetSyncService(repo *repository.SheetSyncRepository, client *sheetsapi.Client) *SheetSyncService {
+	s := &SheetSyncService{repo: repo, client: client}
+	s.entities = map[string]sheetEntity{
+		model.SheetEntityOrders: {
+			sheet: "Pesanan",
+			header: []string{"No. Pesanan", "Tanggal Pesanan", "Nama Pelanggan", "No. Telepon", "Armada",
+				"Tanggal Mulai", "Tanggal Selesai", "Jumlah Unit", "Total (Rp)", "Status Pembayaran"},
+			fetch: s.fetchOrders,
+		},
+		model.SheetEntityPayments: {
+			sheet: "Pembayaran",
+			header: []string{"ID Pembayaran", "No. Pesanan", "Tanggal", "Jenis Pembayaran", "Jumlah (Rp)",
+				"Sisa Tagihan (Rp)", "Tanggal Lunas"},
+			fetch: s.fetchPayments,
+		},
+		model.SheetEntityExpenses: {
+			sheet: "Pengeluaran",
+			header: []string{"ID Transaksi", "No. Invoice", "Tanggal Transaksi", "Keterangan", "Kategori",
+				"Item", "Jumlah (Rp)", "Dicatat Pada"},
+			fetch: s.fetchExpenses,
+		},
+	}
+	return s
+}
+
+// Enabled reports whether a service account is configured
+func (s *SheetSyncService) Enabled() bool {
+	return s != nil && s.client != nil
+}
+
+func (s *SheetSyncService) requireClient() error {
+	if !s.Enabled() {
+		return NewServiceError(ErrInvalidInput, http.StatusServiceUnavailable, "Google Sheets integration is not configured")
+	}
+	return nil
+}
+
+// GetStatus returns the sync setup of the organization and how far each
+// entity was pushed
+func (s *SheetSyncService) GetStatus(ctx context.Context) (*model.SheetSyncStatus, error) {
+	orgID := organizationFromContext(ctx)
+	status := &model.SheetSyncStatus{Configured: s.Enabled(), Cursors: []model.SheetSyncCursor{}}
+	if s.Enabled() {
+		status.ServiceAccountEmail = s.client.ClientEmail()
+	}
+	cfg, err := s.repo.GetConfig(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "sheet sync: failed to get config", "organization_id", orgID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get sheet sync")
+	}
+	status.Config = cfg
+	if cfg != nil {
+		if status.Cursors, err = s.repo.ListCursors(ctx); err != nil {
+			slog.ErrorContext(ctx, "sheet sync: failed to list cursors", "organization_id", orgID, "error", err)
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get sheet sync")
+		}
+	}
+	return status, nil
+}
+
+// Configure sets the spreadsheet of the organization after checking the
+// service account can open it
+func (s *SheetSyncService) Configure(ctx context.Context, userID string, req *model.SheetSyncConfigRequest) (*model.SheetSyncStatus, error) {
+	if err := s.requireClient(); err != nil {
+		return nil, err
+	}
+	spreadsheetID := strings.TrimSpace(req.SpreadsheetID)
+	if spreadsheetID == "" {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "spreadsheet_id is required")
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+
+	if enabled {
+		if _, err := s.client.SheetTitles(spreadsheetID); err != nil {
+			return nil, s.sheetsError(ctx, err)
+		}
+	}
+	if err := s.repo.SaveConfig(ctx, spreadsheetID, enabled, userID, time.Now()); err != nil {
+		slog.ErrorContext(ctx, "sheet sync: failed to save config", "organization_id", organizationFromContext(ctx), "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to save sheet sync")
+	}
+	return s.GetStatus(ctx)
+}
+
+// sheetsError turns a Sheets API error into the error shown to the user
+func (s *SheetSyncService) sheetsError(ctx context.Context, err error) error {
+	slog.ErrorContext(ctx, "sheet sync: Google Sheets request failed", "error", err)
+	var apiErr *sheetsapi.APIError
+	if !errors.As(err, &apiErr) {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to sync Google Sheets")
+	}
+	if apiErr.StatusCode == http.StatusForbidden || apiErr.StatusCode == http.StatusNotFound {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest,
+			fmt.Sprintf("spreadsheet not found; share it with %s as an editor", s.client.ClientEmail()))
+	}
+	return NewServiceError(ErrInternalServer, http.StatusBadGateway, "failed to reach Google Sheets")
+}
+
+// RunSync pushes the new rows of every organization with the sync enabled.
+// An organization that fails is recorded and retried on the next run from
+// where it stopped.
+func (s *SheetSyncService) RunSync(ctx context.Context) error {
+	if err := s.requireClient(); err != nil {
+		return err
+	}
+	configs, err := s.repo.ListEnabledConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("list sheet syncs: %w", err)
+	}
+
+	failed := 0
+	for _, cfg := range configs {
+		ctx := database.WithOrganization(ctx, cfg.OrganizationID)
+		if _, err := s.SyncOrganization(ctx); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d organizations failed", failed, len(configs))
+	}
+	return nil
+}
+
+// SyncOrganization pushes the rows of every entity created since its cursor
+// and more than sheetSyncLag ago
+func (s *SheetSyncService) SyncOrganization(ctx context.Context) ([]model.SheetSyncResult, error) {
+	orgID := organizationFromContext(ctx)
+	cfg, err := s.lockOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlockOrganization(orgID)
+
+	now := time.Now()
+	until := now.Add(-sheetSyncLag)
+	results := make([]model.SheetSyncResult, 0, len(model.SheetEntities))
+	for _, name := range model.SheetEntities {
+		cursor, err := s.repo.GetCursor(ctx, name)
+		if err != nil {
+			return results, s.recordFailure(ctx, fmt.Errorf("get %s cursor: %w", name, err))
+		}
+		n, err := s.pushEntity(ctx, cfg.SpreadsheetID, s.entities[name], cursor, until, func(c model.SheetSyncCursor) error {
+			c.Entity = name
+			return s.repo.SaveCursor(ctx, c, time.Now())
+		})
+		results = append(results, model.SheetSyncResult{Entity: name, Rows: n})
+		if err != nil {
+			return results, s.recordFailure(ctx, fmt.Errorf("push %s: %w", name, err))
+		}
+	}
+	if err := s.repo.SetResult(ctx, "", now); err != nil {
+		slog.ErrorContext(ctx, "sheet sync: failed to record sync", "organization_id", orgID, "error", err)
+	}
+	return results, nil
+}
+
+// Resync pushes again the rows created in a date range, e.g. after they were
+// changed or removed from the sheet. Rows already in the sheet are replaced,
+// and the cursors are left alone.
+func (s *SheetSyncService) Resync(ctx context.Context, req *model.SheetResyncRequest) ([]model.SheetSyncResult, error) {
+	orgID := organizationFromContext(ctx)
+	start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.StartDate), time.Local)
+	if err != nil {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "start_date must be YYYY-MM-DD")
+	}
+	end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.EndDate), time.Local)
+	if err != nil {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must not be before start_date")
+	}
+	names := model.SheetEntities
+	if entity := strings.TrimSpace(req.Entity); entity != "" {
+		if _, ok := s.entities[entity]; !ok {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "entity must be orders, payments or expenses")
+		}
+		names = []string{entity}
+	}
+
+	cfg, err := s.lockOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlockOrganization(orgID)
+
+	// the cursor sorts before every row created on start
+	from := model.SheetSyncCursor{After: start.Add(-time.Microsecond)}
+	until := end.AddDate(0, 0, 1)
+	results := make([]model.SheetSyncResult, 0, len(names))
+	for _, name := range names {
+		n, err := s.pushEntity(ctx, cfg.SpreadsheetID, s.entities[name], from, until, nil)
+		results = append(results, model.SheetSyncResult{Entity: name, Rows: n})
+		if err != nil {
+			return results, s.sheetsError(ctx, fmt.Errorf("resync %s of organization %s: %w", name, orgID, err))
+		}
+	}
+	return results, nil
+}
+
+// lockOrganization returns the enabled sync setup of the organization and
+// keeps other syncs of it from running until unlockOrganization
+func (s *SheetSyncService) lockOrganization(ctx context.Context) (*model.SheetSyncConfig, error) {
+	orgID := organizationFromContext(ctx)
+	if err := s.requireClient(); err != nil {
+		return nil, err
+	}
+	cfg, err := s.repo.GetConfig(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "sheet sync: failed to get config", "organization_id", orgID, "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get sheet sync")
+	}
+	if cfg == nil || !cfg.Enabled {
+		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "Google Sheets sync is not enabled")
+	}
+
+	sheetSyncRunningMu.Lock()
+	defer sheetSyncRunningMu.Unlock()
+	if sheetSyncRunning[orgID] {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusConflict, "a sync of this organization is already running")
+	}
+	sheetSyncRunning[orgID] = true
+	return cfg, nil
+}
+
+func (s *SheetSyncService) unlockOrganization(orgID string) {
+	sheetSyncRunningMu.Lock()
+	delete(sheetSyncRunning, orgID)
+	sheetSyncRunningMu.Unlock()
+}
+
+func (s *SheetSyncService) recordFailure(ctx context.Context, err error) error {
+	orgID := organizationFromContext(ctx)
+	if recordErr := s.repo.SetResult(ctx, err.Error(), time.Now()); recordErr != nil {
+		slog.ErrorContext(ctx, "sheet sync: failed to record error", "organization_id", orgID, "error", recordErr)
+	}
+	return s.sheetsError(ctx, fmt.Errorf("organization %s: %w", orgID, err))
+}
+
+// pushEntity pushes the rows of e created after cursor and before until, a
+// batch at a time. save, when set, gets the cursor of the last row of every
+// batch written, so a push that fails resumes after it.
+func (s *SheetSyncService) pushEntity(ctx context.Context, spreadsheetID string, e sheetEntity, cursor model.SheetSyncCursor, until time.Time, save func(model.SheetSyncCursor) error) (int, error) {
+	pushed := 0
+	for {
+		rows, err := e.fetch(ctx, cursor, until, sheetSyncBatchSize)
+		if err != nil {
+			return pushed, err
+		}
+		if len(rows) == 0 {
+			return pushed, nil
+		}
+
+		values := make([][]interface{}, len(rows))
+		for i, row := range rows {
+			values[i] = row.values
+		}
+		if err := s.client.UpsertRows(spreadsheetID, e.sheet, e.header, values); err != nil {
+			return pushed, err
+		}
+		pushed += len(rows)
+
+		last := rows[len(rows)-1]
+		cursor = model.SheetSyncCursor{Entity: cursor.Entity, After: last.createdAt, AfterID: last.id}
+		if save != nil {
+			if err := save(cursor); err != nil {
+				return pushed, fmt.Errorf("save cursor: %w", err)
+			}
+		}
+		if len(rows) < sheetSyncBatchSize {
+			return pushed, nil
+		}
+	}
+}
+
+func (s *SheetSyncService) fetchOrders(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]sheetRow, error) {
+	items, err := s.repo.ListOrders(ctx, after, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]sheetRow, len(items))
+	for i, it := range items {
+		rows[i] = sheetRow{id: it.OrderID, createdAt: it.CreatedAt, values: []interface{}{
+			it.OrderID, sheetDate(&it.CreatedAt), it.CustomerName, sheetText(it.CustomerPhone), it.FleetName,
+			sheetDate(it.StartDate), sheetDate(it.EndDate), it.UnitQty, it.TotalAmount, orderPaymentStatusLabel(it.PaymentStatus),
+		}}
+	}
+	return rows, nil
+}
+
+func (s *SheetSyncService) fetchPayments(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]sheetRow, error) {
+	items, err := s.repo.ListPayments(ctx, after, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.loadLabels()
+	rows := make([]sheetRow, len(items))
+	for i, it := range items {
+		rows[i] = sheetRow{id: it.PaymentID, createdAt: it.CreatedAt, values: []interface{}{
+			it.PaymentID, it.OrderID, sheetDateTime(&it.CreatedAt), s.paymentTypeLabels[it.PaymentType],
+			it.PaymentAmount, it.PaymentRemaining, sheetDateTime(it.SettledAt),
+		}}
+	}
+	return rows, nil
+}
+
+func (s *SheetSyncService) fetchExpenses(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]sheetRow, error) {
+	items, err := s.repo.ListExpenses(ctx, after, until, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.loadLabels()
+	rows := make([]sheetRow, len(items))
+	for i, it := range items {
+		rows[i] = sheetRow{id: it.TransactionID, createdAt: it.CreatedAt, values: []interface{}{
+			it.TransactionID, it.InvoiceNumber, sheetDate(it.TransactionDate), it.Description,
+			labelOr(s.categoryLabels, it.TransactionCategory), labelOr(s.itemLabels, it.TransactionItem),
+			it.Amount, sheetDateTime(&it.CreatedAt),
+		}}
+	}
+	return rows, nil
+}
+
+// loadLabels reads the payment type, transaction category and item labels
+// from config/common.json
+func (s *SheetSyncService) loadLabels() {
+	s.labelsOnce.Do(func() {
+		s.paymentTypeLabels = map[int]string{}
+		s.categoryLabels = map[string]string{}
+		s.itemLabels = map[string]string{}
+		raw, err := os.ReadFile("config/common.json")
+		if err != nil {
+			return
+		}
+		var cfg struct {
+			PaymentStatus []model.CommonItem `json:"payment-status"`
+			Categories    []struct {
+				ID    string `json:"id"`
+				Label string `json:"label"`
+			} `json:"transaction-categories"`
+			Items []struct {
+				ID    string `json:"id"`
+				Label string `json:"label"`
+			} `json:"transaction-items"`
+		}
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return
+		}
+		for _, it := range cfg.PaymentStatus {
+			s.paymentTypeLabels[it.ID] = it.Label
+		}
+		for _, it := range cfg.Categories {
+			s.categoryLabels[it.ID] = it.Label
+		}
+		for _, it := range cfg.Items {
+			s.itemLabels[it.ID] = it.Label
+		}
+	})
+}
+
+func labelOr(labels map[string]string, id string) string {
+	if label := labels[strings.ToUpper(strings.TrimSpace(id))]; label != "" {
+		return label
+	}
+	return id
+}
+
+func orderPaymentStatusLabel(status int) string {
+	switch status {
+	case 1:
+		return "Lunas"
+	case 3:
+		return "Menunggu verifikasi"
+	case 4:
+		return "Belum Lunas"
+	}
+	return "Belum Dibayar"
+}
+
+// sheetDate writes a date the way Sheets reads one whatever the locale of the
+// spreadsheet
+func sheetDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func sheetDateTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// sheetText keeps values like +62812... from being read as a formula or number
+func sheetText(s string) string {
+	if s == "" {
+		return s
+	}
+	return "'" + s
+}
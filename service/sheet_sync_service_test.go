@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"service-travego/internal/sheetsapi"
+	"service-travego/internal/sheetsapi/sheetstest"
+	"service-travego/model"
+)
+
+func newSheetSyncTestService(t *testing.T) (*sheetstest.Fake, *SheetSyncService) {
+	t.Helper()
+	fake, srv := sheetstest.NewServer()
+	t.Cleanup(srv.Close)
+	fake.AddSpreadsheet("finance")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := sheetsapi.NewClient(srv.URL, &sheetsapi.ServiceAccount{
+		ClientEmail: "sync@travego.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    srv.URL + "/token",
+	}, srv.Client())
+	return fake, NewSheetSyncService(nil, client)
+}
+
+// testSheetEntity serves n rows created a minute apart, in cursor order
+func testSheetEntity(n int, onFetch func(call int)) sheetEntity {
+	base := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	calls := 0
+	return sheetEntity{
+		sheet:  "Pesanan",
+		header: []string{"No. Pesanan", "Total (Rp)"},
+		fetch: func(ctx context.Context, after model.SheetSyncCursor, until time.Time, limit int) ([]sheetRow, error) {
+			calls++
+			if onFetch != nil {
+				onFetch(calls)
+			}
+			var rows []sheetRow
+			for i := 0; i < n && len(rows) < limit; i++ {
+				row := sheetRow{id: fmt.Sprintf("ORD-%04d", i), createdAt: base.Add(time.Duration(i) * time.Minute)}
+				row.values = []interface{}{row.id, i * 1000}
+				if !row.createdAt.Before(until) {
+					break
+				}
+				if row.createdAt.Before(after.After) || (row.createdAt.Equal(after.After) && row.id <= after.AfterID) {
+					continue
+				}
+				rows = append(rows, row)
+			}
+			return rows, nil
+		},
+	}
+}
+
+func TestSheetSyncResumesAfterTheLastPushedBatch(t *testing.T) {
+	fake, s := newSheetSyncTestService(t)
+	until := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// the second batch fails to reach Sheets
+	e := testSheetEntity(450, func(call int) {
+		if call == 2 {
+			fake.FailNext(http.StatusServiceUnavailable)
+		}
+	})
+	var cursor model.SheetSyncCursor
+	save := func(c model.SheetSyncCursor) error { cursor = c; return nil }
+
+	pushed, err := s.pushEntity(context.Background(), "finance", e, cursor, until, save)
+	if err == nil {
+		t.Fatal("expected the push to fail")
+	}
+	if pushed != sheetSyncBatchSize || cursor.AfterID != "ORD-0199" {
+		t.Fatalf("pushed %d, cursor %q; want %d rows up to ORD-0199", pushed, cursor.AfterID, sheetSyncBatchSize)
+	}
+
+	pushed, err = s.pushEntity(context.Background(), "finance", e, cursor, until, save)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if pushed != 250 || cursor.AfterID != "ORD-0449" {
+		t.Fatalf("pushed %d, cursor %q; want 250 rows up to ORD-0449", pushed, cursor.AfterID)
+	}
+	if rows := fake.Rows("finance", "Pesanan"); len(rows) != 451 {
+		t.Fatalf("sheet has %d rows, want header and 450 orders", len(rows))
+	}
+}
+
+func TestSheetResyncReplacesRowsWithoutDuplicates(t *testing.T) {
+	fake, s := newSheetSyncTestService(t)
+	until := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	e := testSheetEntity(30, nil)
+
+	if _, err := s.pushEntity(context.Background(), "finance", e, model.SheetSyncCursor{}, until, nil); err != nil {
+		t.Fatal(err)
+	}
+	from := model.SheetSyncCursor{After: time.Date(2026, 1, 1, 8, 10, 0, 0, time.UTC)}
+	pushed, err := s.pushEntity(context.Background(), "finance", e, from, until, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pushed != 20 {
+		t.Fatalf("resync pushed %d rows, want 20", pushed)
+	}
+
+	rows := fake.Rows("finance", "Pesanan")
+	if len(rows) != 31 {
+		t.Fatalf("sheet has %d rows, want header and 30 orders", len(rows))
+	}
+	for i, row := range rows[1:] {
+		if want := fmt.Sprintf("ORD-%04d", i); row[0] != want {
+			t.Fatalf("row %d = %s, want %s", i+1, row[0], want)
+		}
+	}
+}
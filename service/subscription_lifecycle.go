@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"service-travego/database"
+	"strconv"
+	"time"
+
+	"service-travego/helper"
+	"service-travego/model"
+)
+
+// subscriptionGraceDays is how long an expired subscription keeps working
+// before the organization becomes read only, SUBSCRIPTION_GRACE_DAYS (default 7)
+var subscriptionGraceDays = func() int {
+	if n, err := strconv.Atoi(os.Getenv("SUBSCRIPTION_GRACE_DAYS")); err == nil && n >= 0 {
+		return n
+	}
+	return 7
+}()
+
+// daysUntil counts the calendar days from from to to, whatever their time of
+// day or location
+func daysUntil(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// subscriptionQuote is what buying a package costs and the period it pays for
+type subscriptionQuote struct {
+	Change     string
+	Amount     int
+	Credit     int
+	StartDate  time.Time
+	ExpiryDate time.Time
+}
+
+// prorationCredit is the value of the days left of a period worth price
+func prorationCredit(price, durationDays, daysLeft int) int {
+	if price <= 0 || durationDays <= 0 || daysLeft <= 0 {
+		return 0
+	}
+	if daysLeft > durationDays {
+		daysLeft = durationDays
+	}
+	return price * daysLeft / durationDays
+}
+
+// quoteSubscription prices buying pkg on now. current is the organization's
+// running subscription and currentPkg its package, both nil when there is none.
+//   - the same package extends the current period
+//   - a dearer package starts now, less the unused days of the current one
+//   - a cheaper package starts when the current period ends
+func quoteSubscription(pkg *model.Package, current *model.SubscriptionLifecycle, currentPkg *model.Package, now time.Time) subscriptionQuote {
+	q := subscriptionQuote{Change: model.SubscriptionChangeNew, Amount: pkg.PackagePrice, StartDate: now}
+	base := now
+	if current != nil && currentPkg != nil {
+		switch {
+		case currentPkg.PackageID == pkg.PackageID:
+			q.Change = model.SubscriptionChangeRenewal
+			base = current.ExpiryDate
+		case pkg.PackagePrice > currentPkg.PackagePrice:
+			q.Change = model.SubscriptionChangeUpgrade
+			// a free period, e.g. the trial, is not credited
+			if current.PackagePrice > 0 {
+				q.Credit = prorationCredit(currentPkg.PackagePrice, currentPkg.PackageDuration, daysUntil(now, current.ExpiryDate))
+			}
+			q.Amount = pkg.PackagePrice - q.Credit
+			if q.Amount < 0 {
+				q.Amount = 0
+			}
+		default:
+			q.Change = model.SubscriptionChangeDowngrade
+			base = current.ExpiryDate
+			q.StartDate = time.Date(base.Year(), base.Month(), base.Day()+1, 0, 0, 0, 0, now.Location())
+		}
+	}
+	q.ExpiryDate = endOfDay(time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, pkg.PackageDuration))
+	return q
+}
+
+// runningSubscription returns the organization's subscription and its package
+// while the period has not ended, else nil
+func (s *SubscriptionService) runningSubscription(ctx context.Context, now time.Time) (*model.SubscriptionLifecycle, *model.Package, error) {
+	sub, err := s.subscriptionRepo.GetLifecycle(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sub == nil || sub.Status != model.SubscriptionStatusActive || daysUntil(now, sub.ExpiryDate) < 0 {
+		return nil, nil, nil
+	}
+	return sub, s.findPackage(sub.PackageID), nil
+}
+
+func (s *SubscriptionService) findPackage(packageID string) *model.Package {
+	for i := range s.packages {
+		if s.packages[i].PackageID == packageID {
+			return &s.packages[i]
+		}
+	}
+	return nil
+}
+
+func (s *SubscriptionService) packageName(packageID string) string {
+	if pkg := s.findPackage(packageID); pkg != nil {
+		return pkg.PackageName
+	}
+	return packageID
+}
+
+// lifecycleAction is the next step of a subscription's lifecycle
+type lifecycleAction int
+
+const (
+	lifecycleNone lifecycleAction = iota
+	lifecycleRemind
+	lifecycleApplyScheduled
+	lifecycleStartGrace
+	lifecycleReadOnly
+)
+
+// subscriptionReminderStage returns the reminder a subscription with daysLeft
+// is due, the smallest of SubscriptionReminderDays that is still >= daysLeft;
+// 0 outside every reminder window
+func subscriptionReminderStage(daysLeft int) int {
+	stage := 0
+	for _, d := range model.SubscriptionReminderDays {
+		if daysLeft <= d && (stage == 0 || d < stage) {
+			stage = d
+		}
+	}
+	return stage
+}
+
+// nextLifecycleAction decides what happens to sub on today. For a reminder it
+// also returns the reminder stage.
+func nextLifecycleAction(sub *model.SubscriptionLifecycle, today time.Time) (lifecycleAction, int) {
+	daysLeft := daysUntil(today, sub.ExpiryDate)
+	switch sub.Status {
+	case model.SubscriptionStatusActive:
+		if daysLeft < 0 {
+			if sub.ScheduledPackageID != "" {
+				return lifecycleApplyScheduled, 0
+			}
+			return lifecycleStartGrace, 0
+		}
+		// the next period is paid for already
+		if sub.ScheduledPackageID != "" {
+			return lifecycleNone, 0
+		}
+		if stage := subscriptionReminderStage(daysLeft); stage > 0 && (sub.ReminderDays == 0 || stage < sub.ReminderDays) {
+			return lifecycleRemind, stage
+		}
+	case model.SubscriptionStatusGrace:
+		if sub.GraceUntil == nil || daysUntil(today, *sub.GraceUntil) < 0 {
+			return lifecycleReadOnly, 0
+		}
+	}
+	return lifecycleNone, 0
+}
+
+// RunLifecycle moves every running subscription a step along its lifecycle:
+// renewal reminders, a paid downgrade at the end of the period, the grace
+// period and then read-only mode. The subscription lifecycle cron calls it daily.
+func (s *SubscriptionService) RunLifecycle(now time.Time) error {
+	if err := s.loadPackages(); err != nil {
+		return fmt.Errorf("load packages: %w", err)
+	}
+	ctx := context.Background()
+	subs, err := s.subscriptionRepo.ListLifecycles(ctx)
+	if err != nil {
+		return fmt.Errorf("list subscriptions: %w", err)
+	}
+
+	failed := 0
+	for i := range subs {
+		if err := s.advanceLifecycle(database.WithOrganization(ctx, subs[i].OrganizationID), &subs[i], now); err != nil {
+			slog.ErrorContext(ctx, "subscription lifecycle: organization failed", "organization_id", subs[i].OrganizationID, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d subscriptions failed", failed, len(subs))
+	}
+	return nil
+}
+
+func (s *SubscriptionService) advanceLifecycle(ctx context.Context, sub *model.SubscriptionLifecycle, now time.Time) error {
+	action, stage := nextLifecycleAction(sub, now)
+	event := &model.SubscriptionEvent{
+		OrganizationID: sub.OrganizationID,
+		FromStatus:     sub.Status,
+		ToStatus:       sub.Status,
+		PackageID:      sub.PackageID,
+	}
+
+	switch action {
+	case lifecycleNone:
+		return nil
+	case lifecycleRemind:
+		s.notifyLifecycle(ctx, sub, nil, "Langganan Segera Berakhir", fmt.Sprintf(
+			"Langganan TraveGO Anda akan berakhir dalam %d hari. Perpanjang sekarang agar layanan tetap berjalan tanpa gangguan.", daysUntil(now, sub.ExpiryDate)))
+		if err := s.subscriptionRepo.SetReminderDays(ctx, stage); err != nil {
+			return fmt.Errorf("record reminder: %w", err)
+		}
+		event.Event = model.SubscriptionEventReminderSent
+		event.Note = fmt.Sprintf("%d days before expiry", stage)
+	case lifecycleApplyScheduled:
+		if err := s.subscriptionRepo.ApplyScheduledPackage(ctx); err != nil {
+			return fmt.Errorf("apply scheduled package: %w", err)
+		}
+		event.Event = model.SubscriptionEventDowngraded
+		event.ToStatus = model.SubscriptionStatusActive
+		event.PackageID = sub.ScheduledPackageID
+		event.Note = "from " + sub.PackageID
+	case lifecycleStartGrace:
+		graceUntil := time.Date(sub.ExpiryDate.Year(), sub.ExpiryDate.Month(), sub.ExpiryDate.Day()+subscriptionGraceDays, 0, 0, 0, 0, now.Location())
+		if err := s.subscriptionRepo.UpdateLifecycleStatus(ctx, model.SubscriptionStatusGrace, &graceUntil); err != nil {
+			return fmt.Errorf("start grace period: %w", err)
+		}
+		s.notifyLifecycle(ctx, sub, &graceUntil, "Langganan Telah Berakhir", fmt.Sprintf(
+			"Langganan TraveGO Anda telah berakhir. Layanan tetap aktif selama masa tenggang hingga %s; setelah itu akun hanya dapat dibaca sampai langganan diperpanjang.", graceUntil.Format("02-01-2006")))
+		event.Event = model.SubscriptionEventGraceStarted
+		event.ToStatus = model.SubscriptionStatusGrace
+		event.Note = "grace until " + graceUntil.Format("2006-01-02")
+	case lifecycleReadOnly:
+		if err := s.subscriptionRepo.UpdateLifecycleStatus(ctx, model.SubscriptionStatusReadOnly, nil); err != nil {
+			return fmt.Errorf("set read only: %w", err)
+		}
+		helper.ForgetSubscriptionState(sub.OrganizationID)
+		s.notifyLifecycle(ctx, sub, nil, "Akun Hanya Dapat Dibaca",
+			"Masa tenggang langganan TraveGO Anda telah berakhir dan akun sekarang hanya dapat dibaca. Perpanjang langganan untuk kembali menambah dan mengubah data.")
+		event.Event = model.SubscriptionEventReadOnly
+		event.ToStatus = model.SubscriptionStatusReadOnly
+	}
+
+	if err := s.subscriptionRepo.InsertEvent(ctx, event); err != nil {
+		return fmt.Errorf("record %s event: %w", event.Event, err)
+	}
+	return nil
+}
+
+// notifyLifecycle emails the organization about its subscription; failures
+// are only logged
+func (s *SubscriptionService) notifyLifecycle(ctx context.Context, sub *model.SubscriptionLifecycle, graceUntil *time.Time, title, message string) {
+	if s.orgRepo == nil || s.outbox == nil {
+		return
+	}
+	email, orgName, _, err := s.orgRepo.GetOrganizationEmailAndName(ctx)
+	if err != nil || email == "" {
+		slog.ErrorContext(ctx, "subscription lifecycle: no email", "organization_id", sub.OrganizationID, "error", err)
+		return
+	}
+
+	data := helper.SubscriptionNoticeEmailData{
+		Title:            title,
+		Message:          message,
+		OrganizationName: orgName,
+		PackageName:      s.packageName(sub.PackageID),
+		ExpiryDate:       sub.ExpiryDate.Format("02-01-2006"),
+		RenewURL:         os.Getenv("APP_BASE_URL") + "/dashboard/subscription",
+	}
+	if graceUntil != nil {
+		data.GraceUntil = graceUntil.Format("02-01-2006")
+	}
+	mail, err := helper.NewSubscriptionNoticeEmail(email, data)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, sub.OrganizationID, OutboxKindSubscriptionNotice, mail)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "subscription lifecycle: failed to queue email", "organization_id", sub.OrganizationID, "error", err)
+	}
+}
+
+// GetSubscriptionEvents returns the lifecycle history of the organization's subscription
+func (s *SubscriptionService) GetSubscriptionEvents(ctx context.Context) ([]model.SubscriptionEvent, error) {
+	events, err := s.subscriptionRepo.ListEvents(ctx, 100)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch subscription events")
+	}
+	return events, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"service-travego/model"
+)
+
+var (
+	basicPackage    = &model.Package{PackageID: "trave01", PackagePrice: 300000, PackageDuration: 30}
+	businessPackage = &model.Package{PackageID: "trave02", PackagePrice: 600000, PackageDuration: 30}
+)
+
+func day(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestQuoteSubscriptionNewStartsNow(t *testing.T) {
+	now := day("2026-03-10").Add(9 * time.Hour)
+	q := quoteSubscription(businessPackage, nil, nil, now)
+	if q.Change != model.SubscriptionChangeNew || q.Amount != 600000 || !q.StartDate.Equal(now) {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	if want := day("2026-04-09").Add(23*time.Hour + 59*time.Minute + 59*time.Second); !q.ExpiryDate.Equal(want) {
+		t.Fatalf("expiry = %v, want %v", q.ExpiryDate, want)
+	}
+}
+
+func TestQuoteSubscriptionRenewalExtendsThePeriod(t *testing.T) {
+	current := &model.SubscriptionLifecycle{PackageID: "trave01", PackagePrice: 300000, ExpiryDate: day("2026-03-15")}
+	q := quoteSubscription(basicPackage, current, basicPackage, day("2026-03-10"))
+	if q.Change != model.SubscriptionChangeRenewal || q.Amount != 300000 {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	if got := q.ExpiryDate.Format("2006-01-02"); got != "2026-04-14" {
+		t.Fatalf("expiry = %s, want 2026-04-14", got)
+	}
+}
+
+func TestQuoteSubscriptionUpgradeCreditsUnusedDays(t *testing.T) {
+	// 10 of 30 days left of a 300.000 package are worth 100.000
+	current := &model.SubscriptionLifecycle{PackageID: "trave01", PackagePrice: 300000, ExpiryDate: day("2026-03-20")}
+	q := quoteSubscription(businessPackage, current, basicPackage, day("2026-03-10"))
+	if q.Change != model.SubscriptionChangeUpgrade || q.Credit != 100000 || q.Amount != 500000 {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+
+	// a free trial is not credited
+	current.PackagePrice = 0
+	if q = quoteSubscription(businessPackage, current, basicPackage, day("2026-03-10")); q.Credit != 0 || q.Amount != 600000 {
+		t.Fatalf("unexpected quote for a trial %+v", q)
+	}
+}
+
+func TestQuoteSubscriptionDowngradeStartsAtPeriodEnd(t *testing.T) {
+	current := &model.SubscriptionLifecycle{PackageID: "trave02", PackagePrice: 600000, ExpiryDate: day("2026-03-20")}
+	q := quoteSubscription(basicPackage, current, businessPackage, day("2026-03-10"))
+	if q.Change != model.SubscriptionChangeDowngrade || q.Amount != 300000 {
+		t.Fatalf("unexpected quote %+v", q)
+	}
+	if got := q.StartDate.Format("2006-01-02"); got != "2026-03-21" {
+		t.Fatalf("start = %s, want 2026-03-21", got)
+	}
+	if got := q.ExpiryDate.Format("2006-01-02"); got != "2026-04-19" {
+		t.Fatalf("expiry = %s, want 2026-04-19", got)
+	}
+}
+
+func TestNextLifecycleAction(t *testing.T) {
+	today := day("2026-03-10")
+	graceUntil, graceEnded := day("2026-03-12"), day("2026-03-09")
+	cases := []struct {
+		name   string
+		sub    model.SubscriptionLifecycle
+		action lifecycleAction
+		stage  int
+	}{
+		{"far from expiry", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-04-01")}, lifecycleNone, 0},
+		{"first reminder", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-03-15")}, lifecycleRemind, 7},
+		{"reminder sent", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-03-15"), ReminderDays: 7}, lifecycleNone, 0},
+		{"next reminder", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-03-12"), ReminderDays: 7}, lifecycleRemind, 3},
+		{"downgrade paid", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-03-12"), ScheduledPackageID: "trave01"}, lifecycleNone, 0},
+		{"expires today", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: today, ReminderDays: 1}, lifecycleNone, 0},
+		{"expired", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-03-09")}, lifecycleStartGrace, 0},
+		{"downgrade starts", model.SubscriptionLifecycle{Status: model.SubscriptionStatusActive, ExpiryDate: day("2026-03-09"), ScheduledPackageID: "trave01"}, lifecycleApplyScheduled, 0},
+		{"in grace", model.SubscriptionLifecycle{Status: model.SubscriptionStatusGrace, ExpiryDate: day("2026-03-05"), GraceUntil: &graceUntil}, lifecycleNone, 0},
+		{"grace over", model.SubscriptionLifecycle{Status: model.SubscriptionStatusGrace, ExpiryDate: day("2026-03-01"), GraceUntil: &graceEnded}, lifecycleReadOnly, 0},
+	}
+	for _, c := range cases {
+		action, stage := nextLifecycleAction(&c.sub, today)
+		if action != c.action || stage != c.stage {
+			t.Errorf("%s: got %v/%d, want %v/%d", c.name, action, stage, c.action, c.stage)
+		}
+	}
+}
+
+func TestDaysUntilIgnoresTimeOfDayAndLocation(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	from := time.Date(2026, 3, 10, 1, 0, 0, 0, jakarta)
+	to := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	if got := daysUntil(from, to); got != 1 {
+		t.Fatalf("daysUntil = %d, want 1", got)
+	}
+}
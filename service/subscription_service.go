@@ -1,19 +1,20 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
-	"service-travego/config"
 	"service-travego/helper"
+	"service-travego/internal/paymentgateway"
 	"service-travego/model"
 	"service-travego/repository"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/veritrans/go-midtrans"
 )
 
 type SubscriptionService struct {
@@ -21,7 +22,8 @@ type SubscriptionService struct {
 	orgUserRepo      *repository.OrganizationUserRepository
 	orgRepo          *repository.OrganizationRepository
 	paymentRepo      *repository.PaymentRepository
-	midtransConfig   *config.MidtransConfig
+	gateways         *paymentgateway.Registry
+	outbox           *OutboxService
 	packages         []model.Package
 	once             sync.Once
 	loadErr          error
@@ -33,8 +35,14 @@ func NewSubscriptionService(subscriptionRepo *repository.SubscriptionRepository)
 	}
 }
 
-func (s *SubscriptionService) SetMidtransConfig(cfg *config.MidtransConfig) {
-	s.midtransConfig = cfg
+// SetPaymentGateways sets the payment gateways subscription invoices are issued with
+func (s *SubscriptionService) SetPaymentGateways(gateways *paymentgateway.Registry) {
+	s.gateways = gateways
+}
+
+// SetOutboxService queues the renewal reminder and expiry emails
+func (s *SubscriptionService) SetOutboxService(outbox *OutboxService) {
+	s.outbox = outbox
 }
 
 // SetOrganizationUserRepository sets the organization user repository
@@ -73,20 +81,37 @@ func (s *SubscriptionService) loadPackages() error {
 	return s.loadErr
 }
 
-func (s *SubscriptionService) GetSubscription(orgID string) (model.SubscriptionDetail, error) {
+func (s *SubscriptionService) GetSubscription(ctx context.Context) (model.SubscriptionDetail, error) {
 	if err := s.loadPackages(); err != nil {
 		return model.SubscriptionDetail{}, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load packages")
 	}
 
-	subscriptions, err := s.subscriptionRepo.GetSubscriptionDetails(orgID)
+	subscriptions, err := s.subscriptionRepo.GetSubscriptionDetails(ctx)
 	if err != nil {
-		fmt.Println(err)
+		slog.ErrorContext(ctx, "get subscription failed", "error", err)
 		return model.SubscriptionDetail{}, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch subscriptions")
 	}
-	sub := subscriptions[0]
+	lifecycle, err := s.subscriptionRepo.GetLifecycle(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "get subscription failed", "error", err)
+		return model.SubscriptionDetail{}, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch subscriptions")
+	}
+
+	var sub model.SubscriptionDetail
+	switch {
+	case len(subscriptions) > 0:
+		sub = subscriptions[0]
+	case lifecycle != nil:
+		// the period has ended; show the subscription in its grace period or read only
+		sub.PackageID = lifecycle.PackageID
+		sub.PackagePrice = lifecycle.PackagePrice
+		sub.ExpireDate = lifecycle.ExpiryDate
+	default:
+		return model.SubscriptionDetail{}, NewServiceError(ErrNotFound, http.StatusNotFound, "subscription not found")
+	}
 
 	for _, pkg := range s.packages {
-		fmt.Println("pkg packageID ", pkg.PackageID)
+		slog.DebugContext(ctx, "subscription package", "package_id", pkg.PackageID)
 		encryptedID, _ := helper.EncryptString(pkg.PackageID)
 		if encryptedID == sub.PackageID {
 			sub.PackageID = encryptedID
@@ -101,16 +126,31 @@ func (s *SubscriptionService) GetSubscription(orgID string) (model.SubscriptionD
 	} else {
 		sub.Status = "Aktif"
 	}
+	if lifecycle != nil {
+		sub.LifecycleStatus = model.SubscriptionStatusLabels[lifecycle.Status]
+		sub.GraceUntil = lifecycle.GraceUntil
+		switch lifecycle.Status {
+		case model.SubscriptionStatusGrace:
+			sub.Status = "Masa Tenggang"
+		case model.SubscriptionStatusReadOnly:
+			sub.Status = "Hanya Baca"
+		}
+		if lifecycle.ScheduledPackageID != "" {
+			start := lifecycle.ExpiryDate.AddDate(0, 0, 1)
+			sub.ScheduledPackageID = lifecycle.ScheduledPackageID
+			sub.ScheduledStartDate = &start
+		}
+	}
 
 	return sub, nil
 }
 
-func (s *SubscriptionService) GetSubscriptionHistory(userID, orgID string) ([]model.SubscriptionHistory, error) {
+func (s *SubscriptionService) GetSubscriptionHistory(ctx context.Context, userID string) ([]model.SubscriptionHistory, error) {
 	if err := s.loadPackages(); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load packages")
 	}
 
-	subscriptions, err := s.subscriptionRepo.GetSubscriptionHistory(userID, orgID)
+	subscriptions, err := s.subscriptionRepo.GetSubscriptionHistory(ctx, userID)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch subscription history")
 	}
@@ -135,7 +175,7 @@ func (s *SubscriptionService) GetSubscriptionHistory(userID, orgID string) ([]mo
 	return subscriptions, nil
 }
 
-func (s *SubscriptionService) SubmitSubscriptionPayment(packageID, userID, orgID string) (*model.PaymentResponse, error) {
+func (s *SubscriptionService) SubmitSubscriptionPayment(ctx context.Context, packageID, userID string) (*model.PaymentResponse, error) {
 	if err := s.loadPackages(); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load packages")
 	}
@@ -152,32 +192,17 @@ func (s *SubscriptionService) SubmitSubscriptionPayment(packageID, userID, orgID
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "package not found")
 	}
 
-	// Get current subscription to compare
-	currentSubs, err := s.subscriptionRepo.GetSubscriptionDetails(orgID)
+	// Price the package against the current subscription
+	now := time.Now()
+	current, currentPkg, err := s.runningSubscription(ctx, now)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get current subscription")
 	}
-
-	// Determine package amount
-	var packageAmount int
-	if len(currentSubs) > 0 && currentSubs[0].PackageID == packageID {
-		// Same package, use package price
-		packageAmount = selectedPackage.PackagePrice
-	} else if len(currentSubs) > 0 {
-		// Different package, check if new price is higher
-		if selectedPackage.PackagePrice > int(currentSubs[0].PackagePrice) {
-			packageAmount = selectedPackage.PackagePrice - int(currentSubs[0].PackagePrice)
-		} else {
-			// New package is cheaper or same, use new package price
-			packageAmount = selectedPackage.PackagePrice
-		}
-	} else {
-		// No current subscription, use package price
-		packageAmount = selectedPackage.PackagePrice
-	}
+	quote := quoteSubscription(selectedPackage, current, currentPkg, now)
+	packageAmount := quote.Amount
 
 	// Generate invoice number
-	invoiceNumber, err := s.subscriptionRepo.GenerateSubsInvoiceID()
+	invoiceNumber, err := s.subscriptionRepo.GenerateSubsInvoiceID(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to generate invoice number")
 	}
@@ -185,20 +210,23 @@ func (s *SubscriptionService) SubmitSubscriptionPayment(packageID, userID, orgID
 	// Generate transaction ID
 	transactionID := uuid.New().String()
 
-	// Prepare dates
-	now := time.Now()
+	// Prepare dates; a downgrade starts when the current period ends
 	transactionDate := now.Format("2006-01-02 15:04:05")
-	startDate := transactionDate
-	expiryTime := now.AddDate(0, 0, selectedPackage.PackageDuration)
-	// Set time to 23:59:59
-	expiryTime = time.Date(expiryTime.Year(), expiryTime.Month(), expiryTime.Day(), 23, 59, 59, 0, expiryTime.Location())
-	expiryDate := expiryTime.Format("2006-01-02 15:04:05")
+	startDate := quote.StartDate.Format("2006-01-02 15:04:05")
+	expiryDate := quote.ExpiryDate.Format("2006-01-02 15:04:05")
 	createdAt := transactionDate
 	createdBy := userID
 	status := 2
 
+	gateway, err := gatewayForOrganization(ctx, s.gateways, s.orgRepo)
+	if err != nil {
+		slog.ErrorContext(ctx, "payment gateway error", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "payment gateway is not available")
+	}
+
 	// Insert into travego_transactions
 	err = s.subscriptionRepo.InsertTravegoTransaction(
+		ctx,
 		transactionID,
 		transactionDate,
 		invoiceNumber,
@@ -207,43 +235,39 @@ func (s *SubscriptionService) SubmitSubscriptionPayment(packageID, userID, orgID
 		expiryDate,
 		status,
 		userID,
-		orgID,
+		gateway.Provider(),
 		createdAt,
 		createdBy,
 	)
 	if err != nil {
-		fmt.Println(err)
+		slog.ErrorContext(ctx, "submit subscription payment failed", "error", err)
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to insert transaction")
 	}
 
-	// Get Midtrans snap token
+	// Create the invoice on the organization's payment gateway
 	baseURL := os.Getenv("APP_BASE_URL")
 	finishURL := fmt.Sprintf("%s/dashboard/subscription/payment/success/%s", baseURL, invoiceNumber)
 
-	snapReq := &midtrans.SnapReq{
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  invoiceNumber,
-			GrossAmt: int64(packageAmount),
-		},
-		Callbacks: &midtrans.Callbacks{
-			Finish: finishURL,
-			
-		},
-	}
-
-	snapResp, err := s.midtransConfig.Snap.GetToken(snapReq)
+	charge, err := gateway.CreateCharge(paymentgateway.ChargeRequest{
+		InvoiceNumber: invoiceNumber,
+		Amount:        int64(packageAmount),
+		Description:   "Subscription " + selectedPackage.PackageName,
+		FinishURL:     finishURL,
+	})
 	if err != nil {
-		fmt.Println("Midtrans error:", err)
-		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "midtrans error")
+		slog.ErrorContext(ctx, "payment gateway error", "error", err)
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "payment gateway error")
 	}
 
 	return &model.PaymentResponse{
-		SnapToken: snapResp.Token,
-		OrderID:   invoiceNumber,
+		SnapToken:      charge.Token,
+		OrderID:        invoiceNumber,
+		PaymentGateway: charge.Provider,
+		RedirectURL:    charge.RedirectURL,
 	}, nil
 }
 
-func (s *SubscriptionService) GetSubscriptionSummary(packageID, orgID string) (*model.SubmitSubscriptionResponse, error) {
+func (s *SubscriptionService) GetSubscriptionSummary(ctx context.Context, packageID string) (*model.SubmitSubscriptionResponse, error) {
 	if err := s.loadPackages(); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load packages")
 	}
@@ -260,27 +284,17 @@ func (s *SubscriptionService) GetSubscriptionSummary(packageID, orgID string) (*
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "package not found")
 	}
 
-	// Get current subscription to compare
-	currentSubs, err := s.subscriptionRepo.GetSubscriptionDetails(orgID)
+	// Price the package against the current subscription
+	now := time.Now()
+	current, currentPkg, err := s.runningSubscription(ctx, now)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get current subscription")
 	}
-
-	// Determine package amount
-	var packageAmount int
+	quote := quoteSubscription(selectedPackage, current, currentPkg, now)
+	packageAmount := quote.Amount
 	var currentPackagePrice float64 = 0
-	if len(currentSubs) > 0 {
-		// Current subscription exists, calculate difference
-		currentPackagePrice = currentSubs[0].PackagePrice
-		if selectedPackage.PackagePrice > int(currentSubs[0].PackagePrice) {
-			packageAmount = selectedPackage.PackagePrice - int(currentSubs[0].PackagePrice)
-		} else {
-			// New package is cheaper or same, use full price
-			packageAmount = selectedPackage.PackagePrice
-		}
-	} else {
-		// No current subscription, use full package price
-		packageAmount = selectedPackage.PackagePrice
+	if current != nil {
+		currentPackagePrice = current.PackagePrice
 	}
 
 	// Encrypt package ID
@@ -304,12 +318,16 @@ func (s *SubscriptionService) GetSubscriptionSummary(packageID, orgID string) (*
 		OriginalPrice:       selectedPackage.OriginalPrice,
 		CurrentPackagePrice: currentPackagePrice,
 		DiscountPrice:       discountPrice,
+		ChangeType:          quote.Change,
+		ProrationCredit:     quote.Credit,
+		StartDate:           quote.StartDate,
+		ExpiryDate:          quote.ExpiryDate,
 	}
 
 	return response, nil
 }
 
-func (s *SubscriptionService) GetSubscriptionDetailByInvoice(invoiceNumber string) (*model.SubscriptionDetailByInvoiceResponse, error) {
+func (s *SubscriptionService) GetSubscriptionDetailByInvoice(ctx context.Context, invoiceNumber string) (*model.SubscriptionDetailByInvoiceResponse, error) {
 	if err := s.loadPackages(); err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to load packages")
 	}
@@ -318,7 +336,7 @@ func (s *SubscriptionService) GetSubscriptionDetailByInvoice(invoiceNumber strin
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "payment repository not initialized")
 	}
 
-	_, packageID, startDate, expiryDate, _, _, paymentMethod, createdAt, paymentAmount, err := (*s.paymentRepo).GetSubscriptionDetail(invoiceNumber)
+	_, packageID, startDate, expiryDate, _, _, paymentMethod, createdAt, paymentAmount, err := (*s.paymentRepo).GetSubscriptionDetail(ctx, invoiceNumber)
 	if err != nil {
 		return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "subscription detail not found")
 	}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"service-travego/model"
@@ -49,22 +50,22 @@ func (s *SystemService) ensureLocationLoaded() {
 	s.provinceMap = provinces
 }
 
-func (s *SystemService) GetSystemSummarize(period string) (*model.SystemSummarymarizeResponse, error) {
-	return s.repo.GetSummarize(period)
+func (s *SystemService) GetSystemSummarize(ctx context.Context, period string) (*model.SystemSummarymarizeResponse, error) {
+	return s.repo.GetSummarize(ctx, period)
 }
 
-func (s *SystemService) GetDeviceList(search, status string) ([]model.DeviceListItem, error) {
-	return s.repo.GetDeviceList(search, status)
+func (s *SystemService) GetDeviceList(ctx context.Context, search, status string) ([]model.DeviceListItem, error) {
+	return s.repo.GetDeviceList(ctx, search, status)
 }
 
-func (s *SystemService) UpdateDevice(account string, action string, enableData *model.DeviceEnableRequest) error {
-	return s.repo.UpdateDevice(account, action, enableData)
+func (s *SystemService) UpdateDevice(ctx context.Context, account string, action string, enableData *model.DeviceEnableRequest) error {
+	return s.repo.UpdateDevice(ctx, account, action, enableData)
 }
 
-func (s *SystemService) GetOrganizations(search string, status string) ([]model.SystemOrganizationItem, error) {
+func (s *SystemService) GetOrganizations(ctx context.Context, search string, status string) ([]model.SystemOrganizationItem, error) {
 	s.ensureLocationLoaded()
 
-	raw, err := s.repo.GetOrganizations(search, status)
+	raw, err := s.repo.GetOrganizations(ctx, search, status)
 	if err != nil {
 		return nil, err
 	}
@@ -117,16 +118,16 @@ func (s *SystemService) GetOrganizations(search string, status string) ([]model.
 	return out, nil
 }
 
-func (s *SystemService) GetMessages() ([]model.SystemMessageItem, error) {
-	return s.repo.GetMessages()
+func (s *SystemService) GetMessages(ctx context.Context) ([]model.SystemMessageItem, error) {
+	return s.repo.GetMessages(ctx)
 }
 
-func (s *SystemService) ReadMessage(messageID string) error {
-	return s.repo.ReadMessage(messageID)
+func (s *SystemService) ReadMessage(ctx context.Context, messageID string) error {
+	return s.repo.ReadMessage(ctx, messageID)
 }
 
-func (s *SystemService) GetUsers(search string, isActive string) ([]model.SystemUserItem, error) {
-	raw, err := s.repo.GetUsers(search, isActive)
+func (s *SystemService) GetUsers(ctx context.Context, search string, isActive string) ([]model.SystemUserItem, error) {
+	raw, err := s.repo.GetUsers(ctx, search, isActive)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"service-travego/configs"
 	"service-travego/model"
 	"strings"
 )
@@ -25,26 +27,27 @@ func isZeroOrganizationID(v string) bool {
 	return allZero
 }
 
-func (s *OrganizationService) ListDivisions(organizationID string) ([]model.OrganizationDivision, error) {
-	items, err := s.orgRepo.ListDivisions(organizationID)
+func (s *OrganizationService) ListDivisions(ctx context.Context) ([]model.OrganizationDivision, error) {
+	items, err := s.orgRepo.ListDivisions(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get divisions")
 	}
 	return items, nil
 }
 
-func (s *OrganizationService) CreateDivision(organizationID, userID string, req *model.CreateOrganizationDivisionRequest) (string, error) {
+func (s *OrganizationService) CreateDivision(ctx context.Context, userID string, req *model.CreateOrganizationDivisionRequest) (string, error) {
 	if strings.TrimSpace(req.DivisionName) == "" {
 		return "", NewServiceError(ErrInvalidInput, 400, "division_name wajib")
 	}
-	id, err := s.orgRepo.CreateDivision(organizationID, userID, strings.TrimSpace(req.DivisionName), strings.TrimSpace(req.Description))
+	id, err := s.orgRepo.CreateDivision(ctx, userID, strings.TrimSpace(req.DivisionName), strings.TrimSpace(req.Description))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, 500, "failed to create division")
 	}
 	return id, nil
 }
 
-func (s *OrganizationService) UpdateDivision(organizationID, userID string, req *model.UpdateOrganizationDivisionRequest) error {
+func (s *OrganizationService) UpdateDivision(ctx context.Context, userID string, req *model.UpdateOrganizationDivisionRequest) error {
+	organizationID := organizationFromContext(ctx)
 	if strings.TrimSpace(req.DivisionID) == "" {
 		return NewServiceError(ErrInvalidInput, 400, "division_id wajib")
 	}
@@ -52,7 +55,7 @@ func (s *OrganizationService) UpdateDivision(organizationID, userID string, req
 		return NewServiceError(ErrInvalidInput, 400, "division_name wajib")
 	}
 
-	targetOrgID, err := s.orgRepo.GetDivisionOrganizationID(strings.TrimSpace(req.DivisionID))
+	targetOrgID, err := s.orgRepo.GetDivisionOrganizationID(ctx, strings.TrimSpace(req.DivisionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "division not found")
@@ -66,7 +69,7 @@ func (s *OrganizationService) UpdateDivision(organizationID, userID string, req
 		return NewServiceError(ErrNotFound, 404, "division not found")
 	}
 
-	err = s.orgRepo.UpdateDivision(organizationID, userID, strings.TrimSpace(req.DivisionID), strings.TrimSpace(req.DivisionName), strings.TrimSpace(req.Description))
+	err = s.orgRepo.UpdateDivision(ctx, userID, strings.TrimSpace(req.DivisionID), strings.TrimSpace(req.DivisionName), strings.TrimSpace(req.Description))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "division not found")
@@ -76,12 +79,13 @@ func (s *OrganizationService) UpdateDivision(organizationID, userID string, req
 	return nil
 }
 
-func (s *OrganizationService) DeleteDivision(organizationID, userID, divisionID string) error {
+func (s *OrganizationService) DeleteDivision(ctx context.Context, userID, divisionID string) error {
+	organizationID := organizationFromContext(ctx)
 	if strings.TrimSpace(divisionID) == "" {
 		return NewServiceError(ErrInvalidInput, 400, "division_id wajib")
 	}
 
-	targetOrgID, err := s.orgRepo.GetDivisionOrganizationID(strings.TrimSpace(divisionID))
+	targetOrgID, err := s.orgRepo.GetDivisionOrganizationID(ctx, strings.TrimSpace(divisionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "division not found")
@@ -95,7 +99,7 @@ func (s *OrganizationService) DeleteDivision(organizationID, userID, divisionID
 		return NewServiceError(ErrNotFound, 404, "division not found")
 	}
 
-	err = s.orgRepo.DeleteDivision(organizationID, userID, strings.TrimSpace(divisionID))
+	err = s.orgRepo.DeleteDivision(ctx, userID, strings.TrimSpace(divisionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "division not found")
@@ -105,37 +109,72 @@ func (s *OrganizationService) DeleteDivision(organizationID, userID, divisionID
 	return nil
 }
 
-func (s *OrganizationService) ListRoles(organizationID string) ([]model.OrganizationRole, error) {
-	items, err := s.orgRepo.ListRoles(organizationID)
+func (s *OrganizationService) ListRoles(ctx context.Context) ([]model.OrganizationRole, error) {
+	items, err := s.orgRepo.ListRoles(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, 500, "failed to get roles")
 	}
+	perms, err := s.orgRepo.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, 500, "failed to get role permissions")
+	}
+	for i := range items {
+		items[i].Permissions = perms[items[i].RoleID]
+		if items[i].Permissions == nil {
+			items[i].Permissions = []string{}
+		}
+	}
 	return items, nil
 }
 
-func (s *OrganizationService) CreateRole(organizationID, userID string, req *model.CreateOrganizationRoleRequest) (string, error) {
+// normalizePermissions validates and de-duplicates the permissions of a role
+func normalizePermissions(permissions []string) ([]string, error) {
+	out := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !configs.Permission(p).IsValid() {
+			return nil, NewServiceError(ErrInvalidInput, 400, "permission tidak dikenal: "+p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *OrganizationService) CreateRole(ctx context.Context, userID string, req *model.CreateOrganizationRoleRequest) (string, error) {
 	if strings.TrimSpace(req.RoleName) == "" {
 		return "", NewServiceError(ErrInvalidInput, 400, "role_name wajib")
 	}
 	if strings.TrimSpace(req.DivisionID) == "" {
 		return "", NewServiceError(ErrInvalidInput, 400, "division_id wajib")
 	}
-	ok, err := s.orgRepo.DivisionExists(organizationID, strings.TrimSpace(req.DivisionID))
+	ok, err := s.orgRepo.DivisionExists(ctx, strings.TrimSpace(req.DivisionID))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, 500, "failed to validate division_id")
 	}
 	if !ok {
 		return "", NewServiceError(ErrInvalidInput, 400, "division_id tidak ditemukan")
 	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return "", err
+	}
 
-	id, err := s.orgRepo.CreateRole(organizationID, userID, strings.TrimSpace(req.RoleName), strings.TrimSpace(req.Description), strings.TrimSpace(req.DivisionID))
+	id, err := s.orgRepo.CreateRole(ctx, userID, strings.TrimSpace(req.RoleName), strings.TrimSpace(req.Description), strings.TrimSpace(req.DivisionID))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, 500, "failed to create role")
 	}
+	if err := s.orgRepo.SetRolePermissions(ctx, id, userID, permissions); err != nil {
+		return "", NewServiceError(ErrInternalServer, 500, "failed to save role permissions")
+	}
 	return id, nil
 }
 
-func (s *OrganizationService) UpdateRole(organizationID, userID string, req *model.UpdateOrganizationRoleRequest) error {
+func (s *OrganizationService) UpdateRole(ctx context.Context, userID string, req *model.UpdateOrganizationRoleRequest) error {
+	organizationID := organizationFromContext(ctx)
 	if strings.TrimSpace(req.RoleID) == "" {
 		return NewServiceError(ErrInvalidInput, 400, "role_id wajib")
 	}
@@ -146,7 +185,7 @@ func (s *OrganizationService) UpdateRole(organizationID, userID string, req *mod
 		return NewServiceError(ErrInvalidInput, 400, "division_id wajib")
 	}
 
-	targetOrgID, err := s.orgRepo.GetRoleOrganizationID(strings.TrimSpace(req.RoleID))
+	targetOrgID, err := s.orgRepo.GetRoleOrganizationID(ctx, strings.TrimSpace(req.RoleID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "role not found")
@@ -160,30 +199,42 @@ func (s *OrganizationService) UpdateRole(organizationID, userID string, req *mod
 		return NewServiceError(ErrNotFound, 404, "role not found")
 	}
 
-	ok, err := s.orgRepo.DivisionExists(organizationID, strings.TrimSpace(req.DivisionID))
+	ok, err := s.orgRepo.DivisionExists(ctx, strings.TrimSpace(req.DivisionID))
 	if err != nil {
 		return NewServiceError(ErrInternalServer, 500, "failed to validate division_id")
 	}
 	if !ok {
 		return NewServiceError(ErrInvalidInput, 400, "division_id tidak ditemukan")
 	}
+	var permissions []string
+	if req.Permissions != nil {
+		if permissions, err = normalizePermissions(req.Permissions); err != nil {
+			return err
+		}
+	}
 
-	err = s.orgRepo.UpdateRole(organizationID, userID, strings.TrimSpace(req.RoleID), strings.TrimSpace(req.RoleName), strings.TrimSpace(req.Description), strings.TrimSpace(req.DivisionID))
+	err = s.orgRepo.UpdateRole(ctx, userID, strings.TrimSpace(req.RoleID), strings.TrimSpace(req.RoleName), strings.TrimSpace(req.Description), strings.TrimSpace(req.DivisionID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "role not found")
 		}
 		return NewServiceError(ErrInternalServer, 500, "failed to update role")
 	}
+	if req.Permissions != nil {
+		if err := s.orgRepo.SetRolePermissions(ctx, strings.TrimSpace(req.RoleID), userID, permissions); err != nil {
+			return NewServiceError(ErrInternalServer, 500, "failed to save role permissions")
+		}
+	}
 	return nil
 }
 
-func (s *OrganizationService) DeleteRole(organizationID, userID, roleID string) error {
+func (s *OrganizationService) DeleteRole(ctx context.Context, userID, roleID string) error {
+	organizationID := organizationFromContext(ctx)
 	if strings.TrimSpace(roleID) == "" {
 		return NewServiceError(ErrInvalidInput, 400, "role_id wajib")
 	}
 
-	targetOrgID, err := s.orgRepo.GetRoleOrganizationID(strings.TrimSpace(roleID))
+	targetOrgID, err := s.orgRepo.GetRoleOrganizationID(ctx, strings.TrimSpace(roleID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "role not found")
@@ -197,7 +248,7 @@ func (s *OrganizationService) DeleteRole(organizationID, userID, roleID string)
 		return NewServiceError(ErrNotFound, 404, "role not found")
 	}
 
-	err = s.orgRepo.DeleteRole(organizationID, userID, strings.TrimSpace(roleID))
+	err = s.orgRepo.DeleteRole(ctx, userID, strings.TrimSpace(roleID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return NewServiceError(ErrNotFound, 404, "role not found")
@@ -206,3 +257,43 @@ func (s *OrganizationService) DeleteRole(organizationID, userID, roleID string)
 	}
 	return nil
 }
+
+// AssignMemberRole sets the role a member acts as, which decides their
+// permissions. Only roles of the organization itself can be assigned.
+func (s *OrganizationService) AssignMemberRole(ctx context.Context, userID string, req *model.AssignMemberRoleRequest) error {
+	organizationID := organizationFromContext(ctx)
+	if s.orgUserRepo == nil {
+		return NewServiceError(ErrInternalServer, 500, "organization user repository not initialized")
+	}
+	roleID := strings.TrimSpace(req.RoleID)
+	if roleID != "" {
+		targetOrgID, err := s.orgRepo.GetRoleOrganizationID(ctx, roleID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return NewServiceError(ErrNotFound, 404, "role not found")
+			}
+			return NewServiceError(ErrInternalServer, 500, "failed to assign role")
+		}
+		if strings.TrimSpace(targetOrgID) != strings.TrimSpace(organizationID) {
+			return NewServiceError(ErrNotFound, 404, "role not found")
+		}
+	}
+
+	err := s.orgUserRepo.UpdateOrganizationUserRoleID(ctx, strings.TrimSpace(req.UserID), roleID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, 404, "member not found")
+		}
+		return NewServiceError(ErrInternalServer, 500, "failed to assign role")
+	}
+	return nil
+}
+
+// PermissionOptions lists every permission that can be granted to a role
+func PermissionOptions() []model.PermissionOption {
+	out := make([]model.PermissionOption, 0, len(configs.PermissionLabel))
+	for _, it := range configs.PermissionLabel {
+		out = append(out, model.PermissionOption{Permission: string(it.Permission), Label: it.Label})
+	}
+	return out
+}
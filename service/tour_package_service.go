@@ -17,9 +17,10 @@ import (
 )
 
 type TourPackageService struct {
-	repo       *repository.TourPackageRepository
-	baseURL    string
-	citiesName map[string]string
+	repo           *repository.TourPackageRepository
+	voucherService *VoucherService
+	baseURL        string
+	citiesName     map[string]string
 }
 
 func NewTourPackageService(repo *repository.TourPackageRepository, baseURL string) *TourPackageService {
@@ -29,6 +30,11 @@ func NewTourPackageService(repo *repository.TourPackageRepository, baseURL strin
 	}
 }
 
+// SetVoucherService enables voucher codes on tour package orders.
+func (s *TourPackageService) SetVoucherService(voucherService *VoucherService) {
+	s.voucherService = voucherService
+}
+
 func (s *TourPackageService) resolveThumbnailURL(path string) string {
 	p := strings.TrimSpace(path)
 	if p == "" {
@@ -46,8 +52,8 @@ func (s *TourPackageService) resolveThumbnailURL(path string) string {
 	return helper.GetAssetURL(p)
 }
 
-func (s *TourPackageService) GetTourPackages(orgID string) ([]model.TourPackageListItem, error) {
-	items, err := s.repo.GetTourPackagesByOrgID(orgID)
+func (s *TourPackageService) GetTourPackages(ctx context.Context) ([]model.TourPackageListItem, error) {
+	items, err := s.repo.GetTourPackagesByOrgID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -100,11 +106,12 @@ func (s *TourPackageService) GetTourPackages(orgID string) ([]model.TourPackageL
 	return items, nil
 }
 
-func (s *TourPackageService) GetTourPackageOrderList(orgID string) ([]model.TourPackageOrderListItem, error) {
-	return s.repo.ListTourPackageOrders(orgID)
+func (s *TourPackageService) GetTourPackageOrderList(ctx context.Context) ([]model.TourPackageOrderListItem, error) {
+	return s.repo.ListTourPackageOrders(ctx)
 }
 
-func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID, userID string, req *model.TourPackageOrderCreateRequest) (string, error) {
+func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, userID string, req *model.TourPackageOrderCreateRequest) (string, error) {
+	orgID := organizationFromContext(ctx)
 	if orgID == "" || userID == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization context missing")
 	}
@@ -130,7 +137,7 @@ func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID,
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid end_date")
 	}
 
-	customerExists, err := s.repo.CustomerExistsByOrgID(orgID, strings.TrimSpace(req.CustomerID))
+	customerExists, err := s.repo.CustomerExistsByOrgID(ctx, strings.TrimSpace(req.CustomerID))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate customer")
 	}
@@ -138,7 +145,7 @@ func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID,
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "CUSTOMER_NOT_FOUND")
 	}
 
-	packageExists, err := s.repo.TourPackageExistsByOrgID(orgID, strings.TrimSpace(req.PackageID))
+	packageExists, err := s.repo.TourPackageExistsByOrgID(ctx, strings.TrimSpace(req.PackageID))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate package")
 	}
@@ -146,7 +153,7 @@ func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID,
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "PACKAGE_NOT_FOUND")
 	}
 
-	price, priceOk, err := s.repo.GetTourPackagePriceByID(orgID, strings.TrimSpace(req.PriceID))
+	price, priceOk, err := s.repo.GetTourPackagePriceByID(ctx, strings.TrimSpace(req.PriceID))
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate price")
 	}
@@ -170,7 +177,7 @@ func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID,
 
 	addonTotal := 0.0
 	if len(addonIDs) > 0 {
-		total, allExist, err := s.repo.GetTourPackageAddonTotalByIDs(orgID, addonIDs)
+		total, allExist, err := s.repo.GetTourPackageAddonTotalByIDs(ctx, addonIDs)
 		if err != nil {
 			return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate addons")
 		}
@@ -204,12 +211,31 @@ func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID,
 		totalAmount = 0
 	}
 
-	orgCode, err := s.repo.GetOrganizationCodeByOrgID(orgID)
+	var voucher *model.VoucherApplication
+	if s.voucherService != nil && strings.TrimSpace(req.VoucherCode) != "" {
+		voucher, err = s.voucherService.Apply(ctx, model.VoucherApplyInput{
+			Code:       req.VoucherCode,
+			OrderType:  model.VoucherOrderTourPackage,
+			ProductID:  strings.TrimSpace(req.PackageID),
+			CustomerID: strings.TrimSpace(req.CustomerID),
+			Amount:     totalAmount,
+			At:         time.Now(),
+		})
+		if err != nil {
+			return "", err
+		}
+		if voucher != nil {
+			discountAmount += voucher.DiscountAmount
+			totalAmount = voucher.TotalAmount
+		}
+	}
+
+	orgCode, err := s.repo.GetOrganizationCodeByOrgID(ctx)
 	if err != nil || strings.TrimSpace(orgCode) == "" {
 		return "", NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization context missing")
 	}
 
-	count, err := s.repo.GetTourPackageOrderCountByOrgID(orgID)
+	count, err := s.repo.GetTourPackageOrderCountByOrgID(ctx)
 	if err != nil {
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get order count")
 	}
@@ -233,13 +259,18 @@ func (s *TourPackageService) CreateTourPackageOrder(ctx context.Context, orgID,
 		TotalPax:         totalPax,
 		TotalAmount:      totalAmount,
 		AddonIDs:         addonIDs,
+		Voucher:          voucher,
 	}); err != nil {
+		if verr := voucherUsageError(err); verr != nil {
+			return "", verr
+		}
 		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create order")
 	}
 	return orderID, nil
 }
 
-func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID, userID string, req *model.TourPackageOrderUpdateRequest) error {
+func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, userID string, req *model.TourPackageOrderUpdateRequest) error {
+	orgID := organizationFromContext(ctx)
 	if orgID == "" || userID == "" {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization context missing")
 	}
@@ -268,7 +299,7 @@ func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID,
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid end_date")
 	}
 
-	orderExists, err := s.repo.TourPackageOrderExistsByOrgID(orgID, strings.TrimSpace(req.OrderID))
+	orderExists, err := s.repo.TourPackageOrderExistsByOrgID(ctx, strings.TrimSpace(req.OrderID))
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate order")
 	}
@@ -276,7 +307,7 @@ func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID,
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "ORDER_NOT_FOUND")
 	}
 
-	customerExists, err := s.repo.CustomerExistsByOrgID(orgID, strings.TrimSpace(req.CustomerID))
+	customerExists, err := s.repo.CustomerExistsByOrgID(ctx, strings.TrimSpace(req.CustomerID))
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate customer")
 	}
@@ -284,7 +315,7 @@ func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID,
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "CUSTOMER_NOT_FOUND")
 	}
 
-	packageExists, err := s.repo.TourPackageExistsByOrgID(orgID, strings.TrimSpace(req.PackageID))
+	packageExists, err := s.repo.TourPackageExistsByOrgID(ctx, strings.TrimSpace(req.PackageID))
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate package")
 	}
@@ -292,7 +323,7 @@ func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID,
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "PACKAGE_NOT_FOUND")
 	}
 
-	price, priceOk, err := s.repo.GetTourPackagePriceByID(orgID, strings.TrimSpace(req.PriceID))
+	price, priceOk, err := s.repo.GetTourPackagePriceByID(ctx, strings.TrimSpace(req.PriceID))
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate price")
 	}
@@ -316,7 +347,7 @@ func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID,
 
 	addonTotal := 0.0
 	if len(addonIDs) > 0 {
-		total, allExist, err := s.repo.GetTourPackageAddonTotalByIDs(orgID, addonIDs)
+		total, allExist, err := s.repo.GetTourPackageAddonTotalByIDs(ctx, addonIDs)
 		if err != nil {
 			return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to validate addons")
 		}
@@ -370,7 +401,8 @@ func (s *TourPackageService) UpdateTourPackageOrder(ctx context.Context, orgID,
 	})
 }
 
-func (s *TourPackageService) GetTourPackageOrderDetail(ctx context.Context, orgID, orderID string) (map[string]interface{}, error) {
+func (s *TourPackageService) GetTourPackageOrderDetail(ctx context.Context, orderID string) (map[string]interface{}, error) {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization context missing")
 	}
@@ -379,7 +411,7 @@ func (s *TourPackageService) GetTourPackageOrderDetail(ctx context.Context, orgI
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "order_id is required")
 	}
 
-	order, addons, err := s.repo.GetTourPackageOrderDetail(ctx, orgID, orderID)
+	order, addons, err := s.repo.GetTourPackageOrderDetail(ctx, orderID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "ORDER_NOT_FOUND")
@@ -400,7 +432,7 @@ func (s *TourPackageService) GetTourPackageOrderDetail(ctx context.Context, orgI
 	}
 	customer := map[string]interface{}{}
 	if customerID != "" {
-		cust, err := s.repo.GetCustomerInfoByOrgID(ctx, orgID, customerID)
+		cust, err := s.repo.GetCustomerInfoByOrgID(ctx, customerID)
 		if err == nil && cust != nil {
 			customer = cust
 		}
@@ -579,15 +611,15 @@ func (s *TourPackageService) GetTourPackageOrderDetail(ctx context.Context, orgI
 	}, nil
 }
 
-func (s *TourPackageService) CreateTourPackage(ctx context.Context, req *model.CreateTourPackageRequest, orgID, userID string) error {
+func (s *TourPackageService) CreateTourPackage(ctx context.Context, req *model.CreateTourPackageRequest, userID string) error {
 	normalizeCreateTourPackageItineraries(req)
 	packageID := helper.GenerateUUID()
-	return s.repo.CreateTourPackage(ctx, req, packageID, orgID, userID)
+	return s.repo.CreateTourPackage(ctx, req, packageID, userID)
 }
 
-func (s *TourPackageService) UpdateTourPackage(ctx context.Context, req *model.UpdateTourPackageRequest, orgID, userID string) error {
+func (s *TourPackageService) UpdateTourPackage(ctx context.Context, req *model.UpdateTourPackageRequest, userID string) error {
 	normalizeUpdateTourPackageItineraries(req)
-	return s.repo.UpdateTourPackage(ctx, req, orgID, userID)
+	return s.repo.UpdateTourPackage(ctx, req, userID)
 }
 
 func normalizeCreateTourPackageItineraries(req *model.CreateTourPackageRequest) {
@@ -610,11 +642,11 @@ func normalizeUpdateTourPackageItineraries(req *model.UpdateTourPackageRequest)
 	}
 }
 
-func (s *TourPackageService) DeleteTourPackage(ctx context.Context, orgID, userID, packageID string) error {
-	return s.repo.SoftDeleteTourPackage(ctx, orgID, userID, packageID)
+func (s *TourPackageService) DeleteTourPackage(ctx context.Context, userID, packageID string) error {
+	return s.repo.SoftDeleteTourPackage(ctx, userID, packageID)
 }
 
-func (s *TourPackageService) SetTourPackageActiveStatus(ctx context.Context, orgID, userID, action, packageID string) error {
+func (s *TourPackageService) SetTourPackageActiveStatus(ctx context.Context, userID, action, packageID string) error {
 	action = strings.TrimSpace(strings.ToLower(action))
 	packageID = strings.TrimSpace(packageID)
 	if packageID == "" {
@@ -622,16 +654,16 @@ func (s *TourPackageService) SetTourPackageActiveStatus(ctx context.Context, org
 	}
 	switch action {
 	case "active":
-		return s.repo.SetTourPackageActiveStatus(ctx, orgID, userID, packageID, true)
+		return s.repo.SetTourPackageActiveStatus(ctx, userID, packageID, true)
 	case "inactive":
-		return s.repo.SetTourPackageActiveStatus(ctx, orgID, userID, packageID, false)
+		return s.repo.SetTourPackageActiveStatus(ctx, userID, packageID, false)
 	default:
 		return fmt.Errorf("action must be active or inactive")
 	}
 }
 
-func (s *TourPackageService) GetTourPackageDetail(ctx context.Context, orgID, packageID string) (*model.TourPackageDetailResponse, error) {
-	res, err := s.repo.GetTourPackageDetail(ctx, orgID, packageID)
+func (s *TourPackageService) GetTourPackageDetail(ctx context.Context, packageID string) (*model.TourPackageDetailResponse, error) {
+	res, err := s.repo.GetTourPackageDetail(ctx, packageID)
 	if err != nil {
 		return nil, err
 	}
@@ -707,6 +739,6 @@ func normalizeTourPackageDateTime(v string) (string, error) {
 	return "", fmt.Errorf("invalid datetime")
 }
 
-func (s *TourPackageService) GetPublicTourPackages(ctx context.Context, orgID string) ([]*model.TourPackageListPublicItem, error) {
-	return s.repo.GetPublicTourPackages(ctx, orgID)
+func (s *TourPackageService) GetPublicTourPackages(ctx context.Context) ([]*model.TourPackageListPublicItem, error) {
+	return s.repo.GetPublicTourPackages(ctx)
 }
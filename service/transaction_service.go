@@ -1,11 +1,15 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"service-travego/database"
+	"service-travego/internal/supervisor"
 	"service-travego/model"
 	"service-travego/repository"
 	"strings"
@@ -15,6 +19,7 @@ import (
 type TransactionService struct {
 	repo                *repository.TransactionRepository
 	notificationService *NotificationService
+	audit               *AuditService
 }
 
 func NewTransactionService(repo *repository.TransactionRepository, notificationService *NotificationService) *TransactionService {
@@ -24,15 +29,34 @@ func NewTransactionService(repo *repository.TransactionRepository, notificationS
 	}
 }
 
-func (s *TransactionService) ListAllRevenue(orgID string, req *model.TransactionListRequest) ([]model.TransactionListItem, error) {
-	return s.listTransactions(orgID, req, "revenue")
+// SetAuditService records finance changes in the audit log
+func (s *TransactionService) SetAuditService(audit *AuditService) {
+	s.audit = audit
 }
 
-func (s *TransactionService) ListAllExpenses(orgID string, req *model.TransactionListRequest) ([]model.TransactionListItem, error) {
-	return s.listTransactions(orgID, req, "expenses")
+// transactionAuditSnapshot loads a transaction for the audit log; nil when
+// auditing is off
+func (s *TransactionService) transactionAuditSnapshot(ctx context.Context, transactionID string) interface{} {
+	if s.audit == nil {
+		return nil
+	}
+	fields, err := s.repo.GetTransactionFields(ctx, transactionID)
+	if err != nil {
+		return nil
+	}
+	return fields
+}
+
+func (s *TransactionService) ListAllRevenue(ctx context.Context, req *model.TransactionListRequest) ([]model.TransactionListItem, error) {
+	return s.listTransactions(ctx, req, "revenue")
+}
+
+func (s *TransactionService) ListAllExpenses(ctx context.Context, req *model.TransactionListRequest) ([]model.TransactionListItem, error) {
+	return s.listTransactions(ctx, req, "expenses")
 }
 
-func (s *TransactionService) listTransactions(orgID string, req *model.TransactionListRequest, mode string) ([]model.TransactionListItem, error) {
+func (s *TransactionService) listTransactions(ctx context.Context, req *model.TransactionListRequest, mode string) ([]model.TransactionListItem, error) {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return nil, NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
 	}
@@ -47,9 +71,9 @@ func (s *TransactionService) listTransactions(orgID string, req *model.Transacti
 	var err error
 
 	if mode == "revenue" {
-		rows, err = s.repo.ListAllRevenue(orgID, req)
+		rows, err = s.repo.ListAllRevenue(ctx, req)
 	} else {
-		rows, err = s.repo.ListAllExpenses(orgID, req)
+		rows, err = s.repo.ListAllExpenses(ctx, req)
 	}
 
 	if err != nil {
@@ -76,11 +100,12 @@ func (s *TransactionService) listTransactions(orgID string, req *model.Transacti
 			CreatedBy:                r.CreatedBy,
 		})
 	}
-	fmt.Println(out)
+	slog.DebugContext(ctx, "list transactions", "out", out)
 	return out, nil
 }
 
-func (s *TransactionService) CreateManualRevenue(orgID, userID string, req *model.CreateManualRevenueRequest) error {
+func (s *TransactionService) CreateManualRevenue(ctx context.Context, userID string, req *model.CreateManualRevenueRequest) error {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
 	}
@@ -88,7 +113,13 @@ func (s *TransactionService) CreateManualRevenue(orgID, userID string, req *mode
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "User not found")
 	}
 
-	err := s.repo.CreateManualTransaction(orgID, userID, &repository.CreateManualTransactionRequest{
+	return s.createManualTransaction(ctx, userID, req)
+}
+
+// createManualTransaction stores a manual revenue or expense and records it in
+// the audit log
+func (s *TransactionService) createManualTransaction(ctx context.Context, userID string, req *model.CreateManualRevenueRequest) error {
+	transactionID, err := s.repo.CreateManualTransaction(ctx, userID, &repository.CreateManualTransactionRequest{
 		OrderType:       req.OrderType,
 		OrderID:         req.OrderID,
 		Description:     req.Description,
@@ -100,11 +131,20 @@ func (s *TransactionService) CreateManualRevenue(orgID, userID string, req *mode
 		BankAccount:     req.BankAccount,
 		BankCode:        req.BankCode,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityTransaction, EntityID: transactionID, Action: model.AuditActionCreate,
+		After: s.transactionAuditSnapshot(ctx, transactionID),
+	})
+	return nil
 }
 
 // CreateManualExpense creates a manual expense transaction with the specified order_type
-func (s *TransactionService) CreateManualExpense(orgID, userID string, req *model.CreateManualRevenueRequest) error {
+func (s *TransactionService) CreateManualExpense(ctx context.Context, userID string, req *model.CreateManualRevenueRequest) error {
+	orgID := organizationFromContext(ctx)
 	if strings.TrimSpace(orgID) == "" {
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
 	}
@@ -112,23 +152,29 @@ func (s *TransactionService) CreateManualExpense(orgID, userID string, req *mode
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "User not found")
 	}
 
-	err := s.repo.CreateManualTransaction(orgID, userID, &repository.CreateManualTransactionRequest{
-		OrderType:       req.OrderType,
-		OrderID:         req.OrderID,
-		Description:     req.Description,
-		TransactionDate: req.TransactionDate,
-		Status:          req.Status,
-		TransactionType: req.TransactionType,
-		Amount:          req.Amount,
-		PaymentMethod:   req.PaymentMethod,
-		BankAccount:     req.BankAccount,
-		BankCode:        req.BankCode,
+	return s.createManualTransaction(ctx, userID, req)
+}
+
+func (s *TransactionService) SubmitFleetTripExpense(ctx context.Context, userID, transactionItem, scheduleNumber string, paymentMethod int, amount float64, description string) error {
+	if err := s.submitFleetTripExpense(ctx, userID, transactionItem, scheduleNumber, paymentMethod, amount, description); err != nil {
+		return err
+	}
+	// trip expenses are logged against their SJP (schedule number)
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    strings.TrimSpace(userID),
+		EntityType: model.AuditEntityTransaction, EntityID: strings.TrimSpace(scheduleNumber), Action: model.AuditActionCreate,
+		After: map[string]interface{}{
+			"transaction_item": strings.ToUpper(strings.TrimSpace(transactionItem)),
+			"payment_method":   paymentMethod,
+			"amount":           amount,
+			"description":      strings.TrimSpace(description),
+		},
 	})
-	return err
+	return nil
 }
 
-func (s *TransactionService) SubmitFleetTripExpense(orgID, userID, transactionItem, scheduleNumber string, paymentMethod int, amount float64, description string) error {
-	orgID = strings.TrimSpace(orgID)
+func (s *TransactionService) submitFleetTripExpense(ctx context.Context, userID, transactionItem, scheduleNumber string, paymentMethod int, amount float64, description string) error {
+	orgID := organizationFromContext(ctx)
 	userID = strings.TrimSpace(userID)
 	transactionItem = strings.ToUpper(strings.TrimSpace(transactionItem))
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
@@ -156,7 +202,7 @@ func (s *TransactionService) SubmitFleetTripExpense(orgID, userID, transactionIt
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "payment_method is required")
 	}
 
-	orderID, ok, err := s.repo.GetFleetOrderIDByScheduleNumber(scheduleNumber, orgID)
+	orderID, ok, err := s.repo.GetFleetOrderIDByScheduleNumber(ctx, scheduleNumber)
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get schedule order")
 	}
@@ -166,14 +212,14 @@ func (s *TransactionService) SubmitFleetTripExpense(orgID, userID, transactionIt
 
 	if transactionItem == "TRX-I00" {
 		desc := fmt.Sprintf("Biaya Operasional %s", scheduleNumber)
-		return s.repo.CreateFleetTripOperationalExpenseTransaction(orgID, userID, orderID, scheduleNumber, amount, desc)
+		return s.repo.CreateFleetTripOperationalExpenseTransaction(ctx, userID, orderID, scheduleNumber, amount, desc)
 	}
 
-	totalAmount, err := s.repo.SumTransactionsAmountByReferenceID(scheduleNumber)
+	totalAmount, err := s.repo.SumTransactionsAmountByReferenceID(ctx, scheduleNumber)
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get total amount")
 	}
-	totalExpenses, err := s.repo.SumFleetTripAmountByScheduleNumber(scheduleNumber)
+	totalExpenses, err := s.repo.SumFleetTripAmountByScheduleNumber(ctx, scheduleNumber)
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to get total expenses")
 	}
@@ -181,61 +227,62 @@ func (s *TransactionService) SubmitFleetTripExpense(orgID, userID, transactionIt
 	remaining := totalAmount - totalExpenses
 	if remaining <= 0 {
 		if s.notificationService != nil {
-			go func() {
+			supervisor.Go("transaction.notify_reimbursement", func(ctx context.Context) {
 				baseURL := os.Getenv("BASE_URL")
-				_, _ = s.notificationService.CreateNotification(orgID, NotificationPayload{
+				_, _ = s.notificationService.CreateNotification(database.WithOrganization(ctx, orgID), NotificationPayload{
 					Title:   "Pengeluaran Reimbursement Baru",
 					Message: fmt.Sprintf("Ada pengeluaran reimbursement sebesar %.2f untuk SJP %s", amount, scheduleNumber),
 					URL:     baseURL + "/dashboard/schedules/fleet-schedules/detail/" + scheduleNumber,
 				})
-			}()
+			})
 		}
-		return s.repo.CreateFleetTripExpenseTransaction(orgID, userID, orderID, scheduleNumber, transactionItem, 2, 0, amount, "reimbursement - "+description)
+		return s.repo.CreateFleetTripExpenseTransaction(ctx, userID, orderID, scheduleNumber, transactionItem, 2, 0, amount, "reimbursement - "+description)
 	}
 	if totalExpenses+amount <= totalAmount {
-		return s.repo.CreateFleetTripExpenseTransaction(orgID, userID, orderID, scheduleNumber, transactionItem, 1, 1, amount, description)
+		return s.repo.CreateFleetTripExpenseTransaction(ctx, userID, orderID, scheduleNumber, transactionItem, 1, 1, amount, description)
 	}
 
 	firstAmount := remaining
 	secondAmount := amount - remaining
 
 	if firstAmount > 0 {
-		if err := s.repo.CreateFleetTripExpenseTransaction(orgID, userID, orderID, scheduleNumber, transactionItem, 1, 1, firstAmount, description); err != nil {
+		if err := s.repo.CreateFleetTripExpenseTransaction(ctx, userID, orderID, scheduleNumber, transactionItem, 1, 1, firstAmount, description); err != nil {
 			return err
 		}
 	}
 	if secondAmount > 0 {
-		if err := s.repo.CreateFleetTripExpenseTransaction(orgID, userID, orderID, scheduleNumber, transactionItem, 2, 0, secondAmount, "reimbursement - "+description); err != nil {
+		if err := s.repo.CreateFleetTripExpenseTransaction(ctx, userID, orderID, scheduleNumber, transactionItem, 2, 0, secondAmount, "reimbursement - "+description); err != nil {
 			return err
 		}
 		if s.notificationService != nil {
-			go func() {
+			supervisor.Go("transaction.notify_reimbursement", func(ctx context.Context) {
 				baseURL := os.Getenv("BASE_URL")
-				_, _ = s.notificationService.CreateNotification(orgID, NotificationPayload{
+				_, _ = s.notificationService.CreateNotification(database.WithOrganization(ctx, orgID), NotificationPayload{
 					Title:   "Pengeluaran Reimbursement Baru",
 					Message: fmt.Sprintf("Ada pengeluaran reimbursement sebesar %.2f untuk SJP %s", secondAmount, scheduleNumber),
 					URL:     baseURL + "/dashboard/schedules/fleet-schedules/detail/" + scheduleNumber,
 				})
-			}()
+			})
 		}
 	}
 	return nil
 }
 
-func (s *TransactionService) GetFleetTripTotalAmount(scheduleNumber string) (float64, error) {
+func (s *TransactionService) GetFleetTripTotalAmount(ctx context.Context, scheduleNumber string) (float64, error) {
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	if scheduleNumber == "" {
 		return 0, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "schedule_number is required")
 	}
 
-	total, err := s.repo.SumTransactionsAmountByReferenceID(scheduleNumber)
+	total, err := s.repo.SumTransactionsAmountByReferenceID(ctx, scheduleNumber)
 	if err != nil {
 		return 0, err
 	}
 	return total, nil
 }
 
-func (s *TransactionService) GetFleetTripAmountSummaryByPaymentMethod(scheduleNumber, orgID string) (model.FleetTripAmountSummary, error) {
+func (s *TransactionService) GetFleetTripAmountSummaryByPaymentMethod(ctx context.Context, scheduleNumber string) (model.FleetTripAmountSummary, error) {
+	orgID := organizationFromContext(ctx)
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	orgID = strings.TrimSpace(orgID)
 	result := model.FleetTripAmountSummary{}
@@ -246,10 +293,11 @@ func (s *TransactionService) GetFleetTripAmountSummaryByPaymentMethod(scheduleNu
 		return result, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "schedule_number is required")
 	}
 
-	return s.repo.GetFleetTripAmountSummary(scheduleNumber, orgID)
+	return s.repo.GetFleetTripAmountSummary(ctx, scheduleNumber)
 }
 
-func (s *TransactionService) ListFleetTripExpenses(scheduleNumber, orgID string) ([]model.FleetTripExpenseRow, error) {
+func (s *TransactionService) ListFleetTripExpenses(ctx context.Context, scheduleNumber string) ([]model.FleetTripExpenseRow, error) {
+	orgID := organizationFromContext(ctx)
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	orgID = strings.TrimSpace(orgID)
 	if orgID == "" {
@@ -258,11 +306,11 @@ func (s *TransactionService) ListFleetTripExpenses(scheduleNumber, orgID string)
 	if scheduleNumber == "" {
 		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "schedule_number is required")
 	}
-	return s.repo.ListFleetTripExpensesByScheduleNumber(scheduleNumber, orgID)
+	return s.repo.ListFleetTripExpensesByScheduleNumber(ctx, scheduleNumber)
 }
 
-func (s *TransactionService) DeleteFleetTripExpense(orgID, userID, scheduleNumber, transactionTripID string) error {
-	orgID = strings.TrimSpace(orgID)
+func (s *TransactionService) DeleteFleetTripExpense(ctx context.Context, userID, scheduleNumber, transactionTripID string) error {
+	orgID := organizationFromContext(ctx)
 	userID = strings.TrimSpace(userID)
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	transactionTripID = strings.TrimSpace(transactionTripID)
@@ -280,15 +328,23 @@ func (s *TransactionService) DeleteFleetTripExpense(orgID, userID, scheduleNumbe
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "transaction_trip_id is required")
 	}
 
-	err := s.repo.DeleteFleetTripExpense(orgID, scheduleNumber, transactionTripID)
+	err := s.repo.DeleteFleetTripExpense(ctx, scheduleNumber, transactionTripID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return NewServiceError(ErrNotFound, http.StatusNotFound, "fleet trip expense not found")
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityTransaction, EntityID: transactionTripID, Action: model.AuditActionDelete,
+		Before: map[string]interface{}{"schedule_number": scheduleNumber},
+	})
+	return nil
 }
 
-func (s *TransactionService) SubmitExpenseTransaction(orgID, userID string, req *model.SubmitExpenseTransactionRequest) error {
-	orgID = strings.TrimSpace(orgID)
+func (s *TransactionService) SubmitExpenseTransaction(ctx context.Context, userID string, req *model.SubmitExpenseTransactionRequest) error {
+	orgID := organizationFromContext(ctx)
 	userID = strings.TrimSpace(userID)
 	if orgID == "" {
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
@@ -332,7 +388,7 @@ func (s *TransactionService) SubmitExpenseTransaction(orgID, userID string, req
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "transaction_item is required")
 	}
 
-	return s.repo.CreateExpenseTransaction(orgID, userID, &repository.CreateExpenseTransactionRequest{
+	transactionID, err := s.repo.CreateExpenseTransaction(ctx, userID, &repository.CreateExpenseTransactionRequest{
 		Amount:              req.Amount,
 		Description:         description,
 		UnitID:              unitID,
@@ -342,10 +398,19 @@ func (s *TransactionService) SubmitExpenseTransaction(orgID, userID string, req
 		TransactionCategory: transactionCategory,
 		TransactionItem:     transactionItem,
 	})
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityTransaction, EntityID: transactionID, Action: model.AuditActionCreate,
+		After: s.transactionAuditSnapshot(ctx, transactionID),
+	})
+	return nil
 }
 
-func (s *TransactionService) DeleteExpenseTransaction(orgID, transactionID string) error {
-	orgID = strings.TrimSpace(orgID)
+func (s *TransactionService) DeleteExpenseTransaction(ctx context.Context, userID, transactionID string) error {
+	orgID := organizationFromContext(ctx)
 	transactionID = strings.TrimSpace(transactionID)
 	if orgID == "" {
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
@@ -354,15 +419,24 @@ func (s *TransactionService) DeleteExpenseTransaction(orgID, transactionID strin
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "transaction_id is required")
 	}
 
-	err := s.repo.SoftDeleteExpenseTransaction(orgID, transactionID)
+	before := s.transactionAuditSnapshot(ctx, transactionID)
+	err := s.repo.SoftDeleteExpenseTransaction(ctx, transactionID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return NewServiceError(ErrNotFound, http.StatusNotFound, "transaction not found")
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityTransaction, EntityID: transactionID, Action: model.AuditActionDelete,
+		Before: before,
+	})
+	return nil
 }
 
-func (s *TransactionService) UpdateExpenseTransaction(orgID, userID string, req *model.UpdateExpenseTransactionRequest) error {
-	orgID = strings.TrimSpace(orgID)
+func (s *TransactionService) UpdateExpenseTransaction(ctx context.Context, userID string, req *model.UpdateExpenseTransactionRequest) error {
+	orgID := organizationFromContext(ctx)
 	userID = strings.TrimSpace(userID)
 	if orgID == "" {
 		return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "Organization not found")
@@ -403,7 +477,8 @@ func (s *TransactionService) UpdateExpenseTransaction(orgID, userID string, req
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "transaction_item is required")
 	}
 
-	err = s.repo.UpdateExpenseTransaction(orgID, userID, &repository.UpdateExpenseTransactionRequest{
+	before := s.transactionAuditSnapshot(ctx, transactionID)
+	err = s.repo.UpdateExpenseTransaction(ctx, userID, &repository.UpdateExpenseTransactionRequest{
 		TransactionID:       transactionID,
 		Amount:              req.Amount,
 		UnitID:              unitID,
@@ -415,11 +490,19 @@ func (s *TransactionService) UpdateExpenseTransaction(orgID, userID string, req
 	if errors.Is(err, sql.ErrNoRows) {
 		return NewServiceError(ErrNotFound, http.StatusNotFound, "transaction not found")
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityTransaction, EntityID: transactionID, Action: model.AuditActionUpdate,
+		Before: before, After: s.transactionAuditSnapshot(ctx, transactionID),
+	})
+	return nil
 }
 
-func (s *TransactionService) SubmitFleetTripReimbursement(orgID, userID, scheduleNumber string, recipientID string, paymentMethodID string, transactionDateStr string) error {
-	orgID = strings.TrimSpace(orgID)
+func (s *TransactionService) SubmitFleetTripReimbursement(ctx context.Context, userID, scheduleNumber string, recipientID string, paymentMethodID string, transactionDateStr string) error {
+	orgID := organizationFromContext(ctx)
 	userID = strings.TrimSpace(userID)
 	scheduleNumber = strings.TrimSpace(scheduleNumber)
 	recipientID = strings.TrimSpace(recipientID)
@@ -428,7 +511,7 @@ func (s *TransactionService) SubmitFleetTripReimbursement(orgID, userID, schedul
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "organization_id, user_id, schedule_number, recipient_id, and payment_method_id are required")
 	}
 
-	amount, err := s.repo.GetReimbursementAmount(scheduleNumber)
+	amount, err := s.repo.GetReimbursementAmount(ctx, scheduleNumber)
 	if err != nil {
 		return err
 	}
@@ -440,14 +523,19 @@ func (s *TransactionService) SubmitFleetTripReimbursement(orgID, userID, schedul
 		PaymentMethodID: paymentMethodID,
 		TransactionDate: transactionDateStr,
 	}
-	err = s.repo.CreateFleetTripReimbursement(orgID, userID, recordReimbursement)
+	err = s.repo.CreateFleetTripReimbursement(ctx, userID, recordReimbursement)
 	if err != nil {
 		return err
 	}
 
-	updateErr := s.repo.MarkReimbursementPaid(scheduleNumber)
+	updateErr := s.repo.MarkReimbursementPaid(ctx, scheduleNumber)
 	if updateErr != nil {
 		return updateErr
 	}
+	s.audit.Record(ctx, AuditEntry{
+		ActorID:    userID,
+		EntityType: model.AuditEntityTransaction, EntityID: scheduleNumber, Action: model.AuditActionProcess,
+		After: recordReimbursement,
+	})
 	return nil
 }
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -20,6 +21,7 @@ type UserService struct {
 	orgRepo       *repository.OrganizationRepository
 	citiesName    map[string]string
 	provincesName map[string]string
+	outbox        *OutboxService
 }
 
 func NewUserService(userRepo *repository.UserRepository) *UserService {
@@ -28,6 +30,11 @@ func NewUserService(userRepo *repository.UserRepository) *UserService {
 	}
 }
 
+// SetOutboxService sets the outbox the OTP emails are queued in
+func (s *UserService) SetOutboxService(outbox *OutboxService) {
+	s.outbox = outbox
+}
+
 // SetOrganizationUserRepository sets the organization user repository
 func (s *UserService) SetOrganizationUserRepository(orgUserRepo *repository.OrganizationUserRepository) {
 	s.orgUserRepo = orgUserRepo
@@ -38,28 +45,28 @@ func (s *UserService) SetOrganizationRepository(orgRepo *repository.Organization
 	s.orgRepo = orgRepo
 }
 
-func (s *UserService) GetAllUsers() ([]model.User, error) {
-	users, err := s.userRepo.FindAll()
+func (s *UserService) GetAllUsers(ctx context.Context) ([]model.User, error) {
+	users, err := s.userRepo.FindAll(ctx)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to fetch users")
 	}
 	return users, nil
 }
 
-func (s *UserService) GetUserByID(id string) (*model.User, error) {
-	user, err := s.userRepo.FindByID(id)
+func (s *UserService) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
 	return user, nil
 }
 
-func (s *UserService) CreateUser(user *model.User) (*model.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
 	if user.Email == "" {
 		return nil, NewServiceError(errors.New("validation error"), http.StatusBadRequest, "email is required")
 	}
 
-	existingUser, _ := s.userRepo.FindByEmail(user.Email)
+	existingUser, _ := s.userRepo.FindByEmail(ctx, user.Email)
 	if existingUser != nil {
 		return nil, NewServiceError(ErrEmailExists, http.StatusConflict, "email already exists")
 	}
@@ -72,7 +79,7 @@ func (s *UserService) CreateUser(user *model.User) (*model.User, error) {
 		user.Password = hashedPassword
 	}
 
-	createdUser, err := s.userRepo.Create(user)
+	createdUser, err := s.userRepo.Create(ctx, user)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to create user")
 	}
@@ -80,8 +87,8 @@ func (s *UserService) CreateUser(user *model.User) (*model.User, error) {
 	return createdUser, nil
 }
 
-func (s *UserService) UpdateUser(id string, user *model.User) (*model.User, error) {
-	existingUser, err := s.userRepo.FindByID(id)
+func (s *UserService) UpdateUser(ctx context.Context, id string, user *model.User) (*model.User, error) {
+	existingUser, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -102,7 +109,7 @@ func (s *UserService) UpdateUser(id string, user *model.User) (*model.User, erro
 		existingUser.Province = user.Province
 	}
 
-	updatedUser, err := s.userRepo.Update(existingUser)
+	updatedUser, err := s.userRepo.Update(ctx, existingUser)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update user")
 	}
@@ -110,34 +117,34 @@ func (s *UserService) UpdateUser(id string, user *model.User) (*model.User, erro
 	return updatedUser, nil
 }
 
-func (s *UserService) DeleteUser(id string) error {
-	_, err := s.userRepo.FindByID(id)
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	_, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
 
-	if err = s.userRepo.Delete(id); err != nil {
+	if err = s.userRepo.Delete(ctx, id); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete user")
 	}
 
 	return nil
 }
 
-func (s *UserService) DeleteProfile(userID string) error {
-	_, err := s.userRepo.FindByID(userID)
+func (s *UserService) DeleteProfile(ctx context.Context, userID string) error {
+	_, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
 
-	if err := s.userRepo.SetStatusDeleted(userID); err != nil {
+	if err := s.userRepo.SetStatusDeleted(ctx, userID); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete profile")
 	}
 
 	return nil
 }
 
-func (s *UserService) UpdateProfile(user *model.User) (*model.User, error) {
-	existingUser, err := s.userRepo.FindByID(user.UserID)
+func (s *UserService) UpdateProfile(ctx context.Context, user *model.User) (*model.User, error) {
+	existingUser, err := s.userRepo.FindByID(ctx, user.UserID)
 	if err != nil {
 		return nil, NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -173,7 +180,7 @@ func (s *UserService) UpdateProfile(user *model.User) (*model.User, error) {
 		existingUser.Avatar = user.Avatar
 	}
 
-	updatedUser, err := s.userRepo.UpdateProfile(existingUser)
+	updatedUser, err := s.userRepo.UpdateProfile(ctx, existingUser)
 	if err != nil {
 		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update profile")
 	}
@@ -182,9 +189,9 @@ func (s *UserService) UpdateProfile(user *model.User) (*model.User, error) {
 }
 
 // UpdatePassword updates user password after verifying current password
-func (s *UserService) UpdatePassword(userID, currentPassword, newPassword string) error {
+func (s *UserService) UpdatePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
 	// Find user by ID
-	user, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -206,7 +213,7 @@ func (s *UserService) UpdatePassword(userID, currentPassword, newPassword string
 	}
 
 	// Update password
-	if err = s.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
+	if err = s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update password")
 	}
 
@@ -247,8 +254,8 @@ type OrganizationProfile struct {
 }
 
 // GetProfile retrieves user profile with organization data
-func (s *UserService) GetProfile(userID string) (*ProfileResponse, error) {
-	user, err := s.userRepo.FindByID(userID)
+func (s *UserService) GetProfile(ctx context.Context, userID string) (*ProfileResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -305,7 +312,7 @@ func (s *UserService) GetProfile(userID string) (*ProfileResponse, error) {
 
 	// Get organization data if available
 	if s.orgUserRepo != nil {
-		orgCode, orgName, companyName, joinDate, orgRole, err := s.orgUserRepo.GetOrganizationWithJoinDateByUserID(userID)
+		orgCode, orgName, companyName, joinDate, orgRole, err := s.orgUserRepo.GetOrganizationWithJoinDateByUserID(ctx, userID)
 		if err == nil {
 			profile.Organization = &OrganizationProfile{
 				OrganizationCode: orgCode,
@@ -323,8 +330,8 @@ func (s *UserService) GetProfile(userID string) (*ProfileResponse, error) {
 	return profile, nil
 }
 
-func (s *UserService) CheckPassword(userID, password string) error {
-	user, err := s.userRepo.FindByID(userID)
+func (s *UserService) CheckPassword(ctx context.Context, userID, password string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -334,8 +341,9 @@ func (s *UserService) CheckPassword(userID, password string) error {
 	return nil
 }
 
-func (s *UserService) SendUpdatePasswordOTP(orgID, userID string) error {
-	user, err := s.userRepo.FindByID(userID)
+func (s *UserService) SendUpdatePasswordOTP(ctx context.Context, userID string) error {
+	orgID := organizationFromContext(ctx)
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -343,7 +351,7 @@ func (s *UserService) SendUpdatePasswordOTP(orgID, userID string) error {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "email is required")
 	}
 	if s.orgUserRepo != nil {
-		gotOrgID, _, err := s.orgUserRepo.GetOrganizationAndRoleByUserID(userID)
+		gotOrgID, _, err := s.orgUserRepo.GetOrganizationAndRoleByUserID(ctx, userID)
 		if err != nil {
 			return NewServiceError(ErrUnauthorized, http.StatusUnauthorized, "unauthorized")
 		}
@@ -367,14 +375,18 @@ func (s *UserService) SendUpdatePasswordOTP(orgID, userID string) error {
 	if err := helper.SetOTPWithTTL(key, otp, 5*time.Minute); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to store OTP")
 	}
-	if err := helper.SendResetPasswordOTPEmail(emailCfg, user.Email, user.Username, otp); err != nil {
+	otpEmail, err := helper.NewResetPasswordOTPEmail(user.Email, user.Username, otp)
+	if err == nil {
+		err = s.outbox.EnqueueEmail(ctx, orgID, OutboxKindResetPasswordOTP, otpEmail)
+	}
+	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to send OTP email")
 	}
 
 	return nil
 }
 
-func (s *UserService) UpdatePasswordWithOTP(userID, otp, existingPassword, newPassword, confirmPassword string) error {
+func (s *UserService) UpdatePasswordWithOTP(ctx context.Context, userID, otp, existingPassword, newPassword, confirmPassword string) error {
 	if newPassword != confirmPassword {
 		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "confirm_password must match new_password")
 	}
@@ -385,7 +397,7 @@ func (s *UserService) UpdatePasswordWithOTP(userID, otp, existingPassword, newPa
 		return NewServiceError(ErrInvalidOTP, http.StatusBadRequest, "INVALID_OTP")
 	}
 
-	user, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return NewServiceError(ErrUserNotFound, http.StatusNotFound, "user not found")
 	}
@@ -397,7 +409,7 @@ func (s *UserService) UpdatePasswordWithOTP(userID, otp, existingPassword, newPa
 	if err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to hash password")
 	}
-	if err = s.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
+	if err = s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
 		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to update password")
 	}
 	helper.DeleteOTP(key)
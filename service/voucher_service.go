@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"service-travego/model"
+	"service-travego/repository"
+	"strings"
+	"time"
+)
+
+type VoucherService struct {
+	repo *repository.VoucherRepository
+}
+
+func NewVoucherService(repo *repository.VoucherRepository) *VoucherService {
+	return &VoucherService{repo: repo}
+}
+
+func (s *VoucherService) internalMessage(base string, err error) string {
+	if env := strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))); env != "production" && env != "prod" {
+		return fmt.Sprintf("%s: %v", base, err)
+	}
+	return base
+}
+
+func (s *VoucherService) validate(ctx context.Context, userID string, req *model.VoucherUpsertRequest) error {
+	req.OrganizationID = organizationFromContext(ctx)
+	req.UserID = userID
+	req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+	req.Scope = strings.ToUpper(strings.TrimSpace(req.Scope))
+	req.ScopeID = strings.TrimSpace(req.ScopeID)
+	req.DiscountType = strings.ToUpper(strings.TrimSpace(req.DiscountType))
+
+	if req.Code == "" || strings.ContainsAny(req.Code, " \t\n") {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "code must not contain spaces")
+	}
+	if _, ok := model.VoucherScopeLabel[req.Scope]; !ok {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "scope must be one of: ORGANIZATION, FLEET, TOUR_PACKAGE")
+	}
+	if req.Scope == model.VoucherScopeOrganization {
+		req.ScopeID = ""
+	} else if req.ScopeID == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "scope_id is required")
+	}
+	switch req.DiscountType {
+	case model.VoucherDiscountPercent:
+		if req.DiscountValue <= 0 || req.DiscountValue > 100 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "percent discount must be between 0 and 100")
+		}
+	case model.VoucherDiscountFixed:
+		if req.DiscountValue <= 0 {
+			return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "discount_value must be greater than 0")
+		}
+		req.MaxDiscount = 0
+	default:
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "discount_type must be PERCENT or FIXED")
+	}
+	if req.MaxDiscount < 0 || req.MinSpend < 0 || req.MaxUses < 0 || req.MaxUsesPerCustomer < 0 {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "max_discount, min_spend, max_uses and max_uses_per_customer must not be negative")
+	}
+
+	start, okStart := parsePricingDate(req.StartDate)
+	end, okEnd := parsePricingDate(req.EndDate)
+	if strings.TrimSpace(req.StartDate) != "" && !okStart {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid start_date (YYYY-MM-DD)")
+	}
+	if strings.TrimSpace(req.EndDate) != "" && !okEnd {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "invalid end_date (YYYY-MM-DD)")
+	}
+	if okStart && okEnd && end.Before(start) {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "end_date must be greater than or equal start_date")
+	}
+	req.StartDate, req.EndDate = "", ""
+	if okStart {
+		req.StartDate = start.Format("2006-01-02")
+	}
+	if okEnd {
+		req.EndDate = end.Format("2006-01-02")
+	}
+
+	exists, err := s.repo.CodeExists(ctx, req.Code, strings.TrimSpace(req.VoucherID))
+	if err != nil {
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate voucher code", err))
+	}
+	if exists {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_CODE_ALREADY_EXISTS")
+	}
+	return nil
+}
+
+func (s *VoucherService) List(ctx context.Context) ([]model.Voucher, error) {
+	items, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get vouchers", err))
+	}
+	return items, nil
+}
+
+func (s *VoucherService) Redemptions(ctx context.Context, voucherID string) ([]model.VoucherRedemption, error) {
+	if _, err := s.repo.GetByID(ctx, voucherID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrNotFound, http.StatusNotFound, "voucher not found")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get voucher", err))
+	}
+	items, err := s.repo.ListRedemptions(ctx, voucherID)
+	if err != nil {
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to get voucher redemptions", err))
+	}
+	return items, nil
+}
+
+func (s *VoucherService) Create(ctx context.Context, userID string, req *model.VoucherUpsertRequest) (string, error) {
+	req.VoucherID = ""
+	if err := s.validate(ctx, userID, req); err != nil {
+		return "", err
+	}
+	id, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to create voucher", err))
+	}
+	return id, nil
+}
+
+func (s *VoucherService) Update(ctx context.Context, userID string, req *model.VoucherUpsertRequest) error {
+	if strings.TrimSpace(req.VoucherID) == "" {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "voucher_id is required")
+	}
+	if err := s.validate(ctx, userID, req); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, req); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "voucher not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to update voucher", err))
+	}
+	return nil
+}
+
+func (s *VoucherService) Delete(ctx context.Context, userID, voucherID string) error {
+	if err := s.repo.Delete(ctx, userID, voucherID); err != nil {
+		if err == sql.ErrNoRows {
+			return NewServiceError(ErrNotFound, http.StatusNotFound, "voucher not found")
+		}
+		return NewServiceError(ErrInternalServer, http.StatusInternalServerError, "failed to delete voucher")
+	}
+	return nil
+}
+
+// FindCustomerID resolves the customer of a public order by phone/email.
+func (s *VoucherService) FindCustomerID(ctx context.Context, phone, email string) (string, error) {
+	id, err := s.repo.FindCustomerID(ctx, strings.TrimSpace(phone), strings.TrimSpace(email))
+	if err != nil {
+		return "", NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate customer", err))
+	}
+	return id, nil
+}
+
+// Apply validates a voucher code for an order and computes its discount. The
+// voucher is only booked when the order is saved (see repository.redeemVoucher).
+func (s *VoucherService) Apply(ctx context.Context, in model.VoucherApplyInput) (*model.VoucherApplication, error) {
+	code := strings.TrimSpace(in.Code)
+	if code == "" {
+		return nil, nil
+	}
+
+	v, err := s.repo.GetByCode(ctx, code)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_NOT_FOUND")
+		}
+		return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate voucher", err))
+	}
+	if !v.Active {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_INACTIVE")
+	}
+
+	at := in.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	today := at.Format("2006-01-02")
+	if v.StartDate != "" && today < v.StartDate {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_NOT_STARTED")
+	}
+	if v.EndDate != "" && today > v.EndDate {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_EXPIRED")
+	}
+
+	switch v.Scope {
+	case model.VoucherScopeFleet:
+		if in.OrderType != model.VoucherOrderFleet || v.ScopeID != strings.TrimSpace(in.ProductID) {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_NOT_APPLICABLE")
+		}
+	case model.VoucherScopeTourPackage:
+		if in.OrderType != model.VoucherOrderTourPackage || v.ScopeID != strings.TrimSpace(in.ProductID) {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_NOT_APPLICABLE")
+		}
+	}
+
+	if v.MinSpend > 0 && in.Amount < v.MinSpend {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_MIN_SPEND_NOT_MET")
+	}
+	if v.MaxUses > 0 && v.UsedCount >= v.MaxUses {
+		return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_USAGE_LIMIT_REACHED")
+	}
+	if v.MaxUsesPerCustomer > 0 && strings.TrimSpace(in.CustomerID) != "" {
+		used, err := s.repo.CountCustomerRedemptions(ctx, v.VoucherID, strings.TrimSpace(in.CustomerID))
+		if err != nil {
+			return nil, NewServiceError(ErrInternalServer, http.StatusInternalServerError, s.internalMessage("failed to validate voucher", err))
+		}
+		if used >= v.MaxUsesPerCustomer {
+			return nil, NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_USAGE_LIMIT_REACHED")
+		}
+	}
+
+	discount := v.DiscountValue
+	if v.DiscountType == model.VoucherDiscountPercent {
+		discount = in.Amount * v.DiscountValue / 100
+		if v.MaxDiscount > 0 && discount > v.MaxDiscount {
+			discount = v.MaxDiscount
+		}
+	}
+	discount = math.Round(discount)
+	if discount > in.Amount {
+		discount = in.Amount
+	}
+	if discount < 0 {
+		discount = 0
+	}
+
+	return &model.VoucherApplication{
+		VoucherID:          v.VoucherID,
+		Code:               v.Code,
+		Name:               v.Name,
+		DiscountType:       v.DiscountType,
+		DiscountValue:      v.DiscountValue,
+		Amount:             in.Amount,
+		DiscountAmount:     discount,
+		TotalAmount:        in.Amount - discount,
+		MaxUsesPerCustomer: v.MaxUsesPerCustomer,
+	}, nil
+}
+
+// voucherUsageError maps a failed redemption inside the order transaction.
+func voucherUsageError(err error) error {
+	if errors.Is(err, repository.ErrVoucherUsageLimit) {
+		return NewServiceError(ErrInvalidInput, http.StatusBadRequest, "VOUCHER_USAGE_LIMIT_REACHED")
+	}
+	return nil
+}
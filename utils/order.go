@@ -1,31 +1,22 @@
 package utils
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"service-travego/database"
 	"time"
 )
 
-func GenerateInvoiceNumber(db *sql.DB, driver, organizationID string, orderType int, now time.Time) (string, error) {
-	orgExpr := "organization_id = " + placeholder(driver, 2)
-	if driver == "postgres" || driver == "pgx" {
-		orgExpr = "organization_id::text = " + placeholder(driver, 2)
-	}
-	query := fmt.Sprintf(`SELECT COUNT(1) FROM transactions WHERE order_type = %s AND %s`, placeholder(driver, 1), orgExpr)
-
-	var count int
-	if err := database.QueryRow(db, query, orderType, organizationID).Scan(&count); err != nil {
-		return "", err
-	}
-	seq := count + 1
-	if seq < 1 {
-		seq = 1
-	}
-	return formatInvoiceNumber(orderType, now, seq), nil
+// TenantQuerier is a database.Tenant or database.TenantTx
+type TenantQuerier interface {
+	QueryRow(query string, args ...interface{}) *database.Row
+	OrganizationID() string
 }
 
-func GenerateInvoiceNumberTx(tx *sql.Tx, driver, organizationID string, orderType int, now time.Time) (string, error) {
+// GenerateTenantInvoiceNumber numbers the next invoice of the organization q
+// is scoped to
+func GenerateTenantInvoiceNumber(q TenantQuerier, driver string, orderType int, now time.Time) (string, error) {
 	orgExpr := "organization_id = " + placeholder(driver, 2)
 	if driver == "postgres" || driver == "pgx" {
 		orgExpr = "organization_id::text = " + placeholder(driver, 2)
@@ -33,7 +24,7 @@ func GenerateInvoiceNumberTx(tx *sql.Tx, driver, organizationID string, orderTyp
 	query := fmt.Sprintf(`SELECT COUNT(1) FROM transactions WHERE order_type = %s AND %s`, placeholder(driver, 1), orgExpr)
 
 	var count int
-	if err := database.TxQueryRow(tx, query, orderType, organizationID).Scan(&count); err != nil {
+	if err := q.QueryRow(query, orderType, q.OrganizationID()).Scan(&count); err != nil {
 		return "", err
 	}
 	seq := count + 1
@@ -84,7 +75,9 @@ func formatInvoiceNumber(orderType int, now time.Time, sequence int) string {
 	return fmt.Sprintf("INV-%s%d-%s%04d", datePart, orderType, timePart, sequence)
 }
 
-func GenerateRequestNumber(db *sql.DB, driver, organizationID string) (string, error) {
+// GenerateRequestNumber numbers the next inventory request of the organization
+// q is scoped to
+func GenerateRequestNumber(q TenantQuerier, driver string) (string, error) {
 	orgExpr := "organization_id = " + placeholder(driver, 1)
 	if driver == "postgres" || driver == "pgx" {
 		orgExpr = "organization_id::text = " + placeholder(driver, 1)
@@ -92,7 +85,7 @@ func GenerateRequestNumber(db *sql.DB, driver, organizationID string) (string, e
 	query := fmt.Sprintf("SELECT COUNT(1) FROM inventory_request WHERE %s ", orgExpr)
 
 	var count int
-	if err := database.QueryRow(db, query, organizationID).Scan(&count); err != nil {
+	if err := q.QueryRow(query, q.OrganizationID()).Scan(&count); err != nil {
 		return "", err
 	}
 	seq := count + 1
@@ -115,7 +108,9 @@ func GenerateRequestNumber(db *sql.DB, driver, organizationID string) (string, e
 	return fmt.Sprintf("REQ-%s-00%s", datePart, seqStr), nil
 }
 
-func GenerateItemSKU(db *sql.DB, driver, organizationID string) (string, error) {
+// GenerateItemSKU numbers the next inventory item of the organization q is
+// scoped to
+func GenerateItemSKU(q TenantQuerier, driver string) (string, error) {
 	orgExpr := "organization_id = " + placeholder(driver, 1)
 	if driver == "postgres" || driver == "pgx" {
 		orgExpr = "organization_id::text = " + placeholder(driver, 1)
@@ -123,7 +118,7 @@ func GenerateItemSKU(db *sql.DB, driver, organizationID string) (string, error)
 	query := fmt.Sprintf("SELECT COUNT(1) FROM inventory_items WHERE %s ", orgExpr)
 
 	var count int
-	if err := database.QueryRow(db, query, organizationID).Scan(&count); err != nil {
+	if err := q.QueryRow(query, q.OrganizationID()).Scan(&count); err != nil {
 		return "", err
 	}
 	seq := count + 1
@@ -148,7 +143,9 @@ func GenerateItemSKU(db *sql.DB, driver, organizationID string) (string, error)
 	return fmt.Sprintf("SKU-%s0-%s", skuSeq, yearMonth), nil
 }
 
-func GeneratePurchaseOrderID(db *sql.DB, driver, organizationID string) (string, error) {
+// GeneratePurchaseOrderID numbers the next purchase order of the organization q
+// is scoped to
+func GeneratePurchaseOrderID(q TenantQuerier, driver string) (string, error) {
 	orgExpr := "organization_id = " + placeholder(driver, 1)
 	if driver == "postgres" || driver == "pgx" {
 		orgExpr = "organization_id::text = " + placeholder(driver, 1)
@@ -156,7 +153,7 @@ func GeneratePurchaseOrderID(db *sql.DB, driver, organizationID string) (string,
 	query := fmt.Sprintf("SELECT COUNT(1) FROM inventory_orders WHERE %s ", orgExpr)
 
 	var count int
-	if err := database.QueryRow(db, query, organizationID).Scan(&count); err != nil {
+	if err := q.QueryRow(query, q.OrganizationID()).Scan(&count); err != nil {
 		return "", err
 	}
 	seq := count + 1
@@ -180,11 +177,12 @@ func GeneratePurchaseOrderID(db *sql.DB, driver, organizationID string) (string,
 	return fmt.Sprintf("PO-00%s-%s", seqStr, datePart), nil
 }
 
-// GenerateSubsInvoiceID generates invoice ID for subscription with format TRV-{2digitrandom}{sequence}-{MMYY}
-func GenerateSubsInvoiceID(db *sql.DB, driver string) (string, error) {
+// GenerateSubsInvoiceID generates invoice ID for subscription with format TRV-{2digitrandom}{sequence}-{MMYY};
+// the sequence is Travego's own and counts the transactions of every organization
+func GenerateSubsInvoiceID(ctx context.Context, db *sql.DB, driver string) (string, error) {
 	query := "SELECT COUNT(1) FROM travego_transactions"
 	var count int
-	if err := database.QueryRow(db, query).Scan(&count); err != nil {
+	if err := database.QueryRowContext(ctx, db, query).Scan(&count); err != nil {
 		return "", err
 	}
 	seq := count + 1